@@ -0,0 +1,227 @@
+// prometheus/backend/cmd/admin.go
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"prometheus/backend/config"
+	"prometheus/backend/database"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/cache"
+	"prometheus/backend/internal/keyring"
+	"prometheus/backend/internal/seed"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const adminUsage = `Usage: <server binary> admin <command> [flags]
+
+Emergency operations run over SSH, without the API. Each one is recorded in the audit trail.
+
+Commands:
+  reset-password -user <id|username|email> [-password-stdin]
+        Set a new password and log the user out everywhere. The password is generated and printed, or
+        with -password-stdin read from the first line of standard input, prompting for it on a terminal.
+  unlock -user <id|username|email>
+        Reactivate a deactivated user so they can log in again.
+  rotate-jwt-key
+        Make a new JWT signing key active. Tokens signed by the previous key stay valid until they expire.
+        Needs KEY_MASTER_KEY; without it tokens are signed with JWT_SECRET, changed by redeploying.
+  seed-rollback -seed <name>
+        Undo this build's version of a core seed ("roles"), so the next start applies it again.
+        Fails if that version isn't the latest applied or the seed can't be undone.
+`
+
+// runAdmin runs the admin command in args and returns the process exit code. Changes are audited as the
+// operating system user running the command, e.g. "cli:alice".
+func runAdmin(cfg *config.Config, db *gorm.DB, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+	appCache, err := cache.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to initialize cache: %v\n", err)
+		return 1
+	}
+	auditor := audit.NewService(db)
+	// Operators reach the users of every organization. With Redis, running instances drop the user's cached
+	// status at once; otherwise within a minute.
+	users := auth.NewPlatformUserAdminService(db, auditor, auth.NewUserStatusCache(db, appCache))
+	actor := cliActor()
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	identifier := flags.String("user", "", "ID, username or email of the user")
+	passwordStdin := flags.Bool("password-stdin", false, "Read the new password from standard input")
+	seedName := flags.String("seed", "", "Name of the core seed")
+	if err := flags.Parse(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n%s", err, adminUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "reset-password":
+		err = resetPassword(db, users, actor, *identifier, *passwordStdin)
+	case "unlock":
+		err = unlock(db, users, actor, *identifier)
+	case "rotate-jwt-key":
+		err = rotateJWTKey(cfg, db, auditor, actor)
+	case "seed-rollback":
+		err = rollbackSeed(cfg, db, auditor, actor, *seedName)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown admin command %q\n\n%s", args[0], adminUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// resetPassword prints the new password only when it was generated. A chosen password is never taken
+// from the command line, where it would end up in the shell history and the process list.
+func resetPassword(db *gorm.DB, users auth.UserAdminService, actor audit.Actor, identifier string, fromStdin bool) error {
+	target, err := findUser(db, identifier)
+	if err != nil {
+		return err
+	}
+	generated := !fromStdin
+	var password string
+	if generated {
+		password, err = auth.GenerateRandomPassword()
+	} else {
+		password, err = readPassword(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	if err := users.ResetPassword(actor, nil, target.ID, password); err != nil {
+		return err
+	}
+	fmt.Printf("Password of %s (ID %d) reset; all of their sessions were ended.\n", target.Username, target.ID)
+	if generated {
+		fmt.Printf("New password: %s\n", password)
+	}
+	return nil
+}
+
+// readPassword reads a password from the first line of in, prompting for it when in is a terminal.
+func readPassword(in *os.File) (string, error) {
+	if info, err := in.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "New password: ")
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("no password given on standard input")
+	}
+	return password, nil
+}
+
+func unlock(db *gorm.DB, users auth.UserAdminService, actor audit.Actor, identifier string) error {
+	target, err := findUser(db, identifier)
+	if err != nil {
+		return err
+	}
+	if target.IsActive {
+		fmt.Printf("%s (ID %d) is already active.\n", target.Username, target.ID)
+		return nil
+	}
+	if _, err := users.SetStatus(actor, nil, target.ID, true); err != nil {
+		return err
+	}
+	fmt.Printf("%s (ID %d) reactivated.\n", target.Username, target.ID)
+	return nil
+}
+
+// rotateJWTKey rotates through the keyring service, as POST /admin/signing-keys/rotate does. Running
+// instances pick the new key up when they next reload their keys.
+func rotateJWTKey(cfg *config.Config, db *gorm.DB, auditor audit.Service, actor audit.Actor) error {
+	if cfg.KeyMasterKey == "" {
+		return errors.New("KEY_MASTER_KEY is not configured: tokens are signed with JWT_SECRET, change it and redeploy")
+	}
+	ring, err := keyring.New(db, cfg)
+	if err != nil {
+		return err
+	}
+	key, err := keyring.NewService(db, ring, auditor, cfg.AppEnv).Rotate(actor)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Signing key %s is now active.\n", key.KID)
+	return nil
+}
+
+// rollbackSeed rolls back this build's version of the named core seed. Module seeds aren't known here:
+// admin commands run without setting up the modules.
+func rollbackSeed(cfg *config.Config, db *gorm.DB, auditor audit.Service, actor audit.Actor, name string) error {
+	if name == "" {
+		return errors.New("-seed is required")
+	}
+	for _, s := range database.Seeds(cfg) {
+		if s.Name != name {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := seed.RollbackTx(tx, s); err != nil {
+				return err
+			}
+			return auditor.RecordTx(tx, actor, audit.Entry{
+				Action: "seed.rollback", EntityType: "seed", EntityID: fmt.Sprintf("%s@%d", s.Name, s.Version),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Seed %s version %d rolled back; it is applied again on the next start.\n", s.Name, s.Version)
+		return nil
+	}
+	return fmt.Errorf("no core seed %q", name)
+}
+
+// findUser looks a user up by ID, username or email, across organizations.
+func findUser(db *gorm.DB, identifier string) (*auth.User, error) {
+	if identifier == "" {
+		return nil, errors.New("-user is required")
+	}
+	query := db.Where("username = ? OR email = ?", identifier, identifier)
+	if id, err := strconv.ParseUint(identifier, 10, 32); err == nil {
+		query = db.Where("id = ?", id)
+	}
+	var target auth.User
+	if err := query.First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no user %q", identifier)
+		}
+		return nil, fmt.Errorf("failed to look up user %q: %w", identifier, err)
+	}
+	return &target, nil
+}
+
+// cliActor is who admin commands are audited as: the operating system user, who logged in over SSH
+// (through sudo, the user who ran it).
+func cliActor() audit.Actor {
+	name := os.Getenv("SUDO_USER")
+	if name == "" {
+		if current, err := user.Current(); err == nil {
+			name = current.Username
+		}
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return audit.Actor{Username: "cli:" + name}
+}
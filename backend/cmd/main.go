@@ -2,18 +2,45 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"prometheus/backend/config"
 	"prometheus/backend/database"
+	"prometheus/backend/internal/apikey"
+	"prometheus/backend/internal/audit"
 	"prometheus/backend/internal/auth" // Import auth package for User model
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/cache"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/customfield"
+	"prometheus/backend/internal/division"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/events"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/mail"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/mtls"
+	"prometheus/backend/internal/organization"
 	"prometheus/backend/internal/role" // Import role package for Role model
+	"prometheus/backend/internal/seed"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/tenant"
 	"prometheus/backend/routes"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// shutdownTimeout is how long requests in flight and job workers get to finish on shutdown.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	_ = godotenv.Load()
 	_ = godotenv.Load("../.env")
@@ -22,48 +49,177 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error: Failed to load configuration: %v", err)
 	}
+	// Devtools may shift the application time (see package clock) everywhere but in production.
+	if cfg.AppEnv != "production" {
+		clock.Enable()
+	}
+	switch {
+	case cfg.DevIntegrations == config.IntegrationsFake && cfg.AppEnv == "production":
+		log.Fatalf("Error: DEV_INTEGRATIONS=fake must not be used in production")
+	case cfg.DevIntegrations == config.IntegrationsFake:
+		log.Println("Fake integrations enabled: mail, file storage and payments stay in memory, see /api/v1/devtools/inbox.")
+	case cfg.DevIntegrations != "":
+		log.Fatalf("Error: Unknown DEV_INTEGRATIONS mode %q", cfg.DevIntegrations)
+	}
+	switch cfg.SchemaDriftCheck {
+	case config.SchemaDriftOff, config.SchemaDriftWarn, config.SchemaDriftFail:
+	default:
+		log.Fatalf("Error: Unknown SCHEMA_DRIFT_CHECK mode %q", cfg.SchemaDriftCheck)
+	}
 
 	db, err := database.ConnectDB(cfg)
 	if err != nil {
 		log.Fatalf("Error: Failed to connect to the database: %v", err)
 	}
 	log.Println("Database connected successfully.")
+	reportingDB, err := database.ConnectReportingDB(cfg, db)
+	if err != nil {
+		log.Fatalf("Error: Failed to connect to the reporting database: %v", err)
+	}
 
-	log.Println("Running database auto-migrations...")
-	err = db.AutoMigrate(
+	// user_roles carries grant expiry, so GORM must know its model before migrating and querying users.
+	if err := auth.SetupJoinTables(db); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	// "admin <command>" runs an emergency operation over SSH and exits, without migrating or serving the API.
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(runAdmin(cfg, db, os.Args[2:]))
+	}
+
+	coreModels := []any{
 		&auth.User{},
 		&role.Role{},
-	)
-	if err != nil {
+		&auth.ScopedRole{},
+		&auth.RoleRequest{},
+		&auth.LoginEvent{},
+		&auth.UserPreferences{},
+		&auth.UserImport{},
+		&customfield.Definition{},
+		&mail.TemplateOverride{},
+		&employee.Employee{},
+		&employee.NamePolicy{},
+		&division.Division{},
+		&jobs.Job{},
+		&audit.Log{},
+		&organization.Organization{},
+		&tenant.OnboardingStep{},
+		&events.Event{},
+		&events.Consumer{},
+		&apikey.Key{},
+		&apikey.Usage{},
+		&seed.Record{},
+	}
+	// Drift is checked before migrating, while the schema still shows what someone else's AutoMigrate left.
+	if err := database.CheckSchema(db, cfg.SchemaDriftCheck, "core", coreModels...); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	log.Println("Running database auto-migrations...")
+	if err := db.AutoMigrate(coreModels...); err != nil {
 		log.Fatalf("Error: Failed to auto-migrate database schema: %v", err)
 	}
+	if err := database.MigrateUserRoles(db); err != nil {
+		log.Fatalf("Error: Failed to migrate user roles: %v", err)
+	}
+	if err := audit.Protect(db); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := auth.MigrateUsernameIndex(db); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 	log.Println("Database auto-migrations completed successfully.")
 
-	// Seed the database with initial data (roles, god admin)
-	// This should run after migrations to ensure tables exist.
-	log.Println("Starting database seeding process...")
-	if err := database.SeedRoles(db); err != nil {
-		// Log the error but don't necessarilyFatalf, as the app might still run
-		// depending on how critical initial roles are for startup vs. dynamic creation.
-		log.Printf("Error during role seeding: %v", err)
-	} else {
-		log.Println("Role seeding completed.")
+	// Core seeds (roles, god admin) need only the core tables; module seeds run after the module migrations.
+	// Seeds are recorded per version, so this applies each one once; a failed seed is retried on the next start.
+	if err := seed.Apply(db, database.Seeds(cfg)...); err != nil {
+		log.Fatalf("Error: Failed to seed the database: %v", err)
 	}
 
-	if err := database.SeedGodAdmin(db, cfg); err != nil {
-		log.Printf("Error during god admin seeding: %v", err)
-	} else {
-		log.Println("God Admin user seeding process completed.")
+	// Authorization policies are stored in the database and loaded by the Casbin enforcer.
+	enforcer, err := authz.NewEnforcer(db)
+	if err != nil {
+		log.Fatalf("Error: Failed to initialize authorization enforcer: %v", err)
 	}
-	log.Println("Database seeding process finished.")
+
+	appCache, err := cache.New(cfg)
+	if err != nil {
+		log.Fatalf("Error: Failed to initialize cache: %v", err)
+	}
+
+	files, err := storage.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Error: Failed to initialize file storage: %v", err)
+	}
+
+	// Background job queue; handlers are registered while setting up routes, workers start afterwards.
+	jobQueue := jobs.NewQueue(db, cfg.JobWorkers)
+
+	// Each module contributes its own health checks to /readyz and /metrics. Feature modules listed in
+	// MODULES_DISABLED are skipped along with their routes, migrations and jobs.
+	modules := module.NewRegistry(cfg.ModulesDisabled...)
+	modules.Register(database.NewModule(db, reportingDB))
+	modules.Register(cache.NewModule(appCache))
+	modules.Register(jobQueue)
 
 	router := gin.Default()
-	routes.SetupRoutes(router, db, cfg)
+	// gin trusts every proxy by default, letting any caller pick its client IP through X-Forwarded-For.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Error: Invalid TRUSTED_PROXIES: %v", err)
+	}
+	routes.SetupRoutes(router, db, reportingDB, cfg, enforcer, appCache, files, jobQueue, modules)
+
+	// Feature modules are known once routes are set up; migrate the tables of the enabled ones.
+	if models := modules.Models(); len(models) > 0 {
+		if err := database.CheckSchema(db, cfg.SchemaDriftCheck, "modules", models...); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if err := db.AutoMigrate(models...); err != nil {
+			log.Fatalf("Error: Failed to auto-migrate module schemas: %v", err)
+		}
+	}
+	if err := seed.Apply(db, modules.Seeds()...); err != nil {
+		log.Fatalf("Error: Failed to seed module data: %v", err)
+	}
+
+	// SIGTERM (e.g. a deploy) stops the job workers, which requeue the jobs they were running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	jobQueue.Start(ctx)
+
+	// The internal listener serves the same API over TLS to other services, with mutual TLS when a client
+	// CA is configured.
+	if cfg.InternalAddr != "" {
+		tlsConfig, err := mtls.ServerConfig(cfg.InternalTLSCert, cfg.InternalTLSKey, cfg.InternalTLSClientCA)
+		if err != nil {
+			log.Fatalf("Error: Failed to configure the internal listener: %v", err)
+		}
+		internal := &http.Server{Addr: cfg.InternalAddr, Handler: router, TLSConfig: tlsConfig, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			log.Printf("Internal listener starting on https://localhost%s (mutual TLS: %t)", cfg.InternalAddr, cfg.InternalTLSClientCA != "")
+			if err := internal.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Error: Failed to start the internal listener: %v", err)
+			}
+		}()
+	}
 
 	serverAddr := fmt.Sprintf(":%s", cfg.Port)
 	log.Printf("Server starting on http://localhost%s (AppEnv: %s)", serverAddr, cfg.AppEnv)
 
-	if err := router.Run(serverAddr); err != nil {
-		log.Fatalf("Error: Failed to start server: %v", err)
+	server := &http.Server{Addr: serverAddr, Handler: router, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error: Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to stop the server gracefully: %v", err)
+	}
+	if err := jobQueue.Wait(shutdownCtx); err != nil {
+		log.Printf("Job workers didn't stop in time; their jobs are requeued once their lease expires: %v", err)
 	}
 }
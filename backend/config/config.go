@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"strconv" // For converting string to int
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -21,8 +22,94 @@ type Config struct {
 	JWTExpirationHours int // Added for JWT expiration
 	GodAdminEmail      string
 	GodAdminPassword   string
+	ResponseEnvelope   string // Default envelope mode for success responses: "standard" or "raw"
+	CacheDriver        string // "memory" or "redis"
+	RedisAddr          string
+	RedisPassword      string
+	RedisDB            int
+	JobWorkers         int // Number of background job workers per instance
+	// Billing (Stripe). Billing endpoints are disabled while StripeSecretKey is empty.
+	StripeSecretKey       string
+	StripeWebhookSecret   string
+	StripePriceStandard   string // Stripe price ID of the "standard" plan
+	StripePriceEnterprise string // Stripe price ID of the "enterprise" plan
+	BillingSuccessURL     string // Where Stripe Checkout redirects after payment
+	BillingCancelURL      string
+	// White-label tenants
+	AppBaseURL       string // Frontend URL for users without an organization; links on this host are moved to the tenant's host
+	TenantBaseDomain string // Tenants are served on <slug>.<TenantBaseDomain>; empty disables subdomain resolution
+	// Demo tenant for sales demos, wiped and reseeded with fake data. Disabled while DemoTenantSlug is empty.
+	DemoTenantSlug    string
+	DemoAdminPassword string // Password of the reseeded demo accounts
+	DemoResetHour     int    // Hour of day (UTC) of the nightly reset; -1 disables the schedule
+	// Feature modules turned off for this deployment (e.g. "billing,demo-tenant"); their routes, migrations
+	// and jobs are not registered.
+	ModulesDisabled []string
+	// Shared secret signing RBAC bundles exchanged between environments; empty disables export/import.
+	RBACBundleSecret string
+	// Base64 of the 32-byte master key sealing the JWT signing keys kept in the database (see internal/keyring).
+	// While empty, every token is signed with JWTSecret and keys can't be rotated.
+	KeyMasterKey string
+	// Outgoing mail. Messages are only logged while SMTPHost is empty.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	APIBaseURL   string // Public URL of this API, for links in emails (e.g. report downloads)
+	// Whether links in notification emails go through the API to record opens
+	NotificationEmailTracking bool
+	// Uploaded files (avatars, ...): "local" keeps them in StorageLocalDir, "s3" in S3Bucket.
+	StorageBackend  string
+	StorageLocalDir string
+	S3Bucket        string
+	S3Region        string
+	S3Endpoint      string // For S3-compatible services such as MinIO; empty uses AWS
+	// Event bridge relaying domain events to existing streaming infrastructure; empty disables it.
+	EventBridge      string // "kafka" or "nats"
+	EventBridgeURLs  string // Comma-separated Kafka brokers or NATS server URLs
+	EventBridgeTopic string // Kafka topic, or NATS subject prefix (events go to "<prefix>.<event type>")
+	// Internal listener for service-to-service calls, serving the same API over TLS; empty disables it. With
+	// InternalTLSClientCA set, clients must present a certificate it issued (mutual TLS), and API keys bound to
+	// a certificate identity authenticate with the certificate alone.
+	InternalAddr        string // e.g. ":8443"
+	InternalTLSCert     string // PEM certificate chain of the listener
+	InternalTLSKey      string // PEM private key of the listener
+	InternalTLSClientCA string // PEM bundle of the CAs issuing client certificates
+	// Proxies (addresses or CIDRs) whose X-Forwarded-For header is believed for the client IP, which API key
+	// address restrictions, login history and audit entries rely on. Empty trusts no proxy and uses the peer address.
+	TrustedProxies []string
+	// Whether users may change their own username under /me/username; admins always can.
+	UsernameSelfService bool
+	// Local development: IntegrationsFake replaces mail, file storage and payments with in-memory fakes whose
+	// output is served under /devtools. Refused in production.
+	DevIntegrations string
+	// What to do at boot when the database schema drifted from the models, e.g. after someone ran AutoMigrate
+	// from another build: SchemaDriftWarn logs it, SchemaDriftFail refuses to start, SchemaDriftOff skips the check.
+	SchemaDriftCheck string
+	// Low-privilege database user analytics and report generation read through, on a read-only connection;
+	// it should only be granted SELECT. Empty shares the main connection.
+	ReportingDBUser     string
+	ReportingDBPassword string
+	// Days before a fixed-term employment contract ends that HR is reminded of it, unless set on the contract
+	ContractReminderDays int
+	// Latency objectives: the p95 routes should stay under. SLOTargets lists routes with their own target as
+	// "METHOD /route=duration" pairs (e.g. "GET /api/v1/employees=300ms"); other routes get SLODefaultP95Ms,
+	// or no objective when it is 0.
+	SLOTargets      string
+	SLODefaultP95Ms int
 }
 
+// IntegrationsFake is the DevIntegrations mode using in-memory fakes.
+const IntegrationsFake = "fake"
+
+// SchemaDriftCheck modes.
+const (
+	SchemaDriftOff  = "off"
+	SchemaDriftWarn = "warn"
+	SchemaDriftFail = "fail"
+)
+
 // LoadConfig reads configuration from environment variables or .env file
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists.
@@ -40,6 +127,38 @@ func LoadConfig() (*Config, error) {
 		jwtExpHours = 168 // Fallback default if conversion fails
 	}
 
+	redisDB, err := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	if err != nil {
+		redisDB = 0
+	}
+
+	jobWorkers, err := strconv.Atoi(getEnv("JOB_WORKERS", "2"))
+	if err != nil {
+		jobWorkers = 2
+	}
+
+	demoResetHour, err := strconv.Atoi(getEnv("DEMO_RESET_HOUR", "3"))
+	if err != nil || demoResetHour > 23 {
+		demoResetHour = 3
+	}
+
+	contractReminderDays, err := strconv.Atoi(getEnv("CONTRACT_REMINDER_DAYS", "30"))
+	if err != nil || contractReminderDays < 1 || contractReminderDays > 365 {
+		contractReminderDays = 30
+	}
+
+	var trustedProxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
+	sloDefaultP95Ms, err := strconv.Atoi(getEnv("SLO_DEFAULT_P95_MS", "1000"))
+	if err != nil || sloDefaultP95Ms < 0 {
+		sloDefaultP95Ms = 1000
+	}
+
 	return &Config{
 		AppEnv:             getEnv("APP_ENV", "development"),
 		Port:               getEnv("PORT", "8080"),
@@ -52,6 +171,69 @@ func LoadConfig() (*Config, error) {
 		JWTExpirationHours: jwtExpHours, // Added
 		GodAdminEmail:      getEnv("GOD_ADMIN_EMAIL", "godadmin@example.com"),
 		GodAdminPassword:   getEnv("GOD_ADMIN_PASSWORD", "SecureGodAdminP@ssw0rd123!"),
+		ResponseEnvelope:   getEnv("RESPONSE_ENVELOPE", "standard"),
+		CacheDriver:        getEnv("CACHE_DRIVER", "memory"),
+		RedisAddr:          getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:      getEnv("REDIS_PASSWORD", ""),
+		RedisDB:            redisDB,
+		JobWorkers:         jobWorkers,
+
+		StripeSecretKey:       getEnv("STRIPE_SECRET_KEY", ""),
+		StripeWebhookSecret:   getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		StripePriceStandard:   getEnv("STRIPE_PRICE_STANDARD", ""),
+		StripePriceEnterprise: getEnv("STRIPE_PRICE_ENTERPRISE", ""),
+		BillingSuccessURL:     getEnv("BILLING_SUCCESS_URL", "http://localhost:3000/settings/billing?checkout=success"),
+		BillingCancelURL:      getEnv("BILLING_CANCEL_URL", "http://localhost:3000/settings/billing?checkout=cancelled"),
+
+		AppBaseURL:       getEnv("APP_BASE_URL", "http://localhost:3000"),
+		TenantBaseDomain: getEnv("TENANT_BASE_DOMAIN", ""),
+
+		DemoTenantSlug:    getEnv("DEMO_TENANT_SLUG", ""),
+		DemoAdminPassword: getEnv("DEMO_ADMIN_PASSWORD", "DemoP@ssw0rd123!"),
+		DemoResetHour:     demoResetHour,
+
+		ModulesDisabled: strings.Split(getEnv("MODULES_DISABLED", ""), ","),
+
+		RBACBundleSecret: getEnv("RBAC_BUNDLE_SECRET", ""),
+		KeyMasterKey:     getEnv("KEY_MASTER_KEY", ""),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "Prometheus <no-reply@localhost>"),
+		APIBaseURL:   getEnv("API_BASE_URL", "http://localhost:8080"),
+
+		NotificationEmailTracking: getEnv("NOTIFICATION_EMAIL_TRACKING", "false") == "true",
+
+		StorageBackend:  getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir: getEnv("STORAGE_LOCAL_DIR", "./data/uploads"),
+		S3Bucket:        getEnv("S3_BUCKET", ""),
+		S3Region:        getEnv("S3_REGION", "us-east-1"),
+		S3Endpoint:      getEnv("S3_ENDPOINT", ""),
+
+		EventBridge:      getEnv("EVENT_BRIDGE", ""),
+		EventBridgeURLs:  getEnv("EVENT_BRIDGE_URLS", ""),
+		EventBridgeTopic: getEnv("EVENT_BRIDGE_TOPIC", "prometheus.events"),
+		DevIntegrations:  getEnv("DEV_INTEGRATIONS", ""),
+
+		InternalAddr:        getEnv("INTERNAL_ADDR", ""),
+		InternalTLSCert:     getEnv("INTERNAL_TLS_CERT", ""),
+		InternalTLSKey:      getEnv("INTERNAL_TLS_KEY", ""),
+		InternalTLSClientCA: getEnv("INTERNAL_TLS_CLIENT_CA", ""),
+		TrustedProxies:      trustedProxies,
+
+		UsernameSelfService: getEnv("USERNAME_SELF_SERVICE", "false") == "true",
+
+		SchemaDriftCheck: getEnv("SCHEMA_DRIFT_CHECK", SchemaDriftWarn),
+
+		ReportingDBUser:     getEnv("REPORTING_DB_USER", ""),
+		ReportingDBPassword: getEnv("REPORTING_DB_PASSWORD", ""),
+
+		ContractReminderDays: contractReminderDays,
+
+		SLOTargets:      getEnv("SLO_TARGETS", ""),
+		SLODefaultP95Ms: sloDefaultP95Ms,
 	}, nil
 }
 
@@ -20,25 +20,8 @@ func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta", // Adjusted TimeZone
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
 
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             200 * time.Millisecond, // Slow SQL threshold
-			LogLevel:                  logger.Info,            // Log level
-			IgnoreRecordNotFoundError: true,                   // Ignore ErrRecordNotFound error for logger
-			Colorful:                  true,                   // Enable color
-		},
-	)
-
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: newLogger,
-		// NamingStrategy: schema.NamingStrategy{ // Optional: if you need specific table naming conventions
-		// 	TablePrefix:   "hris_", // Example prefix
-		// 	SingularTable: false,   // Use plural table names (e.g., "users" instead of "user")
-		// },
-	})
-
+	DB, err = open(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -56,3 +39,24 @@ func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
 	fmt.Println("Database connection established and configured successfully.")
 	return DB, nil
 }
+
+// open opens a Postgres connection with the application's SQL logger.
+func open(dsn string) (*gorm.DB, error) {
+	newLogger := logger.New(
+		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
+		logger.Config{
+			SlowThreshold:             200 * time.Millisecond, // Slow SQL threshold
+			LogLevel:                  logger.Info,            // Log level
+			IgnoreRecordNotFoundError: true,                   // Ignore ErrRecordNotFound error for logger
+			Colorful:                  true,                   // Enable color
+		},
+	)
+
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: newLogger,
+		// NamingStrategy: schema.NamingStrategy{ // Optional: if you need specific table naming conventions
+		// 	TablePrefix:   "hris_", // Example prefix
+		// 	SingularTable: false,   // Use plural table names (e.g., "users" instead of "user")
+		// },
+	})
+}
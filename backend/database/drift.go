@@ -0,0 +1,172 @@
+// prometheus/backend/database/drift.go
+package database
+
+import (
+	"fmt"
+	"log"
+	"prometheus/backend/config"
+	"slices"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Drift is a difference between the live schema and the models that AutoMigrate would not explain: a
+// column no model knows, or one whose type or nullability differs from its field. It typically means
+// someone ran AutoMigrate from another build, or changed the schema by hand.
+type Drift struct {
+	Table  string
+	Column string
+	Detail string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s.%s: %s", d.Table, d.Column, d.Detail)
+}
+
+// SchemaDiff compares the live schema with the models. Pending lists the tables and columns the models
+// add, which the next AutoMigrate creates; Drift lists everything else.
+type SchemaDiff struct {
+	Pending []string
+	Drift   []Drift
+}
+
+// typeAliases maps the type names GORM declares to the names Postgres reports for them.
+var typeAliases = map[string]string{
+	"bigserial":                   "int8",
+	"bigint":                      "int8",
+	"serial":                      "int4",
+	"integer":                     "int4",
+	"int":                         "int4",
+	"smallserial":                 "int2",
+	"smallint":                    "int2",
+	"boolean":                     "bool",
+	"decimal":                     "numeric",
+	"double precision":            "float8",
+	"real":                        "float4",
+	"character varying":           "varchar",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+}
+
+// DiffSchema compares the live schema with the tables of models. Tables no model maps are not reported:
+// disabled modules and many-to-many join tables own some.
+func DiffSchema(db *gorm.DB, models ...any) (*SchemaDiff, error) {
+	tables := map[string][]*schema.Schema{}
+	var order []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		if _, seen := tables[stmt.Table]; !seen {
+			order = append(order, stmt.Table)
+		}
+		tables[stmt.Table] = append(tables[stmt.Table], stmt.Schema)
+	}
+
+	diff := &SchemaDiff{}
+	migrator := db.Migrator()
+	for _, table := range order {
+		if !migrator.HasTable(table) {
+			diff.Pending = append(diff.Pending, table)
+			continue
+		}
+		live, err := migrator.ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+		}
+		columns := make(map[string]gorm.ColumnType, len(live))
+		for _, column := range live {
+			columns[column.Name()] = column
+		}
+		expected := map[string]bool{}
+		for _, s := range tables[table] {
+			for _, field := range s.Fields {
+				if field.DBName == "" || field.IgnoreMigration || expected[field.DBName] {
+					continue
+				}
+				expected[field.DBName] = true
+				column, ok := columns[field.DBName]
+				if !ok {
+					diff.Pending = append(diff.Pending, table+"."+field.DBName)
+					continue
+				}
+				diff.Drift = append(diff.Drift, compareColumn(db, table, field, column)...)
+			}
+		}
+		var extra []string
+		for name := range columns {
+			if !expected[name] {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		for _, name := range extra {
+			diff.Drift = append(diff.Drift, Drift{Table: table, Column: name, Detail: "column not in any model"})
+		}
+	}
+	return diff, nil
+}
+
+// compareColumn reports how a live column differs from its field.
+func compareColumn(db *gorm.DB, table string, field *schema.Field, column gorm.ColumnType) []Drift {
+	var drift []Drift
+	declared := strings.ToLower(strings.TrimSpace(db.Dialector.DataTypeOf(field)))
+	want, size := declared, int64(0)
+	if i := strings.Index(declared, "("); i >= 0 {
+		want = strings.TrimSpace(declared[:i])
+		if _, err := fmt.Sscanf(declared[i:], "(%d)", &size); err != nil {
+			size = 0 // Precision and scale, e.g. numeric(12,2), are left to Postgres
+		}
+	}
+	if alias, ok := typeAliases[want]; ok {
+		want = alias
+	}
+	got := strings.ToLower(column.DatabaseTypeName())
+	if alias, ok := typeAliases[got]; ok {
+		got = alias
+	}
+	if want != "" && want != got && !slices.Contains(db.Migrator().GetTypeAliases(got), want) {
+		drift = append(drift, Drift{Table: table, Column: field.DBName, Detail: fmt.Sprintf("type %s, model declares %s", got, declared)})
+	} else if length, ok := column.Length(); ok && size > 0 && length != size {
+		drift = append(drift, Drift{Table: table, Column: field.DBName, Detail: fmt.Sprintf("length %d, model declares %d", length, size)})
+	}
+	if nullable, ok := column.Nullable(); ok && !field.PrimaryKey && nullable == field.NotNull {
+		detail := "nullable, model declares NOT NULL"
+		if !nullable {
+			detail = "NOT NULL, model declares it nullable"
+		}
+		drift = append(drift, Drift{Table: table, Column: field.DBName, Detail: detail})
+	}
+	return drift
+}
+
+// CheckSchema compares the live schema with models before they are migrated, as configured by mode (see
+// config.SchemaDriftCheck). It logs what it finds, and fails only in SchemaDriftFail mode with drift;
+// pending tables and columns are what migrating is for.
+func CheckSchema(db *gorm.DB, mode, scope string, models ...any) error {
+	if mode == config.SchemaDriftOff {
+		return nil
+	}
+	diff, err := DiffSchema(db, models...)
+	if err != nil {
+		return fmt.Errorf("failed to check the %s schema for drift: %w", scope, err)
+	}
+	if len(diff.Pending) > 0 {
+		log.Printf("Schema check (%s): %d table(s) or column(s) to create: %s", scope, len(diff.Pending), strings.Join(diff.Pending, ", "))
+	}
+	if len(diff.Drift) == 0 {
+		return nil
+	}
+	for _, d := range diff.Drift {
+		log.Printf("Schema drift (%s): %s", scope, d)
+	}
+	if mode == config.SchemaDriftFail {
+		return fmt.Errorf("the %s schema drifted from the models in %d place(s); fix the schema or set SCHEMA_DRIFT_CHECK=warn", scope, len(diff.Drift))
+	}
+	log.Printf("Warning: the %s schema drifted from the models in %d place(s); AutoMigrate may change some of them.", scope, len(diff.Drift))
+	return nil
+}
@@ -0,0 +1,33 @@
+// prometheus/backend/database/migrate.go
+package database
+
+import (
+	"fmt"
+	"log"
+	"prometheus/backend/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+// MigrateUserRoles moves the legacy single-role column (users.role_id) into the user_roles join table.
+// It is safe to run on every start: once the column has been dropped it does nothing.
+// Must run after AutoMigrate so that user_roles exists.
+func MigrateUserRoles(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&auth.User{}, "role_id") {
+		return nil
+	}
+
+	log.Println("Migrating users.role_id into user_roles...")
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO user_roles (user_id, role_id)
+			SELECT id, role_id FROM users WHERE role_id IS NOT NULL AND role_id <> 0
+			ON CONFLICT DO NOTHING`).Error; err != nil {
+			return fmt.Errorf("failed to copy role assignments into user_roles: %w", err)
+		}
+		if err := tx.Migrator().DropColumn(&auth.User{}, "role_id"); err != nil {
+			return fmt.Errorf("failed to drop legacy users.role_id column: %w", err)
+		}
+		log.Println("Legacy role assignments migrated to user_roles.")
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+// prometheus/backend/database/module.go
+package database
+
+import (
+	"context"
+	"prometheus/backend/internal/module"
+
+	"gorm.io/gorm"
+)
+
+// dbModule reports database connectivity and connection pool usage, of the reporting connection too when
+// it has its own.
+type dbModule struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+}
+
+// NewModule creates the database module for the module registry. reporting is the connection returned by
+// ConnectReportingDB.
+func NewModule(db, reporting *gorm.DB) module.Module {
+	return &dbModule{db: db, reporting: reporting}
+}
+
+func (m *dbModule) Name() string { return "database" }
+
+func (m *dbModule) HealthContributors() []module.HealthContributor {
+	contributors := []module.HealthContributor{poolCheck("postgres", m.db)}
+	if m.reporting != m.db {
+		contributors = append(contributors, poolCheck("postgres_reporting", m.reporting))
+	}
+	return contributors
+}
+
+// poolCheck pings a connection and reports its pool usage.
+func poolCheck(name string, db *gorm.DB) module.HealthContributor {
+	return module.NewHealthCheck(name, func(ctx context.Context) module.HealthResult {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+		}
+		stats := sqlDB.Stats()
+		return module.HealthResult{
+			Status: module.StatusUp,
+			Metrics: map[string]float64{
+				"open_connections": float64(stats.OpenConnections),
+				"in_use":           float64(stats.InUse),
+				"wait_count":       float64(stats.WaitCount),
+			},
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// prometheus/backend/database/reporting.go
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/config"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrReadOnly is returned for writes attempted through the reporting connection.
+var ErrReadOnly = errors.New("the reporting database connection is read-only")
+
+// ConnectReportingDB opens the connection analytics, report generation and report endpoints read through.
+// It logs in as REPORTING_DB_USER, which should only be granted SELECT, and every transaction on it is
+// read-only, so a bug in reporting can't change data. Without a reporting user it returns main: reporting
+// then shares the main connection.
+func ConnectReportingDB(cfg *config.Config, main *gorm.DB) (*gorm.DB, error) {
+	if cfg.ReportingDBUser == "" {
+		return main, nil
+	}
+	// Unknown DSN settings are sent to the server as run-time parameters.
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta default_transaction_read_only=on",
+		cfg.DBHost, cfg.ReportingDBUser, cfg.ReportingDBPassword, cfg.DBName, cfg.DBPort)
+	db, err := open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the reporting database: %w", err)
+	}
+	if err := refuseWrites(db); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generic database object: %w", err)
+	}
+	// Reports are few and slow; a small pool keeps them from starving the main one on the server.
+	sqlDB.SetMaxIdleConns(2)
+	sqlDB.SetMaxOpenConns(20)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+
+	var readOnly string
+	if err := db.Raw("SHOW transaction_read_only").Scan(&readOnly).Error; err != nil {
+		return nil, fmt.Errorf("failed to check the reporting connection: %w", err)
+	}
+	if readOnly != "on" {
+		return nil, fmt.Errorf("the reporting connection is not read-only (transaction_read_only = %q)", readOnly)
+	}
+	// A session may still turn read-only off; the grants are what really stop writes.
+	var writable int64
+	if err := db.Raw(`SELECT COUNT(*) FROM information_schema.table_privileges
+		WHERE grantee = current_user AND privilege_type IN ('INSERT', 'UPDATE', 'DELETE', 'TRUNCATE')`).Scan(&writable).Error; err != nil {
+		return nil, fmt.Errorf("failed to check the reporting user's privileges: %w", err)
+	}
+	if writable > 0 {
+		log.Printf("Warning: reporting database user %s may write to %d table(s); grant it SELECT only", cfg.ReportingDBUser, writable)
+	}
+	log.Printf("Reporting queries connect as %s, read-only.", cfg.ReportingDBUser)
+	return db, nil
+}
+
+// refuseWrites fails creates, updates and deletes through db before they reach the database, so misuse of
+// the reporting connection shows up as ErrReadOnly rather than a driver error.
+func refuseWrites(db *gorm.DB) error {
+	refuse := func(tx *gorm.DB) { _ = tx.AddError(ErrReadOnly) }
+	if err := db.Callback().Create().Before("gorm:create").Register("reporting:read_only", refuse); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("reporting:read_only", refuse); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("reporting:read_only", refuse)
+}
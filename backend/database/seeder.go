@@ -8,110 +8,130 @@ import (
 	"prometheus/backend/config"
 	"prometheus/backend/internal/auth" // For auth.User model and HashPassword
 	"prometheus/backend/internal/role" // For role.Role model
+	"prometheus/backend/internal/seed"
+	"slices"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-// SeedRoles creates predefined roles in the database if they don't already exist.
-func SeedRoles(db *gorm.DB) error {
-	rolesToSeed := []role.Role{
-		{Name: "staff", Description: "Regular employee with basic access."},
-		{Name: "manager", Description: "Managerial role with oversight of a team/department."},
-		{Name: "hr", Description: "Human Resources personnel with access to employee data and HR functions."},
-		{Name: "admin", Description: "System administrator with broad access, excluding god-level operations."},
-		{Name: "god-admin", Description: "Super administrator with unrestricted access to all system functionalities."},
-	}
-
-	log.Println("Seeding roles...")
-	var count int64
-	for _, r := range rolesToSeed {
-		// Check if role already exists
-		err := db.Model(&role.Role{}).Where("name = ?", r.Name).Count(&count).Error
-		if err != nil {
-			log.Printf("Error counting role %s: %v\n", r.Name, err)
-			continue // Skip to next role on error
-		}
-
-		if count == 0 {
-			// Role does not exist, create it
-			if err := db.Create(&r).Error; err != nil {
-				log.Printf("Error creating role %s: %v\n", r.Name, err)
-			} else {
-				log.Printf("Role '%s' seeded successfully with ID %d.\n", r.Name, r.ID)
-			}
-		} else {
-			log.Printf("Role '%s' already exists. Skipping.\n", r.Name)
-		}
-	}
-	log.Println("Role seeding process completed.")
-	return nil // Can be enhanced to return aggregated errors
+// Seeds returns the core seeds: the predefined roles, then the god admin holding the "god-admin" role.
+// They are applied after the core migrations, before any module's.
+func Seeds(cfg *config.Config) []seed.Seed {
+	return []seed.Seed{rolesSeed(), godAdminSeed(cfg)}
 }
 
-// SeedGodAdmin creates a god-level administrator user if one doesn't exist.
-// This function assumes roles have already been seeded, especially the "god-admin" role.
-func SeedGodAdmin(db *gorm.DB, cfg *config.Config) error {
-	log.Println("Attempting to seed God Admin user...")
-
-	// 1. Check if god admin email is configured
-	if cfg.GodAdminEmail == "" || cfg.GodAdminPassword == "" {
-		log.Println("GodAdminEmail or GodAdminPassword not configured in .env. Skipping God Admin seed.")
-		return nil
-	}
+// predefinedRoles are the roles every deployment starts with.
+var predefinedRoles = []role.Role{
+	{Name: "staff", Description: "Regular employee with basic access."},
+	{Name: "manager", Description: "Managerial role with oversight of a team/department."},
+	{Name: "hr", Description: "Human Resources personnel with access to employee data and HR functions."},
+	{Name: "finance", Description: "Finance personnel who reimburse approved expense claims."},
+	{Name: "admin", Description: "System administrator with broad access, excluding god-level operations."},
+	{Name: "god-admin", Description: "Super administrator with unrestricted access to all system functionalities."},
+}
 
-	// 2. Find the "god-admin" role
-	var godAdminRole role.Role
-	if err := db.Where("name = ?", "god-admin").First(&godAdminRole).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Error: 'god-admin' role not found. Ensure roles are seeded before seeding God Admin.")
-			return fmt.Errorf("'god-admin' role not found: %w", err)
-		}
-		log.Printf("Error fetching 'god-admin' role: %v\n", err)
-		return fmt.Errorf("error fetching 'god-admin' role: %w", err)
+// rolesSeed creates the predefined roles that don't exist yet. Roles renamed or edited since are left as
+// they are. Rolling it back deletes the predefined roles, as long as no one holds them.
+func rolesSeed() seed.Seed {
+	names := make([]string, len(predefinedRoles))
+	// Checksummed by name and description only, so new columns on roles don't read as a changed seed.
+	data := make(map[string]string, len(predefinedRoles))
+	for i, r := range predefinedRoles {
+		names[i] = r.Name
+		data[r.Name] = r.Description
 	}
-	log.Printf("'god-admin' role found with ID: %d\n", godAdminRole.ID)
-
-	// 3. Check if a user with the god admin email already exists
-	var existingUser auth.User
-	err := db.Model(&auth.User{}).Where("email = ?", cfg.GodAdminEmail).First(&existingUser).Error
-	if err == nil {
-		// User with this email already exists
-		log.Printf("User with email '%s' (ID: %d) already exists. Ensuring it has 'god-admin' role.", cfg.GodAdminEmail, existingUser.ID)
-		// Optionally, ensure this existing user has the god-admin role
-		if existingUser.RoleID != godAdminRole.ID {
-			log.Printf("Updating user %s (ID: %d) to 'god-admin' role (ID: %d)", existingUser.Username, existingUser.ID, godAdminRole.ID)
-			existingUser.RoleID = godAdminRole.ID
-			if err := db.Save(&existingUser).Error; err != nil {
-				log.Printf("Failed to update existing user %s to 'god-admin' role: %v", existingUser.Username, err)
-				return fmt.Errorf("failed to update existing user to 'god-admin': %w", err)
+	return seed.Seed{
+		Name:    "roles",
+		Version: 1,
+		Data:    data,
+		Up: func(tx *gorm.DB) error {
+			var existing []string
+			if err := tx.Model(&role.Role{}).Where("name IN ?", names).Pluck("name", &existing).Error; err != nil {
+				return fmt.Errorf("failed to load roles: %w", err)
+			}
+			for _, r := range predefinedRoles {
+				if slices.Contains(existing, r.Name) {
+					continue
+				}
+				r := r // Create sets the ID on its argument; keep predefinedRoles untouched.
+				if err := tx.Create(&r).Error; err != nil {
+					return fmt.Errorf("failed to create role %s: %w", r.Name, err)
+				}
+				log.Printf("Role '%s' seeded with ID %d.", r.Name, r.ID)
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			var held []string
+			if err := tx.Model(&role.Role{}).Where("name IN ?", names).
+				Where("id IN (?) OR id IN (?)", tx.Table("user_roles").Select("role_id"),
+					tx.Model(&auth.ScopedRole{}).Select("role_id")).
+				Order("name").Pluck("name", &held).Error; err != nil {
+				return fmt.Errorf("failed to check role holders: %w", err)
+			}
+			if len(held) > 0 {
+				return fmt.Errorf("roles still held by users: %s; revoke them first", strings.Join(held, ", "))
 			}
-		}
-		return nil // God admin (or user with that email) already exists
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// A different database error occurred
-		log.Printf("Database error when checking for existing god admin user: %v\n", err)
-		return fmt.Errorf("db error checking existing god admin: %w", err)
+			// Hard-deleted, so applying the seed again can create them under the same names.
+			if err := tx.Unscoped().Where("name IN ?", names).Delete(&role.Role{}).Error; err != nil {
+				return fmt.Errorf("failed to delete roles: %w", err)
+			}
+			return nil
+		},
 	}
+}
 
-	// 4. User does not exist, create the god admin user
-	hashedPassword, err := auth.HashPassword(cfg.GodAdminPassword)
-	if err != nil {
-		log.Printf("Error hashing god admin password: %v\n", err)
-		return fmt.Errorf("error hashing god admin password: %w", err)
-	}
+// godAdminSeed creates the god-level administrator configured by GOD_ADMIN_EMAIL and GOD_ADMIN_PASSWORD,
+// or grants the "god-admin" role to the user already having the email. It is skipped until both are set.
+// It can't be rolled back: the account may be in use by then, and is managed like any other.
+func godAdminSeed(cfg *config.Config) seed.Seed {
+	return seed.Seed{
+		Name:    "god-admin",
+		Version: 1,
+		// The email and password are deployment settings, not part of the checksum.
+		Data: map[string]string{"username": "godadmin", "role": "god-admin"},
+		Up: func(tx *gorm.DB) error {
+			if cfg.GodAdminEmail == "" || cfg.GodAdminPassword == "" {
+				return fmt.Errorf("%w: GOD_ADMIN_EMAIL or GOD_ADMIN_PASSWORD is not configured", seed.ErrSkip)
+			}
+			var godAdminRole role.Role
+			if err := tx.Where("name = ?", "god-admin").First(&godAdminRole).Error; err != nil {
+				return fmt.Errorf("failed to load the 'god-admin' role (seeded by the roles seed): %w", err)
+			}
 
-	godAdminUser := auth.User{
-		Username: "godadmin", // Or derive from email, or make configurable
-		Email:    cfg.GodAdminEmail,
-		Password: hashedPassword,
-		RoleID:   godAdminRole.ID,
-		IsActive: true,
-	}
+			var existingUser auth.User
+			err := tx.Where("email = ?", cfg.GodAdminEmail).Preload("Roles").First(&existingUser).Error
+			if err == nil {
+				if existingUser.HasRole(godAdminRole.Name) {
+					return nil
+				}
+				log.Printf("Granting 'god-admin' role to existing user %s (ID: %d).", existingUser.Username, existingUser.ID)
+				if err := tx.Model(&existingUser).Association("Roles").Append(&godAdminRole); err != nil {
+					return fmt.Errorf("failed to grant 'god-admin' to user %d: %w", existingUser.ID, err)
+				}
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to look up the god admin user: %w", err)
+			}
 
-	if err := db.Create(&godAdminUser).Error; err != nil {
-		log.Printf("Error creating god admin user: %v\n", err)
-		return fmt.Errorf("error creating god admin user: %w", err)
+			hashedPassword, err := auth.HashPassword(cfg.GodAdminPassword)
+			if err != nil {
+				return fmt.Errorf("failed to hash the god admin password: %w", err)
+			}
+			godAdminUser := auth.User{
+				Username: "godadmin",
+				Email:    cfg.GodAdminEmail,
+				Password: hashedPassword,
+				Roles:    []role.Role{godAdminRole},
+				IsActive: true,
+			}
+			if err := tx.Create(&godAdminUser).Error; err != nil {
+				return fmt.Errorf("failed to create the god admin user: %w", err)
+			}
+			log.Printf("God Admin user '%s' (Email: %s) seeded with ID %d.", godAdminUser.Username, godAdminUser.Email, godAdminUser.ID)
+			return nil
+		},
 	}
-
-	log.Printf("God Admin user '%s' (Email: %s) seeded successfully with ID %d and Role ID %d.\n", godAdminUser.Username, godAdminUser.Email, godAdminUser.ID, godAdminUser.RoleID)
-	return nil
 }
@@ -0,0 +1,334 @@
+// prometheus/backend/internal/agreement/handler.go
+package agreement
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for collective agreements.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListAgreements returns the organization's agreements.
+// @Summary List collective agreements
+// @Tags Agreements
+// @Produce json
+// @Success 200 {array} Agreement
+// @Router /hr/agreements [get]
+func (h *Handler) ListAgreements(c *gin.Context) {
+	agreements, err := h.service.Agreements(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Agreements fetched successfully", agreements)
+}
+
+// GetAgreement returns an agreement.
+// @Summary Get a collective agreement
+// @Tags Agreements
+// @Produce json
+// @Param id path int true "Agreement ID"
+// @Success 200 {object} Agreement
+// @Failure 404 {object} utils.ErrorResponse "Agreement not found"
+// @Router /hr/agreements/{id} [get]
+func (h *Handler) GetAgreement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	agreement, err := h.service.GetAgreement(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, agreement.UpdatedAt, agreement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Agreement fetched successfully", agreement)
+}
+
+// CreateAgreement defines an agreement.
+// @Summary Create a collective agreement
+// @Description Terms left out keep the organization's defaults for the employees it covers.
+// @Tags Agreements
+// @Accept json
+// @Produce json
+// @Param agreement body AgreementRequest true "Agreement"
+// @Success 201 {object} Agreement
+// @Failure 400 {object} utils.ErrorResponse "Invalid agreement"
+// @Failure 409 {object} utils.ErrorResponse "Code already in use"
+// @Router /hr/agreements [post]
+func (h *Handler) CreateAgreement(c *gin.Context) {
+	var req AgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	agreement, err := h.service.CreateAgreement(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Agreement created successfully", agreement)
+}
+
+// UpdateAgreement replaces an agreement's fields.
+// @Summary Update a collective agreement
+// @Description Requires If-Match (or If-Unmodified-Since) from a previous GET. The new terms apply from
+// @Description now on; closed payroll periods keep the rates they were closed with.
+// @Tags Agreements
+// @Accept json
+// @Produce json
+// @Param id path int true "Agreement ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param agreement body AgreementRequest true "Agreement"
+// @Success 200 {object} Agreement
+// @Failure 400 {object} utils.ErrorResponse "Invalid agreement"
+// @Failure 404 {object} utils.ErrorResponse "Agreement not found"
+// @Failure 409 {object} utils.ErrorResponse "Code already in use"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/agreements/{id} [put]
+func (h *Handler) UpdateAgreement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetAgreement(orgID, id)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	agreement, err := h.service.UpdateAgreement(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, agreement.UpdatedAt, agreement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Agreement updated successfully", agreement)
+}
+
+// DeleteAgreement removes an agreement; the employees it covered return to the defaults.
+// @Summary Delete a collective agreement
+// @Tags Agreements
+// @Param id path int true "Agreement ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Agreement not found"
+// @Router /hr/agreements/{id} [delete]
+func (h *Handler) DeleteAgreement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAgreement(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers returns the employees an agreement covers.
+// @Summary List the employees a collective agreement covers
+// @Tags Agreements
+// @Produce json
+// @Param id path int true "Agreement ID"
+// @Success 200 {array} Membership
+// @Failure 404 {object} utils.ErrorResponse "Agreement not found"
+// @Router /hr/agreements/{id}/members [get]
+func (h *Handler) ListMembers(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	members, err := h.service.Members(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Members fetched successfully", members)
+}
+
+// AddMembers tags employees with an agreement.
+// @Summary Tag employees with a collective agreement
+// @Description Employees covered by another agreement move to this one.
+// @Tags Agreements
+// @Accept json
+// @Produce json
+// @Param id path int true "Agreement ID"
+// @Param members body MembersRequest true "Employees"
+// @Success 200 {array} Membership
+// @Failure 400 {object} utils.ErrorResponse "Unknown employee"
+// @Failure 404 {object} utils.ErrorResponse "Agreement not found"
+// @Router /hr/agreements/{id}/members [post]
+func (h *Handler) AddMembers(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req MembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	members, err := h.service.Assign(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employees assigned successfully", members)
+}
+
+// RemoveMember returns an employee to the default terms.
+// @Summary Remove an employee from their collective agreement
+// @Tags Agreements
+// @Param id path int true "Employee ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Employee not covered by an agreement"
+// @Router /hr/employees/{id}/agreement [delete]
+func (h *Handler) RemoveMember(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Unassign(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDefaults returns the organization's default terms.
+// @Summary Get the default terms
+// @Tags Agreements
+// @Produce json
+// @Success 200 {object} Defaults
+// @Router /hr/agreements/defaults [get]
+func (h *Handler) GetDefaults(c *gin.Context) {
+	defaults, err := h.service.Defaults(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Default terms fetched successfully", defaults)
+}
+
+// PutDefaults replaces the organization's default terms.
+// @Summary Update the default terms
+// @Description The terms of employees no agreement covers, and those their agreement leaves unset.
+// @Description Overtime defaults to each payroll period's rate.
+// @Tags Agreements
+// @Accept json
+// @Produce json
+// @Param defaults body DefaultsRequest true "Default terms"
+// @Success 200 {object} Defaults
+// @Failure 400 {object} utils.ErrorResponse "Invalid terms"
+// @Router /hr/agreements/defaults [put]
+func (h *Handler) PutDefaults(c *gin.Context) {
+	var req DefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	defaults, err := h.service.SetDefaults(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Default terms updated successfully", defaults)
+}
+
+// EmployeeTerms returns the terms applying to an employee.
+// @Summary Get an employee's terms
+// @Tags Agreements
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param on query string false "Day (YYYY-MM-DD); today if omitted"
+// @Success 200 {object} Terms
+// @Failure 400 {object} utils.ErrorResponse "Invalid day"
+// @Router /hr/employees/{id}/terms [get]
+func (h *Handler) EmployeeTerms(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.sendTerms(c, id)
+}
+
+// MyTerms returns the terms applying to the caller.
+// @Summary Get own terms
+// @Tags Agreements
+// @Produce json
+// @Param on query string false "Day (YYYY-MM-DD); today if omitted"
+// @Success 200 {object} Terms
+// @Failure 400 {object} utils.ErrorResponse "Invalid day"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/terms [get]
+func (h *Handler) MyTerms(c *gin.Context) {
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	h.sendTerms(c, emp.ID)
+}
+
+// sendTerms responds with the employee's terms on the day of the on parameter, today by default.
+func (h *Handler) sendTerms(c *gin.Context, employeeID uint) {
+	now := clock.Now().UTC()
+	on := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if raw := c.Query("on"); raw != "" {
+		day, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid on parameter: expected YYYY-MM-DD")
+			return
+		}
+		on = day
+	}
+	terms, err := h.service.Terms(utils.OrganizationFromContext(c), []uint{employeeID}, on)
+	if err != nil {
+		sendAgreementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Terms fetched successfully", terms[employeeID])
+}
+
+// sendAgreementError maps service errors to HTTP status codes.
+func sendAgreementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidAgreement):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCodeTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+// prometheus/backend/internal/agreement/model.go
+package agreement
+
+import (
+	"time"
+)
+
+// Agreement is a union or collective agreement: a pack of terms overriding the organization's defaults for
+// the employees it covers. A nil term leaves the default in place.
+type Agreement struct {
+	ID              uint      `gorm:"primaryKey" json:"id" example:"2"`
+	OrganizationID  *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Code            string    `gorm:"type:varchar(30);not null" json:"code" example:"TV-L"` // Unique within the organization, case-insensitively
+	Name            string    `gorm:"type:varchar(150);not null" json:"name" example:"Public sector agreement"`
+	OvertimePercent *int      `json:"overtime_percent,omitempty" example:"125"`      // Of the regular rate; the payroll period's otherwise
+	NoticeDays      *int      `json:"notice_days,omitempty" example:"42"`            // Calendar days' notice either side gives
+	LeaveDays       *int      `json:"leave_days,omitempty" example:"30"`             // Annual leave entitlement, in working days
+	Members         int64     `gorm:"-" json:"members" example:"35"`                 // Employees it covers
+	Version         uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName says which kind of agreement.
+func (Agreement) TableName() string { return "collective_agreements" }
+
+// Membership tags an employee with the agreement covering them. An employee is covered by one agreement
+// at most.
+type Membership struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;uniqueIndex" json:"employee_id" example:"12"`
+	AgreementID    uint      `gorm:"not null;index" json:"agreement_id" example:"2"`
+	DisplayName    string    `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps memberships next to the agreements.
+func (Membership) TableName() string { return "employee_agreements" }
+
+// Defaults are the terms of employees no agreement covers, and of terms their agreement leaves unset.
+// Organizations that haven't set them use DefaultDefaults.
+// While a leave policy is in effect, its revision's entitlement replaces LeaveDays.
+type Defaults struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	NoticeDays     int       `gorm:"not null" json:"notice_days" example:"30"`
+	LeaveDays      int       `gorm:"not null" json:"leave_days" example:"20"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps the defaults next to the agreements.
+func (Defaults) TableName() string { return "agreement_defaults" }
+
+// DefaultDefaults give a month's notice and 20 days of leave, the statutory minimum in much of the EU.
+func DefaultDefaults(orgID *uint) Defaults {
+	return Defaults{OrganizationID: orgID, NoticeDays: 30, LeaveDays: 20}
+}
+
+// Terms are what applies to an employee on a day: their agreement's terms layered over the defaults, whose
+// leave entitlement comes from the leave policy in effect that day if there is one.
+type Terms struct {
+	EmployeeID            uint     `json:"employee_id" example:"12"`
+	AgreementID           *uint    `json:"agreement_id,omitempty" example:"2"`
+	Agreement             string   `json:"agreement,omitempty" example:"Public sector agreement"`
+	OvertimePercent       *int     `json:"overtime_percent,omitempty" example:"125"` // Nil: the payroll period's
+	NoticeDays            int      `json:"notice_days" example:"42"`
+	LeaveDays             int      `json:"leave_days" example:"30"`
+	LeavePolicyRevisionID *uint    `json:"leave_policy_revision_id,omitempty" example:"11"` // Revision the default leave days came from
+	Overridden            []string `json:"overridden" example:"notice_days,leave_days"`     // Terms set by the agreement
+}
+
+// AgreementRequest creates an agreement or replaces its fields. Omitted terms leave the defaults in place.
+type AgreementRequest struct {
+	Code            string `json:"code" binding:"required,max=30" example:"TV-L"`
+	Name            string `json:"name" binding:"required,max=150" example:"Public sector agreement"`
+	OvertimePercent *int   `json:"overtime_percent,omitempty" binding:"omitempty,min=100,max=500" example:"125"`
+	NoticeDays      *int   `json:"notice_days,omitempty" binding:"omitempty,min=0,max=365" example:"42"`
+	LeaveDays       *int   `json:"leave_days,omitempty" binding:"omitempty,min=0,max=366" example:"30"`
+}
+
+// MembersRequest tags employees with an agreement, moving them from any other.
+type MembersRequest struct {
+	EmployeeIDs []uint `json:"employee_ids" binding:"required,min=1,max=500" example:"12,14"`
+}
+
+// DefaultsRequest replaces the organization's default terms.
+type DefaultsRequest struct {
+	NoticeDays int `json:"notice_days" binding:"min=0,max=365" example:"30"`
+	LeaveDays  int `json:"leave_days" binding:"min=0,max=366" example:"20"`
+}
@@ -0,0 +1,47 @@
+// prometheus/backend/internal/agreement/module.go
+package agreement
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the agreements module.
+const ModuleName = "agreements"
+
+// agreementModule owns collective agreements and the default terms they override.
+type agreementModule struct {
+	handler *Handler
+}
+
+// NewModule creates the agreements module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &agreementModule{handler: NewHandler(svc)}
+}
+
+func (m *agreementModule) Name() string { return ModuleName }
+
+func (m *agreementModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *agreementModule) Models() []any {
+	return []any{&Agreement{}, &Membership{}, &Defaults{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Agreements are core: every plan has notice periods and
+// leave, whether or not it has payroll.
+func (m *agreementModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/terms", routing.Authenticated(), m.handler.MyTerms)
+
+	api.GET("/hr/agreements", routing.Policy(), m.handler.ListAgreements)
+	api.POST("/hr/agreements", routing.Policy(), m.handler.CreateAgreement)
+	api.GET("/hr/agreements/defaults", routing.Policy(), m.handler.GetDefaults)
+	api.PUT("/hr/agreements/defaults", routing.Policy(), m.handler.PutDefaults)
+	api.GET("/hr/agreements/:id", routing.Policy(), m.handler.GetAgreement)
+	api.PUT("/hr/agreements/:id", routing.Policy(), m.handler.UpdateAgreement)
+	api.DELETE("/hr/agreements/:id", routing.Policy(), m.handler.DeleteAgreement)
+	api.GET("/hr/agreements/:id/members", routing.Policy(), m.handler.ListMembers)
+	api.POST("/hr/agreements/:id/members", routing.Policy(), m.handler.AddMembers)
+	api.DELETE("/hr/employees/:id/agreement", routing.Policy(), m.handler.RemoveMember)
+	api.GET("/hr/employees/:id/terms", routing.Policy(), m.handler.EmployeeTerms)
+}
@@ -0,0 +1,430 @@
+// prometheus/backend/internal/agreement/service.go
+package agreement
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/policydoc"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidAgreement is returned for agreements and memberships that fail validation.
+	ErrInvalidAgreement = errors.New("invalid agreement")
+	// ErrCodeTaken is returned for an agreement code already in use.
+	ErrCodeTaken = errors.New("an agreement with this code already exists")
+)
+
+// Service manages collective agreements, which employees they cover and the default terms they override.
+// orgID scopes every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	Agreements(orgID *uint) ([]Agreement, error)
+	GetAgreement(orgID *uint, id uint) (*Agreement, error)
+	CreateAgreement(actor audit.Actor, orgID *uint, req AgreementRequest) (*Agreement, error)
+	UpdateAgreement(actor audit.Actor, orgID *uint, id, expectedVersion uint, req AgreementRequest) (*Agreement, error)
+	// DeleteAgreement removes the agreement; the employees it covered return to the defaults.
+	DeleteAgreement(actor audit.Actor, orgID *uint, id uint) error
+
+	Members(orgID *uint, id uint) ([]Membership, error)
+	// Assign tags employees with the agreement, moving them from any other.
+	Assign(actor audit.Actor, orgID *uint, id uint, req MembersRequest) ([]Membership, error)
+	// Unassign returns an employee to the defaults.
+	Unassign(actor audit.Actor, orgID *uint, employeeID uint) error
+
+	Defaults(orgID *uint) (*Defaults, error)
+	SetDefaults(actor audit.Actor, orgID *uint, req DefaultsRequest) (*Defaults, error)
+
+	// Terms returns the terms applying to each employee on a day, by employee ID. Other modules read notice
+	// periods, leave entitlements and overtime rates here, for the date of what they process.
+	Terms(orgID *uint, employeeIDs []uint, on time.Time) (map[uint]Terms, error)
+	employee.Resolver
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	policies  policydoc.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. The leave policy in effect on a day, if the organization
+// has one, sets the default leave entitlement in place of the stored defaults.
+func NewService(db *gorm.DB, employees employee.Service, policies policydoc.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, policies: policies, auditor: auditor}
+}
+
+func (s *service) Agreements(orgID *uint) ([]Agreement, error) {
+	agreements := []Agreement{}
+	if err := utils.OrgScope(s.db, orgID).Order("LOWER(code), id").Find(&agreements).Error; err != nil {
+		return nil, fmt.Errorf("failed to list agreements: %w", err)
+	}
+	if err := s.counted(orgID, agreements); err != nil {
+		return nil, err
+	}
+	return agreements, nil
+}
+
+func (s *service) GetAgreement(orgID *uint, id uint) (*Agreement, error) {
+	var agreement Agreement
+	if err := utils.OrgScope(s.db, orgID).First(&agreement, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	agreements := []Agreement{agreement}
+	if err := s.counted(orgID, agreements); err != nil {
+		return nil, err
+	}
+	return &agreements[0], nil
+}
+
+func (s *service) CreateAgreement(actor audit.Actor, orgID *uint, req AgreementRequest) (*Agreement, error) {
+	agreement := Agreement{OrganizationID: orgID}
+	if err := applyAgreement(&agreement, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCode(tx, orgID, 0, agreement.Code); err != nil {
+			return err
+		}
+		if err := tx.Create(&agreement).Error; err != nil {
+			return fmt.Errorf("failed to create agreement: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement.create", EntityType: "agreement", EntityID: fmt.Sprintf("%d", agreement.ID), After: agreement,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &agreement, nil
+}
+
+// UpdateAgreement replaces the agreement's fields if it is still at expectedVersion (optimistic locking).
+// The new terms apply to its employees from then on; payroll periods already closed keep theirs.
+func (s *service) UpdateAgreement(actor audit.Actor, orgID *uint, id, expectedVersion uint, req AgreementRequest) (*Agreement, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Agreement
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		agreement := before
+		if err := applyAgreement(&agreement, req); err != nil {
+			return err
+		}
+		if err := checkCode(tx, orgID, id, agreement.Code); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Agreement{}, id, expectedVersion, map[string]interface{}{
+			"code": agreement.Code, "name": agreement.Name, "overtime_percent": agreement.OvertimePercent,
+			"notice_days": agreement.NoticeDays, "leave_days": agreement.LeaveDays,
+		}); err != nil {
+			return err
+		}
+		agreement.Version = expectedVersion + 1
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement.update", EntityType: "agreement", EntityID: fmt.Sprintf("%d", id), Before: before, After: agreement,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAgreement(orgID, id)
+}
+
+func (s *service) DeleteAgreement(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Agreement
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		var members []uint
+		if err := tx.Model(&Membership{}).Where("agreement_id = ?", id).Pluck("employee_id", &members).Error; err != nil {
+			return fmt.Errorf("failed to list members: %w", err)
+		}
+		if err := tx.Where("agreement_id = ?", id).Delete(&Membership{}).Error; err != nil {
+			return fmt.Errorf("failed to remove members: %w", err)
+		}
+		if err := tx.Delete(&Agreement{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete agreement %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement.delete", EntityType: "agreement", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"agreement": before, "employee_ids": members},
+		})
+	})
+}
+
+func (s *service) Members(orgID *uint, id uint) ([]Membership, error) {
+	if _, err := s.GetAgreement(orgID, id); err != nil {
+		return nil, err
+	}
+	members := []Membership{}
+	if err := utils.OrgScope(s.db, orgID).Where("agreement_id = ?", id).Order("employee_id").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	if err := s.named(orgID, members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (s *service) Assign(actor audit.Actor, orgID *uint, id uint, req MembersRequest) ([]Membership, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Locked so a concurrent delete can't leave memberships to a removed agreement.
+		var agreement Agreement
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "SHARE"}), orgID).First(&agreement, id).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := utils.OrgScope(tx.Table("employees").Where("id IN ? AND deleted_at IS NULL", req.EmployeeIDs), orgID).
+			Distinct("id").Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check employees: %w", err)
+		}
+		if count != int64(len(uniq(req.EmployeeIDs))) {
+			return fmt.Errorf("%w: unknown employee", ErrInvalidAgreement)
+		}
+		var before []Membership
+		if err := tx.Where("employee_id IN ?", req.EmployeeIDs).Find(&before).Error; err != nil {
+			return fmt.Errorf("failed to load memberships: %w", err)
+		}
+		for _, employeeID := range uniq(req.EmployeeIDs) {
+			membership := Membership{OrganizationID: orgID, EmployeeID: employeeID, AgreementID: id}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "employee_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"agreement_id", "created_at"}),
+			}).Create(&membership).Error; err != nil {
+				return fmt.Errorf("failed to assign employee %d: %w", employeeID, err)
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement.assign", EntityType: "agreement", EntityID: fmt.Sprintf("%d", id),
+			Before: before, After: map[string]interface{}{"employee_ids": req.EmployeeIDs},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Members(orgID, id)
+}
+
+func (s *service) Unassign(actor audit.Actor, orgID *uint, employeeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Membership
+		if err := utils.OrgScope(tx, orgID).Where("employee_id = ?", employeeID).First(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Membership{}, before.ID).Error; err != nil {
+			return fmt.Errorf("failed to unassign employee %d: %w", employeeID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement.unassign", EntityType: "agreement", EntityID: fmt.Sprintf("%d", before.AgreementID), Before: before,
+		})
+	})
+}
+
+func (s *service) Defaults(orgID *uint) (*Defaults, error) {
+	return defaults(s.db, orgID)
+}
+
+func (s *service) SetDefaults(actor audit.Actor, orgID *uint, req DefaultsRequest) (*Defaults, error) {
+	var updated Defaults
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Organizations have one defaults row; the lock keeps two first saves from creating two.
+		if err := lock.Tx(tx, fmt.Sprintf("agreement-defaults:%s", orgKey(orgID))); err != nil {
+			return err
+		}
+		before, err := defaults(tx, orgID)
+		if err != nil {
+			return err
+		}
+		updated = *before
+		updated.NoticeDays, updated.LeaveDays = req.NoticeDays, req.LeaveDays
+		if err := tx.Save(&updated).Error; err != nil {
+			return fmt.Errorf("failed to save default terms: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "agreement_defaults.update", EntityType: "agreement_defaults", EntityID: orgKey(orgID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Terms(orgID *uint, employeeIDs []uint, on time.Time) (map[uint]Terms, error) {
+	terms := make(map[uint]Terms, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return terms, nil
+	}
+	base, err := defaults(s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policies.EffectiveTerms(orgID, policydoc.KindLeave, on)
+	if err != nil {
+		return nil, err
+	}
+	var policyID *uint
+	if policy != nil && policy.LeaveDays != nil {
+		base.LeaveDays, policyID = *policy.LeaveDays, &policy.ID
+	}
+	var memberships []Membership
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ?", employeeIDs).Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to load memberships: %w", err)
+	}
+	agreementIDs := make([]uint, 0, len(memberships))
+	for _, m := range memberships {
+		agreementIDs = append(agreementIDs, m.AgreementID)
+	}
+	var agreements []Agreement
+	if len(agreementIDs) > 0 {
+		if err := s.db.Where("id IN ?", uniq(agreementIDs)).Find(&agreements).Error; err != nil {
+			return nil, fmt.Errorf("failed to load agreements: %w", err)
+		}
+	}
+	byID := make(map[uint]Agreement, len(agreements))
+	for _, a := range agreements {
+		byID[a.ID] = a
+	}
+	covering := make(map[uint]Agreement, len(memberships))
+	for _, m := range memberships {
+		if a, ok := byID[m.AgreementID]; ok {
+			covering[m.EmployeeID] = a
+		}
+	}
+	for _, id := range employeeIDs {
+		t := Terms{
+			EmployeeID: id, NoticeDays: base.NoticeDays, LeaveDays: base.LeaveDays, LeavePolicyRevisionID: policyID,
+			Overridden: []string{},
+		}
+		if a, ok := covering[id]; ok {
+			agreementID := a.ID
+			t.AgreementID, t.Agreement = &agreementID, a.Name
+			if a.OvertimePercent != nil {
+				t.OvertimePercent = a.OvertimePercent
+				t.Overridden = append(t.Overridden, "overtime_percent")
+			}
+			if a.NoticeDays != nil {
+				t.NoticeDays = *a.NoticeDays
+				t.Overridden = append(t.Overridden, "notice_days")
+			}
+			if a.LeaveDays != nil {
+				t.LeaveDays = *a.LeaveDays
+				t.Overridden = append(t.Overridden, "leave_days")
+			}
+		}
+		terms[id] = t
+	}
+	return terms, nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+// counted fills in how many employees each agreement covers.
+func (s *service) counted(orgID *uint, agreements []Agreement) error {
+	if len(agreements) == 0 {
+		return nil
+	}
+	var rows []struct {
+		AgreementID uint
+		Members     int64
+	}
+	if err := utils.OrgScope(s.db.Model(&Membership{}), orgID).Select("agreement_id, COUNT(*) AS members").
+		Group("agreement_id").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to count members: %w", err)
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		counts[r.AgreementID] = r.Members
+	}
+	for i := range agreements {
+		agreements[i].Members = counts[agreements[i].ID]
+	}
+	return nil
+}
+
+// named fills in the display names of members.
+func (s *service) named(orgID *uint, members []Membership) error {
+	ids := make([]uint, len(members))
+	for i, m := range members {
+		ids[i] = m.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].DisplayName = names[members[i].EmployeeID].Text
+	}
+	return nil
+}
+
+func defaults(db *gorm.DB, orgID *uint) (*Defaults, error) {
+	var found []Defaults
+	if err := utils.OrgScope(db, orgID).Limit(1).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to load default terms: %w", err)
+	}
+	if len(found) == 0 {
+		d := DefaultDefaults(orgID)
+		return &d, nil
+	}
+	return &found[0], nil
+}
+
+// checkCode rejects an agreement code already used in the organization by another agreement. The lock
+// serializes concurrent creations.
+func checkCode(tx *gorm.DB, orgID *uint, id uint, code string) error {
+	if err := lock.Tx(tx, "agreement:"+strings.ToLower(code)); err != nil {
+		return err
+	}
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Agreement{}), orgID).Where("LOWER(code) = LOWER(?) AND id <> ?", code, id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check agreement codes: %w", err)
+	}
+	if count > 0 {
+		return ErrCodeTaken
+	}
+	return nil
+}
+
+func applyAgreement(agreement *Agreement, req AgreementRequest) error {
+	agreement.Code = strings.TrimSpace(req.Code)
+	agreement.Name = strings.TrimSpace(req.Name)
+	if agreement.Code == "" || agreement.Name == "" {
+		return fmt.Errorf("%w: a code and a name are required", ErrInvalidAgreement)
+	}
+	agreement.OvertimePercent, agreement.NoticeDays, agreement.LeaveDays = req.OvertimePercent, req.NoticeDays, req.LeaveDays
+	return nil
+}
+
+// uniq returns ids without duplicates, in first-seen order.
+func uniq(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// orgKey names an organization in lock keys and audit entries.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
@@ -0,0 +1,138 @@
+// prometheus/backend/internal/analytics/dataset.go
+package analytics
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Viewer is who runs a query; datasets use it for row-level filtering.
+type Viewer struct {
+	UserID         uint
+	OrganizationID *uint    // nil = default organization
+	Roles          []string // Global role names
+	// ScopedRoles maps role names to the divisions the viewer holds them for (division-scoped roles).
+	ScopedRoles map[string][]uint
+}
+
+// HasAny reports whether the viewer holds any of roles globally.
+func (v Viewer) HasAny(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(v.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field is a dimension or measure: a fixed SQL expression plus the joins it needs. Expressions are
+// written here, never taken from requests, which is what keeps the query API safe.
+type Field struct {
+	Expr        string
+	Description string
+	Joins       []string // Keys of Dataset.Joins
+}
+
+// Dataset is a fact table with the dimensions and measures the query API may use.
+type Dataset struct {
+	Name        string
+	Description string
+	Table       string
+	Tenant      string            // Column holding a row's organization; every query is filtered on it, whatever Scope does
+	Joins       map[string]string // Join clauses, applied once each when a selected field needs them
+	Dimensions  map[string]Field
+	Measures    map[string]Field
+	// Scope applies row-level filtering for the viewer, within the tenant. ok=false means the viewer may not
+	// query the dataset.
+	Scope func(q *gorm.DB, v Viewer) (scoped *gorm.DB, ok bool)
+}
+
+// DatasetInfo describes a dataset for clients building charts.
+type DatasetInfo struct {
+	Name        string            `json:"name" example:"headcount"`
+	Description string            `json:"description" example:"User accounts"`
+	Dimensions  map[string]string `json:"dimensions"` // Name -> description
+	Measures    map[string]string `json:"measures"`
+}
+
+// Info describes the dataset.
+func (d *Dataset) Info() DatasetInfo {
+	info := DatasetInfo{Name: d.Name, Description: d.Description, Dimensions: map[string]string{}, Measures: map[string]string{}}
+	for name, f := range d.Dimensions {
+		info.Dimensions[name] = f.Description
+	}
+	for name, f := range d.Measures {
+		info.Measures[name] = f.Description
+	}
+	return info
+}
+
+// datasets are the HR facts exposed to the query API.
+var datasets = map[string]*Dataset{
+	"headcount": {
+		Name:        "headcount",
+		Description: "User accounts of the organization",
+		Table:       "users",
+		Tenant:      "users.organization_id",
+		Joins: map[string]string{
+			"roles": "LEFT JOIN user_roles ON user_roles.user_id = users.id LEFT JOIN roles ON roles.id = user_roles.role_id",
+		},
+		Dimensions: map[string]Field{
+			"role":          {Expr: "roles.name", Description: "Global role (users with several roles count once per role)", Joins: []string{"roles"}},
+			"is_active":     {Expr: "users.is_active", Description: "Whether the account is active"},
+			"created_month": {Expr: "to_char(date_trunc('month', users.created_at), 'YYYY-MM')", Description: "Month the account was created"},
+		},
+		Measures: map[string]Field{
+			"users":           {Expr: "COUNT(DISTINCT users.id)", Description: "Number of users"},
+			"active_users":    {Expr: "COUNT(DISTINCT users.id) FILTER (WHERE users.is_active)", Description: "Number of active users"},
+			"recent_logins":   {Expr: "COUNT(DISTINCT users.id) FILTER (WHERE users.last_login >= NOW() - INTERVAL '30 days')", Description: "Users who logged in within 30 days"},
+			"never_logged_in": {Expr: "COUNT(DISTINCT users.id) FILTER (WHERE users.last_login IS NULL)", Description: "Users who never logged in"},
+		},
+		Scope: func(q *gorm.DB, v Viewer) (*gorm.DB, bool) {
+			q = q.Where("users.deleted_at IS NULL")
+			switch {
+			case v.HasAny("hr", "admin", "god-admin", "manager"):
+				return q, true
+			case len(v.ScopedRoles["manager"]) > 0:
+				// Division managers only see the people holding a role in their divisions.
+				return q.Where("users.id IN (?)", q.Session(&gorm.Session{NewDB: true}).Table("scoped_roles").
+					Select("user_id").Where("division_id IN ?", v.ScopedRoles["manager"])), true
+			default:
+				return nil, false
+			}
+		},
+	},
+	"role-requests": {
+		Name:        "role-requests",
+		Description: "Requests for elevated, scoped or time-limited roles",
+		Table:       "role_requests",
+		Tenant:      "users.organization_id", // Joined by Scope
+		Joins: map[string]string{
+			"roles": "JOIN roles ON roles.id = role_requests.role_id",
+		},
+		Dimensions: map[string]Field{
+			"status": {Expr: "role_requests.status", Description: "pending, approved or rejected"},
+			"role":   {Expr: "roles.name", Description: "Requested role", Joins: []string{"roles"}},
+			"month":  {Expr: "to_char(date_trunc('month', role_requests.created_at), 'YYYY-MM')", Description: "Month of the request"},
+		},
+		Measures: map[string]Field{
+			"requests":           {Expr: "COUNT(*)", Description: "Number of requests"},
+			"avg_decision_hours": {Expr: "ROUND(AVG(EXTRACT(EPOCH FROM role_requests.decided_at - role_requests.created_at)) / 3600, 1)", Description: "Average hours until a decision"},
+		},
+		Scope: func(q *gorm.DB, v Viewer) (*gorm.DB, bool) {
+			if !v.HasAny("admin", "god-admin") {
+				return nil, false
+			}
+			return q.Joins("JOIN users ON users.id = role_requests.user_id").Where("role_requests.deleted_at IS NULL"), true
+		},
+	},
+}
+
+// tenant limits a query on the dataset to the viewer's organization.
+func tenant(q *gorm.DB, d *Dataset, v Viewer) *gorm.DB {
+	if v.OrganizationID == nil {
+		return q.Where(d.Tenant + " IS NULL")
+	}
+	return q.Where(d.Tenant+" = ?", *v.OrganizationID)
+}
@@ -0,0 +1,71 @@
+// prometheus/backend/internal/analytics/guard.go
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Guardrails on the plan of a query, checked before it runs. Limits on the request itself are in service.go.
+const (
+	maxJoins = 4
+	// maxPlanCost is the planner's estimated total cost, in Postgres cost units, above which a query is
+	// refused. A sequential scan costs about one unit per page and a hundredth per row.
+	maxPlanCost = 500000
+)
+
+// queryCanceled is the SQLSTATE of statements cancelled by statement_timeout.
+const queryCanceled = "57014"
+
+// ErrQueryTooExpensive is returned for queries whose plan exceeds the guardrails, or that time out.
+var ErrQueryTooExpensive = errors.New("the analytics query is too expensive")
+
+var joinPattern = regexp.MustCompile(`(?i)\bJOIN\b`)
+
+// statement renders a query to SQL without running it, so the statement explained is the one executed.
+func statement(query *gorm.DB) (string, []any) {
+	stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]any{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+// guard checks a statement's joins and planned cost. It runs in the query's transaction, under the same
+// statement timeout.
+func guard(ctx context.Context, tx *gorm.DB, sql string, vars []any) error {
+	if joins := len(joinPattern.FindAllStringIndex(sql, -1)); joins > maxJoins {
+		return fmt.Errorf("%w: it needs %d joins, at most %d are allowed; select fewer fields", ErrQueryTooExpensive, joins, maxJoins)
+	}
+	var raw []byte
+	if err := tx.Statement.ConnPool.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+sql, vars...).Scan(&raw); err != nil {
+		return timedOut(fmt.Errorf("failed to plan analytics query: %w", err))
+	}
+	var plans []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		return fmt.Errorf("failed to read analytics query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return errors.New("failed to read analytics query plan: it is empty")
+	}
+	if cost := plans[0].Plan.TotalCost; cost > maxPlanCost {
+		return fmt.Errorf("%w: its estimated cost is %.0f, at most %d is allowed; add filters or select fewer dimensions", ErrQueryTooExpensive, cost, maxPlanCost)
+	}
+	return nil
+}
+
+// timedOut reports statements cancelled by the statement timeout as ErrQueryTooExpensive.
+func timedOut(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == queryCanceled {
+		return fmt.Errorf("%w: it ran longer than %s", ErrQueryTooExpensive, queryTimeout)
+	}
+	return err
+}
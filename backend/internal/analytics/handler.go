@@ -0,0 +1,85 @@
+// prometheus/backend/internal/analytics/handler.go
+package analytics
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for the analytics query API.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListDatasets returns the datasets the caller may query with their dimensions and measures.
+// @Summary List analytics datasets
+// @Tags Analytics
+// @Produce json
+// @Success 200 {array} DatasetInfo
+// @Router /analytics/datasets [get]
+func (h *Handler) ListDatasets(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Datasets fetched successfully", h.service.Datasets(viewerFromContext(c)))
+}
+
+// Query aggregates measures of a dataset grouped by dimensions.
+// @Summary Run an analytics query
+// @Description Rows are filtered to what the caller's roles may see. At most 3 dimensions, 5 measures and 5 filters;
+// @Description results are cached for 5 minutes. Queries are refused when their plan needs more than 4 joins or
+// @Description exceeds the cost budget, and cancelled after 5 seconds.
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Param query body Query true "Dataset, measures, dimensions and filters"
+// @Success 200 {object} Result
+// @Failure 400 {object} utils.ErrorResponse "Unknown dataset or field, or guardrail exceeded"
+// @Failure 403 {object} utils.ErrorResponse "Dataset not allowed for the caller's roles"
+// @Failure 422 {object} utils.ErrorResponse "Query too expensive"
+// @Router /analytics/query [post]
+func (h *Handler) Query(c *gin.Context) {
+	var q Query
+	if err := c.ShouldBindJSON(&q); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	result, err := h.service.Run(c.Request.Context(), viewerFromContext(c), q)
+	if err != nil {
+		sendAnalyticsError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Query executed successfully", result)
+}
+
+// viewerFromContext builds the viewer from the claims set by AuthMiddleware.
+func viewerFromContext(c *gin.Context) Viewer {
+	v := Viewer{UserID: c.GetUint("userID"), Roles: middleware.RolesFromContext(c), OrganizationID: utils.OrganizationFromContext(c)}
+	for _, sr := range middleware.ScopedRolesFromContext(c) {
+		if v.ScopedRoles == nil {
+			v.ScopedRoles = map[string][]uint{}
+		}
+		v.ScopedRoles[sr.Role] = append(v.ScopedRoles[sr.Role], sr.DivisionID)
+	}
+	return v
+}
+
+// sendAnalyticsError maps service errors to HTTP status codes.
+func sendAnalyticsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownDataset), errors.Is(err, ErrInvalidQuery):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDatasetNotAllowed):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrQueryTooExpensive):
+		utils.SendErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
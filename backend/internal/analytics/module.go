@@ -0,0 +1,33 @@
+// prometheus/backend/internal/analytics/module.go
+package analytics
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the analytics module.
+const ModuleName = "analytics"
+
+// analyticsModule exposes the analytics query API.
+type analyticsModule struct {
+	handler *Handler
+}
+
+// NewModule creates the analytics module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &analyticsModule{handler: NewHandler(svc)}
+}
+
+func (m *analyticsModule) Name() string { return ModuleName }
+
+func (m *analyticsModule) HealthContributors() []module.HealthContributor { return nil }
+
+// RegisterRoutes implements routing.Contributor. Any authenticated user may call the API; datasets
+// decide per role what, if anything, the caller sees.
+func (m *analyticsModule) RegisterRoutes(api *routing.Group) {
+	analyticsAPI := api.InModule(plan.ModuleReports)
+	analyticsAPI.GET("/analytics/datasets", routing.Authenticated(), m.handler.ListDatasets)
+	analyticsAPI.POST("/analytics/query", routing.Authenticated(), m.handler.Query)
+}
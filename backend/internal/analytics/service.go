@@ -0,0 +1,281 @@
+// prometheus/backend/internal/analytics/service.go
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/cache"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Guardrails keeping ad-hoc queries cheap. The plan of each query is checked too, see guard.go.
+const (
+	maxDimensions   = 3
+	maxMeasures     = 5
+	maxFilters      = 5
+	maxFilterValues = 50
+	defaultLimit    = 100
+	maxLimit        = 1000
+	queryTimeout    = 5 * time.Second
+	resultCacheTTL  = 5 * time.Minute
+)
+
+// ErrUnknownDataset is returned for datasets that don't exist.
+var ErrUnknownDataset = errors.New("unknown dataset")
+
+// ErrDatasetNotAllowed is returned when the viewer's roles don't allow the dataset.
+var ErrDatasetNotAllowed = errors.New("your roles do not allow querying this dataset")
+
+// ErrInvalidQuery is returned for queries using unknown fields or exceeding the guardrails.
+var ErrInvalidQuery = errors.New("invalid analytics query")
+
+// Filter restricts a dimension to one or more values.
+type Filter struct {
+	Dimension string   `json:"dimension" binding:"required" example:"is_active"`
+	Values    []string `json:"values" binding:"required,min=1" example:"true"`
+}
+
+// Sort orders the result by a selected dimension or measure.
+type Sort struct {
+	Field string `json:"field" binding:"required" example:"users"`
+	Desc  bool   `json:"desc" example:"true"`
+}
+
+// Query is a request for aggregated data: measures grouped by dimensions.
+type Query struct {
+	Dataset    string   `json:"dataset" binding:"required" example:"headcount"`
+	Measures   []string `json:"measures" binding:"required,min=1" example:"users"`
+	Dimensions []string `json:"dimensions" example:"role"`
+	Filters    []Filter `json:"filters"`
+	Sort       []Sort   `json:"sort"`
+	Limit      int      `json:"limit" example:"100"` // Defaults to 100, at most 1000
+}
+
+// Result holds the rows of a query, one value per column.
+type Result struct {
+	Columns   []string `json:"columns" example:"role,users"`
+	Rows      [][]any  `json:"rows" swaggertype:"array,object"`
+	Truncated bool     `json:"truncated"` // More rows matched than Limit
+	Cached    bool     `json:"cached"`
+}
+
+// Service answers analytics queries over the predefined datasets.
+type Service interface {
+	Datasets(v Viewer) []DatasetInfo
+	Run(ctx context.Context, v Viewer, q Query) (*Result, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db    *gorm.DB
+	cache cache.Cache
+}
+
+// NewService creates a new instance of Service. Queries run on db, the read-only reporting connection (see
+// database.ConnectReportingDB). Results are cached per viewer scope in the analytics namespace.
+func NewService(db *gorm.DB, c cache.Cache) Service {
+	return &service{db: db, cache: c}
+}
+
+// Datasets lists the datasets the viewer may query, by name.
+func (s *service) Datasets(v Viewer) []DatasetInfo {
+	infos := []DatasetInfo{}
+	for _, d := range datasets {
+		if _, ok := d.Scope(s.db, v); ok {
+			infos = append(infos, d.Info())
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Run validates a query against the dataset's fields and guardrails, applies the tenant and the viewer's
+// row-level filters, checks its plan and executes it with a statement timeout.
+func (s *service) Run(ctx context.Context, v Viewer, q Query) (*Result, error) {
+	d, ok := datasets[q.Dataset]
+	if !ok {
+		return nil, ErrUnknownDataset
+	}
+	if _, ok := d.Scope(s.db, v); !ok {
+		return nil, ErrDatasetNotAllowed
+	}
+	if err := validate(d, &q); err != nil {
+		return nil, err
+	}
+
+	key, err := cacheKey(v, q)
+	if err != nil {
+		return nil, err
+	}
+	var cached Result
+	if found, err := s.cache.Get(ctx, cache.NamespaceAnalytics, key, &cached); err == nil && found {
+		cached.Cached = true
+		return &cached, nil
+	}
+
+	var result *Result
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", queryTimeout.Milliseconds())).Error; err != nil {
+			return err
+		}
+		query, _ := d.Scope(tenant(tx.Table(d.Table), d, v), v)
+		result, err = execute(ctx, query, d, q)
+		return err
+	})
+	if errors.Is(err, ErrQueryTooExpensive) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run analytics query: %w", err)
+	}
+
+	// A failed cache write only costs a recomputation next time.
+	_ = s.cache.Set(ctx, cache.NamespaceAnalytics, key, result, resultCacheTTL)
+	return result, nil
+}
+
+// execute builds, guards and runs the aggregate query. Every expression comes from the dataset definition;
+// request values only ever reach the database as bound parameters.
+func execute(ctx context.Context, query *gorm.DB, d *Dataset, q Query) (*Result, error) {
+	joins := []string{}
+	addJoins := func(f Field) {
+		for _, j := range f.Joins {
+			if !slices.Contains(joins, j) {
+				joins = append(joins, j)
+			}
+		}
+	}
+
+	selects := make([]string, 0, len(q.Dimensions)+len(q.Measures))
+	groups := make([]string, 0, len(q.Dimensions))
+	for _, name := range q.Dimensions {
+		f := d.Dimensions[name]
+		addJoins(f)
+		selects = append(selects, fmt.Sprintf("%s AS %q", f.Expr, name))
+		groups = append(groups, f.Expr)
+	}
+	for _, name := range q.Measures {
+		f := d.Measures[name]
+		addJoins(f)
+		selects = append(selects, fmt.Sprintf("%s AS %q", f.Expr, name))
+	}
+	for _, filter := range q.Filters {
+		f := d.Dimensions[filter.Dimension]
+		addJoins(f)
+		// Compare as text so every dimension accepts string values.
+		query = query.Where(fmt.Sprintf("(%s)::text IN ?", f.Expr), filter.Values)
+	}
+	for _, j := range joins {
+		query = query.Joins(d.Joins[j])
+	}
+
+	query = query.Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", "))
+	}
+	if len(q.Sort) > 0 {
+		columns := make([]clause.OrderByColumn, 0, len(q.Sort))
+		for _, srt := range q.Sort {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: srt.Field}, Desc: srt.Desc})
+		}
+		query = query.Order(clause.OrderBy{Columns: columns})
+	}
+
+	sql, vars := statement(query.Limit(q.Limit + 1))
+	if err := guard(ctx, query, sql, vars); err != nil {
+		return nil, err
+	}
+	rows, err := query.Statement.ConnPool.QueryContext(ctx, sql, vars...)
+	if err != nil {
+		return nil, timedOut(err)
+	}
+	defer rows.Close()
+
+	columns := append(append([]string{}, q.Dimensions...), q.Measures...)
+	result := &Result{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			// Postgres returns numerics and text as []byte through the generic scanner.
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		if len(result.Rows) == q.Limit {
+			result.Truncated = true
+			break
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, timedOut(rows.Err())
+}
+
+// validate checks the query against the dataset and guardrails, applying defaults.
+func validate(d *Dataset, q *Query) error {
+	if len(q.Dimensions) > maxDimensions || len(q.Measures) > maxMeasures || len(q.Filters) > maxFilters {
+		return fmt.Errorf("%w: at most %d dimensions, %d measures and %d filters", ErrInvalidQuery, maxDimensions, maxMeasures, maxFilters)
+	}
+	selected := map[string]bool{}
+	for _, name := range q.Dimensions {
+		if _, ok := d.Dimensions[name]; !ok || selected[name] {
+			return fmt.Errorf("%w: unknown or repeated dimension %q", ErrInvalidQuery, name)
+		}
+		selected[name] = true
+	}
+	for _, name := range q.Measures {
+		if _, ok := d.Measures[name]; !ok || selected[name] {
+			return fmt.Errorf("%w: unknown or repeated measure %q", ErrInvalidQuery, name)
+		}
+		selected[name] = true
+	}
+	for _, f := range q.Filters {
+		if _, ok := d.Dimensions[f.Dimension]; !ok {
+			return fmt.Errorf("%w: unknown filter dimension %q", ErrInvalidQuery, f.Dimension)
+		}
+		if len(f.Values) > maxFilterValues {
+			return fmt.Errorf("%w: at most %d values per filter", ErrInvalidQuery, maxFilterValues)
+		}
+	}
+	for _, srt := range q.Sort {
+		if !selected[srt.Field] {
+			return fmt.Errorf("%w: can only sort by a selected dimension or measure, not %q", ErrInvalidQuery, srt.Field)
+		}
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultLimit
+	case q.Limit < 0 || q.Limit > maxLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, maxLimit)
+	}
+	return nil
+}
+
+// cacheKey identifies a query together with everything the viewer's row-level filter depends on.
+func cacheKey(v Viewer, q Query) (string, error) {
+	raw, err := json.Marshal(struct {
+		Viewer Viewer
+		Query  Query
+	}{v, q})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode analytics query: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return q.Dataset + ":" + hex.EncodeToString(sum[:]), nil
+}
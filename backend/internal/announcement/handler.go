@@ -0,0 +1,371 @@
+// prometheus/backend/internal/announcement/handler.go
+package announcement
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// reviewerRoles publish without review and may review others' submissions, when held globally.
+var reviewerRoles = []string{"god-admin", "admin", "hr"}
+
+// leadRole makes its holders division leads for the divisions it is scoped to.
+const leadRole = "manager"
+
+// Handler handles HTTP requests for announcements and their review.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Feed lists the published announcements addressed to the caller.
+// @Summary List announcements
+// @Description Published announcements for the whole organization or the caller's division, newest first.
+// @Tags Announcements
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /announcements [get]
+func (h *Handler) Feed(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	announcements, total, err := h.service.Feed(utils.OrganizationFromContext(c), c.GetUint("userID"), page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Announcements fetched successfully", page.Response(announcements, total))
+}
+
+// Read returns a published announcement addressed to the caller.
+// @Summary Get an announcement of my feed
+// @Tags Announcements
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Success 200 {object} Announcement
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found or not addressed to the caller"
+// @Router /announcements/{id} [get]
+func (h *Handler) Read(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	announcement, err := h.service.Read(utils.OrganizationFromContext(c), c.GetUint("userID"), id)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Announcement fetched successfully", announcement)
+}
+
+// Mine lists the caller's announcements in any status.
+// @Summary List own announcements
+// @Tags Announcements
+// @Produce json
+// @Param status query string false "Status" Enums(draft, pending_review, published, rejected)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Router /manager/announcements [get]
+func (h *Handler) Mine(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	userID := c.GetUint("userID")
+	filter.AuthorID = &userID
+	h.sendList(c, filter)
+}
+
+// List returns the organization's announcements in any status.
+// @Summary List all announcements
+// @Tags Announcements
+// @Produce json
+// @Param status query string false "Status" Enums(draft, pending_review, published, rejected)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Router /hr/announcements [get]
+func (h *Handler) List(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	h.sendList(c, filter)
+}
+
+func (h *Handler) sendList(c *gin.Context, filter Filter) {
+	page := utils.ParsePagination(c)
+	announcements, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Announcements fetched successfully", page.Response(announcements, total))
+}
+
+// ReviewQueue lists the announcements waiting for HR review, oldest submission first.
+// @Summary List announcements pending review
+// @Tags Announcements
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /hr/announcements/review-queue [get]
+func (h *Handler) ReviewQueue(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	announcements, total, err := h.service.ReviewQueue(utils.OrganizationFromContext(c), page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Review queue fetched successfully", page.Response(announcements, total))
+}
+
+// Get returns one of the caller's announcements; HR may get any. The ETag and Last-Modified headers can be
+// sent back as If-Match / If-Unmodified-Since.
+// @Summary Get an announcement
+// @Tags Announcements
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Success 200 {object} Announcement
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Router /manager/announcements/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	announcement, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err == nil && announcement.AuthorID != c.GetUint("userID") && !author(c).Reviewer {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, announcement.UpdatedAt, announcement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Announcement fetched successfully", announcement)
+}
+
+// Create drafts an announcement.
+// @Summary Draft an announcement
+// @Description Division leads write on behalf of a division they lead (division_id) to any audience of
+// @Description divisions, or the whole organization if division_ids is empty. Nothing is published until
+// @Description the draft is submitted.
+// @Tags Announcements
+// @Accept json
+// @Produce json
+// @Param announcement body Request true "Announcement"
+// @Success 201 {object} Announcement
+// @Failure 400 {object} utils.ErrorResponse "Invalid announcement or unknown division"
+// @Failure 403 {object} utils.ErrorResponse "Division not led by the caller"
+// @Router /manager/announcements [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	announcement, err := h.service.Create(audit.ActorFromContext(c), author(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, announcement.UpdatedAt, announcement.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Announcement created successfully", announcement)
+}
+
+// Update replaces the content of the caller's draft or rejected announcement, which becomes a draft again.
+// @Summary Update an announcement
+// @Tags Announcements
+// @Accept json
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param announcement body Request true "Announcement"
+// @Success 200 {object} Announcement
+// @Failure 400 {object} utils.ErrorResponse "Invalid announcement or unknown division"
+// @Failure 403 {object} utils.ErrorResponse "Not the author, or division not led by the caller"
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Failure 409 {object} utils.ErrorResponse "Pending review or published"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /manager/announcements/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	announcement, err := h.service.Update(audit.ActorFromContext(c), author(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, announcement.UpdatedAt, announcement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Announcement updated successfully", announcement)
+}
+
+// Delete removes the caller's unpublished announcement; HR may remove any announcement.
+// @Summary Delete an announcement
+// @Tags Announcements
+// @Param id path int true "Announcement ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} utils.ErrorResponse "Not the author"
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Failure 409 {object} utils.ErrorResponse "Published"
+// @Router /manager/announcements/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), author(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Submit publishes the caller's announcement, or queues it for HR review if its audience reaches beyond
+// the divisions the caller leads.
+// @Summary Submit an announcement
+// @Description Announcements for divisions the author leads are published at once. Anything reaching
+// @Description further, including the whole organization, waits in the HR review queue.
+// @Tags Announcements
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Success 200 {object} Announcement "Published or pending_review"
+// @Failure 403 {object} utils.ErrorResponse "Not the author"
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Failure 409 {object} utils.ErrorResponse "Already pending review or published"
+// @Router /manager/announcements/{id}/submit [post]
+func (h *Handler) Submit(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	announcement, err := h.service.Submit(audit.ActorFromContext(c), author(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	message := "Announcement published successfully"
+	if announcement.Status == StatusPending {
+		message = "Announcement submitted for review"
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, message, announcement)
+}
+
+// Approve publishes a pending announcement.
+// @Summary Approve an announcement
+// @Tags Announcements
+// @Accept json
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Param review body ReviewRequest false "Optional note to the author"
+// @Success 200 {object} Announcement
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Failure 409 {object} utils.ErrorResponse "Not pending review"
+// @Router /hr/announcements/{id}/approve [post]
+func (h *Handler) Approve(c *gin.Context) {
+	h.review(c, true, "Announcement approved and published")
+}
+
+// Reject sends a pending announcement back to its author.
+// @Summary Reject an announcement
+// @Tags Announcements
+// @Accept json
+// @Produce json
+// @Param id path int true "Announcement ID"
+// @Param review body ReviewRequest false "Optional note to the author"
+// @Success 200 {object} Announcement
+// @Failure 404 {object} utils.ErrorResponse "Announcement not found"
+// @Failure 409 {object} utils.ErrorResponse "Not pending review"
+// @Router /hr/announcements/{id}/reject [post]
+func (h *Handler) Reject(c *gin.Context) {
+	h.review(c, false, "Announcement rejected")
+}
+
+func (h *Handler) review(c *gin.Context, approve bool, message string) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ReviewRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	announcement, err := h.service.Review(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, approve, req.Note)
+	if err != nil {
+		sendAnnouncementError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, message, announcement)
+}
+
+// author describes the caller for moderation: reviewers hold an HR role globally, leads the lead role.
+func author(c *gin.Context) Author {
+	roles := middleware.RolesFromContext(c)
+	reviewer := slices.ContainsFunc(reviewerRoles, func(role string) bool { return slices.Contains(roles, role) })
+	leadsAll, leads := middleware.DivisionScope(c, leadRole)
+	return Author{UserID: c.GetUint("userID"), Reviewer: reviewer, LeadsAll: leadsAll, Leads: leads}
+}
+
+func parseFilter(c *gin.Context) (Filter, bool) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusDraft, StatusPending, StatusPublished, StatusRejected:
+		return filter, true
+	}
+	utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	return Filter{}, false
+}
+
+// sendAnnouncementError maps service errors to HTTP status codes.
+func sendAnnouncementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Announcement not found")
+	case errors.Is(err, ErrInvalidAnnouncement):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotLead), errors.Is(err, ErrNotAuthor):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrNotEditable), errors.Is(err, ErrNotPending):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The announcement was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,66 @@
+// prometheus/backend/internal/announcement/model.go
+package announcement
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Status is where an announcement is in the moderation workflow.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"          // Being written; only the author sees it
+	StatusPending   Status = "pending_review" // Submitted for an audience beyond the author's divisions; waits for HR
+	StatusPublished Status = "published"      // Visible to its audience
+	StatusRejected  Status = "rejected"       // Sent back by HR; the author may edit and resubmit it
+)
+
+// Announcement is news for the organization or some of its divisions. Division leads publish to their own
+// divisions directly; anything reaching further, including the whole organization, is reviewed by HR first.
+type Announcement struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"7"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	DivisionID     *uint          `gorm:"index" json:"division_id,omitempty" example:"2"` // Division it comes from
+	AuthorID       uint           `gorm:"not null;index" json:"author_id" example:"12"`   // User ID
+	Title          string         `gorm:"type:varchar(200);not null" json:"title" example:"Team offsite in May"`
+	Body           string         `gorm:"type:text;not null" json:"body"`
+	DivisionIDs    datatypes.JSON `gorm:"type:jsonb;not null" json:"division_ids" swaggertype:"array,integer" example:"2,5"` // Audience; empty is the whole organization
+	Status         Status         `gorm:"type:varchar(20);not null;index" json:"status" example:"draft"`
+	SubmittedAt    *time.Time     `json:"submitted_at,omitempty"`
+	ReviewerID     *uint          `json:"reviewer_id,omitempty" example:"3"`
+	ReviewNote     string         `gorm:"type:varchar(1000)" json:"review_note,omitempty"`
+	ReviewedAt     *time.Time     `json:"reviewed_at,omitempty"`
+	PublishedAt    *time.Time     `gorm:"index" json:"published_at,omitempty"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Request drafts an announcement or replaces a draft's content.
+type Request struct {
+	Title       string `json:"title" binding:"required,max=200" example:"Team offsite in May"`
+	Body        string `json:"body" binding:"required,max=20000"`
+	DivisionID  *uint  `json:"division_id,omitempty" example:"2"`                                        // Required unless the author is HR
+	DivisionIDs []uint `json:"division_ids" binding:"max=100" swaggertype:"array,integer" example:"2,5"` // Empty = whole organization
+}
+
+// ReviewRequest approves or rejects a submitted announcement.
+type ReviewRequest struct {
+	Note string `json:"note,omitempty" binding:"max=1000" example:"Please move the date to the body"`
+}
+
+// Author is who acts on announcements, as far as moderation is concerned.
+type Author struct {
+	UserID   uint
+	Reviewer bool   // HR: publishes anywhere without review, and reviews others' submissions
+	LeadsAll bool   // Holds a lead role globally: may publish to any divisions, but not organization-wide
+	Leads    []uint // Divisions the author leads through a division-scoped role; headed divisions are added by the service
+}
+
+// Filter narrows an announcement listing.
+type Filter struct {
+	Status   Status
+	AuthorID *uint
+}
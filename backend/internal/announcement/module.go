@@ -0,0 +1,52 @@
+// prometheus/backend/internal/announcement/module.go
+package announcement
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/internal/search"
+)
+
+// ModuleName is the name of the announcements module.
+const ModuleName = "announcements"
+
+// announcementModule owns announcements and their moderation.
+type announcementModule struct {
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the announcements module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &announcementModule{service: svc, handler: NewHandler(svc)}
+}
+
+func (m *announcementModule) Name() string { return ModuleName }
+
+func (m *announcementModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *announcementModule) Models() []any {
+	return []any{&Announcement{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Division leads write under /manager, HR reviews under /hr.
+func (m *announcementModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/announcements", routing.Authenticated(), m.handler.Feed)
+	api.GET("/announcements/:id", routing.Authenticated(), m.handler.Read)
+	api.GET("/manager/announcements", routing.Policy(), m.handler.Mine)
+	api.POST("/manager/announcements", routing.Policy(), m.handler.Create)
+	api.GET("/manager/announcements/:id", routing.Policy(), m.handler.Get)
+	api.PUT("/manager/announcements/:id", routing.Policy(), m.handler.Update)
+	api.DELETE("/manager/announcements/:id", routing.Policy(), m.handler.Delete)
+	api.POST("/manager/announcements/:id/submit", routing.Policy(), m.handler.Submit)
+	api.GET("/hr/announcements", routing.Policy(), m.handler.List)
+	api.GET("/hr/announcements/review-queue", routing.Policy(), m.handler.ReviewQueue)
+	api.POST("/hr/announcements/:id/approve", routing.Policy(), m.handler.Approve)
+	api.POST("/hr/announcements/:id/reject", routing.Policy(), m.handler.Reject)
+}
+
+// SearchProviders implements search.Contributor: announcements are found in the feed they were published to.
+func (m *announcementModule) SearchProviders() []search.Provider {
+	return []search.Provider{search.NewProvider(SearchType, "", m.service.Search)}
+}
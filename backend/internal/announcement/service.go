@@ -0,0 +1,473 @@
+// prometheus/backend/internal/announcement/service.go
+package announcement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reviewerRole is the role whose holders are asked to review submissions.
+const reviewerRole = "hr"
+
+// SearchType is the type of announcement search hits.
+const SearchType = "announcement"
+
+var (
+	// ErrInvalidAnnouncement is returned for announcements that fail validation.
+	ErrInvalidAnnouncement = errors.New("invalid announcement")
+	// ErrNotLead is returned when a division lead writes on behalf of a division they don't lead.
+	ErrNotLead = errors.New("you do not lead this division")
+	// ErrNotAuthor is returned when changing someone else's announcement.
+	ErrNotAuthor = errors.New("only the author may change this announcement")
+	// ErrNotEditable is returned when editing or submitting an announcement that is pending or published.
+	ErrNotEditable = errors.New("the announcement is pending review or published")
+	// ErrNotPending is returned when reviewing an announcement that isn't waiting for review.
+	ErrNotPending = errors.New("the announcement is not pending review")
+)
+
+// Service manages announcements and their moderation.
+// orgID scopes every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	// Feed lists the published announcements addressed to the user, newest first.
+	Feed(orgID *uint, userID uint, page utils.Pagination) ([]Announcement, int64, error)
+	// Read returns a published announcement addressed to the user.
+	Read(orgID *uint, userID, id uint) (*Announcement, error)
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Announcement, int64, error)
+	// ReviewQueue lists the announcements waiting for HR, oldest submission first.
+	ReviewQueue(orgID *uint, page utils.Pagination) ([]Announcement, int64, error)
+	Get(orgID *uint, id uint) (*Announcement, error)
+	Create(actor audit.Actor, author Author, orgID *uint, req Request) (*Announcement, error)
+	// Update replaces a draft or rejected announcement of the author, which becomes a draft again.
+	Update(actor audit.Actor, author Author, orgID *uint, id, expectedVersion uint, req Request) (*Announcement, error)
+	// Delete removes an unpublished announcement of the author; reviewers may remove any announcement.
+	Delete(actor audit.Actor, author Author, orgID *uint, id uint) error
+	// Submit publishes the author's announcement if its audience is within the divisions they lead, and
+	// queues it for HR review otherwise.
+	Submit(actor audit.Actor, author Author, orgID *uint, id uint) (*Announcement, error)
+	// Review publishes (approve) or rejects a pending announcement, and tells its author.
+	Review(actor audit.Actor, orgID *uint, id uint, approve bool, note string) (*Announcement, error)
+	// Search finds announcements for the organization-wide search, see search.Provider.
+	Search(ctx context.Context, q search.Query) ([]search.Hit, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+func (s *service) Feed(orgID *uint, userID uint, page utils.Pagination) ([]Announcement, int64, error) {
+	query, err := s.addressedTo(s.db, orgID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.page(query, "published_at DESC, id DESC", page)
+}
+
+func (s *service) Read(orgID *uint, userID, id uint) (*Announcement, error) {
+	query, err := s.addressedTo(s.db, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var announcement Announcement
+	if err := query.First(&announcement, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &announcement, nil
+}
+
+// Search finds the announcements of the user's feed by title and body for the organization-wide search.
+func (s *service) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	query, err := s.addressedTo(s.db.WithContext(ctx), q.OrgID, q.Viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	match, args := q.Condition("title", "body")
+	var announcements []Announcement
+	if err := query.Where(match, args...).Order("published_at DESC, id DESC").Limit(q.Limit).Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to search announcements: %w", err)
+	}
+	hits := make([]search.Hit, 0, len(announcements))
+	for _, a := range announcements {
+		hits = append(hits, search.Hit{
+			Type: SearchType, ID: a.ID, Title: a.Title, Snippet: search.Snippet(a.Body, q.Term, 160),
+			Link: fmt.Sprintf("/api/v1/announcements/%d", a.ID),
+		})
+	}
+	return hits, nil
+}
+
+// addressedTo selects the published announcements addressed to the user: those to the whole organization
+// and to the division of their employee record.
+func (s *service) addressedTo(db *gorm.DB, orgID *uint, userID uint) (*gorm.DB, error) {
+	var divisionIDs []uint
+	if err := db.Table("employees").Where("user_id = ? AND division_id IS NOT NULL AND deleted_at IS NULL", userID).
+		Pluck("division_id", &divisionIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the user's division: %w", err)
+	}
+	query := utils.OrgScope(db.Model(&Announcement{}), orgID).Where("status = ?", StatusPublished)
+	if len(divisionIDs) == 0 {
+		return query.Where("division_ids = '[]'::jsonb"), nil
+	}
+	audience, _ := json.Marshal(divisionIDs[:1])
+	return query.Where("division_ids = '[]'::jsonb OR division_ids @> ?::jsonb", string(audience)), nil
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Announcement, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Announcement{}), orgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.AuthorID != nil {
+		query = query.Where("author_id = ?", *filter.AuthorID)
+	}
+	return s.page(query, "created_at DESC, id DESC", page)
+}
+
+func (s *service) ReviewQueue(orgID *uint, page utils.Pagination) ([]Announcement, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Announcement{}), orgID).Where("status = ?", StatusPending)
+	return s.page(query, "submitted_at, id", page)
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Announcement, error) {
+	var announcement Announcement
+	if err := utils.OrgScope(s.db, orgID).First(&announcement, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &announcement, nil
+}
+
+func (s *service) Create(actor audit.Actor, author Author, orgID *uint, req Request) (*Announcement, error) {
+	announcement := Announcement{OrganizationID: orgID, AuthorID: author.UserID, Status: StatusDraft}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, author, &announcement, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&announcement).Error; err != nil {
+			return fmt.Errorf("failed to create announcement: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "announcement.create", EntityType: "announcement", EntityID: fmt.Sprintf("%d", announcement.ID), After: announcement,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// Update replaces the announcement's content if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, author Author, orgID *uint, id, expectedVersion uint, req Request) (*Announcement, error) {
+	var updated Announcement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.AuthorID != author.UserID {
+			return ErrNotAuthor
+		}
+		if before.Status != StatusDraft && before.Status != StatusRejected {
+			return ErrNotEditable
+		}
+		announcement := *before
+		if err := s.apply(tx, author, &announcement, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Announcement{}, id, expectedVersion, map[string]interface{}{
+			"title":        announcement.Title,
+			"body":         announcement.Body,
+			"division_id":  announcement.DivisionID,
+			"division_ids": announcement.DivisionIDs,
+			"status":       StatusDraft,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload announcement %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "announcement.update", EntityType: "announcement", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Delete(actor audit.Actor, author Author, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if !author.Reviewer {
+			if before.AuthorID != author.UserID {
+				return ErrNotAuthor
+			}
+			if before.Status == StatusPublished {
+				return ErrNotEditable
+			}
+		}
+		if err := tx.Delete(&Announcement{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete announcement %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "announcement.delete", EntityType: "announcement", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Submit(actor audit.Actor, author Author, orgID *uint, id uint) (*Announcement, error) {
+	var updated Announcement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.AuthorID != author.UserID {
+			return ErrNotAuthor
+		}
+		if before.Status != StatusDraft && before.Status != StatusRejected {
+			return ErrNotEditable
+		}
+		var audience []uint
+		if err := json.Unmarshal(before.DivisionIDs, &audience); err != nil {
+			return fmt.Errorf("failed to decode audience: %w", err)
+		}
+		review, err := s.needsReview(tx, author, audience)
+		if err != nil {
+			return err
+		}
+		now := clock.Now().UTC()
+		updates := map[string]interface{}{
+			"status": StatusPublished, "submitted_at": now, "published_at": now,
+			"reviewer_id": nil, "review_note": "", "reviewed_at": nil, "version": gorm.Expr("version + 1"),
+		}
+		if review {
+			updates["status"], updates["published_at"] = StatusPending, nil
+		}
+		if err := tx.Model(&Announcement{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to submit announcement %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload announcement %d: %w", id, err)
+		}
+		if review {
+			if err := s.notifyReviewers(tx, actor, &updated); err != nil {
+				return err
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "announcement.submit", EntityType: "announcement", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Review(actor audit.Actor, orgID *uint, id uint, approve bool, note string) (*Announcement, error) {
+	var updated Announcement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusPending {
+			return ErrNotPending
+		}
+		now := clock.Now().UTC()
+		updates := map[string]interface{}{
+			"status": StatusRejected, "reviewer_id": actor.UserID, "review_note": strings.TrimSpace(note), "reviewed_at": now,
+			"version": gorm.Expr("version + 1"),
+		}
+		action := "announcement.rejected"
+		if approve {
+			updates["status"], updates["published_at"] = StatusPublished, now
+			action = "announcement.approved"
+		}
+		if err := tx.Model(&Announcement{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to review announcement %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload announcement %d: %w", id, err)
+		}
+		decision := "rejected"
+		if approve {
+			decision = "approved and published"
+		}
+		if err := notification.CreateTx(tx, notification.Notice{
+			UserID:         updated.AuthorID,
+			OrganizationID: updated.OrganizationID,
+			Category:       "announcement.decision",
+			Subject:        fmt.Sprintf("Your announcement %q was %s", updated.Title, decision),
+			Body:           updated.ReviewNote,
+			Link:           fmt.Sprintf("/announcements/%d", updated.ID),
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: action, EntityType: "announcement", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// apply validates req for author and copies it onto announcement. Leads write on behalf of a division
+// they lead; the audience may be any of the organization's divisions, since reaching beyond their own is
+// what review is for.
+func (s *service) apply(tx *gorm.DB, author Author, announcement *Announcement, req Request) error {
+	if req.DivisionID == nil && !author.Reviewer {
+		return fmt.Errorf("%w: division_id is required", ErrInvalidAnnouncement)
+	}
+	divisionIDs := distinct(req.DivisionIDs)
+	referenced := divisionIDs
+	if req.DivisionID != nil {
+		referenced = distinct(append(slices.Clone(divisionIDs), *req.DivisionID))
+		if !author.Reviewer {
+			leadsAll, leads, err := s.leads(tx, author)
+			if err != nil {
+				return err
+			}
+			if !leadsAll && !slices.Contains(leads, *req.DivisionID) {
+				return ErrNotLead
+			}
+		}
+	}
+	if len(referenced) > 0 {
+		var found int64
+		query := tx.Table("divisions").Where("id IN ? AND deleted_at IS NULL", referenced)
+		if err := utils.OrgScope(query, announcement.OrganizationID).Count(&found).Error; err != nil {
+			return fmt.Errorf("failed to load divisions: %w", err)
+		}
+		if found != int64(len(referenced)) {
+			return fmt.Errorf("%w: unknown division", ErrInvalidAnnouncement)
+		}
+	}
+	audience, err := json.Marshal(divisionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode audience: %w", err)
+	}
+	announcement.Title = strings.TrimSpace(req.Title)
+	announcement.Body = strings.TrimSpace(req.Body)
+	announcement.DivisionID = req.DivisionID
+	announcement.DivisionIDs = audience
+	return nil
+}
+
+// needsReview reports whether an audience reaches beyond the divisions the author leads. Organization-wide
+// announcements always do, unless the author is a reviewer.
+func (s *service) needsReview(tx *gorm.DB, author Author, audience []uint) (bool, error) {
+	if author.Reviewer {
+		return false, nil
+	}
+	if len(audience) == 0 {
+		return true, nil
+	}
+	leadsAll, leads, err := s.leads(tx, author)
+	if err != nil || leadsAll {
+		return false, err
+	}
+	for _, id := range audience {
+		if !slices.Contains(leads, id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// leads returns the divisions the author leads: through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, author Author) (bool, []uint, error) {
+	if author.LeadsAll {
+		return true, nil, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Joins("JOIN employees ON employees.id = divisions.head_id").
+		Where("employees.user_id = ? AND divisions.deleted_at IS NULL AND employees.deleted_at IS NULL", author.UserID).
+		Pluck("divisions.id", &headed).Error; err != nil {
+		return false, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return false, append(slices.Clone(author.Leads), headed...), nil
+}
+
+// notifyReviewers asks the organization's HR to review a submission.
+func (s *service) notifyReviewers(tx *gorm.DB, actor audit.Actor, announcement *Announcement) error {
+	var reviewers []uint
+	query := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", reviewerRole)
+	if announcement.OrganizationID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *announcement.OrganizationID)
+	}
+	if err := query.Pluck("users.id", &reviewers).Error; err != nil {
+		return fmt.Errorf("failed to find reviewers: %w", err)
+	}
+	notices := make([]notification.Notice, 0, len(reviewers))
+	for _, id := range reviewers {
+		notices = append(notices, notification.Notice{
+			UserID:         id,
+			OrganizationID: announcement.OrganizationID,
+			Category:       "approval.announcement",
+			Subject:        fmt.Sprintf("%s submitted the announcement %q for review", actor.Username, announcement.Title),
+			Link:           "/hr/announcements/review-queue",
+		})
+	}
+	return notification.CreateTx(tx, notices...)
+}
+
+// page counts and fetches a page of announcements in order.
+func (s *service) page(query *gorm.DB, order string, page utils.Pagination) ([]Announcement, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+	announcements := []Announcement{}
+	if err := query.Order(order).Scopes(page.Scope).Find(&announcements).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return announcements, total, nil
+}
+
+// distinct returns the IDs sorted without duplicates, as an empty rather than nil slice so the audience
+// encodes as [].
+func distinct(ids []uint) []uint {
+	sorted := append([]uint{}, ids...)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// lock loads an announcement of the organization for update.
+func (s *service) lock(tx *gorm.DB, orgID *uint, id uint) (*Announcement, error) {
+	var announcement Announcement
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&announcement, id).Error; err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
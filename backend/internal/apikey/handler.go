@@ -0,0 +1,169 @@
+// prometheus/backend/internal/apikey/handler.go
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for API key management.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the API keys of the caller's organization.
+// @Summary List API keys
+// @Tags API Keys
+// @Produce json
+// @Success 200 {array} Key
+// @Router /admin/api-keys [get]
+func (h *Handler) List(c *gin.Context) {
+	keys, err := h.service.List(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "API keys fetched successfully", keys)
+}
+
+// Create creates an API key for the caller's organization. The token is only returned in this response.
+// @Summary Create an API key
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param key body CreateKeyRequest true "Name, readable event types, scopes, allowed addresses and expiry"
+// @Success 201 {object} CreatedKey
+// @Failure 400 {object} utils.ErrorResponse "Invalid request"
+// @Failure 409 {object} utils.ErrorResponse "Certificate identity bound to another key"
+// @Router /admin/api-keys [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	key, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.SendSuccessResponse(c, http.StatusCreated, "API key created successfully", key)
+}
+
+// Update replaces what an API key may do, from where and until when. The token stays the same.
+// @Summary Update an API key
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param key body UpdateKeyRequest true "Name, readable event types, scopes, allowed addresses and expiry"
+// @Success 200 {object} Key
+// @Failure 400 {object} utils.ErrorResponse "Invalid request"
+// @Failure 404 {object} utils.ErrorResponse "API key not found"
+// @Failure 409 {object} utils.ErrorResponse "Certificate identity bound to another key"
+// @Router /admin/api-keys/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	key, err := h.service.Update(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "API key updated successfully", key)
+}
+
+// Revoke disables an API key.
+// @Summary Revoke an API key
+// @Tags API Keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "API key not found"
+// @Router /admin/api-keys/{id} [delete]
+func (h *Handler) Revoke(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Revoke(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "API key revoked successfully", nil)
+}
+
+// Usage sums up an API key's calls.
+// @Summary Get an API key's usage
+// @Description Calls and errors (4xx and 5xx responses) in total, for the ten busiest routes and per day.
+// @Description from and to default to the past seven days; usage is kept for 90 days.
+// @Tags API Keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Success 200 {object} UsageReport
+// @Failure 400 {object} utils.ErrorResponse "Invalid range"
+// @Failure 404 {object} utils.ErrorResponse "API key not found"
+// @Router /admin/api-keys/{id}/usage [get]
+func (h *Handler) Usage(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	now := clock.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -6)
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if raw := c.Query(param); raw != "" {
+			day, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected YYYY-MM-DD")
+				return
+			}
+			*target = day
+		}
+	}
+	// Through the end of the to day.
+	report, err := h.service.Usage(utils.OrganizationFromContext(c), id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "API key usage fetched successfully", report)
+}
+
+// sendKeyError maps service errors to HTTP status codes.
+func sendKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "API key not found")
+	case errors.Is(err, ErrInvalidPattern), errors.Is(err, ErrInvalidScope), errors.Is(err, ErrInvalidAllowedIP),
+		errors.Is(err, ErrInvalidExpiry), errors.Is(err, ErrInvalidPeriod):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCertIdentityTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,175 @@
+// prometheus/backend/internal/apikey/model.go
+package apikey
+
+import (
+	"encoding/json"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Key is an API key for an external system (integrations, data warehouses). Only a hash of the token is
+// stored; the token itself is shown once, when the key is created.
+type Key struct {
+	gorm.Model
+	Name           string         `gorm:"type:varchar(100);not null" json:"name" example:"Payroll sync"`
+	Prefix         string         `gorm:"type:varchar(16);uniqueIndex;not null" json:"prefix" example:"3f2a9c1e"` // Identifies the key in tokens and logs
+	Hash           string         `gorm:"type:varchar(64);not null" json:"-"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"` // nil = default organization
+	EventTypes     datatypes.JSON `json:"event_types" swaggertype:"array,string" example:"user.*,role_request.approve"`
+	Scopes         datatypes.JSON `json:"scopes,omitempty" swaggertype:"array,string" example:"events"`                                // Endpoint groups the key may call; empty for keys created before scopes, which may call all
+	AllowedIPs     datatypes.JSON `json:"allowed_ips,omitempty" swaggertype:"array,string" example:"203.0.113.0/24"`                   // Addresses or CIDR ranges the key may be used from; empty = anywhere
+	CertIdentity   *string        `gorm:"type:varchar(255);index" json:"cert_identity,omitempty" example:"spiffe://prod/payroll-sync"` // Client certificate identity the key is bound to, see package mtls
+	CreatedByID    *uint          `json:"created_by_id,omitempty" example:"1"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time     `json:"last_used_at,omitempty"`
+	RevokedAt      *time.Time     `json:"revoked_at,omitempty"`
+	LastAlertAt    *time.Time     `json:"last_alert_at,omitempty"` // Hour of the last usage spike alerted, see DetectSpikes
+}
+
+// TableName implements gorm's Tabler.
+func (Key) TableName() string { return "api_keys" }
+
+// EventPatterns returns the event type patterns the key may read from the change feed.
+func (k *Key) EventPatterns() []string {
+	var patterns []string
+	_ = json.Unmarshal(k.EventTypes, &patterns) // Written by Create, always a string array
+	return patterns
+}
+
+// Allows reports whether the key may call routes of an endpoint group (see routing.APIKey).
+func (k *Key) Allows(scope string) bool {
+	var scopes []string
+	_ = json.Unmarshal(k.Scopes, &scopes) // Written by Create and Update, always a string array
+	return len(scopes) == 0 || slices.Contains(scopes, "*") || slices.Contains(scopes, scope)
+}
+
+// AllowsIP reports whether the key may be used from an address.
+func (k *Key) AllowsIP(ip string) bool {
+	var allowed []string
+	_ = json.Unmarshal(k.AllowedIPs, &allowed) // Written by Create and Update, always valid entries
+	if len(allowed) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, entry := range allowed {
+		if prefix, err := parseAllowedIP(entry); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsCertificate reports whether the key may be used by a client presenting a certificate with these
+// identities. Keys bound to an identity are refused without it, so a leaked token alone is useless.
+func (k *Key) AllowsCertificate(identities []string) bool {
+	return k.CertIdentity == nil || slices.Contains(identities, *k.CertIdentity)
+}
+
+// Usage counts a key's calls to one route within an hour.
+type Usage struct {
+	KeyID  uint      `gorm:"primaryKey"`
+	Hour   time.Time `gorm:"primaryKey"`
+	Method string    `gorm:"type:varchar(10);primaryKey"`
+	Route  string    `gorm:"type:varchar(255);primaryKey"` // Pattern, e.g. /api/v1/events, as in the HTTP metrics
+	Calls  int64     `gorm:"not null"`
+	Errors int64     `gorm:"not null"` // Responses with a 4xx or 5xx status
+}
+
+// TableName implements gorm's Tabler.
+func (Usage) TableName() string { return "api_key_usage" }
+
+// UsageReport sums up a key's calls over a period.
+type UsageReport struct {
+	KeyID        uint            `json:"key_id" example:"3"`
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Calls        int64           `json:"calls" example:"18250"`
+	Errors       int64           `json:"errors" example:"42"`
+	LastUsedAt   *time.Time      `json:"last_used_at,omitempty"`
+	TopEndpoints []EndpointUsage `json:"top_endpoints"`
+	Daily        []DailyUsage    `json:"daily"`
+}
+
+// EndpointUsage is a key's calls to one route over a report's period.
+type EndpointUsage struct {
+	Method string `json:"method" example:"GET"`
+	Route  string `json:"route" example:"/api/v1/events"`
+	Calls  int64  `json:"calls" example:"18100"`
+	Errors int64  `json:"errors" example:"40"`
+}
+
+// DailyUsage is a key's calls on one day (UTC).
+type DailyUsage struct {
+	Day    time.Time `json:"day" example:"2026-10-14T00:00:00Z"`
+	Calls  int64     `json:"calls" example:"2600"`
+	Errors int64     `json:"errors" example:"6"`
+}
+
+// CreateKeyRequest creates an API key.
+type CreateKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Payroll sync"`
+	// Event types the key may read: exact types, prefixes like "user.*", or "*" for all.
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,required,max=100" example:"user.*"`
+	// Endpoint groups the key may call, as listed under GET /admin/routes, or "*" for all.
+	Scopes []string `json:"scopes" binding:"required,min=1,max=50,dive,required,max=50" example:"events"`
+	// Addresses or CIDR ranges the key may be used from; anywhere if empty.
+	AllowedIPs []string `json:"allowed_ips,omitempty" binding:"max=50,dive,required,max=50" example:"203.0.113.0/24"`
+	// Identity of the client certificate the key is bound to: a URI or DNS SAN, or the subject's common
+	// name. The key then authenticates calls to the internal listener by certificate alone, and is refused
+	// without the certificate.
+	CertIdentity string     `json:"cert_identity,omitempty" binding:"max=255" example:"spiffe://prod/payroll-sync"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// UpdateKeyRequest replaces what a key may do, from where and until when. The token stays the same.
+type UpdateKeyRequest CreateKeyRequest
+
+// CreatedKey is returned once when a key is created.
+type CreatedKey struct {
+	Key
+	Token string `json:"token" example:"pk_3f2a9c1e_Zx8..."` // Shown only now; store it securely
+}
+
+// validScope reports whether an endpoint group is well-formed.
+func validScope(scope string) bool {
+	if scope == "*" {
+		return true
+	}
+	for _, r := range scope {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return scope != ""
+}
+
+// parseAllowedIP parses an allowlist entry: a CIDR range, or a single address.
+func parseAllowedIP(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// validPattern reports whether an event type pattern is well-formed.
+func validPattern(p string) bool {
+	if p == "*" {
+		return true
+	}
+	return !strings.Contains(strings.TrimSuffix(p, ".*"), "*")
+}
@@ -0,0 +1,319 @@
+// prometheus/backend/internal/apikey/service.go
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tokenPrefix starts every API key token, so leaked tokens are easy to recognize (e.g. by secret scanners).
+const tokenPrefix = "pk_"
+
+// lastUsedInterval limits how often LastUsedAt is written for a busy key.
+const lastUsedInterval = time.Minute
+
+// ErrInvalidKey is returned for tokens that are malformed, unknown, revoked or expired.
+var ErrInvalidKey = errors.New("invalid or revoked API key")
+
+// ErrInvalidPattern is returned for malformed event type patterns.
+var ErrInvalidPattern = errors.New(`event types must be exact types, prefixes like "user.*", or "*"`)
+
+// ErrInvalidScope is returned for malformed scopes.
+var ErrInvalidScope = errors.New(`scopes must be endpoint groups such as "events", or "*"`)
+
+// ErrInvalidAllowedIP is returned for allowlist entries that are neither addresses nor CIDR ranges.
+var ErrInvalidAllowedIP = errors.New("allowed IPs must be addresses or CIDR ranges")
+
+// ErrInvalidExpiry is returned for expiry dates that aren't in the future.
+var ErrInvalidExpiry = errors.New("the expiry date must be in the future")
+
+// ErrCertIdentityTaken is returned when binding a key to a certificate identity another active key is
+// bound to.
+var ErrCertIdentityTaken = errors.New("another API key is bound to this certificate identity")
+
+// Service manages API keys and authenticates their tokens.
+// orgID scopes the management calls to one organization's keys (nil = default organization).
+type Service interface {
+	Create(actor audit.Actor, orgID *uint, req CreateKeyRequest) (*CreatedKey, error)
+	Update(actor audit.Actor, orgID *uint, keyID uint, req UpdateKeyRequest) (*Key, error)
+	List(orgID *uint) ([]Key, error)
+	Revoke(actor audit.Actor, orgID *uint, keyID uint) error
+	Authenticate(token string) (*Key, error)
+	// AuthenticateCertificate resolves the identities of a verified client certificate, most specific first,
+	// to the key bound to one of them.
+	AuthenticateCertificate(identities []string) (*Key, error)
+
+	// RecordUsage counts a call made with a key in its hourly usage.
+	RecordUsage(keyID uint, method, route string, status int)
+	// Usage sums up a key's calls between from and to: totals, the busiest routes and calls per day.
+	Usage(orgID *uint, keyID uint, from, to time.Time) (*UsageReport, error)
+	// DetectSpikes alerts on unusual traffic per key, returning how many keys were alerted on.
+	DetectSpikes(ctx context.Context) (int, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db       *gorm.DB
+	auditor  audit.Service
+	notifier SpikeNotifier
+}
+
+// NewService creates a new instance of Service. Usage spikes are told to the organization's admins and
+// the key's creator through notifier; with nil, they are only audited.
+func NewService(db *gorm.DB, auditor audit.Service, notifier SpikeNotifier) Service {
+	return &service{db: db, auditor: auditor, notifier: notifier}
+}
+
+// Create generates a key. The token is "pk_<prefix>_<secret>"; only its SHA-256 hash is stored, which is
+// enough for random 256-bit secrets.
+func (s *service) Create(actor audit.Actor, orgID *uint, req CreateKeyRequest) (*CreatedKey, error) {
+	eventTypes, scopes, allowedIPs, err := encode(req)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := randomString(4, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	token := tokenPrefix + prefix + "_" + secret
+
+	key := Key{
+		Name:           req.Name,
+		Prefix:         prefix,
+		Hash:           hashToken(token),
+		OrganizationID: orgID,
+		EventTypes:     eventTypes,
+		Scopes:         scopes,
+		AllowedIPs:     allowedIPs,
+		CertIdentity:   certIdentity(req.CertIdentity),
+		CreatedByID:    actor.UserID,
+		ExpiresAt:      req.ExpiresAt,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCertIdentity(tx, 0, key.CertIdentity); err != nil {
+			return err
+		}
+		if err := tx.Create(&key).Error; err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "api_key.create", EntityType: "api_key", EntityID: fmt.Sprintf("%d", key.ID), After: key,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedKey{Key: key, Token: token}, nil
+}
+
+// Update replaces a key's name, event types, scopes, allowed addresses, certificate identity and expiry.
+// Revoked keys stay revoked.
+func (s *service) Update(actor audit.Actor, orgID *uint, keyID uint, req UpdateKeyRequest) (*Key, error) {
+	eventTypes, scopes, allowedIPs, err := encode(CreateKeyRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	var key Key
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&key, keyID).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		before := key
+		identity := certIdentity(req.CertIdentity)
+		if err := checkCertIdentity(tx, key.ID, identity); err != nil {
+			return err
+		}
+		if err := tx.Model(&key).Updates(map[string]interface{}{
+			"name": req.Name, "event_types": eventTypes, "scopes": scopes, "allowed_ips": allowedIPs,
+			"cert_identity": identity, "expires_at": req.ExpiresAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update API key: %w", err)
+		}
+		if err := tx.First(&key, key.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload API key: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "api_key.update", EntityType: "api_key", EntityID: fmt.Sprintf("%d", key.ID), Before: before, After: key,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns the organization's keys, newest first, including revoked ones.
+func (s *service) List(orgID *uint) ([]Key, error) {
+	var keys []Key
+	if err := utils.OrgScope(s.db, orgID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke disables a key immediately. Revoked keys are kept for the audit trail.
+func (s *service) Revoke(actor audit.Actor, orgID *uint, keyID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var key Key
+		if err := utils.OrgScope(tx, orgID).First(&key, keyID).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		if key.RevokedAt != nil {
+			return nil
+		}
+		before := key
+		now := time.Now().UTC()
+		if err := tx.Model(&key).Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "api_key.revoke", EntityType: "api_key", EntityID: fmt.Sprintf("%d", key.ID), Before: before, After: key,
+		})
+	})
+}
+
+// Authenticate resolves a token to its key.
+func (s *service) Authenticate(token string) (*Key, error) {
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), "_")
+	if !strings.HasPrefix(token, tokenPrefix) || !ok {
+		return nil, ErrInvalidKey
+	}
+	var key Key
+	if err := s.db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(key.Hash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+	return s.usable(&key)
+}
+
+// AuthenticateCertificate trusts the identities as given: they must come from a certificate verified
+// against the internal listener's client CAs (see mtls.PeerIdentities).
+func (s *service) AuthenticateCertificate(identities []string) (*Key, error) {
+	if len(identities) == 0 {
+		return nil, ErrInvalidKey
+	}
+	var keys []Key
+	if err := s.db.Where("cert_identity IN ? AND revoked_at IS NULL", identities).Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+	for _, identity := range identities {
+		for i := range keys {
+			if *keys[i].CertIdentity == identity {
+				return s.usable(&keys[i])
+			}
+		}
+	}
+	return nil, ErrInvalidKey
+}
+
+// usable refuses revoked and expired keys, and records when a key was last used.
+func (s *service) usable(key *Key) (*Key, error) {
+	now := clock.Now().UTC()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, ErrInvalidKey
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		// Usage tracking is best effort; it must not fail the request.
+		s.db.Model(key).UpdateColumn("last_used_at", now)
+	}
+	return key, nil
+}
+
+// certIdentity normalizes a requested certificate identity; nil unbinds the key.
+func certIdentity(raw string) *string {
+	identity := strings.TrimSpace(raw)
+	if identity == "" {
+		return nil
+	}
+	return &identity
+}
+
+// checkCertIdentity checks that no other active key is bound to the identity. Revoked keys keep theirs for
+// the audit trail.
+func checkCertIdentity(tx *gorm.DB, keyID uint, identity *string) error {
+	if identity == nil {
+		return nil
+	}
+	var taken int64
+	if err := tx.Model(&Key{}).Where("cert_identity = ? AND revoked_at IS NULL AND id <> ?", *identity, keyID).
+		Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check certificate identity: %w", err)
+	}
+	if taken > 0 {
+		return ErrCertIdentityTaken
+	}
+	return nil
+}
+
+// encode validates what a key may do and until when, and encodes its lists for storage.
+func encode(req CreateKeyRequest) (eventTypes, scopes, allowedIPs datatypes.JSON, err error) {
+	for _, p := range req.EventTypes {
+		if !validPattern(p) {
+			return nil, nil, nil, ErrInvalidPattern
+		}
+	}
+	for _, scope := range req.Scopes {
+		if !validScope(scope) {
+			return nil, nil, nil, ErrInvalidScope
+		}
+	}
+	for _, entry := range req.AllowedIPs {
+		if _, err := parseAllowedIP(entry); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %q", ErrInvalidAllowedIP, entry)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(clock.Now()) {
+		return nil, nil, nil, ErrInvalidExpiry
+	}
+	if req.AllowedIPs == nil {
+		req.AllowedIPs = []string{}
+	}
+	if eventTypes, err = json.Marshal(req.EventTypes); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode event types: %w", err)
+	}
+	if scopes, err = json.Marshal(req.Scopes); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode scopes: %w", err)
+	}
+	if allowedIPs, err = json.Marshal(req.AllowedIPs); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode allowed addresses: %w", err)
+	}
+	return eventTypes, scopes, allowedIPs, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return encode(b), nil
+}
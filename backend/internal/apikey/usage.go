@@ -0,0 +1,211 @@
+// prometheus/backend/internal/apikey/usage.go
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SpikeAlert tells a user about a key's usage spike.
+type SpikeAlert struct {
+	UserID         uint
+	OrganizationID *uint
+	Subject        string
+	Body           string
+	Link           string
+}
+
+// SpikeNotifier delivers spike alerts inside the transaction recording the spike. The router backs it
+// with the notification package, which this package can't import: it depends on the API key middleware.
+type SpikeNotifier interface {
+	NotifySpike(tx *gorm.DB, alerts []SpikeAlert) error
+}
+
+// SpikeNotifierFunc adapts a function to SpikeNotifier.
+type SpikeNotifierFunc func(tx *gorm.DB, alerts []SpikeAlert) error
+
+// NotifySpike calls f.
+func (f SpikeNotifierFunc) NotifySpike(tx *gorm.DB, alerts []SpikeAlert) error { return f(tx, alerts) }
+
+// JobDetectSpikes is the job type alerting on usage spikes, run hourly.
+const JobDetectSpikes = "apikey.detect_spikes"
+
+const (
+	// usageRetention is how long hourly usage is kept.
+	usageRetention = 90 * 24 * time.Hour
+	// spikeBaseline is the period a key's hourly calls are compared with.
+	spikeBaseline = 7 * 24 * time.Hour
+	// spikeFactor is how many times its average hourly calls a key must make in an hour to spike.
+	spikeFactor = 5
+	// spikeMinCalls keeps quiet keys from alerting on a handful of calls.
+	spikeMinCalls = 100
+	// topEndpoints is how many routes a usage report lists.
+	topEndpoints = 10
+	// adminRole is notified of usage spikes of its organization's keys, along with whoever created the key.
+	adminRole = "admin"
+)
+
+// ErrInvalidPeriod is returned for usage reports whose period is empty or longer than usageRetention.
+var ErrInvalidPeriod = errors.New("the period must end after it starts and span at most 90 days")
+
+func (s *service) RecordUsage(keyID uint, method, route string, status int) {
+	usage := Usage{KeyID: keyID, Hour: clock.Now().UTC().Truncate(time.Hour), Method: method, Route: route, Calls: 1}
+	if status >= 400 {
+		usage.Errors = 1
+	}
+	// Usage tracking is best effort; it must not fail the request.
+	s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key_id"}, {Name: "hour"}, {Name: "method"}, {Name: "route"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "calls"}, Value: gorm.Expr("api_key_usage.calls + ?", usage.Calls)},
+			{Column: clause.Column{Name: "errors"}, Value: gorm.Expr("api_key_usage.errors + ?", usage.Errors)},
+		},
+	}).Create(&usage)
+}
+
+func (s *service) Usage(orgID *uint, keyID uint, from, to time.Time) (*UsageReport, error) {
+	if !to.After(from) || to.Sub(from) > usageRetention {
+		return nil, ErrInvalidPeriod
+	}
+	var key Key
+	if err := utils.OrgScope(s.db, orgID).First(&key, keyID).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	report := &UsageReport{KeyID: key.ID, From: from, To: to, LastUsedAt: key.LastUsedAt, TopEndpoints: []EndpointUsage{}, Daily: []DailyUsage{}}
+	period := s.db.Model(&Usage{}).Where("key_id = ? AND hour >= ? AND hour < ?", key.ID, from, to)
+	if err := period.Session(&gorm.Session{}).Select("method, route, SUM(calls) AS calls, SUM(errors) AS errors").
+		Group("method, route").Order("calls DESC, route, method").Limit(topEndpoints).
+		Scan(&report.TopEndpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to load API key usage per endpoint: %w", err)
+	}
+	if err := period.Session(&gorm.Session{}).Select("DATE_TRUNC('day', hour) AS day, SUM(calls) AS calls, SUM(errors) AS errors").
+		Group("day").Order("day").Scan(&report.Daily).Error; err != nil {
+		return nil, fmt.Errorf("failed to load API key usage per day: %w", err)
+	}
+	for _, d := range report.Daily {
+		report.Calls += d.Calls
+		report.Errors += d.Errors
+	}
+	return report, nil
+}
+
+// DetectSpikes alerts on keys whose calls in the last full hour exceed spikeFactor times their average
+// hourly calls over the week before, and drops usage older than usageRetention. Each spike is notified to
+// the key's creator and the organization's admins once.
+func (s *service) DetectSpikes(ctx context.Context) (int, error) {
+	hour := clock.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	db := s.db.WithContext(ctx)
+	if err := db.Where("hour < ?", hour.Add(-usageRetention)).Delete(&Usage{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to purge API key usage: %w", err)
+	}
+	var spikes []struct {
+		KeyID    uint
+		Calls    int64
+		Errors   int64
+		Baseline int64 // Calls over spikeBaseline
+	}
+	if err := db.Model(&Usage{}).
+		Select("key_id, SUM(CASE WHEN hour = ? THEN calls ELSE 0 END) AS calls, SUM(CASE WHEN hour = ? THEN errors ELSE 0 END) AS errors, SUM(CASE WHEN hour < ? THEN calls ELSE 0 END) AS baseline",
+			hour, hour, hour).
+		Where("hour >= ? AND hour <= ?", hour.Add(-spikeBaseline), hour).Group("key_id").
+		Having("SUM(CASE WHEN hour = ? THEN calls ELSE 0 END) >= ?", hour, spikeMinCalls).
+		Scan(&spikes).Error; err != nil {
+		return 0, fmt.Errorf("failed to load API key usage: %w", err)
+	}
+	hours := int64(spikeBaseline / time.Hour)
+	alerted := 0
+	for _, spike := range spikes {
+		// Comparing sums avoids rounding a low average down to zero.
+		if spike.Calls*hours <= spikeFactor*spike.Baseline {
+			continue
+		}
+		average := float64(spike.Baseline) / float64(hours)
+		sent, err := s.alert(db, spike.KeyID, hour, fmt.Sprintf("%d calls (%d errors) between %s and %s UTC, against %.1f per hour on average over the week before",
+			spike.Calls, spike.Errors, hour.Format("2006-01-02 15:04"), hour.Add(time.Hour).Format("15:04"), average))
+		if err != nil {
+			return alerted, err
+		}
+		if sent {
+			alerted++
+		}
+	}
+	return alerted, nil
+}
+
+// alert notifies a key's usage spike in hour, unless it was already.
+func (s *service) alert(db *gorm.DB, keyID uint, hour time.Time, body string) (bool, error) {
+	sent := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var key Key
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&key, keyID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Deleted since
+		} else if err != nil {
+			return fmt.Errorf("failed to load API key %d: %w", keyID, err)
+		}
+		if key.RevokedAt != nil || (key.LastAlertAt != nil && !key.LastAlertAt.Before(hour)) {
+			return nil
+		}
+		if err := tx.Model(&key).UpdateColumn("last_alert_at", hour).Error; err != nil {
+			return fmt.Errorf("failed to record API key alert: %w", err)
+		}
+		var recipients []uint
+		query := tx.Model(&auth.User{}).Distinct("users.id").
+			Joins("JOIN user_roles ON user_roles.user_id = users.id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ? AND users.is_active", adminRole)
+		if key.OrganizationID == nil {
+			query = query.Where("users.organization_id IS NULL")
+		} else {
+			query = query.Where("users.organization_id = ?", *key.OrganizationID)
+		}
+		if err := query.Pluck("users.id", &recipients).Error; err != nil {
+			return fmt.Errorf("failed to find admins: %w", err)
+		}
+		if key.CreatedByID != nil && !slices.Contains(recipients, *key.CreatedByID) {
+			recipients = append(recipients, *key.CreatedByID)
+		}
+		alerts := make([]SpikeAlert, 0, len(recipients))
+		for _, id := range recipients {
+			alerts = append(alerts, SpikeAlert{
+				UserID:         id,
+				OrganizationID: key.OrganizationID,
+				Subject:        fmt.Sprintf("Unusual traffic on the API key %q (%s)", key.Name, key.Prefix),
+				Body:           body,
+				Link:           fmt.Sprintf("/admin/api-keys/%d/usage", key.ID),
+			})
+		}
+		if s.notifier != nil {
+			if err := s.notifier.NotifySpike(tx, alerts); err != nil {
+				return err
+			}
+		}
+		sent = true
+		return s.auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+			Action: "api_key.usage_spike", EntityType: "api_key", EntityID: fmt.Sprintf("%d", key.ID),
+			After: map[string]interface{}{"hour": hour, "summary": body},
+		})
+	})
+	return sent, err
+}
+
+// DetectSpikesJob runs Service.DetectSpikes.
+func DetectSpikesJob(service Service) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		alerted, err := service.DetectSpikes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"alerted": alerted}, nil
+	}
+}
@@ -0,0 +1,159 @@
+// prometheus/backend/internal/approval/handler.go
+package approval
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxNoteLength bounds the optional note entered on the confirmation page.
+const maxNoteLength = 500
+
+// page is shown to approvers following a link: a confirmation form, the outcome, or why the link can't be used.
+var page = template.Must(template.New("approval").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>body{font-family:system-ui,sans-serif;max-width:36rem;margin:3rem auto;padding:0 1rem;color:#222}
+textarea{width:100%;min-height:5rem}button{padding:.5rem 1.5rem;font-size:1rem}</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{if .Confirm}}<form method="post">
+<p><label for="note">Note (optional)</label><br><textarea id="note" name="note" maxlength="500"></textarea></p>
+<button type="submit">{{if .Approve}}Approve{{else}}Reject{{end}} as {{.Approver}}</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+// pageData fills page.
+type pageData struct {
+	Title       string
+	Description string
+	Message     string
+	Confirm     bool
+	Approve     bool
+	Approver    string
+}
+
+// Handler serves the pages behind approval links. They are HTML, not API responses: approvers open them
+// from their mail client.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Show asks the approver to confirm the decision of a link.
+// @Summary Open an approval link
+// @Description Shows an HTML confirmation page for the approve or reject link of an approval email. The
+// @Description decision is only made when the page's form is submitted.
+// @Tags Approvals
+// @Produce html
+// @Param token path string true "Signed link token"
+// @Success 200 "Confirmation page"
+// @Failure 400 "Invalid link"
+// @Failure 403 "Approver may no longer decide"
+// @Failure 409 "Already decided"
+// @Failure 410 "Link expired"
+// @Router /actions/{token} [get]
+func (h *Handler) Show(c *gin.Context) {
+	pending, err := h.service.Open(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		renderError(c, pending, err)
+		return
+	}
+	title := "Reject this request?"
+	if pending.Approve {
+		title = "Approve this request?"
+	}
+	render(c, http.StatusOK, pageData{
+		Title: title, Description: pending.Description, Confirm: true, Approve: pending.Approve, Approver: pending.Approver,
+	})
+}
+
+// Decide records the decision of a link.
+// @Summary Decide through an approval link
+// @Tags Approvals
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param token path string true "Signed link token"
+// @Param note formData string false "Optional note"
+// @Success 200 "Decision recorded"
+// @Failure 400 "Invalid link"
+// @Failure 403 "Approver may no longer decide"
+// @Failure 409 "Already decided"
+// @Failure 410 "Link expired"
+// @Failure 422 "Decision can't be applied"
+// @Router /actions/{token} [post]
+func (h *Handler) Decide(c *gin.Context) {
+	note := c.PostForm("note")
+	if len(note) > maxNoteLength {
+		render(c, http.StatusBadRequest, pageData{Title: "Note too long", Message: "Please keep the note under 500 characters."})
+		return
+	}
+	pending, err := h.service.Decide(c.Request.Context(), c.Param("token"), note, c.ClientIP())
+	if err != nil {
+		renderError(c, pending, err)
+		return
+	}
+	title := "Request rejected"
+	if pending.Approve {
+		title = "Request approved"
+	}
+	render(c, http.StatusOK, pageData{Title: title, Description: pending.Description, Message: "Your decision was recorded. You can close this page."})
+}
+
+// renderError explains why a link can't be used.
+func renderError(c *gin.Context, pending *Pending, err error) {
+	data := pageData{Title: "This link can't be used"}
+	if pending != nil {
+		data.Description = pending.Description
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidLink):
+		status, data.Message = http.StatusBadRequest, "The link is invalid. Copy the whole link from the email, or decide in the app."
+	case errors.Is(err, ErrExpiredLink):
+		status, data.Message = http.StatusGone, "The link has expired. Please decide in the app."
+	case errors.Is(err, ErrNotAllowed):
+		status, data.Message = http.StatusForbidden, "You may no longer decide this request, e.g. because your role or sessions were revoked."
+	case errors.Is(err, ErrNotPending):
+		status, data.Title, data.Message = http.StatusConflict, "Already decided", "This request was already decided; nothing was changed."
+	case errors.Is(err, ErrUnprocessable):
+		status, data.Message = http.StatusUnprocessableEntity, "The decision couldn't be applied ("+err.Error()+"). Please decide in the app."
+	default:
+		log.Printf("Approval link failed: %v", err)
+		data.Message = "Something went wrong. Please try again later or decide in the app."
+	}
+	render(c, status, data)
+}
+
+// render writes page. The token in the URL is a credential: the page must not be cached, leak it through
+// the Referer header, or be framed.
+func render(c *gin.Context, status int, data pageData) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render page")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
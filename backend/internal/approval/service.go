@@ -0,0 +1,179 @@
+// prometheus/backend/internal/approval/service.go
+package approval
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"strings"
+	"sync"
+	"time"
+)
+
+// linkTTL is how long an emailed approval link stays valid.
+const linkTTL = 72 * time.Hour
+
+var (
+	// ErrInvalidLink is returned for tampered or malformed links, and links of unknown kinds.
+	ErrInvalidLink = errors.New("invalid approval link")
+	// ErrExpiredLink is returned for links older than linkTTL.
+	ErrExpiredLink = errors.New("approval link has expired")
+	// ErrNotAllowed is returned when the approver may no longer decide, e.g. after losing the role or
+	// being logged out everywhere. Kinds return it from Authorize.
+	ErrNotAllowed = errors.New("you may no longer decide this request")
+	// ErrNotPending is returned when the request was already decided. Kinds return it from Describe and Decide.
+	ErrNotPending = errors.New("request was already decided")
+	// ErrUnprocessable is returned by kinds when the decision can't be applied as it stands, e.g. a
+	// time-limited role grant that would already have expired.
+	ErrUnprocessable = errors.New("request can't be decided by email")
+)
+
+// Kind is a type of request that approvers can decide from an email, e.g. role requests.
+type Kind struct {
+	// Authorize checks that the user may still decide requests of this kind with a link issued at
+	// issuedAt, and returns the actor the decision is recorded as.
+	Authorize func(ctx context.Context, userID uint, issuedAt time.Time) (audit.Actor, error)
+	// Describe returns a one-line summary of a pending request for the confirmation page.
+	Describe func(ctx context.Context, subjectID uint) (string, error)
+	// Decide approves or rejects the request.
+	Decide func(actor audit.Actor, subjectID uint, approve bool, note string) error
+}
+
+// claim is the signed content of a link: who may decide what, and how.
+type claim struct {
+	UserID    uint   `json:"u"`
+	Kind      string `json:"k"`
+	SubjectID uint   `json:"s"`
+	Approve   bool   `json:"a"`
+	IssuedAt  int64  `json:"i"` // Unix seconds
+}
+
+// Pending describes the decision a link stands for.
+type Pending struct {
+	Kind        string
+	SubjectID   uint
+	Approve     bool
+	Description string
+	Approver    string
+}
+
+// Service signs one-click approve/reject links for approval emails and records the decisions made with
+// them. A link only decides anything when submitted from its confirmation page, so mail scanners that
+// follow links can't approve on the reader's behalf.
+type Service struct {
+	secret     []byte
+	apiBaseURL string
+
+	mu    sync.RWMutex
+	kinds map[string]Kind
+}
+
+// NewService creates a Service signing links with secret; apiBaseURL is the public URL of this API.
+func NewService(secret, apiBaseURL string) *Service {
+	return &Service{secret: []byte(secret), apiBaseURL: strings.TrimRight(apiBaseURL, "/"), kinds: make(map[string]Kind)}
+}
+
+// Register makes requests of a kind decidable by email.
+func (s *Service) Register(name string, kind Kind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kinds[name] = kind
+}
+
+// Links returns the approve and reject links of a request for one approver, or empty strings for kinds
+// that aren't registered.
+func (s *Service) Links(userID uint, kind string, subjectID uint) (approve, reject string) {
+	if _, ok := s.kind(kind); !ok {
+		return "", ""
+	}
+	issuedAt := clock.Now().Unix()
+	approve = s.url(claim{UserID: userID, Kind: kind, SubjectID: subjectID, Approve: true, IssuedAt: issuedAt})
+	reject = s.url(claim{UserID: userID, Kind: kind, SubjectID: subjectID, Approve: false, IssuedAt: issuedAt})
+	return approve, reject
+}
+
+// Open checks a link and describes the decision it stands for, without making it.
+func (s *Service) Open(ctx context.Context, token string) (*Pending, error) {
+	_, _, pending, err := s.open(ctx, token)
+	return pending, err
+}
+
+// Decide checks a link again and records its decision. ip is the client address for the audit trail.
+func (s *Service) Decide(ctx context.Context, token, note, ip string) (*Pending, error) {
+	kind, actor, pending, err := s.open(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	actor.IP = ip
+	if err := kind.Decide(actor, pending.SubjectID, pending.Approve, strings.TrimSpace(note)); err != nil {
+		return pending, err
+	}
+	return pending, nil
+}
+
+func (s *Service) open(ctx context.Context, token string) (Kind, audit.Actor, *Pending, error) {
+	c, err := s.verify(token)
+	if err != nil {
+		return Kind{}, audit.Actor{}, nil, err
+	}
+	kind, ok := s.kind(c.Kind)
+	if !ok {
+		return Kind{}, audit.Actor{}, nil, ErrInvalidLink
+	}
+	issuedAt := time.Unix(c.IssuedAt, 0)
+	if clock.Now().After(issuedAt.Add(linkTTL)) {
+		return Kind{}, audit.Actor{}, nil, ErrExpiredLink
+	}
+	actor, err := kind.Authorize(ctx, c.UserID, issuedAt)
+	if err != nil {
+		return Kind{}, audit.Actor{}, nil, err
+	}
+	pending := &Pending{Kind: c.Kind, SubjectID: c.SubjectID, Approve: c.Approve, Approver: actor.Username}
+	if pending.Description, err = kind.Describe(ctx, c.SubjectID); err != nil {
+		return Kind{}, audit.Actor{}, pending, err
+	}
+	return kind, actor, pending, nil
+}
+
+func (s *Service) kind(name string) (Kind, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	kind, ok := s.kinds[name]
+	return kind, ok
+}
+
+// url encodes and signs a claim into a link.
+func (s *Service) url(c claim) string {
+	payload, _ := json.Marshal(c) // A struct of numbers and a string always encodes
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return fmt.Sprintf("%s/api/v1/actions/%s.%s", s.apiBaseURL, encoded, s.sign(encoded))
+}
+
+func (s *Service) verify(token string) (claim, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return claim{}, ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return claim{}, ErrInvalidLink
+	}
+	var c claim
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return claim{}, ErrInvalidLink
+	}
+	return c, nil
+}
+
+func (s *Service) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("approval-link:" + encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
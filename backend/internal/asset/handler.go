@@ -0,0 +1,318 @@
+// prometheus/backend/internal/asset/handler.go
+package asset
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for company assets and their assignments.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListAssets returns the asset register by tag.
+// @Summary List assets
+// @Tags Assets
+// @Produce json
+// @Param status query string false "Status" Enums(available, assigned, in_repair, retired, lost)
+// @Param category query string false "Category" Enums(laptop, phone, badge, monitor, other)
+// @Param holder_id query int false "Employee holding the asset"
+// @Param search query string false "Tag, name or serial number"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/assets [get]
+func (h *Handler) ListAssets(c *gin.Context) {
+	filter := Filter{Search: c.Query("search")}
+	var ok bool
+	if filter.Status, ok = parseStatus(c); !ok {
+		return
+	}
+	if filter.Category, ok = parseCategory(c); !ok {
+		return
+	}
+	if filter.HolderID, ok = utils.OptionalUintQuery(c, "holder_id"); !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	assets, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Assets fetched successfully", page.Response(assets, total))
+}
+
+// GetAsset returns an asset.
+// @Summary Get an asset
+// @Tags Assets
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Success 200 {object} Asset
+// @Failure 404 {object} utils.ErrorResponse "Asset not found"
+// @Router /hr/assets/{id} [get]
+func (h *Handler) GetAsset(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	asset, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, asset.UpdatedAt, asset.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Asset fetched successfully", asset)
+}
+
+// CreateAsset adds an asset to the register.
+// @Summary Create an asset
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param asset body Request true "Asset"
+// @Success 201 {object} Asset
+// @Failure 400 {object} utils.ErrorResponse "Invalid asset"
+// @Failure 409 {object} utils.ErrorResponse "Tag taken"
+// @Router /hr/assets [post]
+func (h *Handler) CreateAsset(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	asset, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Asset created successfully", asset)
+}
+
+// UpdateAsset replaces an asset's fields.
+// @Summary Update an asset
+// @Description The status of an assigned asset changes by returning it, so status must be left out for
+// @Description those.
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param If-Match header string false "ETag from a previous read"
+// @Param asset body Request true "Asset"
+// @Success 200 {object} Asset
+// @Failure 400 {object} utils.ErrorResponse "Invalid asset"
+// @Failure 404 {object} utils.ErrorResponse "Asset not found"
+// @Failure 409 {object} utils.ErrorResponse "Tag taken, or status of an assigned asset"
+// @Failure 412 {object} utils.ErrorResponse "Modified concurrently"
+// @Router /hr/assets/{id} [put]
+func (h *Handler) UpdateAsset(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	asset, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, asset.UpdatedAt, asset.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Asset updated successfully", asset)
+}
+
+// AssignAsset hands an available asset out to an employee.
+// @Summary Assign an asset
+// @Description The employee is notified. The condition defaults to the asset's current one.
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param assignment body AssignRequest true "Assignment"
+// @Success 200 {object} Asset
+// @Failure 400 {object} utils.ErrorResponse "Unknown employee"
+// @Failure 404 {object} utils.ErrorResponse "Asset not found"
+// @Failure 409 {object} utils.ErrorResponse "Asset not available"
+// @Router /hr/assets/{id}/assign [post]
+func (h *Handler) AssignAsset(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	asset, err := h.service.Assign(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Asset assigned successfully", asset)
+}
+
+// ReturnAsset takes an assigned asset back from its holder.
+// @Summary Return an asset
+// @Description Records the condition it came back in and where it goes next. Tasks to collect the asset on
+// @Description the holder's exit checklist are ticked off, also when it is reported lost.
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param return body ReturnRequest true "Return"
+// @Success 200 {object} Asset
+// @Failure 404 {object} utils.ErrorResponse "Asset not found"
+// @Failure 409 {object} utils.ErrorResponse "Asset not assigned"
+// @Router /hr/assets/{id}/return [post]
+func (h *Handler) ReturnAsset(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	asset, err := h.service.Return(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Asset returned successfully", asset)
+}
+
+// AssetHistory returns who held an asset, latest first.
+// @Summary Get an asset's assignment history
+// @Tags Assets
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Success 200 {array} Assignment
+// @Failure 404 {object} utils.ErrorResponse "Asset not found"
+// @Router /hr/assets/{id}/history [get]
+func (h *Handler) AssetHistory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	assignments, err := h.service.History(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Asset history fetched successfully", assignments)
+}
+
+// EmployeeAssets returns the assets an employee holds.
+// @Summary List an employee's assets
+// @Tags Assets
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param history query bool false "Include assets returned"
+// @Success 200 {array} Assignment
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/assets [get]
+func (h *Handler) EmployeeAssets(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	assignments, err := h.service.EmployeeAssets(utils.OrganizationFromContext(c), id, c.Query("history") == "true")
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee assets fetched successfully", assignments)
+}
+
+// MyAssets returns the assets the caller holds.
+// @Summary List my assets
+// @Tags Assets
+// @Produce json
+// @Param history query bool false "Include assets returned"
+// @Success 200 {array} Assignment
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/assets [get]
+func (h *Handler) MyAssets(c *gin.Context) {
+	orgID := utils.OrganizationFromContext(c)
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	assignments, err := h.service.EmployeeAssets(orgID, emp.ID, c.Query("history") == "true")
+	if err != nil {
+		sendAssetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Assets fetched successfully", assignments)
+}
+
+func parseStatus(c *gin.Context) (Status, bool) {
+	status := Status(c.Query("status"))
+	switch status {
+	case "", StatusAvailable, StatusAssigned, StatusRepair, StatusRetired, StatusLost:
+		return status, true
+	}
+	utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	return "", false
+}
+
+func parseCategory(c *gin.Context) (Category, bool) {
+	category := Category(c.Query("category"))
+	switch category {
+	case "", CategoryLaptop, CategoryPhone, CategoryBadge, CategoryMonitor, CategoryOther:
+		return category, true
+	}
+	utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid category parameter")
+	return "", false
+}
+
+// sendAssetError maps service errors to HTTP status codes.
+func sendAssetError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidAsset):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrTagTaken), errors.Is(err, ErrStatus):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,124 @@
+// prometheus/backend/internal/asset/model.go
+package asset
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Category is what kind of asset it is.
+type Category string
+
+const (
+	CategoryLaptop  Category = "laptop"
+	CategoryPhone   Category = "phone"
+	CategoryBadge   Category = "badge"
+	CategoryMonitor Category = "monitor"
+	CategoryOther   Category = "other"
+)
+
+// Status is where an asset is.
+type Status string
+
+const (
+	StatusAvailable Status = "available" // In stock, ready to assign
+	StatusAssigned  Status = "assigned"  // Held by an employee
+	StatusRepair    Status = "in_repair"
+	StatusRetired   Status = "retired" // Disposed of or sold; kept for its history
+	StatusLost      Status = "lost"
+)
+
+// Condition is the state an asset is in, recorded when it is assigned and returned.
+type Condition string
+
+const (
+	ConditionNew     Condition = "new"
+	ConditionGood    Condition = "good"
+	ConditionFair    Condition = "fair"
+	ConditionPoor    Condition = "poor"
+	ConditionDamaged Condition = "damaged"
+)
+
+// Asset is a piece of company property tracked by its tag.
+type Asset struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"17"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Tag            string     `gorm:"type:varchar(50);not null;index" json:"tag" example:"LT-0042"` // Unique within the organization, matched case-insensitively
+	Name           string     `gorm:"type:varchar(150);not null" json:"name" example:"Dell Latitude 7440"`
+	Category       Category   `gorm:"type:varchar(20);not null;index" json:"category" example:"laptop"`
+	SerialNumber   string     `gorm:"type:varchar(100)" json:"serial_number,omitempty" example:"5CG1234XYZ"`
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"assigned"`
+	Condition      Condition  `gorm:"type:varchar(20);not null" json:"condition" example:"good"`
+	HolderID       *uint      `gorm:"index" json:"holder_id,omitempty" example:"12"` // Employee holding the asset while assigned
+	HolderName     string     `gorm:"-" json:"holder_name,omitempty" example:"Laila Haddad"`
+	PurchasedOn    *time.Time `gorm:"type:date" json:"purchased_on,omitempty" example:"2025-02-01T00:00:00Z"`
+	Notes          string     `gorm:"type:varchar(1000)" json:"notes,omitempty"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Label names the asset for people, e.g. on exit checklists.
+func (a Asset) Label() string {
+	category := string(a.Category)
+	if a.Category == CategoryOther {
+		category = "Asset"
+	}
+	return fmt.Sprintf("%s%s %s (%s)", strings.ToUpper(category[:1]), category[1:], a.Tag, a.Name)
+}
+
+// Assignment is an asset held by an employee, from when it was handed out until it was returned.
+type Assignment struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"40"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	AssetID        uint       `gorm:"not null;index" json:"asset_id" example:"17"`
+	EmployeeID     uint       `gorm:"not null;index" json:"employee_id" example:"12"`
+	AssignedAt     time.Time  `gorm:"not null" json:"assigned_at"`
+	AssignedBy     *uint      `json:"assigned_by,omitempty" example:"3"` // User ID
+	ConditionOut   Condition  `gorm:"type:varchar(20);not null" json:"condition_out" example:"good"`
+	Note           string     `gorm:"type:varchar(1000)" json:"note,omitempty"`
+	ReturnedAt     *time.Time `gorm:"index" json:"returned_at,omitempty"` // Nil while the employee holds the asset
+	ReturnedTo     *uint      `json:"returned_to,omitempty" example:"3"`  // User ID
+	ConditionIn    Condition  `gorm:"type:varchar(20)" json:"condition_in,omitempty" example:"fair"`
+	ReturnNote     string     `gorm:"type:varchar(1000)" json:"return_note,omitempty" example:"Scratched lid"`
+	Asset          *Asset     `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+	EmployeeName   string     `gorm:"-" json:"employee_name,omitempty" example:"Laila Haddad"`
+}
+
+// TableName keeps assignments next to assets.
+func (Assignment) TableName() string { return "asset_assignments" }
+
+// Request creates an asset or replaces its fields. Assignment changes go through assigning and returning.
+type Request struct {
+	Tag          string    `json:"tag" binding:"required,max=50" example:"LT-0042"`
+	Name         string    `json:"name" binding:"required,max=150" example:"Dell Latitude 7440"`
+	Category     Category  `json:"category" binding:"required,oneof=laptop phone badge monitor other" example:"laptop"`
+	SerialNumber string    `json:"serial_number,omitempty" binding:"max=100" example:"5CG1234XYZ"`
+	Condition    Condition `json:"condition" binding:"required,oneof=new good fair poor damaged" example:"new"`
+	Status       Status    `json:"status,omitempty" binding:"omitempty,oneof=available in_repair retired lost" example:"available"` // Of an asset not assigned; defaults to available
+	PurchasedOn  string    `json:"purchased_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2025-02-01"`
+	Notes        string    `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// AssignRequest hands an available asset out to an employee.
+type AssignRequest struct {
+	EmployeeID uint      `json:"employee_id" binding:"required" example:"12"`
+	Condition  Condition `json:"condition,omitempty" binding:"omitempty,oneof=new good fair poor damaged" example:"good"` // Defaults to the asset's
+	Note       string    `json:"note,omitempty" binding:"max=1000"`
+}
+
+// ReturnRequest takes an asset back from the employee holding it.
+type ReturnRequest struct {
+	Condition Condition `json:"condition" binding:"required,oneof=new good fair poor damaged" example:"fair"`
+	Status    Status    `json:"status,omitempty" binding:"omitempty,oneof=available in_repair retired lost" example:"available"` // Where the asset goes next, lost if it wasn't handed back; defaults to available
+	Note      string    `json:"note,omitempty" binding:"max=1000" example:"Scratched lid"`
+}
+
+// Filter narrows an asset listing.
+type Filter struct {
+	Status   Status
+	Category Category
+	HolderID *uint
+	Search   string // Tag, name or serial number
+}
@@ -0,0 +1,43 @@
+// prometheus/backend/internal/asset/module.go
+package asset
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// assetModule owns the asset register and who holds which asset.
+type assetModule struct {
+	handler *Handler
+}
+
+// NewModule creates the assets module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &assetModule{handler: NewHandler(svc)}
+}
+
+func (m *assetModule) Name() string { return plan.ModuleAssets }
+
+func (m *assetModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *assetModule) Models() []any {
+	return []any{&Asset{}, &Assignment{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR keeps the register and hands assets out; employees see
+// what they hold under /me.
+func (m *assetModule) RegisterRoutes(api *routing.Group) {
+	assetsAPI := api.InModule(plan.ModuleAssets)
+	assetsAPI.GET("/me/assets", routing.Authenticated(), m.handler.MyAssets)
+
+	assetsAPI.GET("/hr/assets", routing.Policy(), m.handler.ListAssets)
+	assetsAPI.POST("/hr/assets", routing.Policy(), m.handler.CreateAsset)
+	assetsAPI.GET("/hr/assets/:id", routing.Policy(), m.handler.GetAsset)
+	assetsAPI.PUT("/hr/assets/:id", routing.Policy(), m.handler.UpdateAsset)
+	assetsAPI.POST("/hr/assets/:id/assign", routing.Policy(), m.handler.AssignAsset)
+	assetsAPI.POST("/hr/assets/:id/return", routing.Policy(), m.handler.ReturnAsset)
+	assetsAPI.GET("/hr/assets/:id/history", routing.Policy(), m.handler.AssetHistory)
+	assetsAPI.GET("/hr/employees/:id/assets", routing.Policy(), m.handler.EmployeeAssets)
+}
@@ -0,0 +1,456 @@
+// prometheus/backend/internal/asset/service.go
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/offboarding"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidAsset is returned for assets, assignments and returns that fail validation.
+	ErrInvalidAsset = errors.New("invalid asset")
+	// ErrTagTaken is returned when another of the organization's assets has the tag.
+	ErrTagTaken = errors.New("another asset has this tag")
+	// ErrStatus is returned for changes the asset's status doesn't allow: only available assets are
+	// assigned, only assigned ones returned, and an assigned asset's status changes by returning it.
+	ErrStatus = errors.New("the asset's status does not allow this change")
+)
+
+// Checklist ticks off the exit checklist tasks to collect an asset when it is returned. offboarding.Service
+// implements it.
+type Checklist interface {
+	AssetReturnedTx(tx *gorm.DB, actor audit.Actor, assetID uint, note string) error
+}
+
+// Service keeps the register of company assets and who holds them. It tells the offboarding checklist
+// which assets a departing employee has to hand back. orgID scopes every call to one organization (nil =
+// platform users, outside any organization).
+type Service interface {
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Asset, int64, error)
+	Get(orgID *uint, id uint) (*Asset, error)
+	Create(actor audit.Actor, orgID *uint, req Request) (*Asset, error)
+	// Update replaces the asset's fields if it is still at expectedVersion (optimistic locking).
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Asset, error)
+	// Assign hands an available asset out to an employee.
+	Assign(actor audit.Actor, orgID *uint, id uint, req AssignRequest) (*Asset, error)
+	// Return takes an assigned asset back, recording its condition, and ticks off the tasks to collect it.
+	Return(actor audit.Actor, orgID *uint, id uint, req ReturnRequest) (*Asset, error)
+	// History lists who held an asset, latest first.
+	History(orgID *uint, id uint) ([]Assignment, error)
+	// EmployeeAssets lists the assets an employee holds, latest first; with history, also those returned.
+	EmployeeAssets(orgID *uint, employeeID uint, history bool) ([]Assignment, error)
+	employee.Resolver
+
+	offboarding.Assets
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	checklist Checklist
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Returns tick off exit checklist tasks through checklist,
+// nil when the offboarding module is disabled.
+func NewService(db *gorm.DB, employees employee.Service, checklist Checklist, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, checklist: checklist, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Asset, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Asset{}), orgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.HolderID != nil {
+		query = query.Where("holder_id = ?", *filter.HolderID)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := utils.ContainsPattern(strings.ToLower(search))
+		query = query.Where("LOWER(tag) LIKE ? OR LOWER(name) LIKE ? OR LOWER(serial_number) LIKE ?", pattern, pattern, pattern)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count assets: %w", err)
+	}
+	assets := []Asset{}
+	if err := query.Order("LOWER(tag), id").Scopes(page.Scope).Find(&assets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list assets: %w", err)
+	}
+	if err := s.nameHolders(orgID, assets); err != nil {
+		return nil, 0, err
+	}
+	return assets, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Asset, error) {
+	var asset Asset
+	if err := utils.OrgScope(s.db, orgID).First(&asset, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	assets := []Asset{asset}
+	if err := s.nameHolders(orgID, assets); err != nil {
+		return nil, err
+	}
+	return &assets[0], nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Asset, error) {
+	asset := Asset{OrganizationID: orgID}
+	if err := applyAsset(&asset, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTag(tx, orgID, 0, asset.Tag); err != nil {
+			return err
+		}
+		if err := tx.Create(&asset).Error; err != nil {
+			return fmt.Errorf("failed to create asset: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "asset.create", EntityType: "asset", EntityID: fmt.Sprintf("%d", asset.ID), After: asset,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Asset, error) {
+	var updated Asset
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockAsset(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		asset := *before
+		if err := applyAsset(&asset, req); err != nil {
+			return err
+		}
+		if before.Status == StatusAssigned {
+			if req.Status != "" {
+				return ErrStatus
+			}
+			asset.Status = StatusAssigned
+		}
+		if err := checkTag(tx, orgID, id, asset.Tag); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Asset{}, id, expectedVersion, map[string]interface{}{
+			"tag":           asset.Tag,
+			"name":          asset.Name,
+			"category":      asset.Category,
+			"serial_number": asset.SerialNumber,
+			"status":        asset.Status,
+			"condition":     asset.Condition,
+			"purchased_on":  asset.PurchasedOn,
+			"notes":         asset.Notes,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload asset %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "asset.update", EntityType: "asset", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	assets := []Asset{updated}
+	if err := s.nameHolders(orgID, assets); err != nil {
+		return nil, err
+	}
+	return &assets[0], nil
+}
+
+func (s *service) Assign(actor audit.Actor, orgID *uint, id uint, req AssignRequest) (*Asset, error) {
+	var asset *Asset
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if asset, err = lockAsset(tx, orgID, id); err != nil {
+			return err
+		}
+		if asset.Status != StatusAvailable {
+			return ErrStatus
+		}
+		userID, err := employeeUser(tx, orgID, req.EmployeeID)
+		if err != nil {
+			return err
+		}
+		before := *asset
+		condition := req.Condition
+		if condition == "" {
+			condition = asset.Condition
+		}
+		assignment := Assignment{
+			OrganizationID: orgID, AssetID: id, EmployeeID: req.EmployeeID, AssignedAt: clock.Now().UTC(),
+			AssignedBy: actor.UserID, ConditionOut: condition, Note: strings.TrimSpace(req.Note),
+		}
+		if err := tx.Create(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to assign asset %d: %w", id, err)
+		}
+		if err := tx.Model(&Asset{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": StatusAssigned, "holder_id": req.EmployeeID, "condition": condition, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to assign asset %d: %w", id, err)
+		}
+		asset.Status, asset.HolderID, asset.Condition = StatusAssigned, &req.EmployeeID, condition
+		asset.Version++
+		if err := notification.CreateTx(tx, notification.Notice{
+			UserID:         userID,
+			OrganizationID: orgID,
+			Category:       "assets",
+			Subject:        fmt.Sprintf("%s was assigned to you", asset.Label()),
+			Link:           "/me/assets",
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "asset.assign", EntityType: "asset", EntityID: fmt.Sprintf("%d", id), Before: before, After: assignment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	assets := []Asset{*asset}
+	if err := s.nameHolders(orgID, assets); err != nil {
+		return nil, err
+	}
+	return &assets[0], nil
+}
+
+// Return closes the open assignment. An exit checklist collecting the asset is ticked off even when the
+// asset comes back lost: the task is settled either way, with the return note saying how.
+func (s *service) Return(actor audit.Actor, orgID *uint, id uint, req ReturnRequest) (*Asset, error) {
+	status := req.Status
+	if status == "" {
+		status = StatusAvailable
+	}
+	var asset *Asset
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if asset, err = lockAsset(tx, orgID, id); err != nil {
+			return err
+		}
+		if asset.Status != StatusAssigned {
+			return ErrStatus
+		}
+		before := *asset
+		var assignment Assignment
+		if err := tx.Where("asset_id = ? AND returned_at IS NULL", id).Order("assigned_at DESC").First(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to find the open assignment of asset %d: %w", id, err)
+		}
+		now := clock.Now().UTC()
+		note := strings.TrimSpace(req.Note)
+		if err := tx.Model(&Assignment{}).Where("id = ?", assignment.ID).Updates(map[string]interface{}{
+			"returned_at": now, "returned_to": actor.UserID, "condition_in": req.Condition, "return_note": note,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to return asset %d: %w", id, err)
+		}
+		if err := tx.Model(&Asset{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": status, "holder_id": nil, "condition": req.Condition, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to return asset %d: %w", id, err)
+		}
+		asset.Status, asset.HolderID, asset.Condition = status, nil, req.Condition
+		asset.Version++
+		if s.checklist != nil {
+			if err := s.checklist.AssetReturnedTx(tx, actor, id, returnNote(status, req.Condition, note)); err != nil {
+				return err
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "asset.return", EntityType: "asset", EntityID: fmt.Sprintf("%d", id), Before: before,
+			After: map[string]interface{}{"assignment_id": assignment.ID, "employee_id": assignment.EmployeeID, "status": status, "condition": req.Condition},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (s *service) History(orgID *uint, id uint) ([]Assignment, error) {
+	if _, err := s.Get(orgID, id); err != nil {
+		return nil, err
+	}
+	assignments := []Assignment{}
+	if err := s.db.Where("asset_id = ?", id).Order("assigned_at DESC, id DESC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list the assignments of asset %d: %w", id, err)
+	}
+	if err := s.nameEmployees(orgID, assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (s *service) EmployeeAssets(orgID *uint, employeeID uint, history bool) ([]Assignment, error) {
+	if _, err := s.employees.Get(orgID, employeeID); err != nil {
+		return nil, err
+	}
+	query := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID)
+	if !history {
+		query = query.Where("returned_at IS NULL")
+	}
+	assignments := []Assignment{}
+	if err := query.Preload("Asset").Order("assigned_at DESC, id DESC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list the assets of employee %d: %w", employeeID, err)
+	}
+	return assignments, nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+// HeldAssets implements offboarding.Assets from the assets the employee holds, so the exit checklist
+// collects each of them.
+func (s *service) HeldAssets(tx *gorm.DB, orgID *uint, employeeID uint) ([]offboarding.HeldAsset, error) {
+	var assets []Asset
+	if err := utils.OrgScope(tx, orgID).Where("holder_id = ? AND status = ?", employeeID, StatusAssigned).
+		Order("LOWER(tag), id").Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the assets of employee %d: %w", employeeID, err)
+	}
+	held := make([]offboarding.HeldAsset, len(assets))
+	for i, a := range assets {
+		held[i] = offboarding.HeldAsset{ID: a.ID, Label: a.Label()}
+	}
+	return held, nil
+}
+
+// nameHolders fills in the names of the employees holding the assets.
+func (s *service) nameHolders(orgID *uint, assets []Asset) error {
+	var ids []uint
+	for _, a := range assets {
+		if a.HolderID != nil {
+			ids = append(ids, *a.HolderID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range assets {
+		if assets[i].HolderID != nil {
+			assets[i].HolderName = names[*assets[i].HolderID].Text
+		}
+	}
+	return nil
+}
+
+// nameEmployees fills in the names of the employees who held an asset.
+func (s *service) nameEmployees(orgID *uint, assignments []Assignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(assignments))
+	for i, a := range assignments {
+		ids[i] = a.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range assignments {
+		assignments[i].EmployeeName = names[assignments[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// applyAsset copies a request onto an asset. The status only applies to assets not assigned.
+func applyAsset(asset *Asset, req Request) error {
+	asset.Tag, asset.Name = strings.TrimSpace(req.Tag), strings.TrimSpace(req.Name)
+	if asset.Tag == "" || asset.Name == "" {
+		return fmt.Errorf("%w: tag and name must not be blank", ErrInvalidAsset)
+	}
+	asset.Category, asset.SerialNumber = req.Category, strings.TrimSpace(req.SerialNumber)
+	asset.Condition, asset.Notes = req.Condition, req.Notes
+	asset.Status = req.Status
+	if asset.Status == "" {
+		asset.Status = StatusAvailable
+	}
+	asset.PurchasedOn = nil
+	if req.PurchasedOn != "" {
+		purchasedOn, err := time.Parse("2006-01-02", req.PurchasedOn)
+		if err != nil {
+			return fmt.Errorf("%w: purchased_on must be a date", ErrInvalidAsset)
+		}
+		if purchasedOn.After(today()) {
+			return fmt.Errorf("%w: purchased_on must not be in the future", ErrInvalidAsset)
+		}
+		asset.PurchasedOn = &purchasedOn
+	}
+	return nil
+}
+
+// checkTag checks no other asset of the organization has the tag, ignoring case.
+func checkTag(tx *gorm.DB, orgID *uint, id uint, tag string) error {
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Asset{}).Where("LOWER(tag) = LOWER(?) AND id <> ?", tag, id), orgID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check tag: %w", err)
+	}
+	if count > 0 {
+		return ErrTagTaken
+	}
+	return nil
+}
+
+// employeeUser returns the user of one of the organization's current employees.
+func employeeUser(tx *gorm.DB, orgID *uint, employeeID uint) (uint, error) {
+	var rows []struct{ UserID uint }
+	if err := utils.OrgScope(tx.Table("employees").Select("user_id").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).
+		Scan(&rows).Error; err != nil {
+		return 0, fmt.Errorf("failed to load employee: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("%w: unknown employee", ErrInvalidAsset)
+	}
+	return rows[0].UserID, nil
+}
+
+// returnNote tells the exit checklist how the asset came back.
+func returnNote(status Status, condition Condition, note string) string {
+	summary := fmt.Sprintf("Returned in %s condition", condition)
+	if status == StatusLost {
+		summary = "Reported lost"
+	}
+	if note == "" {
+		return summary
+	}
+	return summary + ": " + note
+}
+
+func lockAsset(tx *gorm.DB, orgID *uint, id uint) (*Asset, error) {
+	var asset Asset
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&asset, id).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// today returns the current date at midnight UTC.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
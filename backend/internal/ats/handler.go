@@ -0,0 +1,429 @@
+// prometheus/backend/internal/ats/handler.go
+package ats
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/position"
+	"prometheus/backend/internal/requisition"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hr is the viewer of /hr routes, which see every application in full.
+var hr = Viewer{HR: true}
+
+// Handler handles HTTP requests for applicant tracking.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Candidates returns the organization's candidates, newest first.
+// @Summary List candidates
+// @Tags Applicant tracking
+// @Produce json
+// @Param search query string false "Case-insensitive match on name or email"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /hr/candidates [get]
+func (h *Handler) Candidates(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	candidates, total, err := h.service.Candidates(utils.OrganizationFromContext(c), CandidateFilter{Search: c.Query("search")}, page)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Candidates fetched successfully", page.Response(candidates, total))
+}
+
+// Candidate returns a candidate.
+// @Summary Get a candidate
+// @Tags Applicant tracking
+// @Produce json
+// @Param id path int true "Candidate ID"
+// @Success 200 {object} Candidate
+// @Failure 404 {object} utils.ErrorResponse "Candidate not found"
+// @Router /hr/candidates/{id} [get]
+func (h *Handler) Candidate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	candidate, err := h.service.Candidate(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, candidate.UpdatedAt, candidate.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Candidate fetched successfully", candidate)
+}
+
+// CreateCandidate records a candidate.
+// @Summary Create a candidate
+// @Description Email addresses are unique per organization: a returning candidate applies again under their
+// @Description existing record.
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param candidate body CandidateRequest true "Candidate"
+// @Success 201 {object} Candidate
+// @Failure 400 {object} utils.ErrorResponse "Invalid candidate"
+// @Failure 409 {object} utils.ErrorResponse "Email address already used by another candidate"
+// @Router /hr/candidates [post]
+func (h *Handler) CreateCandidate(c *gin.Context) {
+	var req CandidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	candidate, err := h.service.CreateCandidate(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, candidate.UpdatedAt, candidate.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Candidate created successfully", candidate)
+}
+
+// UpdateCandidate replaces a candidate's details.
+// @Summary Update a candidate
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param id path int true "Candidate ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param candidate body CandidateRequest true "Candidate"
+// @Success 200 {object} Candidate
+// @Failure 400 {object} utils.ErrorResponse "Invalid candidate"
+// @Failure 404 {object} utils.ErrorResponse "Candidate not found"
+// @Failure 409 {object} utils.ErrorResponse "Email address already used by another candidate"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/candidates/{id} [put]
+func (h *Handler) UpdateCandidate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CandidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Candidate(orgID, id)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	candidate, err := h.service.UpdateCandidate(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, candidate.UpdatedAt, candidate.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Candidate updated successfully", candidate)
+}
+
+// DeleteCandidate removes a candidate with their applications and interview feedback.
+// @Summary Delete a candidate
+// @Tags Applicant tracking
+// @Param id path int true "Candidate ID"
+// @Success 204 "Deleted"
+// @Failure 404 {object} utils.ErrorResponse "Candidate not found"
+// @Failure 409 {object} utils.ErrorResponse "Candidate was hired"
+// @Router /hr/candidates/{id} [delete]
+func (h *Handler) DeleteCandidate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteCandidate(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendATSError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Applications returns the organization's applications, most recently moved first.
+// @Summary List applications
+// @Tags Applicant tracking
+// @Produce json
+// @Param stage query string false "Stage" Enums(applied, interview, offer, hired, rejected, withdrawn)
+// @Param opening_id query int false "Job opening ID"
+// @Param candidate_id query int false "Candidate ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/applications [get]
+func (h *Handler) Applications(c *gin.Context) {
+	filter := ApplicationFilter{Stage: Stage(c.Query("stage"))}
+	switch filter.Stage {
+	case "", StageApplied, StageInterview, StageOffer, StageHired, StageRejected, StageWithdrawn:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid stage parameter")
+		return
+	}
+	var ok bool
+	if filter.OpeningID, ok = utils.OptionalUintQuery(c, "opening_id"); !ok {
+		return
+	}
+	if filter.CandidateID, ok = utils.OptionalUintQuery(c, "candidate_id"); !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	applications, total, err := h.service.Applications(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Applications fetched successfully", page.Response(applications, total))
+}
+
+// Application returns an application with its candidate, interviewers and feedback.
+// @Summary Get an application
+// @Tags Applicant tracking
+// @Produce json
+// @Param id path int true "Application ID"
+// @Success 200 {object} Application
+// @Failure 404 {object} utils.ErrorResponse "Application not found"
+// @Router /hr/applications/{id} [get]
+func (h *Handler) Application(c *gin.Context) {
+	h.application(c, hr)
+}
+
+// Interview returns an application the caller interviews for, with their own feedback only.
+// @Summary Get an application to interview for
+// @Tags Applicant tracking
+// @Produce json
+// @Param id path int true "Application ID"
+// @Success 200 {object} Application
+// @Failure 404 {object} utils.ErrorResponse "Application not found or not assigned to the caller"
+// @Router /me/interviews/{id} [get]
+func (h *Handler) Interview(c *gin.Context) {
+	h.application(c, Viewer{UserID: c.GetUint("userID")})
+}
+
+func (h *Handler) application(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	application, err := h.service.Application(utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Application fetched successfully", application)
+}
+
+// Interviews returns the open applications the caller interviews for.
+// @Summary List my interviews
+// @Tags Applicant tracking
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /me/interviews [get]
+func (h *Handler) Interviews(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	applications, total, err := h.service.Interviews(utils.OrganizationFromContext(c), c.GetUint("userID"), page)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Interviews fetched successfully", page.Response(applications, total))
+}
+
+// Apply applies a candidate to an open job opening.
+// @Summary Create an application
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param application body ApplicationRequest true "Application"
+// @Success 201 {object} Application
+// @Failure 400 {object} utils.ErrorResponse "Unknown candidate, or job opening not open"
+// @Failure 409 {object} utils.ErrorResponse "Candidate already applied to the job opening"
+// @Router /hr/applications [post]
+func (h *Handler) Apply(c *gin.Context) {
+	var req ApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	application, err := h.service.Apply(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Application created successfully", application)
+}
+
+// Move advances an application to the next stage, or rejects or withdraws it.
+// @Summary Move an application
+// @Description Applications advance one stage at a time, from applied to interview to offer; offers are
+// @Description accepted by hiring the candidate. Open applications can be rejected, with a reason, or withdrawn.
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param stage body StageRequest true "Stage"
+// @Success 200 {object} Application
+// @Failure 400 {object} utils.ErrorResponse "Rejection without a reason"
+// @Failure 404 {object} utils.ErrorResponse "Application not found"
+// @Failure 409 {object} utils.ErrorResponse "Stage can't be reached from the current one"
+// @Router /hr/applications/{id}/stage [post]
+func (h *Handler) Move(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req StageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	application, err := h.service.Move(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Application moved successfully", application)
+}
+
+// SetInterviewers replaces the interviewers of an open application.
+// @Summary Set the interviewers of an application
+// @Description Newly assigned interviewers are notified. Feedback given by removed interviewers is kept.
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param interviewers body InterviewersRequest true "Interviewers"
+// @Success 200 {object} Application
+// @Failure 400 {object} utils.ErrorResponse "Unknown or inactive users"
+// @Failure 404 {object} utils.ErrorResponse "Application not found"
+// @Failure 409 {object} utils.ErrorResponse "Application closed"
+// @Router /hr/applications/{id}/interviewers [put]
+func (h *Handler) SetInterviewers(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req InterviewersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	application, err := h.service.SetInterviewers(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Interviewers updated successfully", application)
+}
+
+// GiveFeedback records or revises the caller's interview feedback.
+// @Summary Give interview feedback
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param feedback body FeedbackRequest true "Feedback"
+// @Success 200 {object} Application
+// @Failure 403 {object} utils.ErrorResponse "Caller not an interviewer of the application"
+// @Failure 404 {object} utils.ErrorResponse "Application not found"
+// @Failure 409 {object} utils.ErrorResponse "Application closed"
+// @Router /me/interviews/{id}/feedback [put]
+func (h *Handler) GiveFeedback(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	application, err := h.service.GiveFeedback(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Feedback saved successfully", application)
+}
+
+// Hire converts the candidate of an application at the offer stage into an employee.
+// @Summary Hire a candidate
+// @Description In one transaction: creates the user with the staff role and a temporary password, creates the
+// @Description employee record, links the hire to the requisition the job opening recruits against, and queues
+// @Description an invitation email with the sign-in details. Fails without changes if any step fails, e.g.
+// @Description when the plan's employee limit is reached.
+// @Tags Applicant tracking
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param hire body HireRequest true "Hire"
+// @Success 200 {object} Application
+// @Failure 400 {object} utils.ErrorResponse "Invalid employee details, or requisition no longer approved"
+// @Failure 402 {object} utils.ErrorResponse "Employee limit of the plan reached"
+// @Failure 404 {object} utils.ErrorResponse "Application not found"
+// @Failure 409 {object} utils.ErrorResponse "No offer yet, username or email taken, or employee number taken"
+// @Router /hr/applications/{id}/hire [post]
+func (h *Handler) Hire(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req HireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	application, err := h.service.Hire(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendATSError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Candidate hired successfully", application)
+}
+
+func sendATSError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidCandidate), errors.Is(err, ErrInvalidApplication), errors.Is(err, employee.ErrInvalidEmployee),
+		errors.Is(err, requisition.ErrInvalidRequisition), errors.Is(err, position.ErrInvalidPosition):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, ErrNotInterviewer):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrCandidateExists), errors.Is(err, ErrDuplicateApplication), errors.Is(err, ErrStage),
+		errors.Is(err, ErrHired), errors.Is(err, auth.ErrUserExists), errors.Is(err, employee.ErrAlreadyEmployee),
+		errors.Is(err, employee.ErrNumberTaken), errors.Is(err, position.ErrNoVacancy), errors.Is(err, position.ErrAlreadyPlaced):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
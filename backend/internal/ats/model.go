@@ -0,0 +1,173 @@
+// prometheus/backend/internal/ats/model.go
+package ats
+
+import (
+	"prometheus/backend/internal/employee"
+	"time"
+)
+
+// Stage is where an application is in the hiring pipeline.
+type Stage string
+
+const (
+	StageApplied   Stage = "applied"
+	StageInterview Stage = "interview"
+	StageOffer     Stage = "offer"
+	StageHired     Stage = "hired"     // The candidate is an employee now; final
+	StageRejected  Stage = "rejected"  // Turned down by HR; final
+	StageWithdrawn Stage = "withdrawn" // Withdrawn by the candidate; final
+)
+
+// Final reports whether an application in the stage is closed.
+func (s Stage) Final() bool {
+	return s == StageHired || s == StageRejected || s == StageWithdrawn
+}
+
+// Recommendation is an interviewer's verdict.
+type Recommendation string
+
+const (
+	StrongNo  Recommendation = "strong_no"
+	No        Recommendation = "no"
+	Yes       Recommendation = "yes"
+	StrongYes Recommendation = "strong_yes"
+)
+
+// Candidate is a person HR recruits, with the contact details they applied with. A candidate may apply to
+// several job openings.
+type Candidate struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"21"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	FirstName      string    `gorm:"type:varchar(100);not null" json:"first_name" example:"Jonas"`
+	LastName       string    `gorm:"type:varchar(100);not null" json:"last_name" example:"Weber"`
+	Email          string    `gorm:"type:varchar(100);not null;index" json:"email" example:"jonas.weber@example.com"`
+	Phone          string    `gorm:"type:varchar(50)" json:"phone,omitempty" example:"+49 30 1234567"`
+	Source         string    `gorm:"type:varchar(100)" json:"source,omitempty" example:"Referral"`
+	Notes          string    `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy      *uint     `json:"created_by,omitempty" example:"4"`              // User ID
+	Version        uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Application is a candidate applying to a job opening, moving through the stages from applied to hired.
+// Hiring converts the candidate into an employee with a user account.
+type Application struct {
+	ID              uint          `gorm:"primaryKey" json:"id" example:"34"`
+	OrganizationID  *uint         `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CandidateID     uint          `gorm:"not null;uniqueIndex:idx_application_candidate_opening" json:"candidate_id" example:"21"`
+	Candidate       *Candidate    `json:"candidate,omitempty"`
+	OpeningID       uint          `gorm:"not null;uniqueIndex:idx_application_candidate_opening;index" json:"opening_id" example:"5"`
+	OpeningTitle    string        `gorm:"-" json:"opening_title,omitempty" example:"Payroll Specialist"`
+	Stage           Stage         `gorm:"type:varchar(20);not null;index" json:"stage" example:"interview"`
+	StageChangedAt  time.Time     `json:"stage_changed_at"`
+	RejectionReason string        `gorm:"type:varchar(1000)" json:"rejection_reason,omitempty" example:"Salary expectations above the budget"`
+	EmployeeID      *uint         `json:"employee_id,omitempty" example:"41"` // Once hired
+	HiredAt         *time.Time    `json:"hired_at,omitempty"`
+	Interviewers    []Interviewer `gorm:"foreignKey:ApplicationID" json:"interviewers,omitempty"`
+	Feedback        []Feedback    `gorm:"foreignKey:ApplicationID" json:"feedback,omitempty"`
+	CreatedBy       *uint         `json:"created_by,omitempty" example:"4"`              // User ID
+	Version         uint          `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// TableName keeps applications next to candidates.
+func (Application) TableName() string { return "candidate_applications" }
+
+// Interviewer is a user asked to interview the candidate of an application and give feedback.
+type Interviewer struct {
+	ID            uint      `gorm:"primaryKey" json:"id" example:"12"`
+	ApplicationID uint      `gorm:"not null;uniqueIndex:idx_application_interviewer" json:"application_id" example:"34"`
+	UserID        uint      `gorm:"not null;uniqueIndex:idx_application_interviewer;index" json:"user_id" example:"9"`
+	AssignedBy    *uint     `json:"assigned_by,omitempty" example:"4"` // User ID
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName keeps interviewers next to applications.
+func (Interviewer) TableName() string { return "application_interviewers" }
+
+// Feedback is an interviewer's assessment of a candidate. Each interviewer gives one per application and
+// may revise it until the application is closed.
+type Feedback struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"51"`
+	ApplicationID  uint           `gorm:"not null;uniqueIndex:idx_application_feedback" json:"application_id" example:"34"`
+	InterviewerID  uint           `gorm:"not null;uniqueIndex:idx_application_feedback" json:"interviewer_id" example:"9"` // User ID
+	Stage          Stage          `gorm:"type:varchar(20);not null" json:"stage" example:"interview"`                      // Of the application when last given
+	Rating         int            `gorm:"not null" json:"rating" example:"4"`                                              // 1 to 5
+	Recommendation Recommendation `gorm:"type:varchar(20);not null" json:"recommendation" example:"yes"`
+	Comments       string         `gorm:"type:text" json:"comments,omitempty" example:"Strong on payroll regulations, less so on tooling"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName keeps feedback next to applications.
+func (Feedback) TableName() string { return "application_feedback" }
+
+// Viewer is who fetches an application: HR sees everything, interviewers the applications they are
+// assigned to, with their own feedback only.
+type Viewer struct {
+	UserID uint
+	HR     bool
+}
+
+// CandidateRequest creates a candidate or replaces their details.
+type CandidateRequest struct {
+	FirstName string `json:"first_name" binding:"required,max=100" example:"Jonas"`
+	LastName  string `json:"last_name" binding:"required,max=100" example:"Weber"`
+	Email     string `json:"email" binding:"required,email,max=100" example:"jonas.weber@example.com"`
+	Phone     string `json:"phone,omitempty" binding:"max=50" example:"+49 30 1234567"`
+	Source    string `json:"source,omitempty" binding:"max=100" example:"Referral"`
+	Notes     string `json:"notes,omitempty" binding:"max=5000"`
+}
+
+// ApplicationRequest applies a candidate to an open job opening.
+type ApplicationRequest struct {
+	CandidateID uint `json:"candidate_id" binding:"required" example:"21"`
+	OpeningID   uint `json:"opening_id" binding:"required" example:"5"`
+}
+
+// StageRequest moves an application to the next stage, or closes it. Hiring goes through HireRequest.
+type StageRequest struct {
+	Stage  Stage  `json:"stage" binding:"required,oneof=interview offer rejected withdrawn" example:"interview"`
+	Reason string `json:"reason,omitempty" binding:"max=1000" example:"Salary expectations above the budget"` // Required to reject
+}
+
+// InterviewersRequest replaces the interviewers of an application.
+type InterviewersRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"max=20" example:"9,12"`
+}
+
+// FeedbackRequest gives or revises an interviewer's feedback.
+type FeedbackRequest struct {
+	Rating         int            `json:"rating" binding:"required,min=1,max=5" example:"4"`
+	Recommendation Recommendation `json:"recommendation" binding:"required,oneof=strong_no no yes strong_yes" example:"yes"`
+	Comments       string         `json:"comments,omitempty" binding:"max=5000" example:"Strong on payroll regulations, less so on tooling"`
+}
+
+// HireRequest converts the candidate of an application at the offer stage into an employee with a user
+// account. The user is invited by email with a temporary password.
+type HireRequest struct {
+	Username       string                  `json:"username" binding:"required,min=3,max=100" example:"jweber"`
+	Email          string                  `json:"email,omitempty" binding:"omitempty,email,max=100" example:"jweber@acme.example"` // Work email; defaults to the candidate's
+	EmployeeNumber string                  `json:"employee_number" binding:"required,max=50" example:"E-1088"`
+	JobTitle       string                  `json:"job_title,omitempty" binding:"max=150" example:"Payroll Specialist"`               // Defaults to the opening's title
+	HireDate       string                  `json:"hire_date,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-11-02"` // Defaults to today
+	EmploymentType employee.EmploymentType `json:"employment_type" binding:"required,oneof=full_time part_time contractor intern temporary" example:"full_time"`
+	ManagerID      *uint                   `json:"manager_id,omitempty" example:"3"`
+	DivisionID     *uint                   `json:"division_id,omitempty" example:"2"`     // Defaults to the opening's division
+	Names          []employee.Name         `json:"names,omitempty" binding:"max=20,dive"` // Defaults to the candidate's name as the legal name in Latin script
+	Pronouns       string                  `json:"pronouns,omitempty" binding:"max=50" example:"he/him"`
+}
+
+// CandidateFilter narrows a candidate listing.
+type CandidateFilter struct {
+	Search string
+}
+
+// ApplicationFilter narrows an application listing.
+type ApplicationFilter struct {
+	Stage       Stage
+	OpeningID   *uint
+	CandidateID *uint
+}
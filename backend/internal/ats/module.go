@@ -0,0 +1,52 @@
+// prometheus/backend/internal/ats/module.go
+package ats
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the applicant tracking module.
+const ModuleName = "applicant-tracking"
+
+// atsModule owns candidates, their applications and interview feedback.
+type atsModule struct {
+	handler *Handler
+}
+
+// NewModule creates the applicant tracking module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &atsModule{handler: NewHandler(svc)}
+}
+
+func (m *atsModule) Name() string { return ModuleName }
+
+func (m *atsModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *atsModule) Models() []any {
+	return []any{&Candidate{}, &Application{}, &Interviewer{}, &Feedback{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR runs the pipeline and hires; any user may be asked
+// to interview and give feedback.
+func (m *atsModule) RegisterRoutes(api *routing.Group) {
+	recruitingAPI := api.InModule(plan.ModuleATS)
+	recruitingAPI.GET("/me/interviews", routing.Authenticated(), m.handler.Interviews)
+	recruitingAPI.GET("/me/interviews/:id", routing.Authenticated(), m.handler.Interview)
+	recruitingAPI.PUT("/me/interviews/:id/feedback", routing.Authenticated(), m.handler.GiveFeedback)
+
+	recruitingAPI.GET("/hr/candidates", routing.Policy(), m.handler.Candidates)
+	recruitingAPI.POST("/hr/candidates", routing.Policy(), m.handler.CreateCandidate)
+	recruitingAPI.GET("/hr/candidates/:id", routing.Policy(), m.handler.Candidate)
+	recruitingAPI.PUT("/hr/candidates/:id", routing.Policy(), m.handler.UpdateCandidate)
+	recruitingAPI.DELETE("/hr/candidates/:id", routing.Policy(), m.handler.DeleteCandidate)
+
+	recruitingAPI.GET("/hr/applications", routing.Policy(), m.handler.Applications)
+	recruitingAPI.POST("/hr/applications", routing.Policy(), m.handler.Apply)
+	recruitingAPI.GET("/hr/applications/:id", routing.Policy(), m.handler.Application)
+	recruitingAPI.POST("/hr/applications/:id/stage", routing.Policy(), m.handler.Move)
+	recruitingAPI.PUT("/hr/applications/:id/interviewers", routing.Policy(), m.handler.SetInterviewers)
+	recruitingAPI.POST("/hr/applications/:id/hire", routing.Policy(), m.handler.Hire)
+}
@@ -0,0 +1,709 @@
+// prometheus/backend/internal/ats/service.go
+package ats
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/mail"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/opening"
+	"prometheus/backend/internal/outbox"
+	"prometheus/backend/internal/requisition"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// staffRole is the baseline role of hired candidates.
+const staffRole = "staff"
+
+var (
+	// ErrInvalidCandidate is returned for candidates that fail validation.
+	ErrInvalidCandidate = errors.New("invalid candidate")
+	// ErrInvalidApplication is returned for applications, stage changes and hires that fail validation.
+	ErrInvalidApplication = errors.New("invalid application")
+	// ErrCandidateExists is returned when another candidate of the organization has the email address.
+	ErrCandidateExists = errors.New("a candidate with this email address already exists")
+	// ErrDuplicateApplication is returned when a candidate applies to a job opening twice.
+	ErrDuplicateApplication = errors.New("the candidate already applied to this job opening")
+	// ErrStage is returned when an application can't be changed in its current stage.
+	ErrStage = errors.New("the application can't be changed in its current stage")
+	// ErrNotInterviewer is returned when feedback comes from someone not interviewing the candidate.
+	ErrNotInterviewer = errors.New("only the application's interviewers can give feedback")
+	// ErrHired is returned when deleting a candidate who was hired; their employee record keeps the history.
+	ErrHired = errors.New("the candidate was hired and can't be deleted")
+)
+
+// next is the stage each open stage advances to. Offers are accepted through Hire.
+var next = map[Stage]Stage{
+	StageApplied:   StageInterview,
+	StageInterview: StageOffer,
+}
+
+// Service tracks applicants: candidates, their applications to open job openings moving from applied
+// through interview and offer to hired, and interviewer feedback. Hiring creates the employee record
+// and an invited user in one transaction. orgID scopes every call to one organization (nil = platform
+// users, outside any organization).
+type Service interface {
+	// Candidates returns the candidates, newest first.
+	Candidates(orgID *uint, filter CandidateFilter, page utils.Pagination) ([]Candidate, int64, error)
+	Candidate(orgID *uint, id uint) (*Candidate, error)
+	CreateCandidate(actor audit.Actor, orgID *uint, req CandidateRequest) (*Candidate, error)
+	UpdateCandidate(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CandidateRequest) (*Candidate, error)
+	// DeleteCandidate removes a candidate with their applications and feedback, unless they were hired.
+	DeleteCandidate(actor audit.Actor, orgID *uint, id uint) error
+
+	// Applications returns the applications with their candidates, most recently moved first.
+	Applications(orgID *uint, filter ApplicationFilter, page utils.Pagination) ([]Application, int64, error)
+	// Application returns an application with its interviewers and feedback, as viewer may see it;
+	// gorm.ErrRecordNotFound for interviewers not assigned to it.
+	Application(orgID *uint, viewer Viewer, id uint) (*Application, error)
+	// Interviews returns the open applications the user interviews for, as they may see them.
+	Interviews(orgID *uint, userID uint, page utils.Pagination) ([]Application, int64, error)
+	// Apply applies a candidate to an open job opening.
+	Apply(actor audit.Actor, orgID *uint, req ApplicationRequest) (*Application, error)
+	// Move advances an application one stage, or rejects or withdraws it.
+	Move(actor audit.Actor, orgID *uint, id uint, req StageRequest) (*Application, error)
+	// SetInterviewers replaces the interviewers of an open application, notifying those newly assigned.
+	SetInterviewers(actor audit.Actor, orgID *uint, id uint, req InterviewersRequest) (*Application, error)
+	// GiveFeedback records or revises the caller's feedback as an interviewer of an open application.
+	GiveFeedback(actor audit.Actor, orgID *uint, id uint, req FeedbackRequest) (*Application, error)
+	// Hire converts the candidate of an application at the offer stage into an employee with a user account
+	// invited by email, filling the requisition the job opening recruits against, if any.
+	Hire(actor audit.Actor, orgID *uint, id uint, req HireRequest) (*Application, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db           *gorm.DB
+	employees    employee.Service
+	requisitions requisition.Service
+	limits       auth.EmployeeLimiter
+	outbox       *outbox.Outbox
+	templates    mail.TemplateService
+	auditor      audit.Service
+	appBaseURL   string
+}
+
+// NewService creates a new instance of Service. Hires are checked against the organization's plan through
+// limits, and invitations are queued on messages with the InvitationTemplate, linking to appBaseURL.
+func NewService(db *gorm.DB, employees employee.Service, requisitions requisition.Service, limits auth.EmployeeLimiter, messages *outbox.Outbox, templates mail.TemplateService, auditor audit.Service, appBaseURL string) Service {
+	return &service{
+		db: db, employees: employees, requisitions: requisitions, limits: limits,
+		outbox: messages, templates: templates, auditor: auditor, appBaseURL: appBaseURL,
+	}
+}
+
+func (s *service) Candidates(orgID *uint, filter CandidateFilter, page utils.Pagination) ([]Candidate, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Candidate{}), orgID)
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(first_name || ' ' || last_name) LIKE ? OR LOWER(email) LIKE ?", like, like)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count candidates: %w", err)
+	}
+	candidates := []Candidate{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&candidates).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list candidates: %w", err)
+	}
+	return candidates, total, nil
+}
+
+func (s *service) Candidate(orgID *uint, id uint) (*Candidate, error) {
+	var candidate Candidate
+	if err := utils.OrgScope(s.db, orgID).First(&candidate, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &candidate, nil
+}
+
+func (s *service) CreateCandidate(actor audit.Actor, orgID *uint, req CandidateRequest) (*Candidate, error) {
+	candidate := Candidate{OrganizationID: orgID, CreatedBy: actor.UserID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyCandidate(tx, orgID, &candidate, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&candidate).Error; err != nil {
+			return fmt.Errorf("failed to create candidate: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "candidate.create", EntityType: "candidate", EntityID: fmt.Sprintf("%d", candidate.ID), After: candidate,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Candidate(orgID, candidate.ID)
+}
+
+// UpdateCandidate replaces the candidate's details if still at expectedVersion (optimistic locking).
+func (s *service) UpdateCandidate(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CandidateRequest) (*Candidate, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Candidate
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		candidate := before
+		if err := applyCandidate(tx, orgID, &candidate, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Candidate{}, id, expectedVersion, map[string]interface{}{
+			"first_name": candidate.FirstName,
+			"last_name":  candidate.LastName,
+			"email":      candidate.Email,
+			"phone":      candidate.Phone,
+			"source":     candidate.Source,
+			"notes":      candidate.Notes,
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "candidate.update", EntityType: "candidate", EntityID: fmt.Sprintf("%d", id), Before: before, After: candidate,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Candidate(orgID, id)
+}
+
+func (s *service) DeleteCandidate(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var candidate Candidate
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&candidate, id).Error; err != nil {
+			return err
+		}
+		var applicationIDs []uint
+		if err := tx.Model(&Application{}).Where("candidate_id = ?", id).Pluck("id", &applicationIDs).Error; err != nil {
+			return fmt.Errorf("failed to load applications: %w", err)
+		}
+		var hired int64
+		if err := tx.Model(&Application{}).Where("candidate_id = ? AND stage = ?", id, StageHired).Count(&hired).Error; err != nil {
+			return fmt.Errorf("failed to check hires: %w", err)
+		}
+		if hired > 0 {
+			return ErrHired
+		}
+		if len(applicationIDs) > 0 {
+			if err := tx.Where("application_id IN ?", applicationIDs).Delete(&Feedback{}).Error; err != nil {
+				return fmt.Errorf("failed to delete feedback: %w", err)
+			}
+			if err := tx.Where("application_id IN ?", applicationIDs).Delete(&Interviewer{}).Error; err != nil {
+				return fmt.Errorf("failed to delete interviewers: %w", err)
+			}
+			if err := tx.Where("id IN ?", applicationIDs).Delete(&Application{}).Error; err != nil {
+				return fmt.Errorf("failed to delete applications: %w", err)
+			}
+		}
+		if err := tx.Delete(&candidate).Error; err != nil {
+			return fmt.Errorf("failed to delete candidate: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "candidate.delete", EntityType: "candidate", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"email": candidate.Email, "applications": applicationIDs},
+		})
+	})
+}
+
+func (s *service) Applications(orgID *uint, filter ApplicationFilter, page utils.Pagination) ([]Application, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Application{}), orgID)
+	if filter.Stage != "" {
+		query = query.Where("stage = ?", filter.Stage)
+	}
+	if filter.OpeningID != nil {
+		query = query.Where("opening_id = ?", *filter.OpeningID)
+	}
+	if filter.CandidateID != nil {
+		query = query.Where("candidate_id = ?", *filter.CandidateID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+	applications := []Application{}
+	if err := query.Preload("Candidate").Order("stage_changed_at DESC, id DESC").Scopes(page.Scope).
+		Find(&applications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list applications: %w", err)
+	}
+	if err := titles(s.db, applications); err != nil {
+		return nil, 0, err
+	}
+	return applications, total, nil
+}
+
+func (s *service) Application(orgID *uint, viewer Viewer, id uint) (*Application, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if !viewer.HR {
+		query = query.Where("id IN (?)", s.db.Model(&Interviewer{}).Select("application_id").Where("user_id = ?", viewer.UserID))
+	}
+	var application Application
+	if err := query.Preload("Candidate").Preload("Interviewers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Feedback", func(db *gorm.DB) *gorm.DB {
+		return db.Order("updated_at DESC, id DESC")
+	}).First(&application, id).Error; err != nil {
+		return nil, err
+	}
+	applications := []Application{application}
+	if err := titles(s.db, applications); err != nil {
+		return nil, err
+	}
+	application = applications[0]
+	if !viewer.HR {
+		forInterviewer(&application, viewer.UserID)
+	}
+	return &application, nil
+}
+
+func (s *service) Interviews(orgID *uint, userID uint, page utils.Pagination) ([]Application, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Application{}), orgID).
+		Where("id IN (?)", s.db.Model(&Interviewer{}).Select("application_id").Where("user_id = ?", userID)).
+		Where("stage IN ?", []Stage{StageApplied, StageInterview, StageOffer})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count interviews: %w", err)
+	}
+	applications := []Application{}
+	if err := query.Preload("Candidate").Preload("Feedback", "interviewer_id = ?", userID).
+		Order("stage_changed_at DESC, id DESC").Scopes(page.Scope).Find(&applications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list interviews: %w", err)
+	}
+	if err := titles(s.db, applications); err != nil {
+		return nil, 0, err
+	}
+	for i := range applications {
+		forInterviewer(&applications[i], userID)
+	}
+	return applications, total, nil
+}
+
+func (s *service) Apply(actor audit.Actor, orgID *uint, req ApplicationRequest) (*Application, error) {
+	application := Application{
+		OrganizationID: orgID, CandidateID: req.CandidateID, OpeningID: req.OpeningID,
+		Stage: StageApplied, StageChangedAt: clock.Now().UTC(), CreatedBy: actor.UserID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var candidates int64
+		if err := utils.OrgScope(tx.Model(&Candidate{}), orgID).Where("id = ?", req.CandidateID).Count(&candidates).Error; err != nil {
+			return fmt.Errorf("failed to load candidate %d: %w", req.CandidateID, err)
+		}
+		if candidates == 0 {
+			return fmt.Errorf("%w: candidate %d not found", ErrInvalidApplication, req.CandidateID)
+		}
+		var job opening.Opening
+		if err := utils.OrgScope(tx, orgID).First(&job, req.OpeningID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: job opening %d not found", ErrInvalidApplication, req.OpeningID)
+		} else if err != nil {
+			return fmt.Errorf("failed to load job opening %d: %w", req.OpeningID, err)
+		}
+		if job.Status != opening.StatusOpen {
+			return fmt.Errorf("%w: the job opening %q is not open", ErrInvalidApplication, job.Title)
+		}
+		var existing int64
+		if err := tx.Model(&Application{}).Where("candidate_id = ? AND opening_id = ?", req.CandidateID, req.OpeningID).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check applications: %w", err)
+		}
+		if existing > 0 {
+			return ErrDuplicateApplication
+		}
+		if err := tx.Create(&application).Error; err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "application.create", EntityType: "application", EntityID: fmt.Sprintf("%d", application.ID), After: application,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Application(orgID, Viewer{HR: true}, application.ID)
+}
+
+func (s *service) Move(actor audit.Actor, orgID *uint, id uint, req StageRequest) (*Application, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if req.Stage == StageRejected && reason == "" {
+		return nil, fmt.Errorf("%w: say why the candidate is rejected", ErrInvalidApplication)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		application, err := lockApplication(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		switch req.Stage {
+		case StageRejected, StageWithdrawn:
+			if application.Stage.Final() {
+				return ErrStage
+			}
+		default:
+			if next[application.Stage] != req.Stage {
+				return fmt.Errorf("%w: an application %s can't move to %s", ErrStage, application.Stage, req.Stage)
+			}
+			reason = ""
+		}
+		if err := utils.UpdateWithVersion(tx, &Application{}, id, application.Version, map[string]interface{}{
+			"stage":            req.Stage,
+			"stage_changed_at": clock.Now().UTC(),
+			"rejection_reason": reason,
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "application.stage", EntityType: "application", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"stage": application.Stage},
+			After:  map[string]interface{}{"stage": req.Stage, "reason": reason},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Application(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) SetInterviewers(actor audit.Actor, orgID *uint, id uint, req InterviewersRequest) (*Application, error) {
+	userIDs := slices.Clone(req.UserIDs)
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		application, err := lockApplication(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if application.Stage.Final() {
+			return ErrStage
+		}
+		if len(userIDs) > 0 {
+			var users int64
+			query := tx.Model(&auth.User{}).Where("id IN ? AND is_active", userIDs)
+			if orgID == nil {
+				query = query.Where("organization_id IS NULL")
+			} else {
+				query = query.Where("organization_id = ?", *orgID)
+			}
+			if err := query.Count(&users).Error; err != nil {
+				return fmt.Errorf("failed to load interviewers: %w", err)
+			}
+			if int(users) != len(userIDs) {
+				return fmt.Errorf("%w: interviewers must be active users of the organization", ErrInvalidApplication)
+			}
+		}
+		var current []uint
+		if err := tx.Model(&Interviewer{}).Where("application_id = ?", id).Order("user_id").Pluck("user_id", &current).Error; err != nil {
+			return fmt.Errorf("failed to load interviewers: %w", err)
+		}
+		removed := tx.Where("application_id = ?", id)
+		if len(userIDs) > 0 {
+			removed = removed.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := removed.Delete(&Interviewer{}).Error; err != nil {
+			return fmt.Errorf("failed to remove interviewers: %w", err)
+		}
+		var candidate Candidate
+		if err := tx.First(&candidate, application.CandidateID).Error; err != nil {
+			return fmt.Errorf("failed to load candidate %d: %w", application.CandidateID, err)
+		}
+		var job opening.Opening
+		if err := tx.Select("id", "title").First(&job, application.OpeningID).Error; err != nil {
+			return fmt.Errorf("failed to load job opening %d: %w", application.OpeningID, err)
+		}
+		var notices []notification.Notice
+		for _, userID := range userIDs {
+			if slices.Contains(current, userID) {
+				continue
+			}
+			if err := tx.Create(&Interviewer{ApplicationID: id, UserID: userID, AssignedBy: actor.UserID}).Error; err != nil {
+				return fmt.Errorf("failed to assign interviewer: %w", err)
+			}
+			notices = append(notices, notification.Notice{
+				UserID:         userID,
+				OrganizationID: orgID,
+				Category:       "ats.interview",
+				Subject:        fmt.Sprintf("You were asked to interview %s %s for %q", candidate.FirstName, candidate.LastName, job.Title),
+				Body:           "Give your feedback once you have met the candidate.",
+				Link:           fmt.Sprintf("/me/interviews/%d", id),
+			})
+		}
+		if err := notification.CreateTx(tx, notices...); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "application.interviewers", EntityType: "application", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"user_ids": current}, After: map[string]interface{}{"user_ids": userIDs},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Application(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) GiveFeedback(actor audit.Actor, orgID *uint, id uint, req FeedbackRequest) (*Application, error) {
+	if actor.UserID == nil {
+		return nil, ErrNotInterviewer
+	}
+	userID := *actor.UserID
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		application, err := lockApplication(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		var assigned int64
+		if err := tx.Model(&Interviewer{}).Where("application_id = ? AND user_id = ?", id, userID).Count(&assigned).Error; err != nil {
+			return fmt.Errorf("failed to check interviewers: %w", err)
+		}
+		if assigned == 0 {
+			return ErrNotInterviewer
+		}
+		if application.Stage.Final() {
+			return ErrStage
+		}
+		feedback := Feedback{ApplicationID: id, InterviewerID: userID}
+		if err := tx.Where(&feedback).First(&feedback).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to load feedback: %w", err)
+		}
+		before := feedback
+		feedback.Stage = application.Stage
+		feedback.Rating = req.Rating
+		feedback.Recommendation = req.Recommendation
+		feedback.Comments = strings.TrimSpace(req.Comments)
+		if err := tx.Save(&feedback).Error; err != nil {
+			return fmt.Errorf("failed to save feedback: %w", err)
+		}
+		entry := audit.Entry{Action: "application.feedback", EntityType: "application", EntityID: fmt.Sprintf("%d", id), After: feedback}
+		if before.ID != 0 {
+			entry.Before = before
+		}
+		return s.auditor.RecordTx(tx, actor, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Application(orgID, Viewer{UserID: userID}, id)
+}
+
+func (s *service) Hire(actor audit.Actor, orgID *uint, id uint, req HireRequest) (*Application, error) {
+	hireDate := req.HireDate
+	if hireDate == "" {
+		hireDate = today().Format("2006-01-02")
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		application, err := lockApplication(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if application.Stage != StageOffer {
+			return fmt.Errorf("%w: only candidates with an offer can be hired", ErrStage)
+		}
+		var candidate Candidate
+		if err := tx.First(&candidate, application.CandidateID).Error; err != nil {
+			return fmt.Errorf("failed to load candidate %d: %w", application.CandidateID, err)
+		}
+		var job opening.Opening
+		if err := tx.First(&job, application.OpeningID).Error; err != nil {
+			return fmt.Errorf("failed to load job opening %d: %w", application.OpeningID, err)
+		}
+
+		email := req.Email
+		if email == "" {
+			email = candidate.Email
+		}
+		user, password, err := s.createUser(tx, orgID, req.Username, email)
+		if err != nil {
+			return err
+		}
+
+		names := req.Names
+		if len(names) == 0 {
+			names = []employee.Name{{Kind: employee.LegalName, Script: "Latn", Given: candidate.FirstName, Family: candidate.LastName}}
+		}
+		jobTitle := strings.TrimSpace(req.JobTitle)
+		if jobTitle == "" {
+			jobTitle = job.Title
+		}
+		divisionID := req.DivisionID
+		if divisionID == nil {
+			divisionID = &job.DivisionID
+		}
+		hired, err := s.employees.CreateTx(tx, actor, orgID, employee.Request{
+			UserID:         user.ID,
+			EmployeeNumber: req.EmployeeNumber,
+			JobTitle:       jobTitle,
+			HireDate:       hireDate,
+			EmploymentType: req.EmploymentType,
+			ManagerID:      req.ManagerID,
+			DivisionID:     divisionID,
+			Names:          names,
+			Pronouns:       req.Pronouns,
+		})
+		if err != nil {
+			return err
+		}
+		if job.RequisitionID != nil {
+			err := s.requisitions.HireTx(tx, actor, orgID, *job.RequisitionID, requisition.HireRequest{EmployeeID: hired.ID, StartOn: hireDate})
+			if errors.Is(err, requisition.ErrStatus) {
+				return fmt.Errorf("%w: requisition %d is no longer approved", ErrInvalidApplication, *job.RequisitionID)
+			} else if err != nil {
+				return err
+			}
+		}
+
+		now := clock.Now().UTC()
+		if err := utils.UpdateWithVersion(tx, &Application{}, id, application.Version, map[string]interface{}{
+			"stage":            StageHired,
+			"stage_changed_at": now,
+			"employee_id":      hired.ID,
+			"hired_at":         now,
+		}); err != nil {
+			return err
+		}
+		subject, body, err := s.templates.Render(orgID, InvitationTemplate, map[string]interface{}{
+			"FirstName":         candidate.FirstName,
+			"JobTitle":          jobTitle,
+			"StartDate":         hireDate,
+			"Username":          user.Username,
+			"TemporaryPassword": password,
+			"LoginURL":          s.appBaseURL,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render invitation: %w", err)
+		}
+		if err := mail.QueueTx(s.outbox, tx, mail.Message{To: []string{email}, Subject: subject, Body: body}); err != nil {
+			return fmt.Errorf("failed to queue invitation: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "application.hire", EntityType: "application", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"stage": application.Stage},
+			After: map[string]interface{}{
+				"stage": StageHired, "employee_id": hired.ID, "user_id": user.ID, "requisition_id": job.RequisitionID,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Application(orgID, Viewer{HR: true}, id)
+}
+
+// createUser creates the account of a hired candidate with the staff role and a temporary password,
+// which it returns for the invitation.
+func (s *service) createUser(tx *gorm.DB, orgID *uint, username, email string) (*auth.User, string, error) {
+	exists, err := auth.UserExists(tx, username, email)
+	if err != nil {
+		return nil, "", err
+	}
+	if exists {
+		return nil, "", auth.ErrUserExists
+	}
+	if orgID != nil {
+		if err := s.limits.CheckEmployeeLimit(tx, *orgID, 1); err != nil {
+			return nil, "", err
+		}
+	}
+	var staff role.Role
+	if err := tx.Where("name = ?", staffRole).First(&staff).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load the %s role: %w", staffRole, err)
+	}
+	password, err := auth.GenerateRandomPassword()
+	if err != nil {
+		return nil, "", err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := auth.User{Username: username, Email: email, Password: hash, IsActive: true, OrganizationID: orgID, Roles: []role.Role{staff}}
+	if err := tx.Create(&user).Error; err != nil {
+		if auth.IsUsernameConflict(err) {
+			return nil, "", auth.ErrUserExists
+		}
+		return nil, "", fmt.Errorf("failed to create user: %w", err)
+	}
+	return &user, password, nil
+}
+
+// applyCandidate validates req and copies it onto candidate. Email addresses are unique per organization,
+// so a returning candidate applies again under the same record.
+func applyCandidate(tx *gorm.DB, orgID *uint, candidate *Candidate, req CandidateRequest) error {
+	firstName, lastName := strings.TrimSpace(req.FirstName), strings.TrimSpace(req.LastName)
+	if firstName == "" || lastName == "" {
+		return fmt.Errorf("%w: the first and last name can't be blank", ErrInvalidCandidate)
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	var taken int64
+	if err := utils.OrgScope(tx.Model(&Candidate{}), orgID).Where("email = ? AND id <> ?", email, candidate.ID).
+		Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check candidates: %w", err)
+	}
+	if taken > 0 {
+		return ErrCandidateExists
+	}
+	candidate.FirstName = firstName
+	candidate.LastName = lastName
+	candidate.Email = email
+	candidate.Phone = strings.TrimSpace(req.Phone)
+	candidate.Source = strings.TrimSpace(req.Source)
+	candidate.Notes = strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// titles fills in the titles of the applications' job openings.
+func titles(db *gorm.DB, applications []Application) error {
+	if len(applications) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(applications))
+	for _, a := range applications {
+		ids = append(ids, a.OpeningID)
+	}
+	var openings []opening.Opening
+	if err := db.Select("id", "title").Where("id IN ?", ids).Find(&openings).Error; err != nil {
+		return fmt.Errorf("failed to load job openings: %w", err)
+	}
+	byID := make(map[uint]string, len(openings))
+	for _, o := range openings {
+		byID[o.ID] = o.Title
+	}
+	for i := range applications {
+		applications[i].OpeningTitle = byID[applications[i].OpeningID]
+	}
+	return nil
+}
+
+// forInterviewer strips what an interviewer may not see: HR's notes on the candidate, and the feedback
+// of the other interviewers, so each gives theirs unswayed.
+func forInterviewer(application *Application, userID uint) {
+	if application.Candidate != nil {
+		application.Candidate.Notes = ""
+	}
+	own := application.Feedback[:0]
+	for _, f := range application.Feedback {
+		if f.InterviewerID == userID {
+			own = append(own, f)
+		}
+	}
+	application.Feedback = own
+}
+
+func lockApplication(tx *gorm.DB, orgID *uint, id uint) (*Application, error) {
+	var application Application
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&application, id).Error; err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
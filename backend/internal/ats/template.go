@@ -0,0 +1,26 @@
+// prometheus/backend/internal/ats/template.go
+package ats
+
+import "prometheus/backend/internal/mail"
+
+// InvitationTemplate is the email a hired candidate receives with their sign-in details.
+const InvitationTemplate = "ats.invitation"
+
+func init() {
+	mail.RegisterTemplate(mail.Template{
+		Name:        InvitationTemplate,
+		Description: "Account invitation sent to a hired candidate",
+		Subject:     "Welcome aboard, {{.FirstName}}",
+		Body: "Hello {{.FirstName}},\n\nwelcome to the team as {{.JobTitle}}, starting {{.StartDate}}.\n\n" +
+			"Your account is ready. Sign in at {{.LoginURL}} with\n\n  Username: {{.Username}}\n" +
+			"  Temporary password: {{.TemporaryPassword}}\n\nand change your password right away.\n",
+		Sample: map[string]interface{}{
+			"FirstName":         "Jonas",
+			"JobTitle":          "Payroll Specialist",
+			"StartDate":         "2026-11-02",
+			"Username":          "jweber",
+			"TemporaryPassword": "x7Kp2mQv9LtR",
+			"LoginURL":          "https://hris.example.com",
+		},
+	})
+}
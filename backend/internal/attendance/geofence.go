@@ -0,0 +1,58 @@
+// prometheus/backend/internal/attendance/geofence.go
+package attendance
+
+import "math"
+
+// earthRadiusMeters is the mean radius of the Earth.
+const earthRadiusMeters = 6371000
+
+// verdict is where a punch was made relative to the organization's geofences.
+type verdict struct {
+	GeofenceID *uint    // Nearest active geofence, if any has been set up
+	Distance   *float64 // From its center
+	Reason     string   // Why the punch doesn't count as on-site; empty if it does
+}
+
+// locate checks a punch's location against the active geofences. A location is within a geofence if the
+// reported accuracy circle overlaps it, so a reading a few meters off at the office door isn't flagged;
+// readings less precise than the policy allows don't count. Without geofences there is nothing to check.
+func locate(policy Policy, fences []Geofence, req PunchRequest) verdict {
+	var v verdict
+	if len(fences) == 0 {
+		return v
+	}
+	if req.Latitude == nil || req.Longitude == nil {
+		v.Reason = ReasonNoLocation
+		return v
+	}
+	accuracy := 0.0
+	if req.AccuracyMeters != nil {
+		accuracy = *req.AccuracyMeters
+	}
+	inside := false
+	for i := range fences {
+		d := distance(*req.Latitude, *req.Longitude, fences[i].Latitude, fences[i].Longitude)
+		if v.Distance == nil || d < *v.Distance {
+			v.GeofenceID, v.Distance = &fences[i].ID, &d
+		}
+		if d-accuracy <= fences[i].RadiusMeters {
+			inside = true
+		}
+	}
+	switch {
+	case accuracy > policy.MaxAccuracyMeters:
+		v.Reason = ReasonInaccurateLocation
+	case !inside:
+		v.Reason = ReasonOutsideGeofence
+	}
+	return v
+}
+
+// distance returns the great-circle distance in meters between two coordinates (haversine formula).
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,553 @@
+// prometheus/backend/internal/attendance/handler.go
+package attendance
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for clocking in and out, and for the geofences punches are checked against.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ClockIn clocks the caller in.
+// @Summary Clock in
+// @Description Records the caller clocking in now. With geofences set up, the location is checked against
+// @Description them: punches outside every geofence, or without a usable location, are flagged for review or
+// @Description rejected, depending on the organization's policy. A kiosk_code scanned from an attendance kiosk
+// @Description places the punch at the kiosk instead, without a location.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param punch body PunchRequest false "Location of the device"
+// @Success 201 {object} Punch
+// @Failure 400 {object} utils.ErrorResponse "Invalid location"
+// @Failure 403 {object} utils.ErrorResponse "No employee record, rejected by the geofence policy, or invalid kiosk code"
+// @Failure 409 {object} utils.ErrorResponse "Already clocked in"
+// @Router /me/attendance/clock-in [post]
+func (h *Handler) ClockIn(c *gin.Context) {
+	h.punch(c, PunchIn, "Clocked in successfully")
+}
+
+// ClockOut clocks the caller out.
+// @Summary Clock out
+// @Description Records the caller clocking out now; the location or kiosk code is checked like on clock-in.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param punch body PunchRequest false "Location of the device"
+// @Success 201 {object} Punch
+// @Failure 400 {object} utils.ErrorResponse "Invalid location"
+// @Failure 403 {object} utils.ErrorResponse "No employee record, rejected by the geofence policy, or invalid kiosk code"
+// @Failure 409 {object} utils.ErrorResponse "Not clocked in"
+// @Router /me/attendance/clock-out [post]
+func (h *Handler) ClockOut(c *gin.Context) {
+	h.punch(c, PunchOut, "Clocked out successfully")
+}
+
+func (h *Handler) punch(c *gin.Context, typ PunchType, message string) {
+	var req PunchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	punch, err := h.service.Punch(c.GetUint("userID"), typ, req)
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, message, punch)
+}
+
+// Status tells whether the caller is clocked in.
+// @Summary Get own attendance status
+// @Tags Attendance
+// @Produce json
+// @Success 200 {object} Status
+// @Failure 403 {object} utils.ErrorResponse "No employee record"
+// @Router /me/attendance [get]
+func (h *Handler) Status(c *gin.Context) {
+	status, err := h.service.Status(c.GetUint("userID"))
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Attendance status fetched successfully", status)
+}
+
+// Mine lists the caller's punches, newest first.
+// @Summary List own punches
+// @Tags Attendance
+// @Produce json
+// @Param from query string false "At or after (RFC3339)"
+// @Param to query string false "Before (RFC3339)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 403 {object} utils.ErrorResponse "No employee record"
+// @Router /me/attendance/punches [get]
+func (h *Handler) Mine(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	punches, total, err := h.service.Mine(c.GetUint("userID"), filter, page)
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Punches fetched successfully", page.Response(punches, total))
+}
+
+// List returns the organization's punches, newest first, e.g. the flagged ones to review.
+// @Summary List punches
+// @Tags Attendance
+// @Produce json
+// @Param employee_id query int false "Employee ID"
+// @Param flagged query bool false "Only punches flagged by the geofence policy"
+// @Param from query string false "At or after (RFC3339)"
+// @Param to query string false "Before (RFC3339)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/attendance/punches [get]
+func (h *Handler) List(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	filter.FlaggedOnly = c.Query("flagged") == "true"
+	page := utils.ParsePagination(c)
+	punches, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Punches fetched successfully", page.Response(punches, total))
+}
+
+// Expectations lists who was expected at work on a day and who clocked in.
+// @Summary List attendance expectations
+// @Description Employees are expected on the working days of their holiday calendar, from their hire date.
+// @Description Those who didn't clock in are flagged absent; nobody is on a holiday or a rest day.
+// @Tags Attendance
+// @Produce json
+// @Param date query string false "Day (YYYY-MM-DD); defaults to today (UTC)"
+// @Param division_id query int false "Division ID"
+// @Param absent_only query bool false "Only employees flagged absent"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid date or filter"
+// @Router /hr/attendance/expectations [get]
+func (h *Handler) Expectations(c *gin.Context) {
+	date := clock.Now().UTC()
+	if raw := c.Query("date"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid date parameter: expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+	filter := ExpectationFilter{AbsentOnly: c.Query("absent_only") == "true"}
+	if raw := c.Query("division_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid division_id parameter")
+			return
+		}
+		divisionID := uint(id)
+		filter.DivisionID = &divisionID
+	}
+	page := utils.ParsePagination(c)
+	expectations, total, err := h.service.Expectations(utils.OrganizationFromContext(c), date, filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Attendance expectations fetched successfully", page.Response(expectations, total))
+}
+
+// ListGeofences returns the organization's geofences.
+// @Summary List geofences
+// @Tags Attendance
+// @Produce json
+// @Success 200 {array} Geofence
+// @Router /hr/attendance/geofences [get]
+func (h *Handler) ListGeofences(c *gin.Context) {
+	fences, err := h.service.Geofences(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Geofences fetched successfully", fences)
+}
+
+// CreateGeofence adds a geofence around an office.
+// @Summary Create a geofence
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param geofence body GeofenceRequest true "Geofence"
+// @Success 201 {object} Geofence
+// @Failure 400 {object} utils.ErrorResponse "Invalid geofence"
+// @Router /hr/attendance/geofences [post]
+func (h *Handler) CreateGeofence(c *gin.Context) {
+	var req GeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	fence, err := h.service.CreateGeofence(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, fence.UpdatedAt, fence.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Geofence created successfully", fence)
+}
+
+// UpdateGeofence replaces a geofence's fields.
+// @Summary Update a geofence
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param id path int true "Geofence ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param geofence body GeofenceRequest true "Geofence"
+// @Success 200 {object} Geofence
+// @Failure 400 {object} utils.ErrorResponse "Invalid geofence"
+// @Failure 404 {object} utils.ErrorResponse "Geofence not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/attendance/geofences/{id} [put]
+func (h *Handler) UpdateGeofence(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req GeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetGeofence(orgID, id)
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	fence, err := h.service.UpdateGeofence(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, fence.UpdatedAt, fence.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Geofence updated successfully", fence)
+}
+
+// DeleteGeofence removes a geofence.
+// @Summary Delete a geofence
+// @Tags Attendance
+// @Param id path int true "Geofence ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Geofence not found"
+// @Router /hr/attendance/geofences/{id} [delete]
+func (h *Handler) DeleteGeofence(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteGeofence(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendAttendanceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListKiosks returns the organization's attendance kiosks.
+// @Summary List kiosks
+// @Tags Attendance
+// @Produce json
+// @Success 200 {array} Kiosk
+// @Router /hr/attendance/kiosks [get]
+func (h *Handler) ListKiosks(c *gin.Context) {
+	kiosks, err := h.service.Kiosks(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Kiosks fetched successfully", kiosks)
+}
+
+// CreateKiosk sets up an attendance kiosk.
+// @Summary Create a kiosk
+// @Description Returns the kiosk's device token once; the kiosk sends it as X-Kiosk-Token to fetch its
+// @Description rotating codes from /attendance/kiosk/code.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param kiosk body KioskRequest true "Kiosk"
+// @Success 201 {object} KioskCredentials
+// @Failure 400 {object} utils.ErrorResponse "Invalid kiosk"
+// @Router /hr/attendance/kiosks [post]
+func (h *Handler) CreateKiosk(c *gin.Context) {
+	var req KioskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	kiosk, err := h.service.CreateKiosk(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, kiosk.UpdatedAt, kiosk.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Kiosk created successfully", kiosk)
+}
+
+// UpdateKiosk replaces a kiosk's fields.
+// @Summary Update a kiosk
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param kiosk body KioskRequest true "Kiosk"
+// @Success 200 {object} Kiosk
+// @Failure 400 {object} utils.ErrorResponse "Invalid kiosk"
+// @Failure 404 {object} utils.ErrorResponse "Kiosk not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/attendance/kiosks/{id} [put]
+func (h *Handler) UpdateKiosk(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req KioskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetKiosk(orgID, id)
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	kiosk, err := h.service.UpdateKiosk(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, kiosk.UpdatedAt, kiosk.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Kiosk updated successfully", kiosk)
+}
+
+// DeleteKiosk removes a kiosk.
+// @Summary Delete a kiosk
+// @Tags Attendance
+// @Param id path int true "Kiosk ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Kiosk not found"
+// @Router /hr/attendance/kiosks/{id} [delete]
+func (h *Handler) DeleteKiosk(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteKiosk(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResetKiosk issues a kiosk a new device token.
+// @Summary Reset a kiosk's token
+// @Description The old token stops working, and so do codes the kiosk has shown. Use it when a kiosk device
+// @Description is lost or replaced.
+// @Tags Attendance
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Success 200 {object} KioskCredentials
+// @Failure 404 {object} utils.ErrorResponse "Kiosk not found"
+// @Router /hr/attendance/kiosks/{id}/reset [post]
+func (h *Handler) ResetKiosk(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	kiosk, err := h.service.ResetKiosk(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, kiosk.UpdatedAt, kiosk.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Kiosk token reset successfully", kiosk)
+}
+
+// PreviewKioskCode returns the code a kiosk currently shows.
+// @Summary Get a kiosk's current code
+// @Tags Attendance
+// @Produce json
+// @Param id path int true "Kiosk ID"
+// @Success 200 {object} KioskCode
+// @Failure 404 {object} utils.ErrorResponse "Kiosk not found"
+// @Router /hr/attendance/kiosks/{id}/code [get]
+func (h *Handler) PreviewKioskCode(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	code, err := h.service.KioskCode(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Kiosk code fetched successfully", code)
+}
+
+// DeviceCode returns the code for a kiosk device to show as a QR code.
+// @Summary Get the kiosk's current code
+// @Description Called by the kiosk device with its token rather than a user session. The code rotates every
+// @Description refresh_seconds; fetch the next one at expires_at.
+// @Tags Attendance
+// @Produce json
+// @Param X-Kiosk-Token header string true "Device token from kiosk creation or reset"
+// @Success 200 {object} KioskCode
+// @Failure 401 {object} utils.ErrorResponse "Unknown token or inactive kiosk"
+// @Router /attendance/kiosk/code [get]
+func (h *Handler) DeviceCode(c *gin.Context) {
+	code, err := h.service.DeviceCode(c.GetHeader("X-Kiosk-Token"))
+	if err != nil {
+		sendKioskError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.SendSuccessResponse(c, http.StatusOK, "Kiosk code fetched successfully", code)
+}
+
+// GetPolicy returns the organization's attendance settings.
+// @Summary Get the attendance policy
+// @Tags Attendance
+// @Produce json
+// @Success 200 {object} Policy
+// @Router /hr/attendance/policy [get]
+func (h *Handler) GetPolicy(c *gin.Context) {
+	policy, err := h.service.Policy(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Attendance policy fetched successfully", policy)
+}
+
+// PutPolicy replaces the organization's attendance settings.
+// @Summary Update the attendance policy
+// @Description geofence_mode decides what happens to punches outside every geofence or without a usable
+// @Description location: "off" records them as they are, "flag" accepts and flags them for review, "reject"
+// @Description refuses them. Locations less precise than max_accuracy_meters don't count.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param policy body PolicyRequest true "Policy"
+// @Success 200 {object} Policy
+// @Failure 400 {object} utils.ErrorResponse "Invalid policy"
+// @Router /hr/attendance/policy [put]
+func (h *Handler) PutPolicy(c *gin.Context) {
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	policy, err := h.service.SetPolicy(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Attendance policy updated successfully", policy)
+}
+
+func parseFilter(c *gin.Context) (Filter, bool) {
+	var filter Filter
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected RFC3339")
+				return Filter{}, false
+			}
+			*target = &t
+		}
+	}
+	return filter, true
+}
+
+// sendAttendanceError maps service errors to HTTP status codes.
+func sendAttendanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Geofence not found")
+	case errors.Is(err, ErrInvalidPunch), errors.Is(err, ErrInvalidKiosk):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNoEmployee), errors.Is(err, ErrLocationRejected), errors.Is(err, ErrInvalidKioskCode):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrInvalidKioskToken):
+		utils.SendErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, ErrAlreadyClockedIn), errors.Is(err, ErrNotClockedIn):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The geofence was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// sendKioskError maps kiosk service errors to HTTP status codes.
+func sendKioskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Kiosk not found")
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The kiosk was modified concurrently. Reload and try again.")
+	default:
+		sendAttendanceError(c, err)
+	}
+}
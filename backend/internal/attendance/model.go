@@ -0,0 +1,183 @@
+// prometheus/backend/internal/attendance/model.go
+package attendance
+
+import (
+	"prometheus/backend/internal/holiday"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PunchType is the direction of a punch.
+type PunchType string
+
+const (
+	PunchIn  PunchType = "in"
+	PunchOut PunchType = "out"
+)
+
+// GeofenceMode is how punches outside the organization's geofences are treated.
+type GeofenceMode string
+
+const (
+	GeofenceOff    GeofenceMode = "off"    // Locations are recorded when sent, but not checked
+	GeofenceFlag   GeofenceMode = "flag"   // Punches outside every geofence, or without a usable location, are accepted and flagged for review
+	GeofenceReject GeofenceMode = "reject" // Such punches are refused
+)
+
+// Reasons a punch is flagged, or refused under GeofenceReject.
+const (
+	ReasonNoLocation         = "no_location"         // The punch came without coordinates
+	ReasonInaccurateLocation = "inaccurate_location" // The reported accuracy is worse than Policy.MaxAccuracyMeters
+	ReasonOutsideGeofence    = "outside_geofence"    // Farther from every active geofence than its radius
+)
+
+// Punch is an employee clocking in or out. Punches alternate per employee, starting with PunchIn.
+type Punch struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"81"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index:idx_punch_employee" json:"employee_id" example:"12"`
+	Type           PunchType `gorm:"type:varchar(3);not null" json:"type" example:"in"`
+	At             time.Time `gorm:"not null;index:idx_punch_employee" json:"at"`
+	Latitude       *float64  `json:"latitude,omitempty" example:"52.520008"`
+	Longitude      *float64  `json:"longitude,omitempty" example:"13.404954"`
+	AccuracyMeters *float64  `json:"accuracy_meters,omitempty" example:"15"`
+	GeofenceID     *uint     `json:"geofence_id,omitempty" example:"2"`             // Nearest active geofence, or the kiosk's
+	KioskID        *uint     `gorm:"index" json:"kiosk_id,omitempty" example:"4"`   // Kiosk whose QR code was scanned
+	DistanceMeters *float64  `json:"distance_meters,omitempty" example:"40"`        // From the nearest geofence's center
+	Flagged        bool      `gorm:"not null;default:false;index" json:"flagged"`   // Accepted under GeofenceFlag, for review
+	FlagReason     string    `gorm:"type:varchar(30)" json:"flag_reason,omitempty"` // One of the Reason constants
+	Note           string    `gorm:"type:varchar(500)" json:"note,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Geofence is a circular area around an office that punches are expected from.
+type Geofence struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"2"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name" example:"Berlin office"`
+	Latitude       float64        `gorm:"not null" json:"latitude" example:"52.520008"`
+	Longitude      float64        `gorm:"not null" json:"longitude" example:"13.404954"`
+	RadiusMeters   float64        `gorm:"not null" json:"radius_meters" example:"150"`
+	Active         bool           `gorm:"not null" json:"active"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Kiosk is a screen at an office entrance showing a QR code that rotates every RotateSeconds. Employees
+// scan it to clock in or out, which places the punch at the kiosk without needing GPS.
+type Kiosk struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name" example:"Berlin lobby"`
+	GeofenceID     *uint          `json:"geofence_id,omitempty" example:"2"` // Office the kiosk stands in
+	RotateSeconds  int            `gorm:"not null" json:"rotate_seconds" example:"30"`
+	Active         bool           `gorm:"not null" json:"active"`
+	Secret         string         `gorm:"type:varchar(64);not null" json:"-"`             // Signs the kiosk's codes
+	TokenHash      string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"` // SHA-256 of the device token
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"`  // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Policy is an organization's attendance settings. Organizations without one use DefaultPolicy.
+type Policy struct {
+	ID                uint         `gorm:"primaryKey" json:"-"`
+	OrganizationID    *uint        `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	GeofenceMode      GeofenceMode `gorm:"type:varchar(10);not null" json:"geofence_mode" example:"flag"`
+	MaxAccuracyMeters float64      `gorm:"not null" json:"max_accuracy_meters" example:"100"` // Readings less precise than this don't count as a location
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// TableName keeps attendance settings apart from other kinds of policies.
+func (Policy) TableName() string { return "attendance_policies" }
+
+// DefaultPolicy applies to organizations that haven't configured attendance: locations aren't checked.
+func DefaultPolicy(orgID *uint) Policy {
+	return Policy{OrganizationID: orgID, GeofenceMode: GeofenceOff, MaxAccuracyMeters: 100}
+}
+
+// PunchRequest clocks the caller in or out. Coordinates come from the device, e.g. the browser's
+// Geolocation API.
+type PunchRequest struct {
+	Latitude       *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90" example:"52.520008"`
+	Longitude      *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180" example:"13.404954"`
+	AccuracyMeters *float64 `json:"accuracy_meters,omitempty" binding:"omitempty,min=0" example:"15"`
+	KioskCode      string   `json:"kiosk_code,omitempty" binding:"max=200"` // Scanned from a kiosk; replaces the location check
+	Note           string   `json:"note,omitempty" binding:"max=500"`
+}
+
+// GeofenceRequest creates a geofence or replaces its fields.
+type GeofenceRequest struct {
+	Name         string   `json:"name" binding:"required,max=100" example:"Berlin office"`
+	Latitude     *float64 `json:"latitude" binding:"required,min=-90,max=90" example:"52.520008"`
+	Longitude    *float64 `json:"longitude" binding:"required,min=-180,max=180" example:"13.404954"`
+	RadiusMeters float64  `json:"radius_meters" binding:"required,min=10,max=10000" example:"150"`
+	Active       *bool    `json:"active,omitempty"` // Defaults to true
+}
+
+// KioskRequest creates a kiosk or replaces its fields.
+type KioskRequest struct {
+	Name          string `json:"name" binding:"required,max=100" example:"Berlin lobby"`
+	GeofenceID    *uint  `json:"geofence_id,omitempty" example:"2"`
+	RotateSeconds int    `json:"rotate_seconds,omitempty" binding:"omitempty,min=10,max=300" example:"30"` // Defaults to 30
+	Active        *bool  `json:"active,omitempty"`                                                         // Defaults to true
+}
+
+// KioskCredentials is a kiosk with its device token, returned only when the token is issued.
+type KioskCredentials struct {
+	Kiosk
+	Token string `json:"token" example:"kiosk_3q2-7wEjZ1bXk0t4"` // Sent by the kiosk as X-Kiosk-Token to fetch its codes
+}
+
+// KioskCode is the code a kiosk currently shows, to be rendered as a QR code.
+type KioskCode struct {
+	Code           string    `json:"code" example:"4.58392011.Xq3v0lYx6m2oC7aKp1cO2yNn8F4rT0gQeW9hJzU5bLs"`
+	ExpiresAt      time.Time `json:"expires_at"`      // When the kiosk should show the next code
+	RefreshSeconds int       `json:"refresh_seconds"` // The kiosk's rotation period
+}
+
+// PolicyRequest replaces an organization's attendance settings.
+type PolicyRequest struct {
+	GeofenceMode      GeofenceMode `json:"geofence_mode" binding:"required,oneof=off flag reject" example:"flag"`
+	MaxAccuracyMeters float64      `json:"max_accuracy_meters" binding:"required,min=1,max=10000" example:"100"`
+}
+
+// Filter narrows a punch listing.
+type Filter struct {
+	EmployeeID  *uint
+	FlaggedOnly bool
+	From        *time.Time // At or after
+	To          *time.Time // Before
+}
+
+// ExpectationFilter narrows the employees a day's expectations cover.
+type ExpectationFilter struct {
+	DivisionID *uint
+	AbsentOnly bool
+}
+
+// Expectation is whether an employee was expected at work on a day, and whether they clocked in.
+type Expectation struct {
+	EmployeeID  uint            `json:"employee_id" example:"12"`
+	DisplayName string          `json:"display_name" example:"Laila Haddad"`
+	Day         holiday.DayKind `json:"day" example:"working"`                        // working, rest_day or holiday
+	Holiday     string          `json:"holiday,omitempty" example:"Independence Day"` // The holiday's name
+	FirstIn     *time.Time      `json:"first_in,omitempty"`                           // First clock-in of the day
+	Absent      bool            `json:"absent"`                                       // No clock-in on a working day; never flagged on holidays or rest days
+}
+
+// PunchDetail is a punch with the employee's username, for HR listings.
+type PunchDetail struct {
+	Punch
+	Username string `json:"username" example:"jdoe"`
+}
+
+// Status is whether an employee is clocked in.
+type Status struct {
+	ClockedIn bool   `json:"clocked_in"`
+	Last      *Punch `json:"last,omitempty"`
+}
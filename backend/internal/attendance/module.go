@@ -0,0 +1,81 @@
+// prometheus/backend/internal/attendance/module.go
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+
+	"gorm.io/gorm"
+)
+
+// attendanceModule owns clocking in and out, the geofences punches are checked against, and QR code kiosks.
+type attendanceModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the attendance module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &attendanceModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *attendanceModule) Name() string { return plan.ModuleAttendance }
+
+func (m *attendanceModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *attendanceModule) Models() []any {
+	return []any{&Punch{}, &Geofence{}, &Kiosk{}, &Policy{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Everything needs the attendance plan module, except the
+// kiosk devices' code endpoint: kiosks authenticate with their own token rather than a user session.
+func (m *attendanceModule) RegisterRoutes(api *routing.Group) {
+	attendanceAPI := api.InModule(plan.ModuleAttendance)
+	attendanceAPI.GET("/attendance/kiosk/code", routing.Public(), m.handler.DeviceCode)
+	attendanceAPI.GET("/me/attendance", routing.Authenticated(), m.handler.Status)
+	attendanceAPI.GET("/me/attendance/punches", routing.Authenticated(), m.handler.Mine)
+	attendanceAPI.POST("/me/attendance/clock-in", routing.Authenticated(), m.handler.ClockIn)
+	attendanceAPI.POST("/me/attendance/clock-out", routing.Authenticated(), m.handler.ClockOut)
+	attendanceAPI.GET("/hr/attendance/punches", routing.Policy(), m.handler.List)
+	attendanceAPI.GET("/hr/attendance/expectations", routing.Policy(), m.handler.Expectations)
+	attendanceAPI.GET("/hr/attendance/geofences", routing.Policy(), m.handler.ListGeofences)
+	attendanceAPI.POST("/hr/attendance/geofences", routing.Policy(), m.handler.CreateGeofence)
+	attendanceAPI.PUT("/hr/attendance/geofences/:id", routing.Policy(), m.handler.UpdateGeofence)
+	attendanceAPI.DELETE("/hr/attendance/geofences/:id", routing.Policy(), m.handler.DeleteGeofence)
+	attendanceAPI.GET("/hr/attendance/kiosks", routing.Policy(), m.handler.ListKiosks)
+	attendanceAPI.POST("/hr/attendance/kiosks", routing.Policy(), m.handler.CreateKiosk)
+	attendanceAPI.PUT("/hr/attendance/kiosks/:id", routing.Policy(), m.handler.UpdateKiosk)
+	attendanceAPI.DELETE("/hr/attendance/kiosks/:id", routing.Policy(), m.handler.DeleteKiosk)
+	attendanceAPI.POST("/hr/attendance/kiosks/:id/reset", routing.Policy(), m.handler.ResetKiosk)
+	attendanceAPI.GET("/hr/attendance/kiosks/:id/code", routing.Policy(), m.handler.PreviewKioskCode)
+	attendanceAPI.GET("/hr/attendance/policy", routing.Policy(), m.handler.GetPolicy)
+	attendanceAPI.PUT("/hr/attendance/policy", routing.Policy(), m.handler.PutPolicy)
+}
+
+// PrivacySources implements privacy.Contributor: punches are exported, and their locations erased on
+// anonymization. The punches themselves are kept for payroll and working-time records.
+func (m *attendanceModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{
+		privacy.NewSource("attendance", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+			var punches []Punch
+			if err := db.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(db, userID)).Order("at").Find(&punches).Error; err != nil {
+				return nil, fmt.Errorf("failed to export punches: %w", err)
+			}
+			return punches, nil
+		}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+			if err := tx.WithContext(ctx).Model(&Punch{}).Where("employee_id IN (?)", employee.OfUser(tx, userID)).Updates(map[string]interface{}{
+				"latitude": nil, "longitude": nil, "accuracy_meters": nil, "note": "",
+			}).Error; err != nil {
+				return fmt.Errorf("failed to erase punch locations: %w", err)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,656 @@
+// prometheus/backend/internal/attendance/service.go
+package attendance
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/holiday"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNoEmployee is returned when a user without an employee record clocks in or out.
+	ErrNoEmployee = errors.New("you have no employee record to clock in with")
+	// ErrAlreadyClockedIn is returned when clocking in twice.
+	ErrAlreadyClockedIn = errors.New("already clocked in")
+	// ErrNotClockedIn is returned when clocking out without clocking in.
+	ErrNotClockedIn = errors.New("not clocked in")
+	// ErrLocationRejected is returned under GeofenceReject for punches without a usable location or
+	// outside every geofence.
+	ErrLocationRejected = errors.New("punch rejected by the geofence policy")
+	// ErrInvalidPunch is returned for punches that fail validation.
+	ErrInvalidPunch = errors.New("invalid punch")
+	// ErrInvalidKioskCode is returned for punches with a kiosk code that is malformed, expired, or from
+	// another organization's or an inactive kiosk.
+	ErrInvalidKioskCode = errors.New("the kiosk code is invalid or has expired; scan it again")
+	// ErrInvalidKiosk is returned for kiosks that fail validation.
+	ErrInvalidKiosk = errors.New("invalid kiosk")
+	// ErrInvalidKioskToken is returned when a kiosk device presents an unknown token.
+	ErrInvalidKioskToken = errors.New("invalid kiosk token")
+)
+
+// Service records employees clocking in and out, and manages the geofences and policy they are checked
+// against. orgID scopes HR calls to one organization; nil lists every organization's punches, and stands
+// for users outside any organization in geofences and the policy.
+type Service interface {
+	// Punch clocks the user in or out at the current time. With a kiosk code, the punch is placed at the
+	// kiosk instead of being checked against the geofences.
+	Punch(userID uint, typ PunchType, req PunchRequest) (*Punch, error)
+	// Status returns whether the user is clocked in.
+	Status(userID uint) (*Status, error)
+	// Mine lists the user's punches, newest first.
+	Mine(userID uint, filter Filter, page utils.Pagination) ([]Punch, int64, error)
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]PunchDetail, int64, error)
+	// Expectations tells, for each employee, whether date was a working day for them and whether they
+	// clocked in. Employees aren't expected on rest days and holidays of their calendar, or before their hire
+	// date.
+	Expectations(orgID *uint, date time.Time, filter ExpectationFilter, page utils.Pagination) ([]Expectation, int64, error)
+
+	Geofences(orgID *uint) ([]Geofence, error)
+	GetGeofence(orgID *uint, id uint) (*Geofence, error)
+	CreateGeofence(actor audit.Actor, orgID *uint, req GeofenceRequest) (*Geofence, error)
+	UpdateGeofence(actor audit.Actor, orgID *uint, id, expectedVersion uint, req GeofenceRequest) (*Geofence, error)
+	DeleteGeofence(actor audit.Actor, orgID *uint, id uint) error
+
+	Kiosks(orgID *uint) ([]Kiosk, error)
+	GetKiosk(orgID *uint, id uint) (*Kiosk, error)
+	CreateKiosk(actor audit.Actor, orgID *uint, req KioskRequest) (*KioskCredentials, error)
+	UpdateKiosk(actor audit.Actor, orgID *uint, id, expectedVersion uint, req KioskRequest) (*Kiosk, error)
+	DeleteKiosk(actor audit.Actor, orgID *uint, id uint) error
+	// ResetKiosk issues a new device token and signing secret, e.g. after a kiosk was lost: the old token
+	// stops working, and so do codes shown before.
+	ResetKiosk(actor audit.Actor, orgID *uint, id uint) (*KioskCredentials, error)
+	// KioskCode returns the code the kiosk currently shows, for HR to preview.
+	KioskCode(orgID *uint, id uint) (*KioskCode, error)
+	// DeviceCode returns the current code of the active kiosk holding the device token.
+	DeviceCode(token string) (*KioskCode, error)
+
+	// Policy returns the organization's attendance settings, or DefaultPolicy.
+	Policy(orgID *uint) (*Policy, error)
+	SetPolicy(actor audit.Actor, orgID *uint, req PolicyRequest) (*Policy, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	holidays  holiday.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Punches are recorded for the employee record of the user;
+// holidays tells which days employees are expected at work.
+func NewService(db *gorm.DB, employees employee.Service, holidays holiday.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, holidays: holidays, auditor: auditor}
+}
+
+// Punch checks the punch's location under the organization's policy: refused or flagged outside the
+// geofences, depending on the mode.
+func (s *service) Punch(userID uint, typ PunchType, req PunchRequest) (*Punch, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude go together", ErrInvalidPunch)
+	}
+	emp, err := s.employee(userID)
+	if err != nil {
+		return nil, err
+	}
+	var punch *Punch
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Serializes an employee's punches, e.g. a double-clicked clock-in button.
+		if err := lock.Tx(tx, fmt.Sprintf("attendance:%d", emp.ID)); err != nil {
+			return err
+		}
+		last, err := lastPunch(tx, emp.ID)
+		if err != nil {
+			return err
+		}
+		clockedIn := last != nil && last.Type == PunchIn
+		if typ == PunchIn && clockedIn {
+			return ErrAlreadyClockedIn
+		}
+		if typ == PunchOut && !clockedIn {
+			return ErrNotClockedIn
+		}
+		policy, err := s.policy(tx, emp.OrganizationID)
+		if err != nil {
+			return err
+		}
+		var v verdict
+		var kioskID *uint
+		if req.KioskCode != "" {
+			kiosk, err := scannedKiosk(tx, emp.OrganizationID, req.KioskCode)
+			if err != nil {
+				return err
+			}
+			v.GeofenceID, kioskID = kiosk.GeofenceID, &kiosk.ID
+		} else {
+			var fences []Geofence
+			if policy.GeofenceMode != GeofenceOff || req.Latitude != nil {
+				if err := utils.OrgScope(tx.Where("active"), emp.OrganizationID).Find(&fences).Error; err != nil {
+					return fmt.Errorf("failed to load geofences: %w", err)
+				}
+			}
+			v = locate(*policy, fences, req)
+		}
+		punch = &Punch{
+			OrganizationID: emp.OrganizationID,
+			EmployeeID:     emp.ID,
+			Type:           typ,
+			At:             clock.Now().UTC(),
+			Latitude:       req.Latitude,
+			Longitude:      req.Longitude,
+			AccuracyMeters: req.AccuracyMeters,
+			GeofenceID:     v.GeofenceID,
+			DistanceMeters: v.Distance,
+			KioskID:        kioskID,
+			Note:           strings.TrimSpace(req.Note),
+		}
+		if v.Reason != "" {
+			switch policy.GeofenceMode {
+			case GeofenceReject:
+				return fmt.Errorf("%w: %s", ErrLocationRejected, v.Reason)
+			case GeofenceFlag:
+				punch.Flagged, punch.FlagReason = true, v.Reason
+			}
+		}
+		if err := tx.Create(punch).Error; err != nil {
+			return fmt.Errorf("failed to record punch: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return punch, nil
+}
+
+func (s *service) Status(userID uint) (*Status, error) {
+	emp, err := s.employee(userID)
+	if err != nil {
+		return nil, err
+	}
+	last, err := lastPunch(s.db, emp.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{ClockedIn: last != nil && last.Type == PunchIn, Last: last}, nil
+}
+
+func (s *service) Mine(userID uint, filter Filter, page utils.Pagination) ([]Punch, int64, error) {
+	emp, err := s.employee(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.EmployeeID = &emp.ID
+	query := filtered(s.db.Model(&Punch{}), filter)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count punches: %w", err)
+	}
+	punches := []Punch{}
+	if err := query.Order("at DESC, id DESC").Scopes(page.Scope).Find(&punches).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list punches: %w", err)
+	}
+	return punches, total, nil
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]PunchDetail, int64, error) {
+	query := filtered(s.db.Model(&Punch{}), filter).
+		Joins("JOIN employees ON employees.id = punches.employee_id").
+		Joins("JOIN users ON users.id = employees.user_id")
+	if orgID != nil {
+		query = query.Where("punches.organization_id = ?", *orgID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count punches: %w", err)
+	}
+	punches := []PunchDetail{}
+	if err := query.Select("punches.*, users.username").Order("punches.at DESC, punches.id DESC").
+		Scopes(page.Scope).Find(&punches).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list punches: %w", err)
+	}
+	return punches, total, nil
+}
+
+// Expectations works out the whole day before paginating, so absent_only pages through absentees alone.
+func (s *service) Expectations(orgID *uint, date time.Time, filter ExpectationFilter, page utils.Pagination) ([]Expectation, int64, error) {
+	employees := utils.OrgScope(s.db.Table("employees").Where("deleted_at IS NULL AND hire_date <= ?", date.Format("2006-01-02")), orgID)
+	if filter.DivisionID != nil {
+		employees = employees.Where("division_id = ?", *filter.DivisionID)
+	}
+	var ids []uint
+	if err := employees.Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load employees: %w", err)
+	}
+	schedule, err := s.holidays.Schedule(orgID, ids, date)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The day runs from midnight to midnight in the organization's timezone.
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, schedule.Location)
+	var firstIns []struct {
+		EmployeeID uint
+		At         time.Time
+	}
+	if len(ids) > 0 {
+		if err := s.db.Model(&Punch{}).Select("employee_id, MIN(at) AS at").
+			Where("employee_id IN ? AND type = ? AND at >= ? AND at < ?", ids, PunchIn, start.UTC(), start.AddDate(0, 0, 1).UTC()).
+			Group("employee_id").Scan(&firstIns).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to load punches: %w", err)
+		}
+	}
+	clockedIn := make(map[uint]time.Time, len(firstIns))
+	for _, p := range firstIns {
+		clockedIn[p.EmployeeID] = p.At
+	}
+	expectations := make([]Expectation, 0, len(ids))
+	for _, id := range ids {
+		day := schedule.Days[id]
+		e := Expectation{EmployeeID: id, Day: day.Kind}
+		if day.Holiday != nil {
+			e.Holiday = day.Holiday.Name
+		}
+		if at, ok := clockedIn[id]; ok {
+			e.FirstIn = &at
+		}
+		e.Absent = day.Kind == holiday.DayWorking && e.FirstIn == nil
+		if filter.AbsentOnly && !e.Absent {
+			continue
+		}
+		expectations = append(expectations, e)
+	}
+	total := int64(len(expectations))
+	from := min((page.Page-1)*page.PageSize, len(expectations))
+	expectations = slices.Clone(expectations[from:min(from+page.PageSize, len(expectations))])
+	shown := make([]uint, len(expectations))
+	for i, e := range expectations {
+		shown[i] = e.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, shown)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range expectations {
+		expectations[i].DisplayName = names[expectations[i].EmployeeID].Text
+	}
+	return expectations, total, nil
+}
+
+func (s *service) Geofences(orgID *uint) ([]Geofence, error) {
+	fences := []Geofence{}
+	if err := utils.OrgScope(s.db, orgID).Order("name").Find(&fences).Error; err != nil {
+		return nil, fmt.Errorf("failed to list geofences: %w", err)
+	}
+	return fences, nil
+}
+
+func (s *service) GetGeofence(orgID *uint, id uint) (*Geofence, error) {
+	var fence Geofence
+	if err := utils.OrgScope(s.db, orgID).First(&fence, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &fence, nil
+}
+
+func (s *service) CreateGeofence(actor audit.Actor, orgID *uint, req GeofenceRequest) (*Geofence, error) {
+	fence := Geofence{OrganizationID: orgID}
+	applyGeofence(&fence, req)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&fence).Error; err != nil {
+			return fmt.Errorf("failed to create geofence: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "geofence.create", EntityType: "geofence", EntityID: fmt.Sprintf("%d", fence.ID), After: fence,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fence, nil
+}
+
+// UpdateGeofence replaces the geofence's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdateGeofence(actor audit.Actor, orgID *uint, id, expectedVersion uint, req GeofenceRequest) (*Geofence, error) {
+	var updated Geofence
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Geofence
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		fence := before
+		applyGeofence(&fence, req)
+		if err := utils.UpdateWithVersion(tx, &Geofence{}, id, expectedVersion, map[string]interface{}{
+			"name":          fence.Name,
+			"latitude":      fence.Latitude,
+			"longitude":     fence.Longitude,
+			"radius_meters": fence.RadiusMeters,
+			"active":        fence.Active,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload geofence %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "geofence.update", EntityType: "geofence", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteGeofence removes the geofence; punches keep referring to it as the one they were nearest to.
+func (s *service) DeleteGeofence(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Geofence
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Geofence{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete geofence %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "geofence.delete", EntityType: "geofence", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Kiosks(orgID *uint) ([]Kiosk, error) {
+	kiosks := []Kiosk{}
+	if err := utils.OrgScope(s.db, orgID).Order("name").Find(&kiosks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list kiosks: %w", err)
+	}
+	return kiosks, nil
+}
+
+func (s *service) GetKiosk(orgID *uint, id uint) (*Kiosk, error) {
+	var kiosk Kiosk
+	if err := utils.OrgScope(s.db, orgID).First(&kiosk, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &kiosk, nil
+}
+
+func (s *service) CreateKiosk(actor audit.Actor, orgID *uint, req KioskRequest) (*KioskCredentials, error) {
+	secret, token, err := newKioskCredentials()
+	if err != nil {
+		return nil, err
+	}
+	kiosk := Kiosk{OrganizationID: orgID, Secret: secret, TokenHash: hashKioskToken(token)}
+	applyKiosk(&kiosk, req)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkKioskGeofence(tx, orgID, kiosk.GeofenceID); err != nil {
+			return err
+		}
+		if err := tx.Create(&kiosk).Error; err != nil {
+			return fmt.Errorf("failed to create kiosk: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "kiosk.create", EntityType: "kiosk", EntityID: fmt.Sprintf("%d", kiosk.ID), After: kiosk,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KioskCredentials{Kiosk: kiosk, Token: token}, nil
+}
+
+// UpdateKiosk replaces the kiosk's fields if it is still at expectedVersion (optimistic locking). Its token
+// and secret are kept; see ResetKiosk.
+func (s *service) UpdateKiosk(actor audit.Actor, orgID *uint, id, expectedVersion uint, req KioskRequest) (*Kiosk, error) {
+	var updated Kiosk
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Kiosk
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		kiosk := before
+		applyKiosk(&kiosk, req)
+		if err := checkKioskGeofence(tx, orgID, kiosk.GeofenceID); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Kiosk{}, id, expectedVersion, map[string]interface{}{
+			"name":           kiosk.Name,
+			"geofence_id":    kiosk.GeofenceID,
+			"rotate_seconds": kiosk.RotateSeconds,
+			"active":         kiosk.Active,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload kiosk %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "kiosk.update", EntityType: "kiosk", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteKiosk removes the kiosk; its token and codes stop working, punches keep referring to it.
+func (s *service) DeleteKiosk(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Kiosk
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Kiosk{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete kiosk %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "kiosk.delete", EntityType: "kiosk", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) ResetKiosk(actor audit.Actor, orgID *uint, id uint) (*KioskCredentials, error) {
+	secret, token, err := newKioskCredentials()
+	if err != nil {
+		return nil, err
+	}
+	var kiosk Kiosk
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx, orgID).Clauses(clause.Locking{Strength: "UPDATE"}).First(&kiosk, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&kiosk).Updates(map[string]interface{}{
+			"secret": secret, "token_hash": hashKioskToken(token), "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset kiosk %d: %w", id, err)
+		}
+		if err := tx.First(&kiosk, id).Error; err != nil {
+			return fmt.Errorf("failed to reload kiosk %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "kiosk.reset", EntityType: "kiosk", EntityID: fmt.Sprintf("%d", id),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KioskCredentials{Kiosk: kiosk, Token: token}, nil
+}
+
+func (s *service) KioskCode(orgID *uint, id uint) (*KioskCode, error) {
+	kiosk, err := s.GetKiosk(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	code := kioskCode(*kiosk, clock.Now())
+	return &code, nil
+}
+
+func (s *service) DeviceCode(token string) (*KioskCode, error) {
+	if !strings.HasPrefix(token, kioskTokenPrefix) {
+		return nil, ErrInvalidKioskToken
+	}
+	var kiosks []Kiosk
+	if err := s.db.Where("token_hash = ? AND active", hashKioskToken(token)).Limit(1).Find(&kiosks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load kiosk: %w", err)
+	}
+	if len(kiosks) == 0 {
+		return nil, ErrInvalidKioskToken
+	}
+	code := kioskCode(kiosks[0], clock.Now())
+	return &code, nil
+}
+
+func (s *service) Policy(orgID *uint) (*Policy, error) {
+	return s.policy(s.db, orgID)
+}
+
+func (s *service) SetPolicy(actor audit.Actor, orgID *uint, req PolicyRequest) (*Policy, error) {
+	var updated Policy
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Organizations have one policy row; the lock keeps two first saves from creating two.
+		if err := lock.Tx(tx, fmt.Sprintf("attendance-policy:%s", orgKey(orgID))); err != nil {
+			return err
+		}
+		before, err := s.policy(tx, orgID)
+		if err != nil {
+			return err
+		}
+		updated = *before
+		updated.GeofenceMode = req.GeofenceMode
+		updated.MaxAccuracyMeters = req.MaxAccuracyMeters
+		if err := tx.Save(&updated).Error; err != nil {
+			return fmt.Errorf("failed to save attendance policy: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "attendance_policy.update", EntityType: "attendance_policy", EntityID: orgKey(orgID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// employee returns the employee record of a user.
+func (s *service) employee(userID uint) (*employee.Detail, error) {
+	emp, err := s.employees.ForUser(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoEmployee
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load employee record: %w", err)
+	}
+	return emp, nil
+}
+
+func (s *service) policy(db *gorm.DB, orgID *uint) (*Policy, error) {
+	var policies []Policy
+	if err := utils.OrgScope(db, orgID).Limit(1).Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load attendance policy: %w", err)
+	}
+	if len(policies) == 0 {
+		policy := DefaultPolicy(orgID)
+		return &policy, nil
+	}
+	return &policies[0], nil
+}
+
+// lastPunch returns the employee's latest punch, or nil.
+func lastPunch(db *gorm.DB, employeeID uint) (*Punch, error) {
+	var punches []Punch
+	if err := db.Where("employee_id = ?", employeeID).Order("at DESC, id DESC").Limit(1).Find(&punches).Error; err != nil {
+		return nil, fmt.Errorf("failed to load last punch: %w", err)
+	}
+	if len(punches) == 0 {
+		return nil, nil
+	}
+	return &punches[0], nil
+}
+
+// scannedKiosk returns the active kiosk of the organization that showed a scanned code.
+func scannedKiosk(db *gorm.DB, orgID *uint, code string) (*Kiosk, error) {
+	id, window, signature, ok := parseKioskCode(code)
+	if !ok {
+		return nil, ErrInvalidKioskCode
+	}
+	var kiosk Kiosk
+	err := utils.OrgScope(db.Where("active"), orgID).First(&kiosk, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidKioskCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kiosk: %w", err)
+	}
+	if !validKioskCode(kiosk, window, signature, clock.Now()) {
+		return nil, ErrInvalidKioskCode
+	}
+	return &kiosk, nil
+}
+
+// checkKioskGeofence checks that a kiosk's geofence belongs to the organization.
+func checkKioskGeofence(db *gorm.DB, orgID *uint, geofenceID *uint) error {
+	if geofenceID == nil {
+		return nil
+	}
+	var count int64
+	if err := utils.OrgScope(db.Model(&Geofence{}), orgID).Where("id = ?", *geofenceID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check geofence: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: geofence %d not found", ErrInvalidKiosk, *geofenceID)
+	}
+	return nil
+}
+
+func filtered(query *gorm.DB, filter Filter) *gorm.DB {
+	if filter.EmployeeID != nil {
+		query = query.Where("punches.employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.FlaggedOnly {
+		query = query.Where("punches.flagged")
+	}
+	if filter.From != nil {
+		query = query.Where("punches.at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("punches.at < ?", *filter.To)
+	}
+	return query
+}
+
+func applyGeofence(fence *Geofence, req GeofenceRequest) {
+	fence.Name = strings.TrimSpace(req.Name)
+	fence.Latitude = *req.Latitude
+	fence.Longitude = *req.Longitude
+	fence.RadiusMeters = req.RadiusMeters
+	fence.Active = req.Active == nil || *req.Active
+}
+
+func applyKiosk(kiosk *Kiosk, req KioskRequest) {
+	kiosk.Name = strings.TrimSpace(req.Name)
+	kiosk.GeofenceID = req.GeofenceID
+	kiosk.RotateSeconds = req.RotateSeconds
+	if kiosk.RotateSeconds == 0 {
+		kiosk.RotateSeconds = defaultRotateSeconds
+	}
+	kiosk.Active = req.Active == nil || *req.Active
+}
+
+// orgKey names an organization in lock names and audit entries.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
@@ -0,0 +1,71 @@
+// prometheus/backend/internal/audit/handler.go
+package audit
+
+import (
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for the audit trail.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of the audit Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns audit records, newest first.
+// @Summary List audit records
+// @Tags Audit
+// @Produce json
+// @Param entity_type query string false "Entity type (e.g. policy, user_role)"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action (e.g. policy.create)"
+// @Param actor_id query int false "Actor user ID"
+// @Param from query string false "Start time (RFC3339)"
+// @Param to query string false "End time (RFC3339)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /admin/audit-logs [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Action:     c.Query("action"),
+	}
+	if raw := c.Query("actor_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid actor_id parameter")
+			return
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected RFC3339")
+				return
+			}
+			*target = &t
+		}
+	}
+
+	page := utils.ParsePagination(c)
+	logs, total, err := h.service.List(filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Audit records fetched successfully", page.Response(logs, total))
+}
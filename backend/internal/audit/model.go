@@ -0,0 +1,122 @@
+// prometheus/backend/internal/audit/model.go
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/lock"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrImmutable is returned when something tries to modify or delete an audit record.
+var ErrImmutable = errors.New("audit records are immutable")
+
+// Log is an immutable record of a security-relevant change.
+type Log struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	ActorID       *uint          `gorm:"index" json:"actor_id,omitempty" example:"1"` // nil for system actions (seeders, jobs)
+	ActorUsername string         `gorm:"type:varchar(100)" json:"actor_username,omitempty" example:"godadmin"`
+	ActorIP       string         `gorm:"type:varchar(64)" json:"actor_ip,omitempty" example:"10.0.0.12"`
+	Action        string         `gorm:"type:varchar(100);not null;index" json:"action" example:"policy.create"`
+	EntityType    string         `gorm:"type:varchar(100);not null;index:idx_audit_entity" json:"entity_type" example:"policy"`
+	EntityID      string         `gorm:"type:varchar(255);index:idx_audit_entity" json:"entity_id" example:"hr|default|/api/v1/hr/*|GET"`
+	Before        datatypes.JSON `json:"before,omitempty" swaggertype:"object"`
+	After         datatypes.JSON `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt     time.Time      `gorm:"index" json:"created_at"`
+}
+
+// TableName keeps the table name explicit, since "logs" would be ambiguous.
+func (Log) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeUpdate rejects updates so audit records cannot be rewritten through GORM.
+func (l *Log) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// BeforeDelete rejects deletes so audit records cannot be removed through GORM.
+func (l *Log) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// The hooks above only guard the model; Protect installs the same rule in the database, where table
+// updates and raw SQL can't get around it.
+var protectStatements = []string{
+	`CREATE OR REPLACE FUNCTION audit_logs_immutable() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'UPDATE' AND current_setting('` + erasureSetting + `', true) = 'on' THEN
+			RETURN NEW;
+		END IF;
+		RAISE EXCEPTION 'audit records are immutable' USING ERRCODE = 'insufficient_privilege';
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_logs_immutable ON audit_logs`,
+	`CREATE TRIGGER audit_logs_immutable BEFORE UPDATE OR DELETE ON audit_logs
+		FOR EACH ROW EXECUTE FUNCTION audit_logs_immutable()`,
+	`DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs`,
+	`CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_immutable()`,
+}
+
+// erasureSetting is the transaction-local setting that lets updates through the trigger, see AllowErasure.
+const erasureSetting = "audit.erasure"
+
+// Protect installs the trigger rejecting updates, deletes and truncation of audit_logs. It is safe to run
+// on every start; the lock keeps replicas starting together from replacing the trigger at the same time.
+// Must run after AutoMigrate so that audit_logs exists.
+func Protect(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, "audit:protect"); err != nil {
+			return err
+		}
+		for _, statement := range protectStatements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to protect audit records: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// AllowErasure lets tx update audit records, for scrubbing personal data on erasure requests; it is the
+// one exception to audit records being immutable. Deletes stay rejected, and the permission ends with tx.
+func AllowErasure(tx *gorm.DB) error {
+	if err := tx.Exec("SELECT set_config(?, 'on', true)", erasureSetting).Error; err != nil {
+		return fmt.Errorf("failed to allow audit erasure: %w", err)
+	}
+	return nil
+}
+
+// Actor identifies who performed an audited action.
+type Actor struct {
+	UserID         *uint
+	Username       string
+	IP             string
+	OrganizationID *uint // The actor's organization; nil for platform admins and the system
+}
+
+// SystemActor is used for changes made by the system itself (seeders, scheduled jobs).
+var SystemActor = Actor{Username: "system"}
+
+// Entry describes an audited change. Before/After are JSON-encoded snapshots (nil for create/delete).
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     interface{}
+	After      interface{}
+}
+
+// Filter narrows an audit log listing.
+type Filter struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	ActorID    *uint
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,128 @@
+// prometheus/backend/internal/audit/service.go
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Service defines the interface for writing and querying the audit trail.
+type Service interface {
+	// Record writes an audit entry. Failures are returned so callers can decide whether to abort.
+	Record(actor Actor, entry Entry) error
+	// RecordTx writes an audit entry inside tx, so it commits or rolls back with the change itself.
+	RecordTx(tx *gorm.DB, actor Actor, entry Entry) error
+	List(filter Filter, page utils.Pagination) ([]Log, int64, error)
+}
+
+// Hook runs for every audit record inside the transaction writing it, e.g. to publish the change as a
+// domain event. An error aborts the audited change like a failed audit write does.
+type Hook func(tx *gorm.DB, actor Actor, record *Log) error
+
+// service implements the Service interface.
+type service struct {
+	db    *gorm.DB
+	hooks []Hook
+}
+
+// NewService creates a new instance of the audit Service.
+func NewService(db *gorm.DB, hooks ...Hook) Service {
+	return &service{db: db, hooks: hooks}
+}
+
+// ActorFromContext builds an Actor from the claims set by AuthMiddleware.
+func ActorFromContext(c *gin.Context) Actor {
+	actor := Actor{Username: c.GetString("username"), IP: c.ClientIP()}
+	if id, ok := c.Get("userID"); ok {
+		if uid, ok := id.(uint); ok {
+			actor.UserID = &uid
+		}
+	}
+	if id, ok := c.Get("orgID"); ok {
+		if orgID, ok := id.(uint); ok {
+			actor.OrganizationID = &orgID
+		}
+	}
+	return actor
+}
+
+func (s *service) Record(actor Actor, entry Entry) error {
+	return s.RecordTx(s.db, actor, entry)
+}
+
+func (s *service) RecordTx(tx *gorm.DB, actor Actor, entry Entry) error {
+	record := Log{
+		ActorID:       actor.UserID,
+		ActorUsername: actor.Username,
+		ActorIP:       actor.IP,
+		Action:        entry.Action,
+		EntityType:    entry.EntityType,
+		EntityID:      entry.EntityID,
+	}
+	var err error
+	if record.Before, err = snapshot(entry.Before); err != nil {
+		return err
+	}
+	if record.After, err = snapshot(entry.After); err != nil {
+		return err
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		log.Printf("Error writing audit record %s %s/%s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	for _, hook := range s.hooks {
+		if err := hook(tx, actor, &record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) List(filter Filter, page utils.Pagination) ([]Log, int64, error) {
+	query := s.db.Model(&Log{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit records: %w", err)
+	}
+	var logs []Log
+	if err := query.Scopes(page.Scope).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit records: %w", err)
+	}
+	return logs, total, nil
+}
+
+// snapshot JSON-encodes a before/after value; nil stays nil.
+func snapshot(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return data, nil
+}
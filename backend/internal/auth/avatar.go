@@ -0,0 +1,305 @@
+// prometheus/backend/internal/auth/avatar.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxAvatarSize is the largest avatar upload accepted, in bytes.
+const MaxAvatarSize = 2 << 20
+
+// avatarURLTTL is how long a signed avatar URL stays valid.
+const avatarURLTTL = 15 * time.Minute
+
+// avatarTypes maps the accepted image types, as sniffed from the content, to file extensions.
+var avatarTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ErrAvatarTooLarge is returned for uploads above MaxAvatarSize.
+var ErrAvatarTooLarge = fmt.Errorf("avatar must not exceed %d MB", MaxAvatarSize>>20)
+
+// ErrAvatarType is returned for uploads that aren't PNG, JPEG, GIF or WebP images.
+var ErrAvatarType = errors.New("avatar must be a PNG, JPEG, GIF or WebP image")
+
+// ErrNoAvatar is returned when the user hasn't uploaded an avatar.
+var ErrNoAvatar = errors.New("user has no avatar")
+
+// Avatar is a user's avatar: either a signed URL to redirect to, or the content to serve.
+type Avatar struct {
+	URL         string
+	Body        io.ReadCloser
+	ContentType string
+}
+
+// AvatarResponse is returned after uploading an avatar.
+type AvatarResponse struct {
+	AvatarURL string `json:"avatar_url" example:"/api/v1/users/7/avatar?v=3f2a9c1e"`
+}
+
+// AvatarService manages user avatars in file storage.
+type AvatarService interface {
+	Upload(ctx context.Context, actor audit.Actor, userID uint, r io.ReadSeeker, size int64) (*AvatarResponse, error)
+	Delete(ctx context.Context, actor audit.Actor, userID uint) error
+	// Get returns the avatar of a user of the organization (nil = any organization).
+	Get(ctx context.Context, orgID *uint, userID uint) (*Avatar, error)
+}
+
+// avatarService implements the AvatarService interface.
+type avatarService struct {
+	db      *gorm.DB
+	files   storage.Storage
+	auditor audit.Service
+}
+
+// NewAvatarService creates a new instance of AvatarService.
+func NewAvatarService(db *gorm.DB, files storage.Storage, auditor audit.Service) AvatarService {
+	return &avatarService{db: db, files: files, auditor: auditor}
+}
+
+// Upload validates the image and stores it as the user's avatar, replacing the previous one.
+// The type is sniffed from the content; the client's declared type is ignored.
+func (s *avatarService) Upload(ctx context.Context, actor audit.Actor, userID uint, r io.ReadSeeker, size int64) (*AvatarResponse, error) {
+	if size > MaxAvatarSize {
+		return nil, ErrAvatarTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrAvatarType
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := avatarTypes[contentType]
+	if !ok {
+		return nil, ErrAvatarType
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+
+	var user User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("avatars/%d/%s%s", userID, uuid.NewString(), ext)
+	if err := s.files.Put(ctx, key, io.LimitReader(r, MaxAvatarSize), size, contentType); err != nil {
+		return nil, err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("avatar_key", key).Error; err != nil {
+			return fmt.Errorf("failed to save avatar: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.avatar.update", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			After: map[string]string{"content_type": contentType},
+		})
+	})
+	if err != nil {
+		s.remove(key)
+		return nil, err
+	}
+	previous := user.AvatarKey
+	if previous != "" && previous != key {
+		s.remove(previous)
+	}
+	return &AvatarResponse{AvatarURL: AvatarURL(userID, key)}, nil
+}
+
+// Delete removes the user's avatar.
+func (s *avatarService) Delete(ctx context.Context, actor audit.Actor, userID uint) error {
+	var user User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return err
+	}
+	if user.AvatarKey == "" {
+		return ErrNoAvatar
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("avatar_key", "").Error; err != nil {
+			return fmt.Errorf("failed to remove avatar: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.avatar.delete", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.remove(user.AvatarKey)
+	return nil
+}
+
+// Get prefers a signed URL so the storage serves the file; backends without them stream it through the API.
+func (s *avatarService) Get(ctx context.Context, orgID *uint, userID uint) (*Avatar, error) {
+	query := utils.OrgScope(s.db.WithContext(ctx).Select("id", "avatar_key"), orgID)
+	var user User
+	if err := query.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.AvatarKey == "" {
+		return nil, ErrNoAvatar
+	}
+
+	url, err := s.files.SignedURL(ctx, user.AvatarKey, avatarURLTTL)
+	if err == nil {
+		return &Avatar{URL: url}, nil
+	}
+	if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+		return nil, err
+	}
+	body, contentType, err := s.files.Open(ctx, user.AvatarKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNoAvatar
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Avatar{Body: body, ContentType: contentType}, nil
+}
+
+// remove deletes an object that is no longer referenced. Failures only leave an orphaned file behind.
+func (s *avatarService) remove(key string) {
+	if err := s.files.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to delete avatar %s: %v", key, err)
+	}
+}
+
+// AvatarURL is the API route serving a user's avatar. The version parameter changes with every upload
+// so clients and proxies can cache each version indefinitely.
+func AvatarURL(userID uint, key string) string {
+	version := strings.TrimSuffix(path.Base(key), path.Ext(key))
+	if len(version) > 8 {
+		version = version[:8]
+	}
+	return fmt.Sprintf("/api/v1/users/%d/avatar?v=%s", userID, version)
+}
+
+// AvatarHandler handles HTTP requests for user avatars.
+type AvatarHandler struct {
+	service AvatarService
+}
+
+// NewAvatarHandler creates a new instance of AvatarHandler.
+func NewAvatarHandler(service AvatarService) *AvatarHandler {
+	return &AvatarHandler{service: service}
+}
+
+// Upload replaces the caller's avatar.
+// @Summary Upload my avatar
+// @Description PNG, JPEG, GIF or WebP, at most 2 MB, as the multipart field "avatar".
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Image"
+// @Success 200 {object} AvatarResponse
+// @Failure 400 {object} utils.ErrorResponse "Missing file"
+// @Failure 413 {object} utils.ErrorResponse "File too large"
+// @Failure 415 {object} utils.ErrorResponse "Unsupported image type"
+// @Router /me/avatar [post]
+func (h *AvatarHandler) Upload(c *gin.Context) {
+	// Leave room for the multipart envelope around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxAvatarSize+64<<10)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendAvatarError(c, ErrAvatarTooLarge)
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Missing multipart file field \"avatar\"")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Could not read the uploaded file")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.service.Upload(c.Request.Context(), audit.ActorFromContext(c), c.GetUint("userID"), file, header.Size)
+	if err != nil {
+		sendAvatarError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Avatar uploaded successfully", resp)
+}
+
+// Delete removes the caller's avatar.
+// @Summary Delete my avatar
+// @Tags Users
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "No avatar"
+// @Router /me/avatar [delete]
+func (h *AvatarHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), audit.ActorFromContext(c), c.GetUint("userID")); err != nil {
+		sendAvatarError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Avatar deleted successfully", nil)
+}
+
+// Get serves a user's avatar, redirecting to a signed storage URL when the backend supports them.
+// @Summary Get a user's avatar
+// @Description Users only see avatars of their own organization.
+// @Tags Users
+// @Produce image/png
+// @Produce image/jpeg
+// @Param id path int true "User ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a signed URL"
+// @Failure 404 {object} utils.ErrorResponse "User or avatar not found"
+// @Router /users/{id}/avatar [get]
+func (h *AvatarHandler) Get(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	avatar, err := h.service.Get(c.Request.Context(), utils.OrganizationFromContext(c), userID)
+	if err != nil {
+		sendAvatarError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	if avatar.URL != "" {
+		c.Redirect(http.StatusFound, avatar.URL)
+		return
+	}
+	defer avatar.Body.Close()
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, -1, avatar.ContentType, avatar.Body, nil)
+}
+
+// sendAvatarError maps service errors to HTTP status codes.
+func sendAvatarError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, ErrNoAvatar):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrAvatarTooLarge):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrAvatarType):
+		utils.SendErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
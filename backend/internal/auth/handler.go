@@ -23,7 +23,7 @@ func NewAuthHandler(service AuthService) *AuthHandler {
 
 // Register handles new user registration requests.
 // @Summary Register a new user
-// @Description Creates a new user account. Default role is 'staff' if not specified.
+// @Description Creates a new user account with the 'staff' role. Other roles are granted by an admin or requested.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -62,11 +62,6 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
 			return
 		}
-		// A bit fragile check for role ID not found error from service layer
-		if _, ok := err.(interface{ Error() string }); ok && len(err.Error()) > 18 && err.Error()[:18] == "role with ID" {
-			utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
 
 		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to register user: "+err.Error())
 		return
@@ -78,12 +73,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Username:  user.Username,
 		Email:     user.Email,
 		IsActive:  user.IsActive,
-		RoleID:    user.RoleID,
+		RoleNames: user.RoleNames(),
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
-	if user.Role.Name != "" { // if role was preloaded
-		userResponse.RoleName = user.Role.Name
+	for _, r := range user.Roles {
+		userResponse.RoleIDs = append(userResponse.RoleIDs, r.ID)
 	}
 
 	utils.SendSuccessResponse(c, http.StatusCreated, "User registered successfully", userResponse)
@@ -108,7 +103,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.service.LoginUser(req)
+	authResponse, err := h.service.LoginUser(req, LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "invalid username or password" {
 			utils.SendErrorResponse(c, http.StatusUnauthorized, "Invalid username or password")
@@ -132,8 +127,8 @@ type UserResponse struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	IsActive  bool      `json:"is_active"`
-	RoleID    uint      `json:"role_id"`
-	RoleName  string    `json:"role_name,omitempty"`
+	RoleIDs   []uint    `json:"role_ids"`
+	RoleNames []string  `json:"role_names,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
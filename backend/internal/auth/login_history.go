@@ -0,0 +1,148 @@
+// prometheus/backend/internal/auth/login_history.go
+package auth
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Login failure reasons recorded in LoginEvent.FailureReason.
+const (
+	LoginFailureUnknownUser     = "unknown_user"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInactive        = "inactive"
+)
+
+// LoginEvent records a login attempt, successful or not.
+type LoginEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        *uint     `gorm:"index" json:"user_id,omitempty" example:"7"`                           // nil when the identifier matched no user
+	Identifier    string    `gorm:"type:varchar(100);not null;index" json:"identifier" example:"johndoe"` // Username or email as entered
+	IP            string    `gorm:"type:varchar(64)" json:"ip" example:"10.0.0.12"`
+	UserAgent     string    `gorm:"type:varchar(512)" json:"user_agent" example:"Mozilla/5.0"`
+	Success       bool      `gorm:"not null" json:"success" example:"true"`
+	FailureReason string    `gorm:"type:varchar(50)" json:"failure_reason,omitempty" example:"invalid_password"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// LoginClient identifies where a login attempt came from.
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// recordLogin stores a login attempt. A failure to record must not lock users out, so it is only logged.
+func recordLogin(db *gorm.DB, user *User, identifier string, client LoginClient, failure string) {
+	event := LoginEvent{
+		Identifier:    truncateString(identifier, 100),
+		IP:            client.IP,
+		UserAgent:     truncateString(client.UserAgent, 512),
+		Success:       failure == "",
+		FailureReason: failure,
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	if err := db.Create(&event).Error; err != nil {
+		log.Printf("Failed to record login attempt for %q: %v", identifier, err)
+	}
+}
+
+// LoginHistoryService reads the login history of users.
+type LoginHistoryService interface {
+	// ForUser returns a user's login attempts, newest first. orgID limits the lookup to the organization's
+	// users (nil = all users); deleted users' history stays available for investigations.
+	ForUser(orgID *uint, userID uint, page utils.Pagination) ([]LoginEvent, int64, error)
+}
+
+// loginHistoryService implements the LoginHistoryService interface.
+type loginHistoryService struct {
+	db *gorm.DB
+}
+
+// NewLoginHistoryService creates a new instance of LoginHistoryService.
+func NewLoginHistoryService(db *gorm.DB) LoginHistoryService {
+	return &loginHistoryService{db: db}
+}
+
+func (s *loginHistoryService) ForUser(orgID *uint, userID uint, page utils.Pagination) ([]LoginEvent, int64, error) {
+	var user User
+	if err := utils.OrgScope(s.db.Unscoped().Select("id"), orgID).First(&user, userID).Error; err != nil {
+		return nil, 0, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	query := s.db.Model(&LoginEvent{}).Where("user_id = ?", userID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count login events: %w", err)
+	}
+	var events []LoginEvent
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list login events: %w", err)
+	}
+	return events, total, nil
+}
+
+// LoginHistoryHandler handles HTTP requests for login histories.
+type LoginHistoryHandler struct {
+	service LoginHistoryService
+}
+
+// NewLoginHistoryHandler creates a new instance of LoginHistoryHandler.
+func NewLoginHistoryHandler(service LoginHistoryService) *LoginHistoryHandler {
+	return &LoginHistoryHandler{service: service}
+}
+
+// Mine returns the caller's login history.
+// @Summary List my login history
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /me/login-history [get]
+func (h *LoginHistoryHandler) Mine(c *gin.Context) {
+	h.respond(c, nil, c.GetUint("userID"))
+}
+
+// ForUser returns a user's login history, including failed attempts.
+// @Summary List a user's login history
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/login-history [get]
+func (h *LoginHistoryHandler) ForUser(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.respond(c, utils.OrganizationFromContext(c), userID)
+}
+
+func (h *LoginHistoryHandler) respond(c *gin.Context, orgID *uint, userID uint) {
+	page := utils.ParsePagination(c)
+	events, total, err := h.service.ForUser(orgID, userID, page)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Login history fetched successfully", page.Response(events, total))
+}
+
+// truncateString cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -4,23 +4,35 @@ package auth
 import (
 	"time"
 
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/organization"
 	"prometheus/backend/internal/role" // Import the role package
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 // User represents a user account in the system.
 type User struct {
 	gorm.Model
-	Username string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"username" binding:"required" example:"johndoe"`
-	Email    string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email" binding:"required,email" example:"john.doe@example.com"`
-	Password string    `gorm:"type:varchar(255);not null" json:"-" binding:"required"` // Store hashed password, '-' to omit from JSON
-	IsActive bool      `gorm:"default:true;not null" json:"is_active" example:"true"`
-	RoleID   uint      `json:"role_id" example:"1"`                                                          // example:"1" ; removed binding:"required" to allow default role assignment
-	Role     role.Role `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"role"` // Belongs To relationship with Role
-
-	LastLogin *time.Time `json:"last_login,omitempty"`
+	Username string      `gorm:"type:varchar(100);uniqueIndex;not null" json:"username" binding:"required" example:"johndoe"`
+	Email    string      `gorm:"type:varchar(100);uniqueIndex;not null" json:"email" binding:"required,email" example:"john.doe@example.com"`
+	Password string      `gorm:"type:varchar(255);not null" json:"-" binding:"required"` // Store hashed password, '-' to omit from JSON
+	IsActive bool        `gorm:"default:true;not null" json:"is_active" example:"true"`
+	Roles    []role.Role `gorm:"many2many:user_roles;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"roles"` // Many-to-many via user_roles; a user may be e.g. both "manager" and "hr"
+
+	ScopedRoles []ScopedRole `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"scoped_roles,omitempty"` // Division-scoped roles, e.g. "manager of Engineering"
+
+	OrganizationID *uint                      `gorm:"index" json:"organization_id,omitempty" example:"1"` // nil = default (single-tenant) organization
+	Organization   *organization.Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:SET NULL;" json:"organization,omitempty"`
+
+	LastLogin       *time.Time     `json:"last_login,omitempty"`
+	TokensRevokedAt *time.Time     `json:"-"`                                             // Tokens issued up to this instant are rejected, see UserAdminService.ForceLogout
+	CustomFields    datatypes.JSON `gorm:"type:jsonb" json:"-"`                           // Values of the organization's custom fields, see package customfield
+	AvatarKey       string         `gorm:"type:varchar(255)" json:"-"`                    // Storage key of the uploaded avatar, see AvatarService
+	AnonymizedAt    *time.Time     `json:"-"`                                             // Personal data was scrubbed, see package privacy
+	Version         uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
 	// RefreshToken string `gorm:"type:varchar(512);index" json:"-"` // If refresh tokens are implemented, consider length and indexing
 }
 
@@ -35,16 +47,22 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=100" example:"janedoe"`
 	Email    string `json:"email" binding:"required,email" example:"jane.doe@example.com"`
 	Password string `json:"password" binding:"required,min=6,max=72" example:"SecurePassword123"` // Max 72 for bcrypt compatibility
-	RoleID   uint   `json:"role_id,omitempty" example:"2"`                                        // Optional: if not provided, service might assign a default role
 }
 
 // Claims defines the JWT claims structure
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"` // Role name (e.g., "admin", "staff")
+	UserID   uint     `json:"user_id"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Roles    []string `json:"roles"` // Role names (e.g., ["manager", "hr"])
+
+	RoleExpiry map[string]int64 `json:"role_exp,omitempty"` // Expiry (unix seconds) of time-limited roles in Roles
+
+	ScopedRoles []ScopedRoleClaim `json:"scoped_roles,omitempty"` // Division-scoped roles
+
+	OrganizationID *uint  `json:"org_id,omitempty"`
+	Domain         string `json:"domain,omitempty"` // Authorization domain (organization slug); empty = default domain
 }
 
 // AuthResponse defines the structure for authentication responses (e.g., login success)
@@ -56,11 +74,46 @@ type AuthResponse struct {
 
 // UserCompact defines a compact user structure for API responses
 type UserCompact struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	RoleName string `json:"role_name"`
-	IsActive bool   `json:"is_active"`
+	ID        uint     `json:"id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	RoleNames []string `json:"role_names"`
+	IsActive  bool     `json:"is_active"`
+}
+
+// RoleNames returns the names of the user's roles. Roles must be preloaded.
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, r := range u.Roles {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// ScopedRoleClaims converts the user's scoped roles to JWT claims. ScopedRoles.Role must be preloaded.
+func (u *User) ScopedRoleClaims() []ScopedRoleClaim {
+	claims := make([]ScopedRoleClaim, 0, len(u.ScopedRoles))
+	for _, sr := range u.ScopedRoles {
+		if sr.ExpiresAt != nil && !sr.ExpiresAt.After(clock.Now()) {
+			continue // Expired, awaiting removal by the expiry job
+		}
+		claim := ScopedRoleClaim{Role: sr.Role.Name, DivisionID: sr.DivisionID}
+		if sr.ExpiresAt != nil {
+			claim.ExpiresAt = sr.ExpiresAt.Unix()
+		}
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// HasRole reports whether the user holds the named role. Roles must be preloaded.
+func (u *User) HasRole(name string) bool {
+	for _, r := range u.Roles {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // TokenDetails was present in your initial files but not used.
@@ -0,0 +1,233 @@
+// prometheus/backend/internal/auth/preferences.go
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxUIPreferences bounds the free-form "ui" preferences, in bytes of JSON.
+const maxUIPreferences = 16 << 10
+
+// maxNotificationSettings bounds the number of notification toggles.
+const maxNotificationSettings = 50
+
+// localePattern accepts BCP 47 tags of the usual shape, e.g. "en", "de-CH", "zh-Hant-TW".
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{4})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+// notificationKeyPattern restricts notification setting names, e.g. "email.leave_approved".
+var notificationKeyPattern = regexp.MustCompile(`^[a-z0-9_.]{1,64}$`)
+
+// clockPattern matches a time of day such as "22:00".
+var clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// Digest modes: how non-urgent notifications are emailed, see package notification.
+const (
+	DigestImmediate = "immediate" // One email per notification
+	DigestHourly    = "hourly"    // One summary per hour
+	DigestDaily     = "daily"     // One summary per day, in the morning of the user's timezone
+)
+
+// ErrInvalidPreferences is returned when preferences fail validation.
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// Preferences are a user's personal settings. Locale, timezone and notifications are understood by the
+// backend (e.g. for emails and scheduled reports); "ui" is kept as is for the frontend.
+type Preferences struct {
+	Locale        string          `json:"locale" example:"en-US"`
+	Timezone      string          `json:"timezone" example:"Europe/Berlin"`
+	Notifications map[string]bool `json:"notifications"`
+	Digest        string          `json:"digest" example:"daily"` // immediate, hourly or daily
+	QuietHours    *QuietHours     `json:"quiet_hours,omitempty"`
+	UI            json.RawMessage `json:"ui,omitempty" swaggertype:"object"`
+}
+
+// QuietHours is a daily period, in the user's timezone, during which only critical notifications are
+// emailed; the others wait until it ends. End before start spans midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Start string `json:"start" example:"22:00"`
+	End   string `json:"end" example:"07:00"`
+}
+
+// DefaultPreferences apply to users who haven't saved any.
+func DefaultPreferences() Preferences {
+	return Preferences{Locale: "en", Timezone: "UTC", Notifications: map[string]bool{}, Digest: DigestImmediate}
+}
+
+// UserPreferences stores a user's Preferences as a JSON document.
+type UserPreferences struct {
+	UserID    uint           `gorm:"primaryKey;autoIncrement:false"`
+	Data      datatypes.JSON `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName implements gorm's Tabler.
+func (UserPreferences) TableName() string { return "user_preferences" }
+
+// validate checks the preferences and fills in defaults for omitted fields.
+func (p *Preferences) validate() error {
+	defaults := DefaultPreferences()
+	if p.Locale == "" {
+		p.Locale = defaults.Locale
+	}
+	if !localePattern.MatchString(p.Locale) {
+		return fmt.Errorf("%w: locale %q is not a language tag such as en-US", ErrInvalidPreferences, p.Locale)
+	}
+	if p.Timezone == "" {
+		p.Timezone = defaults.Timezone
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPreferences, p.Timezone)
+	}
+	if p.Notifications == nil {
+		p.Notifications = defaults.Notifications
+	}
+	if len(p.Notifications) > maxNotificationSettings {
+		return fmt.Errorf("%w: at most %d notification settings", ErrInvalidPreferences, maxNotificationSettings)
+	}
+	for key := range p.Notifications {
+		if !notificationKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: invalid notification setting %q", ErrInvalidPreferences, key)
+		}
+	}
+	switch p.Digest {
+	case "":
+		p.Digest = defaults.Digest
+	case DigestImmediate, DigestHourly, DigestDaily:
+	default:
+		return fmt.Errorf("%w: digest must be immediate, hourly or daily", ErrInvalidPreferences)
+	}
+	if q := p.QuietHours; q != nil {
+		if !clockPattern.MatchString(q.Start) || !clockPattern.MatchString(q.End) {
+			return fmt.Errorf("%w: quiet hours must start and end at a time such as 22:00", ErrInvalidPreferences)
+		}
+		if q.Start == q.End {
+			return fmt.Errorf("%w: quiet hours must not start and end at the same time", ErrInvalidPreferences)
+		}
+	}
+	if len(p.UI) > maxUIPreferences {
+		return fmt.Errorf("%w: ui preferences must not exceed %d KB", ErrInvalidPreferences, maxUIPreferences>>10)
+	}
+	if len(p.UI) > 0 && (p.UI[0] != '{' || !json.Valid(p.UI)) {
+		return fmt.Errorf("%w: ui preferences must be an object", ErrInvalidPreferences)
+	}
+	return nil
+}
+
+// PreferenceService manages users' personal preferences.
+type PreferenceService interface {
+	Get(userID uint) (*Preferences, error)
+	// Put replaces the user's preferences; omitted fields are reset to their defaults.
+	Put(userID uint, prefs Preferences) (*Preferences, error)
+}
+
+// preferenceService implements the PreferenceService interface.
+type preferenceService struct {
+	db *gorm.DB
+}
+
+// NewPreferenceService creates a new instance of PreferenceService.
+func NewPreferenceService(db *gorm.DB) PreferenceService {
+	return &preferenceService{db: db}
+}
+
+func (s *preferenceService) Get(userID uint) (*Preferences, error) {
+	var stored UserPreferences
+	err := s.db.Where("user_id = ?", userID).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		prefs := DefaultPreferences()
+		return &prefs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load preferences: %w", err)
+	}
+	prefs := DefaultPreferences()
+	if err := json.Unmarshal(stored.Data, &prefs); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+func (s *preferenceService) Put(userID uint, prefs Preferences) (*Preferences, error) {
+	if err := prefs.validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	stored := UserPreferences{UserID: userID, Data: data}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+	}).Create(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// PreferenceHandler handles HTTP requests for the caller's preferences.
+type PreferenceHandler struct {
+	service PreferenceService
+}
+
+// NewPreferenceHandler creates a new instance of PreferenceHandler.
+func NewPreferenceHandler(service PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{service: service}
+}
+
+// Get returns the caller's preferences, or the defaults if none were saved.
+// @Summary Get my preferences
+// @Tags Users
+// @Produce json
+// @Success 200 {object} Preferences
+// @Router /me/preferences [get]
+func (h *PreferenceHandler) Get(c *gin.Context) {
+	prefs, err := h.service.Get(c.GetUint("userID"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Preferences fetched successfully", prefs)
+}
+
+// Put replaces the caller's preferences.
+// @Summary Save my preferences
+// @Description Replaces all preferences; omitted fields are reset to their defaults (locale "en", timezone "UTC",
+// @Description digest "immediate"). Notification settings named "email.<category>" turn off emails of a category.
+// @Description During "quiet_hours" (in the user's timezone) only critical notifications are emailed.
+// @Description "ui" is a free-form object of at most 16 KB for the frontend.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param preferences body Preferences true "Preferences"
+// @Success 200 {object} Preferences
+// @Failure 400 {object} utils.ErrorResponse "Invalid locale, timezone or settings"
+// @Router /me/preferences [put]
+func (h *PreferenceHandler) Put(c *gin.Context) {
+	var req Preferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	prefs, err := h.service.Put(c.GetUint("userID"), req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPreferences) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Preferences saved successfully", prefs)
+}
@@ -0,0 +1,115 @@
+// prometheus/backend/internal/auth/role_grant.go
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRole is the user_roles join table. ExpiresAt makes a grant time-limited (e.g. "hr for two weeks
+// while covering parental leave"); expired grants are removed by the JobExpireRoleGrants job.
+type UserRole struct {
+	UserID    uint       `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint       `gorm:"primaryKey" json:"role_id"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// JobExpireRoleGrants is the job type that revokes expired role grants.
+const JobExpireRoleGrants = "auth.expire_role_grants"
+
+// SetupJoinTables registers the custom join models. Must run before AutoMigrate and before any
+// query that touches User.Roles.
+func SetupJoinTables(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&User{}, "Roles", &UserRole{}); err != nil {
+		return fmt.Errorf("failed to set up user_roles join table: %w", err)
+	}
+	return nil
+}
+
+// GrantRole gives the user a role, optionally until expiresAt. Re-granting an existing role replaces
+// its expiry, so a permanent grant can be made temporary and vice versa.
+func GrantRole(tx *gorm.DB, userID, roleID uint, expiresAt *time.Time) error {
+	grant := UserRole{UserID: userID, RoleID: roleID, ExpiresAt: expiresAt}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&grant).Error
+}
+
+// RoleExpiries returns the expiry of each of the user's time-limited roles, keyed by role name,
+// including grants that already lapsed but were not yet removed. Permanent roles are not included.
+func RoleExpiries(db *gorm.DB, userID uint) (map[string]time.Time, error) {
+	var rows []struct {
+		Name      string
+		ExpiresAt time.Time
+	}
+	err := db.Table("user_roles").
+		Select("roles.name, user_roles.expires_at").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND user_roles.expires_at IS NOT NULL", userID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role expiries of user %d: %w", userID, err)
+	}
+	expiries := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		expiries[r.Name] = r.ExpiresAt
+	}
+	return expiries, nil
+}
+
+// ExpireRoleGrantsJob returns the job handler that deletes expired global and division-scoped grants
+// and audits each revocation.
+func ExpireRoleGrantsJob(db *gorm.DB, auditor audit.Service) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		now := clock.Now().UTC()
+		revoked := 0
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var expired []UserRole
+			// RETURNING keeps the delete and the audited rows consistent under concurrent runs.
+			if err := tx.Clauses(clause.Returning{}).Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&expired).Error; err != nil {
+				return fmt.Errorf("failed to revoke expired role grants: %w", err)
+			}
+			for _, grant := range expired {
+				if err := auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+					Action: "user.role_expired", EntityType: "user", EntityID: fmt.Sprintf("%d", grant.UserID),
+					Before: grant,
+				}); err != nil {
+					return err
+				}
+			}
+
+			var expiredScoped []ScopedRole
+			// Scoped roles are hard-deleted like in ScopedRoleService.Revoke, so the unique index allows re-granting.
+			if err := tx.Unscoped().Clauses(clause.Returning{}).Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&expiredScoped).Error; err != nil {
+				return fmt.Errorf("failed to revoke expired scoped roles: %w", err)
+			}
+			for _, grant := range expiredScoped {
+				if err := auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+					Action: "user.scoped_role_expired", EntityType: "user", EntityID: fmt.Sprintf("%d", grant.UserID),
+					Before: grant,
+				}); err != nil {
+					return err
+				}
+			}
+			revoked = len(expired) + len(expiredScoped)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if revoked > 0 {
+			log.Printf("Revoked %d expired role grants.", revoked)
+		}
+		return map[string]int{"revoked": revoked}, nil
+	}
+}
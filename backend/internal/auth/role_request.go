@@ -0,0 +1,349 @@
+// prometheus/backend/internal/auth/role_request.go
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ElevatedRoles can only be granted through an approved RoleRequest.
+var ElevatedRoles = []string{"hr", "finance", "admin", "god-admin"}
+
+// IsElevatedRole reports whether granting the role requires god-admin approval.
+func IsElevatedRole(name string) bool {
+	return slices.Contains(ElevatedRoles, name)
+}
+
+// ErrElevatedRoleRequiresApproval is returned when an elevated role is granted directly.
+var ErrElevatedRoleRequiresApproval = errors.New("elevated roles (hr, finance, admin, god-admin) require an approved role request")
+
+// ErrRoleRequestNotPending is returned when deciding a request that was already decided.
+var ErrRoleRequestNotPending = errors.New("role request is not pending")
+
+// ErrInvalidExpiry is returned when a time-limited grant would already have expired.
+var ErrInvalidExpiry = errors.New("expires_at must be in the future")
+
+// RoleRequestStatus is the state of a role grant request.
+type RoleRequestStatus string
+
+const (
+	RoleRequestPending  RoleRequestStatus = "pending"
+	RoleRequestApproved RoleRequestStatus = "approved"
+	RoleRequestRejected RoleRequestStatus = "rejected"
+)
+
+// RoleRequest is a pending grant of an elevated role that a god-admin must confirm before it takes effect.
+type RoleRequest struct {
+	gorm.Model
+	UserID       uint              `gorm:"not null;index" json:"user_id" example:"7"`
+	RoleID       uint              `gorm:"not null" json:"role_id" example:"3"`
+	Role         role.Role         `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"role"`
+	DivisionID   *uint             `json:"division_id,omitempty" example:"3"` // Set for a division-scoped grant
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`              // Set for a time-limited grant
+	Reason       string            `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Covering HR during parental leave"`
+	Status       RoleRequestStatus `gorm:"type:varchar(20);not null;index" json:"status" example:"pending"`
+	RequestedBy  *uint             `json:"requested_by,omitempty"`
+	DecidedBy    *uint             `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time        `json:"decided_at,omitempty"`
+	DecisionNote string            `gorm:"type:varchar(500)" json:"decision_note,omitempty"`
+}
+
+// CreateRoleRequestRequest defines the payload for requesting a role grant.
+type CreateRoleRequestRequest struct {
+	RoleID     uint       `json:"role_id" binding:"required" example:"3"`
+	DivisionID *uint      `json:"division_id,omitempty" example:"3"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2026-09-30T00:00:00Z"` // Optional: the grant is revoked automatically at this time
+	Reason     string     `json:"reason" binding:"max=500" example:"Covering HR during parental leave"`
+}
+
+// DecideRoleRequestRequest defines the payload for approving or rejecting a role request.
+type DecideRoleRequestRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Approved for Q3"`
+}
+
+// RoleRequestService defines the interface for the role grant approval workflow.
+type RoleRequestService interface {
+	Create(actor audit.Actor, orgID *uint, userID uint, req CreateRoleRequestRequest) (*RoleRequest, error)
+	List(status RoleRequestStatus, page utils.Pagination) ([]RoleRequest, int64, error)
+	Approve(actor audit.Actor, requestID uint, note string) (*RoleRequest, error)
+	Reject(actor audit.Actor, requestID uint, note string) (*RoleRequest, error)
+}
+
+// roleRequestService implements the RoleRequestService interface.
+type roleRequestService struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewRoleRequestService creates a new instance of RoleRequestService.
+func NewRoleRequestService(db *gorm.DB, auditor audit.Service) RoleRequestService {
+	return &roleRequestService{db: db, auditor: auditor}
+}
+
+// Create records a pending request to grant a role (globally or scoped to a division) to a user of the
+// organization (see utils.OrgScope).
+func (s *roleRequestService) Create(actor audit.Actor, orgID *uint, userID uint, req CreateRoleRequestRequest) (*RoleRequest, error) {
+	user, err := findOrgUser(s.db, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.DivisionID != nil {
+		if err := checkDivision(s.db, user.OrganizationID, *req.DivisionID); err != nil {
+			return nil, err
+		}
+	}
+	roles, err := FindRolesByIDs(s.db, []uint{req.RoleID})
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(clock.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	request := RoleRequest{
+		UserID:      userID,
+		RoleID:      req.RoleID,
+		Role:        roles[0],
+		DivisionID:  req.DivisionID,
+		ExpiresAt:   req.ExpiresAt,
+		Reason:      req.Reason,
+		Status:      RoleRequestPending,
+		RequestedBy: actor.UserID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Role").Create(&request).Error; err != nil {
+			return fmt.Errorf("failed to create role request: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action:     "role_request.create",
+			EntityType: "role_request",
+			EntityID:   fmt.Sprintf("%d", request.ID),
+			After:      request,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// List returns role requests, optionally filtered by status, newest first.
+func (s *roleRequestService) List(status RoleRequestStatus, page utils.Pagination) ([]RoleRequest, int64, error) {
+	query := s.db.Model(&RoleRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count role requests: %w", err)
+	}
+	var requests []RoleRequest
+	if err := query.Preload("Role").Scopes(page.Scope).Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list role requests: %w", err)
+	}
+	return requests, total, nil
+}
+
+// Approve applies the requested grant and marks the request approved, atomically.
+func (s *roleRequestService) Approve(actor audit.Actor, requestID uint, note string) (*RoleRequest, error) {
+	return s.decide(actor, requestID, RoleRequestApproved, note, func(tx *gorm.DB, request *RoleRequest) error {
+		// A time-limited grant approved too late would be revoked immediately; make the requester resubmit.
+		if request.ExpiresAt != nil && !request.ExpiresAt.After(clock.Now()) {
+			return ErrInvalidExpiry
+		}
+		if request.DivisionID != nil {
+			grant := ScopedRole{UserID: request.UserID, RoleID: request.RoleID, DivisionID: *request.DivisionID}
+			// An identical scoped grant may already exist; approving again only updates its expiry.
+			return tx.Where(grant).Assign(map[string]interface{}{"expires_at": request.ExpiresAt}).FirstOrCreate(&grant).Error
+		}
+		return GrantRole(tx, request.UserID, request.RoleID, request.ExpiresAt)
+	})
+}
+
+// Reject marks the request rejected without granting anything.
+func (s *roleRequestService) Reject(actor audit.Actor, requestID uint, note string) (*RoleRequest, error) {
+	return s.decide(actor, requestID, RoleRequestRejected, note, nil)
+}
+
+// decide transitions a pending request, running apply (if any) in the same transaction.
+func (s *roleRequestService) decide(actor audit.Actor, requestID uint, status RoleRequestStatus, note string, apply func(tx *gorm.DB, request *RoleRequest) error) (*RoleRequest, error) {
+	var request RoleRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Role").First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if request.Status != RoleRequestPending {
+			return ErrRoleRequestNotPending
+		}
+		before := request
+
+		if apply != nil {
+			if err := apply(tx, &request); err != nil {
+				return fmt.Errorf("failed to apply role grant: %w", err)
+			}
+		}
+
+		now := clock.Now().UTC()
+		request.Status = status
+		request.DecidedBy = actor.UserID
+		request.DecidedAt = &now
+		request.DecisionNote = note
+		// Guard on status so two god-admins deciding at once can't both succeed.
+		result := tx.Model(&RoleRequest{}).Where("id = ? AND status = ?", request.ID, RoleRequestPending).Updates(map[string]interface{}{
+			"status":        request.Status,
+			"decided_by":    request.DecidedBy,
+			"decided_at":    request.DecidedAt,
+			"decision_note": request.DecisionNote,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update role request: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleRequestNotPending
+		}
+
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action:     "role_request." + string(status),
+			EntityType: "role_request",
+			EntityID:   fmt.Sprintf("%d", request.ID),
+			Before:     before,
+			After:      request,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// RoleRequestHandler handles HTTP requests for the role grant approval workflow.
+type RoleRequestHandler struct {
+	service RoleRequestService
+}
+
+// NewRoleRequestHandler creates a new instance of RoleRequestHandler.
+func NewRoleRequestHandler(service RoleRequestService) *RoleRequestHandler {
+	return &RoleRequestHandler{service: service}
+}
+
+// Create requests a role grant for a user. The grant takes effect only after god-admin approval.
+// @Summary Request a role grant
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body CreateRoleRequestRequest true "Role grant request"
+// @Success 202 {object} RoleRequest "Pending approval"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input or unknown role"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/role-requests [post]
+func (h *RoleRequestHandler) Create(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CreateRoleRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	request, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, req)
+	if err != nil {
+		sendRoleRequestError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusAccepted, "Role request created and awaiting god-admin approval", request)
+}
+
+// List returns role requests.
+// @Summary List role requests
+// @Tags Users
+// @Produce json
+// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /admin/role-requests [get]
+func (h *RoleRequestHandler) List(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	requests, total, err := h.service.List(RoleRequestStatus(c.Query("status")), page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Role requests fetched successfully", page.Response(requests, total))
+}
+
+// Approve grants the requested role.
+// @Summary Approve a role request
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "Role request ID"
+// @Param decision body DecideRoleRequestRequest false "Optional note"
+// @Success 200 {object} RoleRequest
+// @Failure 404 {object} utils.ErrorResponse "Role request not found"
+// @Failure 409 {object} utils.ErrorResponse "Role request already decided"
+// @Router /admin/role-requests/{id}/approve [post]
+func (h *RoleRequestHandler) Approve(c *gin.Context) {
+	h.decide(c, h.service.Approve, "Role request approved")
+}
+
+// Reject declines the requested role.
+// @Summary Reject a role request
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "Role request ID"
+// @Param decision body DecideRoleRequestRequest false "Optional note"
+// @Success 200 {object} RoleRequest
+// @Failure 404 {object} utils.ErrorResponse "Role request not found"
+// @Failure 409 {object} utils.ErrorResponse "Role request already decided"
+// @Router /admin/role-requests/{id}/reject [post]
+func (h *RoleRequestHandler) Reject(c *gin.Context) {
+	h.decide(c, h.service.Reject, "Role request rejected")
+}
+
+func (h *RoleRequestHandler) decide(c *gin.Context, decide func(audit.Actor, uint, string) (*RoleRequest, error), message string) {
+	requestID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DecideRoleRequestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	request, err := decide(audit.ActorFromContext(c), requestID, req.Note)
+	if err != nil {
+		sendRoleRequestError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, message, request)
+}
+
+// sendRoleRequestError maps service errors to HTTP status codes.
+func sendRoleRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Resource not found")
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrInvalidExpiry), errors.Is(err, ErrUnknownDivision):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRoleRequestNotPending):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
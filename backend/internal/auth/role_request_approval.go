@@ -0,0 +1,89 @@
+// prometheus/backend/internal/auth/role_request_approval.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/approval"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleRequestApprovalKind names role requests among the kinds of requests decidable by email.
+const RoleRequestApprovalKind = "role_request"
+
+// RoleRequestApprovals lets god-admins approve or reject role requests from the notification email.
+// The approver must still be an active god-admin whose sessions weren't revoked since the email was sent.
+func RoleRequestApprovals(db *gorm.DB, service RoleRequestService, statuses *UserStatusCache) approval.Kind {
+	return approval.Kind{
+		Authorize: func(ctx context.Context, userID uint, issuedAt time.Time) (audit.Actor, error) {
+			status, err := statuses.Status(ctx, userID)
+			if err != nil {
+				return audit.Actor{}, err
+			}
+			if !status.Accepts(issuedAt) {
+				return audit.Actor{}, approval.ErrNotAllowed
+			}
+			var user User
+			if err := db.WithContext(ctx).Select("id", "username", "organization_id").First(&user, userID).Error; err != nil {
+				return audit.Actor{}, fmt.Errorf("failed to load user %d: %w", userID, err)
+			}
+			var grants int64
+			if err := db.WithContext(ctx).Table("user_roles").Joins("JOIN roles ON roles.id = user_roles.role_id").
+				Where("user_roles.user_id = ? AND roles.name = ? AND (user_roles.expires_at IS NULL OR user_roles.expires_at > ?)",
+					userID, "god-admin", clock.Now().UTC()).Count(&grants).Error; err != nil {
+				return audit.Actor{}, fmt.Errorf("failed to load roles of user %d: %w", userID, err)
+			}
+			if grants == 0 {
+				return audit.Actor{}, approval.ErrNotAllowed
+			}
+			return audit.Actor{UserID: &user.ID, Username: user.Username, OrganizationID: user.OrganizationID}, nil
+		},
+		Describe: func(ctx context.Context, requestID uint) (string, error) {
+			var request RoleRequest
+			err := db.WithContext(ctx).Preload("Role").First(&request, requestID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && request.Status != RoleRequestPending) {
+				return "", approval.ErrNotPending
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to load role request %d: %w", requestID, err)
+			}
+			var user User
+			if err := db.WithContext(ctx).Select("id", "username").First(&user, request.UserID).Error; err != nil {
+				return "", fmt.Errorf("failed to load user %d: %w", request.UserID, err)
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "Grant the %s role to %s", request.Role.Name, user.Username)
+			if request.DivisionID != nil {
+				fmt.Fprintf(&b, " in division %d", *request.DivisionID)
+			}
+			if request.ExpiresAt != nil {
+				fmt.Fprintf(&b, " until %s", request.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"))
+			}
+			if request.Reason != "" {
+				fmt.Fprintf(&b, ". Reason: %s", request.Reason)
+			}
+			return b.String(), nil
+		},
+		Decide: func(actor audit.Actor, requestID uint, approve bool, note string) error {
+			var err error
+			if approve {
+				_, err = service.Approve(actor, requestID, note)
+			} else {
+				_, err = service.Reject(actor, requestID, note)
+			}
+			switch {
+			case errors.Is(err, ErrRoleRequestNotPending), errors.Is(err, gorm.ErrRecordNotFound):
+				return approval.ErrNotPending
+			case errors.Is(err, ErrInvalidExpiry):
+				return fmt.Errorf("%w: the grant would already have expired", approval.ErrUnprocessable)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,283 @@
+// prometheus/backend/internal/auth/scoped_role.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScopedRole grants a role limited to a single division, e.g. "manager of Engineering".
+// Unlike the global roles in user_roles, a scoped role only applies to data of that division.
+type ScopedRole struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_scoped_role_assignment" json:"user_id" example:"7"`
+	RoleID     uint       `gorm:"not null;uniqueIndex:idx_scoped_role_assignment" json:"role_id" example:"2"`
+	Role       role.Role  `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"role"`
+	DivisionID uint       `gorm:"not null;uniqueIndex:idx_scoped_role_assignment;index" json:"division_id" example:"3"`
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at,omitempty"` // Time-limited grant; nil = permanent
+}
+
+// ScopedRoleClaim is the JWT representation of a ScopedRole.
+type ScopedRoleClaim struct {
+	Role       string `json:"role"`
+	DivisionID uint   `json:"division_id"`
+	ExpiresAt  int64  `json:"exp,omitempty"` // Unix seconds; 0 = permanent
+}
+
+// AssignScopedRoleRequest defines the payload for granting a division-scoped role.
+type AssignScopedRoleRequest struct {
+	RoleID     uint `json:"role_id" binding:"required" example:"2"`
+	DivisionID uint `json:"division_id" binding:"required" example:"3"`
+}
+
+// ErrScopedRoleExists is returned when the user already holds the role for that division.
+var ErrScopedRoleExists = errors.New("user already holds this role for the division")
+
+// ErrUnknownDivision is returned when a role is scoped to a division outside the user's organization.
+var ErrUnknownDivision = errors.New("division not found in the organization")
+
+// ScopedRoleService defines the interface for managing division-scoped role assignments.
+// orgID scopes every call to one organization's users and divisions (see utils.OrgScope).
+type ScopedRoleService interface {
+	ListForUser(orgID *uint, userID uint) ([]ScopedRole, error)
+	Assign(actor audit.Actor, orgID *uint, userID uint, req AssignScopedRoleRequest) (*ScopedRole, error)
+	Revoke(actor audit.Actor, orgID *uint, userID, assignmentID uint) error
+}
+
+// scopedRoleService implements the ScopedRoleService interface.
+type scopedRoleService struct {
+	db       *gorm.DB
+	auditor  audit.Service
+	statuses *UserStatusCache
+}
+
+// NewScopedRoleService creates a new instance of ScopedRoleService. statuses is invalidated when an
+// assignment is revoked.
+func NewScopedRoleService(db *gorm.DB, auditor audit.Service, statuses *UserStatusCache) ScopedRoleService {
+	return &scopedRoleService{db: db, auditor: auditor, statuses: statuses}
+}
+
+// ListForUser returns the division-scoped roles of a user.
+func (s *scopedRoleService) ListForUser(orgID *uint, userID uint) ([]ScopedRole, error) {
+	if _, err := findOrgUser(s.db, orgID, userID); err != nil {
+		return nil, err
+	}
+	var assignments []ScopedRole
+	if err := s.db.Preload("Role").Where("user_id = ?", userID).Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scoped roles of user %d: %w", userID, err)
+	}
+	return assignments, nil
+}
+
+// Assign grants a role scoped to a division.
+func (s *scopedRoleService) Assign(actor audit.Actor, orgID *uint, userID uint, req AssignScopedRoleRequest) (*ScopedRole, error) {
+	user, err := findOrgUser(s.db, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDivision(s.db, user.OrganizationID, req.DivisionID); err != nil {
+		return nil, err
+	}
+	roles, err := FindRolesByIDs(s.db, []uint{req.RoleID})
+	if err != nil {
+		return nil, err
+	}
+	if IsElevatedRole(roles[0].Name) {
+		return nil, ErrElevatedRoleRequiresApproval
+	}
+
+	var count int64
+	if err := s.db.Model(&ScopedRole{}).Where("user_id = ? AND role_id = ? AND division_id = ?", userID, req.RoleID, req.DivisionID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing scoped role: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrScopedRoleExists
+	}
+
+	assignment := ScopedRole{UserID: userID, RoleID: req.RoleID, DivisionID: req.DivisionID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to assign scoped role: %w", err)
+		}
+		assignment.Role = roles[0]
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action:     "scoped_role.assign",
+			EntityType: "user_role",
+			EntityID:   fmt.Sprintf("%d", userID),
+			After:      assignment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// Revoke removes a division-scoped role assignment. Scoped roles are hard-deleted so the
+// unique index allows granting the same scope again later. Tokens carry scoped roles in their claims, so
+// the user's tokens are revoked and they have to log in again.
+func (s *scopedRoleService) Revoke(actor audit.Actor, orgID *uint, userID, assignmentID uint) error {
+	if _, err := findOrgUser(s.db, orgID, userID); err != nil {
+		return err
+	}
+	var assignment ScopedRole
+	if err := s.db.Preload("Role").Where("id = ? AND user_id = ?", assignmentID, userID).First(&assignment).Error; err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to revoke scoped role: %w", err)
+		}
+		if err := tx.Model(&User{}).Where("id = ?", userID).Update("tokens_revoked_at", clock.Now().UTC()).Error; err != nil {
+			return fmt.Errorf("failed to revoke tokens of user %d: %w", userID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action:     "scoped_role.revoke",
+			EntityType: "user_role",
+			EntityID:   fmt.Sprintf("%d", userID),
+			Before:     assignment,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.statuses.Invalidate(context.Background(), userID); err != nil {
+		log.Printf("Failed to invalidate cached status of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// findOrgUser loads a user within the organization scope.
+func findOrgUser(db *gorm.DB, orgID *uint, userID uint) (*User, error) {
+	var user User
+	if err := utils.OrgScope(db, orgID).First(&user, userID).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &user, nil
+}
+
+// checkDivision returns ErrUnknownDivision unless the division belongs to the organization.
+func checkDivision(db *gorm.DB, orgID *uint, divisionID uint) error {
+	var count int64
+	if err := utils.OrgScope(db.Table("divisions"), orgID).Where("id = ? AND deleted_at IS NULL", divisionID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check division %d: %w", divisionID, err)
+	}
+	if count == 0 {
+		return ErrUnknownDivision
+	}
+	return nil
+}
+
+// ScopedRoleHandler handles HTTP requests for division-scoped role assignments.
+type ScopedRoleHandler struct {
+	service ScopedRoleService
+}
+
+// NewScopedRoleHandler creates a new instance of ScopedRoleHandler.
+func NewScopedRoleHandler(service ScopedRoleService) *ScopedRoleHandler {
+	return &ScopedRoleHandler{service: service}
+}
+
+// List returns a user's division-scoped roles.
+// @Summary List a user's division-scoped roles
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} ScopedRole
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/scoped-roles [get]
+func (h *ScopedRoleHandler) List(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	assignments, err := h.service.ListForUser(utils.OrganizationFromContext(c), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, http.StatusNotFound, "User not found")
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Scoped roles fetched successfully", assignments)
+}
+
+// Assign grants a division-scoped role to a user.
+// @Summary Grant a division-scoped role
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param assignment body AssignScopedRoleRequest true "Role and division"
+// @Success 201 {object} ScopedRole
+// @Failure 400 {object} utils.ErrorResponse "Invalid input, unknown role or division of another organization"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Failure 409 {object} utils.ErrorResponse "Assignment already exists"
+// @Router /admin/users/{id}/scoped-roles [post]
+func (h *ScopedRoleHandler) Assign(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AssignScopedRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	assignment, err := h.service.Assign(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.SendErrorResponse(c, http.StatusNotFound, "User not found")
+		case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrElevatedRoleRequiresApproval), errors.Is(err, ErrUnknownDivision):
+			utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrScopedRoleExists):
+			utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Scoped role assigned successfully", assignment)
+}
+
+// Revoke removes a division-scoped role from a user.
+// @Summary Revoke a division-scoped role
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param assignmentID path int true "Scoped role assignment ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Assignment not found"
+// @Router /admin/users/{id}/scoped-roles/{assignmentID} [delete]
+func (h *ScopedRoleHandler) Revoke(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	assignmentID, ok := utils.ParseUintParam(c, "assignmentID")
+	if !ok {
+		return
+	}
+	if err := h.service.Revoke(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, assignmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, http.StatusNotFound, "Scoped role assignment not found")
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Scoped role revoked successfully", nil)
+}
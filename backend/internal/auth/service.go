@@ -2,9 +2,12 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"prometheus/backend/config"
+	"prometheus/backend/internal/clock"
 	"prometheus/backend/internal/role" // Ensure this path is correct for your role package
 	"time"
 
@@ -13,23 +16,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrRoleNotFound is returned when a requested role ID does not exist.
+var ErrRoleNotFound = errors.New("role not found")
+
 // AuthService defines the interface for authentication operations.
 type AuthService interface {
 	RegisterUser(req RegisterRequest) (*User, error)
-	LoginUser(req LoginRequest) (*AuthResponse, error)
+	LoginUser(req LoginRequest, client LoginClient) (*AuthResponse, error)
 	GenerateJWT(user *User) (string, error)
 	ValidatePassword(hashedPassword, plainPassword string) error
 }
 
 // authService implements the AuthService interface.
 type authService struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db   *gorm.DB
+	cfg  *config.Config
+	keys SigningKeys
 }
 
-// NewAuthService creates a new instance of AuthService.
-func NewAuthService(db *gorm.DB, cfg *config.Config) AuthService {
-	return &authService{db: db, cfg: cfg}
+// NewAuthService creates a new instance of AuthService. Tokens are signed with the current key of keys.
+func NewAuthService(db *gorm.DB, cfg *config.Config, keys SigningKeys) AuthService {
+	return &authService{db: db, cfg: cfg, keys: keys}
 }
 
 // HashPassword hashes a given password using bcrypt.
@@ -41,6 +48,16 @@ func HashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
+// GenerateRandomPassword returns a random URL-safe password, used for accounts created on someone's
+// behalf (imports, onboarding) that must set their own password before first use.
+func GenerateRandomPassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
 // ValidatePassword compares a hashed password with a plain password.
 func (s *authService) ValidatePassword(hashedPassword, plainPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
@@ -48,15 +65,13 @@ func (s *authService) ValidatePassword(hashedPassword, plainPassword string) err
 
 // RegisterUser handles new user registration.
 func (s *authService) RegisterUser(req RegisterRequest) (*User, error) {
-	// Check if username or email already exists
-	var existingUser User
-	// The error "relation 'users' does not exist" originated from this GORM query
-	// because the table wasn't created yet. AutoMigrate in main.go fixes this.
-	if err := s.db.Where("username = ? OR email = ?", req.Username, req.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("username or email already exists")
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// This means a real database error occurred, other than "not found"
-		return nil, fmt.Errorf("database error while checking existing user: %w", err)
+	// Check if username (in any letter case) or email already exists
+	exists, err := UserExists(s.db, req.Username, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrUserExists
 	}
 
 	hashedPassword, err := HashPassword(req.Password)
@@ -64,48 +79,37 @@ func (s *authService) RegisterUser(req RegisterRequest) (*User, error) {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Determine RoleID
-	roleID := req.RoleID
-	var userRole role.Role // To hold the role details
-
-	if roleID == 0 {
-		// Default to "staff" role if RoleID is not provided or is 0
-		if err := s.db.Where("name = ?", "staff").First(&userRole).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				// This error highlights the need for seeding roles after migration.
-				return nil, errors.New("default 'staff' role not found. Please ensure roles are seeded")
-			}
-			return nil, fmt.Errorf("failed to fetch default 'staff' role: %w", err)
-		}
-		roleID = userRole.ID
-	} else {
-		// Validate if the provided RoleID exists
-		if err := s.db.First(&userRole, roleID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("role with ID %d not found", roleID)
-			}
-			return nil, fmt.Errorf("failed to verify role ID %d: %w", roleID, err)
+	// Self-registered accounts always start as staff. Any other role is granted by an admin or through a
+	// role request, see RoleRequestService.
+	var staffRole role.Role
+	if err := s.db.Where("name = ?", "staff").First(&staffRole).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// This error highlights the need for seeding roles after migration.
+			return nil, errors.New("default 'staff' role not found. Please ensure roles are seeded")
 		}
+		return nil, fmt.Errorf("failed to fetch default 'staff' role: %w", err)
 	}
 
 	newUser := User{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: hashedPassword,
-		RoleID:   roleID,
-		IsActive: true, // Default to active, can be changed by admin later
+		Roles:    []role.Role{staffRole}, // GORM inserts the user_roles join rows on Create
+		IsActive: true,                   // Default to active, can be changed by admin later
 	}
 
 	if err := s.db.Create(&newUser).Error; err != nil {
+		if IsUsernameConflict(err) {
+			return nil, ErrUserExists
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// After creating the user, their ID is populated. Now, preload their Role.
-	// It's good practice to return the newly created user with its associated role.
-	// The 'newUser' variable here will have its Role field populated by this Preload.
-	if err := s.db.Preload("Role").First(&newUser, newUser.ID).Error; err != nil {
+	// After creating the user, their ID is populated. Now, preload their Roles.
+	// It's good practice to return the newly created user with its associated roles.
+	if err := s.db.Preload("Roles").First(&newUser, newUser.ID).Error; err != nil {
 		// Log error but proceed; role might not be critical for immediate response, but it's good to know.
-		fmt.Printf("Warning: failed to preload role for new user %s (ID: %d): %v\n", newUser.Username, newUser.ID, err)
+		fmt.Printf("Warning: failed to preload roles for new user %s (ID: %d): %v\n", newUser.Username, newUser.ID, err)
 		// Even if preloading fails, the user was created.
 		// You might decide to return an error here if Role is absolutely critical for the response.
 	}
@@ -113,30 +117,33 @@ func (s *authService) RegisterUser(req RegisterRequest) (*User, error) {
 	return &newUser, nil
 }
 
-// LoginUser handles user login and JWT generation.
-func (s *authService) LoginUser(req LoginRequest) (*AuthResponse, error) {
+// LoginUser handles user login and JWT generation. Every attempt is recorded in the login history.
+func (s *authService) LoginUser(req LoginRequest, client LoginClient) (*AuthResponse, error) {
 	var user User
-	// Preload Role to get Role.Name for JWT claims and user response
+	// Preload Roles to get role names for JWT claims and user response
 	// Login can be by username or email.
-	if err := s.db.Preload("Role").Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
+	if err := s.db.Preload("Roles").Preload("ScopedRoles.Role").Preload("Organization").Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			recordLogin(s.db, nil, req.Username, client, LoginFailureUnknownUser)
 			return nil, errors.New("invalid username or password") // Keep error generic for security
 		}
 		return nil, fmt.Errorf("database error during login: %w", err)
 	}
 
 	if !user.IsActive {
+		recordLogin(s.db, &user, req.Username, client, LoginFailureInactive)
 		return nil, errors.New("user account is inactive")
 	}
 
 	if err := s.ValidatePassword(user.Password, req.Password); err != nil {
+		recordLogin(s.db, &user, req.Username, client, LoginFailureInvalidPassword)
 		return nil, errors.New("invalid username or password") // Keep error generic
 	}
 
 	// Update LastLogin
 	now := time.Now().UTC() // Use UTC for consistency
 	user.LastLogin = &now
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := s.db.Model(&user).Update("last_login", now).Error; err != nil {
 		// Log error but proceed with login as this is not critical enough to fail login
 		fmt.Printf("Warning: failed to update last login for user %s: %v\n", user.Username, err)
 	}
@@ -145,14 +152,15 @@ func (s *authService) LoginUser(req LoginRequest) (*AuthResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
+	recordLogin(s.db, &user, req.Username, client, "")
 
 	authResponse := &AuthResponse{
 		User: UserCompact{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			RoleName: user.Role.Name, // Role.Name should be populated due to Preload
-			IsActive: user.IsActive,
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			RoleNames: user.RoleNames(), // Roles should be populated due to Preload
+			IsActive:  user.IsActive,
 		},
 		AccessToken: accessToken,
 		// RefreshToken: // TODO: Implement refresh token generation if needed
@@ -163,42 +171,106 @@ func (s *authService) LoginUser(req LoginRequest) (*AuthResponse, error) {
 
 // GenerateJWT creates a new JWT for a given user.
 func (s *authService) GenerateJWT(user *User) (string, error) {
-	// Ensure Role.Name is available for the JWT claims.
-	// It should typically be preloaded before calling GenerateJWT.
+	// Ensure roles are available for the JWT claims.
+	// They should typically be preloaded before calling GenerateJWT.
 	// If not, attempt a last-minute load.
-	if user.Role.Name == "" && user.RoleID != 0 {
-		var roleFromDB role.Role
-		if err := s.db.First(&roleFromDB, user.RoleID).Error; err != nil {
-			return "", fmt.Errorf("could not retrieve role name (ID: %d) for JWT generation: %w", user.RoleID, err)
+	if len(user.Roles) == 0 && len(user.ScopedRoles) == 0 {
+		if err := s.db.Model(user).Association("Roles").Find(&user.Roles); err != nil {
+			return "", fmt.Errorf("could not retrieve roles of user %d for JWT generation: %w", user.ID, err)
+		}
+		if err := s.db.Preload("Role").Where("user_id = ?", user.ID).Find(&user.ScopedRoles).Error; err != nil {
+			return "", fmt.Errorf("could not retrieve scoped roles of user %d for JWT generation: %w", user.ID, err)
+		}
+		if len(user.Roles) == 0 && len(user.ScopedRoles) == 0 {
+			return "", errors.New("user has no roles for JWT generation")
+		}
+	}
+
+	// Tenant users are authorized within their organization's domain.
+	if user.OrganizationID != nil && user.Organization == nil {
+		if err := s.db.Model(user).Association("Organization").Find(&user.Organization); err != nil {
+			return "", fmt.Errorf("could not retrieve organization of user %d for JWT generation: %w", user.ID, err)
+		}
+	}
+	domain := ""
+	if user.Organization != nil {
+		domain = user.Organization.Domain()
+	}
+
+	// Time-limited grants travel with their expiry so AuthMiddleware can drop them once they lapse,
+	// even though the token itself stays valid for longer.
+	expiries, err := RoleExpiries(s.db, user.ID)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	roleNames := make([]string, 0, len(user.Roles))
+	var roleExpiry map[string]int64
+	for _, name := range user.RoleNames() {
+		if expiresAt, timed := expiries[name]; timed {
+			if !expiresAt.After(now) {
+				continue // Lapsed, awaiting removal by the expiry job
+			}
+			if roleExpiry == nil {
+				roleExpiry = map[string]int64{}
+			}
+			roleExpiry[name] = expiresAt.Unix()
 		}
-		user.Role.Name = roleFromDB.Name // Populate the role name
-	} else if user.Role.Name == "" && user.RoleID == 0 {
-		return "", errors.New("user has no RoleID or Role.Name for JWT generation")
+		roleNames = append(roleNames, name)
 	}
 
-	expirationTime := time.Now().Add(time.Duration(s.cfg.JWTExpirationHours) * time.Hour)
+	expirationTime := clock.Now().Add(time.Duration(s.cfg.JWTExpirationHours) * time.Hour)
 	if s.cfg.JWTExpirationHours == 0 { // Default if not set or zero
-		expirationTime = time.Now().Add(24 * 7 * time.Hour) // Default to 7 days
+		expirationTime = clock.Now().Add(24 * 7 * time.Hour) // Default to 7 days
 	}
 
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-			NotBefore: jwt.NewNumericDate(time.Now().UTC()),
+			IssuedAt:  jwt.NewNumericDate(clock.Now().UTC()),
+			NotBefore: jwt.NewNumericDate(clock.Now().UTC()),
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
 		UserID:   user.ID,
 		Username: user.Username,
 		Email:    user.Email,
-		Role:     user.Role.Name, // Role name (e.g., "admin", "staff")
+		Roles:    roleNames, // Role names (e.g., ["manager", "hr"])
+
+		RoleExpiry: roleExpiry,
+
+		ScopedRoles: user.ScopedRoleClaims(),
+
+		OrganizationID: user.OrganizationID,
+		Domain:         domain,
 	}
 
+	kid, secret := s.keys.SigningKey()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.cfg.JWTSecret))
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signedToken, err := token.SignedString(secret)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign JWT token: %w", err)
 	}
 
 	return signedToken, nil
 }
+
+// FindRolesByIDs loads the roles with the given IDs, failing with ErrRoleNotFound if any is missing.
+func FindRolesByIDs(db *gorm.DB, roleIDs []uint) ([]role.Role, error) {
+	var roles []role.Role
+	if err := db.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to verify role IDs: %w", err)
+	}
+	found := make(map[uint]bool, len(roles))
+	for _, r := range roles {
+		found[r.ID] = true
+	}
+	for _, id := range roleIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: role with ID %d not found", ErrRoleNotFound, id)
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,22 @@
+// prometheus/backend/internal/auth/signing_key.go
+package auth
+
+// SigningKeys resolves the HMAC secrets JWTs are signed and verified with. Tokens carry the ID of the
+// key that signed them in their "kid" header, so keys can be rotated without logging everyone out.
+type SigningKeys interface {
+	// SigningKey returns the key new tokens are signed with; an empty kid leaves the header out.
+	SigningKey() (kid string, secret []byte)
+	// VerificationKey returns the secret of a key still trusted to verify tokens, by the token's kid
+	// ("" for tokens without one).
+	VerificationKey(kid string) ([]byte, bool)
+}
+
+// StaticKey signs and verifies every token with one secret, JWT_SECRET, for deployments without key
+// management (see internal/keyring).
+type StaticKey []byte
+
+// SigningKey implements SigningKeys.
+func (k StaticKey) SigningKey() (string, []byte) { return "", k }
+
+// VerificationKey implements SigningKeys.
+func (k StaticKey) VerificationKey(kid string) ([]byte, bool) { return k, kid == "" }
@@ -0,0 +1,1167 @@
+// prometheus/backend/internal/auth/user_admin.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrUserExists is returned when an update would duplicate another user's username or email.
+var ErrUserExists = errors.New("username or email already exists")
+
+// ErrUsernameTaken is returned when a rename would duplicate another user's username.
+var ErrUsernameTaken = errors.New("username already taken")
+
+// ErrInvalidUsername is returned for usernames outside 3 to 100 characters.
+var ErrInvalidUsername = errors.New("username must be 3 to 100 characters")
+
+// ErrAnonymized is returned when restoring a user whose personal data was scrubbed.
+var ErrAnonymized = errors.New("anonymized users cannot be restored")
+
+// ErrCannotModifySelf is returned when admins try to deactivate, delete or re-role their own account.
+var ErrCannotModifySelf = errors.New("you cannot deactivate, delete or change the roles of your own account")
+
+// ErrInvalidPassword is returned for passwords outside 6 to 72 characters, the limits of registration.
+var ErrInvalidPassword = errors.New("password must be 6 to 72 characters")
+
+// UserDetail is the admin view of a user. It is built from User field by field so the password hash
+// (and anything added to User later) never leaks by accident.
+type UserDetail struct {
+	ID             uint             `json:"id" example:"7"`
+	Username       string           `json:"username" example:"johndoe"`
+	Email          string           `json:"email" example:"john.doe@example.com"`
+	IsActive       bool             `json:"is_active" example:"true"`
+	Roles          []UserRoleDetail `json:"roles"`
+	OrganizationID *uint            `json:"organization_id,omitempty" example:"1"`
+	LastLogin      *time.Time       `json:"last_login,omitempty"`
+	AvatarURL      string           `json:"avatar_url,omitempty" example:"/api/v1/users/7/avatar?v=3f2a9c1e"`
+	CustomFields   datatypes.JSON   `json:"custom_fields,omitempty" swaggertype:"object"` // Values by custom field key
+	Version        uint             `json:"version" example:"1"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// UserRoleDetail is a global role held by a user.
+type UserRoleDetail struct {
+	ID        uint       `json:"id" example:"2"`
+	Name      string     `json:"name" example:"manager"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Set for time-limited grants
+}
+
+// userSortFields maps the ?sort= fields of the user listing to columns.
+var userSortFields = map[string]string{
+	"username":   "username",
+	"email":      "email",
+	"is_active":  "is_active",
+	"last_login": "last_login",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// exportBatchSize is how many users an export loads per query.
+const exportBatchSize = 500
+
+// UserFilter narrows a user listing.
+type UserFilter struct {
+	Search   string `json:"q,omitempty"`    // Case-insensitive match on username or email
+	Role     string `json:"role,omitempty"` // Role name
+	IsActive *bool  `json:"is_active,omitempty"`
+}
+
+// UpdateUserRequest changes a user's profile. Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Username *string `json:"username,omitempty" binding:"omitempty,min=3,max=100" example:"johndoe"`
+	Email    *string `json:"email,omitempty" binding:"omitempty,email" example:"john.doe@example.com"`
+	IsActive *bool   `json:"is_active,omitempty" example:"false"`
+}
+
+// ChangeUsernameRequest renames a user.
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=100" example:"jane.doe"`
+}
+
+// SetUserStatusRequest activates or deactivates a user.
+type SetUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required" example:"false"`
+}
+
+// RestoreUserRequest restores a deleted user. Omitting the body restores the account deactivated.
+type RestoreUserRequest struct {
+	Activate bool `json:"activate" example:"true"` // Reactivate the account right away
+}
+
+// DeletedUser is a soft-deleted user that can still be restored.
+type DeletedUser struct {
+	UserDetail
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SetUserRolesRequest replaces a user's global roles. Elevated roles the user does not already hold
+// must be requested through /admin/users/:id/role-requests instead.
+type SetUserRolesRequest struct {
+	RoleIDs []uint `json:"role_ids" binding:"required,min=1" example:"1,2"`
+}
+
+// UserAdminService defines the interface for administrative user management.
+// orgID scopes every call to one organization's users (nil = platform users outside any organization, as with
+// utils.OrgScope). Only a service from NewPlatformUserAdminService reaches the users of every organization.
+type UserAdminService interface {
+	List(orgID *uint, filter UserFilter, sort utils.Sort, page utils.Pagination) ([]UserDetail, int64, error)
+	Export(actor audit.Actor, orgID *uint, filter UserFilter, sort utils.Sort, each func([]UserDetail) error) error
+	Get(orgID *uint, userID uint) (*UserDetail, error)
+	Update(actor audit.Actor, orgID *uint, userID, expectedVersion uint, req UpdateUserRequest) (*UserDetail, error)
+	Delete(actor audit.Actor, orgID *uint, userID uint) error
+	ListDeleted(orgID *uint, page utils.Pagination) ([]DeletedUser, int64, error)
+	Restore(actor audit.Actor, orgID *uint, userID uint, activate bool) (*UserDetail, error)
+	SetStatus(actor audit.Actor, orgID *uint, userID uint, active bool) (*UserDetail, error)
+	ForceLogout(actor audit.Actor, orgID *uint, userID uint) error
+	// ResetPassword replaces a user's password and revokes every token issued to them so far.
+	ResetPassword(actor audit.Actor, orgID *uint, userID uint, password string) error
+	ChangeUsername(actor audit.Actor, orgID *uint, userID uint, req ChangeUsernameRequest) (*UserDetail, error)
+	SetRoles(actor audit.Actor, orgID *uint, userID uint, req SetUserRolesRequest) (*UserDetail, error)
+	ValidateImport(actor audit.Actor, orgID *uint, rows []UserImportRow) (*UserImportReport, error)
+	Import(actor audit.Actor, orgID *uint, rows []UserImportRow) (*UserImport, error)
+	GetImport(orgID *uint, id uint) (*UserImport, error)
+	ProcessImport(ctx context.Context, id uint, reporter jobs.Reporter) (*UserImport, error)
+}
+
+// userAdminService implements the UserAdminService interface.
+type userAdminService struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+	auditor   audit.Service
+	statuses  *UserStatusCache
+	limits    EmployeeLimiter
+	queue     *jobs.Queue
+	// allOrganizations ignores the orgID of every call; see NewPlatformUserAdminService.
+	allOrganizations bool
+}
+
+// NewUserAdminService creates a new instance of UserAdminService. statuses is invalidated whenever a
+// user's active state changes, so their tokens stop working on the next request. limits enforces the plan's
+// employee limit on imports, which run on queue. Exports read through reporting, the read-only reporting
+// connection (see database.ConnectReportingDB).
+func NewUserAdminService(db, reporting *gorm.DB, auditor audit.Service, statuses *UserStatusCache, limits EmployeeLimiter, queue *jobs.Queue) UserAdminService {
+	return &userAdminService{db: db, reporting: reporting, auditor: auditor, statuses: statuses, limits: limits, queue: queue}
+}
+
+// NewPlatformUserAdminService creates a UserAdminService reaching the users of every organization, whatever
+// orgID a call passes. It is meant for operators on the server, such as the admin CLI; it doesn't enforce plan
+// limits or run imports.
+func NewPlatformUserAdminService(db *gorm.DB, auditor audit.Service, statuses *UserStatusCache) UserAdminService {
+	return &userAdminService{db: db, reporting: db, auditor: auditor, statuses: statuses, allOrganizations: true}
+}
+
+// List returns users in the given order.
+func (s *userAdminService) List(orgID *uint, filter UserFilter, sort utils.Sort, page utils.Pagination) ([]UserDetail, int64, error) {
+	query := s.filtered(s.db, orgID, filter)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	var users []User
+	if err := query.Preload("Roles").Scopes(sort.Scope, page.Scope).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	expiries, err := s.grantExpiries(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	details := make([]UserDetail, 0, len(users))
+	for i := range users {
+		details = append(details, newUserDetail(&users[i], expiries))
+	}
+	return details, total, nil
+}
+
+// Export passes the users matching filter to each, in the given order and in batches of exportBatchSize,
+// so exports of large organizations are streamed instead of loaded at once. Users are read through the
+// reporting connection; the export is audited through the main one.
+func (s *userAdminService) Export(actor audit.Actor, orgID *uint, filter UserFilter, sort utils.Sort, each func([]UserDetail) error) error {
+	if err := s.auditor.Record(actor, audit.Entry{Action: "user.export", EntityType: "user", After: filter}); err != nil {
+		return err
+	}
+	for offset := 0; ; offset += exportBatchSize {
+		var users []User
+		if err := s.filtered(s.reporting, orgID, filter).Preload("Roles").Scopes(sort.Scope).
+			Offset(offset).Limit(exportBatchSize).Find(&users).Error; err != nil {
+			return fmt.Errorf("failed to export users: %w", err)
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		details := make([]UserDetail, 0, len(users))
+		for i := range users {
+			details = append(details, newUserDetail(&users[i], nil))
+		}
+		if err := each(details); err != nil {
+			return err
+		}
+		if len(users) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// filtered returns a query through db on the users matching filter within the organization scope.
+func (s *userAdminService) filtered(db *gorm.DB, orgID *uint, filter UserFilter) *gorm.DB {
+	query := s.orgScope(db.Model(&User{}), orgID)
+	if filter.Search != "" {
+		pattern := utils.ContainsPattern(strings.ToLower(filter.Search))
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("id IN (?)", db.Table("user_roles").
+			Select("user_roles.user_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ?", filter.Role))
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	return query
+}
+
+// Get returns a single user.
+func (s *userAdminService) Get(orgID *uint, userID uint) (*UserDetail, error) {
+	user, err := s.load(s.db, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(user)
+}
+
+// Update changes a user's profile if the user is still at expectedVersion (optimistic locking).
+func (s *userAdminService) Update(actor audit.Actor, orgID *uint, userID, expectedVersion uint, req UpdateUserRequest) (*UserDetail, error) {
+	updates := map[string]interface{}{}
+	if req.Username != nil {
+		updates["username"] = *req.Username
+	}
+	if req.Email != nil {
+		updates["email"] = *req.Email
+	}
+	if req.IsActive != nil {
+		if !*req.IsActive && isSelf(actor, userID) {
+			return nil, ErrCannotModifySelf
+		}
+		updates["is_active"] = *req.IsActive
+	}
+
+	var user *User
+	var renamed bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if req.Username != nil || req.Email != nil {
+			var count int64
+			if err := tx.Model(&User{}).Where("id <> ? AND (LOWER(username) = LOWER(?) OR email = ?)", userID,
+				valueOr(req.Username, before.Username), valueOr(req.Email, before.Email)).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check existing users: %w", err)
+			}
+			if count > 0 {
+				return ErrUserExists
+			}
+		}
+		if req.Username != nil && *req.Username != before.Username {
+			if err := claimUsername(tx, userID, *req.Username); err != nil {
+				return err
+			}
+			renamed = true
+			updates["tokens_revoked_at"] = clock.Now().UTC()
+		}
+		if len(updates) > 0 {
+			if err := utils.UpdateWithVersion(tx, &User{}, userID, expectedVersion, updates); err != nil {
+				if IsUsernameConflict(err) {
+					return ErrUsernameTaken
+				}
+				return err
+			}
+		}
+		if user, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.update", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			Before: profileSnapshot(before), After: profileSnapshot(user),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil || renamed {
+		s.forgetStatus(userID)
+	}
+	return s.detail(user)
+}
+
+// Delete deactivates and soft-deletes a user. Their audit history and role grants are kept.
+func (s *userAdminService) Delete(actor audit.Actor, orgID *uint, userID uint) error {
+	if isSelf(actor, userID) {
+		return ErrCannotModifySelf
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(user).Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to deactivate user %d: %w", userID, err)
+		}
+		if err := tx.Delete(user).Error; err != nil {
+			return fmt.Errorf("failed to delete user %d: %w", userID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.delete", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			Before: profileSnapshot(user),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.forgetStatus(userID)
+	return nil
+}
+
+// ListDeleted returns soft-deleted users, most recently deleted first.
+func (s *userAdminService) ListDeleted(orgID *uint, page utils.Pagination) ([]DeletedUser, int64, error) {
+	query := s.orgScope(s.db, orgID).Unscoped().Model(&User{}).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted users: %w", err)
+	}
+	var users []User
+	if err := query.Preload("Roles").Order("deleted_at DESC, id").Scopes(page.Scope).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted users: %w", err)
+	}
+	deleted := make([]DeletedUser, 0, len(users))
+	for i := range users {
+		deleted = append(deleted, DeletedUser{UserDetail: newUserDetail(&users[i], nil), DeletedAt: users[i].DeletedAt.Time})
+	}
+	return deleted, total, nil
+}
+
+// Restore undoes a soft delete. The account stays deactivated unless activate is set, in which case it
+// counts against the plan's employee limit again.
+func (s *userAdminService) Restore(actor audit.Actor, orgID *uint, userID uint, activate bool) (*UserDetail, error) {
+	var restored *User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user User
+		query := s.orgScope(tx.Unscoped(), orgID).Where("deleted_at IS NOT NULL")
+		if err := query.First(&user, userID).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		if user.AnonymizedAt != nil {
+			return ErrAnonymized
+		}
+		if activate && user.OrganizationID != nil && s.limits != nil {
+			if err := s.limits.CheckEmployeeLimit(tx, *user.OrganizationID, 1); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&user).Updates(map[string]interface{}{"deleted_at": nil, "is_active": activate}).Error; err != nil {
+			return fmt.Errorf("failed to restore user %d: %w", userID, err)
+		}
+		var err error
+		if restored, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.restore", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			After: profileSnapshot(restored),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.forgetStatus(userID)
+	return s.detail(restored)
+}
+
+// SetStatus activates or deactivates a user. Deactivation takes effect on the user's next request:
+// their existing tokens are rejected and they can no longer log in.
+func (s *userAdminService) SetStatus(actor audit.Actor, orgID *uint, userID uint, active bool) (*UserDetail, error) {
+	if !active && isSelf(actor, userID) {
+		return nil, ErrCannotModifySelf
+	}
+	action := "user.deactivate"
+	if active {
+		action = "user.activate"
+	}
+
+	var user *User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if before.IsActive == active {
+			user = before
+			return nil
+		}
+		if err := tx.Model(before).Updates(map[string]interface{}{
+			"is_active": active,
+			"version":   gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update status of user %d: %w", userID, err)
+		}
+		if user, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: action, EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			Before: profileSnapshot(before), After: profileSnapshot(user),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.forgetStatus(userID)
+	return s.detail(user)
+}
+
+// ForceLogout revokes every token issued to the user so far; they must log in again. Unlike deactivation,
+// the account stays usable, so it's also the way to end sessions on a lost device.
+func (s *userAdminService) ForceLogout(actor audit.Actor, orgID *uint, userID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(user).Update("tokens_revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke tokens of user %d: %w", userID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.force_logout", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			After: map[string]time.Time{"tokens_revoked_at": now},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.forgetStatus(userID)
+	return nil
+}
+
+// ResetPassword is for emergencies, such as an admin locked out of their account; the password itself is
+// left out of the audit trail.
+func (s *userAdminService) ResetPassword(actor audit.Actor, orgID *uint, userID uint, password string) error {
+	if n := len(password); n < 6 || n > 72 {
+		return ErrInvalidPassword
+	}
+	hashed, err := HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password":          hashed,
+			"tokens_revoked_at": now,
+			"version":           gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset the password of user %d: %w", userID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.password_reset", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			After: map[string]time.Time{"tokens_revoked_at": now},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.forgetStatus(userID)
+	return nil
+}
+
+// ChangeUsername renames a user. Tokens issued so far carry the old username in their claims and are
+// revoked, so the user has to log in again. Audit and login history keep the username used at the time.
+func (s *userAdminService) ChangeUsername(actor audit.Actor, orgID *uint, userID uint, req ChangeUsernameRequest) (*UserDetail, error) {
+	username := strings.TrimSpace(req.Username)
+	if n := len(username); n < 3 || n > 100 {
+		return nil, ErrInvalidUsername
+	}
+	var user *User
+	var renamed bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if before.Username == username {
+			user = before
+			return nil
+		}
+		if err := claimUsername(tx, userID, username); err != nil {
+			return err
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(before).Updates(map[string]interface{}{
+			"username": username, "tokens_revoked_at": now, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			if IsUsernameConflict(err) {
+				return ErrUsernameTaken
+			}
+			return fmt.Errorf("failed to rename user %d: %w", userID, err)
+		}
+		if user, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		renamed = true
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.username_change", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+			Before: map[string]string{"username": before.Username}, After: map[string]string{"username": username},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renamed {
+		s.forgetStatus(userID)
+	}
+	return s.detail(user)
+}
+
+// claimUsername checks inside tx that no other user, deleted ones included, holds username in any letter
+// case. Two concurrent renames may both pass the check; the username index then rejects the second, see
+// IsUsernameConflict.
+func claimUsername(tx *gorm.DB, userID uint, username string) error {
+	var count int64
+	if err := tx.Unscoped().Model(&User{}).Where("id <> ? AND LOWER(username) = LOWER(?)", userID, username).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check existing users: %w", err)
+	}
+	if count > 0 {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// SetRoles replaces the user's global roles. Roles the user keeps retain their expiry; new roles are
+// granted permanently and apply to the user's next token. Removing a role revokes the user's tokens, so
+// the role ends at once rather than when they expire.
+func (s *userAdminService) SetRoles(actor audit.Actor, orgID *uint, userID uint, req SetUserRolesRequest) (*UserDetail, error) {
+	if isSelf(actor, userID) {
+		return nil, ErrCannotModifySelf
+	}
+	roles, err := FindRolesByIDs(s.db, req.RoleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *User
+	var revoked bool
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if user, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		before := user.RoleNames()
+		for _, r := range roles {
+			if IsElevatedRole(r.Name) && !user.HasRole(r.Name) {
+				return ErrElevatedRoleRequiresApproval
+			}
+		}
+
+		keep := make([]uint, 0, len(roles))
+		for _, r := range roles {
+			keep = append(keep, r.ID)
+		}
+		removed := tx.Where("user_id = ? AND role_id NOT IN ?", userID, keep).Delete(&UserRole{})
+		if removed.Error != nil {
+			return fmt.Errorf("failed to remove roles of user %d: %w", userID, removed.Error)
+		}
+		revoked = removed.RowsAffected > 0
+		for _, r := range roles {
+			if user.HasRole(r.Name) {
+				continue
+			}
+			if err := GrantRole(tx, userID, r.ID, nil); err != nil {
+				return fmt.Errorf("failed to grant role %s to user %d: %w", r.Name, userID, err)
+			}
+		}
+		updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+		if revoked {
+			updates["tokens_revoked_at"] = clock.Now().UTC()
+		}
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to bump version of user %d: %w", userID, err)
+		}
+
+		if user, err = s.load(tx, orgID, userID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.set_roles", EntityType: "user_role", EntityID: fmt.Sprintf("%d", userID),
+			Before: before, After: user.RoleNames(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		s.forgetStatus(userID)
+	}
+	return s.detail(user)
+}
+
+// forgetStatus drops the user's cached active state. The change is already committed, so a failure is
+// only logged; the entry expires on its own shortly after.
+func (s *userAdminService) forgetStatus(userID uint) {
+	if err := s.statuses.Invalidate(context.Background(), userID); err != nil {
+		log.Printf("Failed to invalidate cached status of user %d: %v", userID, err)
+	}
+}
+
+// orgScope restricts a query through db to the organization's users, unless the service reaches every
+// organization.
+func (s *userAdminService) orgScope(db *gorm.DB, orgID *uint) *gorm.DB {
+	if s.allOrganizations {
+		return db
+	}
+	return utils.OrgScope(db, orgID)
+}
+
+// load fetches a user with roles, within the organization scope.
+func (s *userAdminService) load(tx *gorm.DB, orgID *uint, userID uint) (*User, error) {
+	query := s.orgScope(tx.Preload("Roles"), orgID)
+	var user User
+	if err := query.First(&user, userID).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &user, nil
+}
+
+func (s *userAdminService) detail(user *User) (*UserDetail, error) {
+	expiries, err := s.grantExpiries([]uint{user.ID})
+	if err != nil {
+		return nil, err
+	}
+	detail := newUserDetail(user, expiries)
+	return &detail, nil
+}
+
+// grantExpiries loads the expiry of every time-limited grant of the users, keyed by user and role ID.
+func (s *userAdminService) grantExpiries(userIDs []uint) (map[[2]uint]time.Time, error) {
+	expiries := map[[2]uint]time.Time{}
+	if len(userIDs) == 0 {
+		return expiries, nil
+	}
+	var grants []UserRole
+	if err := s.db.Where("user_id IN ? AND expires_at IS NOT NULL", userIDs).Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to load role grants: %w", err)
+	}
+	for _, g := range grants {
+		expiries[[2]uint{g.UserID, g.RoleID}] = *g.ExpiresAt
+	}
+	return expiries, nil
+}
+
+func newUserDetail(user *User, expiries map[[2]uint]time.Time) UserDetail {
+	roles := make([]UserRoleDetail, 0, len(user.Roles))
+	for _, r := range user.Roles {
+		role := UserRoleDetail{ID: r.ID, Name: r.Name}
+		if expiresAt, ok := expiries[[2]uint{user.ID, r.ID}]; ok {
+			role.ExpiresAt = &expiresAt
+		}
+		roles = append(roles, role)
+	}
+	slices.SortFunc(roles, func(a, b UserRoleDetail) int { return strings.Compare(a.Name, b.Name) })
+	avatarURL := ""
+	if user.AvatarKey != "" {
+		avatarURL = AvatarURL(user.ID, user.AvatarKey)
+	}
+	return UserDetail{
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		IsActive:       user.IsActive,
+		Roles:          roles,
+		OrganizationID: user.OrganizationID,
+		LastLogin:      user.LastLogin,
+		AvatarURL:      avatarURL,
+		CustomFields:   user.CustomFields,
+		Version:        user.Version,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+// profileSnapshot is the audited part of a user; never the password hash.
+func profileSnapshot(user *User) map[string]interface{} {
+	return map[string]interface{}{"username": user.Username, "email": user.Email, "is_active": user.IsActive}
+}
+
+func isSelf(actor audit.Actor, userID uint) bool {
+	return actor.UserID != nil && *actor.UserID == userID
+}
+
+func valueOr(v *string, fallback string) string {
+	if v != nil {
+		return *v
+	}
+	return fallback
+}
+
+// UserAdminHandler handles HTTP requests for administrative user management.
+type UserAdminHandler struct {
+	service UserAdminService
+}
+
+// NewUserAdminHandler creates a new instance of UserAdminHandler.
+func NewUserAdminHandler(service UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{service: service}
+}
+
+// List returns users.
+// @Summary List users
+// @Description Admins only see the users of their own organization; platform admins those outside any organization.
+// @Tags Users
+// @Produce json
+// @Param q query string false "Case-insensitive match on username or email"
+// @Param role query string false "Role name"
+// @Param is_active query bool false "Active state"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: username, email, is_active, last_login, created_at, updated_at" default(username)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter or sort"
+// @Router /admin/users [get]
+func (h *UserAdminHandler) List(c *gin.Context) {
+	filter, sort, ok := parseUserQuery(c)
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	users, total, err := h.service.List(utils.OrganizationFromContext(c), filter, sort, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Users fetched successfully", page.Response(users, total))
+}
+
+// Export streams the users matching the list filters as a CSV or Excel file.
+// @Summary Export users
+// @Description Takes the same filters and sort as the user listing, without pagination.
+// @Tags Users
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param q query string false "Case-insensitive match on username or email"
+// @Param role query string false "Role name"
+// @Param is_active query bool false "Active state"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: username, email, is_active, last_login, created_at, updated_at" default(username)
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Invalid format, filter or sort"
+// @Router /admin/users/export [get]
+func (h *UserAdminHandler) Export(c *gin.Context) {
+	format, err := utils.ParseExportFormat(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, sort, ok := parseUserQuery(c)
+	if !ok {
+		return
+	}
+
+	// The file is started with the first batch, so errors before it still get a JSON response.
+	var rows utils.RowWriter
+	start := func() error {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"users-%s.%s\"", time.Now().UTC().Format("2006-01-02"), format))
+		c.Header("Cache-Control", "private, no-store")
+		c.Header("Content-Type", format.ContentType())
+		c.Status(http.StatusOK)
+		rows = utils.NewRowWriter(c.Writer, format)
+		return rows.WriteRow([]string{"id", "username", "email", "roles", "status", "last_login", "created_at"})
+	}
+	err = h.service.Export(audit.ActorFromContext(c), utils.OrganizationFromContext(c), filter, sort, func(users []UserDetail) error {
+		if rows == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, u := range users {
+			if err := rows.WriteRow(userExportRow(u)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && rows == nil {
+		err = start()
+	}
+	if err != nil {
+		if rows == nil {
+			utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// Headers are already sent; leave the file incomplete rather than pass it off as whole.
+		log.Printf("User export failed mid-stream: %v", err)
+		c.Abort()
+		return
+	}
+	if err := rows.Close(); err != nil {
+		log.Printf("User export failed to complete: %v", err)
+	}
+}
+
+// userExportRow formats a user as an export row.
+func userExportRow(u UserDetail) []string {
+	roles := make([]string, 0, len(u.Roles))
+	for _, r := range u.Roles {
+		roles = append(roles, r.Name)
+	}
+	status := "inactive"
+	if u.IsActive {
+		status = "active"
+	}
+	lastLogin := ""
+	if u.LastLogin != nil {
+		lastLogin = u.LastLogin.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		strconv.FormatUint(uint64(u.ID), 10), u.Username, u.Email, strings.Join(roles, " "), status,
+		lastLogin, u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// parseUserQuery reads the filters and sort shared by the user listing and export. It sends a 400 response
+// and returns ok=false for invalid parameters.
+func parseUserQuery(c *gin.Context) (filter UserFilter, sort utils.Sort, ok bool) {
+	// "search" is the parameter's former name, still accepted for existing clients.
+	filter = UserFilter{Search: strings.TrimSpace(c.DefaultQuery("q", c.Query("search"))), Role: c.Query("role")}
+	if raw := c.Query("is_active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid is_active parameter")
+			return filter, sort, false
+		}
+		filter.IsActive = &active
+	}
+
+	sort, err := utils.ParseSort(c, userSortFields, "username")
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid sort parameter: "+err.Error())
+		return filter, sort, false
+	}
+	return filter, sort, true
+}
+
+// Get returns a user. The ETag and Last-Modified headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get a user
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} UserDetail
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id} [get]
+func (h *UserAdminHandler) Get(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	user, err := h.service.Get(utils.OrganizationFromContext(c), userID)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, user.UpdatedAt, user.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "User fetched successfully", user)
+}
+
+// Update changes a user's username, email or active state.
+// @Summary Update a user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param user body UpdateUserRequest true "Fields to change"
+// @Success 200 {object} UserDetail
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Failure 409 {object} utils.ErrorResponse "Username or email taken"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /admin/users/{id} [put]
+func (h *UserAdminHandler) Update(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, userID)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	user, err := h.service.Update(audit.ActorFromContext(c), orgID, userID, expectedVersion, req)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, user.UpdatedAt, user.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
+
+// Delete deactivates and soft-deletes a user.
+// @Summary Delete a user
+// @Description The account is kept and can be restored with POST /admin/users/{id}/restore.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id} [delete]
+func (h *UserAdminHandler) Delete(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID); err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
+}
+
+// ListDeleted returns soft-deleted users that can be restored.
+// @Summary List deleted users
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /admin/users/deleted [get]
+func (h *UserAdminHandler) ListDeleted(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	users, total, err := h.service.ListDeleted(utils.OrganizationFromContext(c), page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Deleted users fetched successfully", page.Response(users, total))
+}
+
+// Restore undoes the deletion of a user.
+// @Summary Restore a deleted user
+// @Description The account is restored deactivated unless "activate" is set.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param restore body RestoreUserRequest false "Restore options"
+// @Success 200 {object} UserDetail
+// @Failure 402 {object} utils.ErrorResponse "Plan employee limit reached"
+// @Failure 404 {object} utils.ErrorResponse "No deleted user with this ID"
+// @Router /admin/users/{id}/restore [post]
+func (h *UserAdminHandler) Restore(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RestoreUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	user, err := h.service.Restore(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, req.Activate)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User restored successfully", user)
+}
+
+// SetStatus activates or deactivates a user.
+// @Summary Activate or deactivate a user
+// @Description Deactivated users are logged out immediately: their existing tokens are rejected.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param status body SetUserStatusRequest true "New status"
+// @Success 200 {object} UserDetail
+// @Failure 400 {object} utils.ErrorResponse "Invalid payload or own account"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/status [put]
+func (h *UserAdminHandler) SetStatus(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SetUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	user, err := h.service.SetStatus(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, *req.IsActive)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User status updated successfully", user)
+}
+
+// ForceLogout revokes all of a user's tokens.
+// @Summary Log a user out everywhere
+// @Description Every token issued to the user so far is rejected from the next request on. The account stays
+// @Description active; deactivate it as well to keep the user from logging in again.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/logout [post]
+func (h *UserAdminHandler) ForceLogout(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.ForceLogout(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID); err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User logged out successfully", nil)
+}
+
+// ChangeUsername renames a user.
+// @Summary Change a user's username
+// @Description Usernames must be unique ignoring case, including deleted users. The user's tokens are revoked
+// @Description because they carry the old username; the user has to log in again.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param username body ChangeUsernameRequest true "New username"
+// @Success 200 {object} UserDetail
+// @Failure 400 {object} utils.ErrorResponse "Invalid username"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Failure 409 {object} utils.ErrorResponse "Username already taken"
+// @Router /admin/users/{id}/username [put]
+func (h *UserAdminHandler) ChangeUsername(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.changeUsername(c, utils.OrganizationFromContext(c), userID)
+}
+
+// ChangeOwnUsername renames the caller. Only routed when the deployment lets users pick their username.
+// @Summary Change my username
+// @Description The caller's tokens are revoked because they carry the old username; log in again afterwards.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param username body ChangeUsernameRequest true "New username"
+// @Success 200 {object} UserDetail
+// @Failure 400 {object} utils.ErrorResponse "Invalid username"
+// @Failure 409 {object} utils.ErrorResponse "Username already taken"
+// @Router /me/username [put]
+func (h *UserAdminHandler) ChangeOwnUsername(c *gin.Context) {
+	h.changeUsername(c, nil, c.GetUint("userID"))
+}
+
+func (h *UserAdminHandler) changeUsername(c *gin.Context, orgID *uint, userID uint) {
+	var req ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	user, err := h.service.ChangeUsername(audit.ActorFromContext(c), orgID, userID, req)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Username changed successfully", user)
+}
+
+// SetRoles replaces a user's global roles.
+// @Summary Set a user's roles
+// @Description Elevated roles (hr, finance, admin, god-admin) the user doesn't already hold require a role request.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param roles body SetUserRolesRequest true "Role IDs"
+// @Success 200 {object} UserDetail
+// @Failure 400 {object} utils.ErrorResponse "Unknown or elevated role"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/role [put]
+func (h *UserAdminHandler) SetRoles(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	user, err := h.service.SetRoles(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, req)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User roles updated successfully", user)
+}
+
+// sendUserAdminError maps service errors to HTTP status codes.
+func sendUserAdminError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrElevatedRoleRequiresApproval), errors.Is(err, ErrCannotModifySelf),
+		errors.Is(err, ErrInvalidUsername):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrUserExists), errors.Is(err, ErrUsernameTaken), errors.Is(err, ErrAnonymized):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, lock.ErrLocked):
+		utils.SendErrorResponse(c, http.StatusConflict, "Another import into this organization is still running")
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The user was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
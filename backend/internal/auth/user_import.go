@@ -0,0 +1,560 @@
+// prometheus/backend/internal/auth/user_import.go
+package auth
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxImportRows bounds a single CSV import; larger files should be split.
+const maxImportRows = 1000
+
+// JobImportUsers is the job type creating the accounts of a queued UserImport.
+const JobImportUsers = "users.import"
+
+// Statuses of a UserImportResult.
+const (
+	ImportCreated = "created"
+	ImportFailed  = "failed"
+)
+
+// ErrInvalidImportFile is returned when the CSV can't be read or lacks the required columns.
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// EmployeeLimiter enforces the organization's plan limit on employee accounts. plan.Service implements it.
+type EmployeeLimiter interface {
+	CheckEmployeeLimit(tx *gorm.DB, orgID uint, adding int) error
+}
+
+// UserImportRow is one CSV row of a user import.
+type UserImportRow struct {
+	Line     int    `json:"line" example:"2"` // Line in the CSV file, header = 1
+	Username string `json:"username" example:"jdoe"`
+	Email    string `json:"email" example:"jdoe@acme.example"`
+	Role     string `json:"role" example:"staff"`           // Defaults to "staff"; elevated roles are not allowed
+	Division string `json:"division,omitempty" example:"3"` // Division ID; when set, the role is granted for this division only
+}
+
+// UserImportResult reports the outcome of one row. TemporaryPassword is only returned once, in this
+// report; users must change it after their first login.
+type UserImportResult struct {
+	Line              int    `json:"line" example:"2"`
+	Email             string `json:"email" example:"jdoe@acme.example"`
+	Status            string `json:"status" example:"created"` // created, failed
+	UserID            uint   `json:"user_id,omitempty" example:"42"`
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// UserImportReport summarizes a user import.
+type UserImportReport struct {
+	Created int                `json:"created" example:"148"`
+	Failed  int                `json:"failed" example:"2"`
+	DryRun  bool               `json:"dry_run"` // Created then counts the accounts that would be created
+	Rows    []UserImportResult `json:"rows"`
+}
+
+// UserImportStatus is where a queued user import is.
+type UserImportStatus string
+
+const (
+	UserImportPending   UserImportStatus = "pending"   // Waiting for a worker
+	UserImportCompleted UserImportStatus = "completed" // Every row has a result, see Report
+	UserImportFailed    UserImportStatus = "failed"    // Nothing was created, see Error
+)
+
+// UserImport is a queued CSV import. Hashing a temporary password per row takes too long for one request,
+// so accounts are created by a JobImportUsers job and the report is fetched afterwards. The report holds
+// the temporary passwords until it is first fetched, see UserAdminService.GetImport.
+type UserImport struct {
+	ID             uint             `gorm:"primaryKey" json:"id" example:"9"`
+	OrganizationID *uint            `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Status         UserImportStatus `gorm:"type:varchar(20);not null" json:"status" example:"completed"`
+	JobID          string           `gorm:"type:varchar(36)" json:"job_id,omitempty" example:"2b1f0c4e-8d3a-4c55-9a57-0f5a8e1d2c33"` // Operation creating the accounts
+	Rows           datatypes.JSON   `json:"-"`                                                                                       // The parsed CSV, cleared once processed
+	Created        int              `gorm:"not null" json:"created" example:"148"`
+	Failed         int              `gorm:"not null" json:"failed" example:"2"`
+	Report         datatypes.JSON   `json:"report,omitempty" swaggertype:"object"` // A UserImportReport
+	Error          string           `gorm:"type:varchar(500)" json:"error,omitempty"`
+	ImportedBy     *uint            `json:"imported_by,omitempty" example:"4"`             // User ID
+	ImporterName   string           `gorm:"type:varchar(100)" json:"-"`                    // Username, for the audit trail
+	PasswordsShown bool             `gorm:"not null;default:false" json:"passwords_shown"` // The report's temporary passwords were returned and removed
+	CreatedAt      time.Time        `json:"created_at"`
+	FinishedAt     *time.Time       `json:"finished_at,omitempty"`
+}
+
+// UserImportPayload is the payload of a JobImportUsers job.
+type UserImportPayload struct {
+	ImportID uint `json:"import_id"`
+}
+
+// ParseUserImportCSV reads a CSV with a header row naming the columns username, email, role and division
+// (in any order; role and division are optional).
+func ParseUserImportCSV(r io.Reader) ([]UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"username", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidImportFile, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []UserImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImportFile, maxImportRows)
+		}
+		rows = append(rows, UserImportRow{
+			Line:     line,
+			Username: field(record, "username"),
+			Email:    field(record, "email"),
+			Role:     field(record, "role"),
+			Division: field(record, "division"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidImportFile)
+	}
+	return rows, nil
+}
+
+// ValidateImport checks every row as Import would, including duplicates and plan limits, without creating
+// anything. Temporary passwords aren't generated, so this is quick enough to answer right away.
+func (s *userAdminService) ValidateImport(actor audit.Actor, orgID *uint, rows []UserImportRow) (*UserImportReport, error) {
+	return s.runImport(context.Background(), actor, orgID, rows, true, nil)
+}
+
+// Import queues the rows for a JobImportUsers job, which creates an account for every valid row, each with a
+// random temporary password, in the caller's organization.
+func (s *userAdminService) Import(actor audit.Actor, orgID *uint, rows []UserImportRow) (*UserImport, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode import rows: %w", err)
+	}
+	userImport := UserImport{
+		OrganizationID: orgID,
+		Status:         UserImportPending,
+		Rows:           data,
+		ImportedBy:     actor.UserID,
+		ImporterName:   actor.Username,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&userImport).Error; err != nil {
+			return fmt.Errorf("failed to create user import: %w", err)
+		}
+		job, err := s.queue.EnqueueTx(tx, JobImportUsers, UserImportPayload{ImportID: userImport.ID}, jobs.EnqueueOptions{CreatedBy: actor.UserID, MaxAttempts: 3})
+		if err != nil {
+			return err
+		}
+		userImport.JobID = job.ID
+		return tx.Model(&userImport).Update("job_id", job.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &userImport, nil
+}
+
+// GetImport returns a queued import with its report once processed. The report's temporary passwords are
+// only returned once: they are removed from the stored report as it is handed out.
+func (s *userAdminService) GetImport(orgID *uint, id uint) (*UserImport, error) {
+	var userImport UserImport
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		query := s.orgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID)
+		if err := query.First(&userImport, id).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		if len(userImport.Report) == 0 || userImport.PasswordsShown {
+			return nil
+		}
+		var report UserImportReport
+		if err := json.Unmarshal(userImport.Report, &report); err != nil {
+			return fmt.Errorf("invalid report of user import %d: %w", id, err)
+		}
+		for i := range report.Rows {
+			report.Rows[i].TemporaryPassword = ""
+		}
+		stored, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode the report of user import %d: %w", id, err)
+		}
+		return tx.Model(&userImport).Updates(map[string]interface{}{"report": datatypes.JSON(stored), "passwords_shown": true}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &userImport, nil
+}
+
+// ProcessImport runs a queued import. It runs in one transaction bound to ctx, so an import interrupted
+// by a shutdown leaves nothing behind and runs anew when its job is requeued.
+func (s *userAdminService) ProcessImport(ctx context.Context, id uint, reporter jobs.Reporter) (*UserImport, error) {
+	var userImport UserImport
+	if err := s.db.WithContext(ctx).First(&userImport, id).Error; err != nil {
+		return nil, err
+	}
+	if userImport.Status != UserImportPending {
+		return &userImport, nil
+	}
+	var rows []UserImportRow
+	if err := json.Unmarshal(userImport.Rows, &rows); err != nil {
+		return nil, fmt.Errorf("invalid rows of user import %d: %w", id, err)
+	}
+	actor := audit.Actor{UserID: userImport.ImportedBy, Username: userImport.ImporterName, OrganizationID: userImport.OrganizationID}
+
+	report, err := s.runImport(ctx, actor, userImport.OrganizationID, rows, false, reporter)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	now := clock.Now().UTC()
+	updates := map[string]interface{}{"rows": nil, "finished_at": now}
+	if err != nil {
+		updates["status"], updates["error"] = UserImportFailed, truncateImportError(err.Error())
+	} else {
+		updates["status"], updates["created"], updates["failed"] = UserImportCompleted, report.Created, report.Failed
+		data, encErr := json.Marshal(report)
+		if encErr != nil {
+			return nil, fmt.Errorf("failed to encode the report of user import %d: %w", id, encErr)
+		}
+		updates["report"] = datatypes.JSON(data)
+	}
+	if err := s.db.Model(&userImport).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to store the report of user import %d: %w", id, err)
+	}
+	if errors.Is(err, jobs.ErrCancelled) {
+		return nil, err // The operation ends cancelled; the import failed without creating anything
+	}
+	if err := s.db.First(&userImport, id).Error; err != nil {
+		return nil, err
+	}
+	return &userImport, nil
+}
+
+// runImport creates an account for every valid row. Rows are independent: a failing row is reported and
+// does not stop the others. With dryRun, rows are validated but nothing is created. Concurrent imports
+// into one organization would race on duplicates and the employee limit, so they wait for each other.
+func (s *userAdminService) runImport(ctx context.Context, actor audit.Actor, orgID *uint, rows []UserImportRow, dryRun bool, reporter jobs.Reporter) (*UserImportReport, error) {
+	var roles []role.Role
+	if err := s.db.WithContext(ctx).Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	rolesByName := make(map[string]role.Role, len(roles))
+	for _, r := range roles {
+		rolesByName[r.Name] = r
+	}
+
+	report := &UserImportReport{DryRun: dryRun, Rows: make([]UserImportResult, 0, len(rows))}
+	err := utils.WithTransaction(s.db.WithContext(ctx), dryRun, func(tx *gorm.DB) error {
+		if !dryRun {
+			if err := lock.Tx(tx, importLockName(orgID)); err != nil {
+				return err
+			}
+		}
+		seen := map[string]int{}
+		for i, row := range rows {
+			if reporter != nil && i%importProgressEvery == 0 {
+				if err := reporter.SetProgress(i*100/len(rows), fmt.Sprintf("Imported %d of %d rows", i, len(rows))); err != nil {
+					return err
+				}
+			}
+			result := UserImportResult{Line: row.Line, Email: row.Email}
+			err := validateImportRow(row, rolesByName, seen)
+			if err == nil {
+				// Each row runs in its own savepoint so a failed insert doesn't abort the import.
+				err = tx.Transaction(func(rowTx *gorm.DB) error {
+					return s.importUser(rowTx, orgID, row, rolesByName, dryRun, &result)
+				})
+			}
+			if err != nil {
+				result.Status, result.Error = ImportFailed, err.Error()
+				report.Failed++
+			} else {
+				result.Status = ImportCreated
+				report.Created++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+		if dryRun {
+			return nil
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.import", EntityType: "user", EntityID: "import",
+			After: map[string]int{"created": report.Created, "failed": report.Failed},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importProgressEvery is how many rows are imported between progress reports.
+const importProgressEvery = 50
+
+// truncateImportError keeps an error within UserImport.Error.
+func truncateImportError(message string) string {
+	if len(message) > 500 {
+		return message[:500]
+	}
+	return message
+}
+
+// ImportUsersJob processes the UserImport of a JobImportUsers job.
+func ImportUsersJob(service UserAdminService) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		var payload UserImportPayload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("invalid user import payload: %w", err)
+		}
+		userImport, err := service.ProcessImport(ctx, payload.ImportID, reporter)
+		if err != nil {
+			return nil, err
+		}
+		// The report itself holds temporary passwords and is fetched through the import, not the operation.
+		return map[string]interface{}{
+			"import_id": userImport.ID, "status": userImport.Status,
+			"created": userImport.Created, "failed": userImport.Failed,
+		}, nil
+	}
+}
+
+// importLockName is the lock serializing imports into an organization.
+func importLockName(orgID *uint) string {
+	if orgID == nil {
+		return "users.import"
+	}
+	return fmt.Sprintf("users.import:%d", *orgID)
+}
+
+// importUser creates a single account. Dry runs skip generating (and hashing) the temporary password.
+func (s *userAdminService) importUser(tx *gorm.DB, orgID *uint, row UserImportRow, rolesByName map[string]role.Role, dryRun bool, result *UserImportResult) error {
+	exists, err := UserExists(tx, row.Username, row.Email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrUserExists
+	}
+	if orgID != nil {
+		if err := s.limits.CheckEmployeeLimit(tx, *orgID, 1); err != nil {
+			return err
+		}
+	}
+
+	user := User{Username: row.Username, Email: row.Email, IsActive: true, OrganizationID: orgID}
+	if dryRun {
+		user.Password = "dry-run"
+	} else {
+		password, err := GenerateRandomPassword()
+		if err != nil {
+			return err
+		}
+		if user.Password, err = HashPassword(password); err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		result.TemporaryPassword = password
+	}
+
+	roleName := importRoleName(row)
+	divisionID, _ := importDivisionID(row) // Validated by validateImportRow
+	if divisionID == 0 {
+		user.Roles = []role.Role{rolesByName[roleName]}
+	} else {
+		// Division-scoped role on top of the baseline staff role, so the user can use the app at all.
+		user.Roles = []role.Role{rolesByName["staff"]}
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		if IsUsernameConflict(err) {
+			return ErrUserExists
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	if divisionID != 0 {
+		scoped := ScopedRole{UserID: user.ID, RoleID: rolesByName[roleName].ID, DivisionID: divisionID}
+		if err := tx.Create(&scoped).Error; err != nil {
+			return fmt.Errorf("failed to grant division role: %w", err)
+		}
+	}
+	if !dryRun {
+		result.UserID = user.ID
+	}
+	return nil
+}
+
+// validateImportRow checks a row without touching the database. seen tracks usernames and emails of
+// earlier rows so duplicates within the file are reported against the later line.
+func validateImportRow(row UserImportRow, rolesByName map[string]role.Role, seen map[string]int) error {
+	if n := len(row.Username); n < 3 || n > 100 {
+		return errors.New("username must be 3 to 100 characters")
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email || len(row.Email) > 100 {
+		return errors.New("invalid email address")
+	}
+	roleName := importRoleName(row)
+	if _, ok := rolesByName[roleName]; !ok || IsElevatedRole(roleName) {
+		return fmt.Errorf("role %q is not allowed", roleName)
+	}
+	divisionID, err := importDivisionID(row)
+	if err != nil {
+		return err
+	}
+	if divisionID != 0 {
+		if _, ok := rolesByName["staff"]; !ok {
+			return errors.New("the staff role does not exist")
+		}
+	}
+	for _, key := range []string{"username:" + strings.ToLower(row.Username), "email:" + strings.ToLower(row.Email)} {
+		if line, dup := seen[key]; dup {
+			return fmt.Errorf("duplicate of line %d", line)
+		}
+	}
+	seen["username:"+strings.ToLower(row.Username)] = row.Line
+	seen["email:"+strings.ToLower(row.Email)] = row.Line
+	return nil
+}
+
+// importDivisionID parses the row's division; 0 means none.
+func importDivisionID(row UserImportRow) (uint, error) {
+	if row.Division == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(row.Division, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid division %q", row.Division)
+	}
+	return uint(id), nil
+}
+
+func importRoleName(row UserImportRow) string {
+	if row.Role == "" {
+		return "staff"
+	}
+	return row.Role
+}
+
+// Import creates user accounts from a CSV file.
+// @Summary Import users from CSV
+// @Description Columns (header row required): username, email, role (optional, default staff), division
+// @Description (optional division ID; the role is then granted for that division only). Accounts are created
+// @Description in the background; poll the import for its report, or the operation in job_id for progress.
+// @Description Each created user gets a temporary password, returned once in the report. Use ?dry_run=true
+// @Description to validate only; the report is then returned right away.
+// @Tags Users
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "CSV file (multipart upload)"
+// @Param dry_run query bool false "Validate without creating accounts"
+// @Success 200 {object} UserImportReport "Dry run"
+// @Success 202 {object} UserImport
+// @Failure 400 {object} utils.ErrorResponse "Unreadable CSV"
+// @Router /admin/users/import [post]
+func (h *UserAdminHandler) Import(c *gin.Context) {
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Missing CSV file in form field \"file\"")
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Failed to read the uploaded file")
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	rows, err := ParseUserImportCSV(body)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if utils.IsDryRun(c) {
+		report, err := h.service.ValidateImport(audit.ActorFromContext(c), utils.OrganizationFromContext(c), rows)
+		if err != nil {
+			sendUserAdminError(c, err)
+			return
+		}
+		utils.SendSuccessResponse(c, http.StatusOK, fmt.Sprintf("%d users would be created, %d rows failed", report.Created, report.Failed), report)
+		return
+	}
+	userImport, err := h.service.Import(audit.ActorFromContext(c), utils.OrganizationFromContext(c), rows)
+	if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	c.Header("Location", fmt.Sprintf("/api/v1/admin/users/imports/%d", userImport.ID))
+	utils.SendSuccessResponse(c, http.StatusAccepted, "User import queued", userImport)
+}
+
+// GetImport returns a user import with its report once processed.
+// @Summary Get a user import's report
+// @Description The report is set once the import completes. Its temporary passwords are returned by the
+// @Description first request only and removed afterwards; passwords_shown tells whether they were.
+// @Tags Users
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} UserImport
+// @Failure 404 {object} utils.ErrorResponse "Import not found"
+// @Router /admin/users/imports/{id} [get]
+func (h *UserAdminHandler) GetImport(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userImport, err := h.service.GetImport(utils.OrganizationFromContext(c), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Import not found")
+		return
+	} else if err != nil {
+		sendUserAdminError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User import fetched successfully", userImport)
+}
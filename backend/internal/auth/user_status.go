@@ -0,0 +1,76 @@
+// prometheus/backend/internal/auth/user_status.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/cache"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// userStatusTTL bounds how long another instance may keep honouring a deactivated user's token when it
+// runs with its own in-memory cache; with Redis, invalidation is immediate everywhere.
+const userStatusTTL = time.Minute
+
+// UserStatusCache answers whether a user may still use their tokens, so AuthMiddleware can reject
+// deactivated, deleted or force-logged-out users without a database query on every request. Entries are
+// invalidated by UserAdminService whenever a user's active state changes or their tokens are revoked.
+type UserStatusCache struct {
+	db    *gorm.DB
+	cache cache.Cache
+}
+
+// NewUserStatusCache creates a new UserStatusCache.
+func NewUserStatusCache(db *gorm.DB, c cache.Cache) *UserStatusCache {
+	return &UserStatusCache{db: db, cache: c}
+}
+
+// UserStatus is the cached state of a user that decides whether their tokens are honoured.
+type UserStatus struct {
+	Active          bool  `json:"active"`
+	TokensRevokedAt int64 `json:"tokens_revoked_at,omitempty"` // Unix seconds; 0 = never revoked
+}
+
+// Accepts reports whether a token issued at issuedAt is still valid. Token timestamps have second
+// precision, so a token issued in the second of a revocation is rejected as well.
+func (s UserStatus) Accepts(issuedAt time.Time) bool {
+	return s.Active && issuedAt.Unix() > s.TokensRevokedAt
+}
+
+// Status returns whether the user exists, is not deleted and is active, and when their tokens were last revoked.
+func (s *UserStatusCache) Status(ctx context.Context, userID uint) (UserStatus, error) {
+	key := strconv.FormatUint(uint64(userID), 10)
+
+	var status UserStatus
+	found, err := s.cache.Get(ctx, cache.NamespaceUserStatus, key, &status)
+	if err == nil && found {
+		return status, nil
+	}
+
+	var user User
+	err = s.db.WithContext(ctx).Select("id", "is_active", "tokens_revoked_at").First(&user, userID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = UserStatus{}
+	case err != nil:
+		return UserStatus{}, fmt.Errorf("failed to load status of user %d: %w", userID, err)
+	default:
+		status = UserStatus{Active: user.IsActive}
+		if user.TokensRevokedAt != nil {
+			status.TokensRevokedAt = user.TokensRevokedAt.Unix()
+		}
+	}
+
+	// A failed cache write only costs a query next time.
+	_ = s.cache.Set(ctx, cache.NamespaceUserStatus, key, status, userStatusTTL)
+	return status, nil
+}
+
+// Invalidate drops the cached status of a user so the next request sees the change.
+func (s *UserStatusCache) Invalidate(ctx context.Context, userID uint) error {
+	return s.cache.Delete(ctx, cache.NamespaceUserStatus, strconv.FormatUint(uint64(userID), 10))
+}
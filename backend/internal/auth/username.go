@@ -0,0 +1,58 @@
+// prometheus/backend/internal/auth/username.go
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// usernameIndex makes usernames unique in any letter case, so "JDoe" can't be created next to "jdoe" on
+// any path: registration, imports, hiring or renames. It covers deleted users too, who may be restored.
+const usernameIndex = "idx_users_username_lower"
+
+// uniqueViolation is the Postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// MigrateUsernameIndex creates usernameIndex. It is safe to run on every start. Usernames that already
+// differ only in letter case must be renamed first, see UserAdminService.ChangeUsername; they are listed
+// in the error. Must run after AutoMigrate so that users exists.
+func MigrateUsernameIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&User{}, usernameIndex) {
+		return nil
+	}
+	var duplicates []string
+	if err := db.Unscoped().Model(&User{}).Select("LOWER(username)").Group("LOWER(username)").
+		Having("COUNT(*) > 1").Order("LOWER(username)").Limit(20).Scan(&duplicates).Error; err != nil {
+		return fmt.Errorf("failed to check usernames: %w", err)
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("usernames must be unique regardless of letter case; rename the users holding %s",
+			strings.Join(duplicates, ", "))
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + usernameIndex + " ON users (LOWER(username))").Error; err != nil {
+		return fmt.Errorf("failed to create the username index: %w", err)
+	}
+	return nil
+}
+
+// UserExists reports whether a user, deleted ones included, holds username in any letter case or email.
+// Creating one anyway fails on usernameIndex; the check gives a friendly error first.
+func UserExists(tx *gorm.DB, username, email string) (bool, error) {
+	var count int64
+	if err := tx.Unscoped().Model(&User{}).Where("LOWER(username) = LOWER(?) OR email = ?", username, email).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check existing users: %w", err)
+	}
+	return count > 0, nil
+}
+
+// IsUsernameConflict reports whether err is a violation of usernameIndex, e.g. by a user created
+// concurrently with the same username in another letter case.
+func IsUsernameConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == usernameIndex
+}
@@ -0,0 +1,436 @@
+// prometheus/backend/internal/authz/bundle.go
+package authz
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// bundleFormatVersion is bumped whenever the bundle layout changes incompatibly.
+const bundleFormatVersion = 1
+
+// ErrBundleSigningDisabled is returned when RBAC_BUNDLE_SECRET is not configured.
+var ErrBundleSigningDisabled = errors.New("RBAC bundles are disabled: no signing secret is configured")
+
+// ErrInvalidBundleSignature is returned when a bundle was not signed with this environment's secret
+// or was modified after export.
+var ErrInvalidBundleSignature = errors.New("invalid RBAC bundle signature")
+
+// ErrInvalidBundle is returned for bundles that can't be decoded or contain inconsistent entries.
+var ErrInvalidBundle = errors.New("invalid RBAC bundle")
+
+// RoleDefinition is a role as carried in an RBAC bundle.
+type RoleDefinition struct {
+	Name        string `json:"name" example:"payroll-clerk"`
+	Description string `json:"description" example:"Prepares payroll runs"`
+}
+
+// Bundle is the complete RBAC configuration of one domain: the roles, their policies and the role hierarchy.
+type Bundle struct {
+	FormatVersion int              `json:"format_version" example:"1"`
+	Environment   string           `json:"environment" example:"staging"` // APP_ENV of the exporting deployment
+	ExportedAt    time.Time        `json:"exported_at"`
+	Domain        string           `json:"domain" example:"default"`
+	Roles         []RoleDefinition `json:"roles"`
+	Policies      []Policy         `json:"policies"`
+	RoleLinks     []RoleLink       `json:"role_links"`
+}
+
+// SignedBundle is an exported Bundle with its HMAC-SHA256 signature. The signature covers the bundle
+// bytes exactly as exported, so the bundle must be imported unmodified.
+type SignedBundle struct {
+	Bundle    json.RawMessage `json:"bundle" binding:"required" swaggertype:"object"`
+	Signature string          `json:"signature" binding:"required" example:"9f2c..."`
+}
+
+// BundleDiff lists the changes an import makes (or would make, for a dry run) to the target environment.
+// Roles are only ever added or updated: roles missing from the bundle may still be assigned to users and
+// are left in place.
+type BundleDiff struct {
+	Environment      string           `json:"environment" example:"staging"` // Where the bundle was exported
+	ExportedAt       time.Time        `json:"exported_at"`
+	Domain           string           `json:"domain" example:"default"`
+	RolesAdded       []RoleDefinition `json:"roles_added"`
+	RolesUpdated     []RoleDefinition `json:"roles_updated"`
+	PoliciesAdded    []Policy         `json:"policies_added"`
+	PoliciesRemoved  []Policy         `json:"policies_removed"`
+	RoleLinksAdded   []RoleLink       `json:"role_links_added"`
+	RoleLinksRemoved []RoleLink       `json:"role_links_removed"`
+	Applied          bool             `json:"applied"` // False for dry runs
+}
+
+// Empty reports whether the bundle matches the target environment already.
+func (d *BundleDiff) Empty() bool {
+	return len(d.RolesAdded) == 0 && len(d.RolesUpdated) == 0 && len(d.PoliciesAdded) == 0 &&
+		len(d.PoliciesRemoved) == 0 && len(d.RoleLinksAdded) == 0 && len(d.RoleLinksRemoved) == 0
+}
+
+// BundleService exports the RBAC configuration as a signed bundle and imports bundles from other
+// environments, so policy changes can be tried on staging and promoted to production as a whole.
+type BundleService interface {
+	Export(domain string) (*SignedBundle, error)
+	Import(actor audit.Actor, signed SignedBundle, dryRun bool) (*BundleDiff, error)
+}
+
+// bundleService implements the BundleService interface.
+type bundleService struct {
+	db          *gorm.DB
+	enforcer    *casbin.SyncedEnforcer
+	permissions *PermissionCache
+	auditor     audit.Service
+	secret      []byte
+	environment string
+}
+
+// NewBundleService creates a new instance of BundleService. Environments exchanging bundles must share
+// secret (RBAC_BUNDLE_SECRET); an empty secret disables export and import.
+func NewBundleService(db *gorm.DB, enforcer *casbin.SyncedEnforcer, permissions *PermissionCache, auditor audit.Service, secret, environment string) BundleService {
+	return &bundleService{db: db, enforcer: enforcer, permissions: permissions, auditor: auditor, secret: []byte(secret), environment: environment}
+}
+
+// Export snapshots the roles, policies and role links of a domain.
+func (s *bundleService) Export(domain string) (*SignedBundle, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrBundleSigningDisabled
+	}
+	domain = domainOrDefault(domain)
+	roles, err := s.currentRoles()
+	if err != nil {
+		return nil, err
+	}
+	policies, links, err := s.currentRules(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := Bundle{
+		FormatVersion: bundleFormatVersion,
+		Environment:   s.environment,
+		ExportedAt:    time.Now().UTC(),
+		Domain:        domain,
+		Roles:         make([]RoleDefinition, 0, len(roles)),
+		Policies:      policies,
+		RoleLinks:     links,
+	}
+	for _, r := range roles {
+		bundle.Roles = append(bundle.Roles, RoleDefinition{Name: r.Name, Description: r.Description})
+	}
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode RBAC bundle: %w", err)
+	}
+	return &SignedBundle{Bundle: raw, Signature: s.sign(raw)}, nil
+}
+
+// Import verifies a bundle and makes the bundle's domain match it: missing roles are created, role
+// descriptions updated, and policies and role links added or removed. With dryRun, only the diff is returned.
+func (s *bundleService) Import(actor audit.Actor, signed SignedBundle, dryRun bool) (*BundleDiff, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrBundleSigningDisabled
+	}
+	if !hmac.Equal([]byte(s.sign(signed.Bundle)), []byte(signed.Signature)) {
+		return nil, ErrInvalidBundleSignature
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(signed.Bundle, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if err := validateBundle(&bundle); err != nil {
+		return nil, err
+	}
+
+	diff, err := s.diff(&bundle)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return diff, nil
+	}
+	if diff.Empty() {
+		diff.Applied = true
+		return diff, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range diff.RolesAdded {
+			if err := tx.Create(&role.Role{Name: r.Name, Description: r.Description}).Error; err != nil {
+				return fmt.Errorf("failed to create role %s: %w", r.Name, err)
+			}
+		}
+		for _, r := range diff.RolesUpdated {
+			if err := tx.Model(&role.Role{}).Where("name = ?", r.Name).Updates(map[string]interface{}{
+				"description": r.Description,
+				"version":     gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update role %s: %w", r.Name, err)
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "rbac.import", EntityType: "rbac_bundle", EntityID: bundle.Domain, After: diff,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Casbin rules live outside the transaction. The diff is recomputed against the current state on every
+	// import, so re-running the import repairs a partial failure here.
+	if err := s.applyRules(diff); err != nil {
+		return nil, err
+	}
+	if err := s.permissions.InvalidateAll(context.Background()); err != nil {
+		log.Printf("Warning: failed to invalidate permission cache: %v", err)
+	}
+	diff.Applied = true
+	return diff, nil
+}
+
+// diff compares a bundle with the current configuration of its domain.
+func (s *bundleService) diff(bundle *Bundle) (*BundleDiff, error) {
+	roles, err := s.currentRoles()
+	if err != nil {
+		return nil, err
+	}
+	policies, links, err := s.currentRules(bundle.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &BundleDiff{
+		Environment:      bundle.Environment,
+		ExportedAt:       bundle.ExportedAt,
+		Domain:           bundle.Domain,
+		RolesAdded:       []RoleDefinition{},
+		RolesUpdated:     []RoleDefinition{},
+		PoliciesAdded:    missingFrom(bundle.Policies, policies),
+		PoliciesRemoved:  missingFrom(policies, bundle.Policies),
+		RoleLinksAdded:   missingFrom(bundle.RoleLinks, links),
+		RoleLinksRemoved: missingFrom(links, bundle.RoleLinks),
+	}
+	existing := make(map[string]string, len(roles))
+	for _, r := range roles {
+		existing[r.Name] = r.Description
+	}
+	for _, r := range bundle.Roles {
+		description, ok := existing[r.Name]
+		switch {
+		case !ok:
+			diff.RolesAdded = append(diff.RolesAdded, r)
+		case description != r.Description:
+			diff.RolesUpdated = append(diff.RolesUpdated, r)
+		}
+	}
+	return diff, nil
+}
+
+// applyRules removes obsolete rules before adding new ones, so a policy whose effect changed is replaced.
+func (s *bundleService) applyRules(diff *BundleDiff) error {
+	if len(diff.PoliciesRemoved) > 0 {
+		if _, err := s.enforcer.RemovePolicies(policyRules(diff.PoliciesRemoved)); err != nil {
+			return fmt.Errorf("failed to remove policies: %w", err)
+		}
+	}
+	if len(diff.RoleLinksRemoved) > 0 {
+		if _, err := s.enforcer.RemoveGroupingPolicies(roleLinkRules(diff.RoleLinksRemoved)); err != nil {
+			return fmt.Errorf("failed to remove role links: %w", err)
+		}
+	}
+	if len(diff.PoliciesAdded) > 0 {
+		if _, err := s.enforcer.AddPolicies(policyRules(diff.PoliciesAdded)); err != nil {
+			return fmt.Errorf("failed to add policies: %w", err)
+		}
+	}
+	if len(diff.RoleLinksAdded) > 0 {
+		if _, err := s.enforcer.AddGroupingPolicies(roleLinkRules(diff.RoleLinksAdded)); err != nil {
+			return fmt.Errorf("failed to add role links: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *bundleService) currentRoles() ([]role.Role, error) {
+	var roles []role.Role
+	if err := s.db.Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *bundleService) currentRules(domain string) ([]Policy, []RoleLink, error) {
+	rules, err := s.enforcer.GetFilteredPolicy(1, domain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	policies := make([]Policy, 0, len(rules))
+	for _, r := range rules {
+		if p, ok := PolicyFromRule(r); ok {
+			policies = append(policies, p)
+		}
+	}
+
+	grouping, err := s.enforcer.GetFilteredGroupingPolicy(2, domain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list role links: %w", err)
+	}
+	links := make([]RoleLink, 0, len(grouping))
+	for _, r := range grouping {
+		if len(r) >= 3 {
+			links = append(links, RoleLink{Role: r[0], Parent: r[1], Domain: r[2]})
+		}
+	}
+	return policies, links, nil
+}
+
+func (s *bundleService) sign(raw []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(raw)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateBundle rejects bundles whose entries don't belong to the bundle's domain, so an import can
+// never touch another tenant's rules. Missing effects default to allow.
+func validateBundle(b *Bundle) error {
+	if b.FormatVersion != bundleFormatVersion {
+		return fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, b.FormatVersion)
+	}
+	if b.Domain == "" {
+		return fmt.Errorf("%w: missing domain", ErrInvalidBundle)
+	}
+	for i := range b.Policies {
+		p := &b.Policies[i]
+		p.Effect = effectOrDefault(p.Effect)
+		if p.Domain != b.Domain || p.Subject == "" || p.Object == "" || p.Action == "" {
+			return fmt.Errorf("%w: policy %d is incomplete or outside domain %s", ErrInvalidBundle, i, b.Domain)
+		}
+		if p.Effect != EffectAllow && p.Effect != EffectDeny {
+			return fmt.Errorf("%w: policy %d has unknown effect %q", ErrInvalidBundle, i, p.Effect)
+		}
+	}
+	for i, l := range b.RoleLinks {
+		if l.Domain != b.Domain || l.Role == "" || l.Parent == "" {
+			return fmt.Errorf("%w: role link %d is incomplete or outside domain %s", ErrInvalidBundle, i, b.Domain)
+		}
+	}
+	for i, r := range b.Roles {
+		if r.Name == "" || len(r.Name) > 50 {
+			return fmt.Errorf("%w: role %d has an invalid name", ErrInvalidBundle, i)
+		}
+	}
+	return nil
+}
+
+// missingFrom returns the entries of a that are not in b.
+func missingFrom[T comparable](a, b []T) []T {
+	missing := []T{}
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+func policyRules(policies []Policy) [][]string {
+	rules := make([][]string, 0, len(policies))
+	for _, p := range policies {
+		rules = append(rules, []string{p.Subject, p.Domain, p.Object, p.Action, p.Effect})
+	}
+	return rules
+}
+
+func roleLinkRules(links []RoleLink) [][]string {
+	rules := make([][]string, 0, len(links))
+	for _, l := range links {
+		rules = append(rules, []string{l.Role, l.Parent, l.Domain})
+	}
+	return rules
+}
+
+// BundleHandler handles HTTP requests for RBAC export and import.
+type BundleHandler struct {
+	service BundleService
+}
+
+// NewBundleHandler creates a new instance of BundleHandler.
+func NewBundleHandler(service BundleService) *BundleHandler {
+	return &BundleHandler{service: service}
+}
+
+// Export returns the signed RBAC bundle of a domain.
+// @Summary Export the RBAC configuration
+// @Description Roles, policies and role links of a domain, signed for import into another environment.
+// @Tags Authorization
+// @Produce json
+// @Param domain query string false "Domain (defaults to 'default')"
+// @Success 200 {object} SignedBundle
+// @Failure 503 {object} utils.ErrorResponse "No signing secret configured"
+// @Router /admin/rbac/export [get]
+func (h *BundleHandler) Export(c *gin.Context) {
+	bundle, err := h.service.Export(c.Query("domain"))
+	if err != nil {
+		sendBundleError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "RBAC bundle exported successfully", bundle)
+}
+
+// Import applies a signed RBAC bundle. Use ?dry_run=true to preview the diff without changing anything.
+// @Summary Import an RBAC bundle
+// @Description Makes the bundle's domain match the bundle. Roles are added or updated, never removed.
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Only return the diff"
+// @Param bundle body SignedBundle true "Bundle from GET /admin/rbac/export"
+// @Success 200 {object} BundleDiff
+// @Failure 400 {object} utils.ErrorResponse "Invalid bundle"
+// @Failure 403 {object} utils.ErrorResponse "Invalid signature"
+// @Failure 503 {object} utils.ErrorResponse "No signing secret configured"
+// @Router /admin/rbac/import [post]
+func (h *BundleHandler) Import(c *gin.Context) {
+	var req SignedBundle
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	diff, err := h.service.Import(audit.ActorFromContext(c), req, utils.IsDryRun(c))
+	if err != nil {
+		sendBundleError(c, err)
+		return
+	}
+	message := "RBAC bundle imported successfully"
+	if !diff.Applied {
+		message = "RBAC bundle diff computed; nothing was changed"
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, message, diff)
+}
+
+// sendBundleError maps service errors to HTTP status codes.
+func sendBundleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrBundleSigningDisabled):
+		utils.SendErrorResponse(c, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, ErrInvalidBundleSignature):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrInvalidBundle):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
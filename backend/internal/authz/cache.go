@@ -0,0 +1,131 @@
+// prometheus/backend/internal/authz/cache.go
+package authz
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/cache"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/util"
+)
+
+// permissionCacheTTL bounds staleness in case an invalidation is ever missed.
+const permissionCacheTTL = 15 * time.Minute
+
+// PermissionCache caches the effective (inherited) permission set of each role per domain,
+// so callers that need the full set don't recompute it from the policy tables on every request.
+// Entries are invalidated by PolicyService whenever policies or role links change.
+type PermissionCache struct {
+	enforcer *casbin.SyncedEnforcer
+	cache    cache.Cache
+}
+
+// NewPermissionCache creates a new PermissionCache.
+func NewPermissionCache(enforcer *casbin.SyncedEnforcer, c cache.Cache) *PermissionCache {
+	return &PermissionCache{enforcer: enforcer, cache: c}
+}
+
+// RolePermissions returns the permissions a role holds in a domain, including inherited ones.
+func (p *PermissionCache) RolePermissions(ctx context.Context, role, domain string) ([]Policy, error) {
+	domain = domainOrDefault(domain)
+	key := domain + ":" + role
+
+	var policies []Policy
+	found, err := p.cache.Get(ctx, cache.NamespacePermissions, key, &policies)
+	if err == nil && found {
+		return policies, nil
+	}
+
+	rules, err := p.enforcer.GetImplicitPermissionsForUser(role, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve permissions for role %s: %w", role, err)
+	}
+	policies = make([]Policy, 0, len(rules))
+	for _, r := range rules {
+		if p, ok := PolicyFromRule(r); ok {
+			policies = append(policies, p)
+		}
+	}
+
+	// A failed cache write only costs a recomputation next time.
+	_ = p.cache.Set(ctx, cache.NamespacePermissions, key, policies, permissionCacheTTL)
+	return policies, nil
+}
+
+// InvalidateRole drops the cached permission set of a single role (e.g. after the role was renamed or deleted).
+func (p *PermissionCache) InvalidateRole(ctx context.Context, role, domain string) error {
+	return p.cache.Delete(ctx, cache.NamespacePermissions, domainOrDefault(domain)+":"+role)
+}
+
+// InvalidateAll drops every cached permission set. Role inheritance means a single policy change
+// can affect many roles, so policy mutations flush the whole namespace.
+func (p *PermissionCache) InvalidateAll(ctx context.Context) error {
+	return p.cache.Flush(ctx, cache.NamespacePermissions)
+}
+
+// EffectivePermissions is the permission set of a single user: the union of their roles' (inherited)
+// permissions and any user-specific policies, with allows shadowed by a deny removed.
+type EffectivePermissions struct {
+	Domain      string   `json:"domain" example:"default"`
+	Roles       []string `json:"roles" example:"manager,hr"`
+	Permissions []Policy `json:"permissions"`      // Effective allows
+	Denied      []Policy `json:"denied,omitempty"` // Explicit denies that apply to the user
+}
+
+// UserPermissions resolves the effective permissions of a user holding roles in a domain.
+// Role sets come from the cache; user-specific policies are read from the enforcer directly.
+func (p *PermissionCache) UserPermissions(ctx context.Context, userID uint, roles []string, domain string) (*EffectivePermissions, error) {
+	domain = domainOrDefault(domain)
+
+	var all []Policy
+	for _, role := range roles {
+		policies, err := p.RolePermissions(ctx, role, domain)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, policies...)
+	}
+	userRules, err := p.enforcer.GetFilteredPolicy(0, UserSubject(userID), domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies for user %d: %w", userID, err)
+	}
+	for _, r := range userRules {
+		if policy, ok := PolicyFromRule(r); ok {
+			all = append(all, policy)
+		}
+	}
+
+	result := &EffectivePermissions{Domain: domain, Roles: roles, Permissions: []Policy{}}
+	for _, policy := range all {
+		if policy.Effect == EffectDeny {
+			result.Denied = append(result.Denied, policy)
+		}
+	}
+	seen := map[string]bool{}
+	for _, policy := range all {
+		if policy.Effect == EffectDeny || isDenied(policy, result.Denied) {
+			continue
+		}
+		// The same object/action is typically granted by several inherited roles; report it once.
+		key := policy.Object + " " + policy.Action
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.Permissions = append(result.Permissions, policy)
+	}
+	return result, nil
+}
+
+// isDenied reports whether a deny covers the whole of an allow (same or wildcard action, and a deny
+// object pattern that matches the allow's object). Partial overlaps are left for the enforcer to decide.
+func isDenied(allow Policy, denies []Policy) bool {
+	for _, deny := range denies {
+		if (deny.Action == "*" || deny.Action == allow.Action) && util.KeyMatch2(allow.Object, deny.Object) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// prometheus/backend/internal/authz/enforcer.go
+package authz
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	casbinmodel "github.com/casbin/casbin/v2/model"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+)
+
+// NewEnforcer creates a Casbin enforcer whose policies are stored in the casbin_rule table.
+// If the table is empty, it is seeded with the default role matrix.
+func NewEnforcer(db *gorm.DB) (*casbin.SyncedEnforcer, error) {
+	adapter, err := gormadapter.NewAdapterByDB(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create casbin gorm adapter: %w", err)
+	}
+
+	m, err := casbinmodel.NewModelFromString(casbinModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse casbin model: %w", err)
+	}
+
+	// Policies created before deny rules existed have no effect column; they were all allows.
+	if err := db.Exec("UPDATE casbin_rule SET v4 = ? WHERE ptype = 'p' AND (v4 IS NULL OR v4 = '')", EffectAllow).Error; err != nil {
+		return nil, fmt.Errorf("failed to backfill policy effects: %w", err)
+	}
+
+	enforcer, err := casbin.NewSyncedEnforcer(m, adapter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create casbin enforcer: %w", err)
+	}
+	enforcer.AddFunction("hasAnyRole", hasAnyRoleFunc(enforcer))
+
+	if err := enforcer.LoadPolicy(); err != nil {
+		return nil, fmt.Errorf("failed to load casbin policies: %w", err)
+	}
+
+	if err := seedDefaults(enforcer); err != nil {
+		return nil, err
+	}
+
+	return enforcer, nil
+}
+
+// seedDefaults inserts the default policies and role links when no policies exist yet.
+func seedDefaults(enforcer *casbin.SyncedEnforcer) error {
+	existing, err := enforcer.GetPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to read casbin policies: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	log.Println("Seeding default authorization policies...")
+	for _, p := range defaultPolicies {
+		if _, err := enforcer.AddPolicy(p.Subject, p.Domain, p.Object, p.Action, p.Effect); err != nil {
+			return fmt.Errorf("failed to seed policy %v: %w", p, err)
+		}
+	}
+	for _, l := range defaultRoleLinks {
+		if _, err := enforcer.AddGroupingPolicy(l.Role, l.Parent, l.Domain); err != nil {
+			return fmt.Errorf("failed to seed role link %v: %w", l, err)
+		}
+	}
+	log.Println("Default authorization policies seeded.")
+	return nil
+}
+
+// hasAnyRoleFunc builds the hasAnyRole(roles, sub, dom) matcher function: true if any of the
+// comma-separated roles equals sub or inherits from it through "g" links in dom.
+func hasAnyRoleFunc(enforcer *casbin.SyncedEnforcer) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 3 {
+			return false, fmt.Errorf("hasAnyRole expects 3 arguments, got %d", len(args))
+		}
+		roles, _ := args[0].(string)
+		sub, _ := args[1].(string)
+		dom, _ := args[2].(string)
+		if roles == "" || sub == "" {
+			return false, nil
+		}
+		// Use the embedded Enforcer: the SyncedEnforcer lock is already held while matching.
+		rm := enforcer.Enforcer.GetRoleManager()
+		for _, r := range strings.Split(roles, ",") {
+			if r == sub {
+				return true, nil
+			}
+			linked, err := rm.HasLink(r, sub, dom)
+			if err != nil {
+				return false, err
+			}
+			if linked {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// SeedDomain installs the default role matrix into a domain (e.g. a newly onboarded organization).
+// Rules that already exist are skipped, so it is safe to run repeatedly. It returns how many rules were added.
+func SeedDomain(enforcer *casbin.SyncedEnforcer, domain string) (int, error) {
+	added := 0
+	for _, p := range defaultPolicies {
+		ok, err := enforcer.AddPolicy(p.Subject, domain, p.Object, p.Action, p.Effect)
+		if err != nil {
+			return added, fmt.Errorf("failed to seed policy %v in domain %s: %w", p, domain, err)
+		}
+		if ok {
+			added++
+		}
+	}
+	for _, l := range defaultRoleLinks {
+		ok, err := enforcer.AddGroupingPolicy(l.Role, l.Parent, domain)
+		if err != nil {
+			return added, fmt.Errorf("failed to seed role link %v in domain %s: %w", l, domain, err)
+		}
+		if ok {
+			added++
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,191 @@
+// prometheus/backend/internal/authz/handler.go
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyHandler handles HTTP requests for authorization policy management.
+type PolicyHandler struct {
+	service PolicyService
+}
+
+// NewPolicyHandler creates a new instance of PolicyHandler.
+func NewPolicyHandler(service PolicyService) *PolicyHandler {
+	return &PolicyHandler{service: service}
+}
+
+// ListPolicies returns the policies of a domain.
+// @Summary List authorization policies
+// @Tags Authorization
+// @Produce json
+// @Param domain query string false "Domain (defaults to 'default')"
+// @Success 200 {array} Policy
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/policies [get]
+func (h *PolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.service.ListPolicies(c.Query("domain"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Policies fetched successfully", policies)
+}
+
+// AddPolicy creates a policy.
+// @Summary Add an authorization policy
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param policy body Policy true "Policy to add"
+// @Success 201 {object} Policy
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 409 {object} utils.ErrorResponse "Policy already exists"
+// @Router /admin/policies [post]
+func (h *PolicyHandler) AddPolicy(c *gin.Context) {
+	var req Policy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if err := h.service.AddPolicy(audit.ActorFromContext(c), req); err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	req.Domain = domainOrDefault(req.Domain)
+	req.Effect = effectOrDefault(req.Effect)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Policy added successfully", req)
+}
+
+// RemovePolicy deletes a policy.
+// @Summary Remove an authorization policy
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param policy body Policy true "Policy to remove"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Policy not found"
+// @Router /admin/policies [delete]
+func (h *PolicyHandler) RemovePolicy(c *gin.Context) {
+	var req Policy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if err := h.service.RemovePolicy(audit.ActorFromContext(c), req); err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Policy removed successfully", nil)
+}
+
+// ListRoleLinks returns the role inheritance links of a domain.
+// @Summary List role inheritance links
+// @Tags Authorization
+// @Produce json
+// @Param domain query string false "Domain (defaults to 'default')"
+// @Success 200 {array} RoleLink
+// @Router /admin/policies/role-links [get]
+func (h *PolicyHandler) ListRoleLinks(c *gin.Context) {
+	links, err := h.service.ListRoleLinks(c.Query("domain"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Role links fetched successfully", links)
+}
+
+// AddRoleLink creates a role inheritance link.
+// @Summary Add a role inheritance link
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param link body RoleLink true "Role link to add"
+// @Success 201 {object} RoleLink
+// @Failure 409 {object} utils.ErrorResponse "Role link already exists"
+// @Router /admin/policies/role-links [post]
+func (h *PolicyHandler) AddRoleLink(c *gin.Context) {
+	var req RoleLink
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if err := h.service.AddRoleLink(audit.ActorFromContext(c), req); err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	req.Domain = domainOrDefault(req.Domain)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Role link added successfully", req)
+}
+
+// RemoveRoleLink deletes a role inheritance link.
+// @Summary Remove a role inheritance link
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param link body RoleLink true "Role link to remove"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Role link not found"
+// @Router /admin/policies/role-links [delete]
+func (h *PolicyHandler) RemoveRoleLink(c *gin.Context) {
+	var req RoleLink
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if err := h.service.RemoveRoleLink(audit.ActorFromContext(c), req); err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Role link removed successfully", nil)
+}
+
+// sendPolicyError maps service errors to HTTP status codes.
+func sendPolicyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrPolicyExists):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrPolicyNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// RoleResolver extracts the caller's role names from the request context.
+type RoleResolver func(c *gin.Context) []string
+
+// PermissionHandler exposes the caller's effective permissions, so the frontend can show or hide
+// UI elements without duplicating the role matrix.
+type PermissionHandler struct {
+	cache *PermissionCache
+	roles RoleResolver
+}
+
+// NewPermissionHandler creates a new instance of PermissionHandler.
+// roles is usually middleware.AllRoleNamesFromContext.
+func NewPermissionHandler(cache *PermissionCache, roles RoleResolver) *PermissionHandler {
+	return &PermissionHandler{cache: cache, roles: roles}
+}
+
+// MyPermissions returns the authenticated user's effective permissions.
+// @Summary Get my effective permissions
+// @Description Returns the caller's roles and the object/action pairs they may access in their domain, after inheritance and explicit denies.
+// @Tags Authorization
+// @Produce json
+// @Success 200 {object} EffectivePermissions
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /me/permissions [get]
+func (h *PermissionHandler) MyPermissions(c *gin.Context) {
+	permissions, err := h.cache.UserPermissions(c.Request.Context(), c.GetUint("userID"), h.roles(c), c.GetString("domain"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Permissions fetched successfully", permissions)
+}
@@ -0,0 +1,105 @@
+// prometheus/backend/internal/authz/model.go
+package authz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultDomain is the Casbin domain used until multi-tenancy lands.
+const DefaultDomain = "default"
+
+// Policy effects. A matching deny always overrides any matching allow.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// casbinModel is the RBAC-with-domains model used by the enforcer.
+// The request carries the user subject ("user:<id>") and the user's roles (comma separated).
+// Policy subjects are either role names (inherited through "g" links within a domain, checked by
+// hasAnyRole) or a specific user subject, so e.g. one user can be denied payroll access their role allows.
+// Objects are request paths matched with keyMatch2 (e.g. /api/v1/hr/*), actions are HTTP methods or "*".
+const casbinModel = `
+[request_definition]
+r = usr, roles, dom, obj, act
+
+[policy_definition]
+p = sub, dom, obj, act, eft
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = (r.usr == p.sub || hasAnyRole(r.roles, p.sub, r.dom)) && r.dom == p.dom && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == "*")
+`
+
+// UserSubject returns the policy subject addressing a single user.
+func UserSubject(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// JoinRoles encodes a role list for the "roles" request field.
+func JoinRoles(roles []string) string {
+	return strings.Join(roles, ",")
+}
+
+// Policy is a single permission rule: Subject (role name or "user:<id>") is allowed or denied
+// Action on Object within Domain.
+type Policy struct {
+	Subject string `json:"subject" binding:"required" example:"hr"`
+	Domain  string `json:"domain" example:"default"`
+	Object  string `json:"object" binding:"required" example:"/api/v1/hr/*"`
+	Action  string `json:"action" binding:"required" example:"GET"`
+	Effect  string `json:"effect" binding:"omitempty,oneof=allow deny" example:"allow"` // Defaults to "allow"
+}
+
+// PolicyFromRule converts a Casbin policy row to a Policy.
+func PolicyFromRule(r []string) (Policy, bool) {
+	if len(r) < 4 {
+		return Policy{}, false
+	}
+	p := Policy{Subject: r[0], Domain: r[1], Object: r[2], Action: r[3], Effect: EffectAllow}
+	if len(r) > 4 && r[4] != "" {
+		p.Effect = r[4]
+	}
+	return p, true
+}
+
+// effectOrDefault falls back to EffectAllow when no effect is given.
+func effectOrDefault(effect string) string {
+	if effect == "" {
+		return EffectAllow
+	}
+	return effect
+}
+
+// RoleLink makes Role inherit all permissions of Parent within Domain.
+type RoleLink struct {
+	Role   string `json:"role" binding:"required" example:"admin"`
+	Parent string `json:"parent" binding:"required" example:"hr"`
+	Domain string `json:"domain" example:"default"`
+}
+
+// defaultPolicies mirrors the role matrix that used to be hardcoded in router.go.
+var defaultPolicies = []Policy{
+	{Subject: "staff", Domain: DefaultDomain, Object: "/api/v1/staff-area/*", Action: "*", Effect: EffectAllow},
+	{Subject: "manager", Domain: DefaultDomain, Object: "/api/v1/manager/*", Action: "*", Effect: EffectAllow},
+	{Subject: "hr", Domain: DefaultDomain, Object: "/api/v1/hr/*", Action: "*", Effect: EffectAllow},
+	{Subject: "admin", Domain: DefaultDomain, Object: "/api/v1/admin/*", Action: "*", Effect: EffectAllow},
+	{Subject: "finance", Domain: DefaultDomain, Object: "/api/v1/finance/*", Action: "*", Effect: EffectAllow},
+}
+
+// defaultRoleLinks builds the hierarchy god-admin > admin > hr > manager > staff, with finance beside it:
+// finance > staff, and admin > finance.
+var defaultRoleLinks = []RoleLink{
+	{Role: "manager", Parent: "staff", Domain: DefaultDomain},
+	{Role: "finance", Parent: "staff", Domain: DefaultDomain},
+	{Role: "admin", Parent: "finance", Domain: DefaultDomain},
+	{Role: "hr", Parent: "manager", Domain: DefaultDomain},
+	{Role: "admin", Parent: "hr", Domain: DefaultDomain},
+	{Role: "god-admin", Parent: "admin", Domain: DefaultDomain},
+}
@@ -0,0 +1,178 @@
+// prometheus/backend/internal/authz/service.go
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"gorm.io/gorm"
+)
+
+// ErrPolicyExists is returned when adding a policy or role link that is already present.
+var ErrPolicyExists = errors.New("policy already exists")
+
+// ErrPolicyNotFound is returned when removing a policy or role link that does not exist.
+var ErrPolicyNotFound = errors.New("policy not found")
+
+// PolicyService defines the interface for managing authorization policies.
+type PolicyService interface {
+	ListPolicies(domain string) ([]Policy, error)
+	AddPolicy(actor audit.Actor, p Policy) error
+	RemovePolicy(actor audit.Actor, p Policy) error
+	ListRoleLinks(domain string) ([]RoleLink, error)
+	AddRoleLink(actor audit.Actor, l RoleLink) error
+	RemoveRoleLink(actor audit.Actor, l RoleLink) error
+}
+
+// policyService implements the PolicyService interface on top of a Casbin enforcer.
+type policyService struct {
+	db          *gorm.DB
+	enforcer    *casbin.SyncedEnforcer
+	permissions *PermissionCache
+	auditor     audit.Service
+}
+
+// NewPolicyService creates a new instance of PolicyService. Audit records are written through db.
+func NewPolicyService(db *gorm.DB, enforcer *casbin.SyncedEnforcer, permissions *PermissionCache, auditor audit.Service) PolicyService {
+	return &policyService{db: db, enforcer: enforcer, permissions: permissions, auditor: auditor}
+}
+
+// ListPolicies returns all policies of a domain.
+func (s *policyService) ListPolicies(domain string) ([]Policy, error) {
+	rules, err := s.enforcer.GetFilteredPolicy(1, domainOrDefault(domain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	policies := make([]Policy, 0, len(rules))
+	for _, r := range rules {
+		if p, ok := PolicyFromRule(r); ok {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// AddPolicy stores a new policy.
+func (s *policyService) AddPolicy(actor audit.Actor, p Policy) error {
+	p.Domain = domainOrDefault(p.Domain)
+	p.Effect = effectOrDefault(p.Effect)
+	return s.change(actor, audit.Entry{Action: "policy.create", EntityType: "policy", EntityID: policyID(p), After: p}, func() error {
+		added, err := s.enforcer.AddPolicy(p.Subject, p.Domain, p.Object, p.Action, p.Effect)
+		if err != nil {
+			return fmt.Errorf("failed to add policy: %w", err)
+		}
+		if !added {
+			return ErrPolicyExists
+		}
+		return nil
+	})
+}
+
+// RemovePolicy deletes a policy.
+func (s *policyService) RemovePolicy(actor audit.Actor, p Policy) error {
+	p.Domain = domainOrDefault(p.Domain)
+	p.Effect = effectOrDefault(p.Effect)
+	return s.change(actor, audit.Entry{Action: "policy.delete", EntityType: "policy", EntityID: policyID(p), Before: p}, func() error {
+		removed, err := s.enforcer.RemovePolicy(p.Subject, p.Domain, p.Object, p.Action, p.Effect)
+		if err != nil {
+			return fmt.Errorf("failed to remove policy: %w", err)
+		}
+		if !removed {
+			return ErrPolicyNotFound
+		}
+		return nil
+	})
+}
+
+// ListRoleLinks returns all role inheritance links of a domain.
+func (s *policyService) ListRoleLinks(domain string) ([]RoleLink, error) {
+	rules, err := s.enforcer.GetFilteredGroupingPolicy(2, domainOrDefault(domain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role links: %w", err)
+	}
+	links := make([]RoleLink, 0, len(rules))
+	for _, r := range rules {
+		if len(r) < 3 {
+			continue
+		}
+		links = append(links, RoleLink{Role: r[0], Parent: r[1], Domain: r[2]})
+	}
+	return links, nil
+}
+
+// AddRoleLink makes a role inherit another role's permissions.
+func (s *policyService) AddRoleLink(actor audit.Actor, l RoleLink) error {
+	l.Domain = domainOrDefault(l.Domain)
+	return s.change(actor, audit.Entry{Action: "role_link.create", EntityType: "role_link", EntityID: roleLinkID(l), After: l}, func() error {
+		added, err := s.enforcer.AddGroupingPolicy(l.Role, l.Parent, l.Domain)
+		if err != nil {
+			return fmt.Errorf("failed to add role link: %w", err)
+		}
+		if !added {
+			return ErrPolicyExists
+		}
+		return nil
+	})
+}
+
+// RemoveRoleLink deletes a role inheritance link.
+func (s *policyService) RemoveRoleLink(actor audit.Actor, l RoleLink) error {
+	l.Domain = domainOrDefault(l.Domain)
+	return s.change(actor, audit.Entry{Action: "role_link.delete", EntityType: "role_link", EntityID: roleLinkID(l), Before: l}, func() error {
+		removed, err := s.enforcer.RemoveGroupingPolicy(l.Role, l.Parent, l.Domain)
+		if err != nil {
+			return fmt.Errorf("failed to remove role link: %w", err)
+		}
+		if !removed {
+			return ErrPolicyNotFound
+		}
+		return nil
+	})
+}
+
+// change audits a policy change and applies it with apply. Casbin writes through its own connection, so
+// apply runs inside the transaction of the audit record: the change is only made once its record is
+// written, and a failed change rolls the record back.
+func (s *policyService) change(actor audit.Actor, entry audit.Entry, apply func() error) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.auditor.RecordTx(tx, actor, entry); err != nil {
+			return err
+		}
+		return apply()
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidatePermissions()
+	return nil
+}
+
+// policyID identifies a policy in the audit trail.
+func policyID(p Policy) string {
+	return strings.Join([]string{p.Subject, p.Domain, p.Object, p.Action, p.Effect}, "|")
+}
+
+// roleLinkID identifies a role link in the audit trail.
+func roleLinkID(l RoleLink) string {
+	return strings.Join([]string{l.Role, l.Parent, l.Domain}, "|")
+}
+
+// invalidatePermissions drops cached permission sets after a policy change.
+func (s *policyService) invalidatePermissions() {
+	if err := s.permissions.InvalidateAll(context.Background()); err != nil {
+		log.Printf("Warning: failed to invalidate permission cache: %v", err)
+	}
+}
+
+// domainOrDefault falls back to DefaultDomain when no domain is given.
+func domainOrDefault(domain string) string {
+	if domain == "" {
+		return DefaultDomain
+	}
+	return domain
+}
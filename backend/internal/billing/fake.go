@@ -0,0 +1,98 @@
+// prometheus/backend/internal/billing/fake.go
+package billing
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"prometheus/backend/internal/devtools"
+	"prometheus/backend/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakePayments stands in for Stripe in the fake integration mode. Its checkout URL points back at this
+// API; opening it completes the payment as Stripe's checkout.session.completed webhook would.
+type fakePayments struct {
+	inbox      *devtools.Inbox
+	apiBaseURL string
+	secret     string // Signs the webhooks of Complete, so the public webhook endpoint still can't be spoofed
+
+	mu       sync.Mutex
+	sessions map[string]url.Values // Open checkout sessions by ID
+}
+
+func newFakePayments(inbox *devtools.Inbox, apiBaseURL string) *fakePayments {
+	return &fakePayments{inbox: inbox, apiBaseURL: apiBaseURL, secret: uuid.NewString(), sessions: make(map[string]url.Values)}
+}
+
+func (f *fakePayments) createCheckoutSession(params url.Values) (*CheckoutSession, error) {
+	session := &CheckoutSession{ID: "cs_fake_" + uuid.NewString()}
+	session.URL = fmt.Sprintf("%s/api/v1/devtools/payments/%s/complete", f.apiBaseURL, session.ID)
+	f.mu.Lock()
+	f.sessions[session.ID] = params
+	f.mu.Unlock()
+	f.inbox.Record(devtools.ChannelPayments, fmt.Sprintf("Checkout %s for plan %s", session.ID, params.Get("metadata[plan]")), map[string]interface{}{
+		"session_id":      session.ID,
+		"organization_id": params.Get("client_reference_id"),
+		"plan":            params.Get("metadata[plan]"),
+		"checkout_url":    session.URL,
+	})
+	return session, nil
+}
+
+// verifySignature only accepts the webhooks of Complete.
+func (f *fakePayments) verifySignature(payload []byte, header string, now time.Time) error {
+	if subtle.ConstantTimeCompare([]byte(header), []byte(f.secret)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Complete pays a fake checkout session through the regular webhook path and redirects to its success URL.
+// @Summary Complete a fake checkout
+// @Description Only available with DEV_INTEGRATIONS=fake. Applies the plan as a paid Stripe checkout would.
+// @Tags Devtools
+// @Param id path string true "Checkout session ID"
+// @Success 303 "Redirect to the checkout's success URL"
+// @Failure 404 {object} utils.ErrorResponse "Unknown or already completed session"
+// @Router /devtools/payments/{id}/complete [get]
+func (f *fakePayments) Complete(service Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		f.mu.Lock()
+		params, ok := f.sessions[id]
+		delete(f.sessions, id)
+		f.mu.Unlock()
+		if !ok {
+			utils.SendErrorResponse(c, http.StatusNotFound, "Unknown or already completed checkout session")
+			return
+		}
+
+		event := stripeEvent{ID: "evt_fake_" + uuid.NewString(), Type: "checkout.session.completed"}
+		event.Data.Object = stripeObject{
+			ID:                id,
+			Customer:          "cus_fake_" + params.Get("client_reference_id"),
+			Subscription:      "sub_fake_" + uuid.NewString(),
+			ClientReferenceID: params.Get("client_reference_id"),
+			Metadata:          map[string]string{"organization_id": params.Get("metadata[organization_id]"), "plan": params.Get("metadata[plan]")},
+		}
+		payload, err := json.Marshal(event)
+		if err == nil {
+			err = service.HandleWebhook(payload, f.secret)
+		}
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		f.inbox.Record(devtools.ChannelPayments, fmt.Sprintf("Checkout %s paid", id), map[string]interface{}{
+			"session_id": id, "subscription_id": event.Data.Object.Subscription, "plan": params.Get("metadata[plan]"),
+		})
+		c.Redirect(http.StatusSeeOther, params.Get("success_url"))
+	}
+}
@@ -0,0 +1,109 @@
+// prometheus/backend/internal/billing/handler.go
+package billing
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxWebhookBytes caps webhook payloads; Stripe events are far smaller.
+const maxWebhookBytes = 1 << 20
+
+// Handler handles HTTP requests for billing.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new billing Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Checkout starts a Stripe Checkout session for the caller's organization.
+// @Summary Start subscription checkout
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param checkout body CheckoutRequest true "Plan to subscribe to"
+// @Success 201 {object} CheckoutSession
+// @Failure 400 {object} utils.ErrorResponse "Not a tenant organization or plan not purchasable"
+// @Failure 503 {object} utils.ErrorResponse "Billing not configured"
+// @Router /admin/billing/checkout [post]
+func (h *Handler) Checkout(c *gin.Context) {
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	session, err := h.service.CreateCheckout(audit.ActorFromContext(c), c.GetUint("orgID"), req.Plan)
+	if err != nil {
+		sendBillingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Checkout session created", session)
+}
+
+// GetSubscription returns the caller's organization subscription.
+// @Summary Get subscription
+// @Tags Billing
+// @Produce json
+// @Success 200 {object} Subscription
+// @Failure 404 {object} utils.ErrorResponse "No subscription"
+// @Router /admin/billing/subscription [get]
+func (h *Handler) GetSubscription(c *gin.Context) {
+	sub, err := h.service.Subscription(c.GetUint("orgID"))
+	if err != nil {
+		sendBillingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Subscription fetched successfully", sub)
+}
+
+// Webhook receives Stripe events. It is public; authenticity comes from the Stripe-Signature header.
+// @Summary Stripe webhook
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid signature or payload"
+// @Router /billing/webhook [post]
+func (h *Handler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Failed to read webhook payload")
+		return
+	}
+	if err := h.service.HandleWebhook(payload, c.GetHeader("Stripe-Signature")); err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Any other failure is answered with 5xx so Stripe retries the delivery.
+		log.Printf("Billing: failed to process Stripe webhook: %v", err)
+		sendBillingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Webhook processed", nil)
+}
+
+// sendBillingError maps service errors to HTTP status codes.
+func sendBillingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrBillingDisabled):
+		utils.SendErrorResponse(c, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, ErrNoOrganization), errors.Is(err, plan.ErrUnknownPlan):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "No subscription found")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,80 @@
+// prometheus/backend/internal/billing/model.go
+package billing
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionStatus mirrors Stripe's subscription status.
+type SubscriptionStatus string
+
+const (
+	StatusIncomplete SubscriptionStatus = "incomplete"
+	StatusTrialing   SubscriptionStatus = "trialing"
+	StatusActive     SubscriptionStatus = "active"
+	StatusPastDue    SubscriptionStatus = "past_due"
+	StatusUnpaid     SubscriptionStatus = "unpaid"
+	StatusCanceled   SubscriptionStatus = "canceled"
+)
+
+// InGoodStanding reports whether paid modules stay available. past_due keeps access while Stripe
+// retries the payment; unpaid and canceled fall back to the free plan's modules.
+func (s SubscriptionStatus) InGoodStanding() bool {
+	switch s {
+	case StatusTrialing, StatusActive, StatusPastDue:
+		return true
+	}
+	return false
+}
+
+// Subscription links an organization to its Stripe customer and subscription.
+// Organizations without a Subscription are billed outside Stripe (e.g. invoiced enterprise tenants).
+type Subscription struct {
+	gorm.Model
+	OrganizationID       uint               `gorm:"uniqueIndex;not null" json:"organization_id"`
+	StripeCustomerID     string             `gorm:"type:varchar(255);index" json:"stripe_customer_id,omitempty"`
+	StripeSubscriptionID string             `gorm:"type:varchar(255);index" json:"stripe_subscription_id,omitempty"`
+	Plan                 string             `gorm:"type:varchar(30);not null" json:"plan" example:"standard"`
+	Status               SubscriptionStatus `gorm:"type:varchar(30);not null" json:"status" example:"active"`
+	CurrentPeriodEnd     *time.Time         `json:"current_period_end,omitempty"`
+}
+
+// WebhookEvent records processed Stripe events so redelivered events are ignored.
+type WebhookEvent struct {
+	ID         string    `gorm:"type:varchar(255);primaryKey" json:"id"`
+	Type       string    `gorm:"type:varchar(100);not null" json:"type"`
+	ReceivedAt time.Time `gorm:"not null" json:"received_at"`
+}
+
+// CheckoutRequest starts a Stripe Checkout session for a paid plan.
+type CheckoutRequest struct {
+	Plan string `json:"plan" binding:"required,oneof=standard enterprise" example:"standard"`
+}
+
+// CheckoutSession is returned to the client, which redirects the browser to URL.
+type CheckoutSession struct {
+	ID  string `json:"id" example:"cs_test_a1b2c3"`
+	URL string `json:"url" example:"https://checkout.stripe.com/c/pay/cs_test_a1b2c3"`
+}
+
+// stripeEvent is the subset of a Stripe event envelope we use.
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object stripeObject `json:"object"`
+	} `json:"data"`
+}
+
+// stripeObject covers the fields we read from checkout sessions, subscriptions and invoices.
+type stripeObject struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Metadata          map[string]string `json:"metadata"`
+}
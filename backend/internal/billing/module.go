@@ -0,0 +1,58 @@
+// prometheus/backend/internal/billing/module.go
+package billing
+
+import (
+	"context"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the billing module. MODULES_DISABLED=billing turns Stripe billing off entirely,
+// e.g. for on-premise deployments; tenants are then limited by their plan alone.
+const ModuleName = "billing"
+
+// billingModule owns the subscription tables and the billing endpoints.
+type billingModule struct {
+	handler    *Handler
+	configured bool
+	fake       *fakePayments // Set in the fake integration mode
+}
+
+// NewModule creates the billing module for the module registry.
+func NewModule(svc Service) module.Module {
+	m := &billingModule{handler: NewHandler(svc)}
+	if s, ok := svc.(*service); ok {
+		m.configured = s.payments != nil
+		m.fake, _ = s.payments.(*fakePayments)
+	}
+	return m
+}
+
+func (m *billingModule) Name() string { return ModuleName }
+
+func (m *billingModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("stripe", func(ctx context.Context) module.HealthResult {
+			// Running without Stripe keys is a valid setup (checkout answers 503), not an outage.
+			return module.HealthResult{Status: module.StatusUp, Details: map[string]any{"configured": m.configured}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *billingModule) Models() []any {
+	return []any{&Subscription{}, &WebhookEvent{}}
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *billingModule) RegisterRoutes(api *routing.Group) {
+	// Public, verified by Stripe-Signature
+	api.POST("/billing/webhook", routing.Public(), m.handler.Webhook)
+	// Billing of the caller's organization
+	api.POST("/admin/billing/checkout", routing.Policy(), m.handler.Checkout)
+	api.GET("/admin/billing/subscription", routing.Policy(), m.handler.GetSubscription)
+	if m.fake != nil {
+		// Target of the fake checkout URLs; public like the webhook it stands in for
+		api.GET("/devtools/payments/:id/complete", routing.Public(), m.fake.Complete(m.handler.service))
+	}
+}
@@ -0,0 +1,272 @@
+// prometheus/backend/internal/billing/service.go
+package billing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"prometheus/backend/config"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/devtools"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/plan"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBillingDisabled is returned when Stripe is not configured.
+var ErrBillingDisabled = errors.New("billing is not configured")
+
+// ErrNoOrganization is returned when the caller does not belong to a tenant organization.
+var ErrNoOrganization = errors.New("billing only applies to tenant organizations")
+
+// Service defines the interface for subscription billing.
+// It also implements plan.ModuleChecker, layering subscription state on top of plan limits.
+type Service interface {
+	CreateCheckout(actor audit.Actor, orgID uint, planName string) (*CheckoutSession, error)
+	HandleWebhook(payload []byte, signature string) error
+	Subscription(orgID uint) (*Subscription, error)
+	CheckModule(orgID uint, module string) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db         *gorm.DB
+	payments   paymentProvider
+	plans      plan.Service
+	auditor    audit.Service
+	prices     map[string]string // plan name -> Stripe price ID
+	successURL string
+	cancelURL  string
+	links      *organization.Links
+}
+
+// NewService creates a new billing Service. Checkout redirect URLs are moved to the tenant's own host via links.
+// inbox is only set in the fake integration mode; payments are then simulated and recorded in it.
+func NewService(db *gorm.DB, cfg *config.Config, plans plan.Service, auditor audit.Service, links *organization.Links, inbox *devtools.Inbox) Service {
+	s := &service{
+		db:      db,
+		plans:   plans,
+		auditor: auditor,
+		prices: map[string]string{
+			plan.PlanStandard:   cfg.StripePriceStandard,
+			plan.PlanEnterprise: cfg.StripePriceEnterprise,
+		},
+		successURL: cfg.BillingSuccessURL,
+		cancelURL:  cfg.BillingCancelURL,
+		links:      links,
+	}
+	switch {
+	case inbox != nil:
+		s.payments = newFakePayments(inbox, cfg.APIBaseURL)
+	case cfg.StripeSecretKey != "":
+		s.payments = newStripeClient(cfg.StripeSecretKey, cfg.StripeWebhookSecret)
+	default:
+		log.Println("Billing: STRIPE_SECRET_KEY not set, Stripe integration disabled.")
+	}
+	return s
+}
+
+// CreateCheckout starts a Stripe Checkout session for the organization to subscribe to planName.
+// The organization ID travels in client_reference_id and metadata so the webhook can link it back.
+func (s *service) CreateCheckout(actor audit.Actor, orgID uint, planName string) (*CheckoutSession, error) {
+	if s.payments == nil {
+		return nil, ErrBillingDisabled
+	}
+	if orgID == 0 {
+		return nil, ErrNoOrganization
+	}
+	price := s.prices[planName]
+	if price == "" {
+		return nil, fmt.Errorf("%w: no Stripe price configured for plan %s", plan.ErrUnknownPlan, planName)
+	}
+
+	var org organization.Organization
+	if err := s.db.First(&org, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoOrganization
+		}
+		return nil, fmt.Errorf("failed to load organization %d: %w", orgID, err)
+	}
+
+	orgRef := strconv.FormatUint(uint64(orgID), 10)
+	params := url.Values{}
+	params.Set("mode", "subscription")
+	params.Set("line_items[0][price]", price)
+	params.Set("line_items[0][quantity]", "1")
+	// Send white-labelled tenants back to their own domain.
+	params.Set("success_url", s.links.Rewrite(s.successURL, &org))
+	params.Set("cancel_url", s.links.Rewrite(s.cancelURL, &org))
+	params.Set("client_reference_id", orgRef)
+	params.Set("metadata[organization_id]", orgRef)
+	params.Set("metadata[plan]", planName)
+	params.Set("subscription_data[metadata][organization_id]", orgRef)
+	params.Set("subscription_data[metadata][plan]", planName)
+	// Reuse the Stripe customer for returning tenants so their payment history stays in one place.
+	if existing, err := s.Subscription(orgID); err == nil && existing.StripeCustomerID != "" {
+		params.Set("customer", existing.StripeCustomerID)
+	}
+
+	// Created inside the audit transaction: a session that can't be audited isn't handed out, and simply
+	// expires at Stripe.
+	var session *CheckoutSession
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if session, err = s.payments.createCheckoutSession(params); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "billing.checkout", EntityType: "organization", EntityID: orgRef,
+			After: map[string]string{"plan": planName, "session_id": session.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// HandleWebhook verifies and applies a Stripe event. Events are processed at most once.
+func (s *service) HandleWebhook(payload []byte, signature string) error {
+	if s.payments == nil {
+		return ErrBillingDisabled
+	}
+	if err := s.payments.verifySignature(payload, signature, time.Now()); err != nil {
+		return err
+	}
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Stripe delivers at-least-once; the primary key on the event ID makes redelivery a no-op.
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&WebhookEvent{ID: event.ID, Type: event.Type, ReceivedAt: time.Now().UTC()})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record webhook event: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			log.Printf("Billing: ignoring duplicate Stripe event %s (%s)", event.ID, event.Type)
+			return nil
+		}
+		return s.apply(tx, event)
+	})
+}
+
+// apply updates the subscription for the event types we care about; others are acknowledged and ignored.
+func (s *service) apply(tx *gorm.DB, event stripeEvent) error {
+	obj := event.Data.Object
+	switch event.Type {
+	case "checkout.session.completed":
+		orgID, err := parseOrgID(obj.ClientReferenceID, obj.Metadata)
+		if err != nil {
+			return err
+		}
+		sub := Subscription{
+			OrganizationID:       orgID,
+			StripeCustomerID:     obj.Customer,
+			StripeSubscriptionID: obj.Subscription,
+			Plan:                 obj.Metadata["plan"],
+			Status:               StatusActive,
+		}
+		if err := upsertSubscription(tx, &sub); err != nil {
+			return err
+		}
+		// Switch the organization's plan only once the payment went through.
+		if _, err := s.plans.ChangePlan(audit.SystemActor, orgID, sub.Plan); err != nil {
+			return fmt.Errorf("failed to apply plan %s to organization %d: %w", sub.Plan, orgID, err)
+		}
+		return nil
+
+	case "customer.subscription.updated", "customer.subscription.deleted":
+		updates := map[string]interface{}{"status": SubscriptionStatus(obj.Status)}
+		if obj.CurrentPeriodEnd > 0 {
+			updates["current_period_end"] = time.Unix(obj.CurrentPeriodEnd, 0).UTC()
+		}
+		return s.updateBySubscriptionID(tx, obj.ID, updates)
+
+	case "invoice.paid":
+		return s.updateBySubscriptionID(tx, obj.Subscription, map[string]interface{}{"status": StatusActive})
+
+	case "invoice.payment_failed":
+		return s.updateBySubscriptionID(tx, obj.Subscription, map[string]interface{}{"status": StatusPastDue})
+	}
+	return nil
+}
+
+func (s *service) updateBySubscriptionID(tx *gorm.DB, stripeSubscriptionID string, updates map[string]interface{}) error {
+	if stripeSubscriptionID == "" {
+		return nil
+	}
+	result := tx.Model(&Subscription{}).Where("stripe_subscription_id = ?", stripeSubscriptionID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Possible when events arrive before checkout.session.completed; Stripe retries failed deliveries,
+		// so returning an error lets the later attempt succeed once the subscription exists.
+		return fmt.Errorf("unknown stripe subscription %s", stripeSubscriptionID)
+	}
+	return nil
+}
+
+// Subscription returns the organization's subscription.
+func (s *service) Subscription(orgID uint) (*Subscription, error) {
+	if orgID == 0 {
+		return nil, ErrNoOrganization
+	}
+	var sub Subscription
+	if err := s.db.Where("organization_id = ?", orgID).First(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// CheckModule applies the plan's module list and, for Stripe-billed organizations, restricts access
+// to the free plan's modules while the subscription is not in good standing.
+func (s *service) CheckModule(orgID uint, module string) error {
+	if err := s.plans.CheckModule(orgID, module); err != nil || orgID == 0 {
+		return err
+	}
+	sub, err := s.Subscription(orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // Not billed through Stripe
+	}
+	if err != nil {
+		return err
+	}
+	if sub.Status.InGoodStanding() || slices.Contains(plan.Plans[plan.PlanFree].Modules, module) {
+		return nil
+	}
+	return &plan.LimitError{
+		Code: plan.CodePaymentRequired, Limit: "module:" + module, Plan: sub.Plan,
+		Message: fmt.Sprintf("The %s module is unavailable because the subscription is %s", module, sub.Status),
+	}
+}
+
+// upsertSubscription creates or updates the organization's subscription row.
+func upsertSubscription(tx *gorm.DB, sub *Subscription) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stripe_customer_id", "stripe_subscription_id", "plan", "status", "updated_at"}),
+	}).Create(sub).Error
+}
+
+// parseOrgID reads the organization ID we attached to the checkout session.
+func parseOrgID(reference string, metadata map[string]string) (uint, error) {
+	if reference == "" {
+		reference = metadata["organization_id"]
+	}
+	id, err := strconv.ParseUint(reference, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("checkout session has no organization reference")
+	}
+	return uint(id), nil
+}
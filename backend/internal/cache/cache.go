@@ -0,0 +1,48 @@
+// prometheus/backend/internal/cache/cache.go
+package cache
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/config"
+	"time"
+)
+
+// Well-known cache namespaces. Keeping them in one place makes targeted invalidation possible.
+const (
+	NamespacePermissions = "permissions"
+	NamespaceSettings    = "settings"
+	NamespaceAnalytics   = "analytics"
+	NamespaceTenantHosts = "tenant-hosts"
+	NamespaceUserStatus  = "user-status"
+)
+
+// KnownNamespaces lists the namespaces exposed by the admin cache endpoints.
+var KnownNamespaces = []string{NamespacePermissions, NamespaceSettings, NamespaceAnalytics, NamespaceTenantHosts, NamespaceUserStatus}
+
+// Cache is a namespaced key/value cache. Values are JSON-encoded so the in-memory and Redis
+// backends behave the same way.
+type Cache interface {
+	// Get decodes the cached value into dest. found is false on a cache miss.
+	Get(ctx context.Context, namespace, key string, dest interface{}) (found bool, err error)
+	// Set stores value under key. A ttl of 0 means no expiration.
+	Set(ctx context.Context, namespace, key string, value interface{}, ttl time.Duration) error
+	// Delete removes a single key.
+	Delete(ctx context.Context, namespace, key string) error
+	// Flush removes every key of a namespace.
+	Flush(ctx context.Context, namespace string) error
+	// Keys lists the keys currently stored in a namespace.
+	Keys(ctx context.Context, namespace string) ([]string, error)
+}
+
+// New creates the cache backend selected by CACHE_DRIVER ("memory" or "redis").
+func New(cfg *config.Config) (Cache, error) {
+	switch cfg.CacheDriver {
+	case "", "memory":
+		return NewMemoryCache(), nil
+	case "redis":
+		return NewRedisCache(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", cfg.CacheDriver)
+	}
+}
@@ -0,0 +1,140 @@
+// prometheus/backend/internal/cache/handler.go
+package cache
+
+import (
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NamespaceInfo summarizes a cache namespace.
+type NamespaceInfo struct {
+	Namespace string `json:"namespace" example:"permissions"`
+	KeyCount  int    `json:"key_count" example:"5"`
+}
+
+// NamespaceDetail lists the keys of a cache namespace.
+type NamespaceDetail struct {
+	Namespace string   `json:"namespace" example:"permissions"`
+	Keys      []string `json:"keys"`
+}
+
+// Handler handles admin HTTP requests for inspecting and flushing the cache,
+// e.g. after out-of-band database changes.
+type Handler struct {
+	cache   Cache
+	auditor audit.Service
+}
+
+// NewHandler creates a new instance of the cache Handler.
+func NewHandler(c Cache, auditor audit.Service) *Handler {
+	return &Handler{cache: c, auditor: auditor}
+}
+
+// ListNamespaces returns the known namespaces and how many keys each holds.
+// @Summary List cache namespaces
+// @Tags Cache
+// @Produce json
+// @Success 200 {array} NamespaceInfo
+// @Router /admin/cache [get]
+func (h *Handler) ListNamespaces(c *gin.Context) {
+	infos := make([]NamespaceInfo, 0, len(KnownNamespaces))
+	for _, ns := range KnownNamespaces {
+		keys, err := h.cache.Keys(c.Request.Context(), ns)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		infos = append(infos, NamespaceInfo{Namespace: ns, KeyCount: len(keys)})
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Cache namespaces fetched successfully", infos)
+}
+
+// GetNamespace lists the keys of a namespace.
+// @Summary Inspect a cache namespace
+// @Tags Cache
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Success 200 {object} NamespaceDetail
+// @Failure 404 {object} utils.ErrorResponse "Unknown namespace"
+// @Router /admin/cache/{namespace} [get]
+func (h *Handler) GetNamespace(c *gin.Context) {
+	ns, ok := h.namespace(c)
+	if !ok {
+		return
+	}
+	keys, err := h.cache.Keys(c.Request.Context(), ns)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sort.Strings(keys)
+	utils.SendSuccessResponse(c, http.StatusOK, "Cache namespace fetched successfully", NamespaceDetail{Namespace: ns, Keys: keys})
+}
+
+// FlushNamespace removes every key of a namespace.
+// @Summary Flush a cache namespace
+// @Tags Cache
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Unknown namespace"
+// @Router /admin/cache/{namespace} [delete]
+func (h *Handler) FlushNamespace(c *gin.Context) {
+	ns, ok := h.namespace(c)
+	if !ok {
+		return
+	}
+	if err := h.cache.Flush(c.Request.Context(), ns); err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.record(c, "cache.flush", ns)
+	utils.SendSuccessResponse(c, http.StatusOK, "Cache namespace flushed successfully", nil)
+}
+
+// DeleteKey removes a single key from a namespace.
+// @Summary Invalidate a cache key
+// @Tags Cache
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param key path string true "Key"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Unknown namespace"
+// @Router /admin/cache/{namespace}/{key} [delete]
+func (h *Handler) DeleteKey(c *gin.Context) {
+	ns, ok := h.namespace(c)
+	if !ok {
+		return
+	}
+	key := c.Param("key")
+	if err := h.cache.Delete(c.Request.Context(), ns, key); err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.record(c, "cache.invalidate", ns+"/"+key)
+	utils.SendSuccessResponse(c, http.StatusOK, "Cache key invalidated successfully", nil)
+}
+
+// namespace validates the :namespace path parameter against KnownNamespaces.
+func (h *Handler) namespace(c *gin.Context) (string, bool) {
+	ns := c.Param("namespace")
+	if !slices.Contains(KnownNamespaces, ns) {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Unknown cache namespace: "+ns)
+		return "", false
+	}
+	return ns, true
+}
+
+// record audits who flushed what. The flush already happened, so failures are only logged.
+func (h *Handler) record(c *gin.Context, action, entityID string) {
+	entry := audit.Entry{Action: action, EntityType: "cache", EntityID: entityID}
+	if err := h.auditor.Record(audit.ActorFromContext(c), entry); err != nil {
+		log.Printf("Warning: failed to audit %s of %s: %v", action, entityID, err)
+	}
+}
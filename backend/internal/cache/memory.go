@@ -0,0 +1,90 @@
+// prometheus/backend/internal/cache/memory.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time // zero means no expiration
+}
+
+// memoryCache is a process-local Cache. Suitable for single-instance deployments and development.
+type memoryCache struct {
+	mu    sync.RWMutex
+	items map[string]map[string]memoryEntry
+}
+
+// NewMemoryCache creates a new in-memory Cache.
+func NewMemoryCache() Cache {
+	return &memoryCache{items: make(map[string]map[string]memoryEntry)}
+}
+
+func (m *memoryCache) Get(ctx context.Context, namespace, key string, dest interface{}) (bool, error) {
+	m.mu.RLock()
+	entry, ok := m.items[namespace][key]
+	m.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		_ = m.Delete(ctx, namespace, key)
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.data, dest); err != nil {
+		return false, fmt.Errorf("failed to decode cached value %s/%s: %w", namespace, key, err)
+	}
+	return true, nil
+}
+
+func (m *memoryCache) Set(ctx context.Context, namespace, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s/%s: %w", namespace, key, err)
+	}
+	entry := memoryEntry{data: data}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.items[namespace] == nil {
+		m.items[namespace] = make(map[string]memoryEntry)
+	}
+	m.items[namespace][key] = entry
+	return nil
+}
+
+func (m *memoryCache) Delete(ctx context.Context, namespace, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items[namespace], key)
+	return nil
+}
+
+func (m *memoryCache) Flush(ctx context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, namespace)
+	return nil
+}
+
+func (m *memoryCache) Keys(ctx context.Context, namespace string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	keys := make([]string, 0, len(m.items[namespace]))
+	for k, entry := range m.items[namespace] {
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
@@ -0,0 +1,36 @@
+// prometheus/backend/internal/cache/module.go
+package cache
+
+import (
+	"context"
+	"prometheus/backend/internal/module"
+	"time"
+)
+
+// cacheModule reports whether the cache backend accepts reads and writes.
+type cacheModule struct {
+	cache Cache
+}
+
+// NewModule creates the cache module for the module registry.
+func NewModule(c Cache) module.Module {
+	return &cacheModule{cache: c}
+}
+
+func (m *cacheModule) Name() string { return "cache" }
+
+func (m *cacheModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("roundtrip", func(ctx context.Context) module.HealthResult {
+			// The app works without a cache (just slower), so failures only degrade readiness.
+			if err := m.cache.Set(ctx, "health", "ping", time.Now().Unix(), time.Minute); err != nil {
+				return module.HealthResult{Status: module.StatusDegraded, Error: err.Error()}
+			}
+			var v int64
+			if _, err := m.cache.Get(ctx, "health", "ping", &v); err != nil {
+				return module.HealthResult{Status: module.StatusDegraded, Error: err.Error()}
+			}
+			return module.HealthResult{Status: module.StatusUp}
+		}),
+	}
+}
@@ -0,0 +1,102 @@
+// prometheus/backend/internal/cache/redis.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces all cache keys so the Redis instance can be shared with other services.
+const keyPrefix = "prometheus:cache:"
+
+// redisCache is a Cache shared by all backend replicas, so an invalidation on one replica
+// is seen by every other replica.
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new Redis-backed Cache and verifies the connection.
+func NewRedisCache(addr, password string, db int) (Cache, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: password, DB: db})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", addr, err)
+	}
+	return &redisCache{client: client}, nil
+}
+
+func (r *redisCache) key(namespace, key string) string {
+	return keyPrefix + namespace + ":" + key
+}
+
+func (r *redisCache) Get(ctx context.Context, namespace, key string, dest interface{}) (bool, error) {
+	data, err := r.client.Get(ctx, r.key(namespace, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read cache key %s/%s: %w", namespace, key, err)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, fmt.Errorf("failed to decode cached value %s/%s: %w", namespace, key, err)
+	}
+	return true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, namespace, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s/%s: %w", namespace, key, err)
+	}
+	if err := r.client.Set(ctx, r.key(namespace, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache key %s/%s: %w", namespace, key, err)
+	}
+	return nil
+}
+
+func (r *redisCache) Delete(ctx context.Context, namespace, key string) error {
+	return r.client.Del(ctx, r.key(namespace, key)).Err()
+}
+
+func (r *redisCache) Flush(ctx context.Context, namespace string) error {
+	keys, err := r.scan(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *redisCache) Keys(ctx context.Context, namespace string) ([]string, error) {
+	keys, err := r.scan(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.key(namespace, "")
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, prefix)
+	}
+	return keys, nil
+}
+
+// scan returns the full Redis keys of a namespace. SCAN is used instead of KEYS to avoid blocking Redis.
+func (r *redisCache) scan(ctx context.Context, namespace string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, r.key(namespace, "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan cache namespace %s: %w", namespace, err)
+	}
+	return keys, nil
+}
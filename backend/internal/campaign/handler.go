@@ -0,0 +1,307 @@
+// prometheus/backend/internal/campaign/handler.go
+package campaign
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for HR notification campaigns.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's campaigns, newest first.
+// @Summary List notification campaigns
+// @Tags Campaigns
+// @Produce json
+// @Param status query string false "Status" Enums(draft, scheduled, sending, sent, cancelled)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Router /hr/campaigns [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusDraft, StatusScheduled, StatusSending, StatusSent, StatusCancelled:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	page := utils.ParsePagination(c)
+	campaigns, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaigns fetched successfully", page.Response(campaigns, total))
+}
+
+// Get returns a campaign. The ETag and Last-Modified headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get a notification campaign
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} Campaign
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Router /hr/campaigns/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	campaign, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, campaign.UpdatedAt, campaign.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign fetched successfully", campaign)
+}
+
+// Create saves a campaign as a draft.
+// @Summary Create a notification campaign
+// @Description Composes a one-off message to a filtered audience: in the app, and by email if requested.
+// @Description Nothing is sent until the campaign is scheduled.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param campaign body Request true "Campaign"
+// @Success 201 {object} Campaign
+// @Failure 400 {object} utils.ErrorResponse "Invalid campaign or unknown division, role or user in the audience"
+// @Router /hr/campaigns [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	campaign, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, campaign.UpdatedAt, campaign.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Campaign created successfully", campaign)
+}
+
+// Update replaces the content and audience of a campaign that isn't being sent yet.
+// @Summary Update a notification campaign
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param campaign body Request true "Campaign"
+// @Success 200 {object} Campaign
+// @Failure 400 {object} utils.ErrorResponse "Invalid campaign or unknown division, role or user in the audience"
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Failure 409 {object} utils.ErrorResponse "Campaign already being sent, sent or cancelled"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/campaigns/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	campaign, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, campaign.UpdatedAt, campaign.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign updated successfully", campaign)
+}
+
+// Delete removes a campaign that isn't being sent yet.
+// @Summary Delete a notification campaign
+// @Tags Campaigns
+// @Param id path int true "Campaign ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Failure 409 {object} utils.ErrorResponse "Campaign already being sent, sent or cancelled"
+// @Router /hr/campaigns/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Preview shows the audience and email of a composed campaign without saving it.
+// @Summary Preview a notification campaign
+// @Description Counts the recipients the audience currently resolves to, lists the first of them, and
+// @Description renders the email as the first recipient would receive it.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param campaign body Request true "Campaign"
+// @Success 200 {object} Preview
+// @Failure 400 {object} utils.ErrorResponse "Invalid campaign or unknown division, role or user in the audience"
+// @Router /hr/campaigns/preview [post]
+func (h *Handler) Preview(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	preview, err := h.service.Preview(utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign preview rendered successfully", preview)
+}
+
+// Schedule sends a campaign at a given time, or right away.
+// @Summary Schedule a notification campaign
+// @Description Recipients are notified in batches by a recurring job from the scheduled time on; the
+// @Description audience is resolved as the batches go out.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param schedule body ScheduleRequest false "When to send"
+// @Success 200 {object} Campaign
+// @Failure 400 {object} utils.ErrorResponse "Invalid request"
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Failure 409 {object} utils.ErrorResponse "Campaign already being sent, sent or cancelled"
+// @Router /hr/campaigns/{id}/schedule [post]
+func (h *Handler) Schedule(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ScheduleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	campaign, err := h.service.Schedule(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req.At)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, campaign.UpdatedAt, campaign.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign scheduled successfully", campaign)
+}
+
+// Cancel stops a campaign that wasn't fully sent. Recipients notified already keep their notification.
+// @Summary Cancel a notification campaign
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} Campaign
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Failure 409 {object} utils.ErrorResponse "Campaign already sent or cancelled"
+// @Router /hr/campaigns/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	campaign, err := h.service.Cancel(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, campaign.UpdatedAt, campaign.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign cancelled successfully", campaign)
+}
+
+// Report returns a campaign with the delivery state of its notifications.
+// @Summary Get the delivery report of a notification campaign
+// @Description Counts how many recipients saw and read the campaign in the app, and how many emails were
+// @Description delivered, opened (with email tracking), failed, skipped or are still pending.
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} Report
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Router /hr/campaigns/{id}/report [get]
+func (h *Handler) Report(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	report, err := h.service.Report(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign report fetched successfully", report)
+}
+
+// Receipts lists the delivery state of each recipient's notification.
+// @Summary List the receipts of a notification campaign
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param unread query bool false "Only recipients who haven't read it in the app yet"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 404 {object} utils.ErrorResponse "Campaign not found"
+// @Router /hr/campaigns/{id}/receipts [get]
+func (h *Handler) Receipts(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	receipts, total, err := h.service.Receipts(utils.OrganizationFromContext(c), id, c.Query("unread") == "true", page)
+	if err != nil {
+		sendCampaignError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Campaign receipts fetched successfully", page.Response(receipts, total))
+}
+
+// sendCampaignError maps service errors to HTTP status codes.
+func sendCampaignError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Campaign not found")
+	case errors.Is(err, ErrInvalidCampaign):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotEditable), errors.Is(err, ErrNotCancellable):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The campaign was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,108 @@
+// prometheus/backend/internal/campaign/model.go
+package campaign
+
+import (
+	"fmt"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Status is where a campaign is in its lifecycle.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"     // Being composed; may be edited and deleted
+	StatusScheduled Status = "scheduled" // Waiting for ScheduledAt; may still be edited or cancelled
+	StatusSending   Status = "sending"   // Notifications are being created in batches
+	StatusSent      Status = "sent"      // Every recipient was notified
+	StatusCancelled Status = "cancelled" // Stopped before or while sending; recipients notified so far keep their notification
+)
+
+// Campaign is a one-off message from HR to a filtered audience of the organization's users. It is sent as
+// a notification to each recipient, in the app and optionally by email, so delivery is reported by the
+// notification receipts of its Category.
+type Campaign struct {
+	ID               uint           `gorm:"primaryKey" json:"id" example:"3"`
+	OrganizationID   *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Subject          string         `gorm:"type:varchar(255);not null" json:"subject" example:"Office closed on Friday"`
+	Body             string         `gorm:"type:text" json:"body,omitempty"`
+	Link             string         `gorm:"type:varchar(500)" json:"link,omitempty" example:"/announcements/12"` // Frontend path
+	Email            bool           `gorm:"not null;default:false" json:"email"`                                 // Also emailed; otherwise in-app only
+	Urgent           bool           `gorm:"not null;default:false" json:"urgent"`                                // Emailed right away, bypassing digests
+	Mandatory        bool           `gorm:"not null;default:false" json:"mandatory"`                             // Emailed even if the user turned the category off
+	Audience         datatypes.JSON `gorm:"type:jsonb;not null" json:"audience" swaggertype:"object"`            // See Audience
+	PolicyDocumentID *uint          `gorm:"index" json:"policy_document_id,omitempty" example:"4"`               // Asks recipients to acknowledge this policy
+	PolicyRevisionID *uint          `json:"policy_revision_id,omitempty" example:"11"`                           // The policy's revision in effect when sending started
+	Status           Status         `gorm:"type:varchar(20);not null;index" json:"status" example:"draft"`
+	ScheduledAt      *time.Time     `gorm:"index" json:"scheduled_at,omitempty"`
+	StartedAt        *time.Time     `json:"started_at,omitempty"`
+	FinishedAt       *time.Time     `json:"finished_at,omitempty"`                            // Sent or cancelled
+	Recipients       int64          `gorm:"not null;default:0" json:"recipients" example:"0"` // Notified so far
+	Cursor           uint           `gorm:"not null;default:0" json:"-"`                      // Highest user ID notified; users are notified in ID order
+	CreatedBy        *uint          `json:"created_by,omitempty" example:"2"`
+	Version          uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// Category is the notification category of a campaign's notifications, e.g. "campaign.3".
+func (c *Campaign) Category() string {
+	return fmt.Sprintf("campaign.%d", c.ID)
+}
+
+// Audience selects the active users of the organization a campaign is sent to. Criteria of different
+// kinds must all match, any value of one kind does; an empty audience is everyone.
+type Audience struct {
+	DivisionIDs     []uint                    `json:"division_ids,omitempty" example:"2,5"`
+	Roles           []string                  `json:"roles,omitempty" example:"manager"` // Held globally
+	EmploymentTypes []employee.EmploymentType `json:"employment_types,omitempty" binding:"dive,oneof=full_time part_time contractor intern temporary" example:"full_time"`
+	UserIDs         []uint                    `json:"user_ids,omitempty" example:"12"`
+}
+
+// Request composes a campaign or replaces a draft's content.
+type Request struct {
+	Subject   string   `json:"subject" binding:"required,max=255" example:"Office closed on Friday"`
+	Body      string   `json:"body" binding:"max=10000"`
+	Link      string   `json:"link" binding:"omitempty,max=500,startswith=/" example:"/announcements/12"`
+	Email     bool     `json:"email"`
+	Urgent    bool     `json:"urgent"`
+	Mandatory bool     `json:"mandatory"`
+	Audience  Audience `json:"audience"`
+	// PolicyDocumentID makes the campaign ask for acknowledgement of a policy: of the revision in effect on
+	// the day sending starts, linked from the notification unless Link is set.
+	PolicyDocumentID *uint `json:"policy_document_id,omitempty" example:"4"`
+}
+
+// ScheduleRequest sends a campaign at a given time, or right away.
+type ScheduleRequest struct {
+	At *time.Time `json:"at,omitempty" example:"2026-11-02T08:00:00Z"` // Now if omitted or past
+}
+
+// Filter narrows a campaign listing.
+type Filter struct {
+	Status Status
+}
+
+// Recipient is a user in a campaign's audience.
+type Recipient struct {
+	UserID   uint   `json:"user_id" example:"12"`
+	Username string `json:"username" example:"jdoe"`
+}
+
+// Preview shows who a campaign would reach and what they'd receive, before it is sent.
+type Preview struct {
+	Recipients int64       `json:"recipients" example:"42"` // Current size of the audience
+	Sample     []Recipient `json:"sample"`                  // First recipients
+	Subject    string      `json:"subject"`                 // Of the email, as sent on its own
+	Body       string      `json:"body"`
+}
+
+// Report is a campaign with the delivery state of its notifications.
+type Report struct {
+	Campaign     Campaign           `json:"campaign"`
+	Delivery     notification.Stats `json:"delivery"`
+	Acknowledged *int64             `json:"acknowledged,omitempty" example:"37"` // Recipients who acknowledged the policy revision
+}
@@ -0,0 +1,87 @@
+// prometheus/backend/internal/campaign/module.go
+package campaign
+
+import (
+	"context"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the campaigns module.
+const ModuleName = "campaigns"
+
+// sendInterval is how often due campaigns are sent a batch.
+const sendInterval = time.Minute
+
+// campaignModule owns HR notification campaigns and their throttled sending.
+type campaignModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the campaigns module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &campaignModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *campaignModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. A campaign still due an hour after its scheduled time
+// isn't being picked up by the job queue.
+func (m *campaignModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("sending", func(ctx context.Context) module.HealthResult {
+			var sending, stalled int64
+			db := m.db.WithContext(ctx).Model(&Campaign{})
+			if err := db.Where("status = ?", StatusSending).Count(&sending).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := m.db.WithContext(ctx).Model(&Campaign{}).Where("status = ? AND scheduled_at < ?",
+				StatusScheduled, clock.Now().UTC().Add(-time.Hour)).Count(&stalled).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if stalled > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"sending": float64(sending), "stalled": float64(stalled)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *campaignModule) Models() []any {
+	return []any{&Campaign{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *campaignModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobSend, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		campaigns, notified, err := m.service.Send(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"campaigns": campaigns, "notified": notified}, nil
+	})
+	q.Every(JobSend, sendInterval)
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *campaignModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/hr/campaigns", routing.Policy(), m.handler.List)
+	api.POST("/hr/campaigns", routing.Policy(), m.handler.Create)
+	api.POST("/hr/campaigns/preview", routing.Policy(), m.handler.Preview)
+	api.GET("/hr/campaigns/:id", routing.Policy(), m.handler.Get)
+	api.PUT("/hr/campaigns/:id", routing.Policy(), m.handler.Update)
+	api.DELETE("/hr/campaigns/:id", routing.Policy(), m.handler.Delete)
+	api.POST("/hr/campaigns/:id/schedule", routing.Policy(), m.handler.Schedule)
+	api.POST("/hr/campaigns/:id/cancel", routing.Policy(), m.handler.Cancel)
+	api.GET("/hr/campaigns/:id/report", routing.Policy(), m.handler.Report)
+	api.GET("/hr/campaigns/:id/receipts", routing.Policy(), m.handler.Receipts)
+}
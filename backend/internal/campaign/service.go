@@ -0,0 +1,525 @@
+// prometheus/backend/internal/campaign/service.go
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/policydoc"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobSend is the recurring job type that sends due campaigns.
+const JobSend = "campaign.send"
+
+// sendBatch bounds how many recipients of a campaign one job run notifies, so large campaigns are sent
+// over several runs instead of flooding the notification dispatch and the mail server at once.
+const sendBatch = 500
+
+// sampleSize is how many recipients a preview lists.
+const sampleSize = 10
+
+var (
+	// ErrInvalidCampaign is returned for campaigns that fail validation.
+	ErrInvalidCampaign = errors.New("invalid campaign")
+	// ErrNotEditable is returned when changing a campaign that is already being sent, sent or cancelled.
+	ErrNotEditable = errors.New("the campaign can no longer be changed")
+	// ErrNotCancellable is returned when cancelling a campaign that was sent or cancelled already.
+	ErrNotCancellable = errors.New("the campaign was already sent or cancelled")
+)
+
+// Service manages HR notification campaigns and sends them.
+// orgID scopes every call to one organization's campaigns and users (nil = platform users, outside any organization).
+type Service interface {
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Campaign, int64, error)
+	Get(orgID *uint, id uint) (*Campaign, error)
+	Create(actor audit.Actor, orgID *uint, req Request) (*Campaign, error)
+	// Update replaces the content and audience of a draft or scheduled campaign.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Campaign, error)
+	// Delete removes a draft or scheduled campaign.
+	Delete(actor audit.Actor, orgID *uint, id uint) error
+	// Preview resolves the audience of a composed campaign and renders its email, without saving it.
+	Preview(orgID *uint, req Request) (*Preview, error)
+	// Schedule sends a draft or scheduled campaign at the given time (nil = right away).
+	Schedule(actor audit.Actor, orgID *uint, id uint, at *time.Time) (*Campaign, error)
+	// Cancel stops a campaign that wasn't fully sent yet.
+	Cancel(actor audit.Actor, orgID *uint, id uint) (*Campaign, error)
+	// Report returns a campaign with the delivery state of its notifications.
+	Report(orgID *uint, id uint) (*Report, error)
+	// Receipts lists the delivery state of each recipient's notification.
+	Receipts(orgID *uint, id uint, unreadOnly bool, page utils.Pagination) ([]notification.Receipt, int64, error)
+	// Send notifies the next batch of recipients of each due campaign.
+	Send(ctx context.Context) (campaigns, notified int, err error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db            *gorm.DB
+	notifications notification.Service
+	policies      policydoc.Service
+	auditor       audit.Service
+}
+
+// NewService creates a new instance of Service. Campaigns are sent, previewed and reported through
+// notifications; those asking for a policy's acknowledgement are reported through policies.
+func NewService(db *gorm.DB, notifications notification.Service, policies policydoc.Service, auditor audit.Service) Service {
+	return &service{db: db, notifications: notifications, policies: policies, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Campaign, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Campaign{}), orgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count campaigns: %w", err)
+	}
+	campaigns := []Campaign{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&campaigns).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	return campaigns, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Campaign, error) {
+	var campaign Campaign
+	if err := utils.OrgScope(s.db, orgID).First(&campaign, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &campaign, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Campaign, error) {
+	campaign := Campaign{OrganizationID: orgID, Status: StatusDraft, CreatedBy: actor.UserID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, &campaign, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&campaign).Error; err != nil {
+			return fmt.Errorf("failed to create campaign: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "campaign.create", EntityType: "campaign", EntityID: fmt.Sprintf("%d", campaign.ID), After: campaign,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// Update replaces the campaign's content if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Campaign, error) {
+	var updated Campaign
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if !before.editable() {
+			return ErrNotEditable
+		}
+		campaign := *before
+		if err := s.apply(tx, &campaign, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Campaign{}, id, expectedVersion, map[string]interface{}{
+			"subject":            campaign.Subject,
+			"body":               campaign.Body,
+			"link":               campaign.Link,
+			"email":              campaign.Email,
+			"urgent":             campaign.Urgent,
+			"mandatory":          campaign.Mandatory,
+			"audience":           campaign.Audience,
+			"policy_document_id": campaign.PolicyDocumentID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload campaign %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "campaign.update", EntityType: "campaign", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Delete(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if !before.editable() {
+			return ErrNotEditable
+		}
+		if err := tx.Delete(&Campaign{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete campaign %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "campaign.delete", EntityType: "campaign", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Preview(orgID *uint, req Request) (*Preview, error) {
+	if err := s.validate(s.db, orgID, req.Audience); err != nil {
+		return nil, err
+	}
+	query := s.recipients(s.db, orgID, req.Audience)
+	preview := &Preview{Sample: []Recipient{}}
+	if err := query.Count(&preview.Recipients).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recipients: %w", err)
+	}
+	if err := query.Select("users.id AS user_id, users.username").Order("users.id").Limit(sampleSize).
+		Scan(&preview.Sample).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recipients: %w", err)
+	}
+	username := "jdoe"
+	if len(preview.Sample) > 0 {
+		username = preview.Sample[0].Username
+	}
+	var err error
+	preview.Subject, preview.Body, err = s.notifications.Preview(orgID, username, notification.Notice{
+		Subject: strings.TrimSpace(req.Subject), Body: strings.TrimSpace(req.Body), Link: req.Link,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+func (s *service) Schedule(actor audit.Actor, orgID *uint, id uint, at *time.Time) (*Campaign, error) {
+	var updated Campaign
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if !before.editable() {
+			return ErrNotEditable
+		}
+		when := clock.Now().UTC()
+		if at != nil && at.After(when) {
+			when = at.UTC()
+		}
+		if before.PolicyDocumentID != nil {
+			if _, err := s.policies.Effective(orgID, *before.PolicyDocumentID, when); errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: the policy has no revision in effect by then", ErrInvalidCampaign)
+			} else if err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&Campaign{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": StatusScheduled, "scheduled_at": when, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to schedule campaign %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload campaign %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "campaign.schedule", EntityType: "campaign", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Cancel(actor audit.Actor, orgID *uint, id uint) (*Campaign, error) {
+	var updated Campaign
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status == StatusSent || before.Status == StatusCancelled {
+			return ErrNotCancellable
+		}
+		if err := tx.Model(&Campaign{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": StatusCancelled, "finished_at": clock.Now().UTC(), "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to cancel campaign %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload campaign %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "campaign.cancel", EntityType: "campaign", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Report(orgID *uint, id uint) (*Report, error) {
+	campaign, err := s.Get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Campaign: *campaign, Delivery: notification.Stats{Category: campaign.Category()}}
+	stats, err := s.notifications.Stats(orgID, notification.StatsFilter{Category: campaign.Category()})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) > 0 {
+		report.Delivery = stats[0]
+	}
+	if campaign.PolicyRevisionID != nil {
+		recipients := s.db.Model(&notification.Notification{}).Select("user_id").Where("category = ?", campaign.Category())
+		acknowledged, err := s.policies.Acknowledged(orgID, *campaign.PolicyRevisionID, recipients)
+		if err != nil {
+			return nil, err
+		}
+		report.Acknowledged = &acknowledged
+	}
+	return report, nil
+}
+
+func (s *service) Receipts(orgID *uint, id uint, unreadOnly bool, page utils.Pagination) ([]notification.Receipt, int64, error) {
+	campaign, err := s.Get(orgID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.notifications.Receipts(orgID, notification.StatsFilter{Category: campaign.Category(), UnreadOnly: unreadOnly}, page)
+}
+
+// Send works through due campaigns one batch each, oldest first. A failing campaign is logged and retried
+// on the next run without holding up the others.
+func (s *service) Send(ctx context.Context) (int, int, error) {
+	now := clock.Now().UTC()
+	var due []uint
+	if err := s.db.WithContext(ctx).Model(&Campaign{}).Where("status IN ? AND scheduled_at <= ?",
+		[]Status{StatusScheduled, StatusSending}, now).Order("scheduled_at, id").Pluck("id", &due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to find due campaigns: %w", err)
+	}
+	var campaigns, notified int
+	for _, id := range due {
+		if err := ctx.Err(); err != nil {
+			return campaigns, notified, err
+		}
+		n, err := s.sendBatch(ctx, id, now)
+		if err != nil {
+			log.Printf("Failed to send campaign %d: %v", id, err)
+			continue
+		}
+		campaigns++
+		notified += n
+	}
+	return campaigns, notified, nil
+}
+
+// sendBatch notifies the next recipients of a campaign after its cursor, and marks it sent once the
+// audience is exhausted. Users joining the audience while it is sent are included if they sort after the
+// cursor.
+func (s *service) sendBatch(ctx context.Context, id uint, now time.Time) (int, error) {
+	var notified int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Another instance sending at the same time, or a cancellation in progress, skips or waits on this row.
+		var campaign Campaign
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ?", []Status{StatusScheduled, StatusSending}).First(&campaign, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load campaign: %w", err)
+		}
+		var audience Audience
+		if err := json.Unmarshal(campaign.Audience, &audience); err != nil {
+			return fmt.Errorf("failed to decode audience: %w", err)
+		}
+		updates := map[string]interface{}{"status": StatusSending}
+		if campaign.PolicyDocumentID != nil && campaign.PolicyRevisionID == nil {
+			// Pinned on the first batch, so every recipient acknowledges the same revision even if a new one
+			// takes effect while the campaign is still being sent.
+			revision, err := s.policies.Effective(campaign.OrganizationID, *campaign.PolicyDocumentID, now)
+			if err != nil {
+				return fmt.Errorf("failed to find the policy revision in effect: %w", err)
+			}
+			campaign.PolicyRevisionID = &revision.ID
+			updates["policy_revision_id"] = revision.ID
+		}
+		link := campaign.Link
+		if link == "" && campaign.PolicyRevisionID != nil {
+			link = fmt.Sprintf("/policies/%d?revision=%d&campaign=%d", *campaign.PolicyDocumentID, *campaign.PolicyRevisionID, campaign.ID)
+		}
+		var recipients []uint
+		if err := s.recipients(tx, campaign.OrganizationID, audience).Where("users.id > ?", campaign.Cursor).
+			Order("users.id").Limit(sendBatch).Pluck("users.id", &recipients).Error; err != nil {
+			return fmt.Errorf("failed to find recipients: %w", err)
+		}
+		notices := make([]notification.Notice, 0, len(recipients))
+		for _, userID := range recipients {
+			notices = append(notices, notification.Notice{
+				UserID:         userID,
+				OrganizationID: campaign.OrganizationID,
+				Category:       campaign.Category(),
+				Urgent:         campaign.Urgent,
+				Mandatory:      campaign.Mandatory,
+				Subject:        campaign.Subject,
+				Body:           campaign.Body,
+				Link:           link,
+				InAppOnly:      !campaign.Email,
+			})
+		}
+		if err := notification.CreateTx(tx, notices...); err != nil {
+			return err
+		}
+		updates["recipients"] = gorm.Expr("recipients + ?", len(recipients))
+		if len(recipients) > 0 {
+			updates["cursor"] = recipients[len(recipients)-1]
+		}
+		if campaign.StartedAt == nil {
+			updates["started_at"] = now
+		}
+		if len(recipients) < sendBatch {
+			updates["status"], updates["finished_at"] = StatusSent, now
+		}
+		if err := tx.Model(&Campaign{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to record campaign progress: %w", err)
+		}
+		notified = len(recipients)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return notified, nil
+}
+
+// apply validates req and copies it onto campaign.
+func (s *service) apply(tx *gorm.DB, campaign *Campaign, req Request) error {
+	if err := s.validate(tx, campaign.OrganizationID, req.Audience); err != nil {
+		return err
+	}
+	if req.PolicyDocumentID != nil {
+		if _, err := s.policies.GetDocument(campaign.OrganizationID, *req.PolicyDocumentID, false); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: unknown policy document", ErrInvalidCampaign)
+		} else if err != nil {
+			return err
+		}
+	}
+	audience, err := json.Marshal(req.Audience)
+	if err != nil {
+		return fmt.Errorf("failed to encode audience: %w", err)
+	}
+	campaign.Subject = strings.TrimSpace(req.Subject)
+	campaign.Body = strings.TrimSpace(req.Body)
+	campaign.Link = req.Link
+	campaign.Email = req.Email
+	campaign.Urgent = req.Urgent
+	campaign.Mandatory = req.Mandatory
+	campaign.Audience = audience
+	campaign.PolicyDocumentID = req.PolicyDocumentID
+	return nil
+}
+
+// validate checks the divisions, roles and users of an audience exist within the organization, so a typo
+// doesn't silently narrow it.
+func (s *service) validate(tx *gorm.DB, orgID *uint, audience Audience) error {
+	if len(audience.DivisionIDs) > 0 {
+		// Queried by table name: divisions are soft-deleted, and only their existence matters here.
+		var divisions int64
+		query := tx.Table("divisions").Where("id IN ? AND deleted_at IS NULL", audience.DivisionIDs)
+		if err := utils.OrgScope(query, orgID).Count(&divisions).Error; err != nil {
+			return fmt.Errorf("failed to load divisions: %w", err)
+		}
+		if divisions != int64(len(unique(audience.DivisionIDs))) {
+			return fmt.Errorf("%w: unknown division in audience", ErrInvalidCampaign)
+		}
+	}
+	if len(audience.Roles) > 0 {
+		var roles int64
+		if err := tx.Model(&role.Role{}).Where("name IN ?", audience.Roles).Count(&roles).Error; err != nil {
+			return fmt.Errorf("failed to load roles: %w", err)
+		}
+		if roles != int64(len(unique(audience.Roles))) {
+			return fmt.Errorf("%w: unknown role in audience", ErrInvalidCampaign)
+		}
+	}
+	if len(audience.UserIDs) > 0 {
+		var users int64
+		if err := utils.OrgScope(tx.Model(&auth.User{}).Where("id IN ?", audience.UserIDs), orgID).Count(&users).Error; err != nil {
+			return fmt.Errorf("failed to load users: %w", err)
+		}
+		if users != int64(len(unique(audience.UserIDs))) {
+			return fmt.Errorf("%w: unknown user in audience", ErrInvalidCampaign)
+		}
+	}
+	return nil
+}
+
+// recipients selects the active users of the organization in audience.
+func (s *service) recipients(db *gorm.DB, orgID *uint, audience Audience) *gorm.DB {
+	query := db.Model(&auth.User{}).Where("users.is_active")
+	if orgID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	if len(audience.UserIDs) > 0 {
+		query = query.Where("users.id IN ?", audience.UserIDs)
+	}
+	if len(audience.Roles) > 0 {
+		query = query.Where("users.id IN (?)", db.Table("user_roles").Select("user_roles.user_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").Where("roles.name IN ?", audience.Roles))
+	}
+	if len(audience.DivisionIDs) > 0 || len(audience.EmploymentTypes) > 0 {
+		query = query.Joins("JOIN employees ON employees.user_id = users.id AND employees.deleted_at IS NULL")
+		if len(audience.DivisionIDs) > 0 {
+			query = query.Where("employees.division_id IN ?", audience.DivisionIDs)
+		}
+		if len(audience.EmploymentTypes) > 0 {
+			query = query.Where("employees.employment_type IN ?", audience.EmploymentTypes)
+		}
+	}
+	return query
+}
+
+// lock loads a campaign of the organization for update.
+func (s *service) lock(tx *gorm.DB, orgID *uint, id uint) (*Campaign, error) {
+	var campaign Campaign
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&campaign, id).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// editable reports whether the campaign hasn't started sending yet.
+func (c *Campaign) editable() bool {
+	return c.Status == StatusDraft || c.Status == StatusScheduled
+}
+
+func unique[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,243 @@
+// prometheus/backend/internal/change/handler.go
+package change
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for scheduled employee changes.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's scheduled changes, earliest effective first.
+// @Summary List scheduled changes
+// @Tags Scheduled changes
+// @Produce json
+// @Param status query string false "Status" Enums(pending, applied, failed, cancelled)
+// @Param employee_id query int false "Employee ID"
+// @Param from query string false "Effective on or after (YYYY-MM-DD)"
+// @Param to query string false "Effective on or before (YYYY-MM-DD)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/scheduled-changes [get]
+func (h *Handler) List(c *gin.Context) {
+	var ok bool
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusPending, StatusApplied, StatusFailed, StatusCancelled:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	if filter.From, ok = parseDate(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseDate(c, "to"); !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	changes, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Scheduled changes fetched successfully", page.Response(changes, total))
+}
+
+// Get returns a scheduled change. The ETag and Last-Modified headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get a scheduled change
+// @Tags Scheduled changes
+// @Produce json
+// @Param id path int true "Change ID"
+// @Success 200 {object} Change
+// @Failure 404 {object} utils.ErrorResponse "Change not found"
+// @Router /hr/scheduled-changes/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	change, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, change.UpdatedAt, change.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Scheduled change fetched successfully", change)
+}
+
+// Create schedules a change of an employee.
+// @Summary Schedule an employee change
+// @Description Sets a new job title, employment type, division, manager or salary, any of them, on the
+// @Description effective date. The change is applied by the scheduler on that day; until then it only shows
+// @Description in the employee's preview.
+// @Tags Scheduled changes
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param change body Request true "Change"
+// @Success 201 {object} Change
+// @Failure 400 {object} utils.ErrorResponse "Invalid change, effective date in the past, or unknown division or manager"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/scheduled-changes [post]
+func (h *Handler) Create(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	change, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), employeeID, req)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, change.UpdatedAt, change.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Change scheduled successfully", change)
+}
+
+// Update replaces a pending change, or fixes a failed one so it is applied on the next run.
+// @Summary Update a scheduled change
+// @Tags Scheduled changes
+// @Accept json
+// @Produce json
+// @Param id path int true "Change ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param change body Request true "Change"
+// @Success 200 {object} Change
+// @Failure 400 {object} utils.ErrorResponse "Invalid change, effective date in the past, or unknown division or manager"
+// @Failure 404 {object} utils.ErrorResponse "Change not found"
+// @Failure 409 {object} utils.ErrorResponse "Change already applied or cancelled"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/scheduled-changes/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	change, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, change.UpdatedAt, change.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Scheduled change updated successfully", change)
+}
+
+// Cancel withdraws a change that wasn't applied.
+// @Summary Cancel a scheduled change
+// @Tags Scheduled changes
+// @Produce json
+// @Param id path int true "Change ID"
+// @Success 200 {object} Change
+// @Failure 404 {object} utils.ErrorResponse "Change not found"
+// @Failure 409 {object} utils.ErrorResponse "Change already applied or cancelled"
+// @Router /hr/scheduled-changes/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	change, err := h.service.Cancel(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, change.UpdatedAt, change.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Scheduled change cancelled successfully", change)
+}
+
+// Preview shows an employee's pending changes and where they leave the employee.
+// @Summary Preview an employee's pending changes
+// @Description Lists the pending changes in the order they take effect, each with the job title, employment
+// @Description type, division, manager and salary once applied, starting from today's.
+// @Tags Scheduled changes
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} Preview
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/scheduled-changes [get]
+func (h *Handler) Preview(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	preview, err := h.service.Preview(utils.OrganizationFromContext(c), employeeID)
+	if err != nil {
+		sendChangeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Scheduled changes fetched successfully", preview)
+}
+
+// parseDate reads an optional YYYY-MM-DD query parameter.
+func parseDate(c *gin.Context, name string) (*time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+name+" parameter: expected YYYY-MM-DD")
+		return nil, false
+	}
+	return &date, true
+}
+
+func sendChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidChange):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotPending):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The change was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,103 @@
+// prometheus/backend/internal/change/model.go
+package change
+
+import (
+	"prometheus/backend/internal/employee"
+	"time"
+)
+
+// Status is where a scheduled change is.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Waiting for its effective date
+	StatusApplied   Status = "applied"   // Applied by the scheduler on or after its effective date
+	StatusFailed    Status = "failed"    // Could not be applied, see Error; may be edited back to pending
+	StatusCancelled Status = "cancelled" // Withdrawn before it was applied
+)
+
+// Kind labels what a change is about, for listings. It doesn't restrict which fields the change sets.
+type Kind string
+
+const (
+	KindPromotion Kind = "promotion"
+	KindTransfer  Kind = "transfer"
+	KindSalary    Kind = "salary"
+	KindOther     Kind = "other"
+)
+
+// Change is an HR change entered ahead of the day it takes effect: a new job title or employment type, a
+// move to another division or manager, a new salary, or several at once. The scheduler applies it on
+// EffectiveOn; until then it only shows in the employee's preview. Nil fields are left as they are.
+type Change struct {
+	ID             uint                     `gorm:"primaryKey" json:"id" example:"27"`
+	OrganizationID *uint                    `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint                     `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName    string                   `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Kind           Kind                     `gorm:"type:varchar(20);not null" json:"kind" example:"promotion"`
+	EffectiveOn    time.Time                `gorm:"type:date;not null;index" json:"effective_on" example:"2026-11-01T00:00:00Z"`
+	Status         Status                   `gorm:"type:varchar(20);not null;index" json:"status" example:"pending"`
+	JobTitle       *string                  `gorm:"type:varchar(150)" json:"job_title,omitempty" example:"Senior Payroll Specialist"`
+	EmploymentType *employee.EmploymentType `gorm:"type:varchar(20)" json:"employment_type,omitempty" example:"full_time"`
+	DivisionID     *uint                    `json:"division_id,omitempty" example:"5"`
+	ManagerID      *uint                    `json:"manager_id,omitempty" example:"3"`                            // Employee ID
+	SalaryAmount   *int64                   `json:"salary_amount,omitempty" example:"6800000"`                   // Annual, in minor units
+	SalaryCurrency string                   `gorm:"type:char(3)" json:"salary_currency,omitempty" example:"EUR"` // Set with SalaryAmount
+	Reason         string                   `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Promotion after the annual review"`
+	Error          string                   `gorm:"type:varchar(1000)" json:"error,omitempty"` // Why applying failed
+	AppliedAt      *time.Time               `json:"applied_at,omitempty"`
+	CreatedBy      *uint                    `json:"created_by,omitempty" example:"7"`              // User ID
+	CancelledBy    *uint                    `json:"cancelled_by,omitempty" example:"7"`            // User ID
+	Version        uint                     `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// TableName keeps changes apart from the audit trail's change records.
+func (Change) TableName() string { return "scheduled_changes" }
+
+// State is what changes can set on an employee.
+type State struct {
+	JobTitle       string                  `json:"job_title" example:"Payroll Specialist"`
+	EmploymentType employee.EmploymentType `json:"employment_type" example:"full_time"`
+	DivisionID     *uint                   `json:"division_id,omitempty" example:"2"`
+	ManagerID      *uint                   `json:"manager_id,omitempty" example:"3"`
+	SalaryAmount   *int64                  `json:"salary_amount,omitempty" example:"6200000"` // Nil without a salary on file
+	SalaryCurrency string                  `json:"salary_currency,omitempty" example:"EUR"`
+}
+
+// Step is a pending change with the employee's state once it is applied.
+type Step struct {
+	Change Change `json:"change"`
+	After  State  `json:"after"`
+}
+
+// Preview shows an employee's pending changes in the order they take effect, each with the state it
+// leads to.
+type Preview struct {
+	EmployeeID uint   `json:"employee_id" example:"12"`
+	Current    State  `json:"current"`   // Today
+	Pending    []Step `json:"pending"`   // Due first
+	Projected  State  `json:"projected"` // Once every pending change is applied
+}
+
+// Request schedules a change or replaces a pending or failed one. At least one field must be set.
+type Request struct {
+	Kind           Kind                     `json:"kind" binding:"omitempty,oneof=promotion transfer salary other" example:"promotion"` // Derived from the fields if omitted
+	EffectiveOn    string                   `json:"effective_on" binding:"required,datetime=2006-01-02" example:"2026-11-01"`
+	JobTitle       *string                  `json:"job_title,omitempty" binding:"omitempty,max=150" example:"Senior Payroll Specialist"`
+	EmploymentType *employee.EmploymentType `json:"employment_type,omitempty" binding:"omitempty,oneof=full_time part_time contractor intern temporary" example:"full_time"`
+	DivisionID     *uint                    `json:"division_id,omitempty" example:"5"`
+	ManagerID      *uint                    `json:"manager_id,omitempty" example:"3"`
+	SalaryAmount   *int64                   `json:"salary_amount,omitempty" binding:"omitempty,min=1" example:"6800000"`
+	SalaryCurrency string                   `json:"salary_currency,omitempty" binding:"omitempty,len=3" example:"EUR"`
+	Reason         string                   `json:"reason,omitempty" binding:"max=500" example:"Promotion after the annual review"`
+}
+
+// Filter narrows a listing of changes. From and To bound the effective date.
+type Filter struct {
+	Status     Status
+	EmployeeID *uint
+	From       *time.Time
+	To         *time.Time
+}
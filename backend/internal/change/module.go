@@ -0,0 +1,82 @@
+// prometheus/backend/internal/change/module.go
+package change
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the scheduled changes module.
+const ModuleName = "scheduled-changes"
+
+// applyInterval is how often due changes are applied. Changes take effect by date, so hourly is enough
+// to apply them early on their day.
+const applyInterval = time.Hour
+
+// changeModule owns employee changes scheduled ahead and their application on the effective date.
+type changeModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the scheduled changes module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &changeModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *changeModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. A change still pending the day after it took effect isn't
+// being picked up by the job queue; failed ones wait for HR.
+func (m *changeModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("applying", func(ctx context.Context) module.HealthResult {
+			var overdue, failed int64
+			if err := m.db.WithContext(ctx).Model(&Change{}).Where("status = ? AND effective_on < ?",
+				StatusPending, today()).Count(&overdue).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := m.db.WithContext(ctx).Model(&Change{}).Where("status = ?", StatusFailed).Count(&failed).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if overdue > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"overdue": float64(overdue), "failed": float64(failed)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *changeModule) Models() []any {
+	return []any{&Change{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *changeModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobApply, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		applied, failed, err := m.service.Apply(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"applied": applied, "failed": failed}, nil
+	})
+	q.Every(JobApply, applyInterval)
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *changeModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/hr/scheduled-changes", routing.Policy(), m.handler.List)
+	api.GET("/hr/scheduled-changes/:id", routing.Policy(), m.handler.Get)
+	api.PUT("/hr/scheduled-changes/:id", routing.Policy(), m.handler.Update)
+	api.POST("/hr/scheduled-changes/:id/cancel", routing.Policy(), m.handler.Cancel)
+	api.GET("/hr/employees/:id/scheduled-changes", routing.Policy(), m.handler.Preview)
+	api.POST("/hr/employees/:id/scheduled-changes", routing.Policy(), m.handler.Create)
+}
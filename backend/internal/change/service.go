@@ -0,0 +1,458 @@
+// prometheus/backend/internal/change/service.go
+package change
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobApply is the recurring job type that applies due changes.
+const JobApply = "change.apply"
+
+var (
+	// ErrInvalidChange is returned for changes that fail validation.
+	ErrInvalidChange = errors.New("invalid scheduled change")
+	// ErrNotPending is returned when editing or cancelling a change that was applied or cancelled already.
+	ErrNotPending = errors.New("the change was already applied or cancelled")
+)
+
+// Service schedules HR changes ahead of the day they take effect and applies them on that day.
+// orgID scopes every call to one organization's employees and changes (nil = default organization).
+type Service interface {
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Change, int64, error)
+	Get(orgID *uint, id uint) (*Change, error)
+	// Create schedules a change of an employee, effective today at the earliest.
+	Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Change, error)
+	// Update replaces a pending or failed change; a failed one is pending again.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Change, error)
+	// Cancel withdraws a pending or failed change.
+	Cancel(actor audit.Actor, orgID *uint, id uint) (*Change, error)
+	// Preview shows the employee's pending changes in effective order, with where each leaves them.
+	Preview(orgID *uint, employeeID uint) (*Preview, error)
+	// Apply applies the pending changes due by today, earliest first.
+	Apply(ctx context.Context) (applied, failed int, err error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	salaries  compensation.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Changes are applied to employee records through
+// employees, and to salary histories as records with compensation.SourceScheduled.
+func NewService(db *gorm.DB, employees employee.Service, salaries compensation.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, salaries: salaries, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Change, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Change{}), orgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.From != nil {
+		query = query.Where("effective_on >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("effective_on <= ?", *filter.To)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count scheduled changes: %w", err)
+	}
+	changes := []Change{}
+	if err := query.Order("effective_on, id").Scopes(page.Scope).Find(&changes).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list scheduled changes: %w", err)
+	}
+	if err := s.named(orgID, changes); err != nil {
+		return nil, 0, err
+	}
+	return changes, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Change, error) {
+	var change Change
+	if err := utils.OrgScope(s.db, orgID).First(&change, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	changes := []Change{change}
+	if err := s.named(orgID, changes); err != nil {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Change, error) {
+	if _, err := s.employees.Get(orgID, employeeID); err != nil {
+		return nil, err
+	}
+	change := Change{OrganizationID: orgID, EmployeeID: employeeID, Status: StatusPending, CreatedBy: actor.UserID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, &change, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return fmt.Errorf("failed to schedule change: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "change.create", EntityType: "scheduled_change", EntityID: fmt.Sprintf("%d", change.ID), After: change,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, change.ID)
+}
+
+// Update replaces the change if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Change, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusPending && before.Status != StatusFailed {
+			return ErrNotPending
+		}
+		change := *before
+		if err := s.apply(tx, &change, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Change{}, id, expectedVersion, map[string]interface{}{
+			"kind":            change.Kind,
+			"effective_on":    change.EffectiveOn,
+			"status":          StatusPending,
+			"job_title":       change.JobTitle,
+			"employment_type": change.EmploymentType,
+			"division_id":     change.DivisionID,
+			"manager_id":      change.ManagerID,
+			"salary_amount":   change.SalaryAmount,
+			"salary_currency": change.SalaryCurrency,
+			"reason":          change.Reason,
+			"error":           "",
+		}); err != nil {
+			return err
+		}
+		var updated Change
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload scheduled change %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "change.update", EntityType: "scheduled_change", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+func (s *service) Cancel(actor audit.Actor, orgID *uint, id uint) (*Change, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.lock(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusPending && before.Status != StatusFailed {
+			return ErrNotPending
+		}
+		if err := tx.Model(&Change{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":       StatusCancelled,
+			"cancelled_by": actor.UserID,
+			"version":      gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to cancel scheduled change %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "change.cancel", EntityType: "scheduled_change", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+// Preview starts from the employee's record and the salary in effect today. Failed changes are left
+// out: they wait for HR to fix or cancel them.
+func (s *service) Preview(orgID *uint, employeeID uint) (*Preview, error) {
+	current, err := s.employees.Get(orgID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	state := State{
+		JobTitle:       current.JobTitle,
+		EmploymentType: current.EmploymentType,
+		DivisionID:     current.DivisionID,
+		ManagerID:      current.ManagerID,
+	}
+	salary, err := s.salaries.SalaryOn(orgID, employeeID, today())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if salary != nil {
+		state.SalaryAmount, state.SalaryCurrency = &salary.Amount, salary.Currency
+	}
+	var changes []Change
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ? AND status = ?", employeeID, StatusPending).
+		Order("effective_on, id").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load scheduled changes: %w", err)
+	}
+	if err := s.named(orgID, changes); err != nil {
+		return nil, err
+	}
+	preview := &Preview{EmployeeID: employeeID, Current: state, Pending: make([]Step, 0, len(changes))}
+	for _, change := range changes {
+		state = change.onto(state)
+		preview.Pending = append(preview.Pending, Step{Change: change, After: state})
+	}
+	preview.Projected = state
+	return preview, nil
+}
+
+// Apply works through due changes one transaction each, so a change that can no longer be applied,
+// say because its division was deleted since, is marked failed without holding up the others.
+func (s *service) Apply(ctx context.Context) (int, int, error) {
+	var due []uint
+	if err := s.db.WithContext(ctx).Model(&Change{}).Where("status = ? AND effective_on <= ?", StatusPending, today()).
+		Order("effective_on, id").Pluck("id", &due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to find due changes: %w", err)
+	}
+	var applied, failed int
+	for _, id := range due {
+		if err := ctx.Err(); err != nil {
+			return applied, failed, err
+		}
+		done, err := s.applyDue(ctx, id)
+		if err == nil {
+			if done {
+				applied++
+			}
+			continue
+		}
+		log.Printf("Failed to apply scheduled change %d: %v", id, err)
+		failed++
+		if err := s.db.WithContext(ctx).Model(&Change{}).Where("id = ? AND status = ?", id, StatusPending).
+			Updates(map[string]interface{}{"status": StatusFailed, "error": truncate(err.Error(), 1000)}).Error; err != nil {
+			return applied, failed, fmt.Errorf("failed to record the failure of scheduled change %d: %w", id, err)
+		}
+	}
+	return applied, failed, nil
+}
+
+// applyDue applies one due change, reporting false when it was taken by another instance or is no
+// longer pending.
+func (s *service) applyDue(ctx context.Context, id uint) (bool, error) {
+	var done bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var change Change
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", StatusPending).First(&change, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load scheduled change: %w", err)
+		}
+		if change.JobTitle != nil || change.EmploymentType != nil || change.DivisionID != nil || change.ManagerID != nil {
+			if _, err := s.employees.ApplyTx(tx, audit.SystemActor, change.OrganizationID, change.EmployeeID, employee.Patch{
+				JobTitle:       change.JobTitle,
+				EmploymentType: change.EmploymentType,
+				DivisionID:     change.DivisionID,
+				ManagerID:      change.ManagerID,
+			}); err != nil {
+				return err
+			}
+		}
+		if change.SalaryAmount != nil {
+			// Inserted directly rather than through the compensation service, which opens its own transaction.
+			record := compensation.SalaryRecord{
+				OrganizationID: change.OrganizationID,
+				EmployeeID:     change.EmployeeID,
+				EffectiveOn:    change.EffectiveOn,
+				Amount:         *change.SalaryAmount,
+				Currency:       change.SalaryCurrency,
+				Source:         compensation.SourceScheduled,
+				Reason:         change.Reason,
+				RecordedBy:     change.CreatedBy,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("failed to record salary: %w", err)
+			}
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Change{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":     StatusApplied,
+			"applied_at": now,
+			"error":      "",
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark scheduled change applied: %w", err)
+		}
+		if err := s.auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+			Action: "change.apply", EntityType: "scheduled_change", EntityID: fmt.Sprintf("%d", id), Before: change,
+		}); err != nil {
+			return err
+		}
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// apply validates req and copies it onto change.
+func (s *service) apply(tx *gorm.DB, change *Change, req Request) error {
+	effectiveOn, err := time.Parse("2006-01-02", req.EffectiveOn)
+	if err != nil {
+		return fmt.Errorf("%w: effective_on must be a date", ErrInvalidChange)
+	}
+	if effectiveOn.Before(today()) {
+		return fmt.Errorf("%w: the change can't take effect in the past", ErrInvalidChange)
+	}
+	if req.JobTitle == nil && req.EmploymentType == nil && req.DivisionID == nil && req.ManagerID == nil && req.SalaryAmount == nil {
+		return fmt.Errorf("%w: nothing to change", ErrInvalidChange)
+	}
+	var jobTitle *string
+	if req.JobTitle != nil {
+		title := strings.TrimSpace(*req.JobTitle)
+		if title == "" {
+			return fmt.Errorf("%w: the job title can't be blank", ErrInvalidChange)
+		}
+		jobTitle = &title
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.SalaryCurrency))
+	if (req.SalaryAmount == nil) != (currency == "") {
+		return fmt.Errorf("%w: a salary needs both an amount and a currency", ErrInvalidChange)
+	}
+	if req.DivisionID != nil {
+		// Checked again when applied: the division may be deleted in between.
+		var divisions int64
+		if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", *req.DivisionID), change.OrganizationID).
+			Count(&divisions).Error; err != nil {
+			return fmt.Errorf("failed to load division %d: %w", *req.DivisionID, err)
+		}
+		if divisions == 0 {
+			return fmt.Errorf("%w: division %d not found", ErrInvalidChange, *req.DivisionID)
+		}
+	}
+	if req.ManagerID != nil {
+		if *req.ManagerID == change.EmployeeID {
+			return fmt.Errorf("%w: an employee can't manage themselves", ErrInvalidChange)
+		}
+		if _, err := s.employees.Get(change.OrganizationID, *req.ManagerID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: manager %d not found", ErrInvalidChange, *req.ManagerID)
+		} else if err != nil {
+			return err
+		}
+	}
+	change.EffectiveOn = effectiveOn
+	change.JobTitle = jobTitle
+	change.EmploymentType = req.EmploymentType
+	change.DivisionID = req.DivisionID
+	change.ManagerID = req.ManagerID
+	change.SalaryAmount = req.SalaryAmount
+	change.SalaryCurrency = currency
+	change.Reason = strings.TrimSpace(req.Reason)
+	change.Kind = req.Kind
+	if change.Kind == "" {
+		change.Kind = change.guessKind()
+	}
+	return nil
+}
+
+// guessKind labels a change by what it sets: a new job title is a promotion, a new division or manager
+// a transfer.
+func (c *Change) guessKind() Kind {
+	switch {
+	case c.JobTitle != nil:
+		return KindPromotion
+	case c.DivisionID != nil || c.ManagerID != nil:
+		return KindTransfer
+	case c.SalaryAmount != nil:
+		return KindSalary
+	}
+	return KindOther
+}
+
+// onto returns state with the change applied.
+func (c *Change) onto(state State) State {
+	if c.JobTitle != nil {
+		state.JobTitle = *c.JobTitle
+	}
+	if c.EmploymentType != nil {
+		state.EmploymentType = *c.EmploymentType
+	}
+	if c.DivisionID != nil {
+		state.DivisionID = c.DivisionID
+	}
+	if c.ManagerID != nil {
+		state.ManagerID = c.ManagerID
+	}
+	if c.SalaryAmount != nil {
+		state.SalaryAmount, state.SalaryCurrency = c.SalaryAmount, c.SalaryCurrency
+	}
+	return state
+}
+
+// named fills in the display names of changes' employees.
+func (s *service) named(orgID *uint, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(changes))
+	for i, c := range changes {
+		ids[i] = c.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range changes {
+		changes[i].DisplayName = names[changes[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// lock loads a change for update.
+func (s *service) lock(tx *gorm.DB, orgID *uint, id uint) (*Change, error) {
+	var change Change
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&change, id).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// today is the current UTC date, which changes take effect on.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
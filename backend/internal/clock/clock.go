@@ -0,0 +1,111 @@
+// prometheus/backend/internal/clock/clock.go
+package clock
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDisabled is returned when changing the time while time travel isn't enabled (production).
+var ErrDisabled = errors.New("time travel is disabled in this environment")
+
+// Business logic that depends on the date (role grant expiry, report schedules, token lifetimes, ...)
+// reads the time through Now, so non-production environments can move it with Freeze and Advance
+// instead of waiting real days. The shift applies to this process only; in staging run a single
+// instance, or repeat the change on every replica. Infrastructure such as timeouts, rate limits and job
+// polling keeps using the wall clock.
+
+var state struct {
+	mu      sync.RWMutex
+	enabled bool
+	offset  time.Duration
+	frozen  *time.Time
+}
+
+// Status describes the application time.
+type Status struct {
+	Now    time.Time `json:"now"`
+	Offset string    `json:"offset" example:"72h0m0s"` // Shift from the wall clock
+	Frozen bool      `json:"frozen"`
+}
+
+// Enable allows time travel. Call it once at startup, and never in production.
+func Enable() {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.enabled = true
+}
+
+// Now returns the application time: the wall clock unless it was shifted or frozen.
+func Now() time.Time {
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	if state.frozen != nil {
+		return *state.frozen
+	}
+	if state.offset == 0 {
+		return time.Now()
+	}
+	return time.Now().Add(state.offset)
+}
+
+// Freeze stops the application time at t.
+func Freeze(t time.Time) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.enabled {
+		return ErrDisabled
+	}
+	state.frozen = &t
+	return nil
+}
+
+// Resume lets a frozen application time run again from where it stands.
+func Resume() error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.enabled {
+		return ErrDisabled
+	}
+	if state.frozen != nil {
+		state.offset = time.Until(*state.frozen)
+		state.frozen = nil
+	}
+	return nil
+}
+
+// Advance moves the application time forward by d (backward if negative), frozen or not.
+func Advance(d time.Duration) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.enabled {
+		return ErrDisabled
+	}
+	if state.frozen != nil {
+		t := state.frozen.Add(d)
+		state.frozen = &t
+	} else {
+		state.offset += d
+	}
+	return nil
+}
+
+// Reset returns to the wall clock.
+func Reset() error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.enabled {
+		return ErrDisabled
+	}
+	state.offset, state.frozen = 0, nil
+	return nil
+}
+
+// Current returns the application time and how it deviates from the wall clock.
+func Current() Status {
+	now := Now()
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	return Status{Now: now, Offset: time.Until(now).Round(time.Second).String(), Frozen: state.frozen != nil}
+}
@@ -0,0 +1,183 @@
+// prometheus/backend/internal/compensation/component.go
+package compensation
+
+import (
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *service) Components(orgID *uint, employeeID uint) ([]Component, error) {
+	if err := checkEmployee(s.db, orgID, employeeID); err != nil {
+		return nil, err
+	}
+	components := []Component{}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).
+		Order("start_on DESC, id DESC").Find(&components).Error; err != nil {
+		return nil, fmt.Errorf("failed to list salary components: %w", err)
+	}
+	return components, nil
+}
+
+func (s *service) GetComponent(orgID *uint, id uint) (*Component, error) {
+	var component Component
+	if err := utils.OrgScope(s.db, orgID).First(&component, id).Error; err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+func (s *service) ComponentsBetween(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]Component, error) {
+	components := make(map[uint][]Component, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return components, nil
+	}
+	var found []Component
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ? AND start_on <= ? AND (end_on IS NULL OR end_on >= ?)", employeeIDs, to, from).
+		Order("employee_id, start_on, id").Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to load salary components: %w", err)
+	}
+	for _, c := range found {
+		components[c.EmployeeID] = append(components[c.EmployeeID], c)
+	}
+	return components, nil
+}
+
+func (s *service) AddComponent(actor audit.Actor, orgID *uint, employeeID uint, req ComponentRequest) (*Component, error) {
+	component := Component{OrganizationID: orgID, EmployeeID: employeeID, RecordedBy: actor.UserID}
+	if err := applyComponent(&component, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmployee(tx, orgID, employeeID); err != nil {
+			return err
+		}
+		if err := tx.Create(&component).Error; err != nil {
+			return fmt.Errorf("failed to add salary component: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_component.create", EntityType: "salary_component", EntityID: fmt.Sprintf("%d", component.ID), After: component,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+// UpdateComponent corrects the component's fields if it is still at expectedVersion (optimistic locking).
+// Payroll periods already closed keep what they paid.
+func (s *service) UpdateComponent(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ComponentRequest) (*Component, error) {
+	var updated Component
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Component
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		component := before
+		if err := applyComponent(&component, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Component{}, id, expectedVersion, map[string]interface{}{
+			"kind": component.Kind, "name": component.Name, "amount": component.Amount, "currency": component.Currency,
+			"start_on": component.StartOn, "end_on": component.EndOn, "reason": component.Reason,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload salary component %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_component.update", EntityType: "salary_component", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// EndComponent stops the component after endOn, keeping it in the history.
+func (s *service) EndComponent(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ComponentEndRequest) (*Component, error) {
+	endOn, err := time.Parse("2006-01-02", req.EndOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCompensation)
+	}
+	var updated Component
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var before Component
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if endOn.Before(before.StartOn) {
+			return fmt.Errorf("%w: a component can't end before it starts", ErrInvalidCompensation)
+		}
+		if err := utils.UpdateWithVersion(tx, &Component{}, id, expectedVersion, map[string]interface{}{"end_on": endOn}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload salary component %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_component.end", EntityType: "salary_component", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteComponent removes a component entered by mistake; one that stops applying is ended instead.
+func (s *service) DeleteComponent(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Component
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Component{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete salary component %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_component.delete", EntityType: "salary_component", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func applyComponent(component *Component, req ComponentRequest) error {
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+	startOn, err := time.Parse("2006-01-02", req.StartOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCompensation)
+	}
+	var endOn *time.Time
+	if req.EndOn != "" {
+		end, err := time.Parse("2006-01-02", req.EndOn)
+		if err != nil {
+			return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCompensation)
+		}
+		if end.Before(startOn) {
+			return fmt.Errorf("%w: a component can't end before it starts", ErrInvalidCompensation)
+		}
+		endOn = &end
+	}
+	switch req.Kind {
+	case ComponentAllowance, ComponentDeduction:
+	default:
+		return fmt.Errorf("%w: kind must be allowance or deduction", ErrInvalidCompensation)
+	}
+	component.Name = strings.TrimSpace(req.Name)
+	if component.Name == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidCompensation)
+	}
+	component.Kind, component.Amount, component.Currency = req.Kind, req.Amount, currency
+	component.StartOn, component.EndOn, component.Reason = startOn, endOn, strings.TrimSpace(req.Reason)
+	return nil
+}
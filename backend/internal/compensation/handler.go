@@ -0,0 +1,874 @@
+// prometheus/backend/internal/compensation/handler.go
+package compensation
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for salaries, salary components, bands and compensation reviews.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// SalaryHistory returns an employee's salary history.
+// @Summary Get an employee's salary history
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} SalaryRecord
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/salary [get]
+func (h *Handler) SalaryHistory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	records, err := h.service.SalaryHistory(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary history fetched successfully", records)
+}
+
+// RecordSalary records an employee's salary from a date.
+// @Summary Record a salary
+// @Description Amounts are annual, in minor units of the currency. An entry effective on the same date as
+// @Description an earlier one supersedes it.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param salary body SalaryRequest true "Salary"
+// @Success 201 {object} SalaryRecord
+// @Failure 400 {object} utils.ErrorResponse "Invalid salary"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/salary [post]
+func (h *Handler) RecordSalary(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SalaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	record, err := h.service.RecordSalary(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Salary recorded successfully", record)
+}
+
+// Bonuses returns an employee's bonuses.
+// @Summary List an employee's bonuses
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} Bonus
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/bonuses [get]
+func (h *Handler) Bonuses(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	bonuses, err := h.service.Bonuses(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Bonuses fetched successfully", bonuses)
+}
+
+// Components returns an employee's salary components.
+// @Summary List an employee's salary components
+// @Description Allowances and deductions, current and ended, latest start first.
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} Component
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/salary-components [get]
+func (h *Handler) Components(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	components, err := h.service.Components(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary components fetched successfully", components)
+}
+
+// AddComponent adds an allowance or deduction to an employee's pay.
+// @Summary Add a salary component
+// @Description Amounts are annual, in minor units of the currency; payroll accrues them per calendar day
+// @Description from start_on to end_on. To change an amount, end the component and add a new one.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param component body ComponentRequest true "Component"
+// @Success 201 {object} Component
+// @Failure 400 {object} utils.ErrorResponse "Invalid component"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/salary-components [post]
+func (h *Handler) AddComponent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	component, err := h.service.AddComponent(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Salary component added successfully", component)
+}
+
+// GetComponent returns a salary component.
+// @Summary Get a salary component
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Component ID"
+// @Success 200 {object} Component
+// @Failure 404 {object} utils.ErrorResponse "Component not found"
+// @Router /hr/salary-components/{id} [get]
+func (h *Handler) GetComponent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	component, err := h.service.GetComponent(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, component.UpdatedAt, component.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary component fetched successfully", component)
+}
+
+// UpdateComponent corrects a salary component.
+// @Summary Correct a salary component
+// @Description Requires If-Match (or If-Unmodified-Since) from a previous GET. Closed payroll periods keep
+// @Description what they paid.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Component ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param component body ComponentRequest true "Component"
+// @Success 200 {object} Component
+// @Failure 400 {object} utils.ErrorResponse "Invalid component"
+// @Failure 404 {object} utils.ErrorResponse "Component not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/salary-components/{id} [put]
+func (h *Handler) UpdateComponent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetComponent(orgID, id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	component, err := h.service.UpdateComponent(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, component.UpdatedAt, component.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary component updated successfully", component)
+}
+
+// EndComponent stops a salary component after a day, keeping it in the history.
+// @Summary End a salary component
+// @Description Requires If-Match (or If-Unmodified-Since) from a previous GET.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Component ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param end body ComponentEndRequest true "Last day"
+// @Success 200 {object} Component
+// @Failure 400 {object} utils.ErrorResponse "Ends before it starts"
+// @Failure 404 {object} utils.ErrorResponse "Component not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/salary-components/{id}/end [post]
+func (h *Handler) EndComponent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ComponentEndRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetComponent(orgID, id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	component, err := h.service.EndComponent(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, component.UpdatedAt, component.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary component ended successfully", component)
+}
+
+// DeleteComponent deletes a salary component entered by mistake.
+// @Summary Delete a salary component
+// @Description Components that stop applying should be ended instead, so the history keeps them.
+// @Tags Compensation
+// @Param id path int true "Component ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Component not found"
+// @Router /hr/salary-components/{id} [delete]
+func (h *Handler) DeleteComponent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteComponent(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListBands returns the organization's salary bands.
+// @Summary List salary bands
+// @Tags Compensation
+// @Produce json
+// @Param job_title query string false "Job title, matched case-insensitively"
+// @Param division_id query int false "Division ID"
+// @Success 200 {array} Band
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/salary-bands [get]
+func (h *Handler) ListBands(c *gin.Context) {
+	filter := BandFilter{JobTitle: c.Query("job_title")}
+	var ok bool
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	bands, err := h.service.Bands(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary bands fetched successfully", bands)
+}
+
+// GetBand returns a salary band.
+// @Summary Get a salary band
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Band ID"
+// @Success 200 {object} Band
+// @Failure 404 {object} utils.ErrorResponse "Band not found"
+// @Router /hr/salary-bands/{id} [get]
+func (h *Handler) GetBand(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	band, err := h.service.GetBand(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, band.UpdatedAt, band.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary band fetched successfully", band)
+}
+
+// CreateBand sets the salary range of a job title.
+// @Summary Create a salary band
+// @Description Without division_id the band holds across the organization; with it, it replaces the
+// @Description organization-wide band within that division.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param band body BandRequest true "Band"
+// @Success 201 {object} Band
+// @Failure 400 {object} utils.ErrorResponse "Invalid band or unknown division"
+// @Failure 409 {object} utils.ErrorResponse "Band already exists"
+// @Router /hr/salary-bands [post]
+func (h *Handler) CreateBand(c *gin.Context) {
+	var req BandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	band, err := h.service.CreateBand(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, band.UpdatedAt, band.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Salary band created successfully", band)
+}
+
+// UpdateBand replaces a salary band's fields.
+// @Summary Update a salary band
+// @Description Proposals already checked against the band keep their out-of-band flag.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Band ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param band body BandRequest true "Band"
+// @Success 200 {object} Band
+// @Failure 400 {object} utils.ErrorResponse "Invalid band or unknown division"
+// @Failure 404 {object} utils.ErrorResponse "Band not found"
+// @Failure 409 {object} utils.ErrorResponse "Band already exists"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/salary-bands/{id} [put]
+func (h *Handler) UpdateBand(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req BandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetBand(orgID, id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	band, err := h.service.UpdateBand(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, band.UpdatedAt, band.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Salary band updated successfully", band)
+}
+
+// DeleteBand deletes a salary band.
+// @Summary Delete a salary band
+// @Tags Compensation
+// @Param id path int true "Band ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Band not found"
+// @Router /hr/salary-bands/{id} [delete]
+func (h *Handler) DeleteBand(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteBand(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListCycles returns the organization's compensation reviews, latest first.
+// @Summary List compensation reviews
+// @Tags Compensation
+// @Produce json
+// @Success 200 {array} Cycle
+// @Router /hr/comp-cycles [get]
+func (h *Handler) ListCycles(c *gin.Context) {
+	cycles, err := h.service.Cycles(utils.OrganizationFromContext(c), nil)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation reviews fetched successfully", cycles)
+}
+
+// ListManagerCycles returns the compensation reviews managers take part in: all but drafts.
+// @Summary List compensation reviews for managers
+// @Tags Compensation
+// @Produce json
+// @Success 200 {array} Cycle
+// @Router /manager/comp-cycles [get]
+func (h *Handler) ListManagerCycles(c *gin.Context) {
+	cycles, err := h.service.Cycles(utils.OrganizationFromContext(c), []CycleStatus{CycleOpen, CycleApproval, CycleApplied})
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation reviews fetched successfully", cycles)
+}
+
+// GetCycle returns a compensation review. The ETag and Last-Modified headers can be sent back as If-Match
+// / If-Unmodified-Since.
+// @Summary Get a compensation review
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/comp-cycles/{id} [get]
+func (h *Handler) GetCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.GetCycle(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation review fetched successfully", cycle)
+}
+
+// CreateCycle starts a compensation review as a draft, for HR to set its budgets.
+// @Summary Create a compensation review
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param cycle body CycleRequest true "Cycle"
+// @Success 201 {object} Cycle
+// @Failure 400 {object} utils.ErrorResponse "Invalid cycle"
+// @Router /hr/comp-cycles [post]
+func (h *Handler) CreateCycle(c *gin.Context) {
+	var req CycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	cycle, err := h.service.CreateCycle(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Compensation review created successfully", cycle)
+}
+
+// UpdateCycle replaces a compensation review's fields.
+// @Summary Update a compensation review
+// @Description The currency and effective date can only change while the review is a draft.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param cycle body CycleRequest true "Cycle"
+// @Success 200 {object} Cycle
+// @Failure 400 {object} utils.ErrorResponse "Invalid cycle"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Change not allowed in the cycle's status"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/comp-cycles/{id} [put]
+func (h *Handler) UpdateCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetCycle(orgID, id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	cycle, err := h.service.UpdateCycle(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation review updated successfully", cycle)
+}
+
+// OpenCycle opens a draft compensation review to managers' proposals.
+// @Summary Open a compensation review
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not a draft"
+// @Router /hr/comp-cycles/{id}/open [post]
+func (h *Handler) OpenCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.OpenCycle(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation review opened successfully", cycle)
+}
+
+// StartApproval closes a compensation review to proposals so HR can decide them.
+// @Summary Start approval
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not open"
+// @Router /hr/comp-cycles/{id}/approval [post]
+func (h *Handler) StartApproval(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.StartApproval(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation review in approval", cycle)
+}
+
+// ApplyCycle writes the approved proposals into the salary history.
+// @Summary Apply a compensation review
+// @Description Every proposal must be decided. Approved salaries are recorded effective on the review's
+// @Description date and approved bonuses become payable on it, in one transaction.
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} ApplyResult
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not in approval, or proposals undecided"
+// @Router /hr/comp-cycles/{id}/apply [post]
+func (h *Handler) ApplyCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	result, err := h.service.ApplyCycle(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Compensation review applied successfully", result)
+}
+
+// ListBudgets returns a compensation review's budgets and what its proposals take from them.
+// @Summary List budgets
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {array} BudgetUsage
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/comp-cycles/{id}/budgets [get]
+func (h *Handler) ListBudgets(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	budgets, err := h.service.Budgets(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Budgets fetched successfully", budgets)
+}
+
+// SetBudget sets a division's budget in a compensation review.
+// @Summary Set a division's budget
+// @Description A budget can't go below what the division's pending and approved proposals add up to.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param division_id path int true "Division ID"
+// @Param budget body BudgetRequest true "Budget"
+// @Success 200 {object} BudgetUsage
+// @Failure 400 {object} utils.ErrorResponse "Invalid budget or unknown division"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle applied, or below what proposals take"
+// @Router /hr/comp-cycles/{id}/budgets/{division_id} [put]
+func (h *Handler) SetBudget(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	divisionID, ok := utils.ParseUintParam(c, "division_id")
+	if !ok {
+		return
+	}
+	var req BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	budget, err := h.service.SetBudget(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, divisionID, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Budget set successfully", budget)
+}
+
+// ListProposals returns a compensation review's proposals.
+// @Summary List proposals
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param status query string false "Status" Enums(pending, approved, rejected)
+// @Param division_id query int false "Division ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/comp-cycles/{id}/proposals [get]
+func (h *Handler) ListProposals(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	filter := ProposalFilter{Status: ProposalStatus(c.Query("status"))}
+	switch filter.Status {
+	case "", ProposalPending, ProposalApproved, ProposalRejected:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	proposals, total, err := h.service.Proposals(utils.OrganizationFromContext(c), id, filter, page)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Proposals fetched successfully", page.Response(proposals, total))
+}
+
+// Decide approves or rejects a proposal.
+// @Summary Decide a proposal
+// @Description Decisions can change until the review is applied. Approving a rejected proposal checks the
+// @Description budget again.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param employee_id path int true "Employee ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param decision body DecisionRequest true "Decision"
+// @Success 200 {object} Proposal
+// @Failure 404 {object} utils.ErrorResponse "Cycle or proposal not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not in approval, or over budget"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/comp-cycles/{id}/proposals/{employee_id}/decision [post]
+func (h *Handler) Decide(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetProposal(orgID, id, employeeID)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	proposal, err := h.service.Decide(audit.ActorFromContext(c), orgID, id, employeeID, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, proposal.UpdatedAt, proposal.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Proposal decided successfully", proposal)
+}
+
+// Worksheet returns the caller's reports in a compensation review, with their salaries, bands and
+// proposals, and the budgets of their divisions.
+// @Summary Get the caller's compensation worksheet
+// @Description Reports are the caller's direct reports and the employees of the divisions they lead; HR
+// @Description sees every employee.
+// @Tags Compensation
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Worksheet
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /manager/comp-cycles/{id}/worksheet [get]
+func (h *Handler) Worksheet(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	worksheet, err := h.service.Worksheet(utils.OrganizationFromContext(c), proposer(c), id)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Worksheet fetched successfully", worksheet)
+}
+
+// Propose proposes a report's new salary and bonus, replacing any earlier proposal.
+// @Summary Propose a raise and bonus
+// @Description The salary is checked against the employee's band, and needs a justification outside it.
+// @Description The raise and bonus are checked against what is left of the division's budget. Send the
+// @Description proposal's ETag as If-Match when replacing it.
+// @Tags Compensation
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param employee_id path int true "Employee ID"
+// @Param If-Match header string false "Version ETag of the proposal being replaced"
+// @Param proposal body ProposalRequest true "Proposal"
+// @Success 200 {object} Proposal
+// @Failure 400 {object} utils.ErrorResponse "Invalid proposal, out of band, or no salary on record"
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Cycle or employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not open, or over budget"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /manager/comp-cycles/{id}/proposals/{employee_id} [put]
+func (h *Handler) Propose(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	var req ProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	var expectedVersion uint
+	switch current, err := h.service.GetProposal(orgID, id, employeeID); {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		sendCompensationError(c, err)
+		return
+	default:
+		if expectedVersion, ok = utils.CheckPrecondition(c, current.UpdatedAt, current.Version); !ok {
+			return
+		}
+	}
+	proposal, err := h.service.Propose(audit.ActorFromContext(c), orgID, proposer(c), id, employeeID, expectedVersion, req)
+	if err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, proposal.UpdatedAt, proposal.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Proposal saved successfully", proposal)
+}
+
+// Withdraw deletes the proposal for a report.
+// @Summary Withdraw a proposal
+// @Tags Compensation
+// @Param id path int true "Cycle ID"
+// @Param employee_id path int true "Employee ID"
+// @Success 204
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Cycle or proposal not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not open"
+// @Router /manager/comp-cycles/{id}/proposals/{employee_id} [delete]
+func (h *Handler) Withdraw(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	if err := h.service.Withdraw(audit.ActorFromContext(c), utils.OrganizationFromContext(c), proposer(c), id, employeeID); err != nil {
+		sendCompensationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func proposer(c *gin.Context) Proposer {
+	userID, hr, leads := middleware.LineManagement(c)
+	return Proposer{UserID: userID, HR: hr, Leads: leads}
+}
+
+// sendCompensationError maps service errors to HTTP status codes.
+func sendCompensationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidCompensation), errors.Is(err, ErrOutOfBand):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotReport):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrCycleStatus), errors.Is(err, ErrOverBudget), errors.Is(err, ErrBandTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
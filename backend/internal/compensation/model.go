@@ -0,0 +1,299 @@
+// prometheus/backend/internal/compensation/model.go
+package compensation
+
+import (
+	"time"
+)
+
+// SalarySource is what recorded a salary change.
+type SalarySource string
+
+const (
+	SourceManual     SalarySource = "manual"      // Entered by HR
+	SourceCompReview SalarySource = "comp_review" // Applied from an approved proposal of a compensation review
+	SourceImport     SalarySource = "import"      // Brought over from a legacy system, see internal/legacy
+	SourceScheduled  SalarySource = "scheduled"   // Applied from a change scheduled ahead, see internal/change
+)
+
+// SalaryRecord is an entry in an employee's salary history: their base salary from EffectiveOn until the
+// next entry. History is only appended to; a correction is a new entry. Amounts throughout the package are
+// annual and in minor units of their currency (cents), so they add up exactly.
+type SalaryRecord struct {
+	ID             uint         `gorm:"primaryKey" json:"id" example:"30"`
+	OrganizationID *uint        `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint         `gorm:"not null;index:idx_salary_record_employee" json:"employee_id" example:"12"`
+	EffectiveOn    time.Time    `gorm:"type:date;not null;index:idx_salary_record_employee" json:"effective_on" example:"2026-04-01T00:00:00Z"`
+	Amount         int64        `gorm:"not null" json:"amount" example:"6200000"`
+	Currency       string       `gorm:"type:char(3);not null" json:"currency" example:"EUR"` // ISO 4217
+	Source         SalarySource `gorm:"type:varchar(20);not null" json:"source" example:"comp_review"`
+	CycleID        *uint        `gorm:"index" json:"cycle_id,omitempty" example:"4"` // SourceCompReview
+	Reason         string       `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Annual review"`
+	RecordedBy     *uint        `json:"recorded_by,omitempty" example:"7"` // User ID
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// Bonus is a one-off payment granted to an employee, payable on PayableOn.
+type Bonus struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"8"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	PayableOn      time.Time `gorm:"type:date;not null" json:"payable_on" example:"2026-04-01T00:00:00Z"`
+	Amount         int64     `gorm:"not null" json:"amount" example:"300000"`
+	Currency       string    `gorm:"type:char(3);not null" json:"currency" example:"EUR"`
+	CycleID        *uint     `gorm:"index" json:"cycle_id,omitempty" example:"4"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps bonuses next to salary records.
+func (Bonus) TableName() string { return "salary_bonuses" }
+
+// ComponentKind says whether a salary component adds to pay or takes from it.
+type ComponentKind string
+
+const (
+	ComponentAllowance ComponentKind = "allowance" // Paid on top of the base salary, e.g. a car or housing allowance
+	ComponentDeduction ComponentKind = "deduction" // Withheld from pay, e.g. union dues or a pension contribution
+)
+
+// Component is a recurring allowance or deduction of an employee from StartOn to EndOn, both included
+// (open-ended without EndOn). Like salaries, amounts are annual; payroll accrues them per calendar day.
+// Changing a component's amount is ending it and adding a new one, so the history shows what applied when.
+type Component struct {
+	ID             uint          `gorm:"primaryKey" json:"id" example:"17"`
+	OrganizationID *uint         `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint          `gorm:"not null;index" json:"employee_id" example:"12"`
+	Kind           ComponentKind `gorm:"type:varchar(20);not null" json:"kind" example:"allowance"`
+	Name           string        `gorm:"type:varchar(100);not null" json:"name" example:"Housing allowance"`
+	Amount         int64         `gorm:"not null" json:"amount" example:"360000"`
+	Currency       string        `gorm:"type:char(3);not null" json:"currency" example:"EUR"`
+	StartOn        time.Time     `gorm:"type:date;not null" json:"start_on" example:"2026-01-01T00:00:00Z"`
+	EndOn          *time.Time    `gorm:"type:date" json:"end_on,omitempty" example:"2026-12-31T00:00:00Z"`
+	Reason         string        `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Relocation to Munich"`
+	RecordedBy     *uint         `json:"recorded_by,omitempty" example:"7"`             // User ID
+	Version        uint          `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// TableName keeps components next to salary records.
+func (Component) TableName() string { return "salary_components" }
+
+// Band is the salary range for a job title, in one division or, without a division, in all of them. A
+// division's band takes precedence over the organization-wide one.
+type Band struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	JobTitle       string    `gorm:"type:varchar(150);not null;index" json:"job_title" example:"Payroll Specialist"` // Matched case-insensitively
+	DivisionID     *uint     `gorm:"index" json:"division_id,omitempty" example:"2"`
+	Currency       string    `gorm:"type:char(3);not null" json:"currency" example:"EUR"`
+	Min            int64     `gorm:"not null" json:"min" example:"5000000"`
+	Max            int64     `gorm:"not null" json:"max" example:"7000000"`
+	Version        uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName names bands after what they bound.
+func (Band) TableName() string { return "salary_bands" }
+
+// CycleStatus is where a compensation review is.
+type CycleStatus string
+
+const (
+	CycleDraft    CycleStatus = "draft"    // HR sets the budgets
+	CycleOpen     CycleStatus = "open"     // Managers propose for their reports
+	CycleApproval CycleStatus = "approval" // Proposals are frozen; HR approves or rejects them
+	CycleApplied  CycleStatus = "applied"  // Approved proposals are in the salary history
+)
+
+// Cycle is a compensation review: raises and bonuses proposed by managers within their divisions'
+// budgets, approved by HR and applied together on EffectiveOn.
+type Cycle struct {
+	ID             uint        `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID *uint       `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string      `gorm:"type:varchar(100);not null" json:"name" example:"2026 annual review"`
+	Currency       string      `gorm:"type:char(3);not null" json:"currency" example:"EUR"` // Of budgets and proposals
+	EffectiveOn    time.Time   `gorm:"type:date;not null" json:"effective_on" example:"2026-04-01T00:00:00Z"`
+	Status         CycleStatus `gorm:"type:varchar(20);not null" json:"status" example:"open"`
+	AppliedAt      *time.Time  `json:"applied_at,omitempty"`
+	Version        uint        `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// TableName keeps cycles with the rest of the compensation review tables.
+func (Cycle) TableName() string { return "comp_cycles" }
+
+// Budget is what a division's proposals may add up to in a cycle: the raises to annual salaries and the
+// bonuses.
+type Budget struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"11"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CycleID        uint      `gorm:"not null;uniqueIndex:idx_comp_budget" json:"cycle_id" example:"4"`
+	DivisionID     uint      `gorm:"not null;uniqueIndex:idx_comp_budget" json:"division_id" example:"2"`
+	Raises         int64     `gorm:"not null" json:"raises" example:"4000000"`
+	Bonuses        int64     `gorm:"not null" json:"bonuses" example:"1500000"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps budgets with the rest of the compensation review tables.
+func (Budget) TableName() string { return "comp_budgets" }
+
+// ProposalStatus is where a proposal is.
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalApproved ProposalStatus = "approved"
+	ProposalRejected ProposalStatus = "rejected"
+)
+
+// Proposal is a manager's proposed salary and bonus for a report in a cycle. The employee's salary and
+// division are taken when it is made, so the budget it counts against doesn't move under it.
+type Proposal struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"61"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CycleID        uint           `gorm:"not null;uniqueIndex:idx_comp_proposal" json:"cycle_id" example:"4"`
+	EmployeeID     uint           `gorm:"not null;uniqueIndex:idx_comp_proposal;index" json:"employee_id" example:"12"`
+	DisplayName    string         `gorm:"-" json:"display_name" example:"Laila Haddad"`
+	DivisionID     uint           `gorm:"not null;index" json:"division_id" example:"2"`
+	CurrentAmount  int64          `gorm:"not null" json:"current_amount" example:"5800000"`
+	ProposedAmount int64          `gorm:"not null" json:"proposed_amount" example:"6200000"`
+	Bonus          int64          `gorm:"not null" json:"bonus" example:"300000"`
+	BandID         *uint          `json:"band_id,omitempty" example:"5"` // Band the proposal was checked against
+	OutOfBand      bool           `gorm:"not null" json:"out_of_band"`   // Outside its band, with a justification
+	Justification  string         `gorm:"type:varchar(1000)" json:"justification,omitempty"`
+	Status         ProposalStatus `gorm:"type:varchar(20);not null;index" json:"status" example:"pending"`
+	ProposedBy     *uint          `json:"proposed_by,omitempty" example:"3"` // User ID
+	DecidedBy      *uint          `json:"decided_by,omitempty" example:"7"`  // User ID
+	DecisionNote   string         `gorm:"type:varchar(1000)" json:"decision_note,omitempty"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName keeps proposals with the rest of the compensation review tables.
+func (Proposal) TableName() string { return "comp_proposals" }
+
+// Raise is what the proposal adds to the employee's annual salary.
+func (p Proposal) Raise() int64 { return p.ProposedAmount - p.CurrentAmount }
+
+// Proposer is who proposes: HR for anyone, a manager for their direct reports and the divisions they lead.
+type Proposer struct {
+	UserID uint
+	HR     bool   // Holds an HR role globally
+	Leads  []uint // Divisions led through a division-scoped manager role; headed divisions are added by the service
+}
+
+// SalaryRequest records an employee's salary from a date.
+type SalaryRequest struct {
+	EffectiveOn string `json:"effective_on" binding:"required,datetime=2006-01-02" example:"2026-01-01"`
+	Amount      int64  `json:"amount" binding:"required,min=1" example:"5800000"`
+	Currency    string `json:"currency" binding:"required,len=3" example:"EUR"`
+	Reason      string `json:"reason,omitempty" binding:"max=500" example:"Hired"`
+}
+
+// ComponentRequest adds a salary component or replaces its fields.
+type ComponentRequest struct {
+	Kind     ComponentKind `json:"kind" binding:"required,oneof=allowance deduction" example:"allowance"`
+	Name     string        `json:"name" binding:"required,max=100" example:"Housing allowance"`
+	Amount   int64         `json:"amount" binding:"required,min=1" example:"360000"` // Annual
+	Currency string        `json:"currency" binding:"required,len=3" example:"EUR"`
+	StartOn  string        `json:"start_on" binding:"required,datetime=2006-01-02" example:"2026-01-01"`
+	EndOn    string        `json:"end_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-12-31"`
+	Reason   string        `json:"reason,omitempty" binding:"max=500" example:"Relocation to Munich"`
+}
+
+// ComponentEndRequest ends a component on a day, both included.
+type ComponentEndRequest struct {
+	EndOn string `json:"end_on" binding:"required,datetime=2006-01-02" example:"2026-06-30"`
+}
+
+// BandRequest creates a band or replaces its fields.
+type BandRequest struct {
+	JobTitle   string `json:"job_title" binding:"required,max=150" example:"Payroll Specialist"`
+	DivisionID *uint  `json:"division_id,omitempty" example:"2"`
+	Currency   string `json:"currency" binding:"required,len=3" example:"EUR"`
+	Min        int64  `json:"min" binding:"min=0" example:"5000000"`
+	Max        int64  `json:"max" binding:"required,min=1" example:"7000000"`
+}
+
+// BandFilter narrows a band listing.
+type BandFilter struct {
+	JobTitle   string
+	DivisionID *uint
+}
+
+// CycleRequest creates a cycle or replaces its fields.
+type CycleRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"2026 annual review"`
+	Currency    string `json:"currency" binding:"required,len=3" example:"EUR"`
+	EffectiveOn string `json:"effective_on" binding:"required,datetime=2006-01-02" example:"2026-04-01"`
+}
+
+// BudgetRequest sets a division's budget in a cycle.
+type BudgetRequest struct {
+	Raises  int64 `json:"raises" binding:"min=0" example:"4000000"`
+	Bonuses int64 `json:"bonuses" binding:"min=0" example:"1500000"`
+}
+
+// ProposalRequest proposes a report's new salary and bonus. A salary outside the band needs a
+// justification.
+type ProposalRequest struct {
+	ProposedAmount int64  `json:"proposed_amount" binding:"required,min=1" example:"6200000"`
+	Bonus          int64  `json:"bonus" binding:"min=0" example:"300000"`
+	Justification  string `json:"justification,omitempty" binding:"max=1000" example:"Took over payroll for two countries"`
+}
+
+// DecisionRequest approves or rejects a proposal.
+type DecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty" binding:"max=1000" example:"Within budget"`
+}
+
+// ProposalFilter narrows a proposal listing.
+type ProposalFilter struct {
+	Status     ProposalStatus
+	DivisionID *uint
+}
+
+// BudgetUsage is a division's budget and what its proposals take from it. Rejected proposals take nothing.
+type BudgetUsage struct {
+	DivisionID  uint  `json:"division_id" example:"2"`
+	Raises      int64 `json:"raises" example:"4000000"`
+	Bonuses     int64 `json:"bonuses" example:"1500000"`
+	RaisesUsed  int64 `json:"raises_used" example:"2600000"`
+	BonusesUsed int64 `json:"bonuses_used" example:"900000"`
+	RaisesLeft  int64 `json:"raises_left" example:"1400000"`
+	BonusesLeft int64 `json:"bonuses_left" example:"600000"`
+	Proposals   int   `json:"proposals" example:"9"`
+	HasBudget   bool  `json:"has_budget"` // False for divisions with proposals but no budget set
+}
+
+// WorksheetLine is a report a manager may propose for, with their salary, band and proposal if any.
+type WorksheetLine struct {
+	EmployeeID    uint      `json:"employee_id" example:"12"`
+	DisplayName   string    `json:"display_name" example:"Laila Haddad"`
+	JobTitle      string    `json:"job_title" example:"Payroll Specialist"`
+	DivisionID    *uint     `json:"division_id,omitempty" example:"2"`
+	CurrentAmount *int64    `json:"current_amount,omitempty" example:"5800000"` // Salary on the cycle's effective date; none on record if missing
+	Currency      string    `json:"currency,omitempty" example:"EUR"`
+	Band          *Band     `json:"band,omitempty"`
+	Proposal      *Proposal `json:"proposal,omitempty"`
+}
+
+// Worksheet is what a manager sees of a cycle: their reports and the budgets of their divisions.
+type Worksheet struct {
+	Cycle   Cycle           `json:"cycle"`
+	Lines   []WorksheetLine `json:"lines"`
+	Budgets []BudgetUsage   `json:"budgets"`
+}
+
+// ApplyResult is what applying a cycle wrote to the salary history.
+type ApplyResult struct {
+	Cycle         Cycle `json:"cycle"`
+	SalaryRecords int   `json:"salary_records" example:"37"`
+	Bonuses       int   `json:"bonuses" example:"21"`
+	Rejected      int   `json:"rejected" example:"3"`
+}
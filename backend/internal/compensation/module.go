@@ -0,0 +1,67 @@
+// prometheus/backend/internal/compensation/module.go
+package compensation
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the compensation module.
+const ModuleName = "compensation"
+
+// compensationModule owns salary history, salary components, salary bands and compensation reviews.
+type compensationModule struct {
+	handler *Handler
+}
+
+// NewModule creates the compensation module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &compensationModule{handler: NewHandler(svc)}
+}
+
+func (m *compensationModule) Name() string { return ModuleName }
+
+func (m *compensationModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *compensationModule) Models() []any {
+	return []any{&SalaryRecord{}, &Bonus{}, &Component{}, &Band{}, &Cycle{}, &Budget{}, &Proposal{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Everything belongs to the payroll module: HR keeps salaries,
+// components, bands and reviews, and managers propose for their reports.
+func (m *compensationModule) RegisterRoutes(api *routing.Group) {
+	payrollAPI := api.InModule(plan.ModulePayroll)
+	payrollAPI.GET("/hr/employees/:id/salary", routing.Policy(), m.handler.SalaryHistory)
+	payrollAPI.POST("/hr/employees/:id/salary", routing.Policy(), m.handler.RecordSalary)
+	payrollAPI.GET("/hr/employees/:id/bonuses", routing.Policy(), m.handler.Bonuses)
+	payrollAPI.GET("/hr/employees/:id/salary-components", routing.Policy(), m.handler.Components)
+	payrollAPI.POST("/hr/employees/:id/salary-components", routing.Policy(), m.handler.AddComponent)
+	payrollAPI.GET("/hr/salary-components/:id", routing.Policy(), m.handler.GetComponent)
+	payrollAPI.PUT("/hr/salary-components/:id", routing.Policy(), m.handler.UpdateComponent)
+	payrollAPI.POST("/hr/salary-components/:id/end", routing.Policy(), m.handler.EndComponent)
+	payrollAPI.DELETE("/hr/salary-components/:id", routing.Policy(), m.handler.DeleteComponent)
+	payrollAPI.GET("/hr/salary-bands", routing.Policy(), m.handler.ListBands)
+	payrollAPI.POST("/hr/salary-bands", routing.Policy(), m.handler.CreateBand)
+	payrollAPI.GET("/hr/salary-bands/:id", routing.Policy(), m.handler.GetBand)
+	payrollAPI.PUT("/hr/salary-bands/:id", routing.Policy(), m.handler.UpdateBand)
+	payrollAPI.DELETE("/hr/salary-bands/:id", routing.Policy(), m.handler.DeleteBand)
+
+	payrollAPI.GET("/hr/comp-cycles", routing.Policy(), m.handler.ListCycles)
+	payrollAPI.POST("/hr/comp-cycles", routing.Policy(), m.handler.CreateCycle)
+	payrollAPI.GET("/hr/comp-cycles/:id", routing.Policy(), m.handler.GetCycle)
+	payrollAPI.PUT("/hr/comp-cycles/:id", routing.Policy(), m.handler.UpdateCycle)
+	payrollAPI.POST("/hr/comp-cycles/:id/open", routing.Policy(), m.handler.OpenCycle)
+	payrollAPI.POST("/hr/comp-cycles/:id/approval", routing.Policy(), m.handler.StartApproval)
+	payrollAPI.POST("/hr/comp-cycles/:id/apply", routing.Policy(), m.handler.ApplyCycle)
+	payrollAPI.GET("/hr/comp-cycles/:id/budgets", routing.Policy(), m.handler.ListBudgets)
+	payrollAPI.PUT("/hr/comp-cycles/:id/budgets/:division_id", routing.Policy(), m.handler.SetBudget)
+	payrollAPI.GET("/hr/comp-cycles/:id/proposals", routing.Policy(), m.handler.ListProposals)
+	payrollAPI.POST("/hr/comp-cycles/:id/proposals/:employee_id/decision", routing.Policy(), m.handler.Decide)
+
+	payrollAPI.GET("/manager/comp-cycles", routing.Policy(), m.handler.ListManagerCycles)
+	payrollAPI.GET("/manager/comp-cycles/:id/worksheet", routing.Policy(), m.handler.Worksheet)
+	payrollAPI.PUT("/manager/comp-cycles/:id/proposals/:employee_id", routing.Policy(), m.handler.Propose)
+	payrollAPI.DELETE("/manager/comp-cycles/:id/proposals/:employee_id", routing.Policy(), m.handler.Withdraw)
+}
@@ -0,0 +1,296 @@
+// prometheus/backend/internal/compensation/salary.go
+package compensation
+
+import (
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *service) SalaryHistory(orgID *uint, employeeID uint) ([]SalaryRecord, error) {
+	if err := checkEmployee(s.db, orgID, employeeID); err != nil {
+		return nil, err
+	}
+	records := []SalaryRecord{}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).
+		Order("effective_on DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list salary records: %w", err)
+	}
+	return records, nil
+}
+
+func (s *service) SalaryOn(orgID *uint, employeeID uint, date time.Time) (*SalaryRecord, error) {
+	var record SalaryRecord
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ? AND effective_on <= ?", employeeID, date).
+		Order("effective_on DESC, id DESC").First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *service) Salaries(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]SalaryRecord, error) {
+	salaries := make(map[uint][]SalaryRecord, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return salaries, nil
+	}
+	var records []SalaryRecord
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ? AND effective_on <= ?", employeeIDs, to).
+		Order("employee_id, effective_on, id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load salaries: %w", err)
+	}
+	for _, r := range records {
+		history := salaries[r.EmployeeID]
+		// Of the records effective by from, only the last one is still in effect then; a record effective on
+		// the same date as the one before supersedes it.
+		if n := len(history); n > 0 && (!r.EffectiveOn.After(from) || r.EffectiveOn.Equal(history[n-1].EffectiveOn)) {
+			history[n-1] = r
+		} else {
+			history = append(history, r)
+		}
+		salaries[r.EmployeeID] = history
+	}
+	return salaries, nil
+}
+
+// RecordSalary appends to the history; an entry effective on the same date as another supersedes it.
+func (s *service) RecordSalary(actor audit.Actor, orgID *uint, employeeID uint, req SalaryRequest) (*SalaryRecord, error) {
+	effectiveOn, err := time.Parse("2006-01-02", req.EffectiveOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCompensation)
+	}
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	record := SalaryRecord{
+		OrganizationID: orgID, EmployeeID: employeeID, EffectiveOn: effectiveOn, Amount: req.Amount, Currency: currency,
+		Source: SourceManual, Reason: strings.TrimSpace(req.Reason), RecordedBy: actor.UserID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmployee(tx, orgID, employeeID); err != nil {
+			return err
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to record salary: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary.record", EntityType: "salary_record", EntityID: fmt.Sprintf("%d", record.ID), After: record,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *service) Bonuses(orgID *uint, employeeID uint) ([]Bonus, error) {
+	if err := checkEmployee(s.db, orgID, employeeID); err != nil {
+		return nil, err
+	}
+	bonuses := []Bonus{}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).
+		Order("payable_on DESC, id DESC").Find(&bonuses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list bonuses: %w", err)
+	}
+	return bonuses, nil
+}
+
+func (s *service) Bands(orgID *uint, filter BandFilter) ([]Band, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if title := strings.TrimSpace(filter.JobTitle); title != "" {
+		query = query.Where("LOWER(job_title) = LOWER(?)", title)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	bands := []Band{}
+	if err := query.Order("LOWER(job_title), division_id NULLS FIRST, id").Find(&bands).Error; err != nil {
+		return nil, fmt.Errorf("failed to list bands: %w", err)
+	}
+	return bands, nil
+}
+
+func (s *service) GetBand(orgID *uint, id uint) (*Band, error) {
+	var band Band
+	if err := utils.OrgScope(s.db, orgID).First(&band, id).Error; err != nil {
+		return nil, err
+	}
+	return &band, nil
+}
+
+func (s *service) CreateBand(actor audit.Actor, orgID *uint, req BandRequest) (*Band, error) {
+	band := Band{OrganizationID: orgID}
+	if err := applyBand(&band, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkBand(tx, orgID, 0, band); err != nil {
+			return err
+		}
+		if err := tx.Create(&band).Error; err != nil {
+			return fmt.Errorf("failed to create band: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_band.create", EntityType: "salary_band", EntityID: fmt.Sprintf("%d", band.ID), After: band,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &band, nil
+}
+
+// UpdateBand replaces the band's fields if it is still at expectedVersion (optimistic locking). Proposals
+// already checked against it keep their out-of-band flag.
+func (s *service) UpdateBand(actor audit.Actor, orgID *uint, id, expectedVersion uint, req BandRequest) (*Band, error) {
+	var updated Band
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Band
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		band := before
+		if err := applyBand(&band, req); err != nil {
+			return err
+		}
+		if err := checkBand(tx, orgID, id, band); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Band{}, id, expectedVersion, map[string]interface{}{
+			"job_title": band.JobTitle, "division_id": band.DivisionID, "currency": band.Currency, "min": band.Min, "max": band.Max,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload band %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_band.update", EntityType: "salary_band", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteBand(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Band
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Band{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete band %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "salary_band.delete", EntityType: "salary_band", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+// checkBand rejects a second band for the same job title and division. The lock serializes concurrent
+// creations, which a unique index can't do for case-insensitive titles and null divisions.
+func checkBand(tx *gorm.DB, orgID *uint, id uint, band Band) error {
+	if err := lock.Tx(tx, fmt.Sprintf("salary_band:%s", strings.ToLower(band.JobTitle))); err != nil {
+		return err
+	}
+	query := utils.OrgScope(tx.Model(&Band{}), orgID).Where("LOWER(job_title) = LOWER(?) AND id <> ?", band.JobTitle, id)
+	if band.DivisionID == nil {
+		query = query.Where("division_id IS NULL")
+	} else {
+		query = query.Where("division_id = ?", *band.DivisionID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check bands: %w", err)
+	}
+	if count > 0 {
+		return ErrBandTaken
+	}
+	if band.DivisionID != nil {
+		if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", *band.DivisionID), orgID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check division: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: unknown division", ErrInvalidCompensation)
+		}
+	}
+	return nil
+}
+
+func applyBand(band *Band, req BandRequest) error {
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+	if req.Min > req.Max {
+		return fmt.Errorf("%w: the band's minimum is above its maximum", ErrInvalidCompensation)
+	}
+	band.JobTitle = strings.TrimSpace(req.JobTitle)
+	if band.JobTitle == "" {
+		return fmt.Errorf("%w: a job title is required", ErrInvalidCompensation)
+	}
+	band.DivisionID, band.Currency, band.Min, band.Max = req.DivisionID, currency, req.Min, req.Max
+	return nil
+}
+
+// bandOf returns the band for a job title in a division: the division's own, else the organization-wide
+// one, else nil.
+func bandOf(bands []Band, jobTitle string, divisionID *uint) *Band {
+	var general *Band
+	for i, b := range bands {
+		if !strings.EqualFold(b.JobTitle, jobTitle) {
+			continue
+		}
+		if b.DivisionID == nil {
+			general = &bands[i]
+		} else if divisionID != nil && *b.DivisionID == *divisionID {
+			return &bands[i]
+		}
+	}
+	return general
+}
+
+// salariesOn returns the salary records in effect on date, by employee. Employees without one are missing.
+func salariesOn(db *gorm.DB, employeeIDs []uint, date time.Time) (map[uint]SalaryRecord, error) {
+	var records []SalaryRecord
+	if err := db.Where("employee_id IN ? AND effective_on <= ?", employeeIDs, date).
+		Order("employee_id, effective_on DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load salaries: %w", err)
+	}
+	salaries := make(map[uint]SalaryRecord, len(employeeIDs))
+	for _, r := range records {
+		if _, ok := salaries[r.EmployeeID]; !ok {
+			salaries[r.EmployeeID] = r
+		}
+	}
+	return salaries, nil
+}
+
+// checkEmployee returns gorm.ErrRecordNotFound unless the employee is one of the organization's.
+func checkEmployee(db *gorm.DB, orgID *uint, employeeID uint) error {
+	var count int64
+	if err := utils.OrgScope(db.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check employee: %w", err)
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// parseCurrency normalizes an ISO 4217 code to upper case.
+func parseCurrency(raw string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(raw))
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w: currencies are three-letter ISO 4217 codes", ErrInvalidCompensation)
+	}
+	return currency, nil
+}
@@ -0,0 +1,808 @@
+// prometheus/backend/internal/compensation/service.go
+package compensation
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidCompensation is returned for salaries, bands, cycles and proposals that fail validation.
+	ErrInvalidCompensation = errors.New("invalid compensation")
+	// ErrCycleStatus is returned for changes the cycle's status doesn't allow: budgets are set until it is
+	// applied, proposals made while it is open and decided while it is in approval.
+	ErrCycleStatus = errors.New("the compensation review's status does not allow this change")
+	// ErrNotReport is returned when proposing for someone who isn't the proposer's report.
+	ErrNotReport = errors.New("you may only propose for your reports")
+	// ErrOverBudget is returned when proposals would add up to more than their division's budget.
+	ErrOverBudget = errors.New("over the division's budget")
+	// ErrOutOfBand is returned for a proposed salary outside its band without a justification.
+	ErrOutOfBand = errors.New("the proposed salary is outside the band; add a justification")
+	// ErrBandTaken is returned for a second band for the same job title and division.
+	ErrBandTaken = errors.New("a band for this job title and division already exists")
+)
+
+// Service manages salary history, salary components, salary bands and compensation reviews. orgID scopes
+// every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	// SalaryHistory lists an employee's salary records, latest effective first.
+	SalaryHistory(orgID *uint, employeeID uint) ([]SalaryRecord, error)
+	// SalaryOn returns the employee's salary record in effect on date, or gorm.ErrRecordNotFound.
+	SalaryOn(orgID *uint, employeeID uint, date time.Time) (*SalaryRecord, error)
+	// Salaries returns, by employee, the salary records in effect at any point from from to to, earliest
+	// first. Employees without any are missing.
+	Salaries(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]SalaryRecord, error)
+	RecordSalary(actor audit.Actor, orgID *uint, employeeID uint, req SalaryRequest) (*SalaryRecord, error)
+	// Bonuses lists an employee's bonuses, latest payable first.
+	Bonuses(orgID *uint, employeeID uint) ([]Bonus, error)
+
+	// Components lists an employee's allowances and deductions, current and past, latest start first.
+	Components(orgID *uint, employeeID uint) ([]Component, error)
+	GetComponent(orgID *uint, id uint) (*Component, error)
+	// ComponentsBetween returns, by employee, the components applying at any point from from to to,
+	// earliest start first. Employees without any are missing.
+	ComponentsBetween(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]Component, error)
+	AddComponent(actor audit.Actor, orgID *uint, employeeID uint, req ComponentRequest) (*Component, error)
+	UpdateComponent(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ComponentRequest) (*Component, error)
+	EndComponent(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ComponentEndRequest) (*Component, error)
+	DeleteComponent(actor audit.Actor, orgID *uint, id uint) error
+
+	Bands(orgID *uint, filter BandFilter) ([]Band, error)
+	GetBand(orgID *uint, id uint) (*Band, error)
+	CreateBand(actor audit.Actor, orgID *uint, req BandRequest) (*Band, error)
+	UpdateBand(actor audit.Actor, orgID *uint, id, expectedVersion uint, req BandRequest) (*Band, error)
+	DeleteBand(actor audit.Actor, orgID *uint, id uint) error
+
+	Cycles(orgID *uint, statuses []CycleStatus) ([]Cycle, error)
+	GetCycle(orgID *uint, id uint) (*Cycle, error)
+	CreateCycle(actor audit.Actor, orgID *uint, req CycleRequest) (*Cycle, error)
+	UpdateCycle(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CycleRequest) (*Cycle, error)
+	// OpenCycle lets managers propose.
+	OpenCycle(actor audit.Actor, orgID *uint, id uint) (*Cycle, error)
+	// StartApproval freezes the proposals so HR can decide them.
+	StartApproval(actor audit.Actor, orgID *uint, id uint) (*Cycle, error)
+	// ApplyCycle writes the approved proposals into the salary history, effective on the cycle's date.
+	ApplyCycle(actor audit.Actor, orgID *uint, id uint) (*ApplyResult, error)
+
+	// Budgets lists the cycle's budgets and what its proposals take from them.
+	Budgets(orgID *uint, cycleID uint) ([]BudgetUsage, error)
+	SetBudget(actor audit.Actor, orgID *uint, cycleID, divisionID uint, req BudgetRequest) (*BudgetUsage, error)
+
+	Proposals(orgID *uint, cycleID uint, filter ProposalFilter, page utils.Pagination) ([]Proposal, int64, error)
+	GetProposal(orgID *uint, cycleID, employeeID uint) (*Proposal, error)
+	// Worksheet lists the reports proposer may propose for in the cycle.
+	Worksheet(orgID *uint, proposer Proposer, cycleID uint) (*Worksheet, error)
+	// Propose makes or replaces the proposal for a report while the cycle is open. expectedVersion is 0 for
+	// a first proposal.
+	Propose(actor audit.Actor, orgID *uint, proposer Proposer, cycleID, employeeID, expectedVersion uint, req ProposalRequest) (*Proposal, error)
+	// Withdraw deletes a proposal while the cycle is open.
+	Withdraw(actor audit.Actor, orgID *uint, proposer Proposer, cycleID, employeeID uint) error
+	// Decide approves or rejects a proposal while the cycle is in approval.
+	Decide(actor audit.Actor, orgID *uint, cycleID, employeeID, expectedVersion uint, req DecisionRequest) (*Proposal, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Employee names are resolved through employees.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Cycles(orgID *uint, statuses []CycleStatus) ([]Cycle, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	cycles := []Cycle{}
+	if err := query.Order("effective_on DESC, id DESC").Find(&cycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list compensation reviews: %w", err)
+	}
+	return cycles, nil
+}
+
+func (s *service) GetCycle(orgID *uint, id uint) (*Cycle, error) {
+	var cycle Cycle
+	if err := utils.OrgScope(s.db, orgID).First(&cycle, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &cycle, nil
+}
+
+func (s *service) CreateCycle(actor audit.Actor, orgID *uint, req CycleRequest) (*Cycle, error) {
+	cycle := Cycle{OrganizationID: orgID, Status: CycleDraft}
+	if err := applyCycle(&cycle, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&cycle).Error; err != nil {
+			return fmt.Errorf("failed to create compensation review: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_cycle.create", EntityType: "comp_cycle", EntityID: fmt.Sprintf("%d", cycle.ID), After: cycle,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+// UpdateCycle replaces the cycle's fields if it is still at expectedVersion (optimistic locking). The
+// currency and effective date are fixed once the cycle is open, as proposals were checked against them.
+func (s *service) UpdateCycle(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CycleRequest) (*Cycle, error) {
+	var updated Cycle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockCycle(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status == CycleApplied {
+			return fmt.Errorf("%w: the review is applied", ErrCycleStatus)
+		}
+		cycle := *before
+		if err := applyCycle(&cycle, req); err != nil {
+			return err
+		}
+		if before.Status != CycleDraft && (cycle.Currency != before.Currency || !cycle.EffectiveOn.Equal(before.EffectiveOn)) {
+			return fmt.Errorf("%w: the currency and effective date are fixed once the review is open", ErrCycleStatus)
+		}
+		if err := utils.UpdateWithVersion(tx, &Cycle{}, id, expectedVersion, map[string]interface{}{
+			"name": cycle.Name, "currency": cycle.Currency, "effective_on": cycle.EffectiveOn,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload compensation review %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_cycle.update", EntityType: "comp_cycle", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) OpenCycle(actor audit.Actor, orgID *uint, id uint) (*Cycle, error) {
+	return s.transition(actor, orgID, id, CycleDraft, CycleOpen, "comp_cycle.open")
+}
+
+func (s *service) StartApproval(actor audit.Actor, orgID *uint, id uint) (*Cycle, error) {
+	return s.transition(actor, orgID, id, CycleOpen, CycleApproval, "comp_cycle.approval")
+}
+
+// transition moves a cycle from the status from to status.
+func (s *service) transition(actor audit.Actor, orgID *uint, id uint, from, status CycleStatus, action string) (*Cycle, error) {
+	var updated Cycle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockCycle(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != from {
+			return fmt.Errorf("%w: the review is %s", ErrCycleStatus, before.Status)
+		}
+		if err := tx.Model(&Cycle{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": status, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update compensation review %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload compensation review %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: action, EntityType: "comp_cycle", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ApplyCycle requires every proposal to be decided. Approved salaries are recorded as proposed, effective
+// on the cycle's date, even if the history changed since; a proposal keeping the salary records only its
+// bonus.
+func (s *service) ApplyCycle(actor audit.Actor, orgID *uint, id uint) (*ApplyResult, error) {
+	var result ApplyResult
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockCycle(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != CycleApproval {
+			return fmt.Errorf("%w: reviews are applied from approval, it is %s", ErrCycleStatus, before.Status)
+		}
+		var proposals []Proposal
+		if err := tx.Where("cycle_id = ?", id).Order("employee_id").Find(&proposals).Error; err != nil {
+			return fmt.Errorf("failed to load proposals: %w", err)
+		}
+		var records []SalaryRecord
+		var bonuses []Bonus
+		for _, p := range proposals {
+			switch p.Status {
+			case ProposalPending:
+				return fmt.Errorf("%w: the proposal for employee %d is not decided", ErrCycleStatus, p.EmployeeID)
+			case ProposalRejected:
+				result.Rejected++
+				continue
+			}
+			cycleID := id
+			if p.Raise() != 0 {
+				records = append(records, SalaryRecord{
+					OrganizationID: orgID, EmployeeID: p.EmployeeID, EffectiveOn: before.EffectiveOn, Amount: p.ProposedAmount,
+					Currency: before.Currency, Source: SourceCompReview, CycleID: &cycleID, Reason: before.Name, RecordedBy: actor.UserID,
+				})
+			}
+			if p.Bonus > 0 {
+				bonuses = append(bonuses, Bonus{
+					OrganizationID: orgID, EmployeeID: p.EmployeeID, PayableOn: before.EffectiveOn, Amount: p.Bonus,
+					Currency: before.Currency, CycleID: &cycleID,
+				})
+			}
+		}
+		if len(records) > 0 {
+			if err := tx.CreateInBatches(&records, 200).Error; err != nil {
+				return fmt.Errorf("failed to record salaries: %w", err)
+			}
+		}
+		if len(bonuses) > 0 {
+			if err := tx.CreateInBatches(&bonuses, 200).Error; err != nil {
+				return fmt.Errorf("failed to record bonuses: %w", err)
+			}
+		}
+		if err := tx.Model(&Cycle{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": CycleApplied, "applied_at": clock.Now().UTC(), "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update compensation review %d: %w", id, err)
+		}
+		if err := tx.First(&result.Cycle, id).Error; err != nil {
+			return fmt.Errorf("failed to reload compensation review %d: %w", id, err)
+		}
+		result.SalaryRecords, result.Bonuses = len(records), len(bonuses)
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_cycle.apply", EntityType: "comp_cycle", EntityID: fmt.Sprintf("%d", id), Before: before, After: result,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *service) Budgets(orgID *uint, cycleID uint) ([]BudgetUsage, error) {
+	if _, err := s.GetCycle(orgID, cycleID); err != nil {
+		return nil, err
+	}
+	return usage(s.db, cycleID, nil)
+}
+
+// SetBudget can't take a budget below what its division's proposals already add up to.
+func (s *service) SetBudget(actor audit.Actor, orgID *uint, cycleID, divisionID uint, req BudgetRequest) (*BudgetUsage, error) {
+	var updated *BudgetUsage
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status == CycleApplied {
+			return fmt.Errorf("%w: the review is applied", ErrCycleStatus)
+		}
+		var found int64
+		if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", divisionID), orgID).Count(&found).Error; err != nil {
+			return fmt.Errorf("failed to check division: %w", err)
+		}
+		if found == 0 {
+			return fmt.Errorf("%w: unknown division", ErrInvalidCompensation)
+		}
+		current, err := usage(tx, cycleID, []uint{divisionID})
+		if err != nil {
+			return err
+		}
+		if len(current) > 0 && (req.Raises < current[0].RaisesUsed || req.Bonuses < current[0].BonusesUsed) {
+			return fmt.Errorf("%w: proposals already take %d in raises and %d in bonuses", ErrOverBudget,
+				current[0].RaisesUsed, current[0].BonusesUsed)
+		}
+		var before *Budget
+		var budget Budget
+		switch err := tx.Where("cycle_id = ? AND division_id = ?", cycleID, divisionID).First(&budget).Error; {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			budget = Budget{OrganizationID: orgID, CycleID: cycleID, DivisionID: divisionID}
+		case err != nil:
+			return fmt.Errorf("failed to load budget: %w", err)
+		default:
+			previous := budget
+			before = &previous
+		}
+		budget.Raises, budget.Bonuses = req.Raises, req.Bonuses
+		if err := tx.Save(&budget).Error; err != nil {
+			return fmt.Errorf("failed to save budget: %w", err)
+		}
+		budgets, err := usage(tx, cycleID, []uint{divisionID})
+		if err != nil {
+			return err
+		}
+		updated = &budgets[0]
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_budget.set", EntityType: "comp_budget", EntityID: fmt.Sprintf("%d", budget.ID), Before: before, After: budget,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Proposals(orgID *uint, cycleID uint, filter ProposalFilter, page utils.Pagination) ([]Proposal, int64, error) {
+	if _, err := s.GetCycle(orgID, cycleID); err != nil {
+		return nil, 0, err
+	}
+	query := s.db.Model(&Proposal{}).Where("cycle_id = ?", cycleID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count proposals: %w", err)
+	}
+	proposals := []Proposal{}
+	if err := query.Order("division_id, employee_id").Scopes(page.Scope).Find(&proposals).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list proposals: %w", err)
+	}
+	if err := s.named(orgID, proposals); err != nil {
+		return nil, 0, err
+	}
+	return proposals, total, nil
+}
+
+func (s *service) GetProposal(orgID *uint, cycleID, employeeID uint) (*Proposal, error) {
+	var proposal Proposal
+	if err := utils.OrgScope(s.db, orgID).Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&proposal).Error; err != nil {
+		return nil, err
+	}
+	proposals := []Proposal{proposal}
+	if err := s.named(orgID, proposals); err != nil {
+		return nil, err
+	}
+	return &proposals[0], nil
+}
+
+func (s *service) Worksheet(orgID *uint, proposer Proposer, cycleID uint) (*Worksheet, error) {
+	cycle, err := s.GetCycle(orgID, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status == CycleDraft && !proposer.HR {
+		return nil, gorm.ErrRecordNotFound // Managers see a review once it opens
+	}
+	query := utils.OrgScope(s.db.Table("employees").Where("deleted_at IS NULL AND user_id <> ?", proposer.UserID), orgID)
+	if !proposer.HR {
+		self, leads, err := s.leads(s.db, orgID, proposer)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(manager_id = ? OR division_id IN ?)", self, append(leads, 0))
+	}
+	var reports []report
+	if err := query.Select("id, user_id, job_title, manager_id, division_id").Order("id").Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reports: %w", err)
+	}
+	worksheet := &Worksheet{Cycle: *cycle, Lines: make([]WorksheetLine, 0, len(reports)), Budgets: []BudgetUsage{}}
+	if len(reports) == 0 {
+		return worksheet, nil
+	}
+	ids := make([]uint, len(reports))
+	var divisionIDs []uint
+	for i, r := range reports {
+		ids[i] = r.ID
+		if r.DivisionID != nil && !slices.Contains(divisionIDs, *r.DivisionID) {
+			divisionIDs = append(divisionIDs, *r.DivisionID)
+		}
+	}
+	salaries, err := salariesOn(s.db, ids, cycle.EffectiveOn)
+	if err != nil {
+		return nil, err
+	}
+	var bands []Band
+	if err := utils.OrgScope(s.db, orgID).Find(&bands).Error; err != nil {
+		return nil, fmt.Errorf("failed to load bands: %w", err)
+	}
+	var proposals []Proposal
+	if err := s.db.Where("cycle_id = ? AND employee_id IN ?", cycleID, ids).Find(&proposals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load proposals: %w", err)
+	}
+	if err := s.named(orgID, proposals); err != nil {
+		return nil, err
+	}
+	byEmployee := make(map[uint]Proposal, len(proposals))
+	for _, p := range proposals {
+		byEmployee[p.EmployeeID] = p
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		line := WorksheetLine{EmployeeID: r.ID, DisplayName: names[r.ID].Text, JobTitle: r.JobTitle, DivisionID: r.DivisionID}
+		if salary, ok := salaries[r.ID]; ok {
+			amount := salary.Amount
+			line.CurrentAmount, line.Currency = &amount, salary.Currency
+		}
+		line.Band = bandOf(bands, r.JobTitle, r.DivisionID)
+		if p, ok := byEmployee[r.ID]; ok {
+			line.Proposal = &p
+		}
+		worksheet.Lines = append(worksheet.Lines, line)
+	}
+	slices.SortStableFunc(worksheet.Lines, func(a, b WorksheetLine) int {
+		return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	})
+	if len(divisionIDs) > 0 {
+		if worksheet.Budgets, err = usage(s.db, cycleID, divisionIDs); err != nil {
+			return nil, err
+		}
+	}
+	return worksheet, nil
+}
+
+// Propose checks the salary against the employee's band and the proposal against their division's budget.
+// The budget row is locked, so two managers of a division can't both spend its last raise.
+func (s *service) Propose(actor audit.Actor, orgID *uint, proposer Proposer, cycleID, employeeID, expectedVersion uint, req ProposalRequest) (*Proposal, error) {
+	justification := strings.TrimSpace(req.Justification)
+	var updated Proposal
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status != CycleOpen {
+			return fmt.Errorf("%w: proposals are made while the review is open, it is %s", ErrCycleStatus, cycle.Status)
+		}
+		r, err := s.checkReport(tx, orgID, proposer, employeeID)
+		if err != nil {
+			return err
+		}
+		if r.DivisionID == nil {
+			return fmt.Errorf("%w: the employee has no division to budget the proposal against", ErrInvalidCompensation)
+		}
+		salaries, err := salariesOn(tx, []uint{employeeID}, cycle.EffectiveOn)
+		if err != nil {
+			return err
+		}
+		salary, ok := salaries[employeeID]
+		if !ok {
+			return fmt.Errorf("%w: the employee has no salary on record on %s", ErrInvalidCompensation, cycle.EffectiveOn.Format("2006-01-02"))
+		}
+		if salary.Currency != cycle.Currency {
+			return fmt.Errorf("%w: the employee is paid in %s, the review is in %s", ErrInvalidCompensation, salary.Currency, cycle.Currency)
+		}
+		if req.ProposedAmount < salary.Amount {
+			return fmt.Errorf("%w: the proposed salary is below the current one", ErrInvalidCompensation)
+		}
+		proposal := Proposal{
+			OrganizationID: orgID, CycleID: cycleID, EmployeeID: employeeID, DivisionID: *r.DivisionID, CurrentAmount: salary.Amount,
+			ProposedAmount: req.ProposedAmount, Bonus: req.Bonus, Justification: justification, Status: ProposalPending,
+			ProposedBy: actor.UserID,
+		}
+		var bands []Band
+		if err := utils.OrgScope(tx, orgID).Where("LOWER(job_title) = LOWER(?) AND currency = ?", r.JobTitle, cycle.Currency).Find(&bands).Error; err != nil {
+			return fmt.Errorf("failed to load bands: %w", err)
+		}
+		if band := bandOf(bands, r.JobTitle, r.DivisionID); band != nil {
+			proposal.BandID = &band.ID
+			proposal.OutOfBand = req.ProposedAmount < band.Min || req.ProposedAmount > band.Max
+			if proposal.OutOfBand && justification == "" {
+				return fmt.Errorf("%w (%d to %d)", ErrOutOfBand, band.Min, band.Max)
+			}
+		}
+		if err := checkBudget(tx, cycleID, proposal); err != nil {
+			return err
+		}
+		var before *Proposal
+		var existing Proposal
+		switch err := tx.Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&existing).Error; {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if expectedVersion != 0 {
+				return utils.ErrVersionConflict
+			}
+			if err := tx.Create(&proposal).Error; err != nil {
+				return fmt.Errorf("failed to create proposal: %w", err)
+			}
+			existing = proposal
+		case err != nil:
+			return fmt.Errorf("failed to load proposal: %w", err)
+		default:
+			before = &existing
+			if err := utils.UpdateWithVersion(tx, &Proposal{}, existing.ID, expectedVersion, map[string]interface{}{
+				"division_id": proposal.DivisionID, "current_amount": proposal.CurrentAmount, "proposed_amount": proposal.ProposedAmount,
+				"bonus": proposal.Bonus, "band_id": proposal.BandID, "out_of_band": proposal.OutOfBand,
+				"justification": proposal.Justification, "proposed_by": proposal.ProposedBy,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.First(&updated, existing.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload proposal %d: %w", existing.ID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_proposal.propose", EntityType: "comp_proposal", EntityID: fmt.Sprintf("%d", updated.ID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	proposals := []Proposal{updated}
+	if err := s.named(orgID, proposals); err != nil {
+		return nil, err
+	}
+	return &proposals[0], nil
+}
+
+func (s *service) Withdraw(actor audit.Actor, orgID *uint, proposer Proposer, cycleID, employeeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status != CycleOpen {
+			return fmt.Errorf("%w: proposals are withdrawn while the review is open, it is %s", ErrCycleStatus, cycle.Status)
+		}
+		if _, err := s.checkReport(tx, orgID, proposer, employeeID); err != nil {
+			return err
+		}
+		var before Proposal
+		if err := tx.Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Proposal{}, before.ID).Error; err != nil {
+			return fmt.Errorf("failed to delete proposal %d: %w", before.ID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_proposal.withdraw", EntityType: "comp_proposal", EntityID: fmt.Sprintf("%d", before.ID), Before: before,
+		})
+	})
+}
+
+// Decide may change a decision until the cycle is applied. Approving takes nothing more from the budget:
+// pending proposals already count against it.
+func (s *service) Decide(actor audit.Actor, orgID *uint, cycleID, employeeID, expectedVersion uint, req DecisionRequest) (*Proposal, error) {
+	status := ProposalRejected
+	if req.Approve {
+		status = ProposalApproved
+	}
+	var updated Proposal
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status != CycleApproval {
+			return fmt.Errorf("%w: proposals are decided while the review is in approval, it is %s", ErrCycleStatus, cycle.Status)
+		}
+		var before Proposal
+		if err := tx.Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&before).Error; err != nil {
+			return err
+		}
+		if status == ProposalApproved && before.Status == ProposalRejected {
+			// A rejected proposal gave its share of the budget back, which others may have used since.
+			if err := checkBudget(tx, cycleID, before); err != nil {
+				return err
+			}
+		}
+		if err := utils.UpdateWithVersion(tx, &Proposal{}, before.ID, expectedVersion, map[string]interface{}{
+			"status": status, "decided_by": actor.UserID, "decision_note": strings.TrimSpace(req.Note),
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, before.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload proposal %d: %w", before.ID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "comp_proposal.decide", EntityType: "comp_proposal", EntityID: fmt.Sprintf("%d", before.ID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	proposals := []Proposal{updated}
+	if err := s.named(orgID, proposals); err != nil {
+		return nil, err
+	}
+	return &proposals[0], nil
+}
+
+// report is the part of an employee record proposals need.
+type report struct {
+	ID         uint
+	UserID     uint
+	JobTitle   string
+	ManagerID  *uint
+	DivisionID *uint
+}
+
+// checkReport loads the employee and checks proposer may propose for them: HR for anyone, a manager for
+// their direct reports and the employees of divisions they lead. Nobody proposes for themselves.
+func (s *service) checkReport(tx *gorm.DB, orgID *uint, proposer Proposer, employeeID uint) (*report, error) {
+	var r report
+	if err := utils.OrgScope(tx.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).
+		Select("id, user_id, job_title, manager_id, division_id").Take(&r).Error; err != nil {
+		return nil, err
+	}
+	if r.UserID == proposer.UserID {
+		return nil, fmt.Errorf("%w, not for yourself", ErrNotReport)
+	}
+	if proposer.HR {
+		return &r, nil
+	}
+	self, leads, err := s.leads(tx, orgID, proposer)
+	if err != nil {
+		return nil, err
+	}
+	if (r.ManagerID != nil && *r.ManagerID == self) || (r.DivisionID != nil && slices.Contains(leads, *r.DivisionID)) {
+		return &r, nil
+	}
+	return nil, ErrNotReport
+}
+
+// leads returns the proposer's employee ID (0 if they have no employee record) and the divisions they lead:
+// through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, orgID *uint, proposer Proposer) (uint, []uint, error) {
+	var self []uint
+	if err := utils.OrgScope(tx.Table("employees").Where("user_id = ? AND deleted_at IS NULL", proposer.UserID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load the proposer's employee record: %w", err)
+	}
+	leads := slices.Clone(proposer.Leads)
+	if len(self) == 0 {
+		return 0, leads, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Where("head_id = ? AND deleted_at IS NULL", self[0]).Pluck("id", &headed).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return self[0], append(leads, headed...), nil
+}
+
+// named fills in the display names of proposals.
+func (s *service) named(orgID *uint, proposals []Proposal) error {
+	ids := make([]uint, len(proposals))
+	for i, p := range proposals {
+		ids[i] = p.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range proposals {
+		proposals[i].DisplayName = names[proposals[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// checkBudget checks that proposal fits in its division's budget alongside the division's other pending
+// and approved proposals. It locks the budget until the transaction ends.
+func checkBudget(tx *gorm.DB, cycleID uint, proposal Proposal) error {
+	var budget Budget
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("cycle_id = ? AND division_id = ?", cycleID, proposal.DivisionID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: division %d has no budget in this review", ErrOverBudget, proposal.DivisionID)
+		}
+		return fmt.Errorf("failed to load budget: %w", err)
+	}
+	var others struct {
+		Raises  int64
+		Bonuses int64
+	}
+	if err := tx.Model(&Proposal{}).
+		Select("COALESCE(SUM(proposed_amount - current_amount), 0) AS raises, COALESCE(SUM(bonus), 0) AS bonuses").
+		Where("cycle_id = ? AND division_id = ? AND employee_id <> ? AND status <> ?",
+			cycleID, proposal.DivisionID, proposal.EmployeeID, ProposalRejected).
+		Scan(&others).Error; err != nil {
+		return fmt.Errorf("failed to sum proposals: %w", err)
+	}
+	if left := budget.Raises - others.Raises; proposal.Raise() > left {
+		return fmt.Errorf("%w: %d is left for raises", ErrOverBudget, max(left, 0))
+	}
+	if left := budget.Bonuses - others.Bonuses; proposal.Bonus > left {
+		return fmt.Errorf("%w: %d is left for bonuses", ErrOverBudget, max(left, 0))
+	}
+	return nil
+}
+
+// usage returns the budgets of a cycle with what its pending and approved proposals take from them, by
+// division. divisionIDs limits it to those divisions, listed even without a budget or proposals; nil lists
+// every division with either.
+func usage(db *gorm.DB, cycleID uint, divisionIDs []uint) ([]BudgetUsage, error) {
+	budgetQuery := db.Where("cycle_id = ?", cycleID)
+	proposalQuery := db.Model(&Proposal{}).Where("cycle_id = ? AND status <> ?", cycleID, ProposalRejected)
+	if divisionIDs != nil {
+		budgetQuery = budgetQuery.Where("division_id IN ?", divisionIDs)
+		proposalQuery = proposalQuery.Where("division_id IN ?", divisionIDs)
+	}
+	var budgets []Budget
+	if err := budgetQuery.Find(&budgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load budgets: %w", err)
+	}
+	var sums []struct {
+		DivisionID uint
+		Raises     int64
+		Bonuses    int64
+		Proposals  int
+	}
+	if err := proposalQuery.
+		Select("division_id, SUM(proposed_amount - current_amount) AS raises, SUM(bonus) AS bonuses, COUNT(*) AS proposals").
+		Group("division_id").Scan(&sums).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum proposals: %w", err)
+	}
+	byDivision := make(map[uint]*BudgetUsage)
+	get := func(divisionID uint) *BudgetUsage {
+		if u, ok := byDivision[divisionID]; ok {
+			return u
+		}
+		u := &BudgetUsage{DivisionID: divisionID}
+		byDivision[divisionID] = u
+		return u
+	}
+	for _, id := range divisionIDs {
+		get(id)
+	}
+	for _, b := range budgets {
+		u := get(b.DivisionID)
+		u.Raises, u.Bonuses, u.HasBudget = b.Raises, b.Bonuses, true
+	}
+	for _, sum := range sums {
+		u := get(sum.DivisionID)
+		u.RaisesUsed, u.BonusesUsed, u.Proposals = sum.Raises, sum.Bonuses, sum.Proposals
+	}
+	result := make([]BudgetUsage, 0, len(byDivision))
+	for _, u := range byDivision {
+		u.RaisesLeft, u.BonusesLeft = u.Raises-u.RaisesUsed, u.Bonuses-u.BonusesUsed
+		result = append(result, *u)
+	}
+	slices.SortFunc(result, func(a, b BudgetUsage) int { return cmp.Compare(a.DivisionID, b.DivisionID) })
+	return result, nil
+}
+
+// lockCycle loads a cycle for update, so its status can't change until the transaction ends.
+func lockCycle(tx *gorm.DB, orgID *uint, id uint) (*Cycle, error) {
+	var cycle Cycle
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&cycle, id).Error; err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+func applyCycle(cycle *Cycle, req CycleRequest) error {
+	effectiveOn, err := time.Parse("2006-01-02", req.EffectiveOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCompensation)
+	}
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+	cycle.Name = strings.TrimSpace(req.Name)
+	cycle.Currency, cycle.EffectiveOn = currency, effectiveOn
+	return nil
+}
@@ -0,0 +1,224 @@
+// prometheus/backend/internal/contacts/handler.go
+package contacts
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for emergency contacts and dependents.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Mine returns the caller's emergency contacts and dependents.
+// @Summary Get my emergency contacts and dependents
+// @Description Emergency contacts in the order they are called, dependents from the oldest.
+// @Tags Contacts
+// @Produce json
+// @Success 200 {object} Profile
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/contacts [get]
+func (h *Handler) Mine(c *gin.Context) {
+	profile, err := h.service.Mine(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Contacts fetched successfully", profile)
+}
+
+// ForEmployee returns an employee's emergency contacts and dependents.
+// @Summary Get an employee's emergency contacts and dependents
+// @Tags Contacts
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} Profile
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/contacts [get]
+func (h *Handler) ForEmployee(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	profile, err := h.service.ForEmployee(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Contacts fetched successfully", profile)
+}
+
+// CreateContact adds an emergency contact.
+// @Summary Add an emergency contact
+// @Description At most 5. Relationships: spouse, partner, parent, child, stepchild, foster_child, sibling,
+// @Description grandparent, grandchild, guardian, relative, friend, neighbor, other. Phone numbers are stored
+// @Description as digits, with + for international ones.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param contact body ContactRequest true "Emergency contact"
+// @Success 201 {object} EmergencyContact
+// @Failure 400 {object} utils.ErrorResponse "Invalid phone number or relationship"
+// @Failure 409 {object} utils.ErrorResponse "Too many emergency contacts"
+// @Router /me/emergency-contacts [post]
+func (h *Handler) CreateContact(c *gin.Context) {
+	var req ContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	contact, err := h.service.CreateContact(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), req)
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Emergency contact created successfully", contact)
+}
+
+// UpdateContact replaces an emergency contact.
+// @Summary Update an emergency contact
+// @Description Leaving out the priority keeps it.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Param contact body ContactRequest true "Emergency contact"
+// @Success 200 {object} EmergencyContact
+// @Failure 400 {object} utils.ErrorResponse "Invalid phone number or relationship"
+// @Failure 404 {object} utils.ErrorResponse "Contact not found"
+// @Router /me/emergency-contacts/{id} [put]
+func (h *Handler) UpdateContact(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	contact, err := h.service.UpdateContact(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), id, req)
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Emergency contact updated successfully", contact)
+}
+
+// DeleteContact removes an emergency contact.
+// @Summary Delete an emergency contact
+// @Tags Contacts
+// @Param id path int true "Contact ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Contact not found"
+// @Router /me/emergency-contacts/{id} [delete]
+func (h *Handler) DeleteContact(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteContact(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), id); err != nil {
+		sendContactError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateDependent adds a dependent.
+// @Summary Add a dependent
+// @Description At most 20. Relationships: spouse, partner, parent, child, stepchild, foster_child, sibling,
+// @Description grandparent, grandchild, relative.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param dependent body DependentRequest true "Dependent"
+// @Success 201 {object} Dependent
+// @Failure 400 {object} utils.ErrorResponse "Invalid relationship or birth date"
+// @Failure 409 {object} utils.ErrorResponse "Too many dependents"
+// @Router /me/dependents [post]
+func (h *Handler) CreateDependent(c *gin.Context) {
+	var req DependentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	dependent, err := h.service.CreateDependent(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), req)
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Dependent created successfully", dependent)
+}
+
+// UpdateDependent replaces a dependent.
+// @Summary Update a dependent
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "Dependent ID"
+// @Param dependent body DependentRequest true "Dependent"
+// @Success 200 {object} Dependent
+// @Failure 400 {object} utils.ErrorResponse "Invalid relationship or birth date"
+// @Failure 404 {object} utils.ErrorResponse "Dependent not found"
+// @Router /me/dependents/{id} [put]
+func (h *Handler) UpdateDependent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DependentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	dependent, err := h.service.UpdateDependent(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), id, req)
+	if err != nil {
+		sendContactError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Dependent updated successfully", dependent)
+}
+
+// DeleteDependent removes a dependent.
+// @Summary Delete a dependent
+// @Tags Contacts
+// @Param id path int true "Dependent ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Dependent not found"
+// @Router /me/dependents/{id} [delete]
+func (h *Handler) DeleteDependent(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteDependent(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), id); err != nil {
+		sendContactError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sendContactError maps service errors to HTTP status codes.
+func sendContactError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidContact):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrLimitReached):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
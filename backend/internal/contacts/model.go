@@ -0,0 +1,99 @@
+// prometheus/backend/internal/contacts/model.go
+package contacts
+
+import (
+	"time"
+)
+
+// Relationship is how a contact or dependent is related to the employee.
+type Relationship string
+
+const (
+	RelationSpouse      Relationship = "spouse"
+	RelationPartner     Relationship = "partner"
+	RelationParent      Relationship = "parent"
+	RelationChild       Relationship = "child"
+	RelationStepchild   Relationship = "stepchild"
+	RelationFosterChild Relationship = "foster_child"
+	RelationSibling     Relationship = "sibling"
+	RelationGrandparent Relationship = "grandparent"
+	RelationGrandchild  Relationship = "grandchild"
+	RelationGuardian    Relationship = "guardian"
+	RelationRelative    Relationship = "relative" // Any other family member
+	RelationFriend      Relationship = "friend"
+	RelationNeighbor    Relationship = "neighbor"
+	RelationOther       Relationship = "other"
+)
+
+// contactRelationships are the relationships an emergency contact may have; anyone can be one.
+var contactRelationships = []Relationship{
+	RelationSpouse, RelationPartner, RelationParent, RelationChild, RelationStepchild, RelationFosterChild,
+	RelationSibling, RelationGrandparent, RelationGrandchild, RelationGuardian, RelationRelative, RelationFriend,
+	RelationNeighbor, RelationOther,
+}
+
+// dependentRelationships are the relationships a dependent may have: family the employee supports, as
+// benefits and tax allowances recognize them.
+var dependentRelationships = []Relationship{
+	RelationSpouse, RelationPartner, RelationParent, RelationChild, RelationStepchild, RelationFosterChild,
+	RelationSibling, RelationGrandparent, RelationGrandchild, RelationRelative,
+}
+
+// EmergencyContact is someone to call when something happens to an employee at work. Contacts are called
+// in order of Priority.
+type EmergencyContact struct {
+	ID             uint         `gorm:"primaryKey" json:"id" example:"3"`
+	OrganizationID *uint        `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint         `gorm:"not null;index" json:"employee_id" example:"12"`
+	Name           string       `gorm:"type:varchar(150);not null" json:"name" example:"Samir Haddad"`
+	Relationship   Relationship `gorm:"type:varchar(20);not null" json:"relationship" example:"spouse"`
+	Phone          string       `gorm:"type:varchar(20);not null" json:"phone" example:"+49301234567"`       // Normalized, see normalizePhone
+	AltPhone       string       `gorm:"type:varchar(20)" json:"alt_phone,omitempty" example:"+491701234567"` // Normalized, see normalizePhone
+	Email          string       `gorm:"type:varchar(255)" json:"email,omitempty" example:"samir@example.com"`
+	Priority       int          `gorm:"not null" json:"priority" example:"1"`                                         // 1 is called first
+	Notes          string       `gorm:"type:varchar(500)" json:"notes,omitempty" example:"Speaks English and Arabic"` // E.g. languages spoken, hours reachable
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// Dependent is a family member an employee supports, for benefits enrollment and tax allowances.
+type Dependent struct {
+	ID             uint         `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID *uint        `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint         `gorm:"not null;index" json:"employee_id" example:"12"`
+	Name           string       `gorm:"type:varchar(150);not null" json:"name" example:"Noor Haddad"`
+	Relationship   Relationship `gorm:"type:varchar(20);not null" json:"relationship" example:"child"`
+	BirthDate      *time.Time   `gorm:"type:date" json:"birth_date,omitempty" example:"2019-05-14T00:00:00Z"`
+	Disabled       bool         `gorm:"not null;default:false" json:"disabled" example:"false"` // Entitles to allowances beyond the usual age limits
+	Student        bool         `gorm:"not null;default:false" json:"student" example:"false"`  // Likewise, for adult children in education
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// Profile is what an employee recorded about their emergency contacts and dependents.
+type Profile struct {
+	EmployeeID        uint               `json:"employee_id" example:"12"`
+	EmergencyContacts []EmergencyContact `json:"emergency_contacts"`
+	Dependents        []Dependent        `json:"dependents"`
+}
+
+// ContactRequest creates or replaces an emergency contact. Phone numbers may be written with spaces,
+// dashes, dots and parentheses, and an international prefix as + or 00.
+type ContactRequest struct {
+	Name         string       `json:"name" binding:"required,max=150" example:"Samir Haddad"`
+	Relationship Relationship `json:"relationship" binding:"required" example:"spouse"`
+	Phone        string       `json:"phone" binding:"required,max=30" example:"+49 30 1234567"`
+	AltPhone     string       `json:"alt_phone,omitempty" binding:"max=30" example:"+49 170 1234567"`
+	Email        string       `json:"email,omitempty" binding:"omitempty,email,max=255" example:"samir@example.com"`
+	Priority     int          `json:"priority,omitempty" binding:"omitempty,min=1" example:"1"` // Defaults to after the existing contacts
+	Notes        string       `json:"notes,omitempty" binding:"max=500" example:"Speaks English and Arabic"`
+}
+
+// DependentRequest creates or replaces a dependent.
+type DependentRequest struct {
+	Name         string       `json:"name" binding:"required,max=150" example:"Noor Haddad"`
+	Relationship Relationship `json:"relationship" binding:"required" example:"child"`
+	BirthDate    string       `json:"birth_date,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2019-05-14"`
+	Disabled     bool         `json:"disabled,omitempty" example:"false"`
+	Student      bool         `json:"student,omitempty" example:"false"`
+}
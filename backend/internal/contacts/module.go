@@ -0,0 +1,80 @@
+// prometheus/backend/internal/contacts/module.go
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the emergency contacts module.
+const ModuleName = "emergency-contacts"
+
+// contactsModule owns the emergency contacts and dependents of employees.
+type contactsModule struct {
+	handler *Handler
+}
+
+// NewModule creates the emergency contacts module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &contactsModule{handler: NewHandler(svc)}
+}
+
+func (m *contactsModule) Name() string { return ModuleName }
+
+func (m *contactsModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *contactsModule) Models() []any {
+	return []any{&EmergencyContact{}, &Dependent{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Employees manage their own contacts and dependents; HR
+// reads them.
+func (m *contactsModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/contacts", routing.Authenticated(), m.handler.Mine)
+	api.POST("/me/emergency-contacts", routing.Authenticated(), m.handler.CreateContact)
+	api.PUT("/me/emergency-contacts/:id", routing.Authenticated(), m.handler.UpdateContact)
+	api.DELETE("/me/emergency-contacts/:id", routing.Authenticated(), m.handler.DeleteContact)
+	api.POST("/me/dependents", routing.Authenticated(), m.handler.CreateDependent)
+	api.PUT("/me/dependents/:id", routing.Authenticated(), m.handler.UpdateDependent)
+	api.DELETE("/me/dependents/:id", routing.Authenticated(), m.handler.DeleteDependent)
+
+	api.GET("/hr/employees/:id/contacts", routing.Policy(), m.handler.ForEmployee)
+}
+
+// PrivacySources implements privacy.Contributor: contacts and dependents are exported, and deleted on
+// anonymization, as they are personal data of other people too.
+func (m *contactsModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{
+		privacy.NewSource("emergency_contacts", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+			var contacts []EmergencyContact
+			if err := db.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(db, userID)).Order("priority, id").Find(&contacts).Error; err != nil {
+				return nil, fmt.Errorf("failed to export emergency contacts: %w", err)
+			}
+			return contacts, nil
+		}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+			if err := tx.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(tx, userID)).Delete(&EmergencyContact{}).Error; err != nil {
+				return fmt.Errorf("failed to delete emergency contacts: %w", err)
+			}
+			return nil
+		}),
+		privacy.NewSource("dependents", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+			var dependents []Dependent
+			if err := db.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(db, userID)).Order("id").Find(&dependents).Error; err != nil {
+				return nil, fmt.Errorf("failed to export dependents: %w", err)
+			}
+			return dependents, nil
+		}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+			if err := tx.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(tx, userID)).Delete(&Dependent{}).Error; err != nil {
+				return fmt.Errorf("failed to delete dependents: %w", err)
+			}
+			return nil
+		}),
+	}
+}
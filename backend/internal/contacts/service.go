@@ -0,0 +1,400 @@
+// prometheus/backend/internal/contacts/service.go
+package contacts
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxContacts is the most emergency contacts an employee may record.
+	maxContacts = 5
+	// maxDependents is the most dependents an employee may record.
+	maxDependents = 20
+)
+
+var (
+	// ErrInvalidContact is returned for emergency contacts and dependents that fail validation.
+	ErrInvalidContact = errors.New("invalid contact")
+	// ErrLimitReached is returned when adding a contact or dependent beyond maxContacts or maxDependents.
+	ErrLimitReached = errors.New("too many entries")
+	// ErrNoEmployee is returned when a user without an employee record manages their contacts.
+	ErrNoEmployee = errors.New("you don't have an employee record")
+)
+
+// Service handles the emergency contacts and dependents employees record on their profile. Employees
+// manage their own; HR reads anyone's. orgID scopes every call to one organization's employees
+// (nil = default organization). Changes are audited without the names and numbers themselves, which would
+// otherwise outlive the user's anonymization in the immutable audit trail.
+type Service interface {
+	// Mine returns the user's emergency contacts and dependents.
+	Mine(orgID *uint, userID uint) (*Profile, error)
+	// ForEmployee returns an employee's emergency contacts and dependents, for HR.
+	ForEmployee(orgID *uint, employeeID uint) (*Profile, error)
+	CreateContact(actor audit.Actor, orgID *uint, userID uint, req ContactRequest) (*EmergencyContact, error)
+	UpdateContact(actor audit.Actor, orgID *uint, userID, id uint, req ContactRequest) (*EmergencyContact, error)
+	DeleteContact(actor audit.Actor, orgID *uint, userID, id uint) error
+	CreateDependent(actor audit.Actor, orgID *uint, userID uint, req DependentRequest) (*Dependent, error)
+	UpdateDependent(actor audit.Actor, orgID *uint, userID, id uint, req DependentRequest) (*Dependent, error)
+	DeleteDependent(actor audit.Actor, orgID *uint, userID, id uint) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Mine(orgID *uint, userID uint) (*Profile, error) {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(orgID, employeeID)
+}
+
+func (s *service) ForEmployee(orgID *uint, employeeID uint) (*Profile, error) {
+	if _, err := s.employees.Get(orgID, employeeID); err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return s.profile(orgID, employeeID)
+}
+
+func (s *service) CreateContact(actor audit.Actor, orgID *uint, userID uint, req ContactRequest) (*EmergencyContact, error) {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	contact := EmergencyContact{OrganizationID: orgID, EmployeeID: employeeID}
+	if err := applyContact(&contact, req); err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEmployee(tx, employeeID); err != nil {
+			return err
+		}
+		var count int64
+		var last *int
+		if err := tx.Model(&EmergencyContact{}).Where("employee_id = ?", employeeID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count emergency contacts: %w", err)
+		}
+		if count >= maxContacts {
+			return fmt.Errorf("%w: at most %d emergency contacts can be recorded", ErrLimitReached, maxContacts)
+		}
+		if contact.Priority == 0 {
+			if err := tx.Model(&EmergencyContact{}).Where("employee_id = ?", employeeID).
+				Select("MAX(priority)").Scan(&last).Error; err != nil {
+				return fmt.Errorf("failed to find the last emergency contact: %w", err)
+			}
+			contact.Priority = 1
+			if last != nil {
+				contact.Priority = *last + 1
+			}
+		}
+		if err := tx.Create(&contact).Error; err != nil {
+			return fmt.Errorf("failed to create emergency contact: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "emergency_contact.create", EntityType: "emergency_contact", EntityID: fmt.Sprintf("%d", contact.ID),
+			After: contactSummary(contact),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (s *service) UpdateContact(actor audit.Actor, orgID *uint, userID, id uint, req ContactRequest) (*EmergencyContact, error) {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var contact EmergencyContact
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("employee_id = ?", employeeID).
+			First(&contact, id).Error; err != nil {
+			return err
+		}
+		before := contactSummary(contact)
+		priority := contact.Priority
+		if err := applyContact(&contact, req); err != nil {
+			return err
+		}
+		if contact.Priority == 0 {
+			contact.Priority = priority
+		}
+		if err := tx.Save(&contact).Error; err != nil {
+			return fmt.Errorf("failed to update emergency contact %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "emergency_contact.update", EntityType: "emergency_contact", EntityID: fmt.Sprintf("%d", id),
+			Before: before, After: contactSummary(contact),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (s *service) DeleteContact(actor audit.Actor, orgID *uint, userID, id uint) error {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var contact EmergencyContact
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("employee_id = ?", employeeID).
+			First(&contact, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&contact).Error; err != nil {
+			return fmt.Errorf("failed to delete emergency contact %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "emergency_contact.delete", EntityType: "emergency_contact", EntityID: fmt.Sprintf("%d", id),
+			Before: contactSummary(contact),
+		})
+	})
+}
+
+func (s *service) CreateDependent(actor audit.Actor, orgID *uint, userID uint, req DependentRequest) (*Dependent, error) {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	dependent := Dependent{OrganizationID: orgID, EmployeeID: employeeID}
+	if err := applyDependent(&dependent, req); err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEmployee(tx, employeeID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&Dependent{}).Where("employee_id = ?", employeeID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count dependents: %w", err)
+		}
+		if count >= maxDependents {
+			return fmt.Errorf("%w: at most %d dependents can be recorded", ErrLimitReached, maxDependents)
+		}
+		if err := tx.Create(&dependent).Error; err != nil {
+			return fmt.Errorf("failed to create dependent: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "dependent.create", EntityType: "dependent", EntityID: fmt.Sprintf("%d", dependent.ID),
+			After: dependentSummary(dependent),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dependent, nil
+}
+
+func (s *service) UpdateDependent(actor audit.Actor, orgID *uint, userID, id uint, req DependentRequest) (*Dependent, error) {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var dependent Dependent
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("employee_id = ?", employeeID).
+			First(&dependent, id).Error; err != nil {
+			return err
+		}
+		before := dependentSummary(dependent)
+		if err := applyDependent(&dependent, req); err != nil {
+			return err
+		}
+		if err := tx.Save(&dependent).Error; err != nil {
+			return fmt.Errorf("failed to update dependent %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "dependent.update", EntityType: "dependent", EntityID: fmt.Sprintf("%d", id),
+			Before: before, After: dependentSummary(dependent),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dependent, nil
+}
+
+func (s *service) DeleteDependent(actor audit.Actor, orgID *uint, userID, id uint) error {
+	employeeID, err := s.employeeOf(orgID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var dependent Dependent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("employee_id = ?", employeeID).
+			First(&dependent, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&dependent).Error; err != nil {
+			return fmt.Errorf("failed to delete dependent %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "dependent.delete", EntityType: "dependent", EntityID: fmt.Sprintf("%d", id),
+			Before: dependentSummary(dependent),
+		})
+	})
+}
+
+// profile loads an employee's contacts, in the order they are called, and dependents.
+func (s *service) profile(orgID *uint, employeeID uint) (*Profile, error) {
+	profile := Profile{EmployeeID: employeeID, EmergencyContacts: []EmergencyContact{}, Dependents: []Dependent{}}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Order("priority, id").
+		Find(&profile.EmergencyContacts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list emergency contacts: %w", err)
+	}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Order("birth_date NULLS LAST, id").
+		Find(&profile.Dependents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dependents: %w", err)
+	}
+	return &profile, nil
+}
+
+// employeeOf finds the employee record of a user.
+func (s *service) employeeOf(orgID *uint, userID uint) (uint, error) {
+	var ids []uint
+	if err := utils.OrgScope(s.db.Model(&employee.Employee{}), orgID).Where("user_id = ?", userID).Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to find the employee record of user %d: %w", userID, err)
+	}
+	if len(ids) == 0 {
+		return 0, ErrNoEmployee
+	}
+	return ids[0], nil
+}
+
+// applyContact validates req and copies it onto contact, normalizing the phone numbers.
+func applyContact(contact *EmergencyContact, req ContactRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidContact)
+	}
+	if !slices.Contains(contactRelationships, req.Relationship) {
+		return fmt.Errorf("%w: unknown relationship %q", ErrInvalidContact, req.Relationship)
+	}
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return fmt.Errorf("%w: phone: %v", ErrInvalidContact, err)
+	}
+	altPhone := ""
+	if strings.TrimSpace(req.AltPhone) != "" {
+		if altPhone, err = normalizePhone(req.AltPhone); err != nil {
+			return fmt.Errorf("%w: alt_phone: %v", ErrInvalidContact, err)
+		}
+		if altPhone == phone {
+			return fmt.Errorf("%w: alt_phone is the same number as phone", ErrInvalidContact)
+		}
+	}
+	contact.Name, contact.Relationship, contact.Phone, contact.AltPhone = name, req.Relationship, phone, altPhone
+	contact.Email, contact.Priority, contact.Notes = strings.TrimSpace(req.Email), req.Priority, strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// applyDependent validates req and copies it onto dependent.
+func applyDependent(dependent *Dependent, req DependentRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidContact)
+	}
+	if !slices.Contains(dependentRelationships, req.Relationship) {
+		return fmt.Errorf("%w: unknown relationship %q for a dependent", ErrInvalidContact, req.Relationship)
+	}
+	var birthDate *time.Time
+	if req.BirthDate != "" {
+		date, err := time.Parse("2006-01-02", req.BirthDate)
+		if err != nil {
+			return fmt.Errorf("%w: birth_date must be YYYY-MM-DD", ErrInvalidContact)
+		}
+		now := clock.Now().UTC()
+		if date.After(now) || date.Year() < now.Year()-130 {
+			return fmt.Errorf("%w: birth_date %s is not plausible", ErrInvalidContact, req.BirthDate)
+		}
+		birthDate = &date
+	}
+	dependent.Name, dependent.Relationship, dependent.BirthDate = name, req.Relationship, birthDate
+	dependent.Disabled, dependent.Student = req.Disabled, req.Student
+	return nil
+}
+
+// normalizePhone checks a phone number and reduces it to its digits, with a leading + for international
+// numbers (00 counts as +). Spaces, dashes, dots and parentheses are allowed as separators. E.164 caps
+// numbers at 15 digits; fewer than 5 can't be dialled from outside a private exchange.
+func normalizePhone(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	var digits strings.Builder
+	international := false
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("%q may only hold digits, a leading + and the separators space - . ( )", raw)
+		}
+	}
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		number, international = number[2:], true
+	}
+	if international && strings.HasPrefix(number, "0") {
+		return "", fmt.Errorf("%q has no country code after the international prefix", raw)
+	}
+	if len(number) < 5 || len(number) > 15 {
+		return "", fmt.Errorf("%q must have between 5 and 15 digits", raw)
+	}
+	if international {
+		return "+" + number, nil
+	}
+	return number, nil
+}
+
+// contactSummary is what the audit trail records of a contact: no names or numbers.
+func contactSummary(contact EmergencyContact) map[string]interface{} {
+	return map[string]interface{}{
+		"employee_id": contact.EmployeeID, "relationship": contact.Relationship, "priority": contact.Priority,
+		"has_alt_phone": contact.AltPhone != "", "has_email": contact.Email != "",
+	}
+}
+
+// dependentSummary is what the audit trail records of a dependent: no names or birth dates.
+func dependentSummary(dependent Dependent) map[string]interface{} {
+	return map[string]interface{}{
+		"employee_id": dependent.EmployeeID, "relationship": dependent.Relationship,
+		"disabled": dependent.Disabled, "student": dependent.Student,
+	}
+}
+
+// lockEmployee serializes changes to one employee's contacts and dependents, so limits hold under
+// concurrent requests.
+func lockEmployee(tx *gorm.DB, employeeID uint) error {
+	var locked employee.Employee
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, employeeID).Error; err != nil {
+		return fmt.Errorf("failed to lock employee %d: %w", employeeID, err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// prometheus/backend/internal/contract/handler.go
+package contract
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for employment contracts.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's contracts, latest start first, or those expiring soonest first.
+// @Summary List employment contracts
+// @Tags Contracts
+// @Produce json
+// @Param type query string false "Type" Enums(permanent, fixed_term, probation, contractor, internship)
+// @Param employee_id query int false "Employee ID"
+// @Param expiring_within query int false "Only fixed-term contracts not renewed ending within this many days"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/contracts [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Type: Type(c.Query("type"))}
+	switch filter.Type {
+	case "", TypePermanent, TypeFixedTerm, TypeProbation, TypeContractor, TypeInternship:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid type parameter")
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	if raw := c.Query("expiring_within"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 || days > 3650 {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid expiring_within parameter")
+			return
+		}
+		filter.ExpiringWithin = &days
+	}
+	page := utils.ParsePagination(c)
+	contracts, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Contracts fetched successfully", page.Response(contracts, total))
+}
+
+// Get returns a contract.
+// @Summary Get an employment contract
+// @Tags Contracts
+// @Produce json
+// @Param id path int true "Contract ID"
+// @Success 200 {object} Contract
+// @Failure 404 {object} utils.ErrorResponse "Contract not found"
+// @Router /hr/contracts/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	contract, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, contract.UpdatedAt, contract.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Contract fetched successfully", contract)
+}
+
+// EmployeeContracts returns an employee's contracts, latest first.
+// @Summary List an employee's contracts
+// @Tags Contracts
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} Contract
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/contracts [get]
+func (h *Handler) EmployeeContracts(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	contracts, err := h.service.EmployeeContracts(utils.OrganizationFromContext(c), employeeID)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Contracts fetched successfully", contracts)
+}
+
+// Mine returns the caller's own contracts, latest first.
+// @Summary List my contracts
+// @Tags Contracts
+// @Produce json
+// @Success 200 {array} Contract
+// @Router /me/contracts [get]
+func (h *Handler) Mine(c *gin.Context) {
+	contracts, err := h.service.Mine(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Contracts fetched successfully", contracts)
+}
+
+// Create records a contract of an employee.
+// @Summary Record an employment contract
+// @Description Fixed-term contracts need an end date. HR is notified reminder_days before it, unless the
+// @Description contract was renewed by then.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param contract body Request true "Contract"
+// @Success 201 {object} Contract
+// @Failure 400 {object} utils.ErrorResponse "Invalid contract"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Overlaps another contract of the employee"
+// @Router /hr/employees/{id}/contracts [post]
+func (h *Handler) Create(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	contract, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), employeeID, req)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, contract.UpdatedAt, contract.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Contract recorded successfully", contract)
+}
+
+// Update replaces a contract's fields.
+// @Summary Update an employment contract
+// @Description Changing the type, end date or reminder days of a contract whose reminder was sent sends it
+// @Description again when due.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "Contract ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param contract body Request true "Contract"
+// @Success 200 {object} Contract
+// @Failure 400 {object} utils.ErrorResponse "Invalid contract"
+// @Failure 404 {object} utils.ErrorResponse "Contract not found"
+// @Failure 409 {object} utils.ErrorResponse "Overlaps another contract of the employee"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/contracts/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	contract, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, contract.UpdatedAt, contract.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Contract updated successfully", contract)
+}
+
+// Renew records the contract following one, starting the day after it ends.
+// @Summary Renew an employment contract
+// @Description The renewed contract no longer triggers an expiry reminder.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "Contract ID"
+// @Param renewal body RenewRequest true "Renewal"
+// @Success 201 {object} Contract
+// @Failure 400 {object} utils.ErrorResponse "Invalid renewal or contract without an end date"
+// @Failure 404 {object} utils.ErrorResponse "Contract not found"
+// @Failure 409 {object} utils.ErrorResponse "Already renewed, or overlaps a later contract"
+// @Router /hr/contracts/{id}/renew [post]
+func (h *Handler) Renew(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	contract, err := h.service.Renew(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendContractError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, contract.UpdatedAt, contract.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Contract renewed successfully", contract)
+}
+
+func sendContractError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidContract):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrOverlap), errors.Is(err, ErrRenewed):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The contract was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
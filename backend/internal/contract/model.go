@@ -0,0 +1,69 @@
+// prometheus/backend/internal/contract/model.go
+package contract
+
+import (
+	"time"
+)
+
+// Type is the kind of an employment contract.
+type Type string
+
+const (
+	TypePermanent  Type = "permanent"  // Open-ended
+	TypeFixedTerm  Type = "fixed_term" // Ends on EndOn unless renewed; HR is reminded ahead of it
+	TypeProbation  Type = "probation"
+	TypeContractor Type = "contractor"
+	TypeInternship Type = "internship"
+)
+
+// Contract is an employment contract of an employee. An employee's contracts don't overlap; a renewal is
+// a new contract starting after the one it renews, which then links to it through RenewedByID.
+type Contract struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"14"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint       `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName    string     `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Type           Type       `gorm:"type:varchar(20);not null;index" json:"type" example:"fixed_term"`
+	Reference      string     `gorm:"type:varchar(100)" json:"reference,omitempty" example:"HR-2026-0142"`
+	StartOn        time.Time  `gorm:"type:date;not null" json:"start_on" example:"2026-01-01T00:00:00Z"`
+	EndOn          *time.Time `gorm:"type:date;index" json:"end_on,omitempty" example:"2026-12-31T00:00:00Z"` // Last day; required for fixed-term contracts
+	ReminderDays   int        `gorm:"not null;default:30" json:"reminder_days" example:"30"`                  // Days before EndOn HR is reminded of a fixed-term contract expiring
+	RemindedAt     *time.Time `json:"reminded_at,omitempty"`                                                  // Cleared when the type, EndOn or ReminderDays change
+	RenewalOf      *uint      `gorm:"index" json:"renewal_of,omitempty" example:"9"`                          // Contract this one renews
+	RenewedByID    *uint      `gorm:"index" json:"renewed_by_id,omitempty" example:"21"`                      // Contract renewing this one
+	Notes          string     `gorm:"type:varchar(2000)" json:"notes,omitempty"`
+	CreatedBy      *uint      `json:"created_by,omitempty" example:"4"`              // User ID
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName avoids clashing with other kinds of contracts.
+func (Contract) TableName() string { return "employment_contracts" }
+
+// Request records a contract or replaces its fields. The employee can't be changed afterwards.
+type Request struct {
+	Type         Type   `json:"type" binding:"required,oneof=permanent fixed_term probation contractor internship" example:"fixed_term"`
+	Reference    string `json:"reference,omitempty" binding:"max=100" example:"HR-2026-0142"`
+	StartOn      string `json:"start_on" binding:"required,datetime=2006-01-02" example:"2026-01-01"`
+	EndOn        string `json:"end_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-12-31"`
+	ReminderDays *int   `json:"reminder_days,omitempty" binding:"omitempty,min=1,max=365" example:"30"` // Defaults to the deployment's CONTRACT_REMINDER_DAYS
+	Notes        string `json:"notes,omitempty" binding:"max=2000"`
+}
+
+// RenewRequest renews a contract with one starting the day after it ends.
+type RenewRequest struct {
+	Type         Type   `json:"type" binding:"required,oneof=permanent fixed_term probation contractor internship" example:"fixed_term"`
+	Reference    string `json:"reference,omitempty" binding:"max=100" example:"HR-2027-0007"`
+	EndOn        string `json:"end_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2027-12-31"`
+	ReminderDays *int   `json:"reminder_days,omitempty" binding:"omitempty,min=1,max=365" example:"30"`
+	Notes        string `json:"notes,omitempty" binding:"max=2000"`
+}
+
+// Filter narrows a contract listing.
+type Filter struct {
+	Type       Type
+	EmployeeID *uint
+	// ExpiringWithin lists fixed-term contracts not renewed that end within this many days, soonest first
+	ExpiringWithin *int
+}
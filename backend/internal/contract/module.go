@@ -0,0 +1,81 @@
+// prometheus/backend/internal/contract/module.go
+package contract
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the contracts module.
+const ModuleName = "contracts"
+
+// remindInterval is how often due reminders are sent. Hourly sends them within the first hour of the day
+// they fall due.
+const remindInterval = time.Hour
+
+// contractModule owns employment contracts and the reminders of fixed-term ones expiring.
+type contractModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the contracts module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &contractModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *contractModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. A reminder still unsent a day after it fell due means HR
+// may not hear of a contract expiring, typically because the organization has no active HR user.
+func (m *contractModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("reminding", func(ctx context.Context) module.HealthResult {
+			var overdue int64
+			if err := dueReminders(m.db.WithContext(ctx).Model(&Contract{}), today().AddDate(0, 0, -1)).
+				Where("end_on >= ?", today()).Count(&overdue).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if overdue > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"overdue": float64(overdue)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *contractModule) Models() []any {
+	return []any{&Contract{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *contractModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobRemind, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		reminded, failed, err := m.service.Remind(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"reminded": reminded, "failed": failed}, nil
+	})
+	q.Every(JobRemind, remindInterval)
+}
+
+// RegisterRoutes implements routing.Contributor. HR keeps the contracts; employees see their own under /me.
+func (m *contractModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/contracts", routing.Authenticated(), m.handler.Mine)
+
+	api.GET("/hr/contracts", routing.Policy(), m.handler.List)
+	api.GET("/hr/contracts/:id", routing.Policy(), m.handler.Get)
+	api.PUT("/hr/contracts/:id", routing.Policy(), m.handler.Update)
+	api.POST("/hr/contracts/:id/renew", routing.Policy(), m.handler.Renew)
+	api.GET("/hr/employees/:id/contracts", routing.Policy(), m.handler.EmployeeContracts)
+	api.POST("/hr/employees/:id/contracts", routing.Policy(), m.handler.Create)
+}
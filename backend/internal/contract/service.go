@@ -0,0 +1,466 @@
+// prometheus/backend/internal/contract/service.go
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRemind is the recurring job type that reminds HR of fixed-term contracts about to expire.
+const JobRemind = "contracts.remind"
+
+// hrRole is the role reminded of expiring contracts.
+const hrRole = "hr"
+
+var (
+	// ErrInvalidContract is returned for contracts that fail validation.
+	ErrInvalidContract = errors.New("invalid contract")
+	// ErrOverlap is returned when a contract would overlap another contract of the same employee.
+	ErrOverlap = errors.New("the contract overlaps another contract of the employee")
+	// ErrRenewed is returned when renewing a contract that was already renewed.
+	ErrRenewed = errors.New("the contract was already renewed")
+)
+
+// Service keeps employees' employment contracts and reminds HR ahead of fixed-term contracts expiring.
+// orgID scopes every call to one organization's employees and contracts (nil = default organization).
+type Service interface {
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Contract, int64, error)
+	Get(orgID *uint, id uint) (*Contract, error)
+	// EmployeeContracts returns an employee's contracts, latest first.
+	EmployeeContracts(orgID *uint, employeeID uint) ([]Contract, error)
+	// Mine returns the contracts of the user's own employee record, latest first.
+	Mine(orgID *uint, userID uint) ([]Contract, error)
+	Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Contract, error)
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Contract, error)
+	// Renew records the contract following one that has an end date, starting the day after it.
+	Renew(actor audit.Actor, orgID *uint, id uint, req RenewRequest) (*Contract, error)
+	// Remind notifies HR of every fixed-term contract not renewed whose reminder is due.
+	Remind(ctx context.Context) (reminded, failed int, err error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db           *gorm.DB
+	employees    employee.Service
+	auditor      audit.Service
+	reminderDays int
+}
+
+// NewService creates a new instance of Service. reminderDays is how many days ahead HR is reminded of a
+// fixed-term contract expiring, unless the contract says otherwise.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service, reminderDays int) Service {
+	return &service{db: db, employees: employees, auditor: auditor, reminderDays: reminderDays}
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Contract, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Contract{}), orgID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	order := "start_on DESC, id DESC"
+	if filter.ExpiringWithin != nil {
+		t := today()
+		query = query.Where("type = ? AND renewed_by_id IS NULL AND end_on BETWEEN ? AND ?",
+			TypeFixedTerm, t, t.AddDate(0, 0, *filter.ExpiringWithin))
+		order = "end_on, id"
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count contracts: %w", err)
+	}
+	var contracts []Contract
+	if err := query.Order(order).Scopes(page.Scope).Find(&contracts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list contracts: %w", err)
+	}
+	if err := s.named(orgID, contracts); err != nil {
+		return nil, 0, err
+	}
+	return contracts, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Contract, error) {
+	var contract Contract
+	if err := utils.OrgScope(s.db, orgID).First(&contract, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	contracts := []Contract{contract}
+	if err := s.named(orgID, contracts); err != nil {
+		return nil, err
+	}
+	return &contracts[0], nil
+}
+
+func (s *service) EmployeeContracts(orgID *uint, employeeID uint) ([]Contract, error) {
+	if _, err := s.employees.Get(orgID, employeeID); err != nil {
+		return nil, err
+	}
+	return s.history(orgID, employeeID)
+}
+
+func (s *service) Mine(orgID *uint, userID uint) ([]Contract, error) {
+	var ids []uint
+	if err := utils.OrgScope(s.db.Model(&employee.Employee{}), orgID).Where("user_id = ?", userID).Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find the employee record of user %d: %w", userID, err)
+	}
+	if len(ids) == 0 {
+		return []Contract{}, nil
+	}
+	return s.history(orgID, ids[0])
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Contract, error) {
+	if _, err := s.employees.Get(orgID, employeeID); err != nil {
+		return nil, err
+	}
+	contract := Contract{OrganizationID: orgID, EmployeeID: employeeID, CreatedBy: actor.UserID}
+	if err := s.apply(&contract, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOverlap(tx, &contract); err != nil {
+			return err
+		}
+		if err := tx.Create(&contract).Error; err != nil {
+			return fmt.Errorf("failed to record contract: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "contract.create", EntityType: "contract", EntityID: fmt.Sprintf("%d", contract.ID), After: contract,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, contract.ID)
+}
+
+// Update asks for a new reminder when the end date, the type or the reminder days change.
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Contract, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockContract(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		contract := *before
+		if err := s.apply(&contract, req); err != nil {
+			return err
+		}
+		if contract.RenewedByID != nil && contract.EndOn == nil {
+			return fmt.Errorf("%w: a renewed contract needs an end date", ErrInvalidContract)
+		}
+		if err := checkOverlap(tx, &contract); err != nil {
+			return err
+		}
+		if contract.Type != before.Type || contract.ReminderDays != before.ReminderDays || !sameDate(contract.EndOn, before.EndOn) {
+			contract.RemindedAt = nil
+		}
+		if err := utils.UpdateWithVersion(tx, &Contract{}, id, expectedVersion, map[string]interface{}{
+			"type":          contract.Type,
+			"reference":     contract.Reference,
+			"start_on":      contract.StartOn,
+			"end_on":        contract.EndOn,
+			"reminder_days": contract.ReminderDays,
+			"reminded_at":   contract.RemindedAt,
+			"notes":         contract.Notes,
+		}); err != nil {
+			return err
+		}
+		var updated Contract
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload contract %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "contract.update", EntityType: "contract", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+func (s *service) Renew(actor audit.Actor, orgID *uint, id uint, req RenewRequest) (*Contract, error) {
+	var renewal Contract
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		previous, err := lockContract(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if previous.RenewedByID != nil {
+			return ErrRenewed
+		}
+		if previous.EndOn == nil {
+			return fmt.Errorf("%w: only a contract with an end date can be renewed", ErrInvalidContract)
+		}
+		renewal = Contract{
+			OrganizationID: previous.OrganizationID,
+			EmployeeID:     previous.EmployeeID,
+			RenewalOf:      &previous.ID,
+			CreatedBy:      actor.UserID,
+		}
+		if err := s.apply(&renewal, Request{
+			Type: req.Type, Reference: req.Reference, StartOn: previous.EndOn.AddDate(0, 0, 1).Format("2006-01-02"),
+			EndOn: req.EndOn, ReminderDays: req.ReminderDays, Notes: req.Notes,
+		}); err != nil {
+			return err
+		}
+		if err := checkOverlap(tx, &renewal); err != nil {
+			return err
+		}
+		if err := tx.Create(&renewal).Error; err != nil {
+			return fmt.Errorf("failed to record the renewal: %w", err)
+		}
+		if err := tx.Model(&Contract{}).Where("id = ?", previous.ID).Updates(map[string]interface{}{
+			"renewed_by_id": renewal.ID,
+			"version":       gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to link contract %d to its renewal: %w", previous.ID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "contract.renew", EntityType: "contract", EntityID: fmt.Sprintf("%d", previous.ID), Before: previous, After: renewal,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, renewal.ID)
+}
+
+// Remind works through due reminders one transaction each, so a contract whose organization has no HR
+// user to remind doesn't hold up the others. It stays due and is tried again on the next run; the module's
+// health check reports it as overdue meanwhile.
+func (s *service) Remind(ctx context.Context) (int, int, error) {
+	var due []uint
+	if err := dueReminders(s.db.WithContext(ctx).Model(&Contract{}), today()).Order("end_on, id").
+		Pluck("id", &due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to find due contract reminders: %w", err)
+	}
+	var reminded, failed int
+	for _, id := range due {
+		if err := ctx.Err(); err != nil {
+			return reminded, failed, err
+		}
+		done, err := s.remind(ctx, id)
+		if err != nil {
+			log.Printf("Failed to remind HR of contract %d expiring: %v", id, err)
+			failed++
+			continue
+		}
+		if done {
+			reminded++
+		}
+	}
+	return reminded, failed, nil
+}
+
+// remind notifies the HR users of a due contract's organization, reporting false when the contract was
+// taken by another instance or is no longer due.
+func (s *service) remind(ctx context.Context, id uint) (bool, error) {
+	var done bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		t := today()
+		var contract Contract
+		err := dueReminders(tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}), t).First(&contract, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load contract: %w", err)
+		}
+		recipients, err := hrUsers(tx, contract.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			return errors.New("the organization has no active HR user")
+		}
+		names, err := s.employees.DisplayNames(contract.OrganizationID, employee.UsageDirectory, []uint{contract.EmployeeID})
+		if err != nil {
+			return err
+		}
+		name := names[contract.EmployeeID].Text
+		if name == "" {
+			name = fmt.Sprintf("Employee %d", contract.EmployeeID)
+		}
+		endOn := contract.EndOn.Format("2006-01-02")
+		what := "The fixed-term contract of " + name
+		if contract.Reference != "" {
+			what = fmt.Sprintf("The fixed-term contract %s of %s", contract.Reference, name)
+		}
+		body := fmt.Sprintf("%s ends on %s, in %d day(s). Renew it or plan their departure.",
+			what, endOn, int(contract.EndOn.Sub(t).Hours()/24))
+		for _, userID := range recipients {
+			if err := notification.CreateTx(tx, notification.Notice{
+				UserID:         userID,
+				OrganizationID: contract.OrganizationID,
+				Category:       "contracts.expiring",
+				Subject:        fmt.Sprintf("%s's contract ends on %s", name, endOn),
+				Body:           body,
+				Link:           fmt.Sprintf("/hr/contracts/%d", contract.ID),
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&Contract{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"reminded_at": clock.Now().UTC(),
+			"version":     gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark contract %d reminded: %w", id, err)
+		}
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// apply validates req onto contract.
+func (s *service) apply(contract *Contract, req Request) error {
+	startOn, err := time.Parse("2006-01-02", req.StartOn)
+	if err != nil {
+		return fmt.Errorf("%w: start_on must be a date", ErrInvalidContract)
+	}
+	var endOn *time.Time
+	if req.EndOn != "" {
+		date, err := time.Parse("2006-01-02", req.EndOn)
+		if err != nil {
+			return fmt.Errorf("%w: end_on must be a date", ErrInvalidContract)
+		}
+		if date.Before(startOn) {
+			return fmt.Errorf("%w: the contract can't end before it starts", ErrInvalidContract)
+		}
+		endOn = &date
+	}
+	if req.Type == TypeFixedTerm && endOn == nil {
+		return fmt.Errorf("%w: a fixed-term contract needs an end date", ErrInvalidContract)
+	}
+	contract.Type = req.Type
+	contract.Reference = strings.TrimSpace(req.Reference)
+	contract.StartOn = startOn
+	contract.EndOn = endOn
+	contract.ReminderDays = s.reminderDays
+	if req.ReminderDays != nil {
+		contract.ReminderDays = *req.ReminderDays
+	}
+	contract.Notes = strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// history returns an employee's contracts, latest first.
+func (s *service) history(orgID *uint, employeeID uint) ([]Contract, error) {
+	var contracts []Contract
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Order("start_on DESC, id DESC").
+		Find(&contracts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list the contracts of employee %d: %w", employeeID, err)
+	}
+	if err := s.named(orgID, contracts); err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// named fills in the display names of contracts' employees.
+func (s *service) named(orgID *uint, contracts []Contract) error {
+	if len(contracts) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(contracts))
+	for i, c := range contracts {
+		ids[i] = c.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range contracts {
+		contracts[i].DisplayName = names[contracts[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// checkOverlap refuses a contract overlapping another contract of the same employee. Locking the employee
+// serializes concurrent changes to their contracts.
+func checkOverlap(tx *gorm.DB, contract *Contract) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&employee.Employee{}, contract.EmployeeID).Error; err != nil {
+		return err
+	}
+	query := tx.Model(&Contract{}).Where("employee_id = ? AND (end_on IS NULL OR end_on >= ?)", contract.EmployeeID, contract.StartOn)
+	if contract.EndOn != nil {
+		query = query.Where("start_on <= ?", *contract.EndOn)
+	}
+	if contract.ID != 0 {
+		query = query.Where("id <> ?", contract.ID)
+	}
+	var overlapping []uint
+	if err := query.Limit(1).Pluck("id", &overlapping).Error; err != nil {
+		return fmt.Errorf("failed to check the contracts of employee %d: %w", contract.EmployeeID, err)
+	}
+	if len(overlapping) > 0 {
+		return fmt.Errorf("%w (contract %d)", ErrOverlap, overlapping[0])
+	}
+	return nil
+}
+
+// dueReminders restricts a query to fixed-term contracts not renewed nor reminded yet, ending within their
+// reminder days of t and not ended before it.
+func dueReminders(db *gorm.DB, t time.Time) *gorm.DB {
+	return db.Where("type = ? AND renewed_by_id IS NULL AND reminded_at IS NULL AND end_on >= ? AND end_on - reminder_days <= ?",
+		TypeFixedTerm, t, t)
+}
+
+// hrUsers returns the active users of the organization holding the HR role.
+func hrUsers(tx *gorm.DB, orgID *uint) ([]uint, error) {
+	query := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", hrRole).
+		Where("user_roles.expires_at IS NULL OR user_roles.expires_at > ?", clock.Now().UTC())
+	if orgID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	var ids []uint
+	if err := query.Pluck("users.id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find HR users: %w", err)
+	}
+	return ids, nil
+}
+
+// sameDate reports whether two optional dates are equal.
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// lockContract loads a contract for update.
+func lockContract(tx *gorm.DB, orgID *uint, id uint) (*Contract, error) {
+	var contract Contract
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&contract, id).Error; err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// today is the current UTC date. Reminders are due once it is within a contract's reminder days of its end.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,177 @@
+// prometheus/backend/internal/customfield/handler.go
+package customfield
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for custom fields.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the custom fields of the caller's organization.
+// @Summary List custom fields
+// @Tags Custom Fields
+// @Produce json
+// @Success 200 {array} Definition
+// @Router /admin/custom-fields [get]
+func (h *Handler) List(c *gin.Context) {
+	defs, err := h.service.List(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom fields fetched successfully", defs)
+}
+
+// Create adds a custom field to the caller's organization.
+// @Summary Create a custom field
+// @Description Types: text (max_length), number (min, max), boolean, date (YYYY-MM-DD) and select (options).
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param field body DefinitionRequest true "Field definition"
+// @Success 201 {object} Definition
+// @Failure 400 {object} utils.ErrorResponse "Invalid definition"
+// @Failure 409 {object} utils.ErrorResponse "Key already used"
+// @Router /admin/custom-fields [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req DefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	def, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendFieldError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Custom field created successfully", def)
+}
+
+// Update replaces a custom field's settings.
+// @Summary Update a custom field
+// @Description Key and type can't be changed. Existing values that no longer fit are rejected on their next save.
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param id path int true "Custom field ID"
+// @Param field body DefinitionRequest true "Field definition"
+// @Success 200 {object} Definition
+// @Failure 400 {object} utils.ErrorResponse "Invalid definition"
+// @Failure 404 {object} utils.ErrorResponse "Custom field not found"
+// @Router /admin/custom-fields/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	def, err := h.service.Update(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendFieldError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom field updated successfully", def)
+}
+
+// Delete removes a custom field and all users' values of it.
+// @Summary Delete a custom field
+// @Tags Custom Fields
+// @Produce json
+// @Param id path int true "Custom field ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Custom field not found"
+// @Router /admin/custom-fields/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendFieldError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom field deleted successfully", nil)
+}
+
+// GetValues returns a user's custom field values.
+// @Summary Get a user's custom field values
+// @Tags Custom Fields
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/custom-fields [get]
+func (h *Handler) GetValues(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	values, err := h.service.Values(utils.OrganizationFromContext(c), userID)
+	if err != nil {
+		sendFieldError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom field values fetched successfully", values)
+}
+
+// SetValues replaces a user's custom field values.
+// @Summary Set a user's custom field values
+// @Description Keys are field keys; omitted or null fields are cleared, which fails for required fields.
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param values body map[string]interface{} true "Values by field key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.ErrorResponse "Unknown field or invalid value"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Router /admin/users/{id}/custom-fields [put]
+func (h *Handler) SetValues(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var values map[string]interface{}
+	if err := c.ShouldBindJSON(&values); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	stored, err := h.service.SetValues(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, values)
+	if err != nil {
+		sendFieldError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom field values saved successfully", stored)
+}
+
+// sendFieldError maps service errors to HTTP status codes.
+func sendFieldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidDefinition), errors.Is(err, ErrInvalidValue):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrKeyTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,52 @@
+// prometheus/backend/internal/customfield/model.go
+package customfield
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Type is the value type of a custom field.
+type Type string
+
+const (
+	TypeText    Type = "text"
+	TypeNumber  Type = "number"
+	TypeBoolean Type = "boolean"
+	TypeDate    Type = "date"   // "YYYY-MM-DD"
+	TypeSelect  Type = "select" // One of Options
+)
+
+// Definition is a company-specific attribute of users, e.g. a badge number or shirt size. Values live in
+// the users' custom_fields JSON column under the definition's key, so adding a field needs no migration.
+type Definition struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint          `gorm:"uniqueIndex:idx_custom_field_key" json:"organization_id,omitempty" example:"1"` // nil = default organization
+	Key            string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_custom_field_key" json:"key" example:"shirt_size"`
+	Label          string         `gorm:"type:varchar(100);not null" json:"label" example:"Shirt size"`
+	Type           Type           `gorm:"type:varchar(20);not null" json:"type" example:"select"`
+	Required       bool           `gorm:"not null;default:false" json:"required"`
+	Options        datatypes.JSON `json:"options,omitempty" swaggertype:"array,string" example:"S,M,L,XL"` // Choices of select fields
+	MaxLength      int            `gorm:"not null;default:0" json:"max_length,omitempty" example:"20"`     // Text fields; 0 = maxTextLength
+	Min            *float64       `json:"min,omitempty"`                                                   // Number fields
+	Max            *float64       `json:"max,omitempty"`                                                   // Number fields
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName implements gorm's Tabler.
+func (Definition) TableName() string { return "custom_field_definitions" }
+
+// DefinitionRequest creates a custom field or replaces its settings. The key and type can't be changed
+// once created, since stored values depend on them.
+type DefinitionRequest struct {
+	Key       string   `json:"key" binding:"required" example:"shirt_size"`
+	Label     string   `json:"label" binding:"required,max=100" example:"Shirt size"`
+	Type      Type     `json:"type" binding:"required,oneof=text number boolean date select" example:"select"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options,omitempty" example:"S,M,L,XL"`
+	MaxLength int      `json:"max_length,omitempty" binding:"min=0" example:"20"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+}
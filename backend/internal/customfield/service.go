@@ -0,0 +1,364 @@
+// prometheus/backend/internal/customfield/service.go
+package customfield
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxDefinitions bounds the custom fields of an organization.
+const maxDefinitions = 100
+
+// maxTextLength is the longest text value, and the default MaxLength.
+const maxTextLength = 1000
+
+// maxOptions bounds the choices of a select field.
+const maxOptions = 100
+
+// keyPattern restricts field keys to what is safe as a JSON key and in exports.
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ErrInvalidDefinition is returned for definitions that fail validation.
+var ErrInvalidDefinition = errors.New("invalid custom field definition")
+
+// ErrKeyTaken is returned when the organization already has a field with the key.
+var ErrKeyTaken = errors.New("a custom field with this key already exists")
+
+// ErrInvalidValue is returned for values that don't match their field.
+var ErrInvalidValue = errors.New("invalid custom field value")
+
+// Service manages custom field definitions and users' values.
+// orgID scopes definitions to one organization (nil = default organization) and values to the
+// organization's users (nil = all users, for platform admins).
+type Service interface {
+	List(orgID *uint) ([]Definition, error)
+	Create(actor audit.Actor, orgID *uint, req DefinitionRequest) (*Definition, error)
+	Update(actor audit.Actor, orgID *uint, id uint, req DefinitionRequest) (*Definition, error)
+	// Delete removes a field along with every user's value of it.
+	Delete(actor audit.Actor, orgID *uint, id uint) error
+	Values(orgID *uint, userID uint) (map[string]interface{}, error)
+	// SetValues replaces a user's values; omitted or null fields are cleared unless required.
+	SetValues(actor audit.Actor, orgID *uint, userID uint, values map[string]interface{}) (map[string]interface{}, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+// userRow is the part of a user this package reads and writes, without importing the auth package.
+type userRow struct {
+	ID             uint
+	OrganizationID *uint
+	CustomFields   datatypes.JSON
+}
+
+func (s *service) List(orgID *uint) ([]Definition, error) {
+	var defs []Definition
+	if err := utils.OrgScope(s.db, orgID).Order("id").Find(&defs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	return defs, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req DefinitionRequest) (*Definition, error) {
+	def := Definition{OrganizationID: orgID}
+	if err := apply(&def, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := utils.OrgScope(tx.Model(&Definition{}), orgID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count custom fields: %w", err)
+		}
+		if count >= maxDefinitions {
+			return fmt.Errorf("%w: at most %d fields per organization", ErrInvalidDefinition, maxDefinitions)
+		}
+		var taken int64
+		if err := utils.OrgScope(tx.Model(&Definition{}), orgID).Where("key = ?", def.Key).Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check custom field keys: %w", err)
+		}
+		if taken > 0 {
+			return ErrKeyTaken
+		}
+		if err := tx.Create(&def).Error; err != nil {
+			return fmt.Errorf("failed to create custom field: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "custom_field.create", EntityType: "custom_field", EntityID: fmt.Sprintf("%d", def.ID), After: def,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Update replaces a field's settings. Stored values that no longer fit (e.g. a removed option) are kept
+// and only rejected when the user's values are next saved.
+func (s *service) Update(actor audit.Actor, orgID *uint, id uint, req DefinitionRequest) (*Definition, error) {
+	var def Definition
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx, orgID).First(&def, id).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		if req.Key != def.Key || req.Type != def.Type {
+			return fmt.Errorf("%w: key and type can't be changed", ErrInvalidDefinition)
+		}
+		before := def
+		if err := apply(&def, req); err != nil {
+			return err
+		}
+		if err := tx.Save(&def).Error; err != nil {
+			return fmt.Errorf("failed to update custom field: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "custom_field.update", EntityType: "custom_field", EntityID: fmt.Sprintf("%d", def.ID), Before: before, After: def,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+func (s *service) Delete(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var def Definition
+		if err := utils.OrgScope(tx, orgID).First(&def, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&def).Error; err != nil {
+			return fmt.Errorf("failed to delete custom field: %w", err)
+		}
+		if err := utils.OrgScope(tx.Table("users"), orgID).Where("jsonb_exists(custom_fields, ?)", def.Key).
+			Update("custom_fields", gorm.Expr("custom_fields - ?", def.Key)).Error; err != nil {
+			return fmt.Errorf("failed to remove values of custom field %s: %w", def.Key, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "custom_field.delete", EntityType: "custom_field", EntityID: fmt.Sprintf("%d", def.ID), Before: def,
+		})
+	})
+}
+
+func (s *service) Values(orgID *uint, userID uint) (map[string]interface{}, error) {
+	user, err := loadUser(s.db, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues(user.CustomFields)
+}
+
+func (s *service) SetValues(actor audit.Actor, orgID *uint, userID uint, values map[string]interface{}) (map[string]interface{}, error) {
+	var stored map[string]interface{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := loadUser(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		var defs []Definition
+		if err := utils.OrgScope(tx, user.OrganizationID).Find(&defs).Error; err != nil {
+			return fmt.Errorf("failed to load custom fields: %w", err)
+		}
+		if stored, err = validateValues(defs, values); err != nil {
+			return err
+		}
+		before, err := decodeValues(user.CustomFields)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return fmt.Errorf("failed to encode custom field values: %w", err)
+		}
+		if err := tx.Table("users").Where("id = ?", userID).Updates(map[string]interface{}{
+			"custom_fields": datatypes.JSON(data),
+			"version":       gorm.Expr("version + 1"),
+			"updated_at":    time.Now().UTC(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save custom field values: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.custom_fields.update", EntityType: "user", EntityID: fmt.Sprintf("%d", userID), Before: before, After: stored,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// apply validates req and copies it onto def.
+func apply(def *Definition, req DefinitionRequest) error {
+	if !keyPattern.MatchString(req.Key) {
+		return fmt.Errorf("%w: key must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidDefinition)
+	}
+	if len(req.Options) > 0 && req.Type != TypeSelect {
+		return fmt.Errorf("%w: only select fields have options", ErrInvalidDefinition)
+	}
+	if req.MaxLength > 0 && req.Type != TypeText {
+		return fmt.Errorf("%w: only text fields have a maximum length", ErrInvalidDefinition)
+	}
+	if (req.Min != nil || req.Max != nil) && req.Type != TypeNumber {
+		return fmt.Errorf("%w: only number fields have bounds", ErrInvalidDefinition)
+	}
+	switch req.Type {
+	case TypeSelect:
+		if len(req.Options) == 0 || len(req.Options) > maxOptions {
+			return fmt.Errorf("%w: select fields need 1 to %d options", ErrInvalidDefinition, maxOptions)
+		}
+		for i, option := range req.Options {
+			if strings.TrimSpace(option) == "" || len(option) > 100 || slices.Contains(req.Options[:i], option) {
+				return fmt.Errorf("%w: options must be unique, non-empty and at most 100 characters", ErrInvalidDefinition)
+			}
+		}
+	case TypeText:
+		if req.MaxLength > maxTextLength {
+			return fmt.Errorf("%w: text fields hold at most %d characters", ErrInvalidDefinition, maxTextLength)
+		}
+	case TypeNumber:
+		if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+			return fmt.Errorf("%w: min must not exceed max", ErrInvalidDefinition)
+		}
+	}
+
+	var options datatypes.JSON
+	if len(req.Options) > 0 {
+		encoded, err := json.Marshal(req.Options)
+		if err != nil {
+			return err
+		}
+		options = encoded
+	}
+	def.Key, def.Label, def.Type, def.Required = req.Key, req.Label, req.Type, req.Required
+	def.Options, def.MaxLength, def.Min, def.Max = options, req.MaxLength, req.Min, req.Max
+	return nil
+}
+
+// validateValues checks values against the organization's fields and returns them normalized, without
+// cleared fields.
+func validateValues(defs []Definition, values map[string]interface{}) (map[string]interface{}, error) {
+	byKey := make(map[string]*Definition, len(defs))
+	for i := range defs {
+		byKey[defs[i].Key] = &defs[i]
+	}
+	for key := range values {
+		if _, ok := byKey[key]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidValue, key)
+		}
+	}
+	stored := make(map[string]interface{}, len(values))
+	for _, def := range byKey {
+		raw, present := values[def.Key]
+		if !present || raw == nil {
+			if def.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidValue, def.Label)
+			}
+			continue
+		}
+		value, err := validateValue(def, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidValue, def.Label, err)
+		}
+		stored[def.Key] = value
+	}
+	return stored, nil
+}
+
+// validateValue checks a decoded JSON value against its field.
+func validateValue(def *Definition, raw interface{}) (interface{}, error) {
+	switch def.Type {
+	case TypeText:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("must be text")
+		}
+		limit := def.MaxLength
+		if limit == 0 {
+			limit = maxTextLength
+		}
+		if utf8.RuneCountInString(text) > limit {
+			return nil, fmt.Errorf("must not exceed %d characters", limit)
+		}
+		if def.Required && strings.TrimSpace(text) == "" {
+			return nil, errors.New("is required")
+		}
+		return text, nil
+	case TypeNumber:
+		number, ok := raw.(float64)
+		if !ok {
+			return nil, errors.New("must be a number")
+		}
+		if def.Min != nil && number < *def.Min {
+			return nil, fmt.Errorf("must be at least %g", *def.Min)
+		}
+		if def.Max != nil && number > *def.Max {
+			return nil, fmt.Errorf("must be at most %g", *def.Max)
+		}
+		return number, nil
+	case TypeBoolean:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	case TypeDate:
+		text, ok := raw.(string)
+		if _, err := time.Parse(time.DateOnly, text); !ok || err != nil {
+			return nil, errors.New("must be a date (YYYY-MM-DD)")
+		}
+		return text, nil
+	case TypeSelect:
+		var options []string
+		if err := json.Unmarshal(def.Options, &options); err != nil {
+			return nil, fmt.Errorf("has unreadable options: %w", err)
+		}
+		choice, ok := raw.(string)
+		if !ok || !slices.Contains(options, choice) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+		}
+		return choice, nil
+	}
+	return nil, fmt.Errorf("has unknown type %s", def.Type)
+}
+
+// loadUser fetches a non-deleted user's custom field values, within the organization scope.
+func loadUser(db *gorm.DB, orgID *uint, userID uint) (*userRow, error) {
+	query := db.Table("users").Select("id", "organization_id", "custom_fields").Where("deleted_at IS NULL")
+	if orgID != nil {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+	var user userRow
+	if err := query.Where("id = ?", userID).Take(&user).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &user, nil
+}
+
+func decodeValues(data datatypes.JSON) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(data) == 0 {
+		return values, nil
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode custom field values: %w", err)
+	}
+	return values, nil
+}
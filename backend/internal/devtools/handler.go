@@ -0,0 +1,65 @@
+// prometheus/backend/internal/devtools/handler.go
+package devtools
+
+import (
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves the inbox of the fake integrations. It is only routed with DEV_INTEGRATIONS=fake.
+type Handler struct {
+	inbox *Inbox
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(inbox *Inbox) *Handler {
+	return &Handler{inbox: inbox}
+}
+
+// List returns what the fake integrations recorded, newest first.
+// @Summary List the devtools inbox
+// @Description Only available with DEV_INTEGRATIONS=fake (never in production), to god-admins.
+// @Tags Devtools
+// @Produce json
+// @Param channel query string false "Only items of this channel (mail, payments)"
+// @Success 200 {array} Item
+// @Router /devtools/inbox [get]
+func (h *Handler) List(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Inbox fetched successfully", h.inbox.List(c.Query("channel")))
+}
+
+// Get returns one inbox item.
+// @Summary Get a devtools inbox item
+// @Tags Devtools
+// @Produce json
+// @Param id path int true "Item ID"
+// @Success 200 {object} Item
+// @Failure 404 {object} utils.ErrorResponse "Item not found or already dropped"
+// @Router /devtools/inbox/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid item ID")
+		return
+	}
+	item, ok := h.inbox.Get(id)
+	if !ok {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Item not found")
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Item fetched successfully", item)
+}
+
+// Clear empties the inbox.
+// @Summary Clear the devtools inbox
+// @Tags Devtools
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Router /devtools/inbox [delete]
+func (h *Handler) Clear(c *gin.Context) {
+	h.inbox.Clear()
+	utils.SendSuccessResponse(c, http.StatusOK, "Inbox cleared successfully", nil)
+}
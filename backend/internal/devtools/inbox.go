@@ -0,0 +1,81 @@
+// prometheus/backend/internal/devtools/inbox.go
+package devtools
+
+import (
+	"sync"
+	"time"
+)
+
+// Channels of inbox items.
+const (
+	ChannelMail     = "mail"
+	ChannelPayments = "payments"
+)
+
+// Item is something a fake integration would have sent to a third party.
+type Item struct {
+	ID        uint64      `json:"id" example:"12"`
+	Channel   string      `json:"channel" example:"mail"`
+	Summary   string      `json:"summary" example:"To jane@example.com: Your weekly report"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Inbox keeps the most recent items recorded by fake integrations in memory, so developers can follow
+// flows such as invitations or checkouts without real mail servers or payment accounts.
+type Inbox struct {
+	mu       sync.RWMutex
+	items    []Item
+	nextID   uint64
+	capacity int
+}
+
+// NewInbox creates an Inbox keeping the last capacity items.
+func NewInbox(capacity int) *Inbox {
+	return &Inbox{capacity: capacity, nextID: 1}
+}
+
+// Record adds an item, dropping the oldest once the inbox is full.
+func (i *Inbox) Record(channel, summary string, data interface{}) Item {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	item := Item{ID: i.nextID, Channel: channel, Summary: summary, Data: data, CreatedAt: time.Now().UTC()}
+	i.nextID++
+	i.items = append(i.items, item)
+	if len(i.items) > i.capacity {
+		i.items = i.items[len(i.items)-i.capacity:]
+	}
+	return item
+}
+
+// List returns the items of a channel (empty = all), newest first.
+func (i *Inbox) List(channel string) []Item {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	items := make([]Item, 0, len(i.items))
+	for n := len(i.items) - 1; n >= 0; n-- {
+		if channel == "" || i.items[n].Channel == channel {
+			items = append(items, i.items[n])
+		}
+	}
+	return items
+}
+
+// Get returns an item by ID.
+func (i *Inbox) Get(id uint64) (Item, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, item := range i.items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Clear removes all items.
+func (i *Inbox) Clear() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.items = nil
+}
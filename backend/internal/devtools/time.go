@@ -0,0 +1,118 @@
+// prometheus/backend/internal/devtools/time.go
+package devtools
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FreezeTimeRequest stops the application time.
+type FreezeTimeRequest struct {
+	At *time.Time `json:"at,omitempty" example:"2025-12-31T23:59:00Z"` // Omitted = the current application time
+}
+
+// AdvanceTimeRequest moves the application time.
+type AdvanceTimeRequest struct {
+	Duration string `json:"duration" binding:"required" example:"72h"` // Go duration, negative to go back
+}
+
+// TimeHandler serves time travel for non-production environments (see package clock).
+type TimeHandler struct{}
+
+// NewTimeHandler creates a new instance of TimeHandler.
+func NewTimeHandler() *TimeHandler {
+	return &TimeHandler{}
+}
+
+// Get returns the application time.
+// @Summary Get the application time
+// @Tags Devtools
+// @Produce json
+// @Success 200 {object} clock.Status
+// @Router /devtools/time [get]
+func (h *TimeHandler) Get(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Time fetched successfully", clock.Current())
+}
+
+// Freeze stops the application time.
+// @Summary Freeze the application time
+// @Description Not available in production. Affects expiry, schedules and token lifetimes of this instance only.
+// @Tags Devtools
+// @Accept json
+// @Produce json
+// @Param request body FreezeTimeRequest false "Instant to freeze at"
+// @Success 200 {object} clock.Status
+// @Router /devtools/time/freeze [post]
+func (h *TimeHandler) Freeze(c *gin.Context) {
+	var req FreezeTimeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	at := clock.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+	h.respond(c, clock.Freeze(at))
+}
+
+// Advance moves the application time.
+// @Summary Advance the application time
+// @Tags Devtools
+// @Accept json
+// @Produce json
+// @Param request body AdvanceTimeRequest true "Duration to move by"
+// @Success 200 {object} clock.Status
+// @Failure 400 {object} utils.ErrorResponse "Invalid duration"
+// @Router /devtools/time/advance [post]
+func (h *TimeHandler) Advance(c *gin.Context) {
+	var req AdvanceTimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	d, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid duration, use e.g. 36h or -90m")
+		return
+	}
+	h.respond(c, clock.Advance(d))
+}
+
+// Resume lets a frozen application time run again.
+// @Summary Resume the application time
+// @Tags Devtools
+// @Produce json
+// @Success 200 {object} clock.Status
+// @Router /devtools/time/resume [post]
+func (h *TimeHandler) Resume(c *gin.Context) {
+	h.respond(c, clock.Resume())
+}
+
+// Reset returns to the wall clock.
+// @Summary Reset the application time
+// @Tags Devtools
+// @Produce json
+// @Success 200 {object} clock.Status
+// @Router /devtools/time [delete]
+func (h *TimeHandler) Reset(c *gin.Context) {
+	h.respond(c, clock.Reset())
+}
+
+func (h *TimeHandler) respond(c *gin.Context, err error) {
+	if errors.Is(err, clock.ErrDisabled) {
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	status := clock.Current()
+	log.Printf("Devtools: application time set to %s (offset %s, frozen %t) by user %d", status.Now.Format(time.RFC3339), status.Offset, status.Frozen, c.GetUint("userID"))
+	utils.SendSuccessResponse(c, http.StatusOK, "Time updated successfully", status)
+}
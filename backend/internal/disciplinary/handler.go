@@ -0,0 +1,216 @@
+// prometheus/backend/internal/disciplinary/handler.go
+package disciplinary
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readerRoles are the global roles that may see disciplinary records. Division-scoped roles never do, nor
+// do roles inheriting HR permissions such as admin.
+var readerRoles = []string{"hr", "god-admin"}
+
+// Handler handles HTTP requests for disciplinary records.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Confidential lets only callers holding one of readerRoles globally through. It runs after the route's
+// role check, which also accepts division-scoped roles.
+func (h *Handler) Confidential(c *gin.Context) {
+	roles := middleware.RolesFromContext(c)
+	if !slices.ContainsFunc(roles, func(r string) bool { return slices.Contains(readerRoles, r) }) {
+		utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: Disciplinary records are only visible to HR.")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// List returns the organization's disciplinary records without their entries, latest first.
+// @Summary List disciplinary records
+// @Description Only HR and god-admins see disciplinary records; HR doesn't see records about themselves.
+// @Tags Disciplinary
+// @Produce json
+// @Param kind query string false "Kind" Enums(incident, verbal_warning, written_warning, final_warning, suspension, other)
+// @Param employee_id query int false "Employee ID"
+// @Param closed query bool false "Closed or open records only"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 403 {object} utils.ErrorResponse "Not HR"
+// @Router /hr/disciplinary [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Kind: Kind(c.Query("kind"))}
+	switch filter.Kind {
+	case "", KindIncident, KindVerbalWarning, KindWrittenWarning, KindFinalWarning, KindSuspension, KindOther:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid kind parameter")
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	if raw := c.Query("closed"); raw != "" {
+		closed, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid closed parameter")
+			return
+		}
+		filter.Closed = &closed
+	}
+	page := utils.ParsePagination(c)
+	records, total, err := h.service.List(utils.OrganizationFromContext(c), viewer(c), filter, page)
+	if err != nil {
+		sendDisciplinaryError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Disciplinary records fetched successfully", page.Response(records, total))
+}
+
+// Get returns a disciplinary record with its entries.
+// @Summary Get a disciplinary record
+// @Tags Disciplinary
+// @Produce json
+// @Param id path int true "Record ID"
+// @Success 200 {object} Record
+// @Failure 403 {object} utils.ErrorResponse "Not HR"
+// @Failure 404 {object} utils.ErrorResponse "Record not found"
+// @Router /hr/disciplinary/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	record, err := h.service.Get(utils.OrganizationFromContext(c), viewer(c), id)
+	if err != nil {
+		sendDisciplinaryError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	utils.SendSuccessResponse(c, http.StatusOK, "Disciplinary record fetched successfully", record)
+}
+
+// EmployeeRecords returns an employee's disciplinary records with their entries, latest first.
+// @Summary List an employee's disciplinary records
+// @Tags Disciplinary
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} Record
+// @Failure 403 {object} utils.ErrorResponse "Not HR"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/disciplinary [get]
+func (h *Handler) EmployeeRecords(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	records, err := h.service.EmployeeRecords(utils.OrganizationFromContext(c), viewer(c), employeeID)
+	if err != nil {
+		sendDisciplinaryError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	utils.SendSuccessResponse(c, http.StatusOK, "Disciplinary records fetched successfully", records)
+}
+
+// Create records a disciplinary measure or incident concerning an employee.
+// @Summary Create a disciplinary record
+// @Description The record can't be edited nor deleted afterwards; add entries to correct or close it.
+// @Tags Disciplinary
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param record body Request true "Record"
+// @Success 201 {object} Record
+// @Failure 400 {object} utils.ErrorResponse "Invalid record or blank reason"
+// @Failure 403 {object} utils.ErrorResponse "Not HR"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/disciplinary [post]
+func (h *Handler) Create(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	record, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer(c), employeeID, req)
+	if err != nil {
+		sendDisciplinaryError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Disciplinary record created successfully", record)
+}
+
+// AddEntry adds a note, correction or closure to a disciplinary record.
+// @Summary Add an entry to a disciplinary record
+// @Description A closure closes the record for good; nothing can be added to it afterwards.
+// @Tags Disciplinary
+// @Accept json
+// @Produce json
+// @Param id path int true "Record ID"
+// @Param entry body EntryRequest true "Entry"
+// @Success 201 {object} Record
+// @Failure 400 {object} utils.ErrorResponse "Invalid entry or blank reason"
+// @Failure 403 {object} utils.ErrorResponse "Not HR"
+// @Failure 404 {object} utils.ErrorResponse "Record not found"
+// @Failure 409 {object} utils.ErrorResponse "Record closed"
+// @Router /hr/disciplinary/{id}/entries [post]
+func (h *Handler) AddEntry(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req EntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	record, err := h.service.AddEntry(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer(c), id, req)
+	if err != nil {
+		sendDisciplinaryError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Entry added successfully", record)
+}
+
+// viewer is the caller, a god-admin or HR.
+func viewer(c *gin.Context) Viewer {
+	return Viewer{UserID: c.GetUint("userID"), GodAdmin: slices.Contains(middleware.RolesFromContext(c), "god-admin")}
+}
+
+func sendDisciplinaryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidRecord):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrClosed), errors.Is(err, ErrImmutable):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,112 @@
+// prometheus/backend/internal/disciplinary/model.go
+package disciplinary
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrImmutable is returned when something tries to modify or delete a disciplinary record or entry.
+var ErrImmutable = errors.New("disciplinary records are immutable; add an entry instead")
+
+// Kind labels a disciplinary record.
+type Kind string
+
+const (
+	KindIncident       Kind = "incident"
+	KindVerbalWarning  Kind = "verbal_warning"
+	KindWrittenWarning Kind = "written_warning"
+	KindFinalWarning   Kind = "final_warning"
+	KindSuspension     Kind = "suspension"
+	KindOther          Kind = "other"
+)
+
+// EntryKind labels an entry added to a record.
+type EntryKind string
+
+const (
+	EntryNote       EntryKind = "note"
+	EntryCorrection EntryKind = "correction" // Corrects the record; the original stays as it was written
+	EntryClosure    EntryKind = "closure"    // Closes the record; nothing can be added afterwards
+)
+
+// Record is a confidential disciplinary measure or incident concerning an employee, seen only by HR and
+// god-admins. Records are never edited nor deleted: corrections, follow-ups and the closure are entries
+// added to them, so the history stays as it was written.
+type Record struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName    string    `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Kind           Kind      `gorm:"type:varchar(20);not null;index" json:"kind" example:"written_warning"`
+	OccurredOn     time.Time `gorm:"type:date;not null" json:"occurred_on" example:"2026-10-02T00:00:00Z"`
+	Summary        string    `gorm:"type:varchar(200);not null" json:"summary" example:"Repeated unexcused absence"`
+	Details        string    `gorm:"type:text" json:"details,omitempty"`
+	Reason         string    `gorm:"type:varchar(2000);not null" json:"reason" example:"Third unexcused absence this quarter after a verbal warning"` // Why the record is made
+	Closed         bool      `gorm:"-" json:"closed"`
+	Entries        []Entry   `gorm:"foreignKey:RecordID" json:"entries,omitempty"`
+	CreatedBy      *uint     `json:"created_by,omitempty" example:"4"` // User ID
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps the records next to their entries.
+func (Record) TableName() string { return "disciplinary_records" }
+
+// BeforeUpdate rejects updates so records cannot be rewritten through GORM.
+func (r *Record) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// BeforeDelete rejects deletes so records cannot be removed through GORM.
+func (r *Record) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// Entry is a note, correction or closure added to a record, with the reason it was added.
+type Entry struct {
+	ID        uint      `gorm:"primaryKey" json:"id" example:"11"`
+	RecordID  uint      `gorm:"not null;index" json:"record_id" example:"5"`
+	Kind      EntryKind `gorm:"type:varchar(20);not null" json:"kind" example:"note"`
+	Text      string    `gorm:"type:text;not null" json:"text" example:"Employee acknowledged the warning in the follow-up meeting"`
+	Reason    string    `gorm:"type:varchar(2000);not null" json:"reason" example:"Outcome of the follow-up meeting"`
+	CreatedBy *uint     `json:"created_by,omitempty" example:"4"` // User ID
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName keeps entries next to their records.
+func (Entry) TableName() string { return "disciplinary_entries" }
+
+// BeforeUpdate rejects updates so entries cannot be rewritten through GORM.
+func (e *Entry) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// BeforeDelete rejects deletes so entries cannot be removed through GORM.
+func (e *Entry) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutable
+}
+
+// Request records a disciplinary measure or incident.
+type Request struct {
+	Kind       Kind   `json:"kind" binding:"required,oneof=incident verbal_warning written_warning final_warning suspension other" example:"written_warning"`
+	OccurredOn string `json:"occurred_on" binding:"required,datetime=2006-01-02" example:"2026-10-02"`
+	Summary    string `json:"summary" binding:"required,max=200" example:"Repeated unexcused absence"`
+	Details    string `json:"details,omitempty" binding:"max=20000"`
+	Reason     string `json:"reason" binding:"required,max=2000" example:"Third unexcused absence this quarter after a verbal warning"`
+}
+
+// EntryRequest adds an entry to a record.
+type EntryRequest struct {
+	Kind   EntryKind `json:"kind" binding:"required,oneof=note correction closure" example:"note"`
+	Text   string    `json:"text" binding:"required,max=20000" example:"Employee acknowledged the warning in the follow-up meeting"`
+	Reason string    `json:"reason" binding:"required,max=2000" example:"Outcome of the follow-up meeting"`
+}
+
+// Filter narrows a record listing.
+type Filter struct {
+	Kind       Kind
+	EmployeeID *uint
+	Closed     *bool
+}
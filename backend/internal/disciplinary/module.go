@@ -0,0 +1,40 @@
+// prometheus/backend/internal/disciplinary/module.go
+package disciplinary
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the disciplinary module.
+const ModuleName = "disciplinary"
+
+// disciplinaryModule owns confidential disciplinary records.
+type disciplinaryModule struct {
+	handler *Handler
+}
+
+// NewModule creates the disciplinary module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &disciplinaryModule{handler: NewHandler(svc)}
+}
+
+func (m *disciplinaryModule) Name() string { return ModuleName }
+
+func (m *disciplinaryModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *disciplinaryModule) Models() []any {
+	return []any{&Record{}, &Entry{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Records are gated by role rather than by policy, so
+// granting someone /hr/* doesn't reveal them; there are no routes to edit or delete them.
+func (m *disciplinaryModule) RegisterRoutes(api *routing.Group) {
+	readers := routing.Roles(readerRoles...)
+	api.GET("/hr/disciplinary", readers, m.handler.Confidential, m.handler.List)
+	api.GET("/hr/disciplinary/:id", readers, m.handler.Confidential, m.handler.Get)
+	api.POST("/hr/disciplinary/:id/entries", readers, m.handler.Confidential, m.handler.AddEntry)
+	api.GET("/hr/employees/:id/disciplinary", readers, m.handler.Confidential, m.handler.EmployeeRecords)
+	api.POST("/hr/employees/:id/disciplinary", readers, m.handler.Confidential, m.handler.Create)
+}
@@ -0,0 +1,260 @@
+// prometheus/backend/internal/disciplinary/service.go
+package disciplinary
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidRecord is returned for records and entries that fail validation.
+	ErrInvalidRecord = errors.New("invalid disciplinary record")
+	// ErrClosed is returned when adding an entry to a closed record.
+	ErrClosed = errors.New("the disciplinary record is closed")
+)
+
+// Viewer is who is asking. HR never sees records about themselves; god-admins see every record.
+type Viewer struct {
+	UserID   uint
+	GodAdmin bool
+}
+
+// Service keeps confidential disciplinary records. Records and their entries are append-only, and the
+// audit trail only notes that they were written, not what they say, since audit logs are seen more widely.
+// orgID scopes every call to one organization's employees and records (nil = default organization).
+type Service interface {
+	// List returns records without their entries, latest first.
+	List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Record, int64, error)
+	// Get returns a record with its entries, oldest first.
+	Get(orgID *uint, viewer Viewer, id uint) (*Record, error)
+	// EmployeeRecords returns an employee's records with their entries, latest first.
+	EmployeeRecords(orgID *uint, viewer Viewer, employeeID uint) ([]Record, error)
+	Create(actor audit.Actor, orgID *uint, viewer Viewer, employeeID uint, req Request) (*Record, error)
+	// AddEntry adds a note, correction or closure to a record that isn't closed.
+	AddEntry(actor audit.Actor, orgID *uint, viewer Viewer, id uint, req EntryRequest) (*Record, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Record, int64, error) {
+	query := visible(utils.OrgScope(s.db.Model(&Record{}), orgID), viewer)
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.Closed != nil {
+		closure := s.db.Model(&Entry{}).Select("1").
+			Where("disciplinary_entries.record_id = disciplinary_records.id AND disciplinary_entries.kind = ?", EntryClosure)
+		if *filter.Closed {
+			query = query.Where("EXISTS (?)", closure)
+		} else {
+			query = query.Where("NOT EXISTS (?)", closure)
+		}
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count disciplinary records: %w", err)
+	}
+	var records []Record
+	if err := query.Order("occurred_on DESC, id DESC").Scopes(page.Scope).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list disciplinary records: %w", err)
+	}
+	if err := s.complete(orgID, records); err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+func (s *service) Get(orgID *uint, viewer Viewer, id uint) (*Record, error) {
+	var record Record
+	if err := visible(utils.OrgScope(s.db, orgID), viewer).Preload("Entries", orderEntries).First(&record, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	records := []Record{record}
+	if err := s.complete(orgID, records); err != nil {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+func (s *service) EmployeeRecords(orgID *uint, viewer Viewer, employeeID uint) ([]Record, error) {
+	if err := s.checkSubject(orgID, viewer, employeeID); err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Preload("Entries", orderEntries).
+		Order("occurred_on DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list the disciplinary records of employee %d: %w", employeeID, err)
+	}
+	if err := s.complete(orgID, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, viewer Viewer, employeeID uint, req Request) (*Record, error) {
+	if err := s.checkSubject(orgID, viewer, employeeID); err != nil {
+		return nil, err
+	}
+	occurredOn, err := time.Parse("2006-01-02", req.OccurredOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: occurred_on must be a date", ErrInvalidRecord)
+	}
+	if occurredOn.After(today()) {
+		return nil, fmt.Errorf("%w: occurred_on can't be in the future", ErrInvalidRecord)
+	}
+	record := Record{
+		OrganizationID: orgID,
+		EmployeeID:     employeeID,
+		Kind:           req.Kind,
+		OccurredOn:     occurredOn,
+		Summary:        strings.TrimSpace(req.Summary),
+		Details:        strings.TrimSpace(req.Details),
+		Reason:         strings.TrimSpace(req.Reason),
+		CreatedBy:      actor.UserID,
+	}
+	if record.Summary == "" {
+		return nil, fmt.Errorf("%w: the summary can't be blank", ErrInvalidRecord)
+	}
+	if record.Reason == "" {
+		return nil, fmt.Errorf("%w: the reason can't be blank", ErrInvalidRecord)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to create disciplinary record: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "disciplinary.create", EntityType: "disciplinary_record", EntityID: fmt.Sprintf("%d", record.ID),
+			After: map[string]interface{}{"employee_id": employeeID, "kind": record.Kind},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, viewer, record.ID)
+}
+
+func (s *service) AddEntry(actor audit.Actor, orgID *uint, viewer Viewer, id uint, req EntryRequest) (*Record, error) {
+	entry := Entry{
+		RecordID:  id,
+		Kind:      req.Kind,
+		Text:      strings.TrimSpace(req.Text),
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: actor.UserID,
+	}
+	if entry.Text == "" {
+		return nil, fmt.Errorf("%w: the text can't be blank", ErrInvalidRecord)
+	}
+	if entry.Reason == "" {
+		return nil, fmt.Errorf("%w: the reason can't be blank", ErrInvalidRecord)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the record serializes entries, so nothing is added after the closure.
+		var record Record
+		if err := visible(utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID), viewer).
+			Select("id").First(&record, id).Error; err != nil {
+			return err
+		}
+		var closures int64
+		if err := tx.Model(&Entry{}).Where("record_id = ? AND kind = ?", id, EntryClosure).Count(&closures).Error; err != nil {
+			return fmt.Errorf("failed to check disciplinary record %d: %w", id, err)
+		}
+		if closures > 0 {
+			return ErrClosed
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to add entry to disciplinary record %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "disciplinary.entry_add", EntityType: "disciplinary_record", EntityID: fmt.Sprintf("%d", id),
+			After: map[string]interface{}{"entry_id": entry.ID, "kind": entry.Kind},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, viewer, id)
+}
+
+// checkSubject makes sure the employee exists in the organization and isn't the viewer themselves.
+func (s *service) checkSubject(orgID *uint, viewer Viewer, employeeID uint) error {
+	subject, err := s.employees.Get(orgID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !viewer.GodAdmin && subject.UserID == viewer.UserID {
+		return gorm.ErrRecordNotFound // Records about oneself are hidden, not forbidden
+	}
+	return nil
+}
+
+// complete fills in the display names of records' employees and whether the records are closed.
+func (s *service) complete(orgID *uint, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	employeeIDs := make([]uint, len(records))
+	ids := make([]uint, len(records))
+	for i, r := range records {
+		employeeIDs[i] = r.EmployeeID
+		ids[i] = r.ID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, employeeIDs)
+	if err != nil {
+		return err
+	}
+	var closed []uint
+	if err := s.db.Model(&Entry{}).Where("record_id IN ? AND kind = ?", ids, EntryClosure).
+		Pluck("record_id", &closed).Error; err != nil {
+		return fmt.Errorf("failed to load disciplinary record closures: %w", err)
+	}
+	isClosed := make(map[uint]bool, len(closed))
+	for _, id := range closed {
+		isClosed[id] = true
+	}
+	for i := range records {
+		records[i].DisplayName = names[records[i].EmployeeID].Text
+		records[i].Closed = isClosed[records[i].ID]
+	}
+	return nil
+}
+
+// orderEntries lists a record's entries in the order they were written.
+func orderEntries(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
+// visible hides records about the viewer themselves from everyone but god-admins.
+func visible(db *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.GodAdmin {
+		return db
+	}
+	return db.Where("employee_id NOT IN (SELECT id FROM employees WHERE user_id = ?)", viewer.UserID)
+}
+
+// today is the current UTC date. Records can't be dated after it.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,355 @@
+// prometheus/backend/internal/division/handler.go
+package division
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// teamRoles may see the divisions they hold the role for on the manager routes; holding one of them
+// globally shows every division of the organization.
+var teamRoles = []string{"god-admin", "admin", "hr", "manager"}
+
+// Handler handles HTTP requests for divisions and their members.
+type Handler struct {
+	service   Service
+	employees employee.Service
+}
+
+// NewHandler creates a new instance of Handler. Members are listed through employees.
+func NewHandler(service Service, employees employee.Service) *Handler {
+	return &Handler{service: service, employees: employees}
+}
+
+// List returns divisions with their heads and head counts.
+// @Summary List divisions
+// @Tags Divisions
+// @Produce json
+// @Param q query string false "Case-insensitive match on the name"
+// @Param parent_id query int false "Direct subdivisions of this division"
+// @Param top_level query bool false "Only divisions without a parent"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: name, created_at" default(name)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter or sort"
+// @Router /hr/divisions [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Search: strings.TrimSpace(c.Query("q")), TopLevel: c.Query("top_level") == "true"}
+	if raw := c.Query("parent_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid parent_id parameter")
+			return
+		}
+		parentID := uint(id)
+		filter.ParentID = &parentID
+	}
+	sort, err := utils.ParseSort(c, divisionSortFields, "name")
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid sort parameter: "+err.Error())
+		return
+	}
+	page := utils.ParsePagination(c)
+	divisions, total, err := h.service.List(utils.OrganizationFromContext(c), filter, sort, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Divisions fetched successfully", page.Response(divisions, total))
+}
+
+// Get returns a division. The ETag and Last-Modified headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get a division
+// @Tags Divisions
+// @Produce json
+// @Param id path int true "Division ID"
+// @Success 200 {object} Summary
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Router /hr/divisions/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	division, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, division.UpdatedAt, division.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Division fetched successfully", division)
+}
+
+// Create adds a division.
+// @Summary Create a division
+// @Tags Divisions
+// @Accept json
+// @Produce json
+// @Param division body Request true "Division"
+// @Success 201 {object} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid division, unknown head or parent"
+// @Failure 409 {object} utils.ErrorResponse "Name taken"
+// @Router /hr/divisions [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	division, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, division.UpdatedAt, division.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Division created successfully", division)
+}
+
+// Update replaces a division's name, head and parent.
+// @Summary Update a division
+// @Tags Divisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Division ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param division body Request true "Division"
+// @Success 200 {object} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid division, unknown head or parent, or parent below the division"
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Failure 409 {object} utils.ErrorResponse "Name taken"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/divisions/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	division, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, division.UpdatedAt, division.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Division updated successfully", division)
+}
+
+// Delete removes an empty division.
+// @Summary Delete a division
+// @Tags Divisions
+// @Produce json
+// @Param id path int true "Division ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Failure 409 {object} utils.ErrorResponse "Division still has subdivisions, members or scoped roles"
+// @Router /hr/divisions/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Division deleted successfully", nil)
+}
+
+// Members returns the employees of a division.
+// @Summary List division members
+// @Tags Divisions
+// @Produce json
+// @Param id path int true "Division ID"
+// @Param q query string false "Case-insensitive match on employee number, job title, username or email"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: employee_number, job_title, hire_date, username, created_at" default(employee_number)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid sort"
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Router /hr/divisions/{id}/members [get]
+func (h *Handler) Members(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.sendMembers(c, id, employee.AudienceHR)
+}
+
+// AssignMembers moves employees into a division.
+// @Summary Assign employees to a division
+// @Description Employees in another division are moved; an employee belongs to one division at most.
+// @Tags Divisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Division ID"
+// @Param members body MembersRequest true "Employee IDs"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse "Unknown employee"
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Router /hr/divisions/{id}/members [post]
+func (h *Handler) AssignMembers(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req MembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	moved, err := h.service.AssignMembers(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req.EmployeeIDs)
+	if err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employees assigned successfully", gin.H{"moved": moved})
+}
+
+// RemoveMember takes an employee out of a division.
+// @Summary Remove an employee from a division
+// @Tags Divisions
+// @Produce json
+// @Param id path int true "Division ID"
+// @Param employeeID path int true "Employee ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Division not found, or employee not a member"
+// @Router /hr/divisions/{id}/members/{employeeID} [delete]
+func (h *Handler) RemoveMember(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employeeID")
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveMember(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, employeeID); err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee removed successfully", nil)
+}
+
+// TeamOverview summarizes the divisions the caller manages.
+// @Summary Get my team overview
+// @Description Division managers see the divisions they manage; managers, HR and admins holding their role
+// @Description globally see every division of the organization.
+// @Tags Divisions
+// @Produce json
+// @Success 200 {array} Summary
+// @Router /manager/team-overview [get]
+func (h *Handler) TeamOverview(c *gin.Context) {
+	all, divisionIDs := managedDivisions(c)
+	divisions, err := h.service.Overview(utils.OrganizationFromContext(c), all, divisionIDs)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Team overview fetched successfully", divisions)
+}
+
+// TeamMembers returns the employees of a division the caller manages.
+// @Summary List the members of a managed division
+// @Tags Divisions
+// @Produce json
+// @Param divisionID path int true "Division ID"
+// @Param q query string false "Case-insensitive match on employee number, job title, username or email"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: employee_number, job_title, hire_date, username, created_at" default(employee_number)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 403 {object} utils.ErrorResponse "Division not managed by the caller"
+// @Failure 404 {object} utils.ErrorResponse "Division not found"
+// @Router /manager/divisions/{divisionID}/members [get]
+func (h *Handler) TeamMembers(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "divisionID")
+	if !ok {
+		return
+	}
+	if all, divisionIDs := managedDivisions(c); !all && !slices.Contains(divisionIDs, id) {
+		utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: You do not manage this division.")
+		return
+	}
+	h.sendMembers(c, id, employee.AudienceManagers)
+}
+
+// sendMembers responds with a page of the division's employees, redacted for the audience.
+func (h *Handler) sendMembers(c *gin.Context, id uint, audience employee.Audience) {
+	orgID := utils.OrganizationFromContext(c)
+	if _, err := h.service.Get(orgID, id); err != nil {
+		sendDivisionError(c, err)
+		return
+	}
+	sort, err := utils.ParseSort(c, employee.SortFields, "employee_number")
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid sort parameter: "+err.Error())
+		return
+	}
+	page := utils.ParsePagination(c)
+	filter := employee.Filter{Search: strings.TrimSpace(c.Query("q")), DivisionID: &id}
+	members, total, err := h.employees.List(orgID, filter, sort, page)
+	if err == nil {
+		err = h.employees.Redact(members, audience)
+	}
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Members fetched successfully", page.Response(members, total))
+}
+
+// managedDivisions reports which divisions the caller may see on the manager routes.
+func managedDivisions(c *gin.Context) (all bool, divisionIDs []uint) {
+	for _, role := range teamRoles {
+		roleAll, ids := middleware.DivisionScope(c, role)
+		if roleAll {
+			return true, nil
+		}
+		divisionIDs = append(divisionIDs, ids...)
+	}
+	return false, divisionIDs
+}
+
+// sendDivisionError maps service errors to HTTP status codes.
+func sendDivisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Division not found")
+	case errors.Is(err, ErrNotMember):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidDivision):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrInUse):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The division was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
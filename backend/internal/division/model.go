@@ -0,0 +1,49 @@
+// prometheus/backend/internal/division/model.go
+package division
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Division is a department or other unit of an organization. Divisions form a tree through ParentID;
+// employees belong to at most one division (employee.Employee.DivisionID), and division-scoped roles
+// such as "manager of Engineering" refer to it by ID.
+type Division struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"2"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string         `gorm:"type:varchar(150);not null" json:"name" example:"Engineering"` // Unique within the organization, ignoring case
+	HeadID         *uint          `gorm:"index" json:"head_id,omitempty" example:"12"`                  // Employee ID of the head of the division
+	ParentID       *uint          `gorm:"index" json:"parent_id,omitempty" example:"1"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Request creates a division or replaces its fields.
+type Request struct {
+	Name     string `json:"name" binding:"required,max=150" example:"Engineering"`
+	HeadID   *uint  `json:"head_id,omitempty" example:"12"`
+	ParentID *uint  `json:"parent_id,omitempty" example:"1"`
+}
+
+// MembersRequest assigns employees to a division, moving them out of their previous one.
+type MembersRequest struct {
+	EmployeeIDs []uint `json:"employee_ids" binding:"required,min=1,max=500" example:"12,15"`
+}
+
+// Filter narrows a division listing.
+type Filter struct {
+	Search   string // Case-insensitive match on the name
+	ParentID *uint  // Direct subdivisions of this division
+	TopLevel bool   // Only divisions without a parent
+}
+
+// Summary is a division with its head and head count, for overviews.
+type Summary struct {
+	Division
+	HeadUsername string `json:"head_username,omitempty" example:"jdoe"`
+	MemberCount  int64  `json:"member_count" example:"14"`
+}
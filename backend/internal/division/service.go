@@ -0,0 +1,351 @@
+// prometheus/backend/internal/division/service.go
+package division
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxDepth bounds the walk up the division tree when checking for cycles.
+const maxDepth = 100
+
+// ErrInvalidDivision is returned for divisions that fail validation.
+var ErrInvalidDivision = errors.New("invalid division")
+
+// ErrNameTaken is returned when the organization already has a division of that name.
+var ErrNameTaken = errors.New("division name already taken")
+
+// ErrInUse is returned when deleting a division that still has subdivisions, members or scoped roles.
+var ErrInUse = errors.New("division is still in use")
+
+// ErrNotMember is returned when removing an employee who isn't a member of the division.
+var ErrNotMember = errors.New("employee is not a member of the division")
+
+// divisionSortFields maps the ?sort= fields of the division listing to columns.
+var divisionSortFields = map[string]string{
+	"name":       "divisions.name",
+	"created_at": "divisions.created_at",
+}
+
+// Service manages divisions and which employees belong to them.
+// orgID scopes every call to one organization's divisions (nil = all divisions, for platform admins).
+type Service interface {
+	List(orgID *uint, filter Filter, sort utils.Sort, page utils.Pagination) ([]Summary, int64, error)
+	Get(orgID *uint, id uint) (*Summary, error)
+	Create(actor audit.Actor, orgID *uint, req Request) (*Summary, error)
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Summary, error)
+	// Delete removes an empty division; subdivisions, members and scoped roles must be moved or revoked first.
+	Delete(actor audit.Actor, orgID *uint, id uint) error
+	// AssignMembers moves employees into the division and returns how many changed division.
+	AssignMembers(actor audit.Actor, orgID *uint, id uint, employeeIDs []uint) (int64, error)
+	// RemoveMember takes an employee out of the division, leaving them without one.
+	RemoveMember(actor audit.Actor, orgID *uint, id, employeeID uint) error
+	// Overview summarizes the given divisions, or all of the organization's if all is set.
+	Overview(orgID *uint, all bool, divisionIDs []uint) ([]Summary, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, filter Filter, sort utils.Sort, page utils.Pagination) ([]Summary, int64, error) {
+	query := s.summaries(s.db, orgID)
+	if term := strings.TrimSpace(filter.Search); term != "" {
+		query = query.Where("LOWER(divisions.name) LIKE ?", utils.ContainsPattern(strings.ToLower(term)))
+	}
+	if filter.ParentID != nil {
+		query = query.Where("divisions.parent_id = ?", *filter.ParentID)
+	}
+	if filter.TopLevel {
+		query = query.Where("divisions.parent_id IS NULL")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count divisions: %w", err)
+	}
+	var divisions []Summary
+	if err := query.Select(summaryColumns).Scopes(sort.TableScope("divisions"), page.Scope).Find(&divisions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list divisions: %w", err)
+	}
+	return divisions, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Summary, error) {
+	return s.load(s.db, orgID, id)
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Summary, error) {
+	var created *Summary
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		division := Division{OrganizationID: orgID}
+		apply(&division, req)
+		if err := s.validate(tx, &division); err != nil {
+			return err
+		}
+		if err := tx.Create(&division).Error; err != nil {
+			return fmt.Errorf("failed to create division: %w", err)
+		}
+		var err error
+		if created, err = s.load(tx, nil, division.ID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "division.create", EntityType: "division", EntityID: fmt.Sprintf("%d", division.ID), After: division,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update replaces the division's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Summary, error) {
+	var updated *Summary
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		division := before.Division
+		apply(&division, req)
+		if err := s.validate(tx, &division); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Division{}, id, expectedVersion, map[string]interface{}{
+			"name":      division.Name,
+			"head_id":   division.HeadID,
+			"parent_id": division.ParentID,
+		}); err != nil {
+			return err
+		}
+		if updated, err = s.load(tx, orgID, id); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "division.update", EntityType: "division", EntityID: fmt.Sprintf("%d", id),
+			Before: before.Division, After: updated.Division,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Delete(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		for _, check := range []struct {
+			what  string
+			query *gorm.DB
+		}{
+			{"subdivisions", tx.Model(&Division{}).Where("parent_id = ?", id)},
+			{"members", tx.Model(&employee.Employee{}).Where("division_id = ?", id)},
+			{"scoped roles", tx.Table("scoped_roles").Where("division_id = ? AND deleted_at IS NULL", id)},
+		} {
+			var count int64
+			if err := check.query.Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to count %s of division %d: %w", check.what, id, err)
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: it has %d %s", ErrInUse, count, check.what)
+			}
+		}
+		if err := tx.Delete(&Division{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete division %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "division.delete", EntityType: "division", EntityID: fmt.Sprintf("%d", id), Before: before.Division,
+		})
+	})
+}
+
+// AssignMembers bumps each moved employee's version, so HR editing one of them concurrently gets a
+// conflict instead of silently moving them back.
+func (s *service) AssignMembers(actor audit.Actor, orgID *uint, id uint, employeeIDs []uint) (int64, error) {
+	var moved int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		division, err := s.load(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		var employees []employee.Employee
+		if err := utils.OrgScope(tx.Select("id", "division_id"), division.OrganizationID).
+			Where("id IN ?", employeeIDs).Find(&employees).Error; err != nil {
+			return fmt.Errorf("failed to load employees: %w", err)
+		}
+		found := make(map[uint]bool, len(employees))
+		previous := make(map[uint]*uint, len(employees))
+		var ids []uint
+		for _, e := range employees {
+			found[e.ID] = true
+			if e.DivisionID == nil || *e.DivisionID != id {
+				previous[e.ID] = e.DivisionID
+				ids = append(ids, e.ID)
+			}
+		}
+		for _, employeeID := range employeeIDs {
+			if !found[employeeID] {
+				return fmt.Errorf("%w: employee %d not found", ErrInvalidDivision, employeeID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		result := tx.Model(&employee.Employee{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"division_id": id, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return fmt.Errorf("failed to assign employees: %w", result.Error)
+		}
+		moved = result.RowsAffected
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "division.assign_members", EntityType: "division", EntityID: fmt.Sprintf("%d", id),
+			Before: previous, After: map[string]interface{}{"employee_ids": ids},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func (s *service) RemoveMember(actor audit.Actor, orgID *uint, id, employeeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.load(tx, orgID, id); err != nil {
+			return err
+		}
+		result := tx.Model(&employee.Employee{}).Where("id = ? AND division_id = ?", employeeID, id).
+			Updates(map[string]interface{}{"division_id": nil, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove employee %d: %w", employeeID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotMember
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "division.remove_member", EntityType: "division", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"employee_id": employeeID},
+		})
+	})
+}
+
+func (s *service) Overview(orgID *uint, all bool, divisionIDs []uint) ([]Summary, error) {
+	if !all && len(divisionIDs) == 0 {
+		return []Summary{}, nil
+	}
+	query := s.summaries(s.db, orgID)
+	if !all {
+		query = query.Where("divisions.id IN ?", divisionIDs)
+	}
+	var divisions []Summary
+	if err := query.Select(summaryColumns).Order("divisions.name, divisions.id").Find(&divisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load divisions: %w", err)
+	}
+	return divisions, nil
+}
+
+// validate checks the name is free (serialized per organization and name, since soft-deleted divisions
+// rule out a unique index), the head is an employee of the organization, and the parent is a division of
+// the organization that isn't (indirectly) below this one.
+func (s *service) validate(tx *gorm.DB, division *Division) error {
+	if division.Name == "" {
+		return fmt.Errorf("%w: name must not be blank", ErrInvalidDivision)
+	}
+	var orgKey uint
+	if division.OrganizationID != nil {
+		orgKey = *division.OrganizationID
+	}
+	if err := lock.Tx(tx, fmt.Sprintf("division:%d:%s", orgKey, strings.ToLower(division.Name))); err != nil {
+		return err
+	}
+	var taken int64
+	query := tx.Model(&Division{}).Where("LOWER(name) = LOWER(?) AND id <> ?", division.Name, division.ID)
+	if err := utils.OrgScope(query, division.OrganizationID).Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check division names: %w", err)
+	}
+	if taken > 0 {
+		return ErrNameTaken
+	}
+	if division.HeadID != nil {
+		var heads int64
+		if err := utils.OrgScope(tx.Model(&employee.Employee{}), division.OrganizationID).Where("id = ?", *division.HeadID).Count(&heads).Error; err != nil {
+			return fmt.Errorf("failed to load employee %d: %w", *division.HeadID, err)
+		}
+		if heads == 0 {
+			return fmt.Errorf("%w: head %d is not an employee", ErrInvalidDivision, *division.HeadID)
+		}
+	}
+	if division.ParentID == nil {
+		return nil
+	}
+	next := *division.ParentID
+	for depth := 0; depth < maxDepth; depth++ {
+		if division.ID != 0 && next == division.ID {
+			return fmt.Errorf("%w: the parent is a subdivision of this division", ErrInvalidDivision)
+		}
+		var parent Division
+		err := utils.OrgScope(tx.Select("id", "parent_id"), division.OrganizationID).First(&parent, next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if next == *division.ParentID {
+				return fmt.Errorf("%w: parent %d not found", ErrInvalidDivision, next)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load division %d: %w", next, err)
+		}
+		if parent.ParentID == nil {
+			return nil
+		}
+		next = *parent.ParentID
+	}
+	return fmt.Errorf("%w: division tree deeper than %d levels", ErrInvalidDivision, maxDepth)
+}
+
+// summaryColumns selects a division with the username of its head and its number of members.
+const summaryColumns = "divisions.*, users.username AS head_username, " +
+	"(SELECT COUNT(*) FROM employees WHERE employees.division_id = divisions.id AND employees.deleted_at IS NULL) AS member_count"
+
+// summaries selects divisions joined with their heads, within the organization scope.
+func (s *service) summaries(db *gorm.DB, orgID *uint) *gorm.DB {
+	query := db.Model(&Division{}).
+		Joins("LEFT JOIN employees heads ON heads.id = divisions.head_id AND heads.deleted_at IS NULL").
+		Joins("LEFT JOIN users ON users.id = heads.user_id")
+	if orgID != nil {
+		query = query.Where("divisions.organization_id = ?", *orgID)
+	}
+	return query
+}
+
+// load fetches one division, within the organization scope.
+func (s *service) load(db *gorm.DB, orgID *uint, id uint) (*Summary, error) {
+	var division Summary
+	if err := s.summaries(db, orgID).Select(summaryColumns).Where("divisions.id = ?", id).Take(&division).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &division, nil
+}
+
+func apply(division *Division, req Request) {
+	division.Name = strings.TrimSpace(req.Name)
+	division.HeadID = req.HeadID
+	division.ParentID = req.ParentID
+}
@@ -0,0 +1,238 @@
+// prometheus/backend/internal/document/file.go
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxFileSize is the largest document upload accepted, in bytes.
+const MaxFileSize = 25 << 20
+
+// fileURLTTL is how long a signed document URL stays valid.
+const fileURLTTL = 5 * time.Minute
+
+// fileTypes maps the accepted document types, as sniffed from the content, to file extensions.
+var fileTypes = map[string]string{
+	"application/pdf":           ".pdf",
+	"image/png":                 ".png",
+	"image/jpeg":                ".jpg",
+	"image/webp":                ".webp",
+	"text/plain; charset=utf-8": ".txt",
+}
+
+// ErrFileTooLarge is returned for uploads above MaxFileSize.
+var ErrFileTooLarge = fmt.Errorf("documents must not exceed %d MB", MaxFileSize>>20)
+
+// ErrFileType is returned for uploads that aren't PDFs, images or plain text.
+var ErrFileType = errors.New("documents must be PDFs, PNG, JPEG or WebP images, or UTF-8 text")
+
+// Create stores the file before the transaction filing the document, and removes it again if that fails.
+func (s *service) Create(ctx context.Context, actor audit.Actor, orgID *uint, req Request, r io.ReadSeeker, size int64, name string) (*Document, error) {
+	document := Document{OrganizationID: orgID, Latest: 1, CreatedBy: actor.UserID}
+	if err := s.apply(s.db.WithContext(ctx), &document, req); err != nil {
+		return nil, err
+	}
+	revision, err := s.store(ctx, orgID, r, size, name)
+	if err != nil {
+		return nil, err
+	}
+	revision.Number, revision.Note, revision.UploadedBy = 1, strings.TrimSpace(req.Note), actor.UserID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.quota.ReserveStorage(tx, orgValue(orgID), size); err != nil {
+			return err
+		}
+		if err := tx.Create(&document).Error; err != nil {
+			return fmt.Errorf("failed to create document: %w", err)
+		}
+		revision.DocumentID = document.ID
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to store document revision: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document.create", EntityType: "document", EntityID: fmt.Sprintf("%d", document.ID),
+			After: map[string]interface{}{"document": document, "revision": revision},
+		})
+	})
+	if err != nil {
+		s.remove(revision.Key)
+		return nil, err
+	}
+	return s.GetDocument(orgID, Viewer{HR: true}, document.ID)
+}
+
+// AddRevision keeps the earlier revisions, and their storage, so the history stays downloadable until the
+// document is deleted.
+func (s *service) AddRevision(ctx context.Context, actor audit.Actor, orgID *uint, id, expectedVersion uint, r io.ReadSeeker, size int64, name, note string) (*Document, error) {
+	if _, err := s.GetDocument(orgID, Viewer{HR: true}, id); err != nil {
+		return nil, err
+	}
+	revision, err := s.store(ctx, orgID, r, size, name)
+	if err != nil {
+		return nil, err
+	}
+	revision.DocumentID, revision.Note, revision.UploadedBy = id, strings.TrimSpace(note), actor.UserID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		document, err := lockDocument(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		revision.Number = document.Latest + 1
+		if err := s.quota.ReserveStorage(tx, orgValue(orgID), size); err != nil {
+			return err
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to store document revision: %w", err)
+		}
+		if err := utils.UpdateWithVersion(tx, &Document{}, id, expectedVersion, map[string]interface{}{
+			"latest": revision.Number,
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document.revise", EntityType: "document", EntityID: fmt.Sprintf("%d", id), After: revision,
+		})
+	})
+	if err != nil {
+		s.remove(revision.Key)
+		return nil, err
+	}
+	return s.GetDocument(orgID, Viewer{HR: true}, id)
+}
+
+// Delete gives back the storage of every revision; the files themselves are removed once the
+// transaction has committed.
+func (s *service) Delete(ctx context.Context, actor audit.Actor, orgID *uint, id uint) error {
+	var revisions []Revision
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := lockDocument(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("document_id = ?", id).Find(&revisions).Error; err != nil {
+			return fmt.Errorf("failed to load document revisions: %w", err)
+		}
+		var bytes int64
+		for _, r := range revisions {
+			bytes += r.Size
+		}
+		if err := s.quota.ReleaseStorage(tx, orgValue(orgID), bytes); err != nil {
+			return fmt.Errorf("failed to release document storage: %w", err)
+		}
+		if err := tx.Where("document_id = ?", id).Delete(&Revision{}).Error; err != nil {
+			return fmt.Errorf("failed to delete document revisions: %w", err)
+		}
+		if err := tx.Delete(&Document{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete document %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document.delete", EntityType: "document", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range revisions {
+		s.remove(r.Key)
+	}
+	return nil
+}
+
+// File prefers a signed URL so the storage serves the file; backends without them stream it through the API.
+func (s *service) File(ctx context.Context, orgID *uint, viewer Viewer, id uint, number int) (*File, error) {
+	var document Document
+	if err := utils.OrgScope(s.db.WithContext(ctx), orgID).First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(s.db.WithContext(ctx), orgID, viewer, document); err != nil {
+		return nil, err
+	}
+	if number == 0 {
+		number = document.Latest
+	}
+	var revision Revision
+	if err := s.db.WithContext(ctx).Where("document_id = ? AND number = ?", id, number).First(&revision).Error; err != nil {
+		return nil, err
+	}
+
+	url, err := s.files.SignedURL(ctx, revision.Key, fileURLTTL)
+	if err == nil {
+		return &File{URL: url, Name: revision.Name}, nil
+	}
+	if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+		return nil, err
+	}
+	body, contentType, err := s.files.Open(ctx, revision.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &File{Body: body, ContentType: contentType, Name: revision.Name}, nil
+}
+
+// store sniffs the type from the content, ignoring the client's declared type, and puts the file in storage.
+func (s *service) store(ctx context.Context, orgID *uint, r io.ReadSeeker, size int64, name string) (*Revision, error) {
+	if size > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrFileType
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := fileTypes[contentType]
+	if !ok {
+		return nil, ErrFileType
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	key := fmt.Sprintf("documents/%s/%s%s", orgKey(orgID), uuid.NewString(), ext)
+	if err := s.files.Put(ctx, key, io.LimitReader(r, MaxFileSize), size, contentType); err != nil {
+		return nil, err
+	}
+	return &Revision{Key: key, Name: fileName(name, ext), ContentType: contentType, Size: size}, nil
+}
+
+// remove deletes a file that is no longer referenced. Failures only leave an orphaned file behind.
+func (s *service) remove(key string) {
+	if err := s.files.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to delete document file %s: %v", key, err)
+	}
+}
+
+// fileName keeps the base of the uploaded file's name for display, falling back to "document".
+func fileName(name, ext string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "document" + ext
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+	return name
+}
+
+// orgValue returns the organization as the storage quota names it, 0 for none.
+func orgValue(orgID *uint) uint {
+	if orgID == nil {
+		return 0
+	}
+	return *orgID
+}
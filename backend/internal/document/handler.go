@@ -0,0 +1,492 @@
+// prometheus/backend/internal/document/handler.go
+package document
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hrRoles see every document when held globally.
+var hrRoles = []string{"god-admin", "admin", "hr"}
+
+// Handler handles HTTP requests for documents and their categories.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCategories returns the organization's document categories.
+// @Summary List document categories
+// @Tags Documents
+// @Produce json
+// @Success 200 {array} Category
+// @Router /hr/document-categories [get]
+func (h *Handler) ListCategories(c *gin.Context) {
+	categories, err := h.service.Categories(utils.OrganizationFromContext(c))
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Document categories fetched successfully", categories)
+}
+
+// CreateCategory adds a document category.
+// @Summary Create a document category
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Param category body CategoryRequest true "Category"
+// @Success 201 {object} Category
+// @Failure 400 {object} utils.ErrorResponse "Invalid category"
+// @Failure 409 {object} utils.ErrorResponse "Name already taken"
+// @Router /hr/document-categories [post]
+func (h *Handler) CreateCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	category, err := h.service.CreateCategory(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, category.UpdatedAt, category.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Document category created successfully", category)
+}
+
+// UpdateCategory replaces a category's name and default visibility. Documents filed under it keep theirs.
+// @Summary Update a document category
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param category body CategoryRequest true "Category"
+// @Success 200 {object} Category
+// @Failure 400 {object} utils.ErrorResponse "Invalid category"
+// @Failure 404 {object} utils.ErrorResponse "Category not found"
+// @Failure 409 {object} utils.ErrorResponse "Name already taken"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/document-categories/{id} [put]
+func (h *Handler) UpdateCategory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	categories, err := h.service.Categories(orgID)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	i := slices.IndexFunc(categories, func(category Category) bool { return category.ID == id })
+	if i < 0 {
+		sendDocumentError(c, gorm.ErrRecordNotFound)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, categories[i].UpdatedAt, categories[i].Version)
+	if !ok {
+		return
+	}
+	category, err := h.service.UpdateCategory(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, category.UpdatedAt, category.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Document category updated successfully", category)
+}
+
+// DeleteCategory removes a category without documents.
+// @Summary Delete a document category
+// @Tags Documents
+// @Param id path int true "Category ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Category not found"
+// @Failure 409 {object} utils.ErrorResponse "The category still has documents"
+// @Router /hr/document-categories/{id} [delete]
+func (h *Handler) DeleteCategory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteCategory(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDocuments returns the organization's documents, last changed first, or soonest to expire first.
+// @Summary List documents
+// @Tags Documents
+// @Produce json
+// @Param category_id query int false "Category ID"
+// @Param employee_id query int false "Employee ID"
+// @Param search query string false "Title contains"
+// @Param expiring_within query int false "Only documents expiring within this many days, including expired ones"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/documents [get]
+func (h *Handler) ListDocuments(c *gin.Context) {
+	filter := Filter{Search: c.Query("search")}
+	var ok bool
+	if filter.CategoryID, ok = utils.OptionalUintQuery(c, "category_id"); !ok {
+		return
+	}
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if raw := c.Query("expiring_within"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 || days > 3660 {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid expiring_within parameter")
+			return
+		}
+		filter.ExpiringWithin = &days
+	}
+	page := utils.ParsePagination(c)
+	documents, total, err := h.service.Documents(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Documents fetched successfully", page.Response(documents, total))
+}
+
+// MyDocuments returns the caller's documents they may see, and those shared with everyone.
+// @Summary List my documents
+// @Tags Documents
+// @Produce json
+// @Success 200 {array} Document
+// @Router /me/documents [get]
+func (h *Handler) MyDocuments(c *gin.Context) {
+	documents, err := h.service.MyDocuments(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Documents fetched successfully", documents)
+}
+
+// TeamDocuments returns the documents shared with managers of the employees the caller manages.
+// @Summary List my team's documents
+// @Tags Documents
+// @Produce json
+// @Param employee_id query int false "Employee ID"
+// @Success 200 {array} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/documents [get]
+func (h *Handler) TeamDocuments(c *gin.Context) {
+	employeeID, ok := utils.OptionalUintQuery(c, "employee_id")
+	if !ok {
+		return
+	}
+	documents, err := h.service.TeamDocuments(utils.OrganizationFromContext(c), viewer(c), employeeID)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Documents fetched successfully", documents)
+}
+
+// GetDocument returns a document with its revisions, if the caller may see it. The ETag and Last-Modified
+// headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get a document
+// @Tags Documents
+// @Produce json
+// @Param id path int true "Document ID"
+// @Success 200 {object} Document
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Router /me/documents/{id} [get]
+// @Router /manager/documents/{id} [get]
+// @Router /hr/documents/{id} [get]
+func (h *Handler) GetDocument(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	document, err := h.service.GetDocument(utils.OrganizationFromContext(c), viewer(c), id)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Document fetched successfully", document)
+}
+
+// CreateDocument files a document with its first revision.
+// @Summary Upload a document
+// @Description PDFs, PNG, JPEG or WebP images, or UTF-8 text, at most 25 MB, as the multipart field "file",
+// @Description with the document's fields next to it. Files count against the organization's storage quota.
+// @Tags Documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File"
+// @Param category_id formData int true "Category ID"
+// @Param employee_id formData int false "Employee ID"
+// @Param title formData string true "Title"
+// @Param description formData string false "Description"
+// @Param visibility formData string false "Visibility, defaults to the category's" Enums(hr, owner, managers, everyone)
+// @Param expires_on formData string false "Expiry date (YYYY-MM-DD)"
+// @Param note formData string false "Revision note"
+// @Success 201 {object} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid document, missing file, or unknown category or employee"
+// @Failure 402 {object} utils.ErrorResponse "Storage quota exceeded"
+// @Failure 413 {object} utils.ErrorResponse "File too large"
+// @Failure 415 {object} utils.ErrorResponse "Unsupported file type"
+// @Router /hr/documents [post]
+func (h *Handler) CreateDocument(c *gin.Context) {
+	header, file, ok := uploadedFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+	var req Request
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	document, err := h.service.Create(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), req,
+		file, header.Size, header.Filename)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Document uploaded successfully", document)
+}
+
+// UpdateDocument replaces a document's fields: category, employee, title, visibility and expiry.
+// @Summary Update a document
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param document body Request true "Document"
+// @Success 200 {object} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid document, or unknown category or employee"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/documents/{id} [put]
+func (h *Handler) UpdateDocument(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	expectedVersion, ok := h.precondition(c, orgID, id)
+	if !ok {
+		return
+	}
+	document, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Document updated successfully", document)
+}
+
+// AddRevision replaces a document's file, keeping the earlier ones as its history.
+// @Summary Upload a new revision of a document
+// @Description Same file types and size limit as uploading a document, as the multipart field "file".
+// @Tags Documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param file formData file true "File"
+// @Param note formData string false "What changed"
+// @Success 200 {object} Document
+// @Failure 400 {object} utils.ErrorResponse "Missing file"
+// @Failure 402 {object} utils.ErrorResponse "Storage quota exceeded"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Failure 413 {object} utils.ErrorResponse "File too large"
+// @Failure 415 {object} utils.ErrorResponse "Unsupported file type"
+// @Router /hr/documents/{id}/revisions [post]
+func (h *Handler) AddRevision(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	header, file, ok := uploadedFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+	note := c.PostForm("note")
+	if len([]rune(note)) > 500 {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "The note must not exceed 500 characters")
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	expectedVersion, ok := h.precondition(c, orgID, id)
+	if !ok {
+		return
+	}
+	document, err := h.service.AddRevision(c.Request.Context(), audit.ActorFromContext(c), orgID, id, expectedVersion,
+		file, header.Size, header.Filename, note)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Document revision uploaded successfully", document)
+}
+
+// DeleteDocument removes a document with all its revisions.
+// @Summary Delete a document
+// @Tags Documents
+// @Param id path int true "Document ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Router /hr/documents/{id} [delete]
+func (h *Handler) DeleteDocument(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetFile serves a document's file, redirecting to a signed storage URL when the backend supports them.
+// @Summary Download a document
+// @Tags Documents
+// @Produce application/pdf
+// @Produce image/png
+// @Produce image/jpeg
+// @Param id path int true "Document ID"
+// @Param revision query int false "Revision number, defaults to the current one"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a signed URL"
+// @Failure 404 {object} utils.ErrorResponse "Document or revision not found"
+// @Router /me/documents/{id}/file [get]
+// @Router /manager/documents/{id}/file [get]
+// @Router /hr/documents/{id}/file [get]
+func (h *Handler) GetFile(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	number := 0
+	if raw := c.Query("revision"); raw != "" {
+		var err error
+		if number, err = strconv.Atoi(raw); err != nil || number < 1 {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid revision parameter")
+			return
+		}
+	}
+	file, err := h.service.File(c.Request.Context(), utils.OrganizationFromContext(c), viewer(c), id, number)
+	if err != nil {
+		sendDocumentError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	if file.URL != "" {
+		c.Redirect(http.StatusFound, file.URL)
+		return
+	}
+	defer file.Body.Close()
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, -1, file.ContentType, file.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", file.Name),
+	})
+}
+
+// precondition checks If-Match / If-Unmodified-Since against the document's current version.
+func (h *Handler) precondition(c *gin.Context, orgID *uint, id uint) (uint, bool) {
+	current, err := h.service.GetDocument(orgID, Viewer{HR: true}, id)
+	if err != nil {
+		sendDocumentError(c, err)
+		return 0, false
+	}
+	return utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+}
+
+// uploadedFile opens the multipart field "file", answering the request itself when it is missing or too large.
+func uploadedFile(c *gin.Context) (*multipart.FileHeader, multipart.File, bool) {
+	// Leave room for the multipart envelope and the document's fields around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxFileSize+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendDocumentError(c, ErrFileTooLarge)
+			return nil, nil, false
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Missing multipart file field \"file\"")
+		return nil, nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Could not read the uploaded file")
+		return nil, nil, false
+	}
+	return header, file, true
+}
+
+func viewer(c *gin.Context) Viewer {
+	userID, hr, leads := middleware.LineManagement(c)
+	return Viewer{UserID: userID, HR: hr, Leads: leads}
+}
+
+// sendDocumentError maps service errors to HTTP status codes.
+func sendDocumentError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidDocument):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCategoryTaken), errors.Is(err, ErrCategoryInUse):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrFileTooLarge):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrFileType):
+		utils.SendErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	case errors.Is(err, lock.ErrLocked):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,111 @@
+// prometheus/backend/internal/document/model.go
+package document
+
+import (
+	"io"
+	"time"
+)
+
+// Visibility is who besides HR sees a document.
+type Visibility string
+
+const (
+	VisibilityHR       Visibility = "hr"       // HR only
+	VisibilityOwner    Visibility = "owner"    // Also the employee it is attached to, e.g. their contract
+	VisibilityManagers Visibility = "managers" // Also the employee's managers, e.g. their certificates
+	VisibilityEveryone Visibility = "everyone" // Everyone in the organization, e.g. a handbook
+)
+
+// Category groups documents, such as contracts, policies or certificates, and sets the visibility of the
+// documents filed under it unless they set their own.
+type Category struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"2"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name" example:"Contracts"` // Unique within the organization, ignoring case
+	Visibility     Visibility `gorm:"type:varchar(20);not null" json:"visibility" example:"owner"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName keeps categories next to their documents.
+func (Category) TableName() string { return "document_categories" }
+
+// Document is a file HR keeps, attached to an employee or to the organization as a whole. Replacing the
+// file adds a revision; earlier revisions stay downloadable as its history.
+type Document struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"31"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CategoryID     uint       `gorm:"not null;index" json:"category_id" example:"2"`
+	Category       string     `gorm:"-" json:"category,omitempty" example:"Contracts"`
+	EmployeeID     *uint      `gorm:"index" json:"employee_id,omitempty" example:"12"` // Empty for organization-wide documents
+	DisplayName    string     `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Title          string     `gorm:"type:varchar(200);not null" json:"title" example:"Employment contract"`
+	Description    string     `gorm:"type:varchar(1000)" json:"description,omitempty" example:"Signed on hiring"`
+	Visibility     Visibility `gorm:"type:varchar(20);not null" json:"visibility" example:"owner"`
+	ExpiresOn      *time.Time `gorm:"type:date;index" json:"expires_on,omitempty" example:"2027-08-31T00:00:00Z"`
+	Expired        bool       `gorm:"-" json:"expired"`
+	Latest         int        `gorm:"not null" json:"latest" example:"2"`            // Number of the current revision
+	Revisions      []Revision `gorm:"-" json:"revisions,omitempty"`                  // Newest first, on single documents
+	CreatedBy      *uint      `json:"created_by,omitempty" example:"7"`              // User ID
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Revision is one uploaded file of a document.
+type Revision struct {
+	ID          uint      `gorm:"primaryKey" json:"id" example:"44"`
+	DocumentID  uint      `gorm:"not null;uniqueIndex:idx_document_revision" json:"document_id" example:"31"`
+	Number      int       `gorm:"not null;uniqueIndex:idx_document_revision" json:"number" example:"2"`
+	Key         string    `gorm:"type:varchar(255);not null" json:"-"` // Storage key
+	Name        string    `gorm:"type:varchar(255);not null" json:"name" example:"contract-signed.pdf"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type" example:"application/pdf"`
+	Size        int64     `gorm:"not null" json:"size" example:"184320"`
+	Note        string    `gorm:"type:varchar(500)" json:"note,omitempty" example:"Amended notice period"`
+	UploadedBy  *uint     `json:"uploaded_by,omitempty" example:"7"` // User ID
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName keeps revisions next to their documents.
+func (Revision) TableName() string { return "document_revisions" }
+
+// Viewer is who is asking for documents, for visibility checks.
+type Viewer struct {
+	UserID uint
+	HR     bool   // Holds an HR role globally: sees every document
+	Leads  []uint // Divisions led through a division-scoped manager role; headed divisions are added by the service
+}
+
+// File is a revision's content: either a signed URL to redirect to, or the content to serve.
+type File struct {
+	URL         string
+	Body        io.ReadCloser
+	ContentType string
+	Name        string
+}
+
+// CategoryRequest creates a category or replaces its fields.
+type CategoryRequest struct {
+	Name       string     `json:"name" binding:"required,max=100" example:"Contracts"`
+	Visibility Visibility `json:"visibility" binding:"required,oneof=hr owner managers everyone" example:"owner"`
+}
+
+// Request creates a document, as form fields next to its file, or replaces its fields, as JSON.
+type Request struct {
+	CategoryID  uint       `form:"category_id" json:"category_id" binding:"required" example:"2"`
+	EmployeeID  *uint      `form:"employee_id" json:"employee_id,omitempty" example:"12"`
+	Title       string     `form:"title" json:"title" binding:"required,max=200" example:"Employment contract"`
+	Description string     `form:"description" json:"description,omitempty" binding:"max=1000" example:"Signed on hiring"`
+	Visibility  Visibility `form:"visibility" json:"visibility,omitempty" binding:"omitempty,oneof=hr owner managers everyone" example:"owner"` // Defaults to the category's
+	ExpiresOn   string     `form:"expires_on" json:"expires_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2027-08-31"`
+	Note        string     `form:"note" json:"-" binding:"max=500" example:"Signed copy"` // Of the first revision
+}
+
+// Filter narrows a document listing.
+type Filter struct {
+	CategoryID     *uint
+	EmployeeID     *uint
+	Search         string // Case-insensitive match on the title
+	ExpiringWithin *int   // Days from today; includes documents expired already
+}
@@ -0,0 +1,69 @@
+// prometheus/backend/internal/document/module.go
+package document
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/internal/search"
+)
+
+// ModuleName is the name of the documents module.
+const ModuleName = "documents"
+
+// documentModule owns the documents HR keeps on employees and the organization, with their revisions.
+type documentModule struct {
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the documents module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &documentModule{service: svc, handler: NewHandler(svc)}
+}
+
+func (m *documentModule) Name() string { return ModuleName }
+
+func (m *documentModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *documentModule) Models() []any {
+	return []any{&Category{}, &Document{}, &Revision{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR files and maintains documents; employees read their
+// own and managers their reports' as far as each document's visibility allows.
+func (m *documentModule) RegisterRoutes(api *routing.Group) {
+	documentsAPI := api.InModule(plan.ModuleDocuments)
+	documentsAPI.GET("/me/documents", routing.Authenticated(), m.handler.MyDocuments)
+	documentsAPI.GET("/me/documents/:id", routing.Authenticated(), m.handler.GetDocument)
+	documentsAPI.GET("/me/documents/:id/file", routing.Authenticated(), m.handler.GetFile)
+
+	documentsAPI.GET("/manager/documents", routing.Policy(), m.handler.TeamDocuments)
+	documentsAPI.GET("/manager/documents/:id", routing.Policy(), m.handler.GetDocument)
+	documentsAPI.GET("/manager/documents/:id/file", routing.Policy(), m.handler.GetFile)
+
+	documentsAPI.GET("/hr/document-categories", routing.Policy(), m.handler.ListCategories)
+	documentsAPI.POST("/hr/document-categories", routing.Policy(), m.handler.CreateCategory)
+	documentsAPI.PUT("/hr/document-categories/:id", routing.Policy(), m.handler.UpdateCategory)
+	documentsAPI.DELETE("/hr/document-categories/:id", routing.Policy(), m.handler.DeleteCategory)
+	documentsAPI.GET("/hr/documents", routing.Policy(), m.handler.ListDocuments)
+	documentsAPI.POST("/hr/documents", routing.Policy(), m.handler.CreateDocument)
+	documentsAPI.GET("/hr/documents/:id", routing.Policy(), m.handler.GetDocument)
+	documentsAPI.PUT("/hr/documents/:id", routing.Policy(), m.handler.UpdateDocument)
+	documentsAPI.DELETE("/hr/documents/:id", routing.Policy(), m.handler.DeleteDocument)
+	documentsAPI.POST("/hr/documents/:id/revisions", routing.Policy(), m.handler.AddRevision)
+	documentsAPI.GET("/hr/documents/:id/file", routing.Policy(), m.handler.GetFile)
+}
+
+// PrivacySources implements privacy.Contributor: the documents attached to the user's employee records are
+// exported with their files, and deleted on anonymization.
+func (m *documentModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{m.service.PrivacySource()}
+}
+
+// SearchProviders implements search.Contributor.
+func (m *documentModule) SearchProviders() []search.Provider {
+	return []search.Provider{search.NewProvider(SearchType, plan.ModuleDocuments, m.service.Search)}
+}
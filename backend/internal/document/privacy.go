@@ -0,0 +1,76 @@
+// prometheus/backend/internal/document/privacy.go
+package document
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/privacy"
+
+	"gorm.io/gorm"
+)
+
+// PrivacySource exports the documents attached to the user's employee records, with every revision's file,
+// and deletes them on anonymization, giving back their storage. Organization-wide documents aren't the
+// user's and are kept.
+func (s *service) PrivacySource() privacy.FileSource {
+	return privacy.NewFileSource("documents", s.exportPersonal, s.anonymizePersonal, s.personalFiles)
+}
+
+func (s *service) exportPersonal(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var documents []Document
+	if err := db.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(db, userID)).Order("id").Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to export documents: %w", err)
+	}
+	for i := range documents {
+		if err := db.WithContext(ctx).Where("document_id = ?", documents[i].ID).Order("number").
+			Find(&documents[i].Revisions).Error; err != nil {
+			return nil, fmt.Errorf("failed to export document revisions: %w", err)
+		}
+	}
+	return documents, nil
+}
+
+func (s *service) personalFiles(ctx context.Context, db *gorm.DB, userID uint) ([]privacy.File, error) {
+	var revisions []Revision
+	if err := db.WithContext(ctx).Where("document_id IN (?)", personalDocuments(db, userID)).
+		Order("document_id, number").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list document files: %w", err)
+	}
+	files := make([]privacy.File, 0, len(revisions))
+	for _, r := range revisions {
+		files = append(files, privacy.File{Name: fmt.Sprintf("%d/%d-%s", r.DocumentID, r.Number, r.Name), Key: r.Key})
+	}
+	return files, nil
+}
+
+func (s *service) anonymizePersonal(ctx context.Context, tx *gorm.DB, userID uint) error {
+	tx = tx.WithContext(ctx)
+	var usage []struct {
+		OrganizationID *uint
+		Bytes          int64
+	}
+	if err := tx.Table("document_revisions").Joins("JOIN documents ON documents.id = document_revisions.document_id").
+		Where("documents.employee_id IN (?)", employee.OfUser(tx, userID)).
+		Select("documents.organization_id, SUM(document_revisions.size) AS bytes").
+		Group("documents.organization_id").Scan(&usage).Error; err != nil {
+		return fmt.Errorf("failed to sum document storage: %w", err)
+	}
+	for _, u := range usage {
+		if err := s.quota.ReleaseStorage(tx, orgValue(u.OrganizationID), u.Bytes); err != nil {
+			return fmt.Errorf("failed to release document storage: %w", err)
+		}
+	}
+	if err := tx.Where("document_id IN (?)", personalDocuments(tx, userID)).Delete(&Revision{}).Error; err != nil {
+		return fmt.Errorf("failed to delete document revisions: %w", err)
+	}
+	if err := tx.Where("employee_id IN (?)", employee.OfUser(tx, userID)).Delete(&Document{}).Error; err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// personalDocuments selects the IDs of the documents attached to the user's employee records.
+func personalDocuments(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Table("documents").Select("id").Where("employee_id IN (?)", employee.OfUser(db, userID))
+}
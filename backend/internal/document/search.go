@@ -0,0 +1,57 @@
+// prometheus/backend/internal/document/search.go
+package document
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/utils"
+)
+
+// SearchType is the type of document search hits.
+const SearchType = "document"
+
+// Search finds documents by title and description for the organization-wide search. HR finds every
+// document; everyone else the organization-wide ones shared with everyone and their own, and managers
+// those they may see of their reports, as MyDocuments and TeamDocuments list them.
+func (s *service) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	db := s.db.WithContext(ctx)
+	match, args := q.Condition("title", "description")
+	query := utils.OrgScope(db, q.OrgID).Where(match, args...)
+	hr := q.Viewer.HasRole(hrRoles...)
+	var self uint
+	if !hr {
+		var leads []uint
+		var err error
+		self, leads, err = s.leads(db, q.OrgID, Viewer{UserID: q.Viewer.UserID, Leads: q.Viewer.Manages})
+		if err != nil {
+			return nil, err
+		}
+		visible := db.Where("employee_id IS NULL AND visibility = ?", VisibilityEveryone)
+		if self != 0 {
+			visible = visible.Or("employee_id = ? AND visibility <> ?", self, VisibilityHR)
+		}
+		if self != 0 || len(leads) > 0 {
+			reports := utils.OrgScope(db.Table("employees").Where("deleted_at IS NULL AND id <> ?", self), q.OrgID).
+				Where(db.Where("manager_id = ?", self).Or("division_id IN ?", leads))
+			visible = visible.Or("employee_id IN (?) AND visibility IN ?", reports.Select("id"),
+				[]Visibility{VisibilityManagers, VisibilityEveryone})
+		}
+		query = query.Where(visible)
+	}
+	var documents []Document
+	if err := query.Order("updated_at DESC, id DESC").Limit(q.Limit).Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	hits := make([]search.Hit, 0, len(documents))
+	for _, d := range documents {
+		link := fmt.Sprintf("/api/v1/me/documents/%d", d.ID)
+		if hr {
+			link = fmt.Sprintf("/api/v1/hr/documents/%d", d.ID)
+		} else if d.EmployeeID != nil && *d.EmployeeID != self {
+			link = fmt.Sprintf("/api/v1/manager/documents/%d", d.ID)
+		}
+		hits = append(hits, search.Hit{Type: SearchType, ID: d.ID, Title: d.Title, Snippet: search.Snippet(d.Description, q.Term, 120), Link: link})
+	}
+	return hits, nil
+}
@@ -0,0 +1,475 @@
+// prometheus/backend/internal/document/service.go
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidDocument is returned for documents and categories that fail validation.
+	ErrInvalidDocument = errors.New("invalid document")
+	// ErrCategoryTaken is returned when the organization already has a category with the name.
+	ErrCategoryTaken = errors.New("document category name already taken")
+	// ErrCategoryInUse is returned when deleting a category that still has documents.
+	ErrCategoryInUse = errors.New("the category still has documents")
+)
+
+// Quota accounts for document files against the organization's storage quota. plan.Service implements it.
+type Quota interface {
+	ReserveStorage(tx *gorm.DB, orgID uint, bytes int64) error
+	ReleaseStorage(tx *gorm.DB, orgID uint, bytes int64) error
+}
+
+// Service manages document categories and the documents HR keeps on employees and the organization, with
+// their revision history. Who besides HR sees a document follows its visibility. orgID scopes every call
+// to one organization (nil = platform users, outside any organization).
+type Service interface {
+	Categories(orgID *uint) ([]Category, error)
+	CreateCategory(actor audit.Actor, orgID *uint, req CategoryRequest) (*Category, error)
+	UpdateCategory(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CategoryRequest) (*Category, error)
+	// DeleteCategory removes a category without documents.
+	DeleteCategory(actor audit.Actor, orgID *uint, id uint) error
+
+	// Documents lists the organization's documents for HR, last changed first.
+	Documents(orgID *uint, filter Filter, page utils.Pagination) ([]Document, int64, error)
+	// MyDocuments lists the documents attached to the user's employee record that they may see, and the
+	// organization-wide ones shared with everyone.
+	MyDocuments(orgID *uint, userID uint) ([]Document, error)
+	// TeamDocuments lists the documents managers may see of the employees viewer manages, or of one of them.
+	TeamDocuments(orgID *uint, viewer Viewer, employeeID *uint) ([]Document, error)
+	// GetDocument returns a document with its revisions, if viewer may see it.
+	GetDocument(orgID *uint, viewer Viewer, id uint) (*Document, error)
+	// Create files a document with its first revision.
+	Create(ctx context.Context, actor audit.Actor, orgID *uint, req Request, r io.ReadSeeker, size int64, name string) (*Document, error)
+	// Update replaces a document's fields; its file is replaced through AddRevision.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Document, error)
+	// AddRevision replaces a document's file, keeping the earlier ones as its history.
+	AddRevision(ctx context.Context, actor audit.Actor, orgID *uint, id, expectedVersion uint, r io.ReadSeeker, size int64, name, note string) (*Document, error)
+	// Delete removes a document with all its revisions.
+	Delete(ctx context.Context, actor audit.Actor, orgID *uint, id uint) error
+	// File returns the content of one of a document's revisions (0 = the current one), if viewer may see it.
+	File(ctx context.Context, orgID *uint, viewer Viewer, id uint, number int) (*File, error)
+	// Search finds documents for the organization-wide search, see search.Provider.
+	Search(ctx context.Context, q search.Query) ([]search.Hit, error)
+	// PrivacySource is the personal data kept in documents, see privacy.FileSource.
+	PrivacySource() privacy.FileSource
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	files     storage.Storage
+	quota     Quota
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Files are kept in files and counted against quota;
+// employee names are resolved through employees.
+func NewService(db *gorm.DB, employees employee.Service, files storage.Storage, quota Quota, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, files: files, quota: quota, auditor: auditor}
+}
+
+func (s *service) Categories(orgID *uint) ([]Category, error) {
+	categories := []Category{}
+	if err := utils.OrgScope(s.db, orgID).Order("LOWER(name), id").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to list document categories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *service) CreateCategory(actor audit.Actor, orgID *uint, req CategoryRequest) (*Category, error) {
+	category := Category{OrganizationID: orgID, Name: strings.TrimSpace(req.Name), Visibility: req.Visibility}
+	if category.Name == "" {
+		return nil, fmt.Errorf("%w: a name is required", ErrInvalidDocument)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryName(tx, orgID, 0, category.Name); err != nil {
+			return err
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("failed to create document category: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document_category.create", EntityType: "document_category", EntityID: fmt.Sprintf("%d", category.ID), After: category,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory replaces the category's fields if it is still at expectedVersion (optimistic locking).
+// Documents filed under it keep the visibility they have.
+func (s *service) UpdateCategory(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CategoryRequest) (*Category, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a name is required", ErrInvalidDocument)
+	}
+	var updated Category
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Category
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := checkCategoryName(tx, orgID, id, name); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Category{}, id, expectedVersion, map[string]interface{}{
+			"name": name, "visibility": req.Visibility,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload document category %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document_category.update", EntityType: "document_category", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteCategory(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Category
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&Document{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count documents: %w", err)
+		}
+		if count > 0 {
+			return ErrCategoryInUse
+		}
+		if err := tx.Delete(&Category{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete document category %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document_category.delete", EntityType: "document_category", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Documents(orgID *uint, filter Filter, page utils.Pagination) ([]Document, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Document{}), orgID)
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		query = query.Where("LOWER(title) LIKE ?", utils.ContainsPattern(strings.ToLower(search)))
+	}
+	if filter.ExpiringWithin != nil {
+		query = query.Where("expires_on <= ?", today().AddDate(0, 0, *filter.ExpiringWithin))
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	order := "updated_at DESC, id DESC"
+	if filter.ExpiringWithin != nil {
+		order = "expires_on, id"
+	}
+	documents := []Document{}
+	if err := query.Order(order).Scopes(page.Scope).Find(&documents).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list documents: %w", err)
+	}
+	if err := s.describe(orgID, documents); err != nil {
+		return nil, 0, err
+	}
+	return documents, total, nil
+}
+
+func (s *service) MyDocuments(orgID *uint, userID uint) ([]Document, error) {
+	var self []uint
+	if err := utils.OrgScope(s.db.Table("employees").Where("user_id = ? AND deleted_at IS NULL", userID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the caller's employee record: %w", err)
+	}
+	query := utils.OrgScope(s.db, orgID).Where("employee_id IS NULL AND visibility = ?", VisibilityEveryone)
+	if len(self) > 0 {
+		query = utils.OrgScope(s.db, orgID).Where(s.db.Where("employee_id IS NULL AND visibility = ?", VisibilityEveryone).
+			Or("employee_id = ? AND visibility <> ?", self[0], VisibilityHR))
+	}
+	documents := []Document{}
+	if err := query.Order("LOWER(title), id").Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	if err := s.describe(orgID, documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (s *service) TeamDocuments(orgID *uint, viewer Viewer, employeeID *uint) ([]Document, error) {
+	self, leads, err := s.leads(s.db, orgID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	documents := []Document{}
+	if self == 0 && len(leads) == 0 {
+		return documents, nil
+	}
+	reports := utils.OrgScope(s.db.Table("employees").Where("deleted_at IS NULL AND id <> ?", self), orgID).
+		Where(s.db.Where("manager_id = ?", self).Or("division_id IN ?", leads))
+	if employeeID != nil {
+		reports = reports.Where("id = ?", *employeeID)
+	}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN (?) AND visibility IN ?", reports.Select("id"),
+		[]Visibility{VisibilityManagers, VisibilityEveryone}).Order("employee_id, LOWER(title), id").Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	if err := s.describe(orgID, documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (s *service) GetDocument(orgID *uint, viewer Viewer, id uint) (*Document, error) {
+	var document Document
+	if err := utils.OrgScope(s.db, orgID).First(&document, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	if err := s.checkVisible(s.db, orgID, viewer, document); err != nil {
+		return nil, err
+	}
+	document.Revisions = []Revision{}
+	if err := s.db.Where("document_id = ?", id).Order("number DESC").Find(&document.Revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load document revisions: %w", err)
+	}
+	documents := []Document{document}
+	if err := s.describe(orgID, documents); err != nil {
+		return nil, err
+	}
+	return &documents[0], nil
+}
+
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Document, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockDocument(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		document := *before
+		if err := s.apply(tx, &document, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Document{}, id, expectedVersion, map[string]interface{}{
+			"category_id": document.CategoryID,
+			"employee_id": document.EmployeeID,
+			"title":       document.Title,
+			"description": document.Description,
+			"visibility":  document.Visibility,
+			"expires_on":  document.ExpiresOn,
+		}); err != nil {
+			return err
+		}
+		var updated Document
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload document %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "document.update", EntityType: "document", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDocument(orgID, Viewer{HR: true}, id)
+}
+
+// checkVisible returns gorm.ErrRecordNotFound unless viewer may see the document.
+func (s *service) checkVisible(db *gorm.DB, orgID *uint, viewer Viewer, document Document) error {
+	if viewer.HR || document.Visibility == VisibilityEveryone {
+		return nil
+	}
+	if document.EmployeeID == nil || document.Visibility == VisibilityHR {
+		return gorm.ErrRecordNotFound
+	}
+	var owner struct {
+		UserID     uint
+		ManagerID  *uint
+		DivisionID *uint
+	}
+	if err := utils.OrgScope(db.Table("employees").Where("id = ? AND deleted_at IS NULL", *document.EmployeeID), orgID).
+		Select("user_id, manager_id, division_id").Take(&owner).Error; err != nil {
+		return err
+	}
+	if owner.UserID == viewer.UserID {
+		return nil
+	}
+	if document.Visibility != VisibilityManagers {
+		return gorm.ErrRecordNotFound
+	}
+	self, leads, err := s.leads(db, orgID, viewer)
+	if err != nil {
+		return err
+	}
+	if (self != 0 && owner.ManagerID != nil && *owner.ManagerID == self) ||
+		(owner.DivisionID != nil && slices.Contains(leads, *owner.DivisionID)) {
+		return nil
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// leads returns the viewer's employee ID (0 if they have no employee record) and the divisions they lead:
+// through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, orgID *uint, viewer Viewer) (uint, []uint, error) {
+	var self []uint
+	if err := utils.OrgScope(tx.Table("employees").Where("user_id = ? AND deleted_at IS NULL", viewer.UserID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load the viewer's employee record: %w", err)
+	}
+	leads := slices.Clone(viewer.Leads)
+	if len(self) == 0 {
+		return 0, leads, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Where("head_id = ? AND deleted_at IS NULL", self[0]).Pluck("id", &headed).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return self[0], append(leads, headed...), nil
+}
+
+// apply validates req and copies it onto document. An empty visibility takes the category's.
+func (s *service) apply(tx *gorm.DB, document *Document, req Request) error {
+	var category Category
+	if err := utils.OrgScope(tx, document.OrganizationID).First(&category, req.CategoryID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: category %d not found", ErrInvalidDocument, req.CategoryID)
+	} else if err != nil {
+		return fmt.Errorf("failed to load document category: %w", err)
+	}
+	if req.EmployeeID != nil {
+		if _, err := s.employees.Get(document.OrganizationID, *req.EmployeeID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: employee %d not found", ErrInvalidDocument, *req.EmployeeID)
+		} else if err != nil {
+			return err
+		}
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: a title is required", ErrInvalidDocument)
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = category.Visibility
+	}
+	if req.EmployeeID == nil && (visibility == VisibilityOwner || visibility == VisibilityManagers) {
+		return fmt.Errorf("%w: only documents attached to an employee can be shared with their owner or managers", ErrInvalidDocument)
+	}
+	var expiresOn *time.Time
+	if req.ExpiresOn != "" {
+		parsed, err := time.Parse("2006-01-02", req.ExpiresOn)
+		if err != nil {
+			return fmt.Errorf("%w: expires_on must be a date", ErrInvalidDocument)
+		}
+		expiresOn = &parsed
+	}
+	document.CategoryID = category.ID
+	document.EmployeeID = req.EmployeeID
+	document.Title = title
+	document.Description = strings.TrimSpace(req.Description)
+	document.Visibility = visibility
+	document.ExpiresOn = expiresOn
+	return nil
+}
+
+// describe fills in the category and employee names of documents, and whether they have expired.
+func (s *service) describe(orgID *uint, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	var categories []Category
+	if err := utils.OrgScope(s.db.Select("id, name"), orgID).Find(&categories).Error; err != nil {
+		return fmt.Errorf("failed to load document categories: %w", err)
+	}
+	categoryNames := make(map[uint]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.Name
+	}
+	var employeeIDs []uint
+	for _, d := range documents {
+		if d.EmployeeID != nil {
+			employeeIDs = append(employeeIDs, *d.EmployeeID)
+		}
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDocument, employeeIDs)
+	if err != nil {
+		return err
+	}
+	today := today()
+	for i := range documents {
+		d := &documents[i]
+		d.Category = categoryNames[d.CategoryID]
+		if d.EmployeeID != nil {
+			d.DisplayName = names[*d.EmployeeID].Text
+		}
+		d.Expired = d.ExpiresOn != nil && d.ExpiresOn.Before(today)
+	}
+	return nil
+}
+
+// checkCategoryName rejects a name another of the organization's categories has, ignoring case. The lock
+// serializes concurrent changes to the organization's categories.
+func checkCategoryName(tx *gorm.DB, orgID *uint, id uint, name string) error {
+	if err := lock.Tx(tx, "document_category:"+orgKey(orgID)); err != nil {
+		return err
+	}
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Category{}), orgID).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check document categories: %w", err)
+	}
+	if count > 0 {
+		return ErrCategoryTaken
+	}
+	return nil
+}
+
+// lockDocument loads a document for update, so its revisions can't change until the transaction ends.
+func lockDocument(tx *gorm.DB, orgID *uint, id uint) (*Document, error) {
+	var document Document
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// today is the current UTC date.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// orgKey names an organization in lock names and storage keys.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
@@ -0,0 +1,355 @@
+// prometheus/backend/internal/employee/handler.go
+package employee
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hrRoles see employee records unredacted, when held globally.
+var hrRoles = []string{"god-admin", "admin", "hr"}
+
+// managerRole sees more of the employees in divisions it is held for.
+const managerRole = "manager"
+
+// Handler handles HTTP requests for employee records.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns employees.
+// @Summary List employees
+// @Description display_name is the name the organization's name policy picks for the directory.
+// @Tags Employees
+// @Produce json
+// @Param q query string false "Case-insensitive match on employee number, job title, any name, username or email"
+// @Param division_id query int false "Division ID"
+// @Param manager_id query int false "Direct reports of this employee"
+// @Param employment_type query string false "full_time, part_time, contractor, intern or temporary"
+// @Param sort query string false "Comma-separated field:asc|desc; fields: employee_number, job_title, hire_date, username, created_at" default(employee_number)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter or sort"
+// @Router /hr/employees [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Search: strings.TrimSpace(c.Query("q")), EmploymentType: EmploymentType(c.Query("employment_type"))}
+	for param, target := range map[string]**uint{"division_id": &filter.DivisionID, "manager_id": &filter.ManagerID} {
+		if raw := c.Query(param); raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter")
+				return
+			}
+			value := uint(id)
+			*target = &value
+		}
+	}
+	sort, err := utils.ParseSort(c, SortFields, "employee_number")
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid sort parameter: "+err.Error())
+		return
+	}
+	page := utils.ParsePagination(c)
+	employees, total, err := h.service.List(utils.OrganizationFromContext(c), filter, sort, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employees fetched successfully", page.Response(employees, total))
+}
+
+// Get returns an employee. The ETag and Last-Modified headers can be sent back as If-Match / If-Unmodified-Since.
+// @Summary Get an employee
+// @Tags Employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} Detail
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employee, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, employee.UpdatedAt, employee.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee fetched successfully", employee)
+}
+
+// Mine returns the caller's own employee record.
+// @Summary Get my employee record
+// @Tags Employees
+// @Produce json
+// @Success 200 {object} Detail
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/employee [get]
+func (h *Handler) Mine(c *gin.Context) {
+	employee, err := h.service.ForUser(c.GetUint("userID"))
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee fetched successfully", employee)
+}
+
+// Create adds the employee record of a user.
+// @Summary Create an employee
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param employee body Request true "Employee"
+// @Success 201 {object} Detail
+// @Failure 400 {object} utils.ErrorResponse "Invalid employee, unknown user or manager"
+// @Failure 409 {object} utils.ErrorResponse "User already an employee, or number taken"
+// @Router /hr/employees [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	employee, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, employee.UpdatedAt, employee.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Employee created successfully", employee)
+}
+
+// Update replaces an employee's fields.
+// @Summary Update an employee
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param employee body Request true "Employee"
+// @Success 200 {object} Detail
+// @Failure 400 {object} utils.ErrorResponse "Invalid employee or manager"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Number taken"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/employees/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	employee, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, employee.UpdatedAt, employee.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee updated successfully", employee)
+}
+
+// Delete removes an employee record. The user account is left alone.
+// @Summary Delete an employee
+// @Tags Employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee deleted successfully", nil)
+}
+
+// OrgChart returns the reporting hierarchy.
+// @Summary Get the org chart
+// @Description Employees nest under their managers as direct_reports, down to depth levels below the roots.
+// @Description Roots are the given employee, or everyone without a manager (within the division, if filtered).
+// @Tags Employees
+// @Produce json
+// @Param root_id query int false "Employee at the top"
+// @Param division_id query int false "Only employees of this division"
+// @Param depth query int false "Levels of reports below the roots (0-10)" default(3)
+// @Success 200 {array} ChartNode
+// @Failure 400 {object} utils.ErrorResponse "Invalid parameter"
+// @Failure 404 {object} utils.ErrorResponse "Root employee not found"
+// @Router /org-chart [get]
+func (h *Handler) OrgChart(c *gin.Context) {
+	query := ChartQuery{Depth: DefaultChartDepth}
+	for param, target := range map[string]**uint{"root_id": &query.RootID, "division_id": &query.DivisionID} {
+		if raw := c.Query(param); raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter")
+				return
+			}
+			value := uint(id)
+			*target = &value
+		}
+	}
+	if raw := c.Query("depth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 0 || depth > MaxChartDepth {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid depth parameter: must be between 0 and "+strconv.Itoa(MaxChartDepth))
+			return
+		}
+		query.Depth = depth
+	}
+	chart, err := h.service.OrgChart(utils.OrganizationFromContext(c), query)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Org chart fetched successfully", chart)
+}
+
+// Profile returns what the caller may see of a colleague.
+// @Summary Get an employee's profile
+// @Description Names and pronouns are shown as far as the organization's name policy allows: legal names are
+// @Description usually kept to HR; managers of the employee or their division may see more than other colleagues.
+// @Tags Employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} Profile
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /employees/{id}/profile [get]
+func (h *Handler) Profile(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	roles := middleware.RolesFromContext(c)
+	viewer := Viewer{
+		UserID: c.GetUint("userID"),
+		HR:     slices.ContainsFunc(hrRoles, func(role string) bool { return slices.Contains(roles, role) }),
+	}
+	viewer.ManagesAll, viewer.Manages = middleware.DivisionScope(c, managerRole)
+	profile, err := h.service.Profile(utils.OrganizationFromContext(c), id, viewer)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Profile fetched successfully", profile)
+}
+
+// UpdateMyProfile sets the caller's pronouns and preferred names.
+// @Summary Update my profile
+// @Description Replaces the caller's pronouns and preferred names; legal names are kept by HR.
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param profile body ProfileRequest true "Profile"
+// @Success 200 {object} Detail
+// @Failure 400 {object} utils.ErrorResponse "Invalid name"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/employee/profile [put]
+func (h *Handler) UpdateMyProfile(c *gin.Context) {
+	var req ProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	employee, err := h.service.UpdateProfile(audit.ActorFromContext(c), c.GetUint("userID"), req)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, employee.UpdatedAt, employee.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Profile updated successfully", employee)
+}
+
+// GetNamePolicy returns how the organization resolves display names.
+// @Summary Get the name policy
+// @Tags Employees
+// @Produce json
+// @Success 200 {object} NamePolicy
+// @Router /hr/employee-name-policy [get]
+func (h *Handler) GetNamePolicy(c *gin.Context) {
+	policy, err := h.service.NamePolicy(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Name policy fetched successfully", policy)
+}
+
+// PutNamePolicy replaces how the organization resolves display names.
+// @Summary Update the name policy
+// @Description Each usage (directory, document, payslip) shows a name of the rule's kind, falling back to the
+// @Description other kind and then the username. Among names of that kind the first of scripts wins, e.g.
+// @Description ["Arab", "Latn"] prints Arabic-script names where employees have one. The directory must use
+// @Description preferred names. visibility sets who besides HR sees legal names and pronouns.
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param policy body NamePolicyRequest true "Name policy"
+// @Success 200 {object} NamePolicy
+// @Failure 400 {object} utils.ErrorResponse "Invalid policy or unsupported script"
+// @Router /hr/employee-name-policy [put]
+func (h *Handler) PutNamePolicy(c *gin.Context) {
+	var req NamePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	policy, err := h.service.SetNamePolicy(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendEmployeeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Name policy updated successfully", policy)
+}
+
+// sendEmployeeError maps service errors to HTTP status codes.
+func sendEmployeeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Employee not found")
+	case errors.Is(err, ErrInvalidEmployee):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrAlreadyEmployee), errors.Is(err, ErrNumberTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The employee was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,78 @@
+// prometheus/backend/internal/employee/model.go
+package employee
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// EmploymentType is the contractual relationship of an employee.
+type EmploymentType string
+
+const (
+	FullTime   EmploymentType = "full_time"
+	PartTime   EmploymentType = "part_time"
+	Contractor EmploymentType = "contractor"
+	Intern     EmploymentType = "intern"
+	Temporary  EmploymentType = "temporary"
+)
+
+// Employee is the HR record of a person, linked one-to-one with the User they log in as. Login identity
+// (username, password, roles) stays on the user; everything HR manages about the job lives here.
+type Employee struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"12"`
+	UserID         uint           `gorm:"not null;uniqueIndex" json:"user_id" example:"7"`
+	OrganizationID *uint          `gorm:"uniqueIndex:idx_employee_number" json:"organization_id,omitempty" example:"1"` // Always the user's organization
+	EmployeeNumber string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_employee_number" json:"employee_number" example:"E-1042"`
+	JobTitle       string         `gorm:"type:varchar(150);not null" json:"job_title" example:"Payroll Specialist"`
+	HireDate       time.Time      `gorm:"type:date;not null" json:"hire_date" example:"2023-04-01T00:00:00Z"`
+	EmploymentType EmploymentType `gorm:"type:varchar(20);not null" json:"employment_type" example:"full_time"`
+	ManagerID      *uint          `gorm:"index" json:"manager_id,omitempty" example:"3"` // Employee ID of the line manager
+	DivisionID     *uint          `gorm:"index" json:"division_id,omitempty" example:"2"`
+	Names          datatypes.JSON `gorm:"type:jsonb" json:"names" swaggertype:"array,object"` // Legal and preferred names, per script; see Name
+	Pronouns       string         `gorm:"type:varchar(50)" json:"pronouns,omitempty" example:"she/her"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Detail is an employee with the username and email of their user, and the name the directory shows.
+type Detail struct {
+	Employee
+	Username    string      `json:"username" example:"jdoe"`
+	Email       string      `json:"email" example:"jdoe@example.com"`
+	DisplayName DisplayName `gorm:"-" json:"display_name"`
+}
+
+// Request creates an employee record or replaces its fields. The user can't be changed afterwards.
+type Request struct {
+	UserID         uint           `json:"user_id" binding:"required" example:"7"`
+	EmployeeNumber string         `json:"employee_number" binding:"required,max=50" example:"E-1042"`
+	JobTitle       string         `json:"job_title" binding:"required,max=150" example:"Payroll Specialist"`
+	HireDate       string         `json:"hire_date" binding:"required,datetime=2006-01-02" example:"2023-04-01"`
+	EmploymentType EmploymentType `json:"employment_type" binding:"required,oneof=full_time part_time contractor intern temporary" example:"full_time"`
+	ManagerID      *uint          `json:"manager_id,omitempty" example:"3"`
+	DivisionID     *uint          `json:"division_id,omitempty" example:"2"`
+	Names          []Name         `json:"names" binding:"max=20,dive"` // Replaces all names
+	Pronouns       string         `json:"pronouns,omitempty" binding:"max=50" example:"she/her"`
+}
+
+// Patch changes an employee's job and placement, leaving nil fields as they are. Changes scheduled ahead
+// are applied as patches, see internal/change.
+type Patch struct {
+	JobTitle       *string
+	EmploymentType *EmploymentType
+	DivisionID     *uint
+	ManagerID      *uint
+}
+
+// Filter narrows an employee listing.
+type Filter struct {
+	Search         string         // Case-insensitive match on employee number, job title, any name, username or email
+	DivisionID     *uint          // Division ID
+	ManagerID      *uint          // Direct reports of this employee
+	EmploymentType EmploymentType // Employment type
+}
@@ -0,0 +1,310 @@
+// prometheus/backend/internal/employee/names.go
+package employee
+
+import (
+	"encoding/json"
+	"fmt"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// NameKind distinguishes an employee's legal name from the one they go by.
+type NameKind string
+
+const (
+	LegalName     NameKind = "legal"
+	PreferredName NameKind = "preferred"
+)
+
+// Usage is where a name is shown. The organization's NamePolicy picks the name for each.
+type Usage string
+
+const (
+	UsageDirectory Usage = "directory" // Employee listings, the org chart
+	UsageDocument  Usage = "document"  // Generated documents, e.g. contracts and letters
+	UsagePayslip   Usage = "payslip"
+)
+
+// Direction is the writing direction of a script, for clients to set e.g. dir="rtl" around a name.
+type Direction string
+
+const (
+	LeftToRight Direction = "ltr"
+	RightToLeft Direction = "rtl"
+)
+
+// Name is an employee's legal or preferred name written in one script. An employee has at most one
+// name per kind and script, e.g. a legal name in Latin and in Arabic script.
+type Name struct {
+	Kind   NameKind `json:"kind" binding:"required,oneof=legal preferred" example:"legal"`
+	Script string   `json:"script" binding:"required,len=4" example:"Arab"` // ISO 15924 code: Latn, Cyrl, Grek, Armn, Geor, Arab, Hebr, Thaa, Deva, Beng, Taml, Thai, Hans, Hant, Jpan or Kore
+	Given  string   `json:"given,omitempty" binding:"max=100" example:"ليلى"`
+	Family string   `json:"family,omitempty" binding:"max=100" example:"حداد"`
+	Full   string   `json:"full,omitempty" binding:"max=200"` // Written out in full; overrides composing given and family names
+}
+
+// DisplayName is the name resolved for a usage.
+type DisplayName struct {
+	Text      string    `json:"text" example:"Laila Haddad"`
+	Script    string    `json:"script,omitempty" example:"Latn"` // Empty when falling back to the username
+	Direction Direction `json:"direction" example:"ltr"`
+	Kind      NameKind  `json:"kind,omitempty" example:"preferred"` // Empty when falling back to the username
+}
+
+// NameRule picks the name shown for one usage: a name of Kind if the employee has one, otherwise of the
+// other kind, otherwise the username. Among names of a kind, the first in Scripts wins; without a match,
+// the one listed first on the employee record.
+type NameRule struct {
+	Kind    NameKind `json:"kind" binding:"required,oneof=legal preferred" example:"preferred"`
+	Scripts []string `json:"scripts" binding:"max=10,dive,len=4" swaggertype:"array,string" example:"Latn,Arab"`
+}
+
+// NamePolicy is how an organization resolves display names. Organizations without one use
+// DefaultNamePolicy.
+type NamePolicy struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Rules          datatypes.JSON `gorm:"type:jsonb;not null" json:"rules" swaggertype:"object"` // Usage to NameRule
+	Visibility     datatypes.JSON `gorm:"type:jsonb" json:"visibility" swaggertype:"object"`     // FieldVisibility
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName keeps name settings apart from other kinds of policies.
+func (NamePolicy) TableName() string { return "employee_name_policies" }
+
+// NamePolicyRequest replaces an organization's name policy.
+type NamePolicyRequest struct {
+	Directory  NameRule         `json:"directory" binding:"required"` // Must prefer preferred names
+	Document   NameRule         `json:"document" binding:"required"`
+	Payslip    NameRule         `json:"payslip" binding:"required"`
+	Visibility *FieldVisibility `json:"visibility,omitempty"` // Defaults to DefaultFieldVisibility
+}
+
+// DefaultNamePolicy shows the name employees go by in the directory, and their legal name on documents
+// and payslips.
+func DefaultNamePolicy(orgID *uint) NamePolicy {
+	return newNamePolicy(orgID, NamePolicyRequest{
+		Directory: NameRule{Kind: PreferredName, Scripts: []string{}},
+		Document:  NameRule{Kind: LegalName, Scripts: []string{}},
+		Payslip:   NameRule{Kind: LegalName, Scripts: []string{}},
+	})
+}
+
+func newNamePolicy(orgID *uint, req NamePolicyRequest) NamePolicy {
+	rules, _ := json.Marshal(map[Usage]NameRule{ // Strings only, always encodes
+		UsageDirectory: req.Directory, UsageDocument: req.Document, UsagePayslip: req.Payslip,
+	})
+	visibility := DefaultFieldVisibility()
+	if req.Visibility != nil {
+		visibility = *req.Visibility
+	}
+	encoded, _ := json.Marshal(visibility)
+	return NamePolicy{OrganizationID: orgID, Rules: rules, Visibility: encoded}
+}
+
+// Rule returns the policy's rule for a usage.
+func (p NamePolicy) Rule(usage Usage) NameRule {
+	var rules map[Usage]NameRule
+	_ = json.Unmarshal(p.Rules, &rules) // Written by newNamePolicy; a broken value falls back below
+	if rule, ok := rules[usage]; ok {
+		return rule
+	}
+	return NameRule{Kind: PreferredName}
+}
+
+// FieldVisibility returns who may see the fields the policy restricts.
+func (p NamePolicy) FieldVisibility() FieldVisibility {
+	visibility := DefaultFieldVisibility()
+	if len(p.Visibility) > 0 {
+		_ = json.Unmarshal(p.Visibility, &visibility) // Written by newNamePolicy; policies saved before keep the defaults
+	}
+	return visibility
+}
+
+// script describes how names are written in a script.
+type script struct {
+	tables      []*unicode.RangeTable // Letters a name in the script may use, besides common punctuation and marks
+	rtl         bool
+	familyFirst bool   // The family name comes before the given name
+	separator   string // Between the family and given name
+}
+
+// scripts are the ISO 15924 codes names may be written in.
+var scripts = map[string]script{
+	"Latn": {tables: []*unicode.RangeTable{unicode.Latin}, separator: " "},
+	"Cyrl": {tables: []*unicode.RangeTable{unicode.Cyrillic}, separator: " "},
+	"Grek": {tables: []*unicode.RangeTable{unicode.Greek}, separator: " "},
+	"Armn": {tables: []*unicode.RangeTable{unicode.Armenian}, separator: " "},
+	"Geor": {tables: []*unicode.RangeTable{unicode.Georgian}, separator: " "},
+	"Arab": {tables: []*unicode.RangeTable{unicode.Arabic}, rtl: true, separator: " "},
+	"Hebr": {tables: []*unicode.RangeTable{unicode.Hebrew}, rtl: true, separator: " "},
+	"Thaa": {tables: []*unicode.RangeTable{unicode.Thaana}, rtl: true, separator: " "},
+	"Deva": {tables: []*unicode.RangeTable{unicode.Devanagari}, separator: " "},
+	"Beng": {tables: []*unicode.RangeTable{unicode.Bengali}, separator: " "},
+	"Taml": {tables: []*unicode.RangeTable{unicode.Tamil}, separator: " "},
+	"Thai": {tables: []*unicode.RangeTable{unicode.Thai}, separator: " "},
+	"Hans": {tables: []*unicode.RangeTable{unicode.Han}, familyFirst: true},
+	"Hant": {tables: []*unicode.RangeTable{unicode.Han}, familyFirst: true},
+	"Jpan": {tables: []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana}, familyFirst: true},
+	"Kore": {tables: []*unicode.RangeTable{unicode.Hangul, unicode.Han}, familyFirst: true},
+}
+
+// text writes the name out in the order and spacing of its script.
+func (n Name) text() string {
+	if n.Full != "" {
+		return n.Full
+	}
+	s := scripts[n.Script]
+	first, second := n.Given, n.Family
+	if s.familyFirst {
+		first, second = n.Family, n.Given
+	}
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + s.separator + second
+}
+
+// normalizeNames trims the names and checks each is written in its script, with at most one name per
+// kind and script.
+func normalizeNames(names []Name) ([]Name, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]Name, 0, len(names))
+	for _, n := range names {
+		n.Given, n.Family, n.Full = strings.TrimSpace(n.Given), strings.TrimSpace(n.Family), strings.TrimSpace(n.Full)
+		s, ok := scripts[n.Script]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported script %q", ErrInvalidEmployee, n.Script)
+		}
+		if n.Given == "" && n.Family == "" && n.Full == "" {
+			return nil, fmt.Errorf("%w: the %s %s name is empty", ErrInvalidEmployee, n.Kind, n.Script)
+		}
+		key := string(n.Kind) + "/" + n.Script
+		if seen[key] {
+			return nil, fmt.Errorf("%w: more than one %s name in %s", ErrInvalidEmployee, n.Kind, n.Script)
+		}
+		seen[key] = true
+		for _, part := range []string{n.Given, n.Family, n.Full} {
+			if !writtenIn(part, s) {
+				return nil, fmt.Errorf("%w: %q is not written in %s", ErrInvalidEmployee, part, n.Script)
+			}
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// writtenIn reports whether every letter of value belongs to the script. Spaces, punctuation such as
+// hyphens and apostrophes, and combining marks are shared between scripts and always allowed.
+func writtenIn(value string, s script) bool {
+	for _, r := range value {
+		if unicode.In(r, unicode.Common, unicode.Inherited) || unicode.In(r, s.tables...) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// resolveName picks the name to show under rule, falling back to the username.
+func resolveName(names []Name, rule NameRule, username string) DisplayName {
+	kinds := []NameKind{rule.Kind, PreferredName}
+	if rule.Kind == PreferredName {
+		kinds[1] = LegalName
+	}
+	for _, kind := range kinds {
+		var candidates []Name
+		for _, n := range names {
+			if n.Kind == kind {
+				candidates = append(candidates, n)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		chosen := candidates[0]
+	search:
+		for _, code := range rule.Scripts {
+			for _, n := range candidates {
+				if n.Script == code {
+					chosen = n
+					break search
+				}
+			}
+		}
+		direction := LeftToRight
+		if scripts[chosen.Script].rtl {
+			direction = RightToLeft
+		}
+		return DisplayName{Text: chosen.text(), Script: chosen.Script, Direction: direction, Kind: chosen.Kind}
+	}
+	return DisplayName{Text: username, Direction: LeftToRight}
+}
+
+// decodeNames reads an employee's stored names; records without any have none.
+func decodeNames(raw datatypes.JSON) []Name {
+	var names []Name
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &names) // Written by the service from []Name
+	}
+	return names
+}
+
+// encodeNames stores an employee's names.
+func encodeNames(names []Name) datatypes.JSON {
+	encoded, _ := json.Marshal(names) // Strings only, always encodes
+	return encoded
+}
+
+// namePolicies loads each organization's name policy once while handling many employees.
+type namePolicies struct {
+	db    *gorm.DB
+	byOrg map[uint]*NamePolicy // 0 = users outside any organization
+}
+
+func newNamePolicies(db *gorm.DB) *namePolicies {
+	return &namePolicies{db: db, byOrg: make(map[uint]*NamePolicy)}
+}
+
+func (p *namePolicies) get(orgID *uint) (*NamePolicy, error) {
+	var key uint
+	if orgID != nil {
+		key = *orgID
+	}
+	if policy, ok := p.byOrg[key]; ok {
+		return policy, nil
+	}
+	policy, err := namePolicy(p.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	p.byOrg[key] = policy
+	return policy, nil
+}
+
+// resolve picks an employee's name for a usage under their organization's policy.
+func (p *namePolicies) resolve(usage Usage, orgID *uint, names datatypes.JSON, username string) (DisplayName, error) {
+	policy, err := p.get(orgID)
+	if err != nil {
+		return DisplayName{}, err
+	}
+	return resolveName(decodeNames(names), policy.Rule(usage), username), nil
+}
+
+// namePolicy returns the organization's name policy, or DefaultNamePolicy.
+func namePolicy(db *gorm.DB, orgID *uint) (*NamePolicy, error) {
+	var policies []NamePolicy
+	if err := utils.OrgScope(db, orgID).Limit(1).Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load name policy: %w", err)
+	}
+	if len(policies) == 0 {
+		policy := DefaultNamePolicy(orgID)
+		return &policy, nil
+	}
+	return &policies[0], nil
+}
@@ -0,0 +1,121 @@
+// prometheus/backend/internal/employee/orgchart.go
+package employee
+
+import (
+	"fmt"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultChartDepth is how many levels of reports an org chart shows unless asked otherwise.
+	DefaultChartDepth = 3
+	// MaxChartDepth bounds the depth of an org chart.
+	MaxChartDepth = 10
+)
+
+// ChartQuery selects part of the org chart.
+type ChartQuery struct {
+	RootID     *uint // Employee at the top; nil = everyone without a manager
+	DivisionID *uint // Only employees of the division; those whose manager is outside it become roots
+	Depth      int   // Levels below the roots, 0..MaxChartDepth
+}
+
+// ChartNode is an employee with their direct reports. Only what an org chart shows is included, since
+// every employee may see it.
+type ChartNode struct {
+	ID            uint        `json:"id" example:"12"`
+	UserID        uint        `json:"user_id" example:"7"`
+	Username      string      `json:"username" example:"jdoe"`
+	DisplayName   DisplayName `json:"display_name"`
+	Pronouns      string      `json:"pronouns,omitempty" example:"she/her"` // Unless the organization restricts them
+	JobTitle      string      `json:"job_title" example:"Payroll Specialist"`
+	DivisionID    *uint       `json:"division_id,omitempty" example:"2"`
+	ReportCount   int         `json:"report_count" example:"4"` // Direct reports, including any cut off by the depth limit
+	DirectReports []ChartNode `json:"direct_reports"`
+}
+
+// chartRow is an employee as loaded for the org chart.
+type chartRow struct {
+	ID             uint
+	UserID         uint
+	OrganizationID *uint
+	Username       string
+	Names          datatypes.JSON
+	Pronouns       string
+	JobTitle       string
+	DivisionID     *uint
+	ManagerID      *uint
+	displayName    DisplayName
+}
+
+// OrgChart loads the organization's (or division's) employees in one query and builds the tree in memory.
+func (s *service) OrgChart(orgID *uint, query ChartQuery) ([]ChartNode, error) {
+	db := s.details(s.db, orgID)
+	if query.DivisionID != nil {
+		db = db.Where("employees.division_id = ?", *query.DivisionID)
+	}
+	var rows []chartRow
+	if err := db.Select("employees.id, employees.user_id, employees.organization_id, users.username, employees.names, employees.pronouns, employees.job_title, employees.division_id, employees.manager_id").
+		Order("users.username, employees.id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load org chart: %w", err)
+	}
+	policies := newNamePolicies(s.db)
+	byID := make(map[uint]*chartRow, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		byID[row.ID] = row
+		policy, err := policies.get(row.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if !AudienceEveryone.sees(policy.FieldVisibility().Pronouns) {
+			row.Pronouns = ""
+		}
+		if row.displayName, err = policies.resolve(UsageDirectory, row.OrganizationID, row.Names, row.Username); err != nil {
+			return nil, err
+		}
+	}
+	reports := make(map[uint][]*chartRow, len(rows))
+	var roots []*chartRow
+	for i := range rows {
+		row := &rows[i]
+		if row.ManagerID != nil && byID[*row.ManagerID] != nil && *row.ManagerID != row.ID {
+			reports[*row.ManagerID] = append(reports[*row.ManagerID], row)
+		} else {
+			roots = append(roots, row)
+		}
+	}
+	if query.RootID != nil {
+		root, ok := byID[*query.RootID]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		roots = []*chartRow{root}
+	}
+	visited := make(map[uint]bool, len(rows))
+	var build func(row *chartRow, depth int) ChartNode
+	build = func(row *chartRow, depth int) ChartNode {
+		visited[row.ID] = true
+		node := ChartNode{
+			ID: row.ID, UserID: row.UserID, Username: row.Username, DisplayName: row.displayName, Pronouns: row.Pronouns, JobTitle: row.JobTitle, DivisionID: row.DivisionID,
+			ReportCount: len(reports[row.ID]), DirectReports: []ChartNode{},
+		}
+		if depth >= query.Depth {
+			return node
+		}
+		for _, report := range reports[row.ID] {
+			// Validation rules out cycles; this only guards against data edited behind the service's back.
+			if !visited[report.ID] {
+				node.DirectReports = append(node.DirectReports, build(report, depth+1))
+			}
+		}
+		return node
+	}
+	chart := make([]ChartNode, 0, len(roots))
+	for _, root := range roots {
+		chart = append(chart, build(root, 0))
+	}
+	return chart, nil
+}
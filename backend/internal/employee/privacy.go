@@ -0,0 +1,38 @@
+// prometheus/backend/internal/employee/privacy.go
+package employee
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/privacy"
+
+	"gorm.io/gorm"
+)
+
+// PrivacySource exports a user's employee record. Anonymization erases its names and pronouns but keeps
+// the record: once the user's email is scrubbed too it only feeds headcount and tenure figures.
+func PrivacySource() privacy.Source {
+	return privacy.NewSource("employee", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+		var employees []Employee
+		if err := db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Find(&employees).Error; err != nil {
+			return nil, fmt.Errorf("failed to export employee record: %w", err)
+		}
+		if len(employees) == 0 {
+			return nil, nil
+		}
+		return employees[0], nil
+	}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+		if err := tx.WithContext(ctx).Unscoped().Model(&Employee{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"names": nil, "pronouns": "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to erase employee names: %w", err)
+		}
+		return nil
+	})
+}
+
+// OfUser selects the employee record IDs of a user, including deleted ones, for use as a subquery in the
+// privacy sources of other modules.
+func OfUser(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Table("employees").Select("id").Where("user_id = ?", userID)
+}
@@ -0,0 +1,46 @@
+// prometheus/backend/internal/employee/search.go
+package employee
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/search"
+)
+
+// SearchType is the type of employee search hits.
+const SearchType = "employee"
+
+// Search finds colleagues for the organization-wide search. Everyone finds them by username, job title
+// and preferred names, as the directory shows them; HR also by legal names, employee number and email.
+func (s *service) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	hr := q.Viewer.HasRole(hrRoles...)
+	fields, args := q.Condition("employees.job_title", "users.username")
+	if hr {
+		fields, args = q.Condition("employees.job_title", "users.username", "employees.employee_number", "users.email")
+	}
+	names, nameArgs := q.Condition("concat_ws(' ', n->>'given', n->>'family', n->>'full')")
+	if !hr {
+		names = "n->>'kind' = 'preferred' AND " + names
+	}
+	var employees []Detail
+	if err := s.details(s.db.WithContext(ctx), q.OrgID).Select("employees.*, users.username, users.email").
+		Where("("+fields+" OR EXISTS (SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(employees.names) = 'array' "+
+			"THEN employees.names ELSE '[]'::jsonb END) n WHERE "+names+"))", append(args, nameArgs...)...).
+		Order("employees.id").Limit(q.Limit).Find(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to search employees: %w", err)
+	}
+	policies := newNamePolicies(s.db)
+	hits := make([]search.Hit, 0, len(employees))
+	for _, e := range employees {
+		name, err := policies.resolve(UsageDirectory, e.OrganizationID, e.Names, e.Username)
+		if err != nil {
+			return nil, err
+		}
+		link := fmt.Sprintf("/api/v1/employees/%d/profile", e.ID)
+		if hr {
+			link = fmt.Sprintf("/api/v1/hr/employees/%d", e.ID)
+		}
+		hits = append(hits, search.Hit{Type: SearchType, ID: e.ID, Title: name.Text, Snippet: e.JobTitle, Link: link})
+	}
+	return hits, nil
+}
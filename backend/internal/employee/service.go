@@ -0,0 +1,557 @@
+// prometheus/backend/internal/employee/service.go
+package employee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxManagerDepth bounds the walk up the management chain when checking for cycles.
+const maxManagerDepth = 100
+
+// ErrInvalidEmployee is returned for employee records that fail validation.
+var ErrInvalidEmployee = errors.New("invalid employee")
+
+// ErrAlreadyEmployee is returned when the user already has an employee record.
+var ErrAlreadyEmployee = errors.New("the user already has an employee record")
+
+// ErrNumberTaken is returned when the organization already uses the employee number.
+var ErrNumberTaken = errors.New("employee number already taken")
+
+// ErrNoEmployee is returned when a user without an employee record uses a module's self-service.
+var ErrNoEmployee = errors.New("you have no employee record")
+
+// SortFields maps the ?sort= fields of employee listings to columns.
+var SortFields = map[string]string{
+	"employee_number": "employees.employee_number",
+	"job_title":       "employees.job_title",
+	"hire_date":       "employees.hire_date",
+	"username":        "users.username",
+	"created_at":      "employees.created_at",
+}
+
+// Service manages employee records.
+// orgID scopes every call to one organization's employees (nil = all employees, for platform admins).
+type Service interface {
+	List(orgID *uint, filter Filter, sort utils.Sort, page utils.Pagination) ([]Detail, int64, error)
+	Get(orgID *uint, id uint) (*Detail, error)
+	// ForUser returns the employee record of a user.
+	ForUser(userID uint) (*Detail, error)
+	Resolver
+	Create(actor audit.Actor, orgID *uint, req Request) (*Detail, error)
+	// CreateTx is Create within tx, for records created as part of a larger change such as hiring a candidate.
+	CreateTx(tx *gorm.DB, actor audit.Actor, orgID *uint, req Request) (*Detail, error)
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Detail, error)
+	// ApplyTx patches an employee within tx, validated and audited like Update but without a version check.
+	ApplyTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, patch Patch) (*Detail, error)
+	// Delete removes the record; the employee's direct reports are left without a manager, and divisions
+	// they head without a head.
+	Delete(actor audit.Actor, orgID *uint, id uint) error
+	// OrgChart returns the reporting hierarchy: each root with their direct reports, recursively.
+	OrgChart(orgID *uint, query ChartQuery) ([]ChartNode, error)
+
+	// UpdateProfile sets the pronouns and preferred names of the user's own employee record.
+	UpdateProfile(actor audit.Actor, userID uint, req ProfileRequest) (*Detail, error)
+	// Profile returns a colleague's view of an employee, with what the viewer may see.
+	Profile(orgID *uint, id uint, viewer Viewer) (*Profile, error)
+	// Redact removes from employee records what the audience may not see under their organization's name
+	// policy. Records are returned to HR as they are; everyone else gets them redacted.
+	Redact(employees []Detail, audience Audience) error
+
+	// DisplayNames resolves the names of employees for a usage under their organization's name policy,
+	// by employee ID. Anything that prints employee names, such as documents and payslips, goes through it.
+	DisplayNames(orgID *uint, usage Usage, employeeIDs []uint) (map[uint]DisplayName, error)
+	// NamePolicy returns the organization's name policy, or DefaultNamePolicy.
+	NamePolicy(orgID *uint) (*NamePolicy, error)
+	SetNamePolicy(actor audit.Actor, orgID *uint, req NamePolicyRequest) (*NamePolicy, error)
+
+	// Search finds colleagues for the organization-wide search, see search.Provider.
+	Search(ctx context.Context, q search.Query) ([]search.Hit, error)
+}
+
+// Resolver finds the employee record of the calling user, for modules whose handlers act on the caller's own
+// records (their timesheets, claims, assets, ...). Their services embed it in their interface.
+type Resolver interface {
+	// EmployeeOf returns the employee record of a user, or ErrNoEmployee.
+	EmployeeOf(userID uint) (*Detail, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+// userRow is the part of a user this package reads, without importing the auth package.
+type userRow struct {
+	ID             uint
+	OrganizationID *uint
+}
+
+func (s *service) List(orgID *uint, filter Filter, sort utils.Sort, page utils.Pagination) ([]Detail, int64, error) {
+	query := s.details(s.db, orgID)
+	if term := strings.TrimSpace(filter.Search); term != "" {
+		pattern := utils.ContainsPattern(strings.ToLower(term))
+		query = query.Where("LOWER(employees.employee_number) LIKE ? OR LOWER(employees.job_title) LIKE ? OR LOWER(employees.names::text) LIKE ? OR LOWER(users.username) LIKE ? OR LOWER(users.email) LIKE ?",
+			pattern, pattern, pattern, pattern, pattern)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("employees.division_id = ?", *filter.DivisionID)
+	}
+	if filter.ManagerID != nil {
+		query = query.Where("employees.manager_id = ?", *filter.ManagerID)
+	}
+	if filter.EmploymentType != "" {
+		query = query.Where("employees.employment_type = ?", filter.EmploymentType)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count employees: %w", err)
+	}
+	var employees []Detail
+	if err := query.Select("employees.*, users.username, users.email").Scopes(sort.TableScope("employees"), page.Scope).Find(&employees).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list employees: %w", err)
+	}
+	policies := newNamePolicies(s.db)
+	for i := range employees {
+		name, err := policies.resolve(UsageDirectory, employees[i].OrganizationID, employees[i].Names, employees[i].Username)
+		if err != nil {
+			return nil, 0, err
+		}
+		employees[i].DisplayName = name
+	}
+	return employees, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Detail, error) {
+	return s.load(s.db, orgID, "employees.id = ?", id)
+}
+
+func (s *service) ForUser(userID uint) (*Detail, error) {
+	return s.load(s.db, nil, "employees.user_id = ?", userID)
+}
+
+func (s *service) EmployeeOf(userID uint) (*Detail, error) {
+	emp, err := s.ForUser(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoEmployee
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load employee record: %w", err)
+	}
+	return emp, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Detail, error) {
+	var created *Detail
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, err = s.CreateTx(tx, actor, orgID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *service) CreateTx(tx *gorm.DB, actor audit.Actor, orgID *uint, req Request) (*Detail, error) {
+	hireDate, err := parseDate(req.HireDate)
+	if err != nil {
+		return nil, err
+	}
+	if req.Names, err = normalizeNames(req.Names); err != nil {
+		return nil, err
+	}
+	var user userRow
+	query := tx.Table("users").Select("id", "organization_id").Where("deleted_at IS NULL")
+	if orgID != nil {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+	if err := query.First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user %d not found", ErrInvalidEmployee, req.UserID)
+		}
+		return nil, fmt.Errorf("failed to load user %d: %w", req.UserID, err)
+	}
+	var existing int64
+	if err := tx.Unscoped().Model(&Employee{}).Where("user_id = ?", user.ID).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check employee records: %w", err)
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyEmployee
+	}
+	employee := Employee{UserID: user.ID, OrganizationID: user.OrganizationID}
+	apply(&employee, req, hireDate)
+	if err := s.validate(tx, &employee); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&employee).Error; err != nil {
+		return nil, fmt.Errorf("failed to create employee: %w", err)
+	}
+	created, err := s.load(tx, nil, "employees.id = ?", employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "employee.create", EntityType: "employee", EntityID: fmt.Sprintf("%d", employee.ID), After: employee,
+	}); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update replaces the record's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Detail, error) {
+	hireDate, err := parseDate(req.HireDate)
+	if err != nil {
+		return nil, err
+	}
+	if req.Names, err = normalizeNames(req.Names); err != nil {
+		return nil, err
+	}
+	var updated *Detail
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, "employees.id = ?", id)
+		if err != nil {
+			return err
+		}
+		if req.UserID != before.UserID {
+			return fmt.Errorf("%w: the user of an employee record can't be changed", ErrInvalidEmployee)
+		}
+		employee := before.Employee
+		apply(&employee, req, hireDate)
+		if err := s.validate(tx, &employee); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Employee{}, id, expectedVersion, map[string]interface{}{
+			"employee_number": employee.EmployeeNumber,
+			"job_title":       employee.JobTitle,
+			"hire_date":       employee.HireDate,
+			"employment_type": employee.EmploymentType,
+			"manager_id":      employee.ManagerID,
+			"division_id":     employee.DivisionID,
+			"names":           employee.Names,
+			"pronouns":        employee.Pronouns,
+		}); err != nil {
+			return err
+		}
+		if updated, err = s.load(tx, orgID, "employees.id = ?", id); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "employee.update", EntityType: "employee", EntityID: fmt.Sprintf("%d", id),
+			Before: before.Employee, After: updated.Employee,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) ApplyTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, patch Patch) (*Detail, error) {
+	before, err := s.load(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "employees"}}), orgID, "employees.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	employee := before.Employee
+	if patch.JobTitle != nil {
+		employee.JobTitle = strings.TrimSpace(*patch.JobTitle)
+		if employee.JobTitle == "" {
+			return nil, fmt.Errorf("%w: a job title is required", ErrInvalidEmployee)
+		}
+	}
+	if patch.EmploymentType != nil {
+		employee.EmploymentType = *patch.EmploymentType
+	}
+	if patch.DivisionID != nil {
+		employee.DivisionID = patch.DivisionID
+	}
+	if patch.ManagerID != nil {
+		employee.ManagerID = patch.ManagerID
+	}
+	if err := s.validate(tx, &employee); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&Employee{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"job_title":       employee.JobTitle,
+		"employment_type": employee.EmploymentType,
+		"manager_id":      employee.ManagerID,
+		"division_id":     employee.DivisionID,
+		"version":         gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update employee %d: %w", id, err)
+	}
+	updated, err := s.load(tx, orgID, "employees.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "employee.update", EntityType: "employee", EntityID: fmt.Sprintf("%d", id),
+		Before: before.Employee, After: updated.Employee,
+	}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Delete(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.load(tx, orgID, "employees.id = ?", id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Employee{}).Where("manager_id = ?", id).Update("manager_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach direct reports: %w", err)
+		}
+		if err := tx.Table("divisions").Where("head_id = ?", id).Update("head_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach headed divisions: %w", err)
+		}
+		if err := tx.Delete(&Employee{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete employee %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "employee.delete", EntityType: "employee", EntityID: fmt.Sprintf("%d", id), Before: before.Employee,
+		})
+	})
+}
+
+// UpdateProfile replaces the preferred names and keeps the legal ones, which only HR changes.
+func (s *service) UpdateProfile(actor audit.Actor, userID uint, req ProfileRequest) (*Detail, error) {
+	var updated *Detail
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Employee
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Take(&before).Error; err != nil {
+			return err
+		}
+		names, err := mergeProfile(decodeNames(before.Names), req.PreferredNames)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&before).Updates(map[string]interface{}{
+			"names": encodeNames(names), "pronouns": strings.TrimSpace(req.Pronouns), "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update profile: %w", err)
+		}
+		if updated, err = s.load(tx, nil, "employees.id = ?", before.ID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "employee.profile_update", EntityType: "employee", EntityID: fmt.Sprintf("%d", before.ID),
+			Before: before, After: updated.Employee,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Profile(orgID *uint, id uint, viewer Viewer) (*Profile, error) {
+	employee, err := s.Get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	var viewerEmployeeID *uint
+	var own []Employee
+	if err := s.db.Select("id").Where("user_id = ?", viewer.UserID).Limit(1).Find(&own).Error; err != nil {
+		return nil, fmt.Errorf("failed to load viewer's employee record: %w", err)
+	}
+	if len(own) > 0 {
+		viewerEmployeeID = &own[0].ID
+	}
+	employees := []Detail{*employee}
+	if err := s.Redact(employees, viewer.audience(employee, viewerEmployeeID)); err != nil {
+		return nil, err
+	}
+	profile := employees[0].profile()
+	return &profile, nil
+}
+
+func (s *service) Redact(employees []Detail, audience Audience) error {
+	if audience == AudienceHR {
+		return nil
+	}
+	policies := newNamePolicies(s.db)
+	for i := range employees {
+		policy, err := policies.get(employees[i].OrganizationID)
+		if err != nil {
+			return err
+		}
+		employees[i].redact(policy.FieldVisibility(), audience)
+	}
+	return nil
+}
+
+func (s *service) DisplayNames(orgID *uint, usage Usage, employeeIDs []uint) (map[uint]DisplayName, error) {
+	names := make(map[uint]DisplayName, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return names, nil
+	}
+	var employees []Detail
+	if err := s.details(s.db, orgID).Select("employees.*, users.username, users.email").
+		Where("employees.id IN ?", employeeIDs).Find(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+	policies := newNamePolicies(s.db)
+	for _, e := range employees {
+		name, err := policies.resolve(usage, e.OrganizationID, e.Names, e.Username)
+		if err != nil {
+			return nil, err
+		}
+		names[e.ID] = name
+	}
+	return names, nil
+}
+
+func (s *service) NamePolicy(orgID *uint) (*NamePolicy, error) {
+	return namePolicy(s.db, orgID)
+}
+
+func (s *service) SetNamePolicy(actor audit.Actor, orgID *uint, req NamePolicyRequest) (*NamePolicy, error) {
+	if req.Directory.Kind != PreferredName {
+		return nil, fmt.Errorf("%w: the directory shows the names employees go by; legal names are for documents and payslips", ErrInvalidEmployee)
+	}
+	for _, rule := range []NameRule{req.Directory, req.Document, req.Payslip} {
+		for _, code := range rule.Scripts {
+			if _, ok := scripts[code]; !ok {
+				return nil, fmt.Errorf("%w: unsupported script %q", ErrInvalidEmployee, code)
+			}
+		}
+	}
+	var updated NamePolicy
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		key := "none"
+		if orgID != nil {
+			key = fmt.Sprintf("%d", *orgID)
+		}
+		// Organizations have one policy row; the lock keeps two first saves from creating two.
+		if err := lock.Tx(tx, "employee-name-policy:"+key); err != nil {
+			return err
+		}
+		before, err := namePolicy(tx, orgID)
+		if err != nil {
+			return err
+		}
+		updated = newNamePolicy(orgID, req)
+		updated.ID = before.ID
+		if err := tx.Save(&updated).Error; err != nil {
+			return fmt.Errorf("failed to save name policy: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "employee_name_policy.update", EntityType: "employee_name_policy", EntityID: key, Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// validate checks the employee number is free, the division is one of the organization's, and the
+// manager is a colleague who doesn't (indirectly) report to the employee.
+func (s *service) validate(tx *gorm.DB, employee *Employee) error {
+	var taken int64
+	query := tx.Model(&Employee{}).Where("employee_number = ? AND id <> ?", employee.EmployeeNumber, employee.ID)
+	if err := utils.OrgScope(query, employee.OrganizationID).Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check employee numbers: %w", err)
+	}
+	if taken > 0 {
+		return ErrNumberTaken
+	}
+	if employee.DivisionID != nil {
+		// Queried by table name: the division package builds on this one.
+		var divisions int64
+		query := tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", *employee.DivisionID)
+		if err := utils.OrgScope(query, employee.OrganizationID).Count(&divisions).Error; err != nil {
+			return fmt.Errorf("failed to load division %d: %w", *employee.DivisionID, err)
+		}
+		if divisions == 0 {
+			return fmt.Errorf("%w: division %d not found", ErrInvalidEmployee, *employee.DivisionID)
+		}
+	}
+	if employee.ManagerID == nil {
+		return nil
+	}
+	next := *employee.ManagerID
+	for depth := 0; depth < maxManagerDepth; depth++ {
+		if employee.ID != 0 && next == employee.ID {
+			return fmt.Errorf("%w: the manager reports to this employee", ErrInvalidEmployee)
+		}
+		var manager Employee
+		err := utils.OrgScope(tx.Select("id", "manager_id"), employee.OrganizationID).First(&manager, next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if next == *employee.ManagerID {
+				return fmt.Errorf("%w: manager %d not found", ErrInvalidEmployee, next)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load manager %d: %w", next, err)
+		}
+		if manager.ManagerID == nil {
+			return nil
+		}
+		next = *manager.ManagerID
+	}
+	return fmt.Errorf("%w: management chain deeper than %d levels", ErrInvalidEmployee, maxManagerDepth)
+}
+
+// details selects employees joined with their users, within the organization scope.
+func (s *service) details(db *gorm.DB, orgID *uint) *gorm.DB {
+	query := db.Model(&Employee{}).Joins("JOIN users ON users.id = employees.user_id")
+	if orgID != nil {
+		query = query.Where("employees.organization_id = ?", *orgID)
+	}
+	return query
+}
+
+// load fetches one employee matching condition, within the organization scope.
+func (s *service) load(db *gorm.DB, orgID *uint, condition string, args ...interface{}) (*Detail, error) {
+	var employee Detail
+	if err := s.details(db, orgID).Select("employees.*, users.username, users.email").
+		Where(condition, args...).Take(&employee).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	name, err := newNamePolicies(db).resolve(UsageDirectory, employee.OrganizationID, employee.Names, employee.Username)
+	if err != nil {
+		return nil, err
+	}
+	employee.DisplayName = name
+	return &employee, nil
+}
+
+func apply(employee *Employee, req Request, hireDate time.Time) {
+	employee.EmployeeNumber = strings.TrimSpace(req.EmployeeNumber)
+	employee.JobTitle = strings.TrimSpace(req.JobTitle)
+	employee.HireDate = hireDate
+	employee.EmploymentType = req.EmploymentType
+	employee.ManagerID = req.ManagerID
+	employee.DivisionID = req.DivisionID
+	employee.Names = encodeNames(req.Names)
+	employee.Pronouns = strings.TrimSpace(req.Pronouns)
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidEmployee)
+	}
+	return date, nil
+}
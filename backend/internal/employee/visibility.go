@@ -0,0 +1,121 @@
+// prometheus/backend/internal/employee/visibility.go
+package employee
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Audience is who looks at an employee's record, as far as restricted fields are concerned.
+type Audience string
+
+const (
+	AudienceEveryone Audience = "everyone" // Any colleague
+	AudienceManagers Audience = "managers" // Managers of the employee or their division
+	AudienceHR       Audience = "hr"       // HR and payroll, and the employee themself
+)
+
+// audienceRank orders audiences from the widest to the most trusted.
+var audienceRank = map[Audience]int{AudienceEveryone: 0, AudienceManagers: 1, AudienceHR: 2}
+
+// sees reports whether the audience may see a field visible to level.
+func (a Audience) sees(level Audience) bool {
+	return audienceRank[a] >= audienceRank[level]
+}
+
+// FieldVisibility is who, besides HR and the employee, may see the restricted fields of employee records.
+// Preferred names aren't restricted: they are what everyone is shown.
+type FieldVisibility struct {
+	LegalName Audience `json:"legal_name" binding:"required,oneof=everyone managers hr" example:"hr"`
+	Pronouns  Audience `json:"pronouns" binding:"required,oneof=everyone managers hr" example:"everyone"`
+}
+
+// DefaultFieldVisibility keeps legal names to HR and payroll, and shows pronouns to everyone.
+func DefaultFieldVisibility() FieldVisibility {
+	return FieldVisibility{LegalName: AudienceHR, Pronouns: AudienceEveryone}
+}
+
+// Viewer is a colleague looking at an employee's profile.
+type Viewer struct {
+	UserID     uint
+	HR         bool   // Holds an HR role globally
+	ManagesAll bool   // Holds the manager role globally
+	Manages    []uint // Divisions the viewer manages through a division-scoped role
+}
+
+// audience is what the viewer counts as for an employee: HR for their own record, a manager for their
+// direct reports and members of divisions they manage.
+func (v Viewer) audience(employee *Detail, viewerEmployeeID *uint) Audience {
+	if v.HR || employee.UserID == v.UserID {
+		return AudienceHR
+	}
+	if v.ManagesAll || (employee.DivisionID != nil && slices.Contains(v.Manages, *employee.DivisionID)) ||
+		(viewerEmployeeID != nil && employee.ManagerID != nil && *employee.ManagerID == *viewerEmployeeID) {
+		return AudienceManagers
+	}
+	return AudienceEveryone
+}
+
+// Profile is what a colleague sees of an employee: names and pronouns as far as the organization's
+// visibility rules allow, and none of the HR fields.
+type Profile struct {
+	ID          uint        `json:"id" example:"12"`
+	UserID      uint        `json:"user_id" example:"7"`
+	Username    string      `json:"username" example:"jdoe"`
+	DisplayName DisplayName `json:"display_name"`
+	Names       []Name      `json:"names"`
+	Pronouns    string      `json:"pronouns,omitempty" example:"she/her"`
+	JobTitle    string      `json:"job_title" example:"Payroll Specialist"`
+	DivisionID  *uint       `json:"division_id,omitempty" example:"2"`
+	ManagerID   *uint       `json:"manager_id,omitempty" example:"3"`
+}
+
+// ProfileRequest is how employees describe themselves: their pronouns and the names they go by. Legal
+// names are kept by HR.
+type ProfileRequest struct {
+	Pronouns       string `json:"pronouns" binding:"max=50" example:"she/her"`
+	PreferredNames []Name `json:"preferred_names" binding:"max=10,dive"` // Replaces all preferred names; kind must be "preferred"
+}
+
+// redact removes what the audience may not see from an employee record. The display name is kept: without
+// a preferred name, the legal name is the one the employee goes by.
+func (d *Detail) redact(visibility FieldVisibility, audience Audience) {
+	if !audience.sees(visibility.LegalName) {
+		names := decodeNames(d.Names)
+		kept := make([]Name, 0, len(names))
+		for _, n := range names {
+			if n.Kind != LegalName {
+				kept = append(kept, n)
+			}
+		}
+		d.Names = encodeNames(kept)
+	}
+	if !audience.sees(visibility.Pronouns) {
+		d.Pronouns = ""
+	}
+}
+
+// profile returns the colleague's view of a redacted record.
+func (d *Detail) profile() Profile {
+	return Profile{
+		ID: d.ID, UserID: d.UserID, Username: d.Username, DisplayName: d.DisplayName, Names: append([]Name{}, decodeNames(d.Names)...),
+		Pronouns: d.Pronouns, JobTitle: d.JobTitle, DivisionID: d.DivisionID, ManagerID: d.ManagerID,
+	}
+}
+
+// mergeProfile replaces the preferred names among an employee's names, keeping the legal ones.
+func mergeProfile(current []Name, preferred []Name) ([]Name, error) {
+	merged := make([]Name, 0, len(current)+len(preferred))
+	for _, n := range current {
+		if n.Kind == LegalName {
+			merged = append(merged, n)
+		}
+	}
+	for _, n := range preferred {
+		if n.Kind != PreferredName {
+			return nil, fmt.Errorf("%w: only preferred names can be changed on your profile", ErrInvalidEmployee)
+		}
+		merged = append(merged, n)
+	}
+	return normalizeNames(merged)
+}
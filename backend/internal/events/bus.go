@@ -0,0 +1,84 @@
+// prometheus/backend/internal/events/bus.go
+package events
+
+import (
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bus publishes domain events and delivers them to in-process subscribers. Events are written in the
+// publisher's transaction, so an event exists exactly when the change it describes was committed, and
+// subscribers only ever see committed events.
+type Bus interface {
+	PublishTx(tx *gorm.DB, event Event) error
+	// Subscribe delivers the events matching any of patterns (see Feed.Read) to handler, in order, for the
+	// consumer group. A group receives each event once across instances, resuming where it left off after a
+	// restart; a group subscribing for the first time catches up on the events of the last ReplayWindow.
+	Subscribe(group string, patterns []string, handler HandlerFunc) error
+	// Unsubscribe stops delivering to the group in this process, waiting for its handler to return. The
+	// group keeps its position for when it subscribes again.
+	Unsubscribe(group string)
+	// Close unsubscribes every group.
+	Close()
+}
+
+// bus implements the Bus interface on the events table.
+type bus struct {
+	outbox      bool
+	subscribers *subscribers
+}
+
+// NewBus creates a new instance of Bus. With outbox set, every event also gets an OutboxEntry for the
+// event bridge to relay to Kafka or NATS.
+func NewBus(db *gorm.DB, outbox bool) Bus {
+	return &bus{outbox: outbox, subscribers: &subscribers{db: db, groups: make(map[string]*subscription)}}
+}
+
+func (b *bus) PublishTx(tx *gorm.DB, event Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", event.Type, err)
+	}
+	if b.outbox {
+		if err := tx.Create(&OutboxEntry{EventID: event.ID}).Error; err != nil {
+			return fmt.Errorf("failed to queue event %s for the bridge: %w", event.Type, err)
+		}
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(group string, patterns []string, handler HandlerFunc) error {
+	return b.subscribers.subscribe(group, patterns, handler)
+}
+
+func (b *bus) Unsubscribe(group string) {
+	b.subscribers.unsubscribe(group)
+}
+
+func (b *bus) Close() {
+	b.subscribers.close()
+}
+
+// AuditHook publishes every audited change as an event named after the audit action (e.g. "user.import"),
+// scoped to the actor's organization.
+func AuditHook(b Bus) audit.Hook {
+	return func(tx *gorm.DB, actor audit.Actor, record *audit.Log) error {
+		data := record.After
+		if len(data) == 0 {
+			data = record.Before
+		}
+		return b.PublishTx(tx, Event{
+			Type:           record.Action,
+			OrganizationID: actor.OrganizationID,
+			EntityType:     record.EntityType,
+			EntityID:       record.EntityID,
+			Data:           data,
+			OccurredAt:     record.CreatedAt,
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// prometheus/backend/internal/events/feed.go
+package events
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobPurge is the recurring job type that drops events past the retention period.
+const JobPurge = "events.purge"
+
+// Retention is how long events stay in the change feed.
+const Retention = 30 * 24 * time.Hour
+
+// settleDelay holds back the newest events. IDs are assigned when an event is written, not when its
+// transaction commits, so a slow transaction can commit an event with a lower ID than one already
+// visible; readers must not move their cursor past it before it shows up.
+const settleDelay = 10 * time.Second
+
+const (
+	defaultFeedLimit = 100
+	maxFeedLimit     = 500
+)
+
+// Feed reads the change feed for integrators.
+type Feed interface {
+	// Read returns events after the cursor since (0 = from the start of the retention window) of the
+	// organization (nil = default organization) whose type matches any of patterns. A pattern is an event
+	// type, a prefix ending in ".*" (e.g. "user.*"), or "*" for all. types narrows the result to exact types.
+	Read(orgID *uint, patterns, types []string, since uint64, limit int) (*FeedPage, error)
+	Purge(ctx context.Context) (int64, error)
+}
+
+// feed implements the Feed interface.
+type feed struct {
+	db *gorm.DB
+}
+
+// NewFeed creates a new instance of Feed.
+func NewFeed(db *gorm.DB) Feed {
+	return &feed{db: db}
+}
+
+func (f *feed) Read(orgID *uint, patterns, types []string, since uint64, limit int) (*FeedPage, error) {
+	if limit <= 0 || limit > maxFeedLimit {
+		limit = defaultFeedLimit
+	}
+	page := &FeedPage{Events: []Event{}, NextCursor: strconv.FormatUint(since, 10)}
+	matches, args := typeCondition(patterns)
+	if matches == "" {
+		return page, nil
+	}
+
+	query := f.db.Where("id > ? AND occurred_at <= ?", since, time.Now().UTC().Add(-settleDelay)).
+		Where(matches, args...)
+	if orgID == nil {
+		query = query.Where("organization_id IS NULL")
+	} else {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	var events []Event
+	if err := query.Order("id").Limit(limit + 1).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(events) > limit {
+		events, page.HasMore = events[:limit], true
+	}
+	if len(events) > 0 {
+		page.Events = events
+		page.NextCursor = strconv.FormatUint(events[len(events)-1].ID, 10)
+	}
+	return page, nil
+}
+
+// Purge deletes events older than Retention.
+func (f *feed) Purge(ctx context.Context) (int64, error) {
+	result := f.db.WithContext(ctx).Where("occurred_at < ?", time.Now().UTC().Add(-Retention)).Delete(&Event{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// typeCondition turns type patterns into a WHERE condition; empty if no pattern can match.
+func typeCondition(patterns []string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for _, p := range patterns {
+		switch {
+		case p == "*":
+			return "1 = 1", nil
+		case strings.HasSuffix(p, ".*"):
+			clauses = append(clauses, "type LIKE ?")
+			args = append(args, utils.PrefixPattern(strings.TrimSuffix(p, "*")))
+		case p != "":
+			clauses = append(clauses, "type = ?")
+			args = append(args, p)
+		}
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// PurgeJob runs Purge as a recurring job.
+func PurgeJob(f Feed) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		purged, err := f.Purge(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int64{"purged": purged}, nil
+	}
+}
@@ -0,0 +1,68 @@
+// prometheus/backend/internal/events/handler.go
+package events
+
+import (
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for the change feed.
+type Handler struct {
+	feed Feed
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(feed Feed) *Handler {
+	return &Handler{feed: feed}
+}
+
+// List returns domain events after a cursor, for integrations that poll instead of receiving webhooks.
+// @Summary Read the change feed
+// @Description Authenticated by API key. Returns the events of the key's organization whose types the key
+// @Description may read, oldest first. Store next_cursor and pass it as since on the next poll; events are
+// @Description kept for 30 days and appear about 10 seconds after they happen.
+// @Tags Events
+// @Produce json
+// @Param since query string false "Cursor from a previous page (omit to start at the oldest event)"
+// @Param limit query int false "Events per page (max 500)" default(100)
+// @Param types query string false "Comma-separated event types to return"
+// @Success 200 {object} FeedPage
+// @Failure 400 {object} utils.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid API key"
+// @Router /events [get]
+func (h *Handler) List(c *gin.Context) {
+	key := middleware.APIKeyFromContext(c)
+	if key == nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "API key required")
+		return
+	}
+	var since uint64
+	if raw := c.Query("since"); raw != "" {
+		var err error
+		if since, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid since cursor")
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	page, err := h.feed.Read(key.OrganizationID, key.EventPatterns(), types, since, limit)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Events fetched successfully", page)
+}
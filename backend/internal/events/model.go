@@ -0,0 +1,27 @@
+// prometheus/backend/internal/events/model.go
+package events
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Event is a domain event: something that changed, in the order it was published. The ID is the
+// cursor of the change feed.
+type Event struct {
+	ID             uint64         `gorm:"primaryKey" json:"id" example:"1042"`
+	Type           string         `gorm:"type:varchar(100);not null;index" json:"type" example:"user.deactivate"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"` // nil = default organization or platform-wide
+	EntityType     string         `gorm:"type:varchar(100);not null" json:"entity_type" example:"user"`
+	EntityID       string         `gorm:"type:varchar(255)" json:"entity_id" example:"7"`
+	Data           datatypes.JSON `json:"data,omitempty" swaggertype:"object"` // State after the change (before it, for deletions)
+	OccurredAt     time.Time      `gorm:"not null;index" json:"occurred_at"`
+}
+
+// FeedPage is a page of the change feed.
+type FeedPage struct {
+	Events     []Event `json:"events"`
+	NextCursor string  `json:"next_cursor" example:"1042"` // Pass as ?since= to continue after the last event
+	HasMore    bool    `json:"has_more"`                   // More events are available right away
+}
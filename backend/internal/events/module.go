@@ -0,0 +1,96 @@
+// prometheus/backend/internal/events/module.go
+package events
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the events module.
+const ModuleName = "events"
+
+// BridgeModuleName is the name of the event bridge module.
+const BridgeModuleName = "event-bridge"
+
+// relayInterval is how often the outbox is drained; events reach the broker within about this delay.
+const relayInterval = 10 * time.Second
+
+// staleBacklog is the outbox age at which the bridge reports itself degraded.
+const staleBacklog = 5 * time.Minute
+
+// eventsModule reports on the consumer groups subscribed to the bus. Events themselves are core tables.
+type eventsModule struct {
+	db *gorm.DB
+}
+
+// NewModule creates the events module for the module registry.
+func NewModule(db *gorm.DB) module.Module {
+	return &eventsModule{db: db}
+}
+
+func (m *eventsModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. A group whose handler keeps failing is stuck at the event
+// it fails on; the events after it wait until it is fixed, or until they leave the replay window.
+func (m *eventsModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("consumers", func(ctx context.Context) module.HealthResult {
+			var groups, failing int64
+			if err := m.db.WithContext(ctx).Model(&Consumer{}).Count(&groups).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := m.db.WithContext(ctx).Model(&Consumer{}).Where("last_error <> ''").Count(&failing).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if failing > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"groups": float64(groups), "failing": float64(failing)}}
+		}),
+	}
+}
+
+// bridgeModule relays domain events to Kafka or NATS through the outbox.
+type bridgeModule struct {
+	relay *Relay
+}
+
+// NewBridgeModule creates the event bridge module for the module registry.
+func NewBridgeModule(relay *Relay) module.Module {
+	return &bridgeModule{relay: relay}
+}
+
+func (m *bridgeModule) Name() string { return BridgeModuleName }
+
+func (m *bridgeModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("outbox", func(ctx context.Context) module.HealthResult {
+			pending, age, err := m.relay.Backlog(ctx)
+			if err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			// Events are kept in the outbox while the broker is unreachable; nothing is lost, but consumers lag.
+			status := module.StatusUp
+			if age > staleBacklog {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"pending": float64(pending), "oldest_seconds": age.Seconds()}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *bridgeModule) Models() []any {
+	return []any{&OutboxEntry{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *bridgeModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobRelay, RelayJob(m.relay))
+	q.Every(JobRelay, relayInterval)
+}
@@ -0,0 +1,136 @@
+// prometheus/backend/internal/events/outbox.go
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/jobs"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRelay is the recurring job type that forwards outbox entries to the event bridge.
+const JobRelay = "events.relay"
+
+// relayBatchSize is how many events are published per broker round trip.
+const relayBatchSize = 100
+
+// OutboxEntry marks an event that still has to be published to the bridge. Entries are written in the
+// same transaction as their event and deleted once the broker acknowledged it, so every committed event
+// is published at least once, even across crashes.
+type OutboxEntry struct {
+	ID        uint64    `gorm:"primaryKey"`
+	EventID   uint64    `gorm:"not null;index"`
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"type:varchar(500)"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName implements gorm's Tabler.
+func (OutboxEntry) TableName() string { return "event_outbox" }
+
+// Relay forwards outbox entries to a Publisher.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+}
+
+// NewRelay creates a Relay publishing through publisher.
+func NewRelay(db *gorm.DB, publisher Publisher) *Relay {
+	return &Relay{db: db, publisher: publisher}
+}
+
+// Drain publishes outbox entries in batches until the outbox is empty or a batch fails.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, more, err := r.relayBatch(ctx)
+		total += n
+		if err != nil || !more {
+			return total, err
+		}
+	}
+}
+
+// relayBatch publishes the oldest entries. Locked rows are skipped, so replicas relaying concurrently
+// publish disjoint batches. A failed batch stays in the outbox for the next run.
+func (r *Relay) relayBatch(ctx context.Context) (published int, more bool, err error) {
+	var publishErr error
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entries []OutboxEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("id").Limit(relayBatchSize).Find(&entries).Error; err != nil {
+			return fmt.Errorf("failed to load outbox: %w", err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		more = len(entries) == relayBatchSize
+		entryIDs := make([]uint64, 0, len(entries))
+		eventIDs := make([]uint64, 0, len(entries))
+		for _, e := range entries {
+			entryIDs = append(entryIDs, e.ID)
+			eventIDs = append(eventIDs, e.EventID)
+		}
+		// Events purged by retention before they could be published are dropped with their entries.
+		var batch []Event
+		if err := tx.Where("id IN ?", eventIDs).Order("id").Find(&batch).Error; err != nil {
+			return fmt.Errorf("failed to load events: %w", err)
+		}
+
+		if publishErr = r.publisher.Publish(ctx, batch); publishErr != nil {
+			more = false
+			return tx.Model(&OutboxEntry{}).Where("id IN ?", entryIDs).Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": truncate(publishErr.Error(), 500),
+			}).Error
+		}
+		published = len(batch)
+		return tx.Where("id IN ?", entryIDs).Delete(&OutboxEntry{}).Error
+	})
+	if err == nil && publishErr != nil {
+		err = fmt.Errorf("failed to publish events: %w", publishErr)
+	}
+	return published, more, err
+}
+
+// Backlog returns how many entries wait in the outbox and the age of the oldest.
+func (r *Relay) Backlog(ctx context.Context) (int64, time.Duration, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&OutboxEntry{}).Count(&count).Error; err != nil {
+		return 0, 0, err
+	}
+	var oldest OutboxEntry
+	err := r.db.WithContext(ctx).Order("id").First(&oldest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return count, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, time.Since(oldest.CreatedAt), nil
+}
+
+// RelayJob runs Drain as a recurring job.
+func RelayJob(r *Relay) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		published, err := r.Drain(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"published": published}, nil
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,137 @@
+// prometheus/backend/internal/events/publisher.go
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prometheus/backend/config"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher delivers events to external streaming infrastructure. Publish returns only once the broker
+// acknowledged every event of the batch.
+type Publisher interface {
+	Publish(ctx context.Context, batch []Event) error
+	Close() error
+}
+
+// NewPublisher creates the Publisher selected by EVENT_BRIDGE ("kafka" or "nats").
+func NewPublisher(cfg *config.Config) (Publisher, error) {
+	var urls []string
+	for _, u := range strings.Split(cfg.EventBridgeURLs, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("EVENT_BRIDGE_URLS is required for the %s event bridge", cfg.EventBridge)
+	}
+	switch cfg.EventBridge {
+	case "kafka":
+		return newKafkaPublisher(urls, cfg.EventBridgeTopic), nil
+	case "nats":
+		return &natsPublisher{url: strings.Join(urls, ","), subject: cfg.EventBridgeTopic}, nil
+	default:
+		return nil, fmt.Errorf("unknown event bridge %q, use kafka or nats", cfg.EventBridge)
+	}
+}
+
+// kafkaPublisher writes events to a Kafka topic. Messages are keyed by entity, so consumers see the
+// changes of one entity in order.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func newKafkaPublisher(brokers []string, topic string) *kafkaPublisher {
+	return &kafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, batch []Event) error {
+	messages := make([]kafka.Message, 0, len(batch))
+	for _, e := range batch {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", e.ID, err)
+		}
+		messages = append(messages, kafka.Message{
+			Key:   []byte(e.EntityType + ":" + e.EntityID),
+			Value: value,
+			Headers: []kafka.Header{
+				{Key: "event-id", Value: []byte(strconv.FormatUint(e.ID, 10))},
+				{Key: "event-type", Value: []byte(e.Type)},
+			},
+		})
+	}
+	return p.writer.WriteMessages(ctx, messages...)
+}
+
+func (p *kafkaPublisher) Close() error { return p.writer.Close() }
+
+// natsPublisher publishes events to JetStream on "<subject>.<event type>". The event ID is sent as the
+// message ID, so JetStream drops the duplicates a retried batch may produce. The subjects must be
+// captured by a stream configured on the server.
+type natsPublisher struct {
+	url     string
+	subject string
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, batch []Event) error {
+	js, err := p.jetStream()
+	if err != nil {
+		return err
+	}
+	for _, e := range batch {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", e.ID, err)
+		}
+		if _, err := js.Publish(p.subject+"."+e.Type, data, nats.Context(ctx), nats.MsgId(strconv.FormatUint(e.ID, 10))); err != nil {
+			return fmt.Errorf("failed to publish event %d: %w", e.ID, err)
+		}
+	}
+	return nil
+}
+
+// jetStream connects on first use, so the API starts even while NATS is unreachable.
+func (p *natsPublisher) jetStream() (nats.JetStreamContext, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.js != nil {
+		return p.js, nil
+	}
+	conn, err := nats.Connect(p.url, nats.Name("prometheus-event-bridge"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+	p.conn, p.js = conn, js
+	return js, nil
+}
+
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// prometheus/backend/internal/events/subscribe.go
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/lock"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReplayWindow is how far back a consumer group catches up: a group subscribing for the first time
+// starts with the events of this window, and one that was away longer skips what came before it.
+const ReplayWindow = 24 * time.Hour
+
+const (
+	// bufferSize bounds the events read ahead of a group's handler; reading waits while the buffer is full.
+	bufferSize = 256
+	// consumeBatch is how many events are read per query.
+	consumeBatch = 100
+	// pollInterval is how often a caught-up group looks for new events. Events are only read once
+	// settleDelay has passed, so they reach subscribers about that long after they were published.
+	pollInterval = 2 * time.Second
+	// retryDelay is how long a group waits after its handler failed before it tries the event again, and
+	// how often an instance on standby checks whether the instance consuming the group went away.
+	retryDelay = 30 * time.Second
+	// saveInterval bounds how often a group's cursor is saved while it works through events; at most this
+	// much work is handled again after a crash.
+	saveInterval = time.Second
+)
+
+// ErrGroupTaken is returned when subscribing a consumer group that already has a handler in this process.
+var ErrGroupTaken = errors.New("the consumer group already has a subscriber")
+
+// HandlerFunc consumes an event delivered to a consumer group. An error stops the group at the event, which
+// is delivered again after retryDelay, so handlers should be idempotent.
+type HandlerFunc func(ctx context.Context, event Event) error
+
+// Consumer is the position of a consumer group in the events: the last event its handler consumed.
+type Consumer struct {
+	Group       string    `gorm:"column:group_name;type:varchar(100);primaryKey" json:"group" example:"webhooks"`
+	LastEventID uint64    `gorm:"not null" json:"last_event_id" example:"1042"`
+	LastError   string    `gorm:"type:varchar(500)" json:"last_error,omitempty"` // Of the handler; cleared once it consumes an event
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName implements gorm's Tabler.
+func (Consumer) TableName() string { return "event_consumers" }
+
+// subscription is a consumer group subscribed in this process.
+type subscription struct {
+	group    string
+	patterns []string
+	handler  HandlerFunc
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// subscribers runs the consumer groups subscribed in this process. Each group consumes on one instance at
+// a time, under an advisory lock; other instances subscribing the same group stand by and take over if
+// that instance goes away.
+type subscribers struct {
+	db *gorm.DB
+
+	mu     sync.Mutex
+	groups map[string]*subscription
+}
+
+func (s *subscribers) subscribe(group string, patterns []string, handler HandlerFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.groups[group]; ok {
+		return fmt.Errorf("%w: %s", ErrGroupTaken, group)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{group: group, patterns: patterns, handler: handler, cancel: cancel, done: make(chan struct{})}
+	s.groups[group] = sub
+	go s.run(ctx, sub)
+	return nil
+}
+
+func (s *subscribers) unsubscribe(group string) {
+	s.mu.Lock()
+	sub, ok := s.groups[group]
+	delete(s.groups, group)
+	s.mu.Unlock()
+	if ok {
+		sub.cancel()
+		<-sub.done
+	}
+}
+
+func (s *subscribers) close() {
+	s.mu.Lock()
+	groups := make([]string, 0, len(s.groups))
+	for group := range s.groups {
+		groups = append(groups, group)
+	}
+	s.mu.Unlock()
+	for _, group := range groups {
+		s.unsubscribe(group)
+	}
+}
+
+// run consumes for a group until it is unsubscribed, standing by while another instance holds the group.
+func (s *subscribers) run(ctx context.Context, sub *subscription) {
+	defer close(sub.done)
+	for {
+		err := lock.Try(ctx, s.db, "events:consumer:"+sub.group, func(ctx context.Context) error {
+			return s.consume(ctx, sub)
+		})
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, lock.ErrLocked):
+		case err != nil:
+			log.Printf("Event consumer group %s stopped: %v", sub.group, err)
+			s.recordError(sub.group, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// consume reads the group's events into a bounded buffer and hands them to its handler in order, saving
+// the cursor as it goes. It returns the handler's first error, with the cursor just before the event.
+func (s *subscribers) consume(ctx context.Context, sub *subscription) error {
+	cursor, err := s.start(ctx, sub.group)
+	if err != nil {
+		return err
+	}
+	matches, args := typeCondition(sub.patterns)
+	if matches == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	buffer := make(chan Event, bufferSize)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(buffer)
+		readErr <- s.read(ctx, cursor, matches, args, buffer)
+	}()
+
+	saved, savedAt := cursor, time.Now()
+	for event := range buffer {
+		if err := sub.handler(ctx, event); err != nil {
+			if ctx.Err() == nil {
+				err = fmt.Errorf("event %d (%s): %w", event.ID, event.Type, err)
+			}
+			return errors.Join(err, s.save(sub.group, cursor))
+		}
+		cursor = event.ID
+		// Save when caught up, or every saveInterval while busy.
+		if len(buffer) == 0 || time.Since(savedAt) >= saveInterval {
+			if err := s.save(sub.group, cursor); err != nil {
+				return err
+			}
+			saved, savedAt = cursor, time.Now()
+		}
+	}
+	if cursor != saved {
+		if err := s.save(sub.group, cursor); err != nil {
+			return err
+		}
+	}
+	return <-readErr
+}
+
+// read sends the settled events after cursor matching the condition to buffer, blocking while it is full,
+// until ctx is done.
+func (s *subscribers) read(ctx context.Context, cursor uint64, matches string, args []interface{}, buffer chan<- Event) error {
+	for {
+		var batch []Event
+		if err := s.db.WithContext(ctx).Where("id > ? AND occurred_at <= ?", cursor, time.Now().UTC().Add(-settleDelay)).
+			Where(matches, args...).Order("id").Limit(consumeBatch).Find(&batch).Error; err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read events: %w", err)
+		}
+		for _, event := range batch {
+			select {
+			case buffer <- event:
+				cursor = event.ID
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if len(batch) == consumeBatch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// start returns where a group continues: after its last event, but no earlier than ReplayWindow ago.
+func (s *subscribers) start(ctx context.Context, group string) (uint64, error) {
+	var replayFrom uint64
+	if err := s.db.WithContext(ctx).Model(&Event{}).Where("occurred_at < ?", time.Now().UTC().Add(-ReplayWindow)).
+		Select("COALESCE(MAX(id), 0)").Scan(&replayFrom).Error; err != nil {
+		return 0, fmt.Errorf("failed to find the start of the replay window: %w", err)
+	}
+	var consumer Consumer
+	err := s.db.WithContext(ctx).First(&consumer, "group_name = ?", group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Event consumer group %s starts with the events of the last %s.", group, ReplayWindow)
+		return replayFrom, s.save(group, replayFrom)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load consumer group %s: %w", group, err)
+	}
+	if consumer.LastEventID < replayFrom {
+		log.Printf("Event consumer group %s was away longer than %s; skipping to the replay window.", group, ReplayWindow)
+		return replayFrom, s.save(group, replayFrom)
+	}
+	return consumer.LastEventID, nil
+}
+
+// save records the group's cursor and clears its last error.
+func (s *subscribers) save(group string, cursor uint64) error {
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_event_id", "last_error", "updated_at"}),
+	}).Create(&Consumer{Group: group, LastEventID: cursor, UpdatedAt: time.Now().UTC()}).Error; err != nil {
+		return fmt.Errorf("failed to save the position of consumer group %s: %w", group, err)
+	}
+	return nil
+}
+
+// recordError keeps the reason a group stopped for the health check.
+func (s *subscribers) recordError(group string, err error) {
+	if err := s.db.Model(&Consumer{}).Where("group_name = ?", group).
+		Updates(map[string]interface{}{"last_error": truncate(err.Error(), 500), "updated_at": time.Now().UTC()}).Error; err != nil {
+		log.Printf("Failed to record the error of consumer group %s: %v", group, err)
+	}
+}
@@ -0,0 +1,678 @@
+// prometheus/backend/internal/expense/handler.go
+package expense
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// financeRole sees every submitted claim and reimburses approved ones.
+const financeRole = "finance"
+
+// Handler handles HTTP requests for expense claims and categories.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCategories returns the expense categories. Archived ones are only listed for HR, on request.
+// @Summary List expense categories
+// @Tags Expenses
+// @Produce json
+// @Param archived query bool false "Include archived categories (HR only)"
+// @Success 200 {array} Category
+// @Router /me/expense-categories [get]
+// @Router /hr/expense-categories [get]
+func (h *Handler) ListCategories(c *gin.Context) {
+	archived := c.Query("archived") == "true" && viewer(c).HR
+	categories, err := h.service.Categories(utils.OrganizationFromContext(c), archived)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense categories fetched successfully", categories)
+}
+
+// CreateCategory creates an expense category.
+// @Summary Create an expense category
+// @Description Limits are in minor units of the category's currency; claims in other currencies can't
+// @Description use a category with limits.
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param category body CategoryRequest true "Category"
+// @Success 201 {object} Category
+// @Failure 400 {object} utils.ErrorResponse "Invalid category"
+// @Router /hr/expense-categories [post]
+func (h *Handler) CreateCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	category, err := h.service.CreateCategory(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Expense category created successfully", category)
+}
+
+// UpdateCategory replaces an expense category's fields. Archiving it keeps it on existing claims.
+// @Summary Update an expense category
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param category body CategoryRequest true "Category"
+// @Success 200 {object} Category
+// @Failure 400 {object} utils.ErrorResponse "Invalid category"
+// @Failure 404 {object} utils.ErrorResponse "Category not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/expense-categories/{id} [put]
+func (h *Handler) UpdateCategory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetCategory(orgID, id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	category, err := h.service.UpdateCategory(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, category.UpdatedAt, category.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense category updated successfully", category)
+}
+
+// MyClaims returns the caller's expense claims.
+// @Summary List own expense claims
+// @Tags Expenses
+// @Produce json
+// @Param status query string false "Status" Enums(draft, submitted, approved, rejected, reimbursed)
+// @Success 200 {array} Claim
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/expenses [get]
+func (h *Handler) MyClaims(c *gin.Context) {
+	status, ok := parseStatus(c)
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	claims, err := h.service.MyClaims(utils.OrganizationFromContext(c), emp.ID, status)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claims fetched successfully", claims)
+}
+
+// ListClaims returns the claims of the caller's reports, or everyone's for HR and finance. Drafts are
+// left out.
+// @Summary List expense claims
+// @Tags Expenses
+// @Produce json
+// @Param status query string false "Status; all but drafts by default" Enums(submitted, approved, rejected, reimbursed)
+// @Param employee_id query int false "Employee ID"
+// @Param division_id query int false "Division ID"
+// @Success 200 {array} Claim
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/expenses [get]
+// @Router /finance/expenses [get]
+func (h *Handler) ListClaims(c *gin.Context) {
+	status, ok := parseStatus(c)
+	if !ok {
+		return
+	}
+	if status == StatusDraft {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Drafts are only listed to their owners")
+		return
+	}
+	filter := Filter{Status: status}
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	claims, err := h.service.Claims(utils.OrganizationFromContext(c), viewer(c), filter)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claims fetched successfully", claims)
+}
+
+// GetClaim returns an expense claim with its items.
+// @Summary Get an expense claim
+// @Tags Expenses
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Success 200 {object} Claim
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Router /me/expenses/{id} [get]
+// @Router /manager/expenses/{id} [get]
+// @Router /finance/expenses/{id} [get]
+func (h *Handler) GetClaim(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	claim, err := h.service.GetClaim(utils.OrganizationFromContext(c), viewer(c), id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, claim.UpdatedAt, claim.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claim fetched successfully", claim)
+}
+
+// CreateClaim starts a draft expense claim.
+// @Summary Create an expense claim
+// @Description Amounts are in minor units of the claim's currency. Add items, then submit the claim.
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param claim body ClaimRequest true "Claim"
+// @Success 201 {object} Claim
+// @Failure 400 {object} utils.ErrorResponse "Invalid claim"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/expenses [post]
+func (h *Handler) CreateClaim(c *gin.Context) {
+	var req ClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	claim, err := h.service.CreateClaim(audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Expense claim created successfully", claim)
+}
+
+// UpdateClaim replaces the title and currency of a draft or rejected claim.
+// @Summary Update an expense claim
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param claim body ClaimRequest true "Claim"
+// @Success 200 {object} Claim
+// @Failure 400 {object} utils.ErrorResponse "Invalid claim"
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim no longer editable"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /me/expenses/{id} [put]
+func (h *Handler) UpdateClaim(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetClaim(orgID, viewer(c), id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	claim, err := h.service.UpdateClaim(audit.ActorFromContext(c), orgID, emp.ID, id, expectedVersion, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, claim.UpdatedAt, claim.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claim updated successfully", claim)
+}
+
+// DeleteClaim deletes a draft claim with its receipts.
+// @Summary Delete a draft expense claim
+// @Tags Expenses
+// @Param id path int true "Claim ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim is no draft"
+// @Router /me/expenses/{id} [delete]
+func (h *Handler) DeleteClaim(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	if err := h.service.DeleteClaim(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, id); err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddItem adds an expense to a draft or rejected claim.
+// @Summary Add an expense to a claim
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param item body ItemRequest true "Expense"
+// @Success 201 {object} Claim
+// @Failure 400 {object} utils.ErrorResponse "Invalid expense"
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim no longer editable"
+// @Router /me/expenses/{id}/items [post]
+func (h *Handler) AddItem(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	claim, err := h.service.AddItem(audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, id, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Expense added successfully", claim)
+}
+
+// DeleteItem removes an expense, with its receipt, from a draft or rejected claim.
+// @Summary Remove an expense from a claim
+// @Tags Expenses
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param item_id path int true "Expense ID"
+// @Success 200 {object} Claim
+// @Failure 404 {object} utils.ErrorResponse "Claim or expense not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim no longer editable"
+// @Router /me/expenses/{id}/items/{item_id} [delete]
+func (h *Handler) DeleteItem(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := utils.ParseUintParam(c, "item_id")
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	claim, err := h.service.DeleteItem(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, id, itemID)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense removed successfully", claim)
+}
+
+// UploadReceipt attaches a receipt to an expense, replacing any earlier one.
+// @Summary Upload a receipt
+// @Description PNG, JPEG, GIF or WebP images, or PDFs, at most 10 MB, as the multipart field "receipt".
+// @Description Receipts count against the organization's storage quota.
+// @Tags Expenses
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param item_id path int true "Expense ID"
+// @Param receipt formData file true "Receipt"
+// @Success 200 {object} Item
+// @Failure 400 {object} utils.ErrorResponse "Missing file"
+// @Failure 402 {object} utils.ErrorResponse "Storage quota exceeded"
+// @Failure 404 {object} utils.ErrorResponse "Claim or expense not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim no longer editable"
+// @Failure 413 {object} utils.ErrorResponse "File too large"
+// @Failure 415 {object} utils.ErrorResponse "Unsupported file type"
+// @Router /me/expenses/{id}/items/{item_id}/receipt [post]
+func (h *Handler) UploadReceipt(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := utils.ParseUintParam(c, "item_id")
+	if !ok {
+		return
+	}
+	// Leave room for the multipart envelope around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxReceiptSize+64<<10)
+	header, err := c.FormFile("receipt")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendExpenseError(c, ErrReceiptTooLarge)
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Missing multipart file field \"receipt\"")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Could not read the uploaded file")
+		return
+	}
+	defer file.Close()
+
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	item, err := h.service.UploadReceipt(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c),
+		emp.ID, id, itemID, file, header.Size, header.Filename)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Receipt uploaded successfully", item)
+}
+
+// GetReceipt serves an expense's receipt, redirecting to a signed storage URL when the backend supports them.
+// @Summary Get a receipt
+// @Tags Expenses
+// @Produce image/png
+// @Produce image/jpeg
+// @Produce application/pdf
+// @Param id path int true "Claim ID"
+// @Param item_id path int true "Expense ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a signed URL"
+// @Failure 404 {object} utils.ErrorResponse "Claim, expense or receipt not found"
+// @Router /me/expenses/{id}/items/{item_id}/receipt [get]
+// @Router /manager/expenses/{id}/items/{item_id}/receipt [get]
+// @Router /finance/expenses/{id}/items/{item_id}/receipt [get]
+func (h *Handler) GetReceipt(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := utils.ParseUintParam(c, "item_id")
+	if !ok {
+		return
+	}
+	receipt, err := h.service.Receipt(c.Request.Context(), utils.OrganizationFromContext(c), viewer(c), id, itemID)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	if receipt.URL != "" {
+		c.Redirect(http.StatusFound, receipt.URL)
+		return
+	}
+	defer receipt.Body.Close()
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, -1, receipt.ContentType, receipt.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", receipt.Name),
+	})
+}
+
+// Submit sends a claim to the caller's manager.
+// @Summary Submit an expense claim
+// @Description Checks that the claim has expenses, that categories requiring receipts have them, and the
+// @Description categories' per-expense and monthly limits; the latter count the caller's other claims
+// @Description that are submitted, approved or reimbursed.
+// @Tags Expenses
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Success 200 {object} Claim
+// @Failure 400 {object} utils.ErrorResponse "Empty claim or missing receipts"
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Already submitted, or over a category limit"
+// @Router /me/expenses/{id}/submit [post]
+func (h *Handler) Submit(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	claim, err := h.service.Submit(audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, claim.UpdatedAt, claim.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claim submitted successfully", claim)
+}
+
+// Decide approves or rejects a submitted claim of one of the caller's reports, or anyone's for HR.
+// @Summary Approve or reject an expense claim
+// @Description Rejections need a note; the employee may fix and resubmit the claim. No one decides
+// @Description their own claims.
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param decision body DecisionRequest true "Decision"
+// @Success 200 {object} Claim
+// @Failure 400 {object} utils.ErrorResponse "Rejection without a note"
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim not submitted"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /manager/expenses/{id}/decision [post]
+func (h *Handler) Decide(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID, who := utils.OrganizationFromContext(c), viewer(c)
+	current, err := h.service.GetClaim(orgID, who, id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	claim, err := h.service.Decide(audit.ActorFromContext(c), orgID, who, id, expectedVersion, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, claim.UpdatedAt, claim.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claim decided successfully", claim)
+}
+
+// Reimburse records that an approved claim was paid out.
+// @Summary Mark an expense claim as reimbursed
+// @Tags Expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param reimbursement body ReimburseRequest true "Payment"
+// @Success 200 {object} Claim
+// @Failure 404 {object} utils.ErrorResponse "Claim not found"
+// @Failure 409 {object} utils.ErrorResponse "Claim not approved"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /finance/expenses/{id}/reimburse [post]
+func (h *Handler) Reimburse(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ReimburseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetClaim(orgID, viewer(c), id)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	claim, err := h.service.Reimburse(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, claim.UpdatedAt, claim.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense claim reimbursed successfully", claim)
+}
+
+// Summaries adds expenses up per month, category and currency.
+// @Summary Summarize expenses by month
+// @Description Amounts of submitted, approved and reimbursed claims, by the month the expenses were
+// @Description incurred. from and to default to the past twelve months.
+// @Tags Expenses
+// @Produce json
+// @Param from query string false "First month (YYYY-MM)"
+// @Param to query string false "Last month (YYYY-MM)"
+// @Success 200 {array} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid range"
+// @Router /finance/expenses/summary [get]
+// @Router /hr/expenses/summary [get]
+func (h *Handler) Summaries(c *gin.Context) {
+	now := clock.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, -11, 0)
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if raw := c.Query(param); raw != "" {
+			month, err := time.Parse("2006-01", raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected YYYY-MM")
+				return
+			}
+			*target = month
+		}
+	}
+	if to.Before(from) {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+	// Through the last day of the to month.
+	summaries, err := h.service.Summaries(utils.OrganizationFromContext(c), from, to.AddDate(0, 1, -1))
+	if err != nil {
+		sendExpenseError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Expense summaries fetched successfully", summaries)
+}
+
+func viewer(c *gin.Context) Viewer {
+	userID, hr, leads := middleware.LineManagement(c)
+	return Viewer{UserID: userID, HR: hr, Finance: slices.Contains(middleware.RolesFromContext(c), financeRole), Leads: leads}
+}
+
+func parseStatus(c *gin.Context) (Status, bool) {
+	status := Status(c.Query("status"))
+	switch status {
+	case "", StatusDraft, StatusSubmitted, StatusApproved, StatusRejected, StatusReimbursed:
+		return status, true
+	}
+	utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	return "", false
+}
+
+// sendExpenseError maps service errors to HTTP status codes.
+func sendExpenseError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee), errors.Is(err, ErrNoReceipt):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidExpense):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotReport):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrClaimStatus), errors.Is(err, ErrOverLimit):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrReceiptTooLarge):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrReceiptType):
+		utils.SendErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	case errors.Is(err, lock.ErrLocked):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
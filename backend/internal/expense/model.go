@@ -0,0 +1,152 @@
+// prometheus/backend/internal/expense/model.go
+package expense
+
+import (
+	"io"
+	"time"
+)
+
+// Status is where a claim is.
+type Status string
+
+const (
+	StatusDraft      Status = "draft"      // The employee adds items and receipts
+	StatusSubmitted  Status = "submitted"  // Waiting for the employee's manager
+	StatusApproved   Status = "approved"   // Waiting for finance to pay it out
+	StatusRejected   Status = "rejected"   // The employee may fix and resubmit it
+	StatusReimbursed Status = "reimbursed" // Final
+)
+
+// Category is a kind of expense, optionally with limits. Limits are in the category's currency and only
+// allow claims in it. Amounts throughout the package are in minor units of their currency (cents).
+type Category struct {
+	ID              uint      `gorm:"primaryKey" json:"id" example:"3"`
+	OrganizationID  *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name            string    `gorm:"type:varchar(100);not null" json:"name" example:"Meals"`
+	Currency        string    `gorm:"type:char(3)" json:"currency,omitempty" example:"EUR"` // Of the limits; set when either is
+	ItemLimit       *int64    `json:"item_limit,omitempty" example:"5000"`                  // Per expense
+	MonthlyLimit    *int64    `json:"monthly_limit,omitempty" example:"30000"`              // Per employee and calendar month spent
+	ReceiptRequired bool      `gorm:"not null" json:"receipt_required"`
+	Archived        bool      `gorm:"not null" json:"archived"`                      // Kept for past claims; no new expenses
+	Version         uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName keeps categories with the rest of the expense tables.
+func (Category) TableName() string { return "expense_categories" }
+
+// Claim is an employee's request to be reimbursed for one or more expenses in one currency.
+type Claim struct {
+	ID               uint       `gorm:"primaryKey" json:"id" example:"52"`
+	OrganizationID   *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID       uint       `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName      string     `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Title            string     `gorm:"type:varchar(150);not null" json:"title" example:"Client visit, Lyon"`
+	Currency         string     `gorm:"type:char(3);not null" json:"currency" example:"EUR"`
+	Total            int64      `gorm:"not null" json:"total" example:"18450"` // Of the items
+	Status           Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"submitted"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	DecidedBy        *uint      `json:"decided_by,omitempty" example:"3"` // User ID
+	DecidedAt        *time.Time `json:"decided_at,omitempty"`
+	DecisionNote     string     `gorm:"type:varchar(1000)" json:"decision_note,omitempty"`
+	ReimbursedBy     *uint      `json:"reimbursed_by,omitempty" example:"9"` // User ID
+	ReimbursedAt     *time.Time `json:"reimbursed_at,omitempty"`
+	PaymentReference string     `gorm:"type:varchar(100)" json:"payment_reference,omitempty" example:"SEPA-2026-10-0412"`
+	Items            []Item     `gorm:"foreignKey:ClaimID" json:"items,omitempty"`
+	Version          uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName keeps claims with the rest of the expense tables.
+func (Claim) TableName() string { return "expense_claims" }
+
+// Item is one expense of a claim, with its receipt once uploaded.
+type Item struct {
+	ID          uint      `gorm:"primaryKey" json:"id" example:"140"`
+	ClaimID     uint      `gorm:"not null;index" json:"claim_id" example:"52"`
+	CategoryID  uint      `gorm:"not null;index" json:"category_id" example:"3"`
+	Category    string    `gorm:"-" json:"category,omitempty" example:"Meals"`
+	SpentOn     time.Time `gorm:"type:date;not null" json:"spent_on" example:"2026-10-06T00:00:00Z"`
+	Amount      int64     `gorm:"not null" json:"amount" example:"4250"`
+	Description string    `gorm:"type:varchar(500)" json:"description,omitempty" example:"Lunch with the client"`
+	ReceiptKey  string    `gorm:"type:varchar(255)" json:"-"`
+	ReceiptName string    `gorm:"type:varchar(255)" json:"receipt_name,omitempty" example:"receipt.jpg"` // As uploaded
+	ReceiptSize int64     `gorm:"not null" json:"receipt_size,omitempty" example:"184320"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName keeps items with the rest of the expense tables.
+func (Item) TableName() string { return "expense_items" }
+
+// Viewer is who looks at claims: HR and finance see everyone's, managers their reports', everyone their own.
+type Viewer struct {
+	UserID  uint
+	HR      bool   // Holds an HR role globally
+	Finance bool   // Holds the finance role globally
+	Leads   []uint // Divisions led through a division-scoped manager role; headed divisions are added by the service
+}
+
+// Receipt is an item's receipt: either a signed URL to redirect to, or the content to serve.
+type Receipt struct {
+	URL         string
+	Body        io.ReadCloser
+	ContentType string
+	Name        string
+}
+
+// CategoryRequest creates a category or replaces its fields.
+type CategoryRequest struct {
+	Name            string `json:"name" binding:"required,max=100" example:"Meals"`
+	Currency        string `json:"currency,omitempty" binding:"omitempty,len=3" example:"EUR"`
+	ItemLimit       *int64 `json:"item_limit,omitempty" binding:"omitempty,min=1" example:"5000"`
+	MonthlyLimit    *int64 `json:"monthly_limit,omitempty" binding:"omitempty,min=1" example:"30000"`
+	ReceiptRequired bool   `json:"receipt_required"`
+	Archived        bool   `json:"archived"`
+}
+
+// ClaimRequest creates a claim or replaces its title and currency while it can be edited.
+type ClaimRequest struct {
+	Title    string `json:"title" binding:"required,max=150" example:"Client visit, Lyon"`
+	Currency string `json:"currency" binding:"required,len=3" example:"EUR"`
+}
+
+// ItemRequest adds an expense to a claim.
+type ItemRequest struct {
+	CategoryID  uint   `json:"category_id" binding:"required" example:"3"`
+	SpentOn     string `json:"spent_on" binding:"required,datetime=2006-01-02" example:"2026-10-06"`
+	Amount      int64  `json:"amount" binding:"required,min=1" example:"4250"`
+	Description string `json:"description,omitempty" binding:"max=500" example:"Lunch with the client"`
+}
+
+// DecisionRequest approves or rejects a submitted claim.
+type DecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty" binding:"max=1000" example:"Please split the hotel and the meals"`
+}
+
+// ReimburseRequest records that an approved claim was paid out.
+type ReimburseRequest struct {
+	PaymentReference string `json:"payment_reference,omitempty" binding:"max=100" example:"SEPA-2026-10-0412"`
+}
+
+// Filter narrows a claim listing.
+type Filter struct {
+	Status     Status
+	EmployeeID *uint
+	DivisionID *uint
+}
+
+// Summary adds up a month's expenses in one category and currency, by the status of their claims.
+// Drafts and rejected claims are left out.
+type Summary struct {
+	Month      string `json:"month" example:"2026-10"` // Of the expenses, not the claims
+	CategoryID uint   `json:"category_id" example:"3"`
+	Category   string `json:"category" example:"Meals"`
+	Currency   string `json:"currency" example:"EUR"`
+	Items      int    `json:"items" example:"41"`
+	Submitted  int64  `json:"submitted" example:"12500"`
+	Approved   int64  `json:"approved" example:"30400"`
+	Reimbursed int64  `json:"reimbursed" example:"88210"`
+}
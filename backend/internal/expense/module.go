@@ -0,0 +1,71 @@
+// prometheus/backend/internal/expense/module.go
+package expense
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the expenses module.
+const ModuleName = "expenses"
+
+// expenseModule owns expense claims with their receipts, and the categories they are limited by.
+type expenseModule struct {
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the expenses module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &expenseModule{service: svc, handler: NewHandler(svc)}
+}
+
+func (m *expenseModule) Name() string { return ModuleName }
+
+func (m *expenseModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *expenseModule) Models() []any {
+	return []any{&Category{}, &Claim{}, &Item{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Employees claim under /me, managers decide their
+// reports' claims, finance reimburses approved ones and HR maintains the categories.
+func (m *expenseModule) RegisterRoutes(api *routing.Group) {
+	expensesAPI := api.InModule(plan.ModuleExpenses)
+	expensesAPI.GET("/me/expense-categories", routing.Authenticated(), m.handler.ListCategories)
+	expensesAPI.GET("/me/expenses", routing.Authenticated(), m.handler.MyClaims)
+	expensesAPI.POST("/me/expenses", routing.Authenticated(), m.handler.CreateClaim)
+	expensesAPI.GET("/me/expenses/:id", routing.Authenticated(), m.handler.GetClaim)
+	expensesAPI.PUT("/me/expenses/:id", routing.Authenticated(), m.handler.UpdateClaim)
+	expensesAPI.DELETE("/me/expenses/:id", routing.Authenticated(), m.handler.DeleteClaim)
+	expensesAPI.POST("/me/expenses/:id/items", routing.Authenticated(), m.handler.AddItem)
+	expensesAPI.DELETE("/me/expenses/:id/items/:item_id", routing.Authenticated(), m.handler.DeleteItem)
+	expensesAPI.POST("/me/expenses/:id/items/:item_id/receipt", routing.Authenticated(), m.handler.UploadReceipt)
+	expensesAPI.GET("/me/expenses/:id/items/:item_id/receipt", routing.Authenticated(), m.handler.GetReceipt)
+	expensesAPI.POST("/me/expenses/:id/submit", routing.Authenticated(), m.handler.Submit)
+
+	expensesAPI.GET("/manager/expenses", routing.Policy(), m.handler.ListClaims)
+	expensesAPI.GET("/manager/expenses/:id", routing.Policy(), m.handler.GetClaim)
+	expensesAPI.GET("/manager/expenses/:id/items/:item_id/receipt", routing.Policy(), m.handler.GetReceipt)
+	expensesAPI.POST("/manager/expenses/:id/decision", routing.Policy(), m.handler.Decide)
+
+	expensesAPI.GET("/finance/expenses", routing.Policy(), m.handler.ListClaims)
+	expensesAPI.GET("/finance/expenses/summary", routing.Policy(), m.handler.Summaries)
+	expensesAPI.GET("/finance/expenses/:id", routing.Policy(), m.handler.GetClaim)
+	expensesAPI.GET("/finance/expenses/:id/items/:item_id/receipt", routing.Policy(), m.handler.GetReceipt)
+	expensesAPI.POST("/finance/expenses/:id/reimburse", routing.Policy(), m.handler.Reimburse)
+
+	expensesAPI.GET("/hr/expense-categories", routing.Policy(), m.handler.ListCategories)
+	expensesAPI.POST("/hr/expense-categories", routing.Policy(), m.handler.CreateCategory)
+	expensesAPI.PUT("/hr/expense-categories/:id", routing.Policy(), m.handler.UpdateCategory)
+	expensesAPI.GET("/hr/expenses/summary", routing.Policy(), m.handler.Summaries)
+}
+
+// PrivacySources implements privacy.Contributor: claims are exported with their receipts, and the receipts
+// and the claims' free text deleted on anonymization.
+func (m *expenseModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{m.service.PrivacySource()}
+}
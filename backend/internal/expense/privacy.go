@@ -0,0 +1,68 @@
+// prometheus/backend/internal/expense/privacy.go
+package expense
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/privacy"
+
+	"gorm.io/gorm"
+)
+
+// PrivacySource exports the user's claims with their items and receipts. On anonymization the receipts
+// are deleted, giving back their storage, and the claims' free text is erased; the claims and amounts are
+// kept for the books, as Summaries adds them up.
+func (s *service) PrivacySource() privacy.FileSource {
+	return privacy.NewFileSource("expenses", s.exportPersonal, s.anonymizePersonal, s.personalFiles)
+}
+
+func (s *service) exportPersonal(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var claims []Claim
+	if err := db.WithContext(ctx).Where("employee_id IN (?)", employee.OfUser(db, userID)).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Order("id").Find(&claims).Error; err != nil {
+		return nil, fmt.Errorf("failed to export expense claims: %w", err)
+	}
+	return claims, nil
+}
+
+func (s *service) personalFiles(ctx context.Context, db *gorm.DB, userID uint) ([]privacy.File, error) {
+	var items []Item
+	if err := db.WithContext(ctx).Where("claim_id IN (?) AND receipt_key <> ''", personalClaims(db, userID)).
+		Order("claim_id, id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	files := make([]privacy.File, 0, len(items))
+	for _, item := range items {
+		files = append(files, privacy.File{Name: fmt.Sprintf("%d/%d-%s", item.ClaimID, item.ID, item.ReceiptName), Key: item.ReceiptKey})
+	}
+	return files, nil
+}
+
+func (s *service) anonymizePersonal(ctx context.Context, tx *gorm.DB, userID uint) error {
+	tx = tx.WithContext(ctx)
+	var claims []Claim
+	if err := tx.Where("employee_id IN (?)", employee.OfUser(tx, userID)).Preload("Items").Find(&claims).Error; err != nil {
+		return fmt.Errorf("failed to load expense claims: %w", err)
+	}
+	for _, claim := range claims {
+		if err := s.release(tx, claim.OrganizationID, claim.Items...); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&Item{}).Where("claim_id IN (?)", personalClaims(tx, userID)).Updates(map[string]interface{}{
+		"description": "", "receipt_key": "", "receipt_name": "", "receipt_size": 0,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to erase expense items: %w", err)
+	}
+	if err := tx.Model(&Claim{}).Where("employee_id IN (?)", employee.OfUser(tx, userID)).
+		Updates(map[string]interface{}{"title": "", "decision_note": ""}).Error; err != nil {
+		return fmt.Errorf("failed to erase expense claims: %w", err)
+	}
+	return nil
+}
+
+// personalClaims selects the IDs of the user's claims.
+func personalClaims(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Table("expense_claims").Select("id").Where("employee_id IN (?)", employee.OfUser(db, userID))
+}
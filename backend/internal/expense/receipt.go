@@ -0,0 +1,191 @@
+// prometheus/backend/internal/expense/receipt.go
+package expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxReceiptSize is the largest receipt upload accepted, in bytes.
+const MaxReceiptSize = 10 << 20
+
+// receiptURLTTL is how long a signed receipt URL stays valid.
+const receiptURLTTL = 5 * time.Minute
+
+// receiptTypes maps the accepted receipt types, as sniffed from the content, to file extensions.
+var receiptTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// ErrReceiptTooLarge is returned for uploads above MaxReceiptSize.
+var ErrReceiptTooLarge = fmt.Errorf("receipt must not exceed %d MB", MaxReceiptSize>>20)
+
+// ErrReceiptType is returned for uploads that aren't images or PDFs.
+var ErrReceiptType = errors.New("receipt must be a PNG, JPEG, GIF or WebP image, or a PDF")
+
+// ErrNoReceipt is returned when an item has no receipt.
+var ErrNoReceipt = errors.New("the expense has no receipt")
+
+// UploadReceipt sniffs the type from the content, ignoring the client's declared type, and counts the
+// receipt against the organization's storage quota.
+func (s *service) UploadReceipt(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, claimID, itemID uint, r io.ReadSeeker, size int64, name string) (*Item, error) {
+	if size > MaxReceiptSize {
+		return nil, ErrReceiptTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrReceiptType
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := receiptTypes[contentType]
+	if !ok {
+		return nil, ErrReceiptType
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read receipt: %w", err)
+	}
+	if _, err := s.own(orgID, employeeID, claimID); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("receipts/%s/%d/%s%s", orgKey(orgID), claimID, uuid.NewString(), ext)
+	if err := s.files.Put(ctx, key, io.LimitReader(r, MaxReceiptSize), size, contentType); err != nil {
+		return nil, err
+	}
+	var before, item Item
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claim, err := lockOwn(tx, orgID, employeeID, claimID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(editable, claim.Status) {
+			return ErrClaimStatus
+		}
+		if err := tx.Where("claim_id = ?", claimID).First(&before, itemID).Error; err != nil {
+			return err
+		}
+		if err := s.release(tx, orgID, before); err != nil {
+			return err
+		}
+		if err := s.quota.ReserveStorage(tx, orgValue(orgID), size); err != nil {
+			return err
+		}
+		item = before
+		item.ReceiptKey, item.ReceiptName, item.ReceiptSize = key, receiptName(name, ext), size
+		if err := tx.Model(&Item{}).Where("id = ?", itemID).Updates(map[string]interface{}{
+			"receipt_key": item.ReceiptKey, "receipt_name": item.ReceiptName, "receipt_size": item.ReceiptSize,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save receipt: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.receipt.upload", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", claimID),
+			After: map[string]interface{}{"item_id": itemID, "content_type": contentType, "size": size},
+		})
+	})
+	if err != nil {
+		s.remove(key)
+		return nil, err
+	}
+	if before.ReceiptKey != "" {
+		s.remove(before.ReceiptKey)
+	}
+	return &item, nil
+}
+
+// Receipt prefers a signed URL so the storage serves the file; backends without them stream it through the API.
+func (s *service) Receipt(ctx context.Context, orgID *uint, viewer Viewer, claimID, itemID uint) (*Receipt, error) {
+	var claim Claim
+	if err := utils.OrgScope(s.db.WithContext(ctx), orgID).First(&claim, claimID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(s.db.WithContext(ctx), orgID, viewer, claim); err != nil {
+		return nil, err
+	}
+	var item Item
+	if err := s.db.WithContext(ctx).Where("claim_id = ?", claimID).First(&item, itemID).Error; err != nil {
+		return nil, err
+	}
+	if item.ReceiptKey == "" {
+		return nil, ErrNoReceipt
+	}
+
+	url, err := s.files.SignedURL(ctx, item.ReceiptKey, receiptURLTTL)
+	if err == nil {
+		return &Receipt{URL: url, Name: item.ReceiptName}, nil
+	}
+	if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+		return nil, err
+	}
+	body, contentType, err := s.files.Open(ctx, item.ReceiptKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNoReceipt
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{Body: body, ContentType: contentType, Name: item.ReceiptName}, nil
+}
+
+// release gives back the storage of items' receipts. The files themselves are removed once the
+// transaction has committed.
+func (s *service) release(tx *gorm.DB, orgID *uint, items ...Item) error {
+	var bytes int64
+	for _, item := range items {
+		if item.ReceiptKey != "" {
+			bytes += item.ReceiptSize
+		}
+	}
+	if err := s.quota.ReleaseStorage(tx, orgValue(orgID), bytes); err != nil {
+		return fmt.Errorf("failed to release receipt storage: %w", err)
+	}
+	return nil
+}
+
+// remove deletes a receipt that is no longer referenced. Failures only leave an orphaned file behind.
+func (s *service) remove(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.files.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to delete receipt %s: %v", key, err)
+	}
+}
+
+// receiptName keeps the base of the uploaded file's name for display, falling back to "receipt".
+func receiptName(name, ext string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "receipt" + ext
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+	return name
+}
+
+// orgValue returns the organization as the storage quota names it, 0 for none.
+func orgValue(orgID *uint) uint {
+	if orgID == nil {
+		return 0
+	}
+	return *orgID
+}
@@ -0,0 +1,782 @@
+// prometheus/backend/internal/expense/service.go
+package expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidExpense is returned for categories, claims and items that fail validation.
+	ErrInvalidExpense = errors.New("invalid expense")
+	// ErrOverLimit is returned when submitting a claim that would exceed a category limit.
+	ErrOverLimit = errors.New("over the category's limit")
+	// ErrClaimStatus is returned for changes the claim's status doesn't allow: employees edit and submit
+	// drafts and rejected claims, managers decide submitted ones and finance reimburses approved ones.
+	ErrClaimStatus = errors.New("the claim's status does not allow this change")
+	// ErrNotReport is returned when deciding a claim of someone who isn't the decider's report.
+	ErrNotReport = errors.New("you may only decide your reports' claims")
+)
+
+// editable are the statuses in which the employee may change a claim.
+var editable = []Status{StatusDraft, StatusRejected}
+
+// Quota accounts for receipts against the organization's storage quota. plan.Service implements it.
+type Quota interface {
+	ReserveStorage(tx *gorm.DB, orgID uint, bytes int64) error
+	ReleaseStorage(tx *gorm.DB, orgID uint, bytes int64) error
+}
+
+// Service manages expense categories and claims: employees claim with receipts, managers approve and
+// finance reimburses. orgID scopes every call to one organization (nil = platform users, outside any
+// organization).
+type Service interface {
+	Categories(orgID *uint, includeArchived bool) ([]Category, error)
+	GetCategory(orgID *uint, id uint) (*Category, error)
+	CreateCategory(actor audit.Actor, orgID *uint, req CategoryRequest) (*Category, error)
+	// UpdateCategory replaces the category's fields; new limits apply to claims submitted from then on.
+	UpdateCategory(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CategoryRequest) (*Category, error)
+
+	// MyClaims lists the employee's claims, latest first, without their items.
+	MyClaims(orgID *uint, employeeID uint, status Status) ([]Claim, error)
+	// Claims lists the claims viewer may see other than their own, latest first, without their items.
+	Claims(orgID *uint, viewer Viewer, filter Filter) ([]Claim, error)
+	// GetClaim returns a claim with its items, or gorm.ErrRecordNotFound if viewer may not see it.
+	GetClaim(orgID *uint, viewer Viewer, id uint) (*Claim, error)
+	CreateClaim(actor audit.Actor, orgID *uint, employeeID uint, req ClaimRequest) (*Claim, error)
+	UpdateClaim(actor audit.Actor, orgID *uint, employeeID, id, expectedVersion uint, req ClaimRequest) (*Claim, error)
+	// DeleteClaim deletes a draft claim with its receipts.
+	DeleteClaim(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, id uint) error
+	AddItem(actor audit.Actor, orgID *uint, employeeID, claimID uint, req ItemRequest) (*Claim, error)
+	DeleteItem(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, claimID, itemID uint) (*Claim, error)
+	// UploadReceipt stores the receipt of an item, replacing any earlier one.
+	UploadReceipt(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, claimID, itemID uint, r io.ReadSeeker, size int64, name string) (*Item, error)
+	// Receipt returns an item's receipt to whoever may see its claim.
+	Receipt(ctx context.Context, orgID *uint, viewer Viewer, claimID, itemID uint) (*Receipt, error)
+
+	// Submit sends a draft or rejected claim to the employee's manager, checking receipts and category limits.
+	Submit(actor audit.Actor, orgID *uint, employeeID, id uint) (*Claim, error)
+	// Decide approves or rejects a submitted claim of one of viewer's reports.
+	Decide(actor audit.Actor, orgID *uint, viewer Viewer, id, expectedVersion uint, req DecisionRequest) (*Claim, error)
+	// Reimburse records that an approved claim was paid out.
+	Reimburse(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ReimburseRequest) (*Claim, error)
+
+	// Summaries adds expenses up per month, category and currency, for the months from from to to.
+	Summaries(orgID *uint, from, to time.Time) ([]Summary, error)
+	employee.Resolver
+	// PrivacySource is the personal data kept in claims and receipts, see privacy.FileSource.
+	PrivacySource() privacy.FileSource
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	files     storage.Storage
+	quota     Quota
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Receipts are kept in files and counted against the
+// organization's storage quota.
+func NewService(db *gorm.DB, employees employee.Service, files storage.Storage, quota Quota, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, files: files, quota: quota, auditor: auditor}
+}
+
+func (s *service) Categories(orgID *uint, includeArchived bool) ([]Category, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if !includeArchived {
+		query = query.Where("NOT archived")
+	}
+	categories := []Category{}
+	if err := query.Order("LOWER(name), id").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to list expense categories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *service) GetCategory(orgID *uint, id uint) (*Category, error) {
+	var category Category
+	if err := utils.OrgScope(s.db, orgID).First(&category, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &category, nil
+}
+
+func (s *service) CreateCategory(actor audit.Actor, orgID *uint, req CategoryRequest) (*Category, error) {
+	category := Category{OrganizationID: orgID}
+	if err := applyCategory(&category, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("failed to create expense category: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_category.create", EntityType: "expense_category", EntityID: fmt.Sprintf("%d", category.ID), After: category,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *service) UpdateCategory(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CategoryRequest) (*Category, error) {
+	var updated Category
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Category
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		category := before
+		if err := applyCategory(&category, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Category{}, id, expectedVersion, map[string]interface{}{
+			"name": category.Name, "currency": category.Currency, "item_limit": category.ItemLimit,
+			"monthly_limit": category.MonthlyLimit, "receipt_required": category.ReceiptRequired, "archived": category.Archived,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload expense category %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_category.update", EntityType: "expense_category", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) MyClaims(orgID *uint, employeeID uint, status Status) ([]Claim, error) {
+	query := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	claims := []Claim{}
+	if err := query.Order("created_at DESC, id DESC").Find(&claims).Error; err != nil {
+		return nil, fmt.Errorf("failed to list expense claims: %w", err)
+	}
+	return claims, nil
+}
+
+func (s *service) Claims(orgID *uint, viewer Viewer, filter Filter) ([]Claim, error) {
+	employees := utils.OrgScope(s.db.Table("employees").Select("id").Where("deleted_at IS NULL AND user_id <> ?", viewer.UserID), orgID)
+	if !viewer.HR && !viewer.Finance {
+		self, leads, err := s.leads(s.db, orgID, viewer)
+		if err != nil {
+			return nil, err
+		}
+		employees = employees.Where("(manager_id = ? OR division_id IN ?)", self, append(leads, 0))
+	}
+	if filter.DivisionID != nil {
+		employees = employees.Where("division_id = ?", *filter.DivisionID)
+	}
+	query := utils.OrgScope(s.db, orgID).Where("employee_id IN (?)", employees)
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	} else {
+		query = query.Where("status <> ?", StatusDraft)
+	}
+	claims := []Claim{}
+	if err := query.Order("COALESCE(submitted_at, created_at) DESC, id DESC").Find(&claims).Error; err != nil {
+		return nil, fmt.Errorf("failed to list expense claims: %w", err)
+	}
+	if err := s.named(orgID, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (s *service) GetClaim(orgID *uint, viewer Viewer, id uint) (*Claim, error) {
+	var claim Claim
+	if err := utils.OrgScope(s.db, orgID).First(&claim, id).Error; err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(s.db, orgID, viewer, claim); err != nil {
+		return nil, err
+	}
+	return s.detailed(orgID, claim)
+}
+
+func (s *service) CreateClaim(actor audit.Actor, orgID *uint, employeeID uint, req ClaimRequest) (*Claim, error) {
+	claim := Claim{OrganizationID: orgID, EmployeeID: employeeID, Status: StatusDraft}
+	if err := applyClaim(&claim, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&claim).Error; err != nil {
+			return fmt.Errorf("failed to create expense claim: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.create", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", claim.ID), After: claim,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	claim.Items = []Item{}
+	return &claim, nil
+}
+
+// UpdateClaim replaces the claim's title and currency if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdateClaim(actor audit.Actor, orgID *uint, employeeID, id, expectedVersion uint, req ClaimRequest) (*Claim, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockOwn(tx, orgID, employeeID, id)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(editable, before.Status) {
+			return ErrClaimStatus
+		}
+		claim := *before
+		if err := applyClaim(&claim, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Claim{}, id, expectedVersion, map[string]interface{}{
+			"title": claim.Title, "currency": claim.Currency,
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.update", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", id), Before: before, After: claim,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.own(orgID, employeeID, id)
+}
+
+func (s *service) DeleteClaim(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, id uint) error {
+	var items []Item
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockOwn(tx, orgID, employeeID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusDraft {
+			return ErrClaimStatus
+		}
+		if err := tx.Where("claim_id = ?", id).Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to load expense items: %w", err)
+		}
+		if err := s.release(tx, orgID, items...); err != nil {
+			return err
+		}
+		if err := tx.Where("claim_id = ?", id).Delete(&Item{}).Error; err != nil {
+			return fmt.Errorf("failed to delete expense items: %w", err)
+		}
+		if err := tx.Delete(&Claim{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete expense claim %d: %w", id, err)
+		}
+		before.Items = items
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.delete", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		s.remove(item.ReceiptKey)
+	}
+	return nil
+}
+
+func (s *service) AddItem(actor audit.Actor, orgID *uint, employeeID, claimID uint, req ItemRequest) (*Claim, error) {
+	spentOn, err := time.Parse("2006-01-02", req.SpentOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidExpense)
+	}
+	if spentOn.After(clock.Now().UTC()) {
+		return nil, fmt.Errorf("%w: expenses can't be in the future", ErrInvalidExpense)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		claim, err := lockOwn(tx, orgID, employeeID, claimID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(editable, claim.Status) {
+			return ErrClaimStatus
+		}
+		var category Category
+		if err := utils.OrgScope(tx, orgID).Where("NOT archived").First(&category, req.CategoryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: unknown expense category", ErrInvalidExpense)
+			}
+			return err
+		}
+		item := Item{
+			ClaimID: claimID, CategoryID: category.ID, SpentOn: spentOn, Amount: req.Amount,
+			Description: strings.TrimSpace(req.Description),
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return fmt.Errorf("failed to add expense item: %w", err)
+		}
+		if err := retotal(tx, claimID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.item.add", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", claimID), After: item,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.own(orgID, employeeID, claimID)
+}
+
+func (s *service) DeleteItem(ctx context.Context, actor audit.Actor, orgID *uint, employeeID, claimID, itemID uint) (*Claim, error) {
+	var item Item
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		claim, err := lockOwn(tx, orgID, employeeID, claimID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(editable, claim.Status) {
+			return ErrClaimStatus
+		}
+		if err := tx.Where("claim_id = ?", claimID).First(&item, itemID).Error; err != nil {
+			return err
+		}
+		if err := s.release(tx, orgID, item); err != nil {
+			return err
+		}
+		if err := tx.Delete(&Item{}, itemID).Error; err != nil {
+			return fmt.Errorf("failed to delete expense item %d: %w", itemID, err)
+		}
+		if err := retotal(tx, claimID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.item.delete", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", claimID), Before: item,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.remove(item.ReceiptKey)
+	return s.own(orgID, employeeID, claimID)
+}
+
+func (s *service) Submit(actor audit.Actor, orgID *uint, employeeID, id uint) (*Claim, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Serializes the employee's submissions, so two claims can't both fit under a monthly limit.
+		if err := lock.Tx(tx, fmt.Sprintf("expense:%d", employeeID)); err != nil {
+			return err
+		}
+		claim, err := lockOwn(tx, orgID, employeeID, id)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(editable, claim.Status) {
+			return ErrClaimStatus
+		}
+		var items []Item
+		if err := tx.Where("claim_id = ?", id).Order("spent_on, id").Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to load expense items: %w", err)
+		}
+		if len(items) == 0 {
+			return fmt.Errorf("%w: the claim has no expenses", ErrInvalidExpense)
+		}
+		if err := checkLimits(tx, orgID, *claim, items); err != nil {
+			return err
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Claim{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": StatusSubmitted, "submitted_at": now, "decided_by": nil, "decided_at": nil, "decision_note": "",
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to submit expense claim %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.submit", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": claim.Status}, After: map[string]interface{}{"status": StatusSubmitted, "total": claim.Total},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.own(orgID, employeeID, id)
+}
+
+func (s *service) Decide(actor audit.Actor, orgID *uint, viewer Viewer, id, expectedVersion uint, req DecisionRequest) (*Claim, error) {
+	status := StatusRejected
+	if req.Approve {
+		status = StatusApproved
+	} else if strings.TrimSpace(req.Note) == "" {
+		return nil, fmt.Errorf("%w: say why the claim is rejected", ErrInvalidExpense)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var claim Claim
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&claim, id).Error; err != nil {
+			return err
+		}
+		if err := s.checkDecider(tx, orgID, viewer, claim.EmployeeID); err != nil {
+			return err
+		}
+		if claim.Status != StatusSubmitted {
+			return ErrClaimStatus
+		}
+		now := clock.Now().UTC()
+		if err := utils.UpdateWithVersion(tx, &Claim{}, id, expectedVersion, map[string]interface{}{
+			"status": status, "decided_by": actor.UserID, "decided_at": now, "decision_note": strings.TrimSpace(req.Note),
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.decide", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": claim.Status}, After: map[string]interface{}{"status": status, "note": req.Note},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetClaim(orgID, viewer, id)
+}
+
+func (s *service) Reimburse(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ReimburseRequest) (*Claim, error) {
+	var claim Claim
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&claim, id).Error; err != nil {
+			return err
+		}
+		if claim.Status != StatusApproved {
+			return ErrClaimStatus
+		}
+		now := clock.Now().UTC()
+		if err := utils.UpdateWithVersion(tx, &Claim{}, id, expectedVersion, map[string]interface{}{
+			"status": StatusReimbursed, "reimbursed_by": actor.UserID, "reimbursed_at": now,
+			"payment_reference": strings.TrimSpace(req.PaymentReference),
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "expense_claim.reimburse", EntityType: "expense_claim", EntityID: fmt.Sprintf("%d", id),
+			After: map[string]interface{}{"total": claim.Total, "currency": claim.Currency, "payment_reference": req.PaymentReference},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&claim, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload expense claim %d: %w", id, err)
+	}
+	return s.detailed(orgID, claim)
+}
+
+func (s *service) Summaries(orgID *uint, from, to time.Time) ([]Summary, error) {
+	var rows []struct {
+		Month      string
+		CategoryID uint
+		Currency   string
+		Items      int
+		Submitted  int64
+		Approved   int64
+		Reimbursed int64
+	}
+	if err := utils.OrgScope(s.db.Table("expense_items").Joins("JOIN expense_claims ON expense_claims.id = expense_items.claim_id"), orgID).
+		Select(`TO_CHAR(expense_items.spent_on, 'YYYY-MM') AS month, expense_items.category_id, expense_claims.currency,
+			COUNT(*) AS items,
+			SUM(CASE WHEN expense_claims.status = ? THEN expense_items.amount ELSE 0 END) AS submitted,
+			SUM(CASE WHEN expense_claims.status = ? THEN expense_items.amount ELSE 0 END) AS approved,
+			SUM(CASE WHEN expense_claims.status = ? THEN expense_items.amount ELSE 0 END) AS reimbursed`,
+			StatusSubmitted, StatusApproved, StatusReimbursed).
+		Where("expense_claims.status IN ? AND expense_items.spent_on BETWEEN ? AND ?",
+			[]Status{StatusSubmitted, StatusApproved, StatusReimbursed}, from, to).
+		Group("month, expense_items.category_id, expense_claims.currency").
+		Order("month, expense_items.category_id, expense_claims.currency").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum up expenses: %w", err)
+	}
+	names, err := s.categoryNames(orgID)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, len(rows))
+	for i, r := range rows {
+		summaries[i] = Summary{
+			Month: r.Month, CategoryID: r.CategoryID, Category: names[r.CategoryID], Currency: r.Currency,
+			Items: r.Items, Submitted: r.Submitted, Approved: r.Approved, Reimbursed: r.Reimbursed,
+		}
+	}
+	return summaries, nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+// checkLimits checks a claim's items against their categories: receipts where required, the currency
+// and the limits. Monthly limits count the employee's other claims awaiting or past approval.
+func checkLimits(tx *gorm.DB, orgID *uint, claim Claim, items []Item) error {
+	var categories []Category
+	if err := utils.OrgScope(tx, orgID).Find(&categories).Error; err != nil {
+		return fmt.Errorf("failed to load expense categories: %w", err)
+	}
+	byID := make(map[uint]Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	type month struct {
+		categoryID uint
+		month      string
+	}
+	spent := make(map[month]int64)
+	for _, item := range items {
+		category := byID[item.CategoryID]
+		switch {
+		case category.Archived:
+			return fmt.Errorf("%w: the category %s is archived", ErrInvalidExpense, category.Name)
+		case category.ReceiptRequired && item.ReceiptKey == "":
+			return fmt.Errorf("%w: %s expenses need a receipt (%s)", ErrInvalidExpense, category.Name, item.SpentOn.Format("2006-01-02"))
+		case (category.ItemLimit != nil || category.MonthlyLimit != nil) && category.Currency != claim.Currency:
+			return fmt.Errorf("%w: %s expenses are claimed in %s", ErrInvalidExpense, category.Name, category.Currency)
+		case category.ItemLimit != nil && item.Amount > *category.ItemLimit:
+			return fmt.Errorf("%w: %s expenses are limited to %d per expense", ErrOverLimit, category.Name, *category.ItemLimit)
+		}
+		spent[month{item.CategoryID, item.SpentOn.Format("2006-01")}] += item.Amount
+	}
+	for m, amount := range spent {
+		category := byID[m.categoryID]
+		if category.MonthlyLimit == nil {
+			continue
+		}
+		start, _ := time.Parse("2006-01", m.month)
+		var earlier int64
+		if err := tx.Table("expense_items").Joins("JOIN expense_claims ON expense_claims.id = expense_items.claim_id").
+			Where("expense_claims.employee_id = ? AND expense_claims.id <> ? AND expense_claims.status IN ?",
+				claim.EmployeeID, claim.ID, []Status{StatusSubmitted, StatusApproved, StatusReimbursed}).
+			Where("expense_items.category_id = ? AND expense_items.spent_on >= ? AND expense_items.spent_on < ?",
+				m.categoryID, start, start.AddDate(0, 1, 0)).
+			Select("COALESCE(SUM(expense_items.amount), 0)").Scan(&earlier).Error; err != nil {
+			return fmt.Errorf("failed to check monthly limits: %w", err)
+		}
+		if earlier+amount > *category.MonthlyLimit {
+			return fmt.Errorf("%w: %s expenses in %s would come to %d of a monthly %d", ErrOverLimit,
+				category.Name, m.month, earlier+amount, *category.MonthlyLimit)
+		}
+	}
+	return nil
+}
+
+// checkVisible returns gorm.ErrRecordNotFound unless viewer may see the claim: their own, their reports'
+// once submitted, or anyone's for HR and finance.
+func (s *service) checkVisible(tx *gorm.DB, orgID *uint, viewer Viewer, claim Claim) error {
+	var userID uint
+	if err := tx.Table("employees").Where("id = ?", claim.EmployeeID).Select("user_id").Scan(&userID).Error; err != nil {
+		return fmt.Errorf("failed to load the claim's employee: %w", err)
+	}
+	if userID == viewer.UserID {
+		return nil
+	}
+	if claim.Status == StatusDraft {
+		return gorm.ErrRecordNotFound
+	}
+	if viewer.HR || viewer.Finance {
+		return nil
+	}
+	if err := s.checkDecider(tx, orgID, viewer, claim.EmployeeID); err != nil {
+		if errors.Is(err, ErrNotReport) {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkDecider returns ErrNotReport unless viewer manages the employee. HR decides anyone's claims but
+// their own.
+func (s *service) checkDecider(tx *gorm.DB, orgID *uint, viewer Viewer, employeeID uint) error {
+	var r struct {
+		UserID     uint
+		ManagerID  *uint
+		DivisionID *uint
+	}
+	if err := utils.OrgScope(tx.Table("employees").Where("id = ?", employeeID), orgID).
+		Select("user_id, manager_id, division_id").Take(&r).Error; err != nil {
+		return err
+	}
+	if r.UserID == viewer.UserID {
+		return fmt.Errorf("%w, not your own", ErrNotReport)
+	}
+	if viewer.HR {
+		return nil
+	}
+	self, leads, err := s.leads(tx, orgID, viewer)
+	if err != nil {
+		return err
+	}
+	if (r.ManagerID != nil && *r.ManagerID == self) || (r.DivisionID != nil && slices.Contains(leads, *r.DivisionID)) {
+		return nil
+	}
+	return ErrNotReport
+}
+
+// leads returns the viewer's employee ID (0 if they have no employee record) and the divisions they lead:
+// through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, orgID *uint, viewer Viewer) (uint, []uint, error) {
+	var self []uint
+	if err := utils.OrgScope(tx.Table("employees").Where("user_id = ? AND deleted_at IS NULL", viewer.UserID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load the viewer's employee record: %w", err)
+	}
+	leads := slices.Clone(viewer.Leads)
+	if len(self) == 0 {
+		return 0, leads, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Where("head_id = ? AND deleted_at IS NULL", self[0]).Pluck("id", &headed).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return self[0], append(leads, headed...), nil
+}
+
+// own returns one of the employee's claims with its items.
+func (s *service) own(orgID *uint, employeeID, id uint) (*Claim, error) {
+	var claim Claim
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).First(&claim, id).Error; err != nil {
+		return nil, err
+	}
+	return s.detailed(orgID, claim)
+}
+
+// detailed loads a claim's items with their category names, and the employee's name.
+func (s *service) detailed(orgID *uint, claim Claim) (*Claim, error) {
+	claim.Items = []Item{}
+	if err := s.db.Where("claim_id = ?", claim.ID).Order("spent_on, id").Find(&claim.Items).Error; err != nil {
+		return nil, fmt.Errorf("failed to load expense items: %w", err)
+	}
+	names, err := s.categoryNames(orgID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range claim.Items {
+		claim.Items[i].Category = names[claim.Items[i].CategoryID]
+	}
+	claims := []Claim{claim}
+	if err := s.named(orgID, claims); err != nil {
+		return nil, err
+	}
+	return &claims[0], nil
+}
+
+func (s *service) categoryNames(orgID *uint) (map[uint]string, error) {
+	var categories []Category
+	if err := utils.OrgScope(s.db.Select("id, name"), orgID).Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to load expense categories: %w", err)
+	}
+	names := make(map[uint]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+// named fills in the display names of claims' employees.
+func (s *service) named(orgID *uint, claims []Claim) error {
+	ids := make([]uint, len(claims))
+	for i, c := range claims {
+		ids[i] = c.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range claims {
+		claims[i].DisplayName = names[claims[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// lockOwn loads one of the employee's claims for update, so its status can't change until the
+// transaction ends.
+func lockOwn(tx *gorm.DB, orgID *uint, employeeID, id uint) (*Claim, error) {
+	var claim Claim
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).Where("employee_id = ?", employeeID).
+		First(&claim, id).Error; err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// retotal recomputes a claim's total from its items.
+func retotal(tx *gorm.DB, claimID uint) error {
+	if err := tx.Model(&Claim{}).Where("id = ?", claimID).Updates(map[string]interface{}{
+		"total":   gorm.Expr("(SELECT COALESCE(SUM(amount), 0) FROM expense_items WHERE claim_id = ?)", claimID),
+		"version": gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update the claim's total: %w", err)
+	}
+	return nil
+}
+
+func applyCategory(category *Category, req CategoryRequest) error {
+	category.Name = strings.TrimSpace(req.Name)
+	if category.Name == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidExpense)
+	}
+	category.Currency = ""
+	if req.ItemLimit != nil || req.MonthlyLimit != nil {
+		currency, err := parseCurrency(req.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: limits need a currency", err)
+		}
+		category.Currency = currency
+	}
+	category.ItemLimit, category.MonthlyLimit = req.ItemLimit, req.MonthlyLimit
+	category.ReceiptRequired, category.Archived = req.ReceiptRequired, req.Archived
+	return nil
+}
+
+func applyClaim(claim *Claim, req ClaimRequest) error {
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+	claim.Title = strings.TrimSpace(req.Title)
+	if claim.Title == "" {
+		return fmt.Errorf("%w: a title is required", ErrInvalidExpense)
+	}
+	claim.Currency = currency
+	return nil
+}
+
+// parseCurrency normalizes an ISO 4217 code to upper case.
+func parseCurrency(raw string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(raw))
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w: currencies are three-letter ISO 4217 codes", ErrInvalidExpense)
+	}
+	return currency, nil
+}
+
+// orgKey names an organization in storage keys.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
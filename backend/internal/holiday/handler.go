@@ -0,0 +1,484 @@
+// prometheus/backend/internal/holiday/handler.go
+package holiday
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for holiday calendars and working days.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCalendars returns the organization's holiday calendars.
+// @Summary List holiday calendars
+// @Tags Holidays
+// @Produce json
+// @Success 200 {array} Calendar
+// @Router /hr/holiday-calendars [get]
+func (h *Handler) ListCalendars(c *gin.Context) {
+	calendars, err := h.service.Calendars(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday calendars fetched successfully", calendars)
+}
+
+// GetCalendar returns a holiday calendar. The ETag and Last-Modified headers can be sent back as If-Match /
+// If-Unmodified-Since.
+// @Summary Get a holiday calendar
+// @Tags Holidays
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Success 200 {object} Calendar
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Router /hr/holiday-calendars/{id} [get]
+func (h *Handler) GetCalendar(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	calendar, err := h.service.GetCalendar(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, calendar.UpdatedAt, calendar.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday calendar fetched successfully", calendar)
+}
+
+// CreateCalendar adds a holiday calendar for a country or a location within it.
+// @Summary Create a holiday calendar
+// @Description The organization's first calendar becomes its default; a new default replaces the old one.
+// @Description Divisions follow at most one calendar.
+// @Tags Holidays
+// @Accept json
+// @Produce json
+// @Param calendar body CalendarRequest true "Calendar"
+// @Success 201 {object} Calendar
+// @Failure 400 {object} utils.ErrorResponse "Invalid calendar or division"
+// @Router /hr/holiday-calendars [post]
+func (h *Handler) CreateCalendar(c *gin.Context) {
+	var req CalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	calendar, err := h.service.CreateCalendar(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, calendar.UpdatedAt, calendar.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Holiday calendar created successfully", calendar)
+}
+
+// UpdateCalendar replaces a calendar's fields.
+// @Summary Update a holiday calendar
+// @Tags Holidays
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param calendar body CalendarRequest true "Calendar"
+// @Success 200 {object} Calendar
+// @Failure 400 {object} utils.ErrorResponse "Invalid calendar or division"
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/holiday-calendars/{id} [put]
+func (h *Handler) UpdateCalendar(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetCalendar(orgID, id)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	calendar, err := h.service.UpdateCalendar(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, calendar.UpdatedAt, calendar.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday calendar updated successfully", calendar)
+}
+
+// DeleteCalendar removes a calendar with its holidays.
+// @Summary Delete a holiday calendar
+// @Tags Holidays
+// @Param id path int true "Calendar ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Router /hr/holiday-calendars/{id} [delete]
+func (h *Handler) DeleteCalendar(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteCalendar(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListHolidays returns a calendar's holiday rules.
+// @Summary List a calendar's holidays
+// @Tags Holidays
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Success 200 {array} Holiday
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Router /hr/holiday-calendars/{id}/holidays [get]
+func (h *Handler) ListHolidays(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	holidays, err := h.service.Holidays(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Holidays fetched successfully", holidays)
+}
+
+// CreateHoliday adds a holiday to a calendar.
+// @Summary Create a holiday
+// @Description kind=date needs date; kind=annual needs month and day; kind=nth_weekday needs month,
+// @Description weekday (ISO, 1 = Monday) and nth (-1 for the last). Observed holidays falling on a rest
+// @Description day move to the next working day.
+// @Tags Holidays
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param holiday body HolidayRequest true "Holiday"
+// @Success 201 {object} Holiday
+// @Failure 400 {object} utils.ErrorResponse "Invalid holiday"
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Router /hr/holiday-calendars/{id}/holidays [post]
+func (h *Handler) CreateHoliday(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	holiday, err := h.service.CreateHoliday(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, holiday.UpdatedAt, holiday.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Holiday created successfully", holiday)
+}
+
+// GetHoliday returns a holiday rule.
+// @Summary Get a holiday
+// @Tags Holidays
+// @Produce json
+// @Param id path int true "Holiday ID"
+// @Success 200 {object} Holiday
+// @Failure 404 {object} utils.ErrorResponse "Holiday not found"
+// @Router /hr/holidays/{id} [get]
+func (h *Handler) GetHoliday(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	holiday, err := h.service.GetHoliday(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, holiday.UpdatedAt, holiday.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday fetched successfully", holiday)
+}
+
+// UpdateHoliday replaces a holiday's fields.
+// @Summary Update a holiday
+// @Tags Holidays
+// @Accept json
+// @Produce json
+// @Param id path int true "Holiday ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param holiday body HolidayRequest true "Holiday"
+// @Success 200 {object} Holiday
+// @Failure 400 {object} utils.ErrorResponse "Invalid holiday"
+// @Failure 404 {object} utils.ErrorResponse "Holiday not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/holidays/{id} [put]
+func (h *Handler) UpdateHoliday(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetHoliday(orgID, id)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	holiday, err := h.service.UpdateHoliday(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, holiday.UpdatedAt, holiday.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday updated successfully", holiday)
+}
+
+// DeleteHoliday removes a holiday.
+// @Summary Delete a holiday
+// @Tags Holidays
+// @Param id path int true "Holiday ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Holiday not found"
+// @Router /hr/holidays/{id} [delete]
+func (h *Handler) DeleteHoliday(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteHoliday(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Occurrences lists the dates a calendar's holidays fall on.
+// @Summary List a calendar's holiday dates
+// @Tags Holidays
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param from query string false "First date (YYYY-MM-DD); defaults to 1 January of this year"
+// @Param to query string false "Last date (YYYY-MM-DD); defaults to 31 December of from's year"
+// @Success 200 {array} Occurrence
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Failure 404 {object} utils.ErrorResponse "Calendar not found"
+// @Router /hr/holiday-calendars/{id}/occurrences [get]
+func (h *Handler) Occurrences(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	from, to, ok := parsePeriod(c, true)
+	if !ok {
+		return
+	}
+	occurrences, err := h.service.Occurrences(utils.OrganizationFromContext(c), id, from, to)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Holiday dates fetched successfully", occurrences)
+}
+
+// Assign puts an employee on a calendar other than their division's or the default.
+// @Summary Assign an employee's holiday calendar
+// @Tags Holidays
+// @Accept json
+// @Param id path int true "Employee ID"
+// @Param assignment body AssignRequest true "Calendar; null to follow the division's or the default"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.ErrorResponse "Unknown calendar"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/holiday-calendar [put]
+func (h *Handler) Assign(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if err := h.service.Assign(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req.CalendarID); err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// EmployeeWorkingDays counts an employee's working days in a period, e.g. for a leave request.
+// @Summary Count an employee's working days
+// @Description Days of the organization's work week that aren't holidays in the employee's calendar,
+// @Description from and to both included.
+// @Tags Holidays
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param from query string true "First date (YYYY-MM-DD)"
+// @Param to query string true "Last date (YYYY-MM-DD)"
+// @Success 200 {object} Duration
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Router /hr/employees/{id}/working-days [get]
+func (h *Handler) EmployeeWorkingDays(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.sendWorkingDays(c, id)
+}
+
+// MyWorkingDays counts the caller's working days in a period, e.g. before requesting leave.
+// @Summary Count my working days
+// @Tags Holidays
+// @Produce json
+// @Param from query string true "First date (YYYY-MM-DD)"
+// @Param to query string true "Last date (YYYY-MM-DD)"
+// @Success 200 {object} Duration
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/working-days [get]
+func (h *Handler) MyWorkingDays(c *gin.Context) {
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	h.sendWorkingDays(c, emp.ID)
+}
+
+func (h *Handler) sendWorkingDays(c *gin.Context, employeeID uint) {
+	from, to, ok := parsePeriod(c, false)
+	if !ok {
+		return
+	}
+	duration, err := h.service.WorkingDays(utils.OrganizationFromContext(c), employeeID, from, to)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Working days counted successfully", duration)
+}
+
+// MyHolidays lists the holidays in the caller's calendar.
+// @Summary List my holidays
+// @Tags Holidays
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD); defaults to 1 January of this year"
+// @Param to query string false "Last date (YYYY-MM-DD); defaults to 31 December of from's year"
+// @Success 200 {array} Occurrence
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/holidays [get]
+func (h *Handler) MyHolidays(c *gin.Context) {
+	from, to, ok := parsePeriod(c, true)
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	calendar, err := h.service.CalendarFor(orgID, emp.ID)
+	if err != nil {
+		sendHolidayError(c, err)
+		return
+	}
+	occurrences := []Occurrence{}
+	if calendar != nil {
+		if occurrences, err = h.service.Occurrences(orgID, calendar.ID, from, to); err != nil {
+			sendHolidayError(c, err)
+			return
+		}
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Holidays fetched successfully", occurrences)
+}
+
+// parsePeriod reads the from and to query parameters. With defaults, they default to the current calendar
+// year; without, both are required.
+func parsePeriod(c *gin.Context, defaults bool) (time.Time, time.Time, bool) {
+	parse := func(name string) (*time.Time, bool) {
+		raw := c.Query(name)
+		if raw == "" {
+			if !defaults {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Missing "+name+" parameter")
+				return nil, false
+			}
+			return nil, true
+		}
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+name+" parameter: expected YYYY-MM-DD")
+			return nil, false
+		}
+		return &date, true
+	}
+	from, ok := parse("from")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := parse("to")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if from == nil {
+		start := day(clock.Now().UTC().Year(), time.January, 1)
+		from = &start
+	}
+	if to == nil {
+		end := day(from.Year(), time.December, 31)
+		to = &end
+	}
+	return *from, *to, true
+}
+
+// sendHolidayError maps service errors to HTTP status codes.
+func sendHolidayError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidCalendar):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
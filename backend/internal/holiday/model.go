@@ -0,0 +1,142 @@
+// prometheus/backend/internal/holiday/model.go
+package holiday
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// RuleKind is how a holiday's dates are worked out.
+type RuleKind string
+
+const (
+	RuleDate       RuleKind = "date"        // Once, on Date
+	RuleAnnual     RuleKind = "annual"      // Every year on Month and Day
+	RuleNthWeekday RuleKind = "nth_weekday" // Every year on the Nth Weekday of Month; Nth -1 is the last
+)
+
+// Calendar is a set of public holidays for a country or a location within it. Employees follow the calendar
+// they are assigned to, else the calendar of their division, else the organization's default calendar.
+type Calendar struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"2"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name" example:"Indonesia – Bali"`
+	CountryCode    string         `gorm:"type:char(2);not null" json:"country_code" example:"ID"`                          // ISO 3166-1 alpha-2
+	Location       string         `gorm:"type:varchar(100)" json:"location,omitempty" example:"Bali"`                      // Region or office; empty for the whole country
+	IsDefault      bool           `gorm:"not null" json:"is_default"`                                                      // Followed by employees without another calendar; one per organization
+	DivisionIDs    datatypes.JSON `gorm:"type:jsonb;not null" json:"division_ids" swaggertype:"array,integer" example:"2"` // Divisions following the calendar
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"`                                   // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName keeps calendars next to their holidays.
+func (Calendar) TableName() string { return "holiday_calendars" }
+
+// Holiday is a public holiday in a calendar, once or recurring.
+type Holiday struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"14"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CalendarID     uint       `gorm:"not null;index" json:"calendar_id" example:"2"`
+	Name           string     `gorm:"type:varchar(150);not null" json:"name" example:"Independence Day"`
+	Kind           RuleKind   `gorm:"type:varchar(20);not null" json:"kind" example:"annual"`
+	Date           *time.Time `gorm:"type:date" json:"date,omitempty" example:"2026-03-20T00:00:00Z"` // RuleDate
+	Month          int        `json:"month,omitempty" example:"8"`                                    // RuleAnnual, RuleNthWeekday
+	Day            int        `json:"day,omitempty" example:"17"`                                     // RuleAnnual
+	Weekday        int        `json:"weekday,omitempty" example:"1"`                                  // RuleNthWeekday; ISO, 1 = Monday
+	Nth            int        `json:"nth,omitempty" example:"3"`                                      // RuleNthWeekday; 1 to 5, or -1 for the last
+	FromYear       *int       `json:"from_year,omitempty" example:"2020"`                             // Recurring rules: first year observed
+	UntilYear      *int       `json:"until_year,omitempty" example:"2030"`                            // Recurring rules: last year observed
+	Observed       bool       `gorm:"not null" json:"observed"`                                       // Falling on a rest day, it moves to the next working day
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"`                  // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Assignment puts an employee on a calendar other than their division's or the default, e.g. when they
+// work from another country.
+type Assignment struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;uniqueIndex" json:"employee_id" example:"12"`
+	CalendarID     uint      `gorm:"not null;index" json:"calendar_id" example:"2"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps assignments next to calendars.
+func (Assignment) TableName() string { return "holiday_calendar_assignments" }
+
+// CalendarRequest creates a calendar or replaces its fields.
+type CalendarRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Indonesia – Bali"`
+	CountryCode string `json:"country_code" binding:"required,len=2" example:"ID"`
+	Location    string `json:"location,omitempty" binding:"max=100" example:"Bali"`
+	IsDefault   bool   `json:"is_default"`
+	DivisionIDs []uint `json:"division_ids" binding:"max=500" swaggertype:"array,integer" example:"2"`
+}
+
+// HolidayRequest creates a holiday or replaces its fields. Which fields are required depends on kind.
+type HolidayRequest struct {
+	Name      string   `json:"name" binding:"required,max=150" example:"Independence Day"`
+	Kind      RuleKind `json:"kind" binding:"required,oneof=date annual nth_weekday" example:"annual"`
+	Date      string   `json:"date,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-03-20"`
+	Month     int      `json:"month,omitempty" binding:"omitempty,min=1,max=12" example:"8"`
+	Day       int      `json:"day,omitempty" binding:"omitempty,min=1,max=31" example:"17"`
+	Weekday   int      `json:"weekday,omitempty" binding:"omitempty,min=1,max=7" example:"1"`
+	Nth       int      `json:"nth,omitempty" binding:"omitempty,min=-1,max=5" example:"3"`
+	FromYear  *int     `json:"from_year,omitempty" binding:"omitempty,min=1900,max=2200" example:"2020"`
+	UntilYear *int     `json:"until_year,omitempty" binding:"omitempty,min=1900,max=2200" example:"2030"`
+	Observed  bool     `json:"observed"`
+}
+
+// AssignRequest puts an employee on a calendar; a null calendar_id returns them to their division's or
+// the default.
+type AssignRequest struct {
+	CalendarID *uint `json:"calendar_id" example:"2"`
+}
+
+// Occurrence is a holiday falling on a date.
+type Occurrence struct {
+	Date       string `json:"date" example:"2026-08-17"` // YYYY-MM-DD
+	Name       string `json:"name" example:"Independence Day"`
+	HolidayID  uint   `json:"holiday_id" example:"14"`
+	CalendarID uint   `json:"calendar_id" example:"2"`
+	Observed   bool   `json:"observed,omitempty"` // Moved here from a rest day
+}
+
+// Duration is how many working days a period spans for an employee: what a leave request from From to To,
+// both included, takes from their balance.
+type Duration struct {
+	From         string       `json:"from" example:"2026-08-14"`
+	To           string       `json:"to" example:"2026-08-19"`
+	CalendarID   *uint        `json:"calendar_id,omitempty" example:"2"` // Calendar the employee follows; none means no holidays
+	CalendarDays int          `json:"calendar_days" example:"6"`
+	WorkingDays  int          `json:"working_days" example:"3"`
+	Holidays     []Occurrence `json:"holidays"` // Falling on working days within the period
+}
+
+// DayKind is what a date is for an employee.
+type DayKind string
+
+const (
+	DayWorking DayKind = "working"  // Expected at work
+	DayRest    DayKind = "rest_day" // Outside the organization's work week
+	DayHoliday DayKind = "holiday"  // A public holiday in the employee's calendar
+)
+
+// Day is what a date is for one employee.
+type Day struct {
+	Kind    DayKind     `json:"kind" example:"holiday"`
+	Holiday *Occurrence `json:"holiday,omitempty"`
+}
+
+// Schedule is what a date is for several employees, in the organization's timezone.
+type Schedule struct {
+	Date     string         `json:"date" example:"2026-08-17"`
+	Location *time.Location `json:"-"`
+	Days     map[uint]Day   `json:"days"` // By employee ID
+}
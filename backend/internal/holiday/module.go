@@ -0,0 +1,48 @@
+// prometheus/backend/internal/holiday/module.go
+package holiday
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the holidays module.
+const ModuleName = "holidays"
+
+// holidayModule owns holiday calendars and the working days they leave.
+type holidayModule struct {
+	handler *Handler
+}
+
+// NewModule creates the holidays module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &holidayModule{handler: NewHandler(svc)}
+}
+
+func (m *holidayModule) Name() string { return ModuleName }
+
+func (m *holidayModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *holidayModule) Models() []any {
+	return []any{&Calendar{}, &Holiday{}, &Assignment{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR manages calendars; employees see their own holidays.
+func (m *holidayModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/holidays", routing.Authenticated(), m.handler.MyHolidays)
+	api.GET("/me/working-days", routing.Authenticated(), m.handler.MyWorkingDays)
+	api.GET("/hr/holiday-calendars", routing.Policy(), m.handler.ListCalendars)
+	api.POST("/hr/holiday-calendars", routing.Policy(), m.handler.CreateCalendar)
+	api.GET("/hr/holiday-calendars/:id", routing.Policy(), m.handler.GetCalendar)
+	api.PUT("/hr/holiday-calendars/:id", routing.Policy(), m.handler.UpdateCalendar)
+	api.DELETE("/hr/holiday-calendars/:id", routing.Policy(), m.handler.DeleteCalendar)
+	api.GET("/hr/holiday-calendars/:id/holidays", routing.Policy(), m.handler.ListHolidays)
+	api.POST("/hr/holiday-calendars/:id/holidays", routing.Policy(), m.handler.CreateHoliday)
+	api.GET("/hr/holiday-calendars/:id/occurrences", routing.Policy(), m.handler.Occurrences)
+	api.GET("/hr/holidays/:id", routing.Policy(), m.handler.GetHoliday)
+	api.PUT("/hr/holidays/:id", routing.Policy(), m.handler.UpdateHoliday)
+	api.DELETE("/hr/holidays/:id", routing.Policy(), m.handler.DeleteHoliday)
+	api.PUT("/hr/employees/:id/holiday-calendar", routing.Policy(), m.handler.Assign)
+	api.GET("/hr/employees/:id/working-days", routing.Policy(), m.handler.EmployeeWorkingDays)
+}
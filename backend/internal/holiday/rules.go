@@ -0,0 +1,121 @@
+// prometheus/backend/internal/holiday/rules.go
+package holiday
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultWorkWeek is Monday to Friday, for organizations that haven't set a work week.
+var defaultWorkWeek = []int{1, 2, 3, 4, 5}
+
+// isoWeekday numbers weekdays from 1 (Monday) to 7 (Sunday).
+func isoWeekday(date time.Time) int {
+	if date.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(date.Weekday())
+}
+
+// dateOf returns the holiday's date in year, or false if it doesn't fall in that year: a one-off holiday
+// in another year, a recurring one outside its years, or a day the month doesn't have (29 February).
+func dateOf(h Holiday, year int) (time.Time, bool) {
+	if h.Kind == RuleDate {
+		if h.Date == nil || h.Date.Year() != year {
+			return time.Time{}, false
+		}
+		return day(h.Date.Year(), h.Date.Month(), h.Date.Day()), true
+	}
+	if (h.FromYear != nil && year < *h.FromYear) || (h.UntilYear != nil && year > *h.UntilYear) {
+		return time.Time{}, false
+	}
+	switch h.Kind {
+	case RuleAnnual:
+		date := day(year, time.Month(h.Month), h.Day)
+		if date.Month() != time.Month(h.Month) {
+			return time.Time{}, false
+		}
+		return date, true
+	case RuleNthWeekday:
+		return nthWeekday(year, time.Month(h.Month), h.Weekday, h.Nth)
+	}
+	return time.Time{}, false
+}
+
+// nthWeekday returns the nth ISO weekday of the month, or its last one if nth is -1.
+func nthWeekday(year int, month time.Month, weekday, nth int) (time.Time, bool) {
+	if nth == -1 {
+		last := day(year, month+1, 0)
+		return last.AddDate(0, 0, -((isoWeekday(last) - weekday + 7) % 7)), true
+	}
+	first := day(year, month, 1)
+	date := first.AddDate(0, 0, (weekday-isoWeekday(first)+7)%7+(nth-1)*7)
+	if date.Month() != month {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// expand lists the holidays falling between from and to, both included, in date order. Observed holidays
+// falling on a rest day move to the next working day that isn't already a holiday, so two of them falling
+// on one weekend take the Monday and the Tuesday.
+func expand(holidays []Holiday, from, to time.Time, workWeek []int) []Occurrence {
+	working := func(date time.Time) bool { return slices.Contains(workWeek, isoWeekday(date)) }
+	type raw struct {
+		date    time.Time
+		holiday Holiday
+	}
+	var fixed, moving []raw
+	// From the year before: a holiday on 31 December may move into the period.
+	for year := from.Year() - 1; year <= to.Year(); year++ {
+		for _, h := range holidays {
+			date, ok := dateOf(h, year)
+			if !ok {
+				continue
+			}
+			if h.Observed && !working(date) {
+				moving = append(moving, raw{date, h})
+			} else {
+				fixed = append(fixed, raw{date, h})
+			}
+		}
+	}
+	taken := make(map[time.Time]bool, len(fixed))
+	occurrences := make([]Occurrence, 0, len(fixed)+len(moving))
+	add := func(date time.Time, h Holiday, observed bool) {
+		if date.Before(from) || date.After(to) {
+			return
+		}
+		occurrences = append(occurrences, Occurrence{
+			Date: date.Format("2006-01-02"), Name: h.Name, HolidayID: h.ID, CalendarID: h.CalendarID, Observed: observed,
+		})
+	}
+	for _, r := range fixed {
+		taken[r.date] = true
+		add(r.date, r.holiday, false)
+	}
+	slices.SortFunc(moving, func(a, b raw) int { return a.date.Compare(b.date) })
+	for _, r := range moving {
+		date := r.date
+		if len(workWeek) > 0 {
+			for !working(date) || taken[date] {
+				date = date.AddDate(0, 0, 1)
+			}
+		}
+		taken[date] = true
+		add(date, r.holiday, true)
+	}
+	slices.SortStableFunc(occurrences, func(a, b Occurrence) int {
+		if c := strings.Compare(a.Date, b.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return occurrences
+}
+
+// day returns midnight UTC of a date, normalizing out-of-range days like time.Date.
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
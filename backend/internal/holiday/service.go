@@ -0,0 +1,622 @@
+// prometheus/backend/internal/holiday/service.go
+package holiday
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSpan caps the periods holidays are listed or counted over.
+const maxSpan = 366 * 3
+
+var (
+	// ErrInvalidCalendar is returned for calendars, holidays and periods that fail validation.
+	ErrInvalidCalendar = errors.New("invalid holiday calendar")
+)
+
+// Service manages the organization's holiday calendars and tells which days employees are expected at
+// work. orgID scopes every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	Calendars(orgID *uint) ([]Calendar, error)
+	GetCalendar(orgID *uint, id uint) (*Calendar, error)
+	CreateCalendar(actor audit.Actor, orgID *uint, req CalendarRequest) (*Calendar, error)
+	UpdateCalendar(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CalendarRequest) (*Calendar, error)
+	// DeleteCalendar removes the calendar with its holidays; employees assigned to it return to their
+	// division's calendar or the default.
+	DeleteCalendar(actor audit.Actor, orgID *uint, id uint) error
+
+	Holidays(orgID *uint, calendarID uint) ([]Holiday, error)
+	GetHoliday(orgID *uint, id uint) (*Holiday, error)
+	CreateHoliday(actor audit.Actor, orgID *uint, calendarID uint, req HolidayRequest) (*Holiday, error)
+	UpdateHoliday(actor audit.Actor, orgID *uint, id, expectedVersion uint, req HolidayRequest) (*Holiday, error)
+	DeleteHoliday(actor audit.Actor, orgID *uint, id uint) error
+	// Occurrences lists the calendar's holidays between from and to, both included.
+	Occurrences(orgID *uint, calendarID uint, from, to time.Time) ([]Occurrence, error)
+
+	// Assign puts an employee on a calendar, or back on their division's or the default if calendarID is nil.
+	Assign(actor audit.Actor, orgID *uint, employeeID uint, calendarID *uint) error
+	// CalendarFor returns the calendar an employee follows, or nil if there is none.
+	CalendarFor(orgID *uint, employeeID uint) (*Calendar, error)
+	employee.Resolver
+
+	// WorkingDays counts the working days from from to to, both included, for an employee: the days of the
+	// organization's work week that aren't holidays in their calendar. Leave durations are measured in these.
+	WorkingDays(orgID *uint, employeeID uint, from, to time.Time) (*Duration, error)
+	// Schedule tells for each employee whether date is a working day, a rest day or a holiday.
+	Schedule(orgID *uint, employeeIDs []uint, date time.Time) (*Schedule, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Calendars(orgID *uint) ([]Calendar, error) {
+	calendars := []Calendar{}
+	if err := utils.OrgScope(s.db, orgID).Order("country_code, location, name").Find(&calendars).Error; err != nil {
+		return nil, fmt.Errorf("failed to list holiday calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+func (s *service) GetCalendar(orgID *uint, id uint) (*Calendar, error) {
+	var calendar Calendar
+	if err := utils.OrgScope(s.db, orgID).First(&calendar, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &calendar, nil
+}
+
+// CreateCalendar makes the calendar the default if it is asked to be, or if it is the organization's first.
+func (s *service) CreateCalendar(actor audit.Actor, orgID *uint, req CalendarRequest) (*Calendar, error) {
+	calendar := Calendar{OrganizationID: orgID}
+	applyCalendar(&calendar, req)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkCalendar(tx, &calendar, req.DivisionIDs); err != nil {
+			return err
+		}
+		if err := tx.Create(&calendar).Error; err != nil {
+			return fmt.Errorf("failed to create holiday calendar: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday_calendar.create", EntityType: "holiday_calendar", EntityID: fmt.Sprintf("%d", calendar.ID), After: calendar,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// UpdateCalendar replaces the calendar's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdateCalendar(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CalendarRequest) (*Calendar, error) {
+	var updated Calendar
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Calendar
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		calendar := before
+		applyCalendar(&calendar, req)
+		if err := s.checkCalendar(tx, &calendar, req.DivisionIDs); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Calendar{}, id, expectedVersion, map[string]interface{}{
+			"name":         calendar.Name,
+			"country_code": calendar.CountryCode,
+			"location":     calendar.Location,
+			"is_default":   calendar.IsDefault,
+			"division_ids": calendar.DivisionIDs,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload holiday calendar %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday_calendar.update", EntityType: "holiday_calendar", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteCalendar(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Calendar
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("calendar_id = ?", id).Delete(&Holiday{}).Error; err != nil {
+			return fmt.Errorf("failed to delete holidays of calendar %d: %w", id, err)
+		}
+		if err := tx.Where("calendar_id = ?", id).Delete(&Assignment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete assignments to calendar %d: %w", id, err)
+		}
+		if err := tx.Delete(&Calendar{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete holiday calendar %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday_calendar.delete", EntityType: "holiday_calendar", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Holidays(orgID *uint, calendarID uint) ([]Holiday, error) {
+	if _, err := s.GetCalendar(orgID, calendarID); err != nil {
+		return nil, err
+	}
+	holidays := []Holiday{}
+	if err := s.db.Where("calendar_id = ?", calendarID).Order("kind, month, day, date, name").Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+func (s *service) GetHoliday(orgID *uint, id uint) (*Holiday, error) {
+	var holiday Holiday
+	if err := utils.OrgScope(s.db, orgID).First(&holiday, id).Error; err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+func (s *service) CreateHoliday(actor audit.Actor, orgID *uint, calendarID uint, req HolidayRequest) (*Holiday, error) {
+	holiday := Holiday{OrganizationID: orgID, CalendarID: calendarID}
+	if err := applyHoliday(&holiday, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx, orgID).First(&Calendar{}, calendarID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&holiday).Error; err != nil {
+			return fmt.Errorf("failed to create holiday: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday.create", EntityType: "holiday", EntityID: fmt.Sprintf("%d", holiday.ID), After: holiday,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// UpdateHoliday replaces the holiday's fields if it is still at expectedVersion (optimistic locking). Its
+// calendar can't be changed.
+func (s *service) UpdateHoliday(actor audit.Actor, orgID *uint, id, expectedVersion uint, req HolidayRequest) (*Holiday, error) {
+	var updated Holiday
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Holiday
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		holiday := before
+		if err := applyHoliday(&holiday, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Holiday{}, id, expectedVersion, map[string]interface{}{
+			"name":       holiday.Name,
+			"kind":       holiday.Kind,
+			"date":       holiday.Date,
+			"month":      holiday.Month,
+			"day":        holiday.Day,
+			"weekday":    holiday.Weekday,
+			"nth":        holiday.Nth,
+			"from_year":  holiday.FromYear,
+			"until_year": holiday.UntilYear,
+			"observed":   holiday.Observed,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload holiday %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday.update", EntityType: "holiday", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteHoliday(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Holiday
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Holiday{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete holiday %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday.delete", EntityType: "holiday", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Occurrences(orgID *uint, calendarID uint, from, to time.Time) ([]Occurrence, error) {
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetCalendar(orgID, calendarID); err != nil {
+		return nil, err
+	}
+	workWeek, _, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.holidaysOf([]uint{calendarID})
+	if err != nil {
+		return nil, err
+	}
+	return expand(holidays[calendarID], from, to, workWeek), nil
+}
+
+func (s *service) Assign(actor audit.Actor, orgID *uint, employeeID uint, calendarID *uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var found int64
+		if err := utils.OrgScope(tx.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).Count(&found).Error; err != nil {
+			return fmt.Errorf("failed to check employee: %w", err)
+		}
+		if found == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var before []Assignment
+		if err := tx.Where("employee_id = ?", employeeID).Find(&before).Error; err != nil {
+			return fmt.Errorf("failed to load assignment: %w", err)
+		}
+		if calendarID == nil {
+			if err := tx.Where("employee_id = ?", employeeID).Delete(&Assignment{}).Error; err != nil {
+				return fmt.Errorf("failed to remove assignment: %w", err)
+			}
+		} else {
+			if err := utils.OrgScope(tx, orgID).First(&Calendar{}, *calendarID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: calendar %d not found", ErrInvalidCalendar, *calendarID)
+				}
+				return err
+			}
+			assignment := Assignment{OrganizationID: orgID, EmployeeID: employeeID, CalendarID: *calendarID}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "employee_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"calendar_id", "updated_at"}),
+			}).Create(&assignment).Error; err != nil {
+				return fmt.Errorf("failed to assign calendar: %w", err)
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "holiday_calendar.assign", EntityType: "employee", EntityID: fmt.Sprintf("%d", employeeID),
+			Before: before, After: calendarID,
+		})
+	})
+}
+
+func (s *service) CalendarFor(orgID *uint, employeeID uint) (*Calendar, error) {
+	calendars, err := s.calendarsFor(orgID, []uint{employeeID})
+	if err != nil {
+		return nil, err
+	}
+	return calendars[employeeID], nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+func (s *service) WorkingDays(orgID *uint, employeeID uint, from, to time.Time) (*Duration, error) {
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	calendar, err := s.CalendarFor(orgID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	workWeek, _, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	duration := &Duration{From: from.Format("2006-01-02"), To: to.Format("2006-01-02"), Holidays: []Occurrence{}}
+	holidayOn := map[string]Occurrence{}
+	if calendar != nil {
+		duration.CalendarID = &calendar.ID
+		holidays, err := s.holidaysOf([]uint{calendar.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range expand(holidays[calendar.ID], from, to, workWeek) {
+			if _, ok := holidayOn[o.Date]; !ok {
+				holidayOn[o.Date] = o
+			}
+		}
+	}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		duration.CalendarDays++
+		if !slices.Contains(workWeek, isoWeekday(date)) {
+			continue
+		}
+		if o, ok := holidayOn[date.Format("2006-01-02")]; ok {
+			duration.Holidays = append(duration.Holidays, o)
+			continue
+		}
+		duration.WorkingDays++
+	}
+	return duration, nil
+}
+
+func (s *service) Schedule(orgID *uint, employeeIDs []uint, date time.Time) (*Schedule, error) {
+	workWeek, location, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	date = day(date.Year(), date.Month(), date.Day())
+	schedule := &Schedule{Date: date.Format("2006-01-02"), Location: location, Days: make(map[uint]Day, len(employeeIDs))}
+	if len(employeeIDs) == 0 {
+		return schedule, nil
+	}
+	if !slices.Contains(workWeek, isoWeekday(date)) {
+		for _, id := range employeeIDs {
+			schedule.Days[id] = Day{Kind: DayRest}
+		}
+		return schedule, nil
+	}
+	calendars, err := s.calendarsFor(orgID, employeeIDs)
+	if err != nil {
+		return nil, err
+	}
+	var calendarIDs []uint
+	for _, c := range calendars {
+		if c != nil && !slices.Contains(calendarIDs, c.ID) {
+			calendarIDs = append(calendarIDs, c.ID)
+		}
+	}
+	holidays, err := s.holidaysOf(calendarIDs)
+	if err != nil {
+		return nil, err
+	}
+	onDate := make(map[uint]*Occurrence, len(calendarIDs))
+	for _, id := range calendarIDs {
+		if occurrences := expand(holidays[id], date, date, workWeek); len(occurrences) > 0 {
+			onDate[id] = &occurrences[0]
+		}
+	}
+	for _, id := range employeeIDs {
+		schedule.Days[id] = Day{Kind: DayWorking}
+		if c := calendars[id]; c != nil && onDate[c.ID] != nil {
+			schedule.Days[id] = Day{Kind: DayHoliday, Holiday: onDate[c.ID]}
+		}
+	}
+	return schedule, nil
+}
+
+// calendarsFor resolves the calendar each employee follows: their assignment, else their division's
+// calendar, else the default. Employees without any calendar map to nil.
+func (s *service) calendarsFor(orgID *uint, employeeIDs []uint) (map[uint]*Calendar, error) {
+	calendars, err := s.Calendars(orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]*Calendar, len(employeeIDs))
+	if len(calendars) == 0 {
+		return result, nil
+	}
+	byID := make(map[uint]*Calendar, len(calendars))
+	byDivision := make(map[uint]*Calendar)
+	var fallback *Calendar
+	for i := range calendars {
+		c := &calendars[i]
+		byID[c.ID] = c
+		for _, divisionID := range decodeIDs(c.DivisionIDs) {
+			byDivision[divisionID] = c
+		}
+		if c.IsDefault {
+			fallback = c
+		}
+	}
+	var employees []struct {
+		ID         uint
+		DivisionID *uint
+	}
+	if err := utils.OrgScope(s.db.Table("employees").Select("id, division_id").Where("id IN ?", employeeIDs), orgID).Scan(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+	var assignments []Assignment
+	if err := s.db.Where("employee_id IN ?", employeeIDs).Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load calendar assignments: %w", err)
+	}
+	assigned := make(map[uint]uint, len(assignments))
+	for _, a := range assignments {
+		assigned[a.EmployeeID] = a.CalendarID
+	}
+	for _, e := range employees {
+		calendar := fallback
+		if e.DivisionID != nil && byDivision[*e.DivisionID] != nil {
+			calendar = byDivision[*e.DivisionID]
+		}
+		if c, ok := byID[assigned[e.ID]]; ok {
+			calendar = c
+		}
+		result[e.ID] = calendar
+	}
+	return result, nil
+}
+
+// holidaysOf loads the holidays of calendars, by calendar ID.
+func (s *service) holidaysOf(calendarIDs []uint) (map[uint][]Holiday, error) {
+	result := make(map[uint][]Holiday, len(calendarIDs))
+	if len(calendarIDs) == 0 {
+		return result, nil
+	}
+	var holidays []Holiday
+	if err := s.db.Where("calendar_id IN ?", calendarIDs).Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to load holidays: %w", err)
+	}
+	for _, h := range holidays {
+		result[h.CalendarID] = append(result[h.CalendarID], h)
+	}
+	return result, nil
+}
+
+// settings returns the organization's work week and timezone, set during onboarding. Platform users and
+// organizations without them work Monday to Friday, in UTC.
+func (s *service) settings(orgID *uint) ([]int, *time.Location, error) {
+	if orgID == nil {
+		return defaultWorkWeek, time.UTC, nil
+	}
+	var org organization.Organization
+	if err := s.db.Select("id, timezone, work_week").First(&org, *orgID).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load organization %d: %w", *orgID, err)
+	}
+	workWeek := defaultWorkWeek
+	var configured []int
+	if len(org.WorkWeek) > 0 && json.Unmarshal(org.WorkWeek, &configured) == nil && len(configured) > 0 {
+		workWeek = configured
+	}
+	location, err := time.LoadLocation(org.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return workWeek, location, nil
+}
+
+// checkCalendar checks the calendar's divisions are the organization's and follow no other calendar, and
+// makes it the default if it is the organization's first; a new default replaces the old one.
+func (s *service) checkCalendar(tx *gorm.DB, calendar *Calendar, divisionIDs []uint) error {
+	others := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), calendar.OrganizationID)
+	if calendar.ID != 0 {
+		others = others.Where("id <> ?", calendar.ID)
+	}
+	var existing []Calendar
+	if err := others.Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to load holiday calendars: %w", err)
+	}
+	if len(existing) == 0 {
+		calendar.IsDefault = true
+	}
+	if len(divisionIDs) > 0 {
+		var found int64
+		if err := utils.OrgScope(tx.Table("divisions").Where("id IN ? AND deleted_at IS NULL", divisionIDs), calendar.OrganizationID).Count(&found).Error; err != nil {
+			return fmt.Errorf("failed to check divisions: %w", err)
+		}
+		if found != int64(len(divisionIDs)) {
+			return fmt.Errorf("%w: unknown division", ErrInvalidCalendar)
+		}
+	}
+	for _, other := range existing {
+		for _, id := range decodeIDs(other.DivisionIDs) {
+			if slices.Contains(divisionIDs, id) {
+				return fmt.Errorf("%w: division %d already follows %q", ErrInvalidCalendar, id, other.Name)
+			}
+		}
+	}
+	if calendar.IsDefault {
+		ids := make([]uint, 0, len(existing))
+		for _, other := range existing {
+			if other.IsDefault {
+				ids = append(ids, other.ID)
+			}
+		}
+		if len(ids) > 0 {
+			if err := tx.Model(&Calendar{}).Where("id IN ?", ids).
+				Updates(map[string]interface{}{"is_default": false, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return fmt.Errorf("failed to replace the default calendar: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyCalendar(calendar *Calendar, req CalendarRequest) {
+	calendar.Name = strings.TrimSpace(req.Name)
+	calendar.CountryCode = strings.ToUpper(req.CountryCode)
+	calendar.Location = strings.TrimSpace(req.Location)
+	calendar.IsDefault = req.IsDefault
+	ids := slices.Clone(req.DivisionIDs)
+	slices.Sort(ids)
+	calendar.DivisionIDs = encodeIDs(slices.Compact(ids))
+}
+
+// applyHoliday checks the fields kind requires and clears the others.
+func applyHoliday(holiday *Holiday, req HolidayRequest) error {
+	*holiday = Holiday{
+		ID: holiday.ID, OrganizationID: holiday.OrganizationID, CalendarID: holiday.CalendarID, Version: holiday.Version,
+		CreatedAt: holiday.CreatedAt, UpdatedAt: holiday.UpdatedAt,
+		Name: strings.TrimSpace(req.Name), Kind: req.Kind, Observed: req.Observed,
+	}
+	switch req.Kind {
+	case RuleDate:
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return fmt.Errorf("%w: one-off holidays need a date (YYYY-MM-DD)", ErrInvalidCalendar)
+		}
+		holiday.Date = &date
+		return nil
+	case RuleAnnual:
+		if req.Month == 0 || req.Day == 0 || day(2000, time.Month(req.Month), req.Day).Month() != time.Month(req.Month) {
+			return fmt.Errorf("%w: annual holidays need a valid month and day", ErrInvalidCalendar)
+		}
+		holiday.Month, holiday.Day = req.Month, req.Day
+	case RuleNthWeekday:
+		if req.Month == 0 || req.Weekday == 0 || req.Nth == 0 {
+			return fmt.Errorf("%w: nth_weekday holidays need a month, weekday and nth", ErrInvalidCalendar)
+		}
+		holiday.Month, holiday.Weekday, holiday.Nth = req.Month, req.Weekday, req.Nth
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidCalendar, req.Kind)
+	}
+	if req.FromYear != nil && req.UntilYear != nil && *req.UntilYear < *req.FromYear {
+		return fmt.Errorf("%w: until_year is before from_year", ErrInvalidCalendar)
+	}
+	holiday.FromYear, holiday.UntilYear = req.FromYear, req.UntilYear
+	return nil
+}
+
+func checkPeriod(from, to time.Time) error {
+	if to.Before(from) {
+		return fmt.Errorf("%w: the period ends before it starts", ErrInvalidCalendar)
+	}
+	if to.Sub(from) > maxSpan*24*time.Hour {
+		return fmt.Errorf("%w: periods are limited to %d days", ErrInvalidCalendar, maxSpan)
+	}
+	return nil
+}
+
+func encodeIDs(ids []uint) datatypes.JSON {
+	if ids == nil {
+		ids = []uint{}
+	}
+	raw, _ := json.Marshal(ids)
+	return datatypes.JSON(raw)
+}
+
+func decodeIDs(raw datatypes.JSON) []uint {
+	var ids []uint
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &ids)
+	}
+	return ids
+}
@@ -0,0 +1,97 @@
+// prometheus/backend/internal/jobs/handler.go
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OperationHandler exposes jobs as long-running operations (LRO):
+// endpoints that start async work respond 202 with an operation ID (see SendAccepted),
+// and clients poll GET /operations/:id or cancel with DELETE /operations/:id.
+type OperationHandler struct {
+	queue *Queue
+}
+
+// NewOperationHandler creates a new instance of OperationHandler.
+func NewOperationHandler(queue *Queue) *OperationHandler {
+	return &OperationHandler{queue: queue}
+}
+
+// operationAdminRoles may see and cancel operations started by anyone.
+var operationAdminRoles = []string{"admin", "god-admin"}
+
+// SendAccepted responds 202 Accepted with the operation and a Location header pointing at it.
+func SendAccepted(c *gin.Context, job *Job) {
+	c.Header("Location", "/api/v1/operations/"+job.ID)
+	utils.SendSuccessResponse(c, http.StatusAccepted, "Operation accepted", job)
+}
+
+// Get reports an operation's status, progress, result and errors.
+// @Summary Get a long-running operation
+// @Tags Operations
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} Job
+// @Failure 404 {object} utils.ErrorResponse "Operation not found"
+// @Router /operations/{id} [get]
+func (h *OperationHandler) Get(c *gin.Context) {
+	job, ok := h.load(c)
+	if !ok {
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Operation fetched successfully", job)
+}
+
+// Cancel cancels a pending operation or requests a running one to stop.
+// @Summary Cancel a long-running operation
+// @Tags Operations
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} Job
+// @Failure 404 {object} utils.ErrorResponse "Operation not found"
+// @Failure 409 {object} utils.ErrorResponse "Operation already finished"
+// @Router /operations/{id} [delete]
+func (h *OperationHandler) Cancel(c *gin.Context) {
+	if _, ok := h.load(c); !ok {
+		return
+	}
+	job, err := h.queue.Cancel(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrJobFinished) {
+			utils.SendErrorResponse(c, http.StatusConflict, "Operation already finished")
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Operation cancellation requested", job)
+}
+
+// load fetches the operation and checks that the caller started it (or is an admin).
+// Operations of other users are reported as not found to avoid leaking their existence.
+func (h *OperationHandler) load(c *gin.Context) (*Job, bool) {
+	job, err := h.queue.Get(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, http.StatusNotFound, "Operation not found")
+			return nil, false
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	userID := c.GetUint("userID")
+	roles := c.GetStringSlice("roles")
+	isAdmin := slices.ContainsFunc(roles, func(r string) bool { return slices.Contains(operationAdminRoles, r) })
+	if !isAdmin && (job.CreatedBy == nil || *job.CreatedBy != userID) {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Operation not found")
+		return nil, false
+	}
+	return job, true
+}
@@ -0,0 +1,54 @@
+// prometheus/backend/internal/jobs/model.go
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job is a unit of background work persisted in the jobs table.
+// Rows survive restarts, so any replica's worker pool can pick pending jobs up.
+type Job struct {
+	ID              string         `gorm:"type:varchar(36);primaryKey" json:"id" example:"3f1c6f3e-2a7b-4f0c-9a51-0d5c1f0b8e21"`
+	Type            string         `gorm:"type:varchar(100);not null;index" json:"type" example:"users.import"`
+	Payload         datatypes.JSON `json:"-"`
+	Status          Status         `gorm:"type:varchar(20);not null;index" json:"status" example:"running"`
+	Progress        int            `gorm:"not null;default:0" json:"progress" example:"40"` // 0-100
+	Message         string         `gorm:"type:varchar(255)" json:"message,omitempty" example:"Imported 60 of 150 rows"`
+	Result          datatypes.JSON `json:"result,omitempty" swaggertype:"object"`
+	Error           string         `gorm:"type:text" json:"error,omitempty"`
+	Attempts        int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts     int            `gorm:"not null;default:1" json:"-"`
+	CancelRequested bool           `gorm:"not null;default:false" json:"cancel_requested"`
+	CreatedBy       *uint          `gorm:"index" json:"created_by,omitempty"`
+	RunAt           time.Time      `gorm:"not null;index" json:"-"`
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	HeartbeatAt     *time.Time     `gorm:"index" json:"-"`              // Lease of the running worker, renewed every heartbeatInterval
+	LostRuns        int            `gorm:"not null;default:0" json:"-"` // Runs lost with their worker, see Queue.reapLost
+	FinishedAt      *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// IsFinished reports whether the job reached a terminal state.
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+// DecodePayload unmarshals the job payload into dest.
+func (j *Job) DecodePayload(dest interface{}) error {
+	return json.Unmarshal(j.Payload, dest)
+}
@@ -0,0 +1,47 @@
+// prometheus/backend/internal/jobs/module.go
+package jobs
+
+import (
+	"context"
+	"prometheus/backend/internal/module"
+	"time"
+)
+
+// maxHealthyQueueDepth is the number of overdue pending jobs above which the queue reports degraded.
+const maxHealthyQueueDepth = 500
+
+// Name implements module.Module.
+func (q *Queue) Name() string { return "jobs" }
+
+// HealthContributors implements module.Module: queue depth and recent failures.
+func (q *Queue) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("queue", func(ctx context.Context) module.HealthResult {
+			var pending, running, failedLastHour int64
+			db := q.db.WithContext(ctx).Model(&Job{})
+			if err := db.Where("status = ? AND run_at <= ?", StatusPending, time.Now().UTC()).Count(&pending).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := q.db.WithContext(ctx).Model(&Job{}).Where("status = ?", StatusRunning).Count(&running).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := q.db.WithContext(ctx).Model(&Job{}).Where("status = ? AND finished_at >= ?", StatusFailed, time.Now().UTC().Add(-time.Hour)).Count(&failedLastHour).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+
+			status := module.StatusUp
+			if pending > maxHealthyQueueDepth {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{
+				Status: status,
+				Metrics: map[string]float64{
+					"queue_depth":      float64(pending),
+					"running":          float64(running),
+					"failed_last_hour": float64(failedLastHour),
+					"workers":          float64(q.workers),
+				},
+			}
+		}),
+	}
+}
@@ -0,0 +1,511 @@
+// prometheus/backend/internal/jobs/queue.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/lock"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCancelled is returned by Reporter.SetProgress once cancellation was requested.
+// Handlers should stop and return it (or any error wrapping it).
+var ErrCancelled = errors.New("job cancelled")
+
+// ErrUnknownJobType is returned when enqueuing a job type without a registered handler.
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// ErrJobFinished is returned when cancelling a job that already finished.
+var ErrJobFinished = errors.New("job already finished")
+
+const (
+	// heartbeatInterval is how often the worker running a job renews its lease.
+	heartbeatInterval = 30 * time.Second
+	// leaseTimeout is how long a running job may go without a heartbeat before it is assumed lost with its
+	// worker, e.g. because the replica crashed, and requeued.
+	leaseTimeout = 2 * time.Minute
+	// maxLostRuns is how often a job may be lost with its worker before it is failed rather than requeued,
+	// so a job that brings its replica down doesn't bring down every replica in turn.
+	maxLostRuns = 3
+)
+
+// Reporter lets a running handler publish progress and observe cancellation.
+type Reporter interface {
+	// SetProgress stores progress (0-100) and a short status message.
+	// It returns ErrCancelled if the job was cancelled in the meantime.
+	SetProgress(progress int, message string) error
+}
+
+// Handler executes a job. The returned result is stored as JSON on the job.
+type Handler func(ctx context.Context, job *Job, reporter Reporter) (result interface{}, err error)
+
+// Contributor is implemented by modules that run background jobs. The router registers the jobs of enabled
+// modules only, so a disabled module never has its jobs scheduled.
+type Contributor interface {
+	RegisterJobs(q *Queue)
+}
+
+// Queue is a database-backed job queue with an in-process worker pool.
+type Queue struct {
+	db           *gorm.DB
+	workers      int
+	pollInterval time.Duration
+
+	wg sync.WaitGroup // Workers and schedulers started by Start
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	running   map[string]context.CancelFunc
+	recurring map[string]time.Duration
+}
+
+// NewQueue creates a new Queue. workers <= 0 defaults to 2.
+func NewQueue(db *gorm.DB, workers int) *Queue {
+	if workers <= 0 {
+		workers = 2
+	}
+	return &Queue{
+		db:           db,
+		workers:      workers,
+		pollInterval: 2 * time.Second,
+		handlers:     make(map[string]Handler),
+		running:      make(map[string]context.CancelFunc),
+		recurring:    make(map[string]time.Duration),
+	}
+}
+
+// Register installs the handler for a job type. Call before Start.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Every schedules a registered job type to run periodically with an empty payload (e.g. cleanup
+// jobs). A new run is only enqueued when no run of that type is pending or running, so slow runs
+// don't pile up and several replicas don't duplicate work. Call before Start.
+func (q *Queue) Every(jobType string, interval time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.recurring[jobType] = interval
+}
+
+// EnqueueOptions tweak how a job is scheduled.
+type EnqueueOptions struct {
+	CreatedBy   *uint
+	RunAt       time.Time // zero means now
+	MaxAttempts int       // 0 means 1 (no retries)
+}
+
+// Enqueue persists a new pending job.
+func (q *Queue) Enqueue(jobType string, payload interface{}, opts EnqueueOptions) (*Job, error) {
+	return q.EnqueueTx(q.db, jobType, payload, opts)
+}
+
+// EnqueueTx persists a new pending job using tx, so the job is only visible if tx commits.
+func (q *Queue) EnqueueTx(tx *gorm.DB, jobType string, payload interface{}, opts EnqueueOptions) (*Job, error) {
+	q.mu.RLock()
+	_, known := q.handlers[jobType]
+	q.mu.RUnlock()
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJobType, jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now().UTC()
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	job := &Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: maxAttempts,
+		CreatedBy:   opts.CreatedBy,
+		RunAt:       runAt,
+	}
+	if err := tx.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// Get loads a job by ID.
+func (q *Queue) Get(id string) (*Job, error) {
+	var job Job
+	if err := q.db.First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Cancel cancels a pending job immediately, or asks a running job to stop.
+func (q *Queue) Cancel(id string) (*Job, error) {
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return job, ErrJobFinished
+	}
+
+	now := time.Now().UTC()
+	// Pending jobs are cancelled outright; the status guard avoids racing with a worker claiming it.
+	result := q.db.Model(&Job{}).Where("id = ? AND status = ?", id, StatusPending).
+		Updates(map[string]interface{}{"status": StatusCancelled, "cancel_requested": true, "finished_at": now})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Already running: flag it so the handler sees ErrCancelled on its next progress report.
+		if err := q.db.Model(&Job{}).Where("id = ?", id).Update("cancel_requested", true).Error; err != nil {
+			return nil, fmt.Errorf("failed to request job cancellation: %w", err)
+		}
+		q.mu.RLock()
+		cancel, local := q.running[id]
+		q.mu.RUnlock()
+		if local {
+			cancel()
+		}
+	}
+	return q.Get(id)
+}
+
+// Start launches the worker pool and the reaper of lost jobs. Workers stop when ctx is cancelled; jobs
+// they are running are requeued, see Wait.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		q.spawn(func() { q.work(ctx) })
+	}
+	q.mu.RLock()
+	for jobType, interval := range q.recurring {
+		jobType, interval := jobType, interval
+		q.spawn(func() { q.schedule(ctx, jobType, interval) })
+	}
+	q.mu.RUnlock()
+	q.spawn(func() { q.reap(ctx) })
+	log.Printf("Job queue started with %d workers.", q.workers)
+}
+
+// Wait blocks until the goroutines of Start have stopped after its context was cancelled, so the jobs they
+// were running are requeued before the process exits, or until ctx is done.
+func (q *Queue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// spawn runs fn in a goroutine tracked by Wait.
+func (q *Queue) spawn(fn func()) {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		fn()
+	}()
+}
+
+// schedule enqueues a recurring job every interval until ctx is done.
+func (q *Queue) schedule(ctx context.Context, jobType string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := q.enqueueRecurring(jobType); err != nil {
+			log.Printf("Job queue: failed to schedule %s: %v", jobType, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueRecurring enqueues jobType unless a run is already outstanding. The transaction-scoped
+// advisory lock serializes replicas checking at the same moment.
+func (q *Queue) enqueueRecurring(jobType string) error {
+	return q.db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, "jobs:"+jobType); err != nil {
+			return err
+		}
+		var outstanding int64
+		// A run lost with its worker is requeued by reapLost, so it's outstanding until then as well.
+		if err := tx.Model(&Job{}).Where("type = ? AND status IN ?", jobType, []Status{StatusPending, StatusRunning}).
+			Count(&outstanding).Error; err != nil {
+			return err
+		}
+		if outstanding > 0 {
+			return nil
+		}
+		_, err := q.EnqueueTx(tx, jobType, struct{}{}, EnqueueOptions{})
+		return err
+	})
+}
+
+// work polls for pending jobs until ctx is done.
+func (q *Queue) work(ctx context.Context) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Drain everything that is ready before sleeping again.
+		for {
+			job, err := q.claim()
+			if err != nil {
+				log.Printf("Job queue: failed to claim job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim atomically moves the oldest ready job to running. SKIP LOCKED lets several replicas poll safely.
+func (q *Queue) claim() (*Job, error) {
+	q.mu.RLock()
+	types := make([]string, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	q.mu.RUnlock()
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	var job Job
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ? AND type IN ?", StatusPending, time.Now().UTC(), types).
+			Order("run_at").First(&job).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		job.Status = StatusRunning
+		job.Attempts++
+		job.StartedAt = &now
+		job.HeartbeatAt = &now
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status": job.Status, "attempts": job.Attempts, "started_at": now, "heartbeat_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// run executes a claimed job and stores its outcome.
+func (q *Queue) run(parent context.Context, job *Job) {
+	q.mu.RLock()
+	handler := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	ctx, cancel := context.WithCancel(parent)
+	q.mu.Lock()
+	q.running[job.ID] = cancel
+	q.mu.Unlock()
+	defer func() {
+		cancel()
+		q.mu.Lock()
+		delete(q.running, job.ID)
+		q.mu.Unlock()
+	}()
+
+	stopHeartbeat := q.heartbeat(job)
+	result, err := q.invoke(ctx, handler, job)
+	stopHeartbeat()
+
+	now := time.Now().UTC()
+	updates := map[string]interface{}{"finished_at": now}
+	switch {
+	case err != nil && parent.Err() != nil && !errors.Is(err, ErrCancelled):
+		// The worker is shutting down, e.g. for a deploy. The run doesn't count as an attempt; the job is
+		// picked up again by another replica, or by this one once it's back.
+		updates = map[string]interface{}{
+			"status": StatusPending, "attempts": job.Attempts - 1, "run_at": now,
+			"started_at": nil, "heartbeat_at": nil, "finished_at": nil,
+		}
+		log.Printf("Job queue: requeued job %s (%s) on shutdown", job.ID, job.Type)
+	case err == nil:
+		updates["status"] = StatusSucceeded
+		updates["progress"] = 100
+		if result != nil {
+			if data, encErr := json.Marshal(result); encErr == nil {
+				updates["result"] = data
+			}
+		}
+	case errors.Is(err, ErrCancelled) || errors.Is(err, context.Canceled):
+		updates["status"] = StatusCancelled
+	case job.Attempts < job.MaxAttempts:
+		// Retry with a simple linear backoff.
+		updates["status"] = StatusPending
+		updates["run_at"] = now.Add(time.Duration(job.Attempts) * 30 * time.Second)
+		updates["error"] = err.Error()
+		updates["finished_at"] = nil
+	default:
+		updates["status"] = StatusFailed
+		updates["error"] = err.Error()
+	}
+
+	stored := q.leased(job).Updates(updates)
+	if stored.Error != nil {
+		log.Printf("Job queue: failed to store outcome of job %s: %v", job.ID, stored.Error)
+	} else if stored.RowsAffected == 0 {
+		// The reaper took the job back while it ran, e.g. because the heartbeats couldn't reach the database
+		// for leaseTimeout; the outcome belongs to whichever run holds the job now.
+		log.Printf("Job queue: lost the lease of job %s (%s); discarded the outcome of this run", job.ID, job.Type)
+	}
+}
+
+// leased selects the job while the run holds its lease. The reaper bumps lost_runs when it requeues a job,
+// so the attempt and lost-run counts of the claim identify the run.
+func (q *Queue) leased(job *Job) *gorm.DB {
+	return q.db.Model(&Job{}).Where("id = ? AND status = ? AND attempts = ? AND lost_runs = ?",
+		job.ID, StatusRunning, job.Attempts, job.LostRuns)
+}
+
+// heartbeat renews the lease of a running job every heartbeatInterval until the returned function is called.
+func (q *Queue) heartbeat(job *Job) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := q.leased(job).Update("heartbeat_at", time.Now().UTC()).Error; err != nil {
+					log.Printf("Job queue: failed to renew the lease of job %s: %v", job.ID, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// reap requeues lost jobs every half leaseTimeout until ctx is done.
+func (q *Queue) reap(ctx context.Context) {
+	ticker := time.NewTicker(leaseTimeout / 2)
+	defer ticker.Stop()
+	for {
+		if err := q.reapLost(); err != nil {
+			log.Printf("Job queue: failed to requeue lost jobs: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reapLost requeues running jobs whose worker stopped renewing their lease, e.g. because its replica
+// crashed. Like a shutdown, a lost run doesn't count as an attempt; but a job lost maxLostRuns times is
+// failed, and one whose cancellation was requested is cancelled. Each update re-checks the status, so
+// replicas reaping at the same time don't requeue a job twice.
+func (q *Queue) reapLost() error {
+	now := time.Now().UTC()
+	cutoff := now.Add(-leaseTimeout)
+	lost := "status = ? AND COALESCE(heartbeat_at, started_at) < ?"
+	if err := q.db.Model(&Job{}).Where(lost+" AND cancel_requested", StatusRunning, cutoff).
+		Updates(map[string]interface{}{"status": StatusCancelled, "finished_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to cancel lost jobs: %w", err)
+	}
+	if err := q.db.Model(&Job{}).Where(lost+" AND lost_runs + 1 >= ?", StatusRunning, cutoff, maxLostRuns).
+		Updates(map[string]interface{}{
+			"status": StatusFailed, "lost_runs": gorm.Expr("lost_runs + 1"), "finished_at": now,
+			"error": fmt.Sprintf("the worker running the job stopped responding %d times", maxLostRuns),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to fail lost jobs: %w", err)
+	}
+	result := q.db.Model(&Job{}).Where(lost, StatusRunning, cutoff).Updates(map[string]interface{}{
+		"status": StatusPending, "attempts": gorm.Expr("attempts - 1"), "lost_runs": gorm.Expr("lost_runs + 1"),
+		"run_at": now, "started_at": nil, "heartbeat_at": nil,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to requeue lost jobs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Job queue: requeued %d jobs lost with their worker", result.RowsAffected)
+	}
+	return nil
+}
+
+// invoke calls the handler, converting panics into job failures.
+func (q *Queue) invoke(ctx context.Context, handler Handler, job *Job) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job, &reporter{db: q.db, jobID: job.ID})
+}
+
+// reporter implements Reporter for a running job.
+type reporter struct {
+	db    *gorm.DB
+	jobID string
+}
+
+func (r *reporter) SetProgress(progress int, message string) error {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 100 {
+		progress = 100
+	}
+	if err := r.db.Model(&Job{}).Where("id = ?", r.jobID).
+		Updates(map[string]interface{}{"progress": progress, "message": message}).Error; err != nil {
+		return fmt.Errorf("failed to report job progress: %w", err)
+	}
+
+	var job Job
+	if err := r.db.Select("cancel_requested").First(&job, "id = ?", r.jobID).Error; err != nil {
+		return fmt.Errorf("failed to check job cancellation: %w", err)
+	}
+	if job.CancelRequested {
+		return ErrCancelled
+	}
+	return nil
+}
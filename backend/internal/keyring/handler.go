@@ -0,0 +1,181 @@
+// prometheus/backend/internal/keyring/handler.go
+package keyring
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for JWT signing keys.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the signing keys, newest first. Secrets are never returned.
+// @Summary List signing keys
+// @Tags Signing keys
+// @Produce json
+// @Param status query string false "Status" Enums(active, retiring, revoked)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Router /admin/signing-keys [get]
+func (h *Handler) List(c *gin.Context) {
+	status := Status(c.Query("status"))
+	switch status {
+	case "", StatusActive, StatusRetiring, StatusRevoked:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	page := utils.ParsePagination(c)
+	keys, total, err := h.service.List(status, page)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Signing keys fetched successfully", page.Response(keys, total))
+}
+
+// Get returns a signing key.
+// @Summary Get a signing key
+// @Tags Signing keys
+// @Produce json
+// @Param id path int true "Signing key ID"
+// @Success 200 {object} Key
+// @Failure 404 {object} utils.ErrorResponse "Signing key not found"
+// @Router /admin/signing-keys/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	key, err := h.service.Get(id)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Signing key fetched successfully", key)
+}
+
+// Rotate creates a new active signing key.
+// @Summary Rotate the signing key
+// @Description New tokens are signed with the new key. The previous key keeps verifying the tokens it signed
+// @Description until they expire, then the scheduler revokes it.
+// @Tags Signing keys
+// @Produce json
+// @Success 201 {object} Key
+// @Router /admin/signing-keys/rotate [post]
+func (h *Handler) Rotate(c *gin.Context) {
+	key, err := h.service.Rotate(audit.ActorFromContext(c))
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Signing key rotated successfully", key)
+}
+
+// Revoke stops a signing key from verifying tokens.
+// @Summary Revoke a signing key
+// @Description Tokens signed with the key are rejected at once, logging their holders out. Revoking the active
+// @Description key rotates to a new one first. Other instances apply the revocation within 30 seconds.
+// @Tags Signing keys
+// @Accept json
+// @Produce json
+// @Param id path int true "Signing key ID"
+// @Param revocation body RevokeRequest true "Reason"
+// @Success 200 {object} Key
+// @Failure 404 {object} utils.ErrorResponse "Signing key not found"
+// @Failure 409 {object} utils.ErrorResponse "Already revoked"
+// @Router /admin/signing-keys/{id}/revoke [post]
+func (h *Handler) Revoke(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	key, err := h.service.Revoke(audit.ActorFromContext(c), id, req)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Signing key revoked successfully", key)
+}
+
+// Export returns the active and retiring signing keys for disaster recovery.
+// @Summary Export the signing keys
+// @Description The secrets are encrypted with a key derived from the passphrase, so the bundle can be restored
+// @Description into a deployment with another master key. Store the bundle and the passphrase apart.
+// @Tags Signing keys
+// @Accept json
+// @Produce json
+// @Param export body ExportRequest true "Passphrase"
+// @Success 200 {object} Bundle
+// @Router /admin/signing-keys/export [post]
+func (h *Handler) Export(c *gin.Context) {
+	var req ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	bundle, err := h.service.Export(audit.ActorFromContext(c), req)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Signing keys exported successfully", bundle)
+}
+
+// Import restores signing keys from an export.
+// @Summary Import signing keys
+// @Description Keys already present with the same secret are skipped. The bundle's active key becomes active
+// @Description unless a key is active here already, in which case it retires and only verifies tokens.
+// @Tags Signing keys
+// @Accept json
+// @Produce json
+// @Param import body ImportRequest true "Bundle from POST /admin/signing-keys/export and its passphrase"
+// @Success 200 {object} ImportResult
+// @Failure 400 {object} utils.ErrorResponse "Invalid bundle or wrong passphrase"
+// @Failure 409 {object} utils.ErrorResponse "Key ID exists with another secret"
+// @Router /admin/signing-keys/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	var req ImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	result, err := h.service.Import(audit.ActorFromContext(c), req)
+	if err != nil {
+		sendKeyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Signing keys imported successfully", result)
+}
+
+func sendKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidBundle), errors.Is(err, ErrPassphrase):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRevoked), errors.Is(err, ErrConflict):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,129 @@
+// prometheus/backend/internal/keyring/keyring.go
+package keyring
+
+import (
+	"log"
+	"prometheus/backend/config"
+	"prometheus/backend/internal/clock"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// refreshInterval is how long an instance trusts its copy of the keys. Changes made on this instance apply
+// at once; other instances pick them up within the interval.
+const refreshInterval = 30 * time.Second
+
+// Keyring holds the unsealed signing keys in memory and implements auth.SigningKeys. Until the first key
+// is created, tokens are signed with the legacy JWT_SECRET; afterwards the legacy secret still verifies
+// the tokens it signed until they expire.
+type Keyring struct {
+	db       *gorm.DB
+	sealer   *sealer
+	legacy   []byte
+	lifetime time.Duration
+
+	mu       sync.RWMutex
+	state    state
+	loadedAt time.Time
+}
+
+// state is the keys as last loaded.
+type state struct {
+	kid         string            // Of the active key; empty while none is
+	secrets     map[string][]byte // Active and retiring keys by key ID
+	legacyUntil time.Time         // Zero while no key was ever created
+}
+
+// New creates the keyring sealed with cfg.KeyMasterKey, falling back to cfg.JWTSecret as described above.
+func New(db *gorm.DB, cfg *config.Config) (*Keyring, error) {
+	sealer, err := masterSealer(cfg.KeyMasterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{db: db, sealer: sealer, legacy: []byte(cfg.JWTSecret), lifetime: tokenLifetime(cfg.JWTExpirationHours)}, nil
+}
+
+// SigningKey implements auth.SigningKeys.
+func (k *Keyring) SigningKey() (string, []byte) {
+	st := k.current()
+	if st.kid == "" {
+		return "", k.legacy
+	}
+	return st.kid, st.secrets[st.kid]
+}
+
+// VerificationKey implements auth.SigningKeys.
+func (k *Keyring) VerificationKey(kid string) ([]byte, bool) {
+	st := k.current()
+	if kid == "" {
+		if st.kid == "" || clock.Now().Before(st.legacyUntil) {
+			return k.legacy, true
+		}
+		return nil, false
+	}
+	secret, ok := st.secrets[kid]
+	return secret, ok
+}
+
+// current returns the keys, reloading them once they are older than refreshInterval. A failed reload
+// keeps the previous keys, so a database hiccup doesn't log everyone out.
+func (k *Keyring) current() state {
+	k.mu.RLock()
+	st, fresh := k.state, time.Since(k.loadedAt) < refreshInterval
+	k.mu.RUnlock()
+	if fresh {
+		return st
+	}
+	if err := k.Reload(); err != nil {
+		log.Printf("Failed to reload signing keys, keeping the previous ones: %v", err)
+		k.mu.Lock()
+		k.loadedAt = time.Now()
+		k.mu.Unlock()
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.state
+}
+
+// Reload loads and unseals the keys that still verify tokens.
+func (k *Keyring) Reload() error {
+	var keys []Key
+	if err := k.db.Where("status IN ?", []Status{StatusActive, StatusRetiring}).Find(&keys).Error; err != nil {
+		return err
+	}
+	var first Key
+	err := k.db.Select("created_at").Order("created_at").Limit(1).Find(&first).Error
+	if err != nil {
+		return err
+	}
+	st := state{secrets: make(map[string][]byte, len(keys))}
+	if !first.CreatedAt.IsZero() {
+		st.legacyUntil = first.CreatedAt.Add(k.lifetime)
+	}
+	for _, key := range keys {
+		secret, err := k.sealer.open(key.Sealed, key.KID)
+		if err != nil {
+			// Sealed with another master key, e.g. copied from another environment; it can't verify anything here.
+			log.Printf("Failed to unseal signing key %s: %v", key.KID, err)
+			continue
+		}
+		st.secrets[key.KID] = secret
+		if key.Status == StatusActive {
+			st.kid = key.KID
+		}
+	}
+	k.mu.Lock()
+	k.state, k.loadedAt = st, time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// tokenLifetime is how long tokens are valid with JWT_EXPIRATION_HOURS, defaulting like auth does.
+func tokenLifetime(hours int) time.Duration {
+	if hours == 0 {
+		return 24 * 7 * time.Hour
+	}
+	return time.Duration(hours) * time.Hour
+}
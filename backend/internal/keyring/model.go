@@ -0,0 +1,83 @@
+// prometheus/backend/internal/keyring/model.go
+package keyring
+
+import (
+	"time"
+)
+
+// Status is where a signing key is in its lifecycle.
+type Status string
+
+const (
+	StatusActive   Status = "active"   // Signs new tokens and verifies them; one key at a time
+	StatusRetiring Status = "retiring" // Replaced by a newer key; verifies the tokens it signed until they expire
+	StatusRevoked  Status = "revoked"  // Verifies nothing; final
+)
+
+// Key is a JWT signing key. Its secret is stored sealed with the deployment's master key (KEY_MASTER_KEY),
+// so a database dump alone can't forge tokens.
+type Key struct {
+	ID           uint       `gorm:"primaryKey" json:"id" example:"3"`
+	KID          string     `gorm:"column:kid;type:varchar(32);not null;uniqueIndex" json:"kid" example:"6f1c2a9be04d7731"` // "kid" header of the tokens it signs
+	Algorithm    string     `gorm:"type:varchar(10);not null" json:"algorithm" example:"HS256"`
+	Status       Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"active"`
+	Sealed       []byte     `gorm:"not null" json:"-"`                                                       // The secret sealed with the master key, see sealer
+	Fingerprint  string     `gorm:"type:varchar(64);not null" json:"fingerprint" example:"9f2c41d0e8b7a6c5"` // SHA-256 of the secret; tells keys apart across exports without revealing them
+	CreatedBy    *uint      `json:"created_by,omitempty" example:"1"`                                        // User ID
+	ImportedAt   *time.Time `json:"imported_at,omitempty"`                                                   // Restored from an export
+	RetiringAt   *time.Time `json:"retiring_at,omitempty"`
+	RetireUntil  *time.Time `json:"retire_until,omitempty"` // Revoked by the scheduler once the last token it signed expired
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    *uint      `json:"revoked_by,omitempty" example:"1"` // User ID; nil when retired by the scheduler
+	RevokeReason string     `gorm:"type:varchar(500)" json:"revoke_reason,omitempty" example:"Leaked in a support ticket"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName keeps signing keys apart from API keys.
+func (Key) TableName() string { return "signing_keys" }
+
+// RevokeRequest revokes a signing key. Revoking the active key rotates to a new one first.
+type RevokeRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Leaked in a support ticket"`
+}
+
+// ExportRequest exports the signing keys for disaster recovery.
+type ExportRequest struct {
+	Passphrase string `json:"passphrase" binding:"required,min=16,max=200"` // Encrypts the exported secrets; keep it apart from the export
+}
+
+// Bundle is an export of the signing keys that still verify tokens. The secrets are sealed with a key
+// derived from the export passphrase, not with the master key, so the bundle restores into a deployment
+// with a different master key.
+type Bundle struct {
+	FormatVersion int           `json:"format_version" example:"1"`
+	Environment   string        `json:"environment" example:"production"` // APP_ENV of the exporting deployment
+	ExportedAt    time.Time     `json:"exported_at"`
+	Salt          []byte        `json:"salt" swaggertype:"string" example:"q83vEjRWeJA="` // Of the scrypt key derivation
+	Keys          []ExportedKey `json:"keys"`
+}
+
+// ExportedKey is a signing key in a Bundle.
+type ExportedKey struct {
+	KID         string     `json:"kid" example:"6f1c2a9be04d7731"`
+	Algorithm   string     `json:"algorithm" example:"HS256"`
+	Status      Status     `json:"status" example:"active"`
+	Fingerprint string     `json:"fingerprint" example:"9f2c41d0e8b7a6c5"`
+	RetireUntil *time.Time `json:"retire_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Secret      []byte     `json:"secret" swaggertype:"string"` // Sealed with the passphrase
+}
+
+// ImportRequest restores signing keys from a Bundle.
+type ImportRequest struct {
+	Bundle     Bundle `json:"bundle" binding:"required"`
+	Passphrase string `json:"passphrase" binding:"required,max=200"`
+}
+
+// ImportResult lists what an import restored. Keys already present with the same secret are skipped.
+type ImportResult struct {
+	Imported []string `json:"imported" example:"6f1c2a9be04d7731"` // Key IDs
+	Skipped  []string `json:"skipped"`
+	Active   string   `json:"active,omitempty" example:"6f1c2a9be04d7731"` // Key ID signing tokens after the import
+}
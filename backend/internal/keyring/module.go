@@ -0,0 +1,78 @@
+// prometheus/backend/internal/keyring/module.go
+package keyring
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+)
+
+// ModuleName is the name of the signing keys module.
+const ModuleName = "signing-keys"
+
+// retireInterval is how often retiring keys past their tokens' expiry are revoked.
+const retireInterval = time.Hour
+
+// keyringModule owns the JWT signing keys and their lifecycle.
+type keyringModule struct {
+	ring    *Keyring
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the signing keys module for the module registry.
+func NewModule(ring *Keyring, svc Service) module.Module {
+	return &keyringModule{ring: ring, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *keyringModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. Signing with the legacy JWT_SECRET means no key was created
+// yet, or the active one can't be unsealed with this instance's master key.
+func (m *keyringModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("signing", func(ctx context.Context) module.HealthResult {
+			if err := m.ring.Reload(); err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			kid, _ := m.ring.SigningKey()
+			if kid == "" {
+				return module.HealthResult{Status: module.StatusDegraded, Error: "signing with the legacy JWT_SECRET"}
+			}
+			return module.HealthResult{Status: module.StatusUp, Metrics: map[string]float64{
+				"verification_keys": float64(len(m.ring.current().secrets)),
+			}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *keyringModule) Models() []any {
+	return []any{&Key{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *keyringModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobRetire, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		retired, err := m.service.Retire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"retired": retired}, nil
+	})
+	q.Every(JobRetire, retireInterval)
+}
+
+// RegisterRoutes implements routing.Contributor. Keys belong to the deployment, so only platform
+// administrators manage them.
+func (m *keyringModule) RegisterRoutes(api *routing.Group) {
+	godAdmin := routing.Roles("god-admin")
+	api.GET("/admin/signing-keys", godAdmin, m.handler.List)
+	api.GET("/admin/signing-keys/:id", godAdmin, m.handler.Get)
+	api.POST("/admin/signing-keys/rotate", godAdmin, m.handler.Rotate)
+	api.POST("/admin/signing-keys/:id/revoke", godAdmin, m.handler.Revoke)
+	api.POST("/admin/signing-keys/export", godAdmin, m.handler.Export)
+	api.POST("/admin/signing-keys/import", godAdmin, m.handler.Import)
+}
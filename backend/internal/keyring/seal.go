@@ -0,0 +1,96 @@
+// prometheus/backend/internal/keyring/seal.go
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// secretSize is the size of generated signing secrets, and of the keys sealing them (AES-256).
+const secretSize = 32
+
+// scrypt parameters deriving the key of an export from its passphrase.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// sealer encrypts secrets with AES-256-GCM. A sealed secret is the nonce followed by the ciphertext; the
+// key ID is authenticated along, so sealed secrets can't be swapped between keys.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) != secretSize {
+		return nil, fmt.Errorf("the sealing key must be %d bytes, got %d", secretSize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// masterSealer parses KEY_MASTER_KEY, the base64 of 32 random bytes (e.g. `openssl rand -base64 32`).
+func masterSealer(encoded string) (*sealer, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("KEY_MASTER_KEY is not valid base64: %w", err)
+	}
+	return newSealer(key)
+}
+
+// passphraseSealer derives the sealer of an export from its passphrase and salt.
+func passphraseSealer(passphrase string, salt []byte) (*sealer, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, secretSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the export key: %w", err)
+	}
+	return newSealer(key)
+}
+
+func (s *sealer) seal(secret []byte, kid string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, secret, []byte(kid)), nil
+}
+
+// open fails for secrets sealed with another key or for another key ID, and for tampered ones.
+func (s *sealer) open(sealed []byte, kid string) ([]byte, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("sealed secret too short")
+	}
+	return s.aead.Open(nil, sealed[:size], sealed[size:], []byte(kid))
+}
+
+// randomBytes returns n bytes from the system's secure random source.
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return buf, nil
+}
+
+// fingerprint identifies a secret without revealing it.
+func fingerprint(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:])
+}
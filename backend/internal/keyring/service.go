@@ -0,0 +1,397 @@
+// prometheus/backend/internal/keyring/service.go
+package keyring
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// JobRetire revokes retiring keys once the last token they signed expired.
+const JobRetire = "keyring.retire"
+
+// bundleFormatVersion is bumped whenever the bundle layout changes incompatibly.
+const bundleFormatVersion = 1
+
+// algorithm is the JWT algorithm of every key.
+const algorithm = "HS256"
+
+// lockName serializes changes to the key lifecycle across instances, so there's one active key at a time.
+const lockName = "keyring"
+
+var (
+	// ErrRevoked is returned when revoking a key that was already revoked.
+	ErrRevoked = errors.New("the signing key was already revoked")
+	// ErrInvalidBundle is returned for key bundles that can't be decoded or contain inconsistent keys.
+	ErrInvalidBundle = errors.New("invalid key bundle")
+	// ErrPassphrase is returned when the passphrase doesn't open a key bundle.
+	ErrPassphrase = errors.New("the passphrase doesn't open the key bundle")
+	// ErrConflict is returned when a bundle holds a key ID that exists here with another secret.
+	ErrConflict = errors.New("a signing key with the same ID but another secret exists")
+)
+
+// Service manages the lifecycle of the JWT signing keys: rotating to a new active key, retiring the
+// previous one once its tokens expired, revoking compromised keys, and exporting and importing keys for
+// disaster recovery. Keys belong to the deployment, not to an organization.
+type Service interface {
+	// List returns the keys, newest first.
+	List(status Status, page utils.Pagination) ([]Key, int64, error)
+	Get(id uint) (*Key, error)
+	// Rotate creates a new active key. The previous active key retires: it keeps verifying the tokens it
+	// signed until they expire.
+	Rotate(actor audit.Actor) (*Key, error)
+	// Revoke stops a key from verifying tokens at once, logging out everyone holding one it signed. Revoking
+	// the active key rotates to a new one in the same transaction.
+	Revoke(actor audit.Actor, id uint, req RevokeRequest) (*Key, error)
+	// Export returns the active and retiring keys sealed with a passphrase.
+	Export(actor audit.Actor, req ExportRequest) (*Bundle, error)
+	// Import restores the keys of a bundle that aren't here yet. An imported active key becomes active
+	// unless a key is active here already, in which case it retires.
+	Import(actor audit.Actor, req ImportRequest) (*ImportResult, error)
+	// Retire revokes the retiring keys past their retire_until, returning how many.
+	Retire(ctx context.Context) (int, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db          *gorm.DB
+	ring        *Keyring
+	auditor     audit.Service
+	environment string
+}
+
+// NewService creates a new instance of Service. Changes are applied to ring at once; environment is the
+// APP_ENV recorded in exports.
+func NewService(db *gorm.DB, ring *Keyring, auditor audit.Service, environment string) Service {
+	return &service{db: db, ring: ring, auditor: auditor, environment: environment}
+}
+
+func (s *service) List(status Status, page utils.Pagination) ([]Key, int64, error) {
+	query := s.db.Model(&Key{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count signing keys: %w", err)
+	}
+	keys := []Key{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&keys).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	return keys, total, nil
+}
+
+func (s *service) Get(id uint) (*Key, error) {
+	var key Key
+	if err := s.db.First(&key, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &key, nil
+}
+
+func (s *service) Rotate(actor audit.Actor) (*Key, error) {
+	var created *Key
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, lockName); err != nil {
+			return err
+		}
+		var err error
+		created, err = s.rotate(tx, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reload()
+	return created, nil
+}
+
+func (s *service) Revoke(actor audit.Actor, id uint, req RevokeRequest) (*Key, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, lockName); err != nil {
+			return err
+		}
+		var key Key
+		if err := tx.First(&key, id).Error; err != nil {
+			return err
+		}
+		if key.Status == StatusRevoked {
+			return ErrRevoked
+		}
+		if key.Status == StatusActive {
+			if _, err := s.rotate(tx, actor); err != nil {
+				return err
+			}
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Key{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":        StatusRevoked,
+			"revoked_at":    now,
+			"revoked_by":    actor.UserID,
+			"revoke_reason": strings.TrimSpace(req.Reason),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to revoke signing key %s: %w", key.KID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "signing_key.revoke", EntityType: "signing_key", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"kid": key.KID, "status": key.Status},
+			After:  map[string]interface{}{"kid": key.KID, "status": StatusRevoked, "reason": req.Reason},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reload()
+	return s.Get(id)
+}
+
+func (s *service) Export(actor audit.Actor, req ExportRequest) (*Bundle, error) {
+	var keys []Key
+	if err := s.db.Where("status IN ?", []Status{StatusActive, StatusRetiring}).Order("created_at, id").
+		Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	salt, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	export, err := passphraseSealer(req.Passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		FormatVersion: bundleFormatVersion,
+		Environment:   s.environment,
+		ExportedAt:    clock.Now().UTC(),
+		Salt:          salt,
+		Keys:          make([]ExportedKey, 0, len(keys)),
+	}
+	kids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		secret, err := s.ring.sealer.open(key.Sealed, key.KID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal signing key %s: %w", key.KID, err)
+		}
+		sealed, err := export.seal(secret, key.KID)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Keys = append(bundle.Keys, ExportedKey{
+			KID: key.KID, Algorithm: key.Algorithm, Status: key.Status, Fingerprint: key.Fingerprint,
+			RetireUntil: key.RetireUntil, CreatedAt: key.CreatedAt, Secret: sealed,
+		})
+		kids = append(kids, key.KID)
+	}
+	// Exports hold every key able to sign tokens, so each one is on record.
+	if err := s.auditor.Record(actor, audit.Entry{
+		Action: "signing_key.export", EntityType: "signing_key", EntityID: "bundle",
+		After: map[string]interface{}{"kids": kids},
+	}); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (s *service) Import(actor audit.Actor, req ImportRequest) (*ImportResult, error) {
+	bundle := req.Bundle
+	if bundle.FormatVersion != bundleFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, bundle.FormatVersion)
+	}
+	opener, err := passphraseSealer(req.Passphrase, bundle.Salt)
+	if err != nil {
+		return nil, err
+	}
+	// Unsealed before touching the database: a wrong passphrase or a tampered key fails the whole import.
+	secrets := make(map[string][]byte, len(bundle.Keys))
+	actives := 0
+	for _, exported := range bundle.Keys {
+		if exported.KID == "" || len(exported.KID) > 32 || exported.Algorithm != algorithm {
+			return nil, fmt.Errorf("%w: key %q", ErrInvalidBundle, exported.KID)
+		}
+		if exported.Status != StatusActive && exported.Status != StatusRetiring {
+			return nil, fmt.Errorf("%w: key %s is %s", ErrInvalidBundle, exported.KID, exported.Status)
+		}
+		if _, dup := secrets[exported.KID]; dup {
+			return nil, fmt.Errorf("%w: key %s appears twice", ErrInvalidBundle, exported.KID)
+		}
+		secret, err := opener.open(exported.Secret, exported.KID)
+		if err != nil {
+			return nil, ErrPassphrase
+		}
+		if fingerprint(secret) != exported.Fingerprint {
+			return nil, fmt.Errorf("%w: key %s doesn't match its fingerprint", ErrInvalidBundle, exported.KID)
+		}
+		secrets[exported.KID] = secret
+		if exported.Status == StatusActive {
+			actives++
+		}
+	}
+	if actives > 1 {
+		return nil, fmt.Errorf("%w: more than one active key", ErrInvalidBundle)
+	}
+
+	result := &ImportResult{Imported: []string{}, Skipped: []string{}}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, lockName); err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&Key{}).Where("status = ?", StatusActive).Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to check the active key: %w", err)
+		}
+		now := clock.Now().UTC()
+		retireUntil := now.Add(s.ring.lifetime)
+		for _, exported := range bundle.Keys {
+			var existing Key
+			err := tx.Where("kid = ?", exported.KID).First(&existing).Error
+			if err == nil {
+				if existing.Fingerprint != exported.Fingerprint {
+					return fmt.Errorf("%w: %s", ErrConflict, exported.KID)
+				}
+				result.Skipped = append(result.Skipped, exported.KID)
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to load signing key %s: %w", exported.KID, err)
+			}
+			sealed, err := s.ring.sealer.seal(secrets[exported.KID], exported.KID)
+			if err != nil {
+				return err
+			}
+			key := Key{
+				KID: exported.KID, Algorithm: exported.Algorithm, Status: exported.Status, Sealed: sealed,
+				Fingerprint: exported.Fingerprint, CreatedBy: actor.UserID, ImportedAt: &now,
+				RetireUntil: exported.RetireUntil, CreatedAt: exported.CreatedAt,
+			}
+			if key.Status == StatusActive && active > 0 {
+				key.Status, key.RetiringAt, key.RetireUntil = StatusRetiring, &now, &retireUntil
+			}
+			if key.Status == StatusRetiring && key.RetireUntil == nil {
+				key.RetireUntil = &retireUntil
+			}
+			if err := tx.Create(&key).Error; err != nil {
+				return fmt.Errorf("failed to import signing key %s: %w", key.KID, err)
+			}
+			result.Imported = append(result.Imported, key.KID)
+		}
+		var current Key
+		if err := tx.Where("status = ?", StatusActive).Limit(1).Find(&current).Error; err != nil {
+			return fmt.Errorf("failed to load the active key: %w", err)
+		}
+		result.Active = current.KID
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "signing_key.import", EntityType: "signing_key", EntityID: "bundle",
+			After: map[string]interface{}{
+				"environment": bundle.Environment, "exported_at": bundle.ExportedAt,
+				"imported": result.Imported, "skipped": result.Skipped, "active": result.Active,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reload()
+	return result, nil
+}
+
+func (s *service) Retire(ctx context.Context) (int, error) {
+	var retired int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, lockName); err != nil {
+			return err
+		}
+		var due []Key
+		if err := tx.Where("status = ? AND retire_until <= ?", StatusRetiring, clock.Now().UTC()).Find(&due).Error; err != nil {
+			return fmt.Errorf("failed to find retiring keys: %w", err)
+		}
+		for _, key := range due {
+			if err := tx.Model(&Key{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+				"status":        StatusRevoked,
+				"revoked_at":    clock.Now().UTC(),
+				"revoke_reason": "Retired after its last token expired",
+			}).Error; err != nil {
+				return fmt.Errorf("failed to retire signing key %s: %w", key.KID, err)
+			}
+			if err := s.auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+				Action: "signing_key.retire", EntityType: "signing_key", EntityID: fmt.Sprintf("%d", key.ID),
+				Before: map[string]interface{}{"kid": key.KID, "status": key.Status},
+				After:  map[string]interface{}{"kid": key.KID, "status": StatusRevoked},
+			}); err != nil {
+				return err
+			}
+			retired++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if retired > 0 {
+		s.reload()
+	}
+	return retired, nil
+}
+
+// rotate creates a new active key within tx and retires the previous one. The caller holds the lock.
+func (s *service) rotate(tx *gorm.DB, actor audit.Actor) (*Key, error) {
+	secret, err := randomBytes(secretSize)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomBytes(8)
+	if err != nil {
+		return nil, err
+	}
+	kid := hex.EncodeToString(id)
+	sealed, err := s.ring.sealer.seal(secret, kid)
+	if err != nil {
+		return nil, err
+	}
+	now := clock.Now().UTC()
+	var previous []Key
+	if err := tx.Where("status = ?", StatusActive).Find(&previous).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the active key: %w", err)
+	}
+	if len(previous) > 0 {
+		if err := tx.Model(&Key{}).Where("status = ?", StatusActive).Updates(map[string]interface{}{
+			"status":       StatusRetiring,
+			"retiring_at":  now,
+			"retire_until": now.Add(s.ring.lifetime),
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to retire the active key: %w", err)
+		}
+	}
+	key := Key{KID: kid, Algorithm: algorithm, Status: StatusActive, Sealed: sealed, Fingerprint: fingerprint(secret), CreatedBy: actor.UserID}
+	if err := tx.Create(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	retiring := make([]string, 0, len(previous))
+	for _, p := range previous {
+		retiring = append(retiring, p.KID)
+	}
+	if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "signing_key.rotate", EntityType: "signing_key", EntityID: fmt.Sprintf("%d", key.ID),
+		After: map[string]interface{}{"kid": key.KID, "fingerprint": key.Fingerprint, "retiring": retiring},
+	}); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// reload applies a committed change to this instance's keyring; other instances pick it up within
+// refreshInterval.
+func (s *service) reload() {
+	if err := s.ring.Reload(); err != nil {
+		log.Printf("Failed to reload signing keys: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// prometheus/backend/internal/legacy/handler.go
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for history imports.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Import imports employment history from a legacy system's CSV export.
+// @Summary Import employment history from CSV
+// @Description Columns (header row required) by kind; employees are matched by employee_number, amounts are
+// @Description annual in minor units and dates YYYY-MM-DD, kept as in the file.
+// @Description salary: employee_number, effective_on, amount, currency, reason (optional).
+// @Description leave: employee_number, start_on, end_on, leave_type, days, paid (default true), note (the last three optional).
+// @Description review: employee_number, reviewed_on, rating (1-5), reviewer_number, reviewer, title, summary (all but the first two optional).
+// @Description Rows dated in the future or before the employee's hire date fail; rows already on record are
+// @Description skipped as duplicates, so a file can be imported again after fixing it. Use ?dry_run=true to
+// @Description validate and reconcile without storing anything.
+// @Tags History import
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param kind query string true "What the file holds" Enums(salary, leave, review)
+// @Param file formData file false "CSV file (multipart upload)"
+// @Param dry_run query bool false "Validate without storing"
+// @Success 200 {object} Report
+// @Failure 400 {object} utils.ErrorResponse "Unreadable CSV or unknown kind"
+// @Failure 409 {object} utils.ErrorResponse "Another import into the organization is running"
+// @Router /hr/history-imports [post]
+func (h *Handler) Import(c *gin.Context) {
+	kind := Kind(c.Query("kind"))
+	body, fileName := io.Reader(c.Request.Body), ""
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Missing CSV file in form field \"file\"")
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Failed to read the uploaded file")
+			return
+		}
+		defer f.Close()
+		body, fileName = f, file.Filename
+	}
+
+	rows, err := ParseCSV(kind, body)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	report, err := h.service.Import(audit.ActorFromContext(c), utils.OrganizationFromContext(c), kind, fileName, rows, utils.IsDryRun(c))
+	if err != nil {
+		sendLegacyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, fmt.Sprintf("%d rows imported, %d duplicates skipped, %d rows failed",
+		report.Batch.Imported, report.Batch.Duplicates, report.Batch.Failed), report)
+}
+
+// ListImports returns past history imports.
+// @Summary List history imports
+// @Tags History import
+// @Produce json
+// @Success 200 {array} Batch
+// @Router /hr/history-imports [get]
+func (h *Handler) ListImports(c *gin.Context) {
+	batches, err := h.service.Batches(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "History imports fetched successfully", batches)
+}
+
+// GetReport returns a history import's reconciliation report.
+// @Summary Get a history import's reconciliation report
+// @Description Row results as imported; the reconciliation compares them with the employees as they are now.
+// @Tags History import
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} Report
+// @Failure 404 {object} utils.ErrorResponse "Import not found"
+// @Router /hr/history-imports/{id} [get]
+func (h *Handler) GetReport(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	report, err := h.service.Report(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendLegacyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "History import fetched successfully", report)
+}
+
+// EmployeeHistory returns an employee's imported leave and reviews.
+// @Summary Get an employee's imported history
+// @Description Imported salaries are part of the salary history.
+// @Tags History import
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} History
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Router /hr/employees/{id}/imported-history [get]
+func (h *Handler) EmployeeHistory(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	history, err := h.service.History(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendLegacyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Imported history fetched successfully", history)
+}
+
+// sendLegacyError maps service errors to HTTP status codes.
+func sendLegacyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidKind):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, lock.ErrLocked):
+		utils.SendErrorResponse(c, http.StatusConflict, "Another history import into the organization is running")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
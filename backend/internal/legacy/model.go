@@ -0,0 +1,124 @@
+// prometheus/backend/internal/legacy/model.go
+package legacy
+
+import (
+	"time"
+)
+
+// Kind is what a history import brings in.
+type Kind string
+
+const (
+	KindSalary Kind = "salary" // Into the salary history, see compensation.SalaryRecord
+	KindLeave  Kind = "leave"  // Past leave, kept as LeaveRecord
+	KindReview Kind = "review" // Past performance reviews, kept as ReviewRecord
+)
+
+// RowStatus is the outcome of one row of an import.
+type RowStatus string
+
+const (
+	RowImported  RowStatus = "imported"
+	RowDuplicate RowStatus = "duplicate" // Already on record, e.g. from an earlier import of the same file
+	RowFailed    RowStatus = "failed"
+)
+
+// Batch is one history import: a CSV file of one kind from the legacy system.
+type Batch struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Kind           Kind      `gorm:"type:varchar(20);not null" json:"kind" example:"salary"`
+	FileName       string    `gorm:"type:varchar(255)" json:"file_name,omitempty" example:"salaries-2015-2025.csv"`
+	Rows           int       `gorm:"not null" json:"rows" example:"1250"`
+	Imported       int       `gorm:"not null" json:"imported" example:"1236"`
+	Duplicates     int       `gorm:"not null" json:"duplicates" example:"10"`
+	Failed         int       `gorm:"not null" json:"failed" example:"4"`
+	ImportedBy     *uint     `json:"imported_by,omitempty" example:"7"` // User ID
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName names batches after what they import.
+func (Batch) TableName() string { return "history_import_batches" }
+
+// RowResult is the outcome of one row of a batch, kept for its reconciliation report.
+type RowResult struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	BatchID        uint      `gorm:"not null;index" json:"-"`
+	Line           int       `gorm:"not null" json:"line" example:"2"` // Line in the CSV file, header = 1
+	EmployeeNumber string    `gorm:"type:varchar(50)" json:"employee_number" example:"E-1042"`
+	EmployeeID     *uint     `json:"employee_id,omitempty" example:"12"` // Matched by employee number
+	Date           string    `gorm:"type:varchar(10)" json:"date,omitempty" example:"2019-04-01"`
+	Status         RowStatus `gorm:"type:varchar(20);not null" json:"status" example:"imported"`
+	RecordID       *uint     `json:"record_id,omitempty" example:"311"` // Of the record created, or the one it duplicates
+	Error          string    `gorm:"type:varchar(500)" json:"error,omitempty"`
+}
+
+// TableName keeps rows with their batches.
+func (RowResult) TableName() string { return "history_import_rows" }
+
+// LeaveRecord is leave taken before the organization moved to Prometheus, as the legacy system recorded it.
+type LeaveRecord struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"311"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	BatchID        uint      `gorm:"not null;index" json:"batch_id" example:"4"`
+	LeaveType      string    `gorm:"type:varchar(50);not null" json:"leave_type" example:"annual"`
+	StartOn        time.Time `gorm:"type:date;not null" json:"start_on" example:"2019-08-05T00:00:00Z"`
+	EndOn          time.Time `gorm:"type:date;not null" json:"end_on" example:"2019-08-16T00:00:00Z"`
+	Days           *float64  `json:"days,omitempty" example:"10"` // As the legacy system counted them; half days allowed
+	Paid           bool      `gorm:"not null" json:"paid"`
+	Note           string    `gorm:"type:varchar(500)" json:"note,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName marks leave records as imported history.
+func (LeaveRecord) TableName() string { return "history_leave" }
+
+// ReviewRecord is a performance review held before the organization moved to Prometheus.
+type ReviewRecord struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"87"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	BatchID        uint      `gorm:"not null;index" json:"batch_id" example:"5"`
+	ReviewedOn     time.Time `gorm:"type:date;not null" json:"reviewed_on" example:"2020-12-15T00:00:00Z"`
+	Title          string    `gorm:"type:varchar(150)" json:"title,omitempty" example:"2020 annual review"`
+	Rating         *float64  `json:"rating,omitempty" example:"4"`                // 1 to 5, like talent review scores
+	ReviewerID     *uint     `json:"reviewer_id,omitempty" example:"3"`           // Employee ID, when the reviewer is still employed
+	Reviewer       string    `gorm:"type:varchar(150)" json:"reviewer,omitempty"` // As the file named them
+	Summary        string    `gorm:"type:text" json:"summary,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName marks review records as imported history.
+func (ReviewRecord) TableName() string { return "history_reviews" }
+
+// Row is one CSV row of an import, by lower-case column name.
+type Row struct {
+	Line   int
+	Fields map[string]string
+}
+
+// Reconciliation compares an import with the organization's current employees, so HR can tell whether
+// the legacy history came over complete.
+type Reconciliation struct {
+	Employees        int      `json:"employees" example:"212"`                    // Matched by at least one row
+	UnknownEmployees []string `json:"unknown_employees" example:"E-0007,E-0019"`  // Employee numbers matching no current employee
+	WithoutHistory   []string `json:"without_history" example:"E-1101"`           // Current employees with no record of the kind at all
+	EarliestOn       string   `json:"earliest_on,omitempty" example:"2012-01-01"` // Of the rows imported
+	LatestOn         string   `json:"latest_on,omitempty" example:"2025-12-01"`   // Of the rows imported
+	DuplicateLines   []int    `json:"duplicate_lines" example:"14,15"`            // Rows already on record
+}
+
+// Report is an import's outcome: the batch, each row's result and the reconciliation.
+type Report struct {
+	Batch          Batch          `json:"batch"`
+	DryRun         bool           `json:"dry_run"` // Nothing was stored; Imported counts what would be
+	Rows           []RowResult    `json:"rows"`
+	Reconciliation Reconciliation `json:"reconciliation"`
+}
+
+// History is an employee's imported leave and reviews. Imported salaries are in their salary history.
+type History struct {
+	Leave   []LeaveRecord  `json:"leave"`
+	Reviews []ReviewRecord `json:"reviews"`
+}
@@ -0,0 +1,39 @@
+// prometheus/backend/internal/legacy/module.go
+package legacy
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/middleware"
+)
+
+// ModuleName is the name of the history import module.
+const ModuleName = "history-import"
+
+// legacyModule owns employment history brought over from legacy HR systems.
+type legacyModule struct {
+	handler *Handler
+}
+
+// NewModule creates the history import module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &legacyModule{handler: NewHandler(svc)}
+}
+
+func (m *legacyModule) Name() string { return ModuleName }
+
+func (m *legacyModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *legacyModule) Models() []any {
+	return []any{&Batch{}, &RowResult{}, &LeaveRecord{}, &ReviewRecord{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Imports are for HR migrating an organization, so they are
+// available on every plan.
+func (m *legacyModule) RegisterRoutes(api *routing.Group) {
+	api.POST("/hr/history-imports", routing.Policy(), middleware.DryRunMiddleware(), m.handler.Import)
+	api.GET("/hr/history-imports", routing.Policy(), m.handler.ListImports)
+	api.GET("/hr/history-imports/:id", routing.Policy(), m.handler.GetReport)
+	api.GET("/hr/employees/:id/imported-history", routing.Policy(), m.handler.EmployeeHistory)
+}
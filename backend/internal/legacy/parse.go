@@ -0,0 +1,76 @@
+// prometheus/backend/internal/legacy/parse.go
+package legacy
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxImportRows bounds a single history import; larger files should be split, e.g. by year.
+const maxImportRows = 20000
+
+// ErrInvalidImportFile is returned when the CSV can't be read or lacks the columns its kind requires.
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// columns lists the columns each kind reads; the required ones come first.
+var columns = map[Kind]struct{ required, optional []string }{
+	KindSalary: {[]string{"employee_number", "effective_on", "amount", "currency"}, []string{"reason"}},
+	KindLeave:  {[]string{"employee_number", "start_on", "end_on", "leave_type"}, []string{"days", "paid", "note"}},
+	KindReview: {[]string{"employee_number", "reviewed_on"}, []string{"rating", "reviewer_number", "reviewer", "title", "summary"}},
+}
+
+// ParseCSV reads a CSV with a header row naming the kind's columns, in any order; unknown columns are
+// ignored, so legacy exports need little editing.
+func ParseCSV(kind Kind, r io.Reader) ([]Row, error) {
+	spec, ok := columns[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidImportFile, kind)
+	}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range spec.required {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidImportFile, required)
+		}
+	}
+
+	names := append(append([]string{}, spec.required...), spec.optional...)
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImportFile, maxImportRows)
+		}
+		row := Row{Line: line, Fields: make(map[string]string)}
+		for _, name := range names {
+			if i, ok := index[name]; ok && i < len(record) {
+				row.Fields[name] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidImportFile)
+	}
+	return rows, nil
+}
@@ -0,0 +1,436 @@
+// prometheus/backend/internal/legacy/service.go
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidKind is returned for an import kind other than salary, leave or review.
+var ErrInvalidKind = errors.New("unknown import kind; expected salary, leave or review")
+
+// Service imports employment history from legacy HR systems and reconciles it with current employees.
+// orgID scopes every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	// Import validates every row against the organization's current employees and stores the valid ones,
+	// dated as in the file. Rows are independent: a failing row is reported and doesn't stop the others,
+	// and rows already on record are skipped, so a file can be imported again after fixing its failures.
+	// With dryRun, nothing is stored.
+	Import(actor audit.Actor, orgID *uint, kind Kind, fileName string, rows []Row, dryRun bool) (*Report, error)
+	// Batches lists past imports, latest first.
+	Batches(orgID *uint) ([]Batch, error)
+	// Report returns a past import's rows and its reconciliation against the employees as they are now.
+	Report(orgID *uint, id uint) (*Report, error)
+	// History returns an employee's imported leave and reviews, latest first.
+	History(orgID *uint, employeeID uint) (*History, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+func (s *service) Import(actor audit.Actor, orgID *uint, kind Kind, fileName string, rows []Row, dryRun bool) (*Report, error) {
+	if _, ok := columns[kind]; !ok {
+		return nil, ErrInvalidKind
+	}
+	report := &Report{DryRun: dryRun, Rows: make([]RowResult, 0, len(rows))}
+	err := utils.WithTransaction(s.db, dryRun, func(tx *gorm.DB) error {
+		// Concurrent imports would both miss each other's rows when skipping duplicates.
+		if err := lock.TryTx(tx, "history.import:"+orgKey(orgID)); err != nil {
+			return err
+		}
+		employees, err := currentEmployees(tx, orgID)
+		if err != nil {
+			return err
+		}
+		batch := Batch{OrganizationID: orgID, Kind: kind, FileName: fileName, Rows: len(rows), ImportedBy: actor.UserID}
+		if err := tx.Create(&batch).Error; err != nil {
+			return fmt.Errorf("failed to create import batch: %w", err)
+		}
+		today := clock.Now().UTC()
+		seen := make(map[string]int)
+		for _, row := range rows {
+			result := RowResult{BatchID: batch.ID, Line: row.Line, EmployeeNumber: row.Fields["employee_number"]}
+			record, err := parseRow(kind, row, employees, today, &result)
+			if err == nil {
+				if line, dup := seen[result.Date+"|"+record.key()]; dup {
+					err = fmt.Errorf("duplicate of line %d", line)
+				} else {
+					seen[result.Date+"|"+record.key()] = row.Line
+				}
+			}
+			if err == nil {
+				// Each row runs in its own savepoint so a failed insert doesn't abort the import.
+				err = tx.Transaction(func(rowTx *gorm.DB) error {
+					return record.store(rowTx, orgID, batch.ID, actor, &result)
+				})
+			}
+			switch {
+			case err != nil:
+				result.Status, result.Error = RowFailed, err.Error()
+				batch.Failed++
+			case result.Status == RowDuplicate:
+				batch.Duplicates++
+			default:
+				result.Status = RowImported
+				batch.Imported++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+		if err := tx.Model(&batch).Updates(map[string]interface{}{
+			"imported": batch.Imported, "duplicates": batch.Duplicates, "failed": batch.Failed,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update import batch: %w", err)
+		}
+		if err := tx.CreateInBatches(report.Rows, 500).Error; err != nil {
+			return fmt.Errorf("failed to store import results: %w", err)
+		}
+		if report.Reconciliation, err = reconcile(tx, orgID, kind, employees, report.Rows); err != nil {
+			return err
+		}
+		report.Batch = batch
+		if dryRun {
+			return nil
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "history.import", EntityType: "history_import", EntityID: fmt.Sprintf("%d", batch.ID),
+			After: map[string]interface{}{
+				"kind": kind, "imported": batch.Imported, "duplicates": batch.Duplicates, "failed": batch.Failed,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		// The batch was rolled back with everything else.
+		report.Batch.ID = 0
+		for i := range report.Rows {
+			report.Rows[i].BatchID, report.Rows[i].RecordID = 0, nil
+		}
+	}
+	return report, nil
+}
+
+func (s *service) Batches(orgID *uint) ([]Batch, error) {
+	batches := []Batch{}
+	if err := utils.OrgScope(s.db, orgID).Order("created_at DESC, id DESC").Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list imports: %w", err)
+	}
+	return batches, nil
+}
+
+func (s *service) Report(orgID *uint, id uint) (*Report, error) {
+	var batch Batch
+	if err := utils.OrgScope(s.db, orgID).First(&batch, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	report := &Report{Batch: batch, Rows: []RowResult{}}
+	if err := s.db.Where("batch_id = ?", id).Order("line").Find(&report.Rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load import results: %w", err)
+	}
+	employees, err := currentEmployees(s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Reconciliation, err = reconcile(s.db, orgID, batch.Kind, employees, report.Rows); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *service) History(orgID *uint, employeeID uint) (*History, error) {
+	var count int64
+	if err := utils.OrgScope(s.db.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check employee: %w", err)
+	}
+	if count == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	history := &History{Leave: []LeaveRecord{}, Reviews: []ReviewRecord{}}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Order("start_on DESC, id DESC").Find(&history.Leave).Error; err != nil {
+		return nil, fmt.Errorf("failed to list imported leave: %w", err)
+	}
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Order("reviewed_on DESC, id DESC").Find(&history.Reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to list imported reviews: %w", err)
+	}
+	return history, nil
+}
+
+// record is a validated row, ready to store.
+type record interface {
+	// key identifies the record among the employee's records of its kind on its date, to catch duplicates.
+	key() string
+	// store creates the record, or marks result a duplicate of the one already on record.
+	store(tx *gorm.DB, orgID *uint, batchID uint, actor audit.Actor, result *RowResult) error
+}
+
+type salaryRow struct{ compensation.SalaryRecord }
+
+func (r *salaryRow) key() string {
+	return fmt.Sprintf("%d|%d|%s", r.EmployeeID, r.Amount, r.Currency)
+}
+
+func (r *salaryRow) store(tx *gorm.DB, orgID *uint, _ uint, actor audit.Actor, result *RowResult) error {
+	var existing []compensation.SalaryRecord
+	if err := tx.Where("employee_id = ? AND effective_on = ? AND amount = ? AND currency = ?",
+		r.EmployeeID, r.EffectiveOn, r.Amount, r.Currency).Limit(1).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check salary history: %w", err)
+	}
+	if len(existing) > 0 {
+		result.Status, result.RecordID = RowDuplicate, &existing[0].ID
+		return nil
+	}
+	r.OrganizationID, r.Source, r.RecordedBy = orgID, compensation.SourceImport, actor.UserID
+	if err := tx.Create(&r.SalaryRecord).Error; err != nil {
+		return fmt.Errorf("failed to record salary: %w", err)
+	}
+	result.RecordID = &r.ID
+	return nil
+}
+
+type leaveRow struct{ LeaveRecord }
+
+func (r *leaveRow) key() string {
+	return fmt.Sprintf("%d|%s|%s", r.EmployeeID, r.LeaveType, r.EndOn.Format("2006-01-02"))
+}
+
+func (r *leaveRow) store(tx *gorm.DB, orgID *uint, batchID uint, _ audit.Actor, result *RowResult) error {
+	var existing []LeaveRecord
+	if err := tx.Where("employee_id = ? AND leave_type = ? AND start_on = ? AND end_on = ?",
+		r.EmployeeID, r.LeaveType, r.StartOn, r.EndOn).Limit(1).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check leave history: %w", err)
+	}
+	if len(existing) > 0 {
+		result.Status, result.RecordID = RowDuplicate, &existing[0].ID
+		return nil
+	}
+	r.OrganizationID, r.BatchID = orgID, batchID
+	if err := tx.Create(&r.LeaveRecord).Error; err != nil {
+		return fmt.Errorf("failed to record leave: %w", err)
+	}
+	result.RecordID = &r.ID
+	return nil
+}
+
+type reviewRow struct{ ReviewRecord }
+
+func (r *reviewRow) key() string {
+	return fmt.Sprintf("%d|%s", r.EmployeeID, strings.ToLower(r.Title))
+}
+
+func (r *reviewRow) store(tx *gorm.DB, orgID *uint, batchID uint, _ audit.Actor, result *RowResult) error {
+	var existing []ReviewRecord
+	if err := tx.Where("employee_id = ? AND reviewed_on = ? AND LOWER(title) = LOWER(?)",
+		r.EmployeeID, r.ReviewedOn, r.Title).Limit(1).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check review history: %w", err)
+	}
+	if len(existing) > 0 {
+		result.Status, result.RecordID = RowDuplicate, &existing[0].ID
+		return nil
+	}
+	r.OrganizationID, r.BatchID = orgID, batchID
+	if err := tx.Create(&r.ReviewRecord).Error; err != nil {
+		return fmt.Errorf("failed to record review: %w", err)
+	}
+	result.RecordID = &r.ID
+	return nil
+}
+
+// parseRow validates a row against the current employees, filling in result's employee and date.
+// History can't be dated in the future or before the employee's hire date.
+func parseRow(kind Kind, row Row, employees map[string]employee.Employee, today time.Time, result *RowResult) (record, error) {
+	f := row.Fields
+	emp, ok := employees[strings.ToLower(f["employee_number"])]
+	if !ok {
+		return nil, fmt.Errorf("unknown employee number %q", f["employee_number"])
+	}
+	result.EmployeeID = &emp.ID
+	day := func(name string) (time.Time, error) {
+		d, err := time.Parse("2006-01-02", f[name])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s must be YYYY-MM-DD", name)
+		}
+		if d.After(today) {
+			return time.Time{}, fmt.Errorf("%s is in the future", name)
+		}
+		if hired := emp.HireDate; d.Before(time.Date(hired.Year(), hired.Month(), hired.Day(), 0, 0, 0, 0, time.UTC)) {
+			return time.Time{}, fmt.Errorf("%s is before the employee's hire date %s", name, hired.Format("2006-01-02"))
+		}
+		return d, nil
+	}
+
+	switch kind {
+	case KindSalary:
+		effectiveOn, err := day("effective_on")
+		if err != nil {
+			return nil, err
+		}
+		result.Date = f["effective_on"]
+		amount, err := strconv.ParseInt(f["amount"], 10, 64)
+		if err != nil || amount <= 0 {
+			return nil, errors.New("amount must be a positive whole number, in minor units of the currency")
+		}
+		currency := strings.ToUpper(f["currency"])
+		if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, errors.New("currency must be a three-letter ISO 4217 code")
+		}
+		return &salaryRow{compensation.SalaryRecord{
+			EmployeeID: emp.ID, EffectiveOn: effectiveOn, Amount: amount, Currency: currency, Reason: truncate(f["reason"], 500),
+		}}, nil
+
+	case KindLeave:
+		startOn, err := day("start_on")
+		if err != nil {
+			return nil, err
+		}
+		result.Date = f["start_on"]
+		endOn, err := day("end_on")
+		if err != nil {
+			return nil, err
+		}
+		if endOn.Before(startOn) {
+			return nil, errors.New("end_on is before start_on")
+		}
+		leaveType := strings.ToLower(f["leave_type"])
+		if leaveType == "" || len(leaveType) > 50 {
+			return nil, errors.New("leave_type must be 1 to 50 characters")
+		}
+		r := &leaveRow{LeaveRecord{EmployeeID: emp.ID, LeaveType: leaveType, StartOn: startOn, EndOn: endOn, Paid: true, Note: truncate(f["note"], 500)}}
+		if raw := f["days"]; raw != "" {
+			days, err := strconv.ParseFloat(raw, 64)
+			if err != nil || days < 0 {
+				return nil, errors.New("days must be a non-negative number")
+			}
+			r.Days = &days
+		}
+		if raw := f["paid"]; raw != "" {
+			if r.Paid, err = strconv.ParseBool(raw); err != nil {
+				return nil, errors.New("paid must be true or false")
+			}
+		}
+		return r, nil
+
+	case KindReview:
+		reviewedOn, err := day("reviewed_on")
+		if err != nil {
+			return nil, err
+		}
+		result.Date = f["reviewed_on"]
+		r := &reviewRow{ReviewRecord{
+			EmployeeID: emp.ID, ReviewedOn: reviewedOn, Title: truncate(f["title"], 150),
+			Reviewer: truncate(f["reviewer"], 150), Summary: f["summary"],
+		}}
+		if raw := f["rating"]; raw != "" {
+			rating, err := strconv.ParseFloat(raw, 64)
+			if err != nil || rating < 1 || rating > 5 {
+				return nil, errors.New("rating must be a number from 1 to 5")
+			}
+			r.Rating = &rating
+		}
+		// Reviewers who have since left are kept by name only.
+		if reviewer, ok := employees[strings.ToLower(f["reviewer_number"])]; ok && f["reviewer_number"] != "" {
+			r.ReviewerID = &reviewer.ID
+		}
+		return r, nil
+	}
+	return nil, ErrInvalidKind
+}
+
+// reconcile compares results with the current employees. Employees without history are looked up as the
+// database stands, so a dry run's would-be records count.
+func reconcile(db *gorm.DB, orgID *uint, kind Kind, employees map[string]employee.Employee, results []RowResult) (Reconciliation, error) {
+	rec := Reconciliation{UnknownEmployees: []string{}, WithoutHistory: []string{}, DuplicateLines: []int{}}
+	matched := make(map[uint]bool)
+	unknown := make(map[string]bool)
+	for _, r := range results {
+		if r.EmployeeID != nil {
+			matched[*r.EmployeeID] = true
+		} else if !unknown[r.EmployeeNumber] {
+			unknown[r.EmployeeNumber] = true
+			rec.UnknownEmployees = append(rec.UnknownEmployees, r.EmployeeNumber)
+		}
+		switch r.Status {
+		case RowDuplicate:
+			rec.DuplicateLines = append(rec.DuplicateLines, r.Line)
+		case RowImported:
+			if rec.EarliestOn == "" || r.Date < rec.EarliestOn {
+				rec.EarliestOn = r.Date
+			}
+			if r.Date > rec.LatestOn {
+				rec.LatestOn = r.Date
+			}
+		}
+	}
+	rec.Employees = len(matched)
+
+	table := map[Kind]string{KindSalary: "salary_records", KindLeave: "history_leave", KindReview: "history_reviews"}[kind]
+	var withHistory []uint
+	if err := utils.OrgScope(db.Table(table), orgID).Distinct("employee_id").Pluck("employee_id", &withHistory).Error; err != nil {
+		return Reconciliation{}, fmt.Errorf("failed to reconcile: %w", err)
+	}
+	has := make(map[uint]bool, len(withHistory))
+	for _, id := range withHistory {
+		has[id] = true
+	}
+	for _, e := range employees {
+		if !has[e.ID] {
+			rec.WithoutHistory = append(rec.WithoutHistory, e.EmployeeNumber)
+		}
+	}
+	slices.Sort(rec.WithoutHistory)
+	slices.Sort(rec.UnknownEmployees)
+	return rec, nil
+}
+
+// currentEmployees returns the organization's employees by lower-case employee number.
+func currentEmployees(db *gorm.DB, orgID *uint) (map[string]employee.Employee, error) {
+	var found []employee.Employee
+	if err := utils.OrgScope(db.Select("id, employee_number, hire_date"), orgID).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+	employees := make(map[string]employee.Employee, len(found))
+	for _, e := range found {
+		employees[strings.ToLower(e.EmployeeNumber)] = e
+	}
+	return employees, nil
+}
+
+// truncate cuts s to at most n bytes, on a rune boundary, so long legacy notes don't fail their row.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// orgKey names an organization in lock keys.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
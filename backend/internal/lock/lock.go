@@ -0,0 +1,69 @@
+// prometheus/backend/internal/lock/lock.go
+package lock
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrLocked is returned by the Try functions when another instance holds the lock.
+var ErrLocked = errors.New("operation is already running")
+
+// Locks are Postgres advisory locks keyed by hashtext(name), so every replica sharing the database
+// agrees on who holds one. Names are namespaced by convention, e.g. "jobs:reports.deliver_due".
+
+// Tx blocks until it holds the named lock for the rest of tx. The lock is released when tx commits or
+// rolls back, so it can't outlive the critical section.
+func Tx(tx *gorm.DB, name string) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", name).Error; err != nil {
+		return fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	return nil
+}
+
+// TryTx takes the named lock for the rest of tx, or returns ErrLocked right away if it is held.
+func TryTx(tx *gorm.DB, name string) error {
+	var acquired bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", name).Scan(&acquired).Error; err != nil {
+		return fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	return nil
+}
+
+// Try runs fn while holding the named lock, or returns ErrLocked without running it if the lock is held.
+// Unlike TryTx it doesn't keep a transaction open, which suits long sections that commit as they go. The
+// lock lives on a dedicated connection; if the process dies, Postgres releases it with the connection.
+func Try(ctx context.Context, db *gorm.DB, name string, fn func(ctx context.Context) error) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	defer func() {
+		// Unlock even if ctx was cancelled; closing the connection would otherwise only return it to the pool.
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name); err != nil {
+			// Discard the connection so the lock dies with it instead of staying held by an idle connection.
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+	return fn(ctx)
+}
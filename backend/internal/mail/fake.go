@@ -0,0 +1,41 @@
+// prometheus/backend/internal/mail/fake.go
+package mail
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/devtools"
+	"strings"
+)
+
+// FakeSender records messages in the devtools inbox instead of sending them.
+type FakeSender struct {
+	inbox *devtools.Inbox
+}
+
+// NewFakeSender creates a FakeSender recording into inbox.
+func NewFakeSender(inbox *devtools.Inbox) *FakeSender {
+	return &FakeSender{inbox: inbox}
+}
+
+// fakeAttachment describes an attachment in the inbox; the content itself is left out.
+type fakeAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// Send implements Sender.
+func (s *FakeSender) Send(ctx context.Context, msg Message) error {
+	attachments := make([]fakeAttachment, 0, len(msg.Attachments))
+	for _, a := range msg.Attachments {
+		attachments = append(attachments, fakeAttachment{Filename: a.Filename, ContentType: a.ContentType, Size: len(a.Data)})
+	}
+	s.inbox.Record(devtools.ChannelMail, fmt.Sprintf("To %s: %s", strings.Join(msg.To, ", "), msg.Subject), map[string]interface{}{
+		"to":          msg.To,
+		"subject":     msg.Subject,
+		"body":        msg.Body,
+		"attachments": attachments,
+	})
+	return nil
+}
@@ -0,0 +1,193 @@
+// prometheus/backend/internal/mail/mail.go
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"prometheus/backend/config"
+	"prometheus/backend/internal/outbound"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent along with a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain-text email.
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Sender delivers email.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender returns an SMTP sender, or a LogSender while SMTP_HOST is empty (local development).
+func NewSender(cfg *config.Config) Sender {
+	if cfg.SMTPHost == "" {
+		return LogSender{}
+	}
+	envelope := cfg.MailFrom
+	if addr, err := netmail.ParseAddress(cfg.MailFrom); err == nil {
+		envelope = addr.Address
+	}
+	return &SMTPSender{
+		addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.MailFrom,
+		envelope: envelope,
+		breaker:  outbound.NewBreaker("smtp", 0, 0),
+	}
+}
+
+// LogSender writes messages to the log instead of sending them.
+type LogSender struct{}
+
+// Send implements Sender.
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail (not sent, SMTP not configured): to=%s subject=%q attachments=%d", strings.Join(msg.To, ","), msg.Subject, len(msg.Attachments))
+	return nil
+}
+
+// SMTPSender sends mail through an SMTP relay, authenticating with PLAIN when a username is set.
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string // From header, e.g. "Prometheus <no-reply@acme.example>"
+	envelope string // Bare address of from, for the SMTP envelope
+	breaker  *outbound.Breaker
+}
+
+// smtpTimeout bounds a whole SMTP conversation, so a hung relay can't block the outbox relay.
+const smtpTimeout = 30 * time.Second
+
+// Send implements Sender. While the relay keeps failing, the breaker fails sends fast; the outbox retries them.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	body, err := s.encode(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.breaker.Do(func() error { return s.send(ctx, msg.To, body) }); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// send is smtp.SendMail with a deadline: net/smtp has no timeouts of its own.
+func (s *SMTPSender) send(ctx context.Context, to []string, body []byte) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.envelope); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// encode builds the MIME message: a text part followed by base64-encoded attachments.
+func (s *SMTPSender) encode(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		s.from, strings.Join(msg.To, ", "), mime.QEncoding.Encode("utf-8", msg.Subject),
+		time.Now().Format(time.RFC1123Z), writer.Boundary())
+
+	text, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		// RFC 2045 limits encoded lines to 76 characters.
+		for len(encoded) > 76 {
+			if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := part.Write([]byte(encoded)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return append([]byte(header), buf.Bytes()...), nil
+}
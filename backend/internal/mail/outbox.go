@@ -0,0 +1,29 @@
+// prometheus/backend/internal/mail/outbox.go
+package mail
+
+import (
+	"context"
+	"prometheus/backend/internal/outbox"
+
+	"gorm.io/gorm"
+)
+
+// OutboxKind is the outbox message kind of emails.
+const OutboxKind = "mail"
+
+// QueueTx adds msg to the outbox in tx, so it is sent if and only if tx commits.
+func QueueTx(o *outbox.Outbox, tx *gorm.DB, msg Message) error {
+	_, err := o.AddTx(tx, OutboxKind, msg)
+	return err
+}
+
+// Dispatcher sends the emails queued with QueueTx through sender.
+func Dispatcher(sender Sender) outbox.Dispatcher {
+	return func(ctx context.Context, m *outbox.Message) error {
+		var msg Message
+		if err := m.DecodePayload(&msg); err != nil {
+			return err
+		}
+		return sender.Send(ctx, msg)
+	}
+}
@@ -0,0 +1,104 @@
+// prometheus/backend/internal/mail/template.go
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// maxTemplateSize bounds the subject plus body of a customized template, in bytes.
+const maxTemplateSize = 20 << 10
+
+// ErrUnknownTemplate is returned for template names that aren't registered.
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// ErrInvalidTemplate is returned for templates that don't parse or fail to render.
+var ErrInvalidTemplate = errors.New("invalid email template")
+
+// Template is an email whose subject and body are text/template sources, rendered with a map of
+// variables. Organizations may replace the default subject and body, see TemplateService.
+type Template struct {
+	Name        string                 `json:"name" example:"report.delivery"`
+	Description string                 `json:"description" example:"Scheduled report sent to a subscriber"`
+	Subject     string                 `json:"subject"`
+	Body        string                 `json:"body"`
+	Sample      map[string]interface{} `json:"sample"` // Variables used for previews and test sends
+}
+
+// Variables lists the template's variables, taken from its sample data.
+func (t *Template) Variables() []string {
+	names := make([]string, 0, len(t.Sample))
+	for name := range t.Sample {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	templatesMu sync.RWMutex
+	templates   = map[string]*Template{}
+)
+
+// RegisterTemplate adds a default template. Features register theirs at package initialization.
+func RegisterTemplate(t Template) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	templates[t.Name] = &t
+}
+
+// LookupTemplate returns a registered template.
+func LookupTemplate(name string) (*Template, bool) {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	t, ok := templates[name]
+	return t, ok
+}
+
+// Templates returns the registered templates by name.
+func Templates() []*Template {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	list := make([]*Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Render executes a subject and body with data. Unknown variables are errors, so a typo in a customized
+// template shows up in the preview rather than as "<no value>" in users' inboxes.
+func Render(subject, body string, data map[string]interface{}) (string, string, error) {
+	if len(subject)+len(body) > maxTemplateSize {
+		return "", "", fmt.Errorf("%w: subject and body must not exceed %d KB", ErrInvalidTemplate, maxTemplateSize>>10)
+	}
+	renderedSubject, err := execute("subject", subject, data)
+	if err != nil {
+		return "", "", err
+	}
+	renderedBody, err := execute("body", body, data)
+	if err != nil {
+		return "", "", err
+	}
+	// Header injection: a subject must stay on one line.
+	renderedSubject = strings.Join(strings.Fields(renderedSubject), " ")
+	return renderedSubject, renderedBody, nil
+}
+
+func execute(name, source string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
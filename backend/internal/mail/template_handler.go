@@ -0,0 +1,167 @@
+// prometheus/backend/internal/mail/template_handler.go
+package mail
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles HTTP requests for email templates.
+type TemplateHandler struct {
+	service TemplateService
+}
+
+// NewTemplateHandler creates a new instance of TemplateHandler.
+func NewTemplateHandler(service TemplateService) *TemplateHandler {
+	return &TemplateHandler{service: service}
+}
+
+// List returns the email templates with the caller's organization's customizations.
+// @Summary List email templates
+// @Tags Email Templates
+// @Produce json
+// @Success 200 {array} TemplateInfo
+// @Router /admin/mail-templates [get]
+func (h *TemplateHandler) List(c *gin.Context) {
+	infos, err := h.service.List(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Email templates fetched successfully", infos)
+}
+
+// Get returns an email template with the caller's organization's customization.
+// @Summary Get an email template
+// @Tags Email Templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} TemplateInfo
+// @Failure 404 {object} utils.ErrorResponse "Unknown template"
+// @Router /admin/mail-templates/{name} [get]
+func (h *TemplateHandler) Get(c *gin.Context) {
+	info, err := h.service.Get(utils.OrganizationFromContext(c), c.Param("name"))
+	if err != nil {
+		sendTemplateError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Email template fetched successfully", info)
+}
+
+// Save customizes an email template for the caller's organization.
+// @Summary Customize an email template
+// @Description Subject and body are Go text/template sources using the template's variables, e.g. {{.Username}}.
+// @Description They are rendered with the sample data first; unknown variables and syntax errors are rejected.
+// @Description Disabled customizations can be previewed and test-sent but aren't used for real emails.
+// @Tags Email Templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param template body TemplateRequest true "Customization"
+// @Success 200 {object} TemplateInfo
+// @Failure 400 {object} utils.ErrorResponse "Invalid template"
+// @Failure 404 {object} utils.ErrorResponse "Unknown template"
+// @Router /admin/mail-templates/{name} [put]
+func (h *TemplateHandler) Save(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	info, err := h.service.Save(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.Param("name"), req)
+	if err != nil {
+		sendTemplateError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Email template saved successfully", info)
+}
+
+// Reset removes the caller's organization's customization of an email template.
+// @Summary Reset an email template to its default
+// @Tags Email Templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Unknown template"
+// @Router /admin/mail-templates/{name} [delete]
+func (h *TemplateHandler) Reset(c *gin.Context) {
+	if err := h.service.Reset(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.Param("name")); err != nil {
+		sendTemplateError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Email template reset successfully", nil)
+}
+
+// Preview renders an email template with sample data.
+// @Summary Preview an email template
+// @Description Renders the given subject and body, else the saved customization, else the default.
+// @Description "data" overrides individual sample variables.
+// @Tags Email Templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param preview body PreviewRequest false "Draft and data"
+// @Success 200 {object} RenderedTemplate
+// @Failure 400 {object} utils.ErrorResponse "Invalid template"
+// @Failure 404 {object} utils.ErrorResponse "Unknown template"
+// @Router /admin/mail-templates/{name}/preview [post]
+func (h *TemplateHandler) Preview(c *gin.Context) {
+	var req PreviewRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	rendered, err := h.service.Preview(utils.OrganizationFromContext(c), c.Param("name"), req)
+	if err != nil {
+		sendTemplateError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Email template rendered successfully", rendered)
+}
+
+// SendTest mails a rendered email template to an address.
+// @Summary Send a test email
+// @Description Renders like the preview and sends it right away, with "[Test]" prepended to the subject.
+// @Tags Email Templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param test body TestSendRequest true "Recipient, draft and data"
+// @Success 200 {object} RenderedTemplate
+// @Failure 400 {object} utils.ErrorResponse "Invalid template or address"
+// @Failure 404 {object} utils.ErrorResponse "Unknown template"
+// @Failure 502 {object} utils.ErrorResponse "Mail server rejected the message"
+// @Router /admin/mail-templates/{name}/test [post]
+func (h *TemplateHandler) SendTest(c *gin.Context) {
+	var req TestSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	rendered, err := h.service.SendTest(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.Param("name"), req)
+	if err != nil {
+		sendTemplateError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Test email sent successfully", rendered)
+}
+
+// sendTemplateError maps service errors to HTTP status codes.
+func sendTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownTemplate):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidTemplate):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errSendFailed):
+		utils.SendErrorResponse(c, http.StatusBadGateway, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,287 @@
+// prometheus/backend/internal/mail/template_service.go
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errSendFailed wraps delivery errors of test messages, which are the mail server's fault rather than ours.
+var errSendFailed = errors.New("failed to send test email")
+
+// TemplateOverride is an organization's customized subject and body of a template. Drafts are saved
+// disabled, previewed and test-sent, and only used for real emails once enabled.
+type TemplateOverride struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint     `gorm:"uniqueIndex:idx_mail_template_override" json:"organization_id,omitempty"` // nil = default organization
+	Name           string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_mail_template_override" json:"name"`
+	Subject        string    `gorm:"type:text;not null" json:"subject"`
+	Body           string    `gorm:"type:text;not null" json:"body"`
+	Enabled        bool      `gorm:"not null;default:false" json:"enabled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName implements gorm's Tabler.
+func (TemplateOverride) TableName() string { return "mail_template_overrides" }
+
+// TemplateInfo describes a template for an organization: its default and any customization.
+type TemplateInfo struct {
+	Name           string   `json:"name" example:"report.delivery"`
+	Description    string   `json:"description"`
+	Variables      []string `json:"variables" example:"Username,ReportName"`
+	DefaultSubject string   `json:"default_subject"`
+	DefaultBody    string   `json:"default_body"`
+	Customized     bool     `json:"customized"`
+	Subject        string   `json:"subject,omitempty"` // Customized subject
+	Body           string   `json:"body,omitempty"`    // Customized body
+	Enabled        bool     `json:"enabled"`           // Whether the customization is used for real emails
+}
+
+// TemplateRequest saves a customization.
+type TemplateRequest struct {
+	Subject string `json:"subject" binding:"required" example:"Your {{.Schedule}} report is ready"`
+	Body    string `json:"body" binding:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// PreviewRequest renders a template. Subject and body default to the saved customization (enabled or not),
+// then to the default; data overrides the sample variables.
+type PreviewRequest struct {
+	Subject *string                `json:"subject,omitempty"`
+	Body    *string                `json:"body,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// TestSendRequest renders a template like PreviewRequest and mails it to To.
+type TestSendRequest struct {
+	PreviewRequest
+	To string `json:"to" binding:"required,email" example:"hr@acme.example"`
+}
+
+// RenderedTemplate is a rendered subject and body.
+type RenderedTemplate struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// TemplateService manages organizations' customized email templates and renders emails with them.
+// orgID scopes every call to one organization's customizations (nil = default organization).
+type TemplateService interface {
+	List(orgID *uint) ([]TemplateInfo, error)
+	Get(orgID *uint, name string) (*TemplateInfo, error)
+	Save(actor audit.Actor, orgID *uint, name string, req TemplateRequest) (*TemplateInfo, error)
+	// Reset deletes the customization, going back to the default.
+	Reset(actor audit.Actor, orgID *uint, name string) error
+	Preview(orgID *uint, name string, req PreviewRequest) (*RenderedTemplate, error)
+	SendTest(ctx context.Context, actor audit.Actor, orgID *uint, name string, req TestSendRequest) (*RenderedTemplate, error)
+	// Render renders an email for sending: with the organization's enabled customization, else the default.
+	Render(orgID *uint, name string, data map[string]interface{}) (subject, body string, err error)
+}
+
+// templateService implements the TemplateService interface.
+type templateService struct {
+	db      *gorm.DB
+	sender  Sender
+	auditor audit.Service
+}
+
+// NewTemplateService creates a new instance of TemplateService. Test messages go out through sender
+// directly, so delivery problems show up in the response.
+func NewTemplateService(db *gorm.DB, sender Sender, auditor audit.Service) TemplateService {
+	return &templateService{db: db, sender: sender, auditor: auditor}
+}
+
+func (s *templateService) List(orgID *uint) ([]TemplateInfo, error) {
+	var overrides []TemplateOverride
+	if err := scopedOverrides(s.db, orgID).Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to load template customizations: %w", err)
+	}
+	byName := make(map[string]*TemplateOverride, len(overrides))
+	for i := range overrides {
+		byName[overrides[i].Name] = &overrides[i]
+	}
+	all := Templates()
+	infos := make([]TemplateInfo, 0, len(all))
+	for _, t := range all {
+		infos = append(infos, newTemplateInfo(t, byName[t.Name]))
+	}
+	return infos, nil
+}
+
+func (s *templateService) Get(orgID *uint, name string) (*TemplateInfo, error) {
+	t, override, err := s.load(s.db, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	info := newTemplateInfo(t, override)
+	return &info, nil
+}
+
+// Save validates the customization by rendering it with the sample data before storing it.
+func (s *templateService) Save(actor audit.Actor, orgID *uint, name string, req TemplateRequest) (*TemplateInfo, error) {
+	t, ok := LookupTemplate(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	if _, _, err := Render(req.Subject, req.Body, t.Sample); err != nil {
+		return nil, err
+	}
+	override := TemplateOverride{OrganizationID: orgID, Name: name, Subject: req.Subject, Body: req.Body, Enabled: req.Enabled}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		_, before, err := s.load(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID, name)
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			override.ID = before.ID
+			override.CreatedAt = before.CreatedAt
+		}
+		if err := tx.Save(&override).Error; err != nil {
+			return fmt.Errorf("failed to save template %s: %w", name, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "mail_template.update", EntityType: "mail_template", EntityID: name, Before: before, After: override,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := newTemplateInfo(t, &override)
+	return &info, nil
+}
+
+func (s *templateService) Reset(actor audit.Actor, orgID *uint, name string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		_, override, err := s.load(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID, name)
+		if err != nil {
+			return err
+		}
+		if override == nil {
+			return nil
+		}
+		if err := tx.Delete(override).Error; err != nil {
+			return fmt.Errorf("failed to reset template %s: %w", name, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "mail_template.reset", EntityType: "mail_template", EntityID: name, Before: override,
+		})
+	})
+}
+
+func (s *templateService) Preview(orgID *uint, name string, req PreviewRequest) (*RenderedTemplate, error) {
+	t, override, err := s.load(s.db, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	subject, body := t.Subject, t.Body
+	if override != nil {
+		subject, body = override.Subject, override.Body
+	}
+	if req.Subject != nil {
+		subject = *req.Subject
+	}
+	if req.Body != nil {
+		body = *req.Body
+	}
+	data := make(map[string]interface{}, len(t.Sample)+len(req.Data))
+	for k, v := range t.Sample {
+		data[k] = v
+	}
+	for k, v := range req.Data {
+		data[k] = v
+	}
+	renderedSubject, renderedBody, err := Render(subject, body, data)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedTemplate{Subject: renderedSubject, Body: renderedBody}, nil
+}
+
+func (s *templateService) SendTest(ctx context.Context, actor audit.Actor, orgID *uint, name string, req TestSendRequest) (*RenderedTemplate, error) {
+	rendered, err := s.Preview(orgID, name, req.PreviewRequest)
+	if err != nil {
+		return nil, err
+	}
+	msg := Message{To: []string{req.To}, Subject: "[Test] " + rendered.Subject, Body: rendered.Body}
+	// Sent inside the audit transaction: nothing goes out unaudited, and a failed send isn't recorded.
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "mail_template.test_send", EntityType: "mail_template", EntityID: name, After: map[string]string{"to": req.To},
+		}); err != nil {
+			return err
+		}
+		if err := s.sender.Send(ctx, msg); err != nil {
+			return fmt.Errorf("%w: %v", errSendFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// Render falls back to the default when a customization fails to render, e.g. because a variable was
+// removed from the template since it was saved; a default email beats no email.
+func (s *templateService) Render(orgID *uint, name string, data map[string]interface{}) (string, string, error) {
+	t, override, err := s.load(s.db, orgID, name)
+	if err != nil {
+		return "", "", err
+	}
+	if override != nil && override.Enabled {
+		subject, body, err := Render(override.Subject, override.Body, data)
+		if err == nil {
+			return subject, body, nil
+		}
+		log.Printf("Mail: customized template %s of organization %v failed, using the default: %v", name, orgID, err)
+	}
+	return Render(t.Subject, t.Body, data)
+}
+
+// load returns the registered template and the organization's customization of it, if any.
+func (s *templateService) load(db *gorm.DB, orgID *uint, name string) (*Template, *TemplateOverride, error) {
+	t, ok := LookupTemplate(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	var override TemplateOverride
+	err := scopedOverrides(db, orgID).Where("name = ?", name).Take(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return t, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load template %s: %w", name, err)
+	}
+	return t, &override, nil
+}
+
+func newTemplateInfo(t *Template, override *TemplateOverride) TemplateInfo {
+	info := TemplateInfo{
+		Name:           t.Name,
+		Description:    t.Description,
+		Variables:      t.Variables(),
+		DefaultSubject: t.Subject,
+		DefaultBody:    t.Body,
+	}
+	if override != nil {
+		info.Customized = true
+		info.Subject, info.Body, info.Enabled = override.Subject, override.Body, override.Enabled
+	}
+	return info
+}
+
+// scopedOverrides restricts a query to the organization's customizations.
+func scopedOverrides(db *gorm.DB, orgID *uint) *gorm.DB {
+	if orgID == nil {
+		return db.Where("organization_id IS NULL")
+	}
+	return db.Where("organization_id = ?", *orgID)
+}
@@ -0,0 +1,52 @@
+// prometheus/backend/internal/module/handler.go
+package module
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler exposes aggregated module health and metrics.
+type Handler struct {
+	registry *Registry
+	metrics  http.Handler
+}
+
+// NewHandler creates a new instance of Handler. gatherer is the Prometheus registry that
+// holds the module collector and any other application metrics.
+func NewHandler(registry *Registry, gatherer prometheus.Gatherer) *Handler {
+	return &Handler{
+		registry: registry,
+		metrics:  promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+	}
+}
+
+// Readyz reports readiness. It responds 503 if any module check is down, so load balancers
+// stop routing traffic to this instance. Degraded checks are reported but don't fail readiness.
+// @Summary Readiness probe
+// @Tags Health
+// @Produce json
+// @Success 200 {object} HealthReport
+// @Failure 503 {object} HealthReport
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.registry.Health(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	// Probes and monitoring read this endpoint, so it is served without the response envelope.
+	c.JSON(status, report)
+}
+
+// Metrics serves Prometheus metrics.
+// @Summary Prometheus metrics
+// @Tags Health
+// @Produce plain
+// @Router /metrics [get]
+func (h *Handler) Metrics(c *gin.Context) {
+	h.metrics.ServeHTTP(c.Writer, c.Request)
+}
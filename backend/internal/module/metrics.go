@@ -0,0 +1,59 @@
+// prometheus/backend/internal/module/metrics.go
+package module
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// healthCollector exports module health as Prometheus metrics at scrape time:
+//
+//	hris_module_health{module,check}          1 = up, 0.5 = degraded, 0 = down
+//	hris_module_metric{module,check,metric}   numeric values reported by contributors (queue depth, ...)
+type healthCollector struct {
+	registry   *Registry
+	healthDesc *prometheus.Desc
+	metricDesc *prometheus.Desc
+}
+
+// NewCollector creates a prometheus.Collector that reports the registry's health contributors.
+func NewCollector(registry *Registry) prometheus.Collector {
+	return &healthCollector{
+		registry: registry,
+		healthDesc: prometheus.NewDesc("hris_module_health",
+			"Health of a module check (1 = up, 0.5 = degraded, 0 = down).",
+			[]string{"module", "check"}, nil),
+		metricDesc: prometheus.NewDesc("hris_module_metric",
+			"Numeric value reported by a module health contributor.",
+			[]string{"module", "check", "metric"}, nil),
+	}
+}
+
+func (h *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- h.healthDesc
+	ch <- h.metricDesc
+}
+
+func (h *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	report := h.registry.Health(context.Background())
+	for moduleName, mh := range report.Modules {
+		for checkName, result := range mh.Checks {
+			ch <- prometheus.MustNewConstMetric(h.healthDesc, prometheus.GaugeValue, statusValue(result.Status), moduleName, checkName)
+			for metricName, value := range result.Metrics {
+				ch <- prometheus.MustNewConstMetric(h.metricDesc, prometheus.GaugeValue, value, moduleName, checkName, metricName)
+			}
+		}
+	}
+}
+
+func statusValue(s HealthStatus) float64 {
+	switch s {
+	case StatusUp:
+		return 1
+	case StatusDegraded:
+		return 0.5
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,226 @@
+// prometheus/backend/internal/module/module.go
+package module
+
+import (
+	"context"
+	"log"
+	"prometheus/backend/internal/seed"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Module is a subsystem of the backend (jobs, cache, payroll, ...).
+// Each module reports its own health contributors, which are aggregated into /readyz and /metrics.
+type Module interface {
+	Name() string
+	HealthContributors() []HealthContributor
+}
+
+// Migrator is implemented by modules that own database tables. Models of enabled modules are auto-migrated
+// at startup (see Registry.Models); tables of disabled modules are left alone.
+// Modules contribute routes and background jobs the same way, through routing.Contributor and jobs.Contributor.
+type Migrator interface {
+	Models() []any
+}
+
+// Seeder is implemented by modules that own initial data. Seeds of enabled modules are applied once their
+// tables are migrated, each version once per database (see seed.Apply).
+type Seeder interface {
+	Seeds() []seed.Seed
+}
+
+// HealthStatus is the outcome of a single health check.
+type HealthStatus string
+
+const (
+	StatusUp       HealthStatus = "up"
+	StatusDegraded HealthStatus = "degraded" // Working, but needs attention; does not fail readiness
+	StatusDown     HealthStatus = "down"     // Fails readiness
+)
+
+// HealthResult is reported by a HealthContributor.
+// Metrics are exported as gauges on /metrics (e.g. "queue_depth", "last_success_age_seconds").
+type HealthResult struct {
+	Status  HealthStatus       `json:"status"`
+	Error   string             `json:"error,omitempty"`
+	Details map[string]any     `json:"details,omitempty"`
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// HealthContributor checks one aspect of a module (database ping, queue depth, last cron success, ...).
+type HealthContributor interface {
+	Name() string
+	Check(ctx context.Context) HealthResult
+}
+
+// healthCheck adapts a function to HealthContributor.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) HealthResult
+}
+
+func (h healthCheck) Name() string                           { return h.name }
+func (h healthCheck) Check(ctx context.Context) HealthResult { return h.check(ctx) }
+
+// NewHealthCheck creates a HealthContributor from a function.
+func NewHealthCheck(name string, check func(ctx context.Context) HealthResult) HealthContributor {
+	return healthCheck{name: name, check: check}
+}
+
+// Registry holds all registered modules.
+type Registry struct {
+	mu       sync.RWMutex
+	modules  []Module
+	disabled map[string]bool
+}
+
+// NewRegistry creates an empty Registry. Feature modules named in disabled (MODULES_DISABLED) are never
+// registered.
+func NewRegistry(disabled ...string) *Registry {
+	r := &Registry{disabled: make(map[string]bool, len(disabled))}
+	for _, name := range disabled {
+		if name = strings.TrimSpace(name); name != "" {
+			r.disabled[name] = true
+		}
+	}
+	return r
+}
+
+// Register adds a module to the registry. Use it for infrastructure the backend can't run without
+// (database, cache, jobs); optional modules go through RegisterFeature.
+func (r *Registry) Register(m Module) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modules = append(r.modules, m)
+}
+
+// RegisterFeature adds an optional module unless the deployment disabled it, and reports whether it did.
+// A disabled module contributes nothing: no health checks, migrations, routes or jobs.
+func (r *Registry) RegisterFeature(m Module) bool {
+	if !r.Enabled(m.Name()) {
+		log.Printf("Module %s is disabled by configuration.", m.Name())
+		return false
+	}
+	r.Register(m)
+	return true
+}
+
+// Enabled reports whether the deployment allows the named module.
+func (r *Registry) Enabled(name string) bool {
+	return !r.disabled[name]
+}
+
+// Models returns the models of every registered module implementing Migrator.
+func (r *Registry) Models() []any {
+	var models []any
+	for _, m := range r.Modules() {
+		if migrator, ok := m.(Migrator); ok {
+			models = append(models, migrator.Models()...)
+		}
+	}
+	return models
+}
+
+// Seeds returns the seeds of every registered module implementing Seeder, in registration order.
+func (r *Registry) Seeds() []seed.Seed {
+	var seeds []seed.Seed
+	for _, m := range r.Modules() {
+		if seeder, ok := m.(Seeder); ok {
+			seeds = append(seeds, seeder.Seeds()...)
+		}
+	}
+	return seeds
+}
+
+// Modules returns the registered modules in registration order.
+func (r *Registry) Modules() []Module {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Module(nil), r.modules...)
+}
+
+// ModuleHealth is the aggregated health of one module.
+type ModuleHealth struct {
+	Status HealthStatus            `json:"status"`
+	Checks map[string]HealthResult `json:"checks"`
+}
+
+// HealthReport is the aggregated health of all modules.
+type HealthReport struct {
+	Status  HealthStatus            `json:"status"`
+	Modules map[string]ModuleHealth `json:"modules"`
+}
+
+// checkTimeout bounds each individual health check so a hanging dependency can't stall /readyz.
+const checkTimeout = 3 * time.Second
+
+// Health runs every health contributor of every module concurrently and aggregates the results.
+// The overall status is the worst status of any check.
+func (r *Registry) Health(ctx context.Context) HealthReport {
+	type outcome struct {
+		module, check string
+		result        HealthResult
+	}
+
+	modules := r.Modules()
+	results := make(chan outcome)
+	var wg sync.WaitGroup
+	for _, m := range modules {
+		for _, hc := range m.HealthContributors() {
+			wg.Add(1)
+			go func(moduleName string, hc HealthContributor) {
+				defer wg.Done()
+				checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+				defer cancel()
+				results <- outcome{module: moduleName, check: hc.Name(), result: runCheck(checkCtx, hc)}
+			}(m.Name(), hc)
+		}
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := HealthReport{Status: StatusUp, Modules: make(map[string]ModuleHealth, len(modules))}
+	for _, m := range modules {
+		report.Modules[m.Name()] = ModuleHealth{Status: StatusUp, Checks: map[string]HealthResult{}}
+	}
+	for o := range results {
+		mh := report.Modules[o.module]
+		mh.Checks[o.check] = o.result
+		mh.Status = worst(mh.Status, o.result.Status)
+		report.Modules[o.module] = mh
+		report.Status = worst(report.Status, o.result.Status)
+	}
+	return report
+}
+
+// runCheck executes a check, turning panics and timeouts into a "down" result.
+func runCheck(ctx context.Context, hc HealthContributor) (result HealthResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = HealthResult{Status: StatusDown, Error: "health check panicked"}
+		}
+	}()
+	done := make(chan HealthResult, 1)
+	go func() { done <- hc.Check(ctx) }()
+	select {
+	case result = <-done:
+		if result.Status == "" {
+			result.Status = StatusUp
+		}
+		return result
+	case <-ctx.Done():
+		return HealthResult{Status: StatusDown, Error: "health check timed out"}
+	}
+}
+
+// worst returns the more severe of two statuses.
+func worst(a, b HealthStatus) HealthStatus {
+	rank := map[HealthStatus]int{StatusUp: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
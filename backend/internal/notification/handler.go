@@ -0,0 +1,183 @@
+// prometheus/backend/internal/notification/handler.go
+package notification
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for the caller's notifications.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the caller's notifications.
+// @Summary List my notifications
+// @Tags Notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /me/notifications [get]
+func (h *Handler) List(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	notifications, total, err := h.service.List(c.GetUint("userID"), c.Query("unread") == "true", page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Notifications fetched successfully", page.Response(notifications, total))
+}
+
+// UnreadCount returns how many of the caller's notifications are unread, e.g. for a badge.
+// @Summary Count my unread notifications
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Router /me/notifications/unread-count [get]
+func (h *Handler) UnreadCount(c *gin.Context) {
+	count, err := h.service.UnreadCount(c.GetUint("userID"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Unread notifications counted successfully", gin.H{"unread": count})
+}
+
+// MarkRead marks one of the caller's notifications read.
+// @Summary Mark a notification read
+// @Tags Notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Notification not found"
+// @Router /me/notifications/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.MarkRead(c.GetUint("userID"), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, http.StatusNotFound, "Notification not found")
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Notification marked read", nil)
+}
+
+// MarkAllRead marks all of the caller's notifications read.
+// @Summary Mark all my notifications read
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Router /me/notifications/read [post]
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	count, err := h.service.MarkAllRead(c.GetUint("userID"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Notifications marked read", gin.H{"marked": count})
+}
+
+// Open records that a notification email was opened and redirects to the notification in the app.
+// @Summary Follow a tracked notification link
+// @Description Links in notification emails point here while email tracking is enabled. Unknown or
+// @Description tampered tokens redirect to the inbox.
+// @Tags Notifications
+// @Param token path string true "Signed notification token"
+// @Success 302 "Redirect to the app"
+// @Router /notifications/open/{token} [get]
+func (h *Handler) Open(c *gin.Context) {
+	target, err := h.service.Open(c.Param("token"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// Stats counts delivery and read receipts per category.
+// @Summary Get notification delivery stats
+// @Description Counts, per category, how many notifications were seen and read in the app, and emailed,
+// @Description delivered, opened (with email tracking), failed, skipped or still pending by email.
+// @Tags Notifications
+// @Produce json
+// @Param category query string false "Category"
+// @Param mandatory query bool false "Only mandatory notifications"
+// @Param from query string false "Created at or after (RFC3339)"
+// @Param to query string false "Created before (RFC3339)"
+// @Success 200 {array} Stats
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/notifications/stats [get]
+func (h *Handler) Stats(c *gin.Context) {
+	filter, ok := parseStatsFilter(c)
+	if !ok {
+		return
+	}
+	stats, err := h.service.Stats(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Notification stats fetched successfully", stats)
+}
+
+// Receipts lists the delivery state of each recipient's notification.
+// @Summary List notification receipts
+// @Tags Notifications
+// @Produce json
+// @Param category query string false "Category"
+// @Param mandatory query bool false "Only mandatory notifications"
+// @Param unread query bool false "Only notifications not read in the app yet"
+// @Param from query string false "Created at or after (RFC3339)"
+// @Param to query string false "Created before (RFC3339)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/notifications/receipts [get]
+func (h *Handler) Receipts(c *gin.Context) {
+	filter, ok := parseStatsFilter(c)
+	if !ok {
+		return
+	}
+	filter.UnreadOnly = c.Query("unread") == "true"
+	page := utils.ParsePagination(c)
+	receipts, total, err := h.service.Receipts(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Notification receipts fetched successfully", page.Response(receipts, total))
+}
+
+// parseStatsFilter reads the filter shared by Stats and Receipts, responding 400 if it is invalid.
+func parseStatsFilter(c *gin.Context) (StatsFilter, bool) {
+	filter := StatsFilter{Category: c.Query("category"), MandatoryOnly: c.Query("mandatory") == "true"}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected RFC3339")
+				return StatsFilter{}, false
+			}
+			*target = &t
+		}
+	}
+	return filter, true
+}
@@ -0,0 +1,134 @@
+// prometheus/backend/internal/notification/hook.go
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+// Rule turns an audited change into notices. Rules run inside the transaction of the change, so a
+// notification exists exactly if the change was committed.
+type Rule func(tx *gorm.DB, actor audit.Actor, record *audit.Log) ([]Notice, error)
+
+// AuditHook creates notifications for audited changes with the rule registered for their action.
+func AuditHook(rules map[string]Rule) audit.Hook {
+	return func(tx *gorm.DB, actor audit.Actor, record *audit.Log) error {
+		rule, ok := rules[record.Action]
+		if !ok {
+			return nil
+		}
+		notices, err := rule(tx, actor, record)
+		if err != nil {
+			return err
+		}
+		return CreateTx(tx, notices...)
+	}
+}
+
+// CreateTx stores notifications inside tx, for changes that aren't covered by a Rule.
+func CreateTx(tx *gorm.DB, notices ...Notice) error {
+	if len(notices) == 0 {
+		return nil
+	}
+	records := make([]Notification, 0, len(notices))
+	for _, n := range notices {
+		status := EmailPending
+		if n.InAppOnly {
+			status = EmailSkipped
+		}
+		records = append(records, Notification{
+			UserID:         n.UserID,
+			OrganizationID: n.OrganizationID,
+			Category:       n.Category,
+			Urgent:         n.Urgent || IsCritical(n.Category),
+			Mandatory:      n.Mandatory,
+			Subject:        truncate(n.Subject, 255),
+			Body:           n.Body,
+			Link:           n.Link,
+			ApprovalKind:   n.ApprovalKind,
+			ApprovalID:     n.ApprovalID,
+			EmailStatus:    status,
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+// DefaultRules notify approvers of new role requests and requesters of decisions.
+func DefaultRules() map[string]Rule {
+	return map[string]Rule{
+		"role_request.create":   roleRequestCreated,
+		"role_request.approved": roleRequestDecided,
+		"role_request.rejected": roleRequestDecided,
+	}
+}
+
+// roleRequestCreated asks every god-admin but the requester to decide.
+func roleRequestCreated(tx *gorm.DB, actor audit.Actor, record *audit.Log) ([]Notice, error) {
+	var request auth.RoleRequest
+	if err := json.Unmarshal(record.After, &request); err != nil {
+		return nil, fmt.Errorf("failed to decode role request: %w", err)
+	}
+	var subject auth.User
+	if err := tx.Select("id", "username").First(&subject, request.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user %d: %w", request.UserID, err)
+	}
+	var approvers []uint
+	if err := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", "god-admin").Pluck("users.id", &approvers).Error; err != nil {
+		return nil, fmt.Errorf("failed to find approvers: %w", err)
+	}
+	notices := make([]Notice, 0, len(approvers))
+	for _, id := range approvers {
+		if actor.UserID != nil && *actor.UserID == id {
+			continue
+		}
+		notices = append(notices, Notice{
+			UserID:       id,
+			Category:     "approval.role_request",
+			Subject:      fmt.Sprintf("%s requests the %s role for %s", actor.Username, request.Role.Name, subject.Username),
+			Body:         request.Reason,
+			Link:         "/admin/role-requests",
+			ApprovalKind: auth.RoleRequestApprovalKind,
+			ApprovalID:   request.ID,
+		})
+	}
+	return notices, nil
+}
+
+// roleRequestDecided tells the user who gets the role, and whoever requested it for them.
+func roleRequestDecided(tx *gorm.DB, actor audit.Actor, record *audit.Log) ([]Notice, error) {
+	var request auth.RoleRequest
+	if err := json.Unmarshal(record.After, &request); err != nil {
+		return nil, fmt.Errorf("failed to decode role request: %w", err)
+	}
+	recipients := []uint{request.UserID}
+	if request.RequestedBy != nil && *request.RequestedBy != request.UserID {
+		recipients = append(recipients, *request.RequestedBy)
+	}
+	notices := make([]Notice, 0, len(recipients))
+	for _, id := range recipients {
+		notices = append(notices, Notice{
+			UserID:   id,
+			Category: "role_request.decision",
+			Subject:  fmt.Sprintf("The request for the %s role was %s", request.Role.Name, request.Status),
+			Body:     request.DecisionNote,
+		})
+	}
+	return notices, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
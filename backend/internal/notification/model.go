@@ -0,0 +1,115 @@
+// prometheus/backend/internal/notification/model.go
+package notification
+
+import (
+	"strings"
+	"time"
+)
+
+// EmailStatus tracks whether a notification was emailed.
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending" // Waiting for the next dispatch, or for the user's digest
+	EmailSent    EmailStatus = "sent"    // Queued for delivery, alone or in a digest
+	EmailSkipped EmailStatus = "skipped" // In-app only, turned off by the user, or the user can't receive email anymore
+	EmailFailed  EmailStatus = "failed"  // Queued, but the mail server never accepted it
+)
+
+// Critical categories, emailed at once whatever the user's quiet hours, digest and email settings.
+// Subcategories such as "security.new_device" are critical too.
+const (
+	CategorySecurity       = "security"
+	CategoryPayrollFailure = "payroll.failure"
+)
+
+var criticalCategories = []string{CategorySecurity, CategoryPayrollFailure}
+
+// IsCritical reports whether notifications of category override quiet hours.
+func IsCritical(category string) bool {
+	for _, c := range criticalCategories {
+		if category == c || strings.HasPrefix(category, c+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user about something that happened, in the app and by email.
+type Notification struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	UserID         uint        `gorm:"not null;index:idx_notification_user" json:"-"`
+	OrganizationID *uint       `gorm:"index" json:"-"`
+	Category       string      `gorm:"type:varchar(100);not null" json:"category" example:"approval.role_request"`
+	Urgent         bool        `gorm:"not null;default:false" json:"urgent"`    // Emailed right away, bypassing digests
+	Mandatory      bool        `gorm:"not null;default:false" json:"mandatory"` // Emailed even if the user turned the category off; receipts are reported to HR
+	Subject        string      `gorm:"type:varchar(255);not null" json:"subject" example:"Role request awaiting your decision"`
+	Body           string      `gorm:"type:text" json:"body,omitempty"`
+	Link           string      `gorm:"type:varchar(500)" json:"link,omitempty" example:"/admin/role-requests"` // Frontend path
+	ApprovalKind   string      `gorm:"type:varchar(50)" json:"approval_kind,omitempty" example:"role_request"` // Request the user may decide by email, see approval.Service
+	ApprovalID     uint        `json:"approval_id,omitempty" example:"4"`
+	SeenAt         *time.Time  `json:"seen_at,omitempty"` // First listed in the app
+	ReadAt         *time.Time  `json:"read_at,omitempty"`
+	EmailStatus    EmailStatus `gorm:"type:varchar(20);not null;index" json:"email_status" example:"pending"`
+	EmailedAt      *time.Time  `json:"emailed_at,omitempty"`
+	EmailMessageID string      `gorm:"type:varchar(36);index" json:"-"` // Outbox message of the email, shared by a digest's notifications
+	DeliveredAt    *time.Time  `json:"delivered_at,omitempty"`          // The mail server accepted the email
+	OpenedAt       *time.Time  `json:"opened_at,omitempty"`             // A link in the email was followed, see Tracker
+	EmailAfter     *time.Time  `gorm:"index" json:"-"`                  // Held until the user's quiet hours or digest period are over
+	CreatedAt      time.Time   `gorm:"index:idx_notification_user" json:"created_at"`
+}
+
+// Notice is a notification to create, see Rule.
+type Notice struct {
+	UserID         uint
+	OrganizationID *uint
+	Category       string
+	Urgent         bool
+	Mandatory      bool
+	Subject        string
+	Body           string
+	Link           string
+	ApprovalKind   string // Adds approve/reject links to the email, see approval.Service
+	ApprovalID     uint
+	InAppOnly      bool // Never emailed
+}
+
+// StatsFilter narrows delivery statistics and receipts.
+type StatsFilter struct {
+	Category      string     // Exact category
+	MandatoryOnly bool       // Only mandatory notifications
+	From          *time.Time // Created at or after
+	To            *time.Time // Created before
+	UnreadOnly    bool       // Receipts only: not read in the app yet
+}
+
+// Stats counts how far a category's notifications got, per channel.
+type Stats struct {
+	Category  string `json:"category" example:"policy.acknowledgement"`
+	Total     int64  `json:"total" example:"120"`
+	Seen      int64  `json:"seen" example:"96"` // Listed in the app
+	Read      int64  `json:"read" example:"80"` // Marked read in the app
+	Emailed   int64  `json:"emailed"`           // Emails queued, alone or in a digest
+	Delivered int64  `json:"delivered"`         // Emails accepted by the mail server
+	Failed    int64  `json:"failed"`            // Emails given up on
+	Opened    int64  `json:"opened"`            // Emails whose link was followed (with tracking only)
+	Skipped   int64  `json:"skipped"`           // Emails turned off by the user, or users without email
+	Pending   int64  `json:"pending"`           // Emails waiting for a digest or the end of quiet hours
+}
+
+// Receipt is the delivery state of one user's notification.
+type Receipt struct {
+	NotificationID uint        `json:"notification_id"`
+	UserID         uint        `json:"user_id"`
+	Username       string      `json:"username" example:"jdoe"`
+	Category       string      `json:"category"`
+	Subject        string      `json:"subject"`
+	Mandatory      bool        `json:"mandatory"`
+	CreatedAt      time.Time   `json:"created_at"`
+	SeenAt         *time.Time  `json:"seen_at,omitempty"`
+	ReadAt         *time.Time  `json:"read_at,omitempty"`
+	EmailStatus    EmailStatus `json:"email_status"`
+	EmailedAt      *time.Time  `json:"emailed_at,omitempty"`
+	DeliveredAt    *time.Time  `json:"delivered_at,omitempty"`
+	OpenedAt       *time.Time  `json:"opened_at,omitempty"`
+}
@@ -0,0 +1,104 @@
+// prometheus/backend/internal/notification/module.go
+package notification
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobDispatch is the recurring job type that emails pending notifications.
+const JobDispatch = "notification.dispatch"
+
+// dispatchInterval is how often pending notifications are looked at; also the delay of "immediate" emails.
+const dispatchInterval = time.Minute
+
+// notificationModule owns in-app notifications and their emails.
+type notificationModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the notifications module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &notificationModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *notificationModule) Name() string { return "notifications" }
+
+// HealthContributors implements module.Module. Digests hold emails back for a day at most, so anything
+// pending for longer wasn't dispatched.
+func (m *notificationModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("dispatch", func(ctx context.Context) module.HealthResult {
+			var pending, overdue int64
+			db := m.db.WithContext(ctx).Model(&Notification{})
+			if err := db.Where("email_status = ?", EmailPending).Count(&pending).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := m.db.WithContext(ctx).Model(&Notification{}).Where("email_status = ? AND created_at < ?",
+				EmailPending, clock.Now().UTC().Add(-26*time.Hour)).Count(&overdue).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if overdue > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"pending": float64(pending), "overdue": float64(overdue)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *notificationModule) Models() []any {
+	return []any{&Notification{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *notificationModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobDispatch, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		sent, failed, err := m.service.Dispatch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"sent": sent, "failed": failed}, nil
+	})
+	q.Every(JobDispatch, dispatchInterval)
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *notificationModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/notifications", routing.Authenticated(), m.handler.List)
+	api.GET("/me/notifications/unread-count", routing.Authenticated(), m.handler.UnreadCount)
+	api.POST("/me/notifications/read", routing.Authenticated(), m.handler.MarkAllRead)
+	api.POST("/me/notifications/:id/read", routing.Authenticated(), m.handler.MarkRead)
+	api.GET("/notifications/open/:token", routing.Public(), m.handler.Open)
+	api.GET("/hr/notifications/stats", routing.Policy(), m.handler.Stats)
+	api.GET("/hr/notifications/receipts", routing.Policy(), m.handler.Receipts)
+}
+
+// PrivacySources implements privacy.Contributor: notifications are exported, and deleted on anonymization.
+func (m *notificationModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{
+		privacy.NewSource("notifications", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+			var notifications []Notification
+			if err := db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&notifications).Error; err != nil {
+				return nil, fmt.Errorf("failed to export notifications: %w", err)
+			}
+			return notifications, nil
+		}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+			if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&Notification{}).Error; err != nil {
+				return fmt.Errorf("failed to delete notifications: %w", err)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,489 @@
+// prometheus/backend/internal/notification/service.go
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/approval"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/mail"
+	"prometheus/backend/internal/outbox"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dispatchBatch bounds how many users' notifications one dispatch run emails.
+const dispatchBatch = 200
+
+// digestHour is when daily digests go out, in the user's timezone.
+const digestHour = 8
+
+// Service manages users' notifications and emails them according to their preferences.
+type Service interface {
+	// List returns the user's notifications, newest first, and records them as seen.
+	List(userID uint, unreadOnly bool, page utils.Pagination) ([]Notification, int64, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(userID, notificationID uint) error
+	MarkAllRead(userID uint) (int64, error)
+	// Dispatch emails pending notifications: urgent ones and those of users without a digest one by one,
+	// the others as a digest once it is due. Outside critical categories, nothing goes out during the
+	// user's quiet hours.
+	Dispatch(ctx context.Context) (sent, failed int, err error)
+	// Open records that a notification's email was opened through a tracked link and returns where to
+	// send the reader: the notification's link, or the inbox for unknown tokens.
+	Open(token string) (string, error)
+	// Stats counts delivery and read receipts per category, for the organization's users.
+	Stats(orgID *uint, filter StatsFilter) ([]Stats, error)
+	// Receipts lists the delivery state of each user's notification, e.g. who hasn't read a mandatory one.
+	Receipts(orgID *uint, filter StatsFilter, page utils.Pagination) ([]Receipt, int64, error)
+	// Preview renders the email a notice is sent as on its own, addressed to username.
+	Preview(orgID *uint, username string, n Notice) (subject, body string, err error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db         *gorm.DB
+	outbox     *outbox.Outbox
+	templates  mail.TemplateService
+	prefs      auth.PreferenceService
+	appBaseURL string
+	tracker    *Tracker
+	approvals  *approval.Service
+}
+
+// NewService creates a new instance of Service. Emails are rendered with templates and queued in messages;
+// notification links are relative to appBaseURL, the frontend. With a tracker, email links record opens;
+// approvals signs approve/reject links for notifications about requests the recipient may decide.
+func NewService(db *gorm.DB, messages *outbox.Outbox, templates mail.TemplateService, prefs auth.PreferenceService, appBaseURL string, tracker *Tracker, approvals *approval.Service) Service {
+	return &service{
+		db: db, outbox: messages, templates: templates, prefs: prefs, appBaseURL: strings.TrimRight(appBaseURL, "/"),
+		tracker: tracker, approvals: approvals,
+	}
+}
+
+func (s *service) List(userID uint, unreadOnly bool, page utils.Pagination) ([]Notification, int64, error) {
+	query := s.db.Model(&Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	var notifications []Notification
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	var unseen []uint
+	now := clock.Now().UTC()
+	for i := range notifications {
+		if notifications[i].SeenAt == nil {
+			unseen = append(unseen, notifications[i].ID)
+			notifications[i].SeenAt = &now
+		}
+	}
+	if len(unseen) > 0 {
+		if err := s.db.Model(&Notification{}).Where("id IN ? AND seen_at IS NULL", unseen).Update("seen_at", now).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to mark notifications seen: %w", err)
+		}
+	}
+	return notifications, total, nil
+}
+
+func (s *service) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	if err := s.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (s *service) MarkRead(userID, notificationID uint) error {
+	result := s.db.Model(&Notification{}).Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		Update("read_at", clock.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark notification %d read: %w", notificationID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := s.db.Model(&Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to load notification %d: %w", notificationID, err)
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+func (s *service) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", clock.Now().UTC())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Dispatch handles one user at a time, so a user whose emails fail to render doesn't hold up the others.
+// Held notifications are skipped until their time, so users in quiet hours don't fill up the batch.
+func (s *service) Dispatch(ctx context.Context) (sent, failed int, err error) {
+	now := clock.Now().UTC()
+	if err := s.reconcile(ctx); err != nil {
+		return 0, 0, err
+	}
+	var userIDs []uint
+	if err := s.db.WithContext(ctx).Model(&Notification{}).
+		Where("email_status = ? AND (email_after IS NULL OR email_after <= ?)", EmailPending, now).
+		Distinct("user_id").Limit(dispatchBatch).Pluck("user_id", &userIDs).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to find pending notifications: %w", err)
+	}
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return sent, failed, err
+		}
+		n, err := s.dispatchUser(ctx, userID, now)
+		if err != nil {
+			log.Printf("Notifications: failed to email user %d: %v", userID, err)
+			failed++
+			continue
+		}
+		sent += n
+	}
+	return sent, failed, nil
+}
+
+// dispatchUser emails a user's pending notifications that are due and returns how many emails were queued.
+// All pending notifications are reconsidered, so a critical one arriving during quiet hours doesn't
+// release the others.
+func (s *service) dispatchUser(ctx context.Context, userID uint, now time.Time) (int, error) {
+	prefs, err := s.prefs.Get(userID)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	var emails int
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Another instance dispatching at the same time skips the rows locked here.
+		var pending []Notification
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_id = ? AND email_status = ?", userID, EmailPending).Order("created_at, id").Find(&pending).Error; err != nil {
+			return fmt.Errorf("failed to load notifications: %w", err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		var user auth.User
+		err := tx.Select("id", "username", "email", "is_active", "organization_id").First(&user, userID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+		if err != nil || !user.IsActive {
+			return markEmail(tx, ids(pending), EmailSkipped, now)
+		}
+
+		quietEnd, quiet := quietUntil(prefs.QuietHours, now, loc)
+		var single, digest, held []Notification
+		var skipped []uint
+		for _, n := range pending {
+			switch enabled, set := prefs.Notifications["email."+n.Category]; {
+			case set && !enabled && !n.Urgent && !n.Mandatory:
+				skipped = append(skipped, n.ID)
+			case quiet && !IsCritical(n.Category):
+				held = append(held, n)
+			case n.Urgent || prefs.Digest == auth.DigestImmediate:
+				single = append(single, n)
+			default:
+				digest = append(digest, n)
+			}
+		}
+		if err := markEmail(tx, skipped, EmailSkipped, now); err != nil {
+			return err
+		}
+		if err := holdEmail(tx, ids(held), quietEnd); err != nil {
+			return err
+		}
+		for _, n := range single {
+			approve, reject := s.approvalLinks(n)
+			messageID, err := s.queue(tx, &user, ImmediateTemplate, map[string]interface{}{
+				"Username": user.Username, "Subject": n.Subject, "Body": n.Body, "Link": s.emailLink(n),
+				"ApproveLink": approve, "RejectLink": reject,
+			})
+			if err != nil {
+				return err
+			}
+			if err := markSent(tx, []uint{n.ID}, messageID, now); err != nil {
+				return err
+			}
+			emails++
+		}
+		if len(digest) == 0 {
+			return nil
+		}
+		if due := digestDue(prefs.Digest, digest[0].CreatedAt, loc); now.Before(due) {
+			return holdEmail(tx, ids(digest), due)
+		}
+		items := make([]map[string]interface{}, 0, len(digest))
+		for _, n := range digest {
+			approve, reject := s.approvalLinks(n)
+			items = append(items, map[string]interface{}{
+				"Subject": n.Subject, "Link": s.emailLink(n), "ApproveLink": approve, "RejectLink": reject,
+			})
+		}
+		messageID, err := s.queue(tx, &user, DigestTemplate, map[string]interface{}{
+			"Username": user.Username, "Count": len(digest), "Items": items, "InboxLink": s.link("/notifications"),
+		})
+		if err != nil {
+			return err
+		}
+		emails++
+		return markSent(tx, ids(digest), messageID, now)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return emails, nil
+}
+
+// queue renders a notification email, queues it in tx and returns the outbox message ID.
+func (s *service) queue(tx *gorm.DB, user *auth.User, template string, data map[string]interface{}) (string, error) {
+	subject, body, err := s.templates.Render(user.OrganizationID, template, data)
+	if err != nil {
+		return "", err
+	}
+	msg, err := s.outbox.AddTx(tx, mail.OutboxKind, mail.Message{To: []string{user.Email}, Subject: subject, Body: body})
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+// reconcile copies the outcome of queued emails from the outbox, before sent messages are cleaned up.
+func (s *service) reconcile(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	if err := db.Exec(`UPDATE notifications SET delivered_at = outbox_messages.sent_at FROM outbox_messages
+		WHERE outbox_messages.id = notifications.email_message_id AND notifications.delivered_at IS NULL
+		AND outbox_messages.status = ?`, outbox.StatusSent).Error; err != nil {
+		return fmt.Errorf("failed to record email deliveries: %w", err)
+	}
+	if err := db.Exec(`UPDATE notifications SET email_status = ? FROM outbox_messages
+		WHERE outbox_messages.id = notifications.email_message_id AND notifications.email_status = ?
+		AND outbox_messages.status = ?`, EmailFailed, EmailSent, outbox.StatusDead).Error; err != nil {
+		return fmt.Errorf("failed to record email failures: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Open(token string) (string, error) {
+	inbox := s.link("/notifications")
+	if s.tracker == nil {
+		return inbox, nil
+	}
+	id, ok := s.tracker.verify(token)
+	if !ok {
+		return inbox, nil
+	}
+	var n Notification
+	if err := s.db.Select("id", "link").First(&n, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return inbox, nil
+		}
+		return "", fmt.Errorf("failed to load notification %d: %w", id, err)
+	}
+	if err := s.db.Model(&Notification{}).Where("id = ? AND opened_at IS NULL", id).Update("opened_at", clock.Now().UTC()).Error; err != nil {
+		return "", fmt.Errorf("failed to record notification %d opened: %w", id, err)
+	}
+	if n.Link == "" {
+		return inbox, nil
+	}
+	return s.link(n.Link), nil
+}
+
+func (s *service) Stats(orgID *uint, filter StatsFilter) ([]Stats, error) {
+	stats := []Stats{}
+	err := s.receipts(orgID, filter).Select(`notifications.category,
+		COUNT(*) AS total,
+		COUNT(notifications.seen_at) AS seen,
+		COUNT(notifications.read_at) AS read,
+		COUNT(*) FILTER (WHERE notifications.email_status IN ?) AS emailed,
+		COUNT(notifications.delivered_at) AS delivered,
+		COUNT(*) FILTER (WHERE notifications.email_status = ?) AS failed,
+		COUNT(notifications.opened_at) AS opened,
+		COUNT(*) FILTER (WHERE notifications.email_status = ?) AS skipped,
+		COUNT(*) FILTER (WHERE notifications.email_status = ?) AS pending`,
+		[]EmailStatus{EmailSent, EmailFailed}, EmailFailed, EmailSkipped, EmailPending).
+		Group("notifications.category").Order("notifications.category").Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notification receipts: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *service) Receipts(orgID *uint, filter StatsFilter, page utils.Pagination) ([]Receipt, int64, error) {
+	query := s.receipts(orgID, filter)
+	if filter.UnreadOnly {
+		query = query.Where("notifications.read_at IS NULL")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notification receipts: %w", err)
+	}
+	receipts := []Receipt{}
+	if err := query.Select("notifications.id AS notification_id, notifications.*, users.username").
+		Order("notifications.created_at DESC, notifications.id DESC").Scopes(page.Scope).Scan(&receipts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notification receipts: %w", err)
+	}
+	return receipts, total, nil
+}
+
+func (s *service) Preview(orgID *uint, username string, n Notice) (string, string, error) {
+	return s.templates.Render(orgID, ImmediateTemplate, map[string]interface{}{
+		"Username": username, "Subject": n.Subject, "Body": n.Body, "Link": s.link(n.Link),
+		"ApproveLink": "", "RejectLink": "",
+	})
+}
+
+// receipts selects notifications of the organization's users matching filter. Notifications are scoped
+// through their users, since not every rule knows the organization.
+func (s *service) receipts(orgID *uint, filter StatsFilter) *gorm.DB {
+	query := s.db.Model(&Notification{}).Joins("JOIN users ON users.id = notifications.user_id")
+	if orgID != nil {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	if filter.Category != "" {
+		query = query.Where("notifications.category = ?", filter.Category)
+	}
+	if filter.MandatoryOnly {
+		query = query.Where("notifications.mandatory")
+	}
+	if filter.From != nil {
+		query = query.Where("notifications.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("notifications.created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// approvalLinks returns the one-click approve and reject links of a notification about a request the
+// recipient may decide, or empty strings.
+func (s *service) approvalLinks(n Notification) (approve, reject string) {
+	if s.approvals == nil || n.ApprovalKind == "" {
+		return "", ""
+	}
+	return s.approvals.Links(n.UserID, n.ApprovalKind, n.ApprovalID)
+}
+
+// emailLink is the link of a notification in emails: tracked if enabled, else straight to the app.
+func (s *service) emailLink(n Notification) string {
+	if s.tracker != nil {
+		return s.tracker.URL(n.ID)
+	}
+	return s.link(n.Link)
+}
+
+// link makes a notification's frontend path absolute for emails.
+func (s *service) link(path string) string {
+	if path == "" {
+		return ""
+	}
+	return s.appBaseURL + path
+}
+
+// digestDue returns when the digest holding a notification created at oldest goes out: once its hour is
+// over, or at digestHour the next morning in the user's timezone.
+func digestDue(mode string, oldest time.Time, loc *time.Location) time.Time {
+	if mode == auth.DigestHourly {
+		return oldest.Truncate(time.Hour).Add(time.Hour)
+	}
+	local := oldest.In(loc)
+	due := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, loc)
+	if !due.After(local) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return due
+}
+
+// quietUntil reports whether now falls into the quiet hours and, if so, when they end.
+func quietUntil(q *auth.QuietHours, now time.Time, loc *time.Location) (time.Time, bool) {
+	if q == nil {
+		return time.Time{}, false
+	}
+	start, err1 := time.Parse("15:04", q.Start)
+	end, err2 := time.Parse("15:04", q.End)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, false
+	}
+	local := now.In(loc)
+	at := func(t time.Time, days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, t.Hour(), t.Minute(), 0, 0, loc)
+	}
+	startToday, endToday := at(start, 0), at(end, 0)
+	if startToday.Before(endToday) {
+		if !local.Before(startToday) && local.Before(endToday) {
+			return endToday, true
+		}
+		return time.Time{}, false
+	}
+	// Spanning midnight: quiet after the start tonight, or before the end this morning.
+	if !local.Before(startToday) {
+		return at(end, 1), true
+	}
+	if local.Before(endToday) {
+		return endToday, true
+	}
+	return time.Time{}, false
+}
+
+// holdEmail keeps notifications pending until the given time. Dispatch reconsiders them then, with the
+// user's preferences at that time.
+func holdEmail(tx *gorm.DB, notificationIDs []uint, until time.Time) error {
+	if len(notificationIDs) == 0 {
+		return nil
+	}
+	if err := tx.Model(&Notification{}).Where("id IN ?", notificationIDs).Update("email_after", until.UTC()).Error; err != nil {
+		return fmt.Errorf("failed to hold notifications: %w", err)
+	}
+	return nil
+}
+
+// markSent records notifications as emailed with the outbox message that carries them.
+func markSent(tx *gorm.DB, notificationIDs []uint, messageID string, now time.Time) error {
+	if err := tx.Model(&Notification{}).Where("id IN ?", notificationIDs).Updates(map[string]interface{}{
+		"email_status": EmailSent, "emailed_at": now, "email_message_id": messageID,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update notifications: %w", err)
+	}
+	return nil
+}
+
+func markEmail(tx *gorm.DB, notificationIDs []uint, status EmailStatus, now time.Time) error {
+	if len(notificationIDs) == 0 {
+		return nil
+	}
+	updates := map[string]interface{}{"email_status": status}
+	if status == EmailSent {
+		updates["emailed_at"] = now
+	}
+	if err := tx.Model(&Notification{}).Where("id IN ?", notificationIDs).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update notifications: %w", err)
+	}
+	return nil
+}
+
+func ids(notifications []Notification) []uint {
+	list := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		list = append(list, n.ID)
+	}
+	return list
+}
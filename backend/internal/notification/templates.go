@@ -0,0 +1,50 @@
+// prometheus/backend/internal/notification/templates.go
+package notification
+
+import "prometheus/backend/internal/mail"
+
+// Email templates of notifications, customizable per organization like every mail.Template.
+const (
+	ImmediateTemplate = "notification.immediate" // A single notification
+	DigestTemplate    = "notification.digest"    // A summary of the notifications since the last digest
+)
+
+func init() {
+	mail.RegisterTemplate(mail.Template{
+		Name:        ImmediateTemplate,
+		Description: "A notification, for users receiving them one by one and for urgent ones",
+		Subject:     "{{.Subject}}",
+		Body: "Hello {{.Username}},\n\n{{.Subject}}\n{{if .Body}}\n{{.Body}}\n{{end}}{{if .Link}}\nOpen: {{.Link}}\n{{end}}" +
+			"{{if .ApproveLink}}\nApprove: {{.ApproveLink}}\nReject: {{.RejectLink}}\n{{end}}",
+		Sample: map[string]interface{}{
+			"Username": "jdoe",
+			"Subject":  "godadmin requests the hr role for jdoe",
+			"Body":     "Covering HR during parental leave",
+			"Link":     "https://hris.example.com/admin/role-requests",
+			// Signed one-click links for approvers, empty for other notifications
+			"ApproveLink": "https://api.hris.example.com/api/v1/actions/eyJ1Ijo3fQ.approve",
+			"RejectLink":  "https://api.hris.example.com/api/v1/actions/eyJ1Ijo3fQ.reject",
+		},
+	})
+	mail.RegisterTemplate(mail.Template{
+		Name:        DigestTemplate,
+		Description: "Hourly or daily summary of notifications",
+		Subject:     "{{.Count}} new notification{{if gt .Count 1}}s{{end}}",
+		Body: "Hello {{.Username}},\n\nhere is what happened since your last summary:\n\n" +
+			"{{range .Items}}- {{.Subject}}{{if .Link}}\n  {{.Link}}{{end}}" +
+			"{{if .ApproveLink}}\n  Approve: {{.ApproveLink}}\n  Reject: {{.RejectLink}}{{end}}\n{{end}}\nAll notifications: {{.InboxLink}}\n",
+		Sample: map[string]interface{}{
+			"Username": "jdoe",
+			"Count":    2,
+			"Items": []map[string]interface{}{
+				{
+					"Subject": "godadmin requests the hr role for jdoe", "Link": "https://hris.example.com/admin/role-requests",
+					"ApproveLink": "https://api.hris.example.com/api/v1/actions/eyJ1Ijo3fQ.approve",
+					"RejectLink":  "https://api.hris.example.com/api/v1/actions/eyJ1Ijo3fQ.reject",
+				},
+				{"Subject": "The request for the manager role was approved", "Link": "", "ApproveLink": "", "RejectLink": ""},
+			},
+			"InboxLink": "https://hris.example.com/notifications",
+		},
+	})
+}
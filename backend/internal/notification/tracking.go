@@ -0,0 +1,49 @@
+// prometheus/backend/internal/notification/tracking.go
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tracker routes the links in notification emails through the API, which records that the email was
+// opened and redirects to the app. Emails are plain text, so there is no tracking pixel: following a link
+// is the only signal, and opens are undercounted accordingly.
+type Tracker struct {
+	secret     []byte
+	apiBaseURL string
+}
+
+// NewTracker creates a Tracker signing links with secret; apiBaseURL is the public URL of this API.
+func NewTracker(secret, apiBaseURL string) *Tracker {
+	return &Tracker{secret: []byte(secret), apiBaseURL: strings.TrimRight(apiBaseURL, "/")}
+}
+
+// URL returns the tracked link of a notification.
+func (t *Tracker) URL(notificationID uint) string {
+	id := strconv.FormatUint(uint64(notificationID), 10)
+	return fmt.Sprintf("%s/api/v1/notifications/open/%s.%s", t.apiBaseURL, id, t.sign(id))
+}
+
+// verify returns the notification of a tracked link's token.
+func (t *Tracker) verify(token string) (uint, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(t.sign(id))) {
+		return 0, false
+	}
+	notificationID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(notificationID), true
+}
+
+func (t *Tracker) sign(id string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte("notification-open:" + id))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
@@ -0,0 +1,420 @@
+// prometheus/backend/internal/offboarding/handler.go
+package offboarding
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hr is the viewer of /hr routes, which see every offboarding of the organization.
+var hr = Viewer{HR: true}
+
+// Handler handles HTTP requests for offboardings and their exit checklists.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's offboardings, earliest termination date first.
+// @Summary List offboardings
+// @Tags Offboarding
+// @Produce json
+// @Param status query string false "Status" Enums(scheduled, completed, cancelled)
+// @Param employee_id query int false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/offboardings [get]
+func (h *Handler) List(c *gin.Context) {
+	h.list(c, hr)
+}
+
+// TeamList returns the offboardings of the caller's direct reports.
+// @Summary List my reports' offboardings
+// @Tags Offboarding
+// @Produce json
+// @Param status query string false "Status" Enums(scheduled, completed, cancelled)
+// @Param employee_id query int false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/offboardings [get]
+func (h *Handler) TeamList(c *gin.Context) {
+	h.list(c, team(c))
+}
+
+func (h *Handler) list(c *gin.Context, viewer Viewer) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusScheduled, StatusCompleted, StatusCancelled:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	page := utils.ParsePagination(c)
+	offboardings, total, err := h.service.List(utils.OrganizationFromContext(c), viewer, filter, page)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Offboardings fetched successfully", page.Response(offboardings, total))
+}
+
+// Get returns an offboarding with its exit checklist.
+// @Summary Get an offboarding
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Router /hr/offboardings/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	h.get(c, hr)
+}
+
+// TeamGet returns an offboarding of one of the caller's direct reports.
+// @Summary Get my report's offboarding
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Router /manager/offboardings/{id} [get]
+func (h *Handler) TeamGet(c *gin.Context) {
+	h.get(c, team(c))
+}
+
+func (h *Handler) get(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	offboarding, err := h.service.Get(utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, offboarding.UpdatedAt, offboarding.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Offboarding fetched successfully", offboarding)
+}
+
+// Mine returns the caller's own offboarding and exit checklist.
+// @Summary Get my offboarding
+// @Tags Offboarding
+// @Produce json
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "No offboarding scheduled"
+// @Router /me/offboarding [get]
+func (h *Handler) Mine(c *gin.Context) {
+	offboarding, err := h.service.Mine(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Offboarding fetched successfully", offboarding)
+}
+
+// Create schedules an employee's departure.
+// @Summary Schedule an offboarding
+// @Description Records the employee's last day and generates the exit checklist: knowledge transfer and
+// @Description asset return for the manager, the exit interview and an access review for HR, plus any extra
+// @Description tasks given. With asset tracking, the manager also collects each asset the employee holds; those
+// @Description tasks are ticked off when the asset is returned. The employee's line manager is notified. The day
+// @Description after the termination date, the scheduler deactivates the employee's user and ends all of their sessions.
+// @Tags Offboarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param offboarding body Request true "Offboarding"
+// @Success 201 {object} Offboarding
+// @Failure 400 {object} utils.ErrorResponse "Invalid offboarding or termination date in the past"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Employee already has an offboarding"
+// @Router /hr/employees/{id}/offboarding [post]
+func (h *Handler) Create(c *gin.Context) {
+	employeeID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	offboarding, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), employeeID, req)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, offboarding.UpdatedAt, offboarding.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Offboarding scheduled successfully", offboarding)
+}
+
+// Update moves the termination date of a scheduled offboarding or changes its reason.
+// @Summary Update an offboarding
+// @Description Tasks are left as they are; extra tasks given are ignored.
+// @Tags Offboarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param offboarding body Request true "Offboarding"
+// @Success 200 {object} Offboarding
+// @Failure 400 {object} utils.ErrorResponse "Invalid offboarding or termination date in the past"
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding completed or cancelled"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/offboardings/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, hr, id)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	offboarding, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, offboarding.UpdatedAt, offboarding.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Offboarding updated successfully", offboarding)
+}
+
+// Cancel withdraws a scheduled offboarding.
+// @Summary Cancel an offboarding
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding completed or cancelled"
+// @Router /hr/offboardings/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	offboarding, err := h.service.Cancel(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, offboarding.UpdatedAt, offboarding.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Offboarding cancelled successfully", offboarding)
+}
+
+// Revoke revokes the employee's access right away instead of after the termination date.
+// @Summary Revoke an offboarded employee's access now
+// @Description Deactivates the employee's user and ends all of their sessions, and completes the offboarding.
+// @Description The exit checklist can still be worked through afterwards.
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Success 200 {object} Offboarding
+// @Failure 400 {object} utils.ErrorResponse "Own offboarding"
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding completed or cancelled"
+// @Router /hr/offboardings/{id}/revoke [post]
+func (h *Handler) Revoke(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	offboarding, err := h.service.RevokeNow(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, offboarding.UpdatedAt, offboarding.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Access revoked successfully", offboarding)
+}
+
+// AddTask adds a task to an exit checklist.
+// @Summary Add an exit checklist task
+// @Tags Offboarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param task body TaskRequest true "Task"
+// @Success 201 {object} Offboarding
+// @Failure 400 {object} utils.ErrorResponse "Invalid task"
+// @Failure 404 {object} utils.ErrorResponse "Offboarding not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding cancelled"
+// @Router /hr/offboardings/{id}/tasks [post]
+func (h *Handler) AddTask(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req TaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	offboarding, err := h.service.AddTask(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Task added successfully", offboarding)
+}
+
+// CompleteTask ticks off a task of an exit checklist.
+// @Summary Complete an exit checklist task
+// @Tags Offboarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param task_id path int true "Task ID"
+// @Param completion body CompleteRequest false "Note"
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "Offboarding or task not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding cancelled"
+// @Router /hr/offboardings/{id}/tasks/{task_id}/complete [post]
+func (h *Handler) CompleteTask(c *gin.Context) {
+	h.completeTask(c, hr)
+}
+
+// TeamCompleteTask ticks off a manager's task of a direct report's exit checklist.
+// @Summary Complete my part of an exit checklist
+// @Tags Offboarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param task_id path int true "Task ID"
+// @Param completion body CompleteRequest false "Note"
+// @Success 200 {object} Offboarding
+// @Failure 403 {object} utils.ErrorResponse "Task not owned by managers"
+// @Failure 404 {object} utils.ErrorResponse "Offboarding or task not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding cancelled"
+// @Router /manager/offboardings/{id}/tasks/{task_id}/complete [post]
+func (h *Handler) TeamCompleteTask(c *gin.Context) {
+	h.completeTask(c, team(c))
+}
+
+func (h *Handler) completeTask(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	taskID, ok := utils.ParseUintParam(c, "task_id")
+	if !ok {
+		return
+	}
+	var req CompleteRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	offboarding, err := h.service.CompleteTask(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer, id, taskID, req)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Task completed successfully", offboarding)
+}
+
+// ReopenTask marks a task of an exit checklist as not done.
+// @Summary Reopen an exit checklist task
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param task_id path int true "Task ID"
+// @Success 200 {object} Offboarding
+// @Failure 404 {object} utils.ErrorResponse "Offboarding or task not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding cancelled"
+// @Router /hr/offboardings/{id}/tasks/{task_id}/reopen [post]
+func (h *Handler) ReopenTask(c *gin.Context) {
+	h.reopenTask(c, hr)
+}
+
+// TeamReopenTask marks a manager's task of a direct report's exit checklist as not done.
+// @Summary Reopen my part of an exit checklist
+// @Tags Offboarding
+// @Produce json
+// @Param id path int true "Offboarding ID"
+// @Param task_id path int true "Task ID"
+// @Success 200 {object} Offboarding
+// @Failure 403 {object} utils.ErrorResponse "Task not owned by managers"
+// @Failure 404 {object} utils.ErrorResponse "Offboarding or task not found"
+// @Failure 409 {object} utils.ErrorResponse "Offboarding cancelled"
+// @Router /manager/offboardings/{id}/tasks/{task_id}/reopen [post]
+func (h *Handler) TeamReopenTask(c *gin.Context) {
+	h.reopenTask(c, team(c))
+}
+
+func (h *Handler) reopenTask(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	taskID, ok := utils.ParseUintParam(c, "task_id")
+	if !ok {
+		return
+	}
+	offboarding, err := h.service.ReopenTask(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer, id, taskID)
+	if err != nil {
+		sendOffboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Task reopened successfully", offboarding)
+}
+
+// team is the viewer of /manager routes, which see their direct reports' offboardings.
+func team(c *gin.Context) Viewer {
+	return Viewer{UserID: c.GetUint("userID")}
+}
+
+func sendOffboardingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidOffboarding):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotOwner):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrStatus), errors.Is(err, ErrAlreadyOffboarding):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The offboarding was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+// prometheus/backend/internal/offboarding/model.go
+package offboarding
+
+import (
+	"time"
+)
+
+// Status is where an offboarding is.
+type Status string
+
+const (
+	StatusScheduled Status = "scheduled" // Access is revoked once the termination date has passed
+	StatusCompleted Status = "completed" // Access was revoked; the checklist may still be worked through
+	StatusCancelled Status = "cancelled" // Withdrawn before access was revoked
+)
+
+// TaskKind labels an exit checklist task.
+type TaskKind string
+
+const (
+	TaskAssetReturn       TaskKind = "asset_return"
+	TaskKnowledgeTransfer TaskKind = "knowledge_transfer"
+	TaskExitInterview     TaskKind = "exit_interview"
+	TaskAccessReview      TaskKind = "access_review"
+	TaskOther             TaskKind = "other"
+)
+
+// Owner is who is expected to see a task done.
+type Owner string
+
+const (
+	OwnerHR       Owner = "hr"
+	OwnerManager  Owner = "manager"
+	OwnerEmployee Owner = "employee"
+)
+
+// Offboarding is an employee's departure: the last day they work, the exit checklist to get through
+// before and after it, and the revocation of their access. The scheduler deactivates the employee's user
+// and ends their sessions the day after TerminationDate. An employee has at most one offboarding that
+// isn't cancelled.
+type Offboarding struct {
+	ID              uint       `gorm:"primaryKey" json:"id" example:"9"`
+	OrganizationID  *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID      uint       `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName     string     `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	UserID          uint       `gorm:"not null" json:"user_id" example:"7"`                                             // Of the employee, deactivated on revocation
+	TerminationDate time.Time  `gorm:"type:date;not null;index" json:"termination_date" example:"2026-11-30T00:00:00Z"` // Last day of employment
+	Reason          string     `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Resignation"`
+	Status          Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"scheduled"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	Tasks           []Task     `gorm:"foreignKey:OffboardingID" json:"tasks,omitempty"`
+	CreatedBy       *uint      `json:"created_by,omitempty" example:"4"`              // User ID
+	Version         uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Task is an item of an exit checklist.
+type Task struct {
+	ID            uint       `gorm:"primaryKey" json:"id" example:"31"`
+	OffboardingID uint       `gorm:"not null;index" json:"offboarding_id" example:"9"`
+	Kind          TaskKind   `gorm:"type:varchar(30);not null" json:"kind" example:"asset_return"`
+	Title         string     `gorm:"type:varchar(200);not null" json:"title" example:"Return laptop, badge and keys"`
+	Owner         Owner      `gorm:"type:varchar(20);not null" json:"owner" example:"manager"`
+	DueOn         time.Time  `gorm:"type:date;not null" json:"due_on" example:"2026-11-30T00:00:00Z"`
+	DoneAt        *time.Time `json:"done_at,omitempty"`
+	DoneBy        *uint      `json:"done_by,omitempty" example:"4"` // User ID
+	Note          string     `gorm:"type:varchar(1000)" json:"note,omitempty" example:"Laptop handed to IT"`
+	AssetID       *uint      `gorm:"index" json:"asset_id,omitempty" example:"17"` // Tracked asset to collect, ticked off when it is returned; see Service.UseAssets
+}
+
+// TableName keeps tasks next to offboardings.
+func (Task) TableName() string { return "offboarding_tasks" }
+
+// Request schedules an offboarding or moves its termination date while it is scheduled.
+type Request struct {
+	TerminationDate string        `json:"termination_date" binding:"required,datetime=2006-01-02" example:"2026-11-30"`
+	Reason          string        `json:"reason,omitempty" binding:"max=500" example:"Resignation"`
+	Tasks           []TaskRequest `json:"tasks,omitempty" binding:"max=50,dive"` // On creation only, added to the standard checklist
+}
+
+// TaskRequest adds a task to an exit checklist.
+type TaskRequest struct {
+	Kind  TaskKind `json:"kind" binding:"required,oneof=asset_return knowledge_transfer exit_interview access_review other" example:"asset_return"`
+	Title string   `json:"title" binding:"required,max=200" example:"Return the parking card"`
+	Owner Owner    `json:"owner" binding:"required,oneof=hr manager employee" example:"hr"`
+	DueOn string   `json:"due_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-11-30"` // Defaults to the termination date
+}
+
+// CompleteRequest ticks off a task.
+type CompleteRequest struct {
+	Note string `json:"note,omitempty" binding:"max=1000" example:"Laptop handed to IT"`
+}
+
+// Filter narrows an offboarding listing.
+type Filter struct {
+	Status     Status
+	EmployeeID *uint
+}
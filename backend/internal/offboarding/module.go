@@ -0,0 +1,90 @@
+// prometheus/backend/internal/offboarding/module.go
+package offboarding
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the offboarding module.
+const ModuleName = "offboarding"
+
+// revokeInterval is how often access past the termination date is revoked. Hourly revokes it within the
+// first hour after the last day ends.
+const revokeInterval = time.Hour
+
+// offboardingModule owns employee departures, their exit checklists and the revocation of access.
+type offboardingModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the offboarding module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &offboardingModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *offboardingModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. An offboarding still scheduled two days after its
+// termination date means a former employee can still log in.
+func (m *offboardingModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("revoking", func(ctx context.Context) module.HealthResult {
+			var overdue int64
+			if err := m.db.WithContext(ctx).Model(&Offboarding{}).Where("status = ? AND termination_date < ?",
+				StatusScheduled, today().AddDate(0, 0, -1)).Count(&overdue).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if overdue > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"overdue": float64(overdue)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *offboardingModule) Models() []any {
+	return []any{&Offboarding{}, &Task{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *offboardingModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobRevoke, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		revoked, failed, err := m.service.Revoke(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"revoked": revoked, "failed": failed}, nil
+	})
+	q.Every(JobRevoke, revokeInterval)
+}
+
+// RegisterRoutes implements routing.Contributor. HR schedules departures, managers work through their
+// part of their reports' checklists, and employees see their own.
+func (m *offboardingModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/offboarding", routing.Authenticated(), m.handler.Mine)
+
+	api.GET("/manager/offboardings", routing.Policy(), m.handler.TeamList)
+	api.GET("/manager/offboardings/:id", routing.Policy(), m.handler.TeamGet)
+	api.POST("/manager/offboardings/:id/tasks/:task_id/complete", routing.Policy(), m.handler.TeamCompleteTask)
+	api.POST("/manager/offboardings/:id/tasks/:task_id/reopen", routing.Policy(), m.handler.TeamReopenTask)
+
+	api.GET("/hr/offboardings", routing.Policy(), m.handler.List)
+	api.GET("/hr/offboardings/:id", routing.Policy(), m.handler.Get)
+	api.PUT("/hr/offboardings/:id", routing.Policy(), m.handler.Update)
+	api.POST("/hr/offboardings/:id/cancel", routing.Policy(), m.handler.Cancel)
+	api.POST("/hr/offboardings/:id/revoke", routing.Policy(), m.handler.Revoke)
+	api.POST("/hr/offboardings/:id/tasks", routing.Policy(), m.handler.AddTask)
+	api.POST("/hr/offboardings/:id/tasks/:task_id/complete", routing.Policy(), m.handler.CompleteTask)
+	api.POST("/hr/offboardings/:id/tasks/:task_id/reopen", routing.Policy(), m.handler.ReopenTask)
+	api.POST("/hr/employees/:id/offboarding", routing.Policy(), m.handler.Create)
+}
@@ -0,0 +1,610 @@
+// prometheus/backend/internal/offboarding/service.go
+package offboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRevoke is the recurring job type that revokes the access of employees past their termination date.
+const JobRevoke = "offboarding.revoke"
+
+var (
+	// ErrInvalidOffboarding is returned for offboardings and tasks that fail validation.
+	ErrInvalidOffboarding = errors.New("invalid offboarding")
+	// ErrStatus is returned when editing an offboarding that was completed or cancelled, or adding tasks to
+	// a cancelled one.
+	ErrStatus = errors.New("the offboarding is no longer scheduled")
+	// ErrAlreadyOffboarding is returned when scheduling a second offboarding for an employee.
+	ErrAlreadyOffboarding = errors.New("the employee already has an offboarding")
+	// ErrNotOwner is returned when a manager ticks off a task that isn't theirs.
+	ErrNotOwner = errors.New("only HR can change this task")
+)
+
+// Viewer is who is asking: HR sees every offboarding of the organization, managers only those of their
+// direct reports, and may only tick off the tasks owned by managers.
+type Viewer struct {
+	UserID uint
+	HR     bool
+}
+
+// HeldAsset is a tracked company asset an employee holds.
+type HeldAsset struct {
+	ID    uint
+	Label string // E.g. "Laptop LT-0042 (Dell Latitude 7440)"
+}
+
+// Assets tells which tracked assets an employee holds. The assets module provides it through
+// Service.UseAssets; without it, the standard checklist's asset return task is all there is.
+type Assets interface {
+	HeldAssets(tx *gorm.DB, orgID *uint, employeeID uint) ([]HeldAsset, error)
+}
+
+// Service schedules employee departures, tracks their exit checklists and revokes the employees' access
+// once their termination date has passed. orgID scopes every call to one organization's employees and
+// offboardings (nil = default organization).
+type Service interface {
+	List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Offboarding, int64, error)
+	Get(orgID *uint, viewer Viewer, id uint) (*Offboarding, error)
+	// Mine returns the user's own offboarding, not cancelled; gorm.ErrRecordNotFound if there is none.
+	Mine(orgID *uint, userID uint) (*Offboarding, error)
+	// Create schedules an employee's departure with the standard exit checklist and req's extra tasks.
+	Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Offboarding, error)
+	// Update moves the termination date or changes the reason of a scheduled offboarding.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Offboarding, error)
+	// Cancel withdraws a scheduled offboarding with its checklist.
+	Cancel(actor audit.Actor, orgID *uint, id uint) (*Offboarding, error)
+	AddTask(actor audit.Actor, orgID *uint, id uint, req TaskRequest) (*Offboarding, error)
+	CompleteTask(actor audit.Actor, orgID *uint, viewer Viewer, id, taskID uint, req CompleteRequest) (*Offboarding, error)
+	ReopenTask(actor audit.Actor, orgID *uint, viewer Viewer, id, taskID uint) (*Offboarding, error)
+	// RevokeNow revokes a scheduled offboarding's access ahead of the termination date, for immediate
+	// dismissals.
+	RevokeNow(actor audit.Actor, orgID *uint, id uint) (*Offboarding, error)
+	// Revoke revokes the access of every scheduled offboarding past its termination date.
+	Revoke(ctx context.Context) (revoked, failed int, err error)
+	// UseAssets adds a task to collect each tracked asset the employee holds to new exit checklists.
+	UseAssets(assets Assets)
+	// AssetReturnedTx ticks off the open tasks to collect an asset, within the transaction recording its return.
+	AssetReturnedTx(tx *gorm.DB, actor audit.Actor, assetID uint, note string) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	users     auth.UserAdminService
+	auditor   audit.Service
+
+	mu     sync.RWMutex
+	assets Assets
+}
+
+// NewService creates a new instance of Service. Access is revoked through users: the employee's user is
+// deactivated and every token issued to it so far rejected.
+func NewService(db *gorm.DB, employees employee.Service, users auth.UserAdminService, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, users: users, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Offboarding, int64, error) {
+	query := visible(utils.OrgScope(s.db.Model(&Offboarding{}), orgID), viewer)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count offboardings: %w", err)
+	}
+	var offboardings []Offboarding
+	if err := query.Preload("Tasks", orderTasks).Order("termination_date, id").Scopes(page.Scope).Find(&offboardings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list offboardings: %w", err)
+	}
+	if err := s.named(orgID, offboardings); err != nil {
+		return nil, 0, err
+	}
+	return offboardings, total, nil
+}
+
+func (s *service) Get(orgID *uint, viewer Viewer, id uint) (*Offboarding, error) {
+	var offboarding Offboarding
+	if err := visible(utils.OrgScope(s.db, orgID), viewer).Preload("Tasks", orderTasks).First(&offboarding, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	offboardings := []Offboarding{offboarding}
+	if err := s.named(orgID, offboardings); err != nil {
+		return nil, err
+	}
+	return &offboardings[0], nil
+}
+
+func (s *service) Mine(orgID *uint, userID uint) (*Offboarding, error) {
+	var offboarding Offboarding
+	if err := utils.OrgScope(s.db, orgID).Where("user_id = ? AND status <> ?", userID, StatusCancelled).
+		Preload("Tasks", orderTasks).First(&offboarding).Error; err != nil {
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, employeeID uint, req Request) (*Offboarding, error) {
+	subject, err := s.employees.Get(orgID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.UserID != nil && *actor.UserID == subject.UserID {
+		return nil, fmt.Errorf("%w: you can't offboard yourself", ErrInvalidOffboarding)
+	}
+	terminationDate, err := parseTerminationDate(req.TerminationDate)
+	if err != nil {
+		return nil, err
+	}
+	offboarding := Offboarding{
+		OrganizationID:  orgID,
+		EmployeeID:      employeeID,
+		UserID:          subject.UserID,
+		TerminationDate: terminationDate,
+		Reason:          strings.TrimSpace(req.Reason),
+		Status:          StatusScheduled,
+		CreatedBy:       actor.UserID,
+		Tasks:           checklist(terminationDate),
+	}
+	for _, r := range req.Tasks {
+		task, err := newTask(r, terminationDate)
+		if err != nil {
+			return nil, err
+		}
+		offboarding.Tasks = append(offboarding.Tasks, task)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the employee serializes concurrent requests for the same departure.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&employee.Employee{}, employeeID).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&Offboarding{}).Where("employee_id = ? AND status <> ?", employeeID, StatusCancelled).
+			Count(&open).Error; err != nil {
+			return fmt.Errorf("failed to check offboardings of employee %d: %w", employeeID, err)
+		}
+		if open > 0 {
+			return ErrAlreadyOffboarding
+		}
+		s.mu.RLock()
+		assets := s.assets
+		s.mu.RUnlock()
+		if assets != nil {
+			held, err := assets.HeldAssets(tx, orgID, employeeID)
+			if err != nil {
+				return fmt.Errorf("failed to load the employee's assets: %w", err)
+			}
+			for _, asset := range held {
+				assetID := asset.ID
+				offboarding.Tasks = append(offboarding.Tasks, Task{
+					Kind: TaskAssetReturn, Title: truncate("Collect "+asset.Label, 200), Owner: OwnerManager,
+					DueOn: terminationDate, AssetID: &assetID,
+				})
+			}
+		}
+		if err := tx.Create(&offboarding).Error; err != nil {
+			return fmt.Errorf("failed to schedule offboarding: %w", err)
+		}
+		if err := s.notifyManager(tx, &offboarding, subject); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.create", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", offboarding.ID), After: offboarding,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, offboarding.ID)
+}
+
+// Update leaves the checklist alone: tasks keep their due dates when the termination date moves.
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Offboarding, error) {
+	terminationDate, err := parseTerminationDate(req.TerminationDate)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockOffboarding(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusScheduled {
+			return ErrStatus
+		}
+		if err := utils.UpdateWithVersion(tx, &Offboarding{}, id, expectedVersion, map[string]interface{}{
+			"termination_date": terminationDate,
+			"reason":           strings.TrimSpace(req.Reason),
+		}); err != nil {
+			return err
+		}
+		var updated Offboarding
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload offboarding %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.update", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) Cancel(actor audit.Actor, orgID *uint, id uint) (*Offboarding, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockOffboarding(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusScheduled {
+			return ErrStatus
+		}
+		if err := tx.Model(&Offboarding{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":  StatusCancelled,
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to cancel offboarding %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.cancel", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) AddTask(actor audit.Actor, orgID *uint, id uint, req TaskRequest) (*Offboarding, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		offboarding, err := lockOffboarding(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if offboarding.Status == StatusCancelled {
+			return ErrStatus
+		}
+		task, err := newTask(req, offboarding.TerminationDate)
+		if err != nil {
+			return err
+		}
+		task.OffboardingID = id
+		if err := tx.Create(&task).Error; err != nil {
+			return fmt.Errorf("failed to add offboarding task: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.task_add", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", id), After: task,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) CompleteTask(actor audit.Actor, orgID *uint, viewer Viewer, id, taskID uint, req CompleteRequest) (*Offboarding, error) {
+	now := clock.Now().UTC()
+	return s.updateTask(actor, orgID, viewer, id, taskID, "offboarding.task_complete", map[string]interface{}{
+		"done_at": now,
+		"done_by": actor.UserID,
+		"note":    strings.TrimSpace(req.Note),
+	})
+}
+
+func (s *service) ReopenTask(actor audit.Actor, orgID *uint, viewer Viewer, id, taskID uint) (*Offboarding, error) {
+	return s.updateTask(actor, orgID, viewer, id, taskID, "offboarding.task_reopen", map[string]interface{}{
+		"done_at": nil,
+		"done_by": nil,
+	})
+}
+
+// updateTask sets fields of a task of an offboarding that isn't cancelled.
+func (s *service) updateTask(actor audit.Actor, orgID *uint, viewer Viewer, id, taskID uint, action string, fields map[string]interface{}) (*Offboarding, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var offboarding Offboarding
+		if err := visible(utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID), viewer).First(&offboarding, id).Error; err != nil {
+			return err
+		}
+		if offboarding.Status == StatusCancelled {
+			return ErrStatus
+		}
+		var before Task
+		if err := tx.Where("offboarding_id = ?", id).First(&before, taskID).Error; err != nil {
+			return err
+		}
+		if !viewer.HR && before.Owner != OwnerManager {
+			return ErrNotOwner
+		}
+		if err := tx.Model(&Task{}).Where("id = ?", taskID).Updates(fields).Error; err != nil {
+			return fmt.Errorf("failed to update offboarding task %d: %w", taskID, err)
+		}
+		var after Task
+		if err := tx.First(&after, taskID).Error; err != nil {
+			return fmt.Errorf("failed to reload offboarding task %d: %w", taskID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: action, EntityType: "offboarding", EntityID: fmt.Sprintf("%d", id), Before: before, After: after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, viewer, id)
+}
+
+func (s *service) RevokeNow(actor audit.Actor, orgID *uint, id uint) (*Offboarding, error) {
+	if _, err := s.revoke(s.db, actor, orgID, id, false); err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+// Revoke works through due offboardings one transaction each, like scheduled changes. One that can't be
+// revoked stays scheduled and is tried again on the next run; the module's health check reports it as
+// overdue meanwhile.
+func (s *service) Revoke(ctx context.Context) (int, int, error) {
+	var due []uint
+	if err := s.db.WithContext(ctx).Model(&Offboarding{}).Where("status = ? AND termination_date < ?", StatusScheduled, today()).
+		Order("termination_date, id").Pluck("id", &due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to find due offboardings: %w", err)
+	}
+	var revoked, failed int
+	for _, id := range due {
+		if err := ctx.Err(); err != nil {
+			return revoked, failed, err
+		}
+		done, err := s.revoke(s.db.WithContext(ctx), audit.SystemActor, nil, id, true)
+		if err != nil {
+			log.Printf("Failed to revoke access for offboarding %d: %v", id, err)
+			failed++
+			continue
+		}
+		if done {
+			revoked++
+		}
+	}
+	return revoked, failed, nil
+}
+
+// revoke deactivates the user of a scheduled offboarding, ends their sessions and marks the offboarding
+// completed. The job passes due to skip offboardings taken by another instance and those no longer due,
+// with orgID left unscoped. It reports false when there was nothing to revoke.
+func (s *service) revoke(db *gorm.DB, actor audit.Actor, orgID *uint, id uint, due bool) (bool, error) {
+	var done bool
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var offboarding Offboarding
+		var err error
+		if due {
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND termination_date < ?", StatusScheduled, today()).First(&offboarding, id).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+		} else {
+			err = utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&offboarding, id).Error
+		}
+		if err != nil {
+			return err
+		}
+		if offboarding.Status != StatusScheduled {
+			return ErrStatus
+		}
+		// The user is updated in transactions of its own, which also drop its cached status. Both calls
+		// are idempotent, so a run that fails after them is safely retried.
+		if _, err := s.users.SetStatus(actor, offboarding.OrganizationID, offboarding.UserID, false); err != nil &&
+			!errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to deactivate user %d: %w", offboarding.UserID, err)
+		}
+		if err := s.users.ForceLogout(actor, offboarding.OrganizationID, offboarding.UserID); err != nil &&
+			!errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to log out user %d: %w", offboarding.UserID, err)
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Offboarding{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":     StatusCompleted,
+			"revoked_at": now,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark offboarding %d completed: %w", id, err)
+		}
+		if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.revoke", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", id), Before: offboarding,
+			After: map[string]interface{}{"status": StatusCompleted, "revoked_at": now},
+		}); err != nil {
+			return err
+		}
+		done = true
+		return nil
+	})
+	if errors.Is(err, auth.ErrCannotModifySelf) {
+		return false, fmt.Errorf("%w: you can't revoke your own access", ErrInvalidOffboarding)
+	}
+	return done, err
+}
+
+func (s *service) UseAssets(assets Assets) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assets = assets
+}
+
+// AssetReturnedTx leaves the tasks of cancelled offboardings alone.
+func (s *service) AssetReturnedTx(tx *gorm.DB, actor audit.Actor, assetID uint, note string) error {
+	var tasks []Task
+	if err := tx.Where("asset_id = ? AND done_at IS NULL", assetID).
+		Where("offboarding_id IN (?)", tx.Model(&Offboarding{}).Select("id").Where("status <> ?", StatusCancelled)).
+		Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to find the tasks collecting asset %d: %w", assetID, err)
+	}
+	now := clock.Now().UTC()
+	for _, task := range tasks {
+		if err := tx.Model(&Task{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+			"done_at": now, "done_by": actor.UserID, "note": truncate(strings.TrimSpace(note), 1000),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update offboarding task %d: %w", task.ID, err)
+		}
+		if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "offboarding.task_complete", EntityType: "offboarding", EntityID: fmt.Sprintf("%d", task.OffboardingID),
+			Before: task, After: map[string]interface{}{"task_id": task.ID, "done_at": now, "asset_id": assetID},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyManager tells the employee's line manager about the departure and the tasks expected of them.
+func (s *service) notifyManager(tx *gorm.DB, offboarding *Offboarding, subject *employee.Detail) error {
+	if subject.ManagerID == nil {
+		return nil
+	}
+	manager, err := s.employees.Get(offboarding.OrganizationID, *subject.ManagerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var tasks []string
+	for _, task := range offboarding.Tasks {
+		if task.Owner == OwnerManager {
+			tasks = append(tasks, fmt.Sprintf("- %s (by %s)", task.Title, task.DueOn.Format("2006-01-02")))
+		}
+	}
+	body := "Nothing on the exit checklist is assigned to managers."
+	if len(tasks) > 0 {
+		body = "Your part of the exit checklist:\n" + strings.Join(tasks, "\n")
+	}
+	return notification.CreateTx(tx, notification.Notice{
+		UserID:         manager.UserID,
+		OrganizationID: offboarding.OrganizationID,
+		Category:       "offboarding.scheduled",
+		Subject:        fmt.Sprintf("%s leaves on %s", subject.DisplayName.Text, offboarding.TerminationDate.Format("2006-01-02")),
+		Body:           body,
+		Link:           fmt.Sprintf("/manager/offboardings/%d", offboarding.ID),
+	})
+}
+
+// named fills in the display names of offboardings' employees.
+func (s *service) named(orgID *uint, offboardings []Offboarding) error {
+	if len(offboardings) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(offboardings))
+	for i, o := range offboardings {
+		ids[i] = o.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range offboardings {
+		offboardings[i].DisplayName = names[offboardings[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// checklist is the standard exit checklist of a departure on terminationDate. Hand-overs are due a week
+// ahead, or today for departures closer than that.
+func checklist(terminationDate time.Time) []Task {
+	handover := terminationDate.AddDate(0, 0, -7)
+	if t := today(); handover.Before(t) {
+		handover = t
+	}
+	return []Task{
+		{Kind: TaskKnowledgeTransfer, Title: "Hand over ongoing work, documentation and contacts", Owner: OwnerManager, DueOn: handover},
+		{Kind: TaskAssetReturn, Title: "Collect laptop, badge, keys and other company property", Owner: OwnerManager, DueOn: terminationDate},
+		{Kind: TaskExitInterview, Title: "Hold the exit interview", Owner: OwnerHR, DueOn: terminationDate},
+		{Kind: TaskAccessReview, Title: "Remove access to shared accounts and third-party tools", Owner: OwnerHR, DueOn: terminationDate.AddDate(0, 0, 1)},
+	}
+}
+
+// newTask validates req into a task, due on the termination date unless req says otherwise.
+func newTask(req TaskRequest, terminationDate time.Time) (Task, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return Task{}, fmt.Errorf("%w: the task title can't be blank", ErrInvalidOffboarding)
+	}
+	task := Task{Kind: req.Kind, Title: title, Owner: req.Owner, DueOn: terminationDate}
+	if req.DueOn != "" {
+		dueOn, err := time.Parse("2006-01-02", req.DueOn)
+		if err != nil {
+			return Task{}, fmt.Errorf("%w: due_on must be a date", ErrInvalidOffboarding)
+		}
+		task.DueOn = dueOn
+	}
+	return task, nil
+}
+
+// parseTerminationDate parses a termination date, today at the earliest. Departures that already happened
+// are recorded and revoked at once with RevokeNow.
+func parseTerminationDate(raw string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: termination_date must be a date", ErrInvalidOffboarding)
+	}
+	if date.Before(today()) {
+		return time.Time{}, fmt.Errorf("%w: the termination date can't be in the past", ErrInvalidOffboarding)
+	}
+	return date, nil
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// orderTasks lists a checklist by due date.
+func orderTasks(db *gorm.DB) *gorm.DB {
+	return db.Order("due_on, id")
+}
+
+// visible restricts a query to the offboardings the viewer may see: managers see their direct reports'.
+func visible(db *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.HR {
+		return db
+	}
+	return db.Where("employee_id IN (SELECT e.id FROM employees e JOIN employees m ON m.id = e.manager_id "+
+		"WHERE m.user_id = ? AND e.deleted_at IS NULL)", viewer.UserID)
+}
+
+// lockOffboarding loads an offboarding for update.
+func lockOffboarding(tx *gorm.DB, orgID *uint, id uint) (*Offboarding, error) {
+	var offboarding Offboarding
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&offboarding, id).Error; err != nil {
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+// today is the current UTC date. Access is revoked once it is past the termination date.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,291 @@
+// prometheus/backend/internal/opening/handler.go
+package opening
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for job openings.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's job openings in any status, newest first.
+// @Summary List job openings
+// @Tags Job openings
+// @Produce json
+// @Param status query string false "Status" Enums(draft, pending, open, rejected, closed)
+// @Param division_id query int false "Division ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/job-openings [get]
+// @Router /admin/job-openings [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusDraft, StatusPending, StatusOpen, StatusRejected, StatusClosed:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	var ok bool
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	openings, total, err := h.service.List(utils.OrganizationFromContext(c), filter, page)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Job openings fetched successfully", page.Response(openings, total))
+}
+
+// Get returns a job opening.
+// @Summary Get a job opening
+// @Tags Job openings
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Success 200 {object} Opening
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found"
+// @Router /hr/job-openings/{id} [get]
+// @Router /admin/job-openings/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	opening, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Job opening fetched successfully", opening)
+}
+
+// Listings returns the open job openings employees may apply to.
+// @Summary List open job openings
+// @Tags Job openings
+// @Produce json
+// @Param division_id query int false "Division ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /me/job-openings [get]
+func (h *Handler) Listings(c *gin.Context) {
+	divisionID, ok := utils.OptionalUintQuery(c, "division_id")
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	listings, total, err := h.service.Listings(utils.OrganizationFromContext(c), divisionID, page)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Job openings fetched successfully", page.Response(listings, total))
+}
+
+// Listing returns an open job opening.
+// @Summary Get an open job opening
+// @Tags Job openings
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Success 200 {object} Listing
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found or not open"
+// @Router /me/job-openings/{id} [get]
+func (h *Handler) Listing(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	listing, err := h.service.Listing(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Job opening fetched successfully", listing)
+}
+
+// Create drafts a job opening.
+// @Summary Create a job opening
+// @Description The opening is a draft until submitted, and counts as open only once an admin approves it.
+// @Description An opening recruiting against a requisition must be in its division and ask for no more hires
+// @Description than it allows.
+// @Tags Job openings
+// @Accept json
+// @Produce json
+// @Param opening body Request true "Job opening"
+// @Success 201 {object} Opening
+// @Failure 400 {object} utils.ErrorResponse "Invalid job opening, or unknown division or requisition"
+// @Router /hr/job-openings [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	opening, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Job opening created successfully", opening)
+}
+
+// Update replaces a draft or rejected job opening; a rejected one is a draft again.
+// @Summary Update a job opening
+// @Tags Job openings
+// @Accept json
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param opening body Request true "Job opening"
+// @Success 200 {object} Opening
+// @Failure 400 {object} utils.ErrorResponse "Invalid job opening, or unknown division or requisition"
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found"
+// @Failure 409 {object} utils.ErrorResponse "Job opening submitted, open or closed"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/job-openings/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, id)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	opening, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Job opening updated successfully", opening)
+}
+
+// Submit sends a draft job opening to the organization's admins for approval.
+// @Summary Submit a job opening for approval
+// @Tags Job openings
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Success 200 {object} Opening
+// @Failure 400 {object} utils.ErrorResponse "Requisition not approved or no longer matching"
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found"
+// @Failure 409 {object} utils.ErrorResponse "Job opening not a draft"
+// @Router /hr/job-openings/{id}/submit [post]
+func (h *Handler) Submit(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	opening, err := h.service.Submit(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Job opening submitted successfully", opening)
+}
+
+// Decide approves a submitted job opening, opening it, or rejects it back to HR.
+// @Summary Approve or reject a job opening
+// @Description Approving opens the job opening and lists it to employees. Rejecting needs a note. Admins can't
+// @Description decide openings they submitted themselves.
+// @Tags Job openings
+// @Accept json
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Param decision body DecisionRequest true "Decision"
+// @Success 200 {object} Opening
+// @Failure 400 {object} utils.ErrorResponse "Rejection without a note, or requisition not approved"
+// @Failure 403 {object} utils.ErrorResponse "Submitted by the caller"
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found"
+// @Failure 409 {object} utils.ErrorResponse "Job opening not pending"
+// @Router /admin/job-openings/{id}/decision [post]
+func (h *Handler) Decide(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	opening, err := h.service.Decide(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Decision recorded successfully", opening)
+}
+
+// Close ends recruiting for a job opening, or withdraws it before it opened.
+// @Summary Close a job opening
+// @Tags Job openings
+// @Produce json
+// @Param id path int true "Job opening ID"
+// @Success 200 {object} Opening
+// @Failure 404 {object} utils.ErrorResponse "Job opening not found"
+// @Failure 409 {object} utils.ErrorResponse "Job opening already closed"
+// @Router /hr/job-openings/{id}/close [post]
+func (h *Handler) Close(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	opening, err := h.service.Close(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendOpeningError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, opening.UpdatedAt, opening.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Job opening closed successfully", opening)
+}
+
+func sendOpeningError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidOpening):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSelfApproval):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrStatus):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The job opening was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
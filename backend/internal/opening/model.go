@@ -0,0 +1,80 @@
+// prometheus/backend/internal/opening/model.go
+package opening
+
+import (
+	"time"
+)
+
+// Status is where a job opening is.
+type Status string
+
+const (
+	StatusDraft    Status = "draft"    // Being written by HR
+	StatusPending  Status = "pending"  // Submitted, waiting for an admin
+	StatusOpen     Status = "open"     // Approved; listed to employees and recruited for
+	StatusRejected Status = "rejected" // Sent back by an admin; HR may edit and submit it again
+	StatusClosed   Status = "closed"   // Filled or withdrawn; final
+)
+
+// Opening is an internal job opening: a role HR wants to recruit for in a division. It counts as open only
+// once an admin approved it, and is then listed to the organization's employees. An opening may recruit
+// against an approved requisition, whose division it shares and whose openings cap its headcount.
+type Opening struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Title          string     `gorm:"type:varchar(150);not null" json:"title" example:"Payroll Specialist"`
+	Description    string     `gorm:"type:text" json:"description,omitempty" example:"Runs the monthly payroll for 400 employees"`
+	DivisionID     uint       `gorm:"not null;index" json:"division_id" example:"2"`
+	Headcount      int        `gorm:"not null" json:"headcount" example:"2"`
+	RequisitionID  *uint      `gorm:"index" json:"requisition_id,omitempty" example:"17"`
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"open"`
+	CreatedBy      *uint      `json:"created_by,omitempty" example:"4"`   // User ID
+	SubmittedBy    *uint      `json:"submitted_by,omitempty" example:"4"` // User ID
+	DecidedBy      *uint      `json:"decided_by,omitempty" example:"2"`   // User ID of the admin who approved or rejected it
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	DecisionNote   string     `gorm:"type:varchar(1000)" json:"decision_note,omitempty" example:"Matches the approved requisition"`
+	OpenedAt       *time.Time `json:"opened_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName keeps openings apart from the job queue's jobs.
+func (Opening) TableName() string { return "job_openings" }
+
+// Listing is an open job opening as employees see it.
+type Listing struct {
+	ID          uint       `json:"id" example:"5"`
+	Title       string     `json:"title" example:"Payroll Specialist"`
+	Description string     `json:"description,omitempty" example:"Runs the monthly payroll for 400 employees"`
+	DivisionID  uint       `json:"division_id" example:"2"`
+	Headcount   int        `json:"headcount" example:"2"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+}
+
+// listing is how employees see the opening.
+func (o *Opening) listing() Listing {
+	return Listing{ID: o.ID, Title: o.Title, Description: o.Description, DivisionID: o.DivisionID, Headcount: o.Headcount, OpenedAt: o.OpenedAt}
+}
+
+// Request creates a job opening or replaces its fields while it is a draft or rejected.
+type Request struct {
+	Title         string `json:"title" binding:"required,max=150" example:"Payroll Specialist"`
+	Description   string `json:"description,omitempty" binding:"max=10000" example:"Runs the monthly payroll for 400 employees"`
+	DivisionID    uint   `json:"division_id" binding:"required" example:"2"`
+	Headcount     int    `json:"headcount,omitempty" binding:"omitempty,min=1,max=1000" example:"2"` // Defaults to 1
+	RequisitionID *uint  `json:"requisition_id,omitempty" example:"17"`
+}
+
+// DecisionRequest approves or rejects a submitted job opening.
+type DecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty" binding:"max=1000" example:"Matches the approved requisition"` // Required to reject
+}
+
+// Filter narrows a job opening listing.
+type Filter struct {
+	Status     Status
+	DivisionID *uint
+}
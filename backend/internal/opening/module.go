@@ -0,0 +1,49 @@
+// prometheus/backend/internal/opening/module.go
+package opening
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the job openings module.
+const ModuleName = "job-openings"
+
+// openingModule owns internal job openings and their approval by admins.
+type openingModule struct {
+	handler *Handler
+}
+
+// NewModule creates the job openings module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &openingModule{handler: NewHandler(svc)}
+}
+
+func (m *openingModule) Name() string { return ModuleName }
+
+func (m *openingModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *openingModule) Models() []any {
+	return []any{&Opening{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR writes and closes openings, admins approve them, and
+// employees browse the open ones.
+func (m *openingModule) RegisterRoutes(api *routing.Group) {
+	recruitingAPI := api.InModule(plan.ModuleATS)
+	recruitingAPI.GET("/me/job-openings", routing.Authenticated(), m.handler.Listings)
+	recruitingAPI.GET("/me/job-openings/:id", routing.Authenticated(), m.handler.Listing)
+
+	recruitingAPI.GET("/admin/job-openings", routing.Policy(), m.handler.List)
+	recruitingAPI.GET("/admin/job-openings/:id", routing.Policy(), m.handler.Get)
+	recruitingAPI.POST("/admin/job-openings/:id/decision", routing.Policy(), m.handler.Decide)
+
+	recruitingAPI.GET("/hr/job-openings", routing.Policy(), m.handler.List)
+	recruitingAPI.POST("/hr/job-openings", routing.Policy(), m.handler.Create)
+	recruitingAPI.GET("/hr/job-openings/:id", routing.Policy(), m.handler.Get)
+	recruitingAPI.PUT("/hr/job-openings/:id", routing.Policy(), m.handler.Update)
+	recruitingAPI.POST("/hr/job-openings/:id/submit", routing.Policy(), m.handler.Submit)
+	recruitingAPI.POST("/hr/job-openings/:id/close", routing.Policy(), m.handler.Close)
+}
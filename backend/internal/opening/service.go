@@ -0,0 +1,408 @@
+// prometheus/backend/internal/opening/service.go
+package opening
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/requisition"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// adminRole approves job openings.
+const adminRole = "admin"
+
+var (
+	// ErrInvalidOpening is returned for job openings that fail validation.
+	ErrInvalidOpening = errors.New("invalid job opening")
+	// ErrStatus is returned when a job opening can't be changed in its current status.
+	ErrStatus = errors.New("the job opening can't be changed in its current status")
+	// ErrSelfApproval is returned when an admin decides a job opening they submitted themselves.
+	ErrSelfApproval = errors.New("a job opening must be approved by another admin than who submitted it")
+)
+
+// Service manages internal job openings: written by HR, approved by an admin before they count as open,
+// then listed to employees until closed. orgID scopes every call to one organization (nil = platform
+// users, outside any organization).
+type Service interface {
+	// List returns the job openings in any status, newest first.
+	List(orgID *uint, filter Filter, page utils.Pagination) ([]Opening, int64, error)
+	Get(orgID *uint, id uint) (*Opening, error)
+	// Listings returns the open job openings, most recently opened first.
+	Listings(orgID *uint, divisionID *uint, page utils.Pagination) ([]Listing, int64, error)
+	// Listing returns an open job opening; gorm.ErrRecordNotFound if it isn't open.
+	Listing(orgID *uint, id uint) (*Listing, error)
+	// Create drafts a job opening.
+	Create(actor audit.Actor, orgID *uint, req Request) (*Opening, error)
+	// Update replaces the fields of a draft or rejected job opening; a rejected one is a draft again.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Opening, error)
+	// Submit sends a draft for approval, notifying the organization's admins.
+	Submit(actor audit.Actor, orgID *uint, id uint) (*Opening, error)
+	// Decide approves a submitted job opening, which opens it, or rejects it back to HR.
+	Decide(actor audit.Actor, orgID *uint, id uint, req DecisionRequest) (*Opening, error)
+	// Close ends recruiting for a job opening, or withdraws it before it opened.
+	Close(actor audit.Actor, orgID *uint, id uint) (*Opening, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db           *gorm.DB
+	requisitions requisition.Service
+	auditor      audit.Service
+}
+
+// NewService creates a new instance of Service. Openings recruiting against a requisition are checked
+// against it through requisitions.
+func NewService(db *gorm.DB, requisitions requisition.Service, auditor audit.Service) Service {
+	return &service{db: db, requisitions: requisitions, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, filter Filter, page utils.Pagination) ([]Opening, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Opening{}), orgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count job openings: %w", err)
+	}
+	openings := []Opening{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&openings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list job openings: %w", err)
+	}
+	return openings, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Opening, error) {
+	var opening Opening
+	if err := utils.OrgScope(s.db, orgID).First(&opening, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &opening, nil
+}
+
+func (s *service) Listings(orgID *uint, divisionID *uint, page utils.Pagination) ([]Listing, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Opening{}), orgID).Where("status = ?", StatusOpen)
+	if divisionID != nil {
+		query = query.Where("division_id = ?", *divisionID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count job openings: %w", err)
+	}
+	var openings []Opening
+	if err := query.Order("opened_at DESC, id DESC").Scopes(page.Scope).Find(&openings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list job openings: %w", err)
+	}
+	listings := make([]Listing, len(openings))
+	for i := range openings {
+		listings[i] = openings[i].listing()
+	}
+	return listings, total, nil
+}
+
+func (s *service) Listing(orgID *uint, id uint) (*Listing, error) {
+	var opening Opening
+	if err := utils.OrgScope(s.db, orgID).Where("status = ?", StatusOpen).First(&opening, id).Error; err != nil {
+		return nil, err
+	}
+	listing := opening.listing()
+	return &listing, nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Opening, error) {
+	opening := Opening{OrganizationID: orgID, Status: StatusDraft, CreatedBy: actor.UserID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, orgID, &opening, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&opening).Error; err != nil {
+			return fmt.Errorf("failed to create job opening: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "job_opening.create", EntityType: "job_opening", EntityID: fmt.Sprintf("%d", opening.ID), After: opening,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, opening.ID)
+}
+
+// Update replaces the opening if it is still at expectedVersion (optimistic locking).
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Opening, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockOpening(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusDraft && before.Status != StatusRejected {
+			return ErrStatus
+		}
+		opening := *before
+		if err := s.apply(tx, orgID, &opening, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Opening{}, id, expectedVersion, map[string]interface{}{
+			"title":          opening.Title,
+			"description":    opening.Description,
+			"division_id":    opening.DivisionID,
+			"headcount":      opening.Headcount,
+			"requisition_id": opening.RequisitionID,
+			"status":         StatusDraft,
+		}); err != nil {
+			return err
+		}
+		var updated Opening
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload job opening %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "job_opening.update", EntityType: "job_opening", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+func (s *service) Submit(actor audit.Actor, orgID *uint, id uint) (*Opening, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		opening, err := lockOpening(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if opening.Status != StatusDraft {
+			return ErrStatus
+		}
+		// Checked again on approval: the requisition may be cancelled or filled in between.
+		if err := s.checkRequisition(tx, orgID, opening); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Opening{}, id, opening.Version, map[string]interface{}{
+			"status":        StatusPending,
+			"submitted_by":  actor.UserID,
+			"decided_by":    nil,
+			"decided_at":    nil,
+			"decision_note": "",
+		}); err != nil {
+			return err
+		}
+		recipients, err := admins(tx, orgID)
+		if err != nil {
+			return err
+		}
+		notices := make([]notification.Notice, 0, len(recipients))
+		for _, userID := range recipients {
+			if actor.UserID != nil && *actor.UserID == userID {
+				continue
+			}
+			notices = append(notices, notification.Notice{
+				UserID:         userID,
+				OrganizationID: orgID,
+				Category:       "approval.job_opening",
+				Subject:        fmt.Sprintf("%s submitted the job opening %q for approval", actor.Username, opening.Title),
+				Body:           fmt.Sprintf("%d position(s) to recruit for.", opening.Headcount),
+				Link:           fmt.Sprintf("/admin/job-openings/%d", id),
+			})
+		}
+		if err := notification.CreateTx(tx, notices...); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "job_opening.submit", EntityType: "job_opening", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": opening.Status}, After: map[string]interface{}{"status": StatusPending},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+func (s *service) Decide(actor audit.Actor, orgID *uint, id uint, req DecisionRequest) (*Opening, error) {
+	status, verb := StatusRejected, "rejected"
+	if req.Approve {
+		status, verb = StatusOpen, "approved"
+	} else if strings.TrimSpace(req.Note) == "" {
+		return nil, fmt.Errorf("%w: say why the job opening is rejected", ErrInvalidOpening)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		opening, err := lockOpening(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if opening.Status != StatusPending {
+			return ErrStatus
+		}
+		if actor.UserID != nil && opening.SubmittedBy != nil && *actor.UserID == *opening.SubmittedBy {
+			return ErrSelfApproval
+		}
+		now := clock.Now().UTC()
+		fields := map[string]interface{}{
+			"status":        status,
+			"decided_by":    actor.UserID,
+			"decided_at":    now,
+			"decision_note": strings.TrimSpace(req.Note),
+		}
+		if status == StatusOpen {
+			if err := s.checkRequisition(tx, orgID, opening); err != nil {
+				return err
+			}
+			fields["opened_at"] = now
+		}
+		if err := utils.UpdateWithVersion(tx, &Opening{}, id, opening.Version, fields); err != nil {
+			return err
+		}
+		if opening.SubmittedBy != nil {
+			if err := notification.CreateTx(tx, notification.Notice{
+				UserID:         *opening.SubmittedBy,
+				OrganizationID: orgID,
+				Category:       "job_opening.decision",
+				Subject:        fmt.Sprintf("The job opening %q was %s", opening.Title, verb),
+				Body:           req.Note,
+				Link:           fmt.Sprintf("/hr/job-openings/%d", id),
+			}); err != nil {
+				return err
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "job_opening.decide", EntityType: "job_opening", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": opening.Status},
+			After:  map[string]interface{}{"status": status, "note": req.Note},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+func (s *service) Close(actor audit.Actor, orgID *uint, id uint) (*Opening, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		opening, err := lockOpening(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if opening.Status == StatusClosed {
+			return ErrStatus
+		}
+		if err := utils.UpdateWithVersion(tx, &Opening{}, id, opening.Version, map[string]interface{}{
+			"status":    StatusClosed,
+			"closed_at": clock.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "job_opening.close", EntityType: "job_opening", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": opening.Status}, After: map[string]interface{}{"status": StatusClosed},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, id)
+}
+
+// apply validates req and copies it onto opening.
+func (s *service) apply(tx *gorm.DB, orgID *uint, opening *Opening, req Request) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: the title can't be blank", ErrInvalidOpening)
+	}
+	headcount := req.Headcount
+	if headcount == 0 {
+		headcount = 1
+	}
+	// Queried by table name: divisions are soft-deleted, and only their existence matters here.
+	var divisions int64
+	if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", req.DivisionID), orgID).
+		Count(&divisions).Error; err != nil {
+		return fmt.Errorf("failed to load division %d: %w", req.DivisionID, err)
+	}
+	if divisions == 0 {
+		return fmt.Errorf("%w: division %d not found", ErrInvalidOpening, req.DivisionID)
+	}
+	opening.Title = title
+	opening.Description = strings.TrimSpace(req.Description)
+	opening.DivisionID = req.DivisionID
+	opening.Headcount = headcount
+	opening.RequisitionID = req.RequisitionID
+	if req.RequisitionID != nil {
+		var linked requisition.Requisition
+		if err := utils.OrgScope(tx, orgID).First(&linked, *req.RequisitionID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: requisition %d not found", ErrInvalidOpening, *req.RequisitionID)
+		} else if err != nil {
+			return fmt.Errorf("failed to load requisition %d: %w", *req.RequisitionID, err)
+		}
+		if err := matches(opening, &linked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRequisition checks that the requisition an opening recruits against is approved and still
+// matches it. The requisition stays locked until tx ends.
+func (s *service) checkRequisition(tx *gorm.DB, orgID *uint, opening *Opening) error {
+	if opening.RequisitionID == nil {
+		return nil
+	}
+	linked, err := s.requisitions.ApprovedTx(tx, orgID, *opening.RequisitionID)
+	if errors.Is(err, requisition.ErrStatus) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: requisition %d is not approved", ErrInvalidOpening, *opening.RequisitionID)
+	} else if err != nil {
+		return err
+	}
+	return matches(opening, linked)
+}
+
+// matches checks an opening against the requisition it recruits against.
+func matches(opening *Opening, linked *requisition.Requisition) error {
+	if linked.DivisionID != opening.DivisionID {
+		return fmt.Errorf("%w: the opening must be in the division of requisition %d", ErrInvalidOpening, linked.ID)
+	}
+	if opening.Headcount > linked.Openings {
+		return fmt.Errorf("%w: requisition %d allows at most %d hire(s)", ErrInvalidOpening, linked.ID, linked.Openings)
+	}
+	return nil
+}
+
+// admins returns the active users holding the admin role in the organization.
+func admins(tx *gorm.DB, orgID *uint) ([]uint, error) {
+	query := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", adminRole).
+		Where("user_roles.expires_at IS NULL OR user_roles.expires_at > ?", clock.Now().UTC())
+	if orgID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	var ids []uint
+	if err := query.Pluck("users.id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find admins: %w", err)
+	}
+	return ids, nil
+}
+
+// lockOpening loads a job opening of the organization for update.
+func lockOpening(tx *gorm.DB, orgID *uint, id uint) (*Opening, error) {
+	var opening Opening
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&opening, id).Error; err != nil {
+		return nil, err
+	}
+	return &opening, nil
+}
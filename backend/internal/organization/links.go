@@ -0,0 +1,74 @@
+// prometheus/backend/internal/organization/links.go
+package organization
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Links builds absolute URLs that point at the host a tenant's users actually use: the custom domain
+// if one is set, otherwise <slug>.<baseDomain>. Anything generated for users (emails, signed URLs,
+// payment redirects) should go through Links so white-labelled tenants never see the shared host.
+type Links struct {
+	defaultURL *url.URL // Frontend URL for users without an organization
+	baseDomain string   // Parent domain of tenant subdomains; empty disables subdomains
+}
+
+// NewLinks creates Links. defaultURL is the frontend URL (APP_BASE_URL); baseDomain is TENANT_BASE_DOMAIN.
+func NewLinks(defaultURL, baseDomain string) *Links {
+	u, err := url.Parse(strings.TrimSuffix(defaultURL, "/"))
+	if err != nil || u.Host == "" {
+		u = &url.URL{Scheme: "http", Host: "localhost:3000"}
+	}
+	return &Links{defaultURL: u, baseDomain: strings.ToLower(strings.Trim(baseDomain, "."))}
+}
+
+// BaseDomain returns the parent domain of tenant subdomains ("" if subdomains are disabled).
+func (l *Links) BaseDomain() string {
+	return l.baseDomain
+}
+
+// Host returns the host an organization is served on. org may be nil for users without an organization.
+func (l *Links) Host(org *Organization) string {
+	switch {
+	case org == nil:
+		return l.defaultURL.Host
+	case org.CustomDomain != nil && *org.CustomDomain != "":
+		return *org.CustomDomain
+	case l.baseDomain != "":
+		return org.Slug + "." + l.baseDomain
+	default:
+		return l.defaultURL.Host
+	}
+}
+
+// BaseURL returns the frontend base URL of an organization, without a trailing slash.
+func (l *Links) BaseURL(org *Organization) string {
+	return l.rebase(l.defaultURL, org).String()
+}
+
+// URL returns the absolute URL of path (which may carry a query) on the organization's host.
+func (l *Links) URL(org *Organization, path string) string {
+	return l.BaseURL(org) + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Rewrite moves an absolute URL on the default host (e.g. a configured redirect URL) to the organization's
+// host, keeping its path and query. URLs on other hosts are returned unchanged.
+func (l *Links) Rewrite(rawURL string, org *Organization) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Host, l.defaultURL.Host) {
+		return rawURL
+	}
+	return l.rebase(u, org).String()
+}
+
+// rebase copies u onto the organization's host. Tenant hosts are always served over HTTPS; the default
+// host keeps its configured scheme so local development over plain HTTP keeps working.
+func (l *Links) rebase(u *url.URL, org *Organization) *url.URL {
+	out := *u
+	if host := l.Host(org); host != l.defaultURL.Host {
+		out.Scheme = "https"
+		out.Host = host
+	}
+	return &out
+}
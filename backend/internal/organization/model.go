@@ -0,0 +1,55 @@
+// prometheus/backend/internal/organization/model.go
+package organization
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Status is the lifecycle state of an organization (tenant).
+type Status string
+
+const (
+	StatusProvisioning Status = "provisioning" // Onboarding wizard still running
+	StatusActive       Status = "active"
+	StatusSuspended    Status = "suspended"
+)
+
+// Organization is a tenant. Its Slug doubles as the Casbin authorization domain.
+// Users without an organization belong to the default (single-tenant) domain.
+type Organization struct {
+	gorm.Model
+	Name           string         `gorm:"type:varchar(150);not null" json:"name" example:"Acme Corp"`
+	Slug           string         `gorm:"type:varchar(63);uniqueIndex;not null" json:"slug" example:"acme"`
+	Status         Status         `gorm:"type:varchar(20);not null;default:provisioning" json:"status" example:"active"`
+	Timezone       string         `gorm:"type:varchar(64);not null;default:Asia/Jakarta" json:"timezone" example:"Asia/Jakarta"`
+	CountryCode    string         `gorm:"type:char(2)" json:"country_code,omitempty" example:"ID"` // Default holiday calendar
+	WorkWeek       datatypes.JSON `json:"work_week,omitempty" swaggertype:"array,integer"`         // ISO weekdays, e.g. [1,2,3,4,5]
+	HolidayPresets datatypes.JSON `json:"holiday_presets,omitempty" swaggertype:"array,object"`    // Default holidays captured during onboarding
+
+	Plan             string `gorm:"type:varchar(30);not null;default:free" json:"plan" example:"standard"` // See internal/plan
+	StorageUsedBytes int64  `gorm:"not null;default:0" json:"storage_used_bytes" example:"0"`
+
+	// White-label: tenants are reachable at <slug>.<TENANT_BASE_DOMAIN> and optionally at their own domain.
+	CustomDomain *string  `gorm:"type:varchar(253);uniqueIndex" json:"custom_domain,omitempty" example:"hr.acme.example"`
+	Branding     Branding `gorm:"embedded;embeddedPrefix:brand_" json:"branding"`
+
+	// Demo tenants are periodically wiped and reseeded with fake data (see tenant.DemoService).
+	IsDemo      bool       `gorm:"not null;default:false" json:"is_demo" example:"false"`
+	DemoResetAt *time.Time `json:"demo_reset_at,omitempty"`
+}
+
+// Branding is how a tenant's white-labelled frontend and generated links present themselves.
+type Branding struct {
+	DisplayName  string `gorm:"type:varchar(150)" json:"display_name,omitempty" binding:"max=150" example:"Acme People"`
+	LogoURL      string `gorm:"type:varchar(500)" json:"logo_url,omitempty" binding:"omitempty,url,max=500" example:"https://cdn.acme.example/logo.svg"`
+	PrimaryColor string `gorm:"type:varchar(7)" json:"primary_color,omitempty" binding:"omitempty,hexcolor" example:"#0055ff"`
+	SupportEmail string `gorm:"type:varchar(255)" json:"support_email,omitempty" binding:"omitempty,email" example:"hr@acme.example"`
+}
+
+// Domain returns the authorization domain of the organization.
+func (o *Organization) Domain() string {
+	return o.Slug
+}
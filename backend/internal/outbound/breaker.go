@@ -0,0 +1,120 @@
+// prometheus/backend/internal/outbound/breaker.go
+package outbound
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the integration while its circuit is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	StateClosed   State = iota // Calls go through
+	StateOpen                  // Calls fail fast until the cool-down has passed
+	StateHalfOpen              // One probe call decides whether to close or reopen
+)
+
+// Breaker fails calls to an integration fast after it failed repeatedly, so handlers don't queue up
+// behind timeouts, and lets a single probe through once the cool-down has passed.
+type Breaker struct {
+	name      string
+	threshold int
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int // Consecutive failures while closed
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker creates a closed breaker that opens after threshold consecutive failures and stays open for
+// coolDown. Zero values fall back to DefaultPolicy.
+func NewBreaker(name string, threshold int, coolDown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultPolicy.FailureThreshold
+	}
+	if coolDown <= 0 {
+		coolDown = DefaultPolicy.CoolDown
+	}
+	b := &Breaker{name: name, threshold: threshold, coolDown: coolDown}
+	metrics.state.WithLabelValues(name).Set(float64(StateClosed))
+	return b
+}
+
+// Allow reserves a call, or returns ErrCircuitOpen. Every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.coolDown {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call.
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+	if success {
+		b.failures = 0
+		b.setState(StateClosed)
+		return
+	}
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(StateOpen)
+	}
+}
+
+// Do runs fn through the breaker, counting any error as a failure. It suits integrations without an
+// HTTP client to wrap, such as SMTP.
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		metrics.calls.WithLabelValues(b.name, outcomeRejected).Inc()
+		return err
+	}
+	start := time.Now()
+	err := fn()
+	metrics.duration.WithLabelValues(b.name).Observe(time.Since(start).Seconds())
+	b.Record(err == nil)
+	metrics.calls.WithLabelValues(b.name, outcome(err == nil)).Inc()
+	return err
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// setState changes the state and its gauge; b.mu must be held.
+func (b *Breaker) setState(s State) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	metrics.state.WithLabelValues(b.name).Set(float64(s))
+}
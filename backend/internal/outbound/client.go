@@ -0,0 +1,169 @@
+// prometheus/backend/internal/outbound/client.go
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Policy configures how an integration is called. Zero fields fall back to DefaultPolicy.
+type Policy struct {
+	Timeout          time.Duration // Per attempt, including reading the response body
+	MaxAttempts      int           // 1 disables retries, e.g. for SDKs that retry on their own
+	Backoff          time.Duration // Before the first retry; doubled for each further one, with jitter
+	FailureThreshold int           // Consecutive failures that open the circuit
+	CoolDown         time.Duration // How long the circuit stays open before a probe
+	RetryRatio       float64       // Share of calls that may be retried, so retries can't multiply an outage
+}
+
+// DefaultPolicy suits a typical JSON API.
+var DefaultPolicy = Policy{
+	Timeout:          10 * time.Second,
+	MaxAttempts:      3,
+	Backoff:          200 * time.Millisecond,
+	FailureThreshold: 5,
+	CoolDown:         30 * time.Second,
+	RetryRatio:       0.2,
+}
+
+// maxRetryTokens bounds the retries saved up while an integration is healthy.
+const maxRetryTokens = 10
+
+// Client is an HTTP client for one integration with timeouts, retries and a circuit breaker. Only
+// idempotent requests are retried: GET, HEAD, PUT, DELETE and OPTIONS, and others that carry an
+// Idempotency-Key header. Connection errors, 429 and 5xx responses count as failures.
+type Client struct {
+	name    string
+	http    *http.Client
+	policy  Policy
+	breaker *Breaker
+
+	mu     sync.Mutex
+	tokens float64 // Retry budget
+}
+
+// NewClient creates a Client for the named integration; the name labels its metrics.
+func NewClient(name string, policy Policy) *Client {
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultPolicy.Timeout
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = DefaultPolicy.Backoff
+	}
+	if policy.RetryRatio <= 0 {
+		policy.RetryRatio = DefaultPolicy.RetryRatio
+	}
+	return &Client{
+		name:    name,
+		http:    &http.Client{Timeout: policy.Timeout},
+		policy:  policy,
+		breaker: NewBreaker(name, policy.FailureThreshold, policy.CoolDown),
+		tokens:  maxRetryTokens,
+	}
+}
+
+// Do sends req, retrying failed attempts while the policy and the retry budget allow. It returns the
+// last response or error; a response with a failure status is returned as is, not as an error.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	c.deposit()
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.Allow(); err != nil {
+			metrics.calls.WithLabelValues(c.name, outcomeRejected).Inc()
+			return nil, err
+		}
+		try := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				c.breaker.Record(true) // Not the integration's fault
+				return nil, err
+			}
+			try = req.Clone(req.Context())
+			try.Body = body
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(try)
+		metrics.duration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		// A cancelled request says nothing about the integration's health.
+		if err != nil && req.Context().Err() != nil {
+			c.breaker.Record(true)
+			return nil, err
+		}
+		c.breaker.Record(!failed)
+
+		if !failed || !retryable || attempt >= c.policy.MaxAttempts || !c.withdraw() {
+			metrics.calls.WithLabelValues(c.name, outcome(!failed)).Inc()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.name, err)
+			}
+			return resp, nil
+		}
+		metrics.calls.WithLabelValues(c.name, outcomeRetry).Inc()
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), c.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the delay before the retry following attempt, with up to 50% jitter so clients that
+// failed together don't retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.policy.Backoff << (attempt - 1)
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// deposit credits the retry budget for a call.
+func (c *Client) deposit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens += c.policy.RetryRatio
+	if c.tokens > maxRetryTokens {
+		c.tokens = maxRetryTokens
+	}
+}
+
+// withdraw takes a retry from the budget, reporting false once it's spent.
+func (c *Client) withdraw() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens < 1 {
+		return false
+	}
+	c.tokens--
+	return true
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// sleep waits for d unless ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+// prometheus/backend/internal/outbound/metrics.go
+package outbound
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Outcomes of a call, as the "outcome" label.
+const (
+	outcomeSuccess  = "success"
+	outcomeFailure  = "failure"
+	outcomeRetry    = "retry"    // A failed attempt that was retried
+	outcomeRejected = "rejected" // Not attempted: circuit open
+)
+
+// metrics are shared by every integration, labelled by its name. They are registered with Register.
+var metrics = struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	state    *prometheus.GaugeVec
+}{
+	calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hris_outbound_calls_total",
+		Help: "Outbound integration calls by integration and outcome (success, failure, retry, rejected).",
+	}, []string{"integration", "outcome"}),
+	duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hris_outbound_call_duration_seconds",
+		Help:    "Outbound integration call latency per attempt.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"integration"}),
+	state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hris_outbound_circuit_state",
+		Help: "Circuit breaker state per integration: 0 closed, 1 open, 2 half-open.",
+	}, []string{"integration"}),
+}
+
+// Register adds the outbound call metrics to reg.
+func Register(reg prometheus.Registerer) {
+	reg.MustRegister(metrics.calls, metrics.duration, metrics.state)
+}
+
+func outcome(success bool) string {
+	if success {
+		return outcomeSuccess
+	}
+	return outcomeFailure
+}
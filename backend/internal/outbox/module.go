@@ -0,0 +1,55 @@
+// prometheus/backend/internal/outbox/module.go
+package outbox
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"time"
+)
+
+// JobRelay is the recurring job type that dispatches outbox messages.
+const JobRelay = "outbox.relay"
+
+// relayInterval is how often due messages are looked for.
+const relayInterval = 5 * time.Second
+
+// Name implements module.Module.
+func (o *Outbox) Name() string { return "outbox" }
+
+// HealthContributors implements module.Module. Dead messages are side effects that never happened.
+func (o *Outbox) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("messages", func(ctx context.Context) module.HealthResult {
+			var pending, dead int64
+			if err := o.db.WithContext(ctx).Model(&Message{}).Where("status = ?", StatusPending).Count(&pending).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := o.db.WithContext(ctx).Model(&Message{}).Where("status = ?", StatusDead).Count(&dead).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			status := module.StatusUp
+			if dead > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"pending": float64(pending), "dead": float64(dead)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (o *Outbox) Models() []any {
+	return []any{&Message{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (o *Outbox) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobRelay, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		sent, failed, err := o.Relay(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"sent": sent, "failed": failed}, nil
+	})
+	q.Every(JobRelay, relayInterval)
+}
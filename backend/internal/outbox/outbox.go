@@ -0,0 +1,198 @@
+// prometheus/backend/internal/outbox/outbox.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownKind is returned when adding a message of a kind without a registered dispatcher.
+var ErrUnknownKind = errors.New("unknown outbox message kind")
+
+// Status is the delivery state of a message.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	StatusDead    Status = "dead" // Gave up after maxAttempts; needs a look
+)
+
+// maxAttempts is how often a message is dispatched before it is given up.
+const maxAttempts = 10
+
+// maxBackoff caps the delay between attempts.
+const maxBackoff = time.Hour
+
+// sentRetention is how long sent messages are kept for investigations.
+const sentRetention = 7 * 24 * time.Hour
+
+// Message is a side effect (an email, a webhook call, a notification) recorded in the transaction of the
+// change that triggers it and dispatched after the commit. A rolled-back change leaves no message behind,
+// and a committed one is dispatched even if the process dies right after.
+type Message struct {
+	ID            string         `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Kind          string         `gorm:"type:varchar(100);not null;index" json:"kind" example:"mail"`
+	Payload       datatypes.JSON `json:"-"`
+	Status        Status         `gorm:"type:varchar(20);not null;index" json:"status" example:"pending"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time      `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// TableName implements gorm's Tabler.
+func (Message) TableName() string { return "outbox_messages" }
+
+// DecodePayload unmarshals the message payload into dest.
+func (m *Message) DecodePayload(dest interface{}) error {
+	return json.Unmarshal(m.Payload, dest)
+}
+
+// Dispatcher performs the side effect of a message. Delivery is at-least-once: a dispatcher may see the
+// same message again after a crash, so it should pass Message.ID on as an idempotency key where the
+// receiving end supports one.
+type Dispatcher func(ctx context.Context, msg *Message) error
+
+// Outbox records side effects in transactions and dispatches them.
+type Outbox struct {
+	db *gorm.DB
+
+	mu          sync.RWMutex
+	dispatchers map[string]Dispatcher
+}
+
+// New creates an Outbox. Register a dispatcher for every kind before adding messages of it.
+func New(db *gorm.DB) *Outbox {
+	return &Outbox{db: db, dispatchers: make(map[string]Dispatcher)}
+}
+
+// Register installs the dispatcher of a message kind.
+func (o *Outbox) Register(kind string, dispatcher Dispatcher) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.dispatchers[kind] = dispatcher
+}
+
+// AddTx records a message in tx. It is dispatched once tx commits.
+func (o *Outbox) AddTx(tx *gorm.DB, kind string, payload interface{}) (*Message, error) {
+	o.mu.RLock()
+	_, known := o.dispatchers[kind]
+	o.mu.RUnlock()
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s message: %w", kind, err)
+	}
+	msg := &Message{
+		ID:            uuid.NewString(),
+		Kind:          kind,
+		Payload:       data,
+		Status:        StatusPending,
+		NextAttemptAt: time.Now().UTC(),
+	}
+	if err := tx.Create(msg).Error; err != nil {
+		return nil, fmt.Errorf("failed to add %s message to the outbox: %w", kind, err)
+	}
+	return msg, nil
+}
+
+// Relay dispatches due messages until none is left or ctx is done, and purges old sent messages.
+// Each message is locked while it is dispatched, so replicas relaying concurrently never dispatch the
+// same message twice at the same time.
+func (o *Outbox) Relay(ctx context.Context) (sent, failed int, err error) {
+	if err := o.db.WithContext(ctx).Where("status = ? AND sent_at < ?", StatusSent, time.Now().UTC().Add(-sentRetention)).
+		Delete(&Message{}).Error; err != nil {
+		log.Printf("Outbox: failed to purge sent messages: %v", err)
+	}
+	for ctx.Err() == nil {
+		found, ok, err := o.dispatchNext(ctx)
+		if err != nil {
+			return sent, failed, err
+		}
+		if !found {
+			break
+		}
+		if ok {
+			sent++
+		} else {
+			failed++
+		}
+	}
+	return sent, failed, ctx.Err()
+}
+
+// dispatchNext dispatches the oldest due message, if any, and stores the outcome.
+func (o *Outbox) dispatchNext(ctx context.Context) (found, ok bool, err error) {
+	err = o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var msg Message
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, time.Now().UTC()).
+			Order("next_attempt_at").First(&msg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load outbox message: %w", err)
+		}
+		found = true
+
+		dispatchErr := o.dispatch(ctx, &msg)
+		now := time.Now().UTC()
+		updates := map[string]interface{}{"attempts": msg.Attempts + 1}
+		switch {
+		case dispatchErr == nil:
+			ok = true
+			updates["status"] = StatusSent
+			updates["sent_at"] = now
+			updates["last_error"] = ""
+		case msg.Attempts+1 >= maxAttempts:
+			log.Printf("Outbox: giving up on %s message %s: %v", msg.Kind, msg.ID, dispatchErr)
+			updates["status"] = StatusDead
+			updates["last_error"] = dispatchErr.Error()
+		default:
+			updates["next_attempt_at"] = now.Add(backoff(msg.Attempts + 1))
+			updates["last_error"] = dispatchErr.Error()
+		}
+		return tx.Model(&msg).Updates(updates).Error
+	})
+	return found, ok, err
+}
+
+// dispatch runs the message's dispatcher, converting panics into failures.
+func (o *Outbox) dispatch(ctx context.Context, msg *Message) (err error) {
+	o.mu.RLock()
+	dispatcher, known := o.dispatchers[msg.Kind]
+	o.mu.RUnlock()
+	if !known {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, msg.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("dispatcher panicked: %v", r)
+		}
+	}()
+	return dispatcher(ctx, msg)
+}
+
+// backoff doubles the delay after every failed attempt, starting at a minute.
+func backoff(attempts int) time.Duration {
+	d := time.Minute << (attempts - 1)
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,226 @@
+// prometheus/backend/internal/payroll/calc.go
+package payroll
+
+import (
+	"fmt"
+	"math"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/holiday"
+	"prometheus/backend/internal/timesheet"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// calculate works out each employee's line for a period from the data as it stands; see Line. Employees
+// hired by the period's end get a line, with or without a salary on file, so HR sees who is missing one.
+// employeeIDs narrows the calculation to some employees (nil = all of them).
+func (s *service) calculate(db *gorm.DB, orgID *uint, period Period, employeeIDs []uint) ([]Line, error) {
+	query := utils.OrgScope(db.Select("id, division_id, hire_date"), orgID).Where("hire_date <= ?", period.EndOn)
+	if employeeIDs != nil {
+		query = query.Where("id IN ?", employeeIDs)
+	}
+	var employees []employee.Employee
+	if err := query.Order("id").Find(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+	if len(employees) == 0 {
+		return []Line{}, nil
+	}
+	ids := make([]uint, len(employees))
+	for i, e := range employees {
+		ids[i] = e.ID
+	}
+	// Weeks ending in the period count toward its overtime, so days are looked at from the Monday starting
+	// the first of them.
+	from := period.StartOn.AddDate(0, 0, -6)
+	working, err := s.workingDays(orgID, ids, from, period.EndOn)
+	if err != nil {
+		return nil, err
+	}
+	leave, err := leaveDays(db, orgID, ids, from, period.EndOn)
+	if err != nil {
+		return nil, err
+	}
+	weeks, err := approvedWeeks(db, orgID, ids, from, period.EndOn.AddDate(0, 0, -6))
+	if err != nil {
+		return nil, err
+	}
+	salaries, err := s.salaries.Salaries(orgID, ids, period.StartOn, period.EndOn)
+	if err != nil {
+		return nil, err
+	}
+	components, err := s.salaries.ComponentsBetween(orgID, ids, period.StartOn, period.EndOn)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := s.agreements.Terms(orgID, ids, period.EndOn)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, 0, len(employees))
+	for _, e := range employees {
+		line := Line{PeriodID: period.ID, EmployeeID: e.ID, DivisionID: e.DivisionID, OvertimePercent: period.OvertimePercent}
+		if percent := terms[e.ID].OvertimePercent; percent != nil {
+			line.OvertimePercent = *percent
+		}
+		history := salaries[e.ID]
+		if n := len(history); n > 0 {
+			line.Currency, line.AnnualSalary = history[n-1].Currency, history[n-1].Amount
+		}
+		start := period.StartOn
+		if hired := date(e.HireDate); hired.After(start) {
+			start = hired
+		}
+		var basePay, allowances, deductions float64
+		var paidWorkingDays, paidLeaveDays int
+		for d := start; !d.After(period.EndOn); d = d.AddDate(0, 0, 1) {
+			isWorking := working[e.ID][key(d)]
+			onLeave := isWorking && leave[e.ID][key(d)]
+			if isWorking {
+				line.WorkingDays++
+			}
+			if onLeave {
+				line.UnpaidLeaveDays++
+			}
+			record := inEffect(history, d)
+			switch {
+			case record == nil:
+				line.MissingSalaryDays++
+			case record.Currency != line.Currency:
+				line.CurrencyChanged = true
+			default:
+				line.PaidDays++
+				basePay += float64(record.Amount) / float64(daysInYear(d.Year()))
+				if isWorking {
+					paidWorkingDays++
+				}
+				if onLeave {
+					paidLeaveDays++
+				}
+			}
+			for _, c := range components[e.ID] {
+				if date(c.StartOn).After(d) || (c.EndOn != nil && date(*c.EndOn).Before(d)) {
+					continue
+				}
+				if c.Currency != line.Currency {
+					line.ForeignComponents = true
+					continue
+				}
+				accrued := float64(c.Amount) / float64(daysInYear(d.Year()))
+				if c.Kind == compensation.ComponentDeduction {
+					deductions += accrued
+				} else {
+					allowances += accrued
+				}
+			}
+		}
+		for weekStart, minutes := range weeks[e.ID] {
+			expected := 0
+			for d := weekStart; d.Before(weekStart.AddDate(0, 0, 7)); d = d.AddDate(0, 0, 1) {
+				if working[e.ID][key(d)] && !leave[e.ID][key(d)] {
+					expected += period.DayMinutes
+				}
+			}
+			line.OvertimeMinutes += max(0, minutes-expected)
+		}
+		if paidWorkingDays > 0 {
+			dailyRate := basePay / float64(paidWorkingDays)
+			line.UnpaidLeaveDeduction = int64(math.Round(dailyRate * float64(paidLeaveDays)))
+			line.OvertimePay = int64(math.Round(dailyRate / float64(period.DayMinutes) * float64(line.OvertimeMinutes) *
+				float64(line.OvertimePercent) / 100))
+		}
+		line.BasePay = int64(math.Round(basePay))
+		line.Allowances, line.Deductions = int64(math.Round(allowances)), int64(math.Round(deductions))
+		line.GrossPay = line.BasePay + line.Allowances - line.UnpaidLeaveDeduction + line.OvertimePay
+		line.NetPay = line.GrossPay - line.Deductions
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// workingDays returns, by employee, the days from from to to they are expected at work, as keys.
+func (s *service) workingDays(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint]map[string]bool, error) {
+	working := make(map[uint]map[string]bool, len(employeeIDs))
+	for _, id := range employeeIDs {
+		working[id] = make(map[string]bool)
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		schedule, err := s.holidays.Schedule(orgID, employeeIDs, d)
+		if err != nil {
+			return nil, err
+		}
+		for id, day := range schedule.Days {
+			if day.Kind == holiday.DayWorking {
+				working[id][key(d)] = true
+			}
+		}
+	}
+	return working, nil
+}
+
+// leaveDays returns, by employee, the days from from to to they are on unpaid leave, as keys.
+func leaveDays(db *gorm.DB, orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint]map[string]bool, error) {
+	var leave []UnpaidLeave
+	if err := utils.OrgScope(db, orgID).Where("employee_id IN ? AND start_on <= ? AND end_on >= ?", employeeIDs, to, from).
+		Find(&leave).Error; err != nil {
+		return nil, fmt.Errorf("failed to load unpaid leave: %w", err)
+	}
+	days := make(map[uint]map[string]bool)
+	for _, l := range leave {
+		if days[l.EmployeeID] == nil {
+			days[l.EmployeeID] = make(map[string]bool)
+		}
+		for d := date(l.StartOn); !d.After(date(l.EndOn)); d = d.AddDate(0, 0, 1) {
+			days[l.EmployeeID][key(d)] = true
+		}
+	}
+	return days, nil
+}
+
+// approvedWeeks returns, by employee, the minutes logged on approved timesheets of the weeks starting from
+// from to to, by week start.
+func approvedWeeks(db *gorm.DB, orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint]map[time.Time]int, error) {
+	var timesheets []timesheet.Timesheet
+	if err := utils.OrgScope(db.Select("employee_id, week_start, total_minutes"), orgID).
+		Where("employee_id IN ? AND status = ? AND week_start BETWEEN ? AND ?", employeeIDs, timesheet.StatusApproved, from, to).
+		Find(&timesheets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load approved timesheets: %w", err)
+	}
+	weeks := make(map[uint]map[time.Time]int)
+	for _, t := range timesheets {
+		if weeks[t.EmployeeID] == nil {
+			weeks[t.EmployeeID] = make(map[time.Time]int)
+		}
+		weeks[t.EmployeeID][date(t.WeekStart)] = t.TotalMinutes
+	}
+	return weeks, nil
+}
+
+// inEffect returns the record of a history, earliest first, in effect on d, or nil before the first one.
+func inEffect(history []compensation.SalaryRecord, d time.Time) *compensation.SalaryRecord {
+	var record *compensation.SalaryRecord
+	for i := range history {
+		if date(history[i].EffectiveOn).After(d) {
+			break
+		}
+		record = &history[i]
+	}
+	return record
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// date drops the time of day, so dates read from the database compare with parsed ones.
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func key(d time.Time) string {
+	return d.Format("2006-01-02")
+}
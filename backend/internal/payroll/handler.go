@@ -0,0 +1,334 @@
+// prometheus/backend/internal/payroll/handler.go
+package payroll
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for payroll periods and unpaid leave.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListPeriods returns the organization's payroll periods, latest first.
+// @Summary List payroll periods
+// @Tags Payroll
+// @Produce json
+// @Param status query string false "Status" Enums(open, closed)
+// @Success 200 {array} Period
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Router /hr/payroll/periods [get]
+func (h *Handler) ListPeriods(c *gin.Context) {
+	status := PeriodStatus(c.Query("status"))
+	switch status {
+	case "", PeriodOpen, PeriodClosed:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	periods, err := h.service.Periods(utils.OrganizationFromContext(c), status)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll periods fetched successfully", periods)
+}
+
+// GetPeriod returns a payroll period. The ETag and Last-Modified headers can be sent back as If-Match /
+// If-Unmodified-Since.
+// @Summary Get a payroll period
+// @Tags Payroll
+// @Produce json
+// @Param id path int true "Period ID"
+// @Success 200 {object} Period
+// @Failure 404 {object} utils.ErrorResponse "Period not found"
+// @Router /hr/payroll/periods/{id} [get]
+func (h *Handler) GetPeriod(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	period, err := h.service.GetPeriod(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, period.UpdatedAt, period.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll period fetched successfully", period)
+}
+
+// CreatePeriod opens a payroll period.
+// @Summary Create a payroll period
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param period body PeriodRequest true "Period"
+// @Success 201 {object} Period
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Failure 409 {object} utils.ErrorResponse "Overlaps another period"
+// @Router /hr/payroll/periods [post]
+func (h *Handler) CreatePeriod(c *gin.Context) {
+	var req PeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	period, err := h.service.CreatePeriod(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, period.UpdatedAt, period.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Payroll period created successfully", period)
+}
+
+// UpdatePeriod replaces an open payroll period's fields.
+// @Summary Update a payroll period
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param id path int true "Period ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param period body PeriodRequest true "Period"
+// @Success 200 {object} Period
+// @Failure 400 {object} utils.ErrorResponse "Invalid period"
+// @Failure 404 {object} utils.ErrorResponse "Period not found"
+// @Failure 409 {object} utils.ErrorResponse "Closed, or overlaps another period"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/payroll/periods/{id} [put]
+func (h *Handler) UpdatePeriod(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req PeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetPeriod(orgID, id)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	period, err := h.service.UpdatePeriod(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, period.UpdatedAt, period.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll period updated successfully", period)
+}
+
+// Preview returns what a payroll period pays each employee: salary, unpaid leave deducted and approved
+// overtime.
+// @Summary Preview a payroll period
+// @Description Open periods are calculated from current salaries, unpaid leave and approved timesheets on
+// @Description every call; closed ones return the figures stored when they closed. Lines missing salary
+// @Description days or with a currency change are counted in warnings. Adjustments list the differences
+// @Description owed for closed periods after salary changes entered since they closed, paid on the lines.
+// @Tags Payroll
+// @Produce json
+// @Param id path int true "Period ID"
+// @Success 200 {object} Preview
+// @Failure 404 {object} utils.ErrorResponse "Period not found"
+// @Router /hr/payroll/periods/{id}/preview [get]
+func (h *Handler) Preview(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	preview, err := h.service.Preview(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, preview.Period.UpdatedAt, preview.Period.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll preview fetched successfully", preview)
+}
+
+// Close freezes a payroll period's figures as last previewed.
+// @Summary Close a payroll period
+// @Description Stores the preview as calculated at closing. Unpaid leave within the period can't be
+// @Description recorded or deleted afterwards. Send the ETag of the preview reviewed as If-Match.
+// @Tags Payroll
+// @Produce json
+// @Param id path int true "Period ID"
+// @Param If-Match header string false "Version ETag from the preview"
+// @Success 200 {object} Preview
+// @Failure 404 {object} utils.ErrorResponse "Period not found"
+// @Failure 409 {object} utils.ErrorResponse "Already closed"
+// @Failure 412 {object} utils.ErrorResponse "Modified since previewed"
+// @Router /hr/payroll/periods/{id}/close [post]
+func (h *Handler) Close(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetPeriod(orgID, id)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	preview, err := h.service.Close(audit.ActorFromContext(c), orgID, id, expectedVersion)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, preview.Period.UpdatedAt, preview.Period.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll period closed successfully", preview)
+}
+
+// ListAdjustments returns the retroactive differences closed periods paid, latest first.
+// @Summary List retroactive pay adjustments
+// @Description Each adjustment traces a difference to the period it corrects, what that period paid and
+// @Description owes, and the salary records entered late that caused it.
+// @Tags Payroll
+// @Produce json
+// @Param employee_id query int false "Employee ID"
+// @Param source_period_id query int false "Corrected period ID"
+// @Success 200 {array} Adjustment
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/payroll/adjustments [get]
+func (h *Handler) ListAdjustments(c *gin.Context) {
+	var filter AdjustmentFilter
+	var ok bool
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if filter.SourcePeriodID, ok = utils.OptionalUintQuery(c, "source_period_id"); !ok {
+		return
+	}
+	adjustments, err := h.service.Adjustments(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Payroll adjustments fetched successfully", adjustments)
+}
+
+// ListUnpaidLeave returns unpaid leave, latest first.
+// @Summary List unpaid leave
+// @Tags Payroll
+// @Produce json
+// @Param employee_id query int false "Employee ID"
+// @Param from query string false "Overlapping from (YYYY-MM-DD)"
+// @Param to query string false "Overlapping until (YYYY-MM-DD)"
+// @Success 200 {array} UnpaidLeave
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/payroll/unpaid-leave [get]
+func (h *Handler) ListUnpaidLeave(c *gin.Context) {
+	var filter UnpaidLeaveFilter
+	var ok bool
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if filter.From, ok = optionalDate(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = optionalDate(c, "to"); !ok {
+		return
+	}
+	leave, err := h.service.UnpaidLeave(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Unpaid leave fetched successfully", leave)
+}
+
+// RecordUnpaidLeave records unpaid leave for an employee, deducted from the periods it falls in.
+// @Summary Record unpaid leave
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param leave body UnpaidLeaveRequest true "Unpaid leave"
+// @Success 201 {object} UnpaidLeave
+// @Failure 400 {object} utils.ErrorResponse "Invalid dates"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Overlaps other unpaid leave, or a closed period"
+// @Router /hr/payroll/unpaid-leave [post]
+func (h *Handler) RecordUnpaidLeave(c *gin.Context) {
+	var req UnpaidLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	leave, err := h.service.RecordUnpaidLeave(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Unpaid leave recorded successfully", leave)
+}
+
+// DeleteUnpaidLeave deletes unpaid leave outside closed periods.
+// @Summary Delete unpaid leave
+// @Tags Payroll
+// @Param id path int true "Unpaid leave ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse "Unpaid leave not found"
+// @Failure 409 {object} utils.ErrorResponse "Within a closed period"
+// @Router /hr/payroll/unpaid-leave/{id} [delete]
+func (h *Handler) DeleteUnpaidLeave(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteUnpaidLeave(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendPayrollError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func optionalDate(c *gin.Context, name string) (*time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+name+" parameter: expected YYYY-MM-DD")
+		return nil, false
+	}
+	return &value, true
+}
+
+// sendPayrollError maps service errors to HTTP status codes.
+func sendPayrollError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidPayroll):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrPeriodClosed), errors.Is(err, ErrPeriodOverlap), errors.Is(err, ErrLeaveOverlap):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,180 @@
+// prometheus/backend/internal/payroll/model.go
+package payroll
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// PeriodStatus is where a payroll period is.
+type PeriodStatus string
+
+const (
+	PeriodOpen   PeriodStatus = "open"   // The preview is calculated from current data
+	PeriodClosed PeriodStatus = "closed" // The figures are frozen; unpaid leave within it can't change
+)
+
+// Period is a span of days paid together. Periods of an organization don't overlap. Amounts throughout the
+// package are in minor units of their currency (cents), like salaries.
+type Period struct {
+	ID               uint         `gorm:"primaryKey" json:"id" example:"9"`
+	OrganizationID   *uint        `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name             string       `gorm:"type:varchar(100);not null" json:"name" example:"October 2026"`
+	StartOn          time.Time    `gorm:"type:date;not null;index" json:"start_on" example:"2026-10-01T00:00:00Z"`
+	EndOn            time.Time    `gorm:"type:date;not null" json:"end_on" example:"2026-10-31T00:00:00Z"`
+	DayMinutes       int          `gorm:"not null" json:"day_minutes" example:"480"`      // A working day; approved hours beyond these are overtime
+	OvertimePercent  int          `gorm:"not null" json:"overtime_percent" example:"150"` // Of the regular rate, paid for overtime
+	PolicyRevisionID *uint        `json:"policy_revision_id,omitempty" example:"11"`      // Payroll policy revision in effect on StartOn, if its terms were used
+	Status           PeriodStatus `gorm:"type:varchar(20);not null;index" json:"status" example:"open"`
+	ClosedAt         *time.Time   `json:"closed_at,omitempty"`
+	ClosedBy         *uint        `json:"closed_by,omitempty" example:"7"`               // User ID
+	Version          uint         `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// TableName keeps periods with the rest of the payroll tables.
+func (Period) TableName() string { return "payroll_periods" }
+
+// UnpaidLeave is time off without pay, deducted per working day it spans. HR records it here until leave
+// requests carry it.
+type UnpaidLeave struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"14"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	StartOn        time.Time `gorm:"type:date;not null" json:"start_on" example:"2026-10-19T00:00:00Z"`
+	EndOn          time.Time `gorm:"type:date;not null" json:"end_on" example:"2026-10-21T00:00:00Z"`
+	Reason         string    `gorm:"type:varchar(500)" json:"reason,omitempty" example:"Extended travel"`
+	RecordedBy     *uint     `json:"recorded_by,omitempty" example:"7"` // User ID
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps unpaid leave with the rest of the payroll tables.
+func (UnpaidLeave) TableName() string { return "payroll_unpaid_leave" }
+
+// Line is an employee's pay for a period. Open periods calculate lines on the fly; closing a period stores
+// them.
+//
+// Salary accrues per calendar day employed, at the annual salary in effect that day over the days of its
+// year. The daily rate, what a working day earns, is that pay over the working days employed in the period;
+// unpaid leave deducts it for each working day taken, and overtime pays it per minute times the overtime
+// percentage of the employee's collective agreement, or the period's if it sets none. Overtime is what
+// approved timesheets of weeks ending in the period log beyond the week's working days, less unpaid leave,
+// times the period's working day. Salary components, allowances and deductions, accrue per calendar day
+// employed like the salary, in its currency. Retroactive differences owed for closed periods, see
+// Adjustment, are paid on top.
+type Line struct {
+	ID                   uint   `gorm:"primaryKey" json:"-"`
+	PeriodID             uint   `gorm:"not null;uniqueIndex:idx_payroll_line" json:"period_id" example:"9"`
+	EmployeeID           uint   `gorm:"not null;uniqueIndex:idx_payroll_line" json:"employee_id" example:"12"`
+	DisplayName          string `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	DivisionID           *uint  `json:"division_id,omitempty" example:"2"`
+	Currency             string `gorm:"type:char(3)" json:"currency,omitempty" example:"EUR"` // Empty without any salary on file
+	AnnualSalary         int64  `gorm:"not null" json:"annual_salary" example:"6200000"`      // In effect on the period's last day
+	PaidDays             int    `gorm:"not null" json:"paid_days" example:"31"`               // Calendar days employed with a salary on file
+	MissingSalaryDays    int    `gorm:"not null" json:"missing_salary_days" example:"0"`      // Calendar days employed without one
+	CurrencyChanged      bool   `gorm:"not null" json:"currency_changed"`                     // Days paid in an earlier currency are left out
+	BasePay              int64  `gorm:"not null" json:"base_pay" example:"526575"`
+	Allowances           int64  `gorm:"not null" json:"allowances" example:"30575"`
+	ForeignComponents    bool   `gorm:"not null" json:"foreign_components"` // Components in another currency than the salary are left out
+	WorkingDays          int    `gorm:"not null" json:"working_days" example:"22"`
+	UnpaidLeaveDays      int    `gorm:"not null" json:"unpaid_leave_days" example:"3"` // Working days
+	UnpaidLeaveDeduction int64  `gorm:"not null" json:"unpaid_leave_deduction" example:"71806"`
+	OvertimeMinutes      int    `gorm:"not null" json:"overtime_minutes" example:"150"`
+	OvertimePercent      int    `gorm:"not null" json:"overtime_percent" example:"150"` // The agreement's or the period's
+	OvertimePay          int64  `gorm:"not null" json:"overtime_pay" example:"11220"`
+	GrossPay             int64  `gorm:"not null" json:"gross_pay" example:"496564"`
+	Deductions           int64  `gorm:"not null" json:"deductions" example:"8494"`
+	RetroPay             int64  `gorm:"not null;default:0" json:"retro_pay" example:"24630"`    // Included in gross pay; negative to recover an overpayment
+	RetroDeductions      int64  `gorm:"not null;default:0" json:"retro_deductions" example:"0"` // Included in deductions
+	RetroForeign         bool   `gorm:"not null;default:false" json:"retro_foreign"`            // Differences in another currency than the salary are left out
+	NetPay               int64  `gorm:"not null" json:"net_pay" example:"488070"`               // Gross pay less deductions, before tax
+}
+
+// TableName keeps lines with the rest of the payroll tables.
+func (Line) TableName() string { return "payroll_lines" }
+
+// Total adds up a period's lines in one currency.
+type Total struct {
+	Currency             string `json:"currency" example:"EUR"`
+	Employees            int    `json:"employees" example:"48"`
+	BasePay              int64  `json:"base_pay" example:"24100000"`
+	Allowances           int64  `json:"allowances" example:"1250000"`
+	UnpaidLeaveDeduction int64  `json:"unpaid_leave_deduction" example:"71806"`
+	OvertimePay          int64  `json:"overtime_pay" example:"95300"`
+	GrossPay             int64  `json:"gross_pay" example:"25373494"`
+	Deductions           int64  `json:"deductions" example:"410000"`
+	RetroPay             int64  `json:"retro_pay" example:"24630"`
+	RetroDeductions      int64  `json:"retro_deductions" example:"0"`
+	NetPay               int64  `json:"net_pay" example:"24963494"`
+}
+
+// Preview is what a period pays: calculated from current data while it is open, as stored once closed.
+type Preview struct {
+	Period      Period       `json:"period"`
+	Calculated  bool         `json:"calculated"` // False once the period is closed
+	Lines       []Line       `json:"lines"`
+	Totals      []Total      `json:"totals"`
+	Adjustments []Adjustment `json:"adjustments"`          // Retroactive differences paid on the lines
+	Warnings    int          `json:"warnings" example:"2"` // Lines missing salary days or with a currency mismatch
+}
+
+// Adjustment is what a closed period owes an employee, or overpaid them, after salary records effective
+// within or before it were entered once it closed, such as a raise agreed late. The difference is paid on
+// the employee's line of the next period to close (PeriodID), and kept so the source period's later
+// recalculations only pay what is still owed.
+//
+// Paid is what the source period's stored line and earlier adjustments paid; Owed is what its line comes
+// to recalculated from the data as it stands when the next period closes.
+type Adjustment struct {
+	ID               uint           `gorm:"primaryKey" json:"id" example:"5"`
+	OrganizationID   *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	PeriodID         uint           `gorm:"not null;index" json:"period_id" example:"10"` // Paying it
+	SourcePeriodID   uint           `gorm:"not null;index:idx_payroll_adjustment_source" json:"source_period_id" example:"9"`
+	SourcePeriodName string         `gorm:"-" json:"source_period_name,omitempty" example:"September 2026"`
+	EmployeeID       uint           `gorm:"not null;index:idx_payroll_adjustment_source" json:"employee_id" example:"12"`
+	DisplayName      string         `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	Currency         string         `gorm:"type:char(3);not null" json:"currency" example:"EUR"`
+	PaidGrossPay     int64          `gorm:"not null" json:"paid_gross_pay" example:"496564"`
+	OwedGrossPay     int64          `gorm:"not null" json:"owed_gross_pay" example:"521194"`
+	GrossPay         int64          `gorm:"not null" json:"gross_pay" example:"24630"` // Owed less paid
+	PaidDeductions   int64          `gorm:"not null" json:"paid_deductions" example:"8494"`
+	OwedDeductions   int64          `gorm:"not null" json:"owed_deductions" example:"8494"`
+	Deductions       int64          `gorm:"not null" json:"deductions" example:"0"`                                                // Owed less paid
+	SalaryRecordIDs  datatypes.JSON `gorm:"type:jsonb;not null" json:"salary_record_ids" swaggertype:"array,integer" example:"31"` // Entered since, effective by the source period's end
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+// TableName keeps adjustments with the rest of the payroll tables.
+func (Adjustment) TableName() string { return "payroll_adjustments" }
+
+// AdjustmentFilter narrows a listing of stored adjustments.
+type AdjustmentFilter struct {
+	EmployeeID     *uint
+	SourcePeriodID *uint
+}
+
+// PeriodRequest creates a period or replaces its fields while it is open.
+type PeriodRequest struct {
+	Name            string `json:"name" binding:"required,max=100" example:"October 2026"`
+	StartOn         string `json:"start_on" binding:"required,datetime=2006-01-02" example:"2026-10-01"`
+	EndOn           string `json:"end_on" binding:"required,datetime=2006-01-02" example:"2026-10-31"`
+	DayMinutes      int    `json:"day_minutes,omitempty" binding:"omitempty,min=60,max=1440" example:"480"`      // Defaults to the payroll policy's, or 480
+	OvertimePercent int    `json:"overtime_percent,omitempty" binding:"omitempty,min=100,max=500" example:"150"` // Defaults to the payroll policy's, or 150
+}
+
+// UnpaidLeaveRequest records unpaid leave from StartOn to EndOn, both included.
+type UnpaidLeaveRequest struct {
+	EmployeeID uint   `json:"employee_id" binding:"required" example:"12"`
+	StartOn    string `json:"start_on" binding:"required,datetime=2006-01-02" example:"2026-10-19"`
+	EndOn      string `json:"end_on" binding:"required,datetime=2006-01-02" example:"2026-10-21"`
+	Reason     string `json:"reason,omitempty" binding:"max=500" example:"Extended travel"`
+}
+
+// UnpaidLeaveFilter narrows an unpaid leave listing. From and To keep leave overlapping them.
+type UnpaidLeaveFilter struct {
+	EmployeeID *uint
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,45 @@
+// prometheus/backend/internal/payroll/module.go
+package payroll
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the payroll module.
+const ModuleName = "payroll"
+
+// payrollModule owns payroll periods, their preview and the unpaid leave they deduct.
+type payrollModule struct {
+	handler *Handler
+}
+
+// NewModule creates the payroll module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &payrollModule{handler: NewHandler(svc)}
+}
+
+func (m *payrollModule) Name() string { return ModuleName }
+
+func (m *payrollModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *payrollModule) Models() []any {
+	return []any{&Period{}, &UnpaidLeave{}, &Line{}, &Adjustment{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Everything is HR's, within the payroll plan module.
+func (m *payrollModule) RegisterRoutes(api *routing.Group) {
+	payrollAPI := api.InModule(plan.ModulePayroll)
+	payrollAPI.GET("/hr/payroll/periods", routing.Policy(), m.handler.ListPeriods)
+	payrollAPI.POST("/hr/payroll/periods", routing.Policy(), m.handler.CreatePeriod)
+	payrollAPI.GET("/hr/payroll/periods/:id", routing.Policy(), m.handler.GetPeriod)
+	payrollAPI.PUT("/hr/payroll/periods/:id", routing.Policy(), m.handler.UpdatePeriod)
+	payrollAPI.GET("/hr/payroll/periods/:id/preview", routing.Policy(), m.handler.Preview)
+	payrollAPI.POST("/hr/payroll/periods/:id/close", routing.Policy(), m.handler.Close)
+	payrollAPI.GET("/hr/payroll/adjustments", routing.Policy(), m.handler.ListAdjustments)
+	payrollAPI.GET("/hr/payroll/unpaid-leave", routing.Policy(), m.handler.ListUnpaidLeave)
+	payrollAPI.POST("/hr/payroll/unpaid-leave", routing.Policy(), m.handler.RecordUnpaidLeave)
+	payrollAPI.DELETE("/hr/payroll/unpaid-leave/:id", routing.Policy(), m.handler.DeleteUnpaidLeave)
+}
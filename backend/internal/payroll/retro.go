@@ -0,0 +1,137 @@
+// prometheus/backend/internal/payroll/retro.go
+package payroll
+
+import (
+	"encoding/json"
+	"fmt"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// retro works out what closed periods before period owe employees whose salary history was changed
+// retroactively, and pays it on their lines; see Adjustment.
+//
+// Every period recalculates its predecessors when it closes, so only salary records entered since the
+// latest of them closed can make a difference: closed periods ending on or after the earliest of those
+// records to take effect are recalculated for the employees concerned. History imported from legacy
+// systems records what was paid already and is left out.
+func (s *service) retro(db *gorm.DB, orgID *uint, period Period, lines []Line) ([]Adjustment, error) {
+	var closed []Period
+	if err := utils.OrgScope(db, orgID).Where("status = ? AND end_on < ?", PeriodClosed, period.StartOn).
+		Order("start_on").Find(&closed).Error; err != nil {
+		return nil, fmt.Errorf("failed to list closed payroll periods: %w", err)
+	}
+	if len(closed) == 0 {
+		return []Adjustment{}, nil
+	}
+	var since time.Time
+	for _, p := range closed {
+		if p.ClosedAt != nil && p.ClosedAt.After(since) {
+			since = *p.ClosedAt
+		}
+	}
+	var records []compensation.SalaryRecord
+	if err := utils.OrgScope(db.Select("id, employee_id, effective_on"), orgID).
+		Where("created_at > ? AND effective_on <= ? AND source <> ?", since, closed[len(closed)-1].EndOn, compensation.SourceImport).
+		Order("id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load retroactive salary records: %w", err)
+	}
+	if len(records) == 0 {
+		return []Adjustment{}, nil
+	}
+	byEmployee := make(map[uint]int, len(lines))
+	for i, l := range lines {
+		byEmployee[l.EmployeeID] = i
+	}
+	adjustments := []Adjustment{}
+	for _, source := range closed {
+		// Records taking effect after the period ended don't change what it pays.
+		recordIDs := make(map[uint][]uint)
+		var employeeIDs []uint
+		for _, r := range records {
+			if date(r.EffectiveOn).After(source.EndOn) {
+				continue
+			}
+			if recordIDs[r.EmployeeID] == nil {
+				employeeIDs = append(employeeIDs, r.EmployeeID)
+			}
+			recordIDs[r.EmployeeID] = append(recordIDs[r.EmployeeID], r.ID)
+		}
+		if len(employeeIDs) == 0 {
+			continue
+		}
+		owed, err := s.calculate(db, orgID, source, employeeIDs)
+		if err != nil {
+			return nil, err
+		}
+		paid, err := paidFor(db, orgID, source.ID, period.ID, employeeIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range owed {
+			i, ok := byEmployee[o.EmployeeID]
+			if !ok || o.Currency == "" {
+				continue
+			}
+			p := paid[o.EmployeeID]
+			if p.Currency != "" && p.Currency != o.Currency {
+				// The salary's currency changed retroactively: HR settles the period by hand.
+				lines[i].RetroForeign = true
+				continue
+			}
+			adjustment := Adjustment{
+				OrganizationID: orgID, PeriodID: period.ID, SourcePeriodID: source.ID, SourcePeriodName: source.Name,
+				EmployeeID: o.EmployeeID, Currency: o.Currency,
+				PaidGrossPay: p.GrossPay, OwedGrossPay: o.GrossPay, GrossPay: o.GrossPay - p.GrossPay,
+				PaidDeductions: p.Deductions, OwedDeductions: o.Deductions, Deductions: o.Deductions - p.Deductions,
+			}
+			if adjustment.GrossPay == 0 && adjustment.Deductions == 0 {
+				continue
+			}
+			if lines[i].Currency != o.Currency {
+				lines[i].RetroForeign = true
+				continue
+			}
+			adjustment.SalaryRecordIDs, _ = json.Marshal(recordIDs[o.EmployeeID]) // Integers only, always encodes
+			line := &lines[i]
+			line.RetroPay += adjustment.GrossPay
+			line.RetroDeductions += adjustment.Deductions
+			line.GrossPay += adjustment.GrossPay
+			line.Deductions += adjustment.Deductions
+			line.NetPay += adjustment.GrossPay - adjustment.Deductions
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+	return adjustments, nil
+}
+
+// paidFor returns, by employee, what a closed period paid them: its stored line without the differences
+// it paid for earlier periods, plus the differences later periods other than the one closing paid for it.
+func paidFor(db *gorm.DB, orgID *uint, sourceID, periodID uint, employeeIDs []uint) (map[uint]Line, error) {
+	var stored []Line
+	if err := db.Where("period_id = ? AND employee_id IN ?", sourceID, employeeIDs).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payroll lines: %w", err)
+	}
+	paid := make(map[uint]Line, len(stored))
+	for _, l := range stored {
+		paid[l.EmployeeID] = Line{Currency: l.Currency, GrossPay: l.GrossPay - l.RetroPay, Deductions: l.Deductions - l.RetroDeductions}
+	}
+	var earlier []Adjustment
+	if err := utils.OrgScope(db, orgID).Where("source_period_id = ? AND period_id <> ? AND employee_id IN ?", sourceID, periodID, employeeIDs).
+		Find(&earlier).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payroll adjustments: %w", err)
+	}
+	for _, a := range earlier {
+		p := paid[a.EmployeeID]
+		if p.Currency == "" {
+			p.Currency = a.Currency
+		}
+		p.GrossPay += a.GrossPay
+		p.Deductions += a.Deductions
+		paid[a.EmployeeID] = p
+	}
+	return paid, nil
+}
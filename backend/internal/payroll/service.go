@@ -0,0 +1,548 @@
+// prometheus/backend/internal/payroll/service.go
+package payroll
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/agreement"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/holiday"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/policydoc"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPeriodDays caps a period's length; the preview works through it day by day.
+const maxPeriodDays = 62
+
+var (
+	// ErrInvalidPayroll is returned for periods and unpaid leave that fail validation.
+	ErrInvalidPayroll = errors.New("invalid payroll data")
+	// ErrPeriodClosed is returned when changing a closed period, or unpaid leave within one.
+	ErrPeriodClosed = errors.New("the payroll period is closed")
+	// ErrPeriodOverlap is returned for a period overlapping another.
+	ErrPeriodOverlap = errors.New("the period overlaps another payroll period")
+	// ErrLeaveOverlap is returned for unpaid leave overlapping the employee's other unpaid leave.
+	ErrLeaveOverlap = errors.New("the employee already has unpaid leave in this period")
+)
+
+// Service manages payroll periods, the unpaid leave they deduct and the per-employee preview HR reviews
+// before closing a period. orgID scopes every call to one organization (nil = platform users, outside any
+// organization).
+type Service interface {
+	// Periods lists the organization's periods, latest first; an empty status lists all of them.
+	Periods(orgID *uint, status PeriodStatus) ([]Period, error)
+	GetPeriod(orgID *uint, id uint) (*Period, error)
+	CreatePeriod(actor audit.Actor, orgID *uint, req PeriodRequest) (*Period, error)
+	// UpdatePeriod replaces an open period's fields.
+	UpdatePeriod(actor audit.Actor, orgID *uint, id, expectedVersion uint, req PeriodRequest) (*Period, error)
+	// Preview calculates what an open period pays each employee, or returns what a closed one stored.
+	Preview(orgID *uint, id uint) (*Preview, error)
+	// Close stores the period's preview as its final figures, with the retroactive differences it pays for
+	// earlier periods. Unpaid leave within it can't change afterwards.
+	Close(actor audit.Actor, orgID *uint, id, expectedVersion uint) (*Preview, error)
+	// Adjustments lists the retroactive differences closed periods paid, latest first.
+	Adjustments(orgID *uint, filter AdjustmentFilter) ([]Adjustment, error)
+
+	UnpaidLeave(orgID *uint, filter UnpaidLeaveFilter) ([]UnpaidLeave, error)
+	RecordUnpaidLeave(actor audit.Actor, orgID *uint, req UnpaidLeaveRequest) (*UnpaidLeave, error)
+	DeleteUnpaidLeave(actor audit.Actor, orgID *uint, id uint) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db         *gorm.DB
+	employees  employee.Service
+	holidays   holiday.Service
+	salaries   compensation.Service
+	agreements agreement.Service
+	policies   policydoc.Service
+	auditor    audit.Service
+}
+
+// NewService creates a new instance of Service. holidays tells which days employees are expected at work,
+// salaries provides their salary history and components, agreements the overtime rates overriding the
+// period's, and policies the payroll policy periods take their terms from.
+func NewService(db *gorm.DB, employees employee.Service, holidays holiday.Service, salaries compensation.Service,
+	agreements agreement.Service, policies policydoc.Service, auditor audit.Service) Service {
+	return &service{
+		db: db, employees: employees, holidays: holidays, salaries: salaries, agreements: agreements, policies: policies,
+		auditor: auditor,
+	}
+}
+
+func (s *service) Periods(orgID *uint, status PeriodStatus) ([]Period, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	periods := []Period{}
+	if err := query.Order("start_on DESC").Find(&periods).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payroll periods: %w", err)
+	}
+	return periods, nil
+}
+
+func (s *service) GetPeriod(orgID *uint, id uint) (*Period, error) {
+	var period Period
+	if err := utils.OrgScope(s.db, orgID).First(&period, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &period, nil
+}
+
+func (s *service) CreatePeriod(actor audit.Actor, orgID *uint, req PeriodRequest) (*Period, error) {
+	period := Period{OrganizationID: orgID, Status: PeriodOpen}
+	if err := applyPeriod(&period, req); err != nil {
+		return nil, err
+	}
+	if err := s.applyPolicy(orgID, &period); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOverlap(tx, orgID, 0, period); err != nil {
+			return err
+		}
+		if err := tx.Create(&period).Error; err != nil {
+			return fmt.Errorf("failed to create payroll period: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "payroll_period.create", EntityType: "payroll_period", EntityID: fmt.Sprintf("%d", period.ID), After: period,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// UpdatePeriod replaces the period's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdatePeriod(actor audit.Actor, orgID *uint, id, expectedVersion uint, req PeriodRequest) (*Period, error) {
+	var updated Period
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPeriod(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status == PeriodClosed {
+			return ErrPeriodClosed
+		}
+		period := *before
+		if err := applyPeriod(&period, req); err != nil {
+			return err
+		}
+		if err := s.applyPolicy(orgID, &period); err != nil {
+			return err
+		}
+		if err := checkOverlap(tx, orgID, id, period); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Period{}, id, expectedVersion, map[string]interface{}{
+			"name": period.Name, "start_on": period.StartOn, "end_on": period.EndOn,
+			"day_minutes": period.DayMinutes, "overtime_percent": period.OvertimePercent,
+			"policy_revision_id": period.PolicyRevisionID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload payroll period %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "payroll_period.update", EntityType: "payroll_period", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Preview(orgID *uint, id uint) (*Preview, error) {
+	period, err := s.GetPeriod(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	var lines []Line
+	var adjustments []Adjustment
+	if period.Status == PeriodClosed {
+		if err := s.db.Where("period_id = ?", id).Order("employee_id").Find(&lines).Error; err != nil {
+			return nil, fmt.Errorf("failed to load payroll lines: %w", err)
+		}
+		if err := utils.OrgScope(s.db, orgID).Where("period_id = ?", id).Order("source_period_id, employee_id").
+			Find(&adjustments).Error; err != nil {
+			return nil, fmt.Errorf("failed to load payroll adjustments: %w", err)
+		}
+	} else {
+		if lines, err = s.calculate(s.db, orgID, *period, nil); err != nil {
+			return nil, err
+		}
+		if adjustments, err = s.retro(s.db, orgID, *period, lines); err != nil {
+			return nil, err
+		}
+	}
+	return s.preview(orgID, *period, lines, adjustments)
+}
+
+// Close calculates the preview within the transaction that closes the period, with the period locked, so
+// the stored figures are the ones of the moment it closed.
+func (s *service) Close(actor audit.Actor, orgID *uint, id, expectedVersion uint) (*Preview, error) {
+	var closed Period
+	var lines []Line
+	var adjustments []Adjustment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		period, err := lockPeriod(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if period.Version != expectedVersion {
+			return utils.ErrVersionConflict
+		}
+		if period.Status == PeriodClosed {
+			return ErrPeriodClosed
+		}
+		if lines, err = s.calculate(tx, orgID, *period, nil); err != nil {
+			return err
+		}
+		if adjustments, err = s.retro(tx, orgID, *period, lines); err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			if err := tx.CreateInBatches(&lines, 500).Error; err != nil {
+				return fmt.Errorf("failed to store payroll lines: %w", err)
+			}
+		}
+		if len(adjustments) > 0 {
+			if err := tx.CreateInBatches(&adjustments, 500).Error; err != nil {
+				return fmt.Errorf("failed to store payroll adjustments: %w", err)
+			}
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Period{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": PeriodClosed, "closed_at": now, "closed_by": actor.UserID, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to close payroll period %d: %w", id, err)
+		}
+		if err := tx.First(&closed, id).Error; err != nil {
+			return fmt.Errorf("failed to reload payroll period %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "payroll_period.close", EntityType: "payroll_period", EntityID: fmt.Sprintf("%d", id),
+			Before: period, After: map[string]interface{}{"period": closed, "totals": totals(lines), "adjustments": len(adjustments)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(orgID, closed, lines, adjustments)
+}
+
+func (s *service) Adjustments(orgID *uint, filter AdjustmentFilter) ([]Adjustment, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.SourcePeriodID != nil {
+		query = query.Where("source_period_id = ?", *filter.SourcePeriodID)
+	}
+	adjustments := []Adjustment{}
+	if err := query.Order("created_at DESC, id DESC").Find(&adjustments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payroll adjustments: %w", err)
+	}
+	if err := s.describe(orgID, adjustments); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+func (s *service) UnpaidLeave(orgID *uint, filter UnpaidLeaveFilter) ([]UnpaidLeave, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.From != nil {
+		query = query.Where("end_on >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_on <= ?", *filter.To)
+	}
+	leave := []UnpaidLeave{}
+	if err := query.Order("start_on DESC, id DESC").Find(&leave).Error; err != nil {
+		return nil, fmt.Errorf("failed to list unpaid leave: %w", err)
+	}
+	return leave, nil
+}
+
+func (s *service) RecordUnpaidLeave(actor audit.Actor, orgID *uint, req UnpaidLeaveRequest) (*UnpaidLeave, error) {
+	startOn, err := time.Parse("2006-01-02", req.StartOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidPayroll)
+	}
+	endOn, err := time.Parse("2006-01-02", req.EndOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidPayroll)
+	}
+	if endOn.Before(startOn) {
+		return nil, fmt.Errorf("%w: the leave ends before it starts", ErrInvalidPayroll)
+	}
+	leave := UnpaidLeave{
+		OrganizationID: orgID, EmployeeID: req.EmployeeID, StartOn: startOn, EndOn: endOn,
+		Reason: strings.TrimSpace(req.Reason), RecordedBy: actor.UserID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmployee(tx, orgID, leave.EmployeeID); err != nil {
+			return err
+		}
+		if err := lock.Tx(tx, fmt.Sprintf("payroll_unpaid_leave:%d", leave.EmployeeID)); err != nil {
+			return err
+		}
+		var count int64
+		if err := utils.OrgScope(tx.Model(&UnpaidLeave{}), orgID).
+			Where("employee_id = ? AND start_on <= ? AND end_on >= ?", leave.EmployeeID, endOn, startOn).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check unpaid leave: %w", err)
+		}
+		if count > 0 {
+			return ErrLeaveOverlap
+		}
+		if err := checkOpen(tx, orgID, startOn, endOn); err != nil {
+			return err
+		}
+		if err := tx.Create(&leave).Error; err != nil {
+			return fmt.Errorf("failed to record unpaid leave: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "payroll_unpaid_leave.create", EntityType: "payroll_unpaid_leave", EntityID: fmt.Sprintf("%d", leave.ID), After: leave,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+func (s *service) DeleteUnpaidLeave(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before UnpaidLeave
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := checkOpen(tx, orgID, before.StartOn, before.EndOn); err != nil {
+			return err
+		}
+		if err := tx.Delete(&UnpaidLeave{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete unpaid leave %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "payroll_unpaid_leave.delete", EntityType: "payroll_unpaid_leave", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+// preview names the lines and adjustments and adds the lines up.
+func (s *service) preview(orgID *uint, period Period, lines []Line, adjustments []Adjustment) (*Preview, error) {
+	ids := make([]uint, len(lines))
+	for i, l := range lines {
+		ids[i] = l.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsagePayslip, ids)
+	if err != nil {
+		return nil, err
+	}
+	preview := &Preview{Period: period, Calculated: period.Status == PeriodOpen, Lines: lines, Totals: totals(lines), Adjustments: adjustments}
+	if preview.Lines == nil {
+		preview.Lines = []Line{}
+	}
+	if preview.Adjustments == nil {
+		preview.Adjustments = []Adjustment{}
+	}
+	for i := range preview.Lines {
+		line := &preview.Lines[i]
+		line.DisplayName = names[line.EmployeeID].Text
+		if line.MissingSalaryDays > 0 || line.CurrencyChanged || line.ForeignComponents || line.RetroForeign {
+			preview.Warnings++
+		}
+	}
+	if err := s.describe(orgID, preview.Adjustments); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// describe fills in the employee and source period names of adjustments.
+func (s *service) describe(orgID *uint, adjustments []Adjustment) error {
+	if len(adjustments) == 0 {
+		return nil
+	}
+	employeeIDs := make([]uint, len(adjustments))
+	periodIDs := make([]uint, len(adjustments))
+	for i, a := range adjustments {
+		employeeIDs[i], periodIDs[i] = a.EmployeeID, a.SourcePeriodID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsagePayslip, employeeIDs)
+	if err != nil {
+		return err
+	}
+	var periods []Period
+	if err := utils.OrgScope(s.db.Select("id, name"), orgID).Where("id IN ?", periodIDs).Find(&periods).Error; err != nil {
+		return fmt.Errorf("failed to load payroll periods: %w", err)
+	}
+	periodNames := make(map[uint]string, len(periods))
+	for _, p := range periods {
+		periodNames[p.ID] = p.Name
+	}
+	for i := range adjustments {
+		adjustments[i].DisplayName = names[adjustments[i].EmployeeID].Text
+		adjustments[i].SourcePeriodName = periodNames[adjustments[i].SourcePeriodID]
+	}
+	return nil
+}
+
+// totals adds lines up per currency, in currency order. Lines without a salary on file pay nothing and
+// are left out.
+func totals(lines []Line) []Total {
+	byCurrency := make(map[string]*Total)
+	for _, l := range lines {
+		if l.Currency == "" {
+			continue
+		}
+		t := byCurrency[l.Currency]
+		if t == nil {
+			t = &Total{Currency: l.Currency}
+			byCurrency[l.Currency] = t
+		}
+		t.Employees++
+		t.BasePay += l.BasePay
+		t.Allowances += l.Allowances
+		t.UnpaidLeaveDeduction += l.UnpaidLeaveDeduction
+		t.OvertimePay += l.OvertimePay
+		t.GrossPay += l.GrossPay
+		t.Deductions += l.Deductions
+		t.RetroPay += l.RetroPay
+		t.RetroDeductions += l.RetroDeductions
+		t.NetPay += l.NetPay
+	}
+	totals := make([]Total, 0, len(byCurrency))
+	for _, t := range byCurrency {
+		totals = append(totals, *t)
+	}
+	slices.SortFunc(totals, func(a, b Total) int { return cmp.Compare(a.Currency, b.Currency) })
+	return totals
+}
+
+// lockPeriod loads a period for update, so its status can't change until the transaction ends.
+func lockPeriod(tx *gorm.DB, orgID *uint, id uint) (*Period, error) {
+	var period Period
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&period, id).Error; err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// checkOverlap rejects a period overlapping another of the organization's. The lock serializes concurrent
+// changes to the organization's periods.
+func checkOverlap(tx *gorm.DB, orgID *uint, id uint, period Period) error {
+	key := "payroll_period"
+	if orgID != nil {
+		key = fmt.Sprintf("payroll_period:%d", *orgID)
+	}
+	if err := lock.Tx(tx, key); err != nil {
+		return err
+	}
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Period{}), orgID).Where("start_on <= ? AND end_on >= ? AND id <> ?", period.EndOn, period.StartOn, id).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check payroll periods: %w", err)
+	}
+	if count > 0 {
+		return ErrPeriodOverlap
+	}
+	return nil
+}
+
+// checkOpen rejects changes to unpaid leave from from to to when a closed period covers any of those days.
+func checkOpen(tx *gorm.DB, orgID *uint, from, to time.Time) error {
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Period{}), orgID).Where("status = ? AND start_on <= ? AND end_on >= ?", PeriodClosed, to, from).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check payroll periods: %w", err)
+	}
+	if count > 0 {
+		return ErrPeriodClosed
+	}
+	return nil
+}
+
+// checkEmployee returns gorm.ErrRecordNotFound unless the employee is one of the organization's.
+func checkEmployee(db *gorm.DB, orgID *uint, employeeID uint) error {
+	var count int64
+	if err := utils.OrgScope(db.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check employee: %w", err)
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func applyPeriod(period *Period, req PeriodRequest) error {
+	startOn, err := time.Parse("2006-01-02", req.StartOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidPayroll)
+	}
+	endOn, err := time.Parse("2006-01-02", req.EndOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidPayroll)
+	}
+	if endOn.Before(startOn) {
+		return fmt.Errorf("%w: the period ends before it starts", ErrInvalidPayroll)
+	}
+	if endOn.Sub(startOn) >= maxPeriodDays*24*time.Hour {
+		return fmt.Errorf("%w: periods span at most %d days", ErrInvalidPayroll, maxPeriodDays)
+	}
+	period.Name = strings.TrimSpace(req.Name)
+	if period.Name == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidPayroll)
+	}
+	period.StartOn, period.EndOn = startOn, endOn
+	period.DayMinutes, period.OvertimePercent = req.DayMinutes, req.OvertimePercent
+	return nil
+}
+
+// applyPolicy fills in the working day and overtime rate a request left out from the payroll policy in
+// effect on the period's first day, or with 480 minutes and 150% without one. The period keeps the revision
+// it used, so later revisions don't change what it was created under.
+func (s *service) applyPolicy(orgID *uint, period *Period) error {
+	period.PolicyRevisionID = nil
+	if period.DayMinutes == 0 || period.OvertimePercent == 0 {
+		policy, err := s.policies.EffectiveTerms(orgID, policydoc.KindPayroll, period.StartOn)
+		if err != nil {
+			return err
+		}
+		if policy != nil && policy.DayMinutes != nil && policy.OvertimePercent != nil {
+			if period.DayMinutes == 0 {
+				period.DayMinutes = *policy.DayMinutes
+			}
+			if period.OvertimePercent == 0 {
+				period.OvertimePercent = *policy.OvertimePercent
+			}
+			period.PolicyRevisionID = &policy.ID
+		}
+	}
+	if period.DayMinutes == 0 {
+		period.DayMinutes = 8 * 60
+	}
+	if period.OvertimePercent == 0 {
+		period.OvertimePercent = 150
+	}
+	return nil
+}
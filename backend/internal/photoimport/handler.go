@@ -0,0 +1,120 @@
+// prometheus/backend/internal/photoimport/handler.go
+package photoimport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for photo imports.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Import uploads an archive of employee photos and queues it for processing.
+// @Summary Import employee photos from a zip archive
+// @Description Each photo in the archive (PNG, JPEG or GIF, up to 25 MB) is named by the ID of its employee,
+// @Description e.g. 12.jpg, in any folder. Photos are turned upright, cropped to a square around the face (or
+// @Description slightly above the middle when none is found), resized to at most 512 pixels and set as the
+// @Description avatar of the employee's user. Files are processed in the background; poll the import for its
+// @Description report, or the operation in job_id for progress.
+// @Tags Photo import
+// @Accept multipart/form-data
+// @Produce json
+// @Param archive formData file true "Zip archive of photos, at most 200 MB"
+// @Success 202 {object} Batch
+// @Failure 400 {object} utils.ErrorResponse "Missing or invalid archive"
+// @Failure 413 {object} utils.ErrorResponse "Archive too large"
+// @Router /hr/photo-imports [post]
+func (h *Handler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxArchiveSize+64<<10)
+	header, err := c.FormFile("archive")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendPhotoImportError(c, ErrArchiveTooLarge)
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Missing multipart file field \"archive\"")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Could not read the uploaded file")
+		return
+	}
+	defer file.Close()
+
+	batch, err := h.service.Import(audit.ActorFromContext(c), utils.OrganizationFromContext(c), header.Filename, file, header.Size)
+	if err != nil {
+		sendPhotoImportError(c, err)
+		return
+	}
+	c.Header("Location", fmt.Sprintf("/api/v1/hr/photo-imports/%d", batch.ID))
+	utils.SendSuccessResponse(c, http.StatusAccepted, "Photo import queued", batch)
+}
+
+// List returns past photo imports.
+// @Summary List photo imports
+// @Tags Photo import
+// @Produce json
+// @Param page query int false "Page number (1-based)"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /hr/photo-imports [get]
+func (h *Handler) List(c *gin.Context) {
+	page := utils.ParsePagination(c)
+	batches, total, err := h.service.List(utils.OrganizationFromContext(c), page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Photo imports fetched successfully", page.Response(batches, total))
+}
+
+// Get returns a photo import with the result of each file.
+// @Summary Get a photo import's report
+// @Description Results appear as files are processed; the counts are set once the import completes.
+// @Tags Photo import
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} Batch
+// @Failure 404 {object} utils.ErrorResponse "Import not found"
+// @Router /hr/photo-imports/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	batch, err := h.service.Get(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendPhotoImportError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Photo import fetched successfully", batch)
+}
+
+// sendPhotoImportError maps service errors to HTTP status codes.
+func sendPhotoImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrArchiveTooLarge):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrInvalidArchive):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,71 @@
+// prometheus/backend/internal/photoimport/model.go
+package photoimport
+
+import (
+	"time"
+)
+
+// Status is where a photo import is.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Uploaded, waiting for a worker
+	StatusRunning   Status = "running"   // Photos are being processed
+	StatusCompleted Status = "completed" // Every file has a result, failed or not
+	StatusFailed    Status = "failed"    // The archive couldn't be processed at all, see Error
+)
+
+// FileStatus is the outcome of one file of an archive.
+type FileStatus string
+
+const (
+	FileImported FileStatus = "imported"
+	FileSkipped  FileStatus = "skipped" // Not a photo of an employee, e.g. a folder's metadata or a file not named by an ID
+	FileFailed   FileStatus = "failed"
+)
+
+// Crop is how a photo was cropped to a square.
+type Crop string
+
+const (
+	CropFace   Crop = "face"   // Centered on the face found in the photo
+	CropCenter Crop = "center" // No face found; centered horizontally, slightly above the middle
+)
+
+// Batch is one photo import: a zip archive of employee photos named by employee ID (e.g. 12.jpg), each
+// cropped, resized and set as the avatar of the employee's user.
+type Batch struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"6"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	FileName       string     `gorm:"type:varchar(255)" json:"file_name,omitempty" example:"badge-photos-2026.zip"`
+	ArchiveKey     string     `gorm:"type:varchar(255)" json:"-"` // Storage key of the archive, removed once processed
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"completed"`
+	JobID          string     `gorm:"type:varchar(36)" json:"job_id,omitempty" example:"2b1f0c4e-8d3a-4c55-9a57-0f5a8e1d2c33"` // Operation processing the archive
+	Files          int        `gorm:"not null" json:"files" example:"184"`
+	Imported       int        `gorm:"not null" json:"imported" example:"179"`
+	Skipped        int        `gorm:"not null" json:"skipped" example:"2"`
+	Failed         int        `gorm:"not null" json:"failed" example:"3"`
+	Error          string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+	ImportedBy     *uint      `json:"imported_by,omitempty" example:"4"` // User ID
+	ImporterName   string     `gorm:"type:varchar(100)" json:"-"`        // Username, for the audit trail of the avatars set
+	Results        []Result   `gorm:"foreignKey:BatchID" json:"results,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName names batches after what they import.
+func (Batch) TableName() string { return "photo_import_batches" }
+
+// Result is the outcome of one file of a batch, for its report.
+type Result struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	BatchID    uint       `gorm:"not null;index" json:"-"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name" example:"photos/12.jpg"` // Path in the archive
+	EmployeeID *uint      `json:"employee_id,omitempty" example:"12"`
+	Status     FileStatus `gorm:"type:varchar(20);not null" json:"status" example:"imported"`
+	Crop       Crop       `gorm:"type:varchar(10)" json:"crop,omitempty" example:"face"`
+	Error      string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+}
+
+// TableName keeps results with their batches.
+func (Result) TableName() string { return "photo_import_results" }
@@ -0,0 +1,57 @@
+// prometheus/backend/internal/photoimport/module.go
+package photoimport
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the photo import module.
+const ModuleName = "photo-import"
+
+// photoImportModule sets employee avatars in bulk from archives of photos.
+type photoImportModule struct {
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the photo import module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &photoImportModule{service: svc, handler: NewHandler(svc)}
+}
+
+func (m *photoImportModule) Name() string { return ModuleName }
+
+func (m *photoImportModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *photoImportModule) Models() []any {
+	return []any{&Batch{}, &Result{}}
+}
+
+// RegisterJobs implements jobs.Contributor. Archives are processed when uploaded, never on a schedule.
+func (m *photoImportModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobProcess, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		var payload Payload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("invalid photo import payload: %w", err)
+		}
+		batch, err := m.service.Process(ctx, payload.BatchID, reporter)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"batch_id": batch.ID, "status": batch.Status, "imported": batch.Imported, "skipped": batch.Skipped, "failed": batch.Failed,
+		}, nil
+	})
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *photoImportModule) RegisterRoutes(api *routing.Group) {
+	api.POST("/hr/photo-imports", routing.Policy(), m.handler.Import)
+	api.GET("/hr/photo-imports", routing.Policy(), m.handler.List)
+	api.GET("/hr/photo-imports/:id", routing.Policy(), m.handler.Get)
+}
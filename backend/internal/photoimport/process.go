@@ -0,0 +1,236 @@
+// prometheus/backend/internal/photoimport/process.go
+package photoimport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Registers the PNG decoder
+)
+
+const (
+	// photoSize is the side of the square avatars imported photos become. Smaller photos aren't enlarged.
+	photoSize = 512
+	// photoQuality is the JPEG quality avatars are encoded with.
+	photoQuality = 88
+	// maxPixels rejects images whose decoded size would exhaust memory, such as crafted decompression bombs.
+	maxPixels = 50_000_000
+	// detectSize is the side the photo is scaled down to before looking for a face.
+	detectSize = 160
+	// minSkin is the share of skin-toned pixels below which no face is assumed.
+	minSkin = 0.02
+)
+
+var (
+	// ErrUnsupportedImage is returned for files that aren't PNG, JPEG or GIF images.
+	ErrUnsupportedImage = errors.New("not a PNG, JPEG or GIF image")
+	// ErrImageTooLarge is returned for images above maxPixels.
+	ErrImageTooLarge = fmt.Errorf("images must not exceed %d megapixels", maxPixels/1_000_000)
+)
+
+// processPhoto turns a photo into an avatar: upright as the camera recorded it, cropped to a square
+// around the face, at most photoSize pixels wide, as a JPEG on a white background. It reports how it
+// cropped the photo.
+func processPhoto(data []byte) ([]byte, Crop, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode the image: %w", err)
+	}
+	img := toRGBA(src)
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	crop := CropCenter
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	// Without a face, portraits usually have the head above the middle.
+	cx, cy := bounds.Dx()/2, bounds.Dy()*2/5
+	if x, y, ok := findFace(img); ok {
+		cx, cy, crop = x, y, CropFace
+	}
+	left := clamp(cx-side/2, 0, bounds.Dx()-side)
+	top := clamp(cy-side/2, 0, bounds.Dy()-side)
+	square := img.SubImage(image.Rect(left, top, left+side, top+side)).(*image.RGBA)
+
+	out := square
+	if side > photoSize {
+		out = scaleDown(square, photoSize)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: photoQuality}); err != nil {
+		return nil, "", fmt.Errorf("failed to encode the avatar: %w", err)
+	}
+	return buf.Bytes(), crop, nil
+}
+
+// toRGBA copies an image onto a white background, dropping transparency, with its origin at 0,0.
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// findFace estimates the center of the face as the centroid of skin-toned pixels, on a scaled-down copy of
+// the photo. Skin is told apart by its chroma, which varies little across skin tones; this is a heuristic
+// that works for the plain backgrounds of badge and ID photos, not a face detector.
+func findFace(img *image.RGBA) (int, int, bool) {
+	b := img.Bounds()
+	small := img
+	if max(b.Dx(), b.Dy()) > detectSize {
+		small = scaleDown(img, detectSize)
+	}
+	sb := small.Bounds()
+	var sumX, sumY, count int
+	for y := sb.Min.Y; y < sb.Max.Y; y++ {
+		for x := sb.Min.X; x < sb.Max.X; x++ {
+			c := small.RGBAAt(x, y)
+			_, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			if cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173 {
+				sumX += x - sb.Min.X
+				sumY += y - sb.Min.Y
+				count++
+			}
+		}
+	}
+	if count == 0 || float64(count) < minSkin*float64(sb.Dx()*sb.Dy()) {
+		return 0, 0, false
+	}
+	return sumX * b.Dx() / (count * sb.Dx()), sumY * b.Dy() / (count * sb.Dy()), true
+}
+
+// scaleDown resizes img so that its longer side is size pixels, averaging the source pixels each
+// destination pixel covers.
+func scaleDown(img *image.RGBA, size int) *image.RGBA {
+	b := img.Bounds()
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = max(1, b.Dy()*size/b.Dx())
+	} else if b.Dy() > b.Dx() {
+		w = max(1, b.Dx()*size/b.Dy())
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+max((y+1)*b.Dy()/h, y*b.Dy()/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+max((x+1)*b.Dx()/w, x*b.Dx()/w+1)
+			var r, g, bl, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := img.RGBAAt(sx, sy)
+					r, g, bl, n = r+int(c.R), g+int(c.G), bl+int(c.B), n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 255})
+		}
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the image shows upright.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w // Rotated by a quarter turn
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirror
+				dx, dy = w-1-x, y
+			case 3: // Turn upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // Flip vertically
+				dx, dy = x, h-1-y
+			case 5: // Transpose
+				dx, dy = y, x
+			case 6: // Quarter turn clockwise
+				dx, dy = h-1-y, x
+			case 7: // Transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // Quarter turn counterclockwise
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, 1 (upright) when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	// Walk the segments before the image data looking for the EXIF one (APP1).
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag (0x0112) of the first IFD of EXIF data.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for e := 0; e < entries; e++ {
+		at := ifd + 2 + e*12
+		if at+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[at:at+2]) == 0x0112 {
+			return int(order.Uint16(tiff[at+8 : at+10]))
+		}
+	}
+	return 1
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
@@ -0,0 +1,370 @@
+// prometheus/backend/internal/photoimport/service.go
+package photoimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobProcess is the job type processing the photos of an uploaded archive.
+const JobProcess = "photos.import"
+
+const (
+	// MaxArchiveSize is the largest archive accepted, in bytes.
+	MaxArchiveSize = 200 << 20
+	// maxFiles is the most files an archive may hold.
+	maxFiles = 5000
+	// maxPhotoSize is the largest photo processed, uncompressed, in bytes.
+	maxPhotoSize = 25 << 20
+	// photoWorkers is how many photos of an archive are processed at once.
+	photoWorkers = 4
+)
+
+var (
+	// ErrArchiveTooLarge is returned for archives above MaxArchiveSize.
+	ErrArchiveTooLarge = fmt.Errorf("the archive must not exceed %d MB", MaxArchiveSize>>20)
+	// ErrInvalidArchive is returned for uploads that aren't zip archives, or hold too many files.
+	ErrInvalidArchive = errors.New("invalid photo archive")
+)
+
+// Payload is the payload of a JobProcess job.
+type Payload struct {
+	BatchID uint `json:"batch_id"`
+}
+
+// Service imports employee photos in bulk: HR uploads a zip archive of photos named by employee ID, and a
+// background job crops each around the face, resizes it and sets it as the avatar of the employee's user,
+// recording a result per file. orgID scopes every call to one organization's employees and imports
+// (nil = default organization).
+type Service interface {
+	// Import stores an archive and queues it for processing.
+	Import(actor audit.Actor, orgID *uint, fileName string, r io.ReaderAt, size int64) (*Batch, error)
+	// List returns imports without their results, latest first.
+	List(orgID *uint, page utils.Pagination) ([]Batch, int64, error)
+	// Get returns an import with the result of each file.
+	Get(orgID *uint, id uint) (*Batch, error)
+	// Process processes the photos of a pending import.
+	Process(ctx context.Context, batchID uint, reporter jobs.Reporter) (*Batch, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	files     storage.Storage
+	employees employee.Service
+	avatars   auth.AvatarService
+	queue     *jobs.Queue
+}
+
+// NewService creates a new instance of Service. Photos become avatars through avatars, which audits
+// each change.
+func NewService(db *gorm.DB, files storage.Storage, employees employee.Service, avatars auth.AvatarService, queue *jobs.Queue) Service {
+	return &service{db: db, files: files, employees: employees, avatars: avatars, queue: queue}
+}
+
+// Import checks the archive can be read before storing it, so a wrong upload fails at once rather than
+// in the job.
+func (s *service) Import(actor audit.Actor, orgID *uint, fileName string, r io.ReaderAt, size int64) (*Batch, error) {
+	if size > MaxArchiveSize {
+		return nil, ErrArchiveTooLarge
+	}
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a zip archive", ErrInvalidArchive)
+	}
+	if len(archive.File) > maxFiles {
+		return nil, fmt.Errorf("%w: an archive may hold at most %d files", ErrInvalidArchive, maxFiles)
+	}
+	key := fmt.Sprintf("photo-imports/%s.zip", uuid.NewString())
+	if err := s.files.Put(context.Background(), key, io.NewSectionReader(r, 0, size), size, "application/zip"); err != nil {
+		return nil, fmt.Errorf("failed to store the archive: %w", err)
+	}
+	batch := Batch{
+		OrganizationID: orgID,
+		FileName:       truncate(path.Base(fileName), 255),
+		ArchiveKey:     key,
+		Status:         StatusPending,
+		ImportedBy:     actor.UserID,
+		ImporterName:   truncate(actor.Username, 100),
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return fmt.Errorf("failed to create photo import: %w", err)
+		}
+		job, err := s.queue.EnqueueTx(tx, JobProcess, Payload{BatchID: batch.ID}, jobs.EnqueueOptions{CreatedBy: actor.UserID, MaxAttempts: 3})
+		if err != nil {
+			return err
+		}
+		batch.JobID = job.ID
+		return tx.Model(&batch).Update("job_id", job.ID).Error
+	})
+	if err != nil {
+		s.remove(key)
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *service) List(orgID *uint, page utils.Pagination) ([]Batch, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Batch{}), orgID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count photo imports: %w", err)
+	}
+	var batches []Batch
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&batches).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list photo imports: %w", err)
+	}
+	return batches, total, nil
+}
+
+func (s *service) Get(orgID *uint, id uint) (*Batch, error) {
+	var batch Batch
+	if err := utils.OrgScope(s.db, orgID).Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&batch, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &batch, nil
+}
+
+// Process works through the archive's files with photoWorkers at once, saving each file's result as it
+// goes. Results already saved are kept when the job is retried, and their files skipped. The archive is
+// deleted once every file has a result.
+func (s *service) Process(ctx context.Context, batchID uint, reporter jobs.Reporter) (*Batch, error) {
+	var batch Batch
+	if err := s.db.WithContext(ctx).First(&batch, batchID).Error; err != nil {
+		return nil, err
+	}
+	if batch.Status == StatusCompleted || batch.Status == StatusFailed {
+		return &batch, nil
+	}
+	if err := s.db.Model(&batch).Update("status", StatusRunning).Error; err != nil {
+		return nil, fmt.Errorf("failed to start photo import %d: %w", batchID, err)
+	}
+
+	archive, closeArchive, err := s.openArchive(ctx, batch.ArchiveKey)
+	if err != nil {
+		s.fail(&batch, err)
+		return &batch, nil
+	}
+	defer closeArchive()
+
+	var done []Result
+	if err := s.db.Select("name", "employee_id", "status").Where("batch_id = ?", batchID).Find(&done).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the results of photo import %d: %w", batchID, err)
+	}
+	processed := make(map[string]bool, len(done))
+	claimed := map[uint]string{} // Employee ID -> file, to catch two photos of the same employee
+	for _, result := range done {
+		processed[result.Name] = true
+		if result.Status == FileImported && result.EmployeeID != nil {
+			claimed[*result.EmployeeID] = result.Name
+		}
+	}
+	actor := audit.Actor{UserID: batch.ImportedBy, Username: batch.ImporterName, OrganizationID: batch.OrganizationID}
+
+	var (
+		mu       sync.Mutex
+		finished = len(done)
+		wg       sync.WaitGroup
+	)
+	files := make(chan *zip.File)
+	for i := 0; i < photoWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				result := s.processFile(ctx, actor, batch.OrganizationID, f, func(employeeID uint) string {
+					mu.Lock()
+					defer mu.Unlock()
+					if other, ok := claimed[employeeID]; ok {
+						return other
+					}
+					claimed[employeeID] = f.Name
+					return ""
+				})
+				if ctx.Err() != nil {
+					continue // Processed again on retry
+				}
+				result.BatchID = batchID
+				if err := s.db.Create(&result).Error; err != nil {
+					log.Printf("Failed to save the result of %s in photo import %d: %v", f.Name, batchID, err)
+				}
+				mu.Lock()
+				finished++
+				reporter.SetProgress(finished*100/len(archive.File), fmt.Sprintf("%d of %d files processed", finished, len(archive.File)))
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, f := range archive.File {
+		if processed[truncate(f.Name, 255)] {
+			continue
+		}
+		select {
+		case files <- f:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(files)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err // Retried with the results saved so far
+	}
+
+	if err := s.db.Model(&batch).Updates(map[string]interface{}{
+		"status":      StatusCompleted,
+		"files":       len(archive.File),
+		"imported":    gorm.Expr("(SELECT COUNT(*) FROM photo_import_results WHERE batch_id = ? AND status = ?)", batchID, FileImported),
+		"skipped":     gorm.Expr("(SELECT COUNT(*) FROM photo_import_results WHERE batch_id = ? AND status = ?)", batchID, FileSkipped),
+		"failed":      gorm.Expr("(SELECT COUNT(*) FROM photo_import_results WHERE batch_id = ? AND status = ?)", batchID, FileFailed),
+		"finished_at": clock.Now().UTC(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to complete photo import %d: %w", batchID, err)
+	}
+	s.remove(batch.ArchiveKey)
+	if err := s.db.First(&batch, batchID).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload photo import %d: %w", batchID, err)
+	}
+	return &batch, nil
+}
+
+// processFile imports one file of an archive. claim records that the file is the employee's photo, or
+// returns the file that already is.
+func (s *service) processFile(ctx context.Context, actor audit.Actor, orgID *uint, f *zip.File, claim func(employeeID uint) string) Result {
+	result := Result{Name: truncate(f.Name, 255)}
+	skip := func(reason string) Result {
+		result.Status, result.Error = FileSkipped, reason
+		return result
+	}
+	fail := func(err error) Result {
+		result.Status, result.Error = FileFailed, truncate(err.Error(), 500)
+		return result
+	}
+	base := path.Base(f.Name)
+	if f.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+		return skip("not a photo")
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(base, path.Ext(base)), 10, 32)
+	if err != nil || id == 0 {
+		return skip("the file name isn't an employee ID")
+	}
+	employeeID := uint(id)
+	result.EmployeeID = &employeeID
+	subject, err := s.employees.Get(orgID, employeeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fail(errors.New("no such employee"))
+	} else if err != nil {
+		return fail(err)
+	}
+	if other := claim(employeeID); other != "" {
+		return fail(fmt.Errorf("the archive has another photo of this employee: %s", other))
+	}
+	if f.UncompressedSize64 > maxPhotoSize {
+		return fail(fmt.Errorf("the photo must not exceed %d MB", maxPhotoSize>>20))
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fail(fmt.Errorf("failed to read the photo: %w", err))
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, maxPhotoSize+1))
+	rc.Close()
+	if err != nil {
+		return fail(fmt.Errorf("failed to read the photo: %w", err))
+	}
+	if len(data) > maxPhotoSize {
+		return fail(fmt.Errorf("the photo must not exceed %d MB", maxPhotoSize>>20))
+	}
+	avatar, crop, err := processPhoto(data)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := s.avatars.Upload(ctx, actor, subject.UserID, bytes.NewReader(avatar), int64(len(avatar))); err != nil {
+		return fail(err)
+	}
+	result.Status, result.Crop = FileImported, crop
+	return result
+}
+
+// openArchive copies the stored archive to a temporary file, as reading a zip needs random access.
+func (s *service) openArchive(ctx context.Context, key string) (*zip.Reader, func(), error) {
+	body, _, err := s.files.Open(ctx, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open the archive: %w", err)
+	}
+	defer body.Close()
+	tmp, err := os.CreateTemp("", "photo-import-*.zip")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to buffer the archive: %w", err)
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, io.LimitReader(body, MaxArchiveSize+1))
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to buffer the archive: %w", err)
+	}
+	archive, err := zip.NewReader(tmp, size)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: not a zip archive", ErrInvalidArchive)
+	}
+	return archive, cleanup, nil
+}
+
+// fail marks an import whose archive can't be processed, and drops the archive.
+func (s *service) fail(batch *Batch, cause error) {
+	now := clock.Now().UTC()
+	batch.Status, batch.Error, batch.FinishedAt = StatusFailed, truncate(cause.Error(), 500), &now
+	if err := s.db.Model(batch).Updates(map[string]interface{}{
+		"status": batch.Status, "error": batch.Error, "finished_at": now,
+	}).Error; err != nil {
+		log.Printf("Failed to mark photo import %d failed: %v", batch.ID, err)
+	}
+	s.remove(batch.ArchiveKey)
+}
+
+// remove deletes an archive that is no longer needed. Failures only leave an orphaned file behind.
+func (s *service) remove(key string) {
+	if err := s.files.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Failed to delete photo archive %s: %v", key, err)
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,102 @@
+// prometheus/backend/internal/plan/handler.go
+package plan
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for tenant plans.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new plan Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListPlans returns the available plan definitions.
+// @Summary List plans
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} map[string]Plan
+// @Router /admin/plans [get]
+func (h *Handler) ListPlans(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Plans fetched successfully", Plans)
+}
+
+// GetUsage returns an organization's plan and usage.
+// @Summary Get tenant plan usage
+// @Tags Tenants
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} Usage
+// @Failure 404 {object} utils.ErrorResponse "Organization not found"
+// @Router /admin/tenants/{id}/plan [get]
+func (h *Handler) GetUsage(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	usage, err := h.service.Usage(orgID)
+	if err != nil {
+		SendError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Plan usage fetched successfully", usage)
+}
+
+// ChangePlan switches an organization to another plan.
+// @Summary Change tenant plan
+// @Description Downgrades are refused while current usage exceeds the target plan's limits.
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param plan body ChangePlanRequest true "New plan"
+// @Success 200 {object} Usage
+// @Failure 409 {object} utils.ErrorResponse "Usage exceeds target plan"
+// @Router /admin/tenants/{id}/plan [put]
+func (h *Handler) ChangePlan(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	usage, err := h.service.ChangePlan(audit.ActorFromContext(c), orgID, req.Plan)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrUnknownPlan) {
+			SendError(c, err)
+		} else {
+			utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+		}
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Plan changed", usage)
+}
+
+// SendError writes a plan-related error. LimitErrors become 402 Payment Required with their code,
+// so any handler enforcing a limit can delegate to it.
+func SendError(c *gin.Context, err error) {
+	var limitErr *LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		utils.SendErrorResponseWithCode(c, http.StatusPaymentRequired, limitErr.Code, limitErr.Message)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, ErrUnknownPlan):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
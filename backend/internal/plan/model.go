@@ -0,0 +1,90 @@
+// prometheus/backend/internal/plan/model.go
+package plan
+
+// Module names that can be enabled per plan. Routes belonging to a module are gated with
+// routing.Access.InModule (or a Group.InModule group); a deployment can switch a module off entirely
+// with MODULES_DISABLED.
+const (
+	ModuleCore       = "core" // Users, roles, organization settings; always enabled
+	ModuleLeave      = "leave"
+	ModuleAttendance = "attendance"
+	ModulePayroll    = "payroll"
+	ModuleDocuments  = "documents"
+	ModuleReports    = "reports"
+	ModuleATS        = "ats" // Applicant tracking
+	ModuleAssets     = "assets"
+	ModuleExpenses   = "expenses"
+)
+
+// Plan names.
+const (
+	PlanFree       = "free"
+	PlanStandard   = "standard"
+	PlanEnterprise = "enterprise"
+)
+
+// Unlimited marks a limit that is not enforced.
+const Unlimited = -1
+
+// Plan describes what an organization may use.
+type Plan struct {
+	Name              string   `json:"name" example:"standard"`
+	MaxEmployees      int      `json:"max_employees" example:"250"`               // Unlimited = -1
+	StorageQuotaBytes int64    `json:"storage_quota_bytes" example:"10737418240"` // Unlimited = -1
+	Modules           []string `json:"modules" example:"core,leave,attendance"`
+}
+
+// Plans holds the plan definitions. Kept in code rather than the database so a plan change ships
+// with the code that depends on it.
+var Plans = map[string]Plan{
+	PlanFree: {
+		Name:              PlanFree,
+		MaxEmployees:      10,
+		StorageQuotaBytes: 1 << 30, // 1 GiB
+		Modules:           []string{ModuleCore, ModuleLeave},
+	},
+	PlanStandard: {
+		Name:              PlanStandard,
+		MaxEmployees:      250,
+		StorageQuotaBytes: 10 << 30, // 10 GiB
+		Modules:           []string{ModuleCore, ModuleLeave, ModuleAttendance, ModuleDocuments, ModuleExpenses},
+	},
+	PlanEnterprise: {
+		Name:              PlanEnterprise,
+		MaxEmployees:      Unlimited,
+		StorageQuotaBytes: Unlimited,
+		Modules:           []string{ModuleCore, ModuleLeave, ModuleAttendance, ModulePayroll, ModuleDocuments, ModuleReports, ModuleATS, ModuleAssets, ModuleExpenses},
+	},
+}
+
+// ChangePlanRequest changes an organization's plan.
+type ChangePlanRequest struct {
+	Plan string `json:"plan" binding:"required,oneof=free standard enterprise" example:"enterprise"`
+}
+
+// Usage reports an organization's plan and current consumption.
+type Usage struct {
+	OrganizationID   uint  `json:"organization_id"`
+	Plan             Plan  `json:"plan"`
+	Employees        int64 `json:"employees" example:"42"`
+	StorageUsedBytes int64 `json:"storage_used_bytes" example:"1048576"`
+}
+
+// LimitError is returned when an action would exceed the organization's plan.
+// Handlers surface it as 402 with Code so the frontend can show an upgrade prompt.
+type LimitError struct {
+	Code    string // CodeUpgradeRequired or CodePaymentRequired
+	Limit   string // e.g. "max_employees", "module:payroll", "storage_quota"
+	Plan    string
+	Message string
+}
+
+// Error codes carried by LimitError.
+const (
+	CodeUpgradeRequired = "upgrade_required"
+	CodePaymentRequired = "payment_required" // Plan includes it, but the subscription is not paid up
+)
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,216 @@
+// prometheus/backend/internal/plan/service.go
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/organization"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownPlan is returned when an organization references a plan that is not defined.
+var ErrUnknownPlan = errors.New("unknown plan")
+
+// ModuleChecker decides whether an organization may use a module. Service implements it from the
+// plan alone; billing layers subscription state on top.
+type ModuleChecker interface {
+	CheckModule(orgID uint, module string) error
+}
+
+// Service defines the interface for plan lookups and limit enforcement.
+// All checks are no-ops for users without an organization (the default single-tenant deployment).
+type Service interface {
+	ForOrganization(orgID uint) (Plan, error)
+	Usage(orgID uint) (*Usage, error)
+	ChangePlan(actor audit.Actor, orgID uint, name string) (*Usage, error)
+	CheckModule(orgID uint, module string) error
+	CheckEmployeeLimit(tx *gorm.DB, orgID uint, adding int) error
+	ReserveStorage(tx *gorm.DB, orgID uint, bytes int64) error
+	ReleaseStorage(tx *gorm.DB, orgID uint, bytes int64) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new plan Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+// ForOrganization returns the plan of an organization.
+func (s *service) ForOrganization(orgID uint) (Plan, error) {
+	var org organization.Organization
+	if err := s.db.Select("id", "plan").First(&org, orgID).Error; err != nil {
+		return Plan{}, err
+	}
+	return lookup(org.Plan)
+}
+
+// Usage returns the plan and current consumption of an organization.
+func (s *service) Usage(orgID uint) (*Usage, error) {
+	var org organization.Organization
+	if err := s.db.First(&org, orgID).Error; err != nil {
+		return nil, err
+	}
+	p, err := lookup(org.Plan)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := countEmployees(s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &Usage{OrganizationID: orgID, Plan: p, Employees: employees, StorageUsedBytes: org.StorageUsedBytes}, nil
+}
+
+// ChangePlan switches an organization to another plan. Downgrades are refused while current usage
+// exceeds the target plan's limits, so a tenant is never left over quota.
+func (s *service) ChangePlan(actor audit.Actor, orgID uint, name string) (*Usage, error) {
+	target, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.Usage(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if target.MaxEmployees != Unlimited && usage.Employees > int64(target.MaxEmployees) {
+		return nil, fmt.Errorf("organization has %d employees, plan %s allows %d", usage.Employees, name, target.MaxEmployees)
+	}
+	if target.StorageQuotaBytes != Unlimited && usage.StorageUsedBytes > target.StorageQuotaBytes {
+		return nil, fmt.Errorf("organization uses %d bytes of storage, plan %s allows %d", usage.StorageUsedBytes, name, target.StorageQuotaBytes)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&organization.Organization{}).Where("id = ?", orgID).Update("plan", name).Error; err != nil {
+			return fmt.Errorf("failed to change plan: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.change_plan", EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID),
+			Before: map[string]string{"plan": usage.Plan.Name}, After: map[string]string{"plan": name},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage.Plan = target
+	return usage, nil
+}
+
+// CheckModule returns a LimitError when the organization's plan does not include module.
+func (s *service) CheckModule(orgID uint, module string) error {
+	if orgID == 0 || module == ModuleCore {
+		return nil
+	}
+	p, err := s.ForOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(p.Modules, module) {
+		return &LimitError{
+			Code: CodeUpgradeRequired, Limit: "module:" + module, Plan: p.Name,
+			Message: fmt.Sprintf("The %s module is not included in the %s plan", module, p.Name),
+		}
+	}
+	return nil
+}
+
+// CheckEmployeeLimit returns a LimitError when adding `adding` users would exceed the plan.
+// Pass the transaction that creates the users so the count sees the same snapshot.
+func (s *service) CheckEmployeeLimit(tx *gorm.DB, orgID uint, adding int) error {
+	if orgID == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = s.db
+	}
+	p, err := s.ForOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	if p.MaxEmployees == Unlimited {
+		return nil
+	}
+	current, err := countEmployees(tx, orgID)
+	if err != nil {
+		return err
+	}
+	if current+int64(adding) > int64(p.MaxEmployees) {
+		return &LimitError{
+			Code: CodeUpgradeRequired, Limit: "max_employees", Plan: p.Name,
+			Message: fmt.Sprintf("The %s plan allows %d employees (currently %d)", p.Name, p.MaxEmployees, current),
+		}
+	}
+	return nil
+}
+
+// ReserveStorage atomically adds bytes to the organization's storage usage, failing with a LimitError
+// when the quota would be exceeded. Call it before persisting an upload.
+func (s *service) ReserveStorage(tx *gorm.DB, orgID uint, bytes int64) error {
+	if orgID == 0 || bytes <= 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = s.db
+	}
+	p, err := s.ForOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	query := tx.Model(&organization.Organization{}).Where("id = ?", orgID)
+	if p.StorageQuotaBytes != Unlimited {
+		// The quota check is part of the UPDATE so concurrent uploads cannot both squeeze in.
+		query = query.Where("storage_used_bytes + ? <= ?", bytes, p.StorageQuotaBytes)
+	}
+	result := query.Update("storage_used_bytes", gorm.Expr("storage_used_bytes + ?", bytes))
+	if result.Error != nil {
+		return fmt.Errorf("failed to reserve storage: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &LimitError{
+			Code: CodeUpgradeRequired, Limit: "storage_quota", Plan: p.Name,
+			Message: fmt.Sprintf("The %s plan storage quota of %d bytes would be exceeded", p.Name, p.StorageQuotaBytes),
+		}
+	}
+	return nil
+}
+
+// ReleaseStorage gives back storage after a file was deleted.
+func (s *service) ReleaseStorage(tx *gorm.DB, orgID uint, bytes int64) error {
+	if orgID == 0 || bytes <= 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = s.db
+	}
+	return tx.Model(&organization.Organization{}).Where("id = ?", orgID).
+		Update("storage_used_bytes", gorm.Expr("GREATEST(storage_used_bytes - ?, 0)", bytes)).Error
+}
+
+// lookup resolves a plan by name; an empty name means the free plan.
+func lookup(name string) (Plan, error) {
+	if name == "" {
+		name = PlanFree
+	}
+	p, ok := Plans[name]
+	if !ok {
+		return Plan{}, fmt.Errorf("%w: %s", ErrUnknownPlan, name)
+	}
+	return p, nil
+}
+
+// countEmployees counts the active accounts of an organization.
+func countEmployees(db *gorm.DB, orgID uint) (int64, error) {
+	var count int64
+	err := db.Table("users").Where("organization_id = ? AND deleted_at IS NULL AND is_active = ?", orgID, true).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count employees: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,356 @@
+// prometheus/backend/internal/policydoc/handler.go
+package policydoc
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for policy documents.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListDocuments returns the organization's policy documents with the revision in effect today.
+// @Summary List policy documents
+// @Tags Policies
+// @Produce json
+// @Param kind query string false "Kind" Enums(general, leave, payroll)
+// @Success 200 {array} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid kind"
+// @Router /hr/policies [get]
+func (h *Handler) ListDocuments(c *gin.Context) {
+	kind := Kind(c.Query("kind"))
+	switch kind {
+	case "", KindGeneral, KindLeave, KindPayroll:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid kind parameter")
+		return
+	}
+	documents, err := h.service.Documents(utils.OrganizationFromContext(c), kind)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Policy documents fetched successfully", documents)
+}
+
+// GetDocument returns a policy document with all its revisions, including those not yet in effect.
+// @Summary Get a policy document
+// @Tags Policies
+// @Produce json
+// @Param id path int true "Document ID"
+// @Success 200 {object} Document
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Router /hr/policies/{id} [get]
+func (h *Handler) GetDocument(c *gin.Context) {
+	h.sendDocument(c, true)
+}
+
+// CreateDocument creates a policy document. Its text follows as a first revision.
+// @Summary Create a policy document
+// @Description An organization has one leave and one payroll policy at most; their revisions set the default
+// @Description leave entitlement and the terms of new payroll periods from the day they take effect.
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Param document body DocumentRequest true "Document"
+// @Success 201 {object} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid document"
+// @Failure 409 {object} utils.ErrorResponse "A policy of this kind exists"
+// @Router /hr/policies [post]
+func (h *Handler) CreateDocument(c *gin.Context) {
+	var req DocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	document, err := h.service.CreateDocument(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Policy document created successfully", document)
+}
+
+// UpdateDocument renames a policy document.
+// @Summary Rename a policy document
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param document body DocumentRequest true "Document"
+// @Success 200 {object} Document
+// @Failure 400 {object} utils.ErrorResponse "Invalid document"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/policies/{id} [put]
+func (h *Handler) UpdateDocument(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetDocument(orgID, id, false)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	document, err := h.service.UpdateDocument(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Policy document updated successfully", document)
+}
+
+// AddRevision adds a revision to a policy document.
+// @Summary Revise a policy document
+// @Description Revisions take effect today or later, after the document's latest. Until then they may be
+// @Description edited or withdrawn; from then on they stay as the record of what applied.
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param revision body RevisionRequest true "Revision"
+// @Success 201 {object} Revision
+// @Failure 400 {object} utils.ErrorResponse "Invalid revision"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Router /hr/policies/{id}/revisions [post]
+func (h *Handler) AddRevision(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	revision, err := h.service.AddRevision(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Revision added successfully", revision)
+}
+
+// Effective returns the revision of a policy document that was in effect on a day.
+// @Summary Get the revision in effect on a day
+// @Tags Policies
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param on query string false "Day (YYYY-MM-DD); today if omitted"
+// @Success 200 {object} Revision
+// @Failure 400 {object} utils.ErrorResponse "Invalid day"
+// @Failure 404 {object} utils.ErrorResponse "Document not found, or not yet in effect"
+// @Router /hr/policies/{id}/effective [get]
+func (h *Handler) Effective(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	on := today()
+	if raw := c.Query("on"); raw != "" {
+		day, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid on parameter: expected YYYY-MM-DD")
+			return
+		}
+		on = day
+	}
+	revision, err := h.service.Effective(utils.OrganizationFromContext(c), id, on)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Revision fetched successfully", revision)
+}
+
+// UpdateRevision replaces a revision that hasn't taken effect yet.
+// @Summary Update a pending revision
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Param id path int true "Revision ID"
+// @Param revision body RevisionRequest true "Revision"
+// @Success 200 {object} Revision
+// @Failure 400 {object} utils.ErrorResponse "Invalid revision"
+// @Failure 404 {object} utils.ErrorResponse "Revision not found"
+// @Failure 409 {object} utils.ErrorResponse "Revision in effect"
+// @Router /hr/policy-revisions/{id} [put]
+func (h *Handler) UpdateRevision(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	revision, err := h.service.UpdateRevision(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Revision updated successfully", revision)
+}
+
+// DeleteRevision withdraws a document's latest revision before it takes effect.
+// @Summary Withdraw a pending revision
+// @Tags Policies
+// @Param id path int true "Revision ID"
+// @Success 204
+// @Failure 400 {object} utils.ErrorResponse "Not the latest revision"
+// @Failure 404 {object} utils.ErrorResponse "Revision not found"
+// @Failure 409 {object} utils.ErrorResponse "Revision in effect"
+// @Router /hr/policy-revisions/{id} [delete]
+func (h *Handler) DeleteRevision(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteRevision(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Acknowledgements lists who acknowledged a revision.
+// @Summary List acknowledgements of a revision
+// @Tags Policies
+// @Produce json
+// @Param id path int true "Revision ID"
+// @Success 200 {array} Acknowledgement
+// @Failure 404 {object} utils.ErrorResponse "Revision not found"
+// @Router /hr/policy-revisions/{id}/acknowledgements [get]
+func (h *Handler) Acknowledgements(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	acks, err := h.service.Acknowledgements(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Acknowledgements fetched successfully", acks)
+}
+
+// MyPolicies lists the policies in effect, with whether the caller acknowledged them.
+// @Summary List policies in effect
+// @Tags Policies
+// @Produce json
+// @Success 200 {array} Status
+// @Router /me/policies [get]
+func (h *Handler) MyPolicies(c *gin.Context) {
+	statuses, err := h.service.MyPolicies(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Policies fetched successfully", statuses)
+}
+
+// MyDocument returns a policy document with the revisions that have taken effect.
+// @Summary Get a policy
+// @Tags Policies
+// @Produce json
+// @Param id path int true "Document ID"
+// @Success 200 {object} Document
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Router /me/policies/{id} [get]
+func (h *Handler) MyDocument(c *gin.Context) {
+	h.sendDocument(c, false)
+}
+
+// Acknowledge records that the caller read and accepted a revision of a policy.
+// @Summary Acknowledge a policy
+// @Description Acknowledges the revision in effect today unless revision_id names an earlier one, such as
+// @Description the revision an acknowledgement campaign was sent for. Acknowledging again changes nothing.
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Param id path int true "Document ID"
+// @Param acknowledgement body AcknowledgeRequest false "Revision and campaign"
+// @Success 200 {object} Acknowledgement
+// @Failure 400 {object} utils.ErrorResponse "Unknown campaign"
+// @Failure 404 {object} utils.ErrorResponse "Document or revision not found"
+// @Router /me/policies/{id}/acknowledge [post]
+func (h *Handler) Acknowledge(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AcknowledgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	userID := c.GetUint("userID")
+	ack, err := h.service.Acknowledge(audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID, id, req)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Policy acknowledged successfully", ack)
+}
+
+func (h *Handler) sendDocument(c *gin.Context, pending bool) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	document, err := h.service.GetDocument(utils.OrganizationFromContext(c), id, pending)
+	if err != nil {
+		sendPolicyError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, document.UpdatedAt, document.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Policy document fetched successfully", document)
+}
+
+// sendPolicyError maps service errors to HTTP status codes.
+func sendPolicyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidPolicy):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrKindTaken), errors.Is(err, ErrInEffect):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	case errors.Is(err, lock.ErrLocked):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+// prometheus/backend/internal/policydoc/model.go
+package policydoc
+
+import (
+	"time"
+)
+
+// Kind is what a policy document governs. Leave and payroll policies carry the terms other modules apply;
+// an organization has one document of each of those kinds at most.
+type Kind string
+
+const (
+	KindGeneral Kind = "general" // Text only, e.g. a code of conduct
+	KindLeave   Kind = "leave"   // Sets the default annual leave entitlement
+	KindPayroll Kind = "payroll" // Sets the working day and overtime rate of new payroll periods
+)
+
+// Document is a policy of the organization. Its text and terms live in revisions, each taking effect on
+// a day, so what applied to an event is the revision in effect on the event's date rather than the latest.
+type Document struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Kind           Kind       `gorm:"type:varchar(20);not null;index" json:"kind" example:"leave"`
+	Title          string     `gorm:"type:varchar(200);not null" json:"title" example:"Annual leave policy"`
+	Current        *Revision  `gorm:"-" json:"current,omitempty"`                    // In effect today; nil before the first takes effect
+	Revisions      []Revision `gorm:"-" json:"revisions,omitempty"`                  // Newest first, on single documents
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName says which kind of document.
+func (Document) TableName() string { return "policy_documents" }
+
+// Revision is one version of a policy document, in effect from EffectiveOn until the next revision's.
+// Revisions are numbered in order of taking effect. Once in effect a revision is part of the record and
+// can't change; later ones may be edited or withdrawn until their day comes.
+type Revision struct {
+	ID              uint      `gorm:"primaryKey" json:"id" example:"11"`
+	OrganizationID  *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	DocumentID      uint      `gorm:"not null;uniqueIndex:idx_policy_revision_day" json:"document_id" example:"4"`
+	Number          int       `gorm:"not null" json:"number" example:"3"`
+	EffectiveOn     time.Time `gorm:"type:date;not null;uniqueIndex:idx_policy_revision_day" json:"effective_on" example:"2027-01-01T00:00:00Z"`
+	Body            string    `gorm:"type:text;not null" json:"body"`
+	ChangeNote      string    `gorm:"type:varchar(1000)" json:"change_note,omitempty" example:"Two more days of leave"`
+	LeaveDays       *int      `json:"leave_days,omitempty" example:"22"`        // Leave policies: annual entitlement in working days
+	DayMinutes      *int      `json:"day_minutes,omitempty" example:"480"`      // Payroll policies: a working day
+	OvertimePercent *int      `json:"overtime_percent,omitempty" example:"150"` // Payroll policies: of the regular rate
+	CreatedBy       *uint     `json:"created_by,omitempty" example:"7"`         // User ID
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName keeps revisions next to their documents.
+func (Revision) TableName() string { return "policy_revisions" }
+
+// Acknowledgement records that a user read and accepted a revision, possibly when asked by a campaign.
+type Acknowledgement struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"310"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	RevisionID     uint      `gorm:"not null;uniqueIndex:idx_policy_acknowledgement" json:"revision_id" example:"11"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_policy_acknowledgement;index" json:"user_id" example:"12"`
+	Username       string    `gorm:"-" json:"username,omitempty" example:"jdoe"`
+	CampaignID     *uint     `gorm:"index" json:"campaign_id,omitempty" example:"3"`
+	CreatedAt      time.Time `json:"acknowledged_at"`
+}
+
+// TableName keeps acknowledgements next to the documents.
+func (Acknowledgement) TableName() string { return "policy_acknowledgements" }
+
+// Status is a user's view of a document: the revision in effect, and whether they acknowledged it.
+type Status struct {
+	Document       Document   `json:"document"`
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// DocumentRequest creates a document or renames it. The kind can't change once created.
+type DocumentRequest struct {
+	Kind  Kind   `json:"kind" binding:"omitempty,oneof=general leave payroll" example:"leave"` // Defaults to general
+	Title string `json:"title" binding:"required,max=200" example:"Annual leave policy"`
+}
+
+// RevisionRequest adds a revision or replaces one not yet in effect. Leave policies need leave_days,
+// payroll policies day_minutes and overtime_percent; other terms are rejected.
+type RevisionRequest struct {
+	EffectiveOn     string `json:"effective_on" binding:"required,datetime=2006-01-02" example:"2027-01-01"`
+	Body            string `json:"body" binding:"required,max=100000"`
+	ChangeNote      string `json:"change_note,omitempty" binding:"max=1000" example:"Two more days of leave"`
+	LeaveDays       *int   `json:"leave_days,omitempty" binding:"omitempty,min=0,max=366" example:"22"`
+	DayMinutes      *int   `json:"day_minutes,omitempty" binding:"omitempty,min=60,max=1440" example:"480"`
+	OvertimePercent *int   `json:"overtime_percent,omitempty" binding:"omitempty,min=100,max=500" example:"150"`
+}
+
+// AcknowledgeRequest acknowledges a revision of a document, the one in effect today if omitted.
+type AcknowledgeRequest struct {
+	RevisionID *uint `json:"revision_id,omitempty" example:"11"`
+	CampaignID *uint `json:"campaign_id,omitempty" example:"3"` // The campaign that asked for it, if any
+}
@@ -0,0 +1,55 @@
+// prometheus/backend/internal/policydoc/module.go
+package policydoc
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/internal/search"
+)
+
+// ModuleName is the name of the policy documents module.
+const ModuleName = "policy-documents"
+
+// policyModule owns versioned policy documents and their acknowledgements.
+type policyModule struct {
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the policy documents module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &policyModule{service: svc, handler: NewHandler(svc)}
+}
+
+func (m *policyModule) Name() string { return ModuleName }
+
+func (m *policyModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *policyModule) Models() []any {
+	return []any{&Document{}, &Revision{}, &Acknowledgement{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Policies are core: everyone reads and acknowledges those
+// in effect, HR writes and revises them.
+func (m *policyModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/policies", routing.Authenticated(), m.handler.MyPolicies)
+	api.GET("/me/policies/:id", routing.Authenticated(), m.handler.MyDocument)
+	api.POST("/me/policies/:id/acknowledge", routing.Authenticated(), m.handler.Acknowledge)
+
+	api.GET("/hr/policies", routing.Policy(), m.handler.ListDocuments)
+	api.POST("/hr/policies", routing.Policy(), m.handler.CreateDocument)
+	api.GET("/hr/policies/:id", routing.Policy(), m.handler.GetDocument)
+	api.PUT("/hr/policies/:id", routing.Policy(), m.handler.UpdateDocument)
+	api.POST("/hr/policies/:id/revisions", routing.Policy(), m.handler.AddRevision)
+	api.GET("/hr/policies/:id/effective", routing.Policy(), m.handler.Effective)
+	api.PUT("/hr/policy-revisions/:id", routing.Policy(), m.handler.UpdateRevision)
+	api.DELETE("/hr/policy-revisions/:id", routing.Policy(), m.handler.DeleteRevision)
+	api.GET("/hr/policy-revisions/:id/acknowledgements", routing.Policy(), m.handler.Acknowledgements)
+}
+
+// SearchProviders implements search.Contributor. Policies are the organization's knowledge base: there is
+// no separate one, so knowledge-base searches are answered with them.
+func (m *policyModule) SearchProviders() []search.Provider {
+	return []search.Provider{search.NewProvider(SearchType, "", m.service.Search)}
+}
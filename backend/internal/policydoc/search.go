@@ -0,0 +1,42 @@
+// prometheus/backend/internal/policydoc/search.go
+package policydoc
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/search"
+)
+
+// SearchType is the type of policy search hits.
+const SearchType = "policy"
+
+// Search finds the policies in effect by title and the text of their current revision, for the
+// organization-wide search. Everyone reads those, so hits aren't trimmed further; revisions not yet in
+// effect are only found by HR, through the policy listing.
+func (s *service) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	match, args := q.Condition("policy_documents.title", "policy_revisions.body")
+	var rows []struct {
+		ID    uint
+		Title string
+		Body  string
+	}
+	// Both tables have an organization_id, so the scope is spelled out rather than taken from utils.OrgScope.
+	query := s.db.WithContext(ctx).Table("policy_documents").Where("policy_documents.organization_id IS NULL")
+	if q.OrgID != nil {
+		query = s.db.WithContext(ctx).Table("policy_documents").Where("policy_documents.organization_id = ?", *q.OrgID)
+	}
+	if err := query.Joins("JOIN policy_revisions ON policy_revisions.id = (SELECT r.id FROM policy_revisions r "+
+		"WHERE r.document_id = policy_documents.id AND r.effective_on <= ? ORDER BY r.effective_on DESC LIMIT 1)", today()).
+		Where(match, args...).Select("policy_documents.id, policy_documents.title, policy_revisions.body").
+		Order("LOWER(policy_documents.title), policy_documents.id").Limit(q.Limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to search policies: %w", err)
+	}
+	hits := make([]search.Hit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, search.Hit{
+			Type: SearchType, ID: row.ID, Title: row.Title, Snippet: search.Snippet(row.Body, q.Term, 160),
+			Link: fmt.Sprintf("/api/v1/me/policies/%d", row.ID),
+		})
+	}
+	return hits, nil
+}
@@ -0,0 +1,511 @@
+// prometheus/backend/internal/policydoc/service.go
+package policydoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidPolicy is returned for documents and revisions that fail validation.
+	ErrInvalidPolicy = errors.New("invalid policy document")
+	// ErrKindTaken is returned when creating a second leave or payroll policy.
+	ErrKindTaken = errors.New("the organization already has a policy of this kind")
+	// ErrInEffect is returned when changing or withdrawing a revision that has taken effect.
+	ErrInEffect = errors.New("the revision is in effect and can no longer change")
+)
+
+// Service manages versioned policy documents and their acknowledgements. Other modules ask it for the
+// revision in effect on the date of what they process. orgID scopes every call to one organization
+// (nil = platform users, outside any organization).
+type Service interface {
+	// Documents lists the organization's documents with the revision in effect today; an empty kind lists all.
+	Documents(orgID *uint, kind Kind) ([]Document, error)
+	// GetDocument returns a document with its revisions, leaving out those not yet in effect unless pending.
+	GetDocument(orgID *uint, id uint, pending bool) (*Document, error)
+	CreateDocument(actor audit.Actor, orgID *uint, req DocumentRequest) (*Document, error)
+	// UpdateDocument renames a document.
+	UpdateDocument(actor audit.Actor, orgID *uint, id, expectedVersion uint, req DocumentRequest) (*Document, error)
+
+	GetRevision(orgID *uint, id uint) (*Revision, error)
+	// AddRevision adds a revision taking effect today or later, after the document's latest.
+	AddRevision(actor audit.Actor, orgID *uint, documentID uint, req RevisionRequest) (*Revision, error)
+	// UpdateRevision replaces a revision that hasn't taken effect yet.
+	UpdateRevision(actor audit.Actor, orgID *uint, id uint, req RevisionRequest) (*Revision, error)
+	// DeleteRevision withdraws a document's latest revision before it takes effect.
+	DeleteRevision(actor audit.Actor, orgID *uint, id uint) error
+	// Effective returns the revision of a document in effect on day, or gorm.ErrRecordNotFound before its first.
+	Effective(orgID *uint, documentID uint, day time.Time) (*Revision, error)
+	// EffectiveTerms returns the revision of the organization's leave or payroll policy in effect on day, or
+	// nil if there is none, in which case callers apply their own defaults.
+	EffectiveTerms(orgID *uint, kind Kind, day time.Time) (*Revision, error)
+
+	// MyPolicies lists the documents in effect with whether the user acknowledged their current revision.
+	MyPolicies(orgID *uint, userID uint) ([]Status, error)
+	// Acknowledge records that the user accepted a revision of a document that has taken effect.
+	Acknowledge(actor audit.Actor, orgID *uint, userID, documentID uint, req AcknowledgeRequest) (*Acknowledgement, error)
+	// Acknowledgements lists who acknowledged a revision, earliest first.
+	Acknowledgements(orgID *uint, revisionID uint) ([]Acknowledgement, error)
+	// Acknowledged counts the acknowledgements of a revision among users, or all of them if users is nil.
+	Acknowledged(orgID *uint, revisionID uint, users *gorm.DB) (int64, error)
+	// Search finds policies for the organization-wide search, see search.Provider.
+	Search(ctx context.Context, q search.Query) ([]search.Hit, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db      *gorm.DB
+	auditor audit.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, auditor audit.Service) Service {
+	return &service{db: db, auditor: auditor}
+}
+
+func (s *service) Documents(orgID *uint, kind Kind) ([]Document, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	documents := []Document{}
+	if err := query.Order("LOWER(title), id").Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list policy documents: %w", err)
+	}
+	if err := s.current(orgID, documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (s *service) GetDocument(orgID *uint, id uint, pending bool) (*Document, error) {
+	var document Document
+	if err := utils.OrgScope(s.db, orgID).First(&document, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	today := today()
+	query := s.db.Where("document_id = ?", id)
+	if !pending {
+		query = query.Where("effective_on <= ?", today)
+	}
+	document.Revisions = []Revision{}
+	if err := query.Order("effective_on DESC").Find(&document.Revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load revisions: %w", err)
+	}
+	for i := range document.Revisions {
+		if !document.Revisions[i].EffectiveOn.After(today) {
+			current := document.Revisions[i]
+			document.Current = &current
+			break
+		}
+	}
+	return &document, nil
+}
+
+func (s *service) CreateDocument(actor audit.Actor, orgID *uint, req DocumentRequest) (*Document, error) {
+	document := Document{OrganizationID: orgID, Kind: req.Kind, Title: strings.TrimSpace(req.Title)}
+	if document.Kind == "" {
+		document.Kind = KindGeneral
+	}
+	if document.Title == "" {
+		return nil, fmt.Errorf("%w: a title is required", ErrInvalidPolicy)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if document.Kind != KindGeneral {
+			// Serializes creations, so two requests can't both add the organization's leave policy.
+			if err := lock.Tx(tx, fmt.Sprintf("policy-kind:%s:%s", orgKey(orgID), document.Kind)); err != nil {
+				return err
+			}
+			var count int64
+			if err := utils.OrgScope(tx.Model(&Document{}), orgID).Where("kind = ?", document.Kind).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check policy kinds: %w", err)
+			}
+			if count > 0 {
+				return ErrKindTaken
+			}
+		}
+		if err := tx.Create(&document).Error; err != nil {
+			return fmt.Errorf("failed to create policy document: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.create", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", document.ID), After: document,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// UpdateDocument renames the document if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdateDocument(actor audit.Actor, orgID *uint, id, expectedVersion uint, req DocumentRequest) (*Document, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: a title is required", ErrInvalidPolicy)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Document
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if req.Kind != "" && req.Kind != before.Kind {
+			return fmt.Errorf("%w: the kind of a document can't change", ErrInvalidPolicy)
+		}
+		if err := utils.UpdateWithVersion(tx, &Document{}, id, expectedVersion, map[string]interface{}{"title": title}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.update", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]string{"title": before.Title}, After: map[string]string{"title": title},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDocument(orgID, id, true)
+}
+
+func (s *service) GetRevision(orgID *uint, id uint) (*Revision, error) {
+	var revision Revision
+	if err := utils.OrgScope(s.db, orgID).First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (s *service) AddRevision(actor audit.Actor, orgID *uint, documentID uint, req RevisionRequest) (*Revision, error) {
+	var revision Revision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		document, err := lockDocument(tx, orgID, documentID)
+		if err != nil {
+			return err
+		}
+		revision = Revision{OrganizationID: orgID, DocumentID: documentID, CreatedBy: actor.UserID}
+		if err := applyRevision(&revision, document.Kind, req); err != nil {
+			return err
+		}
+		var latest []Revision
+		if err := tx.Where("document_id = ?", documentID).Order("effective_on DESC").Limit(1).Find(&latest).Error; err != nil {
+			return fmt.Errorf("failed to load revisions: %w", err)
+		}
+		revision.Number = 1
+		if len(latest) > 0 {
+			if !revision.EffectiveOn.After(latest[0].EffectiveOn) {
+				return fmt.Errorf("%w: revisions take effect after the latest, on %s", ErrInvalidPolicy,
+					latest[0].EffectiveOn.Format("2006-01-02"))
+			}
+			revision.Number = latest[0].Number + 1
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to add revision: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.revise", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", documentID), After: revision,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (s *service) UpdateRevision(actor audit.Actor, orgID *uint, id uint, req RevisionRequest) (*Revision, error) {
+	var updated Revision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, document, err := s.lockPending(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		revision := *before
+		if err := applyRevision(&revision, document.Kind, req); err != nil {
+			return err
+		}
+		var neighbours []Revision
+		if err := tx.Where("document_id = ? AND number IN ?", revision.DocumentID, []int{revision.Number - 1, revision.Number + 1}).
+			Find(&neighbours).Error; err != nil {
+			return fmt.Errorf("failed to load revisions: %w", err)
+		}
+		for _, n := range neighbours {
+			if (n.Number < revision.Number && !revision.EffectiveOn.After(n.EffectiveOn)) ||
+				(n.Number > revision.Number && !revision.EffectiveOn.Before(n.EffectiveOn)) {
+				return fmt.Errorf("%w: revision %d takes effect on %s", ErrInvalidPolicy, n.Number, n.EffectiveOn.Format("2006-01-02"))
+			}
+		}
+		if err := tx.Model(&Revision{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"effective_on": revision.EffectiveOn, "body": revision.Body, "change_note": revision.ChangeNote,
+			"leave_days": revision.LeaveDays, "day_minutes": revision.DayMinutes, "overtime_percent": revision.OvertimePercent,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update revision %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload revision %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.revision.update", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", revision.DocumentID),
+			Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteRevision(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, _, err := s.lockPending(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		var later int64
+		if err := tx.Model(&Revision{}).Where("document_id = ? AND number > ?", before.DocumentID, before.Number).
+			Count(&later).Error; err != nil {
+			return fmt.Errorf("failed to load revisions: %w", err)
+		}
+		if later > 0 {
+			return fmt.Errorf("%w: withdraw the later revisions first", ErrInvalidPolicy)
+		}
+		if err := tx.Delete(&Revision{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete revision %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.revision.delete", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", before.DocumentID),
+			Before: before,
+		})
+	})
+}
+
+func (s *service) Effective(orgID *uint, documentID uint, day time.Time) (*Revision, error) {
+	var revision Revision
+	if err := utils.OrgScope(s.db, orgID).Where("document_id = ? AND effective_on <= ?", documentID, day).
+		Order("effective_on DESC").First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (s *service) EffectiveTerms(orgID *uint, kind Kind, day time.Time) (*Revision, error) {
+	var revisions []Revision
+	if err := utils.OrgScope(s.db, orgID).
+		Where("document_id IN (?)", utils.OrgScope(s.db.Model(&Document{}).Select("id").Where("kind = ?", kind), orgID)).
+		Where("effective_on <= ?", day).Order("effective_on DESC").Limit(1).Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the %s policy: %w", kind, err)
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return &revisions[0], nil
+}
+
+func (s *service) MyPolicies(orgID *uint, userID uint) ([]Status, error) {
+	documents, err := s.Documents(orgID, "")
+	if err != nil {
+		return nil, err
+	}
+	revisionIDs := make([]uint, 0, len(documents))
+	for _, d := range documents {
+		if d.Current != nil {
+			revisionIDs = append(revisionIDs, d.Current.ID)
+		}
+	}
+	var acks []Acknowledgement
+	if len(revisionIDs) > 0 {
+		if err := s.db.Where("user_id = ? AND revision_id IN ?", userID, revisionIDs).Find(&acks).Error; err != nil {
+			return nil, fmt.Errorf("failed to load acknowledgements: %w", err)
+		}
+	}
+	acknowledged := make(map[uint]time.Time, len(acks))
+	for _, a := range acks {
+		acknowledged[a.RevisionID] = a.CreatedAt
+	}
+	statuses := []Status{}
+	for _, d := range documents {
+		if d.Current == nil {
+			continue
+		}
+		status := Status{Document: d}
+		if at, ok := acknowledged[d.Current.ID]; ok {
+			status.Acknowledged, status.AcknowledgedAt = true, &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (s *service) Acknowledge(actor audit.Actor, orgID *uint, userID, documentID uint, req AcknowledgeRequest) (*Acknowledgement, error) {
+	var revision *Revision
+	var err error
+	if req.RevisionID != nil {
+		revision, err = s.GetRevision(orgID, *req.RevisionID)
+		if err == nil && (revision.DocumentID != documentID || revision.EffectiveOn.After(today())) {
+			err = gorm.ErrRecordNotFound
+		}
+	} else {
+		revision, err = s.Effective(orgID, documentID, today())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.CampaignID != nil {
+		var count int64
+		if err := utils.OrgScope(s.db.Table("campaigns"), orgID).Where("id = ?", *req.CampaignID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check campaign: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("%w: unknown campaign", ErrInvalidPolicy)
+		}
+	}
+	ack := Acknowledgement{OrganizationID: orgID, RevisionID: revision.ID, UserID: userID, CampaignID: req.CampaignID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Acknowledging twice keeps the first acknowledgement.
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ack)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record acknowledgement: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("revision_id = ? AND user_id = ?", revision.ID, userID).First(&ack).Error
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "policy_document.acknowledge", EntityType: "policy_document", EntityID: fmt.Sprintf("%d", documentID),
+			After: map[string]interface{}{"revision": revision.Number, "campaign_id": req.CampaignID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func (s *service) Acknowledgements(orgID *uint, revisionID uint) ([]Acknowledgement, error) {
+	if _, err := s.GetRevision(orgID, revisionID); err != nil {
+		return nil, err
+	}
+	acks := []Acknowledgement{}
+	if err := s.db.Table("policy_acknowledgements").
+		Select("policy_acknowledgements.*, users.username").
+		Joins("LEFT JOIN users ON users.id = policy_acknowledgements.user_id").
+		Where("policy_acknowledgements.revision_id = ?", revisionID).
+		Order("policy_acknowledgements.created_at, policy_acknowledgements.id").Scan(&acks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list acknowledgements: %w", err)
+	}
+	return acks, nil
+}
+
+func (s *service) Acknowledged(orgID *uint, revisionID uint, users *gorm.DB) (int64, error) {
+	query := utils.OrgScope(s.db.Model(&Acknowledgement{}), orgID).Where("revision_id = ?", revisionID)
+	if users != nil {
+		query = query.Where("user_id IN (?)", users)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count acknowledgements: %w", err)
+	}
+	return count, nil
+}
+
+// current fills in the revision of each document in effect today.
+func (s *service) current(orgID *uint, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(documents))
+	for i, d := range documents {
+		ids[i] = d.ID
+	}
+	var revisions []Revision
+	if err := utils.OrgScope(s.db, orgID).Where("document_id IN ? AND effective_on <= ?", ids, today()).
+		Order("document_id, effective_on DESC").Find(&revisions).Error; err != nil {
+		return fmt.Errorf("failed to load revisions: %w", err)
+	}
+	latest := make(map[uint]Revision, len(documents))
+	for _, r := range revisions {
+		if _, ok := latest[r.DocumentID]; !ok {
+			latest[r.DocumentID] = r
+		}
+	}
+	for i := range documents {
+		if r, ok := latest[documents[i].ID]; ok {
+			documents[i].Current = &r
+		}
+	}
+	return nil
+}
+
+// lockPending loads a revision that hasn't taken effect yet for update, with its document.
+func (s *service) lockPending(tx *gorm.DB, orgID *uint, id uint) (*Revision, *Document, error) {
+	var revision Revision
+	if err := utils.OrgScope(tx, orgID).First(&revision, id).Error; err != nil {
+		return nil, nil, err
+	}
+	document, err := lockDocument(tx, orgID, revision.DocumentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !revision.EffectiveOn.After(today()) {
+		return nil, nil, ErrInEffect
+	}
+	return &revision, document, nil
+}
+
+// lockDocument loads a document for update, serializing changes to its revisions.
+func lockDocument(tx *gorm.DB, orgID *uint, id uint) (*Document, error) {
+	var document Document
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// applyRevision validates req against the document's kind and copies it onto revision.
+func applyRevision(revision *Revision, kind Kind, req RevisionRequest) error {
+	effectiveOn, err := time.Parse("2006-01-02", req.EffectiveOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidPolicy)
+	}
+	if effectiveOn.Before(today()) {
+		return fmt.Errorf("%w: revisions can't take effect in the past", ErrInvalidPolicy)
+	}
+	revision.EffectiveOn = effectiveOn
+	revision.Body = strings.TrimSpace(req.Body)
+	if revision.Body == "" {
+		return fmt.Errorf("%w: the text is required", ErrInvalidPolicy)
+	}
+	revision.ChangeNote = strings.TrimSpace(req.ChangeNote)
+	leave := req.LeaveDays != nil
+	payroll := req.DayMinutes != nil || req.OvertimePercent != nil
+	switch {
+	case kind == KindLeave && (!leave || payroll):
+		return fmt.Errorf("%w: leave policies set leave_days, and only that", ErrInvalidPolicy)
+	case kind == KindPayroll && (req.DayMinutes == nil || req.OvertimePercent == nil || leave):
+		return fmt.Errorf("%w: payroll policies set day_minutes and overtime_percent, and only those", ErrInvalidPolicy)
+	case kind == KindGeneral && (leave || payroll):
+		return fmt.Errorf("%w: general policies carry no terms", ErrInvalidPolicy)
+	}
+	revision.LeaveDays, revision.DayMinutes, revision.OvertimePercent = req.LeaveDays, req.DayMinutes, req.OvertimePercent
+	return nil
+}
+
+// today is the current UTC date, which effective dates are compared with.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// orgKey names an organization in lock names.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
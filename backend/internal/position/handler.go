@@ -0,0 +1,255 @@
+// prometheus/backend/internal/position/handler.go
+package position
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for positions and the headcount report.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListPositions returns the organization's positions with their filled and vacant seats.
+// @Summary List positions
+// @Tags Positions
+// @Produce json
+// @Param division_id query int false "Division ID"
+// @Param status query string false "Status" Enums(active, frozen, closed)
+// @Param vacant query bool false "Only active positions with a vacant seat"
+// @Success 200 {array} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/positions [get]
+func (h *Handler) ListPositions(c *gin.Context) {
+	filter := Filter{Status: Status(c.Query("status")), VacantOnly: c.Query("vacant") == "true"}
+	switch filter.Status {
+	case "", StatusActive, StatusFrozen, StatusClosed:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	if raw := c.Query("division_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid division_id parameter")
+			return
+		}
+		divisionID := uint(id)
+		filter.DivisionID = &divisionID
+	}
+	positions, err := h.service.Positions(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Positions fetched successfully", positions)
+}
+
+// GetPosition returns a position with its holders. The ETag and Last-Modified headers can be sent back as
+// If-Match / If-Unmodified-Since.
+// @Summary Get a position
+// @Tags Positions
+// @Produce json
+// @Param id path int true "Position ID"
+// @Success 200 {object} Summary
+// @Failure 404 {object} utils.ErrorResponse "Position not found"
+// @Router /hr/positions/{id} [get]
+func (h *Handler) GetPosition(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	position, err := h.service.GetPosition(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, position.UpdatedAt, position.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Position fetched successfully", position)
+}
+
+// CreatePosition budgets a position in a division.
+// @Summary Create a position
+// @Tags Positions
+// @Accept json
+// @Produce json
+// @Param position body Request true "Position"
+// @Success 201 {object} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid position or unknown division"
+// @Router /hr/positions [post]
+func (h *Handler) CreatePosition(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	position, err := h.service.CreatePosition(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, position.UpdatedAt, position.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Position created successfully", position)
+}
+
+// UpdatePosition replaces a position's fields, including freezing or closing it.
+// @Summary Update a position
+// @Tags Positions
+// @Accept json
+// @Produce json
+// @Param id path int true "Position ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param position body Request true "Position"
+// @Success 200 {object} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid position or unknown division"
+// @Failure 404 {object} utils.ErrorResponse "Position not found"
+// @Failure 409 {object} utils.ErrorResponse "Headcount below the seats held, or closing a held position"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/positions/{id} [put]
+func (h *Handler) UpdatePosition(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetPosition(orgID, id)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	position, err := h.service.UpdatePosition(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, position.UpdatedAt, position.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Position updated successfully", position)
+}
+
+// DeletePosition removes a position nobody holds. Close it instead to keep it for history.
+// @Summary Delete a position
+// @Tags Positions
+// @Param id path int true "Position ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Position not found"
+// @Failure 409 {object} utils.ErrorResponse "The position has holders"
+// @Router /hr/positions/{id} [delete]
+func (h *Handler) DeletePosition(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeletePosition(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Place puts an employee in a vacant seat of a position.
+// @Summary Place an employee in a position
+// @Tags Positions
+// @Accept json
+// @Produce json
+// @Param id path int true "Position ID"
+// @Param holder body HolderRequest true "Holder"
+// @Success 200 {object} Summary
+// @Failure 400 {object} utils.ErrorResponse "Invalid date or unknown employee"
+// @Failure 404 {object} utils.ErrorResponse "Position not found"
+// @Failure 409 {object} utils.ErrorResponse "No vacant seat, or the employee already holds a position"
+// @Router /hr/positions/{id}/holders [post]
+func (h *Handler) Place(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req HolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	position, err := h.service.Place(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee placed successfully", position)
+}
+
+// Vacate frees the seat an employee holds in a position.
+// @Summary Vacate a seat of a position
+// @Tags Positions
+// @Param id path int true "Position ID"
+// @Param employee_id path int true "Employee ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Position not found, or not held by the employee"
+// @Router /hr/positions/{id}/holders/{employee_id} [delete]
+func (h *Handler) Vacate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	if err := h.service.Vacate(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, employeeID); err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Headcount compares budgeted with actual headcount per division.
+// @Summary Budgeted vs actual headcount
+// @Description Budgeted headcount is the seats of active and frozen positions; actual headcount the
+// @Description employees in each division. Vacant seats of active positions are open to recruiting.
+// @Tags Positions
+// @Produce json
+// @Success 200 {object} HeadcountReport
+// @Router /hr/analytics/headcount [get]
+func (h *Handler) Headcount(c *gin.Context) {
+	report, err := h.service.Headcount(utils.OrganizationFromContext(c))
+	if err != nil {
+		sendPositionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Headcount report generated successfully", report)
+}
+
+// sendPositionError maps service errors to HTTP status codes.
+func sendPositionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidPosition):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNoVacancy), errors.Is(err, ErrAlreadyPlaced), errors.Is(err, ErrHeld):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
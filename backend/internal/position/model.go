@@ -0,0 +1,100 @@
+// prometheus/backend/internal/position/model.go
+package position
+
+import (
+	"time"
+)
+
+// Status is whether a position is budgeted.
+type Status string
+
+const (
+	StatusActive Status = "active" // Budgeted; vacant seats are recruited for
+	StatusFrozen Status = "frozen" // Budgeted but on hold: holders stay, vacant seats aren't recruited for
+	StatusClosed Status = "closed" // No longer budgeted; kept for history once its holders have left
+)
+
+// Position is a budgeted role in a division, independent of who holds it: a job title with a number of
+// seats (Headcount), each held by at most one employee. Seats without a holder are vacant. Divisions'
+// budgeted headcount is the seats of their active and frozen positions.
+type Position struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"6"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	DivisionID     uint       `gorm:"not null;index" json:"division_id" example:"2"`
+	Title          string     `gorm:"type:varchar(150);not null" json:"title" example:"Payroll Specialist"`
+	Headcount      int        `gorm:"not null" json:"headcount" example:"3"`
+	BudgetAmount   *int64     `json:"budget_amount,omitempty" example:"6500000"`                   // Annual salary budget per seat, in minor units
+	BudgetCurrency string     `gorm:"type:char(3)" json:"budget_currency,omitempty" example:"EUR"` // Set with BudgetAmount
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"active"`
+	VacantSince    *time.Time `gorm:"type:date" json:"vacant_since,omitempty" example:"2026-09-15T00:00:00Z"` // Since an active position last had a vacant seat
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"`                          // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Holder is an employee holding a seat of a position. An employee holds at most one position.
+type Holder struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"14"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	PositionID     uint      `gorm:"not null;index" json:"position_id" example:"6"`
+	EmployeeID     uint      `gorm:"not null;uniqueIndex" json:"employee_id" example:"12"`
+	DisplayName    string    `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	StartOn        time.Time `gorm:"type:date;not null" json:"start_on" example:"2026-09-15T00:00:00Z"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps holders next to positions.
+func (Holder) TableName() string { return "position_holders" }
+
+// Summary is a position with how many of its seats are held.
+type Summary struct {
+	Position
+	Division  string   `json:"division" example:"Finance"`
+	Filled    int      `json:"filled" example:"2"`
+	Vacancies int      `json:"vacancies" example:"1"` // Vacant seats of an active position
+	Holders   []Holder `json:"holders,omitempty"`     // When fetched on its own
+}
+
+// Request creates a position or replaces its fields.
+type Request struct {
+	DivisionID     uint   `json:"division_id" binding:"required" example:"2"`
+	Title          string `json:"title" binding:"required,max=150" example:"Payroll Specialist"`
+	Headcount      int    `json:"headcount" binding:"required,min=1,max=1000" example:"3"`
+	BudgetAmount   *int64 `json:"budget_amount,omitempty" binding:"omitempty,min=1" example:"6500000"`
+	BudgetCurrency string `json:"budget_currency,omitempty" binding:"omitempty,len=3" example:"EUR"`
+	Status         Status `json:"status,omitempty" binding:"omitempty,oneof=active frozen closed" example:"active"` // Defaults to active
+}
+
+// HolderRequest places an employee in a vacant seat of a position.
+type HolderRequest struct {
+	EmployeeID uint   `json:"employee_id" binding:"required" example:"12"`
+	StartOn    string `json:"start_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-09-15"` // Defaults to today
+}
+
+// Filter narrows a position listing.
+type Filter struct {
+	DivisionID *uint
+	Status     Status
+	VacantOnly bool // Active positions with a vacant seat
+}
+
+// HeadcountReport compares budgeted with actual headcount per division.
+type HeadcountReport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Rows        []HeadcountRow `json:"rows"`
+	Total       HeadcountRow   `json:"total"`
+}
+
+// HeadcountRow is one division of a headcount report.
+type HeadcountRow struct {
+	DivisionID *uint  `json:"division_id,omitempty" example:"2"` // Empty for employees outside any division, and for the total
+	Division   string `json:"division" example:"Finance"`
+	Positions  int    `json:"positions" example:"4"` // Active and frozen
+	Budgeted   int    `json:"budgeted" example:"9"`  // Seats of active and frozen positions
+	Filled     int    `json:"filled" example:"7"`    // Seats held
+	Vacant     int    `json:"vacant" example:"1"`    // Vacant seats of active positions, open to recruiting
+	Frozen     int    `json:"frozen" example:"1"`    // Vacant seats of frozen positions
+	Actual     int    `json:"actual" example:"8"`    // Employees in the division
+	Unplaced   int    `json:"unplaced" example:"1"`  // Of them, holding no position
+	Variance   int    `json:"variance" example:"-1"` // Actual less budgeted: negative when under budget
+}
@@ -0,0 +1,45 @@
+// prometheus/backend/internal/position/module.go
+package position
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the positions module.
+const ModuleName = "positions"
+
+// positionModule owns budgeted positions, their holders and the headcount report.
+type positionModule struct {
+	handler *Handler
+}
+
+// NewModule creates the positions module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &positionModule{handler: NewHandler(svc)}
+}
+
+func (m *positionModule) Name() string { return ModuleName }
+
+func (m *positionModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *positionModule) Models() []any {
+	return []any{&Position{}, &Holder{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR keeps positions; the headcount report is part of the
+// reports module.
+func (m *positionModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/hr/positions", routing.Policy(), m.handler.ListPositions)
+	api.POST("/hr/positions", routing.Policy(), m.handler.CreatePosition)
+	api.GET("/hr/positions/:id", routing.Policy(), m.handler.GetPosition)
+	api.PUT("/hr/positions/:id", routing.Policy(), m.handler.UpdatePosition)
+	api.DELETE("/hr/positions/:id", routing.Policy(), m.handler.DeletePosition)
+	api.POST("/hr/positions/:id/holders", routing.Policy(), m.handler.Place)
+	api.DELETE("/hr/positions/:id/holders/:employee_id", routing.Policy(), m.handler.Vacate)
+
+	reportsAPI := api.InModule(plan.ModuleReports)
+	reportsAPI.GET("/hr/analytics/headcount", routing.Policy(), m.handler.Headcount)
+}
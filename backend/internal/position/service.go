@@ -0,0 +1,528 @@
+// prometheus/backend/internal/position/service.go
+package position
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidPosition is returned for positions and holders that fail validation.
+	ErrInvalidPosition = errors.New("invalid position")
+	// ErrNoVacancy is returned when placing an employee in a position without a vacant seat, or one that
+	// isn't active.
+	ErrNoVacancy = errors.New("the position has no vacant seat")
+	// ErrAlreadyPlaced is returned when placing an employee who already holds a position.
+	ErrAlreadyPlaced = errors.New("the employee already holds a position")
+	// ErrHeld is returned when deleting a position that still has holders, or cutting its headcount below them.
+	ErrHeld = errors.New("the position has holders")
+)
+
+// Service manages budgeted positions, who holds them, and the headcount report comparing budgeted with
+// actual headcount. orgID scopes every call to one organization (nil = platform users, outside any
+// organization).
+type Service interface {
+	Positions(orgID *uint, filter Filter) ([]Summary, error)
+	// GetPosition returns a position with its holders.
+	GetPosition(orgID *uint, id uint) (*Summary, error)
+	CreatePosition(actor audit.Actor, orgID *uint, req Request) (*Summary, error)
+	UpdatePosition(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Summary, error)
+	// DeletePosition removes a position without holders.
+	DeletePosition(actor audit.Actor, orgID *uint, id uint) error
+
+	// Place puts an employee in a vacant seat of an active position.
+	Place(actor audit.Actor, orgID *uint, id uint, req HolderRequest) (*Summary, error)
+	// PlaceTx is Place within tx, for hires placed as part of a larger change.
+	PlaceTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, req HolderRequest) error
+	// Vacate frees the seat an employee holds in a position.
+	Vacate(actor audit.Actor, orgID *uint, id, employeeID uint) error
+	// VacanciesTx returns how many seats of a position are vacant within tx, with the position locked until
+	// tx ends; 0 unless it is active. Recruiting checks it before opening a requisition or placing a hire.
+	VacanciesTx(tx *gorm.DB, orgID *uint, id uint) (int, error)
+
+	// Headcount compares budgeted with actual headcount per division.
+	Headcount(orgID *uint) (*HeadcountReport, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Holders' names are resolved through employees; the
+// headcount report reads through reporting (see database.ConnectReportingDB).
+func NewService(db, reporting *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, reporting: reporting, employees: employees, auditor: auditor}
+}
+
+func (s *service) Positions(orgID *uint, filter Filter) ([]Summary, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var positions []Position
+	if err := query.Order("title, id").Find(&positions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list positions: %w", err)
+	}
+	summaries, err := s.summarize(s.db, orgID, positions)
+	if err != nil {
+		return nil, err
+	}
+	if filter.VacantOnly {
+		summaries = slices.DeleteFunc(summaries, func(p Summary) bool { return p.Vacancies == 0 })
+	}
+	return summaries, nil
+}
+
+func (s *service) GetPosition(orgID *uint, id uint) (*Summary, error) {
+	var position Position
+	if err := utils.OrgScope(s.db, orgID).First(&position, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	summaries, err := s.summarize(s.db, orgID, []Position{position})
+	if err != nil {
+		return nil, err
+	}
+	summary := &summaries[0]
+	summary.Holders = []Holder{}
+	if err := s.db.Select("position_holders.*").Joins("JOIN employees ON employees.id = position_holders.employee_id AND employees.deleted_at IS NULL").
+		Where("position_holders.position_id = ?", id).Order("position_holders.start_on, position_holders.id").
+		Find(&summary.Holders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load position holders: %w", err)
+	}
+	ids := make([]uint, len(summary.Holders))
+	for i, h := range summary.Holders {
+		ids[i] = h.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range summary.Holders {
+		summary.Holders[i].DisplayName = names[summary.Holders[i].EmployeeID].Text
+	}
+	return summary, nil
+}
+
+func (s *service) CreatePosition(actor audit.Actor, orgID *uint, req Request) (*Summary, error) {
+	position := Position{OrganizationID: orgID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := apply(tx, &position, req); err != nil {
+			return err
+		}
+		if position.Status == StatusActive {
+			today := today()
+			position.VacantSince = &today
+		}
+		if err := tx.Create(&position).Error; err != nil {
+			return fmt.Errorf("failed to create position: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "position.create", EntityType: "position", EntityID: fmt.Sprintf("%d", position.ID), After: position,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPosition(orgID, position.ID)
+}
+
+// UpdatePosition replaces the position's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdatePosition(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Summary, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPosition(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		position := *before
+		if err := apply(tx, &position, req); err != nil {
+			return err
+		}
+		filled, err := filledSeats(tx, []uint{id})
+		if err != nil {
+			return err
+		}
+		if position.Headcount < filled[id] {
+			return fmt.Errorf("%w: %d seats are held", ErrHeld, filled[id])
+		}
+		if position.Status == StatusClosed && filled[id] > 0 {
+			return fmt.Errorf("%w: vacate its seats before closing it", ErrHeld)
+		}
+		if err := utils.UpdateWithVersion(tx, &Position{}, id, expectedVersion, map[string]interface{}{
+			"division_id":     position.DivisionID,
+			"title":           position.Title,
+			"headcount":       position.Headcount,
+			"budget_amount":   position.BudgetAmount,
+			"budget_currency": position.BudgetCurrency,
+			"status":          position.Status,
+		}); err != nil {
+			return err
+		}
+		if err := track(tx, id); err != nil {
+			return err
+		}
+		var updated Position
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload position %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "position.update", EntityType: "position", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPosition(orgID, id)
+}
+
+func (s *service) DeletePosition(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPosition(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		filled, err := filledSeats(tx, []uint{id})
+		if err != nil {
+			return err
+		}
+		if filled[id] > 0 {
+			return ErrHeld
+		}
+		// Holders of deleted employees are all that can be left.
+		if err := tx.Where("position_id = ?", id).Delete(&Holder{}).Error; err != nil {
+			return fmt.Errorf("failed to delete position holders: %w", err)
+		}
+		if err := tx.Delete(&Position{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete position %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "position.delete", EntityType: "position", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Place(actor audit.Actor, orgID *uint, id uint, req HolderRequest) (*Summary, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.PlaceTx(tx, actor, orgID, id, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPosition(orgID, id)
+}
+
+func (s *service) PlaceTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, req HolderRequest) error {
+	startOn := today()
+	if req.StartOn != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartOn)
+		if err != nil {
+			return fmt.Errorf("%w: start_on must be a date", ErrInvalidPosition)
+		}
+		startOn = parsed
+	}
+	vacancies, err := s.VacanciesTx(tx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if vacancies == 0 {
+		return ErrNoVacancy
+	}
+	if _, err := s.employees.Get(orgID, req.EmployeeID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: employee %d not found", ErrInvalidPosition, req.EmployeeID)
+	} else if err != nil {
+		return err
+	}
+	if err := lock.Tx(tx, fmt.Sprintf("position_holder:%d", req.EmployeeID)); err != nil {
+		return err
+	}
+	var held int64
+	if err := tx.Model(&Holder{}).Where("employee_id = ?", req.EmployeeID).Count(&held).Error; err != nil {
+		return fmt.Errorf("failed to check position holders: %w", err)
+	}
+	if held > 0 {
+		return ErrAlreadyPlaced
+	}
+	holder := Holder{OrganizationID: orgID, PositionID: id, EmployeeID: req.EmployeeID, StartOn: startOn}
+	if err := tx.Create(&holder).Error; err != nil {
+		return fmt.Errorf("failed to place employee: %w", err)
+	}
+	if err := track(tx, id); err != nil {
+		return err
+	}
+	return s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "position.place", EntityType: "position", EntityID: fmt.Sprintf("%d", id), After: holder,
+	})
+}
+
+func (s *service) Vacate(actor audit.Actor, orgID *uint, id, employeeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockPosition(tx, orgID, id); err != nil {
+			return err
+		}
+		var holder Holder
+		if err := tx.Where("position_id = ? AND employee_id = ?", id, employeeID).First(&holder).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Holder{}, holder.ID).Error; err != nil {
+			return fmt.Errorf("failed to vacate seat: %w", err)
+		}
+		if err := track(tx, id); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "position.vacate", EntityType: "position", EntityID: fmt.Sprintf("%d", id), Before: holder,
+		})
+	})
+}
+
+func (s *service) VacanciesTx(tx *gorm.DB, orgID *uint, id uint) (int, error) {
+	position, err := lockPosition(tx, orgID, id)
+	if err != nil {
+		return 0, err
+	}
+	if position.Status != StatusActive {
+		return 0, nil
+	}
+	filled, err := filledSeats(tx, []uint{id})
+	if err != nil {
+		return 0, err
+	}
+	return max(0, position.Headcount-filled[id]), nil
+}
+
+// Headcount counts seats and employees per division. Filled seats count toward the position's division,
+// wherever their holder is; actual headcount toward the employee's.
+func (s *service) Headcount(orgID *uint) (*HeadcountReport, error) {
+	var positions []Position
+	if err := utils.OrgScope(s.reporting, orgID).Where("status <> ?", StatusClosed).Find(&positions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load positions: %w", err)
+	}
+	ids := make([]uint, len(positions))
+	for i, p := range positions {
+		ids[i] = p.ID
+	}
+	filled, err := filledSeats(s.reporting, ids)
+	if err != nil {
+		return nil, err
+	}
+	var employees []struct {
+		DivisionID *uint
+		Placed     bool
+	}
+	if err := utils.OrgScope(s.reporting.Table("employees").Where("deleted_at IS NULL"), orgID).
+		Select("division_id, EXISTS (SELECT 1 FROM position_holders WHERE position_holders.employee_id = employees.id) AS placed").
+		Scan(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+
+	rows := make(map[uint]*HeadcountRow)
+	var outside HeadcountRow
+	row := func(divisionID *uint) *HeadcountRow {
+		if divisionID == nil {
+			return &outside
+		}
+		r := rows[*divisionID]
+		if r == nil {
+			id := *divisionID
+			r = &HeadcountRow{DivisionID: &id}
+			rows[id] = r
+		}
+		return r
+	}
+	for _, p := range positions {
+		r := row(&p.DivisionID)
+		r.Positions++
+		r.Budgeted += p.Headcount
+		r.Filled += filled[p.ID]
+		vacant := max(0, p.Headcount-filled[p.ID])
+		if p.Status == StatusActive {
+			r.Vacant += vacant
+		} else {
+			r.Frozen += vacant
+		}
+	}
+	for _, e := range employees {
+		r := row(e.DivisionID)
+		r.Actual++
+		if !e.Placed {
+			r.Unplaced++
+		}
+	}
+	names, err := divisionNames(s.reporting, orgID)
+	if err != nil {
+		return nil, err
+	}
+	report := &HeadcountReport{GeneratedAt: clock.Now().UTC(), Rows: make([]HeadcountRow, 0, len(rows)+1)}
+	for id, r := range rows {
+		r.Division = names[id]
+		report.Rows = append(report.Rows, *r)
+	}
+	slices.SortFunc(report.Rows, func(a, b HeadcountRow) int { return cmp.Compare(a.Division, b.Division) })
+	if outside.Actual > 0 {
+		report.Rows = append(report.Rows, outside)
+	}
+	for i := range report.Rows {
+		r := &report.Rows[i]
+		r.Variance = r.Actual - r.Budgeted
+		report.Total.Positions += r.Positions
+		report.Total.Budgeted += r.Budgeted
+		report.Total.Filled += r.Filled
+		report.Total.Vacant += r.Vacant
+		report.Total.Frozen += r.Frozen
+		report.Total.Actual += r.Actual
+		report.Total.Unplaced += r.Unplaced
+	}
+	report.Total.Variance = report.Total.Actual - report.Total.Budgeted
+	return report, nil
+}
+
+// summarize counts the filled seats of positions and names their divisions.
+func (s *service) summarize(db *gorm.DB, orgID *uint, positions []Position) ([]Summary, error) {
+	ids := make([]uint, len(positions))
+	for i, p := range positions {
+		ids[i] = p.ID
+	}
+	filled, err := filledSeats(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	names, err := divisionNames(db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, len(positions))
+	for i, p := range positions {
+		summaries[i] = Summary{Position: p, Division: names[p.DivisionID], Filled: filled[p.ID]}
+		if p.Status == StatusActive {
+			summaries[i].Vacancies = max(0, p.Headcount-filled[p.ID])
+		}
+	}
+	return summaries, nil
+}
+
+// track keeps a position's VacantSince: set to today when an active position gets a vacant seat, cleared
+// once its seats are all held or it is no longer active.
+func track(tx *gorm.DB, id uint) error {
+	var position Position
+	if err := tx.Select("id, headcount, status, vacant_since").First(&position, id).Error; err != nil {
+		return fmt.Errorf("failed to load position %d: %w", id, err)
+	}
+	filled, err := filledSeats(tx, []uint{id})
+	if err != nil {
+		return err
+	}
+	vacant := position.Status == StatusActive && filled[id] < position.Headcount
+	var vacantSince interface{}
+	switch {
+	case vacant && position.VacantSince == nil:
+		vacantSince = today()
+	case vacant:
+		return nil
+	case position.VacantSince == nil:
+		return nil
+	}
+	if err := tx.Model(&Position{}).Where("id = ?", id).Update("vacant_since", vacantSince).Error; err != nil {
+		return fmt.Errorf("failed to track vacancy of position %d: %w", id, err)
+	}
+	return nil
+}
+
+// filledSeats counts the holders of positions, by position ID. Holders of deleted employees are left out.
+func filledSeats(db *gorm.DB, ids []uint) (map[uint]int, error) {
+	filled := make(map[uint]int, len(ids))
+	if len(ids) == 0 {
+		return filled, nil
+	}
+	var counts []struct {
+		PositionID uint
+		Filled     int
+	}
+	if err := db.Table("position_holders").Select("position_holders.position_id, COUNT(*) AS filled").
+		Joins("JOIN employees ON employees.id = position_holders.employee_id AND employees.deleted_at IS NULL").
+		Where("position_holders.position_id IN ?", ids).Group("position_holders.position_id").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count position holders: %w", err)
+	}
+	for _, c := range counts {
+		filled[c.PositionID] = c.Filled
+	}
+	return filled, nil
+}
+
+// divisionNames returns the names of the organization's divisions, by ID.
+func divisionNames(db *gorm.DB, orgID *uint) (map[uint]string, error) {
+	var divisions []struct {
+		ID   uint
+		Name string
+	}
+	// Queried by table name: the division package builds on the employee package, not this one.
+	if err := utils.OrgScope(db.Table("divisions").Select("id, name").Where("deleted_at IS NULL"), orgID).Scan(&divisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load divisions: %w", err)
+	}
+	names := make(map[uint]string, len(divisions))
+	for _, d := range divisions {
+		names[d.ID] = d.Name
+	}
+	return names, nil
+}
+
+// apply validates req and copies it onto position.
+func apply(tx *gorm.DB, position *Position, req Request) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: a title is required", ErrInvalidPosition)
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.BudgetCurrency))
+	if (req.BudgetAmount == nil) != (currency == "") {
+		return fmt.Errorf("%w: a budget needs both an amount and a currency", ErrInvalidPosition)
+	}
+	var divisions int64
+	if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", req.DivisionID), position.OrganizationID).
+		Count(&divisions).Error; err != nil {
+		return fmt.Errorf("failed to load division %d: %w", req.DivisionID, err)
+	}
+	if divisions == 0 {
+		return fmt.Errorf("%w: division %d not found", ErrInvalidPosition, req.DivisionID)
+	}
+	position.DivisionID = req.DivisionID
+	position.Title = title
+	position.Headcount = req.Headcount
+	position.BudgetAmount, position.BudgetCurrency = req.BudgetAmount, currency
+	position.Status = req.Status
+	if position.Status == "" {
+		position.Status = StatusActive
+	}
+	return nil
+}
+
+// lockPosition loads a position for update, so its seats can't change until the transaction ends.
+func lockPosition(tx *gorm.DB, orgID *uint, id uint) (*Position, error) {
+	var position Position
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&position, id).Error; err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// today is the current UTC date.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,240 @@
+// prometheus/backend/internal/privacy/core.go
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/events"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// CoreSources are the personal data kept by the core: roles, role requests, preferences, login history
+// and audit records. The user's profile itself is handled by Service.
+func CoreSources() []Source {
+	return []Source{
+		NewSource("roles", exportRoles, nil),
+		NewSource("role_requests", exportRoleRequests, anonymizeRoleRequests),
+		NewSource("preferences", exportPreferences, anonymizePreferences),
+		NewSource("login_history", exportLoginHistory, anonymizeLoginHistory),
+		NewSource("audit", exportAudit, anonymizeAudit),
+	}
+}
+
+// roleRecord is a role held by the user, globally or for one division.
+type roleRecord struct {
+	Role       string     `json:"role"`
+	DivisionID *uint      `json:"division_id,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	GrantedAt  time.Time  `json:"granted_at"`
+}
+
+func exportRoles(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var records []roleRecord
+	if err := db.WithContext(ctx).Table("user_roles").
+		Select("roles.name AS role, user_roles.expires_at, user_roles.created_at AS granted_at").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ?", userID).Order("roles.name").Scan(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to export roles: %w", err)
+	}
+	var scoped []auth.ScopedRole
+	if err := db.WithContext(ctx).Preload("Role").Where("user_id = ?", userID).Find(&scoped).Error; err != nil {
+		return nil, fmt.Errorf("failed to export scoped roles: %w", err)
+	}
+	for _, sr := range scoped {
+		divisionID := sr.DivisionID
+		records = append(records, roleRecord{Role: sr.Role.Name, DivisionID: &divisionID, ExpiresAt: sr.ExpiresAt, GrantedAt: sr.CreatedAt})
+	}
+	return records, nil
+}
+
+func exportRoleRequests(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var requests []auth.RoleRequest
+	if err := db.WithContext(ctx).Preload("Role").Where("user_id = ?", userID).Order("id").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to export role requests: %w", err)
+	}
+	return requests, nil
+}
+
+// anonymizeRoleRequests keeps the requests for the approval statistics but drops the free-text reasons,
+// which tend to be personal ("covering during parental leave").
+func anonymizeRoleRequests(ctx context.Context, tx *gorm.DB, userID uint) error {
+	if err := tx.WithContext(ctx).Unscoped().Model(&auth.RoleRequest{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"reason": "", "decision_note": ""}).Error; err != nil {
+		return fmt.Errorf("failed to scrub role requests: %w", err)
+	}
+	return nil
+}
+
+func exportPreferences(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var stored auth.UserPreferences
+	err := db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&stored).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to export preferences: %w", err)
+	}
+	if stored.UserID == 0 {
+		return nil, nil
+	}
+	return stored.Data, nil
+}
+
+func anonymizePreferences(ctx context.Context, tx *gorm.DB, userID uint) error {
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&auth.UserPreferences{}).Error; err != nil {
+		return fmt.Errorf("failed to delete preferences: %w", err)
+	}
+	return nil
+}
+
+func exportLoginHistory(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var events []auth.LoginEvent
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to export login history: %w", err)
+	}
+	return events, nil
+}
+
+// anonymizeLoginHistory keeps login counts and outcomes but drops where they came from. Failed attempts
+// with the user's name or email that matched no user are dropped as well.
+func anonymizeLoginHistory(ctx context.Context, tx *gorm.DB, userID uint) error {
+	if err := tx.WithContext(ctx).Model(&auth.LoginEvent{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"identifier": anonymizedUsername(userID), "ip": "", "user_agent": ""}).Error; err != nil {
+		return fmt.Errorf("failed to scrub login history: %w", err)
+	}
+	var user auth.User
+	if err := tx.WithContext(ctx).Unscoped().Select("username", "email").First(&user, userID).Error; err != nil {
+		return fmt.Errorf("failed to load user %d: %w", userID, err)
+	}
+	if err := tx.WithContext(ctx).Where("user_id IS NULL AND identifier IN ?", []string{user.Username, user.Email}).
+		Delete(&auth.LoginEvent{}).Error; err != nil {
+		return fmt.Errorf("failed to delete login attempts: %w", err)
+	}
+	return nil
+}
+
+// auditRecords are the audit records of changes the user made and of changes made to the user.
+type auditRecords struct {
+	Performed []audit.Log `json:"performed"`
+	Concerned []audit.Log `json:"concerned"`
+}
+
+func exportAudit(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	var records auditRecords
+	if err := db.WithContext(ctx).Where("actor_id = ?", userID).Order("id").Find(&records.Performed).Error; err != nil {
+		return nil, fmt.Errorf("failed to export audit records: %w", err)
+	}
+	if err := db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", "user", fmt.Sprintf("%d", userID)).
+		Order("id").Find(&records.Concerned).Error; err != nil {
+		return nil, fmt.Errorf("failed to export audit records: %w", err)
+	}
+	return records, nil
+}
+
+// personalKeys are the keys of audit snapshots holding personal data, at any depth. The username is
+// replaced by the anonymized one so changes stay readable; the others are dropped.
+var personalKeys = map[string]bool{
+	"username": true, "email": true, "names": true, "name": true, "phone": true, "alt_phone": true,
+	"birth_date": true, "address": true, "avatar_key": true, "ip": true, "user_agent": true,
+}
+
+// personalActions are the audited changes to the user whose snapshots are personal data as a whole.
+var personalActions = map[string]bool{"user.custom_fields.update": true}
+
+// anonymizeAudit keeps who-did-what by user ID and the history of changes to the user, but drops the name
+// and IP of the actor and the personal data in snapshots of the user. The same snapshots were published as
+// events (see events.AuditHook) and are scrubbed there too. Erasure is the one exception to audit records
+// being immutable, see audit.AllowErasure.
+func anonymizeAudit(ctx context.Context, tx *gorm.DB, userID uint) error {
+	tx = tx.WithContext(ctx)
+	if err := audit.AllowErasure(tx); err != nil {
+		return err
+	}
+	if err := tx.Table(audit.Log{}.TableName()).Where("actor_id = ?", userID).
+		Updates(map[string]interface{}{"actor_username": anonymizedUsername(userID), "actor_ip": ""}).Error; err != nil {
+		return fmt.Errorf("failed to scrub audit records: %w", err)
+	}
+
+	entityID := fmt.Sprintf("%d", userID)
+	var records []audit.Log
+	if err := tx.Select("id", "action", "before", "after").Where("entity_type = ? AND entity_id = ?", "user", entityID).
+		Find(&records).Error; err != nil {
+		return fmt.Errorf("failed to load audit records: %w", err)
+	}
+	for _, record := range records {
+		before, err := scrubSnapshot(record.Before, record.Action, userID)
+		if err != nil {
+			return err
+		}
+		after, err := scrubSnapshot(record.After, record.Action, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Table(audit.Log{}.TableName()).Where("id = ?", record.ID).
+			Updates(map[string]interface{}{"before": before, "after": after}).Error; err != nil {
+			return fmt.Errorf("failed to scrub audit record %d: %w", record.ID, err)
+		}
+	}
+
+	var published []events.Event
+	if err := tx.Select("id", "type", "data").Where("entity_type = ? AND entity_id = ?", "user", entityID).
+		Find(&published).Error; err != nil {
+		return fmt.Errorf("failed to load events: %w", err)
+	}
+	for _, event := range published {
+		data, err := scrubSnapshot(event.Data, event.Type, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&events.Event{}).Where("id = ?", event.ID).Update("data", data).Error; err != nil {
+			return fmt.Errorf("failed to scrub event %d: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// scrubSnapshot returns snapshot, recorded for action, without the personal data of the user.
+func scrubSnapshot(snapshot datatypes.JSON, action string, userID uint) (datatypes.JSON, error) {
+	if len(snapshot) == 0 || personalActions[action] {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(snapshot, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	scrubbed, err := json.Marshal(scrubValue(value, anonymizedUsername(userID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return scrubbed, nil
+}
+
+// scrubValue drops personalKeys from value and the objects nested in it, and replaces usernames.
+func scrubValue(value interface{}, username string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			switch {
+			case key == "username":
+				v[key] = username
+			case personalKeys[key]:
+				delete(v, key)
+			default:
+				v[key] = scrubValue(nested, username)
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = scrubValue(nested, username)
+		}
+	}
+	return value
+}
+
+// anonymizedUsername replaces the username of an anonymized user.
+func anonymizedUsername(userID uint) string {
+	return fmt.Sprintf("deleted-user-%d", userID)
+}
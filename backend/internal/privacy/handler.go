@@ -0,0 +1,102 @@
+// prometheus/backend/internal/privacy/handler.go
+package privacy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for personal data exports and anonymization.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ExportMine returns all personal data held about the caller.
+// @Summary Export my personal data
+// @Description The ZIP holds one JSON file per kind of data (profile, roles, login history, audit records, ...)
+// @Description and the uploaded avatar; the JSON format holds the same data without the avatar.
+// @Tags Users
+// @Produce application/zip
+// @Produce json
+// @Param format query string false "zip or json" default(zip)
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Invalid format"
+// @Router /me/data-export [get]
+func (h *Handler) ExportMine(c *gin.Context) {
+	userID := c.GetUint("userID")
+	actor := audit.ActorFromContext(c)
+	switch format := c.DefaultQuery("format", "zip"); format {
+	case "json":
+		export, err := h.service.Export(c.Request.Context(), actor, userID)
+		if err != nil {
+			sendPrivacyError(c, err)
+			return
+		}
+		c.Header("Cache-Control", "private, no-store")
+		utils.SendSuccessResponse(c, http.StatusOK, "Personal data exported successfully", export)
+	case "zip":
+		// Buffered, so a failure halfway still gets a JSON error instead of a truncated archive.
+		var buf bytes.Buffer
+		if err := h.service.ExportZIP(c.Request.Context(), actor, userID, &buf); err != nil {
+			sendPrivacyError(c, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"personal-data-%s.zip\"", clock.Now().UTC().Format("2006-01-02")))
+		c.Header("Cache-Control", "private, no-store")
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "format must be zip or json")
+	}
+}
+
+// Anonymize scrubs a departed user's personal data.
+// @Summary Anonymize a departed user
+// @Description Irreversible. The user must be deactivated or deleted first. Name, email, password, custom
+// @Description fields, avatar and preferences are removed; role history, login counts and audit records are
+// @Description kept under the placeholder name "deleted-user-<id>".
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse "User still active, or own account"
+// @Failure 404 {object} utils.ErrorResponse "User not found"
+// @Failure 409 {object} utils.ErrorResponse "Already anonymized"
+// @Router /admin/users/{id}/anonymize [post]
+func (h *Handler) Anonymize(c *gin.Context) {
+	userID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Anonymize(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c), userID); err != nil {
+		sendPrivacyError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "User anonymized successfully", nil)
+}
+
+// sendPrivacyError maps service errors to HTTP status codes.
+func sendPrivacyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, ErrStillActive), errors.Is(err, ErrCannotAnonymizeSelf):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrAlreadyAnonymized):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
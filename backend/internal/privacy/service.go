@@ -0,0 +1,300 @@
+// prometheus/backend/internal/privacy/service.go
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/storage"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrStillActive is returned when anonymizing a user who hasn't been deactivated or deleted.
+var ErrStillActive = errors.New("only deactivated or deleted users can be anonymized")
+
+// ErrAlreadyAnonymized is returned when anonymizing a user a second time.
+var ErrAlreadyAnonymized = errors.New("user is already anonymized")
+
+// ErrCannotAnonymizeSelf is returned when admins try to anonymize their own account.
+var ErrCannotAnonymizeSelf = errors.New("you cannot anonymize your own account")
+
+// Profile is the user's account data in an export.
+type Profile struct {
+	ID             uint           `json:"id"`
+	Username       string         `json:"username"`
+	Email          string         `json:"email"`
+	IsActive       bool           `json:"is_active"`
+	OrganizationID *uint          `json:"organization_id,omitempty"`
+	LastLogin      *time.Time     `json:"last_login,omitempty"`
+	CustomFields   datatypes.JSON `json:"custom_fields,omitempty" swaggertype:"object"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Export is everything held about a user, by source name ("profile" plus the registered sources).
+type Export map[string]interface{}
+
+// Service exports and anonymizes the personal data held about users.
+type Service interface {
+	// Export collects the user's data as one document. The avatar is only part of ExportZIP.
+	Export(ctx context.Context, actor audit.Actor, userID uint) (Export, error)
+	// ExportZIP writes the user's data to w as a ZIP with one JSON file per source, plus the avatar and the
+	// files of file sources.
+	ExportZIP(ctx context.Context, actor audit.Actor, userID uint, w io.Writer) error
+	// Anonymize scrubs a departed user's personal data for good. Records that feed aggregates (role
+	// history, login counts, audit trail) are kept under a placeholder name.
+	Anonymize(ctx context.Context, actor audit.Actor, orgID *uint, userID uint) error
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+	files     storage.Storage
+	auditor   audit.Service
+	statuses  *auth.UserStatusCache
+	sources   *Registry
+}
+
+// NewService creates a new instance of Service. sources lists where personal data is kept; statuses is
+// invalidated once a user is anonymized. Exports read through reporting, the read-only reporting
+// connection (see database.ConnectReportingDB); only their audit records are written through db.
+func NewService(db, reporting *gorm.DB, files storage.Storage, auditor audit.Service, statuses *auth.UserStatusCache, sources *Registry) Service {
+	return &service{db: db, reporting: reporting, files: files, auditor: auditor, statuses: statuses, sources: sources}
+}
+
+func (s *service) Export(ctx context.Context, actor audit.Actor, userID uint) (Export, error) {
+	user, err := s.loadUser(ctx, s.reporting, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	export := Export{"profile": newProfile(user)}
+	for _, src := range s.sources.Sources() {
+		data, err := src.Export(ctx, s.reporting, userID)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			export[src.Name()] = data
+		}
+	}
+	s.recordExport(actor, userID)
+	return export, nil
+}
+
+func (s *service) ExportZIP(ctx context.Context, actor audit.Actor, userID uint, w io.Writer) error {
+	user, err := s.loadUser(ctx, s.reporting, nil, userID)
+	if err != nil {
+		return err
+	}
+	archive := zip.NewWriter(w)
+	if err := writeJSON(archive, "profile.json", newProfile(user)); err != nil {
+		return err
+	}
+	for _, src := range s.sources.Sources() {
+		data, err := src.Export(ctx, s.reporting, userID)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		if err := writeJSON(archive, src.Name()+".json", data); err != nil {
+			return err
+		}
+		if fs, ok := src.(FileSource); ok {
+			files, err := fs.Files(ctx, s.reporting, userID)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				if err := s.writeFile(ctx, archive, src.Name()+"/"+f.Name, f.Key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if user.AvatarKey != "" {
+		if err := s.writeAvatar(ctx, archive, user.AvatarKey); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish export: %w", err)
+	}
+	s.recordExport(actor, userID)
+	return nil
+}
+
+// writeAvatar adds the uploaded avatar. One that went missing from storage is skipped.
+func (s *service) writeAvatar(ctx context.Context, archive *zip.Writer, key string) error {
+	body, contentType, err := s.files.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read avatar: %w", err)
+	}
+	defer body.Close()
+	name := "avatar"
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		name += exts[0]
+	}
+	return copyToArchive(archive, name, body)
+}
+
+// writeFile adds a file of a FileSource as name. One that went missing from storage is skipped.
+func (s *service) writeFile(ctx context.Context, archive *zip.Writer, name, key string) error {
+	body, _, err := s.files.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer body.Close()
+	return copyToArchive(archive, name, body)
+}
+
+// copyToArchive adds body to the export as name.
+func copyToArchive(archive *zip.Writer, name string, body io.Reader) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// recordExport audits the export. It has already been handed out, so a failure is only logged.
+func (s *service) recordExport(actor audit.Actor, userID uint) {
+	if err := s.auditor.Record(actor, audit.Entry{
+		Action: "user.data_export", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+	}); err != nil {
+		log.Printf("Failed to audit data export of user %d: %v", userID, err)
+	}
+}
+
+// Anonymize runs the sources before scrubbing the profile, so they can still match on the user's name.
+// The avatar and the files of file sources are removed after the commit.
+func (s *service) Anonymize(ctx context.Context, actor audit.Actor, orgID *uint, userID uint) error {
+	if actor.UserID != nil && *actor.UserID == userID {
+		return ErrCannotAnonymizeSelf
+	}
+	var avatarKey string
+	var files []File
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := s.loadUser(ctx, tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if user.AnonymizedAt != nil {
+			return ErrAlreadyAnonymized
+		}
+		if user.IsActive && !user.DeletedAt.Valid {
+			return ErrStillActive
+		}
+		for _, src := range s.sources.Sources() {
+			if fs, ok := src.(FileSource); ok {
+				sourceFiles, err := fs.Files(ctx, tx, userID)
+				if err != nil {
+					return err
+				}
+				files = append(files, sourceFiles...)
+			}
+			if err := src.Anonymize(ctx, tx, userID); err != nil {
+				return err
+			}
+		}
+		now := clock.Now().UTC()
+		username := anonymizedUsername(userID)
+		if err := tx.Unscoped().Model(user).Updates(map[string]interface{}{
+			"username":          username,
+			"email":             username + "@anonymized.invalid",
+			"password":          "!", // Not a bcrypt hash, so no password matches
+			"is_active":         false,
+			"last_login":        nil,
+			"custom_fields":     nil,
+			"avatar_key":        "",
+			"tokens_revoked_at": now,
+			"anonymized_at":     now,
+			"version":           gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize user %d: %w", userID, err)
+		}
+		avatarKey = user.AvatarKey
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "user.anonymize", EntityType: "user", EntityID: fmt.Sprintf("%d", userID),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.statuses.Invalidate(context.Background(), userID); err != nil {
+		log.Printf("Failed to invalidate cached status of user %d: %v", userID, err)
+	}
+	if avatarKey != "" {
+		if err := s.files.Delete(context.Background(), avatarKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to delete avatar %s of anonymized user %d: %v", avatarKey, userID, err)
+		}
+	}
+	for _, f := range files {
+		if err := s.files.Delete(context.Background(), f.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to delete file %s of anonymized user %d: %v", f.Key, userID, err)
+		}
+	}
+	return nil
+}
+
+// loadUser fetches a user, deleted ones included, within the organization scope (nil = any).
+func (s *service) loadUser(ctx context.Context, db *gorm.DB, orgID *uint, userID uint) (*auth.User, error) {
+	query := db.WithContext(ctx).Unscoped()
+	if orgID != nil {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+	var user auth.User
+	if err := query.First(&user, userID).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &user, nil
+}
+
+func newProfile(user *auth.User) Profile {
+	return Profile{
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		IsActive:       user.IsActive,
+		OrganizationID: user.OrganizationID,
+		LastLogin:      user.LastLogin,
+		CustomFields:   user.CustomFields,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+func writeJSON(archive *zip.Writer, name string, data interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
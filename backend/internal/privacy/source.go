@@ -0,0 +1,110 @@
+// prometheus/backend/internal/privacy/source.go
+package privacy
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Source is one kind of personal data held about a user, e.g. login history. Sources are exported as one
+// JSON file each and scrubbed when the user is anonymized.
+type Source interface {
+	// Name is the file name in the export (without ".json"), e.g. "login_history".
+	Name() string
+	// Export returns the user's data, JSON-encoded as is. nil leaves the source out of the export.
+	Export(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error)
+	// Anonymize deletes or scrubs the user's data inside tx. Records counted in aggregates (e.g. how many
+	// logins happened) should be scrubbed rather than deleted.
+	Anonymize(ctx context.Context, tx *gorm.DB, userID uint) error
+}
+
+// File is a stored file holding personal data, e.g. an uploaded document.
+type File struct {
+	Name string // Path in the export below the source's folder, e.g. "31/1-contract.pdf"
+	Key  string // Storage key
+}
+
+// FileSource is a Source whose records point to stored files. ExportZIP adds the files below a folder named
+// after the source; Anonymize removes them from storage once the anonymization has committed, so the
+// source's Anonymize must delete the records pointing to them.
+type FileSource interface {
+	Source
+	// Files lists the user's files.
+	Files(ctx context.Context, db *gorm.DB, userID uint) ([]File, error)
+}
+
+// Contributor is implemented by modules holding personal data of their own.
+type Contributor interface {
+	PrivacySources() []Source
+}
+
+// source adapts a pair of functions to Source.
+type source struct {
+	name      string
+	export    func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error)
+	anonymize func(ctx context.Context, tx *gorm.DB, userID uint) error
+}
+
+// NewSource creates a Source from functions. anonymize may be nil for data that holds nothing personal
+// once the user's profile is scrubbed.
+func NewSource(name string, export func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error),
+	anonymize func(ctx context.Context, tx *gorm.DB, userID uint) error) Source {
+	return &source{name: name, export: export, anonymize: anonymize}
+}
+
+func (s *source) Name() string { return s.name }
+
+func (s *source) Export(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+	return s.export(ctx, db, userID)
+}
+
+func (s *source) Anonymize(ctx context.Context, tx *gorm.DB, userID uint) error {
+	if s.anonymize == nil {
+		return nil
+	}
+	return s.anonymize(ctx, tx, userID)
+}
+
+// fileSource adds a function listing files to source.
+type fileSource struct {
+	source
+	files func(ctx context.Context, db *gorm.DB, userID uint) ([]File, error)
+}
+
+// NewFileSource creates a FileSource from functions. anonymize must delete the records of the files listed.
+func NewFileSource(name string, export func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error),
+	anonymize func(ctx context.Context, tx *gorm.DB, userID uint) error,
+	files func(ctx context.Context, db *gorm.DB, userID uint) ([]File, error)) FileSource {
+	return &fileSource{source: source{name: name, export: export, anonymize: anonymize}, files: files}
+}
+
+func (s *fileSource) Files(ctx context.Context, db *gorm.DB, userID uint) ([]File, error) {
+	return s.files(ctx, db, userID)
+}
+
+// Registry collects the sources of the core and of enabled feature modules.
+type Registry struct {
+	mu      sync.RWMutex
+	sources []Source
+}
+
+// NewRegistry creates a Registry holding sources.
+func NewRegistry(sources ...Source) *Registry {
+	return &Registry{sources: sources}
+}
+
+// Add registers more sources.
+func (r *Registry) Add(sources ...Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, sources...)
+}
+
+// Sources returns the registered sources in registration order.
+func (r *Registry) Sources() []Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Source(nil), r.sources...)
+}
@@ -0,0 +1,180 @@
+// prometheus/backend/internal/reports/catalog.go
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"prometheus/backend/internal/auth"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scope is whose data a report covers: the subscriber's organization (nil = default organization).
+type Scope struct {
+	OrganizationID *uint
+	UserID         uint
+}
+
+// Output is a generated report file.
+type Output struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Rows        int
+}
+
+// Generator builds a report for a scope.
+type Generator func(ctx context.Context, db *gorm.DB, scope Scope) (*Output, error)
+
+// Report is a report available for subscriptions.
+type Report struct {
+	Definition
+	Generate Generator
+}
+
+// Catalog holds the reports users can subscribe to. Feature modules add their own reports (e.g. the
+// attendance module's daily summary) with Add while setting up.
+type Catalog struct {
+	mu      sync.RWMutex
+	reports map[string]Report
+}
+
+// NewCatalog creates a catalog with the built-in reports.
+func NewCatalog() *Catalog {
+	c := &Catalog{reports: map[string]Report{}}
+	c.Add(Report{
+		Definition: Definition{
+			Name:        "pending-approvals",
+			Description: "Role requests awaiting a decision",
+			Roles:       []string{"admin", "god-admin"},
+		},
+		Generate: pendingApprovals,
+	})
+	c.Add(Report{
+		Definition: Definition{
+			Name:        "user-roster",
+			Description: "All user accounts with their roles and last login",
+			Roles:       []string{"hr", "admin", "god-admin"},
+		},
+		Generate: userRoster,
+	})
+	return c
+}
+
+// Add registers a report, replacing one with the same name.
+func (c *Catalog) Add(r Report) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reports[r.Name] = r
+}
+
+// Get returns a report by name.
+func (c *Catalog) Get(name string) (Report, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.reports[name]
+	return r, ok
+}
+
+// Available lists the reports any of roles may subscribe to, by name.
+func (c *Catalog) Available(roles []string) []Definition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	defs := []Definition{}
+	for _, r := range c.reports {
+		if r.Allows(roles) {
+			defs = append(defs, r.Definition)
+		}
+	}
+	slices.SortFunc(defs, func(a, b Definition) int { return strings.Compare(a.Name, b.Name) })
+	return defs
+}
+
+// Allows reports whether any of roles may receive the report.
+func (r Report) Allows(roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(r.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingApprovals lists the pending role requests of the scope's users.
+func pendingApprovals(ctx context.Context, db *gorm.DB, scope Scope) (*Output, error) {
+	var requests []auth.RoleRequest
+	query := db.WithContext(ctx).Preload("Role").
+		Joins("JOIN users ON users.id = role_requests.user_id").
+		Where("role_requests.status = ?", auth.RoleRequestPending)
+	query = scopeUsers(query, scope)
+	if err := query.Order("role_requests.created_at").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pending role requests: %w", err)
+	}
+
+	rows := [][]string{{"request_id", "user_id", "role", "division_id", "reason", "requested_at"}}
+	for _, r := range requests {
+		division := ""
+		if r.DivisionID != nil {
+			division = fmt.Sprintf("%d", *r.DivisionID)
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", r.ID), fmt.Sprintf("%d", r.UserID), r.Role.Name, division, r.Reason,
+			r.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return csvOutput("pending-approvals", rows)
+}
+
+// userRoster lists the scope's users with their global roles.
+func userRoster(ctx context.Context, db *gorm.DB, scope Scope) (*Output, error) {
+	var users []auth.User
+	query := scopeUsers(db.WithContext(ctx).Preload("Roles"), scope)
+	if err := query.Order("username").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	rows := [][]string{{"user_id", "username", "email", "active", "roles", "last_login"}}
+	for _, u := range users {
+		lastLogin := ""
+		if u.LastLogin != nil {
+			lastLogin = u.LastLogin.UTC().Format(time.RFC3339)
+		}
+		roles := u.RoleNames()
+		slices.Sort(roles)
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", u.ID), u.Username, u.Email, fmt.Sprintf("%t", u.IsActive),
+			strings.Join(roles, " "), lastLogin,
+		})
+	}
+	return csvOutput("user-roster", rows)
+}
+
+// scopeUsers restricts a query on users (or joined with users) to the scope's organization.
+func scopeUsers(query *gorm.DB, scope Scope) *gorm.DB {
+	query = query.Where("users.deleted_at IS NULL")
+	if scope.OrganizationID == nil {
+		return query.Where("users.organization_id IS NULL")
+	}
+	return query.Where("users.organization_id = ?", *scope.OrganizationID)
+}
+
+// csvOutput encodes rows (header first) as a dated CSV file.
+func csvOutput(name string, rows [][]string) (*Output, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to encode %s report: %w", name, err)
+	}
+	return &Output{
+		Filename:    fmt.Sprintf("%s-%s.csv", name, time.Now().UTC().Format("2006-01-02")),
+		ContentType: "text/csv",
+		Data:        buf.Bytes(),
+		Rows:        len(rows) - 1,
+	}, nil
+}
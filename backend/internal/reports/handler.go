@@ -0,0 +1,144 @@
+// prometheus/backend/internal/reports/handler.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for report subscriptions.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListReports returns the reports the caller may subscribe to.
+// @Summary List subscribable reports
+// @Tags Reports
+// @Produce json
+// @Success 200 {array} Definition
+// @Router /reports [get]
+func (h *Handler) ListReports(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Reports fetched successfully", h.service.Available(callerRoles(c)))
+}
+
+// ListSubscriptions returns the caller's report subscriptions.
+// @Summary List my report subscriptions
+// @Tags Reports
+// @Produce json
+// @Success 200 {array} Subscription
+// @Router /me/report-subscriptions [get]
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.service.List(c.GetUint("userID"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Subscriptions fetched successfully", subs)
+}
+
+// Subscribe subscribes the caller to a report.
+// @Summary Subscribe to a report
+// @Description Reports are emailed at 06:00 UTC, daily or on Mondays, as an attachment or a signed download link.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param subscription body SubscribeRequest true "Report and schedule"
+// @Success 201 {object} Subscription
+// @Failure 400 {object} utils.ErrorResponse "Unknown report"
+// @Failure 403 {object} utils.ErrorResponse "Report not allowed for the caller's roles"
+// @Failure 409 {object} utils.ErrorResponse "Already subscribed"
+// @Router /me/report-subscriptions [post]
+func (h *Handler) Subscribe(c *gin.Context) {
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	sub, err := h.service.Subscribe(audit.ActorFromContext(c), c.GetUint("userID"), utils.OrganizationFromContext(c), callerRoles(c), req)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Subscribed successfully", sub)
+}
+
+// Unsubscribe removes one of the caller's subscriptions.
+// @Summary Unsubscribe from a report
+// @Tags Reports
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 404 {object} utils.ErrorResponse "Subscription not found"
+// @Router /me/report-subscriptions/{id} [delete]
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.Unsubscribe(audit.ActorFromContext(c), c.GetUint("userID"), id); err != nil {
+		sendReportError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Unsubscribed successfully", nil)
+}
+
+// Download serves a report from a signed link sent by email. No authentication: the signature is the credential.
+// @Summary Download a delivered report
+// @Tags Reports
+// @Produce octet-stream
+// @Param id path string true "Run ID"
+// @Param expires query int true "Expiry (unix seconds)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 403 {object} utils.ErrorResponse "Invalid or expired link"
+// @Router /reports/download/{id} [get]
+func (h *Handler) Download(c *gin.Context) {
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusForbidden, ErrInvalidLink.Error())
+		return
+	}
+	run, err := h.service.Download(c.Param("id"), expires, c.Query("signature"))
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", run.Filename))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, run.ContentType, run.Content)
+}
+
+// callerRoles returns the caller's global role names (set by AuthMiddleware).
+func callerRoles(c *gin.Context) []string {
+	roles, _ := c.Get("roles")
+	names, _ := roles.([]string)
+	return names
+}
+
+// sendReportError maps service errors to HTTP status codes.
+func sendReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Subscription not found")
+	case errors.Is(err, ErrUnknownReport):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrReportNotAllowed), errors.Is(err, ErrInvalidLink):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrSubscriptionExists):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,68 @@
+// prometheus/backend/internal/reports/model.go
+package reports
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schedule is how often a subscription is delivered.
+type Schedule string
+
+const (
+	ScheduleDaily  Schedule = "daily"  // Every day at deliveryHour (UTC)
+	ScheduleWeekly Schedule = "weekly" // Mondays at deliveryHour (UTC)
+)
+
+// Delivery is how a generated report reaches the subscriber.
+type Delivery string
+
+const (
+	DeliveryAttachment Delivery = "attachment" // The file is attached to the email
+	DeliveryLink       Delivery = "link"       // The email carries a signed download link (for large reports)
+)
+
+// Subscription delivers a report to a user on a schedule.
+type Subscription struct {
+	gorm.Model
+	UserID         uint       `gorm:"not null;index" json:"user_id" example:"7"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Report         string     `gorm:"type:varchar(100);not null" json:"report" example:"pending-approvals"`
+	Schedule       Schedule   `gorm:"type:varchar(20);not null" json:"schedule" example:"weekly"`
+	Delivery       Delivery   `gorm:"type:varchar(20);not null" json:"delivery" example:"attachment"`
+	NextRunAt      time.Time  `gorm:"not null;index" json:"next_run_at"`
+	LastSentAt     *time.Time `json:"last_sent_at,omitempty"`
+	LastError      string     `gorm:"type:varchar(500)" json:"last_error,omitempty"`
+}
+
+// Run is a generated report kept for download through a signed link. Runs are purged once expired.
+type Run struct {
+	ID             string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	SubscriptionID uint      `gorm:"index" json:"subscription_id"`
+	Filename       string    `gorm:"type:varchar(255);not null" json:"filename"`
+	ContentType    string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Content        []byte    `json:"-"`
+	ExpiresAt      time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName keeps runs next to subscriptions.
+func (Run) TableName() string { return "report_runs" }
+
+// TableName implements gorm's Tabler.
+func (Subscription) TableName() string { return "report_subscriptions" }
+
+// SubscribeRequest subscribes the caller to a report.
+type SubscribeRequest struct {
+	Report   string   `json:"report" binding:"required" example:"pending-approvals"`
+	Schedule Schedule `json:"schedule" binding:"required,oneof=daily weekly" example:"weekly"`
+	Delivery Delivery `json:"delivery" binding:"omitempty,oneof=attachment link" example:"attachment"` // Defaults to "attachment"
+}
+
+// Definition describes a report users can subscribe to.
+type Definition struct {
+	Name        string   `json:"name" example:"pending-approvals"`
+	Description string   `json:"description" example:"Role requests awaiting a decision"`
+	Roles       []string `json:"roles" example:"admin,god-admin"` // Roles allowed to subscribe
+}
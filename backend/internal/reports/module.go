@@ -0,0 +1,102 @@
+// prometheus/backend/internal/reports/module.go
+package reports
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the reports module.
+const ModuleName = "reports"
+
+// deliverInterval is how often due subscriptions are looked for.
+const deliverInterval = 5 * time.Minute
+
+// reportsModule owns report subscriptions and their scheduled delivery.
+type reportsModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the reports module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &reportsModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *reportsModule) Name() string { return ModuleName }
+
+func (m *reportsModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("deliveries", func(ctx context.Context) module.HealthResult {
+			var overdue, failing int64
+			db := m.db.WithContext(ctx).Model(&Subscription{})
+			if err := db.Where("next_run_at <= ?", clock.Now().UTC().Add(-time.Hour)).Count(&overdue).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			if err := m.db.WithContext(ctx).Model(&Subscription{}).Where("last_error <> ''").Count(&failing).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			// Failed deliveries are retried; worth a look, but reports keep flowing for everyone else.
+			status := module.StatusUp
+			if failing > 0 {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"overdue": float64(overdue), "failing": float64(failing)}}
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *reportsModule) Models() []any {
+	return []any{&Subscription{}, &Run{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *reportsModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobDeliverDue, DeliverDueJob(m.service))
+	q.Every(JobDeliverDue, deliverInterval)
+}
+
+// RegisterRoutes implements routing.Contributor. Subscriptions need the reports plan module; download
+// links keep working for the link's lifetime.
+func (m *reportsModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/reports/download/:id", routing.Public(), m.handler.Download)
+	reportsAPI := api.InModule(plan.ModuleReports)
+	reportsAPI.GET("/reports", routing.Authenticated(), m.handler.ListReports)
+	reportsAPI.GET("/me/report-subscriptions", routing.Authenticated(), m.handler.ListSubscriptions)
+	reportsAPI.POST("/me/report-subscriptions", routing.Authenticated(), m.handler.Subscribe)
+	reportsAPI.DELETE("/me/report-subscriptions/:id", routing.Authenticated(), m.handler.Unsubscribe)
+}
+
+// PrivacySources implements privacy.Contributor: subscriptions are exported, and deleted together with
+// their stored runs when the subscriber is anonymized.
+func (m *reportsModule) PrivacySources() []privacy.Source {
+	return []privacy.Source{
+		privacy.NewSource("report_subscriptions", func(ctx context.Context, db *gorm.DB, userID uint) (interface{}, error) {
+			var subs []Subscription
+			if err := db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&subs).Error; err != nil {
+				return nil, fmt.Errorf("failed to export report subscriptions: %w", err)
+			}
+			return subs, nil
+		}, func(ctx context.Context, tx *gorm.DB, userID uint) error {
+			subs := tx.Unscoped().Model(&Subscription{}).Select("id").Where("user_id = ?", userID)
+			if err := tx.WithContext(ctx).Where("subscription_id IN (?)", subs).Delete(&Run{}).Error; err != nil {
+				return fmt.Errorf("failed to delete report runs: %w", err)
+			}
+			if err := tx.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&Subscription{}).Error; err != nil {
+				return fmt.Errorf("failed to delete report subscriptions: %w", err)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,330 @@
+// prometheus/backend/internal/reports/service.go
+package reports
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/mail"
+	"prometheus/backend/internal/outbox"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobDeliverDue is the recurring job type that delivers due subscriptions.
+const JobDeliverDue = "reports.deliver_due"
+
+// deliveryHour is the hour of day (UTC) scheduled reports are delivered at.
+const deliveryHour = 6
+
+// linkTTL is how long a signed download link stays valid.
+const linkTTL = 7 * 24 * time.Hour
+
+// retryDelay postpones a subscription whose delivery failed.
+const retryDelay = time.Hour
+
+// ErrUnknownReport is returned when subscribing to a report that doesn't exist.
+var ErrUnknownReport = errors.New("unknown report")
+
+// ErrReportNotAllowed is returned when the caller's roles don't allow the report.
+var ErrReportNotAllowed = errors.New("your roles do not allow subscribing to this report")
+
+// ErrSubscriptionExists is returned when the caller already receives the report on that schedule.
+var ErrSubscriptionExists = errors.New("already subscribed to this report on this schedule")
+
+// errCancelled is returned by deliver after it removed a subscription its subscriber may no longer receive.
+var errCancelled = errors.New("subscription cancelled")
+
+// ErrInvalidLink is returned for download links that are forged, expired or already purged.
+var ErrInvalidLink = errors.New("download link is invalid or has expired")
+
+// Service manages report subscriptions and delivers them.
+type Service interface {
+	Available(roles []string) []Definition
+	List(userID uint) ([]Subscription, error)
+	Subscribe(actor audit.Actor, userID uint, orgID *uint, roles []string, req SubscribeRequest) (*Subscription, error)
+	Unsubscribe(actor audit.Actor, userID, subscriptionID uint) error
+	DeliverDue(ctx context.Context) (delivered, failed int, err error)
+	Download(runID string, expires int64, signature string) (*Run, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db         *gorm.DB
+	reporting  *gorm.DB
+	catalog    *Catalog
+	outbox     *outbox.Outbox
+	templates  mail.TemplateService
+	auditor    audit.Service
+	secret     []byte
+	apiBaseURL string
+}
+
+// NewService creates a new instance of Service. Report emails are rendered with templates (see
+// DeliveryTemplate) and queued in messages; secret signs download links; apiBaseURL is the public URL of
+// this API the links point to. Reports are generated through reporting, the read-only reporting connection
+// (see database.ConnectReportingDB).
+func NewService(db, reporting *gorm.DB, catalog *Catalog, messages *outbox.Outbox, templates mail.TemplateService, auditor audit.Service, secret, apiBaseURL string) Service {
+	return &service{db: db, reporting: reporting, catalog: catalog, outbox: messages, templates: templates, auditor: auditor, secret: []byte(secret), apiBaseURL: apiBaseURL}
+}
+
+// Available lists the reports the roles may subscribe to.
+func (s *service) Available(roles []string) []Definition {
+	return s.catalog.Available(roles)
+}
+
+// List returns the user's subscriptions.
+func (s *service) List(userID uint) ([]Subscription, error) {
+	var subs []Subscription
+	if err := s.db.Where("user_id = ?", userID).Order("report, schedule").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// Subscribe schedules a report for the user. The first delivery is the next scheduled slot.
+func (s *service) Subscribe(actor audit.Actor, userID uint, orgID *uint, roles []string, req SubscribeRequest) (*Subscription, error) {
+	report, ok := s.catalog.Get(req.Report)
+	if !ok {
+		return nil, ErrUnknownReport
+	}
+	if !report.Allows(roles) {
+		return nil, ErrReportNotAllowed
+	}
+	if req.Delivery == "" {
+		req.Delivery = DeliveryAttachment
+	}
+
+	sub := &Subscription{
+		UserID:         userID,
+		OrganizationID: orgID,
+		Report:         req.Report,
+		Schedule:       req.Schedule,
+		Delivery:       req.Delivery,
+		NextRunAt:      nextRun(req.Schedule, clock.Now().UTC()),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Subscription{}).Where("user_id = ? AND report = ? AND schedule = ?", userID, req.Report, req.Schedule).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check subscriptions: %w", err)
+		}
+		if count > 0 {
+			return ErrSubscriptionExists
+		}
+		if err := tx.Create(sub).Error; err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "report.subscribe", EntityType: "report_subscription", EntityID: fmt.Sprintf("%d", sub.ID), After: sub,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Unsubscribe deletes one of the user's subscriptions.
+func (s *service) Unsubscribe(actor audit.Actor, userID, subscriptionID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var sub Subscription
+		if err := tx.Where("user_id = ?", userID).First(&sub, subscriptionID).Error; err != nil {
+			return err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+		}
+		if err := tx.Delete(&sub).Error; err != nil {
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "report.unsubscribe", EntityType: "report_subscription", EntityID: fmt.Sprintf("%d", sub.ID), Before: sub,
+		})
+	})
+}
+
+// DeliverDue generates and mails every subscription whose slot has come, and purges expired runs.
+// A failed delivery is retried after retryDelay; the other subscriptions are not affected.
+func (s *service) DeliverDue(ctx context.Context) (int, int, error) {
+	now := clock.Now().UTC()
+	if err := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Run{}).Error; err != nil {
+		log.Printf("Reports: failed to purge expired runs: %v", err)
+	}
+
+	var due []Subscription
+	if err := s.db.WithContext(ctx).Where("next_run_at <= ?", now).Order("next_run_at").Find(&due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to load due subscriptions: %w", err)
+	}
+	delivered, failed := 0, 0
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			return delivered, failed, err
+		}
+		sub := &due[i]
+		err := s.deliver(ctx, sub, now)
+		if errors.Is(err, errCancelled) {
+			continue
+		}
+		if err == nil {
+			delivered++
+			continue
+		}
+		log.Printf("Reports: failed to deliver subscription %d: %v", sub.ID, err)
+		failed++
+		updates := map[string]interface{}{"next_run_at": now.Add(retryDelay), "last_error": truncate(err.Error(), 500)}
+		if err := s.db.WithContext(ctx).Model(sub).Updates(updates).Error; err != nil {
+			return delivered, failed, fmt.Errorf("failed to reschedule subscription %d: %w", sub.ID, err)
+		}
+	}
+	return delivered, failed, nil
+}
+
+// deliver generates a subscription's report, then queues the email and reschedules the subscription in one
+// transaction, so a report is neither mailed twice nor skipped when the process dies in between.
+// Subscribers who were deactivated or lost the roles the report requires are unsubscribed instead.
+func (s *service) deliver(ctx context.Context, sub *Subscription, now time.Time) error {
+	report, ok := s.catalog.Get(sub.Report)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownReport, sub.Report)
+	}
+	var user auth.User
+	err := s.db.WithContext(ctx).Preload("Roles").First(&user, sub.UserID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to load subscriber: %w", err)
+	}
+	if err != nil || !user.IsActive || !report.Allows(user.RoleNames()) {
+		if err := s.cancel(sub); err != nil {
+			return err
+		}
+		return errCancelled
+	}
+
+	output, err := report.Generate(ctx, s.reporting, Scope{OrganizationID: sub.OrganizationID, UserID: sub.UserID})
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Username":     user.Username,
+		"Schedule":     sub.Schedule,
+		"ReportName":   report.Name,
+		"Description":  report.Description,
+		"Rows":         output.Rows,
+		"DownloadLink": "",
+		"LinkDays":     int(linkTTL.Hours() / 24),
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		msg := mail.Message{To: []string{user.Email}}
+		switch sub.Delivery {
+		case DeliveryLink:
+			link, err := s.storeRun(tx, sub, output)
+			if err != nil {
+				return err
+			}
+			data["DownloadLink"] = link
+		default:
+			msg.Attachments = []mail.Attachment{{Filename: output.Filename, ContentType: output.ContentType, Data: output.Data}}
+		}
+		subject, body, err := s.templates.Render(sub.OrganizationID, DeliveryTemplate, data)
+		if err != nil {
+			return err
+		}
+		msg.Subject, msg.Body = subject, body
+		if err := mail.QueueTx(s.outbox, tx, msg); err != nil {
+			return err
+		}
+		return tx.Model(sub).Updates(map[string]interface{}{
+			"next_run_at": nextRun(sub.Schedule, now), "last_sent_at": now, "last_error": "",
+		}).Error
+	})
+}
+
+// cancel removes a subscription whose subscriber may no longer receive it.
+func (s *service) cancel(sub *Subscription) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(sub).Error; err != nil {
+			return fmt.Errorf("failed to cancel subscription %d: %w", sub.ID, err)
+		}
+		return s.auditor.RecordTx(tx, audit.SystemActor, audit.Entry{
+			Action: "report.unsubscribe", EntityType: "report_subscription", EntityID: fmt.Sprintf("%d", sub.ID), Before: sub,
+		})
+	})
+}
+
+// storeRun keeps the report for download and returns its signed link.
+func (s *service) storeRun(tx *gorm.DB, sub *Subscription, output *Output) (string, error) {
+	run := Run{
+		ID:             uuid.NewString(),
+		SubscriptionID: sub.ID,
+		Filename:       output.Filename,
+		ContentType:    output.ContentType,
+		Content:        output.Data,
+		ExpiresAt:      clock.Now().UTC().Add(linkTTL),
+	}
+	if err := tx.Create(&run).Error; err != nil {
+		return "", fmt.Errorf("failed to store report: %w", err)
+	}
+	expires := run.ExpiresAt.Unix()
+	return fmt.Sprintf("%s/api/v1/reports/download/%s?expires=%d&signature=%s", s.apiBaseURL, run.ID, expires, s.sign(run.ID, expires)), nil
+}
+
+// Download returns a stored run if the link's signature and expiry are valid.
+func (s *service) Download(runID string, expires int64, signature string) (*Run, error) {
+	if !hmac.Equal([]byte(s.sign(runID, expires)), []byte(signature)) || clock.Now().Unix() >= expires {
+		return nil, ErrInvalidLink
+	}
+	var run Run
+	if err := s.db.Where("id = ? AND expires_at > ?", runID, clock.Now().UTC()).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLink
+		}
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+	return &run, nil
+}
+
+func (s *service) sign(runID string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("report-run:" + runID + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverDueJob runs DeliverDue as a recurring job.
+func DeliverDueJob(service Service) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		delivered, failed, err := service.DeliverDue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"delivered": delivered, "failed": failed}, nil
+	}
+}
+
+// nextRun returns the first delivery slot after now.
+func nextRun(schedule Schedule, now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), deliveryHour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if schedule == ScheduleWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
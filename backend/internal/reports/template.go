@@ -0,0 +1,27 @@
+// prometheus/backend/internal/reports/template.go
+package reports
+
+import "prometheus/backend/internal/mail"
+
+// DeliveryTemplate is the email a subscriber receives with a report. DownloadLink is empty when the
+// report is attached instead.
+const DeliveryTemplate = "report.delivery"
+
+func init() {
+	mail.RegisterTemplate(mail.Template{
+		Name:        DeliveryTemplate,
+		Description: "Scheduled report sent to a subscriber",
+		Subject:     "Your {{.Schedule}} report: {{.Description}}",
+		Body: "Hello {{.Username}},\n\nyour {{.Schedule}} report {{printf \"%q\" .ReportName}} has {{.Rows}} rows.\n" +
+			"{{if .DownloadLink}}\nDownload it within {{.LinkDays}} days: {{.DownloadLink}}\n{{end}}",
+		Sample: map[string]interface{}{
+			"Username":     "jdoe",
+			"Schedule":     ScheduleWeekly,
+			"ReportName":   "headcount",
+			"Description":  "Active users per role",
+			"Rows":         42,
+			"DownloadLink": "https://hris.example.com/api/reports/runs/example/download",
+			"LinkDays":     int(linkTTL.Hours() / 24),
+		},
+	})
+}
@@ -0,0 +1,84 @@
+// prometheus/backend/internal/requisition/approval.go
+package requisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/approval"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// FinanceApprovalKind names finance steps of requisitions among the kinds of requests decidable by email.
+	FinanceApprovalKind = "requisition_finance"
+	// HeadApprovalKind names division head steps of requisitions among the kinds of requests decidable by email.
+	HeadApprovalKind = "requisition_head"
+)
+
+// Approvals lets finance or division heads decide their step of a requisition from the notification email.
+// The approver must still be active, without sessions revoked since the email was sent; whether they may
+// still decide the step is checked like for decisions made in the app.
+func Approvals(db *gorm.DB, service Service, statuses *auth.UserStatusCache, step Step) approval.Kind {
+	return approval.Kind{
+		Authorize: func(ctx context.Context, userID uint, issuedAt time.Time) (audit.Actor, error) {
+			status, err := statuses.Status(ctx, userID)
+			if err != nil {
+				return audit.Actor{}, err
+			}
+			if !status.Accepts(issuedAt) {
+				return audit.Actor{}, approval.ErrNotAllowed
+			}
+			var user auth.User
+			if err := db.WithContext(ctx).Select("id", "username", "organization_id").First(&user, userID).Error; err != nil {
+				return audit.Actor{}, fmt.Errorf("failed to load user %d: %w", userID, err)
+			}
+			return audit.Actor{UserID: &user.ID, Username: user.Username, OrganizationID: user.OrganizationID}, nil
+		},
+		Describe: func(ctx context.Context, id uint) (string, error) {
+			var requisition Requisition
+			err := db.WithContext(ctx).Preload("Approvals").First(&requisition, id).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && requisition.Status != StatusPending) {
+				return "", approval.ErrNotPending
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to load requisition %d: %w", id, err)
+			}
+			for _, a := range requisition.Approvals {
+				if a.Step == step && a.Decision != DecisionPending {
+					return "", approval.ErrNotPending
+				}
+			}
+			title, err := positionTitle(db.WithContext(ctx), requisition.PositionID)
+			if err != nil {
+				return "", err
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "Recruit %d %s", requisition.Openings, title)
+			if requisition.BudgetAmount != nil {
+				fmt.Fprintf(&b, " with an annual budget of %d %s (minor units) each", *requisition.BudgetAmount,
+					requisition.BudgetCurrency)
+			}
+			fmt.Fprintf(&b, ". Justification: %s", requisition.Justification)
+			return b.String(), nil
+		},
+		Decide: func(actor audit.Actor, id uint, approve bool, note string) error {
+			if !approve && note == "" {
+				return fmt.Errorf("%w: say why the requisition is rejected", approval.ErrUnprocessable)
+			}
+			_, err := service.Decide(actor, actor.OrganizationID, id, step, DecisionRequest{Approve: approve, Note: note})
+			switch {
+			case errors.Is(err, ErrNotApprover):
+				return approval.ErrNotAllowed
+			case errors.Is(err, ErrStatus), errors.Is(err, ErrDecided), errors.Is(err, gorm.ErrRecordNotFound):
+				return approval.ErrNotPending
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,309 @@
+// prometheus/backend/internal/requisition/handler.go
+package requisition
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/position"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hrRoles see every requisition when held globally.
+var hrRoles = []string{"god-admin", "admin", "hr"}
+
+// Handler handles HTTP requests for requisitions.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the requisitions the caller may see, newest first: all of them for HR and finance, those of
+// the divisions they head for managers.
+// @Summary List requisitions
+// @Tags Requisitions
+// @Produce json
+// @Param status query string false "Status" Enums(pending, approved, rejected, cancelled, filled)
+// @Param position_id query int false "Position ID"
+// @Param division_id query int false "Division ID"
+// @Success 200 {array} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/requisitions [get]
+// @Router /finance/requisitions [get]
+// @Router /manager/requisitions [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusPending, StatusApproved, StatusRejected, StatusCancelled, StatusFilled:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	var ok bool
+	if filter.PositionID, ok = utils.OptionalUintQuery(c, "position_id"); !ok {
+		return
+	}
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	requisitions, err := h.service.Requisitions(utils.OrganizationFromContext(c), viewer(c), filter)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Requisitions fetched successfully", requisitions)
+}
+
+// Get returns a requisition with its approvals and hires. The ETag and Last-Modified headers can be sent
+// back as If-Match / If-Unmodified-Since.
+// @Summary Get a requisition
+// @Tags Requisitions
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Success 200 {object} Requisition
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Router /hr/requisitions/{id} [get]
+// @Router /finance/requisitions/{id} [get]
+// @Router /manager/requisitions/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	requisition, err := h.service.Get(utils.OrganizationFromContext(c), viewer(c), id)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Requisition fetched successfully", requisition)
+}
+
+// Create requests approval to recruit for vacant seats of a position.
+// @Summary Create a requisition
+// @Description The position must be active, with enough vacant seats that other pending or approved
+// @Description requisitions don't already recruit for. Finance and the head of the position's division
+// @Description are notified and may decide by email.
+// @Tags Requisitions
+// @Accept json
+// @Produce json
+// @Param requisition body Request true "Requisition"
+// @Success 201 {object} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Invalid requisition, unknown position, or a division without a head"
+// @Failure 409 {object} utils.ErrorResponse "Not enough vacant seats"
+// @Router /hr/requisitions [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	requisition, err := h.service.Create(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Requisition created successfully", requisition)
+}
+
+// Update replaces a pending requisition's fields and asks for its approval again.
+// @Summary Update a requisition
+// @Tags Requisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param requisition body Request true "Requisition"
+// @Success 200 {object} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Invalid requisition, unknown position, or a division without a head"
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Failure 409 {object} utils.ErrorResponse "Not pending, or not enough vacant seats"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/requisitions/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	expectedVersion, ok := h.precondition(c, orgID, id)
+	if !ok {
+		return
+	}
+	requisition, err := h.service.Update(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Requisition updated successfully", requisition)
+}
+
+// Cancel withdraws a pending or approved requisition.
+// @Summary Cancel a requisition
+// @Tags Requisitions
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Success 200 {object} Requisition
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Failure 409 {object} utils.ErrorResponse "Already rejected, cancelled or filled"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/requisitions/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	expectedVersion, ok := h.precondition(c, orgID, id)
+	if !ok {
+		return
+	}
+	requisition, err := h.service.Cancel(audit.ActorFromContext(c), orgID, id, expectedVersion)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Requisition cancelled successfully", requisition)
+}
+
+// DecideFinance approves or rejects the finance step of a pending requisition.
+// @Summary Decide the finance step of a requisition
+// @Description Rejections need a note. Rejecting a step rejects the requisition; it is approved once
+// @Description finance and the division head both approved it.
+// @Tags Requisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Param decision body DecisionRequest true "Decision"
+// @Success 200 {object} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Rejection without a note"
+// @Failure 403 {object} utils.ErrorResponse "Not holding the finance role"
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Failure 409 {object} utils.ErrorResponse "Not pending, or the step was already decided"
+// @Router /finance/requisitions/{id}/decision [post]
+func (h *Handler) DecideFinance(c *gin.Context) {
+	h.decide(c, StepFinance)
+}
+
+// DecideHead approves or rejects the division head step of a pending requisition.
+// @Summary Decide the division head step of a requisition
+// @Description Rejections need a note. Rejecting a step rejects the requisition; it is approved once
+// @Description finance and the division head both approved it.
+// @Tags Requisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Param decision body DecisionRequest true "Decision"
+// @Success 200 {object} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Rejection without a note"
+// @Failure 403 {object} utils.ErrorResponse "Not the head of the position's division"
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Failure 409 {object} utils.ErrorResponse "Not pending, or the step was already decided"
+// @Router /manager/requisitions/{id}/decision [post]
+func (h *Handler) DecideHead(c *gin.Context) {
+	h.decide(c, StepHead)
+}
+
+func (h *Handler) decide(c *gin.Context, step Step) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	requisition, err := h.service.Decide(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, step, req)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Requisition decided successfully", requisition)
+}
+
+// Hire links an employee hired against an approved requisition and places them in its position.
+// @Summary Link a hire to a requisition
+// @Description The requisition is filled with its last opening.
+// @Tags Requisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Requisition ID"
+// @Param hire body HireRequest true "Hire"
+// @Success 200 {object} Requisition
+// @Failure 400 {object} utils.ErrorResponse "Unknown employee, or already hired against a requisition"
+// @Failure 404 {object} utils.ErrorResponse "Requisition not found"
+// @Failure 409 {object} utils.ErrorResponse "Not approved, no vacant seat, or the employee already holds a position"
+// @Router /hr/requisitions/{id}/hires [post]
+func (h *Handler) Hire(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req HireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	requisition, err := h.service.Hire(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, requisition.UpdatedAt, requisition.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Hire linked successfully", requisition)
+}
+
+// precondition checks If-Match / If-Unmodified-Since against the requisition's current version.
+func (h *Handler) precondition(c *gin.Context, orgID *uint, id uint) (uint, bool) {
+	current, err := h.service.Get(orgID, Viewer{HR: true}, id)
+	if err != nil {
+		sendRequisitionError(c, err)
+		return 0, false
+	}
+	return utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+}
+
+func viewer(c *gin.Context) Viewer {
+	roles := middleware.RolesFromContext(c)
+	hr := slices.ContainsFunc(hrRoles, func(role string) bool { return slices.Contains(roles, role) })
+	return Viewer{UserID: c.GetUint("userID"), HR: hr, Finance: slices.Contains(roles, financeRole)}
+}
+
+// sendRequisitionError maps service errors, and those of placing hires in positions, to HTTP status codes.
+func sendRequisitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidRequisition), errors.Is(err, position.ErrInvalidPosition):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotApprover):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrStatus), errors.Is(err, ErrNoOpenings), errors.Is(err, ErrDecided),
+		errors.Is(err, position.ErrNoVacancy), errors.Is(err, position.ErrAlreadyPlaced):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
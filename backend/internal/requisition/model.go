@@ -0,0 +1,116 @@
+// prometheus/backend/internal/requisition/model.go
+package requisition
+
+import (
+	"time"
+)
+
+// Status is where a requisition is.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Waiting for finance and the division head
+	StatusApproved  Status = "approved"  // Recruiting may start; job postings can go live
+	StatusRejected  Status = "rejected"  // Final
+	StatusCancelled Status = "cancelled" // Withdrawn by HR before all openings were filled; final
+	StatusFilled    Status = "filled"    // Every opening has a hire; final
+)
+
+// Step is one of the approvals a requisition needs.
+type Step string
+
+const (
+	StepFinance Step = "finance" // Any user of the organization holding the finance role
+	StepHead    Step = "head"    // The head of the position's division
+)
+
+// Steps are the approvals every requisition needs, in the order they are listed.
+var Steps = []Step{StepFinance, StepHead}
+
+// Decision is an approver's answer to a step.
+type Decision string
+
+const (
+	DecisionPending  Decision = "pending"
+	DecisionApproved Decision = "approved"
+	DecisionRejected Decision = "rejected"
+)
+
+// Requisition asks to recruit for vacant seats of a budgeted position. It needs the approval of finance
+// and of the position's division head before recruiting starts, and collects the employees eventually
+// hired against it.
+type Requisition struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"17"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	PositionID     uint       `gorm:"not null;index" json:"position_id" example:"6"`
+	Position       string     `gorm:"-" json:"position,omitempty" example:"Payroll Specialist"`
+	DivisionID     uint       `gorm:"not null;index" json:"division_id" example:"2"` // Of the position when requested; its head approves
+	Openings       int        `gorm:"not null" json:"openings" example:"1"`
+	Justification  string     `gorm:"type:text;not null" json:"justification" example:"Second payroll run per month from January"`
+	BudgetAmount   *int64     `json:"budget_amount,omitempty" example:"6500000"`                   // Annual salary budget per hire, in minor units
+	BudgetCurrency string     `gorm:"type:char(3)" json:"budget_currency,omitempty" example:"EUR"` // Set with BudgetAmount
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"pending"`
+	RequestedBy    *uint      `json:"requested_by,omitempty" example:"4"` // User ID
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`               // When it was approved or rejected
+	Approvals      []Approval `gorm:"foreignKey:RequisitionID" json:"approvals,omitempty"`
+	Hires          []Hire     `gorm:"foreignKey:RequisitionID" json:"hires,omitempty"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Approval is one step of a requisition's approval.
+type Approval struct {
+	ID            uint       `gorm:"primaryKey" json:"id" example:"33"`
+	RequisitionID uint       `gorm:"not null;uniqueIndex:idx_requisition_approval_step" json:"requisition_id" example:"17"`
+	Step          Step       `gorm:"type:varchar(20);not null;uniqueIndex:idx_requisition_approval_step" json:"step" example:"finance"`
+	Decision      Decision   `gorm:"type:varchar(20);not null" json:"decision" example:"approved"`
+	DecidedBy     *uint      `json:"decided_by,omitempty" example:"9"` // User ID
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	Note          string     `gorm:"type:varchar(1000)" json:"note,omitempty" example:"Within the 2027 headcount budget"`
+}
+
+// TableName keeps approvals next to requisitions.
+func (Approval) TableName() string { return "requisition_approvals" }
+
+// Hire links an employee to the requisition they were hired against. An employee is hired against at most
+// one requisition.
+type Hire struct {
+	ID            uint      `gorm:"primaryKey" json:"id" example:"8"`
+	RequisitionID uint      `gorm:"not null;index" json:"requisition_id" example:"17"`
+	EmployeeID    uint      `gorm:"not null;uniqueIndex" json:"employee_id" example:"41"`
+	DisplayName   string    `gorm:"-" json:"display_name,omitempty" example:"Jonas Weber"`
+	HiredBy       *uint     `json:"hired_by,omitempty" example:"4"` // User ID
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName keeps hires next to requisitions.
+func (Hire) TableName() string { return "requisition_hires" }
+
+// Request creates a requisition or replaces its fields while it is pending.
+type Request struct {
+	PositionID     uint   `json:"position_id" binding:"required" example:"6"`
+	Openings       int    `json:"openings,omitempty" binding:"omitempty,min=1,max=1000" example:"1"` // Defaults to 1
+	Justification  string `json:"justification" binding:"required,max=5000" example:"Second payroll run per month from January"`
+	BudgetAmount   *int64 `json:"budget_amount,omitempty" binding:"omitempty,min=1" example:"6500000"` // Defaults to the position's
+	BudgetCurrency string `json:"budget_currency,omitempty" binding:"omitempty,len=3" example:"EUR"`   // Set with BudgetAmount
+}
+
+// DecisionRequest approves or rejects a step of a requisition.
+type DecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty" binding:"max=1000" example:"Within the 2027 headcount budget"`
+}
+
+// HireRequest links an employee hired against a requisition, placing them in its position.
+type HireRequest struct {
+	EmployeeID uint   `json:"employee_id" binding:"required" example:"41"`
+	StartOn    string `json:"start_on,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-11-02"` // Defaults to today
+}
+
+// Filter narrows a requisition listing.
+type Filter struct {
+	Status     Status
+	PositionID *uint
+	DivisionID *uint
+}
@@ -0,0 +1,50 @@
+// prometheus/backend/internal/requisition/module.go
+package requisition
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the requisitions module.
+const ModuleName = "requisitions"
+
+// requisitionModule owns requisitions, their approvals and the hires made against them.
+type requisitionModule struct {
+	handler *Handler
+}
+
+// NewModule creates the requisitions module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &requisitionModule{handler: NewHandler(svc)}
+}
+
+func (m *requisitionModule) Name() string { return ModuleName }
+
+func (m *requisitionModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *requisitionModule) Models() []any {
+	return []any{&Requisition{}, &Approval{}, &Hire{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR raises requisitions and links hires, finance and
+// division heads approve them.
+func (m *requisitionModule) RegisterRoutes(api *routing.Group) {
+	recruitingAPI := api.InModule(plan.ModuleATS)
+	recruitingAPI.GET("/manager/requisitions", routing.Policy(), m.handler.List)
+	recruitingAPI.GET("/manager/requisitions/:id", routing.Policy(), m.handler.Get)
+	recruitingAPI.POST("/manager/requisitions/:id/decision", routing.Policy(), m.handler.DecideHead)
+
+	recruitingAPI.GET("/finance/requisitions", routing.Policy(), m.handler.List)
+	recruitingAPI.GET("/finance/requisitions/:id", routing.Policy(), m.handler.Get)
+	recruitingAPI.POST("/finance/requisitions/:id/decision", routing.Policy(), m.handler.DecideFinance)
+
+	recruitingAPI.GET("/hr/requisitions", routing.Policy(), m.handler.List)
+	recruitingAPI.POST("/hr/requisitions", routing.Policy(), m.handler.Create)
+	recruitingAPI.GET("/hr/requisitions/:id", routing.Policy(), m.handler.Get)
+	recruitingAPI.PUT("/hr/requisitions/:id", routing.Policy(), m.handler.Update)
+	recruitingAPI.POST("/hr/requisitions/:id/cancel", routing.Policy(), m.handler.Cancel)
+	recruitingAPI.POST("/hr/requisitions/:id/hires", routing.Policy(), m.handler.Hire)
+}
@@ -0,0 +1,560 @@
+// prometheus/backend/internal/requisition/service.go
+package requisition
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/position"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// financeRole approves the budget of requisitions.
+const financeRole = "finance"
+
+var (
+	// ErrInvalidRequisition is returned for requisitions and hires that fail validation.
+	ErrInvalidRequisition = errors.New("invalid requisition")
+	// ErrStatus is returned when a requisition can't be changed in its current status, e.g. hiring against
+	// one that isn't approved.
+	ErrStatus = errors.New("the requisition can't be changed in its current status")
+	// ErrNoOpenings is returned when the position has fewer vacant seats than requested that other
+	// requisitions don't already recruit for.
+	ErrNoOpenings = errors.New("the position has no vacant seats left to recruit for")
+	// ErrNotApprover is returned when the caller may not decide a step: finance steps need the finance
+	// role, head steps the head of the position's division.
+	ErrNotApprover = errors.New("you may not decide this step of the requisition")
+	// ErrDecided is returned when deciding a step that was already decided.
+	ErrDecided = errors.New("this step of the requisition was already decided")
+)
+
+// Viewer is who lists or fetches requisitions: HR and finance see all of them, division heads those of
+// the divisions they head.
+type Viewer struct {
+	UserID  uint
+	HR      bool
+	Finance bool
+}
+
+// Service manages requisitions: requests to recruit for vacant seats of budgeted positions, approved by
+// finance and the division head before recruiting starts, and the hires made against them. orgID scopes
+// every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	// Requisitions lists the requisitions viewer may see, newest first.
+	Requisitions(orgID *uint, viewer Viewer, filter Filter) ([]Requisition, error)
+	// Get returns a requisition with its approvals and hires, if viewer may see it.
+	Get(orgID *uint, viewer Viewer, id uint) (*Requisition, error)
+	// Create requests approval to recruit for vacant seats of an active position, notifying the approvers.
+	Create(actor audit.Actor, orgID *uint, req Request) (*Requisition, error)
+	// Update replaces a pending requisition's fields. Decisions already made are reset and the approvers
+	// notified again.
+	Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Requisition, error)
+	// Cancel withdraws a pending or approved requisition. Hires already made stay linked.
+	Cancel(actor audit.Actor, orgID *uint, id, expectedVersion uint) (*Requisition, error)
+	// Decide approves or rejects a step of a pending requisition. Rejecting a step rejects the requisition;
+	// approving the last one approves it.
+	Decide(actor audit.Actor, orgID *uint, id uint, step Step, req DecisionRequest) (*Requisition, error)
+
+	// Hire links an employee hired against an approved requisition and places them in its position. The
+	// requisition is filled with its last opening.
+	Hire(actor audit.Actor, orgID *uint, id uint, req HireRequest) (*Requisition, error)
+	// HireTx is Hire within tx, for hires made as part of a larger change such as hiring a candidate.
+	HireTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, req HireRequest) error
+	// ApprovedTx returns a requisition locked until tx ends if it is approved, or ErrStatus. Job postings
+	// check it before going live.
+	ApprovedTx(tx *gorm.DB, orgID *uint, id uint) (*Requisition, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	positions position.Service
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Hires are placed in positions through positions; their
+// names are resolved through employees.
+func NewService(db *gorm.DB, positions position.Service, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, positions: positions, employees: employees, auditor: auditor}
+}
+
+func (s *service) Requisitions(orgID *uint, viewer Viewer, filter Filter) ([]Requisition, error) {
+	query := s.visible(utils.OrgScope(s.db, orgID), viewer)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.PositionID != nil {
+		query = query.Where("position_id = ?", *filter.PositionID)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	requisitions := []Requisition{}
+	if err := query.Preload("Approvals", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("created_at DESC, id DESC").Find(&requisitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list requisitions: %w", err)
+	}
+	if err := s.describe(orgID, requisitions); err != nil {
+		return nil, err
+	}
+	return requisitions, nil
+}
+
+func (s *service) Get(orgID *uint, viewer Viewer, id uint) (*Requisition, error) {
+	var requisition Requisition
+	if err := s.visible(utils.OrgScope(s.db, orgID), viewer).
+		Preload("Approvals", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Hires", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&requisition, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	requisitions := []Requisition{requisition}
+	if err := s.describe(orgID, requisitions); err != nil {
+		return nil, err
+	}
+	return &requisitions[0], nil
+}
+
+func (s *service) Create(actor audit.Actor, orgID *uint, req Request) (*Requisition, error) {
+	requisition := Requisition{OrganizationID: orgID, Status: StatusPending, RequestedBy: actor.UserID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, orgID, &requisition, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&requisition).Error; err != nil {
+			return fmt.Errorf("failed to create requisition: %w", err)
+		}
+		if err := s.requestApprovals(tx, actor, &requisition); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "requisition.create", EntityType: "requisition", EntityID: fmt.Sprintf("%d", requisition.ID), After: requisition,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, requisition.ID)
+}
+
+func (s *service) Update(actor audit.Actor, orgID *uint, id, expectedVersion uint, req Request) (*Requisition, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockRequisition(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusPending {
+			return ErrStatus
+		}
+		after := *before
+		if err := s.apply(tx, orgID, &after, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Requisition{}, id, expectedVersion, map[string]interface{}{
+			"position_id": after.PositionID, "division_id": after.DivisionID, "openings": after.Openings,
+			"justification": after.Justification, "budget_amount": after.BudgetAmount, "budget_currency": after.BudgetCurrency,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Where("requisition_id = ?", id).Delete(&Approval{}).Error; err != nil {
+			return fmt.Errorf("failed to reset requisition approvals: %w", err)
+		}
+		if err := s.requestApprovals(tx, actor, &after); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "requisition.update", EntityType: "requisition", EntityID: fmt.Sprintf("%d", id), Before: before, After: after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) Cancel(actor audit.Actor, orgID *uint, id, expectedVersion uint) (*Requisition, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		requisition, err := lockRequisition(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if requisition.Status != StatusPending && requisition.Status != StatusApproved {
+			return ErrStatus
+		}
+		if err := utils.UpdateWithVersion(tx, &Requisition{}, id, expectedVersion, map[string]interface{}{
+			"status": StatusCancelled,
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "requisition.cancel", EntityType: "requisition", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": requisition.Status}, After: map[string]interface{}{"status": StatusCancelled},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) Decide(actor audit.Actor, orgID *uint, id uint, step Step, req DecisionRequest) (*Requisition, error) {
+	decision := DecisionRejected
+	if req.Approve {
+		decision = DecisionApproved
+	} else if strings.TrimSpace(req.Note) == "" {
+		return nil, fmt.Errorf("%w: say why the requisition is rejected", ErrInvalidRequisition)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		requisition, err := lockRequisition(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkApprover(tx, requisition, step, actor.UserID); err != nil {
+			return err
+		}
+		if requisition.Status != StatusPending {
+			return ErrStatus
+		}
+		var approvals []Approval
+		if err := tx.Where("requisition_id = ?", id).Find(&approvals).Error; err != nil {
+			return fmt.Errorf("failed to load requisition approvals: %w", err)
+		}
+		i := slices.IndexFunc(approvals, func(a Approval) bool { return a.Step == step })
+		if i < 0 {
+			return fmt.Errorf("requisition %d has no %s step", id, step)
+		}
+		if approvals[i].Decision != DecisionPending {
+			return ErrDecided
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Approval{}).Where("id = ?", approvals[i].ID).Updates(map[string]interface{}{
+			"decision": decision, "decided_by": actor.UserID, "decided_at": now, "note": strings.TrimSpace(req.Note),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record decision: %w", err)
+		}
+		approvals[i].Decision = decision
+		status := StatusPending
+		if decision == DecisionRejected {
+			status = StatusRejected
+		} else if !slices.ContainsFunc(approvals, func(a Approval) bool { return a.Decision != DecisionApproved }) {
+			status = StatusApproved
+		}
+		if status != StatusPending {
+			// Bumping the version makes HR edits that raced the last decision fail with a conflict.
+			if err := utils.UpdateWithVersion(tx, &Requisition{}, id, requisition.Version, map[string]interface{}{
+				"status": status, "decided_at": now,
+			}); err != nil {
+				return err
+			}
+			if err := s.notifyDecided(tx, requisition, status, req.Note); err != nil {
+				return err
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "requisition.decide", EntityType: "requisition", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": requisition.Status},
+			After:  map[string]interface{}{"status": status, "step": step, "decision": decision, "note": req.Note},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) Hire(actor audit.Actor, orgID *uint, id uint, req HireRequest) (*Requisition, error) {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.HireTx(tx, actor, orgID, id, req)
+	}); err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) HireTx(tx *gorm.DB, actor audit.Actor, orgID *uint, id uint, req HireRequest) error {
+	requisition, err := s.ApprovedTx(tx, orgID, id)
+	if err != nil {
+		return err
+	}
+	var hires, previous int64
+	if err := tx.Model(&Hire{}).Where("requisition_id = ?", id).Count(&hires).Error; err != nil {
+		return fmt.Errorf("failed to count requisition hires: %w", err)
+	}
+	if err := tx.Model(&Hire{}).Where("employee_id = ?", req.EmployeeID).Count(&previous).Error; err != nil {
+		return fmt.Errorf("failed to check requisition hires: %w", err)
+	}
+	if previous > 0 {
+		return fmt.Errorf("%w: employee %d was already hired against a requisition", ErrInvalidRequisition, req.EmployeeID)
+	}
+	if err := s.positions.PlaceTx(tx, actor, orgID, requisition.PositionID, position.HolderRequest{
+		EmployeeID: req.EmployeeID, StartOn: req.StartOn,
+	}); err != nil {
+		return err
+	}
+	hire := Hire{RequisitionID: id, EmployeeID: req.EmployeeID, HiredBy: actor.UserID}
+	if err := tx.Create(&hire).Error; err != nil {
+		return fmt.Errorf("failed to link hire: %w", err)
+	}
+	if int(hires)+1 >= requisition.Openings {
+		if err := utils.UpdateWithVersion(tx, &Requisition{}, id, requisition.Version, map[string]interface{}{
+			"status": StatusFilled,
+		}); err != nil {
+			return err
+		}
+	}
+	return s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "requisition.hire", EntityType: "requisition", EntityID: fmt.Sprintf("%d", id), After: hire,
+	})
+}
+
+func (s *service) ApprovedTx(tx *gorm.DB, orgID *uint, id uint) (*Requisition, error) {
+	requisition, err := lockRequisition(tx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if requisition.Status != StatusApproved {
+		return nil, ErrStatus
+	}
+	return requisition, nil
+}
+
+// apply validates req and copies it onto requisition. The position's vacant seats must cover the
+// openings on top of those other open requisitions still recruit for; the position stays locked until
+// tx ends so concurrent requisitions can't overbook it.
+func (s *service) apply(tx *gorm.DB, orgID *uint, requisition *Requisition, req Request) error {
+	openings := req.Openings
+	if openings == 0 {
+		openings = 1
+	}
+	if (req.BudgetAmount == nil) != (req.BudgetCurrency == "") {
+		return fmt.Errorf("%w: budget_amount and budget_currency go together", ErrInvalidRequisition)
+	}
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" {
+		return fmt.Errorf("%w: the justification must not be blank", ErrInvalidRequisition)
+	}
+	vacancies, err := s.positions.VacanciesTx(tx, orgID, req.PositionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: position %d not found", ErrInvalidRequisition, req.PositionID)
+	} else if err != nil {
+		return err
+	}
+	var reserved int64
+	if err := tx.Model(&Requisition{}).
+		Select("COALESCE(SUM(requisitions.openings - (SELECT COUNT(*) FROM requisition_hires WHERE requisition_hires.requisition_id = requisitions.id)), 0)").
+		Where("position_id = ? AND status IN ? AND id <> ?", req.PositionID, []Status{StatusPending, StatusApproved}, requisition.ID).
+		Scan(&reserved).Error; err != nil {
+		return fmt.Errorf("failed to count open requisitions: %w", err)
+	}
+	if openings > vacancies-int(reserved) {
+		return ErrNoOpenings
+	}
+	summary, err := s.positions.GetPosition(orgID, req.PositionID)
+	if err != nil {
+		return err
+	}
+	requisition.PositionID = req.PositionID
+	requisition.DivisionID = summary.DivisionID
+	requisition.Openings = openings
+	requisition.Justification = justification
+	requisition.BudgetAmount, requisition.BudgetCurrency = req.BudgetAmount, strings.ToUpper(req.BudgetCurrency)
+	if req.BudgetAmount == nil {
+		requisition.BudgetAmount, requisition.BudgetCurrency = summary.BudgetAmount, summary.BudgetCurrency
+	}
+	return nil
+}
+
+// requestApprovals creates the pending steps of a requisition and notifies who may decide them.
+func (s *service) requestApprovals(tx *gorm.DB, actor audit.Actor, requisition *Requisition) error {
+	head, err := headOf(tx, requisition.DivisionID)
+	if err != nil {
+		return err
+	}
+	if head == nil {
+		return fmt.Errorf("%w: the position's division has no head to approve it", ErrInvalidRequisition)
+	}
+	approvals := make([]Approval, len(Steps))
+	for i, step := range Steps {
+		approvals[i] = Approval{RequisitionID: requisition.ID, Step: step, Decision: DecisionPending}
+	}
+	if err := tx.Create(&approvals).Error; err != nil {
+		return fmt.Errorf("failed to create requisition approvals: %w", err)
+	}
+	finance, err := financeUsers(tx, requisition.OrganizationID)
+	if err != nil {
+		return err
+	}
+	title, err := positionTitle(tx, requisition.PositionID)
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("%s requests %d hire(s) as %s", actor.Username, requisition.Openings, title)
+	notice := func(userID uint, path, kind string) notification.Notice {
+		return notification.Notice{
+			UserID:         userID,
+			OrganizationID: requisition.OrganizationID,
+			Category:       "approval.requisition",
+			Subject:        subject,
+			Body:           requisition.Justification,
+			Link:           fmt.Sprintf("%s/%d", path, requisition.ID),
+			ApprovalKind:   kind,
+			ApprovalID:     requisition.ID,
+		}
+	}
+	notices := []notification.Notice{notice(*head, "/manager/requisitions", HeadApprovalKind)}
+	for _, id := range finance {
+		notices = append(notices, notice(id, "/finance/requisitions", FinanceApprovalKind))
+	}
+	return notification.CreateTx(tx, notices...)
+}
+
+// notifyDecided tells whoever requested a requisition that it was approved or rejected.
+func (s *service) notifyDecided(tx *gorm.DB, requisition *Requisition, status Status, note string) error {
+	if requisition.RequestedBy == nil {
+		return nil
+	}
+	title, err := positionTitle(tx, requisition.PositionID)
+	if err != nil {
+		return err
+	}
+	return notification.CreateTx(tx, notification.Notice{
+		UserID:         *requisition.RequestedBy,
+		OrganizationID: requisition.OrganizationID,
+		Category:       "requisition.decision",
+		Subject:        fmt.Sprintf("The requisition for %s was %s", title, status),
+		Body:           note,
+		Link:           fmt.Sprintf("/hr/requisitions/%d", requisition.ID),
+	})
+}
+
+// checkApprover checks that the user may decide a step of the requisition.
+func (s *service) checkApprover(tx *gorm.DB, requisition *Requisition, step Step, userID *uint) error {
+	if userID == nil {
+		return ErrNotApprover
+	}
+	switch step {
+	case StepFinance:
+		finance, err := financeUsers(tx, requisition.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(finance, *userID) {
+			return ErrNotApprover
+		}
+	case StepHead:
+		head, err := headOf(tx, requisition.DivisionID)
+		if err != nil {
+			return err
+		}
+		if head == nil || *head != *userID {
+			return ErrNotApprover
+		}
+	default:
+		return ErrNotApprover
+	}
+	return nil
+}
+
+// visible narrows query to the requisitions viewer may see.
+func (s *service) visible(query *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.HR || viewer.Finance {
+		return query
+	}
+	return query.Where(`division_id IN (SELECT divisions.id FROM divisions JOIN employees ON employees.id = divisions.head_id
+		WHERE employees.user_id = ? AND divisions.deleted_at IS NULL AND employees.deleted_at IS NULL)`, viewer.UserID)
+}
+
+// describe fills in the position titles and the names of hires.
+func (s *service) describe(orgID *uint, requisitions []Requisition) error {
+	var positionIDs, employeeIDs []uint
+	for _, r := range requisitions {
+		positionIDs = append(positionIDs, r.PositionID)
+		for _, h := range r.Hires {
+			employeeIDs = append(employeeIDs, h.EmployeeID)
+		}
+	}
+	if len(requisitions) == 0 {
+		return nil
+	}
+	var positions []position.Position
+	if err := s.db.Select("id", "title").Where("id IN ?", positionIDs).Find(&positions).Error; err != nil {
+		return fmt.Errorf("failed to load positions: %w", err)
+	}
+	titles := make(map[uint]string, len(positions))
+	for _, p := range positions {
+		titles[p.ID] = p.Title
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, employeeIDs)
+	if err != nil {
+		return err
+	}
+	for i := range requisitions {
+		requisitions[i].Position = titles[requisitions[i].PositionID]
+		for j := range requisitions[i].Hires {
+			requisitions[i].Hires[j].DisplayName = names[requisitions[i].Hires[j].EmployeeID].Text
+		}
+	}
+	return nil
+}
+
+// financeUsers returns the active users of the organization holding the finance role.
+func financeUsers(tx *gorm.DB, orgID *uint) ([]uint, error) {
+	query := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", financeRole).
+		Where("user_roles.expires_at IS NULL OR user_roles.expires_at > ?", clock.Now().UTC())
+	if orgID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	var ids []uint
+	if err := query.Pluck("users.id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find finance users: %w", err)
+	}
+	return ids, nil
+}
+
+// headOf returns the user heading a division, or nil when it has no head with a user account.
+func headOf(tx *gorm.DB, divisionID uint) (*uint, error) {
+	// Queried by table name: divisions are soft-deleted, and only their head matters here.
+	var ids []uint
+	if err := tx.Table("divisions").Joins("JOIN employees ON employees.id = divisions.head_id").
+		Where("divisions.id = ? AND divisions.deleted_at IS NULL AND employees.deleted_at IS NULL AND employees.user_id IS NOT NULL", divisionID).
+		Pluck("employees.user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the head of division %d: %w", divisionID, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+// positionTitle returns the title of a position.
+func positionTitle(tx *gorm.DB, id uint) (string, error) {
+	var p position.Position
+	if err := tx.Select("id", "title").First(&p, id).Error; err != nil {
+		return "", fmt.Errorf("failed to load position %d: %w", id, err)
+	}
+	return p.Title, nil
+}
+
+// lockRequisition loads a requisition of the organization for update.
+func lockRequisition(tx *gorm.DB, orgID *uint, id uint) (*Requisition, error) {
+	var requisition Requisition
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&requisition, id).Error; err != nil {
+		return nil, err
+	}
+	return &requisition, nil
+}
@@ -0,0 +1,320 @@
+// prometheus/backend/internal/resignation/handler.go
+package resignation
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/offboarding"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hr is the viewer of /hr routes, which see every resignation of the organization.
+var hr = Viewer{HR: true}
+
+// Handler handles HTTP requests for resignations and exit interviews.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Mine returns the caller's resignation with their exit interview.
+// @Summary Get my resignation
+// @Tags Resignations
+// @Produce json
+// @Success 200 {object} Resignation
+// @Failure 404 {object} utils.ErrorResponse "No resignation"
+// @Router /me/resignation [get]
+func (h *Handler) Mine(c *gin.Context) {
+	resignation, err := h.service.Mine(utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Resignation fetched successfully", resignation)
+}
+
+// Submit gives the caller's notice.
+// @Summary Resign
+// @Description The notice date is today. The line manager is asked to acknowledge the resignation, then HR,
+// @Description who agrees the last working day; employees without a manager go straight to HR.
+// @Tags Resignations
+// @Accept json
+// @Produce json
+// @Param resignation body Request true "Resignation"
+// @Success 201 {object} Resignation
+// @Failure 400 {object} utils.ErrorResponse "Invalid resignation or last day in the past"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Failure 409 {object} utils.ErrorResponse "Already resigned"
+// @Router /me/resignation [post]
+func (h *Handler) Submit(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	resignation, err := h.service.Submit(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"), req)
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Resignation submitted successfully", resignation)
+}
+
+// Withdraw takes back the caller's resignation.
+// @Summary Withdraw my resignation
+// @Description Only while HR hasn't acknowledged it.
+// @Tags Resignations
+// @Produce json
+// @Success 200 {object} Resignation
+// @Failure 404 {object} utils.ErrorResponse "No resignation"
+// @Failure 409 {object} utils.ErrorResponse "Already acknowledged by HR"
+// @Router /me/resignation/withdraw [post]
+func (h *Handler) Withdraw(c *gin.Context) {
+	resignation, err := h.service.Withdraw(audit.ActorFromContext(c), utils.OrganizationFromContext(c), c.GetUint("userID"))
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Resignation withdrawn successfully", resignation)
+}
+
+// SaveMyExitInterview answers the exit interview of the caller's resignation.
+// @Summary Answer my exit interview
+// @Description Answers replace earlier ones. Only HR and the employee see them.
+// @Tags Resignations
+// @Accept json
+// @Produce json
+// @Param interview body ExitInterviewRequest true "Answers"
+// @Success 200 {object} Resignation
+// @Failure 400 {object} utils.ErrorResponse "Invalid answers"
+// @Failure 404 {object} utils.ErrorResponse "No resignation"
+// @Router /me/resignation/exit-interview [put]
+func (h *Handler) SaveMyExitInterview(c *gin.Context) {
+	var req ExitInterviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	mine, err := h.service.Mine(orgID, c.GetUint("userID"))
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	if _, err := h.service.SaveExitInterview(audit.ActorFromContext(c), orgID, mine.ID, req); err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	resignation, err := h.service.Mine(orgID, c.GetUint("userID"))
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Exit interview saved successfully", resignation)
+}
+
+// List returns the organization's resignations, latest notice first.
+// @Summary List resignations
+// @Tags Resignations
+// @Produce json
+// @Param status query string false "Status" Enums(pending_manager, pending_hr, acknowledged, withdrawn)
+// @Param employee_id query int false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/resignations [get]
+func (h *Handler) List(c *gin.Context) {
+	h.list(c, hr)
+}
+
+// TeamList returns the resignations of the caller's direct reports.
+// @Summary List my reports' resignations
+// @Tags Resignations
+// @Produce json
+// @Param status query string false "Status" Enums(pending_manager, pending_hr, acknowledged, withdrawn)
+// @Param employee_id query int false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/resignations [get]
+func (h *Handler) TeamList(c *gin.Context) {
+	h.list(c, team(c))
+}
+
+func (h *Handler) list(c *gin.Context, viewer Viewer) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusPendingManager, StatusPendingHR, StatusAcknowledged, StatusWithdrawn:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid employee_id parameter")
+			return
+		}
+		employeeID := uint(id)
+		filter.EmployeeID = &employeeID
+	}
+	page := utils.ParsePagination(c)
+	resignations, total, err := h.service.List(utils.OrganizationFromContext(c), viewer, filter, page)
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Resignations fetched successfully", page.Response(resignations, total))
+}
+
+// Get returns a resignation with its exit interview.
+// @Summary Get a resignation
+// @Tags Resignations
+// @Produce json
+// @Param id path int true "Resignation ID"
+// @Success 200 {object} Resignation
+// @Failure 404 {object} utils.ErrorResponse "Resignation not found"
+// @Router /hr/resignations/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	h.get(c, hr)
+}
+
+// TeamGet returns a resignation of one of the caller's direct reports, without its exit interview.
+// @Summary Get my report's resignation
+// @Tags Resignations
+// @Produce json
+// @Param id path int true "Resignation ID"
+// @Success 200 {object} Resignation
+// @Failure 404 {object} utils.ErrorResponse "Resignation not found"
+// @Router /manager/resignations/{id} [get]
+func (h *Handler) TeamGet(c *gin.Context) {
+	h.get(c, team(c))
+}
+
+func (h *Handler) get(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	resignation, err := h.service.Get(utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Resignation fetched successfully", resignation)
+}
+
+// Acknowledge records HR's acknowledgment of a resignation.
+// @Summary Acknowledge a resignation as HR
+// @Description Agrees the last working day, the proposed one unless given, whether or not the manager
+// @Description acknowledged the resignation. With schedule_offboarding, the employee's offboarding is scheduled
+// @Description for that day. The employee is notified.
+// @Tags Resignations
+// @Accept json
+// @Produce json
+// @Param id path int true "Resignation ID"
+// @Param acknowledgment body AcknowledgeRequest false "Acknowledgment"
+// @Success 200 {object} Resignation
+// @Failure 400 {object} utils.ErrorResponse "Invalid last day or own resignation"
+// @Failure 404 {object} utils.ErrorResponse "Resignation not found"
+// @Failure 409 {object} utils.ErrorResponse "Already acknowledged or withdrawn, or the employee already has an offboarding"
+// @Router /hr/resignations/{id}/acknowledge [post]
+func (h *Handler) Acknowledge(c *gin.Context) {
+	h.acknowledge(c, Viewer{UserID: c.GetUint("userID"), HR: true})
+}
+
+// TeamAcknowledge records the line manager's acknowledgment of a direct report's resignation.
+// @Summary Acknowledge my report's resignation
+// @Description Hands the resignation to HR, who are notified.
+// @Tags Resignations
+// @Accept json
+// @Produce json
+// @Param id path int true "Resignation ID"
+// @Param acknowledgment body AcknowledgeRequest false "Note; the last day and offboarding are up to HR"
+// @Success 200 {object} Resignation
+// @Failure 404 {object} utils.ErrorResponse "Resignation not found"
+// @Failure 409 {object} utils.ErrorResponse "Not waiting for the manager"
+// @Router /manager/resignations/{id}/acknowledge [post]
+func (h *Handler) TeamAcknowledge(c *gin.Context) {
+	h.acknowledge(c, team(c))
+}
+
+func (h *Handler) acknowledge(c *gin.Context, viewer Viewer) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AcknowledgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	resignation, err := h.service.Acknowledge(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer, id, req)
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Resignation acknowledged successfully", resignation)
+}
+
+// SaveExitInterview records the answers of an exit interview held by HR.
+// @Summary Record an exit interview
+// @Description Answers replace earlier ones, including the employee's own.
+// @Tags Resignations
+// @Accept json
+// @Produce json
+// @Param id path int true "Resignation ID"
+// @Param interview body ExitInterviewRequest true "Answers"
+// @Success 200 {object} Resignation
+// @Failure 400 {object} utils.ErrorResponse "Invalid answers"
+// @Failure 404 {object} utils.ErrorResponse "Resignation not found"
+// @Failure 409 {object} utils.ErrorResponse "Resignation withdrawn"
+// @Router /hr/resignations/{id}/exit-interview [put]
+func (h *Handler) SaveExitInterview(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ExitInterviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	resignation, err := h.service.SaveExitInterview(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendResignationError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Exit interview saved successfully", resignation)
+}
+
+// team is the viewer of /manager routes, which see their direct reports' resignations.
+func team(c *gin.Context) Viewer {
+	return Viewer{UserID: c.GetUint("userID")}
+}
+
+func sendResignationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidResignation), errors.Is(err, offboarding.ErrInvalidOffboarding):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrStatus), errors.Is(err, ErrAlreadyResigned), errors.Is(err, offboarding.ErrAlreadyOffboarding):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
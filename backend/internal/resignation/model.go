@@ -0,0 +1,120 @@
+// prometheus/backend/internal/resignation/model.go
+package resignation
+
+import (
+	"time"
+)
+
+// Status is where a resignation is in its acknowledgment.
+type Status string
+
+const (
+	StatusPendingManager Status = "pending_manager" // Waiting for the line manager to acknowledge it
+	StatusPendingHR      Status = "pending_hr"      // Waiting for HR, directly for employees without a manager
+	StatusAcknowledged   Status = "acknowledged"    // HR agreed the last working day
+	StatusWithdrawn      Status = "withdrawn"       // Taken back by the employee before HR acknowledged it
+)
+
+// LeavingReason is the main reason an employee gives for leaving in the exit interview.
+type LeavingReason string
+
+const (
+	ReasonCompensation    LeavingReason = "compensation"
+	ReasonCareerGrowth    LeavingReason = "career_growth"
+	ReasonManagement      LeavingReason = "management"
+	ReasonWorkLifeBalance LeavingReason = "work_life_balance"
+	ReasonCulture         LeavingReason = "culture"
+	ReasonRole            LeavingReason = "role"
+	ReasonRelocation      LeavingReason = "relocation"
+	ReasonPersonal        LeavingReason = "personal"
+	ReasonOther           LeavingReason = "other"
+)
+
+// Resignation is an employee's notice that they are leaving. The line manager, then HR acknowledge it; HR
+// agrees the last working day, and may schedule the offboarding with it. An employee has at most one
+// resignation that isn't withdrawn.
+type Resignation struct {
+	ID               uint           `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID   *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID       uint           `gorm:"not null;index" json:"employee_id" example:"12"`
+	DisplayName      string         `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	UserID           uint           `gorm:"not null;index" json:"user_id" example:"7"`
+	NoticeDate       time.Time      `gorm:"type:date;not null" json:"notice_date" example:"2026-10-16T00:00:00Z"`        // When the notice was given
+	RequestedLastDay time.Time      `gorm:"type:date;not null" json:"requested_last_day" example:"2026-11-30T00:00:00Z"` // Proposed by the employee
+	LastDay          *time.Time     `gorm:"type:date;index" json:"last_day,omitempty" example:"2026-11-30T00:00:00Z"`    // Agreed by HR
+	Reason           string         `gorm:"type:varchar(2000)" json:"reason,omitempty" example:"Moving abroad"`
+	Status           Status         `gorm:"type:varchar(20);not null;index" json:"status" example:"pending_manager"`
+	ManagerAckAt     *time.Time     `json:"manager_acknowledged_at,omitempty"`
+	ManagerAckBy     *uint          `json:"manager_acknowledged_by,omitempty" example:"5"` // User ID
+	ManagerNote      string         `gorm:"type:varchar(1000)" json:"manager_note,omitempty"`
+	HRAckAt          *time.Time     `json:"hr_acknowledged_at,omitempty"`
+	HRAckBy          *uint          `json:"hr_acknowledged_by,omitempty" example:"4"` // User ID
+	HRNote           string         `gorm:"type:varchar(1000)" json:"hr_note,omitempty"`
+	OffboardingID    *uint          `json:"offboarding_id,omitempty" example:"9"` // Scheduled on HR acknowledgment
+	WithdrawnAt      *time.Time     `json:"withdrawn_at,omitempty"`
+	ExitInterview    *ExitInterview `gorm:"foreignKey:ResignationID" json:"exit_interview,omitempty"` // Only shown to HR and the employee
+	Version          uint           `gorm:"default:1;not null" json:"version" example:"1"`            // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// ExitInterview is the structured questionnaire of a resignation, answered by the employee or recorded by
+// HR after an interview. Ratings go from 1 (poor) to 5 (excellent).
+type ExitInterview struct {
+	ID                 uint          `gorm:"primaryKey" json:"id" example:"3"`
+	ResignationID      uint          `gorm:"not null;uniqueIndex" json:"resignation_id" example:"4"`
+	PrimaryReason      LeavingReason `gorm:"type:varchar(30);not null;index" json:"primary_reason" example:"career_growth"`
+	RoleRating         int           `gorm:"not null" json:"role_rating" example:"4"`
+	ManagerRating      int           `gorm:"not null" json:"manager_rating" example:"3"`
+	GrowthRating       int           `gorm:"not null" json:"growth_rating" example:"2"`
+	CompensationRating int           `gorm:"not null" json:"compensation_rating" example:"3"`
+	WorkLifeRating     int           `gorm:"not null" json:"work_life_rating" example:"4"`
+	CultureRating      int           `gorm:"not null" json:"culture_rating" example:"4"`
+	WouldRecommend     bool          `json:"would_recommend" example:"true"`
+	WouldReturn        bool          `json:"would_return" example:"true"`
+	Liked              string        `gorm:"type:varchar(2000)" json:"liked,omitempty" example:"The team and the product"`
+	Improve            string        `gorm:"type:varchar(2000)" json:"improve,omitempty" example:"Clearer promotion criteria"`
+	Comments           string        `gorm:"type:varchar(4000)" json:"comments,omitempty"`
+	RecordedBy         *uint         `json:"recorded_by,omitempty" example:"7"` // User ID: the employee, or HR transcribing an interview
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// TableName keeps exit interviews next to resignations.
+func (ExitInterview) TableName() string { return "resignation_exit_interviews" }
+
+// Request submits a resignation.
+type Request struct {
+	LastDay string `json:"last_day" binding:"required,datetime=2006-01-02" example:"2026-11-30"` // Proposed last working day
+	Reason  string `json:"reason,omitempty" binding:"max=2000" example:"Moving abroad"`
+}
+
+// AcknowledgeRequest acknowledges a resignation. HR may agree another last working day than the one
+// proposed, and schedule the offboarding.
+type AcknowledgeRequest struct {
+	Note                string `json:"note,omitempty" binding:"max=1000" example:"Handover plan agreed in our 1:1"`
+	LastDay             string `json:"last_day,omitempty" binding:"omitempty,datetime=2006-01-02" example:"2026-11-30"` // HR only; defaults to the proposed one
+	ScheduleOffboarding bool   `json:"schedule_offboarding,omitempty" example:"true"`                                   // HR only
+}
+
+// ExitInterviewRequest answers the exit interview.
+type ExitInterviewRequest struct {
+	PrimaryReason      LeavingReason `json:"primary_reason" binding:"required,oneof=compensation career_growth management work_life_balance culture role relocation personal other" example:"career_growth"`
+	RoleRating         int           `json:"role_rating" binding:"required,min=1,max=5" example:"4"`
+	ManagerRating      int           `json:"manager_rating" binding:"required,min=1,max=5" example:"3"`
+	GrowthRating       int           `json:"growth_rating" binding:"required,min=1,max=5" example:"2"`
+	CompensationRating int           `json:"compensation_rating" binding:"required,min=1,max=5" example:"3"`
+	WorkLifeRating     int           `json:"work_life_rating" binding:"required,min=1,max=5" example:"4"`
+	CultureRating      int           `json:"culture_rating" binding:"required,min=1,max=5" example:"4"`
+	WouldRecommend     bool          `json:"would_recommend" example:"true"`
+	WouldReturn        bool          `json:"would_return" example:"true"`
+	Liked              string        `json:"liked,omitempty" binding:"max=2000" example:"The team and the product"`
+	Improve            string        `json:"improve,omitempty" binding:"max=2000" example:"Clearer promotion criteria"`
+	Comments           string        `json:"comments,omitempty" binding:"max=4000"`
+}
+
+// Filter narrows a resignation listing.
+type Filter struct {
+	Status     Status
+	EmployeeID *uint
+}
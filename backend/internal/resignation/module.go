@@ -0,0 +1,47 @@
+// prometheus/backend/internal/resignation/module.go
+package resignation
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the resignations module.
+const ModuleName = "resignations"
+
+// resignationModule owns resignations and exit interviews.
+type resignationModule struct {
+	handler *Handler
+}
+
+// NewModule creates the resignations module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &resignationModule{handler: NewHandler(svc)}
+}
+
+func (m *resignationModule) Name() string { return ModuleName }
+
+func (m *resignationModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *resignationModule) Models() []any {
+	return []any{&Resignation{}, &ExitInterview{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Employees resign and answer their exit interview, managers
+// acknowledge their reports' resignations, and HR acknowledges them all and records exit interviews.
+func (m *resignationModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/resignation", routing.Authenticated(), m.handler.Mine)
+	api.POST("/me/resignation", routing.Authenticated(), m.handler.Submit)
+	api.POST("/me/resignation/withdraw", routing.Authenticated(), m.handler.Withdraw)
+	api.PUT("/me/resignation/exit-interview", routing.Authenticated(), m.handler.SaveMyExitInterview)
+
+	api.GET("/manager/resignations", routing.Policy(), m.handler.TeamList)
+	api.GET("/manager/resignations/:id", routing.Policy(), m.handler.TeamGet)
+	api.POST("/manager/resignations/:id/acknowledge", routing.Policy(), m.handler.TeamAcknowledge)
+
+	api.GET("/hr/resignations", routing.Policy(), m.handler.List)
+	api.GET("/hr/resignations/:id", routing.Policy(), m.handler.Get)
+	api.POST("/hr/resignations/:id/acknowledge", routing.Policy(), m.handler.Acknowledge)
+	api.PUT("/hr/resignations/:id/exit-interview", routing.Policy(), m.handler.SaveExitInterview)
+}
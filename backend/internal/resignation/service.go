@@ -0,0 +1,535 @@
+// prometheus/backend/internal/resignation/service.go
+package resignation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/offboarding"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// hrRole is the role acknowledging resignations after the line manager.
+const hrRole = "hr"
+
+var (
+	// ErrInvalidResignation is returned for resignations and exit interviews that fail validation.
+	ErrInvalidResignation = errors.New("invalid resignation")
+	// ErrAlreadyResigned is returned when an employee resigns again while a resignation is pending or they
+	// haven't left yet.
+	ErrAlreadyResigned = errors.New("you already resigned")
+	// ErrStatus is returned when acknowledging or withdrawing a resignation that isn't at that stage, or
+	// answering the exit interview of a withdrawn one.
+	ErrStatus = errors.New("the resignation can't be changed at this stage")
+	// ErrNoEmployee is returned when a user without an employee record resigns.
+	ErrNoEmployee = errors.New("you don't have an employee record")
+)
+
+// Viewer is who is asking: HR sees every resignation of the organization with its exit interview,
+// managers only their direct reports' resignations, without exit interviews.
+type Viewer struct {
+	UserID uint
+	HR     bool
+}
+
+// Service handles resignations: employees give notice, their line manager then HR acknowledge it, and HR
+// agrees the last working day. The exit interview is answered by the employee or recorded by HR, and only
+// shown to them. orgID scopes every call to one organization's employees and resignations
+// (nil = default organization).
+type Service interface {
+	List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Resignation, int64, error)
+	Get(orgID *uint, viewer Viewer, id uint) (*Resignation, error)
+	// Mine returns the user's latest resignation, not withdrawn; gorm.ErrRecordNotFound if there is none.
+	Mine(orgID *uint, userID uint) (*Resignation, error)
+	// Submit gives the user's notice, today, with the last working day they propose.
+	Submit(actor audit.Actor, orgID *uint, userID uint, req Request) (*Resignation, error)
+	// Withdraw takes back the user's resignation while HR hasn't acknowledged it.
+	Withdraw(actor audit.Actor, orgID *uint, userID uint) (*Resignation, error)
+	// Acknowledge records the line manager's acknowledgment, or HR's for an HR viewer, who may do so
+	// without waiting for the manager.
+	Acknowledge(actor audit.Actor, orgID *uint, viewer Viewer, id uint, req AcknowledgeRequest) (*Resignation, error)
+	// SaveExitInterview answers or replaces the exit interview of a resignation that isn't withdrawn.
+	SaveExitInterview(actor audit.Actor, orgID *uint, id uint, req ExitInterviewRequest) (*Resignation, error)
+	// UseOffboarding lets HR schedule the offboarding of a resigning employee when acknowledging.
+	UseOffboarding(offboardings offboarding.Service)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+
+	mu           sync.RWMutex
+	offboardings offboarding.Service
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) List(orgID *uint, viewer Viewer, filter Filter, page utils.Pagination) ([]Resignation, int64, error) {
+	query := visible(utils.OrgScope(s.db.Model(&Resignation{}), orgID), viewer)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count resignations: %w", err)
+	}
+	var resignations []Resignation
+	if err := query.Order("notice_date DESC, id DESC").Scopes(page.Scope).Find(&resignations).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list resignations: %w", err)
+	}
+	if err := s.named(orgID, resignations); err != nil {
+		return nil, 0, err
+	}
+	return resignations, total, nil
+}
+
+func (s *service) Get(orgID *uint, viewer Viewer, id uint) (*Resignation, error) {
+	query := visible(utils.OrgScope(s.db, orgID), viewer)
+	if viewer.HR {
+		query = query.Preload("ExitInterview")
+	}
+	var resignation Resignation
+	if err := query.First(&resignation, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	resignations := []Resignation{resignation}
+	if err := s.named(orgID, resignations); err != nil {
+		return nil, err
+	}
+	return &resignations[0], nil
+}
+
+func (s *service) Mine(orgID *uint, userID uint) (*Resignation, error) {
+	var resignation Resignation
+	if err := utils.OrgScope(s.db, orgID).Where("user_id = ? AND status <> ?", userID, StatusWithdrawn).
+		Preload("ExitInterview").Order("id DESC").First(&resignation).Error; err != nil {
+		return nil, err
+	}
+	return &resignation, nil
+}
+
+func (s *service) Submit(actor audit.Actor, orgID *uint, userID uint, req Request) (*Resignation, error) {
+	var ids []uint
+	if err := utils.OrgScope(s.db.Model(&employee.Employee{}), orgID).Where("user_id = ?", userID).Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find the employee record of user %d: %w", userID, err)
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoEmployee
+	}
+	subject, err := s.employees.Get(orgID, ids[0])
+	if err != nil {
+		return nil, err
+	}
+	lastDay, err := parseDate(req.LastDay, "last_day")
+	if err != nil {
+		return nil, err
+	}
+	if lastDay.Before(today()) {
+		return nil, fmt.Errorf("%w: the last day can't be in the past", ErrInvalidResignation)
+	}
+	resignation := Resignation{
+		OrganizationID:   orgID,
+		EmployeeID:       subject.ID,
+		UserID:           userID,
+		NoticeDate:       today(),
+		RequestedLastDay: lastDay,
+		Reason:           strings.TrimSpace(req.Reason),
+		Status:           StatusPendingManager,
+	}
+	if subject.ManagerID == nil {
+		resignation.Status = StatusPendingHR
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the employee serializes concurrent submissions.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&employee.Employee{}, subject.ID).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&Resignation{}).Where("employee_id = ?", subject.ID).
+			Where("status IN ? OR (status = ? AND last_day >= ?)", []Status{StatusPendingManager, StatusPendingHR}, StatusAcknowledged, today()).
+			Count(&open).Error; err != nil {
+			return fmt.Errorf("failed to check resignations of employee %d: %w", subject.ID, err)
+		}
+		if open > 0 {
+			return ErrAlreadyResigned
+		}
+		if err := tx.Create(&resignation).Error; err != nil {
+			return fmt.Errorf("failed to submit resignation: %w", err)
+		}
+		if err := s.notifyReviewers(tx, &resignation, subject, fmt.Sprintf("%s resigned", subject.DisplayName.Text),
+			fmt.Sprintf("Proposed last day: %s.", lastDay.Format("2006-01-02"))); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "resignation.submit", EntityType: "resignation", EntityID: fmt.Sprintf("%d", resignation.ID), After: resignation,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Mine(orgID, userID)
+}
+
+func (s *service) Withdraw(actor audit.Actor, orgID *uint, userID uint) (*Resignation, error) {
+	var withdrawn uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Resignation
+		if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).
+			Where("user_id = ? AND status <> ?", userID, StatusWithdrawn).Order("id DESC").First(&before).Error; err != nil {
+			return err
+		}
+		if before.Status != StatusPendingManager && before.Status != StatusPendingHR {
+			return ErrStatus
+		}
+		now := clock.Now().UTC()
+		if err := tx.Model(&Resignation{}).Where("id = ?", before.ID).Updates(map[string]interface{}{
+			"status":       StatusWithdrawn,
+			"withdrawn_at": now,
+			"version":      gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to withdraw resignation %d: %w", before.ID, err)
+		}
+		subject, err := s.employees.Get(orgID, before.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if err := s.notifyReviewers(tx, &before, subject, fmt.Sprintf("%s withdrew their resignation", subject.DisplayName.Text),
+			"No acknowledgment is needed anymore."); err != nil {
+			return err
+		}
+		withdrawn = before.ID
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "resignation.withdraw", EntityType: "resignation", EntityID: fmt.Sprintf("%d", before.ID), Before: before,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resignation Resignation
+	if err := s.db.First(&resignation, withdrawn).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload resignation %d: %w", withdrawn, err)
+	}
+	return &resignation, nil
+}
+
+func (s *service) Acknowledge(actor audit.Actor, orgID *uint, viewer Viewer, id uint, req AcknowledgeRequest) (*Resignation, error) {
+	if !viewer.HR {
+		return s.acknowledgeAsManager(actor, orgID, viewer, id, req)
+	}
+	current, err := s.Get(orgID, viewer, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.UserID == viewer.UserID {
+		return nil, fmt.Errorf("%w: you can't acknowledge your own resignation", ErrInvalidResignation)
+	}
+	if current.Status != StatusPendingManager && current.Status != StatusPendingHR {
+		return nil, ErrStatus
+	}
+	lastDay := current.RequestedLastDay
+	if req.LastDay != "" {
+		if lastDay, err = parseDate(req.LastDay, "last_day"); err != nil {
+			return nil, err
+		}
+	}
+	if lastDay.Before(current.NoticeDate) {
+		return nil, fmt.Errorf("%w: the last day can't be before the notice date", ErrInvalidResignation)
+	}
+
+	// The offboarding is scheduled in a transaction of its own, and cancelled again if the acknowledgment
+	// fails after it.
+	var scheduled *offboarding.Offboarding
+	if req.ScheduleOffboarding {
+		s.mu.RLock()
+		offboardings := s.offboardings
+		s.mu.RUnlock()
+		if offboardings == nil {
+			return nil, fmt.Errorf("%w: offboarding is disabled", ErrInvalidResignation)
+		}
+		scheduled, err = offboardings.Create(actor, orgID, current.EmployeeID, offboarding.Request{
+			TerminationDate: lastDay.Format("2006-01-02"),
+			Reason:          "Resignation",
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				if _, cancelErr := offboardings.Cancel(actor, orgID, scheduled.ID); cancelErr != nil {
+					log.Printf("Failed to cancel offboarding %d after failing to acknowledge resignation %d: %v", scheduled.ID, id, cancelErr)
+				}
+			}
+		}()
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockResignation(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != StatusPendingManager && before.Status != StatusPendingHR {
+			return ErrStatus
+		}
+		after := map[string]interface{}{
+			"status":    StatusAcknowledged,
+			"last_day":  lastDay,
+			"hr_ack_at": clock.Now().UTC(),
+			"hr_ack_by": actor.UserID,
+			"hr_note":   strings.TrimSpace(req.Note),
+		}
+		if scheduled != nil {
+			after["offboarding_id"] = scheduled.ID
+		}
+		if err := tx.Model(&Resignation{}).Where("id = ?", id).Updates(withVersionBump(after)).Error; err != nil {
+			return fmt.Errorf("failed to acknowledge resignation %d: %w", id, err)
+		}
+		if err := notification.CreateTx(tx, notification.Notice{
+			UserID:         before.UserID,
+			OrganizationID: before.OrganizationID,
+			Category:       "resignation.acknowledged",
+			Subject:        fmt.Sprintf("HR acknowledged your resignation; your last day is %s", lastDay.Format("2006-01-02")),
+			Body:           strings.TrimSpace(req.Note),
+			Link:           "/me/resignation",
+		}); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "resignation.acknowledge_hr", EntityType: "resignation", EntityID: fmt.Sprintf("%d", id), Before: before,
+			After: after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, viewer, id)
+}
+
+// acknowledgeAsManager records the line manager's acknowledgment and hands the resignation to HR.
+func (s *service) acknowledgeAsManager(actor audit.Actor, orgID *uint, viewer Viewer, id uint, req AcknowledgeRequest) (*Resignation, error) {
+	if req.LastDay != "" || req.ScheduleOffboarding {
+		return nil, fmt.Errorf("%w: only HR agrees the last day and schedules the offboarding", ErrInvalidResignation)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Resignation
+		if err := visible(utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID), viewer).First(&before, id).Error; err != nil {
+			return err
+		}
+		if before.Status != StatusPendingManager {
+			return ErrStatus
+		}
+		after := map[string]interface{}{
+			"status":         StatusPendingHR,
+			"manager_ack_at": clock.Now().UTC(),
+			"manager_ack_by": actor.UserID,
+			"manager_note":   strings.TrimSpace(req.Note),
+		}
+		if err := tx.Model(&Resignation{}).Where("id = ?", id).Updates(withVersionBump(after)).Error; err != nil {
+			return fmt.Errorf("failed to acknowledge resignation %d: %w", id, err)
+		}
+		subject, err := s.employees.Get(orgID, before.EmployeeID)
+		if err != nil {
+			return err
+		}
+		before.Status = StatusPendingHR
+		if err := s.notifyReviewers(tx, &before, subject, fmt.Sprintf("%s resigned", subject.DisplayName.Text),
+			fmt.Sprintf("Acknowledged by their manager. Proposed last day: %s.", before.RequestedLastDay.Format("2006-01-02"))); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "resignation.acknowledge_manager", EntityType: "resignation", EntityID: fmt.Sprintf("%d", id), Before: before,
+			After: after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, viewer, id)
+}
+
+// SaveExitInterview keeps the answers out of the audit trail, which managers may see.
+func (s *service) SaveExitInterview(actor audit.Actor, orgID *uint, id uint, req ExitInterviewRequest) (*Resignation, error) {
+	interview := ExitInterview{
+		ResignationID:      id,
+		PrimaryReason:      req.PrimaryReason,
+		RoleRating:         req.RoleRating,
+		ManagerRating:      req.ManagerRating,
+		GrowthRating:       req.GrowthRating,
+		CompensationRating: req.CompensationRating,
+		WorkLifeRating:     req.WorkLifeRating,
+		CultureRating:      req.CultureRating,
+		WouldRecommend:     req.WouldRecommend,
+		WouldReturn:        req.WouldReturn,
+		Liked:              strings.TrimSpace(req.Liked),
+		Improve:            strings.TrimSpace(req.Improve),
+		Comments:           strings.TrimSpace(req.Comments),
+		RecordedBy:         actor.UserID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		resignation, err := lockResignation(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if resignation.Status == StatusWithdrawn {
+			return ErrStatus
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "resignation_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"primary_reason", "role_rating", "manager_rating", "growth_rating",
+				"compensation_rating", "work_life_rating", "culture_rating", "would_recommend", "would_return", "liked",
+				"improve", "comments", "recorded_by", "updated_at"}),
+		}).Create(&interview).Error; err != nil {
+			return fmt.Errorf("failed to save the exit interview of resignation %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "resignation.exit_interview", EntityType: "resignation", EntityID: fmt.Sprintf("%d", id),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, Viewer{HR: true}, id)
+}
+
+func (s *service) UseOffboarding(offboardings offboarding.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offboardings = offboardings
+}
+
+// notifyReviewers tells whoever acknowledges the resignation at its current stage: the employee's line
+// manager, or HR.
+func (s *service) notifyReviewers(tx *gorm.DB, resignation *Resignation, subject *employee.Detail, title, body string) error {
+	var recipients []uint
+	link := fmt.Sprintf("/hr/resignations/%d", resignation.ID)
+	if resignation.Status == StatusPendingManager && subject.ManagerID != nil {
+		manager, err := s.employees.Get(resignation.OrganizationID, *subject.ManagerID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		recipients = []uint{manager.UserID}
+		link = fmt.Sprintf("/manager/resignations/%d", resignation.ID)
+	} else {
+		hr, err := hrUsers(tx, resignation.OrganizationID)
+		if err != nil {
+			return err
+		}
+		recipients = hr
+	}
+	notices := make([]notification.Notice, 0, len(recipients))
+	for _, id := range recipients {
+		if id == resignation.UserID {
+			continue
+		}
+		notices = append(notices, notification.Notice{
+			UserID:         id,
+			OrganizationID: resignation.OrganizationID,
+			Category:       "resignation.submitted",
+			Subject:        title,
+			Body:           body,
+			Link:           link,
+		})
+	}
+	return notification.CreateTx(tx, notices...)
+}
+
+// named fills in the display names of resignations' employees.
+func (s *service) named(orgID *uint, resignations []Resignation) error {
+	if len(resignations) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(resignations))
+	for i, r := range resignations {
+		ids[i] = r.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range resignations {
+		resignations[i].DisplayName = names[resignations[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// hrUsers returns the active users of the organization holding the HR role.
+func hrUsers(tx *gorm.DB, orgID *uint) ([]uint, error) {
+	query := tx.Model(&auth.User{}).Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.is_active", hrRole).
+		Where("user_roles.expires_at IS NULL OR user_roles.expires_at > ?", clock.Now().UTC())
+	if orgID == nil {
+		query = query.Where("users.organization_id IS NULL")
+	} else {
+		query = query.Where("users.organization_id = ?", *orgID)
+	}
+	var ids []uint
+	if err := query.Pluck("users.id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find HR users: %w", err)
+	}
+	return ids, nil
+}
+
+// withVersionBump returns the fields of an update with the version incremented, keeping fields as audited.
+func withVersionBump(fields map[string]interface{}) map[string]interface{} {
+	update := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		update[k] = v
+	}
+	update["version"] = gorm.Expr("version + 1")
+	return update
+}
+
+// parseDate parses a date field of a request.
+func parseDate(raw, field string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date", ErrInvalidResignation, field)
+	}
+	return date, nil
+}
+
+// visible restricts a query to the resignations the viewer may see: managers see their direct reports'.
+func visible(db *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.HR {
+		return db
+	}
+	return db.Where("employee_id IN (SELECT e.id FROM employees e JOIN employees m ON m.id = e.manager_id "+
+		"WHERE m.user_id = ? AND e.deleted_at IS NULL)", viewer.UserID)
+}
+
+// lockResignation loads a resignation for update.
+func lockResignation(tx *gorm.DB, orgID *uint, id uint) (*Resignation, error) {
+	var resignation Resignation
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&resignation, id).Error; err != nil {
+		return nil, err
+	}
+	return &resignation, nil
+}
+
+// today is the current UTC date. Notice is given on it.
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
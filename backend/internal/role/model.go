@@ -8,6 +8,7 @@ type Role struct {
 	gorm.Model
 	Name        string `gorm:"type:varchar(50);uniqueIndex;not null" json:"name" example:"admin"`
 	Description string `gorm:"type:varchar(255)" json:"description" example:"Administrator with full access"`
+	Version     uint   `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
 
-	// Users []auth.User `gorm:"foreignKey:RoleID"` // Example of a Has Many relationship if needed later
+	// Users []auth.User `gorm:"many2many:user_roles"` // Example of the reverse many-to-many relationship if needed later
 }
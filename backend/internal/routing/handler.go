@@ -0,0 +1,61 @@
+// prometheus/backend/internal/routing/handler.go
+package routing
+
+import (
+	"net/http"
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/utils"
+
+	"github.com/casbin/casbin/v2/util"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteAccess is a route together with the Casbin policies that currently govern it.
+type RouteAccess struct {
+	RouteInfo
+	Policies []authz.Policy `json:"policies,omitempty"` // Only for policy routes
+}
+
+// Handler exposes the route registry.
+type Handler struct {
+	registry *Registry
+}
+
+// NewHandler creates a new routing Handler.
+func NewHandler(registry *Registry) *Handler {
+	return &Handler{registry: registry}
+}
+
+// ListRoutes returns every registered endpoint with its access requirement.
+// @Summary List API routes and their access requirements
+// @Description For policy-protected routes, the matching policies of the requested domain are included (roles inherit them through role links).
+// @Tags Authorization
+// @Produce json
+// @Param domain query string false "Domain to resolve policies in (defaults to 'default')"
+// @Success 200 {array} RouteAccess
+// @Router /admin/routes [get]
+func (h *Handler) ListRoutes(c *gin.Context) {
+	domain := c.DefaultQuery("domain", authz.DefaultDomain)
+	rules, err := h.registry.enforcer.GetFilteredPolicy(1, domain)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to load policies: "+err.Error())
+		return
+	}
+
+	routes := h.registry.Routes()
+	result := make([]RouteAccess, 0, len(routes))
+	for _, route := range routes {
+		entry := RouteAccess{RouteInfo: route}
+		if route.Access == AccessPolicy {
+			for _, r := range rules {
+				policy, ok := authz.PolicyFromRule(r)
+				// Path parameters (":id") match keyMatch2 wildcards like any literal segment would.
+				if ok && util.KeyMatch2(route.Path, policy.Object) && (policy.Action == route.Method || policy.Action == "*") {
+					entry.Policies = append(entry.Policies, policy)
+				}
+			}
+		}
+		result = append(result, entry)
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Routes fetched successfully", result)
+}
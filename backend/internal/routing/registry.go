@@ -0,0 +1,210 @@
+// prometheus/backend/internal/routing/registry.go
+package routing
+
+import (
+	"net/http"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/middleware"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessKind is how a route is authorized.
+type AccessKind string
+
+const (
+	AccessPublic        AccessKind = "public"        // No authentication
+	AccessAuthenticated AccessKind = "authenticated" // Any valid JWT
+	AccessRoles         AccessKind = "roles"         // Hardcoded role gate (RBACMiddleware), for routes that must not depend on policies
+	AccessPolicy        AccessKind = "policy"        // Casbin policies stored in the database
+	AccessAPIKey        AccessKind = "api_key"       // API key of an external system instead of a user's JWT
+)
+
+// Access declares what a route requires. Build it with Public, Authenticated, Roles, Policy or APIKey.
+type Access struct {
+	Kind   AccessKind
+	Roles  []string
+	Scope  string // Endpoint group API keys need among their scopes
+	Module string // Optional plan module (see internal/plan) the tenant must have
+}
+
+// Public allows unauthenticated access.
+func Public() Access { return Access{Kind: AccessPublic} }
+
+// Authenticated allows any authenticated user.
+func Authenticated() Access { return Access{Kind: AccessAuthenticated} }
+
+// Roles allows users holding any of the roles.
+func Roles(roles ...string) Access { return Access{Kind: AccessRoles, Roles: roles} }
+
+// Policy defers to the Casbin policies for the route's path and method.
+func Policy() Access { return Access{Kind: AccessPolicy} }
+
+// APIKey allows external systems holding a valid API key with scope, the route's endpoint group (e.g.
+// "events"), among its scopes; what the key may read within it is up to the handler.
+func APIKey(scope string) Access { return Access{Kind: AccessAPIKey, Scope: scope} }
+
+// InModule additionally requires the tenant's plan to include module.
+func (a Access) InModule(module string) Access {
+	a.Module = module
+	return a
+}
+
+// RouteInfo describes a registered route for introspection.
+type RouteInfo struct {
+	Method string     `json:"method" example:"GET"`
+	Path   string     `json:"path" example:"/api/v1/admin/users/:id"`
+	Access AccessKind `json:"access" example:"policy"`
+	Roles  []string   `json:"roles,omitempty" example:"god-admin"`
+	Scope  string     `json:"scope,omitempty" example:"events"` // API key routes: the scope keys need
+	Module string     `json:"module,omitempty" example:"payroll"`
+}
+
+// Contributor is implemented by modules that expose API routes. The router registers the routes of enabled
+// modules only, so a disabled module's endpoints answer 404.
+type Contributor interface {
+	RegisterRoutes(api *Group)
+}
+
+// Registry registers routes together with their access requirement and wires the matching middleware,
+// so a route can't be added without deciding who may call it.
+type Registry struct {
+	auth     gin.HandlerFunc
+	apiKey   gin.HandlerFunc
+	policy   gin.HandlerFunc
+	modules  plan.ModuleChecker
+	enforcer *casbin.SyncedEnforcer
+
+	mu     sync.RWMutex
+	routes []RouteInfo
+}
+
+// NewRegistry creates a new Registry. auth authenticates the caller (AuthMiddleware) and apiKey external
+// systems (APIKeyMiddleware); policy routes are enforced in defaultDomain (or the tenant's domain from the token).
+func NewRegistry(auth, apiKey gin.HandlerFunc, enforcer *casbin.SyncedEnforcer, defaultDomain string, modules plan.ModuleChecker) *Registry {
+	return &Registry{
+		auth:     auth,
+		apiKey:   apiKey,
+		policy:   middleware.CasbinMiddleware(enforcer, defaultDomain),
+		modules:  modules,
+		enforcer: enforcer,
+	}
+}
+
+// Group wraps a gin router group for registration.
+func (r *Registry) Group(group *gin.RouterGroup) *Group {
+	return &Group{registry: r, group: group}
+}
+
+// Routes returns the registered routes, sorted by path and method.
+func (r *Registry) Routes() []RouteInfo {
+	r.mu.RLock()
+	routes := slices.Clone(r.routes)
+	r.mu.RUnlock()
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return routes
+}
+
+// chain builds the middleware for an access requirement: authentication, then the plan module check,
+// then authorization.
+func (r *Registry) chain(access Access) []gin.HandlerFunc {
+	if access.Kind == AccessPublic {
+		return nil
+	}
+	chain := []gin.HandlerFunc{r.auth}
+	if access.Kind == AccessAPIKey {
+		chain = []gin.HandlerFunc{r.apiKey}
+	}
+	if access.Module != "" {
+		chain = append(chain, middleware.RequireModule(r.modules, access.Module))
+	}
+	switch access.Kind {
+	case AccessRoles:
+		chain = append(chain, middleware.RBACMiddleware(access.Roles...))
+	case AccessPolicy:
+		chain = append(chain, r.policy)
+	case AccessAPIKey:
+		chain = append(chain, middleware.RequireAPIKeyScope(access.Scope))
+	}
+	return chain
+}
+
+// Group registers routes below a gin router group.
+type Group struct {
+	registry *Registry
+	group    *gin.RouterGroup
+	module   string // Plan module required by every non-public route of the group
+}
+
+// Group creates a sub-group; middleware passed here runs before the access middleware of each route.
+func (g *Group) Group(relativePath string, handlers ...gin.HandlerFunc) *Group {
+	return &Group{registry: g.registry, group: g.group.Group(relativePath, handlers...), module: g.module}
+}
+
+// InModule returns a view of the group whose non-public routes additionally require the tenant's plan to
+// include module, unless a route names a module itself. Feature modules use it for all of their routes.
+func (g *Group) InModule(module string) *Group {
+	return &Group{registry: g.registry, group: g.group, module: module}
+}
+
+// Handle registers a route with its access requirement.
+func (g *Group) Handle(method, relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	if access.Module == "" && access.Kind != AccessPublic {
+		access.Module = g.module
+	}
+	chain := append(g.registry.chain(access), handlers...)
+	g.group.Handle(method, relativePath, chain...)
+
+	g.registry.mu.Lock()
+	g.registry.routes = append(g.registry.routes, RouteInfo{
+		Method: method,
+		Path:   joinPaths(g.group.BasePath(), relativePath),
+		Access: access.Kind,
+		Roles:  access.Roles,
+		Scope:  access.Scope,
+		Module: access.Module,
+	})
+	g.registry.mu.Unlock()
+}
+
+// GET registers a GET route.
+func (g *Group) GET(relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, relativePath, access, handlers...)
+}
+
+// POST registers a POST route.
+func (g *Group) POST(relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, relativePath, access, handlers...)
+}
+
+// PUT registers a PUT route.
+func (g *Group) PUT(relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, relativePath, access, handlers...)
+}
+
+// PATCH registers a PATCH route.
+func (g *Group) PATCH(relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, relativePath, access, handlers...)
+}
+
+// DELETE registers a DELETE route.
+func (g *Group) DELETE(relativePath string, access Access, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, relativePath, access, handlers...)
+}
+
+// joinPaths joins a group base path and a relative route path the way gin does.
+func joinPaths(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(relative, "/")
+}
@@ -0,0 +1,109 @@
+// prometheus/backend/internal/search/handler.go
+package search
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// managerRole makes its holders managers of the divisions it is scoped to, or of everyone when held
+// globally.
+const managerRole = "manager"
+
+// Handler handles HTTP requests for search.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Search searches across employees, documents, announcements and policies.
+// @Summary Search the organization
+// @Description Hits are what the caller may open, the best title matches first, each with a link to the
+// @Description endpoint showing it. Content of modules outside the organization's plan isn't searched. Types
+// @Description whose search failed or timed out are listed in partial.
+// @Tags Search
+// @Produce json
+// @Param q query string true "Search term, 2 to 100 characters"
+// @Param types query string false "Comma-separated kinds of content, e.g. employee,document; see /search/types"
+// @Param limit query int false "Most hits (default 20, max 50)"
+// @Success 200 {object} Results
+// @Failure 400 {object} utils.ErrorResponse "Term too short or too long, or unknown type"
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	req := Request{Term: c.Query("q")}
+	if raw := strings.TrimSpace(c.Query("types")); raw != "" {
+		for _, kind := range strings.Split(raw, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				req.Types = append(req.Types, kind)
+			}
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		req.Limit = limit
+	}
+	results, err := h.service.Search(c.Request.Context(), utils.OrganizationFromContext(c), viewer(c), req)
+	if err != nil {
+		sendSearchError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Search completed successfully", results)
+}
+
+// Suggest completes a search term being typed.
+// @Summary Suggest search results while typing
+// @Description Up to 8 hits whose title, or a word of it, starts with the term, without snippets.
+// @Tags Search
+// @Produce json
+// @Param q query string true "Term typed so far, at least 2 characters"
+// @Success 200 {array} Hit
+// @Failure 400 {object} utils.ErrorResponse "Term too short or too long"
+// @Router /search/suggest [get]
+func (h *Handler) Suggest(c *gin.Context) {
+	hits, err := h.service.Suggest(c.Request.Context(), utils.OrganizationFromContext(c), viewer(c), c.Query("q"))
+	if err != nil {
+		sendSearchError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Suggestions fetched successfully", hits)
+}
+
+// Types lists the kinds of content that can be searched.
+// @Summary List searchable content types
+// @Tags Search
+// @Produce json
+// @Success 200 {array} string
+// @Router /search/types [get]
+func (h *Handler) Types(c *gin.Context) {
+	utils.SendSuccessResponse(c, http.StatusOK, "Search types fetched successfully", h.service.Types())
+}
+
+func viewer(c *gin.Context) Viewer {
+	v := Viewer{UserID: c.GetUint("userID"), Roles: middleware.RolesFromContext(c)}
+	v.ManagesAll, v.Manages = middleware.DivisionScope(c, managerRole)
+	return v
+}
+
+// sendSearchError maps service errors to HTTP status codes.
+func sendSearchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidQuery), errors.Is(err, ErrUnknownType):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,32 @@
+// prometheus/backend/internal/search/module.go
+package search
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the search module.
+const ModuleName = "search"
+
+// searchModule serves organization-wide search over what the other modules provide.
+type searchModule struct {
+	handler *Handler
+}
+
+// NewModule creates the search module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &searchModule{handler: NewHandler(svc)}
+}
+
+func (m *searchModule) Name() string { return ModuleName }
+
+func (m *searchModule) HealthContributors() []module.HealthContributor { return nil }
+
+// RegisterRoutes implements routing.Contributor. Everyone may search; what they find is trimmed to what
+// they may open.
+func (m *searchModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/search", routing.Authenticated(), m.handler.Search)
+	api.GET("/search/suggest", routing.Authenticated(), m.handler.Suggest)
+	api.GET("/search/types", routing.Authenticated(), m.handler.Types)
+}
@@ -0,0 +1,148 @@
+// prometheus/backend/internal/search/provider.go
+package search
+
+import (
+	"context"
+	"fmt"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Viewer is who is searching. Providers trim their hits to what the viewer may open, by the same rules as
+// the endpoints their links point to.
+type Viewer struct {
+	UserID     uint
+	Roles      []string // Roles held globally
+	ManagesAll bool     // Holds the manager role globally
+	Manages    []uint   // Divisions managed through a division-scoped manager role
+}
+
+// HasRole reports whether the viewer holds any of roles globally.
+func (v Viewer) HasRole(roles ...string) bool {
+	return slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(v.Roles, role) })
+}
+
+// Query is a search as providers receive it.
+type Query struct {
+	OrgID  *uint // nil = default organization
+	Viewer Viewer
+	Term   string // Trimmed and lowercased
+	Prefix bool   // Type-ahead: match the start of titles and of their words only
+	Limit  int    // Most hits to return
+}
+
+// Condition returns a WHERE condition matching the term against any of columns, case-insensitively, with
+// its arguments. Type-ahead queries match the start of the column or of a word in it.
+func (q Query) Condition(columns ...string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, column := range columns {
+		if q.Prefix {
+			conditions = append(conditions, fmt.Sprintf("LOWER(%s) LIKE ? OR LOWER(%s) LIKE ?", column, column))
+			args = append(args, utils.PrefixPattern(q.Term), "% "+utils.PrefixPattern(q.Term))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("LOWER(%s) LIKE ?", column))
+			args = append(args, utils.ContainsPattern(q.Term))
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// Hit is one search result.
+type Hit struct {
+	Type    string `json:"type" example:"document"`
+	ID      uint   `json:"id" example:"31"`
+	Title   string `json:"title" example:"Remote work agreement"`
+	Snippet string `json:"snippet,omitempty" example:"…employees may work remotely up to three days…"` // Where the term matched outside the title
+	Link    string `json:"link" example:"/api/v1/me/documents/31"`                                     // API path opening it with the viewer's access
+}
+
+// Provider searches one kind of content, e.g. documents.
+type Provider interface {
+	// Type names the kind of content in hits and the ?types= filter, e.g. "document".
+	Type() string
+	// Module is the plan module the content belongs to; "" for content every plan includes.
+	Module() string
+	// Search returns up to q.Limit hits the viewer may open, the most relevant first.
+	Search(ctx context.Context, q Query) ([]Hit, error)
+}
+
+// Contributor is implemented by modules with searchable content.
+type Contributor interface {
+	SearchProviders() []Provider
+}
+
+// provider adapts a function to Provider.
+type provider struct {
+	kind   string
+	module string
+	search func(ctx context.Context, q Query) ([]Hit, error)
+}
+
+// NewProvider creates a Provider from a function.
+func NewProvider(kind, module string, search func(ctx context.Context, q Query) ([]Hit, error)) Provider {
+	return &provider{kind: kind, module: module, search: search}
+}
+
+func (p *provider) Type() string { return p.kind }
+
+func (p *provider) Module() string { return p.module }
+
+func (p *provider) Search(ctx context.Context, q Query) ([]Hit, error) {
+	return p.search(ctx, q)
+}
+
+// Registry collects the providers of the core and of enabled feature modules.
+type Registry struct {
+	mu        sync.RWMutex
+	providers []Provider
+}
+
+// NewRegistry creates a Registry holding providers.
+func NewRegistry(providers ...Provider) *Registry {
+	return &Registry{providers: providers}
+}
+
+// Add registers more providers.
+func (r *Registry) Add(providers ...Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers = append(r.providers, providers...)
+}
+
+// Providers returns the registered providers in registration order.
+func (r *Registry) Providers() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Provider(nil), r.providers...)
+}
+
+// Snippet returns about width runes of text around the first occurrence of term (lowercase), with
+// ellipses where it was cut; "" when text doesn't contain term.
+func Snippet(text, term string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	at := strings.Index(lower, term)
+	if at < 0 {
+		return ""
+	}
+	if len(lower) != len(text) {
+		at = 0 // Lowercasing changed byte offsets, so at doesn't point into text
+	}
+	runes := []rune(text)
+	center := utf8.RuneCountInString(text[:at])
+	start := max(0, center-width/3)
+	end := min(len(runes), start+width)
+	start = max(0, end-width)
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
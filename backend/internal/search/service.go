@@ -0,0 +1,204 @@
+// prometheus/backend/internal/search/service.go
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/plan"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// minTermLength is the fewest characters searched for; shorter terms match too much to be useful.
+	minTermLength = 2
+	// maxTermLength is the most characters searched for.
+	maxTermLength = 100
+	// defaultLimit and maxLimit bound the hits of a search.
+	defaultLimit = 20
+	maxLimit     = 50
+	// suggestLimit is how many suggestions type-ahead gets.
+	suggestLimit = 8
+	// providerTimeout is how long a provider may take before the search goes on without it.
+	providerTimeout = 3 * time.Second
+)
+
+var (
+	// ErrInvalidQuery is returned for search terms that are too short or too long.
+	ErrInvalidQuery = fmt.Errorf("search terms must be %d to %d characters", minTermLength, maxTermLength)
+	// ErrUnknownType is returned when filtering on a kind of content nothing provides.
+	ErrUnknownType = errors.New("unknown content type")
+)
+
+// Request is a search.
+type Request struct {
+	Term  string
+	Types []string // Kinds of content to search; empty = all
+	Limit int      // 0 = defaultLimit
+}
+
+// Results are the hits of a search, the best matches first.
+type Results struct {
+	Term    string   `json:"term" example:"remote"`
+	Hits    []Hit    `json:"hits"`
+	Partial []string `json:"partial,omitempty" example:"document"` // Types that failed or timed out, whose hits are missing
+}
+
+// Service searches across the content of the core and of enabled modules. Each provider trims its hits
+// to what the viewer may open; content of modules outside the organization's plan isn't searched.
+// orgID scopes every search to one organization (nil = default organization).
+type Service interface {
+	Search(ctx context.Context, orgID *uint, viewer Viewer, req Request) (*Results, error)
+	// Suggest completes a term being typed from the start of titles and their words.
+	Suggest(ctx context.Context, orgID *uint, viewer Viewer, term string) ([]Hit, error)
+	// Types lists the kinds of content that can be searched, in registration order.
+	Types() []string
+}
+
+// service implements the Service interface.
+type service struct {
+	providers *Registry
+	modules   plan.ModuleChecker
+}
+
+// NewService creates a new instance of Service, searching the content of providers that the plan of the
+// caller's organization includes, according to modules.
+func NewService(providers *Registry, modules plan.ModuleChecker) Service {
+	return &service{providers: providers, modules: modules}
+}
+
+func (s *service) Search(ctx context.Context, orgID *uint, viewer Viewer, req Request) (*Results, error) {
+	term, err := normalize(req.Term)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+	providers := s.providers.Providers()
+	if len(req.Types) > 0 {
+		known := s.Types()
+		for _, kind := range req.Types {
+			if !slices.Contains(known, kind) {
+				return nil, fmt.Errorf("%w %q", ErrUnknownType, kind)
+			}
+		}
+		providers = slices.DeleteFunc(providers, func(p Provider) bool { return !slices.Contains(req.Types, p.Type()) })
+	}
+	hits, partial := s.federate(ctx, providers, Query{OrgID: orgID, Viewer: viewer, Term: term, Limit: limit})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return &Results{Term: term, Hits: hits, Partial: partial}, nil
+}
+
+// Suggest leaves out snippets, which type-ahead has no room for, and providers that fail.
+func (s *service) Suggest(ctx context.Context, orgID *uint, viewer Viewer, term string) ([]Hit, error) {
+	term, err := normalize(term)
+	if err != nil {
+		return nil, err
+	}
+	hits, _ := s.federate(ctx, s.providers.Providers(), Query{OrgID: orgID, Viewer: viewer, Term: term, Prefix: true, Limit: suggestLimit})
+	if len(hits) > suggestLimit {
+		hits = hits[:suggestLimit]
+	}
+	for i := range hits {
+		hits[i].Snippet = ""
+	}
+	return hits, nil
+}
+
+func (s *service) Types() []string {
+	types := []string{}
+	for _, p := range s.providers.Providers() {
+		if !slices.Contains(types, p.Type()) {
+			types = append(types, p.Type())
+		}
+	}
+	return types
+}
+
+// federate runs the providers at once and ranks their hits together: titles equal to the term first, then
+// titles starting with it, then titles with a word starting with it, then the rest, each in provider order
+// then in the order the provider returned them. Providers that fail or time out are reported, not fatal.
+func (s *service) federate(ctx context.Context, providers []Provider, q Query) ([]Hit, []string) {
+	var orgID uint
+	if q.OrgID != nil {
+		orgID = *q.OrgID
+	}
+	results := make([][]Hit, len(providers))
+	failed := make([]bool, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		if p.Module() != "" {
+			if err := s.modules.CheckModule(orgID, p.Module()); err != nil {
+				continue // Not in the plan, like the endpoints the hits would link to
+			}
+		}
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+			defer cancel()
+			hits, err := p.Search(ctx, q)
+			if err != nil {
+				log.Printf("Search provider %s failed: %v", p.Type(), err)
+				failed[i] = true
+				return
+			}
+			results[i] = hits
+		}(i, p)
+	}
+	wg.Wait()
+
+	var hits []Hit
+	var partial []string
+	for i, p := range providers {
+		if failed[i] {
+			partial = append(partial, p.Type())
+		}
+		if len(results[i]) > q.Limit {
+			results[i] = results[i][:q.Limit]
+		}
+		hits = append(hits, results[i]...)
+	}
+	sort.SliceStable(hits, func(a, b int) bool {
+		return rank(hits[a].Title, q.Term) > rank(hits[b].Title, q.Term)
+	})
+	if hits == nil {
+		hits = []Hit{}
+	}
+	return hits, partial
+}
+
+// rank scores how well a title matches the term, higher being better.
+func rank(title, term string) int {
+	title = strings.ToLower(title)
+	switch {
+	case title == term:
+		return 3
+	case strings.HasPrefix(title, term):
+		return 2
+	case strings.Contains(title, " "+term):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalize trims and lowercases a search term, and checks its length.
+func normalize(term string) (string, error) {
+	term = strings.ToLower(strings.Join(strings.Fields(term), " "))
+	if n := utf8.RuneCountInString(term); n < minTermLength || n > maxTermLength {
+		return "", ErrInvalidQuery
+	}
+	return term, nil
+}
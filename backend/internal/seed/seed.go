@@ -0,0 +1,177 @@
+// prometheus/backend/internal/seed/seed.go
+package seed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/lock"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSkip is returned by a seed's Up to leave it unapplied, e.g. while what it needs isn't configured.
+	// Apply then tries again on the next start.
+	ErrSkip = errors.New("seed skipped")
+	// ErrChecksum is returned when a seed's data changed since its version was applied without a new version.
+	ErrChecksum = errors.New("the seed changed since this version was applied; bump its version")
+	// ErrNotApplied is returned when rolling back a seed whose version isn't the latest applied.
+	ErrNotApplied = errors.New("this version of the seed is not the latest applied")
+	// ErrIrreversible is returned when rolling back a seed without Down.
+	ErrIrreversible = errors.New("the seed can't be rolled back")
+)
+
+// Seed is versioned initial data. A version is applied exactly once per database: Apply records it in the
+// seeds table along with the checksum of Data, and skips it from then on.
+type Seed struct {
+	Name    string // Unique, e.g. "roles"
+	Version int    // Starts at 1; bump it whenever Data or Up change
+	// Data is what the seed writes, checksummed to catch changes made without a new version. Leave secrets
+	// and deployment settings out of it.
+	Data any
+	// Up applies the version. It runs in the transaction recording it, so a failure leaves nothing behind
+	// and is retried on the next start.
+	Up func(tx *gorm.DB) error
+	// Down undoes Up, for Rollback; nil if the version can't be undone.
+	Down func(tx *gorm.DB) error
+}
+
+// Record is an applied seed version.
+type Record struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_seed_version"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_seed_version"`
+	Checksum  string    `gorm:"type:varchar(64);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName keeps the records in the seeds table.
+func (Record) TableName() string { return "seeds" }
+
+// Apply applies the seeds, in order, whose version isn't recorded yet. Each seed is applied in its own
+// transaction under an advisory lock, so replicas starting together apply it once. Seeds that fail don't
+// stop the others; their errors are returned together.
+func Apply(db *gorm.DB, seeds ...Seed) error {
+	var errs []error
+	for _, s := range seeds {
+		if err := apply(db, s); err != nil {
+			errs = append(errs, fmt.Errorf("seed %s v%d: %w", s.Name, s.Version, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func apply(db *gorm.DB, s Seed) error {
+	checksum, err := s.checksum()
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := lock.Tx(tx, "seeds:"+s.Name); err != nil {
+			return err
+		}
+		latest, err := latest(tx, s.Name)
+		if err != nil {
+			return err
+		}
+		if latest != nil {
+			switch {
+			case latest.Version > s.Version:
+				log.Printf("Seed %s is at version %d, newer than this build's %d. Leaving it.", s.Name, latest.Version, s.Version)
+				return nil
+			case latest.Version == s.Version && latest.Checksum != checksum:
+				return ErrChecksum
+			case latest.Version == s.Version:
+				return nil
+			}
+		}
+		if err := s.Up(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(&Record{Name: s.Name, Version: s.Version, Checksum: checksum, AppliedAt: clock.Now().UTC()}).Error; err != nil {
+			return fmt.Errorf("failed to record seed: %w", err)
+		}
+		log.Printf("Seed %s version %d applied.", s.Name, s.Version)
+		return nil
+	})
+	if errors.Is(err, ErrSkip) {
+		log.Printf("Seed %s version %d skipped: %v", s.Name, s.Version, err)
+		return nil
+	}
+	return err
+}
+
+// Rollback undoes the seed's version with its Down and forgets it, so the previous version is the latest
+// applied again and the next Apply of this version runs Up anew.
+func Rollback(db *gorm.DB, s Seed) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return RollbackTx(tx, s)
+	})
+}
+
+// RollbackTx is Rollback inside tx, so callers can record the rollback along with it.
+func RollbackTx(tx *gorm.DB, s Seed) error {
+	if s.Down == nil {
+		return ErrIrreversible
+	}
+	if err := lock.Tx(tx, "seeds:"+s.Name); err != nil {
+		return err
+	}
+	latest, err := latest(tx, s.Name)
+	if err != nil {
+		return err
+	}
+	if latest == nil || latest.Version != s.Version {
+		return ErrNotApplied
+	}
+	if err := s.Down(tx); err != nil {
+		return err
+	}
+	if err := tx.Delete(latest).Error; err != nil {
+		return fmt.Errorf("failed to forget seed %s version %d: %w", s.Name, s.Version, err)
+	}
+	log.Printf("Seed %s version %d rolled back.", s.Name, s.Version)
+	return nil
+}
+
+// Applied returns the applied versions of every seed, by name and version.
+func Applied(db *gorm.DB) ([]Record, error) {
+	var records []Record
+	if err := db.Order("name, version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list seeds: %w", err)
+	}
+	return records, nil
+}
+
+// latest returns the latest applied version of a seed, nil if none is.
+func latest(tx *gorm.DB, name string) (*Record, error) {
+	var record Record
+	err := tx.Where("name = ?", name).Order("version DESC").First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seed %s: %w", name, err)
+	}
+	return &record, nil
+}
+
+// checksum hashes the seed's name, version and data.
+func (s Seed) checksum() (string, error) {
+	raw, err := json.Marshal(struct {
+		Name    string
+		Version int
+		Data    any
+	}{s.Name, s.Version, s.Data})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode seed data: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
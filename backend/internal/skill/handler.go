@@ -0,0 +1,464 @@
+// prometheus/backend/internal/skill/handler.go
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for the skill catalog, the skill matrix and the gap report.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListSkills returns the organization's skill catalog.
+// @Summary List skills
+// @Tags Skills
+// @Produce json
+// @Success 200 {array} Skill
+// @Router /hr/skills [get]
+func (h *Handler) ListSkills(c *gin.Context) {
+	skills, err := h.service.Skills(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Skills fetched successfully", skills)
+}
+
+// GetSkill returns a skill. The ETag and Last-Modified headers can be sent back as If-Match /
+// If-Unmodified-Since.
+// @Summary Get a skill
+// @Tags Skills
+// @Produce json
+// @Param id path int true "Skill ID"
+// @Success 200 {object} Skill
+// @Failure 404 {object} utils.ErrorResponse "Skill not found"
+// @Router /hr/skills/{id} [get]
+func (h *Handler) GetSkill(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	skill, err := h.service.GetSkill(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, skill.UpdatedAt, skill.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Skill fetched successfully", skill)
+}
+
+// CreateSkill adds a skill to the catalog.
+// @Summary Create a skill
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Param skill body SkillRequest true "Skill"
+// @Success 201 {object} Skill
+// @Failure 400 {object} utils.ErrorResponse "Invalid skill"
+// @Failure 409 {object} utils.ErrorResponse "Name already taken"
+// @Router /hr/skills [post]
+func (h *Handler) CreateSkill(c *gin.Context) {
+	var req SkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	skill, err := h.service.CreateSkill(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, skill.UpdatedAt, skill.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Skill created successfully", skill)
+}
+
+// UpdateSkill replaces a skill's fields.
+// @Summary Update a skill
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Param id path int true "Skill ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param skill body SkillRequest true "Skill"
+// @Success 200 {object} Skill
+// @Failure 400 {object} utils.ErrorResponse "Invalid skill"
+// @Failure 404 {object} utils.ErrorResponse "Skill not found"
+// @Failure 409 {object} utils.ErrorResponse "Name already taken"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/skills/{id} [put]
+func (h *Handler) UpdateSkill(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetSkill(orgID, id)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	skill, err := h.service.UpdateSkill(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, skill.UpdatedAt, skill.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Skill updated successfully", skill)
+}
+
+// DeleteSkill removes a skill with its ratings and requirements.
+// @Summary Delete a skill
+// @Tags Skills
+// @Param id path int true "Skill ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Skill not found"
+// @Router /hr/skills/{id} [delete]
+func (h *Handler) DeleteSkill(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteSkill(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Rate sets an employee's level in a skill, replacing any earlier rating.
+// @Summary Rate an employee in a skill
+// @Description Levels run from 1 (basic) to 5 (expert). The caller is recorded as the assessor.
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Param rating body RatingRequest true "Rating"
+// @Success 200 {object} Rating
+// @Failure 400 {object} utils.ErrorResponse "Invalid rating, unknown employee or skill"
+// @Router /hr/skills/ratings [put]
+func (h *Handler) Rate(c *gin.Context) {
+	var req RatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	rating, err := h.service.Rate(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Rating saved successfully", rating)
+}
+
+// DeleteRating removes a rating, leaving the employee unrated in the skill.
+// @Summary Delete a rating
+// @Tags Skills
+// @Param id path int true "Rating ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Rating not found"
+// @Router /hr/skills/ratings/{id} [delete]
+func (h *Handler) DeleteRating(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteRating(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Matrix returns employees' levels across skills.
+// @Summary Get the skill matrix
+// @Description One row per employee with their levels by skill ID; skills they weren't rated in are left
+// @Description out. Paginated by employee.
+// @Tags Skills
+// @Produce json
+// @Param division_id query int false "Division ID"
+// @Param skill_ids query string false "Comma-separated skill IDs; all skills if omitted"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/skills/matrix [get]
+func (h *Handler) Matrix(c *gin.Context) {
+	var query MatrixQuery
+	var ok bool
+	if query.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	for _, raw := range strings.Split(c.Query("skill_ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid skill_ids parameter")
+			return
+		}
+		query.SkillIDs = append(query.SkillIDs, uint(id))
+	}
+	page := utils.ParsePagination(c)
+	matrix, total, err := h.service.Matrix(utils.OrganizationFromContext(c), query, page)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Skill matrix fetched successfully", page.Response(matrix, total))
+}
+
+// ListRequirements returns the levels job titles require.
+// @Summary List skill requirements
+// @Tags Skills
+// @Produce json
+// @Param job_title query string false "Job title, matched case-insensitively"
+// @Param division_id query int false "Division ID"
+// @Param skill_id query int false "Skill ID"
+// @Success 200 {array} Requirement
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/skills/requirements [get]
+func (h *Handler) ListRequirements(c *gin.Context) {
+	filter := RequirementFilter{JobTitle: c.Query("job_title")}
+	var ok bool
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	if filter.SkillID, ok = utils.OptionalUintQuery(c, "skill_id"); !ok {
+		return
+	}
+	requirements, err := h.service.Requirements(utils.OrganizationFromContext(c), filter)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Skill requirements fetched successfully", requirements)
+}
+
+// CreateRequirement sets the level a job title requires in a skill.
+// @Summary Create a skill requirement
+// @Description Without division_id the requirement holds across the organization; with it, it replaces
+// @Description the organization-wide requirement within that division.
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Param requirement body RequirementRequest true "Requirement"
+// @Success 201 {object} Requirement
+// @Failure 400 {object} utils.ErrorResponse "Invalid requirement, unknown skill or division"
+// @Failure 409 {object} utils.ErrorResponse "Job title already requires the skill"
+// @Router /hr/skills/requirements [post]
+func (h *Handler) CreateRequirement(c *gin.Context) {
+	var req RequirementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	requirement, err := h.service.CreateRequirement(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Skill requirement created successfully", requirement)
+}
+
+// UpdateRequirement replaces a requirement's fields.
+// @Summary Update a skill requirement
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Param id path int true "Requirement ID"
+// @Param requirement body RequirementRequest true "Requirement"
+// @Success 200 {object} Requirement
+// @Failure 400 {object} utils.ErrorResponse "Invalid requirement, unknown skill or division"
+// @Failure 404 {object} utils.ErrorResponse "Requirement not found"
+// @Failure 409 {object} utils.ErrorResponse "Job title already requires the skill"
+// @Router /hr/skills/requirements/{id} [put]
+func (h *Handler) UpdateRequirement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req RequirementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	requirement, err := h.service.UpdateRequirement(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Skill requirement updated successfully", requirement)
+}
+
+// DeleteRequirement removes a requirement.
+// @Summary Delete a skill requirement
+// @Tags Skills
+// @Param id path int true "Requirement ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.ErrorResponse "Requirement not found"
+// @Router /hr/skills/requirements/{id} [delete]
+func (h *Handler) DeleteRequirement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteRequirement(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id); err != nil {
+		sendSkillError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Gap returns the skills gap report per division.
+// @Summary Get the skills gap report
+// @Description For each division and skill that employees' job titles require: how many meet the level,
+// @Description how many fall short, by how much on average, and how many of those are in training, have
+// @Description completed training but await reassessment, or still need training.
+// @Tags Skills
+// @Produce json
+// @Param division_id query int false "Division ID"
+// @Param skill_id query int false "Skill ID"
+// @Success 200 {object} GapReport
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/analytics/skills-gap [get]
+func (h *Handler) Gap(c *gin.Context) {
+	query, ok := parseGapQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.Gap(utils.OrganizationFromContext(c), query)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Skills gap report generated successfully", report)
+}
+
+// ExportGap downloads the skills gap report for L&D planning.
+// @Summary Export the skills gap report
+// @Description by=division (default) exports the report's rows; by=employee lists every employee below
+// @Description the required level, with their training status, as a training needs list.
+// @Tags Skills
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param by query string false "division or employee" default(division)
+// @Param division_id query int false "Division ID"
+// @Param skill_id query int false "Skill ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Invalid format or filter"
+// @Router /hr/analytics/skills-gap/export [get]
+func (h *Handler) ExportGap(c *gin.Context) {
+	format, err := utils.ParseExportFormat(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	by := c.DefaultQuery("by", "division")
+	if by != "division" && by != "employee" {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid by parameter")
+		return
+	}
+	query, ok := parseGapQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.Gap(utils.OrganizationFromContext(c), query)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"skills-gap-%s-%s.%s\"", by, clock.Now().UTC().Format("2006-01-02"), format))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", format.ContentType())
+	c.Status(http.StatusOK)
+	rows := utils.NewRowWriter(c.Writer, format)
+	err = writeGap(rows, report, by)
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil {
+		// Headers are already sent; leave the file incomplete rather than pass it off as whole.
+		log.Printf("Skills gap export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// writeGap writes the report's rows, or its training needs when by is "employee".
+func writeGap(rows utils.RowWriter, report *GapReport, by string) error {
+	if by == "employee" {
+		if err := rows.WriteRow(needHeader); err != nil {
+			return err
+		}
+		for _, n := range report.Needs {
+			if err := rows.WriteRow(needRecord(n)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := rows.WriteRow(gapHeader); err != nil {
+		return err
+	}
+	for _, r := range report.Rows {
+		if err := rows.WriteRow(gapRecord(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseGapQuery(c *gin.Context) (GapQuery, bool) {
+	var query GapQuery
+	var ok bool
+	if query.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return GapQuery{}, false
+	}
+	if query.SkillID, ok = utils.OptionalUintQuery(c, "skill_id"); !ok {
+		return GapQuery{}, false
+	}
+	return query, true
+}
+
+// sendSkillError maps service errors to HTTP status codes.
+func sendSkillError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidSkill):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrDuplicateRequirement):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The skill was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
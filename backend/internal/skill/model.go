@@ -0,0 +1,110 @@
+// prometheus/backend/internal/skill/model.go
+package skill
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Skill levels run from MinLevel (basic) to MaxLevel (expert).
+const (
+	MinLevel = 1
+	MaxLevel = 5
+)
+
+// Skill is a capability in the organization's skill catalog.
+type Skill struct {
+	ID             uint           `gorm:"primaryKey" json:"id" example:"4"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name" example:"Payroll processing"`
+	Category       string         `gorm:"type:varchar(100)" json:"category,omitempty" example:"Finance"`
+	Description    string         `gorm:"type:varchar(1000)" json:"description,omitempty"`
+	Version        uint           `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Rating is an employee's assessed level in a skill: one cell of the skill matrix.
+type Rating struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"31"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;uniqueIndex:idx_skill_rating" json:"employee_id" example:"12"`
+	SkillID        uint      `gorm:"not null;uniqueIndex:idx_skill_rating;index" json:"skill_id" example:"4"`
+	Level          int       `gorm:"not null" json:"level" example:"3"`
+	Note           string    `gorm:"type:varchar(500)" json:"note,omitempty"`
+	AssessedBy     *uint     `json:"assessed_by,omitempty" example:"3"` // User ID
+	AssessedAt     time.Time `gorm:"not null" json:"assessed_at"`
+}
+
+// TableName keeps ratings next to skills.
+func (Rating) TableName() string { return "skill_ratings" }
+
+// Requirement is the level employees with a job title need in a skill. A requirement for a division
+// replaces the organization-wide one for the same job title and skill within that division. Job titles
+// stand in for positions: they are what employee records carry.
+type Requirement struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"9"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	JobTitle       string    `gorm:"type:varchar(150);not null;index" json:"job_title" example:"Payroll Specialist"` // Matched case-insensitively
+	DivisionID     *uint     `gorm:"index" json:"division_id,omitempty" example:"2"`
+	SkillID        uint      `gorm:"not null;index" json:"skill_id" example:"4"`
+	MinLevel       int       `gorm:"not null" json:"min_level" example:"3"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps requirements next to skills.
+func (Requirement) TableName() string { return "skill_requirements" }
+
+// SkillRequest creates a skill or replaces its fields.
+type SkillRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Payroll processing"`
+	Category    string `json:"category,omitempty" binding:"max=100" example:"Finance"`
+	Description string `json:"description,omitempty" binding:"max=1000"`
+}
+
+// RatingRequest sets an employee's level in a skill, replacing any earlier rating.
+type RatingRequest struct {
+	EmployeeID uint   `json:"employee_id" binding:"required" example:"12"`
+	SkillID    uint   `json:"skill_id" binding:"required" example:"4"`
+	Level      int    `json:"level" binding:"required,min=1,max=5" example:"3"`
+	Note       string `json:"note,omitempty" binding:"max=500"`
+}
+
+// RequirementRequest creates a requirement or replaces its fields.
+type RequirementRequest struct {
+	JobTitle   string `json:"job_title" binding:"required,max=150" example:"Payroll Specialist"`
+	DivisionID *uint  `json:"division_id,omitempty" example:"2"` // Omit for the whole organization
+	SkillID    uint   `json:"skill_id" binding:"required" example:"4"`
+	MinLevel   int    `json:"min_level" binding:"required,min=1,max=5" example:"3"`
+}
+
+// RequirementFilter narrows a requirement listing.
+type RequirementFilter struct {
+	JobTitle   string
+	DivisionID *uint
+	SkillID    *uint
+}
+
+// MatrixQuery selects part of the skill matrix.
+type MatrixQuery struct {
+	DivisionID *uint
+	SkillIDs   []uint // All skills if empty
+}
+
+// Matrix is employees' levels across skills.
+type Matrix struct {
+	Skills    []Skill     `json:"skills"`
+	Employees []MatrixRow `json:"employees"`
+}
+
+// MatrixRow is one employee's levels, by skill ID; skills they weren't rated in are left out.
+type MatrixRow struct {
+	EmployeeID  uint         `json:"employee_id" example:"12"`
+	DisplayName string       `json:"display_name" example:"Laila Haddad"`
+	JobTitle    string       `json:"job_title" example:"Payroll Specialist"`
+	DivisionID  *uint        `json:"division_id,omitempty" example:"2"`
+	Levels      map[uint]int `json:"levels" swaggertype:"object"`
+}
@@ -0,0 +1,51 @@
+// prometheus/backend/internal/skill/module.go
+package skill
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the skills module.
+const ModuleName = "skills"
+
+// skillModule owns the skill catalog, the skill matrix and the skills gap report.
+type skillModule struct {
+	handler *Handler
+}
+
+// NewModule creates the skills module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &skillModule{handler: NewHandler(svc)}
+}
+
+func (m *skillModule) Name() string { return ModuleName }
+
+func (m *skillModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *skillModule) Models() []any {
+	return []any{&Skill{}, &Rating{}, &Requirement{}}
+}
+
+// RegisterRoutes implements routing.Contributor. HR keeps the matrix; the gap report is part of the
+// reports module.
+func (m *skillModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/hr/skills", routing.Policy(), m.handler.ListSkills)
+	api.POST("/hr/skills", routing.Policy(), m.handler.CreateSkill)
+	api.GET("/hr/skills/matrix", routing.Policy(), m.handler.Matrix)
+	api.PUT("/hr/skills/ratings", routing.Policy(), m.handler.Rate)
+	api.DELETE("/hr/skills/ratings/:id", routing.Policy(), m.handler.DeleteRating)
+	api.GET("/hr/skills/requirements", routing.Policy(), m.handler.ListRequirements)
+	api.POST("/hr/skills/requirements", routing.Policy(), m.handler.CreateRequirement)
+	api.PUT("/hr/skills/requirements/:id", routing.Policy(), m.handler.UpdateRequirement)
+	api.DELETE("/hr/skills/requirements/:id", routing.Policy(), m.handler.DeleteRequirement)
+	api.GET("/hr/skills/:id", routing.Policy(), m.handler.GetSkill)
+	api.PUT("/hr/skills/:id", routing.Policy(), m.handler.UpdateSkill)
+	api.DELETE("/hr/skills/:id", routing.Policy(), m.handler.DeleteSkill)
+
+	reportsAPI := api.InModule(plan.ModuleReports)
+	reportsAPI.GET("/hr/analytics/skills-gap", routing.Policy(), m.handler.Gap)
+	reportsAPI.GET("/hr/analytics/skills-gap/export", routing.Policy(), m.handler.ExportGap)
+}
@@ -0,0 +1,221 @@
+// prometheus/backend/internal/skill/report.go
+package skill
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrainingStatus is where an employee stands with training for a skill.
+type TrainingStatus string
+
+const (
+	TrainingNone      TrainingStatus = ""          // No training for the skill
+	TrainingEnrolled  TrainingStatus = "enrolled"  // Enrolled in or attending training that teaches the skill
+	TrainingCompleted TrainingStatus = "completed" // Completed such training
+)
+
+// TrainingRecords tells where employees stand with training, by employee ID and then skill ID. The
+// training module provides it through Service.UseTraining; without it, every gap counts as a training need.
+type TrainingRecords interface {
+	SkillTraining(db *gorm.DB, orgID *uint, employeeIDs []uint) (map[uint]map[uint]TrainingStatus, error)
+}
+
+// GapQuery selects what the gap report covers.
+type GapQuery struct {
+	DivisionID *uint
+	SkillID    *uint
+}
+
+// GapReport compares the levels employees have with the levels their job titles require, per division.
+type GapReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Rows        []GapRow  `json:"rows"`
+	Needs       []Need    `json:"-"` // Per employee, for exports
+}
+
+// GapRow is one skill within one division.
+type GapRow struct {
+	DivisionID        *uint   `json:"division_id,omitempty" example:"2"` // Empty for employees outside any division
+	Division          string  `json:"division" example:"Finance"`
+	SkillID           uint    `json:"skill_id" example:"4"`
+	Skill             string  `json:"skill" example:"Payroll processing"`
+	Category          string  `json:"category,omitempty" example:"Finance"`
+	Required          int     `json:"required" example:"8"`            // Employees whose job title requires the skill
+	Meeting           int     `json:"meeting" example:"5"`             // Rated at or above the required level
+	Gap               int     `json:"gap" example:"3"`                 // Below the required level, including unrated
+	Unrated           int     `json:"unrated" example:"1"`             // Never rated in the skill
+	AverageShortfall  float64 `json:"average_shortfall" example:"1.5"` // Levels missing, averaged over the gap; unrated counts as level 0
+	InTraining        int     `json:"in_training" example:"1"`         // Of the gap, enrolled in training for the skill
+	TrainedBelowLevel int     `json:"trained_below_level" example:"0"` // Of the gap, completed training but not rated at the level yet: due for reassessment
+	TrainingNeeds     int     `json:"training_needs" example:"2"`      // Of the gap, without any training: what L&D has to plan for
+}
+
+// Need is one employee below the required level in a skill.
+type Need struct {
+	EmployeeID    uint
+	DisplayName   string
+	JobTitle      string
+	DivisionID    *uint
+	Division      string
+	SkillID       uint
+	Skill         string
+	Level         int // 0 = unrated
+	RequiredLevel int
+	Training      TrainingStatus
+}
+
+// reportEmployee is an employee as loaded for the gap report.
+type reportEmployee struct {
+	ID         uint
+	JobTitle   string
+	DivisionID *uint
+}
+
+// gapKey identifies a row of the gap report.
+type gapKey struct {
+	division uint // 0 = no division
+	skill    uint
+}
+
+// buildGap computes the gap report from the organization's employees, requirements, ratings and
+// training. Names are filled in by the caller.
+func buildGap(employees []reportEmployee, requirements []Requirement, levels map[uint]map[uint]int,
+	training map[uint]map[uint]TrainingStatus) ([]GapRow, []Need) {
+	byTitle := make(map[string][]Requirement)
+	for _, r := range requirements {
+		title := normalizeTitle(r.JobTitle)
+		byTitle[title] = append(byTitle[title], r)
+	}
+	rows := make(map[gapKey]*GapRow)
+	shortfall := make(map[gapKey]int)
+	var needs []Need
+	for _, e := range employees {
+		for skillID, minLevel := range applicable(byTitle[normalizeTitle(e.JobTitle)], e.DivisionID) {
+			key := gapKey{skill: skillID}
+			if e.DivisionID != nil {
+				key.division = *e.DivisionID
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &GapRow{DivisionID: e.DivisionID, SkillID: skillID}
+				rows[key] = row
+			}
+			row.Required++
+			level := levels[e.ID][skillID]
+			if level >= minLevel {
+				row.Meeting++
+				continue
+			}
+			row.Gap++
+			if level == 0 {
+				row.Unrated++
+			}
+			shortfall[key] += minLevel - level
+			status := training[e.ID][skillID]
+			switch status {
+			case TrainingEnrolled:
+				row.InTraining++
+			case TrainingCompleted:
+				row.TrainedBelowLevel++
+			default:
+				row.TrainingNeeds++
+			}
+			needs = append(needs, Need{
+				EmployeeID: e.ID, JobTitle: e.JobTitle, DivisionID: e.DivisionID, SkillID: skillID,
+				Level: level, RequiredLevel: minLevel, Training: status,
+			})
+		}
+	}
+	result := make([]GapRow, 0, len(rows))
+	for key, row := range rows {
+		if row.Gap > 0 {
+			row.AverageShortfall = float64(shortfall[key]) / float64(row.Gap)
+		}
+		result = append(result, *row)
+	}
+	return result, needs
+}
+
+// applicable returns the level each skill requires of an employee with the job title in the division:
+// the division's requirement where there is one, otherwise the organization-wide one.
+func applicable(requirements []Requirement, divisionID *uint) map[uint]int {
+	levels := make(map[uint]int)
+	for _, r := range requirements {
+		if r.DivisionID == nil {
+			if _, ok := levels[r.SkillID]; !ok {
+				levels[r.SkillID] = r.MinLevel
+			}
+		}
+	}
+	for _, r := range requirements {
+		if r.DivisionID != nil && divisionID != nil && *r.DivisionID == *divisionID {
+			levels[r.SkillID] = r.MinLevel
+		}
+	}
+	return levels
+}
+
+// sortGap orders rows by division and skill name; employees outside any division come last.
+func sortGap(rows []GapRow) {
+	slices.SortFunc(rows, func(a, b GapRow) int {
+		if (a.DivisionID == nil) != (b.DivisionID == nil) {
+			if a.DivisionID == nil {
+				return 1
+			}
+			return -1
+		}
+		if c := strings.Compare(a.Division, b.Division); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Skill, b.Skill); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.SkillID, b.SkillID)
+	})
+}
+
+// normalizeTitle makes job titles comparable: requirements match employees regardless of case and
+// surrounding spaces.
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
+
+// gapHeader and gapRecord lay out the per-division export.
+var gapHeader = []string{
+	"division_id", "division", "skill_id", "skill", "category", "required", "meeting", "gap", "unrated",
+	"average_shortfall", "in_training", "trained_below_level", "training_needs",
+}
+
+func gapRecord(r GapRow) []string {
+	return []string{
+		formatID(r.DivisionID), r.Division, fmt.Sprintf("%d", r.SkillID), r.Skill, r.Category,
+		fmt.Sprintf("%d", r.Required), fmt.Sprintf("%d", r.Meeting), fmt.Sprintf("%d", r.Gap), fmt.Sprintf("%d", r.Unrated),
+		fmt.Sprintf("%.2f", r.AverageShortfall), fmt.Sprintf("%d", r.InTraining), fmt.Sprintf("%d", r.TrainedBelowLevel),
+		fmt.Sprintf("%d", r.TrainingNeeds),
+	}
+}
+
+// needHeader and needRecord lay out the per-employee export.
+var needHeader = []string{
+	"employee_id", "name", "job_title", "division_id", "division", "skill_id", "skill", "level", "required_level", "training",
+}
+
+func needRecord(n Need) []string {
+	return []string{
+		fmt.Sprintf("%d", n.EmployeeID), n.DisplayName, n.JobTitle, formatID(n.DivisionID), n.Division,
+		fmt.Sprintf("%d", n.SkillID), n.Skill, fmt.Sprintf("%d", n.Level), fmt.Sprintf("%d", n.RequiredLevel), string(n.Training),
+	}
+}
+
+func formatID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *id)
+}
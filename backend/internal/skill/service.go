@@ -0,0 +1,564 @@
+// prometheus/backend/internal/skill/service.go
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidSkill is returned for skills, ratings and requirements that fail validation.
+	ErrInvalidSkill = errors.New("invalid skill")
+	// ErrNameTaken is returned when the organization already has a skill with the name.
+	ErrNameTaken = errors.New("skill name already taken")
+	// ErrDuplicateRequirement is returned when the job title already has a requirement for the skill in the
+	// same division, or organization-wide.
+	ErrDuplicateRequirement = errors.New("the job title already requires this skill")
+)
+
+// Service manages the skill catalog, the skill matrix of employees' levels, the levels job titles
+// require, and the gap report comparing them. orgID scopes every call to one organization (nil = platform
+// users, outside any organization).
+type Service interface {
+	Skills(orgID *uint) ([]Skill, error)
+	GetSkill(orgID *uint, id uint) (*Skill, error)
+	CreateSkill(actor audit.Actor, orgID *uint, req SkillRequest) (*Skill, error)
+	UpdateSkill(actor audit.Actor, orgID *uint, id, expectedVersion uint, req SkillRequest) (*Skill, error)
+	// DeleteSkill removes the skill with its ratings and requirements.
+	DeleteSkill(actor audit.Actor, orgID *uint, id uint) error
+
+	// Rate sets an employee's level in a skill.
+	Rate(actor audit.Actor, orgID *uint, req RatingRequest) (*Rating, error)
+	DeleteRating(actor audit.Actor, orgID *uint, id uint) error
+	// Matrix returns employees' levels across skills, a page of employees at a time.
+	Matrix(orgID *uint, query MatrixQuery, page utils.Pagination) (*Matrix, int64, error)
+
+	Requirements(orgID *uint, filter RequirementFilter) ([]Requirement, error)
+	CreateRequirement(actor audit.Actor, orgID *uint, req RequirementRequest) (*Requirement, error)
+	UpdateRequirement(actor audit.Actor, orgID *uint, id uint, req RequirementRequest) (*Requirement, error)
+	DeleteRequirement(actor audit.Actor, orgID *uint, id uint) error
+
+	// Gap compares the levels employees have with the levels their job titles require, per division and
+	// skill, and counts who is in training for the gap.
+	Gap(orgID *uint, query GapQuery) (*GapReport, error)
+	// UseTraining makes the gap report take training into account.
+	UseTraining(records TrainingRecords)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+
+	mu       sync.RWMutex
+	training TrainingRecords
+}
+
+// NewService creates a new instance of Service. Employee names are resolved through employees; the gap
+// report reads through reporting (see database.ConnectReportingDB).
+func NewService(db, reporting *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, reporting: reporting, employees: employees, auditor: auditor}
+}
+
+func (s *service) Skills(orgID *uint) ([]Skill, error) {
+	skills := []Skill{}
+	if err := utils.OrgScope(s.db, orgID).Order("category, name").Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+	return skills, nil
+}
+
+func (s *service) GetSkill(orgID *uint, id uint) (*Skill, error) {
+	var skill Skill
+	if err := utils.OrgScope(s.db, orgID).First(&skill, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &skill, nil
+}
+
+func (s *service) CreateSkill(actor audit.Actor, orgID *uint, req SkillRequest) (*Skill, error) {
+	skill := Skill{OrganizationID: orgID}
+	applySkill(&skill, req)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkName(tx, &skill); err != nil {
+			return err
+		}
+		if err := tx.Create(&skill).Error; err != nil {
+			return fmt.Errorf("failed to create skill: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill.create", EntityType: "skill", EntityID: fmt.Sprintf("%d", skill.ID), After: skill,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+// UpdateSkill replaces the skill's fields if it is still at expectedVersion (optimistic locking).
+func (s *service) UpdateSkill(actor audit.Actor, orgID *uint, id, expectedVersion uint, req SkillRequest) (*Skill, error) {
+	var updated Skill
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Skill
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		skill := before
+		applySkill(&skill, req)
+		if err := checkName(tx, &skill); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Skill{}, id, expectedVersion, map[string]interface{}{
+			"name":        skill.Name,
+			"category":    skill.Category,
+			"description": skill.Description,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload skill %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill.update", EntityType: "skill", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteSkill(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Skill
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("skill_id = ?", id).Delete(&Rating{}).Error; err != nil {
+			return fmt.Errorf("failed to delete ratings of skill %d: %w", id, err)
+		}
+		if err := tx.Where("skill_id = ?", id).Delete(&Requirement{}).Error; err != nil {
+			return fmt.Errorf("failed to delete requirements of skill %d: %w", id, err)
+		}
+		if err := tx.Delete(&Skill{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete skill %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill.delete", EntityType: "skill", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+// Rate replaces any earlier rating of the employee in the skill.
+func (s *service) Rate(actor audit.Actor, orgID *uint, req RatingRequest) (*Rating, error) {
+	var rating Rating
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkSkill(tx, orgID, req.SkillID); err != nil {
+			return err
+		}
+		if err := checkEmployee(tx, orgID, req.EmployeeID); err != nil {
+			return err
+		}
+		var before *Rating
+		var existing []Rating
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("employee_id = ? AND skill_id = ?", req.EmployeeID, req.SkillID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to load rating: %w", err)
+		}
+		if len(existing) > 0 {
+			before, rating = &existing[0], existing[0]
+		}
+		rating.OrganizationID = orgID
+		rating.EmployeeID = req.EmployeeID
+		rating.SkillID = req.SkillID
+		rating.Level = req.Level
+		rating.Note = strings.TrimSpace(req.Note)
+		rating.AssessedBy = actor.UserID
+		rating.AssessedAt = clock.Now().UTC()
+		// The unique index settles two first ratings racing; the loser fails rather than overwrites.
+		if err := tx.Save(&rating).Error; err != nil {
+			return fmt.Errorf("failed to save rating: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill_rating.set", EntityType: "skill_rating", EntityID: fmt.Sprintf("%d", rating.ID), Before: before, After: rating,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (s *service) DeleteRating(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Rating
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Rating{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete rating %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill_rating.delete", EntityType: "skill_rating", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) Matrix(orgID *uint, query MatrixQuery, page utils.Pagination) (*Matrix, int64, error) {
+	skillQuery := utils.OrgScope(s.db, orgID)
+	if len(query.SkillIDs) > 0 {
+		skillQuery = skillQuery.Where("id IN ?", query.SkillIDs)
+	}
+	matrix := &Matrix{Skills: []Skill{}, Employees: []MatrixRow{}}
+	if err := skillQuery.Order("category, name").Find(&matrix.Skills).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load skills: %w", err)
+	}
+	employeeQuery := employeesOf(s.db, orgID, query.DivisionID)
+	var total int64
+	if err := employeeQuery.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count employees: %w", err)
+	}
+	var employees []reportEmployee
+	if err := employeeQuery.Select("id, job_title, division_id").Order("id").Scopes(page.Scope).Scan(&employees).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load employees: %w", err)
+	}
+	if len(employees) == 0 || len(matrix.Skills) == 0 {
+		for _, e := range employees {
+			matrix.Employees = append(matrix.Employees, MatrixRow{EmployeeID: e.ID, JobTitle: e.JobTitle, DivisionID: e.DivisionID, Levels: map[uint]int{}})
+		}
+		return s.named(orgID, matrix, total)
+	}
+	employeeIDs := make([]uint, len(employees))
+	for i, e := range employees {
+		employeeIDs[i] = e.ID
+	}
+	skillIDs := make([]uint, len(matrix.Skills))
+	for i, skill := range matrix.Skills {
+		skillIDs[i] = skill.ID
+	}
+	levels, err := loadLevels(s.db.Where("skill_id IN ?", skillIDs), employeeIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, e := range employees {
+		row := MatrixRow{EmployeeID: e.ID, JobTitle: e.JobTitle, DivisionID: e.DivisionID, Levels: levels[e.ID]}
+		if row.Levels == nil {
+			row.Levels = map[uint]int{}
+		}
+		matrix.Employees = append(matrix.Employees, row)
+	}
+	return s.named(orgID, matrix, total)
+}
+
+// named fills in the display names of the matrix's employees.
+func (s *service) named(orgID *uint, matrix *Matrix, total int64) (*Matrix, int64, error) {
+	ids := make([]uint, len(matrix.Employees))
+	for i, row := range matrix.Employees {
+		ids[i] = row.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range matrix.Employees {
+		matrix.Employees[i].DisplayName = names[matrix.Employees[i].EmployeeID].Text
+	}
+	return matrix, total, nil
+}
+
+func (s *service) Requirements(orgID *uint, filter RequirementFilter) ([]Requirement, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if title := strings.TrimSpace(filter.JobTitle); title != "" {
+		query = query.Where("LOWER(job_title) = ?", normalizeTitle(title))
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	if filter.SkillID != nil {
+		query = query.Where("skill_id = ?", *filter.SkillID)
+	}
+	requirements := []Requirement{}
+	if err := query.Order("job_title, division_id NULLS FIRST, skill_id").Find(&requirements).Error; err != nil {
+		return nil, fmt.Errorf("failed to list requirements: %w", err)
+	}
+	return requirements, nil
+}
+
+func (s *service) CreateRequirement(actor audit.Actor, orgID *uint, req RequirementRequest) (*Requirement, error) {
+	requirement := Requirement{OrganizationID: orgID}
+	applyRequirement(&requirement, req)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkRequirement(tx, &requirement); err != nil {
+			return err
+		}
+		if err := tx.Create(&requirement).Error; err != nil {
+			return fmt.Errorf("failed to create requirement: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill_requirement.create", EntityType: "skill_requirement", EntityID: fmt.Sprintf("%d", requirement.ID), After: requirement,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &requirement, nil
+}
+
+func (s *service) UpdateRequirement(actor audit.Actor, orgID *uint, id uint, req RequirementRequest) (*Requirement, error) {
+	var updated Requirement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Requirement
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		updated = before
+		applyRequirement(&updated, req)
+		if err := s.checkRequirement(tx, &updated); err != nil {
+			return err
+		}
+		if err := tx.Save(&updated).Error; err != nil {
+			return fmt.Errorf("failed to update requirement %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill_requirement.update", EntityType: "skill_requirement", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) DeleteRequirement(actor audit.Actor, orgID *uint, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Requirement
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Requirement{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete requirement %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "skill_requirement.delete", EntityType: "skill_requirement", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+// Gap loads the organization's employees, requirements, ratings and training in a handful of queries and
+// compares them in memory.
+func (s *service) Gap(orgID *uint, query GapQuery) (*GapReport, error) {
+	var employees []reportEmployee
+	if err := employeesOf(s.reporting, orgID, query.DivisionID).Select("id, job_title, division_id").Scan(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+	requirementQuery := utils.OrgScope(s.reporting, orgID)
+	if query.SkillID != nil {
+		requirementQuery = requirementQuery.Where("skill_id = ?", *query.SkillID)
+	}
+	var requirements []Requirement
+	if err := requirementQuery.Find(&requirements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load requirements: %w", err)
+	}
+	employeeIDs := make([]uint, len(employees))
+	for i, e := range employees {
+		employeeIDs[i] = e.ID
+	}
+	levels, err := loadLevels(s.reporting, employeeIDs)
+	if err != nil {
+		return nil, err
+	}
+	var training map[uint]map[uint]TrainingStatus
+	s.mu.RLock()
+	records := s.training
+	s.mu.RUnlock()
+	if records != nil && len(employeeIDs) > 0 {
+		if training, err = records.SkillTraining(s.reporting, orgID, employeeIDs); err != nil {
+			return nil, fmt.Errorf("failed to load training: %w", err)
+		}
+	}
+	rows, needs := buildGap(employees, requirements, levels, training)
+	if err := s.label(orgID, rows, needs); err != nil {
+		return nil, err
+	}
+	sortGap(rows)
+	return &GapReport{GeneratedAt: clock.Now().UTC(), Rows: rows, Needs: needs}, nil
+}
+
+// label fills in the names of the divisions, skills and employees of a gap report.
+func (s *service) label(orgID *uint, rows []GapRow, needs []Need) error {
+	var divisions []struct {
+		ID   uint
+		Name string
+	}
+	// Queried by table name: the division package builds on the employee package, not this one.
+	if err := utils.OrgScope(s.reporting.Table("divisions").Select("id, name").Where("deleted_at IS NULL"), orgID).Scan(&divisions).Error; err != nil {
+		return fmt.Errorf("failed to load divisions: %w", err)
+	}
+	divisionNames := make(map[uint]string, len(divisions))
+	for _, d := range divisions {
+		divisionNames[d.ID] = d.Name
+	}
+	skills, err := s.Skills(orgID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]Skill, len(skills))
+	for _, skill := range skills {
+		byID[skill.ID] = skill
+	}
+	division := func(id *uint) string {
+		if id == nil {
+			return ""
+		}
+		return divisionNames[*id]
+	}
+	for i := range rows {
+		rows[i].Division = division(rows[i].DivisionID)
+		rows[i].Skill, rows[i].Category = byID[rows[i].SkillID].Name, byID[rows[i].SkillID].Category
+	}
+	ids := make([]uint, 0, len(needs))
+	for _, n := range needs {
+		ids = append(ids, n.EmployeeID)
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range needs {
+		needs[i].Division = division(needs[i].DivisionID)
+		needs[i].Skill = byID[needs[i].SkillID].Name
+		needs[i].DisplayName = names[needs[i].EmployeeID].Text
+	}
+	return nil
+}
+
+func (s *service) UseTraining(records TrainingRecords) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.training = records
+}
+
+// checkRequirement checks the skill and division are the organization's, and that the job title doesn't
+// require the skill twice in the same division.
+func (s *service) checkRequirement(tx *gorm.DB, r *Requirement) error {
+	if err := checkSkill(tx, r.OrganizationID, r.SkillID); err != nil {
+		return err
+	}
+	if r.DivisionID != nil {
+		var count int64
+		if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", *r.DivisionID), r.OrganizationID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check division: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: division %d not found", ErrInvalidSkill, *r.DivisionID)
+		}
+	}
+	// Serializes requirement changes of a job title, so the duplicate check holds.
+	if err := lock.Tx(tx, "skill-requirement:"+normalizeTitle(r.JobTitle)); err != nil {
+		return err
+	}
+	query := utils.OrgScope(tx.Model(&Requirement{}), r.OrganizationID).
+		Where("LOWER(job_title) = ? AND skill_id = ? AND id <> ?", normalizeTitle(r.JobTitle), r.SkillID, r.ID)
+	if r.DivisionID == nil {
+		query = query.Where("division_id IS NULL")
+	} else {
+		query = query.Where("division_id = ?", *r.DivisionID)
+	}
+	var duplicates int64
+	if err := query.Count(&duplicates).Error; err != nil {
+		return fmt.Errorf("failed to check requirements: %w", err)
+	}
+	if duplicates > 0 {
+		return ErrDuplicateRequirement
+	}
+	return nil
+}
+
+// checkName checks no other skill of the organization has the name, ignoring case.
+func checkName(tx *gorm.DB, skill *Skill) error {
+	var taken int64
+	query := tx.Model(&Skill{}).Where("LOWER(name) = LOWER(?) AND id <> ?", skill.Name, skill.ID)
+	if err := utils.OrgScope(query, skill.OrganizationID).Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check skill names: %w", err)
+	}
+	if taken > 0 {
+		return ErrNameTaken
+	}
+	return nil
+}
+
+func checkSkill(tx *gorm.DB, orgID *uint, id uint) error {
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Skill{}), orgID).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check skill: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: skill %d not found", ErrInvalidSkill, id)
+	}
+	return nil
+}
+
+func checkEmployee(tx *gorm.DB, orgID *uint, id uint) error {
+	var count int64
+	if err := employeesOf(tx, orgID, nil).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check employee: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: employee %d not found", ErrInvalidSkill, id)
+	}
+	return nil
+}
+
+// employeesOf selects the organization's current employees, optionally of one division.
+func employeesOf(db *gorm.DB, orgID *uint, divisionID *uint) *gorm.DB {
+	query := utils.OrgScope(db.Table("employees").Where("deleted_at IS NULL"), orgID)
+	if divisionID != nil {
+		query = query.Where("division_id = ?", *divisionID)
+	}
+	return query
+}
+
+// loadLevels returns the employees' rated levels, by employee ID and then skill ID.
+func loadLevels(db *gorm.DB, employeeIDs []uint) (map[uint]map[uint]int, error) {
+	levels := make(map[uint]map[uint]int)
+	if len(employeeIDs) == 0 {
+		return levels, nil
+	}
+	var ratings []Rating
+	if err := db.Where("employee_id IN ?", employeeIDs).Find(&ratings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ratings: %w", err)
+	}
+	for _, r := range ratings {
+		if levels[r.EmployeeID] == nil {
+			levels[r.EmployeeID] = make(map[uint]int)
+		}
+		levels[r.EmployeeID][r.SkillID] = r.Level
+	}
+	return levels, nil
+}
+
+func applySkill(skill *Skill, req SkillRequest) {
+	skill.Name = strings.TrimSpace(req.Name)
+	skill.Category = strings.TrimSpace(req.Category)
+	skill.Description = strings.TrimSpace(req.Description)
+}
+
+func applyRequirement(r *Requirement, req RequirementRequest) {
+	r.JobTitle = strings.TrimSpace(req.JobTitle)
+	r.DivisionID = req.DivisionID
+	r.SkillID = req.SkillID
+	r.MinLevel = req.MinLevel
+}
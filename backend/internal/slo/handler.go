@@ -0,0 +1,36 @@
+// prometheus/backend/internal/slo/handler.go
+package slo
+
+import (
+	"net/http"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for latency objectives.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Report returns the latency of every route with recent requests against its objective.
+// @Summary Report route latency against objectives
+// @Description Each route's p95 over the last 10 minutes across all instances, breaching routes first. Routes
+// @Description with fewer than 20 requests in the window have no p95 and never breach.
+// @Tags SLO
+// @Produce json
+// @Success 200 {object} Report
+// @Router /admin/slo [get]
+func (h *Handler) Report(c *gin.Context) {
+	report, err := h.service.Report(c.Request.Context())
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "SLO report fetched successfully", report)
+}
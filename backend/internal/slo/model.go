@@ -0,0 +1,50 @@
+// prometheus/backend/internal/slo/model.go
+package slo
+
+import "time"
+
+// Sample counts the requests to a route in one minute whose latency fell in one bucket of bounds. Every
+// instance adds its own requests, so the samples cover the whole deployment.
+type Sample struct {
+	Minute time.Time `gorm:"primaryKey"`
+	Method string    `gorm:"type:varchar(10);primaryKey"`
+	Route  string    `gorm:"type:varchar(255);primaryKey"`
+	Bucket int       `gorm:"primaryKey"` // Index in bounds; len(bounds) is the bucket above the last bound
+	Count  int64     `gorm:"not null"`
+}
+
+// TableName keeps the samples next to the breaches.
+func (Sample) TableName() string { return "slo_samples" }
+
+// Breach is a route over its latency budget, from the evaluation that found it over until it recovers.
+type Breach struct {
+	Method string    `gorm:"type:varchar(10);primaryKey" json:"method" example:"GET"`
+	Route  string    `gorm:"type:varchar(255);primaryKey" json:"route" example:"/api/v1/employees"`
+	Since  time.Time `gorm:"not null" json:"since"`
+	// Latency and traffic found when the breach started
+	P95Ms    float64 `json:"p95_ms" example:"812"`
+	TargetMs float64 `json:"target_ms" example:"300"`
+	Requests int64   `json:"requests" example:"420"`
+}
+
+// TableName keeps the breaches next to the samples.
+func (Breach) TableName() string { return "slo_breaches" }
+
+// RouteStatus is a route's latency over the window against its objective.
+type RouteStatus struct {
+	Method   string     `json:"method" example:"GET"`
+	Route    string     `json:"route" example:"/api/v1/employees"`
+	TargetMs float64    `json:"target_ms" example:"300"`          // 0 when the route has no objective
+	P95Ms    *float64   `json:"p95_ms,omitempty" example:"212.5"` // Missing below minRequests
+	Requests int64      `json:"requests" example:"1280"`
+	Budget   float64    `json:"budget_used" example:"0.71"` // P95 over target; above 1 the route breaches
+	Breached bool       `json:"breached"`
+	Since    *time.Time `json:"breached_since,omitempty"`
+}
+
+// Report is the latency of every route with requests in the window, breaching routes first.
+type Report struct {
+	Window          string        `json:"window" example:"10m0s"`
+	DefaultTargetMs float64       `json:"default_target_ms" example:"1000"` // 0 when only listed routes have objectives
+	Routes          []RouteStatus `json:"routes"`
+}
@@ -0,0 +1,78 @@
+// prometheus/backend/internal/slo/module.go
+package slo
+
+import (
+	"context"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModuleName is the name of the SLO module.
+const ModuleName = "slo"
+
+// evaluateInterval is how often breaches are looked for; a breach is notified within about this delay.
+const evaluateInterval = time.Minute
+
+// sloModule owns the latency samples and breaches of the routes' objectives.
+type sloModule struct {
+	db      *gorm.DB
+	service Service
+	handler *Handler
+}
+
+// NewModule creates the SLO module for the module registry.
+func NewModule(db *gorm.DB, svc Service) module.Module {
+	return &sloModule{db: db, service: svc, handler: NewHandler(svc)}
+}
+
+func (m *sloModule) Name() string { return ModuleName }
+
+// HealthContributors implements module.Module. A breaching route degrades the module without failing
+// readiness: the instance still serves, just slower than it should.
+func (m *sloModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("budgets", func(ctx context.Context) module.HealthResult {
+			var breaches []Breach
+			if err := m.db.WithContext(ctx).Order("since").Find(&breaches).Error; err != nil {
+				return module.HealthResult{Status: module.StatusDown, Error: err.Error()}
+			}
+			result := module.HealthResult{Status: module.StatusUp, Metrics: map[string]float64{"breached_routes": float64(len(breaches))}}
+			if len(breaches) > 0 {
+				routes := make([]string, len(breaches))
+				for i, b := range breaches {
+					routes[i] = b.Method + " " + b.Route
+				}
+				result.Status = module.StatusDegraded
+				result.Details = map[string]any{"breached": routes}
+			}
+			return result
+		}),
+	}
+}
+
+// Models implements module.Migrator.
+func (m *sloModule) Models() []any {
+	return []any{&Sample{}, &Breach{}}
+}
+
+// RegisterJobs implements jobs.Contributor.
+func (m *sloModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobEvaluate, func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		started, ended, err := m.service.Evaluate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"started": started, "ended": ended}, nil
+	})
+	q.Every(JobEvaluate, evaluateInterval)
+}
+
+// RegisterRoutes implements routing.Contributor. Objectives cover the whole deployment, so only platform
+// administrators see them.
+func (m *sloModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/admin/slo", routing.Roles("god-admin"), m.handler.Report)
+}
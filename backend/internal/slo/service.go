@@ -0,0 +1,205 @@
+// prometheus/backend/internal/slo/service.go
+package slo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/notification"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobEvaluate is the job type comparing the routes' latency with their objectives, run every minute.
+const JobEvaluate = "slo.evaluate"
+
+const (
+	// window is the period a route's p95 is measured over. Only complete minutes count.
+	window = 10 * time.Minute
+	// minRequests keeps routes with a handful of requests in the window from breaching on one slow call.
+	minRequests = 20
+	// sampleRetention is how long samples are kept.
+	sampleRetention = 48 * time.Hour
+	// godAdminRole is notified of breaches: objectives are set per deployment, not per organization.
+	godAdminRole = "god-admin"
+)
+
+// Service measures the routes' latency against their objectives. Objectives track the p95 of a route over
+// the last window, across every instance.
+type Service interface {
+	// Report returns the latency of the routes with requests in the window.
+	Report(ctx context.Context) (*Report, error)
+	// Evaluate records the routes starting and ending a breach, notifies god-admins of the new ones and drops
+	// samples older than sampleRetention. It returns how many breaches started and ended.
+	Evaluate(ctx context.Context) (started, ended int, err error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db         *gorm.DB
+	objectives Objectives
+}
+
+// NewService creates a new instance of Service.
+func NewService(db *gorm.DB, objectives Objectives) Service {
+	return &service{db: db, objectives: objectives}
+}
+
+func (s *service) Report(ctx context.Context) (*Report, error) {
+	routes, err := s.measure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var breaches []Breach
+	if err := s.db.WithContext(ctx).Find(&breaches).Error; err != nil {
+		return nil, fmt.Errorf("failed to load SLO breaches: %w", err)
+	}
+	since := make(map[key]time.Time, len(breaches))
+	for _, b := range breaches {
+		since[key{method: b.Method, route: b.Route}] = b.Since
+	}
+	for i := range routes {
+		if t, ok := since[key{method: routes[i].Method, route: routes[i].Route}]; ok {
+			routes[i].Since = &t
+		}
+	}
+	return &Report{Window: window.String(), DefaultTargetMs: milliseconds(s.objectives.Default), Routes: routes}, nil
+}
+
+func (s *service) Evaluate(ctx context.Context) (int, int, error) {
+	db := s.db.WithContext(ctx)
+	if err := db.Where("minute < ?", clock.Now().UTC().Add(-sampleRetention)).Delete(&Sample{}).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to purge latency samples: %w", err)
+	}
+	routes, err := s.measure(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	breaching := make(map[key]RouteStatus)
+	for _, r := range routes {
+		if r.Breached {
+			breaching[key{method: r.Method, route: r.Route}] = r
+		}
+	}
+	started, ended := 0, 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var current []Breach
+		if err := tx.Find(&current).Error; err != nil {
+			return fmt.Errorf("failed to load SLO breaches: %w", err)
+		}
+		for _, b := range current {
+			k := key{method: b.Method, route: b.Route}
+			if _, ok := breaching[k]; ok {
+				delete(breaching, k) // Still breaching, already notified
+				continue
+			}
+			if err := tx.Delete(&b).Error; err != nil {
+				return fmt.Errorf("failed to end SLO breach: %w", err)
+			}
+			log.Printf("SLO: %s %s is back within its latency budget.", b.Method, b.Route)
+			ended++
+		}
+		if len(breaching) == 0 {
+			return nil
+		}
+		var recipients []uint
+		if err := tx.Model(&auth.User{}).Distinct("users.id").
+			Joins("JOIN user_roles ON user_roles.user_id = users.id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ? AND users.is_active", godAdminRole).Pluck("users.id", &recipients).Error; err != nil {
+			return fmt.Errorf("failed to find god-admins: %w", err)
+		}
+		now := clock.Now().UTC()
+		var notices []notification.Notice
+		for k, r := range breaching {
+			breach := Breach{Method: k.method, Route: k.route, Since: now, P95Ms: *r.P95Ms, TargetMs: r.TargetMs, Requests: r.Requests}
+			if err := tx.Create(&breach).Error; err != nil {
+				return fmt.Errorf("failed to record SLO breach: %w", err)
+			}
+			log.Printf("SLO: %s %s breaches its latency budget: p95 %.0f ms against %.0f ms.", k.method, k.route, breach.P95Ms, breach.TargetMs)
+			for _, id := range recipients {
+				notices = append(notices, notification.Notice{
+					UserID:   id,
+					Category: "slo.breach",
+					Subject:  fmt.Sprintf("%s %s is over its latency budget", k.method, k.route),
+					Body: fmt.Sprintf("The p95 latency was %.0f ms over the last %s (%d requests), against a target of %.0f ms.",
+						breach.P95Ms, window, breach.Requests, breach.TargetMs),
+					Link: "/admin/slo",
+				})
+			}
+			started++
+		}
+		return notification.CreateTx(tx, notices...)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return started, ended, nil
+}
+
+// measure computes the p95 of every route with requests in the window, breaching routes first, then by
+// budget used.
+func (s *service) measure(ctx context.Context) ([]RouteStatus, error) {
+	end := clock.Now().UTC().Truncate(time.Minute)
+	var rows []struct {
+		Method string
+		Route  string
+		Bucket int
+		Count  int64
+	}
+	if err := s.db.WithContext(ctx).Model(&Sample{}).Select("method, route, bucket, SUM(count) AS count").
+		Where("minute >= ? AND minute < ?", end.Add(-window), end).Group("method, route, bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load latency samples: %w", err)
+	}
+	histograms := make(map[key][]int64)
+	for _, row := range rows {
+		if row.Bucket < 0 || row.Bucket > len(bounds) {
+			continue // Written with other bounds
+		}
+		k := key{method: row.Method, route: row.Route}
+		if histograms[k] == nil {
+			histograms[k] = make([]int64, len(bounds)+1)
+		}
+		histograms[k][row.Bucket] += row.Count
+	}
+	routes := make([]RouteStatus, 0, len(histograms))
+	for k, buckets := range histograms {
+		status := RouteStatus{Method: k.method, Route: k.route, TargetMs: milliseconds(s.objectives.Target(k.method, k.route))}
+		for _, n := range buckets {
+			status.Requests += n
+		}
+		if status.Requests >= minRequests {
+			p95 := milliseconds(percentile(buckets, 0.95))
+			status.P95Ms = &p95
+			if status.TargetMs > 0 {
+				status.Budget = p95 / status.TargetMs
+				status.Breached = p95 > status.TargetMs
+			}
+		}
+		routes = append(routes, status)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Breached != b.Breached {
+			return a.Breached
+		}
+		if a.Budget != b.Budget {
+			return a.Budget > b.Budget
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Method < b.Method
+	})
+	return routes, nil
+}
+
+// milliseconds converts a duration for reports.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
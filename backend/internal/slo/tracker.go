@@ -0,0 +1,169 @@
+// prometheus/backend/internal/slo/tracker.go
+package slo
+
+import (
+	"fmt"
+	"log"
+	"prometheus/backend/internal/clock"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bounds are the upper bounds of the latency buckets samples are counted in. Percentiles are interpolated
+// within a bucket, so targets are best set on or between these bounds.
+var bounds = []time.Duration{
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond,
+	200 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond,
+	time.Second, 1500 * time.Millisecond, 2 * time.Second, 3 * time.Second, 5 * time.Second, 10 * time.Second,
+	30 * time.Second,
+}
+
+// Objectives are the latency targets of the routes: the p95 each route should stay under.
+type Objectives struct {
+	Default time.Duration            // Of routes not listed; 0 leaves them without an objective
+	Routes  map[string]time.Duration // By "METHOD /route/pattern"; 0 exempts a route from the default
+}
+
+// ParseObjectives reads SLO_TARGETS, comma-separated "METHOD /route/pattern=duration" pairs such as
+// "GET /api/v1/employees=300ms,POST /api/v1/reports/:id/run=5s".
+func ParseObjectives(spec string, defaultTarget time.Duration) (Objectives, error) {
+	objectives := Objectives{Default: defaultTarget, Routes: map[string]time.Duration{}}
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		route, target, ok := strings.Cut(pair, "=")
+		method, pattern, hasMethod := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasMethod || !strings.HasPrefix(strings.TrimSpace(pattern), "/") {
+			return Objectives{}, fmt.Errorf("invalid SLO target %q: expected \"METHOD /route=duration\"", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(target))
+		if err != nil || d < 0 {
+			return Objectives{}, fmt.Errorf("invalid SLO target %q: expected a duration such as 300ms", pair)
+		}
+		objectives.Routes[routeKey(method, strings.TrimSpace(pattern))] = d
+	}
+	return objectives, nil
+}
+
+// Target returns the p95 a route should stay under, 0 if it has no objective.
+func (o Objectives) Target(method, route string) time.Duration {
+	if target, ok := o.Routes[routeKey(method, route)]; ok {
+		return target
+	}
+	return o.Default
+}
+
+// Tracker counts request latencies per route and minute, and adds each minute's counts to the samples once
+// the minute is over. It implements middleware.LatencyObserver.
+type Tracker struct {
+	db *gorm.DB
+
+	mu     sync.Mutex
+	minute time.Time
+	counts map[key][]int64
+}
+
+// key identifies a route.
+type key struct {
+	method string
+	route  string
+}
+
+// NewTracker creates a Tracker saving its samples to db.
+func NewTracker(db *gorm.DB) *Tracker {
+	return &Tracker{db: db, counts: map[key][]int64{}}
+}
+
+// Observe counts a request. The first request of a minute saves the counts of the previous one in the
+// background, so an idle instance saves its last minute late, when it gets a request again.
+func (t *Tracker) Observe(method, route string, elapsed time.Duration) {
+	if route == "unmatched" {
+		return // Unknown paths would add a route per URL scanned
+	}
+	minute := clock.Now().UTC().Truncate(time.Minute)
+	t.mu.Lock()
+	var done map[key][]int64
+	doneMinute := t.minute
+	if !minute.Equal(t.minute) {
+		done, t.counts, t.minute = t.counts, map[key][]int64{}, minute
+	}
+	k := key{method: method, route: route}
+	counts := t.counts[k]
+	if counts == nil {
+		counts = make([]int64, len(bounds)+1)
+		t.counts[k] = counts
+	}
+	counts[bucket(elapsed)]++
+	t.mu.Unlock()
+	if len(done) > 0 {
+		go t.save(doneMinute, done)
+	}
+}
+
+// save adds a minute's counts to the samples. Samples are best effort; a failure only loses that minute.
+func (t *Tracker) save(minute time.Time, counts map[key][]int64) {
+	var samples []Sample
+	for k, buckets := range counts {
+		for i, n := range buckets {
+			if n > 0 {
+				samples = append(samples, Sample{Minute: minute, Method: k.method, Route: k.route, Bucket: i, Count: n})
+			}
+		}
+	}
+	if err := t.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "minute"}, {Name: "method"}, {Name: "route"}, {Name: "bucket"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "count"}, Value: gorm.Expr("slo_samples.count + excluded.count")},
+		},
+	}).CreateInBatches(samples, 500).Error; err != nil {
+		log.Printf("Failed to save the latency samples of %s: %v", minute.Format(time.RFC3339), err)
+	}
+}
+
+// bucket returns the index of the bucket counting a latency.
+func bucket(elapsed time.Duration) int {
+	for i, bound := range bounds {
+		if elapsed <= bound {
+			return i
+		}
+	}
+	return len(bounds)
+}
+
+// percentile interpolates the latency under which the fraction q of the requests counted in buckets fall,
+// assuming requests spread evenly within a bucket. Requests above the last bound count as at that bound.
+func percentile(buckets []int64, q float64) time.Duration {
+	var total int64
+	for _, n := range buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := q * float64(total)
+	var seen int64
+	for i, n := range buckets {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(bounds) {
+			return bounds[len(bounds)-1]
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = bounds[i-1]
+		}
+		return lower + time.Duration(float64(bounds[i]-lower)*(rank-float64(seen))/float64(n))
+	}
+	return bounds[len(bounds)-1]
+}
+
+func routeKey(method, route string) string {
+	return strings.ToUpper(strings.TrimSpace(method)) + " " + route
+}
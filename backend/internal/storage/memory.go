@@ -0,0 +1,60 @@
+// prometheus/backend/internal/storage/memory.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// memoryObject is an object kept by memoryStorage.
+type memoryObject struct {
+	data        []byte
+	contentType string
+}
+
+// memoryStorage keeps objects in memory, for the fake integration mode; they are gone after a restart.
+type memoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewMemoryStorage creates an empty in-memory Storage.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{objects: make(map[string]memoryObject)}
+}
+
+func (s *memoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{data: data, contentType: contentType}
+	return nil
+}
+
+func (s *memoryStorage) Open(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), obj.contentType, nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "", ErrSignedURLUnsupported
+}
@@ -0,0 +1,87 @@
+// prometheus/backend/internal/storage/s3.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"prometheus/backend/config"
+	"prometheus/backend/internal/outbound"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3Storage keeps objects in an S3 bucket (or an S3-compatible service such as MinIO).
+type s3Storage struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	bucket  string
+}
+
+// NewS3Storage creates a Storage on cfg.S3Bucket. Credentials come from the default AWS chain
+// (environment, shared config, instance role).
+func NewS3Storage(ctx context.Context, cfg *config.Config) (Storage, error) {
+	if cfg.S3Bucket == "" {
+		return nil, errors.New("S3_BUCKET is required for the s3 storage backend")
+	}
+	// The SDK retries on its own; the shared client adds the circuit breaker, timeout and metrics.
+	httpClient := outbound.NewClient("s3", outbound.Policy{Timeout: time.Minute, MaxAttempts: 1})
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.S3Region), awsconfig.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.S3Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.S3Endpoint)
+			o.UsePathStyle = true // MinIO and most S3-compatible services
+		}
+	})
+	return &s3Storage{client: client, presign: s3.NewPresignClient(client), bucket: cfg.S3Bucket}, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          r,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3Storage) Open(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		var missing *types.NoSuchKey
+		if errors.As(err, &missing) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return out.Body, aws.ToString(out.ContentType), nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)},
+		s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL for %s: %w", key, err)
+	}
+	return req.URL, nil
+}
@@ -0,0 +1,46 @@
+// prometheus/backend/internal/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"prometheus/backend/config"
+	"time"
+)
+
+// ErrNotFound is returned when an object doesn't exist.
+var ErrNotFound = errors.New("object not found")
+
+// ErrSignedURLUnsupported is returned by backends that can't hand out direct links; their objects are
+// served through the API instead.
+var ErrSignedURLUnsupported = errors.New("storage backend does not support signed URLs")
+
+// Storage stores uploaded files (avatars, documents, ...) under slash-separated keys.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object's content and content type. The caller closes the reader.
+	Open(ctx context.Context, key string) (io.ReadCloser, string, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited direct link to the object, or ErrSignedURLUnsupported.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// New creates the Storage selected by STORAGE_BACKEND ("local", "s3" or "memory"). The fake integration
+// mode always uses memory.
+func New(ctx context.Context, cfg *config.Config) (Storage, error) {
+	if cfg.DevIntegrations == config.IntegrationsFake {
+		return NewMemoryStorage(), nil
+	}
+	switch cfg.StorageBackend {
+	case "", "local":
+		return NewLocalStorage(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3Storage(ctx, cfg)
+	case "memory":
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
@@ -0,0 +1,69 @@
+// prometheus/backend/internal/talent/grid.go
+package talent
+
+// Scores of at least these are moderate and high; below moderateFrom is low.
+const (
+	moderateFrom = 2.5
+	highFrom     = 3.75
+)
+
+// bandRank orders the bands along an axis.
+var bandRank = map[Band]int{BandLow: 0, BandModerate: 1, BandHigh: 2}
+
+// bands lists the bands in rank order.
+var bands = []Band{BandLow, BandModerate, BandHigh}
+
+// boxLabels names the boxes, by potential and then performance.
+var boxLabels = map[Band]map[Band]string{
+	BandHigh:     {BandLow: "Enigma", BandModerate: "Growth employee", BandHigh: "Future leader"},
+	BandModerate: {BandLow: "Dilemma", BandModerate: "Core player", BandHigh: "High performer"},
+	BandLow:      {BandLow: "Underperformer", BandModerate: "Effective employee", BandHigh: "Trusted professional"},
+}
+
+// BandOf returns the band a review score falls in.
+func BandOf(score float64) Band {
+	switch {
+	case score >= highFrom:
+		return BandHigh
+	case score >= moderateFrom:
+		return BandModerate
+	default:
+		return BandLow
+	}
+}
+
+// BoxOf numbers the boxes from 1 (low performance, low potential) to 9 (high, high), performance first
+// within each potential band: 3 is high performance with low potential, 7 low performance with high potential.
+func BoxOf(performance, potential Band) int {
+	return bandRank[potential]*3 + bandRank[performance] + 1
+}
+
+// change describes moving from one box to another: up or down when performance and potential rise or fall
+// taken together, sideways when one rises as much as the other falls.
+func change(from, to Placement) string {
+	delta := bandRank[to.Performance] + bandRank[to.Potential] - bandRank[from.Performance] - bandRank[from.Potential]
+	switch {
+	case delta > 0:
+		return "up"
+	case delta < 0:
+		return "down"
+	case to.Performance == from.Performance && to.Potential == from.Potential:
+		return "same"
+	default:
+		return "sideways"
+	}
+}
+
+// emptyGrid returns the nine boxes, in order, without employees.
+func emptyGrid() []GridBox {
+	boxes := make([]GridBox, 0, 9)
+	for _, potential := range bands {
+		for _, performance := range bands {
+			boxes = append(boxes, GridBox{
+				Box: BoxOf(performance, potential), Label: boxLabels[potential][performance],
+				Performance: performance, Potential: potential, Employees: []Placement{},
+			})
+		}
+	}
+	return boxes
+}
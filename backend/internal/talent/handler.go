@@ -0,0 +1,408 @@
+// prometheus/backend/internal/talent/handler.go
+package talent
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for review cycles and the nine-box grid.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCycles returns the organization's review cycles, latest first.
+// @Summary List review cycles
+// @Tags Talent
+// @Produce json
+// @Success 200 {array} Cycle
+// @Router /hr/talent/cycles [get]
+func (h *Handler) ListCycles(c *gin.Context) {
+	cycles, err := h.service.Cycles(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Review cycles fetched successfully", cycles)
+}
+
+// GetCycle returns a review cycle. The ETag and Last-Modified headers can be sent back as If-Match /
+// If-Unmodified-Since.
+// @Summary Get a review cycle
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/talent/cycles/{id} [get]
+func (h *Handler) GetCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.GetCycle(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Review cycle fetched successfully", cycle)
+}
+
+// CreateCycle opens a review cycle.
+// @Summary Create a review cycle
+// @Tags Talent
+// @Accept json
+// @Produce json
+// @Param cycle body CycleRequest true "Cycle"
+// @Success 201 {object} Cycle
+// @Failure 400 {object} utils.ErrorResponse "Invalid cycle"
+// @Router /hr/talent/cycles [post]
+func (h *Handler) CreateCycle(c *gin.Context) {
+	var req CycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	cycle, err := h.service.CreateCycle(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Review cycle created successfully", cycle)
+}
+
+// UpdateCycle replaces the name and dates of a cycle that isn't closed.
+// @Summary Update a review cycle
+// @Tags Talent
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param cycle body CycleRequest true "Cycle"
+// @Success 200 {object} Cycle
+// @Failure 400 {object} utils.ErrorResponse "Invalid cycle"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle closed"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/talent/cycles/{id} [put]
+func (h *Handler) UpdateCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetCycle(orgID, id)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	cycle, err := h.service.UpdateCycle(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, cycle.UpdatedAt, cycle.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Review cycle updated successfully", cycle)
+}
+
+// StartCalibration freezes an open cycle's scores and opens its calibration session.
+// @Summary Start calibration
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not open"
+// @Router /hr/talent/cycles/{id}/calibrate [post]
+func (h *Handler) StartCalibration(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.StartCalibration(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Calibration started", cycle)
+}
+
+// CloseCycle makes a cycle's placements final.
+// @Summary Close a review cycle
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} Cycle
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Already closed"
+// @Router /hr/talent/cycles/{id}/close [post]
+func (h *Handler) CloseCycle(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := h.service.CloseCycle(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Review cycle closed", cycle)
+}
+
+// RecordReviews places employees on the grid by their review scores.
+// @Summary Record review scores
+// @Description Scores run from 1 to 5: below 2.5 is low, below 3.75 moderate, and high from there.
+// @Description Recording an employee again replaces their scores; only while the cycle is open.
+// @Tags Talent
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param reviews body ReviewsRequest true "Review scores"
+// @Success 200 {array} Placement
+// @Failure 400 {object} utils.ErrorResponse "Invalid scores or unknown employee"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not open"
+// @Router /hr/talent/cycles/{id}/reviews [put]
+func (h *Handler) RecordReviews(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ReviewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	placements, err := h.service.RecordReviews(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Reviews recorded successfully", placements)
+}
+
+// Grid returns a cycle's nine-box grid.
+// @Summary Get the nine-box grid
+// @Description Boxes are numbered 1 (low performance, low potential) to 9 (high, high), performance
+// @Description first: box 3 is high performance with low potential.
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param division_id query int false "Division ID"
+// @Success 200 {object} Grid
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/talent/cycles/{id}/grid [get]
+func (h *Handler) Grid(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var query GridQuery
+	if query.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	grid, err := h.service.Grid(utils.OrganizationFromContext(c), id, query)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Grid fetched successfully", grid)
+}
+
+// GetPlacement returns an employee's placement in a cycle. The ETag can be sent back as If-Match when
+// calibrating.
+// @Summary Get a placement
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param employee_id path int true "Employee ID"
+// @Success 200 {object} Placement
+// @Failure 404 {object} utils.ErrorResponse "Cycle or placement not found"
+// @Router /hr/talent/cycles/{id}/placements/{employee_id} [get]
+func (h *Handler) GetPlacement(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	placement, err := h.service.GetPlacement(utils.OrganizationFromContext(c), id, employeeID)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, placement.UpdatedAt, placement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Placement fetched successfully", placement)
+}
+
+// Calibrate moves an employee to another box during the calibration session.
+// @Summary Calibrate a placement
+// @Description The review scores are kept; the move and its reason are recorded as a movement.
+// @Tags Talent
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param employee_id path int true "Employee ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param calibration body CalibrateRequest true "New box"
+// @Success 200 {object} Placement
+// @Failure 400 {object} utils.ErrorResponse "Invalid calibration"
+// @Failure 404 {object} utils.ErrorResponse "Cycle or placement not found"
+// @Failure 409 {object} utils.ErrorResponse "Cycle not in calibration"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/talent/cycles/{id}/placements/{employee_id} [put]
+func (h *Handler) Calibrate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.ParseUintParam(c, "employee_id")
+	if !ok {
+		return
+	}
+	var req CalibrateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetPlacement(orgID, id, employeeID)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	placement, err := h.service.Calibrate(audit.ActorFromContext(c), orgID, id, employeeID, expectedVersion, req)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, placement.UpdatedAt, placement.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Placement calibrated successfully", placement)
+}
+
+// Movements lists the moves on the grid within a cycle.
+// @Summary List movements in a cycle
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param employee_id query int false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/talent/cycles/{id}/movements [get]
+func (h *Handler) Movements(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	employeeID, ok := utils.OptionalUintQuery(c, "employee_id")
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	movements, total, err := h.service.Movements(utils.OrganizationFromContext(c), id, employeeID, page)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Movements fetched successfully", page.Response(movements, total))
+}
+
+// History returns an employee's placements across cycles.
+// @Summary Get an employee's grid history
+// @Description Oldest cycle first; each entry says how the employee moved since the previous cycle and
+// @Description lists the moves within the cycle.
+// @Tags Talent
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {array} HistoryEntry
+// @Router /hr/talent/employees/{id}/history [get]
+func (h *Handler) History(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	history, err := h.service.History(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Grid history fetched successfully", history)
+}
+
+// Transitions counts employees moving between boxes from one cycle to another.
+// @Summary Compare two cycles
+// @Tags Talent
+// @Produce json
+// @Param from query int true "Earlier cycle ID"
+// @Param to query int true "Later cycle ID"
+// @Success 200 {object} Transitions
+// @Failure 400 {object} utils.ErrorResponse "Missing or identical cycles"
+// @Failure 404 {object} utils.ErrorResponse "Cycle not found"
+// @Router /hr/talent/transitions [get]
+func (h *Handler) Transitions(c *gin.Context) {
+	from, ok := utils.OptionalUintQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := utils.OptionalUintQuery(c, "to")
+	if !ok {
+		return
+	}
+	if from == nil || to == nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Both from and to cycles are required")
+		return
+	}
+	transitions, err := h.service.Transitions(utils.OrganizationFromContext(c), *from, *to)
+	if err != nil {
+		sendTalentError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Transitions fetched successfully", transitions)
+}
+
+// sendTalentError maps service errors to HTTP status codes.
+func sendTalentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrInvalidCycle):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCycleStatus):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
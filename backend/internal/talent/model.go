@@ -0,0 +1,164 @@
+// prometheus/backend/internal/talent/model.go
+package talent
+
+import (
+	"time"
+)
+
+// CycleStatus is where a review cycle is.
+type CycleStatus string
+
+const (
+	CycleOpen        CycleStatus = "open"        // Reviews are being recorded; placements follow the scores
+	CycleCalibration CycleStatus = "calibration" // HR calibrates placements; scores are frozen
+	CycleClosed      CycleStatus = "closed"      // Final: nothing changes any more
+)
+
+// Band is one third of an axis of the grid.
+type Band string
+
+const (
+	BandLow      Band = "low"
+	BandModerate Band = "moderate"
+	BandHigh     Band = "high"
+)
+
+// Source is what moved an employee on the grid.
+type Source string
+
+const (
+	SourceReview      Source = "review"      // Scores recorded for the cycle
+	SourceCalibration Source = "calibration" // HR during calibration
+)
+
+// Cycle is a review cycle: a period over which employees' performance and potential are assessed and
+// placed on the nine-box grid.
+type Cycle struct {
+	ID             uint        `gorm:"primaryKey" json:"id" example:"3"`
+	OrganizationID *uint       `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Name           string      `gorm:"type:varchar(100);not null" json:"name" example:"2025 H1"`
+	StartsOn       time.Time   `gorm:"type:date;not null" json:"starts_on" example:"2025-01-01T00:00:00Z"`
+	EndsOn         time.Time   `gorm:"type:date;not null;index" json:"ends_on" example:"2025-06-30T00:00:00Z"`
+	Status         CycleStatus `gorm:"type:varchar(20);not null" json:"status" example:"open"`
+	ClosedAt       *time.Time  `json:"closed_at,omitempty"`
+	Version        uint        `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// TableName keeps cycles with the rest of the talent tables.
+func (Cycle) TableName() string { return "talent_cycles" }
+
+// Placement is an employee's review in a cycle and where it puts them on the grid.
+type Placement struct {
+	ID               uint      `gorm:"primaryKey" json:"id" example:"41"`
+	OrganizationID   *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CycleID          uint      `gorm:"not null;uniqueIndex:idx_talent_placement" json:"cycle_id" example:"3"`
+	EmployeeID       uint      `gorm:"not null;uniqueIndex:idx_talent_placement;index" json:"employee_id" example:"12"`
+	DisplayName      string    `gorm:"-" json:"display_name" example:"Laila Haddad"`
+	PerformanceScore float64   `gorm:"not null" json:"performance_score" example:"4.2"` // From the review, 1 to 5
+	PotentialScore   float64   `gorm:"not null" json:"potential_score" example:"3.1"`   // From the review, 1 to 5
+	Performance      Band      `gorm:"type:varchar(10);not null" json:"performance" example:"high"`
+	Potential        Band      `gorm:"type:varchar(10);not null" json:"potential" example:"moderate"`
+	Box              int       `gorm:"-" json:"box" example:"6"`   // 1 (low, low) to 9 (high, high), see BoxOf
+	Calibrated       bool      `gorm:"not null" json:"calibrated"` // Moved by HR during calibration
+	Note             string    `gorm:"type:varchar(1000)" json:"note,omitempty"`
+	Version          uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName keeps placements with the rest of the talent tables.
+func (Placement) TableName() string { return "talent_placements" }
+
+// Movement is an employee moving on the grid within a cycle. The first placement in a cycle has no From.
+type Movement struct {
+	ID              uint      `gorm:"primaryKey" json:"id" example:"90"`
+	OrganizationID  *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CycleID         uint      `gorm:"not null;index" json:"cycle_id" example:"3"`
+	EmployeeID      uint      `gorm:"not null;index" json:"employee_id" example:"12"`
+	FromPerformance Band      `gorm:"type:varchar(10)" json:"from_performance,omitempty" example:"moderate"`
+	FromPotential   Band      `gorm:"type:varchar(10)" json:"from_potential,omitempty" example:"moderate"`
+	ToPerformance   Band      `gorm:"type:varchar(10);not null" json:"to_performance" example:"high"`
+	ToPotential     Band      `gorm:"type:varchar(10);not null" json:"to_potential" example:"moderate"`
+	Source          Source    `gorm:"type:varchar(20);not null" json:"source" example:"calibration"`
+	Reason          string    `gorm:"type:varchar(1000)" json:"reason,omitempty" example:"Led the ERP migration"`
+	ActorID         *uint     `json:"actor_id,omitempty" example:"3"` // User ID
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName keeps movements with the rest of the talent tables.
+func (Movement) TableName() string { return "talent_movements" }
+
+// CycleRequest creates a cycle or replaces its fields.
+type CycleRequest struct {
+	Name     string `json:"name" binding:"required,max=100" example:"2025 H1"`
+	StartsOn string `json:"starts_on" binding:"required,datetime=2006-01-02" example:"2025-01-01"`
+	EndsOn   string `json:"ends_on" binding:"required,datetime=2006-01-02" example:"2025-06-30"`
+}
+
+// ReviewScore is one employee's review outcome in a cycle.
+type ReviewScore struct {
+	EmployeeID       uint    `json:"employee_id" binding:"required" example:"12"`
+	PerformanceScore float64 `json:"performance_score" binding:"required,min=1,max=5" example:"4.2"`
+	PotentialScore   float64 `json:"potential_score" binding:"required,min=1,max=5" example:"3.1"`
+}
+
+// ReviewsRequest records review outcomes for a cycle, replacing employees' earlier scores in it.
+type ReviewsRequest struct {
+	Reviews []ReviewScore `json:"reviews" binding:"required,min=1,max=1000,dive"`
+}
+
+// CalibrateRequest moves an employee on the grid during calibration.
+type CalibrateRequest struct {
+	Performance Band   `json:"performance" binding:"required,oneof=low moderate high" example:"high"`
+	Potential   Band   `json:"potential" binding:"required,oneof=low moderate high" example:"moderate"`
+	Reason      string `json:"reason" binding:"required,max=1000" example:"Led the ERP migration"`
+}
+
+// GridQuery narrows the grid to some employees.
+type GridQuery struct {
+	DivisionID *uint
+}
+
+// Grid is a cycle's placements, box by box.
+type Grid struct {
+	Cycle Cycle     `json:"cycle"`
+	Boxes []GridBox `json:"boxes"` // Box 1 to 9, in order
+	Total int       `json:"total" example:"58"`
+}
+
+// GridBox is one box of the grid with the employees placed in it.
+type GridBox struct {
+	Box         int         `json:"box" example:"6"`
+	Label       string      `json:"label" example:"High performer"`
+	Performance Band        `json:"performance" example:"high"`
+	Potential   Band        `json:"potential" example:"moderate"`
+	Count       int         `json:"count" example:"7"`
+	Employees   []Placement `json:"employees"`
+}
+
+// HistoryEntry is an employee's final placement in one cycle, compared with the cycle before.
+type HistoryEntry struct {
+	Cycle     Cycle      `json:"cycle"`
+	Placement Placement  `json:"placement"`
+	FromBox   *int       `json:"from_box,omitempty" example:"5"` // Box in the previous cycle the employee was placed in
+	Change    string     `json:"change,omitempty" example:"up"`  // up, down, sideways or same compared with FromBox
+	Movements []Movement `json:"movements"`                      // Within the cycle, oldest first
+}
+
+// Transition counts employees moving from one box to another between two cycles.
+type Transition struct {
+	FromBox int `json:"from_box" example:"5"`
+	ToBox   int `json:"to_box" example:"6"`
+	Count   int `json:"count" example:"3"`
+}
+
+// Transitions compares the placements of two cycles.
+type Transitions struct {
+	FromCycle   Cycle        `json:"from_cycle"`
+	ToCycle     Cycle        `json:"to_cycle"`
+	Transitions []Transition `json:"transitions"`        // Non-zero pairs, by from and to box
+	Joined      int          `json:"joined" example:"4"` // Placed in the later cycle only
+	Left        int          `json:"left" example:"2"`   // Placed in the earlier cycle only
+}
@@ -0,0 +1,46 @@
+// prometheus/backend/internal/talent/module.go
+package talent
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the talent module.
+const ModuleName = "talent"
+
+// talentModule owns review cycles and the nine-box grid.
+type talentModule struct {
+	handler *Handler
+}
+
+// NewModule creates the talent module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &talentModule{handler: NewHandler(svc)}
+}
+
+func (m *talentModule) Name() string { return ModuleName }
+
+func (m *talentModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *talentModule) Models() []any {
+	return []any{&Cycle{}, &Placement{}, &Movement{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Review cycles and calibration are HR's.
+func (m *talentModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/hr/talent/cycles", routing.Policy(), m.handler.ListCycles)
+	api.POST("/hr/talent/cycles", routing.Policy(), m.handler.CreateCycle)
+	api.GET("/hr/talent/cycles/:id", routing.Policy(), m.handler.GetCycle)
+	api.PUT("/hr/talent/cycles/:id", routing.Policy(), m.handler.UpdateCycle)
+	api.POST("/hr/talent/cycles/:id/calibrate", routing.Policy(), m.handler.StartCalibration)
+	api.POST("/hr/talent/cycles/:id/close", routing.Policy(), m.handler.CloseCycle)
+	api.PUT("/hr/talent/cycles/:id/reviews", routing.Policy(), m.handler.RecordReviews)
+	api.GET("/hr/talent/cycles/:id/grid", routing.Policy(), m.handler.Grid)
+	api.GET("/hr/talent/cycles/:id/movements", routing.Policy(), m.handler.Movements)
+	api.GET("/hr/talent/cycles/:id/placements/:employee_id", routing.Policy(), m.handler.GetPlacement)
+	api.PUT("/hr/talent/cycles/:id/placements/:employee_id", routing.Policy(), m.handler.Calibrate)
+	api.GET("/hr/talent/employees/:id/history", routing.Policy(), m.handler.History)
+	api.GET("/hr/talent/transitions", routing.Policy(), m.handler.Transitions)
+}
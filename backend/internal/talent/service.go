@@ -0,0 +1,511 @@
+// prometheus/backend/internal/talent/service.go
+package talent
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidCycle is returned for cycles, reviews and calibrations that fail validation.
+	ErrInvalidCycle = errors.New("invalid review cycle")
+	// ErrCycleStatus is returned for changes the cycle's status doesn't allow: reviews are recorded while it
+	// is open, placements calibrated while it is in calibration, and nothing changes once it is closed.
+	ErrCycleStatus = errors.New("the review cycle's status does not allow this change")
+)
+
+// Service manages review cycles and the nine-box grid of their placements. orgID scopes every call to one
+// organization (nil = platform users, outside any organization).
+type Service interface {
+	Cycles(orgID *uint) ([]Cycle, error)
+	GetCycle(orgID *uint, id uint) (*Cycle, error)
+	CreateCycle(actor audit.Actor, orgID *uint, req CycleRequest) (*Cycle, error)
+	UpdateCycle(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CycleRequest) (*Cycle, error)
+	// StartCalibration freezes an open cycle's scores so HR can calibrate its placements.
+	StartCalibration(actor audit.Actor, orgID *uint, id uint) (*Cycle, error)
+	// CloseCycle makes the cycle's placements final.
+	CloseCycle(actor audit.Actor, orgID *uint, id uint) (*Cycle, error)
+
+	// RecordReviews places employees on the grid by their review scores in an open cycle.
+	RecordReviews(actor audit.Actor, orgID *uint, cycleID uint, req ReviewsRequest) ([]Placement, error)
+	Grid(orgID *uint, cycleID uint, query GridQuery) (*Grid, error)
+	GetPlacement(orgID *uint, cycleID, employeeID uint) (*Placement, error)
+	// Calibrate moves an employee to another box while the cycle is in calibration.
+	Calibrate(actor audit.Actor, orgID *uint, cycleID, employeeID, expectedVersion uint, req CalibrateRequest) (*Placement, error)
+	// Movements lists the moves within a cycle, newest first.
+	Movements(orgID *uint, cycleID uint, employeeID *uint, page utils.Pagination) ([]Movement, int64, error)
+
+	// History returns an employee's placements across cycles, oldest cycle first.
+	History(orgID *uint, employeeID uint) ([]HistoryEntry, error)
+	// Transitions counts employees moving between boxes from one cycle to another.
+	Transitions(orgID *uint, fromCycleID, toCycleID uint) (*Transitions, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Employee names are resolved through employees.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Cycles(orgID *uint) ([]Cycle, error) {
+	cycles := []Cycle{}
+	if err := utils.OrgScope(s.db, orgID).Order("ends_on DESC, id DESC").Find(&cycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list review cycles: %w", err)
+	}
+	return cycles, nil
+}
+
+func (s *service) GetCycle(orgID *uint, id uint) (*Cycle, error) {
+	var cycle Cycle
+	if err := utils.OrgScope(s.db, orgID).First(&cycle, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &cycle, nil
+}
+
+func (s *service) CreateCycle(actor audit.Actor, orgID *uint, req CycleRequest) (*Cycle, error) {
+	cycle := Cycle{OrganizationID: orgID, Status: CycleOpen}
+	if err := applyCycle(&cycle, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&cycle).Error; err != nil {
+			return fmt.Errorf("failed to create review cycle: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "talent_cycle.create", EntityType: "talent_cycle", EntityID: fmt.Sprintf("%d", cycle.ID), After: cycle,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+// UpdateCycle replaces the cycle's fields if it is still at expectedVersion (optimistic locking) and not
+// closed.
+func (s *service) UpdateCycle(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CycleRequest) (*Cycle, error) {
+	var updated Cycle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Cycle
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if before.Status == CycleClosed {
+			return fmt.Errorf("%w: the cycle is closed", ErrCycleStatus)
+		}
+		cycle := before
+		if err := applyCycle(&cycle, req); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Cycle{}, id, expectedVersion, map[string]interface{}{
+			"name": cycle.Name, "starts_on": cycle.StartsOn, "ends_on": cycle.EndsOn,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload review cycle %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "talent_cycle.update", EntityType: "talent_cycle", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) StartCalibration(actor audit.Actor, orgID *uint, id uint) (*Cycle, error) {
+	return s.transition(actor, orgID, id, CycleOpen, CycleCalibration, "talent_cycle.calibrate")
+}
+
+// CloseCycle closes a cycle in calibration, or an open one that needs no calibration.
+func (s *service) CloseCycle(actor audit.Actor, orgID *uint, id uint) (*Cycle, error) {
+	return s.transition(actor, orgID, id, "", CycleClosed, "talent_cycle.close")
+}
+
+// transition moves a cycle to status, from the status from or, if from is empty, from any status but
+// status itself.
+func (s *service) transition(actor audit.Actor, orgID *uint, id uint, from, status CycleStatus, action string) (*Cycle, error) {
+	var updated Cycle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockCycle(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status == status || (from != "" && before.Status != from) {
+			return fmt.Errorf("%w: the cycle is %s", ErrCycleStatus, before.Status)
+		}
+		updates := map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")}
+		if status == CycleClosed {
+			updates["closed_at"] = clock.Now().UTC()
+		}
+		if err := tx.Model(&Cycle{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update review cycle %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload review cycle %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: action, EntityType: "talent_cycle", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RecordReviews replaces the scores of employees already reviewed in the cycle. Employees move on the grid
+// when their scores cross into another band, and every move is kept as a movement.
+func (s *service) RecordReviews(actor audit.Actor, orgID *uint, cycleID uint, req ReviewsRequest) ([]Placement, error) {
+	ids := make([]uint, 0, len(req.Reviews))
+	for _, r := range req.Reviews {
+		if slices.Contains(ids, r.EmployeeID) {
+			return nil, fmt.Errorf("%w: employee %d is reviewed twice", ErrInvalidCycle, r.EmployeeID)
+		}
+		ids = append(ids, r.EmployeeID)
+	}
+	placements := make([]Placement, 0, len(req.Reviews))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status != CycleOpen {
+			return fmt.Errorf("%w: reviews are recorded while the cycle is open, it is %s", ErrCycleStatus, cycle.Status)
+		}
+		var found int64
+		if err := utils.OrgScope(tx.Table("employees").Where("deleted_at IS NULL AND id IN ?", ids), orgID).Count(&found).Error; err != nil {
+			return fmt.Errorf("failed to check employees: %w", err)
+		}
+		if found != int64(len(ids)) {
+			return fmt.Errorf("%w: unknown employee", ErrInvalidCycle)
+		}
+		var existing []Placement
+		if err := tx.Where("cycle_id = ? AND employee_id IN ?", cycleID, ids).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to load placements: %w", err)
+		}
+		byEmployee := make(map[uint]Placement, len(existing))
+		for _, p := range existing {
+			byEmployee[p.EmployeeID] = p
+		}
+		for _, r := range req.Reviews {
+			before, placed := byEmployee[r.EmployeeID]
+			placement := before
+			if !placed {
+				placement = Placement{OrganizationID: orgID, CycleID: cycleID, EmployeeID: r.EmployeeID}
+			}
+			placement.PerformanceScore, placement.PotentialScore = r.PerformanceScore, r.PotentialScore
+			placement.Performance, placement.Potential = BandOf(r.PerformanceScore), BandOf(r.PotentialScore)
+			if placed {
+				placement.Version++
+			}
+			// The unique index settles two first reviews racing; the loser fails rather than overwrites.
+			if err := tx.Save(&placement).Error; err != nil {
+				return fmt.Errorf("failed to save placement of employee %d: %w", r.EmployeeID, err)
+			}
+			if !placed || before.Performance != placement.Performance || before.Potential != placement.Potential {
+				movement := Movement{
+					OrganizationID: orgID, CycleID: cycleID, EmployeeID: r.EmployeeID, ToPerformance: placement.Performance,
+					ToPotential: placement.Potential, Source: SourceReview, ActorID: actor.UserID,
+				}
+				if placed {
+					movement.FromPerformance, movement.FromPotential = before.Performance, before.Potential
+				}
+				if err := tx.Create(&movement).Error; err != nil {
+					return fmt.Errorf("failed to record movement of employee %d: %w", r.EmployeeID, err)
+				}
+			}
+			placement.Box = BoxOf(placement.Performance, placement.Potential)
+			placements = append(placements, placement)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "talent_cycle.reviews", EntityType: "talent_cycle", EntityID: fmt.Sprintf("%d", cycleID), After: req,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return placements, nil
+}
+
+func (s *service) Grid(orgID *uint, cycleID uint, query GridQuery) (*Grid, error) {
+	cycle, err := s.GetCycle(orgID, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	placementQuery := s.db.Where("cycle_id = ?", cycleID)
+	if query.DivisionID != nil {
+		placementQuery = placementQuery.Where("employee_id IN (?)",
+			s.db.Table("employees").Select("id").Where("division_id = ? AND deleted_at IS NULL", *query.DivisionID))
+	}
+	var placements []Placement
+	if err := placementQuery.Order("employee_id").Find(&placements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load placements: %w", err)
+	}
+	if err := s.named(orgID, placements); err != nil {
+		return nil, err
+	}
+	grid := &Grid{Cycle: *cycle, Boxes: emptyGrid(), Total: len(placements)}
+	for _, p := range placements {
+		box := &grid.Boxes[p.Box-1]
+		box.Employees = append(box.Employees, p)
+		box.Count++
+	}
+	for i := range grid.Boxes {
+		slices.SortFunc(grid.Boxes[i].Employees, func(a, b Placement) int {
+			return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+		})
+	}
+	return grid, nil
+}
+
+func (s *service) GetPlacement(orgID *uint, cycleID, employeeID uint) (*Placement, error) {
+	var placement Placement
+	if err := utils.OrgScope(s.db, orgID).Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&placement).Error; err != nil {
+		return nil, err
+	}
+	placements := []Placement{placement}
+	if err := s.named(orgID, placements); err != nil {
+		return nil, err
+	}
+	return &placements[0], nil
+}
+
+// Calibrate keeps the employee's scores: calibration moves them on the grid without rewriting their review.
+func (s *service) Calibrate(actor audit.Actor, orgID *uint, cycleID, employeeID, expectedVersion uint, req CalibrateRequest) (*Placement, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidCycle)
+	}
+	var updated Placement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cycle, err := lockCycle(tx, orgID, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle.Status != CycleCalibration {
+			return fmt.Errorf("%w: placements are calibrated while the cycle is in calibration, it is %s", ErrCycleStatus, cycle.Status)
+		}
+		var before Placement
+		if err := tx.Where("cycle_id = ? AND employee_id = ?", cycleID, employeeID).First(&before).Error; err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Placement{}, before.ID, expectedVersion, map[string]interface{}{
+			"performance": req.Performance, "potential": req.Potential, "calibrated": true, "note": reason,
+		}); err != nil {
+			return err
+		}
+		if before.Performance != req.Performance || before.Potential != req.Potential {
+			if err := tx.Create(&Movement{
+				OrganizationID: orgID, CycleID: cycleID, EmployeeID: employeeID,
+				FromPerformance: before.Performance, FromPotential: before.Potential,
+				ToPerformance: req.Performance, ToPotential: req.Potential,
+				Source: SourceCalibration, Reason: reason, ActorID: actor.UserID,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to record movement of employee %d: %w", employeeID, err)
+			}
+		}
+		if err := tx.First(&updated, before.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload placement %d: %w", before.ID, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "talent_placement.calibrate", EntityType: "talent_placement", EntityID: fmt.Sprintf("%d", before.ID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	placements := []Placement{updated}
+	if err := s.named(orgID, placements); err != nil {
+		return nil, err
+	}
+	return &placements[0], nil
+}
+
+func (s *service) Movements(orgID *uint, cycleID uint, employeeID *uint, page utils.Pagination) ([]Movement, int64, error) {
+	if _, err := s.GetCycle(orgID, cycleID); err != nil {
+		return nil, 0, err
+	}
+	query := s.db.Model(&Movement{}).Where("cycle_id = ?", cycleID)
+	if employeeID != nil {
+		query = query.Where("employee_id = ?", *employeeID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count movements: %w", err)
+	}
+	movements := []Movement{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&movements).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list movements: %w", err)
+	}
+	return movements, total, nil
+}
+
+func (s *service) History(orgID *uint, employeeID uint) ([]HistoryEntry, error) {
+	var placements []Placement
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id = ?", employeeID).Find(&placements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load placements: %w", err)
+	}
+	if len(placements) == 0 {
+		return []HistoryEntry{}, nil
+	}
+	if err := s.named(orgID, placements); err != nil {
+		return nil, err
+	}
+	cycleIDs := make([]uint, len(placements))
+	for i, p := range placements {
+		cycleIDs[i] = p.CycleID
+	}
+	var cycles []Cycle
+	if err := s.db.Where("id IN ?", cycleIDs).Find(&cycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load review cycles: %w", err)
+	}
+	var movements []Movement
+	if err := s.db.Where("employee_id = ? AND cycle_id IN ?", employeeID, cycleIDs).Order("created_at, id").Find(&movements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load movements: %w", err)
+	}
+	byCycle := make(map[uint]Placement, len(placements))
+	for _, p := range placements {
+		byCycle[p.CycleID] = p
+	}
+	slices.SortFunc(cycles, compareCycles)
+	history := make([]HistoryEntry, 0, len(cycles))
+	for _, cycle := range cycles {
+		entry := HistoryEntry{Cycle: cycle, Placement: byCycle[cycle.ID], Movements: []Movement{}}
+		for _, m := range movements {
+			if m.CycleID == cycle.ID {
+				entry.Movements = append(entry.Movements, m)
+			}
+		}
+		if len(history) > 0 {
+			previous := history[len(history)-1].Placement
+			fromBox := previous.Box
+			entry.FromBox = &fromBox
+			entry.Change = change(previous, entry.Placement)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+func (s *service) Transitions(orgID *uint, fromCycleID, toCycleID uint) (*Transitions, error) {
+	if fromCycleID == toCycleID {
+		return nil, fmt.Errorf("%w: compare two different cycles", ErrInvalidCycle)
+	}
+	from, err := s.GetCycle(orgID, fromCycleID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetCycle(orgID, toCycleID)
+	if err != nil {
+		return nil, err
+	}
+	var placements []Placement
+	if err := s.db.Where("cycle_id IN ?", []uint{fromCycleID, toCycleID}).Find(&placements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load placements: %w", err)
+	}
+	fromBoxes, toBoxes := make(map[uint]int), make(map[uint]int)
+	for _, p := range placements {
+		if p.CycleID == fromCycleID {
+			fromBoxes[p.EmployeeID] = BoxOf(p.Performance, p.Potential)
+		} else {
+			toBoxes[p.EmployeeID] = BoxOf(p.Performance, p.Potential)
+		}
+	}
+	result := &Transitions{FromCycle: *from, ToCycle: *to, Transitions: []Transition{}}
+	counts := make(map[[2]int]int)
+	for employeeID, fromBox := range fromBoxes {
+		toBox, ok := toBoxes[employeeID]
+		if !ok {
+			result.Left++
+			continue
+		}
+		counts[[2]int{fromBox, toBox}]++
+	}
+	for employeeID := range toBoxes {
+		if _, ok := fromBoxes[employeeID]; !ok {
+			result.Joined++
+		}
+	}
+	for boxes, count := range counts {
+		result.Transitions = append(result.Transitions, Transition{FromBox: boxes[0], ToBox: boxes[1], Count: count})
+	}
+	slices.SortFunc(result.Transitions, func(a, b Transition) int {
+		if c := cmp.Compare(a.FromBox, b.FromBox); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ToBox, b.ToBox)
+	})
+	return result, nil
+}
+
+// named fills in the boxes and display names of placements.
+func (s *service) named(orgID *uint, placements []Placement) error {
+	ids := make([]uint, len(placements))
+	for i, p := range placements {
+		ids[i] = p.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range placements {
+		placements[i].Box = BoxOf(placements[i].Performance, placements[i].Potential)
+		placements[i].DisplayName = names[placements[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// lockCycle loads a cycle for update, so its status can't change until the transaction ends.
+func lockCycle(tx *gorm.DB, orgID *uint, id uint) (*Cycle, error) {
+	var cycle Cycle
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&cycle, id).Error; err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+// compareCycles orders cycles by when they end.
+func compareCycles(a, b Cycle) int {
+	if c := a.EndsOn.Compare(b.EndsOn); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func applyCycle(cycle *Cycle, req CycleRequest) error {
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCycle)
+	}
+	endsOn, err := time.Parse("2006-01-02", req.EndsOn)
+	if err != nil {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidCycle)
+	}
+	if endsOn.Before(startsOn) {
+		return fmt.Errorf("%w: the cycle ends before it starts", ErrInvalidCycle)
+	}
+	cycle.Name = strings.TrimSpace(req.Name)
+	cycle.StartsOn, cycle.EndsOn = startsOn, endsOn
+	return nil
+}
@@ -0,0 +1,438 @@
+// prometheus/backend/internal/tenant/demo.go
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"prometheus/backend/config"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/cache"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/role"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// JobResetDemoTenant is the job type that wipes and reseeds the demo tenant.
+const JobResetDemoTenant = "tenant.reset_demo"
+
+// demoEmployees is the number of fake staff accounts created besides the named demo accounts.
+const demoEmployees = 40
+
+// ErrNoDemoTenant is returned when DEMO_TENANT_SLUG is not configured.
+var ErrNoDemoTenant = errors.New("no demo tenant is configured")
+
+// ErrNotDemoTenant is returned when the configured slug belongs to an organization that is not flagged as a
+// demo, so a misconfiguration can never wipe a real tenant.
+var ErrNotDemoTenant = errors.New("organization is not a demo tenant")
+
+// DemoResetPayload is the payload of JobResetDemoTenant. Scheduled runs (empty payload) only reset during the
+// configured hour; manual runs set Force.
+type DemoResetPayload struct {
+	Force bool `json:"force"`
+}
+
+// DemoResetResult summarizes a reset.
+type DemoResetResult struct {
+	OrganizationID uint     `json:"organization_id"`
+	UsersRemoved   int      `json:"users_removed"`
+	UsersCreated   int      `json:"users_created"`
+	Accounts       []string `json:"accounts"` // Usernames of the named demo accounts (sharing DEMO_ADMIN_PASSWORD)
+	Skipped        string   `json:"skipped,omitempty"`
+}
+
+// DemoService defines the interface for the sales demo tenant.
+type DemoService interface {
+	// Reset wipes the demo tenant's users and policies and reseeds it with fresh fake data. The tenant is
+	// created on first use.
+	Reset(ctx context.Context, actor audit.Actor, reporter jobs.Reporter) (*DemoResetResult, error)
+}
+
+// demoService implements the DemoService interface.
+type demoService struct {
+	db       *gorm.DB
+	enforcer *casbin.SyncedEnforcer
+	cache    cache.Cache
+	auditor  audit.Service
+	slug     string
+	password string
+}
+
+// NewDemoService creates a new instance of DemoService for the tenant configured by DEMO_TENANT_SLUG.
+func NewDemoService(db *gorm.DB, cfg *config.Config, enforcer *casbin.SyncedEnforcer, c cache.Cache, auditor audit.Service) DemoService {
+	return &demoService{db: db, enforcer: enforcer, cache: c, auditor: auditor, slug: cfg.DemoTenantSlug, password: cfg.DemoAdminPassword}
+}
+
+func (s *demoService) Reset(ctx context.Context, actor audit.Actor, reporter jobs.Reporter) (*DemoResetResult, error) {
+	if s.slug == "" {
+		return nil, ErrNoDemoTenant
+	}
+	db := s.db.WithContext(ctx)
+	org, err := s.demoOrganization(db)
+	if err != nil {
+		return nil, err
+	}
+	result := &DemoResetResult{OrganizationID: org.ID}
+
+	var roles []role.Role
+	if err := db.Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	rolesByName := make(map[string]role.Role, len(roles))
+	for _, r := range roles {
+		rolesByName[r.Name] = r
+	}
+	hashed, err := auth.HashPassword(s.password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash demo password: %w", err)
+	}
+	if err := reporter.SetProgress(10, "Removing demo data"); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		removed, err := s.wipe(tx, org.ID)
+		if err != nil {
+			return err
+		}
+		result.UsersRemoved = removed
+
+		if err := s.resetOrganization(tx, org); err != nil {
+			return err
+		}
+		accounts, created, err := s.seedUsers(tx, org, rolesByName, hashed)
+		if err != nil {
+			return err
+		}
+		result.Accounts, result.UsersCreated = accounts, created
+		if err := completeOnboarding(tx, org.ID); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.demo_reset", EntityType: "organization", EntityID: fmt.Sprintf("%d", org.ID),
+			After: map[string]int{"users_removed": result.UsersRemoved, "users_created": result.UsersCreated},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := reporter.SetProgress(80, "Reseeding demo policies"); err != nil {
+		return nil, err
+	}
+
+	// Policies live in the enforcer, outside the transaction; reseeding is idempotent, so a failure here
+	// is repaired by the next run.
+	if err := s.resetPolicies(org.Domain()); err != nil {
+		return nil, err
+	}
+	for _, ns := range []string{cache.NamespacePermissions, cache.NamespaceTenantHosts, cache.NamespaceUserStatus} {
+		if err := s.cache.Flush(ctx, ns); err != nil {
+			log.Printf("Demo tenant: failed to flush %s cache: %v", ns, err)
+		}
+	}
+	return result, nil
+}
+
+// demoOrganization loads the demo tenant, creating it on first use.
+func (s *demoService) demoOrganization(db *gorm.DB) (*organization.Organization, error) {
+	var org organization.Organization
+	err := db.Where("slug = ?", s.slug).First(&org).Error
+	switch {
+	case err == nil:
+		if !org.IsDemo {
+			return nil, fmt.Errorf("%w: %s", ErrNotDemoTenant, s.slug)
+		}
+		return &org, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !slugPattern.MatchString(s.slug) {
+			return nil, ErrInvalidSlug
+		}
+		org = organization.Organization{Name: "Demo Corp", Slug: s.slug, Status: organization.StatusActive, IsDemo: true}
+		if err := db.Create(&org).Error; err != nil {
+			return nil, fmt.Errorf("failed to create demo organization: %w", err)
+		}
+		return &org, nil
+	default:
+		return nil, fmt.Errorf("failed to load demo organization: %w", err)
+	}
+}
+
+// wipe hard-deletes the tenant's users together with their role grants and role requests, so the fixed demo
+// usernames can be recreated. Audit records are kept.
+func (s *demoService) wipe(tx *gorm.DB, orgID uint) (int, error) {
+	var userIDs []uint
+	if err := tx.Unscoped().Model(&auth.User{}).Where("organization_id = ?", orgID).Pluck("id", &userIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to list demo users: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	for _, model := range []interface{}{&auth.UserRole{}, &auth.ScopedRole{}, &auth.RoleRequest{}} {
+		if err := tx.Unscoped().Where("user_id IN ?", userIDs).Delete(model).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete demo user data: %w", err)
+		}
+	}
+	if err := tx.Unscoped().Where("id IN ?", userIDs).Delete(&auth.User{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete demo users: %w", err)
+	}
+	return len(userIDs), nil
+}
+
+// resetOrganization restores the tenant's settings: every module enabled, no custom domain, demo branding.
+func (s *demoService) resetOrganization(tx *gorm.DB, org *organization.Organization) error {
+	now := time.Now().UTC()
+	org.Name = "Demo Corp"
+	org.Status = organization.StatusActive
+	org.Timezone = "Asia/Jakarta"
+	org.CountryCode = "ID"
+	org.WorkWeek = datatypes.JSON(`[1,2,3,4,5]`)
+	org.HolidayPresets = datatypes.JSON(`[]`)
+	org.Plan = plan.PlanEnterprise
+	org.StorageUsedBytes = 0
+	org.CustomDomain = nil
+	org.Branding = organization.Branding{DisplayName: "Demo Corp People", PrimaryColor: "#0055ff"}
+	org.DemoResetAt = &now
+	if err := tx.Save(org).Error; err != nil {
+		return fmt.Errorf("failed to reset demo organization: %w", err)
+	}
+	return nil
+}
+
+// seedUsers creates one account per role (sharing the demo password) plus fake staff with random passwords.
+// Usernames and emails are prefixed with the slug because both are unique across tenants.
+func (s *demoService) seedUsers(tx *gorm.DB, org *organization.Organization, rolesByName map[string]role.Role, hashed string) ([]string, int, error) {
+	var accounts []string
+	created := 0
+	create := func(username, email, passwordHash string, roleNames ...string) error {
+		user := auth.User{
+			Username:       username,
+			Email:          email,
+			Password:       passwordHash,
+			IsActive:       true,
+			OrganizationID: &org.ID,
+		}
+		for _, name := range roleNames {
+			r, ok := rolesByName[name]
+			if !ok {
+				return fmt.Errorf("role %q not found, ensure roles are seeded", name)
+			}
+			user.Roles = append(user.Roles, r)
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create demo user %s: %w", username, err)
+		}
+		created++
+		return nil
+	}
+
+	for _, name := range []string{"admin", "hr", "manager", "staff"} {
+		username := org.Slug + "-" + name
+		if err := create(username, username+"@demo.example", hashed, name); err != nil {
+			return nil, 0, err
+		}
+		accounts = append(accounts, username)
+	}
+
+	// Fake staff can't sign in; they only populate lists and reports.
+	unusable, err := auth.GenerateRandomPassword()
+	if err != nil {
+		return nil, 0, err
+	}
+	unusableHash, err := auth.HashPassword(unusable)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := 1; i <= demoEmployees; i++ {
+		first := demoFirstNames[rand.Intn(len(demoFirstNames))]
+		last := demoLastNames[rand.Intn(len(demoLastNames))]
+		local := fmt.Sprintf("%s.%s%d", strings.ToLower(first), strings.ToLower(last), i)
+		roleName := "staff"
+		if i%10 == 0 {
+			roleName = "manager"
+		}
+		if err := create(org.Slug+"-"+local, local+"@"+org.Slug+".demo.example", unusableHash, roleName); err != nil {
+			return nil, 0, err
+		}
+	}
+	return accounts, created, nil
+}
+
+// resetPolicies replaces the tenant's policies and role links with the default role matrix, undoing any
+// changes made during demos.
+func (s *demoService) resetPolicies(domain string) error {
+	if _, err := s.enforcer.RemoveFilteredPolicy(1, domain); err != nil {
+		return fmt.Errorf("failed to remove demo policies: %w", err)
+	}
+	if _, err := s.enforcer.RemoveFilteredGroupingPolicy(2, domain); err != nil {
+		return fmt.Errorf("failed to remove demo role links: %w", err)
+	}
+	if _, err := authz.SeedDomain(s.enforcer, domain); err != nil {
+		return err
+	}
+	return nil
+}
+
+// completeOnboarding marks every onboarding step completed, so the demo tenant never shows the wizard.
+func completeOnboarding(tx *gorm.DB, orgID uint) error {
+	now := time.Now().UTC()
+	for _, step := range Steps {
+		record := OnboardingStep{OrganizationID: orgID, Step: step}
+		if err := tx.Where(record).FirstOrInit(&record).Error; err != nil {
+			return fmt.Errorf("failed to load onboarding step: %w", err)
+		}
+		record.Status, record.Error, record.CompletedAt = StepCompleted, "", &now
+		if err := tx.Save(&record).Error; err != nil {
+			return fmt.Errorf("failed to save onboarding step: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResetDemoTenantJob returns the job handler for JobResetDemoTenant. Scheduled runs fire hourly and only
+// reset during resetHour (UTC), at most once a day; resetHour < 0 disables scheduled resets.
+func ResetDemoTenantJob(db *gorm.DB, service DemoService, resetHour int) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job, reporter jobs.Reporter) (interface{}, error) {
+		var payload DemoResetPayload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, err
+		}
+		actor := audit.SystemActor
+		if payload.Force {
+			actor = audit.Actor{UserID: job.CreatedBy}
+		} else {
+			now := time.Now().UTC()
+			if resetHour < 0 || now.Hour() != resetHour {
+				return DemoResetResult{Skipped: "outside the reset hour"}, nil
+			}
+			var resetToday int64
+			if err := db.WithContext(ctx).Model(&organization.Organization{}).
+				Where("is_demo AND demo_reset_at > ?", now.Add(-20*time.Hour)).Count(&resetToday).Error; err != nil {
+				return nil, fmt.Errorf("failed to check last demo reset: %w", err)
+			}
+			if resetToday > 0 {
+				return DemoResetResult{Skipped: "already reset today"}, nil
+			}
+		}
+		result, err := service.Reset(ctx, actor, reporter)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Demo tenant reset: removed %d users, created %d.", result.UsersRemoved, result.UsersCreated)
+		return result, nil
+	}
+}
+
+// DemoHandler handles the demo tenant endpoints.
+type DemoHandler struct {
+	queue      *jobs.Queue
+	configured bool
+}
+
+// NewDemoHandler creates a new DemoHandler.
+func NewDemoHandler(queue *jobs.Queue, cfg *config.Config) *DemoHandler {
+	return &DemoHandler{queue: queue, configured: cfg.DemoTenantSlug != ""}
+}
+
+// Reset starts a reset of the demo tenant as a long-running operation.
+// @Summary Reset the demo tenant
+// @Description Wipes and reseeds the tenant configured by DEMO_TENANT_SLUG with fresh fake data. Poll the returned operation for the result.
+// @Tags Tenants
+// @Produce json
+// @Success 202 {object} jobs.Job
+// @Failure 404 {object} utils.ErrorResponse "No demo tenant configured"
+// @Router /admin/demo-tenant/reset [post]
+func (h *DemoHandler) Reset(c *gin.Context) {
+	if !h.configured {
+		utils.SendErrorResponse(c, http.StatusNotFound, ErrNoDemoTenant.Error())
+		return
+	}
+	actor := audit.ActorFromContext(c)
+	job, err := h.queue.Enqueue(JobResetDemoTenant, DemoResetPayload{Force: true}, jobs.EnqueueOptions{CreatedBy: actor.UserID})
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jobs.SendAccepted(c, job)
+}
+
+// DemoModuleName is the name of the demo tenant module (MODULES_DISABLED=demo-tenant turns it off).
+const DemoModuleName = "demo-tenant"
+
+// demoModule registers the demo tenant's reset job and endpoint.
+type demoModule struct {
+	db      *gorm.DB
+	service DemoService
+	handler *DemoHandler
+	cfg     *config.Config
+}
+
+// NewDemoModule creates the demo tenant module for the module registry.
+func NewDemoModule(db *gorm.DB, cfg *config.Config, service DemoService, queue *jobs.Queue) module.Module {
+	return &demoModule{db: db, service: service, handler: NewDemoHandler(queue, cfg), cfg: cfg}
+}
+
+func (m *demoModule) Name() string { return DemoModuleName }
+
+func (m *demoModule) HealthContributors() []module.HealthContributor {
+	return []module.HealthContributor{
+		module.NewHealthCheck("last_reset", func(ctx context.Context) module.HealthResult {
+			if m.cfg.DemoTenantSlug == "" || m.cfg.DemoResetHour < 0 {
+				return module.HealthResult{Status: module.StatusUp, Details: map[string]any{"scheduled": false}}
+			}
+			var org organization.Organization
+			err := m.db.WithContext(ctx).Where("slug = ? AND is_demo", m.cfg.DemoTenantSlug).First(&org).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return module.HealthResult{Status: module.StatusDegraded, Error: err.Error()}
+			}
+			// A missed nightly reset leaves yesterday's demo data behind; worth a look, but not an outage.
+			if org.DemoResetAt == nil {
+				return module.HealthResult{Status: module.StatusDegraded, Error: "demo tenant was never reset"}
+			}
+			age := time.Since(*org.DemoResetAt)
+			status := module.StatusUp
+			if age > 48*time.Hour {
+				status = module.StatusDegraded
+			}
+			return module.HealthResult{Status: status, Metrics: map[string]float64{"last_success_age_seconds": age.Seconds()}}
+		}),
+	}
+}
+
+// RegisterJobs implements jobs.Contributor. Scheduled runs fire hourly and reset during DEMO_RESET_HOUR.
+func (m *demoModule) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobResetDemoTenant, ResetDemoTenantJob(m.db, m.service, m.cfg.DemoResetHour))
+	if m.cfg.DemoTenantSlug != "" && m.cfg.DemoResetHour >= 0 {
+		q.Every(JobResetDemoTenant, time.Hour)
+	}
+}
+
+// RegisterRoutes implements routing.Contributor.
+func (m *demoModule) RegisterRoutes(api *routing.Group) {
+	// Never touches tenants not flagged as demo
+	api.POST("/admin/demo-tenant/reset", routing.Roles("god-admin"), m.handler.Reset)
+}
+
+var demoFirstNames = []string{
+	"Adi", "Ayu", "Budi", "Citra", "Dewi", "Eko", "Fajar", "Gita", "Hadi", "Indah",
+	"Joko", "Kartika", "Lestari", "Made", "Nina", "Putri", "Rizky", "Sari", "Tono", "Wulan",
+}
+
+var demoLastNames = []string{
+	"Santoso", "Wijaya", "Pratama", "Saputra", "Hidayat", "Kusuma", "Nugroho", "Setiawan", "Halim", "Siregar",
+}
@@ -0,0 +1,431 @@
+// prometheus/backend/internal/tenant/domain.go
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/cache"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/utils"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantHostCacheTTL bounds how long a host keeps resolving to an organization after out-of-band DB changes.
+const tenantHostCacheTTL = 5 * time.Minute
+
+// ErrInvalidDomain is returned when a custom domain is not a fully qualified host name.
+var ErrInvalidDomain = errors.New("custom domain must be a fully qualified host name")
+
+// ErrReservedDomain is returned when a custom domain lies within the platform's tenant base domain.
+var ErrReservedDomain = errors.New("custom domain must not be below the tenant base domain")
+
+// ErrDomainTaken is returned when the custom domain belongs to another organization.
+var ErrDomainTaken = errors.New("custom domain is already used by another organization")
+
+// ErrUnknownTenantHost is returned when a subdomain of the tenant base domain matches no organization.
+var ErrUnknownTenantHost = errors.New("unknown tenant")
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// SetDomainRequest sets a tenant's custom domain. The domain's DNS must point at the platform.
+type SetDomainRequest struct {
+	Domain string `json:"domain" binding:"required,max=253" example:"hr.acme.example"`
+}
+
+// PublicBranding is what the frontend needs to white-label its login page before anyone signs in.
+type PublicBranding struct {
+	organization.Branding
+	Name    string `json:"name,omitempty" example:"Acme Corp"`
+	Slug    string `json:"slug,omitempty" example:"acme"`
+	BaseURL string `json:"base_url" example:"https://hr.acme.example"`
+}
+
+// CertificateHook is called when a tenant's custom domain changes, so TLS for it can be set up outside the
+// application (e.g. registering the domain with a load balancer or certificate manager). A failing Provision
+// aborts the domain change.
+type CertificateHook interface {
+	Provision(ctx context.Context, domain string) error
+	Release(ctx context.Context, domain string) error
+}
+
+// LogCertificateHook only logs domain changes. It suits deployments whose TLS terminator issues certificates
+// on demand and asks DomainService.HostPolicy (via GET /tls/ask) whether a domain may get one.
+type LogCertificateHook struct{}
+
+func (LogCertificateHook) Provision(_ context.Context, domain string) error {
+	log.Printf("Tenant: custom domain %s added, TLS certificate will be issued on demand", domain)
+	return nil
+}
+
+func (LogCertificateHook) Release(_ context.Context, domain string) error {
+	log.Printf("Tenant: custom domain %s removed", domain)
+	return nil
+}
+
+// DomainService defines the interface for white-label tenant hosts and branding.
+type DomainService interface {
+	// Resolve returns the organization served on host, or nil for the shared (non-tenant) host.
+	Resolve(ctx context.Context, host string) (*organization.Organization, error)
+	SetCustomDomain(actor audit.Actor, orgID uint, domain string) (*organization.Organization, error)
+	RemoveCustomDomain(actor audit.Actor, orgID uint) (*organization.Organization, error)
+	SetBranding(actor audit.Actor, orgID uint, branding organization.Branding) (*organization.Organization, error)
+	// HostPolicy reports whether a TLS certificate may be issued for host; it has the signature of
+	// autocert.HostPolicy so it can be plugged into an in-process ACME manager as well.
+	HostPolicy(ctx context.Context, host string) error
+}
+
+// domainService implements the DomainService interface.
+type domainService struct {
+	db      *gorm.DB
+	cache   cache.Cache
+	links   *organization.Links
+	certs   CertificateHook
+	auditor audit.Service
+}
+
+// NewDomainService creates a new instance of DomainService.
+func NewDomainService(db *gorm.DB, c cache.Cache, links *organization.Links, certs CertificateHook, auditor audit.Service) DomainService {
+	return &domainService{db: db, cache: c, links: links, certs: certs, auditor: auditor}
+}
+
+// Resolve maps <slug>.<base domain> and custom domains to their organization. Results, including misses,
+// are cached per host because this runs on every request.
+func (s *domainService) Resolve(ctx context.Context, host string) (*organization.Organization, error) {
+	host = normalizeHost(host)
+	if host == "" {
+		return nil, nil
+	}
+
+	var cached organization.Organization
+	if found, err := s.cache.Get(ctx, cache.NamespaceTenantHosts, host, &cached); err == nil && found {
+		if cached.ID == 0 {
+			return nil, s.missError(host)
+		}
+		return &cached, nil
+	}
+
+	query := s.db.Where("custom_domain = ?", host)
+	if slug, ok := s.subdomainSlug(host); ok {
+		query = s.db.Where("slug = ?", slug)
+	}
+	var org organization.Organization
+	err := query.First(&org).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to resolve tenant host %s: %w", host, err)
+	}
+	// A zero organization marks a miss.
+	_ = s.cache.Set(ctx, cache.NamespaceTenantHosts, host, org, tenantHostCacheTTL)
+	if org.ID == 0 {
+		return nil, s.missError(host)
+	}
+	return &org, nil
+}
+
+// missError is nil for hosts outside the tenant base domain (they are the shared host), and
+// ErrUnknownTenantHost for subdomains that belong to no organization.
+func (s *domainService) missError(host string) error {
+	if _, ok := s.subdomainSlug(host); ok {
+		return ErrUnknownTenantHost
+	}
+	return nil
+}
+
+// subdomainSlug extracts the slug from <slug>.<base domain>.
+func (s *domainService) subdomainSlug(host string) (string, bool) {
+	base := s.links.BaseDomain()
+	if base == "" {
+		return "", false
+	}
+	slug, ok := strings.CutSuffix(host, "."+base)
+	return slug, ok && slugPattern.MatchString(slug)
+}
+
+// SetCustomDomain points a custom domain at the organization, replacing any previous one.
+func (s *domainService) SetCustomDomain(actor audit.Actor, orgID uint, domain string) (*organization.Organization, error) {
+	domain = normalizeHost(domain)
+	if !domainPattern.MatchString(domain) {
+		return nil, ErrInvalidDomain
+	}
+	if base := s.links.BaseDomain(); base != "" && (domain == base || strings.HasSuffix(domain, "."+base)) {
+		return nil, ErrReservedDomain
+	}
+
+	var org organization.Organization
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&org, orgID).Error; err != nil {
+			return err
+		}
+		var before string
+		if org.CustomDomain != nil {
+			before = *org.CustomDomain
+		}
+		if before == domain {
+			return nil
+		}
+		var taken int64
+		if err := tx.Model(&organization.Organization{}).Where("custom_domain = ? AND id <> ?", domain, orgID).Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check custom domain: %w", err)
+		}
+		if taken > 0 {
+			return ErrDomainTaken
+		}
+		if err := tx.Model(&org).Update("custom_domain", domain).Error; err != nil {
+			return fmt.Errorf("failed to set custom domain: %w", err)
+		}
+		org.CustomDomain = &domain
+		if err := s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.set_domain", EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID),
+			Before: before, After: domain,
+		}); err != nil {
+			return err
+		}
+		// Provisioning last, so a failing hook rolls the change back.
+		if err := s.certs.Provision(context.Background(), domain); err != nil {
+			return fmt.Errorf("failed to provision TLS for %s: %w", domain, err)
+		}
+		if before != "" {
+			s.release(before)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return &org, nil
+}
+
+// RemoveCustomDomain detaches the organization's custom domain; it stays reachable on its subdomain.
+func (s *domainService) RemoveCustomDomain(actor audit.Actor, orgID uint) (*organization.Organization, error) {
+	var org organization.Organization
+	var before string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&org, orgID).Error; err != nil {
+			return err
+		}
+		if org.CustomDomain == nil {
+			return nil
+		}
+		before = *org.CustomDomain
+		if err := tx.Model(&org).Update("custom_domain", nil).Error; err != nil {
+			return fmt.Errorf("failed to remove custom domain: %w", err)
+		}
+		org.CustomDomain = nil
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.remove_domain", EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID), Before: before,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if before == "" {
+		return &org, nil
+	}
+	s.release(before)
+	s.invalidate()
+	return &org, nil
+}
+
+// SetBranding replaces the organization's branding.
+func (s *domainService) SetBranding(actor audit.Actor, orgID uint, branding organization.Branding) (*organization.Organization, error) {
+	var org organization.Organization
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&org, orgID).Error; err != nil {
+			return err
+		}
+		before := org.Branding
+		org.Branding = branding
+		// Select so cleared fields are written too.
+		if err := tx.Model(&org).Select("brand_display_name", "brand_logo_url", "brand_primary_color", "brand_support_email").
+			Updates(&org).Error; err != nil {
+			return fmt.Errorf("failed to update branding: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.set_branding", EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID),
+			Before: before, After: branding,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return &org, nil
+}
+
+// HostPolicy allows certificates only for custom domains of organizations that are not suspended.
+// Tenant subdomains are expected to be covered by a wildcard certificate.
+func (s *domainService) HostPolicy(ctx context.Context, host string) error {
+	host = normalizeHost(host)
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&organization.Organization{}).
+		Where("custom_domain = ? AND status <> ?", host, organization.StatusSuspended).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check custom domain: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("host %q is not a tenant custom domain", host)
+	}
+	return nil
+}
+
+// release runs the certificate hook for a domain that is no longer used. The domain is already detached,
+// so failures are only logged.
+func (s *domainService) release(domain string) {
+	if err := s.certs.Release(context.Background(), domain); err != nil {
+		log.Printf("Tenant: failed to release TLS for %s: %v", domain, err)
+	}
+}
+
+// invalidate drops all cached host resolutions. Domain and branding changes are rare, so flushing the
+// namespace is simpler than tracking every host an organization was cached under.
+func (s *domainService) invalidate() {
+	if err := s.cache.Flush(context.Background(), cache.NamespaceTenantHosts); err != nil {
+		log.Printf("Tenant: failed to flush tenant host cache: %v", err)
+	}
+}
+
+// normalizeHost lowercases a host and strips the port and any trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// DomainHandler handles white-label domain and branding endpoints.
+type DomainHandler struct {
+	service DomainService
+}
+
+// NewDomainHandler creates a new DomainHandler.
+func NewDomainHandler(service DomainService) *DomainHandler {
+	return &DomainHandler{service: service}
+}
+
+// GetBranding returns the branding of the tenant served on the request's host.
+// @Summary Get branding of the current host
+// @Description Resolved from the Host header (tenant subdomain or custom domain). The shared host has no branding.
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} PublicBranding
+// @Failure 404 {object} utils.ErrorResponse "Unknown tenant"
+// @Router /branding [get]
+func (h *DomainHandler) GetBranding(c *gin.Context) {
+	result := PublicBranding{BaseURL: c.GetString("baseURL")}
+	if org, ok := c.Get("hostOrg"); ok {
+		o := org.(*organization.Organization)
+		result.Branding, result.Name, result.Slug = o.Branding, o.Name, o.Slug
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Branding fetched successfully", result)
+}
+
+// SetCustomDomain points a custom domain at a tenant.
+// @Summary Set tenant custom domain
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain body SetDomainRequest true "Custom domain"
+// @Success 200 {object} organization.Organization
+// @Failure 409 {object} utils.ErrorResponse "Domain already taken"
+// @Router /admin/tenants/{id}/domain [put]
+func (h *DomainHandler) SetCustomDomain(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SetDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	org, err := h.service.SetCustomDomain(audit.ActorFromContext(c), orgID, req.Domain)
+	if err != nil {
+		sendDomainError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom domain set", org)
+}
+
+// RemoveCustomDomain detaches a tenant's custom domain.
+// @Summary Remove tenant custom domain
+// @Tags Tenants
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} organization.Organization
+// @Router /admin/tenants/{id}/domain [delete]
+func (h *DomainHandler) RemoveCustomDomain(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	org, err := h.service.RemoveCustomDomain(audit.ActorFromContext(c), orgID)
+	if err != nil {
+		sendDomainError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Custom domain removed", org)
+}
+
+// SetBranding replaces a tenant's branding.
+// @Summary Set tenant branding
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param branding body organization.Branding true "Branding"
+// @Success 200 {object} organization.Organization
+// @Router /admin/tenants/{id}/branding [put]
+func (h *DomainHandler) SetBranding(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req organization.Branding
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	org, err := h.service.SetBranding(audit.ActorFromContext(c), orgID, req)
+	if err != nil {
+		sendDomainError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Branding updated", org)
+}
+
+// AskCertificate lets an on-demand TLS terminator (e.g. Caddy's on_demand_tls "ask") check whether it may
+// obtain a certificate for a domain: 200 if it is an active tenant's custom domain, 404 otherwise.
+func (h *DomainHandler) AskCertificate(c *gin.Context) {
+	if err := h.service.HostPolicy(c.Request.Context(), c.Query("domain")); err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// sendDomainError maps service errors to HTTP status codes.
+func sendDomainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, ErrInvalidDomain), errors.Is(err, ErrReservedDomain):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDomainTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,211 @@
+// prometheus/backend/internal/tenant/handler.go
+package tenant
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OnboardingHandler handles the tenant onboarding wizard endpoints.
+type OnboardingHandler struct {
+	service OnboardingService
+}
+
+// NewOnboardingHandler creates a new OnboardingHandler.
+func NewOnboardingHandler(service OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{service: service}
+}
+
+// CreateOrganization starts onboarding for a new tenant.
+// @Summary Create a tenant organization
+// @Description Creates the organization in provisioning state. Re-sending the same name and slug returns the existing organization.
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param organization body CreateOrganizationRequest true "Organization"
+// @Success 201 {object} organization.Organization
+// @Failure 409 {object} utils.ErrorResponse "Slug already taken"
+// @Router /admin/tenants [post]
+func (h *OnboardingHandler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	org, err := h.service.CreateOrganization(audit.ActorFromContext(c), req)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Organization created", org)
+}
+
+// Status returns the onboarding progress of a tenant.
+// @Summary Get onboarding status
+// @Tags Tenants
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} OnboardingStatus
+// @Router /admin/tenants/{id}/onboarding [get]
+func (h *OnboardingHandler) Status(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	status, err := h.service.Status(orgID)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Onboarding status fetched successfully", status)
+}
+
+// SeedRoles installs the default roles and policies for the tenant.
+// @Summary Seed tenant roles
+// @Tags Tenants
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} OnboardingStep
+// @Failure 409 {object} utils.ErrorResponse "Previous step incomplete"
+// @Router /admin/tenants/{id}/onboarding/roles [post]
+func (h *OnboardingHandler) SeedRoles(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	step, err := h.service.SeedRoles(audit.ActorFromContext(c), orgID)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Roles seeded", step)
+}
+
+// CreateAdmin creates the tenant's first administrator.
+// @Summary Create tenant admin
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param admin body CreateAdminRequest true "Admin account"
+// @Success 200 {object} OnboardingStep
+// @Failure 409 {object} utils.ErrorResponse "Previous step incomplete"
+// @Router /admin/tenants/{id}/onboarding/admin [post]
+func (h *OnboardingHandler) CreateAdmin(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CreateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	step, err := h.service.CreateAdmin(audit.ActorFromContext(c), orgID, req)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Admin account created", step)
+}
+
+// SetCalendar stores the tenant's work week and holiday defaults.
+// @Summary Set tenant calendar
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param calendar body CalendarRequest true "Calendar settings"
+// @Success 200 {object} OnboardingStep
+// @Failure 409 {object} utils.ErrorResponse "Previous step incomplete"
+// @Router /admin/tenants/{id}/onboarding/calendar [post]
+func (h *OnboardingHandler) SetCalendar(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	step, err := h.service.SetCalendar(audit.ActorFromContext(c), orgID, req)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Calendar saved", step)
+}
+
+// ImportEmployees creates the tenant's employee accounts.
+// @Summary Import tenant employees
+// @Description Rows whose email already exists in the organization are skipped, so a failed import can be re-sent.
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param employees body ImportEmployeesRequest true "Employees"
+// @Success 200 {object} OnboardingStep
+// @Failure 409 {object} utils.ErrorResponse "Previous step incomplete"
+// @Router /admin/tenants/{id}/onboarding/employees [post]
+func (h *OnboardingHandler) ImportEmployees(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ImportEmployeesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	step, err := h.service.ImportEmployees(audit.ActorFromContext(c), orgID, req)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Employee import processed", step)
+}
+
+// Complete activates the tenant once every step is done.
+// @Summary Complete onboarding
+// @Tags Tenants
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} OnboardingStatus
+// @Failure 409 {object} utils.ErrorResponse "Steps incomplete"
+// @Router /admin/tenants/{id}/onboarding/complete [post]
+func (h *OnboardingHandler) Complete(c *gin.Context) {
+	orgID, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	status, err := h.service.Complete(audit.ActorFromContext(c), orgID)
+	if err != nil {
+		sendOnboardingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Organization activated", status)
+}
+
+// sendOnboardingError maps service errors to HTTP status codes.
+func sendOnboardingError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, ErrInvalidSlug):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSlugTaken), errors.Is(err, ErrPreviousStepIncomplete):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+// prometheus/backend/internal/tenant/model.go
+package tenant
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Onboarding steps, in the order the wizard runs them. Each step is idempotent and can be re-run.
+const (
+	StepOrganization = "organization"
+	StepRoles        = "roles"
+	StepAdmin        = "admin"
+	StepCalendar     = "calendar"
+	StepEmployees    = "employees"
+)
+
+// Steps lists the onboarding steps in order.
+var Steps = []string{StepOrganization, StepRoles, StepAdmin, StepCalendar, StepEmployees}
+
+// StepStatus is the state of an onboarding step.
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepCompleted StepStatus = "completed"
+	StepFailed    StepStatus = "failed"
+)
+
+// OnboardingStep records the outcome of one wizard step for an organization, so provisioning can be resumed.
+type OnboardingStep struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	OrganizationID uint           `gorm:"not null;uniqueIndex:idx_onboarding_step" json:"organization_id"`
+	Step           string         `gorm:"type:varchar(30);not null;uniqueIndex:idx_onboarding_step" json:"step" example:"roles"`
+	Status         StepStatus     `gorm:"type:varchar(20);not null" json:"status" example:"completed"`
+	Result         datatypes.JSON `json:"result,omitempty" swaggertype:"object"`
+	Error          string         `gorm:"type:text" json:"error,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CreateOrganizationRequest starts onboarding. Re-sending the same slug returns the existing organization.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=150" example:"Acme Corp"`
+	Slug string `json:"slug" binding:"required,min=2,max=63" example:"acme"`
+}
+
+// CreateAdminRequest defines the first administrator of the organization.
+type CreateAdminRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=100" example:"acme-admin"`
+	Email    string `json:"email" binding:"required,email" example:"it@acme.example"`
+	Password string `json:"password" binding:"required,min=8,max=72" example:"Sup3rSecret!"`
+}
+
+// HolidayPreset is a default holiday captured during onboarding.
+type HolidayPreset struct {
+	Date string `json:"date" binding:"required" example:"2026-08-17"` // YYYY-MM-DD
+	Name string `json:"name" binding:"required" example:"Independence Day"`
+}
+
+// CalendarRequest sets the organization's work week and holiday defaults.
+type CalendarRequest struct {
+	Timezone    string          `json:"timezone" binding:"required" example:"Asia/Jakarta"`
+	CountryCode string          `json:"country_code" binding:"omitempty,len=2" example:"ID"`
+	WorkWeek    []int           `json:"work_week" binding:"required,min=1,max=7,dive,min=1,max=7" example:"1,2,3,4,5"` // ISO weekdays
+	Holidays    []HolidayPreset `json:"holidays" binding:"dive"`
+}
+
+// EmployeeImportRow is one employee account to create.
+type EmployeeImportRow struct {
+	Username string `json:"username" binding:"required,min=3,max=100" example:"jdoe"`
+	Email    string `json:"email" binding:"required,email" example:"jdoe@acme.example"`
+	Role     string `json:"role" example:"staff"` // Defaults to "staff"; elevated roles are not allowed here
+}
+
+// ImportEmployeesRequest creates employee accounts. Rows whose email already exists are skipped.
+type ImportEmployeesRequest struct {
+	Employees []EmployeeImportRow `json:"employees" binding:"required,min=1,dive"`
+}
+
+// EmployeeImportResult reports the outcome of a single row.
+type EmployeeImportResult struct {
+	Email  string `json:"email"`
+	Status string `json:"status" example:"created"` // created, skipped, failed
+	Error  string `json:"error,omitempty"`
+}
+
+// OnboardingStatus summarizes onboarding progress.
+type OnboardingStatus struct {
+	OrganizationID uint             `json:"organization_id"`
+	Status         string           `json:"status" example:"provisioning"`
+	NextStep       string           `json:"next_step,omitempty" example:"admin"`
+	Steps          []OnboardingStep `json:"steps"`
+}
@@ -0,0 +1,408 @@
+// prometheus/backend/internal/tenant/service.go
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/role"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"gorm.io/gorm"
+)
+
+// ErrPreviousStepIncomplete is returned when a step is run before the steps it depends on.
+var ErrPreviousStepIncomplete = errors.New("previous onboarding step is not completed")
+
+// ErrInvalidSlug is returned when an organization slug is not a valid DNS label.
+var ErrInvalidSlug = errors.New("slug must be lowercase letters, digits and hyphens")
+
+// ErrSlugTaken is returned when the slug belongs to an organization with a different name.
+var ErrSlugTaken = errors.New("slug is already used by another organization")
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// OnboardingService defines the interface for the tenant onboarding wizard.
+type OnboardingService interface {
+	CreateOrganization(actor audit.Actor, req CreateOrganizationRequest) (*organization.Organization, error)
+	SeedRoles(actor audit.Actor, orgID uint) (*OnboardingStep, error)
+	CreateAdmin(actor audit.Actor, orgID uint, req CreateAdminRequest) (*OnboardingStep, error)
+	SetCalendar(actor audit.Actor, orgID uint, req CalendarRequest) (*OnboardingStep, error)
+	ImportEmployees(actor audit.Actor, orgID uint, req ImportEmployeesRequest) (*OnboardingStep, error)
+	Complete(actor audit.Actor, orgID uint) (*OnboardingStatus, error)
+	Status(orgID uint) (*OnboardingStatus, error)
+}
+
+// onboardingService implements the OnboardingService interface.
+type onboardingService struct {
+	db       *gorm.DB
+	enforcer *casbin.SyncedEnforcer
+	auditor  audit.Service
+	plans    plan.Service
+}
+
+// NewOnboardingService creates a new instance of OnboardingService.
+func NewOnboardingService(db *gorm.DB, enforcer *casbin.SyncedEnforcer, auditor audit.Service, plans plan.Service) OnboardingService {
+	return &onboardingService{db: db, enforcer: enforcer, auditor: auditor, plans: plans}
+}
+
+// CreateOrganization creates the organization in provisioning state. Calling it again with the same
+// slug and name returns the existing organization instead of failing.
+func (s *onboardingService) CreateOrganization(actor audit.Actor, req CreateOrganizationRequest) (*organization.Organization, error) {
+	if !slugPattern.MatchString(req.Slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	var org organization.Organization
+	err := s.db.Where("slug = ?", req.Slug).First(&org).Error
+	switch {
+	case err == nil:
+		if org.Name != req.Name {
+			return nil, ErrSlugTaken
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		org = organization.Organization{Name: req.Name, Slug: req.Slug, Status: organization.StatusProvisioning}
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&org).Error; err != nil {
+				return fmt.Errorf("failed to create organization: %w", err)
+			}
+			return s.record(tx, actor, "tenant.create", org.ID, org)
+		}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to look up organization: %w", err)
+	}
+
+	if _, err := s.complete(org.ID, StepOrganization, org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// SeedRoles installs the default role matrix in the organization's authorization domain.
+func (s *onboardingService) SeedRoles(actor audit.Actor, orgID uint) (*OnboardingStep, error) {
+	org, err := s.requireStep(orgID, StepRoles)
+	if err != nil {
+		return nil, err
+	}
+	// Casbin writes through its own connection, so the rules are seeded inside the audit transaction: a
+	// failing audit fails the step, and seeding again adds only what is missing.
+	var result map[string]interface{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		added, err := authz.SeedDomain(s.enforcer, org.Domain())
+		if err != nil {
+			return err
+		}
+		result = map[string]interface{}{"domain": org.Domain(), "rules_added": added}
+		return s.record(tx, actor, "tenant.seed_roles", orgID, result)
+	})
+	if err != nil {
+		return s.fail(orgID, StepRoles, err)
+	}
+	return s.complete(orgID, StepRoles, result)
+}
+
+// CreateAdmin creates the organization's first administrator. Re-running the step with the same email
+// returns the existing account.
+func (s *onboardingService) CreateAdmin(actor audit.Actor, orgID uint, req CreateAdminRequest) (*OnboardingStep, error) {
+	if _, err := s.requireStep(orgID, StepAdmin); err != nil {
+		return nil, err
+	}
+
+	var admin auth.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("email = ?", req.Email).First(&admin).Error
+		if err == nil {
+			if admin.OrganizationID == nil || *admin.OrganizationID != orgID {
+				return fmt.Errorf("email %s already belongs to another organization", req.Email)
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := s.plans.CheckEmployeeLimit(tx, orgID, 1); err != nil {
+			return err
+		}
+		var adminRole role.Role
+		if err := tx.Where("name = ?", "admin").First(&adminRole).Error; err != nil {
+			return fmt.Errorf("'admin' role not found: %w", err)
+		}
+		hashed, err := auth.HashPassword(req.Password)
+		if err != nil {
+			return err
+		}
+		admin = auth.User{
+			Username:       req.Username,
+			Email:          req.Email,
+			Password:       hashed,
+			IsActive:       true,
+			OrganizationID: &orgID,
+			// Provisioning is performed by a god-admin, so the elevated role is granted directly.
+			Roles: []role.Role{adminRole},
+		}
+		if err := tx.Create(&admin).Error; err != nil {
+			return fmt.Errorf("failed to create organization admin: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "tenant.create_admin", EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID),
+			After: map[string]interface{}{"user_id": admin.ID, "username": admin.Username, "email": admin.Email},
+		})
+	})
+	if err != nil {
+		return s.fail(orgID, StepAdmin, err)
+	}
+	return s.complete(orgID, StepAdmin, map[string]interface{}{"user_id": admin.ID, "username": admin.Username})
+}
+
+// SetCalendar stores the work week, timezone and holiday defaults. Re-running overwrites them.
+func (s *onboardingService) SetCalendar(actor audit.Actor, orgID uint, req CalendarRequest) (*OnboardingStep, error) {
+	if _, err := s.requireStep(orgID, StepCalendar); err != nil {
+		return nil, err
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", req.Timezone, err)
+	}
+	for _, h := range req.Holidays {
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: expected YYYY-MM-DD", h.Date)
+		}
+	}
+
+	workWeek, _ := json.Marshal(req.WorkWeek)
+	holidays, _ := json.Marshal(req.Holidays)
+	updates := map[string]interface{}{
+		"timezone":        req.Timezone,
+		"country_code":    req.CountryCode,
+		"work_week":       workWeek,
+		"holiday_presets": holidays,
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&organization.Organization{}).Where("id = ?", orgID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return s.record(tx, actor, "tenant.set_calendar", orgID, req)
+	}); err != nil {
+		return s.fail(orgID, StepCalendar, err)
+	}
+	return s.complete(orgID, StepCalendar, req)
+}
+
+// ImportEmployees creates employee accounts with random passwords. Rows whose email already exists in the
+// organization are skipped, so a partially failed import can simply be re-sent.
+func (s *onboardingService) ImportEmployees(actor audit.Actor, orgID uint, req ImportEmployeesRequest) (*OnboardingStep, error) {
+	if _, err := s.requireStep(orgID, StepEmployees); err != nil {
+		return nil, err
+	}
+
+	var roles []role.Role
+	if err := s.db.Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	rolesByName := make(map[string]role.Role, len(roles))
+	for _, r := range roles {
+		rolesByName[r.Name] = r
+	}
+
+	results := make([]EmployeeImportResult, 0, len(req.Employees))
+	created, failed := 0, 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range req.Employees {
+			var result EmployeeImportResult
+			// Each row runs in its own savepoint, rolled back when it fails, so it doesn't abort the others.
+			_ = tx.Transaction(func(rowTx *gorm.DB) error {
+				result = s.importEmployee(rowTx, orgID, row, rolesByName)
+				if result.Status == "failed" {
+					return errors.New(result.Error)
+				}
+				return nil
+			})
+			switch result.Status {
+			case "created":
+				created++
+			case "failed":
+				failed++
+			}
+			results = append(results, result)
+		}
+		return s.record(tx, actor, "tenant.import_employees", orgID, map[string]int{"created": created, "failed": failed})
+	})
+	if err != nil {
+		return s.fail(orgID, StepEmployees, err)
+	}
+
+	summary := map[string]interface{}{"created": created, "failed": failed, "rows": results}
+	if failed > 0 {
+		step, _ := s.save(orgID, StepEmployees, StepFailed, summary, fmt.Sprintf("%d rows failed", failed))
+		return step, nil
+	}
+	return s.complete(orgID, StepEmployees, summary)
+}
+
+// importEmployee creates a single employee account.
+func (s *onboardingService) importEmployee(tx *gorm.DB, orgID uint, row EmployeeImportRow, rolesByName map[string]role.Role) EmployeeImportResult {
+	result := EmployeeImportResult{Email: row.Email}
+	roleName := row.Role
+	if roleName == "" {
+		roleName = "staff"
+	}
+	r, ok := rolesByName[roleName]
+	if !ok || auth.IsElevatedRole(roleName) {
+		result.Status, result.Error = "failed", fmt.Sprintf("role %q is not allowed", roleName)
+		return result
+	}
+
+	var existing auth.User
+	err := tx.Unscoped().Where("email = ? OR LOWER(username) = LOWER(?)", row.Email, row.Username).First(&existing).Error
+	if err == nil {
+		if existing.OrganizationID != nil && *existing.OrganizationID == orgID {
+			result.Status = "skipped"
+		} else {
+			result.Status, result.Error = "failed", "username or email already exists"
+		}
+		return result
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+
+	if err := s.plans.CheckEmployeeLimit(tx, orgID, 1); err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+
+	password, err := auth.GenerateRandomPassword()
+	if err == nil {
+		password, err = auth.HashPassword(password)
+	}
+	if err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+	user := auth.User{Username: row.Username, Email: row.Email, Password: password, IsActive: true, OrganizationID: &orgID, Roles: []role.Role{r}}
+	if err := tx.Create(&user).Error; err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+	result.Status = "created"
+	return result
+}
+
+// Complete activates the organization once every step has completed.
+func (s *onboardingService) Complete(actor audit.Actor, orgID uint) (*OnboardingStatus, error) {
+	status, err := s.Status(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if status.NextStep != "" {
+		return nil, fmt.Errorf("%w: %s", ErrPreviousStepIncomplete, status.NextStep)
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&organization.Organization{}).Where("id = ?", orgID).Update("status", organization.StatusActive).Error; err != nil {
+			return fmt.Errorf("failed to activate organization: %w", err)
+		}
+		return s.record(tx, actor, "tenant.activate", orgID, nil)
+	}); err != nil {
+		return nil, err
+	}
+	return s.Status(orgID)
+}
+
+// Status reports the state of every onboarding step and the next step to run.
+func (s *onboardingService) Status(orgID uint) (*OnboardingStatus, error) {
+	var org organization.Organization
+	if err := s.db.First(&org, orgID).Error; err != nil {
+		return nil, err
+	}
+	var recorded []OnboardingStep
+	if err := s.db.Where("organization_id = ?", orgID).Find(&recorded).Error; err != nil {
+		return nil, fmt.Errorf("failed to load onboarding steps: %w", err)
+	}
+
+	status := &OnboardingStatus{OrganizationID: orgID, Status: string(org.Status)}
+	for _, name := range Steps {
+		idx := slices.IndexFunc(recorded, func(st OnboardingStep) bool { return st.Step == name })
+		step := OnboardingStep{OrganizationID: orgID, Step: name, Status: StepPending}
+		if idx >= 0 {
+			step = recorded[idx]
+		}
+		if step.Status != StepCompleted && status.NextStep == "" {
+			status.NextStep = name
+		}
+		status.Steps = append(status.Steps, step)
+	}
+	return status, nil
+}
+
+// requireStep loads the organization and checks that every step before `step` has completed.
+func (s *onboardingService) requireStep(orgID uint, step string) (*organization.Organization, error) {
+	var org organization.Organization
+	if err := s.db.First(&org, orgID).Error; err != nil {
+		return nil, err
+	}
+	required := Steps[:slices.Index(Steps, step)]
+	var completed int64
+	if err := s.db.Model(&OnboardingStep{}).
+		Where("organization_id = ? AND step IN ? AND status = ?", orgID, required, StepCompleted).
+		Count(&completed).Error; err != nil {
+		return nil, fmt.Errorf("failed to check onboarding progress: %w", err)
+	}
+	if int(completed) < len(required) {
+		return nil, ErrPreviousStepIncomplete
+	}
+	return &org, nil
+}
+
+func (s *onboardingService) complete(orgID uint, step string, result interface{}) (*OnboardingStep, error) {
+	return s.save(orgID, step, StepCompleted, result, "")
+}
+
+func (s *onboardingService) fail(orgID uint, step string, cause error) (*OnboardingStep, error) {
+	if _, err := s.save(orgID, step, StepFailed, nil, cause.Error()); err != nil {
+		return nil, err
+	}
+	return nil, cause
+}
+
+// save upserts the step record.
+func (s *onboardingService) save(orgID uint, step string, status StepStatus, result interface{}, errMsg string) (*OnboardingStep, error) {
+	var record OnboardingStep
+	if err := s.db.Where(OnboardingStep{OrganizationID: orgID, Step: step}).FirstOrInit(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to load onboarding step: %w", err)
+	}
+	record.Status = status
+	record.Error = errMsg
+	record.Result = nil
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode onboarding step result: %w", err)
+		}
+		record.Result = data
+	}
+	record.CompletedAt = nil
+	if status == StepCompleted {
+		now := time.Now().UTC()
+		record.CompletedAt = &now
+	}
+	if err := s.db.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save onboarding step: %w", err)
+	}
+	return &record, nil
+}
+
+// record audits an onboarding action inside the transaction making the change.
+func (s *onboardingService) record(tx *gorm.DB, actor audit.Actor, action string, orgID uint, after interface{}) error {
+	return s.auditor.RecordTx(tx, actor, audit.Entry{Action: action, EntityType: "organization", EntityID: fmt.Sprintf("%d", orgID), After: after})
+}
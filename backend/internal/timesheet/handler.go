@@ -0,0 +1,554 @@
+// prometheus/backend/internal/timesheet/handler.go
+package timesheet
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for projects and timesheets.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListProjects returns the organization's projects with their tasks.
+// @Summary List projects
+// @Tags Timesheets
+// @Produce json
+// @Param active query bool false "Only active projects and tasks"
+// @Success 200 {array} Project
+// @Router /hr/timesheet-projects [get]
+func (h *Handler) ListProjects(c *gin.Context) {
+	projects, err := h.service.Projects(utils.OrganizationFromContext(c), c.Query("active") == "true")
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Projects fetched successfully", projects)
+}
+
+// MyProjects returns the projects and tasks the caller can log hours against.
+// @Summary List projects to log hours against
+// @Tags Timesheets
+// @Produce json
+// @Success 200 {array} Project
+// @Router /me/timesheet-projects [get]
+func (h *Handler) MyProjects(c *gin.Context) {
+	projects, err := h.service.Projects(utils.OrganizationFromContext(c), true)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Projects fetched successfully", projects)
+}
+
+// GetProject returns a project with its tasks.
+// @Summary Get a project
+// @Tags Timesheets
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} Project
+// @Failure 404 {object} utils.ErrorResponse "Project not found"
+// @Router /hr/timesheet-projects/{id} [get]
+func (h *Handler) GetProject(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	project, err := h.service.GetProject(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, project.UpdatedAt, project.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Project fetched successfully", project)
+}
+
+// CreateProject creates a project.
+// @Summary Create a project
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param project body ProjectRequest true "Project"
+// @Success 201 {object} Project
+// @Failure 400 {object} utils.ErrorResponse "Invalid project"
+// @Failure 409 {object} utils.ErrorResponse "Code already in use"
+// @Router /hr/timesheet-projects [post]
+func (h *Handler) CreateProject(c *gin.Context) {
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	project, err := h.service.CreateProject(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, project.UpdatedAt, project.Version)
+	utils.SendSuccessResponse(c, http.StatusCreated, "Project created successfully", project)
+}
+
+// UpdateProject replaces a project's fields. An inactive project takes no new hours.
+// @Summary Update a project
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param project body ProjectRequest true "Project"
+// @Success 200 {object} Project
+// @Failure 400 {object} utils.ErrorResponse "Invalid project"
+// @Failure 404 {object} utils.ErrorResponse "Project not found"
+// @Failure 409 {object} utils.ErrorResponse "Code already in use"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/timesheet-projects/{id} [put]
+func (h *Handler) UpdateProject(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetProject(orgID, id)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	project, err := h.service.UpdateProject(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, project.UpdatedAt, project.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Project updated successfully", project)
+}
+
+// CreateTask adds a task to a project.
+// @Summary Create a task
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param task body TaskRequest true "Task"
+// @Success 201 {object} Task
+// @Failure 400 {object} utils.ErrorResponse "Invalid task"
+// @Failure 404 {object} utils.ErrorResponse "Project not found"
+// @Router /hr/timesheet-projects/{id}/tasks [post]
+func (h *Handler) CreateTask(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req TaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	task, err := h.service.CreateTask(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Task created successfully", task)
+}
+
+// UpdateTask replaces a task's fields. An inactive task takes no new hours.
+// @Summary Update a task
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param task body TaskRequest true "Task"
+// @Success 200 {object} Task
+// @Failure 400 {object} utils.ErrorResponse "Invalid task"
+// @Failure 404 {object} utils.ErrorResponse "Task not found"
+// @Router /hr/timesheet-tasks/{id} [put]
+func (h *Handler) UpdateTask(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req TaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	task, err := h.service.UpdateTask(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Task updated successfully", task)
+}
+
+// Mine lists the caller's timesheets.
+// @Summary List own timesheets
+// @Tags Timesheets
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/timesheets [get]
+func (h *Handler) Mine(c *gin.Context) {
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	page := utils.ParsePagination(c)
+	timesheets, total, err := h.service.Mine(utils.OrganizationFromContext(c), emp.ID, page)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheets fetched successfully", page.Response(timesheets, total))
+}
+
+// MyWeek returns the caller's timesheet for a week, empty if they haven't logged anything yet.
+// @Summary Get own timesheet for a week
+// @Tags Timesheets
+// @Produce json
+// @Param week path string true "Monday starting the week (YYYY-MM-DD)"
+// @Success 200 {object} Timesheet
+// @Failure 400 {object} utils.ErrorResponse "Not a Monday"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/timesheets/{week} [get]
+func (h *Handler) MyWeek(c *gin.Context) {
+	weekStart, ok := parseWeek(c)
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	timesheet, err := h.service.Week(utils.OrganizationFromContext(c), emp.ID, weekStart)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheet fetched successfully", timesheet)
+}
+
+// SaveEntries replaces the hours the caller logged in a week.
+// @Summary Log hours for a week
+// @Description Replaces all entries of the week. Submitted and approved weeks are read-only; saving a
+// @Description rejected week makes it a draft again.
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param week path string true "Monday starting the week (YYYY-MM-DD)"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param entries body EntriesRequest true "Entries"
+// @Success 200 {object} Timesheet
+// @Failure 400 {object} utils.ErrorResponse "Invalid entries"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Failure 409 {object} utils.ErrorResponse "Submitted or approved"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /me/timesheets/{week}/entries [put]
+func (h *Handler) SaveEntries(c *gin.Context) {
+	weekStart, ok := parseWeek(c)
+	if !ok {
+		return
+	}
+	var req EntriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Week(orgID, emp.ID, weekStart)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	timesheet, err := h.service.SaveEntries(audit.ActorFromContext(c), orgID, emp.ID, weekStart, expectedVersion, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheet saved successfully", timesheet)
+}
+
+// Submit sends the caller's week to their manager. It is read-only from then on.
+// @Summary Submit a week
+// @Tags Timesheets
+// @Produce json
+// @Param week path string true "Monday starting the week (YYYY-MM-DD)"
+// @Success 200 {object} Timesheet
+// @Failure 400 {object} utils.ErrorResponse "Not a Monday, or the week hasn't started"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Failure 409 {object} utils.ErrorResponse "Already submitted or approved"
+// @Router /me/timesheets/{week}/submit [post]
+func (h *Handler) Submit(c *gin.Context) {
+	weekStart, ok := parseWeek(c)
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	timesheet, err := h.service.Submit(audit.ActorFromContext(c), utils.OrganizationFromContext(c), emp.ID, weekStart)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheet submitted successfully", timesheet)
+}
+
+// List returns the timesheets the caller decides: their reports' for a manager, everyone's for HR.
+// @Summary List timesheets
+// @Tags Timesheets
+// @Produce json
+// @Param status query string false "Status" Enums(draft, submitted, approved, rejected)
+// @Param employee_id query int false "Employee ID"
+// @Param division_id query int false "Division ID"
+// @Param week query string false "Monday starting the week (YYYY-MM-DD)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/timesheets [get]
+// @Router /hr/timesheets [get]
+func (h *Handler) List(c *gin.Context) {
+	filter := Filter{Status: Status(c.Query("status"))}
+	switch filter.Status {
+	case "", StatusDraft, StatusSubmitted, StatusApproved, StatusRejected:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+		return
+	}
+	var ok bool
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return
+	}
+	if raw := c.Query("week"); raw != "" {
+		weekStart, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid week parameter: expected YYYY-MM-DD")
+			return
+		}
+		filter.WeekStart = &weekStart
+	}
+	page := utils.ParsePagination(c)
+	timesheets, total, err := h.service.List(utils.OrganizationFromContext(c), approver(c), filter, page)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheets fetched successfully", page.Response(timesheets, total))
+}
+
+// Get returns a timesheet the caller decides, with its entries and history.
+// @Summary Get a timesheet
+// @Tags Timesheets
+// @Produce json
+// @Param id path int true "Timesheet ID"
+// @Success 200 {object} Timesheet
+// @Failure 404 {object} utils.ErrorResponse "Timesheet not found"
+// @Router /manager/timesheets/{id} [get]
+// @Router /hr/timesheets/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	timesheet, err := h.service.Get(utils.OrganizationFromContext(c), approver(c), id)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheet fetched successfully", timesheet)
+}
+
+// Approve approves a report's submitted timesheet.
+// @Summary Approve a timesheet
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Timesheet ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param decision body DecisionRequest false "Note"
+// @Success 200 {object} Timesheet
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Timesheet not found"
+// @Failure 409 {object} utils.ErrorResponse "Not submitted"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /manager/timesheets/{id}/approve [post]
+func (h *Handler) Approve(c *gin.Context) {
+	h.decide(c, h.service.Approve, "Timesheet approved successfully")
+}
+
+// Reject returns a report's submitted timesheet to them to correct.
+// @Summary Reject a timesheet
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Timesheet ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param decision body DecisionRequest true "What to correct"
+// @Success 200 {object} Timesheet
+// @Failure 400 {object} utils.ErrorResponse "No note"
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Timesheet not found"
+// @Failure 409 {object} utils.ErrorResponse "Not submitted"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /manager/timesheets/{id}/reject [post]
+func (h *Handler) Reject(c *gin.Context) {
+	h.decide(c, h.service.Reject, "Timesheet rejected successfully")
+}
+
+type decision func(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, req DecisionRequest) (*Timesheet, error)
+
+func (h *Handler) decide(c *gin.Context, decide decision, message string) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+	orgID, caller := utils.OrganizationFromContext(c), approver(c)
+	current, err := h.service.Get(orgID, caller, id)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	timesheet, err := decide(audit.ActorFromContext(c), orgID, caller, id, expectedVersion, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, message, timesheet)
+}
+
+// Reopen returns a submitted or approved timesheet to its employee as a draft.
+// @Summary Reopen a timesheet
+// @Description The entries are kept for the employee to correct. The reason, and the hours logged when it
+// @Description was reopened, stay in the timesheet's history and the audit log.
+// @Tags Timesheets
+// @Accept json
+// @Produce json
+// @Param id path int true "Timesheet ID"
+// @Param If-Match header string false "Version ETag from GET"
+// @Param reopen body ReopenRequest true "Reason"
+// @Success 200 {object} Timesheet
+// @Failure 400 {object} utils.ErrorResponse "No reason"
+// @Failure 404 {object} utils.ErrorResponse "Timesheet not found"
+// @Failure 409 {object} utils.ErrorResponse "Not submitted or approved"
+// @Failure 412 {object} utils.ErrorResponse "Modified since fetched"
+// @Router /hr/timesheets/{id}/reopen [post]
+func (h *Handler) Reopen(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ReopenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.Get(orgID, approver(c), id)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	timesheet, err := h.service.Reopen(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendTimesheetError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, timesheet.UpdatedAt, timesheet.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Timesheet reopened successfully", timesheet)
+}
+
+func approver(c *gin.Context) Approver {
+	userID, hr, leads := middleware.LineManagement(c)
+	return Approver{UserID: userID, HR: hr, Leads: leads}
+}
+
+// parseWeek reads the week path parameter, the Monday starting the week.
+func parseWeek(c *gin.Context) (time.Time, bool) {
+	weekStart, err := time.Parse("2006-01-02", c.Param("week"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid week: expected the Monday starting it as YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return weekStart, true
+}
+
+// sendTimesheetError maps service errors to HTTP status codes.
+func sendTimesheetError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidTimesheet):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotApprover):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrTimesheetStatus), errors.Is(err, ErrCodeTaken):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The timesheet was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,161 @@
+// prometheus/backend/internal/timesheet/model.go
+package timesheet
+
+import (
+	"time"
+)
+
+// Project is something staff log hours against, optionally broken into tasks. Projects and tasks are
+// deactivated rather than deleted, as logged hours keep pointing at them.
+type Project struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"3"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Code           string    `gorm:"type:varchar(30);not null" json:"code" example:"ERP"` // Unique within the organization, case-insensitively
+	Name           string    `gorm:"type:varchar(150);not null" json:"name" example:"ERP migration"`
+	Active         bool      `gorm:"not null" json:"active"`
+	Tasks          []Task    `gorm:"foreignKey:ProjectID" json:"tasks"`
+	Version        uint      `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps projects with the rest of the timesheet tables.
+func (Project) TableName() string { return "timesheet_projects" }
+
+// Task is a part of a project.
+type Task struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"8"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	ProjectID      uint      `gorm:"not null;index" json:"project_id" example:"3"`
+	Name           string    `gorm:"type:varchar(150);not null" json:"name" example:"Data mapping"`
+	Active         bool      `gorm:"not null" json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName keeps tasks with the rest of the timesheet tables.
+func (Task) TableName() string { return "timesheet_tasks" }
+
+// Status is where a timesheet is.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"     // The employee is logging hours
+	StatusSubmitted Status = "submitted" // Waiting for the manager; read-only
+	StatusApproved  Status = "approved"  // Read-only; HR may reopen it
+	StatusRejected  Status = "rejected"  // Returned to the employee to correct and submit again
+)
+
+// Timesheet is an employee's hours for one week, Monday to Sunday. Submitted and approved timesheets are
+// read-only until HR reopens them.
+type Timesheet struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"120"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint       `gorm:"not null;uniqueIndex:idx_timesheet_week" json:"employee_id" example:"12"`
+	DisplayName    string     `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	WeekStart      time.Time  `gorm:"type:date;not null;uniqueIndex:idx_timesheet_week;index" json:"week_start" example:"2026-10-12T00:00:00Z"` // A Monday
+	Status         Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"submitted"`
+	TotalMinutes   int        `gorm:"not null" json:"total_minutes" example:"2400"`
+	SubmittedAt    *time.Time `json:"submitted_at,omitempty"`
+	DecidedBy      *uint      `json:"decided_by,omitempty" example:"3"` // User ID of the approver or rejecter
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	DecisionNote   string     `gorm:"type:varchar(1000)" json:"decision_note,omitempty"`
+	Entries        []Entry    `gorm:"foreignKey:TimesheetID" json:"entries"`
+	Events         []Event    `gorm:"foreignKey:TimesheetID" json:"events,omitempty"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Entry is time logged on a day against a project and, optionally, one of its tasks.
+type Entry struct {
+	ID          uint      `gorm:"primaryKey" json:"id" example:"900"`
+	TimesheetID uint      `gorm:"not null;index" json:"timesheet_id" example:"120"`
+	Date        time.Time `gorm:"type:date;not null" json:"date" example:"2026-10-13T00:00:00Z"`
+	ProjectID   uint      `gorm:"not null;index" json:"project_id" example:"3"`
+	TaskID      *uint     `gorm:"index" json:"task_id,omitempty" example:"8"`
+	Minutes     int       `gorm:"not null" json:"minutes" example:"240"`
+	Note        string    `gorm:"type:varchar(500)" json:"note,omitempty" example:"Mapped the payroll tables"`
+}
+
+// TableName keeps entries with the rest of the timesheet tables.
+func (Entry) TableName() string { return "timesheet_entries" }
+
+// EventAction is what happened to a timesheet.
+type EventAction string
+
+const (
+	EventSubmitted EventAction = "submitted"
+	EventApproved  EventAction = "approved"
+	EventRejected  EventAction = "rejected"
+	EventReopened  EventAction = "reopened" // By HR, with a reason
+)
+
+// Event is a step in a timesheet's history, kept so a reopened week shows what was approved before.
+type Event struct {
+	ID             uint        `gorm:"primaryKey" json:"id" example:"301"`
+	OrganizationID *uint       `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	TimesheetID    uint        `gorm:"not null;index" json:"timesheet_id" example:"120"`
+	Action         EventAction `gorm:"type:varchar(20);not null" json:"action" example:"reopened"`
+	FromStatus     Status      `gorm:"type:varchar(20);not null" json:"from_status" example:"approved"`
+	TotalMinutes   int         `gorm:"not null" json:"total_minutes" example:"2400"` // Logged at the time
+	ActorID        *uint       `json:"actor_id,omitempty" example:"7"`               // User ID
+	Note           string      `gorm:"type:varchar(1000)" json:"note,omitempty" example:"Hours on the wrong project"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// TableName keeps events with the rest of the timesheet tables.
+func (Event) TableName() string { return "timesheet_events" }
+
+// Approver is who decides timesheets: HR for anyone, a manager for their direct reports and the divisions
+// they lead.
+type Approver struct {
+	UserID uint
+	HR     bool   // Holds an HR role globally
+	Leads  []uint // Divisions led through a division-scoped manager role; headed divisions are added by the service
+}
+
+// ProjectRequest creates a project or replaces its fields.
+type ProjectRequest struct {
+	Code   string `json:"code" binding:"required,max=30" example:"ERP"`
+	Name   string `json:"name" binding:"required,max=150" example:"ERP migration"`
+	Active *bool  `json:"active,omitempty" example:"true"` // Defaults to true
+}
+
+// TaskRequest creates a task or replaces its fields.
+type TaskRequest struct {
+	Name   string `json:"name" binding:"required,max=150" example:"Data mapping"`
+	Active *bool  `json:"active,omitempty" example:"true"` // Defaults to true
+}
+
+// EntryRequest is time logged on a day.
+type EntryRequest struct {
+	Date      string `json:"date" binding:"required,datetime=2006-01-02" example:"2026-10-13"`
+	ProjectID uint   `json:"project_id" binding:"required" example:"3"`
+	TaskID    *uint  `json:"task_id,omitempty" example:"8"`
+	Minutes   int    `json:"minutes" binding:"required,min=1,max=1440" example:"240"`
+	Note      string `json:"note,omitempty" binding:"max=500" example:"Mapped the payroll tables"`
+}
+
+// EntriesRequest replaces all entries of a week.
+type EntriesRequest struct {
+	Entries []EntryRequest `json:"entries" binding:"max=500,dive"`
+}
+
+// DecisionRequest approves or rejects a submitted timesheet. Rejecting needs a note.
+type DecisionRequest struct {
+	Note string `json:"note,omitempty" binding:"max=1000" example:"Tuesday is logged twice"`
+}
+
+// ReopenRequest returns a submitted or approved timesheet to its employee.
+type ReopenRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000" example:"Hours on the wrong project"`
+}
+
+// Filter narrows a timesheet listing.
+type Filter struct {
+	Status     Status
+	EmployeeID *uint
+	DivisionID *uint
+	WeekStart  *time.Time
+}
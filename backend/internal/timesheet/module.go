@@ -0,0 +1,56 @@
+// prometheus/backend/internal/timesheet/module.go
+package timesheet
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the timesheet module.
+const ModuleName = "timesheets"
+
+// timesheetModule owns projects, tasks and the weekly timesheets hours are logged on.
+type timesheetModule struct {
+	handler *Handler
+}
+
+// NewModule creates the timesheet module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &timesheetModule{handler: NewHandler(svc)}
+}
+
+func (m *timesheetModule) Name() string { return ModuleName }
+
+func (m *timesheetModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *timesheetModule) Models() []any {
+	return []any{&Project{}, &Task{}, &Timesheet{}, &Entry{}, &Event{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Everything belongs to the attendance module: staff log and
+// submit their weeks, managers decide their reports', and HR keeps the projects and reopens weeks.
+func (m *timesheetModule) RegisterRoutes(api *routing.Group) {
+	attendanceAPI := api.InModule(plan.ModuleAttendance)
+	attendanceAPI.GET("/me/timesheets", routing.Authenticated(), m.handler.Mine)
+	attendanceAPI.GET("/me/timesheets/:week", routing.Authenticated(), m.handler.MyWeek)
+	attendanceAPI.PUT("/me/timesheets/:week/entries", routing.Authenticated(), m.handler.SaveEntries)
+	attendanceAPI.POST("/me/timesheets/:week/submit", routing.Authenticated(), m.handler.Submit)
+	attendanceAPI.GET("/me/timesheet-projects", routing.Authenticated(), m.handler.MyProjects)
+
+	attendanceAPI.GET("/manager/timesheets", routing.Policy(), m.handler.List)
+	attendanceAPI.GET("/manager/timesheets/:id", routing.Policy(), m.handler.Get)
+	attendanceAPI.POST("/manager/timesheets/:id/approve", routing.Policy(), m.handler.Approve)
+	attendanceAPI.POST("/manager/timesheets/:id/reject", routing.Policy(), m.handler.Reject)
+
+	attendanceAPI.GET("/hr/timesheets", routing.Policy(), m.handler.List)
+	attendanceAPI.GET("/hr/timesheets/:id", routing.Policy(), m.handler.Get)
+	attendanceAPI.POST("/hr/timesheets/:id/reopen", routing.Policy(), m.handler.Reopen)
+	attendanceAPI.GET("/hr/timesheet-projects", routing.Policy(), m.handler.ListProjects)
+	attendanceAPI.POST("/hr/timesheet-projects", routing.Policy(), m.handler.CreateProject)
+	attendanceAPI.GET("/hr/timesheet-projects/:id", routing.Policy(), m.handler.GetProject)
+	attendanceAPI.PUT("/hr/timesheet-projects/:id", routing.Policy(), m.handler.UpdateProject)
+	attendanceAPI.POST("/hr/timesheet-projects/:id/tasks", routing.Policy(), m.handler.CreateTask)
+	attendanceAPI.PUT("/hr/timesheet-tasks/:id", routing.Policy(), m.handler.UpdateTask)
+}
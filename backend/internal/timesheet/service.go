@@ -0,0 +1,687 @@
+// prometheus/backend/internal/timesheet/service.go
+package timesheet
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidTimesheet is returned for projects, tasks and entries that fail validation.
+	ErrInvalidTimesheet = errors.New("invalid timesheet")
+	// ErrReadOnly is returned when changing a submitted or approved timesheet.
+	ErrReadOnly = errors.New("the timesheet is submitted or approved and read-only")
+	// ErrTimesheetStatus is returned for decisions the timesheet's status doesn't allow.
+	ErrTimesheetStatus = errors.New("the timesheet's status does not allow this change")
+	// ErrNotApprover is returned when deciding a timesheet of someone who isn't the approver's report.
+	ErrNotApprover = errors.New("you may only decide your reports' timesheets")
+	// ErrCodeTaken is returned for a project code already in use.
+	ErrCodeTaken = errors.New("a project with this code already exists")
+)
+
+// Service manages projects, tasks and weekly timesheets. orgID scopes every call to one organization
+// (nil = platform users, outside any organization).
+type Service interface {
+	// Projects lists the organization's projects with their tasks; activeOnly leaves out inactive projects
+	// and tasks.
+	Projects(orgID *uint, activeOnly bool) ([]Project, error)
+	GetProject(orgID *uint, id uint) (*Project, error)
+	CreateProject(actor audit.Actor, orgID *uint, req ProjectRequest) (*Project, error)
+	UpdateProject(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ProjectRequest) (*Project, error)
+	CreateTask(actor audit.Actor, orgID *uint, projectID uint, req TaskRequest) (*Task, error)
+	UpdateTask(actor audit.Actor, orgID *uint, id uint, req TaskRequest) (*Task, error)
+
+	employee.Resolver
+	// Mine lists an employee's timesheets, latest week first.
+	Mine(orgID *uint, employeeID uint, page utils.Pagination) ([]Timesheet, int64, error)
+	// Week returns an employee's timesheet for the week starting weekStart, or an unsaved draft (ID 0) if
+	// they haven't logged anything yet.
+	Week(orgID *uint, employeeID uint, weekStart time.Time) (*Timesheet, error)
+	// SaveEntries replaces the week's entries while it is a draft or rejected; a rejected week becomes a
+	// draft again. expectedVersion is 0 for a week not saved yet.
+	SaveEntries(actor audit.Actor, orgID *uint, employeeID uint, weekStart time.Time, expectedVersion uint, req EntriesRequest) (*Timesheet, error)
+	// Submit sends the week to the employee's manager; it is read-only from then on.
+	Submit(actor audit.Actor, orgID *uint, employeeID uint, weekStart time.Time) (*Timesheet, error)
+
+	// List lists the timesheets approver may decide, latest week first.
+	List(orgID *uint, approver Approver, filter Filter, page utils.Pagination) ([]Timesheet, int64, error)
+	// Get returns a timesheet approver may decide, with its entries and history.
+	Get(orgID *uint, approver Approver, id uint) (*Timesheet, error)
+	Approve(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, req DecisionRequest) (*Timesheet, error)
+	Reject(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, req DecisionRequest) (*Timesheet, error)
+	// Reopen returns a submitted or approved timesheet to a draft so its employee can correct it.
+	Reopen(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ReopenRequest) (*Timesheet, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Employee records and names are resolved through
+// employees.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Projects(orgID *uint, activeOnly bool) ([]Project, error) {
+	query := utils.OrgScope(s.db, orgID)
+	tasks := func(db *gorm.DB) *gorm.DB { return db.Order("LOWER(name), id") }
+	if activeOnly {
+		query = query.Where("active")
+		tasks = func(db *gorm.DB) *gorm.DB { return db.Where("active").Order("LOWER(name), id") }
+	}
+	projects := []Project{}
+	if err := query.Preload("Tasks", tasks).Order("LOWER(code), id").Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	return projects, nil
+}
+
+func (s *service) GetProject(orgID *uint, id uint) (*Project, error) {
+	var project Project
+	if err := utils.OrgScope(s.db, orgID).Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("LOWER(name), id")
+	}).First(&project, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	return &project, nil
+}
+
+func (s *service) CreateProject(actor audit.Actor, orgID *uint, req ProjectRequest) (*Project, error) {
+	project := Project{OrganizationID: orgID}
+	if err := applyProject(&project, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCode(tx, orgID, 0, project.Code); err != nil {
+			return err
+		}
+		if err := tx.Create(&project).Error; err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "timesheet_project.create", EntityType: "timesheet_project", EntityID: fmt.Sprintf("%d", project.ID), After: project,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.Tasks = []Task{}
+	return &project, nil
+}
+
+// UpdateProject replaces the project's fields if it is still at expectedVersion (optimistic locking).
+// Deactivating it stops new hours being logged against it; hours already logged stay.
+func (s *service) UpdateProject(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ProjectRequest) (*Project, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Project
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		project := before
+		if err := applyProject(&project, req); err != nil {
+			return err
+		}
+		if err := checkCode(tx, orgID, id, project.Code); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Project{}, id, expectedVersion, map[string]interface{}{
+			"code": project.Code, "name": project.Name, "active": project.Active,
+		}); err != nil {
+			return err
+		}
+		project.Version = expectedVersion + 1
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "timesheet_project.update", EntityType: "timesheet_project", EntityID: fmt.Sprintf("%d", id), Before: before, After: project,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProject(orgID, id)
+}
+
+func (s *service) CreateTask(actor audit.Actor, orgID *uint, projectID uint, req TaskRequest) (*Task, error) {
+	task := Task{OrganizationID: orgID, ProjectID: projectID}
+	if err := applyTask(&task, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := utils.OrgScope(tx, orgID).First(&Project{}, projectID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&task).Error; err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "timesheet_task.create", EntityType: "timesheet_task", EntityID: fmt.Sprintf("%d", task.ID), After: task,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (s *service) UpdateTask(actor audit.Actor, orgID *uint, id uint, req TaskRequest) (*Task, error) {
+	var updated Task
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Task
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		task := before
+		if err := applyTask(&task, req); err != nil {
+			return err
+		}
+		if err := tx.Model(&Task{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name": task.Name, "active": task.Active,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update task %d: %w", id, err)
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload task %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "timesheet_task.update", EntityType: "timesheet_task", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+func (s *service) Mine(orgID *uint, employeeID uint, page utils.Pagination) ([]Timesheet, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Timesheet{}), orgID).Where("employee_id = ?", employeeID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count timesheets: %w", err)
+	}
+	timesheets := []Timesheet{}
+	if err := query.Order("week_start DESC").Scopes(page.Scope).Find(&timesheets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list timesheets: %w", err)
+	}
+	return timesheets, total, nil
+}
+
+func (s *service) Week(orgID *uint, employeeID uint, weekStart time.Time) (*Timesheet, error) {
+	if err := checkWeek(weekStart); err != nil {
+		return nil, err
+	}
+	var timesheet Timesheet
+	err := withHistory(utils.OrgScope(s.db, orgID)).Where("employee_id = ? AND week_start = ?", employeeID, weekStart).First(&timesheet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Timesheet{OrganizationID: orgID, EmployeeID: employeeID, WeekStart: weekStart, Status: StatusDraft, Entries: []Entry{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timesheet: %w", err)
+	}
+	return &timesheet, nil
+}
+
+func (s *service) SaveEntries(actor audit.Actor, orgID *uint, employeeID uint, weekStart time.Time, expectedVersion uint, req EntriesRequest) (*Timesheet, error) {
+	if err := checkWeek(weekStart); err != nil {
+		return nil, err
+	}
+	entries, total, err := parseEntries(weekStart, req)
+	if err != nil {
+		return nil, err
+	}
+	var id uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjects(tx, orgID, entries); err != nil {
+			return err
+		}
+		timesheet, created, err := lockWeek(tx, orgID, employeeID, weekStart)
+		if err != nil {
+			return err
+		}
+		// An unsaved week is shown with version 0, so a first save expects 0.
+		if (created && expectedVersion != 0) || (!created && timesheet.Version != expectedVersion) {
+			return utils.ErrVersionConflict
+		}
+		if timesheet.Status == StatusSubmitted || timesheet.Status == StatusApproved {
+			return ErrReadOnly
+		}
+		before := *timesheet
+		if err := tx.Where("timesheet_id = ?", timesheet.ID).Delete(&Entry{}).Error; err != nil {
+			return fmt.Errorf("failed to clear entries: %w", err)
+		}
+		for i := range entries {
+			entries[i].TimesheetID = timesheet.ID
+		}
+		if len(entries) > 0 {
+			if err := tx.CreateInBatches(&entries, 200).Error; err != nil {
+				return fmt.Errorf("failed to save entries: %w", err)
+			}
+		}
+		if err := tx.Model(&Timesheet{}).Where("id = ?", timesheet.ID).Updates(map[string]interface{}{
+			"status": StatusDraft, "total_minutes": total, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update timesheet %d: %w", timesheet.ID, err)
+		}
+		id = timesheet.ID
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "timesheet.save", EntityType: "timesheet", EntityID: fmt.Sprintf("%d", id), Before: before, After: req,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.load(orgID, id)
+}
+
+// Submit refuses weeks that haven't started yet; an empty week can be submitted, e.g. one spent on leave.
+func (s *service) Submit(actor audit.Actor, orgID *uint, employeeID uint, weekStart time.Time) (*Timesheet, error) {
+	if err := checkWeek(weekStart); err != nil {
+		return nil, err
+	}
+	if weekStart.After(clock.Now().UTC()) {
+		return nil, fmt.Errorf("%w: the week hasn't started yet", ErrInvalidTimesheet)
+	}
+	var id uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		timesheet, _, err := lockWeek(tx, orgID, employeeID, weekStart)
+		if err != nil {
+			return err
+		}
+		if timesheet.Status != StatusDraft && timesheet.Status != StatusRejected {
+			return ErrReadOnly
+		}
+		id = timesheet.ID
+		return s.transition(tx, actor, timesheet, StatusSubmitted, EventSubmitted, "", map[string]interface{}{
+			"submitted_at": clock.Now().UTC(), "decided_by": nil, "decided_at": nil, "decision_note": "",
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.load(orgID, id)
+}
+
+func (s *service) List(orgID *uint, approver Approver, filter Filter, page utils.Pagination) ([]Timesheet, int64, error) {
+	query := utils.OrgScope(s.db.Model(&Timesheet{}), orgID)
+	if !approver.HR {
+		reports, err := s.reports(s.db, orgID, approver)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("employee_id IN (?)", reports)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("employee_id IN (?)",
+			s.db.Table("employees").Select("id").Where("division_id = ? AND deleted_at IS NULL", *filter.DivisionID))
+	}
+	if filter.WeekStart != nil {
+		query = query.Where("week_start = ?", *filter.WeekStart)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count timesheets: %w", err)
+	}
+	timesheets := []Timesheet{}
+	if err := query.Order("week_start DESC, employee_id").Scopes(page.Scope).Find(&timesheets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list timesheets: %w", err)
+	}
+	if err := s.named(orgID, timesheets); err != nil {
+		return nil, 0, err
+	}
+	return timesheets, total, nil
+}
+
+func (s *service) Get(orgID *uint, approver Approver, id uint) (*Timesheet, error) {
+	timesheet, err := s.load(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkApprover(s.db, orgID, approver, timesheet.EmployeeID); err != nil {
+		if errors.Is(err, ErrNotApprover) {
+			return nil, gorm.ErrRecordNotFound // Others' timesheets don't exist for a manager
+		}
+		return nil, err
+	}
+	return timesheet, nil
+}
+
+func (s *service) Approve(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, req DecisionRequest) (*Timesheet, error) {
+	return s.decide(actor, orgID, approver, id, expectedVersion, StatusApproved, EventApproved, strings.TrimSpace(req.Note))
+}
+
+func (s *service) Reject(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, req DecisionRequest) (*Timesheet, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, fmt.Errorf("%w: say what to correct", ErrInvalidTimesheet)
+	}
+	return s.decide(actor, orgID, approver, id, expectedVersion, StatusRejected, EventRejected, note)
+}
+
+// decide approves or rejects a submitted timesheet. Nobody decides their own.
+func (s *service) decide(actor audit.Actor, orgID *uint, approver Approver, id, expectedVersion uint, status Status, action EventAction, note string) (*Timesheet, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		timesheet, err := lockTimesheet(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkApprover(tx, orgID, approver, timesheet.EmployeeID); err != nil {
+			return err
+		}
+		if timesheet.Version != expectedVersion {
+			return utils.ErrVersionConflict
+		}
+		if timesheet.Status != StatusSubmitted {
+			return fmt.Errorf("%w: only submitted timesheets are decided, it is %s", ErrTimesheetStatus, timesheet.Status)
+		}
+		return s.transition(tx, actor, timesheet, status, action, note, map[string]interface{}{
+			"decided_by": actor.UserID, "decided_at": clock.Now().UTC(), "decision_note": note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(orgID, approver, id)
+}
+
+// Reopen keeps the entries: the employee corrects them and submits again. The reason and what was logged
+// when it was reopened stay in the history.
+func (s *service) Reopen(actor audit.Actor, orgID *uint, id, expectedVersion uint, req ReopenRequest) (*Timesheet, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidTimesheet)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		timesheet, err := lockTimesheet(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if timesheet.Version != expectedVersion {
+			return utils.ErrVersionConflict
+		}
+		if timesheet.Status != StatusSubmitted && timesheet.Status != StatusApproved {
+			return fmt.Errorf("%w: only submitted or approved timesheets are reopened, it is %s", ErrTimesheetStatus, timesheet.Status)
+		}
+		return s.transition(tx, actor, timesheet, StatusDraft, EventReopened, reason, map[string]interface{}{
+			"submitted_at": nil, "decided_by": nil, "decided_at": nil, "decision_note": "",
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.load(orgID, id)
+}
+
+// transition moves a locked timesheet to status with updates, and records the step as an event and in
+// the audit log.
+func (s *service) transition(tx *gorm.DB, actor audit.Actor, timesheet *Timesheet, status Status, action EventAction, note string, updates map[string]interface{}) error {
+	updates["status"] = status
+	updates["version"] = gorm.Expr("version + 1")
+	if err := tx.Model(&Timesheet{}).Where("id = ?", timesheet.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update timesheet %d: %w", timesheet.ID, err)
+	}
+	event := Event{
+		OrganizationID: timesheet.OrganizationID, TimesheetID: timesheet.ID, Action: action, FromStatus: timesheet.Status,
+		TotalMinutes: timesheet.TotalMinutes, ActorID: actor.UserID, Note: note,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to record timesheet event: %w", err)
+	}
+	return s.auditor.RecordTx(tx, actor, audit.Entry{
+		Action: "timesheet." + string(action), EntityType: "timesheet", EntityID: fmt.Sprintf("%d", timesheet.ID),
+		Before: timesheet, After: event,
+	})
+}
+
+// lockWeek loads an employee's timesheet for a week for update, creating it as an empty draft if needed;
+// created says it did. The advisory lock settles two first saves of a week racing.
+func lockWeek(tx *gorm.DB, orgID *uint, employeeID uint, weekStart time.Time) (timesheet *Timesheet, created bool, err error) {
+	if err := lock.Tx(tx, fmt.Sprintf("timesheet:%d:%s", employeeID, weekStart.Format("2006-01-02"))); err != nil {
+		return nil, false, err
+	}
+	var existing Timesheet
+	err = utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).
+		Where("employee_id = ? AND week_start = ?", employeeID, weekStart).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		existing = Timesheet{OrganizationID: orgID, EmployeeID: employeeID, WeekStart: weekStart, Status: StatusDraft}
+		if err := tx.Create(&existing).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to create timesheet: %w", err)
+		}
+		return &existing, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load timesheet: %w", err)
+	}
+	return &existing, false, nil
+}
+
+// load returns a timesheet with its entries, history and employee's name.
+func (s *service) load(orgID *uint, id uint) (*Timesheet, error) {
+	var timesheet Timesheet
+	if err := withHistory(utils.OrgScope(s.db, orgID)).First(&timesheet, id).Error; err != nil {
+		return nil, err
+	}
+	timesheets := []Timesheet{timesheet}
+	if err := s.named(orgID, timesheets); err != nil {
+		return nil, err
+	}
+	return &timesheets[0], nil
+}
+
+// checkApprover returns ErrNotApprover unless approver may decide the employee's timesheets.
+func (s *service) checkApprover(tx *gorm.DB, orgID *uint, approver Approver, employeeID uint) error {
+	var r struct {
+		UserID     uint
+		ManagerID  *uint
+		DivisionID *uint
+	}
+	if err := utils.OrgScope(tx.Table("employees").Where("id = ?", employeeID), orgID).
+		Select("user_id, manager_id, division_id").Take(&r).Error; err != nil {
+		return fmt.Errorf("failed to load employee %d: %w", employeeID, err)
+	}
+	if r.UserID == approver.UserID {
+		return fmt.Errorf("%w, not your own", ErrNotApprover)
+	}
+	if approver.HR {
+		return nil
+	}
+	self, leads, err := s.leads(tx, orgID, approver)
+	if err != nil {
+		return err
+	}
+	if (r.ManagerID != nil && *r.ManagerID == self) || (r.DivisionID != nil && slices.Contains(leads, *r.DivisionID)) {
+		return nil
+	}
+	return ErrNotApprover
+}
+
+// reports returns a subquery of the IDs of the employees approver decides for: their direct reports and the
+// employees of divisions they lead, not themselves.
+func (s *service) reports(tx *gorm.DB, orgID *uint, approver Approver) (*gorm.DB, error) {
+	self, leads, err := s.leads(tx, orgID, approver)
+	if err != nil {
+		return nil, err
+	}
+	return utils.OrgScope(tx.Table("employees").Select("id"), orgID).
+		Where("(manager_id = ? OR division_id IN ?) AND user_id <> ?", self, append(leads, 0), approver.UserID), nil
+}
+
+// leads returns the approver's employee ID (0 if they have no employee record) and the divisions they lead:
+// through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, orgID *uint, approver Approver) (uint, []uint, error) {
+	var self []uint
+	if err := utils.OrgScope(tx.Table("employees").Where("user_id = ? AND deleted_at IS NULL", approver.UserID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load the approver's employee record: %w", err)
+	}
+	leads := slices.Clone(approver.Leads)
+	if len(self) == 0 {
+		return 0, leads, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Where("head_id = ? AND deleted_at IS NULL", self[0]).Pluck("id", &headed).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return self[0], append(leads, headed...), nil
+}
+
+// named fills in the display names of timesheets' employees.
+func (s *service) named(orgID *uint, timesheets []Timesheet) error {
+	ids := make([]uint, len(timesheets))
+	for i, t := range timesheets {
+		ids[i] = t.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range timesheets {
+		timesheets[i].DisplayName = names[timesheets[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// lockTimesheet loads a timesheet for update, so its status can't change until the transaction ends.
+func lockTimesheet(tx *gorm.DB, orgID *uint, id uint) (*Timesheet, error) {
+	var timesheet Timesheet
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&timesheet, id).Error; err != nil {
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// withHistory preloads a timesheet's entries in date order and its events oldest first.
+func withHistory(db *gorm.DB) *gorm.DB {
+	return db.Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("date, id") }).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") })
+}
+
+// parseEntries validates entries against the week and totals them. A day can't hold more than 24 hours.
+func parseEntries(weekStart time.Time, req EntriesRequest) ([]Entry, int, error) {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	entries := make([]Entry, 0, len(req.Entries))
+	perDay := make(map[time.Time]int)
+	total := 0
+	for _, e := range req.Entries {
+		date, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrInvalidTimesheet)
+		}
+		if date.Before(weekStart) || date.After(weekEnd) {
+			return nil, 0, fmt.Errorf("%w: %s is outside the week", ErrInvalidTimesheet, e.Date)
+		}
+		perDay[date] += e.Minutes
+		if perDay[date] > 24*60 {
+			return nil, 0, fmt.Errorf("%w: more than 24 hours logged on %s", ErrInvalidTimesheet, e.Date)
+		}
+		total += e.Minutes
+		entries = append(entries, Entry{
+			Date: date, ProjectID: e.ProjectID, TaskID: e.TaskID, Minutes: e.Minutes, Note: strings.TrimSpace(e.Note),
+		})
+	}
+	return entries, total, nil
+}
+
+// checkProjects checks entries are logged against the organization's active projects, and active tasks of
+// those projects.
+func checkProjects(tx *gorm.DB, orgID *uint, entries []Entry) error {
+	var projectIDs, taskIDs []uint
+	for _, e := range entries {
+		if !slices.Contains(projectIDs, e.ProjectID) {
+			projectIDs = append(projectIDs, e.ProjectID)
+		}
+		if e.TaskID != nil && !slices.Contains(taskIDs, *e.TaskID) {
+			taskIDs = append(taskIDs, *e.TaskID)
+		}
+	}
+	if len(projectIDs) > 0 {
+		var active int64
+		if err := utils.OrgScope(tx.Model(&Project{}), orgID).Where("id IN ? AND active", projectIDs).Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to check projects: %w", err)
+		}
+		if active != int64(len(projectIDs)) {
+			return fmt.Errorf("%w: unknown or inactive project", ErrInvalidTimesheet)
+		}
+	}
+	if len(taskIDs) > 0 {
+		var tasks []Task
+		if err := utils.OrgScope(tx, orgID).Where("id IN ? AND active", taskIDs).Find(&tasks).Error; err != nil {
+			return fmt.Errorf("failed to check tasks: %w", err)
+		}
+		projectOf := make(map[uint]uint, len(tasks))
+		for _, t := range tasks {
+			projectOf[t.ID] = t.ProjectID
+		}
+		for _, e := range entries {
+			if e.TaskID != nil && projectOf[*e.TaskID] != e.ProjectID {
+				return fmt.Errorf("%w: task %d is unknown, inactive or not part of project %d", ErrInvalidTimesheet, *e.TaskID, e.ProjectID)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCode rejects a project code already used in the organization by another project. The lock
+// serializes concurrent creations.
+func checkCode(tx *gorm.DB, orgID *uint, id uint, code string) error {
+	if err := lock.Tx(tx, "timesheet_project:"+strings.ToLower(code)); err != nil {
+		return err
+	}
+	var count int64
+	if err := utils.OrgScope(tx.Model(&Project{}), orgID).Where("LOWER(code) = LOWER(?) AND id <> ?", code, id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check project codes: %w", err)
+	}
+	if count > 0 {
+		return ErrCodeTaken
+	}
+	return nil
+}
+
+// checkWeek requires weeks to start on a Monday.
+func checkWeek(weekStart time.Time) error {
+	if weekStart.Weekday() != time.Monday {
+		return fmt.Errorf("%w: weeks start on a Monday", ErrInvalidTimesheet)
+	}
+	return nil
+}
+
+func applyProject(project *Project, req ProjectRequest) error {
+	project.Code = strings.TrimSpace(req.Code)
+	project.Name = strings.TrimSpace(req.Name)
+	if project.Code == "" || project.Name == "" {
+		return fmt.Errorf("%w: a code and a name are required", ErrInvalidTimesheet)
+	}
+	project.Active = req.Active == nil || *req.Active
+	return nil
+}
+
+func applyTask(task *Task, req TaskRequest) error {
+	task.Name = strings.TrimSpace(req.Name)
+	if task.Name == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidTimesheet)
+	}
+	task.Active = req.Active == nil || *req.Active
+	return nil
+}
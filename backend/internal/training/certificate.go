@@ -0,0 +1,164 @@
+// prometheus/backend/internal/training/certificate.go
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCertificateSize is the largest certificate upload accepted, in bytes.
+const MaxCertificateSize = 10 << 20
+
+// certificateURLTTL is how long a signed certificate URL stays valid.
+const certificateURLTTL = 5 * time.Minute
+
+// certificateTypes maps the accepted certificate types, as sniffed from the content, to file extensions.
+var certificateTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"application/pdf": ".pdf",
+}
+
+// ErrCertificateTooLarge is returned for uploads above MaxCertificateSize.
+var ErrCertificateTooLarge = fmt.Errorf("certificate must not exceed %d MB", MaxCertificateSize>>20)
+
+// ErrCertificateType is returned for uploads that aren't PDFs or images.
+var ErrCertificateType = errors.New("certificate must be a PDF, or a PNG or JPEG image")
+
+// ErrNoCertificate is returned when an enrollment has no certificate.
+var ErrNoCertificate = errors.New("the enrollment has no certificate")
+
+// UploadCertificate sniffs the type from the content, ignoring the client's declared type, and counts the
+// certificate against the organization's storage quota. Certificates are uploaded to open and completed
+// enrollments.
+func (s *service) UploadCertificate(ctx context.Context, actor audit.Actor, orgID *uint, viewer Viewer, id uint, r io.ReadSeeker, size int64, name string) (*Enrollment, error) {
+	if size > MaxCertificateSize {
+		return nil, ErrCertificateTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrCertificateType
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := certificateTypes[contentType]
+	if !ok {
+		return nil, ErrCertificateType
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	if _, err := s.GetEnrollment(orgID, viewer, id); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("training/%s/%d/%s%s", orgKey(orgID), id, uuid.NewString(), ext)
+	if err := s.files.Put(ctx, key, io.LimitReader(r, MaxCertificateSize), size, contentType); err != nil {
+		return nil, err
+	}
+	var before, enrollment Enrollment
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := lockEnrollment(tx, orgID, viewer, id)
+		if err != nil {
+			return err
+		}
+		before = *locked
+		if before.Status != StatusEnrolled && before.Status != StatusCompleted {
+			return ErrStatus
+		}
+		if err := s.quota.ReleaseStorage(tx, orgValue(orgID), before.CertificateSize); err != nil {
+			return fmt.Errorf("failed to release certificate storage: %w", err)
+		}
+		if err := s.quota.ReserveStorage(tx, orgValue(orgID), size); err != nil {
+			return err
+		}
+		enrollment = before
+		enrollment.CertificateKey, enrollment.CertificateName, enrollment.CertificateSize = key, certificateName(name, ext), size
+		enrollment.Version++
+		if err := tx.Model(&Enrollment{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"certificate_key": enrollment.CertificateKey, "certificate_name": enrollment.CertificateName,
+			"certificate_size": enrollment.CertificateSize, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save certificate: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_enrollment.certificate.upload", EntityType: "training_enrollment", EntityID: fmt.Sprintf("%d", id),
+			After: map[string]interface{}{"content_type": contentType, "size": size},
+		})
+	})
+	if err != nil {
+		s.remove(key)
+		return nil, err
+	}
+	if before.CertificateKey != "" {
+		s.remove(before.CertificateKey)
+	}
+	return &enrollment, nil
+}
+
+// Certificate prefers a signed URL so the storage serves the file; backends without them stream it through the API.
+func (s *service) Certificate(ctx context.Context, orgID *uint, viewer Viewer, id uint) (*Certificate, error) {
+	var enrollment Enrollment
+	if err := visible(utils.OrgScope(s.db.WithContext(ctx), orgID), viewer).First(&enrollment, id).Error; err != nil {
+		return nil, err
+	}
+	if enrollment.CertificateKey == "" {
+		return nil, ErrNoCertificate
+	}
+
+	url, err := s.files.SignedURL(ctx, enrollment.CertificateKey, certificateURLTTL)
+	if err == nil {
+		return &Certificate{URL: url, Name: enrollment.CertificateName}, nil
+	}
+	if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+		return nil, err
+	}
+	body, contentType, err := s.files.Open(ctx, enrollment.CertificateKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNoCertificate
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{Body: body, ContentType: contentType, Name: enrollment.CertificateName}, nil
+}
+
+// remove deletes a certificate that is no longer referenced. Failures only leave an orphaned file behind.
+func (s *service) remove(key string) {
+	if err := s.files.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to delete certificate %s: %v", key, err)
+	}
+}
+
+// certificateName keeps the base of the uploaded file's name for display, falling back to "certificate".
+func certificateName(name, ext string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "certificate" + ext
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+	return name
+}
+
+// orgValue returns the organization as the storage quota names it, 0 for none.
+func orgValue(orgID *uint) uint {
+	if orgID == nil {
+		return 0
+	}
+	return *orgID
+}
@@ -0,0 +1,674 @@
+// prometheus/backend/internal/training/handler.go
+package training
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for courses, sessions, enrollments and the compliance report.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCourses returns the course catalog. Employees see the courses open to enrollment.
+// @Summary List courses
+// @Tags Training
+// @Produce json
+// @Param archived query bool false "Include archived courses (HR only)"
+// @Success 200 {array} Course
+// @Router /hr/training/courses [get]
+// @Router /me/training/courses [get]
+func (h *Handler) ListCourses(c *gin.Context) {
+	includeArchived := isHRRoute(c) && c.Query("archived") == "true"
+	courses, err := h.service.Courses(utils.OrganizationFromContext(c), includeArchived)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Courses fetched successfully", courses)
+}
+
+// GetCourse returns a course.
+// @Summary Get a course
+// @Tags Training
+// @Produce json
+// @Param id path int true "Course ID"
+// @Success 200 {object} Course
+// @Failure 404 {object} utils.ErrorResponse "Course not found"
+// @Router /hr/training/courses/{id} [get]
+func (h *Handler) GetCourse(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	course, err := h.service.GetCourse(utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, course.UpdatedAt, course.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Course fetched successfully", course)
+}
+
+// CreateCourse adds a course to the catalog.
+// @Summary Create a course
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param course body CourseRequest true "Course"
+// @Success 201 {object} Course
+// @Failure 400 {object} utils.ErrorResponse "Invalid course, division or skill"
+// @Router /hr/training/courses [post]
+func (h *Handler) CreateCourse(c *gin.Context) {
+	var req CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	course, err := h.service.CreateCourse(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Course created successfully", course)
+}
+
+// UpdateCourse replaces a course's fields.
+// @Summary Update a course
+// @Description A new validity applies to completions recorded from then on. Archiving closes the course to
+// @Description new enrollments and leaves it out of the compliance report.
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param If-Match header string false "ETag from a previous read"
+// @Param course body CourseRequest true "Course"
+// @Success 200 {object} Course
+// @Failure 404 {object} utils.ErrorResponse "Course not found"
+// @Failure 412 {object} utils.ErrorResponse "Modified concurrently"
+// @Router /hr/training/courses/{id} [put]
+func (h *Handler) UpdateCourse(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetCourse(orgID, id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	course, err := h.service.UpdateCourse(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, course.UpdatedAt, course.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Course updated successfully", course)
+}
+
+// ListSessions returns a course's sessions by start. Employees see the upcoming ones they may enroll in.
+// @Summary List a course's sessions
+// @Tags Training
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param upcoming query bool false "Only sessions not cancelled nor started (always for employees)"
+// @Success 200 {array} Session
+// @Failure 404 {object} utils.ErrorResponse "Course not found"
+// @Router /hr/training/courses/{id}/sessions [get]
+// @Router /me/training/courses/{id}/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	upcoming := !isHRRoute(c) || c.Query("upcoming") == "true"
+	sessions, err := h.service.Sessions(utils.OrganizationFromContext(c), id, upcoming)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Sessions fetched successfully", sessions)
+}
+
+// CreateSession schedules a session of a course.
+// @Summary Schedule a session
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param session body SessionRequest true "Session"
+// @Success 201 {object} Session
+// @Failure 400 {object} utils.ErrorResponse "Invalid session"
+// @Failure 404 {object} utils.ErrorResponse "Course not found"
+// @Router /hr/training/courses/{id}/sessions [post]
+func (h *Handler) CreateSession(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	session, err := h.service.CreateSession(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Session scheduled successfully", session)
+}
+
+// UpdateSession reschedules a session.
+// @Summary Update a session
+// @Description The capacity can't drop below the employees already enrolled.
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param If-Match header string false "ETag from a previous read"
+// @Param session body SessionRequest true "Session"
+// @Success 200 {object} Session
+// @Failure 400 {object} utils.ErrorResponse "Invalid session"
+// @Failure 404 {object} utils.ErrorResponse "Session not found"
+// @Failure 409 {object} utils.ErrorResponse "Session cancelled"
+// @Failure 412 {object} utils.ErrorResponse "Modified concurrently"
+// @Router /hr/training/sessions/{id} [put]
+func (h *Handler) UpdateSession(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req SessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	orgID := utils.OrganizationFromContext(c)
+	current, err := h.service.GetSession(orgID, id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	expectedVersion, ok := utils.CheckPrecondition(c, current.UpdatedAt, current.Version)
+	if !ok {
+		return
+	}
+	session, err := h.service.UpdateSession(audit.ActorFromContext(c), orgID, id, expectedVersion, req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SetVersionHeaders(c, session.UpdatedAt, session.Version)
+	utils.SendSuccessResponse(c, http.StatusOK, "Session updated successfully", session)
+}
+
+// CancelSession cancels a session.
+// @Summary Cancel a session
+// @Description Employees enrolled in the session stay enrolled in the course, without a session, and are notified.
+// @Tags Training
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} Session
+// @Failure 404 {object} utils.ErrorResponse "Session not found"
+// @Failure 409 {object} utils.ErrorResponse "Already cancelled"
+// @Router /hr/training/sessions/{id}/cancel [post]
+func (h *Handler) CancelSession(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	session, err := h.service.CancelSession(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Session cancelled successfully", session)
+}
+
+// ListEnrollments returns enrollments, latest first: the caller's own under /me, everyone's under /hr.
+// @Summary List enrollments
+// @Tags Training
+// @Produce json
+// @Param course_id query int false "Course ID"
+// @Param session_id query int false "Session ID"
+// @Param employee_id query int false "Employee ID (HR only)"
+// @Param status query string false "Status" Enums(enrolled, completed, failed, withdrawn)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/training/enrollments [get]
+// @Router /me/training/enrollments [get]
+func (h *Handler) ListEnrollments(c *gin.Context) {
+	var filter EnrollmentFilter
+	var ok bool
+	if filter.CourseID, ok = utils.OptionalUintQuery(c, "course_id"); !ok {
+		return
+	}
+	if filter.SessionID, ok = utils.OptionalUintQuery(c, "session_id"); !ok {
+		return
+	}
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return
+	}
+	if filter.Status, ok = parseStatus(c); !ok {
+		return
+	}
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	page := utils.ParsePagination(c)
+	enrollments, total, err := h.service.Enrollments(utils.OrganizationFromContext(c), viewer, filter, page)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Enrollments fetched successfully", page.Response(enrollments, total))
+}
+
+// GetEnrollment returns an enrollment.
+// @Summary Get an enrollment
+// @Tags Training
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 200 {object} Enrollment
+// @Failure 404 {object} utils.ErrorResponse "Enrollment not found"
+// @Router /hr/training/enrollments/{id} [get]
+// @Router /me/training/enrollments/{id} [get]
+func (h *Handler) GetEnrollment(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.service.GetEnrollment(utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Enrollment fetched successfully", enrollment)
+}
+
+// Enroll enrolls employees in a course, in one of its sessions if given.
+// @Summary Enroll in a course
+// @Description HR enrolls the listed employees, who are notified. Employees enroll themselves with
+// @Description employee_ids left out, in sessions that haven't started.
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param enrollment body EnrollRequest true "Course, session and employees"
+// @Success 201 {array} Enrollment
+// @Failure 400 {object} utils.ErrorResponse "Invalid enrollment"
+// @Failure 404 {object} utils.ErrorResponse "Course or session not found"
+// @Failure 409 {object} utils.ErrorResponse "Already enrolled, course archived, or session closed or full"
+// @Router /hr/training/enrollments [post]
+// @Router /me/training/enrollments [post]
+func (h *Handler) Enroll(c *gin.Context) {
+	var req EnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	enrollments, err := h.service.Enroll(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer, req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Enrolled successfully", enrollments)
+}
+
+// Withdraw withdraws an open enrollment.
+// @Summary Withdraw an enrollment
+// @Tags Training
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 200 {object} Enrollment
+// @Failure 404 {object} utils.ErrorResponse "Enrollment not found"
+// @Failure 409 {object} utils.ErrorResponse "Enrollment no longer open"
+// @Router /hr/training/enrollments/{id}/withdraw [post]
+// @Router /me/training/enrollments/{id}/withdraw [post]
+func (h *Handler) Withdraw(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.service.Withdraw(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Enrollment withdrawn successfully", enrollment)
+}
+
+// Complete records the outcome of an open enrollment.
+// @Summary Complete an enrollment
+// @Description A completion expires after the course's validity. Courses requiring a certificate need one
+// @Description uploaded before they are completed.
+// @Tags Training
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param completion body CompletionRequest true "Result"
+// @Success 200 {object} Enrollment
+// @Failure 400 {object} utils.ErrorResponse "Invalid completion"
+// @Failure 404 {object} utils.ErrorResponse "Enrollment not found"
+// @Failure 409 {object} utils.ErrorResponse "Enrollment no longer open, or certificate missing"
+// @Router /hr/training/enrollments/{id}/complete [post]
+func (h *Handler) Complete(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	enrollment, err := h.service.Complete(audit.ActorFromContext(c), utils.OrganizationFromContext(c), id, req)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Enrollment completed successfully", enrollment)
+}
+
+// UploadCertificate attaches a certificate to an enrollment, replacing any earlier one.
+// @Summary Upload a certificate
+// @Description PDFs, or PNG or JPEG images, at most 10 MB, as the multipart field "certificate", to open or
+// @Description completed enrollments. Certificates count against the organization's storage quota.
+// @Tags Training
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param certificate formData file true "Certificate"
+// @Success 200 {object} Enrollment
+// @Failure 400 {object} utils.ErrorResponse "Missing file"
+// @Failure 402 {object} utils.ErrorResponse "Storage quota exceeded"
+// @Failure 404 {object} utils.ErrorResponse "Enrollment not found"
+// @Failure 409 {object} utils.ErrorResponse "Enrollment withdrawn or failed"
+// @Failure 413 {object} utils.ErrorResponse "File too large"
+// @Failure 415 {object} utils.ErrorResponse "Unsupported file type"
+// @Router /hr/training/enrollments/{id}/certificate [post]
+// @Router /me/training/enrollments/{id}/certificate [post]
+func (h *Handler) UploadCertificate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	// Leave room for the multipart envelope around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxCertificateSize+64<<10)
+	header, err := c.FormFile("certificate")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendTrainingError(c, ErrCertificateTooLarge)
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Missing multipart file field \"certificate\"")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Could not read the uploaded file")
+		return
+	}
+	defer file.Close()
+
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.service.UploadCertificate(c.Request.Context(), audit.ActorFromContext(c), utils.OrganizationFromContext(c),
+		viewer, id, file, header.Size, header.Filename)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Certificate uploaded successfully", enrollment)
+}
+
+// GetCertificate serves an enrollment's certificate, redirecting to a signed storage URL when the backend
+// supports them.
+// @Summary Get a certificate
+// @Tags Training
+// @Produce application/pdf
+// @Produce image/png
+// @Produce image/jpeg
+// @Param id path int true "Enrollment ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a signed URL"
+// @Failure 404 {object} utils.ErrorResponse "Enrollment or certificate not found"
+// @Router /hr/training/enrollments/{id}/certificate [get]
+// @Router /me/training/enrollments/{id}/certificate [get]
+func (h *Handler) GetCertificate(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	certificate, err := h.service.Certificate(c.Request.Context(), utils.OrganizationFromContext(c), viewer, id)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	if certificate.URL != "" {
+		c.Redirect(http.StatusFound, certificate.URL)
+		return
+	}
+	defer certificate.Body.Close()
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, -1, certificate.ContentType, certificate.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", certificate.Name),
+	})
+}
+
+// Compliance reports how far employees are with their mandatory training.
+// @Summary Mandatory training compliance
+// @Description Per division and mandatory course: the employees it applies to, how many completed it and
+// @Description haven't expired since, and of the rest how many are enrolled, expired or missing it.
+// @Tags Training
+// @Produce json
+// @Param division_id query int false "Division ID"
+// @Param course_id query int false "Course ID"
+// @Success 200 {object} ComplianceReport
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /hr/analytics/training-compliance [get]
+func (h *Handler) Compliance(c *gin.Context) {
+	query, ok := parseComplianceQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.Compliance(utils.OrganizationFromContext(c), query)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Training compliance report generated successfully", report)
+}
+
+// ExportCompliance downloads the compliance report.
+// @Summary Export the training compliance report
+// @Description by=division (default) exports the report's rows; by=employee lists every employee not
+// @Description compliant with a mandatory course, and why.
+// @Tags Training
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param by query string false "division or employee" default(division)
+// @Param division_id query int false "Division ID"
+// @Param course_id query int false "Course ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Invalid format or filter"
+// @Router /hr/analytics/training-compliance/export [get]
+func (h *Handler) ExportCompliance(c *gin.Context) {
+	format, err := utils.ParseExportFormat(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	by := c.DefaultQuery("by", "division")
+	if by != "division" && by != "employee" {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid by parameter")
+		return
+	}
+	query, ok := parseComplianceQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.Compliance(utils.OrganizationFromContext(c), query)
+	if err != nil {
+		sendTrainingError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"training-compliance-%s-%s.%s\"", by, clock.Now().UTC().Format("2006-01-02"), format))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", format.ContentType())
+	c.Status(http.StatusOK)
+	rows := utils.NewRowWriter(c.Writer, format)
+	err = writeCompliance(rows, report, by)
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil {
+		// Headers are already sent; leave the file incomplete rather than pass it off as whole.
+		log.Printf("Training compliance export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// writeCompliance writes the report's rows, or its gaps when by is "employee".
+func writeCompliance(rows utils.RowWriter, report *ComplianceReport, by string) error {
+	if by == "employee" {
+		if err := rows.WriteRow(gapHeader); err != nil {
+			return err
+		}
+		for _, g := range report.Gaps {
+			if err := rows.WriteRow(gapRecord(g)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := rows.WriteRow(complianceHeader); err != nil {
+		return err
+	}
+	for _, r := range report.Rows {
+		if err := rows.WriteRow(complianceRecord(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewer returns who is acting: HR under /hr, otherwise the caller's employee record. It sends an error
+// response and returns false for callers without one.
+func (h *Handler) viewer(c *gin.Context) (Viewer, bool) {
+	if isHRRoute(c) {
+		return Viewer{HR: true}, true
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendTrainingError(c, err)
+		return Viewer{}, false
+	}
+	return Viewer{EmployeeID: emp.ID}, true
+}
+
+// isHRRoute reports whether the request came through an /hr route, which the authorization policy
+// restricts to HR; /me routes act on the caller's own training.
+func isHRRoute(c *gin.Context) bool {
+	return strings.Contains(c.FullPath(), "/hr/training/")
+}
+
+func parseComplianceQuery(c *gin.Context) (ComplianceQuery, bool) {
+	var query ComplianceQuery
+	var ok bool
+	if query.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return ComplianceQuery{}, false
+	}
+	if query.CourseID, ok = utils.OptionalUintQuery(c, "course_id"); !ok {
+		return ComplianceQuery{}, false
+	}
+	return query, true
+}
+
+func parseStatus(c *gin.Context) (Status, bool) {
+	status := Status(c.Query("status"))
+	switch status {
+	case "", StatusEnrolled, StatusCompleted, StatusFailed, StatusWithdrawn:
+		return status, true
+	}
+	utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	return "", false
+}
+
+// sendTrainingError maps service errors to HTTP status codes.
+func sendTrainingError(c *gin.Context, err error) {
+	var limitErr *plan.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		plan.SendError(c, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee), errors.Is(err, ErrNoCertificate):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidCourse), errors.Is(err, ErrInvalidEnrollment):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrArchived), errors.Is(err, ErrSessionClosed), errors.Is(err, ErrSessionFull),
+		errors.Is(err, ErrAlreadyEnrolled), errors.Is(err, ErrStatus), errors.Is(err, ErrCertificateRequired):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrCertificateTooLarge):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrCertificateType):
+		utils.SendErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, utils.ErrVersionConflict):
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "The record was modified concurrently. Reload and try again.")
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,194 @@
+// prometheus/backend/internal/training/model.go
+package training
+
+import (
+	"io"
+	"time"
+)
+
+// Status is where an enrollment stands.
+type Status string
+
+const (
+	StatusEnrolled  Status = "enrolled"  // Signed up, attending or waiting for a session
+	StatusCompleted Status = "completed" // Passed; counts towards compliance until it expires
+	StatusFailed    Status = "failed"    // Attended without passing; enroll again to retake the course
+	StatusWithdrawn Status = "withdrawn" // Left before completing
+)
+
+// Course is a training in the organization's catalog. A mandatory course is required of every employee,
+// or of the employees of one division, and counts in the compliance report.
+type Course struct {
+	ID                  uint      `gorm:"primaryKey" json:"id" example:"6"`
+	OrganizationID      *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	Title               string    `gorm:"type:varchar(200);not null" json:"title" example:"Workplace safety"`
+	Description         string    `gorm:"type:varchar(2000)" json:"description,omitempty"`
+	Provider            string    `gorm:"type:varchar(150)" json:"provider,omitempty" example:"SafeWork Academy"`
+	DurationHours       float64   `gorm:"not null" json:"duration_hours,omitempty" example:"4"`
+	Mandatory           bool      `gorm:"not null;index" json:"mandatory"`
+	DivisionID          *uint     `gorm:"index" json:"division_id,omitempty" example:"2"`         // Mandatory only within this division; omitted for the whole organization
+	ValidityMonths      int       `gorm:"not null" json:"validity_months,omitempty" example:"24"` // Completions expire after this many months; 0 = never
+	CertificateRequired bool      `gorm:"not null" json:"certificate_required"`                   // Completing needs an uploaded certificate
+	Archived            bool      `gorm:"not null" json:"archived"`                               // Closed to new enrollments and left out of the compliance report
+	SkillIDs            []uint    `gorm:"-" json:"skill_ids" example:"4"`                         // Skills the course teaches, for the skills gap report
+	Version             uint      `gorm:"default:1;not null" json:"version" example:"1"`          // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// TableName keeps courses under the training prefix.
+func (Course) TableName() string { return "training_courses" }
+
+// CourseSkill links a course to a skill it teaches.
+type CourseSkill struct {
+	CourseID uint `gorm:"primaryKey"`
+	SkillID  uint `gorm:"primaryKey;index"`
+}
+
+// TableName keeps the links next to courses.
+func (CourseSkill) TableName() string { return "training_course_skills" }
+
+// Session is a scheduled run of a course. Courses taken on one's own, e.g. online, need no session.
+type Session struct {
+	ID             uint       `gorm:"primaryKey" json:"id" example:"14"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CourseID       uint       `gorm:"not null;index" json:"course_id" example:"6"`
+	StartsAt       time.Time  `gorm:"not null;index" json:"starts_at"`
+	EndsAt         time.Time  `gorm:"not null" json:"ends_at"`
+	Location       string     `gorm:"type:varchar(200)" json:"location,omitempty" example:"Training room 2"`
+	Instructor     string     `gorm:"type:varchar(150)" json:"instructor,omitempty" example:"Tomás Rivera"`
+	Capacity       int        `gorm:"not null" json:"capacity,omitempty" example:"12"` // 0 = unlimited
+	Enrolled       int64      `gorm:"-" json:"enrolled" example:"9"`                   // Enrollments still open or completed
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	Version        uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName keeps sessions next to courses.
+func (Session) TableName() string { return "training_sessions" }
+
+// Enrollment is an employee taking a course, in a session or on their own. An employee has at most one
+// open enrollment per course; retaking a course after completing or failing it is a new enrollment.
+type Enrollment struct {
+	ID              uint       `gorm:"primaryKey" json:"id" example:"52"`
+	OrganizationID  *uint      `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	CourseID        uint       `gorm:"not null;index" json:"course_id" example:"6"`
+	SessionID       *uint      `gorm:"index" json:"session_id,omitempty" example:"14"`
+	EmployeeID      uint       `gorm:"not null;index" json:"employee_id" example:"12"`
+	Status          Status     `gorm:"type:varchar(20);not null;index" json:"status" example:"enrolled"`
+	EnrolledBy      *uint      `json:"enrolled_by,omitempty" example:"3"` // User ID; the employee's own when self-enrolled, nil when enrolled by the system
+	CompletedOn     *time.Time `gorm:"type:date" json:"completed_on,omitempty" example:"2026-03-12T00:00:00Z"`
+	ExpiresOn       *time.Time `gorm:"type:date;index" json:"expires_on,omitempty" example:"2028-03-12T00:00:00Z"` // From the course's validity; nil = never
+	Note            string     `gorm:"type:varchar(1000)" json:"note,omitempty"`
+	CertificateKey  string     `gorm:"type:varchar(255)" json:"-"`
+	CertificateName string     `gorm:"type:varchar(255)" json:"certificate_name,omitempty" example:"safety-certificate.pdf"` // As uploaded
+	CertificateSize int64      `gorm:"not null" json:"certificate_size,omitempty" example:"184320"`
+	CourseTitle     string     `gorm:"-" json:"course_title,omitempty" example:"Workplace safety"`
+	Version         uint       `gorm:"default:1;not null" json:"version" example:"1"` // Optimistic locking, see utils.UpdateWithVersion
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName keeps enrollments next to courses.
+func (Enrollment) TableName() string { return "training_enrollments" }
+
+// Certificate is an enrollment's certificate: either a signed URL to redirect to, or the content to serve.
+type Certificate struct {
+	URL         string
+	Body        io.ReadCloser
+	ContentType string
+	Name        string
+}
+
+// Viewer is who is acting on enrollments: HR, through the /hr routes, or an employee on their own.
+type Viewer struct {
+	EmployeeID uint // Of the employee acting on their own enrollments
+	HR         bool // Acts on every enrollment of the organization
+}
+
+// CourseRequest creates a course or replaces its fields.
+type CourseRequest struct {
+	Title               string  `json:"title" binding:"required,max=200" example:"Workplace safety"`
+	Description         string  `json:"description,omitempty" binding:"max=2000"`
+	Provider            string  `json:"provider,omitempty" binding:"max=150" example:"SafeWork Academy"`
+	DurationHours       float64 `json:"duration_hours,omitempty" binding:"min=0,max=1000" example:"4"`
+	Mandatory           bool    `json:"mandatory"`
+	DivisionID          *uint   `json:"division_id,omitempty" example:"2"` // Only with mandatory; omit for the whole organization
+	ValidityMonths      int     `json:"validity_months,omitempty" binding:"min=0,max=120" example:"24"`
+	CertificateRequired bool    `json:"certificate_required"`
+	Archived            bool    `json:"archived"`
+	SkillIDs            []uint  `json:"skill_ids,omitempty" binding:"max=50" example:"4"`
+}
+
+// SessionRequest schedules a session or replaces its fields.
+type SessionRequest struct {
+	StartsAt   time.Time `json:"starts_at" binding:"required" example:"2026-11-04T09:00:00Z"`
+	EndsAt     time.Time `json:"ends_at" binding:"required" example:"2026-11-04T13:00:00Z"`
+	Location   string    `json:"location,omitempty" binding:"max=200" example:"Training room 2"`
+	Instructor string    `json:"instructor,omitempty" binding:"max=150" example:"Tomás Rivera"`
+	Capacity   int       `json:"capacity,omitempty" binding:"min=0,max=10000" example:"12"`
+}
+
+// EnrollRequest enrolls employees in a course, in one of its sessions if given. Employees enroll
+// themselves with the employee IDs left out.
+type EnrollRequest struct {
+	CourseID    uint   `json:"course_id" binding:"required" example:"6"`
+	SessionID   *uint  `json:"session_id,omitempty" example:"14"`
+	EmployeeIDs []uint `json:"employee_ids,omitempty" binding:"max=500" example:"12"`
+}
+
+// CompletionRequest records the outcome of an enrollment.
+type CompletionRequest struct {
+	Result      Status `json:"result" binding:"required,oneof=completed failed" example:"completed"`
+	CompletedOn string `json:"completed_on" binding:"required,datetime=2006-01-02" example:"2026-03-12"`
+	Note        string `json:"note,omitempty" binding:"max=1000"`
+}
+
+// EnrollmentFilter narrows an enrollment listing.
+type EnrollmentFilter struct {
+	CourseID   *uint
+	SessionID  *uint
+	EmployeeID *uint
+	Status     Status
+}
+
+// ComplianceQuery selects what the compliance report covers.
+type ComplianceQuery struct {
+	DivisionID *uint
+	CourseID   *uint
+}
+
+// ComplianceReport tells how far employees are with their mandatory training, per division and course.
+type ComplianceReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Rows        []ComplianceRow `json:"rows"`
+	Gaps        []Gap           `json:"-"` // Per employee, for exports
+}
+
+// ComplianceRow is one mandatory course within one division.
+type ComplianceRow struct {
+	DivisionID *uint   `json:"division_id,omitempty" example:"2"` // Empty for employees outside any division
+	Division   string  `json:"division" example:"Operations"`
+	CourseID   uint    `json:"course_id" example:"6"`
+	Course     string  `json:"course" example:"Workplace safety"`
+	Required   int     `json:"required" example:"20"`          // Employees the course is mandatory for
+	Compliant  int     `json:"compliant" example:"15"`         // Completed and not expired
+	Expiring   int     `json:"expiring" example:"2"`           // Of the compliant, expiring within 30 days
+	Expired    int     `json:"expired" example:"1"`            // Completed once, since expired, and not enrolled again
+	InProgress int     `json:"in_progress" example:"2"`        // Enrolled without a valid completion
+	Missing    int     `json:"missing" example:"2"`            // Never completed nor enrolled
+	Rate       float64 `json:"compliance_rate" example:"0.75"` // Compliant / required
+}
+
+// Gap is one employee not compliant with a mandatory course.
+type Gap struct {
+	EmployeeID  uint
+	DisplayName string
+	DivisionID  *uint
+	Division    string
+	CourseID    uint
+	Course      string
+	Standing    string     // "expired", "in_progress" or "missing"
+	ExpiredOn   *time.Time // For expired completions
+}
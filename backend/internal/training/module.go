@@ -0,0 +1,63 @@
+// prometheus/backend/internal/training/module.go
+package training
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the training module.
+const ModuleName = "training"
+
+// trainingModule owns courses, their sessions and enrollments, and the mandatory training compliance report.
+type trainingModule struct {
+	handler *Handler
+}
+
+// NewModule creates the training module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &trainingModule{handler: NewHandler(svc)}
+}
+
+func (m *trainingModule) Name() string { return ModuleName }
+
+func (m *trainingModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *trainingModule) Models() []any {
+	return []any{&Course{}, &CourseSkill{}, &Session{}, &Enrollment{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Employees browse the catalog and enroll under /me, HR
+// keeps the catalog and records completions; the compliance report is part of the reports module.
+func (m *trainingModule) RegisterRoutes(api *routing.Group) {
+	api.GET("/me/training/courses", routing.Authenticated(), m.handler.ListCourses)
+	api.GET("/me/training/courses/:id/sessions", routing.Authenticated(), m.handler.ListSessions)
+	api.GET("/me/training/enrollments", routing.Authenticated(), m.handler.ListEnrollments)
+	api.POST("/me/training/enrollments", routing.Authenticated(), m.handler.Enroll)
+	api.GET("/me/training/enrollments/:id", routing.Authenticated(), m.handler.GetEnrollment)
+	api.POST("/me/training/enrollments/:id/withdraw", routing.Authenticated(), m.handler.Withdraw)
+	api.POST("/me/training/enrollments/:id/certificate", routing.Authenticated(), m.handler.UploadCertificate)
+	api.GET("/me/training/enrollments/:id/certificate", routing.Authenticated(), m.handler.GetCertificate)
+
+	api.GET("/hr/training/courses", routing.Policy(), m.handler.ListCourses)
+	api.POST("/hr/training/courses", routing.Policy(), m.handler.CreateCourse)
+	api.GET("/hr/training/courses/:id", routing.Policy(), m.handler.GetCourse)
+	api.PUT("/hr/training/courses/:id", routing.Policy(), m.handler.UpdateCourse)
+	api.GET("/hr/training/courses/:id/sessions", routing.Policy(), m.handler.ListSessions)
+	api.POST("/hr/training/courses/:id/sessions", routing.Policy(), m.handler.CreateSession)
+	api.PUT("/hr/training/sessions/:id", routing.Policy(), m.handler.UpdateSession)
+	api.POST("/hr/training/sessions/:id/cancel", routing.Policy(), m.handler.CancelSession)
+	api.GET("/hr/training/enrollments", routing.Policy(), m.handler.ListEnrollments)
+	api.POST("/hr/training/enrollments", routing.Policy(), m.handler.Enroll)
+	api.GET("/hr/training/enrollments/:id", routing.Policy(), m.handler.GetEnrollment)
+	api.POST("/hr/training/enrollments/:id/withdraw", routing.Policy(), m.handler.Withdraw)
+	api.POST("/hr/training/enrollments/:id/complete", routing.Policy(), m.handler.Complete)
+	api.POST("/hr/training/enrollments/:id/certificate", routing.Policy(), m.handler.UploadCertificate)
+	api.GET("/hr/training/enrollments/:id/certificate", routing.Policy(), m.handler.GetCertificate)
+
+	reportsAPI := api.InModule(plan.ModuleReports)
+	reportsAPI.GET("/hr/analytics/training-compliance", routing.Policy(), m.handler.Compliance)
+	reportsAPI.GET("/hr/analytics/training-compliance/export", routing.Policy(), m.handler.ExportCompliance)
+}
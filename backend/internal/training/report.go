@@ -0,0 +1,261 @@
+// prometheus/backend/internal/training/report.go
+package training
+
+import (
+	"cmp"
+	"fmt"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+)
+
+// expiringWindow is how soon a completion expiring counts as expiring in the compliance report.
+const expiringWindow = 30 * 24 * time.Hour
+
+// Standings of an employee with a mandatory course, as exported per employee.
+const (
+	standingCompliant  = "compliant"
+	standingExpired    = "expired"
+	standingInProgress = "in_progress"
+	standingMissing    = "missing"
+)
+
+// reportEmployee is an employee as loaded for the compliance report.
+type reportEmployee struct {
+	ID         uint
+	DivisionID *uint
+}
+
+// record is where an employee stands with a course: their latest expiry of a completion, and whether
+// they are enrolled.
+type record struct {
+	completed bool
+	expiresOn *time.Time // Of the completion valid longest; nil with completed = never expires
+	enrolled  bool
+}
+
+// complianceKey identifies a row of the compliance report.
+type complianceKey struct {
+	division uint // 0 = no division
+	course   uint
+}
+
+// Compliance loads the mandatory courses, the employees they apply to and their enrollments in a handful
+// of queries and compares them in memory.
+func (s *service) Compliance(orgID *uint, query ComplianceQuery) (*ComplianceReport, error) {
+	courseQuery := utils.OrgScope(s.reporting, orgID).Where("mandatory AND NOT archived")
+	if query.CourseID != nil {
+		courseQuery = courseQuery.Where("id = ?", *query.CourseID)
+	}
+	var courses []Course
+	if err := courseQuery.Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("failed to load mandatory courses: %w", err)
+	}
+	employeeQuery := utils.OrgScope(s.reporting.Table("employees").Where("deleted_at IS NULL"), orgID)
+	if query.DivisionID != nil {
+		employeeQuery = employeeQuery.Where("division_id = ?", *query.DivisionID)
+	}
+	var employees []reportEmployee
+	if err := employeeQuery.Select("id, division_id").Scan(&employees).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+	records, err := s.records(orgID, courses, employees)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	rows := make(map[complianceKey]*ComplianceRow)
+	var gaps []Gap
+	for _, course := range courses {
+		for _, e := range employees {
+			if course.DivisionID != nil && (e.DivisionID == nil || *e.DivisionID != *course.DivisionID) {
+				continue
+			}
+			key := complianceKey{course: course.ID}
+			if e.DivisionID != nil {
+				key.division = *e.DivisionID
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &ComplianceRow{DivisionID: e.DivisionID, CourseID: course.ID, Course: course.Title}
+				rows[key] = row
+			}
+			row.Required++
+			r := records[e.ID][course.ID]
+			st := standing(r, now)
+			switch st {
+			case standingCompliant:
+				row.Compliant++
+				if r.expiresOn != nil && r.expiresOn.Sub(now) <= expiringWindow {
+					row.Expiring++
+				}
+				continue
+			case standingInProgress:
+				row.InProgress++
+			case standingExpired:
+				row.Expired++
+			default:
+				row.Missing++
+			}
+			gap := Gap{EmployeeID: e.ID, DivisionID: e.DivisionID, CourseID: course.ID, Course: course.Title, Standing: st}
+			if r.completed {
+				gap.ExpiredOn = r.expiresOn
+			}
+			gaps = append(gaps, gap)
+		}
+	}
+	result := make([]ComplianceRow, 0, len(rows))
+	for _, row := range rows {
+		row.Rate = float64(row.Compliant) / float64(row.Required)
+		result = append(result, *row)
+	}
+	if err := s.labelCompliance(orgID, result, gaps); err != nil {
+		return nil, err
+	}
+	sortCompliance(result)
+	return &ComplianceReport{GeneratedAt: clock.Now().UTC(), Rows: result, Gaps: gaps}, nil
+}
+
+// records loads where the employees stand with the courses, by employee ID and then course ID.
+func (s *service) records(orgID *uint, courses []Course, employees []reportEmployee) (map[uint]map[uint]record, error) {
+	records := make(map[uint]map[uint]record)
+	if len(courses) == 0 || len(employees) == 0 {
+		return records, nil
+	}
+	courseIDs := make([]uint, len(courses))
+	for i, c := range courses {
+		courseIDs[i] = c.ID
+	}
+	employeeIDs := make([]uint, len(employees))
+	for i, e := range employees {
+		employeeIDs[i] = e.ID
+	}
+	var enrollments []Enrollment
+	if err := utils.OrgScope(s.reporting, orgID).Select("employee_id, course_id, status, expires_on").
+		Where("course_id IN ? AND employee_id IN ? AND status IN ?", courseIDs, employeeIDs, []Status{StatusEnrolled, StatusCompleted}).
+		Find(&enrollments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load enrollments: %w", err)
+	}
+	for _, e := range enrollments {
+		if records[e.EmployeeID] == nil {
+			records[e.EmployeeID] = make(map[uint]record)
+		}
+		r := records[e.EmployeeID][e.CourseID]
+		if e.Status == StatusEnrolled {
+			r.enrolled = true
+		} else if !r.completed || (r.expiresOn != nil && (e.ExpiresOn == nil || e.ExpiresOn.After(*r.expiresOn))) {
+			r.completed, r.expiresOn = true, e.ExpiresOn
+		}
+		records[e.EmployeeID][e.CourseID] = r
+	}
+	return records, nil
+}
+
+// standing tells where an employee stands with a mandatory course on day now.
+func standing(r record, now time.Time) string {
+	switch {
+	case r.completed && (r.expiresOn == nil || !r.expiresOn.Before(now)):
+		return standingCompliant
+	case r.enrolled:
+		return standingInProgress
+	case r.completed:
+		return standingExpired
+	default:
+		return standingMissing
+	}
+}
+
+// labelCompliance fills in the names of the divisions and employees of a compliance report.
+func (s *service) labelCompliance(orgID *uint, rows []ComplianceRow, gaps []Gap) error {
+	var divisions []struct {
+		ID   uint
+		Name string
+	}
+	if err := utils.OrgScope(s.reporting.Table("divisions").Select("id, name").Where("deleted_at IS NULL"), orgID).Scan(&divisions).Error; err != nil {
+		return fmt.Errorf("failed to load divisions: %w", err)
+	}
+	names := make(map[uint]string, len(divisions))
+	for _, d := range divisions {
+		names[d.ID] = d.Name
+	}
+	division := func(id *uint) string {
+		if id == nil {
+			return ""
+		}
+		return names[*id]
+	}
+	for i := range rows {
+		rows[i].Division = division(rows[i].DivisionID)
+	}
+	ids := make([]uint, 0, len(gaps))
+	for _, g := range gaps {
+		ids = append(ids, g.EmployeeID)
+	}
+	displayNames, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range gaps {
+		gaps[i].Division = division(gaps[i].DivisionID)
+		gaps[i].DisplayName = displayNames[gaps[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// sortCompliance orders rows by division and course title; employees outside any division come last.
+func sortCompliance(rows []ComplianceRow) {
+	slices.SortFunc(rows, func(a, b ComplianceRow) int {
+		if (a.DivisionID == nil) != (b.DivisionID == nil) {
+			if a.DivisionID == nil {
+				return 1
+			}
+			return -1
+		}
+		if c := strings.Compare(a.Division, b.Division); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Course, b.Course); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.CourseID, b.CourseID)
+	})
+}
+
+// complianceHeader and complianceRecord lay out the per-division export.
+var complianceHeader = []string{
+	"division_id", "division", "course_id", "course", "required", "compliant", "expiring", "expired",
+	"in_progress", "missing", "compliance_rate",
+}
+
+func complianceRecord(r ComplianceRow) []string {
+	return []string{
+		formatID(r.DivisionID), r.Division, fmt.Sprintf("%d", r.CourseID), r.Course, fmt.Sprintf("%d", r.Required),
+		fmt.Sprintf("%d", r.Compliant), fmt.Sprintf("%d", r.Expiring), fmt.Sprintf("%d", r.Expired),
+		fmt.Sprintf("%d", r.InProgress), fmt.Sprintf("%d", r.Missing), fmt.Sprintf("%.2f", r.Rate),
+	}
+}
+
+// gapHeader and gapRecord lay out the per-employee export.
+var gapHeader = []string{"employee_id", "name", "division_id", "division", "course_id", "course", "standing", "expired_on"}
+
+func gapRecord(g Gap) []string {
+	expiredOn := ""
+	if g.ExpiredOn != nil {
+		expiredOn = g.ExpiredOn.Format("2006-01-02")
+	}
+	return []string{
+		fmt.Sprintf("%d", g.EmployeeID), g.DisplayName, formatID(g.DivisionID), g.Division,
+		fmt.Sprintf("%d", g.CourseID), g.Course, g.Standing, expiredOn,
+	}
+}
+
+func formatID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *id)
+}
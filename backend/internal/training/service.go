@@ -0,0 +1,838 @@
+// prometheus/backend/internal/training/service.go
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/skill"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidCourse is returned for courses and sessions that fail validation.
+	ErrInvalidCourse = errors.New("invalid course")
+	// ErrInvalidEnrollment is returned for enrollments and completions that fail validation.
+	ErrInvalidEnrollment = errors.New("invalid enrollment")
+	// ErrArchived is returned when enrolling in an archived course.
+	ErrArchived = errors.New("the course is archived")
+	// ErrSessionClosed is returned when enrolling in, or changing, a cancelled session, and when employees
+	// enroll themselves in a session that has started.
+	ErrSessionClosed = errors.New("the session is closed")
+	// ErrSessionFull is returned when enrolling would exceed a session's capacity.
+	ErrSessionFull = errors.New("the session is full")
+	// ErrAlreadyEnrolled is returned when an employee already has an open enrollment in the course.
+	ErrAlreadyEnrolled = errors.New("already enrolled in the course")
+	// ErrStatus is returned for changes the enrollment's status doesn't allow: only open enrollments are
+	// withdrawn or completed.
+	ErrStatus = errors.New("the enrollment's status does not allow this change")
+	// ErrCertificateRequired is returned when completing a course that needs a certificate without one.
+	ErrCertificateRequired = errors.New("the course requires a certificate before completion")
+)
+
+// Quota accounts for certificates against the organization's storage quota. plan.Service implements it.
+type Quota interface {
+	ReserveStorage(tx *gorm.DB, orgID uint, bytes int64) error
+	ReleaseStorage(tx *gorm.DB, orgID uint, bytes int64) error
+}
+
+// Service manages the course catalog, sessions, enrollments with their certificates, and the compliance
+// report on mandatory courses. It tells the skills gap report who is trained in which skill. orgID scopes
+// every call to one organization (nil = platform users, outside any organization).
+type Service interface {
+	Courses(orgID *uint, includeArchived bool) ([]Course, error)
+	GetCourse(orgID *uint, id uint) (*Course, error)
+	CreateCourse(actor audit.Actor, orgID *uint, req CourseRequest) (*Course, error)
+	// UpdateCourse replaces the course's fields; a new validity applies to completions recorded from then on.
+	UpdateCourse(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CourseRequest) (*Course, error)
+
+	// Sessions lists a course's sessions by start, only those not cancelled nor started if upcoming.
+	Sessions(orgID *uint, courseID uint, upcoming bool) ([]Session, error)
+	GetSession(orgID *uint, id uint) (*Session, error)
+	CreateSession(actor audit.Actor, orgID *uint, courseID uint, req SessionRequest) (*Session, error)
+	UpdateSession(actor audit.Actor, orgID *uint, id, expectedVersion uint, req SessionRequest) (*Session, error)
+	// CancelSession cancels a session. Its open enrollments stay enrolled in the course, without a session.
+	CancelSession(actor audit.Actor, orgID *uint, id uint) (*Session, error)
+
+	// Enrollments lists the enrollments viewer may see, latest first.
+	Enrollments(orgID *uint, viewer Viewer, filter EnrollmentFilter, page utils.Pagination) ([]Enrollment, int64, error)
+	// GetEnrollment returns an enrollment, or gorm.ErrRecordNotFound if viewer may not see it.
+	GetEnrollment(orgID *uint, viewer Viewer, id uint) (*Enrollment, error)
+	// Enroll enrolls the request's employees as HR, or viewer on their own.
+	Enroll(actor audit.Actor, orgID *uint, viewer Viewer, req EnrollRequest) ([]Enrollment, error)
+	Withdraw(actor audit.Actor, orgID *uint, viewer Viewer, id uint) (*Enrollment, error)
+	// Complete records the outcome of an open enrollment.
+	Complete(actor audit.Actor, orgID *uint, id uint, req CompletionRequest) (*Enrollment, error)
+	// UploadCertificate stores the certificate of an enrollment, replacing any earlier one.
+	UploadCertificate(ctx context.Context, actor audit.Actor, orgID *uint, viewer Viewer, id uint, r io.ReadSeeker, size int64, name string) (*Enrollment, error)
+	// Certificate returns an enrollment's certificate to whoever may see the enrollment.
+	Certificate(ctx context.Context, orgID *uint, viewer Viewer, id uint) (*Certificate, error)
+
+	// Compliance tells how far employees are with their mandatory courses, per division and course.
+	Compliance(orgID *uint, query ComplianceQuery) (*ComplianceReport, error)
+	employee.Resolver
+
+	skill.TrainingRecords
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	reporting *gorm.DB
+	employees employee.Service
+	files     storage.Storage
+	quota     Quota
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Certificates are kept in files and counted against the
+// organization's storage quota. The compliance report reads through reporting (see
+// database.ConnectReportingDB).
+func NewService(db, reporting *gorm.DB, employees employee.Service, files storage.Storage, quota Quota, auditor audit.Service) Service {
+	return &service{db: db, reporting: reporting, employees: employees, files: files, quota: quota, auditor: auditor}
+}
+
+func (s *service) Courses(orgID *uint, includeArchived bool) ([]Course, error) {
+	query := utils.OrgScope(s.db, orgID)
+	if !includeArchived {
+		query = query.Where("NOT archived")
+	}
+	courses := []Course{}
+	if err := query.Order("LOWER(title), id").Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+	if err := loadSkills(s.db, courses); err != nil {
+		return nil, err
+	}
+	return courses, nil
+}
+
+func (s *service) GetCourse(orgID *uint, id uint) (*Course, error) {
+	var course Course
+	if err := utils.OrgScope(s.db, orgID).First(&course, id).Error; err != nil {
+		return nil, err // gorm.ErrRecordNotFound is mapped to 404 by the handler
+	}
+	courses := []Course{course}
+	if err := loadSkills(s.db, courses); err != nil {
+		return nil, err
+	}
+	return &courses[0], nil
+}
+
+func (s *service) CreateCourse(actor audit.Actor, orgID *uint, req CourseRequest) (*Course, error) {
+	course := Course{OrganizationID: orgID}
+	if err := applyCourse(&course, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCourse(tx, &course); err != nil {
+			return err
+		}
+		if err := tx.Create(&course).Error; err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+		if err := saveSkills(tx, course.ID, course.SkillIDs); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_course.create", EntityType: "training_course", EntityID: fmt.Sprintf("%d", course.ID), After: course,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func (s *service) UpdateCourse(actor audit.Actor, orgID *uint, id, expectedVersion uint, req CourseRequest) (*Course, error) {
+	var updated Course
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before Course
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		befores := []Course{before}
+		if err := loadSkills(tx, befores); err != nil {
+			return err
+		}
+		before = befores[0]
+		course := before
+		if err := applyCourse(&course, req); err != nil {
+			return err
+		}
+		if err := checkCourse(tx, &course); err != nil {
+			return err
+		}
+		if err := utils.UpdateWithVersion(tx, &Course{}, id, expectedVersion, map[string]interface{}{
+			"title":                course.Title,
+			"description":          course.Description,
+			"provider":             course.Provider,
+			"duration_hours":       course.DurationHours,
+			"mandatory":            course.Mandatory,
+			"division_id":          course.DivisionID,
+			"validity_months":      course.ValidityMonths,
+			"certificate_required": course.CertificateRequired,
+			"archived":             course.Archived,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Where("course_id = ?", id).Delete(&CourseSkill{}).Error; err != nil {
+			return fmt.Errorf("failed to replace the skills of course %d: %w", id, err)
+		}
+		if err := saveSkills(tx, id, course.SkillIDs); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload course %d: %w", id, err)
+		}
+		updated.SkillIDs = course.SkillIDs
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_course.update", EntityType: "training_course", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Sessions(orgID *uint, courseID uint, upcoming bool) ([]Session, error) {
+	if _, err := s.GetCourse(orgID, courseID); err != nil {
+		return nil, err
+	}
+	query := utils.OrgScope(s.db, orgID).Where("course_id = ?", courseID)
+	if upcoming {
+		query = query.Where("cancelled_at IS NULL AND starts_at > ?", clock.Now())
+	}
+	sessions := []Session{}
+	if err := query.Order("starts_at, id").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if err := countEnrolled(s.db, sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *service) GetSession(orgID *uint, id uint) (*Session, error) {
+	var session Session
+	if err := utils.OrgScope(s.db, orgID).First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	sessions := []Session{session}
+	if err := countEnrolled(s.db, sessions); err != nil {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+func (s *service) CreateSession(actor audit.Actor, orgID *uint, courseID uint, req SessionRequest) (*Session, error) {
+	session := Session{OrganizationID: orgID, CourseID: courseID}
+	if err := applySession(&session, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCourse(tx, orgID, courseID); err != nil {
+			return err
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_session.create", EntityType: "training_session", EntityID: fmt.Sprintf("%d", session.ID), After: session,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateSession reschedules a session if it is still at expectedVersion (optimistic locking). Its capacity
+// can't drop below the employees already enrolled.
+func (s *service) UpdateSession(actor audit.Actor, orgID *uint, id, expectedVersion uint, req SessionRequest) (*Session, error) {
+	var updated Session
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockSession(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if before.CancelledAt != nil {
+			return ErrSessionClosed
+		}
+		session := *before
+		if err := applySession(&session, req); err != nil {
+			return err
+		}
+		enrolled, err := enrolledIn(tx, id)
+		if err != nil {
+			return err
+		}
+		if session.Capacity > 0 && enrolled > int64(session.Capacity) {
+			return fmt.Errorf("%w: %d employees are enrolled, more than the capacity", ErrInvalidCourse, enrolled)
+		}
+		if err := utils.UpdateWithVersion(tx, &Session{}, id, expectedVersion, map[string]interface{}{
+			"starts_at":  session.StartsAt,
+			"ends_at":    session.EndsAt,
+			"location":   session.Location,
+			"instructor": session.Instructor,
+			"capacity":   session.Capacity,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, id).Error; err != nil {
+			return fmt.Errorf("failed to reload session %d: %w", id, err)
+		}
+		updated.Enrolled = enrolled
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_session.update", EntityType: "training_session", EntityID: fmt.Sprintf("%d", id), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) CancelSession(actor audit.Actor, orgID *uint, id uint) (*Session, error) {
+	var session *Session
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if session, err = lockSession(tx, orgID, id); err != nil {
+			return err
+		}
+		if session.CancelledAt != nil {
+			return ErrSessionClosed
+		}
+		var course Course
+		if err := tx.First(&course, session.CourseID).Error; err != nil {
+			return fmt.Errorf("failed to load course %d: %w", session.CourseID, err)
+		}
+		now := clock.Now()
+		if err := tx.Model(&Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"cancelled_at": now, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to cancel session %d: %w", id, err)
+		}
+		session.CancelledAt = &now
+		session.Version++
+
+		var affected []Enrollment
+		if err := tx.Where("session_id = ? AND status = ?", id, StatusEnrolled).Find(&affected).Error; err != nil {
+			return fmt.Errorf("failed to load the session's enrollments: %w", err)
+		}
+		if len(affected) > 0 {
+			if err := tx.Model(&Enrollment{}).Where("session_id = ? AND status = ?", id, StatusEnrolled).Updates(map[string]interface{}{
+				"session_id": nil, "version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to move enrollments off session %d: %w", id, err)
+			}
+			employeeIDs := make([]uint, len(affected))
+			for i, e := range affected {
+				employeeIDs[i] = e.EmployeeID
+			}
+			users, err := userIDs(tx, orgID, employeeIDs)
+			if err != nil {
+				return err
+			}
+			notices := make([]notification.Notice, 0, len(users))
+			for _, userID := range users {
+				notices = append(notices, notification.Notice{
+					UserID:         userID,
+					OrganizationID: orgID,
+					Category:       "training",
+					Subject:        fmt.Sprintf("The %q session of %s was cancelled", course.Title, session.StartsAt.UTC().Format("2 Jan 2006")),
+					Body:           "You stay enrolled in the course; HR will enroll you in another session, or pick one yourself.",
+					Link:           "/me/training/enrollments",
+				})
+			}
+			if err := notification.CreateTx(tx, notices...); err != nil {
+				return err
+			}
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_session.cancel", EntityType: "training_session", EntityID: fmt.Sprintf("%d", id),
+			After: map[string]interface{}{"enrollments_moved": len(affected)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *service) Enrollments(orgID *uint, viewer Viewer, filter EnrollmentFilter, page utils.Pagination) ([]Enrollment, int64, error) {
+	query := visible(utils.OrgScope(s.db.Model(&Enrollment{}), orgID), viewer)
+	if filter.CourseID != nil {
+		query = query.Where("course_id = ?", *filter.CourseID)
+	}
+	if filter.SessionID != nil {
+		query = query.Where("session_id = ?", *filter.SessionID)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+	enrollments := []Enrollment{}
+	if err := query.Order("created_at DESC, id DESC").Scopes(page.Scope).Find(&enrollments).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list enrollments: %w", err)
+	}
+	if err := titleCourses(s.db, enrollments); err != nil {
+		return nil, 0, err
+	}
+	return enrollments, total, nil
+}
+
+func (s *service) GetEnrollment(orgID *uint, viewer Viewer, id uint) (*Enrollment, error) {
+	var enrollment Enrollment
+	if err := visible(utils.OrgScope(s.db, orgID), viewer).First(&enrollment, id).Error; err != nil {
+		return nil, err
+	}
+	enrollments := []Enrollment{enrollment}
+	if err := titleCourses(s.db, enrollments); err != nil {
+		return nil, err
+	}
+	return &enrollments[0], nil
+}
+
+func (s *service) Enroll(actor audit.Actor, orgID *uint, viewer Viewer, req EnrollRequest) ([]Enrollment, error) {
+	employeeIDs := []uint{viewer.EmployeeID}
+	if viewer.HR {
+		employeeIDs = slices.Clone(req.EmployeeIDs)
+		slices.Sort(employeeIDs)
+		employeeIDs = slices.Compact(employeeIDs)
+		if len(employeeIDs) == 0 {
+			return nil, fmt.Errorf("%w: employee_ids is required", ErrInvalidEnrollment)
+		}
+	} else if len(req.EmployeeIDs) > 0 {
+		return nil, fmt.Errorf("%w: employees enroll themselves only", ErrInvalidEnrollment)
+	}
+
+	var enrollments []Enrollment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The course lock serializes enrollments in the course, keeping one open enrollment per employee.
+		course, err := lockCourse(tx, orgID, req.CourseID)
+		if err != nil {
+			return err
+		}
+		if course.Archived {
+			return ErrArchived
+		}
+		if req.SessionID != nil {
+			session, err := lockSession(tx, orgID, *req.SessionID)
+			if err != nil {
+				return err
+			}
+			if session.CourseID != course.ID {
+				return fmt.Errorf("%w: the session is not one of the course's", ErrInvalidEnrollment)
+			}
+			if session.CancelledAt != nil || (!viewer.HR && !session.StartsAt.After(clock.Now())) {
+				return ErrSessionClosed
+			}
+			enrolled, err := enrolledIn(tx, session.ID)
+			if err != nil {
+				return err
+			}
+			if session.Capacity > 0 && enrolled+int64(len(employeeIDs)) > int64(session.Capacity) {
+				return ErrSessionFull
+			}
+		}
+		users, err := userIDs(tx, orgID, employeeIDs)
+		if err != nil {
+			return err
+		}
+		if len(users) != len(employeeIDs) {
+			return fmt.Errorf("%w: unknown employee", ErrInvalidEnrollment)
+		}
+		var open int64
+		if err := tx.Model(&Enrollment{}).Where("course_id = ? AND employee_id IN ? AND status = ?", course.ID, employeeIDs, StatusEnrolled).
+			Count(&open).Error; err != nil {
+			return fmt.Errorf("failed to check enrollments: %w", err)
+		}
+		if open > 0 {
+			return ErrAlreadyEnrolled
+		}
+
+		enrollments = make([]Enrollment, len(employeeIDs))
+		var notices []notification.Notice
+		for i, employeeID := range employeeIDs {
+			enrollments[i] = Enrollment{
+				OrganizationID: orgID, CourseID: course.ID, SessionID: req.SessionID, EmployeeID: employeeID,
+				Status: StatusEnrolled, EnrolledBy: actor.UserID, CourseTitle: course.Title,
+			}
+			if viewer.HR {
+				notices = append(notices, notification.Notice{
+					UserID:         users[employeeID],
+					OrganizationID: orgID,
+					Category:       "training",
+					Subject:        fmt.Sprintf("You were enrolled in %q", course.Title),
+					Link:           "/me/training/enrollments",
+				})
+			}
+		}
+		if err := tx.Create(&enrollments).Error; err != nil {
+			return fmt.Errorf("failed to enroll: %w", err)
+		}
+		if err := notification.CreateTx(tx, notices...); err != nil {
+			return err
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_course.enroll", EntityType: "training_course", EntityID: fmt.Sprintf("%d", course.ID),
+			After: map[string]interface{}{"session_id": req.SessionID, "employee_ids": employeeIDs},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (s *service) Withdraw(actor audit.Actor, orgID *uint, viewer Viewer, id uint) (*Enrollment, error) {
+	var enrollment *Enrollment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if enrollment, err = lockEnrollment(tx, orgID, viewer, id); err != nil {
+			return err
+		}
+		if enrollment.Status != StatusEnrolled {
+			return ErrStatus
+		}
+		if err := tx.Model(&Enrollment{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": StatusWithdrawn, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to withdraw enrollment %d: %w", id, err)
+		}
+		enrollment.Status = StatusWithdrawn
+		enrollment.Version++
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_enrollment.withdraw", EntityType: "training_enrollment", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": StatusEnrolled}, After: map[string]interface{}{"status": StatusWithdrawn},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// Complete sets when the completion expires from the course's validity at the time it is recorded.
+func (s *service) Complete(actor audit.Actor, orgID *uint, id uint, req CompletionRequest) (*Enrollment, error) {
+	completedOn, err := time.Parse("2006-01-02", req.CompletedOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: completed_on must be YYYY-MM-DD", ErrInvalidEnrollment)
+	}
+	if completedOn.After(today()) {
+		return nil, fmt.Errorf("%w: completed_on must not be in the future", ErrInvalidEnrollment)
+	}
+	var enrollment *Enrollment
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if enrollment, err = lockEnrollment(tx, orgID, Viewer{HR: true}, id); err != nil {
+			return err
+		}
+		if enrollment.Status != StatusEnrolled {
+			return ErrStatus
+		}
+		var course Course
+		if err := tx.First(&course, enrollment.CourseID).Error; err != nil {
+			return fmt.Errorf("failed to load course %d: %w", enrollment.CourseID, err)
+		}
+		if req.Result == StatusCompleted && course.CertificateRequired && enrollment.CertificateKey == "" {
+			return ErrCertificateRequired
+		}
+		enrollment.Status, enrollment.CompletedOn, enrollment.Note = req.Result, &completedOn, req.Note
+		enrollment.ExpiresOn = nil
+		if req.Result == StatusCompleted && course.ValidityMonths > 0 {
+			expiresOn := completedOn.AddDate(0, course.ValidityMonths, 0)
+			enrollment.ExpiresOn = &expiresOn
+		}
+		if err := tx.Model(&Enrollment{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status": enrollment.Status, "completed_on": enrollment.CompletedOn, "expires_on": enrollment.ExpiresOn,
+			"note": enrollment.Note, "version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to complete enrollment %d: %w", id, err)
+		}
+		enrollment.Version++
+		enrollment.CourseTitle = course.Title
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "training_enrollment.complete", EntityType: "training_enrollment", EntityID: fmt.Sprintf("%d", id),
+			Before: map[string]interface{}{"status": StatusEnrolled},
+			After:  map[string]interface{}{"status": enrollment.Status, "completed_on": req.CompletedOn, "expires_on": enrollment.ExpiresOn},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+// SkillTraining implements skill.TrainingRecords from the enrollments in courses teaching a skill. A
+// completion counts even once expired: the skill was taught, whatever its certificate's validity.
+func (s *service) SkillTraining(db *gorm.DB, orgID *uint, employeeIDs []uint) (map[uint]map[uint]skill.TrainingStatus, error) {
+	var rows []struct {
+		EmployeeID uint
+		SkillID    uint
+		Status     Status
+	}
+	query := db.Table("training_enrollments AS e").
+		Select("e.employee_id, cs.skill_id, e.status").
+		Joins("JOIN training_course_skills AS cs ON cs.course_id = e.course_id").
+		Where("e.employee_id IN ? AND e.status IN ?", employeeIDs, []Status{StatusEnrolled, StatusCompleted})
+	if orgID == nil {
+		query = query.Where("e.organization_id IS NULL")
+	} else {
+		query = query.Where("e.organization_id = ?", *orgID)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load skill training: %w", err)
+	}
+	training := make(map[uint]map[uint]skill.TrainingStatus)
+	for _, r := range rows {
+		if training[r.EmployeeID] == nil {
+			training[r.EmployeeID] = make(map[uint]skill.TrainingStatus)
+		}
+		if r.Status == StatusCompleted {
+			training[r.EmployeeID][r.SkillID] = skill.TrainingCompleted
+		} else if training[r.EmployeeID][r.SkillID] == skill.TrainingNone {
+			training[r.EmployeeID][r.SkillID] = skill.TrainingEnrolled
+		}
+	}
+	return training, nil
+}
+
+// applyCourse copies a request onto a course.
+func applyCourse(course *Course, req CourseRequest) error {
+	if req.DivisionID != nil && !req.Mandatory {
+		return fmt.Errorf("%w: division_id only applies to mandatory courses", ErrInvalidCourse)
+	}
+	skillIDs := slices.Clone(req.SkillIDs)
+	slices.Sort(skillIDs)
+	course.Title, course.Description, course.Provider = req.Title, req.Description, req.Provider
+	course.DurationHours, course.Mandatory, course.DivisionID = req.DurationHours, req.Mandatory, req.DivisionID
+	course.ValidityMonths, course.CertificateRequired, course.Archived = req.ValidityMonths, req.CertificateRequired, req.Archived
+	course.SkillIDs = slices.Compact(skillIDs)
+	if course.SkillIDs == nil {
+		course.SkillIDs = []uint{}
+	}
+	return nil
+}
+
+// checkCourse checks the course's division and skills are the organization's.
+func checkCourse(tx *gorm.DB, course *Course) error {
+	if course.DivisionID != nil {
+		var count int64
+		// Queried by table name: the division package builds on the employee package, not this one.
+		if err := utils.OrgScope(tx.Table("divisions").Where("id = ? AND deleted_at IS NULL", *course.DivisionID), course.OrganizationID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check division: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: unknown division", ErrInvalidCourse)
+		}
+	}
+	if len(course.SkillIDs) > 0 {
+		var count int64
+		if err := utils.OrgScope(tx.Model(&skill.Skill{}).Where("id IN ?", course.SkillIDs), course.OrganizationID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check skills: %w", err)
+		}
+		if count != int64(len(course.SkillIDs)) {
+			return fmt.Errorf("%w: unknown skill", ErrInvalidCourse)
+		}
+	}
+	return nil
+}
+
+// applySession copies a request onto a session.
+func applySession(session *Session, req SessionRequest) error {
+	if !req.EndsAt.After(req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidCourse)
+	}
+	session.StartsAt, session.EndsAt = req.StartsAt.UTC(), req.EndsAt.UTC()
+	session.Location, session.Instructor, session.Capacity = req.Location, req.Instructor, req.Capacity
+	return nil
+}
+
+// saveSkills links a course to the skills it teaches.
+func saveSkills(tx *gorm.DB, courseID uint, skillIDs []uint) error {
+	if len(skillIDs) == 0 {
+		return nil
+	}
+	links := make([]CourseSkill, len(skillIDs))
+	for i, id := range skillIDs {
+		links[i] = CourseSkill{CourseID: courseID, SkillID: id}
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return fmt.Errorf("failed to link the skills of course %d: %w", courseID, err)
+	}
+	return nil
+}
+
+// loadSkills fills in the skills the courses teach.
+func loadSkills(db *gorm.DB, courses []Course) error {
+	ids := make([]uint, len(courses))
+	for i := range courses {
+		ids[i] = courses[i].ID
+		courses[i].SkillIDs = []uint{}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var links []CourseSkill
+	if err := db.Where("course_id IN ?", ids).Order("skill_id").Find(&links).Error; err != nil {
+		return fmt.Errorf("failed to load course skills: %w", err)
+	}
+	byCourse := make(map[uint][]uint)
+	for _, l := range links {
+		byCourse[l.CourseID] = append(byCourse[l.CourseID], l.SkillID)
+	}
+	for i := range courses {
+		if skills, ok := byCourse[courses[i].ID]; ok {
+			courses[i].SkillIDs = skills
+		}
+	}
+	return nil
+}
+
+// countEnrolled fills in how many employees hold a place in each session.
+func countEnrolled(db *gorm.DB, sessions []Session) error {
+	ids := make([]uint, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var counts []struct {
+		SessionID uint
+		Count     int64
+	}
+	if err := db.Model(&Enrollment{}).Select("session_id, COUNT(*) AS count").
+		Where("session_id IN ? AND status IN ?", ids, []Status{StatusEnrolled, StatusCompleted}).
+		Group("session_id").Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count enrollments: %w", err)
+	}
+	bySession := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		bySession[c.SessionID] = c.Count
+	}
+	for i := range sessions {
+		sessions[i].Enrolled = bySession[sessions[i].ID]
+	}
+	return nil
+}
+
+// enrolledIn counts the places taken in a session.
+func enrolledIn(tx *gorm.DB, sessionID uint) (int64, error) {
+	var count int64
+	if err := tx.Model(&Enrollment{}).Where("session_id = ? AND status IN ?", sessionID, []Status{StatusEnrolled, StatusCompleted}).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+	return count, nil
+}
+
+// titleCourses fills in the titles of the enrollments' courses.
+func titleCourses(db *gorm.DB, enrollments []Enrollment) error {
+	ids := make([]uint, 0, len(enrollments))
+	for _, e := range enrollments {
+		ids = append(ids, e.CourseID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var courses []Course
+	if err := db.Select("id, title").Where("id IN ?", ids).Find(&courses).Error; err != nil {
+		return fmt.Errorf("failed to load courses: %w", err)
+	}
+	titles := make(map[uint]string, len(courses))
+	for _, c := range courses {
+		titles[c.ID] = c.Title
+	}
+	for i := range enrollments {
+		enrollments[i].CourseTitle = titles[enrollments[i].CourseID]
+	}
+	return nil
+}
+
+// userIDs returns the users of the organization's current employees, by employee ID. Employees that
+// aren't the organization's are left out.
+func userIDs(tx *gorm.DB, orgID *uint, employeeIDs []uint) (map[uint]uint, error) {
+	var rows []struct {
+		ID     uint
+		UserID uint
+	}
+	if err := utils.OrgScope(tx.Table("employees").Select("id, user_id").Where("id IN ? AND deleted_at IS NULL", employeeIDs), orgID).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employees: %w", err)
+	}
+	users := make(map[uint]uint, len(rows))
+	for _, r := range rows {
+		users[r.ID] = r.UserID
+	}
+	return users, nil
+}
+
+func lockCourse(tx *gorm.DB, orgID *uint, id uint) (*Course, error) {
+	var course Course
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&course, id).Error; err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func lockSession(tx *gorm.DB, orgID *uint, id uint) (*Session, error) {
+	var session Session
+	if err := utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID).First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func lockEnrollment(tx *gorm.DB, orgID *uint, viewer Viewer, id uint) (*Enrollment, error) {
+	var enrollment Enrollment
+	if err := visible(utils.OrgScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID), viewer).First(&enrollment, id).Error; err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// visible restricts an enrollment query to the enrollments viewer may see.
+func visible(query *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.HR {
+		return query
+	}
+	return query.Where("employee_id = ?", viewer.EmployeeID)
+}
+
+func today() time.Time {
+	now := clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
@@ -0,0 +1,39 @@
+// prometheus/backend/internal/utils/dryrun.go
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DryRunContextKey is the gin context key set by middleware.DryRunMiddleware.
+const DryRunContextKey = "dryRun"
+
+// errDryRunRollback forces the surrounding transaction to roll back after a dry run.
+var errDryRunRollback = errors.New("dry run: rolling back transaction")
+
+// IsDryRun reports whether the current request asked for a dry run (?dry_run=true).
+func IsDryRun(c *gin.Context) bool {
+	return c.GetBool(DryRunContextKey)
+}
+
+// WithTransaction runs fn inside a database transaction. When dryRun is true the transaction is
+// always rolled back after fn succeeds, so destructive operations can report what they would do
+// (rows affected, records created) without committing anything.
+func WithTransaction(db *gorm.DB, dryRun bool, fn func(tx *gorm.DB) error) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRunRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRunRollback) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+// prometheus/backend/internal/utils/optimistic.go
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a row was changed by someone else between read and write.
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
+// UpdateWithVersion applies updates to the row identified by id only if its version column still equals
+// expectedVersion, and bumps the version in the same statement (optimistic locking).
+// model must be a pointer to a struct with a `Version uint` field, e.g. &auth.User{}.
+func UpdateWithVersion(db *gorm.DB, model interface{}, id uint, expectedVersion uint, updates map[string]interface{}) error {
+	values := make(map[string]interface{}, len(updates)+1)
+	for k, v := range updates {
+		values[k] = v
+	}
+	values["version"] = gorm.Expr("version + 1")
+
+	result := db.Model(model).Where("id = ? AND version = ?", id, expectedVersion).Updates(values)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update record %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// prometheus/backend/internal/utils/organization.go
+package utils
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OrganizationFromContext returns the caller's organization, set by AuthMiddleware from the token or by
+// APIKeyMiddleware from the key, or nil for platform users outside any organization.
+func OrganizationFromContext(c *gin.Context) *uint {
+	if id, ok := c.Get("orgID"); ok {
+		if orgID, ok := id.(uint); ok {
+			return &orgID
+		}
+	}
+	return nil
+}
+
+// OrgScope restricts a query to one organization's rows (nil = platform users, outside any organization).
+func OrgScope(db *gorm.DB, orgID *uint) *gorm.DB {
+	if orgID == nil {
+		return db.Where("organization_id IS NULL")
+	}
+	return db.Where("organization_id = ?", *orgID)
+}
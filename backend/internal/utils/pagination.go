@@ -0,0 +1,59 @@
+// prometheus/backend/internal/utils/pagination.go
+package utils
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Pagination holds page parameters parsed from ?page=&page_size=.
+type Pagination struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// PaginatedResponse wraps a page of items with paging metadata.
+type PaginatedResponse struct {
+	Items      interface{} `json:"items"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	TotalItems int64       `json:"total_items"`
+	TotalPages int64       `json:"total_pages"`
+}
+
+// ParsePagination reads page (1-based) and page_size from the query string, applying defaults and limits.
+func ParsePagination(c *gin.Context) Pagination {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return Pagination{Page: page, PageSize: pageSize}
+}
+
+// Scope applies LIMIT/OFFSET for the page to a GORM query.
+func (p Pagination) Scope(db *gorm.DB) *gorm.DB {
+	return db.Offset((p.Page - 1) * p.PageSize).Limit(p.PageSize)
+}
+
+// Response builds a PaginatedResponse for items out of total.
+func (p Pagination) Response(items interface{}, total int64) PaginatedResponse {
+	pages := total / int64(p.PageSize)
+	if total%int64(p.PageSize) != 0 {
+		pages++
+	}
+	return PaginatedResponse{Items: items, Page: p.Page, PageSize: p.PageSize, TotalItems: total, TotalPages: pages}
+}
@@ -0,0 +1,36 @@
+// prometheus/backend/internal/utils/params.go
+package utils
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseUintParam reads a positive integer path parameter (e.g. ":id"),
+// sending a 400 response and returning false if it is missing or invalid.
+func ParseUintParam(c *gin.Context, name string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || value == 0 {
+		SendErrorResponse(c, http.StatusBadRequest, "Invalid "+name+" parameter")
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// OptionalUintQuery reads an optional positive integer query parameter (e.g. "?employee_id="), returning nil
+// when it is absent. It sends a 400 response and returns false if the parameter is invalid.
+func OptionalUintQuery(c *gin.Context, name string) (*uint, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || value == 0 {
+		SendErrorResponse(c, http.StatusBadRequest, "Invalid "+name+" parameter")
+		return nil, false
+	}
+	id := uint(value)
+	return &id, true
+}
@@ -0,0 +1,94 @@
+// prometheus/backend/internal/utils/precondition.go
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Precondition holds the conditional-write headers sent by a client.
+// UnmodifiedSince comes from If-Unmodified-Since, Version from If-Match (an ETag produced by SetVersionHeaders).
+type Precondition struct {
+	UnmodifiedSince *time.Time
+	Version         *uint
+}
+
+// ParsePrecondition reads If-Unmodified-Since and If-Match from the request.
+// A request without either header yields an empty Precondition (unconditional write).
+func ParsePrecondition(c *gin.Context) (Precondition, error) {
+	var p Precondition
+
+	if raw := c.GetHeader("If-Unmodified-Since"); raw != "" {
+		t, err := http.ParseTime(raw)
+		if err != nil {
+			return p, fmt.Errorf("invalid If-Unmodified-Since header: %w", err)
+		}
+		p.UnmodifiedSince = &t
+	}
+
+	if raw := c.GetHeader("If-Match"); raw != "" && raw != "*" {
+		version, err := parseVersionETag(raw)
+		if err != nil {
+			return p, err
+		}
+		p.Version = &version
+	}
+
+	return p, nil
+}
+
+// Satisfied reports whether the stored copy (last updated at updatedAt, currently at version) still
+// matches what the client based its edit on.
+func (p Precondition) Satisfied(updatedAt time.Time, version uint) bool {
+	// HTTP dates have second precision, so compare at that granularity.
+	if p.UnmodifiedSince != nil && updatedAt.Truncate(time.Second).After(*p.UnmodifiedSince) {
+		return false
+	}
+	if p.Version != nil && *p.Version != version {
+		return false
+	}
+	return true
+}
+
+// CheckPrecondition parses the conditional headers and verifies them against the stored copy.
+// It sends a 400 (malformed headers) or 412 (server copy changed) response and returns false if the
+// write must not proceed. The expected version is returned so it can be passed to UpdateWithVersion.
+func CheckPrecondition(c *gin.Context, updatedAt time.Time, version uint) (uint, bool) {
+	p, err := ParsePrecondition(c)
+	if err != nil {
+		SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	if !p.Satisfied(updatedAt, version) {
+		SetVersionHeaders(c, updatedAt, version)
+		SendErrorResponse(c, http.StatusPreconditionFailed, "The resource was modified since you last fetched it. Reload and try again.")
+		return 0, false
+	}
+	if p.Version != nil {
+		return *p.Version, true
+	}
+	return version, true
+}
+
+// SetVersionHeaders sets ETag and Last-Modified so clients can send them back as preconditions.
+func SetVersionHeaders(c *gin.Context, updatedAt time.Time, version uint) {
+	c.Header("ETag", fmt.Sprintf("\"%d\"", version))
+	c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+}
+
+// parseVersionETag extracts the version number from an ETag such as "3" or W/"3".
+func parseVersionETag(raw string) (uint, error) {
+	tag := strings.TrimPrefix(strings.TrimSpace(raw), "W/")
+	tag = strings.Trim(tag, "\"")
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid If-Match header: expected a version ETag such as \"3\"")
+	}
+	return uint(version), nil
+}
@@ -0,0 +1,81 @@
+// prometheus/backend/internal/utils/query.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSortFields bounds how many fields a single ?sort= may order by.
+const maxSortFields = 3
+
+// Sort holds an ordering parsed from ?sort=field:dir[,field:dir]. Only whitelisted columns can end up in it,
+// so it is safe to pass to ORDER BY.
+type Sort struct {
+	columns []clause.OrderByColumn
+}
+
+// ParseSort reads ?sort= (e.g. "created_at:desc,username"), mapping public field names to columns with
+// allowed. The direction defaults to ascending. An empty parameter falls back to fallback, which uses the
+// same syntax. Unknown fields and directions are reported as an error for a 400 response.
+func ParseSort(c *gin.Context, allowed map[string]string, fallback string) (Sort, error) {
+	raw := strings.TrimSpace(c.Query("sort"))
+	if raw == "" {
+		raw = fallback
+	}
+	var sort Sort
+	for _, part := range strings.Split(raw, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		column, ok := allowed[field]
+		if !ok {
+			return Sort{}, fmt.Errorf("cannot sort by %q", field)
+		}
+		var desc bool
+		switch strings.ToLower(dir) {
+		case "", "asc":
+		case "desc":
+			desc = true
+		default:
+			return Sort{}, fmt.Errorf("invalid sort direction %q", dir)
+		}
+		sort.columns = append(sort.columns, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+	if len(sort.columns) > maxSortFields {
+		return Sort{}, fmt.Errorf("cannot sort by more than %d fields", maxSortFields)
+	}
+	return sort, nil
+}
+
+// Scope applies the ordering to a GORM query, with the primary key as tie-breaker so pages are stable.
+func (s Sort) Scope(db *gorm.DB) *gorm.DB {
+	return s.order(db, clause.Column{Name: "id"})
+}
+
+// TableScope is Scope for queries joining other tables: the tie-breaker is the primary key of table.
+func (s Sort) TableScope(table string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return s.order(db, clause.Column{Table: table, Name: "id"})
+	}
+}
+
+func (s Sort) order(db *gorm.DB, tieBreaker clause.Column) *gorm.DB {
+	columns := append(append([]clause.OrderByColumn(nil), s.columns...), clause.OrderByColumn{Column: tieBreaker})
+	return db.Order(clause.OrderBy{Columns: columns})
+}
+
+// ContainsPattern builds a LIKE pattern matching values that contain term, with LIKE wildcards in term
+// escaped so user input is matched literally.
+func ContainsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// PrefixPattern builds a LIKE pattern matching values that start with term, escaped like ContainsPattern.
+func PrefixPattern(term string) string {
+	return likeEscaper.Replace(term) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -13,12 +13,31 @@ type SuccessResponse struct {
 
 // ErrorResponse defines the structure for an error API response.
 type ErrorResponse struct {
-	Status  string `json:"status"`  // e.g., "error"
-	Message string `json:"message"` // Detailed error message
+	Status  string `json:"status"`         // e.g., "error"
+	Message string `json:"message"`        // Detailed error message
+	Code    string `json:"code,omitempty"` // Machine-readable error code (e.g. "upgrade_required"), optional
 }
 
+// Envelope modes for success responses.
+const (
+	EnvelopeStandard = "standard" // {status, message, data} wrapper (default)
+	EnvelopeRaw      = "raw"      // bare data payload
+)
+
+// EnvelopeContextKey is the gin context key holding the negotiated envelope mode.
+const EnvelopeContextKey = "responseEnvelope"
+
 // SendSuccessResponse sends a standardized success JSON response.
+// If the client negotiated raw mode (see middleware.EnvelopeMiddleware), only the data payload is sent.
 func SendSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
+	if c.GetString(EnvelopeContextKey) == EnvelopeRaw {
+		if data == nil {
+			c.Status(statusCode)
+			return
+		}
+		c.JSON(statusCode, data)
+		return
+	}
 	c.JSON(statusCode, SuccessResponse{
 		Status:  "success",
 		Message: message,
@@ -33,3 +52,13 @@ func SendErrorResponse(c *gin.Context, statusCode int, message string) {
 		Message: message,
 	})
 }
+
+// SendErrorResponseWithCode sends a standardized error JSON response carrying a machine-readable code,
+// so clients can react to specific failures (e.g. show an upgrade prompt) without parsing the message.
+func SendErrorResponseWithCode(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, ErrorResponse{
+		Status:  "error",
+		Message: message,
+		Code:    code,
+	})
+}
@@ -0,0 +1,119 @@
+// prometheus/backend/internal/worktime/check.go
+package worktime
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// span is a stretch of work: a rostered shift, or a clock-in to its clock-out.
+type span struct {
+	start, end time.Time
+}
+
+// lookback is how many days before a checked range work is loaded, so weeks and runs of consecutive days
+// reaching into the range count in full.
+func lookback(rules Rules) int {
+	return max(7, rules.MaxConsecutiveDays+1)
+}
+
+// evaluate checks an employee's spans against the rules, reporting violations dated from from to to, local
+// midnights in location. spans must reach lookback(rules) days before from.
+func evaluate(rules Rules, source Source, employeeID uint, spans []span, location *time.Location, from, to time.Time) []Violation {
+	slices.SortFunc(spans, func(a, b span) int { return a.start.Compare(b.start) })
+	var violations []Violation
+	report := func(rule Rule, day time.Time, actual, limit int) {
+		violations = append(violations, Violation{
+			EmployeeID: employeeID, Source: source, Rule: rule, Date: day.Format("2006-01-02"), Actual: actual, Limit: limit,
+		})
+	}
+	inRange := func(day time.Time) bool { return !day.Before(from) && !day.After(to) }
+
+	// Minutes worked per local day, splitting spans at midnight.
+	daily := make(map[time.Time]int)
+	for _, s := range spans {
+		for start := s.start; start.Before(s.end); {
+			day := localDay(start, location)
+			end := s.end
+			if next := day.AddDate(0, 0, 1); next.Before(end) {
+				end = next
+			}
+			daily[day] += int(end.Sub(start).Minutes())
+			start = end
+		}
+	}
+
+	if rules.MaxDailyMinutes > 0 {
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if daily[day] > rules.MaxDailyMinutes {
+				report(RuleDailyHours, day, daily[day], rules.MaxDailyMinutes)
+			}
+		}
+	}
+	if rules.MaxWeeklyMinutes > 0 {
+		for week := monday(from); !week.After(to); week = week.AddDate(0, 0, 7) {
+			total := 0
+			for day := week; day.Before(week.AddDate(0, 0, 7)); day = day.AddDate(0, 0, 1) {
+				total += daily[day]
+			}
+			if total > rules.MaxWeeklyMinutes {
+				report(RuleWeeklyHours, week, total, rules.MaxWeeklyMinutes)
+			}
+		}
+	}
+	if rules.MinRestMinutes > 0 && len(spans) > 0 {
+		worked := spans[0].end
+		for _, s := range spans[1:] {
+			gap := int(s.start.Sub(worked).Minutes())
+			if gap > rules.BreakMinutes && gap < rules.MinRestMinutes && inRange(localDay(s.start, location)) {
+				report(RuleRest, localDay(s.start, location), gap, rules.MinRestMinutes)
+			}
+			if s.end.After(worked) {
+				worked = s.end
+			}
+		}
+	}
+	if rules.MaxConsecutiveDays > 0 {
+		run, streak := 0, -1
+		for day := from.AddDate(0, 0, -rules.MaxConsecutiveDays); !day.After(to); day = day.AddDate(0, 0, 1) {
+			if daily[day] == 0 {
+				run, streak = 0, -1
+				continue
+			}
+			run++
+			if run > rules.MaxConsecutiveDays && streak < 0 && inRange(day) {
+				report(RuleConsecutiveDays, day, run, rules.MaxConsecutiveDays)
+				streak = len(violations) - 1
+			}
+			if streak >= 0 {
+				violations[streak].Actual = run
+			}
+		}
+	}
+	return violations
+}
+
+// sortViolations orders violations by date, then employee and rule.
+func sortViolations(violations []Violation) {
+	slices.SortFunc(violations, func(a, b Violation) int {
+		if a.Date != b.Date {
+			return cmp.Compare(a.Date, b.Date)
+		}
+		if a.EmployeeID != b.EmployeeID {
+			return cmp.Compare(a.EmployeeID, b.EmployeeID)
+		}
+		return cmp.Compare(a.Rule, b.Rule)
+	})
+}
+
+// localDay returns local midnight of the day t falls on in location.
+func localDay(t time.Time, location *time.Location) time.Time {
+	y, m, d := t.In(location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, location)
+}
+
+// monday returns the Monday starting day's week.
+func monday(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
@@ -0,0 +1,254 @@
+// prometheus/backend/internal/worktime/handler.go
+package worktime
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/utils"
+	"prometheus/backend/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler handles HTTP requests for rosters and working-time compliance.
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetRules returns the organization's working-time rules.
+// @Summary Get the working-time rules
+// @Tags Working time
+// @Produce json
+// @Success 200 {object} Rules
+// @Router /hr/working-time/rules [get]
+func (h *Handler) GetRules(c *gin.Context) {
+	rules, err := h.service.Rules(utils.OrganizationFromContext(c))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Working-time rules fetched successfully", rules)
+}
+
+// PutRules replaces the organization's working-time rules.
+// @Summary Update the working-time rules
+// @Description Zero turns a limit off. Gaps between shifts up to break_minutes are breaks within a shift;
+// @Description longer ones are rest, and must last min_rest_minutes.
+// @Tags Working time
+// @Accept json
+// @Produce json
+// @Param rules body RulesRequest true "Rules"
+// @Success 200 {object} Rules
+// @Failure 400 {object} utils.ErrorResponse "Invalid rules"
+// @Router /hr/working-time/rules [put]
+func (h *Handler) PutRules(c *gin.Context) {
+	var req RulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	rules, err := h.service.SetRules(audit.ActorFromContext(c), utils.OrganizationFromContext(c), req)
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Working-time rules updated successfully", rules)
+}
+
+// Violations checks rosters and attendance against the working-time rules.
+// @Summary List working-time violations
+// @Description Roster violations show what planned shifts would break before they are worked; attendance
+// @Description ones what was actually worked. Managers see their reports, HR everyone. from and to default
+// @Description to the past week and the next two.
+// @Tags Working time
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param source query string false "Source" Enums(roster, attendance)
+// @Param employee_id query int false "Employee ID"
+// @Param division_id query int false "Division ID"
+// @Success 200 {array} Violation
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/working-time/violations [get]
+// @Router /hr/working-time/violations [get]
+func (h *Handler) Violations(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	filter.Source = Source(c.Query("source"))
+	switch filter.Source {
+	case "", SourceRoster, SourceAttendance:
+	default:
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid source parameter")
+		return
+	}
+	violations, err := h.service.Violations(utils.OrganizationFromContext(c), viewer(c), filter)
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Working-time violations fetched successfully", violations)
+}
+
+// ListShifts returns the rostered shifts of the caller's reports, or everyone's for HR.
+// @Summary List rostered shifts
+// @Tags Working time
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param employee_id query int false "Employee ID"
+// @Param division_id query int false "Division ID"
+// @Success 200 {array} Shift
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Router /manager/shifts [get]
+// @Router /hr/shifts [get]
+func (h *Handler) ListShifts(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	shifts, err := h.service.Shifts(utils.OrganizationFromContext(c), viewer(c), filter)
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Shifts fetched successfully", shifts)
+}
+
+// MyShifts returns the caller's rostered shifts.
+// @Summary List own rostered shifts
+// @Tags Working time
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Success 200 {array} Shift
+// @Failure 400 {object} utils.ErrorResponse "Invalid filter"
+// @Failure 404 {object} utils.ErrorResponse "No employee record"
+// @Router /me/shifts [get]
+func (h *Handler) MyShifts(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	emp, err := h.service.EmployeeOf(c.GetUint("userID"))
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	shifts, err := h.service.MyShifts(utils.OrganizationFromContext(c), emp.ID, filter)
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusOK, "Shifts fetched successfully", shifts)
+}
+
+// CreateShift rosters a shift for one of the caller's reports, or anyone for HR.
+// @Summary Roster a shift
+// @Description The response lists the violations of the employee's roster from the week before the
+// @Description shift's to the week after; they are warnings, the shift is rostered regardless.
+// @Tags Working time
+// @Accept json
+// @Produce json
+// @Param shift body ShiftRequest true "Shift"
+// @Success 201 {object} ShiftResult
+// @Failure 400 {object} utils.ErrorResponse "Invalid shift"
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Overlaps another shift"
+// @Router /manager/shifts [post]
+// @Router /hr/shifts [post]
+func (h *Handler) CreateShift(c *gin.Context) {
+	var req ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	result, err := h.service.CreateShift(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer(c), req)
+	if err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	utils.SendSuccessResponse(c, http.StatusCreated, "Shift rostered successfully", result)
+}
+
+// DeleteShift takes a shift off the roster.
+// @Summary Delete a rostered shift
+// @Tags Working time
+// @Param id path int true "Shift ID"
+// @Success 204
+// @Failure 403 {object} utils.ErrorResponse "Not the caller's report"
+// @Failure 404 {object} utils.ErrorResponse "Shift not found"
+// @Router /manager/shifts/{id} [delete]
+// @Router /hr/shifts/{id} [delete]
+func (h *Handler) DeleteShift(c *gin.Context) {
+	id, ok := utils.ParseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteShift(audit.ActorFromContext(c), utils.OrganizationFromContext(c), viewer(c), id); err != nil {
+		sendWorktimeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func viewer(c *gin.Context) Viewer {
+	userID, hr, leads := middleware.LineManagement(c)
+	return Viewer{UserID: userID, HR: hr, Leads: leads}
+}
+
+// parseFilter reads the range and employee filters shared by rosters and violations. The range defaults to
+// the past week and the next two.
+func parseFilter(c *gin.Context) (Filter, bool) {
+	today := clock.Now().UTC()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	filter := Filter{From: today.AddDate(0, 0, -7), To: today.AddDate(0, 0, 14)}
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			day, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter: expected YYYY-MM-DD")
+				return Filter{}, false
+			}
+			*target = day
+		}
+	}
+	var ok bool
+	if filter.EmployeeID, ok = utils.OptionalUintQuery(c, "employee_id"); !ok {
+		return Filter{}, false
+	}
+	if filter.DivisionID, ok = utils.OptionalUintQuery(c, "division_id"); !ok {
+		return Filter{}, false
+	}
+	return filter, true
+}
+
+// sendWorktimeError maps service errors to HTTP status codes.
+func sendWorktimeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, employee.ErrNoEmployee):
+		utils.SendErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidShift):
+		utils.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotReport):
+		utils.SendErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrShiftOverlap):
+		utils.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+// prometheus/backend/internal/worktime/model.go
+package worktime
+
+import (
+	"time"
+)
+
+// Rules are an organization's working-time limits. A zero limit isn't checked. Organizations without rules
+// use DefaultRules.
+type Rules struct {
+	ID                 uint      `gorm:"primaryKey" json:"-"`
+	OrganizationID     *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	MaxDailyMinutes    int       `gorm:"not null" json:"max_daily_minutes" example:"600"`
+	MaxWeeklyMinutes   int       `gorm:"not null" json:"max_weekly_minutes" example:"2880"` // Monday to Sunday
+	MinRestMinutes     int       `gorm:"not null" json:"min_rest_minutes" example:"660"`    // Between shifts
+	BreakMinutes       int       `gorm:"not null" json:"break_minutes" example:"120"`       // Gaps up to this long are breaks within a shift, not rest
+	MaxConsecutiveDays int       `gorm:"not null" json:"max_consecutive_days" example:"6"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName keeps the rules apart from other kinds of policies.
+func (Rules) TableName() string { return "working_time_rules" }
+
+// DefaultRules follow the EU Working Time Directive: at most 10 hours a day and 48 a week, 11 hours of rest
+// between shifts and a day off after six working days.
+func DefaultRules(orgID *uint) Rules {
+	return Rules{
+		OrganizationID: orgID, MaxDailyMinutes: 10 * 60, MaxWeeklyMinutes: 48 * 60, MinRestMinutes: 11 * 60,
+		BreakMinutes: 2 * 60, MaxConsecutiveDays: 6,
+	}
+}
+
+// Shift is a planned stretch of work on an employee's roster. An employee's shifts don't overlap.
+type Shift struct {
+	ID             uint      `gorm:"primaryKey" json:"id" example:"410"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	EmployeeID     uint      `gorm:"not null;index:idx_shift_employee" json:"employee_id" example:"12"`
+	DisplayName    string    `gorm:"-" json:"display_name,omitempty" example:"Laila Haddad"`
+	StartAt        time.Time `gorm:"not null;index:idx_shift_employee" json:"start_at" example:"2026-10-19T06:00:00Z"`
+	EndAt          time.Time `gorm:"not null" json:"end_at" example:"2026-10-19T14:30:00Z"`
+	Note           string    `gorm:"type:varchar(500)" json:"note,omitempty" example:"Early shift"`
+	CreatedBy      *uint     `json:"created_by,omitempty" example:"7"` // User ID
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName names shifts after the roster they make up.
+func (Shift) TableName() string { return "roster_shifts" }
+
+// Source is what working time is checked from.
+type Source string
+
+const (
+	SourceRoster     Source = "roster"     // Planned shifts, so violations show before they happen
+	SourceAttendance Source = "attendance" // Punches; a shift still clocked in counts until now
+)
+
+// Rule is a working-time limit.
+type Rule string
+
+const (
+	RuleDailyHours      Rule = "daily_hours"
+	RuleWeeklyHours     Rule = "weekly_hours"
+	RuleRest            Rule = "rest"
+	RuleConsecutiveDays Rule = "consecutive_days"
+)
+
+// Violation is an employee's working time breaking a rule. Date is the day for daily hours, the Monday of
+// the week for weekly hours, the day the shift after too short a rest starts, and the first day over the
+// limit for consecutive days. Actual and Limit are minutes, or days for consecutive days.
+type Violation struct {
+	EmployeeID  uint   `json:"employee_id" example:"12"`
+	DisplayName string `json:"display_name,omitempty" example:"Laila Haddad"`
+	Source      Source `json:"source" example:"roster"`
+	Rule        Rule   `json:"rule" example:"rest"`
+	Date        string `json:"date" example:"2026-10-20"`
+	Actual      int    `json:"actual" example:"540"`
+	Limit       int    `json:"limit" example:"660"`
+}
+
+// Viewer is who looks at rosters and violations: HR for anyone, a manager for their direct reports and the
+// divisions they lead.
+type Viewer struct {
+	UserID uint
+	HR     bool   // Holds an HR role globally
+	Leads  []uint // Divisions led through a division-scoped manager role; headed divisions are added by the service
+}
+
+// RulesRequest replaces the organization's working-time rules. Zero turns a limit off.
+type RulesRequest struct {
+	MaxDailyMinutes    int `json:"max_daily_minutes" binding:"min=0,max=1440" example:"600"`
+	MaxWeeklyMinutes   int `json:"max_weekly_minutes" binding:"min=0,max=10080" example:"2880"`
+	MinRestMinutes     int `json:"min_rest_minutes" binding:"min=0,max=2880" example:"660"`
+	BreakMinutes       int `json:"break_minutes" binding:"min=0,max=720" example:"120"`
+	MaxConsecutiveDays int `json:"max_consecutive_days" binding:"min=0,max=31" example:"6"`
+}
+
+// ShiftRequest puts a shift on an employee's roster.
+type ShiftRequest struct {
+	EmployeeID uint      `json:"employee_id" binding:"required" example:"12"`
+	StartAt    time.Time `json:"start_at" binding:"required" example:"2026-10-19T06:00:00Z"`
+	EndAt      time.Time `json:"end_at" binding:"required" example:"2026-10-19T14:30:00Z"`
+	Note       string    `json:"note,omitempty" binding:"max=500" example:"Early shift"`
+}
+
+// ShiftResult is a shift just rostered with the violations the employee's roster has around it.
+type ShiftResult struct {
+	Shift      Shift       `json:"shift"`
+	Violations []Violation `json:"violations"`
+}
+
+// Filter narrows rosters and violations to the days from From to To, both included, in the organization's
+// timezone.
+type Filter struct {
+	From       time.Time
+	To         time.Time
+	Source     Source // Violations only; empty checks both
+	EmployeeID *uint
+	DivisionID *uint
+}
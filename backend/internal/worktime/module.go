@@ -0,0 +1,49 @@
+// prometheus/backend/internal/worktime/module.go
+package worktime
+
+import (
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/routing"
+)
+
+// ModuleName is the name of the working-time module.
+const ModuleName = "working-time"
+
+// worktimeModule owns rosters and the working-time rules they and attendance are checked against.
+type worktimeModule struct {
+	handler *Handler
+}
+
+// NewModule creates the working-time module for the module registry.
+func NewModule(svc Service) module.Module {
+	return &worktimeModule{handler: NewHandler(svc)}
+}
+
+func (m *worktimeModule) Name() string { return ModuleName }
+
+func (m *worktimeModule) HealthContributors() []module.HealthContributor { return nil }
+
+// Models implements module.Migrator.
+func (m *worktimeModule) Models() []any {
+	return []any{&Rules{}, &Shift{}}
+}
+
+// RegisterRoutes implements routing.Contributor. Everything belongs to the attendance module: managers
+// roster their reports and watch their violations, HR does so for everyone and sets the rules.
+func (m *worktimeModule) RegisterRoutes(api *routing.Group) {
+	attendanceAPI := api.InModule(plan.ModuleAttendance)
+	attendanceAPI.GET("/me/shifts", routing.Authenticated(), m.handler.MyShifts)
+
+	attendanceAPI.GET("/manager/shifts", routing.Policy(), m.handler.ListShifts)
+	attendanceAPI.POST("/manager/shifts", routing.Policy(), m.handler.CreateShift)
+	attendanceAPI.DELETE("/manager/shifts/:id", routing.Policy(), m.handler.DeleteShift)
+	attendanceAPI.GET("/manager/working-time/violations", routing.Policy(), m.handler.Violations)
+
+	attendanceAPI.GET("/hr/shifts", routing.Policy(), m.handler.ListShifts)
+	attendanceAPI.POST("/hr/shifts", routing.Policy(), m.handler.CreateShift)
+	attendanceAPI.DELETE("/hr/shifts/:id", routing.Policy(), m.handler.DeleteShift)
+	attendanceAPI.GET("/hr/working-time/violations", routing.Policy(), m.handler.Violations)
+	attendanceAPI.GET("/hr/working-time/rules", routing.Policy(), m.handler.GetRules)
+	attendanceAPI.PUT("/hr/working-time/rules", routing.Policy(), m.handler.PutRules)
+}
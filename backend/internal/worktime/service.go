@@ -0,0 +1,457 @@
+// prometheus/backend/internal/worktime/service.go
+package worktime
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/attendance"
+	"prometheus/backend/internal/audit"
+	"prometheus/backend/internal/clock"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/lock"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxRangeDays caps the days rosters are listed and checked over.
+const maxRangeDays = 93
+
+var (
+	// ErrInvalidShift is returned for shifts, rules and ranges that fail validation.
+	ErrInvalidShift = errors.New("invalid working time")
+	// ErrShiftOverlap is returned for a shift overlapping another of the employee's.
+	ErrShiftOverlap = errors.New("the shift overlaps another shift of the employee")
+	// ErrNotReport is returned when a manager rosters someone who isn't their report.
+	ErrNotReport = errors.New("you may only roster your reports")
+)
+
+// Service manages rosters and the organization's working-time rules, and checks rosters and attendance
+// against them. orgID scopes every call to one organization (nil = platform users, outside any
+// organization).
+type Service interface {
+	Rules(orgID *uint) (*Rules, error)
+	SetRules(actor audit.Actor, orgID *uint, req RulesRequest) (*Rules, error)
+
+	// Shifts lists the rostered shifts of the employees viewer sees, by start.
+	Shifts(orgID *uint, viewer Viewer, filter Filter) ([]Shift, error)
+	// MyShifts lists an employee's own rostered shifts, by start.
+	MyShifts(orgID *uint, employeeID uint, filter Filter) ([]Shift, error)
+	// CreateShift rosters a shift and returns it with the violations of the employee's roster in the weeks
+	// around it; violations don't stop it being rostered.
+	CreateShift(actor audit.Actor, orgID *uint, viewer Viewer, req ShiftRequest) (*ShiftResult, error)
+	DeleteShift(actor audit.Actor, orgID *uint, viewer Viewer, id uint) error
+	employee.Resolver
+
+	// Violations checks the rosters and attendance of the employees viewer sees against the rules.
+	Violations(orgID *uint, viewer Viewer, filter Filter) ([]Violation, error)
+}
+
+// service implements the Service interface.
+type service struct {
+	db        *gorm.DB
+	employees employee.Service
+	auditor   audit.Service
+}
+
+// NewService creates a new instance of Service. Attendance is read from the punches the attendance module
+// records.
+func NewService(db *gorm.DB, employees employee.Service, auditor audit.Service) Service {
+	return &service{db: db, employees: employees, auditor: auditor}
+}
+
+func (s *service) Rules(orgID *uint) (*Rules, error) {
+	return rules(s.db, orgID)
+}
+
+func (s *service) SetRules(actor audit.Actor, orgID *uint, req RulesRequest) (*Rules, error) {
+	if req.MinRestMinutes > 0 && req.BreakMinutes >= req.MinRestMinutes {
+		return nil, fmt.Errorf("%w: breaks must be shorter than the minimum rest", ErrInvalidShift)
+	}
+	var updated Rules
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Organizations have one rules row; the lock keeps two first saves from creating two.
+		if err := lock.Tx(tx, fmt.Sprintf("working-time-rules:%s", orgKey(orgID))); err != nil {
+			return err
+		}
+		before, err := rules(tx, orgID)
+		if err != nil {
+			return err
+		}
+		updated = *before
+		updated.MaxDailyMinutes, updated.MaxWeeklyMinutes = req.MaxDailyMinutes, req.MaxWeeklyMinutes
+		updated.MinRestMinutes, updated.BreakMinutes = req.MinRestMinutes, req.BreakMinutes
+		updated.MaxConsecutiveDays = req.MaxConsecutiveDays
+		if err := tx.Save(&updated).Error; err != nil {
+			return fmt.Errorf("failed to save working-time rules: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "working_time_rules.update", EntityType: "working_time_rules", EntityID: orgKey(orgID), Before: before, After: updated,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *service) Shifts(orgID *uint, viewer Viewer, filter Filter) ([]Shift, error) {
+	ids, err := s.visible(orgID, viewer, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.shifts(orgID, ids, filter)
+}
+
+func (s *service) MyShifts(orgID *uint, employeeID uint, filter Filter) ([]Shift, error) {
+	return s.shifts(orgID, []uint{employeeID}, filter)
+}
+
+func (s *service) CreateShift(actor audit.Actor, orgID *uint, viewer Viewer, req ShiftRequest) (*ShiftResult, error) {
+	shift := Shift{
+		OrganizationID: orgID, EmployeeID: req.EmployeeID, StartAt: req.StartAt.UTC(), EndAt: req.EndAt.UTC(),
+		Note: strings.TrimSpace(req.Note), CreatedBy: actor.UserID,
+	}
+	if !shift.EndAt.After(shift.StartAt) {
+		return nil, fmt.Errorf("%w: the shift ends before it starts", ErrInvalidShift)
+	}
+	if shift.EndAt.Sub(shift.StartAt) > 24*time.Hour {
+		return nil, fmt.Errorf("%w: shifts last at most 24 hours", ErrInvalidShift)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkViewer(tx, orgID, viewer, shift.EmployeeID); err != nil {
+			return err
+		}
+		if err := lock.Tx(tx, fmt.Sprintf("roster:%d", shift.EmployeeID)); err != nil {
+			return err
+		}
+		var count int64
+		if err := utils.OrgScope(tx.Model(&Shift{}), orgID).Where("employee_id = ? AND start_at < ? AND end_at > ?",
+			shift.EmployeeID, shift.EndAt, shift.StartAt).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check shifts: %w", err)
+		}
+		if count > 0 {
+			return ErrShiftOverlap
+		}
+		if err := tx.Create(&shift).Error; err != nil {
+			return fmt.Errorf("failed to create shift: %w", err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "roster_shift.create", EntityType: "roster_shift", EntityID: fmt.Sprintf("%d", shift.ID), After: shift,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	location, err := s.location(orgID)
+	if err != nil {
+		return nil, err
+	}
+	day := localDay(shift.StartAt, location)
+	filter := Filter{From: monday(day).AddDate(0, 0, -7), To: monday(day).AddDate(0, 0, 13), Source: SourceRoster}
+	violations, err := s.check(orgID, []uint{shift.EmployeeID}, filter, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.named(orgID, violations); err != nil {
+		return nil, err
+	}
+	return &ShiftResult{Shift: shift, Violations: violations}, nil
+}
+
+func (s *service) DeleteShift(actor audit.Actor, orgID *uint, viewer Viewer, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var before Shift
+		if err := utils.OrgScope(tx, orgID).First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := s.checkViewer(tx, orgID, viewer, before.EmployeeID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&Shift{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete shift %d: %w", id, err)
+		}
+		return s.auditor.RecordTx(tx, actor, audit.Entry{
+			Action: "roster_shift.delete", EntityType: "roster_shift", EntityID: fmt.Sprintf("%d", id), Before: before,
+		})
+	})
+}
+
+func (s *service) EmployeeOf(userID uint) (*employee.Detail, error) {
+	return s.employees.EmployeeOf(userID)
+}
+
+func (s *service) Violations(orgID *uint, viewer Viewer, filter Filter) ([]Violation, error) {
+	if err := checkRange(filter); err != nil {
+		return nil, err
+	}
+	ids, err := s.visible(orgID, viewer, filter)
+	if err != nil {
+		return nil, err
+	}
+	location, err := s.location(orgID)
+	if err != nil {
+		return nil, err
+	}
+	filter.From = time.Date(filter.From.Year(), filter.From.Month(), filter.From.Day(), 0, 0, 0, 0, location)
+	filter.To = time.Date(filter.To.Year(), filter.To.Month(), filter.To.Day(), 0, 0, 0, 0, location)
+	violations, err := s.check(orgID, ids, filter, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.named(orgID, violations); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// check evaluates the employees' rosters, attendance or both, per filter.Source, from filter.From to
+// filter.To, local midnights in location.
+func (s *service) check(orgID *uint, employeeIDs []uint, filter Filter, location *time.Location) ([]Violation, error) {
+	violations := []Violation{}
+	if len(employeeIDs) == 0 {
+		return violations, nil
+	}
+	limits, err := rules(s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	// Spans are loaded a day beyond the range on either side, as they may cross midnight into it.
+	from := filter.From.AddDate(0, 0, -lookback(*limits)-1)
+	to := filter.To.AddDate(0, 0, 2)
+	sources := map[Source]func() (map[uint][]span, error){
+		SourceRoster:     func() (map[uint][]span, error) { return s.rostered(orgID, employeeIDs, from, to) },
+		SourceAttendance: func() (map[uint][]span, error) { return s.attended(orgID, employeeIDs, from, to) },
+	}
+	for _, source := range []Source{SourceRoster, SourceAttendance} {
+		if filter.Source != "" && filter.Source != source {
+			continue
+		}
+		spans, err := sources[source]()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range employeeIDs {
+			violations = append(violations, evaluate(*limits, source, id, spans[id], location, filter.From, filter.To)...)
+		}
+	}
+	sortViolations(violations)
+	return violations, nil
+}
+
+// rostered returns the employees' shifts overlapping from to to, by employee.
+func (s *service) rostered(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]span, error) {
+	var shifts []Shift
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ? AND start_at < ? AND end_at > ?", employeeIDs, to, from).
+		Find(&shifts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shifts: %w", err)
+	}
+	spans := make(map[uint][]span)
+	for _, sh := range shifts {
+		spans[sh.EmployeeID] = append(spans[sh.EmployeeID], span{start: sh.StartAt, end: sh.EndAt})
+	}
+	return spans, nil
+}
+
+// attended returns the employees' clock-ins to clock-outs overlapping from to to, by employee. A clock-in
+// without a clock-out yet counts until now.
+func (s *service) attended(orgID *uint, employeeIDs []uint, from, to time.Time) (map[uint][]span, error) {
+	var punches []attendance.Punch
+	// A day's margin catches the clock-in of a shift that was under way at from.
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ? AND at >= ? AND at < ?", employeeIDs, from.AddDate(0, 0, -1), to).
+		Order("employee_id, at, id").Find(&punches).Error; err != nil {
+		return nil, fmt.Errorf("failed to load punches: %w", err)
+	}
+	now := clock.Now()
+	spans := make(map[uint][]span)
+	open := make(map[uint]time.Time)
+	for _, p := range punches {
+		switch p.Type {
+		case attendance.PunchIn:
+			open[p.EmployeeID] = p.At
+		case attendance.PunchOut:
+			if in, ok := open[p.EmployeeID]; ok {
+				spans[p.EmployeeID] = append(spans[p.EmployeeID], span{start: in, end: p.At})
+				delete(open, p.EmployeeID)
+			}
+		}
+	}
+	for id, in := range open {
+		if now.After(in) {
+			spans[id] = append(spans[id], span{start: in, end: now})
+		}
+	}
+	return spans, nil
+}
+
+// shifts lists the employees' shifts starting from filter.From to filter.To, by start.
+func (s *service) shifts(orgID *uint, employeeIDs []uint, filter Filter) ([]Shift, error) {
+	if err := checkRange(filter); err != nil {
+		return nil, err
+	}
+	shifts := []Shift{}
+	if len(employeeIDs) == 0 {
+		return shifts, nil
+	}
+	location, err := s.location(orgID)
+	if err != nil {
+		return nil, err
+	}
+	from := time.Date(filter.From.Year(), filter.From.Month(), filter.From.Day(), 0, 0, 0, 0, location)
+	to := time.Date(filter.To.Year(), filter.To.Month(), filter.To.Day()+1, 0, 0, 0, 0, location)
+	if err := utils.OrgScope(s.db, orgID).Where("employee_id IN ? AND start_at >= ? AND start_at < ?", employeeIDs, from, to).
+		Order("start_at, employee_id").Find(&shifts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shifts: %w", err)
+	}
+	ids := make([]uint, len(shifts))
+	for i, sh := range shifts {
+		ids[i] = sh.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shifts {
+		shifts[i].DisplayName = names[shifts[i].EmployeeID].Text
+	}
+	return shifts, nil
+}
+
+// visible returns the IDs of the employees viewer sees, narrowed by filter: everyone for HR, their reports
+// for a manager.
+func (s *service) visible(orgID *uint, viewer Viewer, filter Filter) ([]uint, error) {
+	query := utils.OrgScope(s.db.Table("employees").Where("deleted_at IS NULL"), orgID)
+	if !viewer.HR {
+		self, leads, err := s.leads(s.db, orgID, viewer)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(manager_id = ? OR division_id IN ?) AND user_id <> ?", self, append(leads, 0), viewer.UserID)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("id = ?", *filter.EmployeeID)
+	}
+	if filter.DivisionID != nil {
+		query = query.Where("division_id = ?", *filter.DivisionID)
+	}
+	var ids []uint
+	if err := query.Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+	return ids, nil
+}
+
+// checkViewer returns ErrNotReport unless viewer may roster the employee; gorm.ErrRecordNotFound if the
+// employee isn't the organization's.
+func (s *service) checkViewer(tx *gorm.DB, orgID *uint, viewer Viewer, employeeID uint) error {
+	var r struct {
+		UserID     uint
+		ManagerID  *uint
+		DivisionID *uint
+	}
+	if err := utils.OrgScope(tx.Table("employees").Where("id = ? AND deleted_at IS NULL", employeeID), orgID).
+		Select("user_id, manager_id, division_id").Take(&r).Error; err != nil {
+		return err
+	}
+	if viewer.HR {
+		return nil
+	}
+	if r.UserID == viewer.UserID {
+		return fmt.Errorf("%w, not yourself", ErrNotReport)
+	}
+	self, leads, err := s.leads(tx, orgID, viewer)
+	if err != nil {
+		return err
+	}
+	if (r.ManagerID != nil && *r.ManagerID == self) || (r.DivisionID != nil && slices.Contains(leads, *r.DivisionID)) {
+		return nil
+	}
+	return ErrNotReport
+}
+
+// leads returns the viewer's employee ID (0 if they have no employee record) and the divisions they lead:
+// through division-scoped roles, and as their head.
+func (s *service) leads(tx *gorm.DB, orgID *uint, viewer Viewer) (uint, []uint, error) {
+	var self []uint
+	if err := utils.OrgScope(tx.Table("employees").Where("user_id = ? AND deleted_at IS NULL", viewer.UserID), orgID).
+		Pluck("id", &self).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load the viewer's employee record: %w", err)
+	}
+	leads := slices.Clone(viewer.Leads)
+	if len(self) == 0 {
+		return 0, leads, nil
+	}
+	var headed []uint
+	if err := tx.Table("divisions").Where("head_id = ? AND deleted_at IS NULL", self[0]).Pluck("id", &headed).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load headed divisions: %w", err)
+	}
+	return self[0], append(leads, headed...), nil
+}
+
+// named fills in the display names of violations' employees.
+func (s *service) named(orgID *uint, violations []Violation) error {
+	ids := make([]uint, len(violations))
+	for i, v := range violations {
+		ids[i] = v.EmployeeID
+	}
+	names, err := s.employees.DisplayNames(orgID, employee.UsageDirectory, ids)
+	if err != nil {
+		return err
+	}
+	for i := range violations {
+		violations[i].DisplayName = names[violations[i].EmployeeID].Text
+	}
+	return nil
+}
+
+// location returns the organization's timezone, which days and weeks are counted in. Platform users and
+// organizations without a valid one use UTC.
+func (s *service) location(orgID *uint) (*time.Location, error) {
+	if orgID == nil {
+		return time.UTC, nil
+	}
+	var org organization.Organization
+	if err := s.db.Select("id, timezone").First(&org, *orgID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load organization %d: %w", *orgID, err)
+	}
+	location, err := time.LoadLocation(org.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return location, nil
+}
+
+func rules(db *gorm.DB, orgID *uint) (*Rules, error) {
+	var found []Rules
+	if err := utils.OrgScope(db, orgID).Limit(1).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to load working-time rules: %w", err)
+	}
+	if len(found) == 0 {
+		defaults := DefaultRules(orgID)
+		return &defaults, nil
+	}
+	return &found[0], nil
+}
+
+// checkRange requires from to come before to, at most maxRangeDays apart.
+func checkRange(filter Filter) error {
+	if filter.To.Before(filter.From) {
+		return fmt.Errorf("%w: the range ends before it starts", ErrInvalidShift)
+	}
+	if filter.To.Sub(filter.From) >= maxRangeDays*24*time.Hour {
+		return fmt.Errorf("%w: ranges span at most %d days", ErrInvalidShift, maxRangeDays)
+	}
+	return nil
+}
+
+// orgKey names an organization in lock keys and audit entries.
+func orgKey(orgID *uint) string {
+	if orgID == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *orgID)
+}
@@ -0,0 +1,70 @@
+// prometheus/backend/middleware/access.go
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/access"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OwnerLoader resolves the owner (user ID) of the resource addressed by the request,
+// typically by loading the record referenced in a path parameter.
+type OwnerLoader func(c *gin.Context) (uint, error)
+
+// SubjectFromContext builds an access.Subject from the claims set by AuthMiddleware.
+func SubjectFromContext(c *gin.Context) (access.Subject, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return access.Subject{}, false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		return access.Subject{}, false
+	}
+	return access.Subject{UserID: id, Roles: RolesFromContext(c)}, true
+}
+
+// AccessMiddleware creates a Gin middleware for ownership-based access checks.
+// It loads the resource owner with loadOwner and grants access if any of the rules pass,
+// e.g. access.Owner(), access.ManagerOfOwner(resolver), access.AnyRole("hr").
+// This middleware should be used AFTER AuthMiddleware.
+func AccessMiddleware(loadOwner OwnerLoader, rules ...access.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject, ok := SubjectFromContext(c)
+		if !ok {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User not found in context. Ensure AuthMiddleware runs first.")
+			c.Abort()
+			return
+		}
+
+		ownerID, err := loadOwner(c)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.SendErrorResponse(c, http.StatusNotFound, "The requested resource was not found.")
+			} else {
+				utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to load resource owner: "+err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		allowed, err := access.Check(subject, access.Resource{OwnerID: ownerID}, rules...)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to evaluate access rules: "+err.Error())
+			c.Abort()
+			return
+		}
+		if !allowed {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: You are not allowed to access this resource.")
+			c.Abort()
+			return
+		}
+
+		c.Set("resourceOwnerID", ownerID)
+		c.Next()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	// Make sure 'fmt' is imported for potential future use, though not strictly needed for this fix
 	"net/http"
 	"prometheus/backend/internal/auth" // For auth.Claims
+	"prometheus/backend/internal/clock"
 	"prometheus/backend/internal/utils"
 	"strings"
 
@@ -13,9 +14,14 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ClaimsCheck runs after a token verified and may reject it (by returning an error) or adjust its
+// claims against current server-side state before they are placed in the context.
+type ClaimsCheck func(c *gin.Context, claims *auth.Claims) error
+
 // AuthMiddleware creates a Gin middleware for JWT authentication.
-// It verifies the token and sets user information in the context if valid.
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// It verifies the token with the key named by its "kid" header, applies the optional checks and sets user
+// information in the context if valid.
+func AuthMiddleware(keys auth.SigningKeys, checks ...ClaimsCheck) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -42,8 +48,14 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 				// The parser will then wrap this in a jwt.ValidationError.
 				return nil, jwt.ErrSignatureInvalid
 			}
-			return []byte(jwtSecret), nil
-		})
+			// Keys are looked up by ID; revoked and unknown keys verify nothing.
+			kid, _ := token.Header["kid"].(string)
+			secret, ok := keys.VerificationKey(kid)
+			if !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return secret, nil
+		}, jwt.WithTimeFunc(clock.Now)) // Application time, so tokens survive devtools time travel
 
 		if err != nil {
 			var errMsg string
@@ -76,11 +88,24 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		for _, check := range checks {
+			if err := check(c, claims); err != nil {
+				utils.SendErrorResponse(c, http.StatusUnauthorized, err.Error())
+				c.Abort()
+				return
+			}
+		}
+
 		// Token is valid, set user claims in context for downstream handlers
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		c.Set("roles", claims.Roles)
+		c.Set("scopedRoles", claims.ScopedRoles)
+		c.Set("domain", claims.Domain)
+		if claims.OrganizationID != nil {
+			c.Set("orgID", *claims.OrganizationID)
+		}
 
 		c.Next()
 	}
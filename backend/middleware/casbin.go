@@ -0,0 +1,48 @@
+// prometheus/backend/middleware/casbin.go
+package middleware
+
+import (
+	"net/http"
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/utils"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// CasbinMiddleware creates a Gin middleware that authorizes requests with a Casbin enforcer.
+// The request is evaluated once for the user ("user:<id>") together with all of their roles
+// (set by AuthMiddleware), so an explicit deny for the user or any of their roles overrides
+// every allow. The object is the request path and the action is the HTTP method.
+// Policies live in the database (see internal/authz). Tenant users are checked in their organization's
+// domain (from the JWT); everyone else in defaultDomain.
+// This middleware should be used AFTER AuthMiddleware.
+func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, defaultDomain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		domain := c.GetString("domain")
+		if domain == "" {
+			domain = defaultDomain
+		}
+
+		userRoles := AllRoleNamesFromContext(c)
+		if len(userRoles) == 0 {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User roles not found in context. Ensure AuthMiddleware runs first.")
+			c.Abort()
+			return
+		}
+
+		allowed, err := enforcer.Enforce(authz.UserSubject(c.GetUint("userID")), authz.JoinRoles(userRoles), domain, c.Request.URL.Path, c.Request.Method)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Server Error: Failed to evaluate authorization policy.")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: You do not have the required permission for this resource.")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+// prometheus/backend/middleware/deprecation.go
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationInfo describes a deprecated route.
+// Deprecated is the date the route was deprecated, Sunset (optional) is the date after which
+// it may be removed, and Successor (optional) is the path of the replacement endpoint.
+type DeprecationInfo struct {
+	Deprecated time.Time
+	Sunset     *time.Time
+	Successor  string
+}
+
+// DeprecatedRoute creates a Gin middleware that marks a route (or a whole group) as deprecated.
+// It emits the Deprecation (RFC 9745), Sunset (RFC 8594) and Link rel="successor-version" headers
+// and logs every caller still hitting the route, so we know who to chase before removing v1 endpoints.
+//
+// Usage:
+//
+//	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+//	apiV1.GET("/old", middleware.DeprecatedRoute(middleware.DeprecationInfo{
+//		Deprecated: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+//		Sunset:     &sunset,
+//		Successor:  "/api/v2/new",
+//	}), handler)
+func DeprecatedRoute(info DeprecationInfo) gin.HandlerFunc {
+	// Header values never change for a given route, so compute them once.
+	deprecationHeader := fmt.Sprintf("@%d", info.Deprecated.Unix())
+	var sunsetHeader string
+	if info.Sunset != nil {
+		sunsetHeader = info.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var linkHeader string
+	if info.Successor != "" {
+		linkHeader = fmt.Sprintf("<%s>; rel=\"successor-version\"", info.Successor)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecationHeader)
+		if sunsetHeader != "" {
+			c.Header("Sunset", sunsetHeader)
+		}
+		if linkHeader != "" {
+			c.Header("Link", linkHeader)
+		}
+
+		c.Next()
+
+		// Log after the handler ran so the userID set by AuthMiddleware (if any) is available.
+		userID, _ := c.Get("userID")
+		log.Printf("Deprecated route called: %s %s (user: %v, ip: %s, user-agent: %q, status: %d)",
+			c.Request.Method, c.FullPath(), userID, c.ClientIP(), c.Request.UserAgent(), c.Writer.Status())
+	}
+}
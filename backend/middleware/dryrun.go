@@ -0,0 +1,34 @@
+// prometheus/backend/middleware/dryrun.go
+package middleware
+
+import (
+	"net/http"
+	"prometheus/backend/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DryRunMiddleware parses the standard ?dry_run=true query parameter for bulk and destructive endpoints
+// (purges, bulk deactivation, payroll runs). Handlers read it with utils.IsDryRun and wrap their work in
+// utils.WithTransaction so nothing is committed. Dry-run responses carry an "X-Dry-Run: true" header.
+func DryRunMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun := false
+		if raw := c.Query("dry_run"); raw != "" {
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid dry_run parameter: must be true or false")
+				c.Abort()
+				return
+			}
+			dryRun = parsed
+		}
+
+		c.Set(utils.DryRunContextKey, dryRun)
+		if dryRun {
+			c.Header("X-Dry-Run", "true")
+		}
+		c.Next()
+	}
+}
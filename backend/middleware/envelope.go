@@ -0,0 +1,36 @@
+// prometheus/backend/middleware/envelope.go
+package middleware
+
+import (
+	"prometheus/backend/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnvelopeHeader is the request header clients use to choose the response envelope ("standard" or "raw").
+const EnvelopeHeader = "X-Response-Envelope"
+
+// EnvelopeMiddleware negotiates whether success responses are wrapped in the {status,message,data}
+// envelope or served as raw resources. The client's X-Response-Envelope header wins; otherwise
+// defaultMode (from config) is used. Error responses always keep the envelope.
+func EnvelopeMiddleware(defaultMode string) gin.HandlerFunc {
+	if defaultMode != utils.EnvelopeRaw {
+		defaultMode = utils.EnvelopeStandard
+	}
+
+	return func(c *gin.Context) {
+		mode := defaultMode
+		switch strings.ToLower(strings.TrimSpace(c.GetHeader(EnvelopeHeader))) {
+		case utils.EnvelopeRaw:
+			mode = utils.EnvelopeRaw
+		case utils.EnvelopeStandard:
+			mode = utils.EnvelopeStandard
+		}
+
+		c.Set(utils.EnvelopeContextKey, mode)
+		c.Header(EnvelopeHeader, mode)
+		c.Header("Vary", EnvelopeHeader)
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+// prometheus/backend/middleware/metrics.go
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetrics holds the request metrics recorded by MetricsMiddleware.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics creates and registers the HTTP request metrics.
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hris_http_requests_total",
+			Help: "Total HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "hris_http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// LatencyObserver receives the latency of every request, such as the SLO tracker (see internal/slo).
+// Observe is called on the request path and must not block.
+type LatencyObserver interface {
+	Observe(method, route string, elapsed time.Duration)
+}
+
+// MetricsMiddleware records request counts and latencies, and hands latencies to observers. Routes are
+// labelled with their pattern (c.FullPath(), e.g. /api/v1/operations/:id) to keep label cardinality bounded.
+func MetricsMiddleware(m *HTTPMetrics, observers ...LatencyObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route).Observe(elapsed.Seconds())
+		for _, o := range observers {
+			o.Observe(c.Request.Method, route, elapsed)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// prometheus/backend/middleware/plan.go
+package middleware
+
+import (
+	"prometheus/backend/internal/plan"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireModule creates a Gin middleware that rejects requests to a module the caller's organization may not
+// use with 402 and code "upgrade_required" (or "payment_required" when checked through billing).
+// Users without an organization are not restricted.
+// This middleware should be used AFTER AuthMiddleware.
+func RequireModule(modules plan.ModuleChecker, module string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := modules.CheckModule(c.GetUint("orgID"), module); err != nil {
+			plan.SendError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -10,43 +10,55 @@ import (
 )
 
 // RBACMiddleware creates a Gin middleware for Role-Based Access Control.
-// It checks if the authenticated user's role is one of the allowedRoles.
+// It checks if the authenticated user holds at least one of the allowedRoles, either globally or
+// scoped to a division (narrow data access further with ScopeMiddleware or DivisionScope).
 // This middleware should be used AFTER AuthMiddleware.
 func RBACMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Attempt to get user role from context (set by AuthMiddleware)
-		userRoleInterface, exists := c.Get("role")
-		if !exists {
+		// Attempt to get user roles from context (set by AuthMiddleware)
+		if _, exists := c.Get("roles"); !exists {
 			// This should ideally not happen if AuthMiddleware is applied first
 			// and successfully authenticates the user.
-			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User role not found in context. Ensure AuthMiddleware runs first.")
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User roles not found in context. Ensure AuthMiddleware runs first.")
 			c.Abort()
 			return
 		}
 
-		userRole, ok := userRoleInterface.(string)
-		if !ok {
-			// Role in context is not a string, which is unexpected.
-			utils.SendErrorResponse(c, http.StatusInternalServerError, "Server Error: User role in context is not of expected type.")
+		userRoles := AllRoleNamesFromContext(c)
+		if len(userRoles) == 0 {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User has no roles.")
 			c.Abort()
 			return
 		}
 
-		if len(userRole) == 0 {
-			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: User role is empty.")
-			c.Abort()
-			return
-		}
-
-		// Check if the user's role is in the list of allowed roles
-		// Using slices.Contains for Go 1.21+
-		if !slices.Contains(allowedRoles, userRole) {
+		// Check if any of the user's roles is in the list of allowed roles
+		if !slices.ContainsFunc(userRoles, func(r string) bool { return slices.Contains(allowedRoles, r) }) {
 			utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: You do not have the required role for this resource.")
 			c.Abort()
 			return
 		}
 
-		// User has the required role, proceed to the next handler
+		// User has a required role, proceed to the next handler
 		c.Next()
 	}
 }
+
+// RolesFromContext returns the global (unscoped) role names set by AuthMiddleware.
+func RolesFromContext(c *gin.Context) []string {
+	roles, _ := c.Get("roles")
+	names, _ := roles.([]string)
+	return names
+}
+
+// AllRoleNamesFromContext returns the global role names plus the names of any division-scoped roles.
+// Used by coarse route gates; a "manager of Engineering" may reach manager routes, but only sees
+// Engineering data there.
+func AllRoleNamesFromContext(c *gin.Context) []string {
+	names := slices.Clone(RolesFromContext(c))
+	for _, sr := range ScopedRolesFromContext(c) {
+		if !slices.Contains(names, sr.Role) {
+			names = append(names, sr.Role)
+		}
+	}
+	return names
+}
@@ -0,0 +1,41 @@
+// prometheus/backend/middleware/role_expiry.go
+package middleware
+
+import (
+	"fmt"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/clock"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DropExpiredRoles is a ClaimsCheck that removes time-limited roles from the claims once they lapse,
+// even though the token is still valid. Tokens carrying time-limited roles are also re-checked against
+// user_roles, so a grant revoked or shortened early takes effect immediately. Tokens without
+// time-limited roles cost no database query.
+func DropExpiredRoles(db *gorm.DB) ClaimsCheck {
+	return func(c *gin.Context, claims *auth.Claims) error {
+		now := clock.Now()
+		claims.ScopedRoles = slices.DeleteFunc(claims.ScopedRoles, func(sr auth.ScopedRoleClaim) bool {
+			return sr.ExpiresAt != 0 && now.Unix() >= sr.ExpiresAt
+		})
+		if len(claims.RoleExpiry) == 0 {
+			return nil
+		}
+
+		current, err := auth.RoleExpiries(db, claims.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to verify role grants")
+		}
+		claims.Roles = slices.DeleteFunc(claims.Roles, func(name string) bool {
+			if _, timed := claims.RoleExpiry[name]; !timed {
+				return false
+			}
+			expiresAt, stillGranted := current[name]
+			return !stillGranted || !expiresAt.After(now)
+		})
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+// prometheus/backend/middleware/scope.go
+package middleware
+
+import (
+	"net/http"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DivisionLoader resolves the division the requested data belongs to,
+// e.g. from a ":divisionID" path parameter or by loading the addressed record.
+type DivisionLoader func(c *gin.Context) (uint, error)
+
+// ScopedRolesFromContext returns the division-scoped roles set by AuthMiddleware.
+func ScopedRolesFromContext(c *gin.Context) []auth.ScopedRoleClaim {
+	scoped, _ := c.Get("scopedRoles")
+	claims, _ := scoped.([]auth.ScopedRoleClaim)
+	return claims
+}
+
+// DivisionScope reports which divisions the user may act on with the given role.
+// all is true when the user holds the role globally; otherwise divisionIDs lists the divisions
+// the role is scoped to (empty if the user doesn't hold the role at all).
+// Handlers use it to filter queries, e.g. WHERE division_id IN divisionIDs.
+func DivisionScope(c *gin.Context, role string) (all bool, divisionIDs []uint) {
+	if slices.Contains(RolesFromContext(c), role) {
+		return true, nil
+	}
+	for _, sr := range ScopedRolesFromContext(c) {
+		if sr.Role == role {
+			divisionIDs = append(divisionIDs, sr.DivisionID)
+		}
+	}
+	return false, divisionIDs
+}
+
+// lineHRRoles act on anyone's records in line-management modules when held globally.
+var lineHRRoles = []string{"god-admin", "admin", "hr"}
+
+// LineManagement identifies the caller of modules where HR acts on anyone's records and managers on those of
+// their reports (compensation, timesheets, rosters, expenses, documents): hr is true for the HR roles held
+// globally, and leads lists the divisions the manager role is scoped to. Holding the manager role globally
+// makes no one a report; only scoped and headed divisions do.
+func LineManagement(c *gin.Context) (userID uint, hr bool, leads []uint) {
+	roles := RolesFromContext(c)
+	hr = slices.ContainsFunc(lineHRRoles, func(role string) bool { return slices.Contains(roles, role) })
+	_, leads = DivisionScope(c, "manager")
+	return c.GetUint("userID"), hr, leads
+}
+
+// HasRoleForDivision reports whether the user holds the role globally or scoped to divisionID.
+func HasRoleForDivision(c *gin.Context, role string, divisionID uint) bool {
+	all, divisionIDs := DivisionScope(c, role)
+	return all || slices.Contains(divisionIDs, divisionID)
+}
+
+// ScopeMiddleware creates a Gin middleware that checks both role and scope: the user must hold one of
+// the roles globally, or scoped to the division returned by loadDivision.
+// This middleware should be used AFTER AuthMiddleware.
+func ScopeMiddleware(loadDivision DivisionLoader, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		divisionID, err := loadDivision(c)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Could not determine division: "+err.Error())
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if HasRoleForDivision(c, role, divisionID) {
+				c.Set("divisionID", divisionID)
+				c.Next()
+				return
+			}
+		}
+
+		utils.SendErrorResponse(c, http.StatusForbidden, "Access Denied: You do not have the required role for this division.")
+		c.Abort()
+	}
+}
@@ -0,0 +1,69 @@
+// prometheus/backend/middleware/tenant_host.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantResolver maps a request host to the organization served on it (nil for the shared host).
+// tenant.DomainService implements it.
+type TenantResolver interface {
+	Resolve(ctx context.Context, host string) (*organization.Organization, error)
+}
+
+// TenantHostMiddleware resolves the organization served on the request's host (a tenant subdomain or custom
+// domain) and stores it as "hostOrg" / "hostOrgID" in the context, along with "baseURL", the absolute base URL
+// of that host to use for generated links. Unknown tenant subdomains are answered with 404; requests to the
+// shared host carry no host organization. unknownHost is the resolver's error for unknown subdomains.
+func TenantHostMiddleware(resolver TenantResolver, links *organization.Links, unknownHost error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		org, err := resolver.Resolve(c.Request.Context(), c.Request.Host)
+		if err != nil {
+			if errors.Is(err, unknownHost) {
+				utils.SendErrorResponse(c, http.StatusNotFound, "Unknown tenant")
+			} else {
+				utils.SendErrorResponse(c, http.StatusInternalServerError, "Server Error: Failed to resolve tenant.")
+			}
+			c.Abort()
+			return
+		}
+		if org != nil {
+			c.Set("hostOrg", org)
+			c.Set("hostOrgID", org.ID)
+		}
+		c.Set("baseURL", links.BaseURL(org))
+		c.Next()
+	}
+}
+
+// MatchTenantHost is a ClaimsCheck that rejects tokens of other organizations on a tenant's host, so a token
+// issued for one white-labelled tenant can't be replayed against another. Platform users (no organization)
+// must use the shared host. Requests to the shared host are not restricted.
+// TenantHostMiddleware must run before AuthMiddleware.
+func MatchTenantHost() ClaimsCheck {
+	return func(c *gin.Context, claims *auth.Claims) error {
+		hostOrgID, ok := c.Get("hostOrgID")
+		if !ok {
+			return nil
+		}
+		if claims.OrganizationID == nil || *claims.OrganizationID != hostOrgID.(uint) {
+			return errors.New("token does not belong to this tenant")
+		}
+		return nil
+	}
+}
+
+// HostOrganization returns the organization resolved by TenantHostMiddleware, or nil on the shared host.
+func HostOrganization(c *gin.Context) *organization.Organization {
+	if org, ok := c.Get("hostOrg"); ok {
+		return org.(*organization.Organization)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// prometheus/backend/middleware/user_status.go
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"prometheus/backend/internal/auth"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RejectInactiveUsers is a ClaimsCheck that rejects tokens of users who were deactivated, deleted or
+// force-logged-out after the token was issued, instead of honouring them until they expire. The user's
+// state is read through the status cache, so most requests cost no database query.
+func RejectInactiveUsers(statuses *auth.UserStatusCache) ClaimsCheck {
+	return func(c *gin.Context, claims *auth.Claims) error {
+		status, err := statuses.Status(c.Request.Context(), claims.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to verify account status")
+		}
+		if !status.Active {
+			return errors.New("account is deactivated")
+		}
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if !status.Accepts(issuedAt) {
+			return errors.New("session was revoked, please log in again")
+		}
+		return nil
+	}
+}
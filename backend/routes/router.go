@@ -2,116 +2,614 @@
 package routes
 
 import (
+	"log"
 	"net/http"
 	"prometheus/backend/config"
+	"prometheus/backend/internal/agreement"
+	"prometheus/backend/internal/analytics"
+	"prometheus/backend/internal/announcement"
+	"prometheus/backend/internal/apikey"
+	"prometheus/backend/internal/approval"
+	"prometheus/backend/internal/asset"
+	"prometheus/backend/internal/ats"
+	"prometheus/backend/internal/attendance"
+	"prometheus/backend/internal/audit"
 	"prometheus/backend/internal/auth"
+	"prometheus/backend/internal/authz"
+	"prometheus/backend/internal/billing"
+	"prometheus/backend/internal/cache"
+	"prometheus/backend/internal/campaign"
+	"prometheus/backend/internal/change"
+	"prometheus/backend/internal/compensation"
+	"prometheus/backend/internal/contacts"
+	"prometheus/backend/internal/contract"
+	"prometheus/backend/internal/customfield"
+	"prometheus/backend/internal/devtools"
+	"prometheus/backend/internal/disciplinary"
+	"prometheus/backend/internal/division"
+	"prometheus/backend/internal/document"
+	"prometheus/backend/internal/employee"
+	"prometheus/backend/internal/events"
+	"prometheus/backend/internal/expense"
+	"prometheus/backend/internal/holiday"
+	"prometheus/backend/internal/jobs"
+	"prometheus/backend/internal/keyring"
+	"prometheus/backend/internal/legacy"
+	"prometheus/backend/internal/mail"
+	"prometheus/backend/internal/module"
+	"prometheus/backend/internal/notification"
+	"prometheus/backend/internal/offboarding"
+	"prometheus/backend/internal/opening"
+	"prometheus/backend/internal/organization"
+	"prometheus/backend/internal/outbound"
+	"prometheus/backend/internal/outbox"
+	"prometheus/backend/internal/payroll"
+	"prometheus/backend/internal/photoimport"
+	"prometheus/backend/internal/plan"
+	"prometheus/backend/internal/policydoc"
+	"prometheus/backend/internal/position"
+	"prometheus/backend/internal/privacy"
+	"prometheus/backend/internal/reports"
+	"prometheus/backend/internal/requisition"
+	"prometheus/backend/internal/resignation"
+	"prometheus/backend/internal/routing"
+	"prometheus/backend/internal/search"
+	"prometheus/backend/internal/skill"
+	"prometheus/backend/internal/slo"
+	"prometheus/backend/internal/storage"
+	"prometheus/backend/internal/talent"
+	"prometheus/backend/internal/tenant"
+	"prometheus/backend/internal/timesheet"
+	"prometheus/backend/internal/training"
 	"prometheus/backend/internal/utils" // For the placeholder handler & responses
-	"prometheus/backend/middleware"     // Ensure your middleware package is correctly referenced
+	"prometheus/backend/internal/worktime"
+	"prometheus/backend/middleware" // Ensure your middleware package is correctly referenced
+	"time"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 )
 
-// SetupRoutes initializes all API routes including authentication and protected routes.
-func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg *config.Config) {
-	// Health check endpoint
+// SetupRoutes initializes all API routes including authentication and protected routes. Analytics and reports
+// read through reportingDB (see database.ConnectReportingDB).
+func SetupRoutes(r *gin.Engine, db, reportingDB *gorm.DB, cfg *config.Config, enforcer *casbin.SyncedEnforcer, appCache cache.Cache, files storage.Storage, jobQueue *jobs.Queue, modules *module.Registry) {
+	// Application metrics: HTTP request metrics plus the health contributors of every registered module.
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(module.NewCollector(modules))
+	outbound.Register(metricsRegistry)
+	// Latency objectives: the metrics middleware also counts per-route latencies, which are checked against
+	// each route's target p95 and reported under GET /admin/slo.
+	var latencyObservers []middleware.LatencyObserver
+	if modules.Enabled(slo.ModuleName) {
+		objectives, err := slo.ParseObjectives(cfg.SLOTargets, time.Duration(cfg.SLODefaultP95Ms)*time.Millisecond)
+		if err != nil {
+			log.Fatalf("Error: Failed to configure the latency objectives: %v", err)
+		}
+		latencyObservers = append(latencyObservers, slo.NewTracker(db))
+		modules.RegisterFeature(slo.NewModule(db, slo.NewService(db, objectives)))
+	}
+	r.Use(middleware.MetricsMiddleware(middleware.NewHTTPMetrics(metricsRegistry), latencyObservers...))
+	moduleHandler := module.NewHandler(modules, metricsRegistry)
+
+	// Health check endpoint (liveness)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "message": "Prometheus backend is healthy and running!"})
 	})
+	// Readiness aggregates the health contributors of all modules
+	r.GET("/readyz", moduleHandler.Readyz)
+	r.GET("/metrics", moduleHandler.Metrics)
 
 	// Initialize services and handlers
+	// Fake integrations for local development: mail and payments land in an inbox served under /devtools.
+	var inbox *devtools.Inbox
+	mailSender := mail.NewSender(cfg)
+	if cfg.DevIntegrations == config.IntegrationsFake {
+		inbox = devtools.NewInbox(500)
+		mailSender = mail.NewFakeSender(inbox)
+	}
+	// Domain events. The optional bridge relays them to Kafka or NATS through an outbox.
+	var eventRelay *events.Relay
+	if cfg.EventBridge != "" && modules.Enabled(events.BridgeModuleName) {
+		publisher, err := events.NewPublisher(cfg)
+		if err != nil {
+			log.Fatalf("Error: Failed to configure the event bridge: %v", err)
+		}
+		eventRelay = events.NewRelay(db, publisher)
+	}
+	// Modules subscribe to the bus in consumer groups, which catch up on recent events when they start.
+	eventBus := events.NewBus(db, eventRelay != nil)
+	modules.Register(events.NewModule(db))
+	// Audit trail; every audited change is also published as a domain event for the change feed, and may
+	// notify users (e.g. approvers of a new role request)
+	auditService := audit.NewService(db, events.AuditHook(eventBus), notification.AuditHook(notification.DefaultRules()))
+	auditHandler := audit.NewHandler(auditService)
+	// JWT signing keys, rotated and sealed with KEY_MASTER_KEY in the database; without a master key every
+	// token is signed with JWT_SECRET
+	var signingKeys auth.SigningKeys = auth.StaticKey(cfg.JWTSecret)
+	if cfg.KeyMasterKey != "" {
+		ring, err := keyring.New(db, cfg)
+		if err != nil {
+			log.Fatalf("Error: Failed to configure the signing keys: %v", err)
+		}
+		signingKeys = ring
+		modules.Register(keyring.NewModule(ring, keyring.NewService(db, ring, auditService, cfg.AppEnv)))
+	}
 	// Auth
-	authService := auth.NewAuthService(db, cfg)
+	authService := auth.NewAuthService(db, cfg, signingKeys)
 	authHandler := auth.NewAuthHandler(authService)
+	// Deactivated users are rejected on their next request, not when their token expires
+	userStatuses := auth.NewUserStatusCache(db, appCache)
+	scopedRoleService := auth.NewScopedRoleService(db, auditService, userStatuses)
+	scopedRoleHandler := auth.NewScopedRoleHandler(scopedRoleService)
+	roleRequestService := auth.NewRoleRequestService(db, auditService)
+	roleRequestHandler := auth.NewRoleRequestHandler(roleRequestService)
+	// Signed one-click approve/reject links in approval emails
+	approvalService := approval.NewService(cfg.JWTSecret, cfg.APIBaseURL)
+	approvalService.Register(auth.RoleRequestApprovalKind, auth.RoleRequestApprovals(db, roleRequestService, userStatuses))
+	approvalHandler := approval.NewHandler(approvalService)
+	// Authorization policies
+	permissionCache := authz.NewPermissionCache(enforcer, appCache)
+	policyService := authz.NewPolicyService(db, enforcer, permissionCache, auditService)
+	policyHandler := authz.NewPolicyHandler(policyService)
+	// Signed RBAC bundles promote roles and policies from staging to production
+	bundleService := authz.NewBundleService(db, enforcer, permissionCache, auditService, cfg.RBACBundleSecret, cfg.AppEnv)
+	bundleHandler := authz.NewBundleHandler(bundleService)
+	permissionHandler := authz.NewPermissionHandler(permissionCache, middleware.AllRoleNamesFromContext)
+	// Cache administration
+	cacheHandler := cache.NewHandler(appCache, auditService)
+	// White-label tenant hosts: generated links point at the tenant's subdomain or custom domain
+	tenantLinks := organization.NewLinks(cfg.AppBaseURL, cfg.TenantBaseDomain)
+	domainService := tenant.NewDomainService(db, appCache, tenantLinks, tenant.LogCertificateHook{}, auditService)
+	domainHandler := tenant.NewDomainHandler(domainService)
+	// Tenant plans and onboarding
+	planService := plan.NewService(db, auditService)
+	planHandler := plan.NewHandler(planService)
+	// User management; imports count against the plan's employee limit
+	userAdminService := auth.NewUserAdminService(db, reportingDB, auditService, userStatuses, planService, jobQueue)
+	userAdminHandler := auth.NewUserAdminHandler(userAdminService)
+	avatarService := auth.NewAvatarService(db, files, auditService)
+	avatarHandler := auth.NewAvatarHandler(avatarService)
+	// Personal data export and anonymization (GDPR); feature modules add their data through privacy.Contributor
+	personalData := privacy.NewRegistry(privacy.CoreSources()...)
+	privacyHandler := privacy.NewHandler(privacy.NewService(db, reportingDB, files, auditService, userStatuses, personalData))
+	// HR records of employees, linked one-to-one with their login users
+	employeeService := employee.NewService(db, auditService)
+	employeeHandler := employee.NewHandler(employeeService)
+	divisionHandler := division.NewHandler(division.NewService(db, auditService), employeeService)
+	personalData.Add(employee.PrivacySource())
+	// Organization-wide search; feature modules add their content through search.Contributor: documents,
+	// announcements and policies, which serve as the knowledge base. There is no ticketing module to search.
+	searchProviders := search.NewRegistry(search.NewProvider(employee.SearchType, "", employeeService.Search))
+	// Public holiday calendars per country or location; they decide which days count as working days
+	holidayService := holiday.NewService(db, employeeService, auditService)
+	modules.RegisterFeature(holiday.NewModule(holidayService))
+	// Clocking in and out, checked against office geofences per the organization's policy
+	modules.RegisterFeature(attendance.NewModule(db, attendance.NewService(db, employeeService, holidayService, auditService)))
+	loginHistoryHandler := auth.NewLoginHistoryHandler(auth.NewLoginHistoryService(db))
+	preferenceService := auth.NewPreferenceService(db)
+	preferenceHandler := auth.NewPreferenceHandler(preferenceService)
+	// Company-specific user attributes, stored as JSON on the user
+	customFieldHandler := customfield.NewHandler(customfield.NewService(db, auditService))
+	// Email templates, customizable per organization
+	mailTemplateService := mail.NewTemplateService(db, mailSender, auditService)
+	mailTemplateHandler := mail.NewTemplateHandler(mailTemplateService)
+	billingService := billing.NewService(db, cfg, planService, auditService, tenantLinks, inbox)
+	// Without the billing module, tenants are limited by their plan alone.
+	var moduleChecker plan.ModuleChecker = planService
+	if modules.RegisterFeature(billing.NewModule(billingService)) {
+		moduleChecker = billingService
+	}
+	modules.RegisterFeature(search.NewModule(search.NewService(searchProviders, moduleChecker)))
+	onboardingService := tenant.NewOnboardingService(db, enforcer, auditService, planService)
+	onboardingHandler := tenant.NewOnboardingHandler(onboardingService)
+	// Demo tenant for sales demos: reset on demand and nightly (checked hourly, runs in DEMO_RESET_HOUR)
+	demoService := tenant.NewDemoService(db, cfg, enforcer, appCache, auditService)
+	modules.RegisterFeature(tenant.NewDemoModule(db, cfg, demoService, jobQueue))
+	// Transactional outbox: side effects are recorded with the change that triggers them and relayed after commit
+	messages := outbox.New(db)
+	messages.Register(mail.OutboxKind, mail.Dispatcher(mailSender))
+	modules.Register(messages)
+	// In-app notifications, emailed one by one or as hourly/daily digests per the user's preferences
+	var notificationTracker *notification.Tracker
+	if cfg.NotificationEmailTracking {
+		notificationTracker = notification.NewTracker(cfg.JWTSecret, cfg.APIBaseURL)
+	}
+	notificationService := notification.NewService(db, messages, mailTemplateService, preferenceService, cfg.AppBaseURL, notificationTracker, approvalService)
+	modules.Register(notification.NewModule(db, notificationService))
+	// Announcements by division leads; HR reviews those reaching beyond the lead's divisions
+	modules.RegisterFeature(announcement.NewModule(announcement.NewService(db, auditService)))
+	// Versioned policy documents; leave, payroll and campaigns use the revision in effect on their date
+	policyDocService := policydoc.NewService(db, auditService)
+	modules.RegisterFeature(policydoc.NewModule(policyDocService))
+	// One-off HR messages to a filtered audience, sent as notifications in throttled batches
+	modules.RegisterFeature(campaign.NewModule(db, campaign.NewService(db, notificationService, policyDocService, auditService)))
+	// Scheduled report subscriptions, delivered by email
+	reportService := reports.NewService(db, reportingDB, reports.NewCatalog(), messages, mailTemplateService, auditService, cfg.JWTSecret, cfg.APIBaseURL)
+	modules.RegisterFeature(reports.NewModule(db, reportService))
+	// Analytics query API over predefined HR datasets, filtered per role
+	modules.RegisterFeature(analytics.NewModule(analytics.NewService(reportingDB, appCache)))
+	// Skill matrix, the levels job titles require and the skills gap report for L&D planning
+	skillService := skill.NewService(db, reportingDB, employeeService, auditService)
+	modules.RegisterFeature(skill.NewModule(skillService))
+	// Courses, sessions and enrollments with certificates, and compliance with mandatory training per division
+	trainingService := training.NewService(db, reportingDB, employeeService, files, planService, auditService)
+	if modules.RegisterFeature(training.NewModule(trainingService)) {
+		skillService.UseTraining(trainingService)
+	}
+	// Review cycles placing employees on the nine-box grid, calibrated by HR
+	modules.RegisterFeature(talent.NewModule(talent.NewService(db, employeeService, auditService)))
+	// Salary history and bands, and compensation reviews with manager proposals within division budgets
+	compensationService := compensation.NewService(db, employeeService, auditService)
+	modules.RegisterFeature(compensation.NewModule(compensationService))
+	// Promotions, transfers and salary changes entered ahead and applied by the scheduler on their effective date
+	modules.RegisterFeature(change.NewModule(db, change.NewService(db, employeeService, compensationService, auditService)))
+	// Departures with exit checklists, revoking the employee's access once their termination date has passed
+	offboardingService := offboarding.NewService(db, employeeService, userAdminService, auditService)
+	offboardingEnabled := modules.RegisterFeature(offboarding.NewModule(db, offboardingService))
+	// Company assets handed out to employees, their condition and return, collected on exit checklists
+	var assetChecklist asset.Checklist
+	if offboardingEnabled {
+		assetChecklist = offboardingService
+	}
+	assetService := asset.NewService(db, employeeService, assetChecklist, auditService)
+	if modules.RegisterFeature(asset.NewModule(assetService)) && offboardingEnabled {
+		offboardingService.UseAssets(assetService)
+	}
+	// Employment contracts per employee, reminding HR ahead of fixed-term ones expiring
+	modules.RegisterFeature(contract.NewModule(db, contract.NewService(db, employeeService, auditService, cfg.ContractReminderDays)))
+	// Confidential disciplinary records, seen only by HR and god-admins and never edited nor deleted
+	modules.RegisterFeature(disciplinary.NewModule(disciplinary.NewService(db, employeeService, auditService)))
+	// Emergency contacts and dependents employees keep on their profile, read by HR
+	modules.RegisterFeature(contacts.NewModule(contacts.NewService(db, employeeService, auditService)))
+	// Resignations acknowledged by the manager then HR, who may schedule the offboarding, with exit interviews
+	resignationService := resignation.NewService(db, employeeService, auditService)
+	if modules.RegisterFeature(resignation.NewModule(resignationService)) && offboardingEnabled {
+		resignationService.UseOffboarding(offboardingService)
+	}
+	// Budgeted positions per division, their holders and vacancies, and budgeted vs actual headcount
+	positionService := position.NewService(db, reportingDB, employeeService, auditService)
+	modules.RegisterFeature(position.NewModule(positionService))
+	// Requisitions to recruit for vacant positions, approved by finance and the division head, with their hires
+	requisitionService := requisition.NewService(db, positionService, employeeService, auditService)
+	approvalService.Register(requisition.FinanceApprovalKind, requisition.Approvals(db, requisitionService, userStatuses, requisition.StepFinance))
+	approvalService.Register(requisition.HeadApprovalKind, requisition.Approvals(db, requisitionService, userStatuses, requisition.StepHead))
+	modules.RegisterFeature(requisition.NewModule(requisitionService))
+	// Internal job openings written by HR, open once an admin approves them and listed to employees
+	modules.RegisterFeature(opening.NewModule(opening.NewService(db, requisitionService, auditService)))
+	// Applicant tracking: candidates moving from applied to hired, interviewer feedback, and hiring into an employee with an invited user
+	modules.RegisterFeature(ats.NewModule(ats.NewService(db, employeeService, requisitionService, planService, messages, mailTemplateService, auditService, cfg.AppBaseURL)))
+	// Weekly timesheets against projects and tasks, approved by managers and reopened by HR
+	modules.RegisterFeature(timesheet.NewModule(timesheet.NewService(db, employeeService, auditService)))
+	// Collective agreements overriding the default overtime, notice and leave terms of the employees they cover
+	agreementService := agreement.NewService(db, employeeService, policyDocService, auditService)
+	modules.RegisterFeature(agreement.NewModule(agreementService))
+	// Payroll periods previewing salary, unpaid leave and approved overtime per employee before HR closes them
+	modules.RegisterFeature(payroll.NewModule(payroll.NewService(db, employeeService, holidayService, compensationService,
+		agreementService, policyDocService, auditService)))
+	// Rosters, and working-time rules checked against them and actual attendance
+	modules.RegisterFeature(worktime.NewModule(worktime.NewService(db, employeeService, auditService)))
+	// Salary, leave and review history imported from legacy HR systems, reconciled with current employees
+	modules.RegisterFeature(legacy.NewModule(legacy.NewService(db, auditService)))
+	// Employee photos imported in bulk from a zip archive, cropped around the face and set as avatars
+	modules.RegisterFeature(photoimport.NewModule(photoimport.NewService(db, files, employeeService, avatarService, jobQueue)))
+	// Expense claims with receipts, approved by managers and reimbursed by finance within category limits
+	modules.RegisterFeature(expense.NewModule(expense.NewService(db, employeeService, files, planService, auditService)))
+	// Contracts, policies and certificates with revision history, expiry dates and role-based visibility
+	modules.RegisterFeature(document.NewModule(document.NewService(db, employeeService, files, planService, auditService)))
+	// Time-limited role grants are revoked by a recurring job
+	jobQueue.Register(auth.JobExpireRoleGrants, auth.ExpireRoleGrantsJob(db, auditService))
+	jobQueue.Every(auth.JobExpireRoleGrants, time.Minute)
+	// CSV user imports create their accounts in the background
+	jobQueue.Register(auth.JobImportUsers, auth.ImportUsersJob(userAdminService))
+	if eventRelay != nil {
+		modules.RegisterFeature(events.NewBridgeModule(eventRelay))
+	}
+	// Change feed for integrators, read with API keys; events expire after events.Retention
+	eventFeed := events.NewFeed(db)
+	eventHandler := events.NewHandler(eventFeed)
+	jobQueue.Register(events.JobPurge, events.PurgeJob(eventFeed))
+	jobQueue.Every(events.JobPurge, time.Hour)
+	apiKeyService := apikey.NewService(db, auditService, apikey.SpikeNotifierFunc(func(tx *gorm.DB, alerts []apikey.SpikeAlert) error {
+		notices := make([]notification.Notice, 0, len(alerts))
+		for _, a := range alerts {
+			notices = append(notices, notification.Notice{
+				UserID: a.UserID, OrganizationID: a.OrganizationID, Category: notification.CategorySecurity + ".api_key_spike",
+				Subject: a.Subject, Body: a.Body, Link: a.Link,
+			})
+		}
+		return notification.CreateTx(tx, notices...)
+	}))
+	apiKeyHandler := apikey.NewHandler(apiKeyService)
+	jobQueue.Register(apikey.JobDetectSpikes, apikey.DetectSpikesJob(apiKeyService))
+	jobQueue.Every(apikey.JobDetectSpikes, time.Hour)
+	// Long-running operations (backed by the job queue)
+	operationHandler := jobs.NewOperationHandler(jobQueue)
+
+	// On-demand TLS check for tenant custom domains (e.g. Caddy's on_demand_tls "ask"); not part of the API.
+	r.GET("/tls/ask", domainHandler.AskCertificate)
 
 	// API v1 Group
+	// Routes being retired in favour of v2 should be wrapped with middleware.DeprecatedRoute
+	// so callers receive Deprecation/Sunset headers and show up in the logs.
 	apiV1 := r.Group("/api/v1")
+	// Clients may request unwrapped payloads with "X-Response-Envelope: raw".
+	apiV1.Use(middleware.EnvelopeMiddleware(cfg.ResponseEnvelope))
+	// Resolve the tenant served on the request host (subdomain or custom domain) before authentication.
+	apiV1.Use(middleware.TenantHostMiddleware(domainService, tenantLinks, tenant.ErrUnknownTenantHost))
+
+	// Every route is registered through the route registry with its access requirement; the registry
+	// applies the matching middleware (JWT authentication, plan module check, role gate or Casbin policy)
+	// and lists the routes under GET /admin/routes for audits.
+	// Policy routes are authorized by Casbin policies stored in the database
+	// (see internal/authz for the default role matrix).
+	routeRegistry := routing.NewRegistry(
+		middleware.AuthMiddleware(signingKeys, middleware.MatchTenantHost(), middleware.RejectInactiveUsers(userStatuses),
+			middleware.DropExpiredRoles(db)),
+		middleware.APIKeyMiddleware(apiKeyService),
+		enforcer, authz.DefaultDomain, moduleChecker,
+	)
+	routeHandler := routing.NewHandler(routeRegistry)
+	api := routeRegistry.Group(apiV1)
 	{
 		// --- Authentication Routes (Public) ---
-		authRoutes := apiV1.Group("/auth")
+		authRoutes := api.Group("/auth")
 		{
-			authRoutes.POST("/register", authHandler.Register)
-			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/register", routing.Public(), authHandler.Register)
+			authRoutes.POST("/login", routing.Public(), authHandler.Login)
 			// TODO: Add future auth routes: /refresh-token, /logout, /forgot-password, /reset-password
 		}
 
-		// --- Protected Routes (Require Authentication via JWT) ---
-		protected := apiV1.Group("/")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret)) // Apply JWT authentication
+		// --- Branding of the current host (Public, for the white-labelled login page) ---
+		api.GET("/branding", routing.Public(), domainHandler.GetBranding)
+
+		// --- Devtools inbox of the fake integrations (DEV_INTEGRATIONS=fake only, never in production) ---
+		// god-admin only: the inbox holds every user's mail, password reset links included
+		if inbox != nil {
+			devtoolsHandler := devtools.NewHandler(inbox)
+			inboxAdmin := routing.Roles("god-admin")
+			api.GET("/devtools/inbox", inboxAdmin, devtoolsHandler.List)
+			api.GET("/devtools/inbox/:id", inboxAdmin, devtoolsHandler.Get)
+			api.DELETE("/devtools/inbox", inboxAdmin, devtoolsHandler.Clear)
+		}
+		// --- Devtools time travel (not in production); god-admin only since it affects every user ---
+		if cfg.AppEnv != "production" {
+			timeHandler := devtools.NewTimeHandler()
+			devAdmin := routing.Roles("god-admin")
+			api.GET("/devtools/time", devAdmin, timeHandler.Get)
+			api.POST("/devtools/time/freeze", devAdmin, timeHandler.Freeze)
+			api.POST("/devtools/time/advance", devAdmin, timeHandler.Advance)
+			api.POST("/devtools/time/resume", devAdmin, timeHandler.Resume)
+			api.DELETE("/devtools/time", devAdmin, timeHandler.Reset)
+		}
+
+		// --- Authenticated Routes (any valid JWT) ---
+		// Example: Get current authenticated user's profile
+		api.GET("/me", routing.Authenticated(), func(c *gin.Context) {
+			userID, _ := c.Get("userID")
+			username, _ := c.Get("username")
+			email, _ := c.Get("email")
+			roles, _ := c.Get("roles")
+
+			utils.SendSuccessResponse(c, http.StatusOK, "Current user profile fetched successfully", gin.H{
+				"id":       userID,
+				"username": username,
+				"email":    email,
+				"roles":    roles,
+			})
+		})
+
+		// Effective permissions of the caller, used by the frontend to show/hide UI elements.
+		api.GET("/me/permissions", routing.Authenticated(), permissionHandler.MyPermissions)
+		// Own login attempts, to spot logins the user doesn't recognize
+		api.GET("/me/login-history", routing.Authenticated(), loginHistoryHandler.Mine)
+		api.GET("/me/preferences", routing.Authenticated(), preferenceHandler.Get)
+		api.PUT("/me/preferences", routing.Authenticated(), preferenceHandler.Put)
+		if cfg.UsernameSelfService {
+			api.PUT("/me/username", routing.Authenticated(), userAdminHandler.ChangeOwnUsername)
+		}
+
+		// Avatars: uploaded by the user, visible to their organization
+		api.POST("/me/avatar", routing.Authenticated(), avatarHandler.Upload)
+		api.DELETE("/me/avatar", routing.Authenticated(), avatarHandler.Delete)
+		api.GET("/me/data-export", routing.Authenticated(), privacyHandler.ExportMine)
+		api.GET("/me/employee", routing.Authenticated(), employeeHandler.Mine)
+		api.PUT("/me/employee/profile", routing.Authenticated(), employeeHandler.UpdateMyProfile)
+		api.GET("/employees/:id/profile", routing.Authenticated(), employeeHandler.Profile)
+		api.GET("/org-chart", routing.Authenticated(), employeeHandler.OrgChart)
+		api.GET("/users/:id/avatar", routing.Authenticated(), avatarHandler.Get)
+
+		// --- Long-Running Operations ---
+		// Async endpoints (imports, exports, payroll runs) return an operation ID; poll or cancel it here.
+		api.GET("/operations/:id", routing.Authenticated(), operationHandler.Get)
+		api.DELETE("/operations/:id", routing.Authenticated(), operationHandler.Cancel)
+
+		// --- Change Feed (API key) ---
+		// External systems poll domain events instead of receiving webhooks; keys are managed under /admin/api-keys.
+		api.GET("/events", routing.APIKey("events"), eventHandler.List)
+
+		// Feature-module routes should declare their module with Access.InModule(plan.ModuleX)
+		// so tenants whose plan lacks the module (or whose subscription lapsed) get 402.
+
+		// --- Policy Management Routes ---
+		// Kept on a hardcoded god-admin gate so a bad policy change can never lock everyone out.
+		policyRoutes := api.Group("/admin/policies")
+		godAdmin := routing.Roles("god-admin")
+		{
+			policyRoutes.GET("", godAdmin, policyHandler.ListPolicies)
+			policyRoutes.POST("", godAdmin, policyHandler.AddPolicy)
+			policyRoutes.DELETE("", godAdmin, policyHandler.RemovePolicy)
+			policyRoutes.GET("/role-links", godAdmin, policyHandler.ListRoleLinks)
+			policyRoutes.POST("/role-links", godAdmin, policyHandler.AddRoleLink)
+			policyRoutes.DELETE("/role-links", godAdmin, policyHandler.RemoveRoleLink)
+		}
+		// Export from one environment, preview with ?dry_run=true and import into another
+		api.GET("/admin/rbac/export", godAdmin, bundleHandler.Export)
+		api.POST("/admin/rbac/import", godAdmin, middleware.DryRunMiddleware(), bundleHandler.Import)
+
+		// --- Role Grant Approvals ---
+		// Elevated roles (hr, finance, admin, god-admin) only take effect once a god-admin approves the request.
+		roleApprovalRoutes := api.Group("/admin/role-requests")
+		{
+			roleApprovalRoutes.POST("/:id/approve", godAdmin, roleRequestHandler.Approve)
+			roleApprovalRoutes.POST("/:id/reject", godAdmin, roleRequestHandler.Reject)
+		}
+		// Approve/reject links of approval emails; the signed token authenticates the approver
+		api.GET("/actions/:token", routing.Public(), approvalHandler.Show)
+		api.POST("/actions/:token", routing.Public(), approvalHandler.Decide)
+
+		// --- Admin Only Routes ---
+		// --- Tenant Onboarding (god-admin only) ---
+		// Each step is idempotent; GET .../onboarding reports the next step so provisioning can be resumed.
+		tenantRoutes := api.Group("/admin/tenants")
 		{
-			// Example: Get current authenticated user's profile
-			protected.GET("/me", func(c *gin.Context) {
-				userID, _ := c.Get("userID")
-				username, _ := c.Get("username")
-				email, _ := c.Get("email")
-				role, _ := c.Get("role")
-
-				utils.SendSuccessResponse(c, http.StatusOK, "Current user profile fetched successfully", gin.H{
-					"id":       userID,
-					"username": username,
-					"email":    email,
-					"role":     role,
+			tenantRoutes.POST("", godAdmin, onboardingHandler.CreateOrganization)
+			tenantRoutes.GET("/:id/onboarding", godAdmin, onboardingHandler.Status)
+			tenantRoutes.POST("/:id/onboarding/roles", godAdmin, onboardingHandler.SeedRoles)
+			tenantRoutes.POST("/:id/onboarding/admin", godAdmin, onboardingHandler.CreateAdmin)
+			tenantRoutes.POST("/:id/onboarding/calendar", godAdmin, onboardingHandler.SetCalendar)
+			tenantRoutes.POST("/:id/onboarding/employees", godAdmin, onboardingHandler.ImportEmployees)
+			tenantRoutes.POST("/:id/onboarding/complete", godAdmin, onboardingHandler.Complete)
+			// Plans (max employees, enabled modules, storage quota)
+			tenantRoutes.GET("/:id/plan", godAdmin, planHandler.GetUsage)
+			tenantRoutes.PUT("/:id/plan", godAdmin, planHandler.ChangePlan)
+			// White-label custom domain and branding
+			tenantRoutes.PUT("/:id/domain", godAdmin, domainHandler.SetCustomDomain)
+			tenantRoutes.DELETE("/:id/domain", godAdmin, domainHandler.RemoveCustomDomain)
+			tenantRoutes.PUT("/:id/branding", godAdmin, domainHandler.SetBranding)
+		}
+		api.GET("/admin/plans", godAdmin, planHandler.ListPlans)
+
+		// These routes require the 'admin' permission (inherited by 'god-admin').
+		adminRoutes := api.Group("/admin")
+		{
+			adminRoutes.GET("/dashboard", routing.Policy(), func(c *gin.Context) {
+				username, _ := c.Get("username") // Username is set by AuthMiddleware
+				utils.SendSuccessResponse(c, http.StatusOK, "Admin dashboard data loaded.", gin.H{
+					"message": "Welcome to the admin dashboard, " + username.(string) + "!",
 				})
 			})
+			// Every registered route with its access requirement, for audits
+			adminRoutes.GET("/routes", routing.Policy(), routeHandler.ListRoutes)
+			// Immutable audit trail of role and permission changes
+			adminRoutes.GET("/audit-logs", routing.Policy(), auditHandler.List)
+			// Cache inspection and invalidation after out-of-band DB changes
+			adminRoutes.GET("/cache", routing.Policy(), cacheHandler.ListNamespaces)
+			adminRoutes.GET("/cache/:namespace", routing.Policy(), cacheHandler.GetNamespace)
+			adminRoutes.DELETE("/cache/:namespace", routing.Policy(), cacheHandler.FlushNamespace)
+			adminRoutes.DELETE("/cache/:namespace/:key", routing.Policy(), cacheHandler.DeleteKey)
+			// API keys of external systems (tenant admins manage their own organization's keys)
+			adminRoutes.GET("/api-keys", routing.Policy(), apiKeyHandler.List)
+			adminRoutes.POST("/api-keys", routing.Policy(), apiKeyHandler.Create)
+			adminRoutes.PUT("/api-keys/:id", routing.Policy(), apiKeyHandler.Update)
+			adminRoutes.DELETE("/api-keys/:id", routing.Policy(), apiKeyHandler.Revoke)
+			adminRoutes.GET("/api-keys/:id/usage", routing.Policy(), apiKeyHandler.Usage)
+			adminRoutes.GET("/custom-fields", routing.Policy(), customFieldHandler.List)
+			adminRoutes.POST("/custom-fields", routing.Policy(), customFieldHandler.Create)
+			adminRoutes.PUT("/custom-fields/:id", routing.Policy(), customFieldHandler.Update)
+			adminRoutes.DELETE("/custom-fields/:id", routing.Policy(), customFieldHandler.Delete)
+			// Email templates: customize, preview with sample data and test-send before enabling
+			adminRoutes.GET("/mail-templates", routing.Policy(), mailTemplateHandler.List)
+			adminRoutes.GET("/mail-templates/:name", routing.Policy(), mailTemplateHandler.Get)
+			adminRoutes.PUT("/mail-templates/:name", routing.Policy(), mailTemplateHandler.Save)
+			adminRoutes.DELETE("/mail-templates/:name", routing.Policy(), mailTemplateHandler.Reset)
+			adminRoutes.POST("/mail-templates/:name/preview", routing.Policy(), mailTemplateHandler.Preview)
+			adminRoutes.POST("/mail-templates/:name/test", routing.Policy(), mailTemplateHandler.SendTest)
+			// User management (tenant admins are limited to their own organization's users)
+			adminRoutes.GET("/users", routing.Policy(), userAdminHandler.List)
+			adminRoutes.GET("/users/export", routing.Policy(), userAdminHandler.Export)
+			adminRoutes.GET("/users/deleted", routing.Policy(), userAdminHandler.ListDeleted)
+			adminRoutes.POST("/users/import", routing.Policy(), middleware.DryRunMiddleware(), userAdminHandler.Import)
+			adminRoutes.GET("/users/imports/:id", routing.Policy(), userAdminHandler.GetImport)
+			adminRoutes.GET("/users/:id", routing.Policy(), userAdminHandler.Get)
+			adminRoutes.PUT("/users/:id", routing.Policy(), userAdminHandler.Update)
+			adminRoutes.DELETE("/users/:id", routing.Policy(), userAdminHandler.Delete)
+			adminRoutes.POST("/users/:id/restore", routing.Policy(), userAdminHandler.Restore)
+			adminRoutes.PUT("/users/:id/status", routing.Policy(), userAdminHandler.SetStatus)
+			adminRoutes.POST("/users/:id/logout", routing.Policy(), userAdminHandler.ForceLogout)
+			adminRoutes.PUT("/users/:id/username", routing.Policy(), userAdminHandler.ChangeUsername)
+			adminRoutes.POST("/users/:id/anonymize", routing.Policy(), privacyHandler.Anonymize)
+			adminRoutes.GET("/users/:id/custom-fields", routing.Policy(), customFieldHandler.GetValues)
+			adminRoutes.PUT("/users/:id/custom-fields", routing.Policy(), customFieldHandler.SetValues)
+			adminRoutes.GET("/users/:id/login-history", routing.Policy(), loginHistoryHandler.ForUser)
+			adminRoutes.PUT("/users/:id/role", routing.Policy(), userAdminHandler.SetRoles)
+			// Role grant requests (approved via /admin/role-requests/:id/approve by a god-admin)
+			adminRoutes.GET("/role-requests", routing.Policy(), roleRequestHandler.List)
+			adminRoutes.POST("/users/:id/role-requests", routing.Policy(), roleRequestHandler.Create)
+			// Division-scoped role assignments (e.g. "manager of Engineering")
+			adminRoutes.GET("/users/:id/scoped-roles", routing.Policy(), scopedRoleHandler.List)
+			adminRoutes.POST("/users/:id/scoped-roles", routing.Policy(), scopedRoleHandler.Assign)
+			adminRoutes.DELETE("/users/:id/scoped-roles/:assignmentID", routing.Policy(), scopedRoleHandler.Revoke)
+			// TODO: Add more admin-specific routes: system settings etc.
+		}
 
-			// --- Admin Only Routes (Example of RBAC) ---
-			// These routes require authentication AND 'admin' or 'god-admin' role.
-			adminRoutes := protected.Group("/admin")
-			// Apply RBACMiddleware for admin roles AFTER AuthMiddleware
-			adminRoutes.Use(middleware.RBACMiddleware("admin", "god-admin"))
-			{
-				adminRoutes.GET("/dashboard", func(c *gin.Context) {
-					username, _ := c.Get("username") // Username is set by AuthMiddleware
-					utils.SendSuccessResponse(c, http.StatusOK, "Admin dashboard data loaded.", gin.H{
-						"message": "Welcome to the admin dashboard, " + username.(string) + "!",
-					})
-				})
-				// TODO: Add more admin-specific routes: user management, system settings, audit logs etc.
-				// adminRoutes.GET("/users", userHandler.ListUsers)
-				// adminRoutes.PUT("/users/:userID/status", userHandler.UpdateUserStatus)
-			}
-
-			// --- HR Routes (Example of RBAC) ---
-			hrRoutes := protected.Group("/hr")
-			// HR, Admin, and GodAdmin can access these routes
-			hrRoutes.Use(middleware.RBACMiddleware("hr", "admin", "god-admin"))
-			{
-				hrRoutes.GET("/employee-data", func(c *gin.Context) {
-					utils.SendSuccessResponse(c, http.StatusOK, "Sensitive Employee Data (Mock)", gin.H{
-						"data": "This is mock HR-specific employee data accessible by HR, Admin, GodAdmin.",
-					})
-				})
-				// TODO: Add more HR-specific routes: manage employee profiles, leave requests, payroll previews etc.
-			}
-
-			// --- Manager Routes (Example of RBAC) ---
-			managerRoutes := protected.Group("/manager")
-			// Managers, HR, Admin, and GodAdmin can access these routes
-			managerRoutes.Use(middleware.RBACMiddleware("manager", "hr", "admin", "god-admin"))
-			{
-				managerRoutes.GET("/team-overview", func(c *gin.Context) {
-					utils.SendSuccessResponse(c, http.StatusOK, "Team Overview Data (Mock)", gin.H{
-						"data": "This is mock data for a manager's team.",
-					})
+		// --- HR Routes ---
+		// HR, Admin, and GodAdmin can access these routes
+		hrRoutes := api.Group("/hr")
+		{
+			hrRoutes.GET("/employee-data", routing.Policy(), func(c *gin.Context) {
+				utils.SendSuccessResponse(c, http.StatusOK, "Sensitive Employee Data (Mock)", gin.H{
+					"data": "This is mock HR-specific employee data accessible by HR, Admin, GodAdmin.",
 				})
-				// TODO: Add routes for approving leave, overtime for team members.
-			}
-
-			// --- Staff Routes (Example of RBAC) ---
-			// Example for a 'staff' accessible route (most permissive after login)
-			// All authenticated users (staff, manager, hr, admin, god-admin) can access these.
-			staffAccessibleRoutes := protected.Group("/staff-area") // Using a more descriptive group name
-			staffAccessibleRoutes.Use(middleware.RBACMiddleware("staff", "manager", "hr", "admin", "god-admin"))
-			{
-				staffAccessibleRoutes.GET("/my-tasks", func(c *gin.Context) {
-					utils.SendSuccessResponse(c, http.StatusOK, "List of my tasks (Mock)", gin.H{
-						"tasks": []string{"Complete TPS reports", "Attend mandatory fun session"},
-					})
+			})
+			hrRoutes.GET("/employees", routing.Policy(), employeeHandler.List)
+			hrRoutes.POST("/employees", routing.Policy(), employeeHandler.Create)
+			hrRoutes.GET("/employees/:id", routing.Policy(), employeeHandler.Get)
+			hrRoutes.PUT("/employees/:id", routing.Policy(), employeeHandler.Update)
+			hrRoutes.DELETE("/employees/:id", routing.Policy(), employeeHandler.Delete)
+			hrRoutes.GET("/employee-name-policy", routing.Policy(), employeeHandler.GetNamePolicy)
+			hrRoutes.PUT("/employee-name-policy", routing.Policy(), employeeHandler.PutNamePolicy)
+			hrRoutes.GET("/divisions", routing.Policy(), divisionHandler.List)
+			hrRoutes.POST("/divisions", routing.Policy(), divisionHandler.Create)
+			hrRoutes.GET("/divisions/:id", routing.Policy(), divisionHandler.Get)
+			hrRoutes.PUT("/divisions/:id", routing.Policy(), divisionHandler.Update)
+			hrRoutes.DELETE("/divisions/:id", routing.Policy(), divisionHandler.Delete)
+			hrRoutes.GET("/divisions/:id/members", routing.Policy(), divisionHandler.Members)
+			hrRoutes.POST("/divisions/:id/members", routing.Policy(), divisionHandler.AssignMembers)
+			hrRoutes.DELETE("/divisions/:id/members/:employeeID", routing.Policy(), divisionHandler.RemoveMember)
+			// TODO: Add more HR-specific routes: leave requests, payroll previews etc.
+		}
+
+		// --- Manager Routes ---
+		// Managers, HR, Admin, and GodAdmin can access these routes
+		managerRoutes := api.Group("/manager")
+		{
+			// Division managers only see the divisions they manage
+			managerRoutes.GET("/team-overview", routing.Policy(), divisionHandler.TeamOverview)
+			managerRoutes.GET("/divisions/:divisionID/members", routing.Policy(), divisionHandler.TeamMembers)
+			// TODO: Add routes for approving leave, overtime for team members.
+		}
+
+		// --- Staff Routes ---
+		// All authenticated users (staff, manager, hr, admin, god-admin) can access these.
+		staffAccessibleRoutes := api.Group("/staff-area") // Using a more descriptive group name
+		{
+			staffAccessibleRoutes.GET("/my-tasks", routing.Policy(), func(c *gin.Context) {
+				utils.SendSuccessResponse(c, http.StatusOK, "List of my tasks (Mock)", gin.H{
+					"tasks": []string{"Complete TPS reports", "Attend mandatory fun session"},
 				})
-			}
+			})
+		}
+
+		// TODO: Add other routes for different modules (leave, etc.)
+		// Policy routes need a matching Casbin policy.
+	}
 
-			// TODO: Add other protected routes for different modules (user, division, attendance, etc.)
-			// Ensure each group has appropriate RBACMiddleware.
+	// Enabled feature modules register their own routes, jobs, personal data and searchable content; disabled
+	// ones were never registered.
+	for _, m := range modules.Modules() {
+		if rc, ok := m.(routing.Contributor); ok {
+			rc.RegisterRoutes(api)
+		}
+		if jc, ok := m.(jobs.Contributor); ok {
+			jc.RegisterJobs(jobQueue)
+		}
+		if pc, ok := m.(privacy.Contributor); ok {
+			personalData.Add(pc.PrivacySources()...)
+		}
+		if sc, ok := m.(search.Contributor); ok {
+			searchProviders.Add(sc.SearchProviders()...)
 		}
 	}
 